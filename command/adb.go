@@ -0,0 +1,99 @@
+package command
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/v-byte-cpu/sx/command/log"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+	"github.com/v-byte-cpu/sx/pkg/scan/adb"
+)
+
+const defaultADBPort = 5555
+
+func newADBCmd() *adbCmd {
+	c := &adbCmd{}
+
+	cmd := &cobra.Command{
+		Use: "adb [flags] [subnet]",
+		Example: strings.Join([]string{
+			"adb 192.168.0.1/24", "adb -p 5555,5556 10.0.0.1",
+			"adb --json --getprop 10.0.0.1/16",
+			"adb -f ip_ports_file.jsonl", "adb -p 5555 -f ips_file.jsonl"}, "\n"),
+		Short: "Perform Android Debug Bridge scan",
+		Long: strings.Join([]string{
+			"Perform Android Debug Bridge scan.",
+			"The CNXN handshake is sent to each target, port 5555 by default, and daemons are reported with the state:",
+			"  device - the connection is accepted without authentication, the device properties of the banner are reported",
+			"  auth   - the RSA key authorized on the device is required",
+			"  tls    - TLS of wireless debugging is required",
+			"With the getprop option the Android version of devices without authentication is read",
+			"with the getprop command of the shell service."}, "\n"),
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer cancel()
+
+			if err = c.opts.parseRawOptions(); err != nil {
+				return
+			}
+			scanRange, err := c.opts.parseScanRange(args)
+			if err != nil {
+				return
+			}
+
+			var logger log.Logger
+			if logger, err = c.opts.getLogger(adb.ScanType, resultWriter); err != nil {
+				return
+			}
+
+			engine := c.opts.newADBScanEngine(ctx)
+			return c.opts.runScanEngine(ctx, cmd, engine, scanRange, logger)
+		},
+	}
+
+	c.opts.initCliFlags(cmd)
+
+	c.cmd = cmd
+	return c
+}
+
+type adbCmd struct {
+	cmd  *cobra.Command
+	opts adbCmdOpts
+}
+
+type adbCmdOpts struct {
+	genericScanCmdOpts
+	timeout time.Duration
+	getprop bool
+}
+
+func (o *adbCmdOpts) initCliFlags(cmd *cobra.Command) {
+	o.genericScanCmdOpts.initCliFlags(cmd)
+	cmd.Flags().DurationVarP(&o.timeout, "timeout", "t", 2*time.Second, "set connect and data timeout")
+	cmd.Flags().BoolVar(&o.getprop, "getprop", false,
+		"read Android version of devices without authentication with the getprop command of the shell service")
+}
+
+func (o *adbCmdOpts) parseRawOptions() (err error) {
+	if err = o.genericScanCmdOpts.parseRawOptions(); err != nil {
+		return
+	}
+	// targets of the subnet argument are scanned on the standard port unless ports are set
+	if len(o.portRanges) == 0 && len(o.ipFile) == 0 && len(o.rawInput) == 0 {
+		o.portRanges = []*scan.PortRange{{StartPort: defaultADBPort, EndPort: defaultADBPort}}
+	}
+	return
+}
+
+func (o *adbCmdOpts) newADBScanEngine(ctx context.Context) scan.EngineResulter {
+	return o.newScanEngine(ctx, adb.NewScanner(
+		adb.WithDialTimeout(o.timeout),
+		adb.WithDataTimeout(o.timeout),
+		adb.WithGetprop(o.getprop),
+	))
+}
@@ -0,0 +1,88 @@
+package command
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+func TestADBCmdDstSubnetError(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{
+			name: "RequiredArg",
+			args: nil,
+		},
+		{
+			name: "InvalidDstSubnet",
+			args: []string{"invalid_ip_address"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := newADBCmd().cmd
+			err := cmd.RunE(cmd, tt.args)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestADBCmdOptsInitCliFlags(t *testing.T) {
+	t.Parallel()
+	var opts adbCmdOpts
+	cmd := &cobra.Command{}
+
+	opts.initCliFlags(cmd)
+	err := cmd.ParseFlags(strings.Split(
+		"--json -p 5555,5556 -f ip_file.jsonl -w 300 --exit-delay 10s --timeout 3s --getprop", " "))
+
+	require.NoError(t, err)
+	require.Equal(t, true, opts.json)
+	require.Equal(t, "5555,5556", opts.rawPortRanges)
+	require.Equal(t, "ip_file.jsonl", opts.ipFile)
+	require.Equal(t, 300, opts.workers)
+	require.Equal(t, 10*time.Second, opts.exitDelay)
+
+	require.Equal(t, 3*time.Second, opts.timeout)
+	require.True(t, opts.getprop)
+}
+
+func TestADBCmdOptsParseRawOptions(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		opts     adbCmdOpts
+		expected []*scan.PortRange
+	}{
+		{
+			name: "Ports",
+			opts: adbCmdOpts{
+				genericScanCmdOpts: genericScanCmdOpts{rawPortRanges: "5555,5556", workers: 300},
+			},
+			expected: []*scan.PortRange{{StartPort: 5555, EndPort: 5555}, {StartPort: 5556, EndPort: 5556}},
+		},
+		{
+			name: "DefaultPort",
+			opts: adbCmdOpts{
+				genericScanCmdOpts: genericScanCmdOpts{workers: 300},
+			},
+			expected: []*scan.PortRange{{StartPort: 5555, EndPort: 5555}},
+		},
+	}
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			err := tt.opts.parseRawOptions()
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, tt.opts.portRanges)
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package command
+
+import (
+	"crypto/tls"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/spf13/cobra"
+	"github.com/v-byte-cpu/sx/pkg/aggregator"
+)
+
+var errMTLSFlags = errors.New("--mtls-cert, --mtls-key and --mtls-ca flags are required")
+
+// certificates of the agent and the aggregator, both sides present certificates signed by the CA
+var (
+	mtlsCertFile string
+	mtlsKeyFile  string
+	mtlsCAFile   string
+)
+
+// agentConn is the connection to the aggregator in the agent mode
+var agentConn *agentWriter
+
+// agentWriter serializes writes of result writers of all scan types to the aggregator connection
+type agentWriter struct {
+	mu   sync.Mutex
+	conn io.WriteCloser
+}
+
+func (w *agentWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.conn.Write(p)
+}
+
+func initMTLSFlags(cmd *cobra.Command) {
+	cmd.PersistentFlags().StringVar(&mtlsCertFile, "mtls-cert", "",
+		"set certificate file in PEM format of the agent or the aggregator")
+	cmd.PersistentFlags().StringVar(&mtlsKeyFile, "mtls-key", "", "set private key file in PEM format of the certificate")
+	cmd.PersistentFlags().StringVar(&mtlsCAFile, "mtls-ca", "",
+		"set CA certificate file in PEM format, the other side must present the certificate signed by the CA")
+}
+
+func checkMTLSFlags() error {
+	if len(mtlsCertFile) == 0 || len(mtlsKeyFile) == 0 || len(mtlsCAFile) == 0 {
+		return errMTLSFlags
+	}
+	return nil
+}
+
+type agentCmdOpts struct {
+	// aggregatorAddr enables the agent mode, results are sent to the aggregator instead of resultWriter
+	aggregatorAddr string
+}
+
+func (o *agentCmdOpts) initCliFlags(cmd *cobra.Command) {
+	cmd.PersistentFlags().StringVar(&o.aggregatorAddr, "aggregator", "",
+		strings.Join([]string{"run as the agent that sends results to the aggregator at host:port over mutual TLS",
+			"instead of writing them, the --mtls-cert, --mtls-key and --mtls-ca flags are required"}, "\n"))
+	initMTLSFlags(cmd)
+}
+
+// openAgentConn connects to the aggregator if the agent mode is enabled
+func (o *agentCmdOpts) openAgentConn() (err error) {
+	if err = closeAgentConn(); err != nil || len(o.aggregatorAddr) == 0 {
+		return
+	}
+	if err = checkMTLSFlags(); err != nil {
+		return
+	}
+	config, err := aggregator.ClientTLSConfig(mtlsCertFile, mtlsKeyFile, mtlsCAFile)
+	if err != nil {
+		return
+	}
+	conn, err := tls.Dial("tcp", o.aggregatorAddr, config)
+	if err != nil {
+		return
+	}
+	agentConn = &agentWriter{conn: conn}
+	return
+}
+
+func closeAgentConn() (err error) {
+	if agentConn == nil {
+		return
+	}
+	err = agentConn.conn.Close()
+	agentConn = nil
+	return
+}
@@ -0,0 +1,113 @@
+package command
+
+import (
+	"context"
+	"crypto/tls"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/v-byte-cpu/sx/command/log"
+	"github.com/v-byte-cpu/sx/pkg/aggregator"
+)
+
+func newAggregatorCmd() *aggregatorCmd {
+	c := &aggregatorCmd{}
+
+	cmd := &cobra.Command{
+		Use: "aggregator [flags]",
+		Example: strings.Join([]string{
+			"aggregator --mtls-cert aggregator.pem --mtls-key aggregator-key.pem --mtls-ca ca.pem",
+			"aggregator --listen :9443 --json --output results.jsonl --mtls-cert aggregator.pem --mtls-key aggregator-key.pem --mtls-ca ca.pem",
+		}, "\n"),
+		Short: "Merge results of remote sx agents streamed over mutual TLS",
+		Long: strings.Join([]string{
+			"Merge results of remote sx agents streamed over mutual TLS until interrupted.",
+			"Agents are sx scans run with the --aggregator flag on remote network segments,",
+			"both sides present certificates signed by the same CA, the common name of the agent certificate",
+			"is the agent name. Results found by several agents are written once, tagged with the agent field."}, "\n"),
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) (err error) {
+			ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer cancel()
+
+			if err = checkMTLSFlags(); err != nil {
+				return
+			}
+			config, err := aggregator.ServerTLSConfig(mtlsCertFile, mtlsKeyFile, mtlsCAFile)
+			if err != nil {
+				return
+			}
+			w := resultWriter
+			if len(c.output) > 0 {
+				var f *os.File
+				if f, err = os.OpenFile(c.output, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644); err != nil {
+					return
+				}
+				defer f.Close()
+				w = f
+			}
+			logger, err := c.getLogger(w)
+			if err != nil {
+				return
+			}
+			listener, err := tls.Listen("tcp", c.listen, config)
+			if err != nil {
+				return
+			}
+			return serveAggregator(ctx, aggregator.NewServer(listener), logger)
+		},
+	}
+
+	cmd.Flags().StringVar(&c.listen, "listen", ":9443", "set address to accept agent connections on")
+	cmd.Flags().BoolVar(&c.json, "json", false, "enable JSON output")
+	cmd.Flags().StringVar(&c.output, "output", "", "append results to the file instead of stdout")
+
+	c.cmd = cmd
+	return c
+}
+
+type aggregatorCmd struct {
+	cmd    *cobra.Command
+	listen string
+	json   bool
+	output string
+}
+
+func (c *aggregatorCmd) getLogger(w io.Writer) (log.Logger, error) {
+	logger, err := newLogger(newResultWriter(w, aggregator.ScanType, c.json), aggregator.ScanType)
+	if err != nil {
+		return nil, err
+	}
+	return log.NewUniqueLogger(logger), nil
+}
+
+// serveAggregator writes results of agents until ctx is done
+func serveAggregator(ctx context.Context, srv *aggregator.Server, logger log.Logger) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for err := range srv.Errors() {
+			logger.Error(err)
+		}
+	}()
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- srv.Serve(ctx)
+	}()
+
+	logErr := logger.LogResults(ctx, srv.Results())
+	// the server is stopped if results can't be written
+	cancel()
+	err := <-serveErr
+	<-done
+	if logErr != nil {
+		return logErr
+	}
+	return err
+}
@@ -0,0 +1,91 @@
+package command
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/v-byte-cpu/sx/command/log"
+	"github.com/v-byte-cpu/sx/pkg/aggregator"
+	"github.com/v-byte-cpu/sx/pkg/scan/tcp"
+)
+
+// TestAggregatorCmdMTLSFlagsError is not parallel because it reads global mTLS flags
+func TestAggregatorCmdMTLSFlagsError(t *testing.T) {
+	cmd := newAggregatorCmd().cmd
+	require.ErrorIs(t, cmd.RunE(cmd, nil), errMTLSFlags)
+}
+
+// TestOpenAgentConn is not parallel because it changes the global agent connection
+func TestOpenAgentConn(t *testing.T) {
+	var opts agentCmdOpts
+	require.NoError(t, opts.openAgentConn())
+	require.Nil(t, agentConn)
+
+	opts.aggregatorAddr = "127.0.0.1:9443"
+	require.ErrorIs(t, opts.openAgentConn(), errMTLSFlags)
+	require.Nil(t, agentConn)
+	require.NoError(t, closeAgentConn())
+}
+
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestServeAggregator(t *testing.T) {
+	t.Parallel()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	var out syncBuffer
+	logger, err := log.NewLogger(log.NewStreamWriter(&out, &log.JSONEncoder{}), aggregator.ScanType,
+		log.FlushInterval(10*time.Millisecond))
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error, 1)
+	go func() {
+		done <- serveAggregator(ctx, aggregator.NewServer(listener), log.NewUniqueLogger(logger))
+	}()
+
+	// both agents find the same port, the second one finds another port after it
+	result := &tcp.ScanResult{ScanType: tcp.SYNScanType, IP: "192.168.0.3", Port: 22}
+	last := &tcp.ScanResult{ScanType: tcp.SYNScanType, IP: "192.168.0.3", Port: 80}
+	for _, results := range [][]*tcp.ScanResult{{result}, {result, last}} {
+		conn, err := net.Dial("tcp", listener.Addr().String())
+		require.NoError(t, err)
+		defer conn.Close()
+		w := log.NewAgentWriter(&agentWriter{conn: conn}, "tcpsyn")
+		for _, r := range results {
+			require.NoError(t, w.Write(context.Background(), r))
+		}
+		require.Eventually(t, func() bool {
+			return strings.Contains(out.String(), fmt.Sprintf(`"port":%d`, results[len(results)-1].Port))
+		}, 5*time.Second, 10*time.Millisecond)
+	}
+	cancel()
+	require.NoError(t, <-done)
+	require.Equal(t, 2, strings.Count(out.String(), "\n"))
+	require.Equal(t, 1, strings.Count(out.String(), `"port":22`))
+	require.Contains(t, out.String(), `"scan":"tcpsyn"`)
+}
@@ -0,0 +1,60 @@
+package command
+
+import (
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/spf13/cobra"
+	"github.com/v-byte-cpu/sx/command/log"
+	"github.com/v-byte-cpu/sx/pkg/alert"
+)
+
+var (
+	alertMu      sync.Mutex
+	alertEngines []*alert.Engine
+)
+
+// alertCmdOpts are options of alert rules evaluated on the live stream of scan results,
+// they are mostly useful in monitoring mode
+type alertCmdOpts struct {
+	alerts *alert.Config
+
+	rawAlertsFile string
+}
+
+func (o *alertCmdOpts) initCliFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&o.rawAlertsFile, "alerts", "",
+		"set YAML file with alert rules, actions of matched rules run for each logged result")
+}
+
+func (o *alertCmdOpts) parseRawOptions() (err error) {
+	if len(o.rawAlertsFile) > 0 {
+		o.alerts, err = alert.LoadFile(o.rawAlertsFile)
+	}
+	return
+}
+
+// withAlertLogger wraps the logger to evaluate alert rules on logged results if the rules are set
+func (o *alertCmdOpts) withAlertLogger(logger log.Logger) log.Logger {
+	if o.alerts == nil {
+		return logger
+	}
+	engine := alert.NewEngine(o.alerts, alert.WithErrorHandler(func(err error) {
+		fmt.Fprintln(os.Stderr, "Warning:", err)
+	}))
+	alertMu.Lock()
+	defer alertMu.Unlock()
+	alertEngines = append(alertEngines, engine)
+	return log.NewFilterLogger(logger, engine.Evaluate)
+}
+
+// closeAlerts waits for actions of alerts raised during the scan to finish
+func closeAlerts() {
+	alertMu.Lock()
+	defer alertMu.Unlock()
+	for _, engine := range alertEngines {
+		engine.Close()
+	}
+	alertEngines = nil
+}
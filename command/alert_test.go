@@ -0,0 +1,79 @@
+package command
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+	"github.com/v-byte-cpu/sx/command/log"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+	"github.com/v-byte-cpu/sx/pkg/scan/tcp"
+)
+
+func writeTestAlerts(t *testing.T, rules string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "alerts.yml")
+	require.NoError(t, os.WriteFile(path, []byte(rules), 0o600))
+	return path
+}
+
+func TestAlertCmdOptsInitCliFlags(t *testing.T) {
+	t.Parallel()
+	var opts alertCmdOpts
+	cmd := &cobra.Command{}
+	path := writeTestAlerts(t, "rules: [{ports: [23], actions: [{syslog: local}]}]")
+
+	opts.initCliFlags(cmd)
+	require.NoError(t, cmd.ParseFlags([]string{"--alerts", path}))
+	require.Equal(t, path, opts.rawAlertsFile)
+
+	require.NoError(t, opts.parseRawOptions())
+	require.NotNil(t, opts.alerts)
+	require.Len(t, opts.alerts.Rules, 1)
+}
+
+func TestAlertCmdOptsParseRawOptionsError(t *testing.T) {
+	t.Parallel()
+	opts := alertCmdOpts{rawAlertsFile: filepath.Join(t.TempDir(), "none.yml")}
+	require.Error(t, opts.parseRawOptions())
+
+	opts = alertCmdOpts{rawAlertsFile: writeTestAlerts(t, "rules: [{ports: [23]}]")}
+	require.Error(t, opts.parseRawOptions())
+}
+
+func TestAlertLogger(t *testing.T) {
+	t.Parallel()
+	out := filepath.Join(t.TempDir(), "alerts.log")
+	opts := alertCmdOpts{rawAlertsFile: writeTestAlerts(t, fmt.Sprintf(`
+rules:
+  - name: telnet
+    ports: [23]
+    actions:
+      - exec: [sh, -c, 'echo "$SX_ALERT_RULE $SX_ALERT_TARGET" >> "$0"', %q]
+`, out))}
+	require.NoError(t, opts.parseRawOptions())
+
+	var buf bytes.Buffer
+	plainLogger, err := log.NewLogger(log.NewStreamWriter(&buf, &log.PlainEncoder{}), "tcpsyn")
+	require.NoError(t, err)
+	logger := opts.withAlertLogger(plainLogger)
+
+	results := make(chan scan.Result, 2)
+	results <- &tcp.ScanResult{ScanType: tcp.SYNScanType, IP: "192.168.0.1", Port: 22}
+	results <- &tcp.ScanResult{ScanType: tcp.SYNScanType, IP: "192.168.0.1", Port: 23}
+	close(results)
+	require.NoError(t, logger.LogResults(context.Background(), results))
+	closeAlerts()
+
+	// all results are logged, the alert is raised only for the telnet port
+	require.Len(t, strings.Split(strings.TrimSpace(buf.String()), "\n"), 2)
+	data, err := os.ReadFile(out)
+	require.NoError(t, err)
+	require.Equal(t, "telnet 192.168.0.1:23\n", string(data))
+}
@@ -0,0 +1,88 @@
+package command
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/v-byte-cpu/sx/command/log"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+	"github.com/v-byte-cpu/sx/pkg/scan/amqp"
+)
+
+func newAMQPCmd() *amqpCmd {
+	c := &amqpCmd{}
+
+	cmd := &cobra.Command{
+		Use: "amqp [flags] [subnet]",
+		Example: strings.Join([]string{
+			"amqp -p 5672 192.168.0.1/24", "amqp -p 5672,5671 10.0.0.1",
+			"amqp --json -p 5672 10.0.0.1/16", "amqp --management -p 5672 10.0.0.1/24",
+			"amqp -f ip_ports_file.jsonl", "amqp -p 5672 -f ips_file.jsonl"}, "\n"),
+		Short: "Perform AMQP 0-9-1 broker scan",
+		Long: strings.Join([]string{
+			"Perform AMQP 0-9-1 broker scan.",
+			"The AMQP protocol header is sent to each target to read the Connection.Start method with the server",
+			"product, version and offered authentication mechanisms, e.g. of RabbitMQ brokers.",
+			"The --management option checks the RabbitMQ management API with the default guest/guest credentials."}, "\n"),
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer cancel()
+
+			if err = c.opts.parseRawOptions(); err != nil {
+				return
+			}
+			scanRange, err := c.opts.parseScanRange(args)
+			if err != nil {
+				return
+			}
+
+			var logger log.Logger
+			if logger, err = c.opts.getLogger(amqp.ScanType, resultWriter); err != nil {
+				return
+			}
+
+			engine := c.opts.newAMQPScanEngine(ctx)
+			return c.opts.runScanEngine(ctx, cmd, engine, scanRange, logger)
+		},
+	}
+
+	c.opts.initCliFlags(cmd)
+
+	c.cmd = cmd
+	return c
+}
+
+type amqpCmd struct {
+	cmd  *cobra.Command
+	opts amqpCmdOpts
+}
+
+type amqpCmdOpts struct {
+	genericScanCmdOpts
+	timeout        time.Duration
+	management     bool
+	managementPort uint16
+}
+
+func (o *amqpCmdOpts) initCliFlags(cmd *cobra.Command) {
+	o.genericScanCmdOpts.initCliFlags(cmd)
+	cmd.Flags().DurationVarP(&o.timeout, "timeout", "t", 2*time.Second, "set connect and data timeout")
+	cmd.Flags().BoolVar(&o.management, "management", false, "check RabbitMQ management API with guest/guest credentials")
+	cmd.Flags().Uint16Var(&o.managementPort, "management-port", 15672, "set RabbitMQ management API port")
+}
+
+func (o *amqpCmdOpts) newAMQPScanEngine(ctx context.Context) scan.EngineResulter {
+	opts := []amqp.ScannerOption{
+		amqp.WithDialTimeout(o.timeout),
+		amqp.WithDataTimeout(o.timeout),
+		amqp.WithManagementPort(o.managementPort),
+	}
+	if o.management {
+		opts = append(opts, amqp.WithManagement())
+	}
+	return o.newScanEngine(ctx, amqp.NewScanner(opts...))
+}
@@ -0,0 +1,55 @@
+package command
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAMQPCmdDstSubnetError(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{
+			name: "RequiredArg",
+			args: nil,
+		},
+		{
+			name: "InvalidDstSubnet",
+			args: []string{"invalid_ip_address"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := newAMQPCmd().cmd
+			err := cmd.RunE(cmd, tt.args)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestAMQPCmdOptsInitCliFlags(t *testing.T) {
+	t.Parallel()
+	var opts amqpCmdOpts
+	cmd := &cobra.Command{}
+
+	opts.initCliFlags(cmd)
+	err := cmd.ParseFlags(strings.Split(
+		"--json -p 5672-5673 -f ip_file.jsonl -w 300 --exit-delay 10s --timeout 3s --management --management-port 8080", " "))
+
+	require.NoError(t, err)
+	require.Equal(t, true, opts.json)
+	require.Equal(t, "5672-5673", opts.rawPortRanges)
+	require.Equal(t, "ip_file.jsonl", opts.ipFile)
+	require.Equal(t, 300, opts.workers)
+	require.Equal(t, 10*time.Second, opts.exitDelay)
+
+	require.Equal(t, 3*time.Second, opts.timeout)
+	require.True(t, opts.management)
+	require.Equal(t, uint16(8080), opts.managementPort)
+}
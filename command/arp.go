@@ -3,6 +3,7 @@ package command
 import (
 	"context"
 	"errors"
+	"net"
 	"os"
 	"os/signal"
 	"runtime"
@@ -16,13 +17,16 @@ import (
 	"github.com/v-byte-cpu/sx/pkg/scan/arp"
 )
 
+var errARPProbeSrcIP = errors.New("--probe and --srcip flags can not be used together")
+
 func newARPCmd() *arpCmd {
 	c := &arpCmd{}
 
 	cmd := &cobra.Command{
-		Use:     "arp [flags] subnet",
-		Example: strings.Join([]string{"arp 192.168.0.1/24", "arp 10.0.0.1"}, "\n"),
-		Short:   "Perform ARP scan",
+		Use: "arp [flags] subnet",
+		Example: strings.Join([]string{"arp 192.168.0.1/24", "arp 10.0.0.1",
+			"arp -i eth0 --srcip 10.99.0.254 10.99.0.0/24", "arp -i eth0 --probe 172.16.0.0/16"}, "\n"),
+		Short: "Perform ARP scan",
 		RunE: func(cmd *cobra.Command, args []string) (err error) {
 			ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
 			defer cancel()
@@ -57,6 +61,7 @@ func newARPCmd() *arpCmd {
 				withPacketBPFFilter(arp.BPFFilter),
 				withRateCount(c.opts.rateCount),
 				withRateWindow(c.opts.rateWindow),
+				withPacketSampling(c.opts.sampleCmdOpts),
 				withPacketEngineConfig(newEngineConfig(
 					withLogger(logger),
 					withScanRange(r),
@@ -80,15 +85,32 @@ type arpCmd struct {
 type arpCmdOpts struct {
 	packetScanCmdOpts
 	liveTimeout time.Duration
+	probe       bool
 }
 
 func (o *arpCmdOpts) initCliFlags(cmd *cobra.Command) {
 	o.packetScanCmdOpts.initCliFlags(cmd)
 	cmd.Flags().DurationVar(&o.liveTimeout, "live", 0, "enable live mode")
+	cmd.Flags().BoolVar(&o.probe, "probe", false,
+		strings.Join([]string{"send ARP probes with 0.0.0.0 sender IP address (RFC 5227)",
+			"hosts answer regardless of their subnet and do not cache the sender"}, "\n"))
+}
+
+func (o *arpCmdOpts) parseRawOptions() (err error) {
+	if err = o.packetScanCmdOpts.parseRawOptions(); err != nil {
+		return
+	}
+	if o.probe {
+		if o.srcIP != nil {
+			return errARPProbeSrcIP
+		}
+		o.srcIP = net.IPv4zero.To4()
+	}
+	return
 }
 
 func (o *arpCmdOpts) getLogger() (logger log.Logger, err error) {
-	if logger, err = o.packetScanCmdOpts.getLogger("arp", os.Stdout); err != nil {
+	if logger, err = o.packetScanCmdOpts.getLogger("arp", resultWriter); err != nil {
 		return
 	}
 	if o.liveTimeout > 0 {
@@ -102,11 +124,15 @@ func (o *arpCmdOpts) newARPScanMethod(ctx context.Context) *arp.ScanMethod {
 	if o.excludeIPs != nil {
 		reqgen = scan.NewFilterIPRequestGenerator(reqgen, o.excludeIPs)
 	}
+	reqgen = o.withScope(reqgen)
 	if o.liveTimeout > 0 {
 		reqgen = scan.NewLiveRequestGenerator(reqgen, o.liveTimeout)
 	}
+	if o.dstMAC != nil {
+		reqgen = arp.NewDstMACRequestGenerator(reqgen, o.dstMAC)
+	}
 	pktgen := scan.NewPacketMultiGenerator(arp.NewPacketFiller(), runtime.NumCPU())
-	psrc := scan.NewPacketSource(reqgen, pktgen)
+	psrc := scan.NewPacketSource(o.withHeartbeat(reqgen), pktgen)
 	results := scan.NewResultChan(ctx, 1000)
 	return arp.NewScanMethod(psrc, results)
 }
@@ -43,7 +43,7 @@ func TestARPCmdOptsInitCliFlags(t *testing.T) {
 	err := cmd.ParseFlags(strings.Split(
 		strings.Join([]string{
 			"--json -i eth0 --srcip 192.168.0.1 --srcmac 00:11:22:33:44:55 -r 500/7s --exit-delay 10s",
-			"--live 5s",
+			"--live 5s --probe",
 		}, " "), " "))
 
 	require.NoError(t, err)
@@ -54,4 +54,25 @@ func TestARPCmdOptsInitCliFlags(t *testing.T) {
 	require.Equal(t, "500/7s", opts.rawRateLimit)
 	require.Equal(t, 10*time.Second, opts.exitDelay)
 	require.Equal(t, 5*time.Second, opts.liveTimeout)
+	require.Equal(t, true, opts.probe)
+}
+
+func TestARPCmdOptsParseRawOptionsProbe(t *testing.T) {
+	t.Parallel()
+	opts := arpCmdOpts{probe: true}
+
+	err := opts.parseRawOptions()
+
+	require.NoError(t, err)
+	require.Equal(t, net.IP{0, 0, 0, 0}, opts.srcIP)
+}
+
+func TestARPCmdOptsParseRawOptionsProbeWithSrcIP(t *testing.T) {
+	t.Parallel()
+	opts := arpCmdOpts{probe: true}
+	opts.srcIP = net.IPv4(10, 99, 0, 254)
+
+	err := opts.parseRawOptions()
+
+	require.ErrorIs(t, err, errARPProbeSrcIP)
 }
@@ -0,0 +1,93 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/v-byte-cpu/sx/command/log"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+	"github.com/v-byte-cpu/sx/pkg/scan/bacnet"
+)
+
+func newBACnetCmd() *bacnetCmd {
+	c := &bacnetCmd{}
+
+	cmd := &cobra.Command{
+		Use: "bacnet [flags] [subnet]",
+		Example: strings.Join([]string{
+			"bacnet -p 47808 192.168.0.1/24", "bacnet --timeout 500ms -p 47808 10.0.0.1/16",
+			"bacnet --retries 2 -p 47808 10.0.0.1/16",
+			"bacnet -f ip_ports_file.jsonl", "bacnet -p 47808 -f ips_file.jsonl"}, "\n"),
+		Short: "Perform BACnet/IP device enumeration scan",
+		Long: strings.Join([]string{
+			"Perform BACnet/IP device enumeration scan.",
+			"ReadProperty requests of the device object are sent to each target over UDP,",
+			"devices are reported with their instance number, vendor, model and firmware revision.",
+			"Only properties are read, nothing is written to devices."}, "\n"),
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer cancel()
+
+			if err = c.opts.parseRawOptions(); err != nil {
+				return
+			}
+			scanRange, err := c.opts.parseScanRange(args)
+			if err != nil {
+				return
+			}
+
+			var logger log.Logger
+			if logger, err = c.opts.getLogger(bacnet.ScanType, resultWriter); err != nil {
+				return
+			}
+
+			engine := c.opts.newBACnetScanEngine(ctx)
+			return c.opts.runScanEngine(ctx, cmd, engine, scanRange, logger)
+		},
+	}
+
+	c.opts.initCliFlags(cmd)
+
+	c.cmd = cmd
+	return c
+}
+
+type bacnetCmd struct {
+	cmd  *cobra.Command
+	opts bacnetCmdOpts
+}
+
+type bacnetCmdOpts struct {
+	genericScanCmdOpts
+	timeout time.Duration
+	retries int
+}
+
+func (o *bacnetCmdOpts) initCliFlags(cmd *cobra.Command) {
+	o.genericScanCmdOpts.initCliFlags(cmd)
+	cmd.Flags().DurationVarP(&o.timeout, "timeout", "t", 1*time.Second, "set time to wait for a response to each request")
+	cmd.Flags().IntVar(&o.retries, "retries", 1, "set number of additional requests of each property if there is no response")
+}
+
+func (o *bacnetCmdOpts) parseRawOptions() (err error) {
+	if err = o.genericScanCmdOpts.parseRawOptions(); err != nil {
+		return
+	}
+	if o.retries < 0 {
+		return errors.New("invalid retries: non-negative number required")
+	}
+	return
+}
+
+func (o *bacnetCmdOpts) newBACnetScanEngine(ctx context.Context) scan.EngineResulter {
+	scanner := bacnet.NewScanner(
+		bacnet.WithDataTimeout(o.timeout),
+		bacnet.WithRetries(o.retries),
+	)
+	return o.newScanEngine(ctx, scanner)
+}
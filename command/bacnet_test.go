@@ -0,0 +1,85 @@
+package command
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+func TestBACnetCmdDstSubnetError(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{
+			name: "RequiredArg",
+			args: nil,
+		},
+		{
+			name: "InvalidDstSubnet",
+			args: []string{"invalid_ip_address"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := newBACnetCmd().cmd
+			err := cmd.RunE(cmd, tt.args)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestBACnetCmdOptsInitCliFlags(t *testing.T) {
+	t.Parallel()
+	var opts bacnetCmdOpts
+	cmd := &cobra.Command{}
+
+	opts.initCliFlags(cmd)
+	err := cmd.ParseFlags(strings.Split(
+		"--json -p 47808 -f ip_file.jsonl -w 300 --exit-delay 10s --timeout 3s --retries 3", " "))
+
+	require.NoError(t, err)
+	require.Equal(t, true, opts.json)
+	require.Equal(t, "47808", opts.rawPortRanges)
+	require.Equal(t, "ip_file.jsonl", opts.ipFile)
+	require.Equal(t, 300, opts.workers)
+	require.Equal(t, 10*time.Second, opts.exitDelay)
+
+	require.Equal(t, 3*time.Second, opts.timeout)
+	require.Equal(t, 3, opts.retries)
+}
+
+func TestBACnetCmdOptsParseRawOptions(t *testing.T) {
+	t.Parallel()
+	opts := bacnetCmdOpts{
+		genericScanCmdOpts: genericScanCmdOpts{
+			rawPortRanges: "47808",
+			workers:       300,
+		},
+	}
+
+	err := opts.parseRawOptions()
+
+	require.NoError(t, err)
+	require.Equal(t, []*scan.PortRange{{StartPort: 47808, EndPort: 47808}}, opts.portRanges)
+}
+
+func TestBACnetCmdOptsParseRawOptionsError(t *testing.T) {
+	t.Parallel()
+	opts := bacnetCmdOpts{
+		genericScanCmdOpts: genericScanCmdOpts{
+			rawPortRanges: "47808",
+			workers:       300,
+		},
+		retries: -1,
+	}
+
+	err := opts.parseRawOptions()
+
+	require.Error(t, err)
+}
@@ -0,0 +1,335 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/v-byte-cpu/sx/command/log"
+	"github.com/v-byte-cpu/sx/pkg/calibrate"
+	"github.com/v-byte-cpu/sx/pkg/ip"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+// tuningOff disables the tuning profile
+const tuningOff = "off"
+
+var (
+	errRingBlocks      = errors.New("invalid ring blocks: non-negative number required")
+	errCalibrateDstMAC = errors.New("calibrate requires the --dstmac flag with the MAC address of the reflector or the gateway to it")
+	errCalibrateLoss   = errors.New("invalid max loss: a fraction between 0 and 1 required")
+	errReflectorIP     = errors.New("invalid reflector IP: IPv4 address required")
+)
+
+type tuningCmdOpts struct {
+	// tuningFile is the local tuning profile written by the calibrate command,
+	// its settings are defaults of flags of packet scans
+	tuningFile string
+	// ringBlocks is the number of blocks of the receive ring of packet sockets, the default ring is used if it is zero
+	ringBlocks int
+}
+
+func (o *tuningCmdOpts) initCliFlags(cmd *cobra.Command) {
+	cmd.PersistentFlags().StringVar(&o.tuningFile, "tuning", defaultTuningFile(),
+		strings.Join([]string{"set tuning profile written by the calibrate command, its rate and ring blocks are defaults",
+			"of packet scans on the calibrated interface that don't set them explicitly, off disables it"}, "\n"))
+	cmd.PersistentFlags().IntVar(&o.ringBlocks, "ring-blocks", 0,
+		"set number of 512 KiB blocks of the receive ring of packet scans, 0 means the default of 128 blocks")
+}
+
+// defaultTuningFile returns the tuning profile in the user configuration directory
+func defaultTuningFile() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "sx", "tuning.yaml")
+}
+
+// tuningEnabled is true if the tuning profile is neither disabled nor the default one that can't be found
+func (o *tuningCmdOpts) tuningEnabled() bool {
+	return len(o.tuningFile) > 0 && o.tuningFile != tuningOff
+}
+
+// tuningProfile is the loaded tuning profile, it is applied to packet scans on its interface
+var tuningProfile *calibrate.Profile
+
+// loadTuningProfile reads the tuning profile, the missing profile is skipped, the calibrate command writes it
+func (o *tuningCmdOpts) loadTuningProfile() error {
+	tuningProfile = nil
+	if o.ringBlocks < 0 {
+		return errRingBlocks
+	}
+	if !o.tuningEnabled() {
+		return nil
+	}
+	profile, err := calibrate.LoadProfile(o.tuningFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("tuning profile: %w", err)
+	}
+	tuningProfile = profile
+	return nil
+}
+
+// applyTuningProfile sets the rate and ring blocks of the packet scan that are not set explicitly
+// or by the safe profile to settings of the tuning profile measured on the interface of the scan
+func (o *packetScanCmdOpts) applyTuningProfile(iface *net.Interface) (err error) {
+	profile := tuningProfile
+	if profile == nil || profile.Interface != iface.Name {
+		return
+	}
+	if len(o.rawRateLimit) == 0 && len(profile.Rate) > 0 {
+		if o.rateCount, o.rateWindow, err = parseRateLimit(profile.Rate); err != nil {
+			return fmt.Errorf("tuning profile: --rate: %w", err)
+		}
+		o.rawRateLimit = profile.Rate
+		recordManifestFlag("rate", profile.Rate)
+	}
+	if rootOpts.ringBlocks == 0 && profile.RingBlocks > 0 {
+		rootOpts.ringBlocks = profile.RingBlocks
+		recordManifestFlag("ring-blocks", strconv.Itoa(profile.RingBlocks))
+	}
+	return
+}
+
+func newCalibrateCmd() *calibrateCmd {
+	c := &calibrateCmd{}
+
+	cmd := &cobra.Command{
+		Use: "calibrate [flags] [reflector-ip]",
+		Example: strings.Join([]string{
+			"calibrate",
+			"calibrate --max-rate 200000 --step-duration 2s",
+			"calibrate -i eth0 --dstmac 00:11:22:33:44:55 192.168.0.1"}, "\n"),
+		Short: "Measure the maximum sustainable packet rate and save suggested scan settings",
+		Long: strings.Join([]string{
+			"Measure the maximum sustainable send/receive rate of the interface with ICMP echo probes",
+			"sent to the loopback interface or to the reflector IP at doubling rates until probes are lost.",
+			"Suggested rate, workers and ring blocks are saved to the tuning profile (see the --tuning flag),",
+			"rate and ring blocks are defaults of subsequent packet scans on the interface, flags set explicitly override them."}, "\n"),
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer cancel()
+
+			if err = c.opts.parseRawOptions(); err != nil {
+				return
+			}
+			probe, err := c.opts.parseProbe(args)
+			if err != nil {
+				return
+			}
+			logger, err := newLogger(newResultWriter(resultWriter, calibrate.ScanType, c.opts.json), calibrate.ScanType)
+			if err != nil {
+				return
+			}
+			steps, err := c.opts.calibrate(ctx, probe, logger)
+			if err != nil {
+				return
+			}
+			return c.opts.writeProfile(ctx, probe, steps, logger)
+		},
+	}
+
+	c.opts.initCliFlags(cmd)
+
+	c.cmd = cmd
+	return c
+}
+
+type calibrateCmd struct {
+	cmd  *cobra.Command
+	opts calibrateCmdOpts
+}
+
+type calibrateCmdOpts struct {
+	json         bool
+	noSave       bool
+	startRate    int
+	maxRate      int
+	stepDuration time.Duration
+	drainTimeout time.Duration
+	maxLoss      float64
+	iface        *net.Interface
+	dstMAC       net.HardwareAddr
+
+	rawInterface string
+	rawDstMAC    string
+}
+
+func (o *calibrateCmdOpts) initCliFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&o.json, "json", false, "enable JSON output")
+	cmd.Flags().BoolVar(&o.noSave, "no-save", false, "print suggested settings without saving them to the tuning profile")
+	cmd.Flags().StringVarP(&o.rawInterface, "iface", "i", "",
+		"set interface to send/receive packets, the loopback interface is calibrated without the reflector IP")
+	cmd.Flags().StringVar(&o.rawDstMAC, "dstmac", "", "set MAC address of the reflector or the gateway to it")
+	cmd.Flags().IntVar(&o.startRate, "start-rate", calibrate.DefaultStartRate, "set rate of the first step in packets per second")
+	cmd.Flags().IntVar(&o.maxRate, "max-rate", calibrate.DefaultMaxRate, "set maximum rate to measure in packets per second")
+	cmd.Flags().DurationVar(&o.stepDuration, "step-duration", calibrate.DefaultStepDuration, "set time of sending probes at each rate")
+	cmd.Flags().DurationVar(&o.drainTimeout, "drain-timeout", calibrate.DefaultDrainTimeout,
+		"set time to wait for last replies of each rate")
+	cmd.Flags().Float64Var(&o.maxLoss, "max-loss", calibrate.DefaultMaxLoss, "set maximum fraction of lost probes of sustainable rates")
+}
+
+func (o *calibrateCmdOpts) parseRawOptions() (err error) {
+	if o.maxLoss < 0 || o.maxLoss >= 1 {
+		return errCalibrateLoss
+	}
+	if len(o.rawInterface) > 0 {
+		if o.iface, err = net.InterfaceByName(o.rawInterface); err != nil {
+			return
+		}
+	}
+	if len(o.rawDstMAC) > 0 {
+		if o.dstMAC, err = net.ParseMAC(o.rawDstMAC); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// calibrate measures rates of the interface of the probe
+func (o *calibrateCmdOpts) calibrate(ctx context.Context, probe *calibrateProbe, logger log.Logger) ([]*calibrate.Step, error) {
+	ps, err := newPacketSource(probe.iface.Name, probe.vpnMode)
+	if err != nil {
+		return nil, err
+	}
+	defer ps.Close()
+	prober := calibrate.NewICMPProber(probe.srcMAC, probe.dstMAC, probe.srcIP, probe.dstIP, probe.vpnMode)
+	if err = ps.SetBPFFilter(prober.BPFFilter()); err != nil {
+		return nil, fmt.Errorf("BPFFilter: %w", err)
+	}
+	calibrator, err := calibrate.NewCalibrator(ps, prober,
+		calibrate.WithStartRate(o.startRate), calibrate.WithMaxRate(o.maxRate),
+		calibrate.WithStepDuration(o.stepDuration), calibrate.WithDrainTimeout(o.drainTimeout),
+		calibrate.WithMaxLoss(o.maxLoss), calibrate.WithErrorLogger(logger.Error),
+		calibrate.WithDrops(func() (uint64, error) {
+			stats, err := ps.Stats()
+			if err != nil {
+				return 0, err
+			}
+			return stats.Dropped, nil
+		}))
+	if err != nil {
+		return nil, err
+	}
+	return calibrator.Calibrate(ctx)
+}
+
+// writeProfile writes measured steps and suggested settings, the settings are saved to the tuning profile
+func (o *calibrateCmdOpts) writeProfile(ctx context.Context, probe *calibrateProbe,
+	steps []*calibrate.Step, logger log.Logger) error {
+	profile, err := calibrate.Suggest(steps, defaultWorkerCount, maxWorkers())
+	results := make(chan scan.Result, len(steps)+1)
+	for _, step := range steps {
+		results <- step
+	}
+	if err == nil {
+		profile.Interface = probe.iface.Name
+		profile.Reflector = probe.dstIP.String()
+		profile.MeasuredAt = time.Now().UTC()
+		results <- profile
+	}
+	close(results)
+	if logErr := logger.LogResults(ctx, results); logErr != nil {
+		return logErr
+	}
+	if err != nil || o.noSave || !rootOpts.tuningEnabled() {
+		return err
+	}
+	return profile.Save(rootOpts.tuningFile)
+}
+
+// calibrateProbe are addresses of probes of the calibrated interface
+type calibrateProbe struct {
+	iface   *net.Interface
+	srcIP   net.IP
+	dstIP   net.IP
+	srcMAC  net.HardwareAddr
+	dstMAC  net.HardwareAddr
+	vpnMode bool
+}
+
+// parseProbe returns addresses of the loopback interface without arguments, the interface
+// of the reflector IP argument is the interface flag, the interface of its subnet or the default one
+func (o *calibrateCmdOpts) parseProbe(args []string) (probe *calibrateProbe, err error) {
+	if len(args) == 0 {
+		iface := o.iface
+		if iface == nil {
+			if iface, err = loopbackInterface(); err != nil {
+				return
+			}
+		}
+		// the loopback interface has Ethernet headers with zero addresses
+		loopback := net.IPv4(127, 0, 0, 1)
+		zeroMAC := make(net.HardwareAddr, 6)
+		return &calibrateProbe{iface: iface, srcIP: loopback, dstIP: loopback, srcMAC: zeroMAC, dstMAC: zeroMAC}, nil
+	}
+
+	dstIP := net.ParseIP(args[0]).To4()
+	if dstIP == nil {
+		return nil, errReflectorIP
+	}
+	probe = &calibrateProbe{iface: o.iface, dstIP: dstIP}
+	if probe.iface != nil {
+		probe.srcIP, err = ip.GetInterfaceIP(probe.iface)
+	} else if probe.iface, probe.srcIP, err = ip.GetLocalSubnetInterface(
+		&net.IPNet{IP: dstIP, Mask: net.CIDRMask(32, 32)}); err == nil && probe.iface == nil {
+		// the reflector is behind the gateway
+		probe.iface, probe.srcIP, err = ip.GetDefaultInterface()
+	}
+	if err != nil {
+		return
+	}
+	if probe.iface == nil || probe.srcIP.To4() == nil {
+		return nil, errSrcInterface
+	}
+	probe.srcIP = probe.srcIP.To4()
+	probe.srcMAC = probe.iface.HardwareAddr
+	if probe.srcMAC == nil {
+		probe.vpnMode = true
+		return
+	}
+	if o.dstMAC == nil {
+		return nil, errCalibrateDstMAC
+	}
+	probe.dstMAC = o.dstMAC
+	return
+}
+
+// loopbackInterface returns the first interface with the loopback flag
+func loopbackInterface() (*net.Interface, error) {
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return nil, err
+	}
+	for i := range ifaces {
+		if ifaces[i].Flags&net.FlagLoopback != 0 {
+			return &ifaces[i], nil
+		}
+	}
+	return nil, errSrcInterface
+}
+
+// maxWorkers returns the number of connections allowed by the open file limit, zero if it is unknown
+func maxWorkers() int {
+	limit, err := scan.OpenFileLimit()
+	if err != nil || limit <= reservedFileCount {
+		return 0
+	}
+	if limit-reservedFileCount > uint64(1<<20) {
+		return 1 << 20
+	}
+	return int(limit - reservedFileCount)
+}
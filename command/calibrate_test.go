@@ -0,0 +1,133 @@
+package command
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/v-byte-cpu/sx/pkg/calibrate"
+)
+
+func saveTuningProfile(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "sx", "tuning.yaml")
+	profile := &calibrate.Profile{Interface: "lo", Reflector: "127.0.0.1", MaxRate: 100000,
+		Rate: "80000/s", Workers: 400, RingBlocks: 256}
+	require.NoError(t, profile.Save(path))
+	return path
+}
+
+// setTuningOpts replaces options of the running root command with the tuning profile and ring blocks for the test
+func setTuningOpts(t *testing.T, path string, blocks int) *rootCmdOpts {
+	t.Helper()
+	opts := &rootCmdOpts{tuningCmdOpts: tuningCmdOpts{tuningFile: path, ringBlocks: blocks}}
+	setRootOpts(t, opts)
+	t.Cleanup(func() {
+		tuningProfile = nil
+	})
+	return opts
+}
+
+func TestApplyTuningProfile(t *testing.T) {
+	root := setTuningOpts(t, saveTuningProfile(t), 0)
+	require.NoError(t, root.loadTuningProfile())
+
+	var opts packetScanCmdOpts
+	require.NoError(t, opts.applyTuningProfile(&net.Interface{Name: "lo"}))
+	require.Equal(t, "80000/s", opts.rawRateLimit)
+	require.Equal(t, 80000, opts.rateCount)
+	require.Equal(t, time.Second, opts.rateWindow)
+	require.Equal(t, 256, root.ringBlocks)
+}
+
+func TestApplyTuningProfileExplicit(t *testing.T) {
+	root := setTuningOpts(t, saveTuningProfile(t), 64)
+	require.NoError(t, root.loadTuningProfile())
+
+	// the rate set explicitly or by the safe profile is kept
+	opts := packetScanCmdOpts{rawRateLimit: "100/s"}
+	require.NoError(t, opts.parseRawOptions())
+	require.NoError(t, opts.applyTuningProfile(&net.Interface{Name: "lo"}))
+	require.Equal(t, 100, opts.rateCount)
+	require.Equal(t, 64, root.ringBlocks)
+}
+
+func TestApplyTuningProfileOtherInterface(t *testing.T) {
+	root := setTuningOpts(t, saveTuningProfile(t), 0)
+	require.NoError(t, root.loadTuningProfile())
+
+	var opts packetScanCmdOpts
+	require.NoError(t, opts.applyTuningProfile(&net.Interface{Name: "eth0"}))
+	require.Empty(t, opts.rawRateLimit)
+	require.Zero(t, opts.rateCount)
+	require.Zero(t, root.ringBlocks)
+}
+
+func TestLoadTuningProfileSkipped(t *testing.T) {
+	tests := []struct {
+		name string
+		path string
+	}{
+		{name: "Missing", path: filepath.Join(t.TempDir(), "tuning.yaml")},
+		{name: "Off", path: tuningOff},
+		{name: "Empty"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := setTuningOpts(t, tt.path, 0)
+
+			require.NoError(t, root.loadTuningProfile())
+			require.Nil(t, tuningProfile)
+		})
+	}
+}
+
+func TestLoadTuningProfileInvalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tuning.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("workers: many\n"), 0o644))
+	root := setTuningOpts(t, path, 0)
+
+	err := root.loadTuningProfile()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "tuning profile")
+}
+
+func TestLoadTuningProfileInvalidRingBlocks(t *testing.T) {
+	root := setTuningOpts(t, tuningOff, -1)
+
+	require.ErrorIs(t, root.loadTuningProfile(), errRingBlocks)
+}
+
+func TestCalibrateCmdOptsParseProbeLoopback(t *testing.T) {
+	t.Parallel()
+
+	var opts calibrateCmdOpts
+	probe, err := opts.parseProbe(nil)
+	require.NoError(t, err)
+	require.NotZero(t, probe.iface.Flags&net.FlagLoopback)
+	require.Equal(t, "127.0.0.1", probe.srcIP.String())
+	require.Equal(t, "127.0.0.1", probe.dstIP.String())
+	require.Equal(t, net.HardwareAddr{0, 0, 0, 0, 0, 0}, probe.dstMAC)
+	require.False(t, probe.vpnMode)
+}
+
+func TestCalibrateCmdOptsParseProbeInvalidReflector(t *testing.T) {
+	t.Parallel()
+
+	var opts calibrateCmdOpts
+	_, err := opts.parseProbe([]string{"::1"})
+	require.ErrorIs(t, err, errReflectorIP)
+	_, err = opts.parseProbe([]string{"reflector"})
+	require.ErrorIs(t, err, errReflectorIP)
+}
+
+func TestCalibrateCmdOptsParseRawOptionsInvalidLoss(t *testing.T) {
+	t.Parallel()
+
+	opts := calibrateCmdOpts{maxLoss: 1}
+	require.ErrorIs(t, opts.parseRawOptions(), errCalibrateLoss)
+}
@@ -0,0 +1,63 @@
+package command
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+
+	"github.com/v-byte-cpu/sx/pkg/packet/afpacket"
+)
+
+var (
+	captureStatsMu sync.Mutex
+	// captureStats are kernel capture counters of packet scans by the network interface
+	captureStats map[string]*afpacket.Stats
+)
+
+// recordCaptureStats adds kernel counters of the packet source at the end of the scan to the interface stats
+func recordCaptureStats(iface string, ps packetSource) {
+	stats, err := ps.Stats()
+	if err != nil {
+		return
+	}
+	captureStatsMu.Lock()
+	defer captureStatsMu.Unlock()
+	if captureStats == nil {
+		captureStats = make(map[string]*afpacket.Stats)
+	}
+	total, ok := captureStats[iface]
+	if !ok {
+		total = &afpacket.Stats{}
+		captureStats[iface] = total
+	}
+	total.Received += stats.Received
+	total.Dropped += stats.Dropped
+	total.QueueFreezes += stats.QueueFreezes
+}
+
+// captureInterfaces returns names of interfaces with recorded capture stats in sorted order
+func captureInterfaces() []string {
+	names := make([]string, 0, len(captureStats))
+	for name := range captureStats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// writeCaptureStats writes kernel capture counters of each interface packet scans ran on
+func writeCaptureStats(w io.Writer) {
+	captureStatsMu.Lock()
+	defer captureStatsMu.Unlock()
+	var dropped bool
+	for _, name := range captureInterfaces() {
+		stats := captureStats[name]
+		fmt.Fprintf(w, "capture %s: %d packets received, %d dropped, %d ring overruns\n",
+			name, stats.Received, stats.Dropped, stats.QueueFreezes)
+		dropped = dropped || stats.Dropped > 0 || stats.QueueFreezes > 0
+	}
+	if dropped {
+		fmt.Fprintln(w, "Warning: the kernel dropped captured packets, responses may be missing, lower the --rate to avoid drops")
+	}
+}
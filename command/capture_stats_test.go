@@ -0,0 +1,79 @@
+package command
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+	"github.com/v-byte-cpu/sx/pkg/packet/afpacket"
+)
+
+// setCaptureState replaces the process-wide capture stats for the test
+func setCaptureState(t *testing.T) {
+	t.Helper()
+	prev := captureStats
+	captureStats = nil
+	t.Cleanup(func() {
+		captureStats = prev
+	})
+}
+
+type fakeCaptureSource struct {
+	packetSource
+	stats *afpacket.Stats
+	err   error
+}
+
+func (s *fakeCaptureSource) Stats() (*afpacket.Stats, error) {
+	return s.stats, s.err
+}
+
+func TestWriteCaptureStats(t *testing.T) {
+	setCaptureState(t)
+	recordCaptureStats("eth1", &fakeCaptureSource{stats: &afpacket.Stats{Received: 10}})
+	recordCaptureStats("eth0", &fakeCaptureSource{stats: &afpacket.Stats{Received: 100}})
+	recordCaptureStats("eth0", &fakeCaptureSource{stats: &afpacket.Stats{Received: 50, Dropped: 3, QueueFreezes: 1}})
+	recordCaptureStats("eth2", &fakeCaptureSource{err: errors.New("failed")})
+
+	var out strings.Builder
+	writeCaptureStats(&out)
+	require.Equal(t, strings.Join([]string{
+		"capture eth0: 150 packets received, 3 dropped, 1 ring overruns",
+		"capture eth1: 10 packets received, 0 dropped, 0 ring overruns",
+		"Warning: the kernel dropped captured packets, responses may be missing, lower the --rate to avoid drops",
+		""}, "\n"), out.String())
+}
+
+func TestWriteCaptureStatsNoDrops(t *testing.T) {
+	setCaptureState(t)
+	recordCaptureStats("eth0", &fakeCaptureSource{stats: &afpacket.Stats{Received: 100}})
+
+	var out strings.Builder
+	writeCaptureStats(&out)
+	require.Equal(t, "capture eth0: 100 packets received, 0 dropped, 0 ring overruns\n", out.String())
+}
+
+func TestWriteCaptureStatsEmpty(t *testing.T) {
+	setCaptureState(t)
+	var out strings.Builder
+	writeCaptureStats(&out)
+	require.Empty(t, out.String())
+}
+
+func TestWriteManifestCaptureStats(t *testing.T) {
+	setCaptureState(t)
+	path := filepath.Join(t.TempDir(), "manifest.json")
+	setManifestState(t, []string{"tcp", "-p", "22", "10.0.0.1"})
+	recordCaptureStats("eth0", &fakeCaptureSource{stats: &afpacket.Stats{Received: 100, Dropped: 3, QueueFreezes: 1}})
+
+	opts := manifestCmdOpts{path: path}
+	require.NoError(t, opts.beginManifest(&cobra.Command{Use: "tcp"}))
+	require.NoError(t, writeManifest(nil))
+
+	m, err := readManifest(path)
+	require.NoError(t, err)
+	require.Equal(t, []*manifestCapture{{Interface: "eth0", Received: 100, Dropped: 3, RingOverruns: 1}}, m.Capture)
+}
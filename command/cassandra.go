@@ -0,0 +1,79 @@
+package command
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/v-byte-cpu/sx/command/log"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+	"github.com/v-byte-cpu/sx/pkg/scan/cassandra"
+)
+
+func newCassandraCmd() *cassandraCmd {
+	c := &cassandraCmd{}
+
+	cmd := &cobra.Command{
+		Use: "cassandra [flags] [subnet]",
+		Example: strings.Join([]string{
+			"cassandra -p 9042 192.168.0.1/24", "cassandra -p 9042,9142 10.0.0.1",
+			"cassandra --json -p 9042 10.0.0.1/16",
+			"cassandra -f ip_ports_file.jsonl", "cassandra -p 9042 -f ips_file.jsonl"}, "\n"),
+		Short: "Perform Cassandra CQL authentication scan",
+		Long: strings.Join([]string{
+			"Perform Cassandra CQL authentication scan.",
+			"The CQL OPTIONS and STARTUP messages are sent to each target to find out the native protocol version,",
+			"supported CQL versions and whether the server requires authentication.",
+			"The cluster name and the release version are queried from servers that don't require it."}, "\n"),
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer cancel()
+
+			if err = c.opts.parseRawOptions(); err != nil {
+				return
+			}
+			scanRange, err := c.opts.parseScanRange(args)
+			if err != nil {
+				return
+			}
+
+			var logger log.Logger
+			if logger, err = c.opts.getLogger(cassandra.ScanType, resultWriter); err != nil {
+				return
+			}
+
+			engine := c.opts.newCassandraScanEngine(ctx)
+			return c.opts.runScanEngine(ctx, cmd, engine, scanRange, logger)
+		},
+	}
+
+	c.opts.initCliFlags(cmd)
+
+	c.cmd = cmd
+	return c
+}
+
+type cassandraCmd struct {
+	cmd  *cobra.Command
+	opts cassandraCmdOpts
+}
+
+type cassandraCmdOpts struct {
+	genericScanCmdOpts
+	timeout time.Duration
+}
+
+func (o *cassandraCmdOpts) initCliFlags(cmd *cobra.Command) {
+	o.genericScanCmdOpts.initCliFlags(cmd)
+	cmd.Flags().DurationVarP(&o.timeout, "timeout", "t", 2*time.Second, "set connect and data timeout")
+}
+
+func (o *cassandraCmdOpts) newCassandraScanEngine(ctx context.Context) scan.EngineResulter {
+	return o.newScanEngine(ctx, cassandra.NewScanner(
+		cassandra.WithDialTimeout(o.timeout),
+		cassandra.WithDataTimeout(o.timeout),
+	))
+}
@@ -0,0 +1,94 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/v-byte-cpu/sx/command/log"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+	"github.com/v-byte-cpu/sx/pkg/scan/coap"
+)
+
+const defaultCoAPPort = 5683
+
+func newCoAPCmd() *coapCmd {
+	c := &coapCmd{}
+
+	cmd := &cobra.Command{
+		Use: "coap [flags] [subnet]",
+		Example: strings.Join([]string{
+			"coap 192.168.0.1/24", "coap --timeout 500ms -p 5683,5685 10.0.0.1/16",
+			"coap -f ip_ports_file.jsonl", "coap -p 5683 -f ips_file.jsonl"}, "\n"),
+		Short: "Perform CoAP resource discovery scan",
+		Long: strings.Join([]string{
+			"Perform CoAP resource discovery scan.",
+			"The GET /.well-known/core request is sent to each target over UDP, port 5683 by default,",
+			"servers are reported with the response code and resources advertised in the CoRE link format."}, "\n"),
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer cancel()
+
+			if err = c.opts.parseRawOptions(); err != nil {
+				return
+			}
+			scanRange, err := c.opts.parseScanRange(args)
+			if err != nil {
+				return
+			}
+
+			var logger log.Logger
+			if logger, err = c.opts.getLogger(coap.ScanType, resultWriter); err != nil {
+				return
+			}
+
+			engine := c.opts.newCoAPScanEngine(ctx)
+			return c.opts.runScanEngine(ctx, cmd, engine, scanRange, logger)
+		},
+	}
+
+	c.opts.initCliFlags(cmd)
+
+	c.cmd = cmd
+	return c
+}
+
+type coapCmd struct {
+	cmd  *cobra.Command
+	opts coapCmdOpts
+}
+
+type coapCmdOpts struct {
+	genericScanCmdOpts
+	timeout   time.Duration
+	maxBlocks int
+}
+
+func (o *coapCmdOpts) initCliFlags(cmd *cobra.Command) {
+	o.genericScanCmdOpts.initCliFlags(cmd)
+	cmd.Flags().DurationVarP(&o.timeout, "timeout", "t", 2*time.Second, "set time to wait for each response")
+	cmd.Flags().IntVar(&o.maxBlocks, "max-blocks", coap.DefaultMaxBlocks, "set maximum number of blocks of block-wise resource listings to read")
+}
+
+func (o *coapCmdOpts) parseRawOptions() (err error) {
+	if err = o.genericScanCmdOpts.parseRawOptions(); err != nil {
+		return
+	}
+	if o.maxBlocks <= 0 {
+		return errors.New("invalid max blocks: positive number required")
+	}
+	// targets of the subnet argument are scanned on the standard port unless ports are set
+	if len(o.portRanges) == 0 && len(o.ipFile) == 0 && len(o.rawInput) == 0 {
+		o.portRanges = []*scan.PortRange{{StartPort: defaultCoAPPort, EndPort: defaultCoAPPort}}
+	}
+	return
+}
+
+func (o *coapCmdOpts) newCoAPScanEngine(ctx context.Context) scan.EngineResulter {
+	scanner := coap.NewScanner(coap.WithDataTimeout(o.timeout), coap.WithMaxBlocks(o.maxBlocks))
+	return o.newScanEngine(ctx, scanner)
+}
@@ -0,0 +1,104 @@
+package command
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+func TestCoAPCmdDstSubnetError(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{
+			name: "RequiredArg",
+			args: nil,
+		},
+		{
+			name: "InvalidDstSubnet",
+			args: []string{"invalid_ip_address"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := newCoAPCmd().cmd
+			err := cmd.RunE(cmd, tt.args)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestCoAPCmdOptsInitCliFlags(t *testing.T) {
+	t.Parallel()
+	var opts coapCmdOpts
+	cmd := &cobra.Command{}
+
+	opts.initCliFlags(cmd)
+	err := cmd.ParseFlags(strings.Split(
+		"--json -p 5683 -f ip_file.jsonl -w 300 --exit-delay 10s --timeout 3s --max-blocks 4", " "))
+
+	require.NoError(t, err)
+	require.Equal(t, true, opts.json)
+	require.Equal(t, "5683", opts.rawPortRanges)
+	require.Equal(t, "ip_file.jsonl", opts.ipFile)
+	require.Equal(t, 300, opts.workers)
+	require.Equal(t, 10*time.Second, opts.exitDelay)
+
+	require.Equal(t, 3*time.Second, opts.timeout)
+	require.Equal(t, 4, opts.maxBlocks)
+}
+
+func TestCoAPCmdOptsParseRawOptions(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		opts     coapCmdOpts
+		expected []*scan.PortRange
+	}{
+		{
+			name: "Ports",
+			opts: coapCmdOpts{
+				genericScanCmdOpts: genericScanCmdOpts{rawPortRanges: "5683-5684", workers: 300},
+				maxBlocks:          1,
+			},
+			expected: []*scan.PortRange{{StartPort: 5683, EndPort: 5684}},
+		},
+		{
+			name: "DefaultPort",
+			opts: coapCmdOpts{
+				genericScanCmdOpts: genericScanCmdOpts{workers: 300},
+				maxBlocks:          1,
+			},
+			expected: []*scan.PortRange{{StartPort: 5683, EndPort: 5683}},
+		},
+		{
+			name: "IPPortFile",
+			opts: coapCmdOpts{
+				genericScanCmdOpts: genericScanCmdOpts{ipFile: "ip_ports_file.jsonl", workers: 300},
+				maxBlocks:          1,
+			},
+		},
+	}
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			err := tt.opts.parseRawOptions()
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, tt.opts.portRanges)
+		})
+	}
+}
+
+func TestCoAPCmdOptsParseRawOptionsInvalidMaxBlocks(t *testing.T) {
+	t.Parallel()
+	opts := coapCmdOpts{genericScanCmdOpts: genericScanCmdOpts{workers: 300}}
+
+	require.Error(t, opts.parseRawOptions())
+}
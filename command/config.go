@@ -28,6 +28,8 @@ const (
 	defaultWorkerCount = 100
 	defaultTimeout     = 5 * time.Second
 	defaultExitDelay   = 300 * time.Millisecond
+	// defaultDedupLimit bounds memory of deduplication of combined inputs to about 32 MB
+	defaultDedupLimit = 1 << 21
 )
 
 var (
@@ -37,15 +39,20 @@ var (
 	errRateLimit     = errors.New("invalid ratelimit")
 	errARPCacheStdin = errors.New("ARP cache is expected from file or stdin pipe")
 	errIPFlags       = errors.New("invalid ip flags")
-	errNoDstIP       = errors.New("requires one ip subnet argument or file with ip/port pairs")
+	errNoDstIP       = errors.New("requires one ip subnet argument, file with ip/port pairs or input")
 	errARPStdin      = errors.New("ARP cache and IP file can not be read from stdin at the same time")
+	errDstMACFlags   = errors.New("--dstmac and --gwmac flags can not be used together")
 )
 
 type packetScanCmdOpts struct {
+	heartbeatCmdOpts
+	sampleCmdOpts
+	scopeCmdOpts
 	json       bool
 	iface      *net.Interface
 	srcIP      net.IP
 	srcMAC     net.HardwareAddr
+	dstMAC     net.HardwareAddr
 	rateCount  int
 	rateWindow time.Duration
 	exitDelay  time.Duration
@@ -53,15 +60,23 @@ type packetScanCmdOpts struct {
 
 	rawInterface   string
 	rawSrcMAC      string
+	rawDstMAC      string
 	rawRateLimit   string
 	rawExcludeFile string
 }
 
 func (o *packetScanCmdOpts) initCliFlags(cmd *cobra.Command) {
+	o.heartbeatCmdOpts.initCliFlags(cmd)
+	o.sampleCmdOpts.initCliFlags(cmd)
+	o.scopeCmdOpts.initCliFlags(cmd)
 	cmd.Flags().BoolVar(&o.json, "json", false, "enable JSON output")
 	cmd.Flags().StringVarP(&o.rawInterface, "iface", "i", "", "set interface to send/receive packets")
 	cmd.Flags().IPVar(&o.srcIP, "srcip", nil, "set source IP address for generated packets")
 	cmd.Flags().StringVar(&o.rawSrcMAC, "srcmac", "", "set source MAC address for generated packets")
+	cmd.Flags().StringVar(&o.rawDstMAC, "dstmac", "",
+		strings.Join([]string{
+			"set destination MAC address for all generated packets, e.g. broadcast ff:ff:ff:ff:ff:ff",
+			"ARP cache and gateway MAC address are not used"}, "\n"))
 	cmd.Flags().StringVar(&o.rawExcludeFile, "exclude", "",
 		strings.Join([]string{
 			"set file with IPs or subnets in CIDR notation to exclude, one-per line.",
@@ -79,6 +94,15 @@ func (o *packetScanCmdOpts) initCliFlags(cmd *cobra.Command) {
 }
 
 func (o *packetScanCmdOpts) parseRawOptions() (err error) {
+	if err = o.heartbeatCmdOpts.parseRawOptions(); err != nil {
+		return
+	}
+	if err = o.sampleCmdOpts.parseRawOptions(); err != nil {
+		return
+	}
+	if err = o.scopeCmdOpts.parseRawOptions(); err != nil {
+		return
+	}
 	if len(o.rawInterface) > 0 {
 		if o.iface, err = net.InterfaceByName(o.rawInterface); err != nil {
 			return
@@ -89,6 +113,11 @@ func (o *packetScanCmdOpts) parseRawOptions() (err error) {
 			return
 		}
 	}
+	if len(o.rawDstMAC) > 0 {
+		if o.dstMAC, err = net.ParseMAC(o.rawDstMAC); err != nil {
+			return
+		}
+	}
 	if len(o.rawRateLimit) > 0 {
 		if o.rateCount, o.rateWindow, err = parseRateLimit(o.rawRateLimit); err != nil {
 			return
@@ -112,11 +141,14 @@ func (o *packetScanCmdOpts) getScanRange(dstSubnet *net.IPNet) (*scan.Range, err
 	if iface == nil {
 		return nil, errSrcInterface
 	}
+	if err = o.applyTuningProfile(iface); err != nil {
+		return nil, err
+	}
 
 	if o.srcIP != nil {
 		srcIP = o.srcIP
 	}
-	if srcIP == nil {
+	if srcIP.To4() == nil {
 		return nil, errSrcIP
 	}
 
@@ -160,13 +192,8 @@ func (o *packetScanCmdOpts) getLocalSubnetInterface(dstSubnet *net.IPNet) (iface
 	return o.iface, ifaceIP, err
 }
 
-func (o *packetScanCmdOpts) getLogger(name string, w io.Writer) (logger log.Logger, err error) {
-	opts := []log.LoggerOption{log.FlushInterval(1 * time.Second)}
-	if o.json {
-		opts = append(opts, log.JSON())
-	}
-	logger, err = log.NewLogger(w, name, opts...)
-	return
+func (o *packetScanCmdOpts) getLogger(name string, w io.Writer) (log.Logger, error) {
+	return newLogger(newResultWriter(w, name, o.json), name)
 }
 
 type ipScanCmdOpts struct {
@@ -179,8 +206,11 @@ type ipScanCmdOpts struct {
 	logger    log.Logger
 	scanRange *scan.Range
 	cache     *arp.Cache
+	// input is set by scans that accept ip/port pairs from external sources
+	input scan.RequestGenerator
 
 	rawGatewayMAC string
+	rawInput      string
 }
 
 func (o *ipScanCmdOpts) initCliFlags(cmd *cobra.Command) {
@@ -195,6 +225,9 @@ func (o *ipScanCmdOpts) parseRawOptions() (err error) {
 	if err = o.packetScanCmdOpts.parseRawOptions(); err != nil {
 		return
 	}
+	if len(o.rawGatewayMAC) > 0 && o.dstMAC != nil {
+		return errDstMACFlags
+	}
 	if len(o.rawGatewayMAC) > 0 {
 		if o.gatewayMAC, err = net.ParseMAC(o.rawGatewayMAC); err != nil {
 			return
@@ -216,12 +249,12 @@ func (o *ipScanCmdOpts) parseOptions(scanName string, args []string) (err error)
 		o.vpnMode = true
 	}
 
-	if o.logger, err = o.getLogger(scanName, os.Stdout); err != nil {
+	if o.logger, err = o.getLogger(scanName, resultWriter); err != nil {
 		return
 	}
 
-	// disable arp cache parsing for vpn mode
-	if o.vpnMode {
+	// disable arp cache parsing for vpn mode and custom destination MAC address
+	if o.vpnMode || o.dstMAC != nil {
 		return
 	}
 	if err = o.validateARPStdin(); err != nil {
@@ -246,7 +279,7 @@ func (o *ipScanCmdOpts) validateARPStdin() (err error) {
 }
 
 func (o *ipScanCmdOpts) parseDstSubnet(args []string) (ipnet *net.IPNet, err error) {
-	if len(args) == 0 && len(o.ipFile) == 0 {
+	if len(args) == 0 && len(o.ipFile) == 0 && len(o.rawInput) == 0 {
 		return nil, errNoDstIP
 	}
 	if len(args) == 0 {
@@ -288,6 +321,17 @@ func (o *ipScanCmdOpts) isARPCacheFromStdin() bool {
 	return len(o.arpCacheFile) == 0 || o.arpCacheFile == "-"
 }
 
+// withDstMAC sets destination MAC addresses of requests to the --dstmac option or from the ARP cache
+func (o *ipScanCmdOpts) withDstMAC(reqgen scan.RequestGenerator) scan.RequestGenerator {
+	if o.dstMAC != nil {
+		return arp.NewDstMACRequestGenerator(reqgen, o.dstMAC)
+	}
+	if o.cache != nil {
+		return arp.NewCacheRequestGenerator(reqgen, o.gatewayMAC, o.cache)
+	}
+	return reqgen
+}
+
 func (o *ipScanCmdOpts) getGatewayMAC(iface *net.Interface, cache *arp.Cache) (mac net.HardwareAddr, err error) {
 	if o.gatewayMAC != nil {
 		return o.gatewayMAC, nil
@@ -312,12 +356,21 @@ func (o *ipPortScanCmdOpts) initCliFlags(cmd *cobra.Command) {
 	o.ipScanCmdOpts.initCliFlags(cmd)
 	cmd.Flags().StringVarP(&o.rawPortRanges, "ports", "p", "", "set ports to scan")
 	cmd.Flags().StringVar(&o.portFile, "ports-file", "", "set file with ports or port ranges to scan, one-per line")
+	cmd.Flags().StringVar(&o.rawInput, "input", "", inputUsage())
 }
 
 func (o *ipPortScanCmdOpts) parseRawOptions() (err error) {
 	if err = o.ipScanCmdOpts.parseRawOptions(); err != nil {
 		return
 	}
+	if len(o.rawInput) > 0 {
+		if len(o.rawPortRanges) > 0 || len(o.portFile) > 0 {
+			return errInputPorts
+		}
+		if o.input, err = parseInput(o.rawInput); err != nil {
+			return
+		}
+	}
 	if len(o.rawPortRanges) > 0 {
 		if o.portRanges, err = parsePortRanges(o.rawPortRanges); err != nil {
 			return
@@ -348,7 +401,11 @@ func (o *ipPortScanCmdOpts) newIPPortGenerator() (reqgen scan.RequestGenerator)
 		if o.excludeIPs != nil {
 			reqgen = scan.NewFilterIPRequestGenerator(reqgen, o.excludeIPs)
 		}
+		reqgen = withHistory(o.withScope(reqgen))
 	}()
+	if o.input != nil {
+		return o.input
+	}
 	if len(o.ipFile) == 0 {
 		return scan.NewIPPortGenerator(scan.NewIPGenerator(), scan.NewPortGenerator())
 	}
@@ -367,27 +424,66 @@ func (o *ipPortScanCmdOpts) newIPPortGenerator() (reqgen scan.RequestGenerator)
 }
 
 type genericScanCmdOpts struct {
-	json       bool
-	ipFile     string
-	portFile   string
-	portRanges []*scan.PortRange
-	workers    int
-	rateCount  int
-	rateWindow time.Duration
-	exitDelay  time.Duration
-	excludeIPs scan.IPContainer
+	policyCmdOpts
+	exitCodeCmdOpts
+	heartbeatCmdOpts
+	inFlightCmdOpts
+	preflightCmdOpts
+	ipv6SweepCmdOpts
+	monitorCmdOpts
+	alertCmdOpts
+	tagPolicyCmdOpts
+	scopeCmdOpts
+	json            bool
+	ipFile          string
+	traceInput      bool
+	portFile        string
+	portRanges      []*scan.PortRange
+	workers         int
+	hostConcurrency int
+	rateCount       int
+	rateWindow      time.Duration
+	exitDelay       time.Duration
+	excludeIPs      scan.IPContainer
+	input           scan.RequestGenerator
+	requests        *scan.CountRequestGenerator
+	dedupLimit      int
+	// dstSubnet is the subnet argument, it is combined with the file and input targets
+	dstSubnet *net.IPNet
 
 	rawPortRanges  string
 	rawRateLimit   string
 	rawExcludeFile string
+	rawInput       string
 }
 
 func (o *genericScanCmdOpts) initCliFlags(cmd *cobra.Command) {
+	o.policyCmdOpts.initCliFlags(cmd)
+	o.exitCodeCmdOpts.initCliFlags(cmd)
+	o.heartbeatCmdOpts.initCliFlags(cmd)
+	o.inFlightCmdOpts.initCliFlags(cmd)
+	o.preflightCmdOpts.initCliFlags(cmd)
+	o.ipv6SweepCmdOpts.initCliFlags(cmd)
+	o.monitorCmdOpts.initCliFlags(cmd)
+	o.alertCmdOpts.initCliFlags(cmd)
+	o.tagPolicyCmdOpts.initCliFlags(cmd)
+	o.scopeCmdOpts.initCliFlags(cmd)
 	cmd.Flags().BoolVar(&o.json, "json", false, "enable JSON output")
 	cmd.Flags().StringVarP(&o.rawPortRanges, "ports", "p", "", "set ports to scan")
 	cmd.Flags().StringVar(&o.portFile, "ports-file", "", "set file with ports or port ranges to scan, one-per line")
 	cmd.Flags().StringVarP(&o.ipFile, "file", "f", "", "set JSONL file with ip/port pairs to scan")
+	cmd.Flags().BoolVar(&o.traceInput, "trace-input", false,
+		"add the file name and the line number of each target read from the file to the meta field of results")
+	cmd.Flags().StringVar(&o.rawInput, "input", "", inputUsage())
 	cmd.Flags().IntVarP(&o.workers, "workers", "w", defaultWorkerCount, "set workers count")
+	cmd.Flags().IntVar(&o.dedupLimit, "dedup-limit", defaultDedupLimit,
+		strings.Join([]string{
+			"set maximum number of ip/port pairs remembered to skip duplicates when the subnet argument, file and input are combined",
+			"duplicates of pairs beyond the limit are scanned again"}, "\n"))
+	cmd.Flags().IntVar(&o.hostConcurrency, "host-concurrency", 0,
+		strings.Join([]string{
+			"set maximum number of simultaneous connections to one host, 0 means no limit",
+			"requests to other hosts are scanned meanwhile"}, "\n"))
 	cmd.Flags().StringVar(&o.rawExcludeFile, "exclude", "",
 		strings.Join([]string{
 			"set file with IPs or subnets in CIDR notation to exclude, one-per line.",
@@ -435,11 +531,59 @@ func (o *genericScanCmdOpts) parseRawOptions() (err error) {
 	if o.workers <= 0 {
 		return errors.New("invalid workers count")
 	}
-	return
+	if o.hostConcurrency < 0 {
+		return errors.New("invalid host concurrency: non-negative number required")
+	}
+	if o.dedupLimit < 0 {
+		return errors.New("invalid dedup limit: non-negative number required")
+	}
+	if len(o.rawInput) > 0 {
+		if len(o.portRanges) > 0 {
+			return errInputPorts
+		}
+		if o.input, err = parseInput(o.rawInput); err != nil {
+			return
+		}
+	}
+	if err = o.exitCodeCmdOpts.parseRawOptions(); err != nil {
+		return
+	}
+	if err = o.heartbeatCmdOpts.parseRawOptions(); err != nil {
+		return
+	}
+	if err = o.inFlightCmdOpts.parseRawOptions(); err != nil {
+		return
+	}
+	if err = o.preflightCmdOpts.parseRawOptions(); err != nil {
+		return
+	}
+	if err = o.ipv6SweepCmdOpts.parseRawOptions(); err != nil {
+		return
+	}
+	if o.ipv6Generator != nil && (len(o.ipFile) > 0 || len(o.rawInput) > 0) {
+		return errIPv6SweepTargets
+	}
+	if err = o.monitorCmdOpts.parseRawOptions(); err != nil {
+		return
+	}
+	if o.monitorInterval > 0 && o.ipFile == "-" {
+		return errMonitorStdin
+	}
+	if err = o.alertCmdOpts.parseRawOptions(); err != nil {
+		return
+	}
+	if err = o.tagPolicyCmdOpts.parseRawOptions(); err != nil {
+		return
+	}
+	if err = o.scopeCmdOpts.parseRawOptions(); err != nil {
+		return
+	}
+	return o.policyCmdOpts.parseRawOptions()
 }
 
 func (o *genericScanCmdOpts) parseScanRange(args []string) (r *scan.Range, err error) {
 	dstSubnet, err := o.parseDstSubnet(args)
+	o.dstSubnet = dstSubnet
 	r = &scan.Range{
 		DstSubnet: dstSubnet,
 		Ports:     o.portRanges,
@@ -448,7 +592,7 @@ func (o *genericScanCmdOpts) parseScanRange(args []string) (r *scan.Range, err e
 }
 
 func (o *genericScanCmdOpts) parseDstSubnet(args []string) (ipnet *net.IPNet, err error) {
-	if len(args) == 0 && len(o.ipFile) == 0 {
+	if len(args) == 0 && len(o.ipFile) == 0 && len(o.rawInput) == 0 {
 		return nil, errNoDstIP
 	}
 	if len(args) == 0 {
@@ -457,13 +601,12 @@ func (o *genericScanCmdOpts) parseDstSubnet(args []string) (ipnet *net.IPNet, er
 	return ip.ParseIPNet(args[0])
 }
 
-func (o *genericScanCmdOpts) getLogger(name string, w io.Writer) (logger log.Logger, err error) {
-	opts := []log.LoggerOption{log.FlushInterval(1 * time.Second)}
-	if o.json {
-		opts = append(opts, log.JSON())
+func (o *genericScanCmdOpts) getLogger(name string, w io.Writer) (log.Logger, error) {
+	logger, err := newLogger(newResultWriter(w, name, o.json), name)
+	if err != nil {
+		return nil, err
 	}
-	logger, err = log.NewLogger(w, name, opts...)
-	return
+	return o.withAlertLogger(o.withMonitorLogger(logger)), nil
 }
 
 func (o *genericScanCmdOpts) newScanEngine(ctx context.Context, scanner scan.Scanner) *scan.GenericEngine {
@@ -471,8 +614,52 @@ func (o *genericScanCmdOpts) newScanEngine(ctx context.Context, scanner scan.Sca
 		scanner = scan.NewRateLimitScanner(scanner,
 			ratelimit.New(o.rateCount, ratelimit.Per(o.rateWindow)))
 	}
+	return o.newUnlimitedScanEngine(ctx, scanner)
+}
+
+// newUnlimitedScanEngine doesn't apply the rate limit to scan requests,
+// it is used by scanners that open several connections per request and limit them on their own
+func (o *genericScanCmdOpts) newUnlimitedScanEngine(ctx context.Context, scanner scan.Scanner) *scan.GenericEngine {
 	results := scan.NewResultChan(ctx, 1000)
-	return scan.NewScanEngine(o.newIPPortGenerator(), scanner, results, scan.WithScanWorkerCount(o.workers))
+	o.requests = scan.NewCountRequestGenerator(o.withMonitor(o.withPolicyProbes(o.newIPPortGenerator())))
+	// the open file limit may be raised by preflight checks, so they run before the in-flight limit is computed
+	o.runPreflight(o.maxConnections())
+	opts := append([]scan.GenericEngineOption{
+		scan.WithScanWorkerCount(o.workers), scan.WithHostConcurrency(o.hostConcurrency),
+	}, o.scanEngineOptions()...)
+	return scan.NewScanEngine(o.withHeartbeat(o.requests), o.withInFlightLimit(scanner), results, opts...)
+}
+
+// runScanEngine scans the range and logs results, policy violations are logged after the scan,
+// filters are applied to results after the policy records them, the exit code error of the scan is returned
+func (o *genericScanCmdOpts) runScanEngine(ctx context.Context, cmd *cobra.Command, engine scan.EngineResulter,
+	scanRange *scan.Range, logger log.Logger, filters ...log.FilterFunc) (err error) {
+	resultLogger := logger
+	for _, filter := range filters {
+		resultLogger = log.NewFilterLogger(resultLogger, filter)
+	}
+	scanLogger, checker := o.newPolicyChecker(resultLogger)
+	stats := log.NewStatsLogger(scanLogger)
+	if err = startScanEngine(ctx, engine,
+		newEngineConfig(
+			withLogger(stats),
+			withScanRange(scanRange),
+			withExitDelay(o.exitDelay),
+		)); err != nil {
+		return
+	}
+	if err = checkPolicy(cmd, logger, checker); err != nil {
+		return
+	}
+	return o.checkExitCode(cmd, stats, o.requests.Count())
+}
+
+// maxConnections returns the maximum number of simultaneous connections of the scan
+func (o *genericScanCmdOpts) maxConnections() int {
+	if o.maxInFlight > 0 && o.maxInFlight < o.workers {
+		return o.maxInFlight
+	}
+	return o.workers
 }
 
 func (o *genericScanCmdOpts) newIPPortGenerator() (reqgen scan.RequestGenerator) {
@@ -480,22 +667,54 @@ func (o *genericScanCmdOpts) newIPPortGenerator() (reqgen scan.RequestGenerator)
 		if o.excludeIPs != nil {
 			reqgen = scan.NewFilterIPRequestGenerator(reqgen, o.excludeIPs)
 		}
+		reqgen = withHistory(o.withScope(reqgen))
 	}()
-	if len(o.ipFile) == 0 {
-		return scan.NewIPPortGenerator(scan.NewIPGenerator(), scan.NewPortGenerator())
+	if o.ipv6Generator != nil {
+		return scan.NewIPPortGenerator(o.ipv6Generator, scan.NewPortGenerator())
 	}
-	if len(o.portRanges) == 0 {
-		return scan.NewFileIPPortGenerator(func() (io.ReadCloser, error) {
-			return os.Open(o.ipFile)
-		})
+	var inputs []scan.RequestGenerator
+	if o.input != nil {
+		inputs = append(inputs, withDNSWildcardTags(o.input))
 	}
-	ipgen := scan.NewFileIPGenerator(func() (io.ReadCloser, error) {
+	if len(o.ipFile) > 0 {
+		inputs = append(inputs, o.newFileGenerator())
+	}
+	if len(inputs) == 0 || o.dstSubnet != nil {
+		inputs = append(inputs, scan.NewIPPortGenerator(scan.NewIPGenerator(), scan.NewPortGenerator()))
+	}
+	if len(inputs) == 1 {
+		return inputs[0]
+	}
+	dedup := scan.NewDedupRequestGenerator(scan.NewMultiRequestGenerator(inputs...), o.dedupLimit)
+	registerDedup(dedup)
+	return dedup
+}
+
+// newFileGenerator creates the generator of the file with ip/port pairs,
+// or the file with ips if ports are set, "-" is the standard input
+func (o *genericScanCmdOpts) newFileGenerator() scan.RequestGenerator {
+	openFile := func() (io.ReadCloser, error) {
 		if o.ipFile == "-" {
 			return io.NopCloser(os.Stdin), nil
 		}
 		return os.Open(o.ipFile)
-	})
-	return scan.NewIPPortGenerator(ipgen, scan.NewPortGenerator())
+	}
+	var opts []scan.FileGeneratorOption
+	if o.traceInput {
+		opts = append(opts, scan.WithSource(inputSourceName(o.ipFile)))
+	}
+	if len(o.portRanges) == 0 {
+		return scan.NewFileIPPortGenerator(openFile, opts...)
+	}
+	return scan.NewIPPortGenerator(scan.NewFileIPGenerator(openFile, opts...), scan.NewPortGenerator())
+}
+
+// inputSourceName returns the name of the input file for result metadata, "-" is the standard input
+func inputSourceName(fileName string) string {
+	if fileName == "-" {
+		return "stdin"
+	}
+	return fileName
 }
 
 func parsePortRange(portsRange string) (r *scan.PortRange, err error) {
@@ -526,6 +745,23 @@ func parsePortRanges(portsRanges string) (result []*scan.PortRange, err error) {
 	return
 }
 
+// parsePorts returns ports of port ranges, e.g. 636,10636-10637, the empty string means no ports
+func parsePorts(portsRanges string) (result []uint16, err error) {
+	if len(portsRanges) == 0 {
+		return
+	}
+	var ranges []*scan.PortRange
+	if ranges, err = parsePortRanges(portsRanges); err != nil {
+		return
+	}
+	for _, r := range ranges {
+		for port := int(r.StartPort); port <= int(r.EndPort); port++ {
+			result = append(result, uint16(port))
+		}
+	}
+	return
+}
+
 func parseRateLimit(rateLimit string) (rateCount int, rateWindow time.Duration, err error) {
 	parts := strings.Split(rateLimit, "/")
 	if len(parts) > 2 {
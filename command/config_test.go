@@ -1,6 +1,7 @@
 package command
 
 import (
+	"context"
 	"errors"
 	"io"
 	"net"
@@ -21,13 +22,14 @@ func TestPacketScanCmdOptsInitCliFlags(t *testing.T) {
 
 	opts.initCliFlags(cmd)
 	err := cmd.ParseFlags(strings.Split(
-		"--json -i eth0 --srcip 192.168.0.1 --srcmac 00:11:22:33:44:55 -r 500/7s --exit-delay 10s --exclude ips.txt", " "))
+		"--json -i eth0 --srcip 192.168.0.1 --srcmac 00:11:22:33:44:55 --dstmac ff:ff:ff:ff:ff:ff -r 500/7s --exit-delay 10s --exclude ips.txt", " "))
 
 	require.NoError(t, err)
 	require.Equal(t, true, opts.json)
 	require.Equal(t, "eth0", opts.rawInterface)
 	require.Equal(t, net.IPv4(192, 168, 0, 1), opts.srcIP)
 	require.Equal(t, "00:11:22:33:44:55", opts.rawSrcMAC)
+	require.Equal(t, "ff:ff:ff:ff:ff:ff", opts.rawDstMAC)
 	require.Equal(t, "500/7s", opts.rawRateLimit)
 	require.Equal(t, 10*time.Second, opts.exitDelay)
 	require.Equal(t, "ips.txt", opts.rawExcludeFile)
@@ -37,6 +39,7 @@ func TestPacketScanCmdOptsParseRawOptions(t *testing.T) {
 	t.Parallel()
 	opts := &packetScanCmdOpts{
 		rawSrcMAC:    "00:11:22:33:44:55",
+		rawDstMAC:    "01:00:5e:00:00:01",
 		rawRateLimit: "500/7s",
 	}
 
@@ -44,10 +47,17 @@ func TestPacketScanCmdOptsParseRawOptions(t *testing.T) {
 
 	require.NoError(t, err)
 	require.Equal(t, net.HardwareAddr{0x00, 0x11, 0x22, 0x33, 0x44, 0x55}, opts.srcMAC)
+	require.Equal(t, net.HardwareAddr{0x01, 0x00, 0x5e, 0x00, 0x00, 0x01}, opts.dstMAC)
 	require.Equal(t, 500, opts.rateCount)
 	require.Equal(t, 7*time.Second, opts.rateWindow)
 }
 
+func TestGenericScanCmdOptsParseRawOptionsInvalidHostConcurrency(t *testing.T) {
+	t.Parallel()
+	opts := genericScanCmdOpts{workers: 300, hostConcurrency: -1}
+	require.Error(t, opts.parseRawOptions())
+}
+
 func TestIPScanCmdOptsInitCliFlags(t *testing.T) {
 	t.Parallel()
 	var opts ipScanCmdOpts
@@ -94,6 +104,39 @@ func TestIPScanCmdOptsParseRawOptions(t *testing.T) {
 	require.Equal(t, net.HardwareAddr{0x11, 0x22, 0x33, 0x44, 0x55, 0x66}, opts.gatewayMAC)
 }
 
+func TestIPScanCmdOptsParseRawOptionsDstMACWithGatewayMAC(t *testing.T) {
+	t.Parallel()
+	opts := &ipScanCmdOpts{
+		packetScanCmdOpts: packetScanCmdOpts{
+			rawDstMAC: "ff:ff:ff:ff:ff:ff",
+		},
+		rawGatewayMAC: "11:22:33:44:55:66",
+	}
+
+	err := opts.parseRawOptions()
+	require.ErrorIs(t, err, errDstMACFlags)
+}
+
+func TestIPScanCmdOptsWithDstMAC(t *testing.T) {
+	t.Parallel()
+	opts := &ipScanCmdOpts{
+		packetScanCmdOpts: packetScanCmdOpts{
+			dstMAC: net.HardwareAddr{0xff, 0xff, 0xff, 0xff, 0xff, 0xff},
+		},
+	}
+	_, ipnet, err := net.ParseCIDR("192.168.0.1/32")
+	require.NoError(t, err)
+
+	reqgen := opts.withDstMAC(scan.NewIPRequestGenerator(scan.NewIPGenerator()))
+	requests, err := reqgen.GenerateRequests(context.Background(), &scan.Range{DstSubnet: ipnet})
+	require.NoError(t, err)
+
+	request := <-requests
+	require.NotNil(t, request)
+	require.NoError(t, request.Err)
+	require.Equal(t, []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff}, []byte(request.DstMAC))
+}
+
 func TestIPPortScanCmdOptsInitCliFlags(t *testing.T) {
 	t.Parallel()
 	var opts ipPortScanCmdOpts
@@ -157,7 +200,7 @@ func TestGenericScanCmdOptsInitCliFlags(t *testing.T) {
 
 	opts.initCliFlags(cmd)
 	err := cmd.ParseFlags(strings.Split(
-		"--json -p 23-57,71-2733 -f ip_file.jsonl -w 300 -r 500/7s --exit-delay 10s --exclude ips.txt --ports-file ports.txt", " "))
+		"--json -p 23-57,71-2733 -f ip_file.jsonl -w 300 -r 500/7s --exit-delay 10s --exclude ips.txt --ports-file ports.txt --host-concurrency 2 --trace-input", " "))
 
 	require.NoError(t, err)
 	require.Equal(t, true, opts.json)
@@ -168,6 +211,8 @@ func TestGenericScanCmdOptsInitCliFlags(t *testing.T) {
 	require.Equal(t, "500/7s", opts.rawRateLimit)
 	require.Equal(t, 10*time.Second, opts.exitDelay)
 	require.Equal(t, "ips.txt", opts.rawExcludeFile)
+	require.Equal(t, 2, opts.hostConcurrency)
+	require.Equal(t, true, opts.traceInput)
 }
 
 func TestGenericScanCmdOptsParseRawOptions(t *testing.T) {
@@ -350,6 +395,12 @@ func TestGenericScanCmdOptsParseDstSubnet(t *testing.T) {
 			args:     []string{},
 			expected: nil,
 		},
+		{
+			name:     "Input",
+			opts:     genericScanCmdOpts{rawInput: "k8s"},
+			args:     []string{},
+			expected: nil,
+		},
 		{
 			name:      "NoIPHosts",
 			opts:      genericScanCmdOpts{ipFile: ""},
@@ -493,6 +544,21 @@ func TestParsePortRange(t *testing.T) {
 	}
 }
 
+func TestParsePorts(t *testing.T) {
+	t.Parallel()
+
+	ports, err := parsePorts("636,10636-10638")
+	require.NoError(t, err)
+	require.Equal(t, []uint16{636, 10636, 10637, 10638}, ports)
+
+	ports, err = parsePorts("")
+	require.NoError(t, err)
+	require.Nil(t, ports)
+
+	_, err = parsePorts("636-abc")
+	require.Error(t, err)
+}
+
 func TestParsePortRanges(t *testing.T) {
 	t.Parallel()
 
@@ -1062,3 +1128,9 @@ func waitDone(t *testing.T, done <-chan interface{}) {
 		require.Fail(t, "test timeout")
 	}
 }
+
+func TestInputSourceName(t *testing.T) {
+	t.Parallel()
+	require.Equal(t, "stdin", inputSourceName("-"))
+	require.Equal(t, "ips.jsonl", inputSourceName("ips.jsonl"))
+}
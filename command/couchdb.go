@@ -0,0 +1,96 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/v-byte-cpu/sx/command/log"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+	"github.com/v-byte-cpu/sx/pkg/scan/couchdb"
+)
+
+const defaultCouchDBPort = 5984
+
+func newCouchDBCmd() *couchdbCmd {
+	c := &couchdbCmd{}
+
+	cmd := &cobra.Command{
+		Use: "couchdb [flags] [subnet]",
+		Example: strings.Join([]string{
+			"couchdb 192.168.0.1/24", "couchdb -p 5984,6984 10.0.0.1",
+			"couchdb --proto https -p 6984 192.168.0.3",
+			"couchdb -f ip_ports_file.jsonl", "couchdb -p 5984 -f ips_file.jsonl"}, "\n"),
+		Short: "Perform CouchDB scan",
+		Long: strings.Join([]string{
+			"Perform CouchDB scan.",
+			"The welcome message, the session and the database list are requested without credentials,",
+			"port 5984 by default. Servers are reported with the version, whether anonymous users are admins",
+			"(admin party) and whether databases are listed without credentials."}, "\n"),
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer cancel()
+
+			if err = c.opts.parseRawOptions(); err != nil {
+				return
+			}
+			scanRange, err := c.opts.parseScanRange(args)
+			if err != nil {
+				return
+			}
+
+			var logger log.Logger
+			if logger, err = c.opts.getLogger(couchdb.ScanType, resultWriter); err != nil {
+				return
+			}
+
+			engine := c.opts.newCouchDBScanEngine(ctx)
+			return c.opts.runScanEngine(ctx, cmd, engine, scanRange, logger)
+		},
+	}
+
+	c.opts.initCliFlags(cmd)
+
+	c.cmd = cmd
+	return c
+}
+
+type couchdbCmd struct {
+	cmd  *cobra.Command
+	opts couchdbCmdOpts
+}
+
+type couchdbCmdOpts struct {
+	genericScanCmdOpts
+	timeout time.Duration
+	proto   string
+}
+
+func (o *couchdbCmdOpts) initCliFlags(cmd *cobra.Command) {
+	o.genericScanCmdOpts.initCliFlags(cmd)
+	cmd.Flags().DurationVarP(&o.timeout, "timeout", "t", defaultTimeout, "set request timeout")
+	cmd.Flags().StringVar(&o.proto, "proto", cliHTTPProtoFlag, "set protocol to use, only http or https are valid")
+}
+
+func (o *couchdbCmdOpts) parseRawOptions() (err error) {
+	if err = o.genericScanCmdOpts.parseRawOptions(); err != nil {
+		return
+	}
+	if o.proto != cliHTTPProtoFlag && o.proto != cliHTTPSProtoFlag {
+		return errors.New("invalid HTTP proto flag: http or https required")
+	}
+	// targets of the subnet argument are scanned on the standard port unless ports are set
+	if len(o.portRanges) == 0 && len(o.ipFile) == 0 && len(o.rawInput) == 0 {
+		o.portRanges = []*scan.PortRange{{StartPort: defaultCouchDBPort, EndPort: defaultCouchDBPort}}
+	}
+	return
+}
+
+func (o *couchdbCmdOpts) newCouchDBScanEngine(ctx context.Context) scan.EngineResulter {
+	scanner := couchdb.NewScanner(o.proto, couchdb.WithDataTimeout(o.timeout))
+	return o.newScanEngine(ctx, scanner)
+}
@@ -0,0 +1,100 @@
+package command
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+func TestCouchDBCmdDstSubnetError(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{
+			name: "RequiredArg",
+			args: nil,
+		},
+		{
+			name: "InvalidDstSubnet",
+			args: []string{"invalid_ip_address"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := newCouchDBCmd().cmd
+			err := cmd.RunE(cmd, tt.args)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestCouchDBCmdOptsInitCliFlags(t *testing.T) {
+	t.Parallel()
+	var opts couchdbCmdOpts
+	cmd := &cobra.Command{}
+
+	opts.initCliFlags(cmd)
+	err := cmd.ParseFlags(strings.Split(
+		"--json -p 5984,6984 -f ip_file.jsonl -w 300 --exit-delay 10s --timeout 2s --proto https", " "))
+
+	require.NoError(t, err)
+	require.Equal(t, true, opts.json)
+	require.Equal(t, "5984,6984", opts.rawPortRanges)
+	require.Equal(t, "ip_file.jsonl", opts.ipFile)
+	require.Equal(t, 300, opts.workers)
+	require.Equal(t, 10*time.Second, opts.exitDelay)
+
+	require.Equal(t, 2*time.Second, opts.timeout)
+	require.Equal(t, "https", opts.proto)
+}
+
+func TestCouchDBCmdOptsParseRawOptions(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		opts     couchdbCmdOpts
+		expected []*scan.PortRange
+	}{
+		{
+			name: "Ports",
+			opts: couchdbCmdOpts{
+				genericScanCmdOpts: genericScanCmdOpts{rawPortRanges: "5984,6984", workers: 300},
+				proto:              "http",
+			},
+			expected: []*scan.PortRange{{StartPort: 5984, EndPort: 5984}, {StartPort: 6984, EndPort: 6984}},
+		},
+		{
+			name: "DefaultPort",
+			opts: couchdbCmdOpts{
+				genericScanCmdOpts: genericScanCmdOpts{workers: 300},
+				proto:              "http",
+			},
+			expected: []*scan.PortRange{{StartPort: 5984, EndPort: 5984}},
+		},
+	}
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			err := tt.opts.parseRawOptions()
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, tt.opts.portRanges)
+		})
+	}
+}
+
+func TestCouchDBCmdOptsParseRawOptionsInvalidProto(t *testing.T) {
+	t.Parallel()
+	opts := couchdbCmdOpts{
+		genericScanCmdOpts: genericScanCmdOpts{workers: 300},
+		proto:              "ftp",
+	}
+
+	require.Error(t, opts.parseRawOptions())
+}
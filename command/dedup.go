@@ -0,0 +1,34 @@
+package command
+
+import (
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+var (
+	dedupMu         sync.Mutex
+	dedupGenerators []*scan.DedupRequestGenerator
+)
+
+// registerDedup remembers the deduplicating generator of combined inputs to report skipped duplicates
+func registerDedup(rg *scan.DedupRequestGenerator) {
+	dedupMu.Lock()
+	defer dedupMu.Unlock()
+	dedupGenerators = append(dedupGenerators, rg)
+}
+
+// writeDedupStats writes the number of duplicate targets of combined inputs that were skipped
+func writeDedupStats(w io.Writer) {
+	dedupMu.Lock()
+	defer dedupMu.Unlock()
+	var skipped int64
+	for _, rg := range dedupGenerators {
+		skipped += rg.Skipped()
+	}
+	if skipped > 0 {
+		fmt.Fprintf(w, "dedup: %d duplicate targets skipped\n", skipped)
+	}
+}
@@ -0,0 +1,53 @@
+package command
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+func TestGenericScanCmdOptsNewIPPortGeneratorCombined(t *testing.T) {
+	t.Parallel()
+	ipFile := filepath.Join(t.TempDir(), "ips.jsonl")
+	require.NoError(t, os.WriteFile(ipFile, []byte(`{"ip":"10.0.0.1"}`+"\n"+`{"ip":"10.0.1.1"}`+"\n"), 0o644))
+
+	opts := genericScanCmdOpts{
+		workers:       defaultWorkerCount,
+		dedupLimit:    defaultDedupLimit,
+		ipFile:        ipFile,
+		rawPortRanges: "22",
+	}
+	require.NoError(t, opts.parseRawOptions())
+	r, err := opts.parseScanRange([]string{"10.0.0.0/30"})
+	require.NoError(t, err)
+
+	reqgen := opts.newIPPortGenerator()
+	require.IsType(t, &scan.DedupRequestGenerator{}, reqgen)
+	requests, err := reqgen.GenerateRequests(context.Background(), r)
+	require.NoError(t, err)
+	var targets []string
+	for request := range requests {
+		require.NoError(t, request.Err)
+		targets = append(targets, request.DstIP.String())
+	}
+	require.ElementsMatch(t, []string{"10.0.0.0", "10.0.0.1", "10.0.0.2", "10.0.0.3", "10.0.1.1"}, targets)
+	require.Equal(t, int64(1), reqgen.(*scan.DedupRequestGenerator).Skipped())
+}
+
+func TestGenericScanCmdOptsNewIPPortGeneratorSingleInput(t *testing.T) {
+	t.Parallel()
+	opts := genericScanCmdOpts{
+		workers:    defaultWorkerCount,
+		dedupLimit: defaultDedupLimit,
+		ipFile:     "ips.jsonl",
+	}
+	require.NoError(t, opts.parseRawOptions())
+	_, err := opts.parseScanRange(nil)
+	require.NoError(t, err)
+	_, ok := opts.newIPPortGenerator().(*scan.DedupRequestGenerator)
+	require.False(t, ok)
+}
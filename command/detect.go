@@ -0,0 +1,95 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/v-byte-cpu/sx/command/log"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+	"github.com/v-byte-cpu/sx/pkg/scan/detect"
+)
+
+func newDetectCmd() *detectCmd {
+	c := &detectCmd{}
+
+	cmd := &cobra.Command{
+		Use: "detect [flags] [subnet]",
+		Example: strings.Join([]string{
+			"detect -p 8000-9000 192.168.0.1/24", "detect -p 2222,8443 10.0.0.1",
+			"detect --json --banner-timeout 2s -p 1-65535 10.0.0.1",
+			"detect -f ip_ports_file.jsonl", "detect -p 8080 -f ips_file.jsonl"}, "\n"),
+		Short: "Detect services of open ports with TLS, HTTP and banner probes",
+		Long: strings.Join([]string{
+			"Detect services of open ports with TLS, HTTP and banner probes.",
+			"The greeting of server-first protocols like SSH, FTP, SMTP, POP3, IMAP, MySQL and VNC is read first,",
+			"silent servers are sent the TLS handshake and the HTTP request over TLS on the same connection.",
+			"The plain HTTP request is sent over the second connection if the TLS handshake wasn't answered.",
+			"Each port is labeled with the service, so the right application scan can be run on ports with non-standard numbers."}, "\n"),
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer cancel()
+
+			if err = c.opts.parseRawOptions(); err != nil {
+				return
+			}
+			scanRange, err := c.opts.parseScanRange(args)
+			if err != nil {
+				return
+			}
+
+			var logger log.Logger
+			if logger, err = c.opts.getLogger(detect.ScanType, resultWriter); err != nil {
+				return
+			}
+
+			engine := c.opts.newDetectScanEngine(ctx)
+			return c.opts.runScanEngine(ctx, cmd, engine, scanRange, logger)
+		},
+	}
+
+	c.opts.initCliFlags(cmd)
+
+	c.cmd = cmd
+	return c
+}
+
+type detectCmd struct {
+	cmd  *cobra.Command
+	opts detectCmdOpts
+}
+
+type detectCmdOpts struct {
+	genericScanCmdOpts
+	timeout       time.Duration
+	bannerTimeout time.Duration
+}
+
+func (o *detectCmdOpts) initCliFlags(cmd *cobra.Command) {
+	o.genericScanCmdOpts.initCliFlags(cmd)
+	cmd.Flags().DurationVarP(&o.timeout, "timeout", "t", 2*time.Second, "set connect and data timeout")
+	cmd.Flags().DurationVar(&o.bannerTimeout, "banner-timeout", 1*time.Second,
+		"set time to wait for the greeting of server-first protocols before TLS and HTTP probes are sent")
+}
+
+func (o *detectCmdOpts) parseRawOptions() (err error) {
+	if err = o.genericScanCmdOpts.parseRawOptions(); err != nil {
+		return
+	}
+	if o.bannerTimeout <= 0 {
+		return errors.New("invalid banner timeout: positive duration required")
+	}
+	return
+}
+
+func (o *detectCmdOpts) newDetectScanEngine(ctx context.Context) scan.EngineResulter {
+	return o.newScanEngine(ctx, detect.NewScanner(
+		detect.WithDialTimeout(o.timeout),
+		detect.WithDataTimeout(o.timeout),
+		detect.WithBannerTimeout(o.bannerTimeout),
+	))
+}
@@ -0,0 +1,64 @@
+package command
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectCmdDstSubnetError(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{
+			name: "RequiredArg",
+			args: nil,
+		},
+		{
+			name: "InvalidDstSubnet",
+			args: []string{"invalid_ip_address"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := newDetectCmd().cmd
+			err := cmd.RunE(cmd, tt.args)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestDetectCmdOptsInitCliFlags(t *testing.T) {
+	t.Parallel()
+	var opts detectCmdOpts
+	cmd := &cobra.Command{}
+
+	opts.initCliFlags(cmd)
+	err := cmd.ParseFlags(strings.Split(
+		"--json -p 8000-8080 -f ip_file.jsonl -w 300 --exit-delay 10s --timeout 3s --banner-timeout 500ms", " "))
+
+	require.NoError(t, err)
+	require.Equal(t, true, opts.json)
+	require.Equal(t, "8000-8080", opts.rawPortRanges)
+	require.Equal(t, "ip_file.jsonl", opts.ipFile)
+	require.Equal(t, 300, opts.workers)
+	require.Equal(t, 10*time.Second, opts.exitDelay)
+
+	require.Equal(t, 3*time.Second, opts.timeout)
+	require.Equal(t, 500*time.Millisecond, opts.bannerTimeout)
+}
+
+func TestDetectCmdOptsParseRawOptionsError(t *testing.T) {
+	t.Parallel()
+	var opts detectCmdOpts
+	cmd := &cobra.Command{}
+
+	opts.initCliFlags(cmd)
+	require.NoError(t, cmd.ParseFlags(strings.Split("-p 8080 --banner-timeout 0s", " ")))
+	require.Error(t, opts.parseRawOptions())
+}
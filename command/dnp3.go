@@ -0,0 +1,113 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/v-byte-cpu/sx/command/log"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+	"github.com/v-byte-cpu/sx/pkg/scan/dnp3"
+)
+
+func newDNP3Cmd() *dnp3Cmd {
+	c := &dnp3Cmd{}
+
+	cmd := &cobra.Command{
+		Use: "dnp3 [flags] [subnet]",
+		Example: strings.Join([]string{
+			"dnp3 -p 20000 192.168.0.1/24", "dnp3 --json --addresses 1-10,1024 -p 20000 10.0.0.1/16",
+			"dnp3 --master-address 3 -p 20000 10.0.0.1/16",
+			"dnp3 -f ip_ports_file.jsonl", "dnp3 -p 20000 -f ips_file.jsonl"}, "\n"),
+		Short: "Perform DNP3 outstation detection scan",
+		Long: strings.Join([]string{
+			"Perform DNP3 outstation detection scan.",
+			"Request Link Status frames of the data link layer are sent to each outstation address over one connection,",
+			"devices are reported with link addresses of outstations that answered.",
+			"Link status requests don't change the state of outstations."}, "\n"),
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer cancel()
+
+			if err = c.opts.parseRawOptions(); err != nil {
+				return
+			}
+			scanRange, err := c.opts.parseScanRange(args)
+			if err != nil {
+				return
+			}
+
+			var logger log.Logger
+			if logger, err = c.opts.getLogger(dnp3.ScanType, resultWriter); err != nil {
+				return
+			}
+
+			engine := c.opts.newDNP3ScanEngine(ctx)
+			return c.opts.runScanEngine(ctx, cmd, engine, scanRange, logger)
+		},
+	}
+
+	c.opts.initCliFlags(cmd)
+
+	c.cmd = cmd
+	return c
+}
+
+type dnp3Cmd struct {
+	cmd  *cobra.Command
+	opts dnp3CmdOpts
+}
+
+type dnp3CmdOpts struct {
+	genericScanCmdOpts
+	timeout          time.Duration
+	rawAddresses     string
+	rawMasterAddress int
+	addresses        []uint16
+}
+
+func (o *dnp3CmdOpts) initCliFlags(cmd *cobra.Command) {
+	o.genericScanCmdOpts.initCliFlags(cmd)
+	cmd.Flags().DurationVarP(&o.timeout, "timeout", "t", 2*time.Second, "set connect and data timeout")
+	cmd.Flags().StringVar(&o.rawAddresses, "addresses", "0-100",
+		strings.Join([]string{"set outstation addresses or address ranges to request, e.g. 1-10,1024",
+			"requests to all addresses are sent at once and answers are read until the timeout"}, "\n"))
+	cmd.Flags().IntVar(&o.rawMasterAddress, "master-address", 0, "set master address of requests")
+}
+
+func (o *dnp3CmdOpts) parseRawOptions() (err error) {
+	if err = o.genericScanCmdOpts.parseRawOptions(); err != nil {
+		return
+	}
+	ranges, err := parsePortRanges(o.rawAddresses)
+	if err != nil {
+		return fmt.Errorf("invalid addresses %q: numbers between 0 and 65535 required", o.rawAddresses)
+	}
+	o.addresses = nil
+	for _, r := range ranges {
+		if r.StartPort > r.EndPort {
+			return fmt.Errorf("invalid address range %d-%d", r.StartPort, r.EndPort)
+		}
+		for addr := int(r.StartPort); addr <= int(r.EndPort); addr++ {
+			o.addresses = append(o.addresses, uint16(addr))
+		}
+	}
+	if o.rawMasterAddress < 0 || o.rawMasterAddress > 0xffff {
+		return errors.New("invalid master address: number between 0 and 65535 required")
+	}
+	return
+}
+
+func (o *dnp3CmdOpts) newDNP3ScanEngine(ctx context.Context) scan.EngineResulter {
+	return o.newScanEngine(ctx, dnp3.NewScanner(
+		dnp3.WithDialTimeout(o.timeout),
+		dnp3.WithDataTimeout(o.timeout),
+		dnp3.WithAddresses(o.addresses),
+		dnp3.WithMasterAddress(uint16(o.rawMasterAddress)),
+	))
+}
@@ -0,0 +1,69 @@
+package command
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDNP3CmdDstSubnetError(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{
+			name: "RequiredArg",
+			args: nil,
+		},
+		{
+			name: "InvalidDstSubnet",
+			args: []string{"invalid_ip_address"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := newDNP3Cmd().cmd
+			err := cmd.RunE(cmd, tt.args)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestDNP3CmdOptsInitCliFlags(t *testing.T) {
+	t.Parallel()
+	var opts dnp3CmdOpts
+	cmd := &cobra.Command{}
+
+	opts.initCliFlags(cmd)
+	err := cmd.ParseFlags(strings.Split(
+		"--json -p 20000 -f ip_file.jsonl -w 300 --exit-delay 10s --timeout 3s --addresses 1-3,1024 --master-address 3", " "))
+
+	require.NoError(t, err)
+	require.Equal(t, true, opts.json)
+	require.Equal(t, "20000", opts.rawPortRanges)
+	require.Equal(t, "ip_file.jsonl", opts.ipFile)
+	require.Equal(t, 300, opts.workers)
+	require.Equal(t, 10*time.Second, opts.exitDelay)
+
+	require.Equal(t, 3*time.Second, opts.timeout)
+	require.Equal(t, "1-3,1024", opts.rawAddresses)
+	require.Equal(t, 3, opts.rawMasterAddress)
+	require.NoError(t, opts.parseRawOptions())
+	require.Equal(t, []uint16{1, 2, 3, 1024}, opts.addresses)
+}
+
+func TestDNP3CmdOptsParseRawOptionsError(t *testing.T) {
+	t.Parallel()
+	for _, args := range []string{"--addresses 65536", "--addresses 10-1", "--addresses a", "--master-address 65536"} {
+		var opts dnp3CmdOpts
+		cmd := &cobra.Command{}
+
+		opts.initCliFlags(cmd)
+		require.NoError(t, cmd.ParseFlags(append([]string{"-p", "20000"}, strings.Split(args, " ")...)))
+		require.Error(t, opts.parseRawOptions())
+	}
+}
@@ -0,0 +1,83 @@
+package command
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/v-byte-cpu/sx/command/log"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+	"github.com/v-byte-cpu/sx/pkg/scan/dns"
+)
+
+func newDNSCmd() *dnsCmd {
+	c := &dnsCmd{}
+
+	cmd := &cobra.Command{
+		Use: "dns [flags] [subnet]",
+		Example: strings.Join([]string{
+			"dns -p 53 192.168.0.1/24", "dns --query example.org -p 53,5353 10.0.0.1",
+			"dns -f ip_ports_file.jsonl", "dns -p 53 -f ips_file.jsonl"}, "\n"),
+		Short: "Perform DNS open resolver scan",
+		Long: strings.Join([]string{
+			"Perform DNS open resolver scan.",
+			"A recursive A query is sent to each target over UDP, targets that answer with the RA flag set",
+			"are reported as recursive together with the response code and answers."}, "\n"),
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer cancel()
+
+			if err = c.opts.parseRawOptions(); err != nil {
+				return
+			}
+			scanRange, err := c.opts.parseScanRange(args)
+			if err != nil {
+				return
+			}
+
+			var logger log.Logger
+			if logger, err = c.opts.getLogger(dns.ResolverScanType, resultWriter); err != nil {
+				return
+			}
+
+			var engine scan.EngineResulter
+			if engine, err = c.opts.newDNSScanEngine(ctx); err != nil {
+				return
+			}
+			return c.opts.runScanEngine(ctx, cmd, engine, scanRange, logger)
+		},
+	}
+
+	c.opts.initCliFlags(cmd)
+
+	c.cmd = cmd
+	return c
+}
+
+type dnsCmd struct {
+	cmd  *cobra.Command
+	opts dnsCmdOpts
+}
+
+type dnsCmdOpts struct {
+	genericScanCmdOpts
+	timeout time.Duration
+	query   string
+}
+
+func (o *dnsCmdOpts) initCliFlags(cmd *cobra.Command) {
+	o.genericScanCmdOpts.initCliFlags(cmd)
+	cmd.Flags().DurationVarP(&o.timeout, "timeout", "t", 2*time.Second, "set time to wait for a response")
+	cmd.Flags().StringVar(&o.query, "query", dns.DefaultQueryName, "set DNS name to query A records of")
+}
+
+func (o *dnsCmdOpts) newDNSScanEngine(ctx context.Context) (scan.EngineResulter, error) {
+	scanner, err := dns.NewResolverScanner(o.query, dns.WithQueryTimeout(o.timeout))
+	if err != nil {
+		return nil, err
+	}
+	return o.newScanEngine(ctx, scanner), nil
+}
@@ -0,0 +1,78 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+
+	"github.com/v-byte-cpu/sx/pkg/scan/dns"
+)
+
+var (
+	dnsCacheOnce sync.Once
+	dnsCache     *dns.Cache
+)
+
+// sharedDNSCache returns the process-wide cache of DNS lookups performed by inputs and scanners,
+// nil is returned if nameservers are not configured in resolv.conf
+func sharedDNSCache() *dns.Cache {
+	dnsCacheOnce.Do(func() {
+		servers, err := dns.SystemResolvers()
+		if err != nil {
+			return
+		}
+		pool, err := dns.NewResolverPool(servers)
+		if err != nil {
+			return
+		}
+		dnsCache = dns.NewCache(pool)
+	})
+	return dnsCache
+}
+
+// lookupIP resolves IPv4 addresses of the host, names that don't resolve anymore
+// are remembered and skipped if the --dns-liveness option is set
+func lookupIP(ctx context.Context, host string) ([]net.IP, error) {
+	if dnsLivenessFilter != nil {
+		return dnsLivenessFilter.LookupIP(ctx, host)
+	}
+	return lookupHostIP(ctx, host)
+}
+
+// lookupHostIP resolves IPv4 addresses of the host, names resolved by wildcard DNS records are
+// dropped or remembered for tagging if the --dns-wildcard option is set
+func lookupHostIP(ctx context.Context, host string) ([]net.IP, error) {
+	if dnsWildcardDetector != nil {
+		return dnsWildcardDetector.LookupIP(ctx, host)
+	}
+	return resolveIP(ctx, host)
+}
+
+// resolveIP resolves IPv4 addresses of the host with the shared DNS cache. Names that are not found
+// are resolved by the system resolver as well since they may be declared in the hosts file.
+func resolveIP(ctx context.Context, host string) ([]net.IP, error) {
+	if cache := sharedDNSCache(); cache != nil {
+		ips, err := cache.LookupIP(ctx, host)
+		var dnsErr *net.DNSError
+		if err == nil || !errors.As(err, &dnsErr) || !dnsErr.IsNotFound {
+			return ips, err
+		}
+	}
+	return net.DefaultResolver.LookupIP(ctx, "ip4", host)
+}
+
+// writeDNSCacheStats writes hit rate of the shared DNS cache if it was used
+func writeDNSCacheStats(w io.Writer) {
+	// synchronize with the cache initialization, the cache is not created after this call
+	dnsCacheOnce.Do(func() {})
+	if dnsCache == nil {
+		return
+	}
+	stats := dnsCache.Stats()
+	if lookups := stats.Hits + stats.Misses; lookups > 0 {
+		fmt.Fprintf(w, "dns cache: %d lookups, %.1f%% hit rate\n", lookups, 100*stats.HitRate())
+	}
+}
@@ -0,0 +1,17 @@
+package command
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLookupIPLiteral(t *testing.T) {
+	t.Parallel()
+	ips, err := lookupIP(context.Background(), "10.0.0.1")
+	require.NoError(t, err)
+	require.Len(t, ips, 1)
+	require.True(t, net.IPv4(10, 0, 0, 1).Equal(ips[0]))
+}
@@ -0,0 +1,41 @@
+package command
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/v-byte-cpu/sx/pkg/scan"
+	"github.com/v-byte-cpu/sx/pkg/scan/dns"
+)
+
+// dnsLiveness enables checking whether hostname targets still resolve before they are scanned
+var dnsLiveness bool
+
+// dnsLivenessFilter is set if dnsLiveness is set, it checks names resolved by lookupIP
+var dnsLivenessFilter *dns.LivenessFilter
+
+func initDNSLiveness() {
+	dnsLivenessFilter = nil
+	if dnsLiveness {
+		dnsLivenessFilter = dns.NewLivenessFilter(lookupHostIP)
+	}
+}
+
+// withDNSLiveness checks names of requests in batches and skips requests to names
+// that don't resolve anymore if the --dns-liveness option is set
+func withDNSLiveness(reqgen scan.RequestGenerator) scan.RequestGenerator {
+	if dnsLivenessFilter == nil {
+		return reqgen
+	}
+	return dns.NewLivenessGenerator(reqgen, dnsLivenessFilter)
+}
+
+// writeDNSLivenessStats writes the number of checked and skipped names if the filter was used
+func writeDNSLivenessStats(w io.Writer) {
+	if dnsLivenessFilter == nil {
+		return
+	}
+	if stats := dnsLivenessFilter.Stats(); stats.Checked > 0 {
+		fmt.Fprintf(w, "dns liveness: %d names checked, %d skipped\n", stats.Checked, stats.Dead)
+	}
+}
@@ -0,0 +1,33 @@
+package command
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+// TestInitDNSLiveness is not parallel because it changes the global liveness filter
+func TestInitDNSLiveness(t *testing.T) {
+	defer func() {
+		dnsLiveness = false
+		initDNSLiveness()
+	}()
+	reqgen := scan.NewIPPortGenerator(scan.NewIPGenerator(), scan.NewPortGenerator())
+
+	dnsLiveness = true
+	initDNSLiveness()
+	require.NotNil(t, dnsLivenessFilter)
+	require.NotEqual(t, reqgen, withDNSLiveness(reqgen))
+
+	// nothing is written if no names were checked
+	var buf bytes.Buffer
+	writeDNSLivenessStats(&buf)
+	require.Empty(t, buf.String())
+
+	dnsLiveness = false
+	initDNSLiveness()
+	require.Nil(t, dnsLivenessFilter)
+	require.Equal(t, reqgen, withDNSLiveness(reqgen))
+}
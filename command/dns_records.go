@@ -0,0 +1,208 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/v-byte-cpu/sx/command/log"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+	"github.com/v-byte-cpu/sx/pkg/scan/dns"
+	"go.uber.org/ratelimit"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+var errNoDstName = errors.New("requires DNS name arguments or file with names")
+
+func newDNSRecordsCmd() *dnsRecordsCmd {
+	c := &dnsRecordsCmd{}
+
+	cmd := &cobra.Command{
+		Use: "dns-records [flags] [names...]",
+		Example: strings.Join([]string{
+			"dns-records example.com", "dns-records --types A,SOA,257 example.com example.org",
+			"dns-records --resolvers 1.1.1.1,8.8.8.8 -f names.txt",
+			"cat names.txt | dns-records -f -"}, "\n"),
+		Short: "Perform DNS records scan",
+		Long: strings.Join([]string{
+			"Perform DNS records scan.",
+			"Each name is queried for every record type and one result is emitted per DNS record."}, "\n"),
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer cancel()
+
+			if err = c.opts.parseRawOptions(); err != nil {
+				return
+			}
+			scanRange, err := c.opts.parseScanRange(args)
+			if err != nil {
+				return
+			}
+
+			var logger log.Logger
+			if logger, err = c.opts.getLogger(dns.RecordScanType, resultWriter); err != nil {
+				return
+			}
+
+			var engine scan.EngineResulter
+			if engine, err = c.opts.newDNSRecordsScanEngine(ctx, args); err != nil {
+				return
+			}
+			stats := log.NewStatsLogger(logger)
+			if err = startScanEngine(ctx, engine,
+				newEngineConfig(
+					withLogger(stats),
+					withScanRange(scanRange),
+					withExitDelay(c.opts.exitDelay),
+				)); err != nil {
+				return
+			}
+			return c.opts.checkExitCode(cmd, stats, c.opts.requests.Count())
+		},
+	}
+
+	c.opts.initCliFlags(cmd)
+
+	c.cmd = cmd
+	return c
+}
+
+type dnsRecordsCmd struct {
+	cmd  *cobra.Command
+	opts dnsRecordsCmdOpts
+}
+
+type dnsRecordsCmdOpts struct {
+	exitCodeCmdOpts
+	json        bool
+	nameFile    string
+	traceInput  bool
+	workers     int
+	rateCount   int
+	rateWindow  time.Duration
+	exitDelay   time.Duration
+	timeout     time.Duration
+	retries     int
+	resolvers   []string
+	recordTypes []dnsmessage.Type
+	requests    *scan.CountRequestGenerator
+
+	rawRateLimit   string
+	rawRecordTypes []string
+}
+
+func (o *dnsRecordsCmdOpts) initCliFlags(cmd *cobra.Command) {
+	o.exitCodeCmdOpts.initMaxErrorRateCliFlag(cmd)
+	cmd.Flags().BoolVar(&o.json, "json", false, "enable JSON output")
+	cmd.Flags().StringVarP(&o.nameFile, "file", "f", "", "set file with DNS names to scan, one-per line")
+	cmd.Flags().BoolVar(&o.traceInput, "trace-input", false,
+		"add the file name and the line number of each name read from the file to the meta field of results")
+	cmd.Flags().IntVarP(&o.workers, "workers", "w", defaultWorkerCount, "set workers count")
+	cmd.Flags().StringVarP(&o.rawRateLimit, "rate", "r", "",
+		strings.Join([]string{
+			"set rate limit for DNS queries",
+			`format: "rateCount/rateWindow"`,
+			"where rateCount is a number of queries, rateWindow is the time interval",
+			"e.g. 1000/s -- 1000 queries per second", "500/7s -- 500 queries per 7 seconds\n"}, "\n"))
+	cmd.Flags().DurationVar(&o.exitDelay, "exit-delay", defaultExitDelay,
+		strings.Join([]string{
+			"set exit delay to wait for last response",
+			"any expression accepted by time.ParseDuration is valid"}, "\n"))
+	cmd.Flags().DurationVarP(&o.timeout, "timeout", "t", 2*time.Second, "set DNS query timeout")
+	cmd.Flags().IntVar(&o.retries, "retries", 2, "set number of query retries with the next resolver")
+	cmd.Flags().StringSliceVar(&o.resolvers, "resolvers", nil,
+		strings.Join([]string{"set comma-separated list of DNS resolvers, ip or ip:port",
+			"nameservers from /etc/resolv.conf are used by default"}, "\n"))
+	cmd.Flags().StringSliceVar(&o.rawRecordTypes, "types", []string{"A", "AAAA", "MX", "TXT", "NS"},
+		strings.Join([]string{"set comma-separated list of DNS record types to query",
+			"record type names or numeric values are valid"}, "\n"))
+}
+
+func (o *dnsRecordsCmdOpts) parseRawOptions() (err error) {
+	if len(o.rawRateLimit) > 0 {
+		if o.rateCount, o.rateWindow, err = parseRateLimit(o.rawRateLimit); err != nil {
+			return
+		}
+	}
+	if o.workers <= 0 {
+		return errors.New("invalid workers count")
+	}
+	if err = o.exitCodeCmdOpts.parseRawOptions(); err != nil {
+		return
+	}
+	if o.retries < 0 {
+		return errors.New("invalid retries: non-negative number required")
+	}
+	if len(o.rawRecordTypes) == 0 {
+		return dns.ErrRecordType
+	}
+	o.recordTypes = nil
+	for _, rawType := range o.rawRecordTypes {
+		var recordType dnsmessage.Type
+		if recordType, err = dns.ParseRecordType(rawType); err != nil {
+			return
+		}
+		o.recordTypes = append(o.recordTypes, recordType)
+	}
+	if len(o.resolvers) == 0 {
+		if o.resolvers, err = dns.SystemResolvers(); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// parseScanRange passes DNS record types as ports of the scan range
+func (o *dnsRecordsCmdOpts) parseScanRange(args []string) (r *scan.Range, err error) {
+	if len(args) == 0 && len(o.nameFile) == 0 {
+		return nil, errNoDstName
+	}
+	r = &scan.Range{}
+	for _, recordType := range o.recordTypes {
+		r.Ports = append(r.Ports, &scan.PortRange{
+			StartPort: uint16(recordType), EndPort: uint16(recordType)})
+	}
+	return
+}
+
+func (o *dnsRecordsCmdOpts) getLogger(name string, w io.Writer) (log.Logger, error) {
+	return newLogger(newResultWriter(w, name, o.json), name)
+}
+
+func (o *dnsRecordsCmdOpts) newNameGenerator(names []string) scan.RequestGenerator {
+	var opts []scan.FileGeneratorOption
+	if o.traceInput && len(o.nameFile) > 0 {
+		opts = append(opts, scan.WithSource(inputSourceName(o.nameFile)))
+	}
+	return scan.NewFileNamePortGenerator(func() (io.ReadCloser, error) {
+		if len(o.nameFile) == 0 {
+			return io.NopCloser(strings.NewReader(strings.Join(names, "\n"))), nil
+		}
+		if o.nameFile == "-" {
+			return io.NopCloser(os.Stdin), nil
+		}
+		return os.Open(o.nameFile)
+	}, scan.NewPortGenerator(), opts...)
+}
+
+func (o *dnsRecordsCmdOpts) newDNSRecordsScanEngine(ctx context.Context, names []string) (engine scan.EngineResulter, err error) {
+	var pool *dns.ResolverPool
+	if pool, err = dns.NewResolverPool(o.resolvers,
+		dns.WithResolverTimeout(o.timeout), dns.WithRetries(o.retries)); err != nil {
+		return
+	}
+	var scanner scan.Scanner = dns.NewRecordScanner(pool)
+	if o.rateCount > 0 {
+		scanner = scan.NewRateLimitScanner(scanner,
+			ratelimit.New(o.rateCount, ratelimit.Per(o.rateWindow)))
+	}
+	results := scan.NewResultChan(ctx, 1000)
+	o.requests = scan.NewCountRequestGenerator(withDNSLiveness(o.newNameGenerator(names)))
+	return scan.NewScanEngine(o.requests, scanner, results,
+		scan.WithScanWorkerCount(o.workers)), nil
+}
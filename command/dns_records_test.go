@@ -0,0 +1,132 @@
+package command
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+func TestDNSRecordsCmdDstNameError(t *testing.T) {
+	t.Parallel()
+	cmd := newDNSRecordsCmd().cmd
+	require.NoError(t, cmd.Flags().Set("resolvers", "127.0.0.1"))
+	err := cmd.RunE(cmd, nil)
+	require.ErrorIs(t, err, errNoDstName)
+}
+
+func TestDNSRecordsCmdOptsInitCliFlags(t *testing.T) {
+	t.Parallel()
+	var opts dnsRecordsCmdOpts
+	cmd := &cobra.Command{}
+
+	opts.initCliFlags(cmd)
+	err := cmd.ParseFlags(strings.Split(
+		"--json -f names.txt --trace-input -w 300 -r 500/7s --exit-delay 10s --timeout 3s --retries 1 "+
+			"--resolvers 1.1.1.1,8.8.8.8:53 --types A,MX", " "))
+
+	require.NoError(t, err)
+	require.Equal(t, true, opts.json)
+	require.Equal(t, "names.txt", opts.nameFile)
+	require.Equal(t, true, opts.traceInput)
+	require.Equal(t, 300, opts.workers)
+	require.Equal(t, "500/7s", opts.rawRateLimit)
+	require.Equal(t, 10*time.Second, opts.exitDelay)
+	require.Equal(t, 3*time.Second, opts.timeout)
+	require.Equal(t, 1, opts.retries)
+	require.Equal(t, []string{"1.1.1.1", "8.8.8.8:53"}, opts.resolvers)
+	require.Equal(t, []string{"A", "MX"}, opts.rawRecordTypes)
+}
+
+func TestDNSRecordsCmdOptsDefaultTypes(t *testing.T) {
+	t.Parallel()
+	var opts dnsRecordsCmdOpts
+	cmd := &cobra.Command{}
+
+	opts.initCliFlags(cmd)
+	require.NoError(t, cmd.ParseFlags([]string{"--resolvers", "127.0.0.1"}))
+	require.NoError(t, opts.parseRawOptions())
+	require.Equal(t, []dnsmessage.Type{
+		dnsmessage.TypeA, dnsmessage.TypeAAAA, dnsmessage.TypeMX, dnsmessage.TypeTXT, dnsmessage.TypeNS,
+	}, opts.recordTypes)
+}
+
+func TestDNSRecordsCmdOptsParseRawOptions(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		opts  dnsRecordsCmdOpts
+		types []dnsmessage.Type
+		err   bool
+	}{
+		{
+			name: "NamedAndNumericTypes",
+			opts: dnsRecordsCmdOpts{
+				workers: 100, resolvers: []string{"127.0.0.1"},
+				rawRecordTypes: []string{"txt", "257"},
+			},
+			types: []dnsmessage.Type{dnsmessage.TypeTXT, dnsmessage.Type(257)},
+		},
+		{
+			name: "InvalidType",
+			opts: dnsRecordsCmdOpts{
+				workers: 100, resolvers: []string{"127.0.0.1"},
+				rawRecordTypes: []string{"A", "ABC"},
+			},
+			err: true,
+		},
+		{
+			name: "EmptyTypes",
+			opts: dnsRecordsCmdOpts{workers: 100, resolvers: []string{"127.0.0.1"}},
+			err:  true,
+		},
+		{
+			name: "InvalidWorkers",
+			opts: dnsRecordsCmdOpts{
+				resolvers: []string{"127.0.0.1"}, rawRecordTypes: []string{"A"},
+			},
+			err: true,
+		},
+		{
+			name: "InvalidRetries",
+			opts: dnsRecordsCmdOpts{
+				workers: 100, retries: -1, resolvers: []string{"127.0.0.1"},
+				rawRecordTypes: []string{"A"},
+			},
+			err: true,
+		},
+	}
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			err := tt.opts.parseRawOptions()
+			if tt.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.types, tt.opts.recordTypes)
+		})
+	}
+}
+
+func TestDNSRecordsCmdOptsParseScanRange(t *testing.T) {
+	t.Parallel()
+	opts := dnsRecordsCmdOpts{
+		recordTypes: []dnsmessage.Type{dnsmessage.TypeA, dnsmessage.TypeMX},
+	}
+	r, err := opts.parseScanRange([]string{"example.com"})
+	require.NoError(t, err)
+	require.Equal(t, &scan.Range{
+		Ports: []*scan.PortRange{
+			{StartPort: uint16(dnsmessage.TypeA), EndPort: uint16(dnsmessage.TypeA)},
+			{StartPort: uint16(dnsmessage.TypeMX), EndPort: uint16(dnsmessage.TypeMX)},
+		},
+	}, r)
+}
@@ -0,0 +1,65 @@
+package command
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+	"github.com/v-byte-cpu/sx/pkg/scan/dns"
+)
+
+func TestDNSCmdDstSubnetError(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{
+			name: "RequiredArg",
+			args: nil,
+		},
+		{
+			name: "InvalidDstSubnet",
+			args: []string{"invalid_ip_address"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := newDNSCmd().cmd
+			err := cmd.RunE(cmd, tt.args)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestDNSCmdOptsInitCliFlags(t *testing.T) {
+	t.Parallel()
+	var opts dnsCmdOpts
+	cmd := &cobra.Command{}
+
+	opts.initCliFlags(cmd)
+	err := cmd.ParseFlags(strings.Split(
+		"--json -p 53,5353 -f ip_file.jsonl -w 300 --exit-delay 10s --timeout 3s --query example.org", " "))
+
+	require.NoError(t, err)
+	require.Equal(t, true, opts.json)
+	require.Equal(t, "53,5353", opts.rawPortRanges)
+	require.Equal(t, "ip_file.jsonl", opts.ipFile)
+	require.Equal(t, 300, opts.workers)
+	require.Equal(t, 10*time.Second, opts.exitDelay)
+
+	require.Equal(t, 3*time.Second, opts.timeout)
+	require.Equal(t, "example.org", opts.query)
+}
+
+func TestDNSCmdOptsNewDNSScanEngineInvalidQuery(t *testing.T) {
+	t.Parallel()
+	opts := dnsCmdOpts{query: ""}
+
+	_, err := opts.newDNSScanEngine(context.Background())
+
+	require.ErrorIs(t, err, dns.ErrName)
+}
@@ -0,0 +1,44 @@
+package command
+
+import (
+	"errors"
+
+	"github.com/v-byte-cpu/sx/pkg/scan"
+	"github.com/v-byte-cpu/sx/pkg/scan/dns"
+)
+
+const (
+	dnsWildcardTag  = "tag"
+	dnsWildcardDrop = "drop"
+)
+
+var errDNSWildcard = errors.New("invalid dns wildcard mode: tag or drop required")
+
+// dnsWildcardMode enables detection of names resolved by wildcard DNS records
+var dnsWildcardMode string
+
+// dnsWildcardDetector is set if dnsWildcardMode is set, it checks names resolved by lookupIP
+var dnsWildcardDetector *dns.WildcardDetector
+
+func parseDNSWildcardMode() error {
+	switch dnsWildcardMode {
+	case "":
+		dnsWildcardDetector = nil
+	case dnsWildcardTag:
+		dnsWildcardDetector = dns.NewWildcardDetector(resolveIP)
+	case dnsWildcardDrop:
+		dnsWildcardDetector = dns.NewWildcardDetector(resolveIP, dns.WithWildcardDrop())
+	default:
+		return errDNSWildcard
+	}
+	return nil
+}
+
+// withDNSWildcardTags adds the dns_wildcard meta field to requests to addresses of wildcard records
+// if names are resolved in the tag mode
+func withDNSWildcardTags(reqgen scan.RequestGenerator) scan.RequestGenerator {
+	if dnsWildcardMode != dnsWildcardTag || dnsWildcardDetector == nil {
+		return reqgen
+	}
+	return dns.NewWildcardTagGenerator(reqgen, dnsWildcardDetector)
+}
@@ -0,0 +1,35 @@
+package command
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+// TestParseDNSWildcardMode is not parallel because it changes the global wildcard detector
+func TestParseDNSWildcardMode(t *testing.T) {
+	defer func() {
+		dnsWildcardMode = ""
+		require.NoError(t, parseDNSWildcardMode())
+	}()
+	reqgen := scan.NewIPPortGenerator(scan.NewIPGenerator(), scan.NewPortGenerator())
+
+	dnsWildcardMode = "tag"
+	require.NoError(t, parseDNSWildcardMode())
+	require.NotNil(t, dnsWildcardDetector)
+	require.NotEqual(t, reqgen, withDNSWildcardTags(reqgen))
+
+	dnsWildcardMode = "drop"
+	require.NoError(t, parseDNSWildcardMode())
+	require.NotNil(t, dnsWildcardDetector)
+	require.Equal(t, reqgen, withDNSWildcardTags(reqgen))
+
+	dnsWildcardMode = ""
+	require.NoError(t, parseDNSWildcardMode())
+	require.Nil(t, dnsWildcardDetector)
+	require.Equal(t, reqgen, withDNSWildcardTags(reqgen))
+
+	dnsWildcardMode = "ignore"
+	require.ErrorIs(t, parseDNSWildcardMode(), errDNSWildcard)
+}
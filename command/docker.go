@@ -37,17 +37,12 @@ func newDockerCmd() *dockerCmd {
 			}
 
 			var logger log.Logger
-			if logger, err = c.opts.getLogger(docker.ScanType, os.Stdout); err != nil {
+			if logger, err = c.opts.getLogger(docker.ScanType, resultWriter); err != nil {
 				return
 			}
 
 			engine := c.opts.newDockerScanEngine(ctx)
-			return startScanEngine(ctx, engine,
-				newEngineConfig(
-					withLogger(logger),
-					withScanRange(scanRange),
-					withExitDelay(c.opts.exitDelay),
-				))
+			return c.opts.runScanEngine(ctx, cmd, engine, scanRange, logger)
 		},
 	}
 
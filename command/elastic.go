@@ -37,17 +37,12 @@ func newElasticCmd() *elasticCmd {
 			}
 
 			var logger log.Logger
-			if logger, err = c.opts.getLogger(elastic.ScanType, os.Stdout); err != nil {
+			if logger, err = c.opts.getLogger(elastic.ScanType, resultWriter); err != nil {
 				return
 			}
 
 			engine := c.opts.newElasticScanEngine(ctx)
-			return startScanEngine(ctx, engine,
-				newEngineConfig(
-					withLogger(logger),
-					withScanRange(scanRange),
-					withExitDelay(c.opts.exitDelay),
-				))
+			return c.opts.runScanEngine(ctx, cmd, engine, scanRange, logger)
 		},
 	}
 
@@ -0,0 +1,50 @@
+package command
+
+import (
+	"os"
+	"time"
+
+	"github.com/v-byte-cpu/sx/command/log"
+)
+
+// errorsFile is the file of the NDJSON error stream, errors are logged to stderr if it is not set
+var errorsFile string
+
+var (
+	errorStream     *log.ErrorStream
+	errorStreamFile *os.File
+)
+
+// openErrorStream creates the file of the error stream if it is enabled
+func openErrorStream() (err error) {
+	if len(errorsFile) == 0 {
+		return
+	}
+	if errorStreamFile, err = os.Create(errorsFile); err != nil {
+		return
+	}
+	errorStream = log.NewErrorStream(errorStreamFile)
+	return
+}
+
+func closeErrorStream() (err error) {
+	if errorStreamFile == nil {
+		return
+	}
+	err = errorStreamFile.Close()
+	errorStream, errorStreamFile = nil, nil
+	return
+}
+
+// newLogger returns the logger of scan results and errors,
+// errors are written to the error stream if it is enabled and port states are recorded if the history is enabled
+func newLogger(rw log.ResultWriter, name string) (log.Logger, error) {
+	logger, err := log.NewLogger(rw, name, log.FlushInterval(1*time.Second))
+	if err != nil {
+		return nil, err
+	}
+	if errorStream != nil {
+		logger = log.NewErrorStreamLogger(logger, errorStream, name)
+	}
+	return withHistoryLogger(logger), nil
+}
@@ -0,0 +1,54 @@
+package command
+
+import (
+	"encoding/json"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/v-byte-cpu/sx/command/log"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+// TestErrorStream is not parallel because it changes the global error stream
+func TestErrorStream(t *testing.T) {
+	errorsFile = filepath.Join(t.TempDir(), "errors.jsonl")
+	defer func() { errorsFile = "" }()
+	require.NoError(t, openErrorStream())
+
+	logger, err := newLogger(newResultWriter(os.Stdout, "tcp", true), "tcp")
+	require.NoError(t, err)
+	logger.Error(scan.NewTargetError(&scan.Request{DstIP: net.IPv4(192, 168, 0, 1).To4(), DstPort: 22}, false,
+		scan.NewError(scan.ErrUnreachable, errors.New("connection refused"))))
+	logger.Error(errors.New("send error"))
+	require.NoError(t, closeErrorStream())
+
+	data, err := os.ReadFile(errorsFile)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	require.Len(t, lines, 2)
+	var record log.ErrorRecord
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &record))
+	require.Equal(t, "tcp", record.Scan)
+	require.Equal(t, "unreachable", record.Kind)
+	require.Equal(t, "192.168.0.1", record.IP)
+	require.Equal(t, uint16(22), record.Port)
+	require.Equal(t, "connection refused", record.Error)
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &record))
+	require.Equal(t, "send error", record.Error)
+
+	// the stream is disabled after it is closed
+	logger, err = newLogger(newResultWriter(os.Stdout, "tcp", true), "tcp")
+	require.NoError(t, err)
+	require.NotPanics(t, func() { logger.Error(errors.New("send error")) })
+}
+
+func TestOpenErrorStreamError(t *testing.T) {
+	errorsFile = filepath.Join(t.TempDir(), "none", "errors.jsonl")
+	defer func() { errorsFile = "" }()
+	require.Error(t, openErrorStream())
+}
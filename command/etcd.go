@@ -0,0 +1,85 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/v-byte-cpu/sx/command/log"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+	"github.com/v-byte-cpu/sx/pkg/scan/etcd"
+)
+
+func newEtcdCmd() *etcdCmd {
+	c := &etcdCmd{}
+
+	cmd := &cobra.Command{
+		Use: "etcd [flags] [subnet]",
+		Example: strings.Join([]string{
+			"etcd -p 2379 192.168.0.1/24", "etcd -p 2379-2380 10.0.0.1",
+			"etcd --proto https -p 2379 192.168.0.3",
+			"etcd -f ip_ports_file.jsonl", "etcd -p 2379-2380 -f ips_file.jsonl"}, "\n"),
+		Short: "Perform etcd scan",
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer cancel()
+
+			if err = c.opts.parseRawOptions(); err != nil {
+				return
+			}
+			scanRange, err := c.opts.parseScanRange(args)
+			if err != nil {
+				return
+			}
+
+			var logger log.Logger
+			if logger, err = c.opts.getLogger(etcd.ScanType, resultWriter); err != nil {
+				return
+			}
+
+			engine := c.opts.newEtcdScanEngine(ctx)
+			return c.opts.runScanEngine(ctx, cmd, engine, scanRange, logger)
+		},
+	}
+
+	c.opts.initCliFlags(cmd)
+
+	c.cmd = cmd
+	return c
+}
+
+type etcdCmd struct {
+	cmd  *cobra.Command
+	opts etcdCmdOpts
+}
+
+type etcdCmdOpts struct {
+	genericScanCmdOpts
+	timeout time.Duration
+	proto   string
+}
+
+func (o *etcdCmdOpts) initCliFlags(cmd *cobra.Command) {
+	o.genericScanCmdOpts.initCliFlags(cmd)
+	cmd.Flags().DurationVarP(&o.timeout, "timeout", "t", defaultTimeout, "set request timeout")
+	cmd.Flags().StringVar(&o.proto, "proto", cliHTTPProtoFlag, "set protocol to use, only http or https are valid")
+}
+
+func (o *etcdCmdOpts) parseRawOptions() (err error) {
+	if err = o.genericScanCmdOpts.parseRawOptions(); err != nil {
+		return
+	}
+	if o.proto != cliHTTPProtoFlag && o.proto != cliHTTPSProtoFlag {
+		return errors.New("invalid HTTP proto flag: http or https required")
+	}
+	return
+}
+
+func (o *etcdCmdOpts) newEtcdScanEngine(ctx context.Context) scan.EngineResulter {
+	scanner := etcd.NewScanner(o.proto, etcd.WithDataTimeout(o.timeout))
+	return o.newScanEngine(ctx, scanner)
+}
@@ -0,0 +1,79 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/spf13/cobra"
+	"github.com/v-byte-cpu/sx/command/log"
+)
+
+const (
+	// exitCodeViolations is returned when scan results violate the expected state policy
+	exitCodeViolations = 2
+	// exitCodeOpen is returned when open ports are found and --fail-on-open is set
+	exitCodeOpen = 3
+	// exitCodeErrorRate is returned when the share of failed scan requests exceeds --max-error-rate
+	exitCodeErrorRate = 4
+)
+
+var errMaxErrorRate = errors.New("invalid max error rate: number from 0 to 1 required")
+
+// exitCodeCmdOpts are options to exit with non-zero code depending on scan results,
+// so sx can be used in automation pipelines
+type exitCodeCmdOpts struct {
+	failOnOpen   bool
+	maxErrorRate float64
+
+	rawMaxErrorRate string
+}
+
+func (o *exitCodeCmdOpts) initCliFlags(cmd *cobra.Command) {
+	o.initFailOnOpenCliFlag(cmd)
+	o.initMaxErrorRateCliFlag(cmd)
+}
+
+func (o *exitCodeCmdOpts) initFailOnOpenCliFlag(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&o.failOnOpen, "fail-on-open", false,
+		fmt.Sprintf("exit with code %d if any open port is found", exitCodeOpen))
+}
+
+func (o *exitCodeCmdOpts) initMaxErrorRateCliFlag(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&o.rawMaxErrorRate, "max-error-rate", "",
+		fmt.Sprintf("exit with code %d if the share of failed scan requests exceeds the rate, e.g. 0.1", exitCodeErrorRate))
+}
+
+func (o *exitCodeCmdOpts) parseRawOptions() (err error) {
+	if len(o.rawMaxErrorRate) == 0 {
+		return
+	}
+	if o.maxErrorRate, err = strconv.ParseFloat(o.rawMaxErrorRate, 64); err != nil ||
+		o.maxErrorRate < 0 || o.maxErrorRate > 1 {
+		return errMaxErrorRate
+	}
+	return
+}
+
+// checkExitCode returns an error with the exit code of the first matched condition:
+// open ports are found or the error rate of scan requests is exceeded
+func (o *exitCodeCmdOpts) checkExitCode(cmd *cobra.Command, stats *log.StatsLogger, requests int64) error {
+	if o.failOnOpen && stats.Results() > 0 {
+		cmd.SilenceUsage = true
+		return &exitError{code: exitCodeOpen, err: fmt.Errorf("open ports found: %d", stats.Results())}
+	}
+	if len(o.rawMaxErrorRate) == 0 || stats.Errors() == 0 {
+		return nil
+	}
+	// errors without any requests mean that requests could not be generated at all
+	rate := 1.0
+	if requests > 0 {
+		rate = float64(stats.Errors()) / float64(requests)
+	}
+	if rate <= o.maxErrorRate {
+		return nil
+	}
+	cmd.SilenceUsage = true
+	return &exitError{code: exitCodeErrorRate,
+		err: fmt.Errorf("error rate exceeded: %d errors of %d requests", stats.Errors(), requests)}
+}
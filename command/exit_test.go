@@ -0,0 +1,145 @@
+package command
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+	"github.com/v-byte-cpu/sx/command/log"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+	"github.com/v-byte-cpu/sx/pkg/scan/tcp"
+)
+
+func TestExitCodeCmdOptsInitCliFlags(t *testing.T) {
+	t.Parallel()
+	cmd := &cobra.Command{}
+	var opts exitCodeCmdOpts
+	opts.initCliFlags(cmd)
+
+	require.NoError(t, cmd.ParseFlags([]string{"--fail-on-open", "--max-error-rate", "0.25"}))
+	require.True(t, opts.failOnOpen)
+	require.NoError(t, opts.parseRawOptions())
+	require.Equal(t, 0.25, opts.maxErrorRate)
+}
+
+func TestExitCodeCmdOptsParseRawOptionsError(t *testing.T) {
+	t.Parallel()
+	for _, rawRate := range []string{"abc", "-0.1", "1.5"} {
+		opts := exitCodeCmdOpts{rawMaxErrorRate: rawRate}
+		require.ErrorIs(t, opts.parseRawOptions(), errMaxErrorRate, rawRate)
+	}
+}
+
+func newTestStatsLogger(t *testing.T, results, errs int) *log.StatsLogger {
+	t.Helper()
+	logger, err := log.NewLogger(newResultWriter(&bytes.Buffer{}, "test", false), "test")
+	require.NoError(t, err)
+	stats := log.NewStatsLogger(logger)
+
+	resultCh := make(chan scan.Result, results)
+	for i := 0; i < results; i++ {
+		resultCh <- &tcp.ScanResult{ScanType: tcp.SYNScanType, IP: "10.0.0.1", Port: uint16(80 + i)}
+	}
+	close(resultCh)
+	require.NoError(t, stats.LogResults(context.Background(), resultCh))
+	for i := 0; i < errs; i++ {
+		stats.Error(errors.New("scan error"))
+	}
+	return stats
+}
+
+func TestCheckExitCode(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		opts     exitCodeCmdOpts
+		results  int
+		errors   int
+		requests int64
+		code     int
+	}{
+		{
+			name:     "NoOptions",
+			results:  1,
+			errors:   10,
+			requests: 10,
+		},
+		{
+			name:     "FailOnOpen",
+			opts:     exitCodeCmdOpts{failOnOpen: true},
+			results:  1,
+			requests: 1,
+			code:     exitCodeOpen,
+		},
+		{
+			name:     "FailOnOpenWithoutResults",
+			opts:     exitCodeCmdOpts{failOnOpen: true},
+			requests: 1,
+		},
+		{
+			name:     "ErrorRateNotExceeded",
+			opts:     exitCodeCmdOpts{rawMaxErrorRate: "0.5"},
+			errors:   5,
+			requests: 10,
+		},
+		{
+			name:     "ErrorRateExceeded",
+			opts:     exitCodeCmdOpts{rawMaxErrorRate: "0.5"},
+			errors:   6,
+			requests: 10,
+			code:     exitCodeErrorRate,
+		},
+		{
+			name:   "ErrorsWithoutRequests",
+			opts:   exitCodeCmdOpts{rawMaxErrorRate: "0.5"},
+			errors: 1,
+			code:   exitCodeErrorRate,
+		},
+		{
+			name:     "OpenPortsBeforeErrorRate",
+			opts:     exitCodeCmdOpts{failOnOpen: true, rawMaxErrorRate: "0"},
+			results:  1,
+			errors:   1,
+			requests: 2,
+			code:     exitCodeOpen,
+		},
+	}
+
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			require.NoError(t, tt.opts.parseRawOptions())
+			stats := newTestStatsLogger(t, tt.results, tt.errors)
+
+			cmd := &cobra.Command{}
+			err := tt.opts.checkExitCode(cmd, stats, tt.requests)
+			if tt.code == 0 {
+				require.NoError(t, err)
+				require.False(t, cmd.SilenceUsage)
+				return
+			}
+			var exitErr *exitError
+			require.ErrorAs(t, err, &exitErr)
+			require.Equal(t, tt.code, exitErr.code)
+			require.True(t, cmd.SilenceUsage)
+		})
+	}
+}
+
+func TestTCPFlagsCmdOptsParseRawOptionsFailOnOpen(t *testing.T) {
+	t.Parallel()
+	opts := tcpFlagsCmdOpts{
+		rawTCPFlags:     "fin",
+		exitCodeCmdOpts: exitCodeCmdOpts{failOnOpen: true},
+	}
+	opts.rawPortRanges = "80"
+	require.ErrorIs(t, opts.parseRawOptions(), errTCPflagOpen)
+
+	opts = tcpFlagsCmdOpts{exitCodeCmdOpts: exitCodeCmdOpts{failOnOpen: true}}
+	opts.rawPortRanges = "80"
+	require.NoError(t, opts.parseRawOptions())
+}
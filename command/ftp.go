@@ -0,0 +1,89 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/v-byte-cpu/sx/command/log"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+	"github.com/v-byte-cpu/sx/pkg/scan/ftp"
+)
+
+func newFTPCmd() *ftpCmd {
+	c := &ftpCmd{}
+
+	cmd := &cobra.Command{
+		Use: "ftp [flags] [subnet]",
+		Example: strings.Join([]string{
+			"ftp -p 21 192.168.0.1/24", "ftp -p 21,2121 10.0.0.1",
+			"ftp --json --list 10 -p 21 10.0.0.1/16",
+			"ftp -f ip_ports_file.jsonl", "ftp -p 21 -f ips_file.jsonl"}, "\n"),
+		Short: "Perform FTP banner and anonymous login scan",
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer cancel()
+
+			if err = c.opts.parseRawOptions(); err != nil {
+				return
+			}
+			scanRange, err := c.opts.parseScanRange(args)
+			if err != nil {
+				return
+			}
+
+			var logger log.Logger
+			if logger, err = c.opts.getLogger(ftp.ScanType, resultWriter); err != nil {
+				return
+			}
+
+			engine := c.opts.newFTPScanEngine(ctx)
+			return c.opts.runScanEngine(ctx, cmd, engine, scanRange, logger)
+		},
+	}
+
+	c.opts.initCliFlags(cmd)
+
+	c.cmd = cmd
+	return c
+}
+
+type ftpCmd struct {
+	cmd  *cobra.Command
+	opts ftpCmdOpts
+}
+
+type ftpCmdOpts struct {
+	genericScanCmdOpts
+	timeout    time.Duration
+	listSample int
+}
+
+func (o *ftpCmdOpts) initCliFlags(cmd *cobra.Command) {
+	o.genericScanCmdOpts.initCliFlags(cmd)
+	cmd.Flags().DurationVarP(&o.timeout, "timeout", "t", 2*time.Second, "set connect and data timeout")
+	cmd.Flags().IntVar(&o.listSample, "list", 0,
+		"set number of lines of the root directory listing to read after anonymous login, 0 disables listing")
+}
+
+func (o *ftpCmdOpts) parseRawOptions() (err error) {
+	if err = o.genericScanCmdOpts.parseRawOptions(); err != nil {
+		return
+	}
+	if o.listSample < 0 {
+		return errors.New("invalid list sample: non-negative number required")
+	}
+	return
+}
+
+func (o *ftpCmdOpts) newFTPScanEngine(ctx context.Context) scan.EngineResulter {
+	return o.newScanEngine(ctx, ftp.NewScanner(
+		ftp.WithDialTimeout(o.timeout),
+		ftp.WithDataTimeout(o.timeout),
+		ftp.WithListSample(o.listSample),
+	))
+}
@@ -0,0 +1,64 @@
+package command
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFTPCmdDstSubnetError(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{
+			name: "RequiredArg",
+			args: nil,
+		},
+		{
+			name: "InvalidDstSubnet",
+			args: []string{"invalid_ip_address"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := newFTPCmd().cmd
+			err := cmd.RunE(cmd, tt.args)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestFTPCmdOptsInitCliFlags(t *testing.T) {
+	t.Parallel()
+	var opts ftpCmdOpts
+	cmd := &cobra.Command{}
+
+	opts.initCliFlags(cmd)
+	err := cmd.ParseFlags(strings.Split(
+		"--json -p 20-21 -f ip_file.jsonl -w 300 --exit-delay 10s --timeout 3s --list 5", " "))
+
+	require.NoError(t, err)
+	require.Equal(t, true, opts.json)
+	require.Equal(t, "20-21", opts.rawPortRanges)
+	require.Equal(t, "ip_file.jsonl", opts.ipFile)
+	require.Equal(t, 300, opts.workers)
+	require.Equal(t, 10*time.Second, opts.exitDelay)
+
+	require.Equal(t, 3*time.Second, opts.timeout)
+	require.Equal(t, 5, opts.listSample)
+}
+
+func TestFTPCmdOptsParseRawOptionsError(t *testing.T) {
+	t.Parallel()
+	var opts ftpCmdOpts
+	cmd := &cobra.Command{}
+
+	opts.initCliFlags(cmd)
+	require.NoError(t, cmd.ParseFlags(strings.Split("-p 21 --list -1", " ")))
+	require.Error(t, opts.parseRawOptions())
+}
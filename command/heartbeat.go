@@ -0,0 +1,59 @@
+package command
+
+import (
+	"errors"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+const defaultHeartbeatInterval = 5 * time.Second
+
+var (
+	errHeartbeatTarget   = errors.New("invalid heartbeat target: host:port required")
+	errHeartbeatInterval = errors.New("invalid heartbeat interval: positive duration required")
+)
+
+// heartbeatCmdOpts are options to pause long scans while a known-good reference target
+// doesn't respond instead of producing false negative results
+type heartbeatCmdOpts struct {
+	heartbeat         string
+	heartbeatInterval time.Duration
+}
+
+func (o *heartbeatCmdOpts) initCliFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&o.heartbeat, "heartbeat", "",
+		"set host:port of a known-good TCP service to probe during the scan, the scan is paused while it doesn't respond")
+	cmd.Flags().DurationVar(&o.heartbeatInterval, "heartbeat-interval", defaultHeartbeatInterval,
+		"set interval and timeout of heartbeat probes")
+}
+
+func (o *heartbeatCmdOpts) parseRawOptions() error {
+	if len(o.heartbeat) == 0 {
+		return nil
+	}
+	_, port, err := net.SplitHostPort(o.heartbeat)
+	if err != nil {
+		return errHeartbeatTarget
+	}
+	if n, err := strconv.ParseUint(port, 10, 16); err != nil || n == 0 {
+		return errHeartbeatTarget
+	}
+	if o.heartbeatInterval <= 0 {
+		return errHeartbeatInterval
+	}
+	return nil
+}
+
+// withHeartbeat wraps the request generator to probe the heartbeat target if it is set
+func (o *heartbeatCmdOpts) withHeartbeat(reqgen scan.RequestGenerator) scan.RequestGenerator {
+	if len(o.heartbeat) == 0 {
+		return reqgen
+	}
+	return scan.NewHeartbeatRequestGenerator(reqgen,
+		scan.NewDialProber(o.heartbeat, o.heartbeatInterval),
+		scan.WithHeartbeatInterval(o.heartbeatInterval))
+}
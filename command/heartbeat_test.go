@@ -0,0 +1,89 @@
+package command
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+func TestHeartbeatCmdOptsInitCliFlags(t *testing.T) {
+	t.Parallel()
+	cmd := &cobra.Command{}
+	var opts heartbeatCmdOpts
+	opts.initCliFlags(cmd)
+
+	require.Equal(t, defaultHeartbeatInterval, opts.heartbeatInterval)
+	require.NoError(t, cmd.ParseFlags(strings.Split("--heartbeat 10.0.0.1:443 --heartbeat-interval 2s", " ")))
+	require.NoError(t, opts.parseRawOptions())
+	require.Equal(t, "10.0.0.1:443", opts.heartbeat)
+	require.Equal(t, 2*time.Second, opts.heartbeatInterval)
+}
+
+func TestHeartbeatCmdOptsParseRawOptions(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		opts     heartbeatCmdOpts
+		expected error
+	}{
+		{
+			name: "NoHeartbeat",
+		},
+		{
+			name: "IPv6Target",
+			opts: heartbeatCmdOpts{heartbeat: "[::1]:22", heartbeatInterval: time.Second},
+		},
+		{
+			name: "HostnameTarget",
+			opts: heartbeatCmdOpts{heartbeat: "example.com:80", heartbeatInterval: time.Second},
+		},
+		{
+			name:     "NoPort",
+			opts:     heartbeatCmdOpts{heartbeat: "10.0.0.1", heartbeatInterval: time.Second},
+			expected: errHeartbeatTarget,
+		},
+		{
+			name:     "InvalidPort",
+			opts:     heartbeatCmdOpts{heartbeat: "10.0.0.1:http", heartbeatInterval: time.Second},
+			expected: errHeartbeatTarget,
+		},
+		{
+			name:     "ZeroPort",
+			opts:     heartbeatCmdOpts{heartbeat: "10.0.0.1:0", heartbeatInterval: time.Second},
+			expected: errHeartbeatTarget,
+		},
+		{
+			name:     "ZeroInterval",
+			opts:     heartbeatCmdOpts{heartbeat: "10.0.0.1:443"},
+			expected: errHeartbeatInterval,
+		},
+	}
+
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			err := tt.opts.parseRawOptions()
+			if tt.expected == nil {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorIs(t, err, tt.expected)
+		})
+	}
+}
+
+func TestHeartbeatCmdOptsWithHeartbeat(t *testing.T) {
+	t.Parallel()
+	reqgen := scan.NewIPPortGenerator(scan.NewIPGenerator(), scan.NewPortGenerator())
+
+	var opts heartbeatCmdOpts
+	require.Same(t, reqgen, opts.withHeartbeat(reqgen))
+
+	opts = heartbeatCmdOpts{heartbeat: "10.0.0.1:443", heartbeatInterval: time.Second}
+	require.NotSame(t, reqgen, opts.withHeartbeat(reqgen))
+}
@@ -0,0 +1,355 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/v-byte-cpu/sx/command/log"
+	"github.com/v-byte-cpu/sx/pkg/history"
+	"github.com/v-byte-cpu/sx/pkg/ip"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+var (
+	errHistoryFlag    = errors.New("history command reads the history file argument, --history flag is not supported")
+	errHistoryChanges = errors.New("invalid min-changes: non-negative number required")
+	errHistoryHost    = errors.New("invalid host: IP address required")
+)
+
+// historyStore is set if the history file is set, it records states of ports in each run
+var historyStore *history.Store
+
+type historyStoreCmdOpts struct {
+	// file enables recording of port state transitions of scan results to the file
+	file string
+}
+
+func (o *historyStoreCmdOpts) initCliFlags(cmd *cobra.Command) {
+	cmd.PersistentFlags().StringVar(&o.file, "history", "",
+		strings.Join([]string{"append transitions of port states in results to the file, see the history command",
+			"known ports that are scanned again without results become filtered"}, "\n"))
+}
+
+// openHistory reads the history file if it is set, transitions of the previous store are written first
+func (o *historyStoreCmdOpts) openHistory() (err error) {
+	if err = finishHistoryRun(); err != nil {
+		return
+	}
+	historyStore = nil
+	if len(o.file) > 0 {
+		historyStore, err = history.OpenStore(o.file)
+	}
+	return
+}
+
+// finishHistoryRun appends transitions of the finished run to the history file
+func finishHistoryRun() error {
+	if historyStore == nil {
+		return nil
+	}
+	return historyStore.FinishRun()
+}
+
+// withHistory records known ports scanned in the run, so that ports without results become filtered
+func withHistory(reqgen scan.RequestGenerator) scan.RequestGenerator {
+	if historyStore == nil {
+		return reqgen
+	}
+	return scan.NewObserveRequestGenerator(reqgen, func(r *scan.Request) {
+		historyStore.Probe(r.DstIP, r.DstPort)
+	})
+}
+
+// withHistoryLogger wraps the logger to record states of ports of logged results
+func withHistoryLogger(logger log.Logger) log.Logger {
+	if historyStore == nil {
+		return logger
+	}
+	return log.NewFilterLogger(logger, historyStore.Record)
+}
+
+func newHistoryCmd() *historyCmd {
+	c := &historyCmd{}
+
+	cmd := &cobra.Command{
+		Use: "history [flags] history.jsonl",
+		Example: strings.Join([]string{
+			"history history.jsonl",
+			"history --min-changes 3 -p 80,443 history.jsonl",
+			"history --events --ip 192.168.0.0/24 history.jsonl"}, "\n"),
+		Short: "Show the port state history recorded with the --history flag",
+		Long: strings.Join([]string{
+			"Show the port state history recorded with the --history flag.",
+			"Each port is summarized with its last state, the time of its last transition, the number of",
+			"state changes and the fraction of time it was open since it was first seen.",
+			"Ports with many changes are flapping services."}, "\n"),
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			if historyStore != nil {
+				return errHistoryFlag
+			}
+			if err = c.opts.parseRawOptions(); err != nil {
+				return
+			}
+			transitions, err := history.LoadFile(args[0])
+			if err != nil {
+				return
+			}
+			logger, err := newLogger(newResultWriter(resultWriter, history.ScanType, c.opts.json), history.ScanType)
+			if err != nil {
+				return
+			}
+			results := c.opts.results(transitions, time.Now())
+			return logger.LogResults(context.Background(), results)
+		},
+	}
+
+	c.opts.initCliFlags(cmd)
+	cmd.AddCommand(
+		newHistoryRunsCmd().cmd,
+		newHistoryHostCmd().cmd,
+		newHistoryDiffCmd().cmd,
+	)
+
+	c.cmd = cmd
+	return c
+}
+
+type historyCmd struct {
+	cmd  *cobra.Command
+	opts historyCmdOpts
+}
+
+type historyCmdOpts struct {
+	json       bool
+	events     bool
+	minChanges int
+	subnet     *net.IPNet
+	ports      []*scan.PortRange
+
+	rawSubnet     string
+	rawPortRanges string
+}
+
+func (o *historyCmdOpts) initCliFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&o.json, "json", false, "enable JSON output")
+	cmd.Flags().BoolVar(&o.events, "events", false, "show state transitions with their timestamps instead of summaries")
+	cmd.Flags().IntVar(&o.minChanges, "min-changes", 0, "show only ports with at least the number of state changes")
+	cmd.Flags().StringVar(&o.rawSubnet, "ip", "", "show only ports of IPs of the subnet, e.g. 192.168.0.0/24")
+	cmd.Flags().StringVarP(&o.rawPortRanges, "ports", "p", "", "show only ports of the ranges, e.g. 22,80-90")
+}
+
+func (o *historyCmdOpts) parseRawOptions() (err error) {
+	if o.minChanges < 0 {
+		return errHistoryChanges
+	}
+	if len(o.rawSubnet) > 0 {
+		if o.subnet, err = ip.ParseIPNet(o.rawSubnet); err != nil {
+			return
+		}
+	}
+	if len(o.rawPortRanges) > 0 {
+		if o.ports, err = parsePortRanges(o.rawPortRanges); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// results returns summaries or transitions of selected ports
+func (o *historyCmdOpts) results(transitions []*history.Transition, now time.Time) <-chan scan.Result {
+	var selected []*history.Transition
+	for _, t := range transitions {
+		if o.selects(t.IP, t.Port) {
+			selected = append(selected, t)
+		}
+	}
+	summaries := history.Summarize(selected, now)
+	changes := make(map[string]int, len(summaries))
+	for _, s := range summaries {
+		changes[s.ID()] = s.Changes
+	}
+
+	results := make(chan scan.Result, len(selected))
+	defer close(results)
+	if o.events {
+		for _, t := range selected {
+			if changes[net.JoinHostPort(t.IP, strconv.Itoa(int(t.Port)))] >= o.minChanges {
+				results <- t
+			}
+		}
+		return results
+	}
+	for _, s := range summaries {
+		if s.Changes >= o.minChanges {
+			results <- s
+		}
+	}
+	return results
+}
+
+func (o *historyCmdOpts) selects(ipAddr string, port uint16) bool {
+	if o.subnet != nil && !o.subnet.Contains(net.ParseIP(ipAddr)) {
+		return false
+	}
+	if len(o.ports) == 0 {
+		return true
+	}
+	for _, r := range o.ports {
+		if r.StartPort <= port && port <= r.EndPort {
+			return true
+		}
+	}
+	return false
+}
+
+func newHistoryRunsCmd() *historyQueryCmd {
+	c := &historyQueryCmd{}
+
+	cmd := &cobra.Command{
+		Use:     "runs [flags] history.jsonl",
+		Example: "history runs history.jsonl",
+		Short:   "List runs of the history with the number of their changes and open ports",
+		Long: strings.Join([]string{
+			"List runs of the history with the number of their changes and open ports after them.",
+			"Runs are numbered from 1, only runs that changed states of ports are stored."}, "\n"),
+		Args: cobra.ExactArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			return c.opts.logResults(args[0], func(transitions []*history.Transition) ([]scan.Result, error) {
+				runs := history.ListRuns(transitions)
+				results := make([]scan.Result, 0, len(runs))
+				for _, run := range runs {
+					results = append(results, run)
+				}
+				return results, nil
+			})
+		},
+	}
+
+	c.opts.initCliFlags(cmd)
+
+	c.cmd = cmd
+	return c
+}
+
+func newHistoryHostCmd() *historyQueryCmd {
+	c := &historyQueryCmd{}
+
+	cmd := &cobra.Command{
+		Use:     "host [flags] ip history.jsonl",
+		Example: "history host 192.168.0.3 history.jsonl",
+		Short:   "Show state transitions of ports of the host",
+		Args:    cobra.ExactArgs(2),
+		RunE: func(_ *cobra.Command, args []string) error {
+			host := net.ParseIP(args[0])
+			if host == nil {
+				return errHistoryHost
+			}
+			return c.opts.logResults(args[1], func(transitions []*history.Transition) ([]scan.Result, error) {
+				var results []scan.Result
+				for _, t := range history.ResultsForHost(transitions, host) {
+					results = append(results, t)
+				}
+				return results, nil
+			})
+		},
+	}
+
+	c.opts.initCliFlags(cmd)
+
+	c.cmd = cmd
+	return c
+}
+
+func newHistoryDiffCmd() *historyQueryCmd {
+	c := &historyQueryCmd{}
+
+	cmd := &cobra.Command{
+		Use:     "diff [flags] run-a run-b history.jsonl",
+		Example: strings.Join([]string{"history diff 1 5 history.jsonl", "history diff --json 4 5 history.jsonl"}, "\n"),
+		Short:   "Show ports which states differ between two runs of the history",
+		Long: strings.Join([]string{
+			"Show ports which states after the run run-a differ from their states after the run run-b.",
+			"Runs are numbers listed by the history runs command, the state of ports unknown in a run is empty."}, "\n"),
+		Args: cobra.ExactArgs(3),
+		RunE: func(_ *cobra.Command, args []string) error {
+			runA, err := strconv.Atoi(args[0])
+			if err != nil {
+				return history.ErrRun
+			}
+			runB, err := strconv.Atoi(args[1])
+			if err != nil {
+				return history.ErrRun
+			}
+			return c.opts.logResults(args[2], func(transitions []*history.Transition) ([]scan.Result, error) {
+				changes, err := history.ChangesBetween(transitions, runA, runB)
+				if err != nil {
+					return nil, err
+				}
+				results := make([]scan.Result, 0, len(changes))
+				for _, change := range changes {
+					results = append(results, change)
+				}
+				return results, nil
+			})
+		},
+	}
+
+	c.opts.initCliFlags(cmd)
+
+	c.cmd = cmd
+	return c
+}
+
+type historyQueryCmd struct {
+	cmd  *cobra.Command
+	opts historyQueryCmdOpts
+}
+
+type historyQueryCmdOpts struct {
+	json bool
+}
+
+func (o *historyQueryCmdOpts) initCliFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&o.json, "json", false, "enable JSON output")
+}
+
+// logResults writes results of the query of transitions of the history file
+func (o *historyQueryCmdOpts) logResults(path string,
+	query func(transitions []*history.Transition) ([]scan.Result, error)) (err error) {
+	if historyStore != nil {
+		return errHistoryFlag
+	}
+	transitions, err := history.LoadFile(path)
+	if err != nil {
+		return
+	}
+	queryResults, err := query(transitions)
+	if err != nil {
+		return
+	}
+	logger, err := newLogger(newResultWriter(resultWriter, history.ScanType, o.json), history.ScanType)
+	if err != nil {
+		return
+	}
+	results := make(chan scan.Result, len(queryResults))
+	for _, result := range queryResults {
+		results <- result
+	}
+	close(results)
+	return logger.LogResults(context.Background(), results)
+}
+
+// writeHistoryError reports errors of the history file that don't stop the scan
+func writeHistoryError(err error) {
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Error: history:", err)
+	}
+}
@@ -0,0 +1,180 @@
+package command
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+	"github.com/v-byte-cpu/sx/pkg/history"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+	"github.com/v-byte-cpu/sx/pkg/scan/tcp"
+)
+
+func resultsToSlice(results <-chan scan.Result) (slice []scan.Result) {
+	for result := range results {
+		slice = append(slice, result)
+	}
+	return
+}
+
+func TestHistoryCmdOptsInitCliFlags(t *testing.T) {
+	t.Parallel()
+	var opts historyCmdOpts
+	cmd := &cobra.Command{}
+
+	opts.initCliFlags(cmd)
+	err := cmd.ParseFlags(strings.Split("--json --events --min-changes 2 --ip 192.168.0.0/24 -p 22,80-90", " "))
+
+	require.NoError(t, err)
+	require.NoError(t, opts.parseRawOptions())
+	require.True(t, opts.json)
+	require.True(t, opts.events)
+	require.Equal(t, 2, opts.minChanges)
+	require.Equal(t, "192.168.0.0/24", opts.subnet.String())
+	require.Equal(t, []*scan.PortRange{{StartPort: 22, EndPort: 22}, {StartPort: 80, EndPort: 90}}, opts.ports)
+}
+
+func TestHistoryCmdOptsParseRawOptionsError(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		opts historyCmdOpts
+	}{
+		{name: "MinChanges", opts: historyCmdOpts{minChanges: -1}},
+		{name: "Subnet", opts: historyCmdOpts{rawSubnet: "invalid"}},
+		{name: "Ports", opts: historyCmdOpts{rawPortRanges: "70000"}},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			require.Error(t, tt.opts.parseRawOptions())
+		})
+	}
+}
+
+func TestHistoryCmdOptsResults(t *testing.T) {
+	t.Parallel()
+	start := time.Date(2021, 5, 1, 10, 0, 0, 0, time.UTC)
+	transitions := []*history.Transition{
+		{ScanType: history.ScanType, IP: "192.168.0.3", Port: 22, State: history.Open, Time: start},
+		{ScanType: history.ScanType, IP: "192.168.0.3", Port: 80, State: history.Open, Time: start},
+		{ScanType: history.ScanType, IP: "10.0.0.1", Port: 80, State: history.Open, Time: start},
+		{ScanType: history.ScanType, IP: "192.168.0.3", Port: 80, State: history.Filtered, Time: start.Add(time.Hour)},
+	}
+	opts := historyCmdOpts{minChanges: 1, rawSubnet: "192.168.0.0/24"}
+	require.NoError(t, opts.parseRawOptions())
+
+	results := resultsToSlice(opts.results(transitions, start.Add(2*time.Hour)))
+	require.Len(t, results, 1)
+	require.Equal(t, &history.Summary{ScanType: history.ScanType, IP: "192.168.0.3", Port: 80,
+		State: history.Filtered, Since: start.Add(time.Hour), FirstSeen: start, Changes: 1, Uptime: 0.5}, results[0])
+
+	opts.events = true
+	results = resultsToSlice(opts.results(transitions, start.Add(2*time.Hour)))
+	require.Equal(t, []scan.Result{transitions[1], transitions[3]}, results)
+}
+
+// TestHistory is not parallel because it changes the global history store and the result writer
+func TestHistory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	opts := historyStoreCmdOpts{file: path}
+	defer func() {
+		require.NoError(t, (&historyStoreCmdOpts{}).openHistory())
+	}()
+	require.NoError(t, opts.openHistory())
+
+	logger, err := newLogger(newResultWriter(&bytes.Buffer{}, tcp.SYNScanType, true), tcp.SYNScanType)
+	require.NoError(t, err)
+	results := make(chan scan.Result, 1)
+	results <- &tcp.ScanResult{ScanType: tcp.SYNScanType, IP: "192.168.0.3", Port: 22, Flags: "sa"}
+	close(results)
+	require.NoError(t, logger.LogResults(context.Background(), results))
+	// transitions are written when the next store is opened
+	require.NoError(t, (&historyStoreCmdOpts{}).openHistory())
+	require.Nil(t, historyStore)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Contains(t, string(data), `"ip":"192.168.0.3","port":22,"state":"open"`)
+
+	var out bytes.Buffer
+	prevWriter := resultWriter
+	resultWriter = &out
+	defer func() {
+		resultWriter = prevWriter
+	}()
+	cmd := newHistoryCmd().cmd
+	require.NoError(t, cmd.ParseFlags([]string{"--json"}))
+	require.NoError(t, cmd.RunE(cmd, []string{path}))
+	require.Contains(t, out.String(), `{"scan":"history","ip":"192.168.0.3","port":22,"state":"open"`)
+	require.Contains(t, out.String(), `"changes":0,"uptime":1}`)
+
+	// the history command doesn't record its own results
+	require.NoError(t, opts.openHistory())
+	require.ErrorIs(t, cmd.RunE(cmd, []string{path}), errHistoryFlag)
+}
+
+// TestHistoryQueryCmds is not parallel because it changes the result writer
+func TestHistoryQueryCmds(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	require.NoError(t, os.WriteFile(path, []byte(strings.Join([]string{
+		`{"scan":"history","ip":"192.168.0.3","port":22,"state":"open","time":"2021-05-01T10:00:00Z"}`,
+		`{"scan":"history","ip":"192.168.0.5","port":80,"state":"open","time":"2021-05-01T10:00:00Z"}`,
+		`{"scan":"history","ip":"192.168.0.3","port":22,"state":"filtered","time":"2021-05-01T11:00:00Z"}`,
+	}, "\n")), 0o600))
+
+	var out bytes.Buffer
+	prevWriter := resultWriter
+	resultWriter = &out
+	defer func() {
+		resultWriter = prevWriter
+	}()
+
+	tests := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{
+			name: "Runs",
+			args: []string{"runs", "--json", path},
+			expected: `{"scan":"history","run":1,"time":"2021-05-01T10:00:00Z","changes":2,"open":2}` + "\n" +
+				`{"scan":"history","run":2,"time":"2021-05-01T11:00:00Z","changes":1,"open":1}` + "\n",
+		},
+		{
+			name: "Host",
+			args: []string{"host", "192.168.0.3", path},
+			expected: "192.168.0.3          22    open     2021-05-01T10:00:00Z\n" +
+				"192.168.0.3          22    filtered 2021-05-01T11:00:00Z\n",
+		},
+		{
+			name:     "Diff",
+			args:     []string{"diff", "--json", "1", "2", path},
+			expected: `{"scan":"history","ip":"192.168.0.3","port":22,"from":"open","to":"filtered"}` + "\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out.Reset()
+			cmd := newHistoryCmd().cmd
+			cmd.SetArgs(tt.args)
+			require.NoError(t, cmd.Execute())
+			require.Equal(t, tt.expected, out.String())
+		})
+	}
+
+	for _, args := range [][]string{{"host", "host.local", path}, {"diff", "1", "3", path}, {"diff", "one", "2", path}} {
+		cmd := newHistoryCmd().cmd
+		cmd.SetArgs(args)
+		cmd.SetOut(&bytes.Buffer{})
+		cmd.SetErr(&bytes.Buffer{})
+		require.Error(t, cmd.Execute(), args)
+	}
+}
@@ -0,0 +1,144 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/v-byte-cpu/sx/command/log"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+	"github.com/v-byte-cpu/sx/pkg/scan/http"
+)
+
+func newHTTPCmd() *httpCmd {
+	c := &httpCmd{}
+
+	cmd := &cobra.Command{
+		Use: "http [flags] [subnet]",
+		Example: strings.Join([]string{
+			"http -p 80 192.168.0.1/24", "http -p 80,8080 10.0.0.1",
+			"http --proto https -p 443 192.168.0.3",
+			"http --favicon -p 80 10.0.0.1/16",
+			"http --method HEAD --max-redirects 3 -p 80 10.0.0.1/16",
+			"http --probe-paths --host-budget 5 -p 80,8080 10.0.0.1/16",
+			"http --paths /robots.txt,/.env -p 80 10.0.0.1/16",
+			"http -f ip_ports_file.jsonl", "http -p 80-90 -f ips_file.jsonl"}, "\n"),
+		Short: "Perform HTTP scan",
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer cancel()
+
+			if cmd.Flags().Changed("paths") {
+				c.opts.probePaths = true
+			}
+			if err = c.opts.parseRawOptions(); err != nil {
+				return
+			}
+			scanRange, err := c.opts.parseScanRange(args)
+			if err != nil {
+				return
+			}
+
+			var logger log.Logger
+			if logger, err = c.opts.getLogger(http.ScanType, resultWriter); err != nil {
+				return
+			}
+
+			engine := c.opts.newHTTPScanEngine(ctx)
+			return c.opts.runScanEngine(ctx, cmd, engine, scanRange, logger)
+		},
+	}
+
+	c.opts.initCliFlags(cmd)
+
+	c.cmd = cmd
+	return c
+}
+
+type httpCmd struct {
+	cmd  *cobra.Command
+	opts httpCmdOpts
+}
+
+type httpCmdOpts struct {
+	genericScanCmdOpts
+	timeout      time.Duration
+	proto        string
+	method       string
+	maxRedirects int
+	favicon      bool
+	probePaths   bool
+	paths        []string
+	hostBudget   int
+	keepAlive    time.Duration
+}
+
+func (o *httpCmdOpts) initCliFlags(cmd *cobra.Command) {
+	o.genericScanCmdOpts.initCliFlags(cmd)
+	cmd.Flags().DurationVarP(&o.timeout, "timeout", "t", defaultTimeout, "set request timeout")
+	cmd.Flags().StringVar(&o.proto, "proto", cliHTTPProtoFlag, "set protocol to use, only http or https are valid")
+	cmd.Flags().StringVar(&o.method, "method", "GET", "set HTTP method of the request to each target")
+	cmd.Flags().IntVar(&o.maxRedirects, "max-redirects", 0,
+		strings.Join([]string{"set maximum number of redirects to follow",
+			"0 means redirects are not followed and the redirect response is recorded"}, "\n"))
+	cmd.Flags().BoolVar(&o.favicon, "favicon", false,
+		strings.Join([]string{"fetch /favicon.ico and compute its hash",
+			"the hash is compatible with the http.favicon.hash Shodan filter"}, "\n"))
+	cmd.Flags().BoolVar(&o.probePaths, "probe-paths", false, "probe paths from the --paths list and record their status codes")
+	cmd.Flags().StringSliceVar(&o.paths, "paths", http.DefaultPaths,
+		strings.Join([]string{"set comma-separated list of paths to probe", "enables path probing"}, "\n"))
+	cmd.Flags().IntVar(&o.hostBudget, "host-budget", 10,
+		strings.Join([]string{"set maximum number of additional HTTP requests (favicon and path probes) sent to one IP address across all ports",
+			"0 means no limit"}, "\n"))
+	cmd.Flags().DurationVar(&o.keepAlive, "keep-alive", 0,
+		strings.Join([]string{"reuse connections to the same host:port for favicon and path probes",
+			"idle connections are closed after the duration, 0 disables keep-alive"}, "\n"))
+}
+
+func (o *httpCmdOpts) parseRawOptions() (err error) {
+	if err = o.genericScanCmdOpts.parseRawOptions(); err != nil {
+		return
+	}
+	if o.proto != cliHTTPProtoFlag && o.proto != cliHTTPSProtoFlag {
+		return errors.New("invalid HTTP proto flag: http or https required")
+	}
+	if len(o.method) == 0 || strings.ContainsAny(o.method, " \t\r\n") {
+		return errors.New("invalid HTTP method")
+	}
+	if o.maxRedirects < 0 {
+		return errors.New("invalid max redirects: non-negative number required")
+	}
+	if o.hostBudget < 0 {
+		return errors.New("invalid host budget: non-negative number required")
+	}
+	if o.keepAlive < 0 {
+		return errors.New("invalid keep-alive: non-negative duration required")
+	}
+	for _, path := range o.paths {
+		if !strings.HasPrefix(path, "/") {
+			return errors.New("invalid path: must start with /")
+		}
+	}
+	return
+}
+
+func (o *httpCmdOpts) newHTTPScanEngine(ctx context.Context) scan.EngineResulter {
+	opts := []http.ScannerOption{
+		http.WithDataTimeout(o.timeout),
+		http.WithMethod(o.method),
+		http.WithMaxRedirects(o.maxRedirects),
+		http.WithFavicon(o.favicon),
+		http.WithHostBudget(o.hostBudget),
+		http.WithKeepAlive(o.keepAlive),
+		http.WithLookupIP(lookupIP),
+	}
+	if o.probePaths {
+		opts = append(opts, http.WithPaths(o.paths))
+	}
+	scanner := http.NewScanner(o.proto, opts...)
+	return o.newScanEngine(ctx, scanner)
+}
@@ -0,0 +1,127 @@
+package command
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+func TestHTTPCmdDstSubnetError(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{
+			name: "RequiredArg",
+			args: nil,
+		},
+		{
+			name: "InvalidDstSubnet",
+			args: []string{"invalid_ip_address"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := newHTTPCmd().cmd
+			err := cmd.RunE(cmd, tt.args)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestHTTPCmdOptsInitCliFlags(t *testing.T) {
+	t.Parallel()
+	var opts httpCmdOpts
+	cmd := &cobra.Command{}
+
+	opts.initCliFlags(cmd)
+	err := cmd.ParseFlags(strings.Split(
+		"--json -p 23-57,71-2733 -f ip_file.jsonl -w 300 --exit-delay 10s --timeout 2s --proto https --method HEAD --max-redirects 3 --favicon "+
+			"--probe-paths --paths /robots.txt,/.env --host-budget 5 --keep-alive 30s", " "))
+
+	require.NoError(t, err)
+	require.Equal(t, true, opts.json)
+	require.Equal(t, "23-57,71-2733", opts.rawPortRanges)
+	require.Equal(t, "ip_file.jsonl", opts.ipFile)
+	require.Equal(t, 300, opts.workers)
+	require.Equal(t, 10*time.Second, opts.exitDelay)
+
+	require.Equal(t, 2*time.Second, opts.timeout)
+	require.Equal(t, "https", opts.proto)
+	require.Equal(t, "HEAD", opts.method)
+	require.Equal(t, 3, opts.maxRedirects)
+	require.Equal(t, true, opts.favicon)
+	require.Equal(t, true, opts.probePaths)
+	require.Equal(t, []string{"/robots.txt", "/.env"}, opts.paths)
+	require.Equal(t, 5, opts.hostBudget)
+	require.Equal(t, 30*time.Second, opts.keepAlive)
+}
+
+func TestHTTPCmdOptsParseRawOptions(t *testing.T) {
+	t.Parallel()
+	opts := httpCmdOpts{
+		genericScanCmdOpts: genericScanCmdOpts{
+			rawPortRanges: "80,8080",
+			workers:       300,
+		},
+		proto:  "http",
+		method: "GET",
+	}
+
+	err := opts.parseRawOptions()
+
+	require.NoError(t, err)
+	require.Equal(t, []*scan.PortRange{
+		{StartPort: 80, EndPort: 80},
+		{StartPort: 8080, EndPort: 8080}}, opts.portRanges)
+}
+
+func TestHTTPCmdOptsParseRawOptionsError(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		opts httpCmdOpts
+	}{
+		{
+			name: "InvalidProto",
+			opts: httpCmdOpts{proto: "ftp"},
+		},
+		{
+			name: "EmptyMethod",
+			opts: httpCmdOpts{proto: "http"},
+		},
+		{
+			name: "InvalidMethod",
+			opts: httpCmdOpts{proto: "http", method: "GET /"},
+		},
+		{
+			name: "InvalidMaxRedirects",
+			opts: httpCmdOpts{proto: "http", method: "GET", maxRedirects: -1},
+		},
+		{
+			name: "InvalidHostBudget",
+			opts: httpCmdOpts{proto: "http", method: "GET", hostBudget: -1},
+		},
+		{
+			name: "InvalidKeepAlive",
+			opts: httpCmdOpts{proto: "http", method: "GET", keepAlive: -time.Second},
+		},
+		{
+			name: "InvalidPath",
+			opts: httpCmdOpts{proto: "http", method: "GET", paths: []string{"robots.txt"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := tt.opts
+			opts.genericScanCmdOpts = genericScanCmdOpts{rawPortRanges: "80", workers: 300}
+			err := opts.parseRawOptions()
+			require.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/v-byte-cpu/sx/command/log"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+	"github.com/v-byte-cpu/sx/pkg/scan/httpproxy"
+)
+
+func newHTTPProxyCmd() *httpProxyCmd {
+	c := &httpProxyCmd{}
+
+	cmd := &cobra.Command{
+		Use: "http-proxy [flags] subnet",
+		Example: strings.Join([]string{
+			"http-proxy -p 3128,8080 192.168.0.1/24", "http-proxy --get -p 8080 10.0.0.1",
+			"http-proxy --check-url https://example.com/ --check-string 'Example Domain' -p 3128 10.0.0.1/16",
+			"http-proxy -f ip_ports_file.jsonl", "http-proxy -p 3128-3130 -f ips_file.jsonl"}, "\n"),
+		Short: "Perform HTTP proxy scan",
+		Long: strings.Join([]string{"Perform HTTP proxy scan.",
+			"Open proxies are detected by requesting the check URL through CONNECT tunnel",
+			"and optionally with GET request, which also reveals the anonymity level of the proxy"}, " "),
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer cancel()
+
+			if err = c.opts.parseRawOptions(); err != nil {
+				return
+			}
+			scanRange, err := c.opts.parseScanRange(args)
+			if err != nil {
+				return
+			}
+
+			var logger log.Logger
+			if logger, err = c.opts.getLogger(httpproxy.ScanType, resultWriter); err != nil {
+				return
+			}
+
+			engine := c.opts.newHTTPProxyScanEngine(ctx)
+			return c.opts.runScanEngine(ctx, cmd, engine, scanRange, logger)
+		},
+	}
+
+	c.opts.initCliFlags(cmd)
+
+	c.cmd = cmd
+	return c
+}
+
+type httpProxyCmd struct {
+	cmd  *cobra.Command
+	opts httpProxyCmdOpts
+}
+
+type httpProxyCmdOpts struct {
+	proxyScanCmdOpts
+	timeout     time.Duration
+	get         bool
+	checkString string
+	checkURL    *url.URL
+
+	rawCheckURL string
+}
+
+func (o *httpProxyCmdOpts) initCliFlags(cmd *cobra.Command) {
+	o.proxyScanCmdOpts.initCliFlags(cmd)
+	cmd.Flags().DurationVarP(&o.timeout, "timeout", "t", 2*time.Second, "set connect and data timeout")
+	cmd.Flags().BoolVar(&o.get, "get", false,
+		strings.Join([]string{"also send GET request with the absolute check URL and detect the anonymity level",
+			"only http check URLs are supported"}, "\n"))
+	cmd.Flags().StringVar(&o.rawCheckURL, "check-url", httpproxy.DefaultCheckURL,
+		strings.Join([]string{"set http or https URL requested through the proxy",
+			"the URL should echo request headers to detect the anonymity level"}, "\n"))
+	cmd.Flags().StringVar(&o.checkString, "check-string", "", "set string that must be present in the check URL response")
+}
+
+func (o *httpProxyCmdOpts) parseRawOptions() (err error) {
+	if err = o.proxyScanCmdOpts.parseRawOptions(); err != nil {
+		return
+	}
+	if o.checkURL, err = url.Parse(o.rawCheckURL); err != nil {
+		return
+	}
+	if (o.checkURL.Scheme != "http" && o.checkURL.Scheme != "https") || len(o.checkURL.Host) == 0 {
+		return errors.New("invalid check URL: http or https URL required")
+	}
+	if o.get && o.checkURL.Scheme != "http" {
+		return errors.New("invalid get: http check URL required")
+	}
+	return
+}
+
+func (o *httpProxyCmdOpts) newHTTPProxyScanEngine(ctx context.Context) scan.EngineResulter {
+	scanner := httpproxy.NewScanner(
+		httpproxy.WithDialTimeout(o.timeout),
+		httpproxy.WithDataTimeout(o.timeout),
+		httpproxy.WithCheckURL(o.checkURL),
+		httpproxy.WithCheckString(o.checkString),
+		httpproxy.WithGET(o.get))
+	return o.newScanEngine(ctx, scanner)
+}
@@ -0,0 +1,112 @@
+package command
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPProxyCmdDstSubnetError(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{
+			name: "RequiredArg",
+			args: nil,
+		},
+		{
+			name: "InvalidDstSubnet",
+			args: []string{"invalid_ip_address"},
+		},
+	}
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			cmd := newHTTPProxyCmd().cmd
+			err := cmd.RunE(cmd, tt.args)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestHTTPProxyCmdOptsInitCliFlags(t *testing.T) {
+	t.Parallel()
+	var opts httpProxyCmdOpts
+	cmd := &cobra.Command{}
+
+	opts.initCliFlags(cmd)
+	err := cmd.ParseFlags(strings.Split(
+		"--json -p 3128,8080 -f ip_file.jsonl -w 300 --exit-delay 10s --timeout 3s "+
+			"--get --check-url http://10.0.0.1/headers --check-string ok", " "))
+
+	require.NoError(t, err)
+	require.Equal(t, true, opts.json)
+	require.Equal(t, "3128,8080", opts.rawPortRanges)
+	require.Equal(t, "ip_file.jsonl", opts.ipFile)
+	require.Equal(t, 300, opts.workers)
+	require.Equal(t, 10*time.Second, opts.exitDelay)
+
+	require.Equal(t, 3*time.Second, opts.timeout)
+	require.True(t, opts.get)
+	require.Equal(t, "http://10.0.0.1/headers", opts.rawCheckURL)
+	require.Equal(t, "ok", opts.checkString)
+}
+
+func TestHTTPProxyCmdOptsParseCheckURL(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		args []string
+		err  bool
+	}{
+		{
+			name: "Default",
+		},
+		{
+			name: "HTTPS",
+			args: []string{"--check-url", "https://example.com/"},
+		},
+		{
+			name: "GetHTTP",
+			args: []string{"--get", "--check-url", "http://example.com/"},
+		},
+		{
+			name: "GetHTTPS",
+			args: []string{"--get", "--check-url", "https://example.com/"},
+			err:  true,
+		},
+		{
+			name: "InvalidScheme",
+			args: []string{"--check-url", "ftp://example.com/"},
+			err:  true,
+		},
+		{
+			name: "NoHost",
+			args: []string{"--check-url", "example.com"},
+			err:  true,
+		},
+	}
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var opts httpProxyCmdOpts
+			cmd := &cobra.Command{}
+			opts.initCliFlags(cmd)
+			require.NoError(t, cmd.ParseFlags(append([]string{"-p", "3128"}, tt.args...)))
+
+			err := opts.parseRawOptions()
+			if tt.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
@@ -7,10 +7,10 @@ import (
 	"os/signal"
 	"runtime"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/v-byte-cpu/sx/pkg/scan"
-	"github.com/v-byte-cpu/sx/pkg/scan/arp"
 	"github.com/v-byte-cpu/sx/pkg/scan/icmp"
 )
 
@@ -23,6 +23,7 @@ func newICMPCmd() *icmpCmd {
 			"icmp 192.168.0.1/24",
 			"icmp --ttl 37 192.168.0.1/24",
 			"icmp --ipproto 157 192.168.0.1/24",
+			"icmp --probes echo,timestamp,address-mask 10.0.0.0/16",
 			`icmp --type 13 --code 0 --payload '\x01\x02\x03' 10.0.0.1`}, "\n"),
 		Short: "Perform ICMP scan",
 		RunE: func(cmd *cobra.Command, args []string) (err error) {
@@ -43,6 +44,7 @@ func newICMPCmd() *icmpCmd {
 				withPacketBPFFilter(icmp.BPFFilter),
 				withRateCount(c.opts.rateCount),
 				withRateWindow(c.opts.rateWindow),
+				withPacketSampling(c.opts.sampleCmdOpts),
 				withPacketVPNmode(c.opts.vpnMode),
 				withPacketEngineConfig(newEngineConfig(
 					withLogger(c.opts.logger),
@@ -75,8 +77,12 @@ type icmpCmdOpts struct {
 	icmpCode    uint8
 	icmpPayload []byte
 
+	probes        []uint8
+	probeInterval time.Duration
+
 	rawIPFlags     string
 	rawICMPPayload string
+	rawProbes      string
 }
 
 func (o *icmpCmdOpts) initCliFlags(cmd *cobra.Command) {
@@ -93,6 +99,13 @@ func (o *icmpCmdOpts) initCliFlags(cmd *cobra.Command) {
 	cmd.Flags().Uint8VarP(&o.icmpCode, "code", "c", 0, "set ICMP code of generated packet")
 	cmd.Flags().StringVarP(&o.rawICMPPayload, "payload", "p", "",
 		strings.Join([]string{"set byte payload of generated packet", "48 random bytes by default"}, "\n"))
+	cmd.Flags().StringVar(&o.rawProbes, "probes", "",
+		strings.Join([]string{
+			"set comma-separated ICMP probe types to send to each host in rounds instead of --type,",
+			"hosts that answered any probe are not probed in the next rounds",
+			"available probes: " + strings.Join(icmp.ProbeNames(), ", ")}, "\n"))
+	cmd.Flags().DurationVar(&o.probeInterval, "probe-interval", 1*time.Second,
+		"set time to wait for replies before the next probe round")
 }
 
 func (o *icmpCmdOpts) parseRawOptions() (err error) {
@@ -109,6 +122,11 @@ func (o *icmpCmdOpts) parseRawOptions() (err error) {
 			return
 		}
 	}
+	if len(o.rawProbes) > 0 {
+		if o.probes, err = icmp.ParseProbes(o.rawProbes); err != nil {
+			return
+		}
+	}
 	return
 }
 
@@ -123,13 +141,20 @@ func (o *icmpCmdOpts) newICMPScanMethod(ctx context.Context) *icmp.ScanMethod {
 	if o.excludeIPs != nil {
 		reqgen = scan.NewFilterIPRequestGenerator(reqgen, o.excludeIPs)
 	}
-	if o.cache != nil {
-		reqgen = arp.NewCacheRequestGenerator(reqgen, o.gatewayMAC, o.cache)
+	reqgen = o.withScope(reqgen)
+	reqgen = o.withDstMAC(reqgen)
+	fillerOpts := o.getICMPOptions()
+	var processorOpts []icmp.PacketProcessorOption
+	if len(o.probes) > 0 {
+		hosts := icmp.NewLiveHosts()
+		reqgen = icmp.NewMultiProbeRequestGenerator(reqgen, o.probes, hosts, icmp.WithProbeInterval(o.probeInterval))
+		fillerOpts = append(fillerOpts, icmp.WithRequestType())
+		processorOpts = append(processorOpts, icmp.WithReplyObserver(hosts))
 	}
-	pktgen := scan.NewPacketMultiGenerator(icmp.NewPacketFiller(o.getICMPOptions()...), runtime.NumCPU())
-	psrc := scan.NewPacketSource(reqgen, pktgen)
+	pktgen := scan.NewPacketMultiGenerator(icmp.NewPacketFiller(fillerOpts...), runtime.NumCPU())
+	psrc := scan.NewPacketSource(o.withHeartbeat(reqgen), pktgen)
 	results := scan.NewResultChan(ctx, 1000)
-	return icmp.NewScanMethod(psrc, results, o.vpnMode)
+	return icmp.NewScanMethod(psrc, results, o.vpnMode, processorOpts...)
 }
 
 func (o *icmpCmdOpts) getICMPOptions() (opts []icmp.PacketFillerOption) {
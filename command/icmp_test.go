@@ -9,6 +9,7 @@ import (
 	"github.com/google/gopacket/layers"
 	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/require"
+	"github.com/v-byte-cpu/sx/pkg/scan/icmp"
 )
 
 func TestICMPCmdDstSubnetError(t *testing.T) {
@@ -46,6 +47,7 @@ func TestICMPCmdOptsInitCliFlags(t *testing.T) {
 			"--json -i eth0 --srcip 192.168.0.1 --srcmac 00:11:22:33:44:55 -r 500/7s --exit-delay 10s",
 			"--gwmac 11:22:33:44:55:66 -f ip_file.jsonl -a arp.cache",
 			`--ttl 128 --ipproto 6 --iplen 11 --ipflags df,mf --type 3 --code 5 --payload \x01\x02\x03`,
+			"--probes echo,timestamp --probe-interval 3s",
 		}, " "), " "))
 
 	require.NoError(t, err)
@@ -67,6 +69,8 @@ func TestICMPCmdOptsInitCliFlags(t *testing.T) {
 	require.Equal(t, uint8(3), opts.icmpType)
 	require.Equal(t, uint8(5), opts.icmpCode)
 	require.Equal(t, `\x01\x02\x03`, opts.rawICMPPayload)
+	require.Equal(t, "echo,timestamp", opts.rawProbes)
+	require.Equal(t, 3*time.Second, opts.probeInterval)
 }
 
 func TestICMPCmdOptsParseRawOptions(t *testing.T) {
@@ -81,6 +85,7 @@ func TestICMPCmdOptsParseRawOptions(t *testing.T) {
 		},
 		rawIPFlags:     "df,mf",
 		rawICMPPayload: `\x01\x02\x03`,
+		rawProbes:      "echo,address-mask",
 	}
 
 	err := opts.parseRawOptions()
@@ -94,4 +99,14 @@ func TestICMPCmdOptsParseRawOptions(t *testing.T) {
 
 	require.Equal(t, uint8(layers.IPv4DontFragment)|uint8(layers.IPv4MoreFragments), opts.ipFlags)
 	require.Equal(t, []byte{1, 2, 3}, opts.icmpPayload)
+	require.Equal(t, []uint8{layers.ICMPv4TypeEchoRequest, layers.ICMPv4TypeAddressMaskRequest}, opts.probes)
+}
+
+func TestICMPCmdOptsParseRawOptionsInvalidProbes(t *testing.T) {
+	t.Parallel()
+	opts := &icmpCmdOpts{rawProbes: "echo,syn"}
+
+	err := opts.parseRawOptions()
+
+	require.ErrorIs(t, err, icmp.ErrProbe)
 }
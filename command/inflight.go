@@ -0,0 +1,111 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sync"
+
+	"github.com/spf13/cobra"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+// reservedFileCount is the number of file descriptors left for files, pcap handles and DNS lookups
+const reservedFileCount = 64
+
+var errMaxInFlight = errors.New("invalid max in-flight requests: non-negative number required")
+
+// inFlightLimiter is shared by all scanners of the running command, it is created by the first scanner
+var (
+	inFlightOnce    sync.Once
+	inFlightLimiter *scan.InFlightLimiter
+)
+
+// initInFlightLimiter discards the limiter of the previous command run, e.g. of the previous workflow stage,
+// so that the limit and statistics are of the running command
+func initInFlightLimiter() {
+	inFlightOnce = sync.Once{}
+	inFlightLimiter = nil
+}
+
+// inFlightCmdOpts are options to cap the number of simultaneous requests of connect scanners,
+// so that large worker counts don't exhaust file descriptors of the process
+type inFlightCmdOpts struct {
+	maxInFlight int
+}
+
+func (o *inFlightCmdOpts) initCliFlags(cmd *cobra.Command) {
+	cmd.Flags().IntVar(&o.maxInFlight, "max-in-flight", 0,
+		fmt.Sprintf("set maximum number of simultaneous requests shared by all scanners, 0 means RLIMIT_NOFILE minus %d", reservedFileCount))
+}
+
+func (o *inFlightCmdOpts) parseRawOptions() error {
+	if o.maxInFlight < 0 {
+		return errMaxInFlight
+	}
+	return nil
+}
+
+// withInFlightLimit wraps the scanner to wait for a slot of the in-flight limiter of the running command
+func (o *inFlightCmdOpts) withInFlightLimit(scanner scan.Scanner) scan.Scanner {
+	inFlightOnce.Do(func() {
+		fileLimit, err := scan.OpenFileLimit()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Warning: open file limit:", err)
+		}
+		limit, clamped := inFlightLimit(o.maxInFlight, fileLimit)
+		if clamped {
+			fmt.Fprintf(os.Stderr, "Warning: max in-flight requests %d exceed the open file limit %d, %d is used\n",
+				o.maxInFlight, fileLimit, limit)
+		}
+		if limit > 0 {
+			inFlightLimiter = scan.NewInFlightLimiter(limit)
+		}
+	})
+	if inFlightLimiter == nil {
+		return scanner
+	}
+	return scan.NewInFlightScanner(scanner, inFlightLimiter)
+}
+
+// inFlightLimit returns the in-flight limit for the requested value and the open file limit,
+// zero means no limit. The limit is clamped if the requested value doesn't fit the open file limit.
+func inFlightLimit(requested int, fileLimit uint64) (limit int, clamped bool) {
+	if fileLimit == 0 {
+		return requested, false
+	}
+	available := 1
+	if fileLimit > reservedFileCount+1 {
+		available = int(minUint64(fileLimit-reservedFileCount, math.MaxInt32))
+	}
+	if requested > available {
+		return available, true
+	}
+	if requested == 0 && available < math.MaxInt32 {
+		return available, false
+	}
+	return requested, false
+}
+
+func minUint64(a, b uint64) uint64 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// writeInFlightStats writes queue metrics of the in-flight limiter if requests waited for a slot
+func writeInFlightStats(w io.Writer) {
+	// synchronize with the limiter initialization, the limiter is not created after this call
+	inFlightOnce.Do(func() {})
+	if inFlightLimiter == nil {
+		return
+	}
+	stats := inFlightLimiter.Stats()
+	if stats.Waited > 0 {
+		fmt.Fprintf(w, "in-flight: limit %d, max queue length %d, %d requests waited\n",
+			stats.Limit, stats.MaxQueued, stats.Waited)
+	}
+}
@@ -0,0 +1,105 @@
+package command
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInFlightCmdOptsInitCliFlags(t *testing.T) {
+	t.Parallel()
+	cmd := &cobra.Command{}
+	var opts inFlightCmdOpts
+	opts.initCliFlags(cmd)
+
+	require.Zero(t, opts.maxInFlight)
+	require.NoError(t, cmd.ParseFlags(strings.Split("--max-in-flight 500", " ")))
+	require.NoError(t, opts.parseRawOptions())
+	require.Equal(t, 500, opts.maxInFlight)
+}
+
+func TestInFlightCmdOptsParseRawOptionsError(t *testing.T) {
+	t.Parallel()
+	opts := inFlightCmdOpts{maxInFlight: -1}
+	require.ErrorIs(t, opts.parseRawOptions(), errMaxInFlight)
+}
+
+func TestInFlightLimit(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name      string
+		requested int
+		fileLimit uint64
+		limit     int
+		clamped   bool
+	}{
+		{
+			name: "NoFileLimitAuto",
+		},
+		{
+			name:      "NoFileLimit",
+			requested: 100,
+			limit:     100,
+		},
+		{
+			name:      "Auto",
+			fileLimit: 1024,
+			limit:     1024 - reservedFileCount,
+		},
+		{
+			name:      "Requested",
+			requested: 100,
+			fileLimit: 1024,
+			limit:     100,
+		},
+		{
+			name:      "Clamped",
+			requested: 5000,
+			fileLimit: 1024,
+			limit:     1024 - reservedFileCount,
+			clamped:   true,
+		},
+		{
+			name:      "TinyFileLimit",
+			fileLimit: 10,
+			limit:     1,
+		},
+		{
+			name:      "UnlimitedAuto",
+			fileLimit: math.MaxUint64,
+		},
+		{
+			name:      "UnlimitedRequested",
+			requested: 100,
+			fileLimit: math.MaxUint64,
+			limit:     100,
+		},
+	}
+
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			limit, clamped := inFlightLimit(tt.requested, tt.fileLimit)
+			require.Equal(t, tt.limit, limit)
+			require.Equal(t, tt.clamped, clamped)
+		})
+	}
+}
+
+func TestInitInFlightLimiter(t *testing.T) {
+	initInFlightLimiter()
+	t.Cleanup(initInFlightLimiter)
+
+	(&inFlightCmdOpts{maxInFlight: 5}).withInFlightLimit(&openPortScanner{})
+	(&inFlightCmdOpts{maxInFlight: 7}).withInFlightLimit(&openPortScanner{})
+	require.Equal(t, 5, inFlightLimiter.Stats().Limit)
+
+	// the next command run, e.g. the next workflow stage, gets its own limiter
+	initInFlightLimiter()
+	(&inFlightCmdOpts{maxInFlight: 7}).withInFlightLimit(&openPortScanner{})
+	require.Equal(t, 7, inFlightLimiter.Stats().Limit)
+}
@@ -0,0 +1,96 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/v-byte-cpu/sx/command/log"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+	"github.com/v-byte-cpu/sx/pkg/scan/influx"
+)
+
+const defaultInfluxPort = 8086
+
+func newInfluxCmd() *influxCmd {
+	c := &influxCmd{}
+
+	cmd := &cobra.Command{
+		Use: "influx [flags] [subnet]",
+		Example: strings.Join([]string{
+			"influx 192.168.0.1/24", "influx -p 8086,8088 10.0.0.1",
+			"influx --proto https -p 8086 192.168.0.3",
+			"influx -f ip_ports_file.jsonl", "influx -p 8086 -f ips_file.jsonl"}, "\n"),
+		Short: "Perform InfluxDB scan",
+		Long: strings.Join([]string{
+			"Perform InfluxDB scan.",
+			"The /ping endpoint and the SHOW DATABASES query are requested without credentials, port 8086 by default.",
+			"Servers are reported with the version and the build from response headers and whether",
+			"authentication is enabled, databases are listed if queries are executed without credentials."}, "\n"),
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer cancel()
+
+			if err = c.opts.parseRawOptions(); err != nil {
+				return
+			}
+			scanRange, err := c.opts.parseScanRange(args)
+			if err != nil {
+				return
+			}
+
+			var logger log.Logger
+			if logger, err = c.opts.getLogger(influx.ScanType, resultWriter); err != nil {
+				return
+			}
+
+			engine := c.opts.newInfluxScanEngine(ctx)
+			return c.opts.runScanEngine(ctx, cmd, engine, scanRange, logger)
+		},
+	}
+
+	c.opts.initCliFlags(cmd)
+
+	c.cmd = cmd
+	return c
+}
+
+type influxCmd struct {
+	cmd  *cobra.Command
+	opts influxCmdOpts
+}
+
+type influxCmdOpts struct {
+	genericScanCmdOpts
+	timeout time.Duration
+	proto   string
+}
+
+func (o *influxCmdOpts) initCliFlags(cmd *cobra.Command) {
+	o.genericScanCmdOpts.initCliFlags(cmd)
+	cmd.Flags().DurationVarP(&o.timeout, "timeout", "t", defaultTimeout, "set request timeout")
+	cmd.Flags().StringVar(&o.proto, "proto", cliHTTPProtoFlag, "set protocol to use, only http or https are valid")
+}
+
+func (o *influxCmdOpts) parseRawOptions() (err error) {
+	if err = o.genericScanCmdOpts.parseRawOptions(); err != nil {
+		return
+	}
+	if o.proto != cliHTTPProtoFlag && o.proto != cliHTTPSProtoFlag {
+		return errors.New("invalid HTTP proto flag: http or https required")
+	}
+	// targets of the subnet argument are scanned on the standard port unless ports are set
+	if len(o.portRanges) == 0 && len(o.ipFile) == 0 && len(o.rawInput) == 0 {
+		o.portRanges = []*scan.PortRange{{StartPort: defaultInfluxPort, EndPort: defaultInfluxPort}}
+	}
+	return
+}
+
+func (o *influxCmdOpts) newInfluxScanEngine(ctx context.Context) scan.EngineResulter {
+	scanner := influx.NewScanner(o.proto, influx.WithDataTimeout(o.timeout))
+	return o.newScanEngine(ctx, scanner)
+}
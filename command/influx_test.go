@@ -0,0 +1,100 @@
+package command
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+func TestInfluxCmdDstSubnetError(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{
+			name: "RequiredArg",
+			args: nil,
+		},
+		{
+			name: "InvalidDstSubnet",
+			args: []string{"invalid_ip_address"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := newInfluxCmd().cmd
+			err := cmd.RunE(cmd, tt.args)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestInfluxCmdOptsInitCliFlags(t *testing.T) {
+	t.Parallel()
+	var opts influxCmdOpts
+	cmd := &cobra.Command{}
+
+	opts.initCliFlags(cmd)
+	err := cmd.ParseFlags(strings.Split(
+		"--json -p 8086,8088 -f ip_file.jsonl -w 300 --exit-delay 10s --timeout 2s --proto https", " "))
+
+	require.NoError(t, err)
+	require.Equal(t, true, opts.json)
+	require.Equal(t, "8086,8088", opts.rawPortRanges)
+	require.Equal(t, "ip_file.jsonl", opts.ipFile)
+	require.Equal(t, 300, opts.workers)
+	require.Equal(t, 10*time.Second, opts.exitDelay)
+
+	require.Equal(t, 2*time.Second, opts.timeout)
+	require.Equal(t, "https", opts.proto)
+}
+
+func TestInfluxCmdOptsParseRawOptions(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		opts     influxCmdOpts
+		expected []*scan.PortRange
+	}{
+		{
+			name: "Ports",
+			opts: influxCmdOpts{
+				genericScanCmdOpts: genericScanCmdOpts{rawPortRanges: "8086,8088", workers: 300},
+				proto:              "http",
+			},
+			expected: []*scan.PortRange{{StartPort: 8086, EndPort: 8086}, {StartPort: 8088, EndPort: 8088}},
+		},
+		{
+			name: "DefaultPort",
+			opts: influxCmdOpts{
+				genericScanCmdOpts: genericScanCmdOpts{workers: 300},
+				proto:              "http",
+			},
+			expected: []*scan.PortRange{{StartPort: 8086, EndPort: 8086}},
+		},
+	}
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			err := tt.opts.parseRawOptions()
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, tt.opts.portRanges)
+		})
+	}
+}
+
+func TestInfluxCmdOptsParseRawOptionsInvalidProto(t *testing.T) {
+	t.Parallel()
+	opts := influxCmdOpts{
+		genericScanCmdOpts: genericScanCmdOpts{workers: 300},
+		proto:              "ftp",
+	}
+
+	require.Error(t, opts.parseRawOptions())
+}
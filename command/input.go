@@ -0,0 +1,241 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/v-byte-cpu/sx/pkg/input/aws"
+	"github.com/v-byte-cpu/sx/pkg/input/consul"
+	"github.com/v-byte-cpu/sx/pkg/input/etcd"
+	"github.com/v-byte-cpu/sx/pkg/input/inventory"
+	"github.com/v-byte-cpu/sx/pkg/input/k8s"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+var (
+	errInputScheme = errors.New("invalid input: unknown scheme")
+	errInputPorts  = errors.New("invalid input: ports are provided by the input and can not be set")
+	errInputPath   = errors.New("invalid input: file path required")
+)
+
+// inputGenerators are built-in request generators of input URIs, e.g. k8s:?context=prod&namespace=web,
+// they are registered along with generators of embedders and plugins
+var inputGenerators = map[string]scan.GeneratorFactory{
+	"k8s":    newK8sInputGenerator,
+	"aws":    newAWSInputGenerator,
+	"consul": newConsulInputGenerator,
+	"etcd":   newEtcdInputGenerator,
+
+	"terraform": newTerraformInputGenerator,
+	"ansible":   newAnsibleInputGenerator,
+}
+
+func init() {
+	for scheme, newGenerator := range inputGenerators {
+		scan.RegisterGenerator(scheme, newGenerator)
+	}
+}
+
+func inputSchemes() []string {
+	return scan.Generators()
+}
+
+func inputUsage() string {
+	return strings.Join([]string{
+		"set external source of ip/port pairs to scan in the URI form scheme:[path][?options]",
+		fmt.Sprintf("supported schemes: %s", strings.Join(inputSchemes(), ", ")),
+		"k8s:[kubeconfig][?context=name&namespace=name|*&resources=services,endpoints,nodes&protocol=tcp|udp|sctp]",
+		"aws:[?profile=name&regions=us-east-1,eu-west-1&resources=instances,addresses,loadbalancers&ports=22,80]",
+		"consul://[host:port][?services=web,api&tag=name&dc=name&token=token&tls=true]",
+		"etcd://[host:port][?prefix=/services/&username=name&password=password&tls=true]",
+		"terraform:path/to/terraform.tfstate[?ports=22,80]",
+		"ansible:path/to/inventory[?group=name&ports=22,80]"}, "\n")
+}
+
+func parseInput(rawInput string) (reqgen scan.RequestGenerator, err error) {
+	var u *url.URL
+	if u, err = url.Parse(rawInput); err != nil {
+		return
+	}
+	newGenerator, ok := scan.LookupGenerator(u.Scheme)
+	if !ok {
+		// input without options, e.g. k8s
+		if newGenerator, ok = scan.LookupGenerator(rawInput); !ok {
+			return nil, errInputScheme
+		}
+		u = &url.URL{Scheme: rawInput}
+	}
+	return newGenerator(u)
+}
+
+// inputPath returns path part of the input URI, both k8s:relative/path and k8s:/abs/path forms are valid
+func inputPath(u *url.URL) string {
+	if len(u.Opaque) > 0 {
+		return u.Opaque
+	}
+	return u.Path
+}
+
+// inputEndpoint returns base URL of the service API from the host part of the input URI,
+// HTTPS is used if the tls option is set
+func inputEndpoint(u *url.URL, defaultHost string) (string, error) {
+	host := u.Host
+	if len(host) == 0 {
+		host = defaultHost
+	}
+	scheme := "http"
+	if rawTLS := u.Query().Get("tls"); len(rawTLS) > 0 {
+		useTLS, err := strconv.ParseBool(rawTLS)
+		if err != nil {
+			return "", fmt.Errorf("invalid input tls option: %w", err)
+		}
+		if useTLS {
+			scheme = "https"
+		}
+	}
+	return scheme + "://" + host, nil
+}
+
+func newK8sInputGenerator(u *url.URL) (scan.RequestGenerator, error) {
+	path := inputPath(u)
+	if len(path) == 0 {
+		path = k8s.DefaultConfigPath()
+	}
+	conf, err := k8s.LoadConfigFile(path)
+	if err != nil {
+		return nil, err
+	}
+	query := u.Query()
+	client, err := k8s.NewClient(conf, query.Get("context"))
+	if err != nil {
+		return nil, err
+	}
+	var opts []k8s.GeneratorOption
+	switch namespace := query.Get("namespace"); namespace {
+	case "":
+	case "*":
+		opts = append(opts, k8s.WithAllNamespaces())
+	default:
+		opts = append(opts, k8s.WithNamespace(namespace))
+	}
+	if resources := query.Get("resources"); len(resources) > 0 {
+		opts = append(opts, k8s.WithResources(strings.Split(resources, ",")))
+	}
+	if protocol := query.Get("protocol"); len(protocol) > 0 {
+		opts = append(opts, k8s.WithProtocol(protocol))
+	}
+	return k8s.NewRequestGenerator(client, opts...)
+}
+
+func newAWSInputGenerator(u *url.URL) (scan.RequestGenerator, error) {
+	query := u.Query()
+	opts := []aws.GeneratorOption{aws.WithLookupIP(lookupIP)}
+	if resources := query.Get("resources"); len(resources) > 0 {
+		opts = append(opts, aws.WithResources(strings.Split(resources, ",")))
+	}
+	if rawPorts := query.Get("ports"); len(rawPorts) > 0 {
+		ports, err := parsePortRanges(rawPorts)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, aws.WithPorts(ports))
+	}
+	// regions from the shared config or environment are used by default
+	regions := []string{""}
+	if rawRegions := query.Get("regions"); len(rawRegions) > 0 {
+		regions = strings.Split(rawRegions, ",")
+	}
+	clients := make([]*aws.Clients, 0, len(regions))
+	for _, region := range regions {
+		var loadOpts []func(*awsconfig.LoadOptions) error
+		if profile := query.Get("profile"); len(profile) > 0 {
+			loadOpts = append(loadOpts, awsconfig.WithSharedConfigProfile(profile))
+		}
+		if len(region) > 0 {
+			loadOpts = append(loadOpts, awsconfig.WithRegion(region))
+		}
+		cfg, err := awsconfig.LoadDefaultConfig(context.Background(), loadOpts...)
+		if err != nil {
+			return nil, err
+		}
+		clients = append(clients, aws.NewClients(cfg))
+	}
+	return aws.NewRequestGenerator(clients, opts...)
+}
+
+func newConsulInputGenerator(u *url.URL) (scan.RequestGenerator, error) {
+	address, err := inputEndpoint(u, consul.DefaultHost)
+	if err != nil {
+		return nil, err
+	}
+	query := u.Query()
+	token := query.Get("token")
+	if len(token) == 0 {
+		token = os.Getenv("CONSUL_HTTP_TOKEN")
+	}
+	client := consul.NewClient(address, consul.WithToken(token), consul.WithDatacenter(query.Get("dc")))
+	opts := []consul.GeneratorOption{consul.WithLookupIP(lookupIP)}
+	if services := query.Get("services"); len(services) > 0 {
+		opts = append(opts, consul.WithServices(strings.Split(services, ",")))
+	}
+	if tag := query.Get("tag"); len(tag) > 0 {
+		opts = append(opts, consul.WithTag(tag))
+	}
+	return consul.NewRequestGenerator(client, opts...), nil
+}
+
+func newEtcdInputGenerator(u *url.URL) (scan.RequestGenerator, error) {
+	endpoint, err := inputEndpoint(u, etcd.DefaultHost)
+	if err != nil {
+		return nil, err
+	}
+	query := u.Query()
+	var clientOpts []etcd.ClientOption
+	if username := query.Get("username"); len(username) > 0 {
+		clientOpts = append(clientOpts, etcd.WithAuth(username, query.Get("password")))
+	}
+	return etcd.NewRequestGenerator(etcd.NewClient(endpoint, clientOpts...),
+		etcd.WithPrefix(query.Get("prefix")), etcd.WithLookupIP(lookupIP)), nil
+}
+
+func newTerraformInputGenerator(u *url.URL) (scan.RequestGenerator, error) {
+	path := inputPath(u)
+	if len(path) == 0 {
+		return nil, errInputPath
+	}
+	hosts, err := inventory.LoadTerraformStateFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return newInventoryInputGenerator(u, hosts)
+}
+
+func newAnsibleInputGenerator(u *url.URL) (scan.RequestGenerator, error) {
+	path := inputPath(u)
+	if len(path) == 0 {
+		return nil, errInputPath
+	}
+	hosts, err := inventory.LoadAnsibleInventoryFile(path, u.Query().Get("group"))
+	if err != nil {
+		return nil, err
+	}
+	return newInventoryInputGenerator(u, hosts)
+}
+
+func newInventoryInputGenerator(u *url.URL, hosts []*inventory.Host) (scan.RequestGenerator, error) {
+	opts := []inventory.GeneratorOption{inventory.WithLookupIP(lookupIP)}
+	if rawPorts := u.Query().Get("ports"); len(rawPorts) > 0 {
+		ports, err := parsePortRanges(rawPorts)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, inventory.WithPorts(ports))
+	}
+	return inventory.NewRequestGenerator(hosts, opts...), nil
+}
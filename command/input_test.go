@@ -0,0 +1,258 @@
+package command
+
+import (
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/v-byte-cpu/sx/pkg/input/aws"
+	"github.com/v-byte-cpu/sx/pkg/input/consul"
+	"github.com/v-byte-cpu/sx/pkg/input/etcd"
+	"github.com/v-byte-cpu/sx/pkg/input/inventory"
+	"github.com/v-byte-cpu/sx/pkg/input/k8s"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+const testKubeconfig = `
+current-context: dev
+clusters:
+- name: dev
+  cluster:
+    server: https://127.0.0.1:6443
+    insecure-skip-tls-verify: true
+contexts:
+- name: dev
+  context:
+    cluster: dev
+    user: dev
+users:
+- name: dev
+  user:
+    token: secret
+`
+
+func writeTestKubeconfig(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "kubeconfig")
+	require.NoError(t, os.WriteFile(path, []byte(testKubeconfig), 0600))
+	return path
+}
+
+func TestParseInputUnknownScheme(t *testing.T) {
+	t.Parallel()
+	tests := []string{"abc", "abc:path", "file:///tmp/ips", ""}
+	for _, input := range tests {
+		_, err := parseInput(input)
+		require.ErrorIs(t, err, errInputScheme, input)
+	}
+}
+
+func TestParseInputK8s(t *testing.T) {
+	t.Parallel()
+	path := writeTestKubeconfig(t)
+
+	tests := []struct {
+		name  string
+		input string
+		err   error
+	}{
+		{
+			name:  "AbsPath",
+			input: "k8s:" + path,
+		},
+		{
+			name:  "Options",
+			input: "k8s:" + path + "?context=dev&namespace=*&resources=services,nodes&protocol=udp",
+		},
+		{
+			name:  "InvalidContext",
+			input: "k8s:" + path + "?context=prod",
+			err:   k8s.ErrNoContext,
+		},
+	}
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			reqgen, err := parseInput(tt.input)
+			if tt.err != nil {
+				require.ErrorIs(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			require.IsType(t, &k8s.RequestGenerator{}, reqgen)
+		})
+	}
+}
+
+func TestParseInputK8sInvalidResource(t *testing.T) {
+	t.Parallel()
+	_, err := parseInput("k8s:" + writeTestKubeconfig(t) + "?resources=pods")
+	require.Error(t, err)
+}
+
+func TestParseInputAWS(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name   string
+		input  string
+		err    error
+		anyErr bool
+	}{
+		{
+			name:  "Ports",
+			input: "aws:?regions=us-east-1,eu-west-1&ports=22,8000-8080",
+		},
+		{
+			name:  "LoadBalancers",
+			input: "aws:?regions=us-east-1&resources=loadbalancers",
+		},
+		{
+			name:  "NoPorts",
+			input: "aws:?regions=us-east-1",
+			err:   aws.ErrPorts,
+		},
+		{
+			name:   "InvalidPorts",
+			input:  "aws:?regions=us-east-1&ports=abc",
+			anyErr: true,
+		},
+	}
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			reqgen, err := parseInput(tt.input)
+			if tt.anyErr {
+				require.Error(t, err)
+				return
+			}
+			if tt.err != nil {
+				require.ErrorIs(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			require.IsType(t, &aws.RequestGenerator{}, reqgen)
+		})
+	}
+}
+
+func TestParseInputRegistry(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		input    string
+		expected scan.RequestGenerator
+	}{
+		{input: "consul", expected: &consul.RequestGenerator{}},
+		{input: "consul://10.0.0.1:8500?services=web,api&tag=prod&dc=dc1&tls=true", expected: &consul.RequestGenerator{}},
+		{input: "etcd", expected: &etcd.RequestGenerator{}},
+		{input: "etcd://10.0.0.1:2379?prefix=/services/&username=root&password=secret", expected: &etcd.RequestGenerator{}},
+	}
+	for _, tt := range tests {
+		reqgen, err := parseInput(tt.input)
+		require.NoError(t, err, tt.input)
+		require.IsType(t, tt.expected, reqgen, tt.input)
+	}
+
+	_, err := parseInput("consul://10.0.0.1:8500?tls=abc")
+	require.Error(t, err)
+}
+
+func TestParseInputCustomGenerator(t *testing.T) {
+	t.Parallel()
+	reqgen := scan.NewIPPortGenerator(scan.NewIPGenerator(), scan.NewPortGenerator())
+	var options url.Values
+	scan.RegisterGenerator("test-queue", func(u *url.URL) (scan.RequestGenerator, error) {
+		options = u.Query()
+		return reqgen, nil
+	})
+
+	result, err := parseInput("test-queue://db.local/targets?batch=100")
+	require.NoError(t, err)
+	require.Same(t, reqgen, result)
+	require.Equal(t, "100", options.Get("batch"))
+	require.Contains(t, inputSchemes(), "test-queue")
+}
+
+func TestInputEndpoint(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{input: "consul", expected: "http://127.0.0.1:8500"},
+		{input: "consul://10.0.0.1:8501?tls=true", expected: "https://10.0.0.1:8501"},
+		{input: "consul://consul.local:8500?tls=false", expected: "http://consul.local:8500"},
+	}
+	for _, tt := range tests {
+		u, err := url.Parse(tt.input)
+		require.NoError(t, err)
+		endpoint, err := inputEndpoint(u, consul.DefaultHost)
+		require.NoError(t, err)
+		require.Equal(t, tt.expected, endpoint, tt.input)
+	}
+}
+
+func TestParseInputInventory(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	statePath := filepath.Join(dir, "terraform.tfstate")
+	require.NoError(t, os.WriteFile(statePath, []byte(`{"version":4,"resources":[]}`), 0600))
+	inventoryPath := filepath.Join(dir, "hosts")
+	require.NoError(t, os.WriteFile(inventoryPath, []byte("[web]\n10.0.0.1\n"), 0600))
+
+	tests := []struct {
+		name   string
+		input  string
+		err    error
+		anyErr bool
+	}{
+		{name: "Terraform", input: "terraform:" + statePath + "?ports=22,80"},
+		{name: "Ansible", input: "ansible:" + inventoryPath + "?group=web"},
+		{name: "TerraformNoPath", input: "terraform", err: errInputPath},
+		{name: "AnsibleNoPath", input: "ansible:?group=web", err: errInputPath},
+		{name: "AnsibleInvalidGroup", input: "ansible:" + inventoryPath + "?group=db", err: inventory.ErrGroup},
+		{name: "InvalidPorts", input: "ansible:" + inventoryPath + "?ports=abc", anyErr: true},
+		{name: "FileNotFound", input: "terraform:" + filepath.Join(dir, "none"), anyErr: true},
+	}
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			reqgen, err := parseInput(tt.input)
+			if tt.anyErr {
+				require.Error(t, err)
+				return
+			}
+			if tt.err != nil {
+				require.ErrorIs(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			require.IsType(t, &inventory.RequestGenerator{}, reqgen)
+		})
+	}
+}
+
+func TestGenericScanCmdOptsParseRawOptionsInputWithPorts(t *testing.T) {
+	t.Parallel()
+	opts := genericScanCmdOpts{
+		rawPortRanges: "80",
+		workers:       100,
+		rawInput:      "k8s:" + writeTestKubeconfig(t),
+	}
+	err := opts.parseRawOptions()
+	require.ErrorIs(t, err, errInputPorts)
+}
+
+func TestGenericScanCmdOptsNewIPPortGeneratorInput(t *testing.T) {
+	t.Parallel()
+	opts := genericScanCmdOpts{
+		workers:  100,
+		rawInput: "k8s:" + writeTestKubeconfig(t),
+	}
+	require.NoError(t, opts.parseRawOptions())
+	require.IsType(t, &k8s.RequestGenerator{}, opts.newIPPortGenerator())
+}
@@ -0,0 +1,99 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/v-byte-cpu/sx/command/log"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+	"github.com/v-byte-cpu/sx/pkg/scan/ipmi"
+)
+
+func newIPMICmd() *ipmiCmd {
+	c := &ipmiCmd{}
+
+	cmd := &cobra.Command{
+		Use: "ipmi [flags] [subnet]",
+		Example: strings.Join([]string{
+			"ipmi -p 623 192.168.0.1/24", "ipmi --timeout 500ms -p 623 10.0.0.1/16",
+			"ipmi --users ADMIN,root -p 623 10.0.0.1/16", "ipmi --users '' -p 623 10.0.0.1/16",
+			"ipmi -f ip_ports_file.jsonl", "ipmi -p 623 -f ips_file.jsonl"}, "\n"),
+		Short: "Perform IPMI version, cipher zero and RAKP hash disclosure scan",
+		Long: strings.Join([]string{
+			"Perform IPMI version, cipher zero and RAKP hash disclosure scan.",
+			"The Get Channel Authentication Capabilities request is sent to each target over UDP,",
+			"BMCs are reported with their IPMI version and supported authentication types.",
+			"RMCP+ sessions are opened with IPMI v2.0 BMCs to check whether cipher suite 0 is accepted",
+			"and whether password hashes of user names are returned in RAKP Message 2.",
+			"Sessions are never activated, so no user is logged in."}, "\n"),
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer cancel()
+
+			if err = c.opts.parseRawOptions(); err != nil {
+				return
+			}
+			scanRange, err := c.opts.parseScanRange(args)
+			if err != nil {
+				return
+			}
+
+			var logger log.Logger
+			if logger, err = c.opts.getLogger(ipmi.ScanType, resultWriter); err != nil {
+				return
+			}
+
+			engine := c.opts.newIPMIScanEngine(ctx)
+			return c.opts.runScanEngine(ctx, cmd, engine, scanRange, logger)
+		},
+	}
+
+	c.opts.initCliFlags(cmd)
+
+	c.cmd = cmd
+	return c
+}
+
+type ipmiCmd struct {
+	cmd  *cobra.Command
+	opts ipmiCmdOpts
+}
+
+type ipmiCmdOpts struct {
+	genericScanCmdOpts
+	timeout time.Duration
+	users   []string
+}
+
+func (o *ipmiCmdOpts) initCliFlags(cmd *cobra.Command) {
+	o.genericScanCmdOpts.initCliFlags(cmd)
+	cmd.Flags().DurationVarP(&o.timeout, "timeout", "t", 1*time.Second, "set time to wait for a response to each request")
+	cmd.Flags().StringSliceVar(&o.users, "users", ipmi.DefaultUsers,
+		strings.Join([]string{"set comma-separated list of user names to check for RAKP hash disclosure",
+			"the empty name is the null user, an empty list disables the check"}, "\n"))
+}
+
+func (o *ipmiCmdOpts) parseRawOptions() (err error) {
+	if err = o.genericScanCmdOpts.parseRawOptions(); err != nil {
+		return
+	}
+	for _, user := range o.users {
+		if len(user) > ipmi.MaxUserNameSize {
+			return fmt.Errorf("invalid user name %q: up to %d characters required", user, ipmi.MaxUserNameSize)
+		}
+	}
+	return
+}
+
+func (o *ipmiCmdOpts) newIPMIScanEngine(ctx context.Context) scan.EngineResulter {
+	scanner := ipmi.NewScanner(
+		ipmi.WithDataTimeout(o.timeout),
+		ipmi.WithUsers(o.users),
+	)
+	return o.newScanEngine(ctx, scanner)
+}
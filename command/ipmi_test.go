@@ -0,0 +1,85 @@
+package command
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+func TestIPMICmdDstSubnetError(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{
+			name: "RequiredArg",
+			args: nil,
+		},
+		{
+			name: "InvalidDstSubnet",
+			args: []string{"invalid_ip_address"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := newIPMICmd().cmd
+			err := cmd.RunE(cmd, tt.args)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestIPMICmdOptsInitCliFlags(t *testing.T) {
+	t.Parallel()
+	var opts ipmiCmdOpts
+	cmd := &cobra.Command{}
+
+	opts.initCliFlags(cmd)
+	err := cmd.ParseFlags(strings.Split(
+		"--json -p 623 -f ip_file.jsonl -w 300 --exit-delay 10s --timeout 3s --users ,ADMIN", " "))
+
+	require.NoError(t, err)
+	require.Equal(t, true, opts.json)
+	require.Equal(t, "623", opts.rawPortRanges)
+	require.Equal(t, "ip_file.jsonl", opts.ipFile)
+	require.Equal(t, 300, opts.workers)
+	require.Equal(t, 10*time.Second, opts.exitDelay)
+
+	require.Equal(t, 3*time.Second, opts.timeout)
+	require.Equal(t, []string{"", "ADMIN"}, opts.users)
+}
+
+func TestIPMICmdOptsParseRawOptions(t *testing.T) {
+	t.Parallel()
+	opts := ipmiCmdOpts{
+		genericScanCmdOpts: genericScanCmdOpts{
+			rawPortRanges: "623",
+			workers:       300,
+		},
+	}
+
+	err := opts.parseRawOptions()
+
+	require.NoError(t, err)
+	require.Equal(t, []*scan.PortRange{{StartPort: 623, EndPort: 623}}, opts.portRanges)
+}
+
+func TestIPMICmdOptsParseRawOptionsError(t *testing.T) {
+	t.Parallel()
+	opts := ipmiCmdOpts{
+		genericScanCmdOpts: genericScanCmdOpts{
+			rawPortRanges: "623",
+			workers:       300,
+		},
+		users: []string{"ADMIN", "administrator_account"},
+	}
+
+	err := opts.parseRawOptions()
+
+	require.Error(t, err)
+}
@@ -0,0 +1,75 @@
+package command
+
+import (
+	"errors"
+	"io"
+	"net"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+var (
+	errIPv6SweepTargets        = errors.New("IPv6 sweep requires one IPv6 subnet argument, file with ip/port pairs or input is not supported")
+	errIPv6SweepEmbeddedSubnet = errors.New("invalid IPv6 sweep embedded subnet: IPv4 subnet required")
+)
+
+// ipv6SweepCmdOpts are options to scan huge IPv6 subnets like /64 with targeted strategies
+// that generate commonly used addresses instead of all addresses of the subnet
+type ipv6SweepCmdOpts struct {
+	ipv6Strategies     []string
+	ipv6Hitlist        string
+	ipv6EmbeddedSubnet *net.IPNet
+	ipv6LowByteCount   int
+	ipv6Generator      scan.IPGenerator
+
+	rawIPv6Sweep          string
+	rawIPv6EmbeddedSubnet string
+}
+
+func (o *ipv6SweepCmdOpts) initCliFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&o.rawIPv6Sweep, "ipv6-sweep", "",
+		strings.Join([]string{
+			"set comma-separated strategies to sweep the IPv6 subnet instead of scanning all its addresses",
+			"available strategies: " + strings.Join(scan.IPv6Strategies(), ", ")}, "\n"))
+	cmd.Flags().StringVar(&o.ipv6Hitlist, "ipv6-hitlist", "",
+		"set file with known IPv6 addresses whose host parts are applied to the subnet by the hitlist strategy, one-per line")
+	cmd.Flags().StringVar(&o.rawIPv6EmbeddedSubnet, "ipv6-embedded-subnet", "",
+		"set IPv4 subnet whose addresses are embedded into interface identifiers by the embedded-ipv4 strategy")
+	cmd.Flags().IntVar(&o.ipv6LowByteCount, "ipv6-low-byte-count", 255,
+		"set number of addresses generated by the low-byte strategy")
+}
+
+func (o *ipv6SweepCmdOpts) parseRawOptions() (err error) {
+	if len(o.rawIPv6Sweep) == 0 {
+		return nil
+	}
+	o.ipv6Strategies = strings.Split(o.rawIPv6Sweep, ",")
+	if len(o.rawIPv6EmbeddedSubnet) > 0 {
+		var subnet *net.IPNet
+		if _, subnet, err = net.ParseCIDR(o.rawIPv6EmbeddedSubnet); err != nil || subnet.IP.To4() == nil {
+			return errIPv6SweepEmbeddedSubnet
+		}
+		o.ipv6EmbeddedSubnet = subnet
+	}
+	o.ipv6Generator, err = o.newIPv6Generator()
+	return
+}
+
+func (o *ipv6SweepCmdOpts) newIPv6Generator() (scan.IPGenerator, error) {
+	opts := []scan.IPv6GeneratorOption{
+		scan.WithIPv6Strategies(o.ipv6Strategies...),
+		scan.WithLowByteCount(o.ipv6LowByteCount),
+	}
+	if o.ipv6EmbeddedSubnet != nil {
+		opts = append(opts, scan.WithEmbeddedIPv4Subnet(o.ipv6EmbeddedSubnet))
+	}
+	if len(o.ipv6Hitlist) > 0 {
+		opts = append(opts, scan.WithHitlist(func() (io.ReadCloser, error) {
+			return os.Open(o.ipv6Hitlist)
+		}))
+	}
+	return scan.NewIPv6Generator(opts...)
+}
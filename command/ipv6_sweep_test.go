@@ -0,0 +1,96 @@
+package command
+
+import (
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+func TestIPv6SweepCmdOptsInitCliFlags(t *testing.T) {
+	t.Parallel()
+	cmd := &cobra.Command{}
+	var opts ipv6SweepCmdOpts
+	opts.initCliFlags(cmd)
+
+	require.NoError(t, cmd.ParseFlags(strings.Split(
+		"--ipv6-sweep low-byte,embedded-ipv4,hitlist --ipv6-hitlist seeds.txt --ipv6-embedded-subnet 10.0.0.0/24 --ipv6-low-byte-count 16", " ")))
+	require.NoError(t, opts.parseRawOptions())
+	require.Equal(t, []string{scan.IPv6LowByte, scan.IPv6EmbeddedIPv4, scan.IPv6Hitlist}, opts.ipv6Strategies)
+	require.Equal(t, "seeds.txt", opts.ipv6Hitlist)
+	require.Equal(t, &net.IPNet{IP: net.IPv4(10, 0, 0, 0).To4(), Mask: net.CIDRMask(24, 32)}, opts.ipv6EmbeddedSubnet)
+	require.Equal(t, 16, opts.ipv6LowByteCount)
+	require.NotNil(t, opts.ipv6Generator)
+}
+
+func TestIPv6SweepCmdOptsParseRawOptions(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		opts     ipv6SweepCmdOpts
+		expected error
+	}{
+		{
+			name: "NoSweep",
+		},
+		{
+			name: "LowByteAndWordy",
+			opts: ipv6SweepCmdOpts{rawIPv6Sweep: "low-byte,wordy", ipv6LowByteCount: 255},
+		},
+		{
+			name:     "UnknownStrategy",
+			opts:     ipv6SweepCmdOpts{rawIPv6Sweep: "low-byte,random", ipv6LowByteCount: 255},
+			expected: scan.ErrIPv6Strategy,
+		},
+		{
+			name:     "HitlistWithoutFile",
+			opts:     ipv6SweepCmdOpts{rawIPv6Sweep: "hitlist", ipv6LowByteCount: 255},
+			expected: scan.ErrIPv6Strategy,
+		},
+		{
+			name:     "EmbeddedIPv4WithoutSubnet",
+			opts:     ipv6SweepCmdOpts{rawIPv6Sweep: "embedded-ipv4", ipv6LowByteCount: 255},
+			expected: scan.ErrIPv6Strategy,
+		},
+		{
+			name: "EmbeddedIPv6Subnet",
+			opts: ipv6SweepCmdOpts{rawIPv6Sweep: "embedded-ipv4", rawIPv6EmbeddedSubnet: "2001:db8::/64",
+				ipv6LowByteCount: 255},
+			expected: errIPv6SweepEmbeddedSubnet,
+		},
+		{
+			name:     "InvalidLowByteCount",
+			opts:     ipv6SweepCmdOpts{rawIPv6Sweep: "low-byte"},
+			expected: scan.ErrIPv6Strategy,
+		},
+	}
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			err := tt.opts.parseRawOptions()
+			if tt.expected != nil {
+				require.ErrorIs(t, err, tt.expected)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestGenericScanCmdOptsIPv6SweepTargetsError(t *testing.T) {
+	t.Parallel()
+	opts := genericScanCmdOpts{
+		ipFile:  "ip_file.jsonl",
+		workers: 100,
+		ipv6SweepCmdOpts: ipv6SweepCmdOpts{
+			rawIPv6Sweep:     "low-byte",
+			ipv6LowByteCount: 255,
+		},
+	}
+	err := opts.parseRawOptions()
+	require.ErrorIs(t, err, errIPv6SweepTargets)
+}
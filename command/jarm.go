@@ -0,0 +1,88 @@
+package command
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/v-byte-cpu/sx/command/log"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+	"github.com/v-byte-cpu/sx/pkg/scan/jarm"
+	"go.uber.org/ratelimit"
+)
+
+func newJARMCmd() *jarmCmd {
+	c := &jarmCmd{}
+
+	cmd := &cobra.Command{
+		Use: "jarm [flags] [subnet]",
+		Example: strings.Join([]string{
+			"jarm -p 443 192.168.0.1/24", "jarm -p 443,8443 10.0.0.1",
+			"jarm --rate 100/s -p 443 10.0.0.1/16",
+			"jarm -f ip_ports_file.jsonl", "jarm -p 443 -f ips_file.jsonl"}, "\n"),
+		Short: "Perform JARM TLS fingerprinting scan",
+		Long: strings.Join([]string{
+			"Perform JARM TLS fingerprinting scan.",
+			"Each target gets 10 TLS ClientHello probes on separate connections one after another,",
+			"the --rate option limits probe connections, not targets"}, " "),
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer cancel()
+
+			if err = c.opts.parseRawOptions(); err != nil {
+				return
+			}
+			scanRange, err := c.opts.parseScanRange(args)
+			if err != nil {
+				return
+			}
+
+			var logger log.Logger
+			if logger, err = c.opts.getLogger(jarm.ScanType, resultWriter); err != nil {
+				return
+			}
+
+			engine := c.opts.newJARMScanEngine(ctx)
+			return c.opts.runScanEngine(ctx, cmd, engine, scanRange, logger)
+		},
+	}
+
+	c.opts.initCliFlags(cmd)
+
+	c.cmd = cmd
+	return c
+}
+
+type jarmCmd struct {
+	cmd  *cobra.Command
+	opts jarmCmdOpts
+}
+
+type jarmCmdOpts struct {
+	genericScanCmdOpts
+	timeout time.Duration
+}
+
+func (o *jarmCmdOpts) initCliFlags(cmd *cobra.Command) {
+	o.genericScanCmdOpts.initCliFlags(cmd)
+	cmd.Flags().DurationVarP(&o.timeout, "timeout", "t", 2*time.Second, "set connect and data timeout of each probe")
+}
+
+func (o *jarmCmdOpts) newJARMScanEngine(ctx context.Context) scan.EngineResulter {
+	return o.newUnlimitedScanEngine(ctx, o.newJARMScanner())
+}
+
+func (o *jarmCmdOpts) newJARMScanner() *jarm.Scanner {
+	opts := []jarm.ScannerOption{
+		jarm.WithDialTimeout(o.timeout),
+		jarm.WithDataTimeout(o.timeout),
+	}
+	// every target takes several connections, so the rate limit is applied to connections
+	if o.rateCount > 0 {
+		opts = append(opts, jarm.WithRateLimiter(ratelimit.New(o.rateCount, ratelimit.Per(o.rateWindow))))
+	}
+	return jarm.NewScanner(opts...)
+}
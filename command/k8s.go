@@ -0,0 +1,93 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/v-byte-cpu/sx/command/log"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+	"github.com/v-byte-cpu/sx/pkg/scan/k8s"
+)
+
+var errK8sComponents = errors.New("invalid components: apiserver or kubelet required")
+
+func newK8sCmd() *k8sCmd {
+	c := &k8sCmd{}
+
+	cmd := &cobra.Command{
+		Use: "k8s [flags] [subnet]",
+		Example: strings.Join([]string{
+			"k8s -p 6443,8443,10250,10255 192.168.0.1/24", "k8s --components kubelet -p 10250 10.0.0.1",
+			"k8s -f ip_ports_file.jsonl", "k8s -p 6443 -f ips_file.jsonl"}, "\n"),
+		Short: "Perform Kubernetes API server and kubelet scan",
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer cancel()
+
+			if err = c.opts.parseRawOptions(); err != nil {
+				return
+			}
+			scanRange, err := c.opts.parseScanRange(args)
+			if err != nil {
+				return
+			}
+
+			var logger log.Logger
+			if logger, err = c.opts.getLogger(k8s.ScanType, resultWriter); err != nil {
+				return
+			}
+
+			engine := c.opts.newK8sScanEngine(ctx)
+			return c.opts.runScanEngine(ctx, cmd, engine, scanRange, logger)
+		},
+	}
+
+	c.opts.initCliFlags(cmd)
+
+	c.cmd = cmd
+	return c
+}
+
+type k8sCmd struct {
+	cmd  *cobra.Command
+	opts k8sCmdOpts
+}
+
+type k8sCmdOpts struct {
+	genericScanCmdOpts
+	timeout    time.Duration
+	components []string
+}
+
+func (o *k8sCmdOpts) initCliFlags(cmd *cobra.Command) {
+	o.genericScanCmdOpts.initCliFlags(cmd)
+	cmd.Flags().DurationVarP(&o.timeout, "timeout", "t", defaultTimeout, "set request timeout")
+	cmd.Flags().StringSliceVar(&o.components, "components", []string{k8s.ComponentAPIServer, k8s.ComponentKubelet},
+		strings.Join([]string{"set comma-separated list of components to probe, apiserver or kubelet",
+			"the component of the well-known port is probed first, e.g. kubelet on 10250 and 10255"}, "\n"))
+}
+
+func (o *k8sCmdOpts) parseRawOptions() (err error) {
+	if err = o.genericScanCmdOpts.parseRawOptions(); err != nil {
+		return
+	}
+	if len(o.components) == 0 {
+		return errK8sComponents
+	}
+	for _, c := range o.components {
+		if c != k8s.ComponentAPIServer && c != k8s.ComponentKubelet {
+			return errK8sComponents
+		}
+	}
+	return
+}
+
+func (o *k8sCmdOpts) newK8sScanEngine(ctx context.Context) scan.EngineResulter {
+	scanner := k8s.NewScanner(k8s.WithDataTimeout(o.timeout), k8s.WithComponents(o.components...))
+	return o.newScanEngine(ctx, scanner)
+}
@@ -0,0 +1,100 @@
+package command
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+func TestK8sCmdDstSubnetError(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{
+			name: "RequiredArg",
+			args: nil,
+		},
+		{
+			name: "InvalidDstSubnet",
+			args: []string{"invalid_ip_address"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := newK8sCmd().cmd
+			err := cmd.RunE(cmd, tt.args)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestK8sCmdOptsInitCliFlags(t *testing.T) {
+	t.Parallel()
+	var opts k8sCmdOpts
+	cmd := &cobra.Command{}
+
+	opts.initCliFlags(cmd)
+	err := cmd.ParseFlags(strings.Split(
+		"--json -p 23-57,71-2733 -f ip_file.jsonl -w 300 --exit-delay 10s --timeout 2s --components kubelet", " "))
+
+	require.NoError(t, err)
+	require.Equal(t, true, opts.json)
+	require.Equal(t, "23-57,71-2733", opts.rawPortRanges)
+	require.Equal(t, "ip_file.jsonl", opts.ipFile)
+	require.Equal(t, 300, opts.workers)
+	require.Equal(t, 10*time.Second, opts.exitDelay)
+
+	require.Equal(t, 2*time.Second, opts.timeout)
+	require.Equal(t, []string{"kubelet"}, opts.components)
+}
+
+func TestK8sCmdOptsParseRawOptions(t *testing.T) {
+	t.Parallel()
+	opts := k8sCmdOpts{
+		genericScanCmdOpts: genericScanCmdOpts{
+			rawPortRanges: "23-57,71-2733",
+			workers:       300,
+		},
+		components: []string{"apiserver", "kubelet"},
+	}
+
+	err := opts.parseRawOptions()
+
+	require.NoError(t, err)
+	require.Equal(t, []*scan.PortRange{
+		{StartPort: 23, EndPort: 57},
+		{StartPort: 71, EndPort: 2733}}, opts.portRanges)
+}
+
+func TestK8sCmdOptsParseRawOptionsComponents(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name       string
+		components []string
+	}{
+		{
+			name: "NoComponents",
+		},
+		{
+			name:       "UnknownComponent",
+			components: []string{"apiserver", "etcd"},
+		},
+	}
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			opts := k8sCmdOpts{
+				genericScanCmdOpts: genericScanCmdOpts{rawPortRanges: "6443", workers: 300},
+				components:         tt.components,
+			}
+			require.ErrorIs(t, opts.parseRawOptions(), errK8sComponents)
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package command
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/v-byte-cpu/sx/command/log"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+	"github.com/v-byte-cpu/sx/pkg/scan/kafka"
+)
+
+func newKafkaCmd() *kafkaCmd {
+	c := &kafkaCmd{}
+
+	cmd := &cobra.Command{
+		Use: "kafka [flags] [subnet]",
+		Example: strings.Join([]string{
+			"kafka -p 9092 192.168.0.1/24", "kafka -p 9092,9093 10.0.0.1",
+			"kafka --json -p 9092 10.0.0.1/16",
+			"kafka -f ip_ports_file.jsonl", "kafka -p 9092 -f ips_file.jsonl"}, "\n"),
+		Short: "Perform Kafka broker metadata scan",
+		Long: strings.Join([]string{
+			"Perform Kafka broker metadata scan.",
+			"The ApiVersions request is sent to each target to find out version ranges of supported APIs,",
+			"that identify the Kafka release. The Metadata request of all topics is sent afterwards to find out",
+			"the cluster id, advertised brokers and whether topics are listable without authentication."}, "\n"),
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer cancel()
+
+			if err = c.opts.parseRawOptions(); err != nil {
+				return
+			}
+			scanRange, err := c.opts.parseScanRange(args)
+			if err != nil {
+				return
+			}
+
+			var logger log.Logger
+			if logger, err = c.opts.getLogger(kafka.ScanType, resultWriter); err != nil {
+				return
+			}
+
+			engine := c.opts.newKafkaScanEngine(ctx)
+			return c.opts.runScanEngine(ctx, cmd, engine, scanRange, logger)
+		},
+	}
+
+	c.opts.initCliFlags(cmd)
+
+	c.cmd = cmd
+	return c
+}
+
+type kafkaCmd struct {
+	cmd  *cobra.Command
+	opts kafkaCmdOpts
+}
+
+type kafkaCmdOpts struct {
+	genericScanCmdOpts
+	timeout time.Duration
+}
+
+func (o *kafkaCmdOpts) initCliFlags(cmd *cobra.Command) {
+	o.genericScanCmdOpts.initCliFlags(cmd)
+	cmd.Flags().DurationVarP(&o.timeout, "timeout", "t", 2*time.Second, "set connect and data timeout")
+}
+
+func (o *kafkaCmdOpts) newKafkaScanEngine(ctx context.Context) scan.EngineResulter {
+	return o.newScanEngine(ctx, kafka.NewScanner(
+		kafka.WithDialTimeout(o.timeout),
+		kafka.WithDataTimeout(o.timeout),
+	))
+}
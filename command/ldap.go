@@ -0,0 +1,102 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/v-byte-cpu/sx/command/log"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+	"github.com/v-byte-cpu/sx/pkg/scan/ldap"
+)
+
+const defaultLDAPPorts = "389,636"
+
+func newLDAPCmd() *ldapCmd {
+	c := &ldapCmd{}
+
+	cmd := &cobra.Command{
+		Use: "ldap [flags] [subnet]",
+		Example: strings.Join([]string{
+			"ldap 192.168.0.1/24", "ldap -p 389,3268 10.0.0.1",
+			"ldap --json --tls-ports 636,10636 -p 10389,10636 10.0.0.1/16",
+			"ldap -f ip_ports_file.jsonl", "ldap -p 389 -f ips_file.jsonl"}, "\n"),
+		Short: "Perform LDAP anonymous bind and rootDSE scan",
+		Long: strings.Join([]string{
+			"Perform LDAP anonymous bind and rootDSE scan.",
+			"The anonymous simple bind is sent to each target, ports 389 and 636 by default,",
+			"servers are reported with the bind result and naming contexts, supported LDAP versions",
+			"and vendor of the rootDSE. Ports of the --tls-ports flag are connected over TLS (LDAPS)."}, "\n"),
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer cancel()
+
+			if err = c.opts.parseRawOptions(); err != nil {
+				return
+			}
+			scanRange, err := c.opts.parseScanRange(args)
+			if err != nil {
+				return
+			}
+
+			var logger log.Logger
+			if logger, err = c.opts.getLogger(ldap.ScanType, resultWriter); err != nil {
+				return
+			}
+
+			engine := c.opts.newLDAPScanEngine(ctx)
+			return c.opts.runScanEngine(ctx, cmd, engine, scanRange, logger)
+		},
+	}
+
+	c.opts.initCliFlags(cmd)
+
+	c.cmd = cmd
+	return c
+}
+
+type ldapCmd struct {
+	cmd  *cobra.Command
+	opts ldapCmdOpts
+}
+
+type ldapCmdOpts struct {
+	genericScanCmdOpts
+	timeout     time.Duration
+	rawTLSPorts string
+
+	tlsPorts []uint16
+}
+
+func (o *ldapCmdOpts) initCliFlags(cmd *cobra.Command) {
+	o.genericScanCmdOpts.initCliFlags(cmd)
+	cmd.Flags().DurationVarP(&o.timeout, "timeout", "t", 2*time.Second, "set connect and data timeout")
+	cmd.Flags().StringVar(&o.rawTLSPorts, "tls-ports", "636,3269", "set ports that are connected over TLS (LDAPS)")
+}
+
+func (o *ldapCmdOpts) parseRawOptions() (err error) {
+	if err = o.genericScanCmdOpts.parseRawOptions(); err != nil {
+		return
+	}
+	if o.tlsPorts, err = parsePorts(o.rawTLSPorts); err != nil {
+		return fmt.Errorf("tls ports: %w", err)
+	}
+	// targets of the subnet argument are scanned on standard ports unless ports are set
+	if len(o.portRanges) == 0 && len(o.ipFile) == 0 && len(o.rawInput) == 0 {
+		o.portRanges, err = parsePortRanges(defaultLDAPPorts)
+	}
+	return
+}
+
+func (o *ldapCmdOpts) newLDAPScanEngine(ctx context.Context) scan.EngineResulter {
+	scanner := ldap.NewScanner(
+		ldap.WithDialTimeout(o.timeout),
+		ldap.WithDataTimeout(o.timeout),
+		ldap.WithTLSPorts(o.tlsPorts),
+	)
+	return o.newScanEngine(ctx, scanner)
+}
@@ -0,0 +1,110 @@
+package command
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+func TestLDAPCmdDstSubnetError(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{
+			name: "RequiredArg",
+			args: nil,
+		},
+		{
+			name: "InvalidDstSubnet",
+			args: []string{"invalid_ip_address"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := newLDAPCmd().cmd
+			err := cmd.RunE(cmd, tt.args)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestLDAPCmdOptsInitCliFlags(t *testing.T) {
+	t.Parallel()
+	var opts ldapCmdOpts
+	cmd := &cobra.Command{}
+
+	opts.initCliFlags(cmd)
+	err := cmd.ParseFlags(strings.Split(
+		"--json -p 389 -f ip_file.jsonl -w 300 --exit-delay 10s --timeout 3s --tls-ports 10636", " "))
+
+	require.NoError(t, err)
+	require.Equal(t, true, opts.json)
+	require.Equal(t, "389", opts.rawPortRanges)
+	require.Equal(t, "ip_file.jsonl", opts.ipFile)
+	require.Equal(t, 300, opts.workers)
+	require.Equal(t, 10*time.Second, opts.exitDelay)
+
+	require.Equal(t, 3*time.Second, opts.timeout)
+	require.Equal(t, "10636", opts.rawTLSPorts)
+}
+
+func TestLDAPCmdOptsParseRawOptions(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name          string
+		opts          ldapCmdOpts
+		expectedPorts []*scan.PortRange
+		expectedTLS   []uint16
+	}{
+		{
+			name: "Ports",
+			opts: ldapCmdOpts{
+				genericScanCmdOpts: genericScanCmdOpts{rawPortRanges: "10389-10390", workers: 300},
+				rawTLSPorts:        "636,10636-10637",
+			},
+			expectedPorts: []*scan.PortRange{{StartPort: 10389, EndPort: 10390}},
+			expectedTLS:   []uint16{636, 10636, 10637},
+		},
+		{
+			name: "DefaultPorts",
+			opts: ldapCmdOpts{
+				genericScanCmdOpts: genericScanCmdOpts{workers: 300},
+				rawTLSPorts:        "636",
+			},
+			expectedPorts: []*scan.PortRange{{StartPort: 389, EndPort: 389}, {StartPort: 636, EndPort: 636}},
+			expectedTLS:   []uint16{636},
+		},
+		{
+			name: "NoTLSPorts",
+			opts: ldapCmdOpts{
+				genericScanCmdOpts: genericScanCmdOpts{ipFile: "ip_ports_file.jsonl", workers: 300},
+			},
+		},
+	}
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			err := tt.opts.parseRawOptions()
+			require.NoError(t, err)
+			require.Equal(t, tt.expectedPorts, tt.opts.portRanges)
+			require.Equal(t, tt.expectedTLS, tt.opts.tlsPorts)
+		})
+	}
+}
+
+func TestLDAPCmdOptsParseRawOptionsInvalidTLSPorts(t *testing.T) {
+	t.Parallel()
+	opts := ldapCmdOpts{
+		genericScanCmdOpts: genericScanCmdOpts{workers: 300},
+		rawTLSPorts:        "636-abc",
+	}
+
+	require.Error(t, opts.parseRawOptions())
+}
@@ -0,0 +1,58 @@
+package log
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+
+	"github.com/v-byte-cpu/sx/pkg/aggregator"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+// AgentWriter sends results to the aggregator in envelopes with their JSON and plain text forms,
+// so that the aggregator can write them in any format. Writers of the same connection share it,
+// so each envelope is written with one Write call, w must be safe for concurrent use.
+type AgentWriter struct {
+	w        io.Writer
+	scanType string
+}
+
+// Assert that log.AgentWriter conforms to the log.ResultWriter interface
+var _ ResultWriter = (*AgentWriter)(nil)
+
+// NewAgentWriter creates the writer of results to the aggregator connection,
+// scanType is the type of results that don't have their own scan type
+func NewAgentWriter(w io.Writer, scanType string) *AgentWriter {
+	return &AgentWriter{w: w, scanType: scanType}
+}
+
+func (w *AgentWriter) Write(ctx context.Context, result scan.Result) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	data, err := result.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	line, err := json.Marshal(&aggregator.Envelope{
+		ScanType: ResultScanType(result, w.scanType),
+		ID:       result.ID(),
+		Text:     result.String(),
+		Result:   data,
+	})
+	if err != nil {
+		return err
+	}
+	_, err = w.w.Write(append(line, '\n'))
+	return err
+}
+
+// Flush does nothing, envelopes are not buffered
+func (*AgentWriter) Flush(ctx context.Context) error {
+	return ctx.Err()
+}
+
+// Close does nothing, the connection is owned by the caller
+func (*AgentWriter) Close() error {
+	return nil
+}
@@ -0,0 +1,52 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/v-byte-cpu/sx/pkg/aggregator"
+	"github.com/v-byte-cpu/sx/pkg/scan/tcp"
+)
+
+func TestAgentWriter(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	w := NewAgentWriter(&buf, "arp")
+	arpResult := newScanResult(net.IPv4(192, 168, 0, 3).To4())
+	tcpResult := &tcp.ScanResult{ScanType: tcp.SYNScanType, IP: "192.168.0.3", Port: 22}
+	require.NoError(t, w.Write(context.Background(), arpResult))
+	require.NoError(t, w.Write(context.Background(), tcpResult))
+	// envelopes are not buffered
+	require.NoError(t, w.Flush(context.Background()))
+	require.NoError(t, w.Close())
+
+	dec := json.NewDecoder(&buf)
+	var env aggregator.Envelope
+	require.NoError(t, dec.Decode(&env))
+	require.Equal(t, aggregator.Envelope{ScanType: "arp", ID: arpResult.ID(), Text: arpResult.String(),
+		Result: json.RawMessage(scanResultToJSON(t, arpResult))}, env)
+	require.NoError(t, dec.Decode(&env))
+	require.Equal(t, aggregator.Envelope{ScanType: tcp.SYNScanType, ID: tcpResult.ID(), Text: tcpResult.String(),
+		Result: json.RawMessage(scanResultToJSON(t, tcpResult))}, env)
+	require.False(t, dec.More())
+}
+
+func TestAgentWriterError(t *testing.T) {
+	t.Parallel()
+
+	w := NewAgentWriter(&errWriter{}, "arp")
+	require.Error(t, w.Write(context.Background(), newScanResult(net.IPv4(192, 168, 0, 3).To4())))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	var buf bytes.Buffer
+	w = NewAgentWriter(&buf, "arp")
+	require.ErrorIs(t, w.Write(ctx, newScanResult(net.IPv4(192, 168, 0, 3).To4())), context.Canceled)
+	require.ErrorIs(t, w.Flush(ctx), context.Canceled)
+	require.Empty(t, buf.String())
+}
@@ -0,0 +1,86 @@
+package log
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+// NewWriterFunc creates the writer of results of the scan type
+type NewWriterFunc func(scanType string) (ResultWriter, error)
+
+// DemuxWriter routes results to separate writers by the scan type of results,
+// e.g. tcp syn results and policy violations of the same scan.
+// The writer of each scan type is created on the first result of the type.
+type DemuxWriter struct {
+	defaultType string
+	newWriter   NewWriterFunc
+	writers     map[string]ResultWriter
+	// scanTypes keeps the creation order of writers
+	scanTypes []string
+}
+
+// Assert that log.DemuxWriter conforms to the log.ResultWriter interface
+var _ ResultWriter = (*DemuxWriter)(nil)
+
+// NewDemuxWriter creates a demultiplexing writer, results without the scan type get the default type
+func NewDemuxWriter(defaultType string, newWriter NewWriterFunc) *DemuxWriter {
+	return &DemuxWriter{
+		defaultType: defaultType,
+		newWriter:   newWriter,
+		writers:     make(map[string]ResultWriter),
+	}
+}
+
+func (w *DemuxWriter) Write(ctx context.Context, result scan.Result) (err error) {
+	scanType := ResultScanType(result, w.defaultType)
+	rw, ok := w.writers[scanType]
+	if !ok {
+		if rw, err = w.newWriter(scanType); err != nil {
+			return
+		}
+		w.writers[scanType] = rw
+		w.scanTypes = append(w.scanTypes, scanType)
+	}
+	return rw.Write(ctx, result)
+}
+
+func (w *DemuxWriter) Flush(ctx context.Context) (err error) {
+	for _, scanType := range w.scanTypes {
+		if ferr := w.writers[scanType].Flush(ctx); err == nil {
+			err = ferr
+		}
+	}
+	return
+}
+
+func (w *DemuxWriter) Close() (err error) {
+	for _, scanType := range w.scanTypes {
+		if cerr := w.writers[scanType].Close(); err == nil {
+			err = cerr
+		}
+	}
+	return
+}
+
+// ResultScanType returns the value of the ScanType field of the result, e.g. tcpsyn for TCP SYN scan results,
+// wrapped results like scan.MetaResult are unwrapped. The default type is returned for results without the field.
+func ResultScanType(result scan.Result, defaultType string) string {
+	for {
+		u, ok := result.(interface{ Unwrap() scan.Result })
+		if !ok {
+			break
+		}
+		result = u.Unwrap()
+	}
+	v := reflect.Indirect(reflect.ValueOf(result))
+	if v.Kind() != reflect.Struct {
+		return defaultType
+	}
+	field := v.FieldByName("ScanType")
+	if !field.IsValid() || field.Kind() != reflect.String || len(field.String()) == 0 {
+		return defaultType
+	}
+	return field.String()
+}
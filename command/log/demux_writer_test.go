@@ -0,0 +1,75 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+	"github.com/v-byte-cpu/sx/pkg/scan/tcp"
+)
+
+func TestDemuxWriterRoutesByScanType(t *testing.T) {
+	t.Parallel()
+
+	buffers := make(map[string]*bytes.Buffer)
+	w := NewDemuxWriter("arp", func(scanType string) (ResultWriter, error) {
+		buf := &bytes.Buffer{}
+		buffers[scanType] = buf
+		return NewStreamWriter(buf, &PlainEncoder{}), nil
+	})
+	arpResult := newScanResult(net.IPv4(192, 168, 0, 3).To4())
+	tcpResult := &tcp.ScanResult{ScanType: tcp.SYNScanType, IP: "192.168.0.3", Port: 22}
+	metaResult := &scan.MetaResult{Result: tcpResult, Meta: map[string]interface{}{"kind": "ec2"}}
+
+	ctx := context.Background()
+	require.NoError(t, w.Write(ctx, arpResult))
+	require.NoError(t, w.Write(ctx, tcpResult))
+	require.NoError(t, w.Write(ctx, metaResult))
+	require.NoError(t, w.Flush(ctx))
+
+	require.Len(t, buffers, 2)
+	require.Equal(t, arpResult.String()+"\n", buffers["arp"].String())
+	require.Equal(t, tcpResult.String()+"\n"+metaResult.String()+"\n", buffers[tcp.SYNScanType].String())
+}
+
+func TestDemuxWriterNewWriterError(t *testing.T) {
+	t.Parallel()
+
+	w := NewDemuxWriter("arp", func(string) (ResultWriter, error) {
+		return nil, errors.New("permission denied")
+	})
+	require.Error(t, w.Write(context.Background(), newScanResult(net.IPv4(192, 168, 0, 3).To4())))
+	require.NoError(t, w.Flush(context.Background()))
+}
+
+func TestDemuxWriterFiles(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	w := NewDemuxWriter("arp", func(scanType string) (ResultWriter, error) {
+		return NewFileWriter(filepath.Join(dir, "out-"+scanType+".json"), &JSONEncoder{})
+	})
+	result := &tcp.ScanResult{ScanType: tcp.SYNScanType, IP: "192.168.0.3", Port: 22}
+	require.NoError(t, w.Write(context.Background(), result))
+	require.NoError(t, w.Close())
+
+	data, err := os.ReadFile(filepath.Join(dir, "out-tcpsyn.json"))
+	require.NoError(t, err)
+	require.Equal(t, scanResultToJSON(t, result)+"\n", string(data))
+}
+
+func TestResultScanType(t *testing.T) {
+	t.Parallel()
+
+	tcpResult := &tcp.ScanResult{ScanType: tcp.SYNScanType, IP: "192.168.0.3", Port: 22}
+	require.Equal(t, tcp.SYNScanType, ResultScanType(tcpResult, "default"))
+	require.Equal(t, tcp.SYNScanType, ResultScanType(&scan.MetaResult{Result: tcpResult}, "default"))
+	require.Equal(t, "default", ResultScanType(newScanResult(net.IPv4(192, 168, 0, 3).To4()), "default"))
+	require.Equal(t, "default", ResultScanType(&tcp.ScanResult{}, "default"))
+}
@@ -7,13 +7,13 @@ import (
 	"github.com/v-byte-cpu/sx/pkg/scan"
 )
 
-type JSONResultWriter struct{}
+type JSONEncoder struct{}
 
-func (*JSONResultWriter) Write(w io.Writer, result scan.Result) error {
+func (*JSONEncoder) Encode(w io.Writer, result scan.Result) error {
 	data, err := result.MarshalJSON()
 	if err != nil {
 		return err
 	}
-	fmt.Fprintf(w, "%s\n", data)
-	return nil
+	_, err = fmt.Fprintf(w, "%s\n", data)
+	return err
 }
@@ -7,9 +7,9 @@ import (
 	"github.com/v-byte-cpu/sx/pkg/scan"
 )
 
-type PlainResultWriter struct{}
+type PlainEncoder struct{}
 
-func (*PlainResultWriter) Write(w io.Writer, result scan.Result) error {
+func (*PlainEncoder) Encode(w io.Writer, result scan.Result) error {
 	_, err := fmt.Fprintf(w, "%s\n", result.String())
 	return err
 }
@@ -0,0 +1,84 @@
+package log
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+// ErrorRecord is one line of the error stream
+type ErrorRecord struct {
+	Time time.Time `json:"time"`
+	Scan string    `json:"scan"`
+	// Kind is the kind of the error, e.g. timeout or unreachable, empty for unclassified errors
+	Kind string `json:"kind,omitempty"`
+	IP   string `json:"ip,omitempty"`
+	Name string `json:"name,omitempty"`
+	Port uint16 `json:"port,omitempty"`
+	// Skipped is set if the target was not scanned, e.g. the input line is invalid
+	Skipped bool   `json:"skipped,omitempty"`
+	Error   string `json:"error"`
+}
+
+// ErrorStream writes errors of scans in NDJSON format, it is safe for concurrent use
+type ErrorStream struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+	now func() time.Time
+}
+
+func NewErrorStream(w io.Writer) *ErrorStream {
+	return &ErrorStream{enc: json.NewEncoder(w), now: time.Now}
+}
+
+// Write writes the error of the scan with the given type
+func (s *ErrorStream) Write(scanType string, err error) error {
+	record := &ErrorRecord{
+		Scan:  scanType,
+		Error: err.Error(),
+	}
+	var scanErr *scan.Error
+	if errors.As(err, &scanErr) {
+		record.Kind = scanErr.Kind.Error()
+	}
+	var targetErr *scan.TargetError
+	if errors.As(err, &targetErr) {
+		if targetErr.IP != nil {
+			record.IP = targetErr.IP.String()
+		}
+		record.Name = targetErr.Name
+		record.Port = targetErr.Port
+		record.Skipped = targetErr.Skipped
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	record.Time = s.now()
+	return s.enc.Encode(record)
+}
+
+// ErrorStreamLogger writes errors to the error stream instead of the wrapped logger,
+// errors that can't be written to the stream are passed to the wrapped logger
+type ErrorStreamLogger struct {
+	logger Logger
+	stream *ErrorStream
+	label  string
+}
+
+func NewErrorStreamLogger(logger Logger, stream *ErrorStream, label string) *ErrorStreamLogger {
+	return &ErrorStreamLogger{logger: logger, stream: stream, label: label}
+}
+
+func (l *ErrorStreamLogger) Error(err error) {
+	if werr := l.stream.Write(l.label, err); werr != nil {
+		l.logger.Error(err)
+	}
+}
+
+func (l *ErrorStreamLogger) LogResults(ctx context.Context, results <-chan scan.Result) error {
+	return l.logger.LogResults(ctx, results)
+}
@@ -0,0 +1,101 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+type errorWriter struct{}
+
+func (errorWriter) Write([]byte) (int, error) {
+	return 0, os.ErrClosed
+}
+
+type recordLogger struct {
+	errors []error
+}
+
+func (l *recordLogger) Error(err error) {
+	l.errors = append(l.errors, err)
+}
+
+func (*recordLogger) LogResults(context.Context, <-chan scan.Result) error {
+	return nil
+}
+
+func TestErrorStreamWrite(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		err      error
+		expected string
+	}{
+		{
+			name:     "PlainError",
+			err:      errors.New("BPFFilter: invalid filter"),
+			expected: `{"time":"2024-05-01T10:00:00Z","scan":"tcp","error":"BPFFilter: invalid filter"}`,
+		},
+		{
+			name: "TargetError",
+			err: scan.NewTargetError(&scan.Request{DstIP: net.IPv4(192, 168, 0, 1).To4(), DstPort: 22}, false,
+				scan.NewError(scan.ErrTimeout, errors.New("dial tcp 192.168.0.1:22: i/o timeout"))),
+			expected: `{"time":"2024-05-01T10:00:00Z","scan":"tcp","kind":"timeout","ip":"192.168.0.1","port":22,` +
+				`"error":"dial tcp 192.168.0.1:22: i/o timeout"}`,
+		},
+		{
+			name: "SkippedTarget",
+			err: scan.NewTargetError(&scan.Request{DstName: "example.com", DstPort: 80}, true,
+				scan.NewError(scan.ErrUnreachable, errors.New("no such host"))),
+			expected: `{"time":"2024-05-01T10:00:00Z","scan":"tcp","kind":"unreachable","name":"example.com","port":80,` +
+				`"skipped":true,"error":"no such host"}`,
+		},
+		{
+			name:     "InvalidInput",
+			err:      scan.NewTargetError(&scan.Request{}, true, scan.ErrJSON),
+			expected: `{"time":"2024-05-01T10:00:00Z","scan":"tcp","kind":"parse error","skipped":true,"error":"invalid json"}`,
+		},
+	}
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var buf bytes.Buffer
+			s := NewErrorStream(&buf)
+			s.now = func() time.Time { return time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC) }
+
+			require.NoError(t, s.Write("tcp", tt.err))
+			require.Equal(t, tt.expected+"\n", buf.String())
+		})
+	}
+}
+
+func TestErrorStreamLogger(t *testing.T) {
+	t.Parallel()
+	var buf bytes.Buffer
+	next := &recordLogger{}
+	logger := NewErrorStreamLogger(next, NewErrorStream(&buf), "tcp")
+
+	logger.Error(errors.New("send error"))
+
+	require.Contains(t, buf.String(), `"error":"send error"`)
+	require.Empty(t, next.errors)
+}
+
+func TestErrorStreamLoggerWriteError(t *testing.T) {
+	t.Parallel()
+	next := &recordLogger{}
+	logger := NewErrorStreamLogger(next, NewErrorStream(errorWriter{}), "tcp")
+	err := errors.New("send error")
+
+	logger.Error(err)
+
+	require.Equal(t, []error{err}, next.errors)
+}
@@ -0,0 +1,53 @@
+package log
+
+import (
+	"context"
+
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+// FilterFunc reports whether the result should be logged.
+type FilterFunc func(result scan.Result) bool
+
+type FilterLogger struct {
+	logger Logger
+	filter FilterFunc
+}
+
+func NewFilterLogger(logger Logger, filter FilterFunc) *FilterLogger {
+	return &FilterLogger{logger, filter}
+}
+
+func (l *FilterLogger) Error(err error) {
+	l.logger.Error(err)
+}
+
+func (l *FilterLogger) LogResults(ctx context.Context, results <-chan scan.Result) error {
+	return l.logger.LogResults(ctx, l.filterResults(ctx, results))
+}
+
+func (l *FilterLogger) filterResults(ctx context.Context, in <-chan scan.Result) <-chan scan.Result {
+	results := make(chan scan.Result, cap(in))
+	go func() {
+		defer close(results)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case result, ok := <-in:
+				if !ok {
+					return
+				}
+				if !l.filter(result) {
+					continue
+				}
+				select {
+				case <-ctx.Done():
+					return
+				case results <- result:
+				}
+			}
+		}
+	}()
+	return results
+}
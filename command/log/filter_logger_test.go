@@ -0,0 +1,103 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+	"github.com/v-byte-cpu/sx/pkg/scan/arp"
+)
+
+func TestFilterLoggerResults(t *testing.T) {
+	t.Parallel()
+
+	filter := func(result scan.Result) bool {
+		return strings.HasSuffix(result.(*arp.ScanResult).IP, ".3")
+	}
+	tests := []struct {
+		name     string
+		expected []byte
+		results  []scan.Result
+	}{
+		{
+			name:     "emptyResults",
+			expected: nil,
+			results:  nil,
+		},
+		{
+			name:     "oneMatchedResult",
+			expected: []byte(newScanResult(net.IPv4(192, 168, 0, 3).To4()).String() + "\n"),
+			results: []scan.Result{
+				newScanResult(net.IPv4(192, 168, 0, 3).To4()),
+			},
+		},
+		{
+			name:     "oneFilteredResult",
+			expected: nil,
+			results: []scan.Result{
+				newScanResult(net.IPv4(192, 168, 0, 5).To4()),
+			},
+		},
+		{
+			name: "matchedAndFilteredResults",
+			expected: []byte(strings.Join([]string{
+				newScanResult(net.IPv4(192, 168, 0, 3).To4()).String(),
+				newScanResult(net.IPv4(192, 168, 1, 3).To4()).String(),
+			}, "\n") + "\n"),
+			results: []scan.Result{
+				newScanResult(net.IPv4(192, 168, 0, 3).To4()),
+				newScanResult(net.IPv4(192, 168, 0, 5).To4()),
+				newScanResult(net.IPv4(192, 168, 1, 3).To4()),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			var buf bytes.Buffer
+			plainLogger, err := NewLogger(NewStreamWriter(&buf, &PlainEncoder{}), "arp")
+			require.NoError(t, err)
+			logger := NewFilterLogger(plainLogger, filter)
+
+			resultCh := make(chan scan.Result, len(tt.results))
+			for _, result := range tt.results {
+				resultCh <- result
+			}
+			close(resultCh)
+			require.NoError(t, logger.LogResults(context.Background(), resultCh))
+
+			assert.Equal(t, string(tt.expected), buf.String())
+		})
+	}
+}
+
+func TestFilterLoggerContextExit(t *testing.T) {
+	t.Parallel()
+
+	done := make(chan interface{})
+	go func() {
+		defer close(done)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		var buf bytes.Buffer
+		logger, err := NewLogger(NewStreamWriter(&buf, &PlainEncoder{}), "arp")
+		require.NoError(t, err)
+
+		filterLogger := NewFilterLogger(logger, func(scan.Result) bool { return true })
+		<-filterLogger.filterResults(ctx, nil)
+	}()
+	select {
+	case <-done:
+	case <-time.After(3 * time.Second):
+		require.Fail(t, "test timeout")
+	}
+}
@@ -0,0 +1,693 @@
+package log
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	"github.com/stretchr/testify/require"
+	"github.com/v-byte-cpu/sx/pkg/monitor"
+	"github.com/v-byte-cpu/sx/pkg/policy"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+	"github.com/v-byte-cpu/sx/pkg/scan/adb"
+	"github.com/v-byte-cpu/sx/pkg/scan/amqp"
+	"github.com/v-byte-cpu/sx/pkg/scan/arp"
+	"github.com/v-byte-cpu/sx/pkg/scan/bacnet"
+	"github.com/v-byte-cpu/sx/pkg/scan/cassandra"
+	"github.com/v-byte-cpu/sx/pkg/scan/coap"
+	"github.com/v-byte-cpu/sx/pkg/scan/couchdb"
+	"github.com/v-byte-cpu/sx/pkg/scan/detect"
+	"github.com/v-byte-cpu/sx/pkg/scan/dnp3"
+	"github.com/v-byte-cpu/sx/pkg/scan/dns"
+	"github.com/v-byte-cpu/sx/pkg/scan/docker"
+	"github.com/v-byte-cpu/sx/pkg/scan/elastic"
+	"github.com/v-byte-cpu/sx/pkg/scan/etcd"
+	"github.com/v-byte-cpu/sx/pkg/scan/ftp"
+	"github.com/v-byte-cpu/sx/pkg/scan/http"
+	"github.com/v-byte-cpu/sx/pkg/scan/httpproxy"
+	"github.com/v-byte-cpu/sx/pkg/scan/icmp"
+	"github.com/v-byte-cpu/sx/pkg/scan/influx"
+	"github.com/v-byte-cpu/sx/pkg/scan/ipmi"
+	"github.com/v-byte-cpu/sx/pkg/scan/jarm"
+	"github.com/v-byte-cpu/sx/pkg/scan/k8s"
+	"github.com/v-byte-cpu/sx/pkg/scan/kafka"
+	"github.com/v-byte-cpu/sx/pkg/scan/ldap"
+	"github.com/v-byte-cpu/sx/pkg/scan/mdns"
+	"github.com/v-byte-cpu/sx/pkg/scan/memcached"
+	"github.com/v-byte-cpu/sx/pkg/scan/modbus"
+	"github.com/v-byte-cpu/sx/pkg/scan/mongo"
+	"github.com/v-byte-cpu/sx/pkg/scan/mssql"
+	"github.com/v-byte-cpu/sx/pkg/scan/mysql"
+	"github.com/v-byte-cpu/sx/pkg/scan/natpmp"
+	"github.com/v-byte-cpu/sx/pkg/scan/netbios"
+	"github.com/v-byte-cpu/sx/pkg/scan/ntp"
+	"github.com/v-byte-cpu/sx/pkg/scan/openvpn"
+	"github.com/v-byte-cpu/sx/pkg/scan/postgres"
+	"github.com/v-byte-cpu/sx/pkg/scan/prometheus"
+	"github.com/v-byte-cpu/sx/pkg/scan/rdp"
+	"github.com/v-byte-cpu/sx/pkg/scan/respond"
+	"github.com/v-byte-cpu/sx/pkg/scan/s7"
+	"github.com/v-byte-cpu/sx/pkg/scan/smb"
+	"github.com/v-byte-cpu/sx/pkg/scan/smtp"
+	"github.com/v-byte-cpu/sx/pkg/scan/snmp"
+	"github.com/v-byte-cpu/sx/pkg/scan/socks4"
+	"github.com/v-byte-cpu/sx/pkg/scan/socks5"
+	"github.com/v-byte-cpu/sx/pkg/scan/ssdp"
+	"github.com/v-byte-cpu/sx/pkg/scan/ssh"
+	"github.com/v-byte-cpu/sx/pkg/scan/stun"
+	"github.com/v-byte-cpu/sx/pkg/scan/tcp"
+	"github.com/v-byte-cpu/sx/pkg/scan/tftp"
+	"github.com/v-byte-cpu/sx/pkg/scan/tls"
+	"github.com/v-byte-cpu/sx/pkg/scan/udp"
+	"github.com/v-byte-cpu/sx/pkg/scan/vnc"
+	"github.com/v-byte-cpu/sx/pkg/scan/winrm"
+	"github.com/v-byte-cpu/sx/pkg/scan/wireguard"
+)
+
+// Golden files in testdata/golden hold the expected output of every encoder for every scan type,
+// so that format changes are explicit in code review. Regenerate them after an intended change with
+//
+//	go test ./command/log/ -run TestGolden -update
+var update = flag.Bool("update", false, "update golden files")
+
+var goldenFormats = []struct {
+	ext     string
+	encoder ResultEncoder
+}{
+	{ext: "json", encoder: &JSONEncoder{}},
+	{ext: "txt", encoder: &PlainEncoder{}},
+}
+
+func goldenResults() []struct {
+	name    string
+	results []scan.Result
+} {
+	faviconHash := int32(-1231564551)
+	contentLength := int64(612)
+	notBefore := time.Date(2022, time.January, 1, 0, 0, 0, 0, time.UTC)
+	notAfter := time.Date(2023, time.January, 1, 0, 0, 0, 0, time.UTC)
+	return []struct {
+		name    string
+		results []scan.Result
+	}{
+		{
+			name: "arp",
+			results: []scan.Result{
+				&arp.ScanResult{IP: "192.168.0.1", MAC: "00:11:22:33:44:55", Vendor: "Cisco Systems, Inc"},
+				&arp.ScanResult{IP: "192.168.0.2", MAC: "aa:bb:cc:dd:ee:ff"},
+			},
+		},
+		{
+			name: "icmp",
+			results: []scan.Result{
+				&icmp.ScanResult{ScanType: icmp.ScanType, IP: "192.168.0.1", TTL: 64,
+					ICMP: &icmp.Response{Type: 0, Code: 0}},
+				&icmp.ScanResult{ScanType: icmp.ScanType, IP: "10.0.0.1", TTL: 128,
+					ICMP: &icmp.Response{Type: 14, Code: 0}},
+			},
+		},
+		{
+			name: "udp",
+			results: []scan.Result{
+				&icmp.ScanResult{ScanType: udp.ScanType, IP: "192.168.0.1", TTL: 64,
+					ICMP: &icmp.Response{Type: 3, Code: 3}},
+				&udp.ScanResult{ScanType: udp.ScanType, IP: "192.168.0.2", Port: 53, Matcher: "dns"},
+			},
+		},
+		{
+			name: "tcp",
+			results: []scan.Result{
+				&tcp.ScanResult{ScanType: tcp.SYNScanType, IP: "192.168.0.1", Port: 22},
+				&tcp.ScanResult{ScanType: tcp.FlagsScanType, IP: "192.168.0.1", Port: 8080, Flags: "ar"},
+			},
+		},
+		{
+			name: "socks",
+			results: []scan.Result{
+				&socks5.ScanResult{ScanType: socks5.ScanType, Version: socks5.SOCKSVersion, IP: "192.168.0.1", Port: 1080,
+					Methods: []string{socks5.AuthMethodNoAuth, socks5.AuthMethodUserPass}},
+				&socks5.ScanResult{ScanType: socks5.ScanType, Version: socks5.SOCKSVersion, IP: "192.168.0.4", Port: 1080,
+					Auth: true, Methods: []string{socks5.AuthMethodUserPass}, Username: "admin", Password: "secret"},
+				&socks4.ScanResult{ScanType: socks4.ScanType, Version: socks4.SOCKSVersion, IP: "192.168.0.2", Port: 1080,
+					SOCKS4a: true, Granted: true},
+				&socks4.ScanResult{ScanType: socks4.ScanType, Version: socks4.SOCKSVersion, IP: "192.168.0.3", Port: 4145},
+			},
+		},
+		{
+			name: "httpproxy",
+			results: []scan.Result{
+				&httpproxy.ScanResult{ScanType: httpproxy.ScanType, IP: "192.168.0.1", Port: 3128, Connect: true},
+				&httpproxy.ScanResult{ScanType: httpproxy.ScanType, IP: "192.168.0.2", Port: 8080,
+					Connect: true, Get: true, Anonymity: httpproxy.Transparent},
+				&httpproxy.ScanResult{ScanType: httpproxy.ScanType, IP: "192.168.0.3", Port: 8080,
+					Get: true, Anonymity: httpproxy.Elite},
+			},
+		},
+		{
+			name: "docker",
+			results: []scan.Result{
+				&docker.ScanResult{ScanType: docker.ScanType, Proto: "http", Host: "192.168.0.1:2375",
+					Info: types.Info{Name: "node1", OperatingSystem: "Ubuntu 20.04.2 LTS",
+						KernelVersion: "5.4.0-77-generic", Architecture: "x86_64"},
+					Version: types.Version{Version: "20.10.7", APIVersion: "1.41"}},
+			},
+		},
+		{
+			name: "elastic",
+			results: []scan.Result{
+				&elastic.ScanResult{ScanType: elastic.ScanType, Proto: "http", Host: "192.168.0.1:9200",
+					Info: map[string]interface{}{
+						"cluster_name": "elasticsearch",
+						"version":      map[string]interface{}{"number": "7.13.2"},
+					},
+					Indexes: map[string]interface{}{
+						"logs":  map[string]interface{}{"aliases": map[string]interface{}{}},
+						"users": map[string]interface{}{"aliases": map[string]interface{}{"people": map[string]interface{}{}}},
+					}},
+			},
+		},
+		{
+			name: "etcd",
+			results: []scan.Result{
+				&etcd.ScanResult{ScanType: etcd.ScanType, Proto: "http", Host: "192.168.0.1:2379",
+					Version: "3.4.16", ClusterVersion: "3.4.0", API: "v3", KeysReadable: true, KeyCount: 42,
+					Members: []*etcd.Member{{ID: "8e9e05c52164694d", Name: "default",
+						PeerURLs: []string{"http://10.0.0.1:2380"}, ClientURLs: []string{"http://10.0.0.1:2379"}}}},
+				&etcd.ScanResult{ScanType: etcd.ScanType, Proto: "http", Host: "192.168.0.2:2379",
+					Version: "3.5.0", ClusterVersion: "3.5.0", API: "v3", AuthEnabled: true},
+			},
+		},
+		{
+			name: "couchdb",
+			results: []scan.Result{
+				&couchdb.ScanResult{ScanType: couchdb.ScanType, Proto: "http", Host: "192.168.0.1:5984",
+					Version: "2.3.1", Vendor: "The Apache Software Foundation", Features: []string{"scheduler"},
+					AdminParty: true, DatabasesReadable: true, Databases: []string{"_replicator", "_users", "customers"}},
+				&couchdb.ScanResult{ScanType: couchdb.ScanType, Proto: "http", Host: "192.168.0.2:5984",
+					Version: "3.2.2", Vendor: "The Apache Software Foundation"},
+			},
+		},
+		{
+			name: "influx",
+			results: []scan.Result{
+				&influx.ScanResult{ScanType: influx.ScanType, Proto: "http", Host: "192.168.0.1:8086",
+					Version: "1.8.10", Build: "OSS", DatabasesReadable: true, Databases: []string{"_internal", "telegraf"}},
+				&influx.ScanResult{ScanType: influx.ScanType, Proto: "http", Host: "192.168.0.2:8086",
+					Version: "v2.7.1", Build: "OSS", AuthEnabled: true},
+			},
+		},
+		{
+			name: "prometheus",
+			results: []scan.Result{
+				&prometheus.ScanResult{ScanType: prometheus.ScanType, Proto: "http", Host: "192.168.0.1:9090",
+					Type: "prometheus", Version: "2.30.0", Revision: "9a9d1d", GoVersion: "go1.17.1", Metrics: 812},
+				&prometheus.ScanResult{ScanType: prometheus.ScanType, Proto: "http", Host: "192.168.0.2:9187", Metrics: 12},
+			},
+		},
+		{
+			name: "k8s",
+			results: []scan.Result{
+				&k8s.ScanResult{ScanType: k8s.ScanType, Proto: "https", Host: "192.168.0.1:6443",
+					Component: k8s.ComponentAPIServer, Access: k8s.AccessAnonymous, Version: "v1.21.2", Platform: "linux/amd64"},
+				&k8s.ScanResult{ScanType: k8s.ScanType, Proto: "http", Host: "192.168.0.2:10255",
+					Component: k8s.ComponentKubelet, Access: k8s.AccessRead, Pods: 12},
+			},
+		},
+		{
+			name: "ftp",
+			results: []scan.Result{
+				&ftp.ScanResult{ScanType: ftp.ScanType, IP: "192.168.0.1", Port: 21,
+					Banner: "(vsFTPd 3.0.3)", Anonymous: true, List: []string{
+						"drwxr-xr-x    2 0        0            4096 Jan 01  2021 pub"}},
+				&ftp.ScanResult{ScanType: ftp.ScanType, IP: "192.168.0.2", Port: 21,
+					Banner: "Welcome to Pure-FTPd\nYou are user number 1 of 50 allowed."},
+			},
+		},
+		{
+			name: "smtp",
+			results: []scan.Result{
+				&smtp.ScanResult{ScanType: smtp.ScanType, IP: "192.168.0.1", Port: 25,
+					Banner: "mail.example.org ESMTP Postfix", Extensions: []string{"PIPELINING", "STARTTLS", "AUTH PLAIN LOGIN"},
+					StartTLS: true, Auth: []string{"PLAIN", "LOGIN"}},
+				&smtp.ScanResult{ScanType: smtp.ScanType, IP: "192.168.0.2", Port: 25,
+					Banner: "relay.example.org ESMTP", OpenRelay: true},
+			},
+		},
+		{
+			name: "mongo",
+			results: []scan.Result{
+				&mongo.ScanResult{ScanType: mongo.ScanType, IP: "192.168.0.1", Port: 27017,
+					Version: "6.0.3", Databases: []string{"admin", "config", "local", "shop"}},
+				&mongo.ScanResult{ScanType: mongo.ScanType, IP: "192.168.0.2", Port: 27017,
+					Version: "5.0.14", SetName: "rs0", AuthRequired: true},
+			},
+		},
+		{
+			name: "memcached",
+			results: []scan.Result{
+				&memcached.ScanResult{ScanType: memcached.ScanType, IP: "192.168.0.1", Port: 11211,
+					Version: "1.6.9", CurrItems: 42, TCP: true},
+				&memcached.ScanResult{ScanType: memcached.ScanType, IP: "192.168.0.2", Port: 11211,
+					Version: "1.4.25", CurrItems: 1024, TCP: true, UDP: true, UDPPackets: 3, UDPBytes: 2960},
+			},
+		},
+		{
+			name: "mysql",
+			results: []scan.Result{
+				&mysql.ScanResult{ScanType: mysql.ScanType, IP: "192.168.0.1", Port: 3306,
+					Version: "8.0.32", ProtocolVersion: 10, AuthPlugin: "caching_sha2_password", TLS: true},
+				&mysql.ScanResult{ScanType: mysql.ScanType, IP: "192.168.0.2", Port: 3306,
+					Error: "1130: Host '10.0.0.1' is not allowed to connect to this MySQL server"},
+			},
+		},
+		{
+			name: "postgres",
+			results: []scan.Result{
+				&postgres.ScanResult{ScanType: postgres.ScanType, IP: "192.168.0.1", Port: 5432,
+					TLS: true, TLSRequired: true, Auth: "scram-sha-256", SASLMechanisms: []string{"SCRAM-SHA-256-PLUS", "SCRAM-SHA-256"}},
+				&postgres.ScanResult{ScanType: postgres.ScanType, IP: "192.168.0.2", Port: 5432,
+					Auth: "trust", Version: "15.2"},
+			},
+		},
+		{
+			name: "cassandra",
+			results: []scan.Result{
+				&cassandra.ScanResult{ScanType: cassandra.ScanType, IP: "192.168.0.1", Port: 9042, ProtocolVersion: 4,
+					CQLVersions: []string{"3.4.5"}, ClusterName: "Test Cluster", Version: "4.1.3"},
+				&cassandra.ScanResult{ScanType: cassandra.ScanType, IP: "192.168.0.2", Port: 9042, ProtocolVersion: 4,
+					CQLVersions: []string{"3.4.4"}, AuthRequired: true, Authenticator: "org.apache.cassandra.auth.PasswordAuthenticator"},
+			},
+		},
+		{
+			name: "kafka",
+			results: []scan.Result{
+				&kafka.ScanResult{ScanType: kafka.ScanType, IP: "192.168.0.1", Port: 9092,
+					APIVersions: []*kafka.APIVersion{{Key: 3, Name: "Metadata", Min: 0, Max: 12}, {Key: 18, Name: "ApiVersions", Min: 0, Max: 3}},
+					ClusterID:   "Xy3qT2pTRa6Fa8MvNQb7sA", Brokers: []string{"kafka-1.local:9092"},
+					TopicsListable: true, Topics: []string{"orders", "events"}},
+				&kafka.ScanResult{ScanType: kafka.ScanType, IP: "192.168.0.2", Port: 9092,
+					APIVersions:  []*kafka.APIVersion{{Key: 3, Name: "Metadata", Min: 0, Max: 12}, {Key: 18, Name: "ApiVersions", Min: 0, Max: 3}},
+					AuthRequired: true},
+			},
+		},
+		{
+			name: "amqp",
+			results: []scan.Result{
+				&amqp.ScanResult{ScanType: amqp.ScanType, IP: "192.168.0.1", Port: 5672, Protocol: "0-9-1",
+					Product: "RabbitMQ", Version: "3.12.4", Platform: "Erlang/OTP 26.0.2", ClusterName: "rabbit@mq1",
+					Mechanisms: []string{"AMQPLAIN", "PLAIN"}, Management: true, ManagementGuest: true},
+				&amqp.ScanResult{ScanType: amqp.ScanType, IP: "192.168.0.2", Port: 5672, Protocol: "1-0-0"},
+			},
+		},
+		{
+			name: "http",
+			results: []scan.Result{
+				&http.ScanResult{ScanType: http.ScanType, Proto: "http", Host: "192.168.0.1:80", Status: 200},
+				&http.ScanResult{ScanType: http.ScanType, Proto: "http", Host: "192.168.0.2:8080", Status: 200,
+					URL: "http://192.168.0.2:8080/login", Server: "nginx/1.18.0", ContentLength: &contentLength,
+					Title: "Welcome to nginx!"},
+				&http.ScanResult{ScanType: http.ScanType, Proto: "https", Host: "example.com:443", Status: 301,
+					FaviconHash: &faviconHash,
+					Paths:       []*http.PathResult{{Path: "/admin", Status: 403}, {Path: "/.git/HEAD", Status: 200}}},
+			},
+		},
+		{
+			name: "detect",
+			results: []scan.Result{
+				&detect.ScanResult{ScanType: detect.ScanType, IP: "192.168.0.1", Port: 2222,
+					Service: detect.ServiceSSH, Banner: "SSH-2.0-OpenSSH_8.9p1"},
+				&detect.ScanResult{ScanType: detect.ScanType, IP: "192.168.0.1", Port: 8443,
+					Service: detect.ServiceHTTPS, TLS: true, Banner: "HTTP/1.1 200 OK"},
+				&detect.ScanResult{ScanType: detect.ScanType, IP: "192.168.0.2", Port: 9000,
+					Service: detect.ServiceUnknown},
+			},
+		},
+		{
+			name: "tls",
+			results: []scan.Result{
+				&tls.ScanResult{ScanType: tls.ScanType, IP: "192.168.0.1", Port: 443, Subject: "CN=example.com",
+					SANs: []string{"example.com", "www.example.com"}, Issuer: "CN=R3,O=Let's Encrypt,C=US",
+					NotBefore: notBefore, NotAfter: notAfter, DaysLeft: 12, Expiring: true,
+					Version: "TLS 1.3", Cipher: "TLS_AES_128_GCM_SHA256"},
+				&tls.ScanResult{ScanType: tls.ScanType, IP: "192.168.0.2", Port: 8443, Subject: "CN=localhost,O=sx",
+					Issuer: "CN=localhost,O=sx", NotBefore: notBefore, NotAfter: notAfter, DaysLeft: 300,
+					Version: "TLS 1.2", Cipher: "TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256"},
+			},
+		},
+		{
+			name: "jarm",
+			results: []scan.Result{
+				&jarm.ScanResult{ScanType: jarm.ScanType, IP: "192.168.0.1", Port: 443,
+					JARM: "3fd3fd00000000000043d43d00043d32c43d8acf7f98719049050401317871"},
+			},
+		},
+		{
+			name: "ssh",
+			results: []scan.Result{
+				&ssh.ScanResult{ScanType: ssh.ScanType, IP: "192.168.0.1", Port: 22,
+					Banner: "SSH-2.0-OpenSSH_8.4p1 Debian-5", Proto: "2.0", Software: "OpenSSH_8.4p1", Comments: "Debian-5",
+					HostKeys: []*ssh.HostKey{
+						{Type: "ssh-ed25519", Fingerprint: "SHA256:nThbg6kXUpJWGl7E1IGOCspRomTxdCARLviKw6E5SY8"},
+					},
+					Algorithms: &ssh.Algorithms{
+						Kex:         []string{"curve25519-sha256", "ecdh-sha2-nistp256"},
+						HostKey:     []string{"rsa-sha2-512", "ssh-ed25519"},
+						Ciphers:     []string{"chacha20-poly1305@openssh.com", "aes128-ctr"},
+						MACs:        []string{"hmac-sha2-256"},
+						Compression: []string{"none"},
+					}},
+				&ssh.ScanResult{ScanType: ssh.ScanType, IP: "192.168.0.2", Port: 2222,
+					Banner: "SSH-2.0-dropbear_2020.81", Proto: "2.0", Software: "dropbear_2020.81"},
+			},
+		},
+		{
+			name: "smb",
+			results: []scan.Result{
+				&smb.ScanResult{ScanType: smb.ScanType, IP: "192.168.0.1", Port: 445,
+					Dialects: []string{"NT LM 0.12", "2.0.2", "2.1"}, SMB1: true, SigningEnabled: true,
+					OS: "Windows 7 Professional 7601 Service Pack 1", LanMan: "Windows 7 Professional 6.1", Domain: "WORKGROUP"},
+				&smb.ScanResult{ScanType: smb.ScanType, IP: "192.168.0.2", Port: 445,
+					Dialects: []string{"2.0.2", "2.1", "3.0", "3.0.2", "3.1.1"}, SigningEnabled: true, SigningRequired: true},
+			},
+		},
+		{
+			name: "ldap",
+			results: []scan.Result{
+				&ldap.ScanResult{ScanType: ldap.ScanType, IP: "192.168.0.1", Port: 389, Anonymous: true,
+					NamingContexts: []string{"dc=example,dc=com"}, LDAPVersions: []string{"2", "3"},
+					Vendor: "OpenLDAP", VendorVersion: "2.6.3"},
+				&ldap.ScanResult{ScanType: ldap.ScanType, IP: "192.168.0.2", Port: 636, TLS: true,
+					BindResult: "inappropriateAuthentication", NamingContexts: []string{"dc=corp,dc=local"}},
+			},
+		},
+		{
+			name: "winrm",
+			results: []scan.Result{
+				&winrm.ScanResult{ScanType: winrm.ScanType, IP: "192.168.0.1", Port: 5985, Proto: "http",
+					ProtocolVersion: "http://schemas.dmtf.org/wbem/wsman/1/wsman.xsd",
+					ProductVendor:   "Microsoft Corporation", ProductVersion: "OS: 0.0.0 SP: 0.0 Stack: 3.0",
+					Auth: []string{"Negotiate", "Kerberos"}, Server: "Microsoft-HTTPAPI/2.0"},
+				&winrm.ScanResult{ScanType: winrm.ScanType, IP: "192.168.0.2", Port: 5985, Proto: "http",
+					Auth: []string{"Negotiate", "Basic"}, Unencrypted: true},
+			},
+		},
+		{
+			name: "rdp",
+			results: []scan.Result{
+				&rdp.ScanResult{ScanType: rdp.ScanType, IP: "192.168.0.1", Port: 3389,
+					Protocols: []string{rdp.SecurityRDP, rdp.SecurityTLS, rdp.SecurityHybrid}, Security: rdp.SecurityRDP},
+				&rdp.ScanResult{ScanType: rdp.ScanType, IP: "192.168.0.2", Port: 3389,
+					Protocols: []string{rdp.SecurityHybrid, rdp.SecurityHybridEx}, Security: rdp.SecurityHybrid, NLA: true},
+			},
+		},
+		{
+			name: "vnc",
+			results: []scan.Result{
+				&vnc.ScanResult{ScanType: vnc.ScanType, IP: "192.168.0.1", Port: 5900,
+					Version: "3.8", SecurityTypes: []string{"none", "vnc"}, NoAuth: true},
+				&vnc.ScanResult{ScanType: vnc.ScanType, IP: "192.168.0.2", Port: 5901,
+					Version: "3.3", Reason: "Too many security failures"},
+			},
+		},
+		{
+			name: "adb",
+			results: []scan.Result{
+				&adb.ScanResult{ScanType: adb.ScanType, IP: "192.168.0.1", Port: 5555, State: adb.StateDevice,
+					System: "device", Name: "sdk_gphone_x86", Model: "Android SDK built for x86", Device: "generic_x86",
+					Features: []string{"shell_v2", "cmd"}, AndroidVersion: "12", SDK: "31"},
+				&adb.ScanResult{ScanType: adb.ScanType, IP: "192.168.0.2", Port: 5555, State: adb.StateAuth},
+			},
+		},
+		{
+			name: "ntp",
+			results: []scan.Result{
+				&ntp.ScanResult{ScanType: ntp.ScanType, IP: "192.168.0.1", Port: 123, Version: 4, Stratum: 2, RefID: "10.0.0.1"},
+				&ntp.ScanResult{ScanType: ntp.ScanType, IP: "192.168.0.2", Port: 123, Version: 2, Stratum: 1, RefID: "GPS",
+					Monlist: true, MonlistPackets: 100, MonlistBytes: 44000},
+			},
+		},
+		{
+			name: "ipmi",
+			results: []scan.Result{
+				&ipmi.ScanResult{ScanType: ipmi.ScanType, IP: "192.168.0.1", Port: 623, Version: "2.0",
+					AuthTypes: []string{"md2", "md5", "password"}, OEMID: 674, RAKPUsers: []string{"ADMIN"}},
+				&ipmi.ScanResult{ScanType: ipmi.ScanType, IP: "192.168.0.2", Port: 623, Version: "2.0",
+					AuthTypes: []string{"none", "md5"}, NullUsers: true, AnonymousLogin: true, CipherZero: true,
+					RAKPUsers: []string{"", "root"}},
+				&ipmi.ScanResult{ScanType: ipmi.ScanType, IP: "192.168.0.3", Port: 623, Version: "1.5",
+					AuthTypes: []string{"md5", "password"}},
+			},
+		},
+		{
+			name: "bacnet",
+			results: []scan.Result{
+				&bacnet.ScanResult{ScanType: bacnet.ScanType, IP: "192.168.0.1", Port: 47808, Instance: 389001,
+					ObjectName: "AHU-3 Controller", VendorID: 7, VendorName: "Siemens Building Technologies",
+					ModelName: "PXC Modular", Firmware: "3.2.1", Software: "PXC 3.2"},
+				&bacnet.ScanResult{ScanType: bacnet.ScanType, IP: "192.168.0.2", Port: 47808, Instance: 12, VendorID: 260},
+			},
+		},
+		{
+			name: "modbus",
+			results: []scan.Result{
+				&modbus.ScanResult{ScanType: modbus.ScanType, IP: "192.168.0.1", Port: 502, UnitID: 255,
+					Vendor: "Schneider Electric", ProductCode: "BMX P34 2020", Revision: "v2.70"},
+				&modbus.ScanResult{ScanType: modbus.ScanType, IP: "192.168.0.2", Port: 502, UnitID: 1,
+					Exception: "illegal function"},
+			},
+		},
+		{
+			name: "s7",
+			results: []scan.Result{
+				&s7.ScanResult{ScanType: s7.ScanType, IP: "192.168.0.1", Port: 102, ModuleType: "CPU 315-2 PN/DP",
+					Module: "6ES7 315-2EH14-0AB0", Serial: "S C-C2UR28922012", Firmware: "3.2.6",
+					SystemName: "SIMATIC 300(1)", PlantID: "Line 4"},
+				&s7.ScanResult{ScanType: s7.ScanType, IP: "192.168.0.2", Port: 102, Module: "6ES7 214-1AG40-0XB0",
+					Firmware: "4.2.1"},
+			},
+		},
+		{
+			name: "dnp3",
+			results: []scan.Result{
+				&dnp3.ScanResult{ScanType: dnp3.ScanType, IP: "192.168.0.1", Port: 20000, Addresses: []uint16{1, 10}},
+				&dnp3.ScanResult{ScanType: dnp3.ScanType, IP: "192.168.0.2", Port: 20000, Addresses: []uint16{1024}},
+			},
+		},
+		{
+			name: "mssql",
+			results: []scan.Result{
+				&mssql.ScanResult{ScanType: mssql.ScanType, IP: "192.168.0.1", Port: 1434, Instances: []*mssql.Instance{
+					{ServerName: "DB01", Name: "MSSQLSERVER", Version: "15.0.2000.5", TCPPort: 1433,
+						PreloginVersion: "15.0.2000", Encryption: "off"},
+					{ServerName: "DB01", Name: "SQLEXPRESS", Version: "14.0.1000.169",
+						NamedPipe: "\\\\DB01\\pipe\\MSSQL$SQLEXPRESS\\sql\\query"},
+				}},
+			},
+		},
+		{
+			name: "snmp",
+			results: []scan.Result{
+				&snmp.ScanResult{ScanType: snmp.ScanType, IP: "192.168.0.1", Port: 161, Version: snmp.Version2c,
+					Communities: []string{"public", "private"}, SysDescr: "Linux router 5.10.0 #1 SMP", SysName: "router"},
+				&snmp.ScanResult{ScanType: snmp.ScanType, IP: "192.168.0.2", Port: 161, Version: snmp.Version1,
+					Communities: []string{"public"}},
+			},
+		},
+		{
+			name: "ssdp",
+			results: []scan.Result{
+				&ssdp.ScanResult{ScanType: ssdp.ScanType, IP: "192.168.0.1", Port: 1900,
+					Location: "http://192.168.0.1:49152/rootDesc.xml", Server: "Linux/5.4 UPnP/1.1 MiniUPnPd/2.2.1",
+					Services: []string{"upnp:rootdevice", "urn:schemas-upnp-org:device:InternetGatewayDevice:1"}},
+				&ssdp.ScanResult{ScanType: ssdp.ScanType, IP: "192.168.0.2", Port: 1900,
+					Location: "http://192.168.0.2:8060/"},
+			},
+		},
+		{
+			name: "mdns",
+			results: []scan.Result{
+				&mdns.ScanResult{ScanType: mdns.ScanType, IP: "192.168.0.1", Port: 5353,
+					Hostname: "printer.local.", Services: []string{"_ipp._tcp.local.", "_http._tcp.local."}},
+				&mdns.ScanResult{ScanType: mdns.ScanType, IP: "192.168.0.2", Port: 5353, Hostname: "tv.local."},
+			},
+		},
+		{
+			name: "netbios",
+			results: []scan.Result{
+				&netbios.ScanResult{ScanType: netbios.ScanType, IP: "192.168.0.1", Port: 137,
+					Name: "DESKTOP-01", Workgroup: "WORKGROUP", MAC: "00:0c:29:01:02:03"},
+				&netbios.ScanResult{ScanType: netbios.ScanType, IP: "192.168.0.2", Port: 137, Name: "NAS"},
+			},
+		},
+		{
+			name: "coap",
+			results: []scan.Result{
+				&coap.ScanResult{ScanType: coap.ScanType, IP: "192.168.0.1", Port: 5683, Code: "2.05",
+					Resources: []*coap.Resource{
+						{URI: "/sensors/temp", ResourceType: "temperature-c", Interface: "sensor", Observable: true},
+						{URI: "/light"},
+					}},
+				&coap.ScanResult{ScanType: coap.ScanType, IP: "192.168.0.2", Port: 5683, Code: "4.04"},
+			},
+		},
+		{
+			name: "tftp",
+			results: []scan.Result{
+				&tftp.ScanResult{ScanType: tftp.ScanType, IP: "192.168.0.1", Port: 69, Files: []*tftp.File{
+					{Name: "pxelinux.0", Readable: true},
+					{Name: "startup-config", ErrorCode: 1, Error: "File not found"},
+				}},
+				&tftp.ScanResult{ScanType: tftp.ScanType, IP: "192.168.0.2", Port: 69, Files: []*tftp.File{
+					{Name: "startup-config", ErrorCode: 2, Error: "Access violation"},
+				}},
+			},
+		},
+		{
+			name: "openvpn",
+			results: []scan.Result{
+				&openvpn.ScanResult{ScanType: openvpn.ScanType, IP: "192.168.0.1", Port: 1194,
+					SessionID: "a1a2a3a4a5a6a7a8", Acked: true},
+				&openvpn.ScanResult{ScanType: openvpn.ScanType, IP: "192.168.0.2", Port: 1194,
+					SessionID: "0102030405060708"},
+			},
+		},
+		{
+			name: "wireguard",
+			results: []scan.Result{
+				&wireguard.ScanResult{ScanType: wireguard.ScanType, IP: "192.168.0.1", Port: 51820,
+					State: wireguard.StateHandshake, Keys: true},
+				&wireguard.ScanResult{ScanType: wireguard.ScanType, IP: "192.168.0.2", Port: 51820,
+					State: wireguard.StateOpenFiltered},
+			},
+		},
+		{
+			name: "stun",
+			results: []scan.Result{
+				&stun.ScanResult{ScanType: stun.ScanType, IP: "192.168.0.1", Port: 3478,
+					MappedAddress: "203.0.113.5:40000", Software: "Coturn-4.5.2 'dan Eider'"},
+				&stun.ScanResult{ScanType: stun.ScanType, IP: "192.168.0.2", Port: 3478, Error: "401 Unauthorized"},
+			},
+		},
+		{
+			name: "natpmp",
+			results: []scan.Result{
+				&natpmp.ScanResult{ScanType: natpmp.ScanType, IP: "192.168.0.1", Port: 5351,
+					NATPMP: "SUCCESS", ExternalIP: "203.0.113.5", PCP: "ADDRESS_MISMATCH", Epoch: 3600},
+				&natpmp.ScanResult{ScanType: natpmp.ScanType, IP: "192.168.0.2", Port: 5351,
+					NATPMP: "NOT_AUTHORIZED", Epoch: 86400},
+			},
+		},
+		{
+			name: "dns",
+			results: []scan.Result{
+				&dns.ResolverResult{ScanType: dns.ResolverScanType, IP: "192.168.0.1", Port: 53, Recursive: true,
+					RCode: "Success", Answers: []string{"CNAME www.example.com.", "A 93.184.216.34"}},
+				&dns.ResolverResult{ScanType: dns.ResolverScanType, IP: "192.168.0.2", Port: 53, RCode: "Refused"},
+			},
+		},
+		{
+			name: "dnsrecord",
+			results: []scan.Result{
+				&dns.RecordResult{ScanType: dns.RecordScanType, Name: "example.com", Type: "A", TTL: 300, Value: "93.184.216.34"},
+				&dns.RecordResult{ScanType: dns.RecordScanType, Name: "example.com", Type: "MX", TTL: 3600, Value: "10 mail.example.com."},
+			},
+		},
+		{
+			name: "respond",
+			results: []scan.Result{
+				&respond.Result{Proto: respond.ProtoARP, SrcIP: "192.168.0.3", DstIP: "10.0.0.5", Reply: respond.ReplyARP},
+				&respond.Result{Proto: respond.ProtoTCP, SrcIP: "192.168.0.3", DstIP: "10.0.0.5", DstPort: 22,
+					Reply: respond.ReplySYNACK},
+				&respond.Result{Proto: respond.ProtoTCP, SrcIP: "192.168.0.3", DstIP: "10.0.0.5", DstPort: 23,
+					Reply: respond.ReplyRST},
+			},
+		},
+		{
+			name: "policy",
+			results: []scan.Result{
+				&policy.Violation{ScanType: policy.ScanType, Violation: policy.UnexpectedOpen,
+					IP: "192.168.0.1", Port: 23, Rule: "web"},
+				&policy.Violation{ScanType: policy.ScanType, Violation: policy.ExpectedClosed,
+					IP: "192.168.0.2", Port: 443},
+			},
+		},
+		{
+			name: "monitor",
+			results: []scan.Result{
+				&monitor.Event{ScanType: monitor.ScanType, Event: monitor.Closed, Target: "192.168.0.1:8080",
+					FirstSeen: time.Date(2021, 5, 1, 10, 0, 0, 0, time.UTC),
+					LastSeen:  time.Date(2021, 5, 3, 18, 30, 0, 0, time.UTC), MissedRuns: 3},
+			},
+		},
+		{
+			name: "meta",
+			results: []scan.Result{
+				&scan.MetaResult{
+					Result: &tcp.ScanResult{ScanType: tcp.SYNScanType, IP: "192.168.0.1", Port: 22},
+					Meta:   map[string]interface{}{"env": "prod", "name": "web-1"},
+				},
+			},
+		},
+	}
+}
+
+func TestGoldenOutput(t *testing.T) {
+	t.Parallel()
+	for _, vtt := range goldenResults() {
+		tt := vtt
+		for _, vf := range goldenFormats {
+			f := vf
+			t.Run(tt.name+"/"+f.ext, func(t *testing.T) {
+				t.Parallel()
+				var buf bytes.Buffer
+				for _, result := range tt.results {
+					require.NoError(t, f.encoder.Encode(&buf, result))
+				}
+
+				path := filepath.Join("testdata", "golden", tt.name+"."+f.ext)
+				if *update {
+					require.NoError(t, os.WriteFile(path, buf.Bytes(), 0o644))
+					return
+				}
+				expected, err := os.ReadFile(path)
+				require.NoError(t, err, "run with -update to create golden files")
+				require.Equal(t, string(expected), buf.String(), "output differs from %s", path)
+			})
+		}
+	}
+}
+
+func TestGoldenProxyOutput(t *testing.T) {
+	t.Parallel()
+	for _, vtt := range goldenResults() {
+		tt := vtt
+		if tt.name != "socks" && tt.name != "httpproxy" {
+			continue
+		}
+		for _, vformat := range []string{ProxyFormatList, ProxyFormatProxyChains, ProxyFormatURI} {
+			format := vformat
+			t.Run(tt.name+"/"+format, func(t *testing.T) {
+				t.Parallel()
+				enc, err := NewProxyEncoder(format)
+				require.NoError(t, err)
+				var buf bytes.Buffer
+				for _, result := range tt.results {
+					require.NoError(t, enc.Encode(&buf, result))
+				}
+
+				path := filepath.Join("testdata", "golden", tt.name+".proxy-"+format)
+				if *update {
+					require.NoError(t, os.WriteFile(path, buf.Bytes(), 0o644))
+					return
+				}
+				expected, err := os.ReadFile(path)
+				require.NoError(t, err, "run with -update to create golden files")
+				require.Equal(t, string(expected), buf.String(), "output differs from %s", path)
+			})
+		}
+	}
+}
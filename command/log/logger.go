@@ -1,9 +1,7 @@
 package log
 
 import (
-	"bufio"
 	"context"
-	"io"
 	"time"
 
 	"github.com/v-byte-cpu/sx/pkg/scan"
@@ -12,48 +10,28 @@ import (
 
 type Logger interface {
 	Error(err error)
-	LogResults(ctx context.Context, results <-chan scan.Result)
-}
-
-type FlushWriter interface {
-	io.Writer
-	Flush() error
-}
-
-type ResultWriter interface {
-	Write(w io.Writer, result scan.Result) error
+	// LogResults writes results until the channel is closed or ctx is done,
+	// the first write error stops logging and is returned
+	LogResults(ctx context.Context, results <-chan scan.Result) error
 }
 
 type logger struct {
 	zapl  *zap.Logger
 	label string
 
-	w             io.Writer
 	rw            ResultWriter
 	flushInterval time.Duration
 }
 
 type LoggerOption func(*logger)
 
-func JSON() LoggerOption {
-	return func(l *logger) {
-		l.rw = &JSONResultWriter{}
-	}
-}
-
-func Plain() LoggerOption {
-	return func(l *logger) {
-		l.rw = &PlainResultWriter{}
-	}
-}
-
 func FlushInterval(interval time.Duration) LoggerOption {
 	return func(l *logger) {
 		l.flushInterval = interval
 	}
 }
 
-func NewLogger(w io.Writer, label string, opts ...LoggerOption) (Logger, error) {
+func NewLogger(rw ResultWriter, label string, opts ...LoggerOption) (Logger, error) {
 	zapl, err := zap.NewProduction()
 	if err != nil {
 		return nil, err
@@ -62,8 +40,7 @@ func NewLogger(w io.Writer, label string, opts ...LoggerOption) (Logger, error)
 	l := &logger{
 		zapl:          zapl,
 		label:         label,
-		rw:            &PlainResultWriter{},
-		w:             w,
+		rw:            rw,
 		flushInterval: 1 * time.Second,
 	}
 	for _, o := range opts {
@@ -76,10 +53,13 @@ func (l *logger) Error(err error) {
 	l.zapl.Error(l.label, zap.Error(err))
 }
 
-func (l *logger) LogResults(ctx context.Context, results <-chan scan.Result) {
-	bw := bufio.NewWriter(l.w)
-	defer bw.Flush()
-	var err error
+func (l *logger) LogResults(ctx context.Context, results <-chan scan.Result) (err error) {
+	defer func() {
+		// results written before ctx is done must reach the output anyway
+		if ferr := l.rw.Flush(context.Background()); err == nil {
+			err = ferr
+		}
+	}()
 	timec := time.After(l.flushInterval)
 	for {
 		select {
@@ -89,12 +69,12 @@ func (l *logger) LogResults(ctx context.Context, results <-chan scan.Result) {
 			if !ok {
 				return
 			}
-			if err := l.rw.Write(l.w, result); err != nil {
-				l.Error(err)
+			if err = l.rw.Write(ctx, result); err != nil {
+				return
 			}
 		case <-timec:
-			if err = bw.Flush(); err != nil {
-				l.Error(err)
+			if err = l.rw.Flush(ctx); err != nil {
+				return
 			}
 			timec = time.After(l.flushInterval)
 		}
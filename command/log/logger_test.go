@@ -3,6 +3,7 @@ package log
 import (
 	"bytes"
 	"context"
+	"io"
 	"net"
 	"strings"
 	"testing"
@@ -12,6 +13,8 @@ import (
 	"github.com/stretchr/testify/require"
 	"github.com/v-byte-cpu/sx/pkg/scan"
 	"github.com/v-byte-cpu/sx/pkg/scan/arp"
+	"github.com/v-byte-cpu/sx/pkg/scan/socks5"
+	"github.com/v-byte-cpu/sx/pkg/scan/tcp"
 )
 
 func scanResultToJSON(t *testing.T, result scan.Result) string {
@@ -58,7 +61,7 @@ func TestJSONLoggerResults(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 
 			var buf bytes.Buffer
-			logger, err := NewLogger(&buf, "arp", JSON())
+			logger, err := NewLogger(NewStreamWriter(&buf, &JSONEncoder{}), "arp")
 			require.NoError(t, err)
 
 			resultCh := make(chan scan.Result, len(tt.results))
@@ -66,7 +69,7 @@ func TestJSONLoggerResults(t *testing.T) {
 				resultCh <- result
 			}
 			close(resultCh)
-			logger.LogResults(context.Background(), resultCh)
+			require.NoError(t, logger.LogResults(context.Background(), resultCh))
 
 			assert.Equal(t, string(tt.expected), buf.String())
 		})
@@ -118,7 +121,7 @@ func TestPlainLoggerResults(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 
 			var buf bytes.Buffer
-			logger, err := NewLogger(&buf, "arp", Plain())
+			logger, err := NewLogger(NewStreamWriter(&buf, &PlainEncoder{}), "arp")
 			require.NoError(t, err)
 
 			resultCh := make(chan scan.Result, len(tt.results))
@@ -126,7 +129,7 @@ func TestPlainLoggerResults(t *testing.T) {
 				resultCh <- result
 			}
 			close(resultCh)
-			logger.LogResults(context.Background(), resultCh)
+			require.NoError(t, logger.LogResults(context.Background(), resultCh))
 
 			assert.Equal(t, string(tt.expected), buf.String())
 		})
@@ -144,10 +147,10 @@ func TestLoggerContextExit(t *testing.T) {
 		cancel()
 
 		var buf bytes.Buffer
-		logger, err := NewLogger(&buf, "arp", Plain())
+		logger, err := NewLogger(NewStreamWriter(&buf, &PlainEncoder{}), "arp")
 		require.NoError(t, err)
 
-		logger.LogResults(ctx, nil)
+		require.NoError(t, logger.LogResults(ctx, nil))
 	}()
 	select {
 	case <-done:
@@ -155,3 +158,66 @@ func TestLoggerContextExit(t *testing.T) {
 		require.Fail(t, "test timeout")
 	}
 }
+
+func TestLoggerWriteError(t *testing.T) {
+	t.Parallel()
+
+	done := make(chan interface{})
+	go func() {
+		defer close(done)
+
+		logger, err := NewLogger(NewStreamWriter(&errWriter{}, &PlainEncoder{}), "arp",
+			FlushInterval(10*time.Millisecond))
+		require.NoError(t, err)
+
+		// the channel is not closed, logging is stopped by the flush error
+		resultCh := make(chan scan.Result, 1)
+		resultCh <- newScanResult(net.IPv4(192, 168, 0, 3).To4())
+		require.Error(t, logger.LogResults(context.Background(), resultCh))
+	}()
+	select {
+	case <-done:
+	case <-time.After(3 * time.Second):
+		require.Fail(t, "test timeout")
+	}
+}
+
+func BenchmarkResultEncoder(b *testing.B) {
+	results := []struct {
+		name   string
+		result scan.Result
+	}{
+		{
+			name:   "arp",
+			result: &arp.ScanResult{IP: "192.168.0.1", MAC: "00:11:22:33:44:55", Vendor: "Cisco Systems, Inc"},
+		},
+		{
+			name:   "tcp",
+			result: &tcp.ScanResult{ScanType: tcp.SYNScanType, IP: "192.168.0.1", Port: 22},
+		},
+		{
+			name:   "socks",
+			result: &socks5.ScanResult{ScanType: socks5.ScanType, Version: socks5.SOCKSVersion, IP: "192.168.0.1", Port: 1080},
+		},
+	}
+	encoders := []struct {
+		name    string
+		encoder ResultEncoder
+	}{
+		{name: "json", encoder: &JSONEncoder{}},
+		{name: "plain", encoder: &PlainEncoder{}},
+	}
+	for _, e := range encoders {
+		for _, r := range results {
+			encoder, result := e.encoder, r.result
+			b.Run(e.name+"/"+r.name, func(b *testing.B) {
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					if err := encoder.Encode(io.Discard, result); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package log
+
+import (
+	"context"
+
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+// MergeLogger logs results produced outside of the scan engine along with scan results,
+// e.g. events of the monitoring mode
+type MergeLogger struct {
+	logger Logger
+	extra  <-chan scan.Result
+}
+
+func NewMergeLogger(logger Logger, extra <-chan scan.Result) *MergeLogger {
+	return &MergeLogger{logger, extra}
+}
+
+func (l *MergeLogger) Error(err error) {
+	l.logger.Error(err)
+}
+
+func (l *MergeLogger) LogResults(ctx context.Context, results <-chan scan.Result) error {
+	return l.logger.LogResults(ctx, l.mergeResults(ctx, results))
+}
+
+// mergeResults forwards results until the scan results channel is closed,
+// extra results that are pending at that moment are forwarded too
+func (l *MergeLogger) mergeResults(ctx context.Context, in <-chan scan.Result) <-chan scan.Result {
+	results := make(chan scan.Result, cap(in))
+	go func() {
+		defer close(results)
+		var result scan.Result
+		var ok bool
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case result = <-l.extra:
+			case result, ok = <-in:
+				if !ok {
+					l.drainExtra(ctx, results)
+					return
+				}
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case results <- result:
+			}
+		}
+	}()
+	return results
+}
+
+func (l *MergeLogger) drainExtra(ctx context.Context, results chan<- scan.Result) {
+	for {
+		select {
+		case result := <-l.extra:
+			select {
+			case <-ctx.Done():
+				return
+			case results <- result:
+			}
+		default:
+			return
+		}
+	}
+}
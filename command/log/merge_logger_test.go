@@ -0,0 +1,76 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+func TestMergeLoggerResults(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	plainLogger, err := NewLogger(NewStreamWriter(&buf, &PlainEncoder{}), "arp")
+	require.NoError(t, err)
+	extra := make(chan scan.Result, 1)
+	logger := NewMergeLogger(plainLogger, extra)
+
+	resultCh := make(chan scan.Result)
+	done := make(chan error, 1)
+	go func() {
+		done <- logger.LogResults(context.Background(), resultCh)
+	}()
+	resultCh <- newScanResult(net.IPv4(192, 168, 0, 1).To4())
+	extra <- newScanResult(net.IPv4(192, 168, 0, 2).To4())
+	// wait for the extra result to be forwarded before the next scan result
+	for len(extra) > 0 {
+		time.Sleep(time.Millisecond)
+	}
+	resultCh <- newScanResult(net.IPv4(192, 168, 0, 3).To4())
+	// the pending extra result is logged after scan results are closed
+	extra <- newScanResult(net.IPv4(192, 168, 0, 4).To4())
+	close(resultCh)
+
+	select {
+	case err = <-done:
+		require.NoError(t, err)
+	case <-time.After(3 * time.Second):
+		require.Fail(t, "test timeout")
+	}
+	require.Equal(t, strings.Join([]string{
+		newScanResult(net.IPv4(192, 168, 0, 1).To4()).String(),
+		newScanResult(net.IPv4(192, 168, 0, 2).To4()).String(),
+		newScanResult(net.IPv4(192, 168, 0, 3).To4()).String(),
+		newScanResult(net.IPv4(192, 168, 0, 4).To4()).String(),
+	}, "\n")+"\n", buf.String())
+}
+
+func TestMergeLoggerContextExit(t *testing.T) {
+	t.Parallel()
+
+	done := make(chan interface{})
+	go func() {
+		defer close(done)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		var buf bytes.Buffer
+		logger, err := NewLogger(NewStreamWriter(&buf, &PlainEncoder{}), "arp")
+		require.NoError(t, err)
+
+		mergeLogger := NewMergeLogger(logger, nil)
+		<-mergeLogger.mergeResults(ctx, nil)
+	}()
+	select {
+	case <-done:
+	case <-time.After(3 * time.Second):
+		require.Fail(t, "test timeout")
+	}
+}
@@ -0,0 +1,40 @@
+package log
+
+import (
+	"context"
+
+	"github.com/v-byte-cpu/sx/pkg/redact"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+// RedactWriter anonymizes results before they are written to rw, e.g. to share them outside of the organization
+type RedactWriter struct {
+	rw       ResultWriter
+	redactor *redact.Redactor
+	scanType string
+}
+
+// Assert that log.RedactWriter conforms to the log.ResultWriter interface
+var _ ResultWriter = (*RedactWriter)(nil)
+
+// NewRedactWriter creates the writer of redacted results to rw,
+// scanType is the type of results that don't have their own scan type
+func NewRedactWriter(rw ResultWriter, redactor *redact.Redactor, scanType string) *RedactWriter {
+	return &RedactWriter{rw: rw, redactor: redactor, scanType: scanType}
+}
+
+func (w *RedactWriter) Write(ctx context.Context, result scan.Result) error {
+	redacted, err := w.redactor.Redact(result, ResultScanType(result, w.scanType))
+	if err != nil {
+		return err
+	}
+	return w.rw.Write(ctx, redacted)
+}
+
+func (w *RedactWriter) Flush(ctx context.Context) error {
+	return w.rw.Flush(ctx)
+}
+
+func (w *RedactWriter) Close() error {
+	return w.rw.Close()
+}
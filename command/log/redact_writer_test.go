@@ -0,0 +1,59 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/v-byte-cpu/sx/pkg/redact"
+	"github.com/v-byte-cpu/sx/pkg/scan/tcp"
+)
+
+func TestRedactWriter(t *testing.T) {
+	t.Parallel()
+
+	redactor, err := redact.NewRedactor(redact.WithIPMode(redact.IPTruncate), redact.WithDroppedFields([]string{"mac"}))
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	w := NewRedactWriter(NewStreamWriter(&buf, &JSONEncoder{}), redactor, "arp")
+	require.NoError(t, w.Write(context.Background(), newScanResult(net.IPv4(192, 168, 0, 3).To4())))
+	require.NoError(t, w.Flush(context.Background()))
+	require.NoError(t, w.Close())
+
+	require.NotContains(t, buf.String(), "192.168.0.3")
+	require.NotContains(t, buf.String(), `"mac"`)
+	require.Contains(t, buf.String(), `"ip":"192.168.0.0"`)
+}
+
+func TestRedactWriterSplitOutput(t *testing.T) {
+	t.Parallel()
+
+	redactor, err := redact.NewRedactor(redact.WithIPMode(redact.IPTruncate))
+	require.NoError(t, err)
+	dir := t.TempDir()
+	w := NewRedactWriter(NewDemuxWriter("arp", func(scanType string) (ResultWriter, error) {
+		return NewFileWriter(filepath.Join(dir, "out-"+scanType+".txt"), &PlainEncoder{})
+	}), redactor, "arp")
+	result := &tcp.ScanResult{ScanType: tcp.SYNScanType, IP: "192.168.0.3", Port: 22}
+	require.NoError(t, w.Write(context.Background(), result))
+	require.NoError(t, w.Close())
+
+	data, err := os.ReadFile(filepath.Join(dir, "out-tcpsyn.txt"))
+	require.NoError(t, err)
+	require.Contains(t, string(data), "192.168.0.0")
+	require.NotContains(t, string(data), "192.168.0.3")
+}
+
+func TestRedactWriterError(t *testing.T) {
+	t.Parallel()
+
+	redactor, err := redact.NewRedactor()
+	require.NoError(t, err)
+	w := NewRedactWriter(NewStreamWriter(&errWriter{}, &JSONEncoder{}), redactor, "arp")
+	require.NoError(t, w.Write(context.Background(), newScanResult(net.IPv4(192, 168, 0, 3).To4())))
+	require.Error(t, w.Close())
+}
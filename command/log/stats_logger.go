@@ -0,0 +1,50 @@
+package log
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+// StatsLogger counts logged results and errors
+type StatsLogger struct {
+	logger Logger
+	// count accepts results that are counted, all results are logged
+	count   func(result scan.Result) bool
+	results int64
+	errors  int64
+}
+
+func NewStatsLogger(logger Logger) *StatsLogger {
+	return NewCountStatsLogger(logger, func(scan.Result) bool { return true })
+}
+
+// NewCountStatsLogger counts only results accepted by the count function, e.g. open ports among other replies
+func NewCountStatsLogger(logger Logger, count func(result scan.Result) bool) *StatsLogger {
+	return &StatsLogger{logger: logger, count: count}
+}
+
+func (l *StatsLogger) Error(err error) {
+	atomic.AddInt64(&l.errors, 1)
+	l.logger.Error(err)
+}
+
+func (l *StatsLogger) LogResults(ctx context.Context, results <-chan scan.Result) error {
+	return NewFilterLogger(l.logger, func(result scan.Result) bool {
+		if l.count(result) {
+			atomic.AddInt64(&l.results, 1)
+		}
+		return true
+	}).LogResults(ctx, results)
+}
+
+// Results returns the number of logged results
+func (l *StatsLogger) Results() int64 {
+	return atomic.LoadInt64(&l.results)
+}
+
+// Errors returns the number of logged errors
+func (l *StatsLogger) Errors() int64 {
+	return atomic.LoadInt64(&l.errors)
+}
@@ -0,0 +1,63 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+func TestStatsLogger(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	plainLogger, err := NewLogger(NewStreamWriter(&buf, &PlainEncoder{}), "arp")
+	require.NoError(t, err)
+	logger := NewStatsLogger(plainLogger)
+
+	results := []scan.Result{
+		newScanResult(net.IPv4(192, 168, 0, 3).To4()),
+		newScanResult(net.IPv4(192, 168, 0, 5).To4()),
+	}
+	resultCh := make(chan scan.Result, len(results))
+	for _, result := range results {
+		resultCh <- result
+	}
+	close(resultCh)
+	require.NoError(t, logger.LogResults(context.Background(), resultCh))
+	logger.Error(errors.New("scan error"))
+
+	require.Equal(t, int64(2), logger.Results())
+	require.Equal(t, int64(1), logger.Errors())
+	require.Equal(t, results[0].String()+"\n"+results[1].String()+"\n", buf.String())
+}
+
+func TestCountStatsLogger(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	plainLogger, err := NewLogger(NewStreamWriter(&buf, &PlainEncoder{}), "arp")
+	require.NoError(t, err)
+	countIP := net.IPv4(192, 168, 0, 5).To4()
+	logger := NewCountStatsLogger(plainLogger, func(result scan.Result) bool {
+		return result.ID() == countIP.String()
+	})
+
+	results := []scan.Result{
+		newScanResult(net.IPv4(192, 168, 0, 3).To4()),
+		newScanResult(countIP),
+	}
+	resultCh := make(chan scan.Result, len(results))
+	for _, result := range results {
+		resultCh <- result
+	}
+	close(resultCh)
+	require.NoError(t, logger.LogResults(context.Background(), resultCh))
+
+	require.Equal(t, int64(1), logger.Results())
+	require.Equal(t, results[0].String()+"\n"+results[1].String()+"\n", buf.String())
+}
@@ -18,8 +18,8 @@ func (l *UniqueLogger) Error(err error) {
 	l.logger.Error(err)
 }
 
-func (l *UniqueLogger) LogResults(ctx context.Context, results <-chan scan.Result) {
-	l.logger.LogResults(ctx, l.uniqResults(ctx, results))
+func (l *UniqueLogger) LogResults(ctx context.Context, results <-chan scan.Result) error {
+	return l.logger.LogResults(ctx, l.uniqResults(ctx, results))
 }
 
 func (*UniqueLogger) uniqResults(ctx context.Context, in <-chan scan.Result) <-chan scan.Result {
@@ -70,7 +70,7 @@ func TestUniqueLoggerResults(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 
 			var buf bytes.Buffer
-			plainLogger, err := NewLogger(&buf, "arp")
+			plainLogger, err := NewLogger(NewStreamWriter(&buf, &PlainEncoder{}), "arp")
 			require.NoError(t, err)
 			logger := NewUniqueLogger(plainLogger)
 
@@ -79,7 +79,7 @@ func TestUniqueLoggerResults(t *testing.T) {
 				resultCh <- result
 			}
 			close(resultCh)
-			logger.LogResults(context.Background(), resultCh)
+			require.NoError(t, logger.LogResults(context.Background(), resultCh))
 
 			assert.Equal(t, string(tt.expected), buf.String())
 		})
@@ -97,7 +97,7 @@ func TestUniqueLoggerContextExit(t *testing.T) {
 		cancel()
 
 		var buf bytes.Buffer
-		logger, err := NewLogger(&buf, "arp", Plain())
+		logger, err := NewLogger(NewStreamWriter(&buf, &PlainEncoder{}), "arp")
 		require.NoError(t, err)
 
 		uniqLogger := NewUniqueLogger(logger)
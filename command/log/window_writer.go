@@ -0,0 +1,37 @@
+package log
+
+import (
+	"context"
+	"time"
+
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+// WindowWriter tags results with the start of the fixed time window they are written in,
+// e.g. 5-minute windows of a continuous scan, so that downstream consumers can aggregate results by windows
+type WindowWriter struct {
+	rw     ResultWriter
+	window time.Duration
+	now    func() time.Time
+}
+
+// Assert that log.WindowWriter conforms to the log.ResultWriter interface
+var _ ResultWriter = (*WindowWriter)(nil)
+
+// NewWindowWriter wraps results written to rw into scan.WindowResult,
+// windows are aligned to multiples of the window duration since the zero time, e.g. to minutes and hours
+func NewWindowWriter(rw ResultWriter, window time.Duration) *WindowWriter {
+	return &WindowWriter{rw: rw, window: window, now: time.Now}
+}
+
+func (w *WindowWriter) Write(ctx context.Context, result scan.Result) error {
+	return w.rw.Write(ctx, &scan.WindowResult{Result: result, WindowStart: w.now().Truncate(w.window)})
+}
+
+func (w *WindowWriter) Flush(ctx context.Context) error {
+	return w.rw.Flush(ctx)
+}
+
+func (w *WindowWriter) Close() error {
+	return w.rw.Close()
+}
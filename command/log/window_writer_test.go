@@ -0,0 +1,45 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+	"github.com/v-byte-cpu/sx/pkg/scan/tcp"
+)
+
+func TestWindowWriter(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	w := NewWindowWriter(NewStreamWriter(&buf, &JSONEncoder{}), 5*time.Minute)
+	w.now = func() time.Time {
+		return time.Date(2021, 5, 1, 10, 7, 42, 0, time.UTC)
+	}
+	result := newScanResult(net.IPv4(192, 168, 0, 3).To4())
+	require.NoError(t, w.Write(context.Background(), result))
+	require.NoError(t, w.Close())
+
+	expected := &scan.WindowResult{Result: result, WindowStart: time.Date(2021, 5, 1, 10, 5, 0, 0, time.UTC)}
+	require.Equal(t, scanResultToJSON(t, expected)+"\n", buf.String())
+	require.Contains(t, buf.String(), `"window_start":"2021-05-01T10:05:00Z"`)
+}
+
+func TestWindowWriterSplitOutput(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	w := NewWindowWriter(NewDemuxWriter("arp", func(scanType string) (ResultWriter, error) {
+		return NewFileWriter(filepath.Join(dir, "out-"+scanType+".txt"), &PlainEncoder{})
+	}), time.Hour)
+	result := &tcp.ScanResult{ScanType: tcp.SYNScanType, IP: "192.168.0.3", Port: 22}
+	require.NoError(t, w.Write(context.Background(), result))
+	require.NoError(t, w.Close())
+
+	require.FileExists(t, filepath.Join(dir, "out-tcpsyn.txt"))
+}
@@ -0,0 +1,85 @@
+package log
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"os"
+
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+// ResultWriter writes scan results to the output, e.g. stdout, a file or a remote service.
+// Write errors are returned to the caller, so that the scan is stopped instead of blocking on a broken output.
+type ResultWriter interface {
+	// Write writes the result, possibly buffering it, ctx cancels blocking writes
+	Write(ctx context.Context, result scan.Result) error
+	// Flush writes buffered results to the output
+	Flush(ctx context.Context) error
+	// Close flushes buffered results and releases resources of the output
+	Close() error
+}
+
+// ResultEncoder encodes scan results in the output format
+type ResultEncoder interface {
+	Encode(w io.Writer, result scan.Result) error
+}
+
+// StreamWriter writes encoded results to io.Writer through a buffer
+type StreamWriter struct {
+	bw  *bufio.Writer
+	enc ResultEncoder
+}
+
+// Assert that log.StreamWriter conforms to the log.ResultWriter interface
+var _ ResultWriter = (*StreamWriter)(nil)
+
+// NewStreamWriter creates a writer of the encoded results,
+// the underlying writer is not closed since it is owned by the caller, e.g. os.Stdout
+func NewStreamWriter(w io.Writer, enc ResultEncoder) *StreamWriter {
+	return &StreamWriter{bw: bufio.NewWriter(w), enc: enc}
+}
+
+func (w *StreamWriter) Write(ctx context.Context, result scan.Result) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return w.enc.Encode(w.bw, result)
+}
+
+func (w *StreamWriter) Flush(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return w.bw.Flush()
+}
+
+func (w *StreamWriter) Close() error {
+	return w.bw.Flush()
+}
+
+// FileWriter writes encoded results to the file that is closed with the writer
+type FileWriter struct {
+	*StreamWriter
+	f *os.File
+}
+
+// Assert that log.FileWriter conforms to the log.ResultWriter interface
+var _ ResultWriter = (*FileWriter)(nil)
+
+// NewFileWriter creates or truncates the file and returns the writer of encoded results to it
+func NewFileWriter(path string, enc ResultEncoder) (*FileWriter, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	return &FileWriter{StreamWriter: NewStreamWriter(f, enc), f: f}, nil
+}
+
+func (w *FileWriter) Close() (err error) {
+	err = w.StreamWriter.Close()
+	if cerr := w.f.Close(); err == nil {
+		err = cerr
+	}
+	return
+}
@@ -0,0 +1,63 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type errWriter struct{}
+
+func (*errWriter) Write([]byte) (int, error) {
+	return 0, errors.New("broken pipe")
+}
+
+func TestStreamWriterBuffersUntilFlush(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	w := NewStreamWriter(&buf, &PlainEncoder{})
+	result := newScanResult(net.IPv4(192, 168, 0, 3).To4())
+	require.NoError(t, w.Write(context.Background(), result))
+	require.Empty(t, buf.String())
+
+	require.NoError(t, w.Flush(context.Background()))
+	require.Equal(t, result.String()+"\n", buf.String())
+}
+
+func TestStreamWriterClose(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	w := NewStreamWriter(&buf, &JSONEncoder{})
+	result := newScanResult(net.IPv4(192, 168, 0, 3).To4())
+	require.NoError(t, w.Write(context.Background(), result))
+	require.NoError(t, w.Close())
+	require.Equal(t, scanResultToJSON(t, result)+"\n", buf.String())
+}
+
+func TestStreamWriterContextDone(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var buf bytes.Buffer
+	w := NewStreamWriter(&buf, &PlainEncoder{})
+	require.ErrorIs(t, w.Write(ctx, newScanResult(net.IPv4(192, 168, 0, 3).To4())), context.Canceled)
+	require.ErrorIs(t, w.Flush(ctx), context.Canceled)
+	require.Empty(t, buf.String())
+}
+
+func TestStreamWriterFlushError(t *testing.T) {
+	t.Parallel()
+
+	w := NewStreamWriter(&errWriter{}, &PlainEncoder{})
+	require.NoError(t, w.Write(context.Background(), newScanResult(net.IPv4(192, 168, 0, 3).To4())))
+	require.Error(t, w.Flush(context.Background()))
+	require.Error(t, w.Close())
+}
@@ -0,0 +1,370 @@
+package command
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+const manifestFlag = "manifest"
+
+var errManifestInput = errors.New("input file changed since the manifest was written")
+
+// manifestInputFlags are flags with files the scan reads its targets, ports and options from
+var manifestInputFlags = []string{"file", "ports-file", "arp-cache", "exclude", "policy", "alerts", "tag-policies", "credentials-file"}
+
+var (
+	// manifestArgs are command line arguments of the current run, they are replaced by the rerun command
+	manifestArgs []string
+
+	manifestMu sync.Mutex
+	manifest   *runManifest
+)
+
+// runManifest records everything needed to reproduce or audit the result set of the scan
+type runManifest struct {
+	Version string `json:"version"`
+	Command string `json:"command"`
+	// Args are command line arguments without the --manifest flag, they are repeated by the rerun command
+	Args []string `json:"args"`
+	// Flags are effective values of all flags of the command including defaults
+	Flags     map[string]string  `json:"flags"`
+	Inputs    []*manifestInput   `json:"inputs,omitempty"`
+	Interface *manifestInterface `json:"interface,omitempty"`
+	// Capture are kernel capture counters of each interface packet scans ran on
+	Capture []*manifestCapture `json:"capture,omitempty"`
+	// Preflight are limits of the system found by preflight checks of connect scans
+	Preflight *manifestPreflight `json:"preflight,omitempty"`
+	StartTime time.Time          `json:"start_time"`
+	EndTime   time.Time          `json:"end_time"`
+	Duration  string             `json:"duration"`
+	Error     string             `json:"error,omitempty"`
+
+	// path and auditLogPath are files the manifest is written to after the run
+	path         string
+	auditLogPath string
+}
+
+type manifestInput struct {
+	Flag string `json:"flag"`
+	Path string `json:"path"`
+	// SHA256 is empty for inputs read from stdin
+	SHA256 string `json:"sha256,omitempty"`
+	Size   int64  `json:"size"`
+}
+
+type manifestInterface struct {
+	Name      string   `json:"name"`
+	Index     int      `json:"index"`
+	MTU       int      `json:"mtu"`
+	MAC       string   `json:"mac"`
+	Addresses []string `json:"addresses,omitempty"`
+	SrcIP     string   `json:"src_ip"`
+	SrcMAC    string   `json:"src_mac"`
+}
+
+type manifestCapture struct {
+	Interface    string `json:"interface"`
+	Received     uint64 `json:"received"`
+	Dropped      uint64 `json:"dropped"`
+	RingOverruns uint64 `json:"ring_overruns"`
+}
+
+type manifestPreflight struct {
+	OpenFiles      uint64   `json:"open_files,omitempty"`
+	RaisedFrom     uint64   `json:"raised_from,omitempty"`
+	ConntrackCount int      `json:"conntrack_count,omitempty"`
+	ConntrackMax   int      `json:"conntrack_max,omitempty"`
+	EphemeralPorts int      `json:"ephemeral_ports,omitempty"`
+	Warnings       []string `json:"warnings,omitempty"`
+}
+
+// stripManifestFlag removes the --manifest flag from arguments, so that the rerun doesn't overwrite the manifest
+func stripManifestFlag(args []string) []string {
+	result := make([]string, 0, len(args))
+	for i := 0; i < len(args); i++ {
+		switch {
+		case args[i] == "--"+manifestFlag:
+			i++
+		case strings.HasPrefix(args[i], "--"+manifestFlag+"="):
+		default:
+			result = append(result, args[i])
+		}
+	}
+	return result
+}
+
+type manifestCmdOpts struct {
+	// path enables writing the run manifest to the file after the scan
+	path string
+	// auditLogPath enables appending the run manifest to the NDJSON file after the scan
+	auditLogPath string
+}
+
+func (o *manifestCmdOpts) initCliFlags(cmd *cobra.Command) {
+	cmd.PersistentFlags().StringVar(&o.path, manifestFlag, "",
+		"write the manifest of the run with the effective configuration, input hashes and timing to the file")
+	cmd.PersistentFlags().StringVar(&o.auditLogPath, "audit-log", "",
+		"append the manifest of each run to the file in NDJSON format, one line per run")
+}
+
+// beginManifest records the configuration of the command that is about to run
+func (o *manifestCmdOpts) beginManifest(cmd *cobra.Command) (err error) {
+	if len(o.path) == 0 && len(o.auditLogPath) == 0 {
+		return
+	}
+	m := &runManifest{
+		Version:      cmd.Root().Version,
+		Command:      strings.TrimPrefix(cmd.CommandPath(), cmd.Root().Name()+" "),
+		Args:         stripManifestFlag(manifestArgs),
+		Flags:        make(map[string]string),
+		StartTime:    time.Now(),
+		path:         o.path,
+		auditLogPath: o.auditLogPath,
+	}
+	cmd.Flags().VisitAll(func(f *pflag.Flag) {
+		if f.Name != "help" && f.Name != manifestFlag {
+			m.Flags[f.Name] = f.Value.String()
+		}
+	})
+	for _, name := range manifestInputFlags {
+		f := cmd.Flags().Lookup(name)
+		if f == nil || len(f.Value.String()) == 0 {
+			continue
+		}
+		var input *manifestInput
+		if input, err = newManifestInput(name, f.Value.String()); err != nil {
+			return
+		}
+		m.Inputs = append(m.Inputs, input)
+	}
+
+	manifestMu.Lock()
+	defer manifestMu.Unlock()
+	manifest = m
+	return
+}
+
+func newManifestInput(flag, path string) (*manifestInput, error) {
+	input := &manifestInput{Flag: flag, Path: path}
+	if path == "-" {
+		return input, nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	h := sha256.New()
+	if input.Size, err = io.Copy(h, f); err != nil {
+		return nil, err
+	}
+	input.SHA256 = hex.EncodeToString(h.Sum(nil))
+	return input, nil
+}
+
+// recordManifestFlag records the value of the flag set after the run started, e.g. by the tuning profile
+func recordManifestFlag(name, value string) {
+	manifestMu.Lock()
+	defer manifestMu.Unlock()
+	if manifest != nil {
+		manifest.Flags[name] = value
+	}
+}
+
+// recordManifestRange records the network interface of packet scans
+func recordManifestRange(r *scan.Range) {
+	if r.Interface == nil {
+		return
+	}
+	manifestMu.Lock()
+	defer manifestMu.Unlock()
+	if manifest == nil || manifest.Interface != nil {
+		return
+	}
+	iface := &manifestInterface{
+		Name:   r.Interface.Name,
+		Index:  r.Interface.Index,
+		MTU:    r.Interface.MTU,
+		MAC:    r.Interface.HardwareAddr.String(),
+		SrcIP:  r.SrcIP.String(),
+		SrcMAC: r.SrcMAC.String(),
+	}
+	if addrs, err := r.Interface.Addrs(); err == nil {
+		for _, addr := range addrs {
+			iface.Addresses = append(iface.Addresses, addr.String())
+		}
+	}
+	manifest.Interface = iface
+}
+
+// writeManifest writes the manifest of the finished run to the --manifest file, runErr is the error of the run
+func writeManifest(runErr error) (err error) {
+	manifestMu.Lock()
+	defer manifestMu.Unlock()
+	if manifest == nil {
+		return
+	}
+	manifest.EndTime = time.Now()
+	manifest.Duration = manifest.EndTime.Sub(manifest.StartTime).String()
+	if runErr != nil {
+		manifest.Error = runErr.Error()
+	}
+	manifest.Capture = manifestCaptureStats()
+	manifest.Preflight = manifestPreflightReport()
+	if len(manifest.auditLogPath) > 0 {
+		if err = appendAuditLog(manifest); err != nil {
+			return
+		}
+	}
+	if len(manifest.path) == 0 {
+		return
+	}
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return
+	}
+	return os.WriteFile(manifest.path, append(data, '\n'), 0o644)
+}
+
+// discardManifest drops the manifest of the finished run, so that it is not written again
+func discardManifest() {
+	manifestMu.Lock()
+	defer manifestMu.Unlock()
+	manifest = nil
+}
+
+// appendAuditLog appends the manifest to the audit log file as one JSON line
+func appendAuditLog(m *runManifest) (err error) {
+	data, err := json.Marshal(m)
+	if err != nil {
+		return
+	}
+	f, err := os.OpenFile(m.auditLogPath, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+	if err != nil {
+		return
+	}
+	if _, err = f.Write(append(data, '\n')); err != nil {
+		f.Close()
+		return
+	}
+	return f.Close()
+}
+
+func manifestCaptureStats() (result []*manifestCapture) {
+	captureStatsMu.Lock()
+	defer captureStatsMu.Unlock()
+	for _, name := range captureInterfaces() {
+		stats := captureStats[name]
+		result = append(result, &manifestCapture{
+			Interface:    name,
+			Received:     stats.Received,
+			Dropped:      stats.Dropped,
+			RingOverruns: stats.QueueFreezes,
+		})
+	}
+	return
+}
+
+// manifestPreflightReport returns limits found by preflight checks of the run, nil if they didn't run
+func manifestPreflightReport() *manifestPreflight {
+	r := preflightResult
+	if r == nil {
+		return nil
+	}
+	return &manifestPreflight{
+		OpenFiles:      r.openFiles,
+		RaisedFrom:     r.raisedFrom,
+		ConntrackCount: r.conntrackCount,
+		ConntrackMax:   r.conntrackMax,
+		EphemeralPorts: r.ephemeralPorts,
+		Warnings:       r.warnings,
+	}
+}
+
+func readManifest(path string) (*runManifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var m runManifest
+	if err = json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("manifest %s: %w", path, err)
+	}
+	return &m, nil
+}
+
+// checkInputs verifies that input files of the manifest have the same content
+func (m *runManifest) checkInputs() error {
+	for _, input := range m.Inputs {
+		if len(input.SHA256) == 0 {
+			continue
+		}
+		current, err := newManifestInput(input.Flag, input.Path)
+		if err != nil {
+			return err
+		}
+		if current.SHA256 != input.SHA256 {
+			return fmt.Errorf("%w: --%s %s", errManifestInput, input.Flag, input.Path)
+		}
+	}
+	return nil
+}
+
+func newRerunCmd() *rerunCmd {
+	c := &rerunCmd{}
+
+	cmd := &cobra.Command{
+		Use:     "rerun [flags] manifest.json",
+		Example: strings.Join([]string{"rerun manifest.json", "rerun --force --manifest rerun.json manifest.json"}, "\n"),
+		Short:   "Repeat the scan recorded in the run manifest",
+		Args:    cobra.ExactArgs(1),
+		// the recorded command runs the persistent pre-run of the root command itself
+		PersistentPreRunE: func(*cobra.Command, []string) error { return nil },
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			m, err := readManifest(args[0])
+			if err != nil {
+				return
+			}
+			if err = m.checkInputs(); err != nil {
+				if !c.force {
+					return
+				}
+				fmt.Fprintln(os.Stderr, "Warning:", err)
+			}
+			root := cmd.Root()
+			if m.Version != root.Version {
+				fmt.Fprintf(os.Stderr, "Warning: manifest was written by sx %s, running sx %s\n", m.Version, root.Version)
+			}
+			manifestArgs = m.Args
+			root.SetArgs(m.Args)
+			if err = root.Execute(); err != nil {
+				// the error is already reported by the recorded command
+				cmd.SilenceErrors = true
+				cmd.SilenceUsage = true
+			}
+			return
+		},
+	}
+
+	cmd.Flags().BoolVar(&c.force, "force", false, "rerun even if input files changed since the manifest was written")
+
+	c.cmd = cmd
+	return c
+}
+
+type rerunCmd struct {
+	cmd   *cobra.Command
+	force bool
+}
@@ -0,0 +1,207 @@
+package command
+
+import (
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+// setManifestState replaces the process-wide manifest state for the test
+func setManifestState(t *testing.T, args []string) {
+	t.Helper()
+	prevArgs := manifestArgs
+	manifestArgs, manifest = args, nil
+	t.Cleanup(func() {
+		manifestArgs, manifest = prevArgs, nil
+	})
+}
+
+func TestStripManifestFlag(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		args     []string
+		expected []string
+	}{
+		{
+			name:     "NoFlag",
+			args:     []string{"tcp", "-p", "22", "10.0.0.1"},
+			expected: []string{"tcp", "-p", "22", "10.0.0.1"},
+		},
+		{
+			name:     "Separate",
+			args:     []string{"tcp", "--manifest", "m.json", "-p", "22", "10.0.0.1"},
+			expected: []string{"tcp", "-p", "22", "10.0.0.1"},
+		},
+		{
+			name:     "Equals",
+			args:     []string{"--manifest=m.json", "tcp", "-p", "22", "10.0.0.1"},
+			expected: []string{"tcp", "-p", "22", "10.0.0.1"},
+		},
+	}
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			require.Equal(t, tt.expected, stripManifestFlag(tt.args))
+		})
+	}
+}
+
+func TestWriteManifest(t *testing.T) {
+	dir := t.TempDir()
+	ipFile := filepath.Join(dir, "ips.jsonl")
+	require.NoError(t, os.WriteFile(ipFile, []byte(`{"ip":"10.0.0.1","port":5900}`+"\n"), 0o600))
+	path := filepath.Join(dir, "manifest.json")
+	args := []string{"vnc", "--manifest", path, "-f", ipFile, "--timeout", "3s"}
+	setManifestState(t, args)
+
+	var opts manifestCmdOpts
+	root := &cobra.Command{Use: "sx", Version: "1.2.3"}
+	opts.initCliFlags(root)
+	cmd := newVNCCmd().cmd
+	root.AddCommand(cmd)
+	require.NoError(t, cmd.ParseFlags(args[1:]))
+	require.Equal(t, path, opts.path)
+
+	require.NoError(t, opts.beginManifest(cmd))
+	iface := &net.Interface{Index: 2, MTU: 1500, Name: "eth0",
+		HardwareAddr: net.HardwareAddr{0x10, 0x11, 0x12, 0x13, 0x14, 0x15}}
+	recordManifestRange(&scan.Range{Interface: iface, SrcIP: net.IPv4(192, 168, 0, 3).To4(), SrcMAC: iface.HardwareAddr})
+	require.NoError(t, writeManifest(errors.New("scan failed")))
+
+	m, err := readManifest(path)
+	require.NoError(t, err)
+	require.Equal(t, "1.2.3", m.Version)
+	require.Equal(t, "vnc", m.Command)
+	require.Equal(t, []string{"vnc", "-f", ipFile, "--timeout", "3s"}, m.Args)
+	require.Equal(t, "3s", m.Flags["timeout"])
+	require.Equal(t, "100", m.Flags["workers"])
+	require.NotContains(t, m.Flags, manifestFlag)
+	require.Equal(t, []*manifestInput{{Flag: "file", Path: ipFile,
+		SHA256: "86785c314dc33656b8285721df83c7666668f044794ee3804205ff44f30af33a", Size: 30}}, m.Inputs)
+	require.Equal(t, &manifestInterface{Name: "eth0", Index: 2, MTU: 1500, MAC: "10:11:12:13:14:15",
+		SrcIP: "192.168.0.3", SrcMAC: "10:11:12:13:14:15"}, m.Interface)
+	require.False(t, m.EndTime.Before(m.StartTime))
+	require.Equal(t, "scan failed", m.Error)
+	require.NoError(t, m.checkInputs())
+}
+
+func TestWriteManifestAuditLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	setManifestState(t, []string{"vnc", "--audit-log", path})
+	opts := manifestCmdOpts{auditLogPath: path}
+
+	for _, runErr := range []error{nil, errors.New("scan failed")} {
+		cmd := newVNCCmd().cmd
+		require.NoError(t, opts.beginManifest(cmd))
+		require.NoError(t, writeManifest(runErr))
+	}
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	require.Len(t, lines, 2)
+	require.Contains(t, lines[0], `"command":"vnc"`)
+	require.NotContains(t, lines[0], `"error"`)
+	require.Contains(t, lines[1], `"error":"scan failed"`)
+}
+
+func TestWriteManifestPreflight(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "manifest.json")
+	setManifestState(t, []string{"vnc", "--manifest", path})
+	prev := preflightResult
+	preflightResult = &preflightReport{openFiles: 1024, conntrackCount: 1000, conntrackMax: 262144,
+		ephemeralPorts: 28232, warnings: []string{"open file limit 1024 is below 5064 required for 5000 connections"}}
+	t.Cleanup(func() {
+		preflightResult = prev
+	})
+
+	cmd := newVNCCmd().cmd
+	require.NoError(t, (&manifestCmdOpts{path: path}).beginManifest(cmd))
+	require.NoError(t, writeManifest(nil))
+
+	m, err := readManifest(path)
+	require.NoError(t, err)
+	require.Equal(t, &manifestPreflight{OpenFiles: 1024, ConntrackCount: 1000, ConntrackMax: 262144,
+		EphemeralPorts: 28232, Warnings: []string{"open file limit 1024 is below 5064 required for 5000 connections"}},
+		m.Preflight)
+}
+
+func TestWriteManifestDisabled(t *testing.T) {
+	setManifestState(t, []string{"vnc"})
+	cmd := newVNCCmd().cmd
+	require.NoError(t, (&manifestCmdOpts{}).beginManifest(cmd))
+	require.Nil(t, manifest)
+	require.NoError(t, writeManifest(nil))
+}
+
+func TestManifestCheckInputsChanged(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "ports.txt")
+	require.NoError(t, os.WriteFile(path, []byte("22\n"), 0o600))
+	input, err := newManifestInput("ports-file", path)
+	require.NoError(t, err)
+	m := &runManifest{Inputs: []*manifestInput{input, {Flag: "file", Path: "-"}}}
+	require.NoError(t, m.checkInputs())
+
+	require.NoError(t, os.WriteFile(path, []byte("22\n80\n"), 0o600))
+	require.ErrorIs(t, m.checkInputs(), errManifestInput)
+}
+
+func TestRerunCmd(t *testing.T) {
+	dir := t.TempDir()
+	setManifestState(t, nil)
+	path := filepath.Join(dir, "manifest.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"version":"test","args":["vnc","invalid_ip_address"]}`), 0o600))
+
+	root := newRootCmd("test").cmd
+	root.SetArgs([]string{"rerun", path})
+	root.SetOut(&strings.Builder{})
+	root.SetErr(&strings.Builder{})
+	// the recorded command runs and fails to parse the subnet
+	require.Error(t, root.Execute())
+	require.Equal(t, []string{"vnc", "invalid_ip_address"}, manifestArgs)
+}
+
+func TestRerunCmdInputChanged(t *testing.T) {
+	dir := t.TempDir()
+	setManifestState(t, nil)
+	ipFile := filepath.Join(dir, "ips.jsonl")
+	require.NoError(t, os.WriteFile(ipFile, []byte("changed\n"), 0o600))
+	path := filepath.Join(dir, "manifest.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"version":"test","args":["vnc","-f","`+ipFile+`"],`+
+		`"inputs":[{"flag":"file","path":"`+ipFile+`","sha256":"00","size":1}]}`), 0o600))
+
+	cmd := newRerunCmd().cmd
+	err := cmd.RunE(cmd, []string{path})
+	require.ErrorIs(t, err, errManifestInput)
+	require.Nil(t, manifestArgs)
+}
+
+func TestRerunCmdInvalidManifest(t *testing.T) {
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "manifest.json")
+	require.NoError(t, os.WriteFile(path, []byte("not json"), 0o600))
+
+	cmd := newRerunCmd().cmd
+	require.Error(t, cmd.RunE(cmd, []string{path}))
+	require.Error(t, cmd.RunE(cmd, []string{filepath.Join(t.TempDir(), "missing.json")}))
+}
+
+func TestManifestDuration(t *testing.T) {
+	setManifestState(t, nil)
+	manifest = &runManifest{StartTime: time.Now().Add(-time.Second), path: filepath.Join(t.TempDir(), "manifest.json")}
+	require.NoError(t, writeManifest(nil))
+	d, err := time.ParseDuration(manifest.Duration)
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, d, time.Second)
+}
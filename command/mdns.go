@@ -0,0 +1,108 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/v-byte-cpu/sx/command/log"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+	"github.com/v-byte-cpu/sx/pkg/scan/mdns"
+)
+
+const defaultMDNSPort = 5353
+
+var errMDNSMulticastTargets = errors.New("multicast mode doesn't accept subnet, file with ip/port pairs or input")
+
+func newMDNSCmd() *mdnsCmd {
+	c := &mdnsCmd{}
+
+	cmd := &cobra.Command{
+		Use: "mdns [flags] [subnet]",
+		Example: strings.Join([]string{
+			"mdns -p 5353 192.168.0.1/24", "mdns --multicast", "mdns --multicast --timeout 5s",
+			"mdns -f ip_ports_file.jsonl", "mdns -p 5353 -f ips_file.jsonl"}, "\n"),
+		Short: "Perform mDNS service discovery scan",
+		Long: strings.Join([]string{
+			"Perform mDNS service discovery scan.",
+			"The DNS-SD service type enumeration query is sent to each target over UDP, responders are reported",
+			"with their hostname and advertised service types. In the multicast mode the query is sent to",
+			mdns.MulticastIP.String() + ":5353 instead, so all responders of the local network are discovered without the target list."}, "\n"),
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer cancel()
+
+			if err = c.opts.parseRawOptions(); err != nil {
+				return
+			}
+			scanRange, err := c.opts.parseScanRange(args)
+			if err != nil {
+				return
+			}
+
+			var logger log.Logger
+			if logger, err = c.opts.getLogger(mdns.ScanType, resultWriter); err != nil {
+				return
+			}
+
+			engine, err := c.opts.newMDNSScanEngine(ctx)
+			if err != nil {
+				return
+			}
+			return c.opts.runScanEngine(ctx, cmd, engine, scanRange, logger)
+		},
+	}
+
+	c.opts.initCliFlags(cmd)
+
+	c.cmd = cmd
+	return c
+}
+
+type mdnsCmd struct {
+	cmd  *cobra.Command
+	opts mdnsCmdOpts
+}
+
+type mdnsCmdOpts struct {
+	genericScanCmdOpts
+	timeout   time.Duration
+	multicast bool
+}
+
+func (o *mdnsCmdOpts) initCliFlags(cmd *cobra.Command) {
+	o.genericScanCmdOpts.initCliFlags(cmd)
+	cmd.Flags().DurationVarP(&o.timeout, "timeout", "t", 2*time.Second, "set time to wait for responses")
+	cmd.Flags().BoolVar(&o.multicast, "multicast", false,
+		"discover responders of the local network with the multicast query instead of scanning targets")
+}
+
+func (o *mdnsCmdOpts) parseScanRange(args []string) (*scan.Range, error) {
+	if !o.multicast {
+		return o.genericScanCmdOpts.parseScanRange(args)
+	}
+	if len(args) > 0 || len(o.ipFile) > 0 || len(o.rawInput) > 0 {
+		return nil, errMDNSMulticastTargets
+	}
+	ports := o.portRanges
+	if len(ports) == 0 {
+		ports = []*scan.PortRange{{StartPort: defaultMDNSPort, EndPort: defaultMDNSPort}}
+	}
+	return &scan.Range{
+		DstSubnet: &net.IPNet{IP: mdns.MulticastIP.To4(), Mask: net.CIDRMask(32, 32)},
+		Ports:     ports,
+	}, nil
+}
+
+func (o *mdnsCmdOpts) newMDNSScanEngine(ctx context.Context) (scan.EngineResulter, error) {
+	scanner, err := mdns.NewScanner(ctx, mdns.WithDataTimeout(o.timeout))
+	if err != nil {
+		return nil, err
+	}
+	return o.newScanEngine(ctx, scanner), nil
+}
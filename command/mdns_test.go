@@ -0,0 +1,133 @@
+package command
+
+import (
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+func TestMDNSCmdDstSubnetError(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{
+			name: "RequiredArg",
+			args: nil,
+		},
+		{
+			name: "InvalidDstSubnet",
+			args: []string{"invalid_ip_address"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := newMDNSCmd().cmd
+			err := cmd.RunE(cmd, tt.args)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestMDNSCmdOptsInitCliFlags(t *testing.T) {
+	t.Parallel()
+	var opts mdnsCmdOpts
+	cmd := &cobra.Command{}
+
+	opts.initCliFlags(cmd)
+	err := cmd.ParseFlags(strings.Split(
+		"--json -p 5353 -f ip_file.jsonl -w 300 --exit-delay 10s --timeout 3s --multicast", " "))
+
+	require.NoError(t, err)
+	require.Equal(t, true, opts.json)
+	require.Equal(t, "5353", opts.rawPortRanges)
+	require.Equal(t, "ip_file.jsonl", opts.ipFile)
+	require.Equal(t, 300, opts.workers)
+	require.Equal(t, 10*time.Second, opts.exitDelay)
+
+	require.Equal(t, 3*time.Second, opts.timeout)
+	require.Equal(t, true, opts.multicast)
+}
+
+func TestMDNSCmdOptsParseScanRange(t *testing.T) {
+	t.Parallel()
+	multicastSubnet := &net.IPNet{IP: net.IPv4(224, 0, 0, 251).To4(), Mask: net.CIDRMask(32, 32)}
+	tests := []struct {
+		name     string
+		opts     mdnsCmdOpts
+		args     []string
+		expected *scan.Range
+	}{
+		{
+			name: "Unicast",
+			opts: mdnsCmdOpts{genericScanCmdOpts: genericScanCmdOpts{
+				portRanges: []*scan.PortRange{{StartPort: 5353, EndPort: 5353}},
+			}},
+			args: []string{"192.168.0.1/24"},
+			expected: &scan.Range{
+				DstSubnet: &net.IPNet{IP: net.IPv4(192, 168, 0, 0).To4(), Mask: net.CIDRMask(24, 32)},
+				Ports:     []*scan.PortRange{{StartPort: 5353, EndPort: 5353}},
+			},
+		},
+		{
+			name: "Multicast",
+			opts: mdnsCmdOpts{multicast: true},
+			expected: &scan.Range{
+				DstSubnet: multicastSubnet,
+				Ports:     []*scan.PortRange{{StartPort: 5353, EndPort: 5353}},
+			},
+		},
+		{
+			name: "MulticastCustomPort",
+			opts: mdnsCmdOpts{multicast: true, genericScanCmdOpts: genericScanCmdOpts{
+				portRanges: []*scan.PortRange{{StartPort: 5354, EndPort: 5354}},
+			}},
+			expected: &scan.Range{
+				DstSubnet: multicastSubnet,
+				Ports:     []*scan.PortRange{{StartPort: 5354, EndPort: 5354}},
+			},
+		},
+	}
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			r, err := tt.opts.parseScanRange(tt.args)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, r)
+		})
+	}
+}
+
+func TestMDNSCmdOptsParseScanRangeMulticastError(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		opts mdnsCmdOpts
+		args []string
+	}{
+		{
+			name: "Subnet",
+			opts: mdnsCmdOpts{multicast: true},
+			args: []string{"192.168.0.1/24"},
+		},
+		{
+			name: "IPFile",
+			opts: mdnsCmdOpts{multicast: true, genericScanCmdOpts: genericScanCmdOpts{ipFile: "ips_file.jsonl"}},
+		},
+	}
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := tt.opts.parseScanRange(tt.args)
+			require.ErrorIs(t, err, errMDNSMulticastTargets)
+		})
+	}
+}
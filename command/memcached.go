@@ -0,0 +1,80 @@
+package command
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/v-byte-cpu/sx/command/log"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+	"github.com/v-byte-cpu/sx/pkg/scan/memcached"
+)
+
+func newMemcachedCmd() *memcachedCmd {
+	c := &memcachedCmd{}
+
+	cmd := &cobra.Command{
+		Use: "memcached [flags] [subnet]",
+		Example: strings.Join([]string{
+			"memcached -p 11211 192.168.0.1/24", "memcached -p 11211-11212 10.0.0.1",
+			"memcached --json -p 11211,11212 10.0.0.1/16",
+			"memcached -f ip_ports_file.jsonl", "memcached -p 11211 -f ips_file.jsonl"}, "\n"),
+		Short: "Perform Memcached stats scan over TCP and UDP",
+		Long: strings.Join([]string{
+			"Perform Memcached stats scan over TCP and UDP.",
+			"The stats command is sent to each target over TCP and over UDP in the UDP frame,",
+			"servers are reported with their version and the number of stored items.",
+			"Servers that answer over UDP can be abused for amplification attacks,",
+			"the number of response packets and bytes is reported for them."}, "\n"),
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer cancel()
+
+			if err = c.opts.parseRawOptions(); err != nil {
+				return
+			}
+			scanRange, err := c.opts.parseScanRange(args)
+			if err != nil {
+				return
+			}
+
+			var logger log.Logger
+			if logger, err = c.opts.getLogger(memcached.ScanType, resultWriter); err != nil {
+				return
+			}
+
+			engine := c.opts.newMemcachedScanEngine(ctx)
+			return c.opts.runScanEngine(ctx, cmd, engine, scanRange, logger)
+		},
+	}
+
+	c.opts.initCliFlags(cmd)
+
+	c.cmd = cmd
+	return c
+}
+
+type memcachedCmd struct {
+	cmd  *cobra.Command
+	opts memcachedCmdOpts
+}
+
+type memcachedCmdOpts struct {
+	genericScanCmdOpts
+	timeout time.Duration
+}
+
+func (o *memcachedCmdOpts) initCliFlags(cmd *cobra.Command) {
+	o.genericScanCmdOpts.initCliFlags(cmd)
+	cmd.Flags().DurationVarP(&o.timeout, "timeout", "t", 2*time.Second, "set connect timeout and time to wait for responses")
+}
+
+func (o *memcachedCmdOpts) newMemcachedScanEngine(ctx context.Context) scan.EngineResulter {
+	return o.newScanEngine(ctx, memcached.NewScanner(
+		memcached.WithDialTimeout(o.timeout),
+		memcached.WithDataTimeout(o.timeout),
+	))
+}
@@ -0,0 +1,102 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/v-byte-cpu/sx/command/log"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+	"github.com/v-byte-cpu/sx/pkg/scan/modbus"
+)
+
+func newModbusCmd() *modbusCmd {
+	c := &modbusCmd{}
+
+	cmd := &cobra.Command{
+		Use: "modbus [flags] [subnet]",
+		Example: strings.Join([]string{
+			"modbus -p 502 192.168.0.1/24", "modbus --json --unit-ids 1,255,0 -p 502 10.0.0.1/16",
+			"modbus -f ip_ports_file.jsonl", "modbus -p 502 -f ips_file.jsonl"}, "\n"),
+		Short: "Perform Modbus/TCP device identification scan",
+		Long: strings.Join([]string{
+			"Perform Modbus/TCP device identification scan.",
+			"The Read Device Identification request (function 43/14) is sent with each unit id until the device answers it,",
+			"devices are reported with the vendor, product code and revision.",
+			"Devices that don't support device identification are reported with the exception they answered."}, "\n"),
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer cancel()
+
+			if err = c.opts.parseRawOptions(); err != nil {
+				return
+			}
+			scanRange, err := c.opts.parseScanRange(args)
+			if err != nil {
+				return
+			}
+
+			var logger log.Logger
+			if logger, err = c.opts.getLogger(modbus.ScanType, resultWriter); err != nil {
+				return
+			}
+
+			engine := c.opts.newModbusScanEngine(ctx)
+			return c.opts.runScanEngine(ctx, cmd, engine, scanRange, logger)
+		},
+	}
+
+	c.opts.initCliFlags(cmd)
+
+	c.cmd = cmd
+	return c
+}
+
+type modbusCmd struct {
+	cmd  *cobra.Command
+	opts modbusCmdOpts
+}
+
+type modbusCmdOpts struct {
+	genericScanCmdOpts
+	timeout    time.Duration
+	rawUnitIDs []int
+	unitIDs    []uint8
+}
+
+func (o *modbusCmdOpts) initCliFlags(cmd *cobra.Command) {
+	o.genericScanCmdOpts.initCliFlags(cmd)
+	cmd.Flags().DurationVarP(&o.timeout, "timeout", "t", 2*time.Second, "set connect and data timeout")
+	cmd.Flags().IntSliceVar(&o.rawUnitIDs, "unit-ids", []int{255, 1},
+		strings.Join([]string{"set comma-separated list of unit ids to try in order",
+			"devices usually answer 255, gateways forward requests of other unit ids to serial devices"}, "\n"))
+}
+
+func (o *modbusCmdOpts) parseRawOptions() (err error) {
+	if err = o.genericScanCmdOpts.parseRawOptions(); err != nil {
+		return
+	}
+	if len(o.rawUnitIDs) == 0 {
+		return errors.New("invalid unit ids: at least one unit id required")
+	}
+	o.unitIDs = make([]uint8, 0, len(o.rawUnitIDs))
+	for _, unitID := range o.rawUnitIDs {
+		if unitID < 0 || unitID > 255 {
+			return errors.New("invalid unit id: number between 0 and 255 required")
+		}
+		o.unitIDs = append(o.unitIDs, uint8(unitID))
+	}
+	return
+}
+
+func (o *modbusCmdOpts) newModbusScanEngine(ctx context.Context) scan.EngineResulter {
+	return o.newScanEngine(ctx, modbus.NewScanner(
+		modbus.WithDialTimeout(o.timeout),
+		modbus.WithDataTimeout(o.timeout),
+		modbus.WithUnitIDs(o.unitIDs),
+	))
+}
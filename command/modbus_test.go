@@ -0,0 +1,68 @@
+package command
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+)
+
+func TestModbusCmdDstSubnetError(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{
+			name: "RequiredArg",
+			args: nil,
+		},
+		{
+			name: "InvalidDstSubnet",
+			args: []string{"invalid_ip_address"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := newModbusCmd().cmd
+			err := cmd.RunE(cmd, tt.args)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestModbusCmdOptsInitCliFlags(t *testing.T) {
+	t.Parallel()
+	var opts modbusCmdOpts
+	cmd := &cobra.Command{}
+
+	opts.initCliFlags(cmd)
+	err := cmd.ParseFlags(strings.Split(
+		"--json -p 502 -f ip_file.jsonl -w 300 --exit-delay 10s --timeout 3s --unit-ids 1,0", " "))
+
+	require.NoError(t, err)
+	require.Equal(t, true, opts.json)
+	require.Equal(t, "502", opts.rawPortRanges)
+	require.Equal(t, "ip_file.jsonl", opts.ipFile)
+	require.Equal(t, 300, opts.workers)
+	require.Equal(t, 10*time.Second, opts.exitDelay)
+
+	require.Equal(t, 3*time.Second, opts.timeout)
+	require.Equal(t, []int{1, 0}, opts.rawUnitIDs)
+	require.NoError(t, opts.parseRawOptions())
+	require.Equal(t, []uint8{1, 0}, opts.unitIDs)
+}
+
+func TestModbusCmdOptsParseRawOptionsError(t *testing.T) {
+	t.Parallel()
+	for _, rawUnitIDs := range []string{"256", "-1"} {
+		var opts modbusCmdOpts
+		cmd := &cobra.Command{}
+
+		opts.initCliFlags(cmd)
+		require.NoError(t, cmd.ParseFlags([]string{"-p", "502", "--unit-ids", rawUnitIDs}))
+		require.Error(t, opts.parseRawOptions())
+	}
+}
@@ -0,0 +1,74 @@
+package command
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/v-byte-cpu/sx/command/log"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+	"github.com/v-byte-cpu/sx/pkg/scan/mongo"
+)
+
+func newMongoCmd() *mongoCmd {
+	c := &mongoCmd{}
+
+	cmd := &cobra.Command{
+		Use: "mongo [flags] [subnet]",
+		Example: strings.Join([]string{
+			"mongo -p 27017 192.168.0.1/24", "mongo -p 27017-27019 10.0.0.1",
+			"mongo --json -p 27017,27018 10.0.0.1/16",
+			"mongo -f ip_ports_file.jsonl", "mongo -p 27017 -f ips_file.jsonl"}, "\n"),
+		Short: "Perform MongoDB version and authentication scan",
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer cancel()
+
+			if err = c.opts.parseRawOptions(); err != nil {
+				return
+			}
+			scanRange, err := c.opts.parseScanRange(args)
+			if err != nil {
+				return
+			}
+
+			var logger log.Logger
+			if logger, err = c.opts.getLogger(mongo.ScanType, resultWriter); err != nil {
+				return
+			}
+
+			engine := c.opts.newMongoScanEngine(ctx)
+			return c.opts.runScanEngine(ctx, cmd, engine, scanRange, logger)
+		},
+	}
+
+	c.opts.initCliFlags(cmd)
+
+	c.cmd = cmd
+	return c
+}
+
+type mongoCmd struct {
+	cmd  *cobra.Command
+	opts mongoCmdOpts
+}
+
+type mongoCmdOpts struct {
+	genericScanCmdOpts
+	timeout time.Duration
+}
+
+func (o *mongoCmdOpts) initCliFlags(cmd *cobra.Command) {
+	o.genericScanCmdOpts.initCliFlags(cmd)
+	cmd.Flags().DurationVarP(&o.timeout, "timeout", "t", 2*time.Second, "set connect and data timeout")
+}
+
+func (o *mongoCmdOpts) newMongoScanEngine(ctx context.Context) scan.EngineResulter {
+	return o.newScanEngine(ctx, mongo.NewScanner(
+		mongo.WithDialTimeout(o.timeout),
+		mongo.WithDataTimeout(o.timeout),
+	))
+}
@@ -0,0 +1,106 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/v-byte-cpu/sx/command/log"
+	"github.com/v-byte-cpu/sx/pkg/monitor"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+const (
+	defaultClosedAfter  = 3
+	defaultStableSample = 0.05
+)
+
+var (
+	errMonitorInterval = errors.New("invalid monitor interval: non-negative duration required")
+	errClosedAfter     = errors.New("invalid closed-after runs: positive number required")
+	errMonitorStdin    = errors.New("monitoring mode can not read targets from stdin")
+	errFullEvery       = errors.New("invalid full-every runs: non-negative number required")
+	errStableSample    = errors.New("invalid stable sample: fraction from 0 to 1 required")
+)
+
+// monitorCmdOpts are options of the continuous monitoring mode, the scan is repeated
+// until it is interrupted and lifecycle events of targets are logged along with results
+type monitorCmdOpts struct {
+	monitorInterval time.Duration
+	closedAfter     int
+	fullEvery       int
+	stableSample    float64
+
+	tracker *monitor.Tracker
+	events  chan scan.Result
+}
+
+func (o *monitorCmdOpts) initCliFlags(cmd *cobra.Command) {
+	cmd.Flags().DurationVar(&o.monitorInterval, "monitor", 0,
+		strings.Join([]string{
+			"enable monitoring mode: repeat the scan until interrupted, waiting the interval between runs",
+			"the interval should exceed the scan timeout, so that responses of each run arrive before the next one"}, "\n"))
+	cmd.Flags().IntVar(&o.closedAfter, "closed-after", defaultClosedAfter,
+		"log the closed event of targets that didn't respond for the number of consecutive runs of monitoring mode")
+	cmd.Flags().IntVar(&o.fullEvery, "full-every", 0,
+		strings.Join([]string{
+			"enable differential rescans in monitoring mode: scan all hosts every number of runs",
+			"other runs scan only hosts that changed within the number of runs and a sample of stable hosts"}, "\n"))
+	cmd.Flags().Float64Var(&o.stableSample, "stable-sample", defaultStableSample,
+		"set the fraction of stable hosts scanned in runs between full runs of differential rescans")
+}
+
+func (o *monitorCmdOpts) parseRawOptions() error {
+	if o.monitorInterval < 0 {
+		return errMonitorInterval
+	}
+	if o.monitorInterval == 0 {
+		return nil
+	}
+	if o.closedAfter <= 0 {
+		return errClosedAfter
+	}
+	if o.fullEvery < 0 {
+		return errFullEvery
+	}
+	if o.stableSample < 0 || o.stableSample > 1 {
+		return errStableSample
+	}
+	o.tracker = monitor.NewTracker(o.closedAfter)
+	o.events = make(chan scan.Result, 1000)
+	return nil
+}
+
+// withMonitor wraps the request generator to repeat the scan and log closed events at the start of each run,
+// stable hosts are skipped between full runs if differential rescans are enabled
+func (o *monitorCmdOpts) withMonitor(reqgen scan.RequestGenerator) scan.RequestGenerator {
+	if o.tracker == nil {
+		return reqgen
+	}
+	if o.fullEvery > 1 {
+		reqgen = scan.NewFilterIPRequestGenerator(reqgen, monitor.NewDiffFilter(o.tracker, o.fullEvery, o.stableSample))
+	}
+	return scan.NewMonitorRequestGenerator(reqgen, o.monitorInterval, func(ctx context.Context, run int) {
+		// port states of each run are written to the history before the next run
+		if run > 1 {
+			writeHistoryError(finishHistoryRun())
+		}
+		for _, event := range o.tracker.StartRun() {
+			select {
+			case <-ctx.Done():
+				return
+			case o.events <- event:
+			}
+		}
+	})
+}
+
+// withMonitorLogger wraps the logger to record targets of results and to log closed events
+func (o *monitorCmdOpts) withMonitorLogger(logger log.Logger) log.Logger {
+	if o.tracker == nil {
+		return logger
+	}
+	return log.NewFilterLogger(log.NewMergeLogger(logger, o.events), o.tracker.Record)
+}
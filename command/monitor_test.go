@@ -0,0 +1,132 @@
+package command
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+	"github.com/v-byte-cpu/sx/command/log"
+	"github.com/v-byte-cpu/sx/pkg/monitor"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+	"github.com/v-byte-cpu/sx/pkg/scan/tcp"
+)
+
+func TestMonitorCmdOptsInitCliFlags(t *testing.T) {
+	t.Parallel()
+	var opts monitorCmdOpts
+	cmd := &cobra.Command{}
+
+	opts.initCliFlags(cmd)
+	require.NoError(t, cmd.ParseFlags(strings.Split("--monitor 10m --closed-after 5 --full-every 6 --stable-sample 0.1", " ")))
+	require.Equal(t, 10*time.Minute, opts.monitorInterval)
+	require.Equal(t, 5, opts.closedAfter)
+	require.Equal(t, 6, opts.fullEvery)
+	require.Equal(t, 0.1, opts.stableSample)
+
+	require.NoError(t, opts.parseRawOptions())
+	require.NotNil(t, opts.tracker)
+}
+
+func TestMonitorCmdOptsParseRawOptions(t *testing.T) {
+	t.Parallel()
+	opts := &monitorCmdOpts{closedAfter: defaultClosedAfter}
+	require.NoError(t, opts.parseRawOptions())
+	require.Nil(t, opts.tracker)
+
+	opts = &monitorCmdOpts{monitorInterval: -time.Second, closedAfter: defaultClosedAfter}
+	require.ErrorIs(t, opts.parseRawOptions(), errMonitorInterval)
+
+	opts = &monitorCmdOpts{monitorInterval: time.Minute}
+	require.ErrorIs(t, opts.parseRawOptions(), errClosedAfter)
+
+	opts = &monitorCmdOpts{monitorInterval: time.Minute, closedAfter: defaultClosedAfter, fullEvery: -1}
+	require.ErrorIs(t, opts.parseRawOptions(), errFullEvery)
+
+	opts = &monitorCmdOpts{monitorInterval: time.Minute, closedAfter: defaultClosedAfter, stableSample: 1.5}
+	require.ErrorIs(t, opts.parseRawOptions(), errStableSample)
+
+	var genericOpts genericScanCmdOpts
+	cmd := &cobra.Command{}
+	genericOpts.initCliFlags(cmd)
+	require.NoError(t, cmd.ParseFlags(strings.Split("--monitor 1m -f - -p 22", " ")))
+	require.ErrorIs(t, genericOpts.parseRawOptions(), errMonitorStdin)
+}
+
+func TestMonitorClosedEvents(t *testing.T) {
+	t.Parallel()
+	opts := &monitorCmdOpts{monitorInterval: 50 * time.Millisecond, closedAfter: 1}
+	require.NoError(t, opts.parseRawOptions())
+
+	var buf bytes.Buffer
+	plainLogger, err := log.NewLogger(log.NewStreamWriter(&buf, &log.PlainEncoder{}), "tcpsyn")
+	require.NoError(t, err)
+	logger := opts.withMonitorLogger(plainLogger)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	requests, err := opts.withMonitor(scan.NewIPPortGenerator(scan.NewIPGenerator(), scan.NewPortGenerator())).
+		GenerateRequests(ctx, &scan.Range{
+			DstSubnet: &net.IPNet{IP: net.IPv4(192, 168, 0, 1), Mask: net.CIDRMask(32, 32)},
+			Ports:     []*scan.PortRange{{StartPort: 22, EndPort: 22}},
+		})
+	require.NoError(t, err)
+
+	results := make(chan scan.Result)
+	done := make(chan error, 1)
+	go func() {
+		done <- logger.LogResults(ctx, results)
+	}()
+	// the port is open only in the first run
+	request := <-requests
+	results <- &tcp.ScanResult{ScanType: tcp.SYNScanType, IP: request.DstIP.String(), Port: request.DstPort}
+	<-requests
+	// the closed event is logged at the start of the third run
+	<-requests
+	close(results)
+	select {
+	case err = <-done:
+		require.NoError(t, err)
+	case <-time.After(3 * time.Second):
+		require.Fail(t, "test timeout")
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2)
+	require.Contains(t, lines[0], "192.168.0.1")
+	require.Contains(t, lines[1], "192.168.0.1:22")
+	require.Contains(t, lines[1], monitor.Closed)
+}
+
+func TestMonitorDiffRescan(t *testing.T) {
+	t.Parallel()
+	opts := &monitorCmdOpts{monitorInterval: time.Millisecond, closedAfter: 1, fullEvery: 3}
+	require.NoError(t, opts.parseRawOptions())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	requests, err := opts.withMonitor(scan.NewIPPortGenerator(scan.NewIPGenerator(), scan.NewPortGenerator())).
+		GenerateRequests(ctx, &scan.Range{
+			DstSubnet: &net.IPNet{IP: net.IPv4(192, 168, 0, 0), Mask: net.CIDRMask(30, 32)},
+			Ports:     []*scan.PortRange{{StartPort: 22, EndPort: 22}},
+		})
+	require.NoError(t, err)
+
+	// the first run is full, the open port appears in it
+	for i := 0; i < 4; i++ {
+		request := <-requests
+		if request.DstIP.Equal(net.IPv4(192, 168, 0, 1)) {
+			opts.tracker.Record(&tcp.ScanResult{ScanType: tcp.SYNScanType, IP: request.DstIP.String(), Port: request.DstPort})
+		}
+	}
+	// the next run scans only the changed host, stable hosts are not sampled
+	request := <-requests
+	require.Equal(t, net.IPv4(192, 168, 0, 1).To4(), request.DstIP.To4())
+	opts.tracker.Record(&tcp.ScanResult{ScanType: tcp.SYNScanType, IP: request.DstIP.String(), Port: request.DstPort})
+	request = <-requests
+	require.Equal(t, net.IPv4(192, 168, 0, 1).To4(), request.DstIP.To4())
+}
@@ -0,0 +1,87 @@
+package command
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/v-byte-cpu/sx/command/log"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+	"github.com/v-byte-cpu/sx/pkg/scan/mssql"
+)
+
+func newMSSQLCmd() *mssqlCmd {
+	c := &mssqlCmd{}
+
+	cmd := &cobra.Command{
+		Use: "mssql [flags] [subnet]",
+		Example: strings.Join([]string{
+			"mssql -p 1434 192.168.0.1/24", "mssql --timeout 500ms -p 1434 10.0.0.1/16",
+			"mssql --prelogin -p 1434 192.168.0.1/24",
+			"mssql -f ip_ports_file.jsonl", "mssql -p 1434 -f ips_file.jsonl"}, "\n"),
+		Short: "Perform SQL Server Browser service scan",
+		Long: strings.Join([]string{
+			"Perform SQL Server Browser service scan.",
+			"The CLNT_BCAST_EX request is sent to each target over UDP, usually to the port 1434,",
+			"SQL Server instances are reported with their names, versions, TCP ports and named pipes.",
+			"With --prelogin the TDS PRELOGIN request is sent to the TCP port of every instance",
+			"to collect its version and encryption mode."}, "\n"),
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer cancel()
+
+			if err = c.opts.parseRawOptions(); err != nil {
+				return
+			}
+			scanRange, err := c.opts.parseScanRange(args)
+			if err != nil {
+				return
+			}
+
+			var logger log.Logger
+			if logger, err = c.opts.getLogger(mssql.ScanType, resultWriter); err != nil {
+				return
+			}
+
+			engine := c.opts.newMSSQLScanEngine(ctx)
+			return c.opts.runScanEngine(ctx, cmd, engine, scanRange, logger)
+		},
+	}
+
+	c.opts.initCliFlags(cmd)
+
+	c.cmd = cmd
+	return c
+}
+
+type mssqlCmd struct {
+	cmd  *cobra.Command
+	opts mssqlCmdOpts
+}
+
+type mssqlCmdOpts struct {
+	genericScanCmdOpts
+	timeout  time.Duration
+	prelogin bool
+}
+
+func (o *mssqlCmdOpts) initCliFlags(cmd *cobra.Command) {
+	o.genericScanCmdOpts.initCliFlags(cmd)
+	cmd.Flags().DurationVarP(&o.timeout, "timeout", "t", 2*time.Second, "set connect and data timeout")
+	cmd.Flags().BoolVar(&o.prelogin, "prelogin", false,
+		"send TDS PRELOGIN request to TCP ports of discovered instances")
+}
+
+func (o *mssqlCmdOpts) newMSSQLScanEngine(ctx context.Context) scan.EngineResulter {
+	opts := []mssql.ScannerOption{
+		mssql.WithDialTimeout(o.timeout),
+		mssql.WithDataTimeout(o.timeout),
+	}
+	if o.prelogin {
+		opts = append(opts, mssql.WithPrelogin())
+	}
+	return o.newScanEngine(ctx, mssql.NewScanner(opts...))
+}
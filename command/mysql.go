@@ -0,0 +1,74 @@
+package command
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/v-byte-cpu/sx/command/log"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+	"github.com/v-byte-cpu/sx/pkg/scan/mysql"
+)
+
+func newMySQLCmd() *mysqlCmd {
+	c := &mysqlCmd{}
+
+	cmd := &cobra.Command{
+		Use: "mysql [flags] [subnet]",
+		Example: strings.Join([]string{
+			"mysql -p 3306 192.168.0.1/24", "mysql -p 3306,33060 10.0.0.1",
+			"mysql --json -p 3306 10.0.0.1/16",
+			"mysql -f ip_ports_file.jsonl", "mysql -p 3306 -f ips_file.jsonl"}, "\n"),
+		Short: "Perform MySQL version and handshake fingerprint scan",
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer cancel()
+
+			if err = c.opts.parseRawOptions(); err != nil {
+				return
+			}
+			scanRange, err := c.opts.parseScanRange(args)
+			if err != nil {
+				return
+			}
+
+			var logger log.Logger
+			if logger, err = c.opts.getLogger(mysql.ScanType, resultWriter); err != nil {
+				return
+			}
+
+			engine := c.opts.newMySQLScanEngine(ctx)
+			return c.opts.runScanEngine(ctx, cmd, engine, scanRange, logger)
+		},
+	}
+
+	c.opts.initCliFlags(cmd)
+
+	c.cmd = cmd
+	return c
+}
+
+type mysqlCmd struct {
+	cmd  *cobra.Command
+	opts mysqlCmdOpts
+}
+
+type mysqlCmdOpts struct {
+	genericScanCmdOpts
+	timeout time.Duration
+}
+
+func (o *mysqlCmdOpts) initCliFlags(cmd *cobra.Command) {
+	o.genericScanCmdOpts.initCliFlags(cmd)
+	cmd.Flags().DurationVarP(&o.timeout, "timeout", "t", 2*time.Second, "set connect and data timeout")
+}
+
+func (o *mysqlCmdOpts) newMySQLScanEngine(ctx context.Context) scan.EngineResulter {
+	return o.newScanEngine(ctx, mysql.NewScanner(
+		mysql.WithDialTimeout(o.timeout),
+		mysql.WithDataTimeout(o.timeout),
+	))
+}
@@ -0,0 +1,96 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/v-byte-cpu/sx/command/log"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+	"github.com/v-byte-cpu/sx/pkg/scan/natpmp"
+)
+
+const defaultNATPMPPort = 5351
+
+func newNATPMPCmd() *natpmpCmd {
+	c := &natpmpCmd{}
+
+	cmd := &cobra.Command{
+		Use: "natpmp [flags] [subnet]",
+		Example: strings.Join([]string{
+			"natpmp 192.168.0.1/24", "natpmp -p 5351 10.0.0.1/16",
+			"natpmp -f ip_ports_file.jsonl", "natpmp -p 5351 -f ips_file.jsonl"}, "\n"),
+		Short: "Perform NAT-PMP and PCP gateway scan",
+		Long: strings.Join([]string{
+			"Perform NAT-PMP and PCP gateway scan.",
+			"The NAT-PMP external address request and the PCP ANNOUNCE request are sent to each target over UDP,",
+			"port 5351 by default, gateways that answered are reported with result codes, the external address",
+			"and the epoch. The requests don't create port mappings.",
+			"Gateways must answer these requests only on the LAN side, so results on the WAN side are misconfigurations."}, "\n"),
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer cancel()
+
+			if err = c.opts.parseRawOptions(); err != nil {
+				return
+			}
+			scanRange, err := c.opts.parseScanRange(args)
+			if err != nil {
+				return
+			}
+
+			var logger log.Logger
+			if logger, err = c.opts.getLogger(natpmp.ScanType, resultWriter); err != nil {
+				return
+			}
+
+			engine := c.opts.newNATPMPScanEngine(ctx)
+			return c.opts.runScanEngine(ctx, cmd, engine, scanRange, logger)
+		},
+	}
+
+	c.opts.initCliFlags(cmd)
+
+	c.cmd = cmd
+	return c
+}
+
+type natpmpCmd struct {
+	cmd  *cobra.Command
+	opts natpmpCmdOpts
+}
+
+type natpmpCmdOpts struct {
+	genericScanCmdOpts
+	timeout time.Duration
+	retries int
+}
+
+func (o *natpmpCmdOpts) initCliFlags(cmd *cobra.Command) {
+	o.genericScanCmdOpts.initCliFlags(cmd)
+	cmd.Flags().DurationVarP(&o.timeout, "timeout", "t", 1*time.Second, "set time to wait for a response to each request")
+	cmd.Flags().IntVar(&o.retries, "retries", 1, "set number of additional requests if there is no response")
+}
+
+func (o *natpmpCmdOpts) parseRawOptions() (err error) {
+	if err = o.genericScanCmdOpts.parseRawOptions(); err != nil {
+		return
+	}
+	if o.retries < 0 {
+		return errors.New("invalid retries: non-negative number required")
+	}
+	// targets of the subnet argument are scanned on the standard port unless ports are set
+	if len(o.portRanges) == 0 && len(o.ipFile) == 0 && len(o.rawInput) == 0 {
+		o.portRanges = []*scan.PortRange{{StartPort: defaultNATPMPPort, EndPort: defaultNATPMPPort}}
+	}
+	return
+}
+
+func (o *natpmpCmdOpts) newNATPMPScanEngine(ctx context.Context) scan.EngineResulter {
+	scanner := natpmp.NewScanner(natpmp.WithDataTimeout(o.timeout), natpmp.WithRetries(o.retries))
+	return o.newScanEngine(ctx, scanner)
+}
@@ -0,0 +1,98 @@
+package command
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+func TestNATPMPCmdDstSubnetError(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{
+			name: "RequiredArg",
+			args: nil,
+		},
+		{
+			name: "InvalidDstSubnet",
+			args: []string{"invalid_ip_address"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := newNATPMPCmd().cmd
+			err := cmd.RunE(cmd, tt.args)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestNATPMPCmdOptsInitCliFlags(t *testing.T) {
+	t.Parallel()
+	var opts natpmpCmdOpts
+	cmd := &cobra.Command{}
+
+	opts.initCliFlags(cmd)
+	err := cmd.ParseFlags(strings.Split(
+		"--json -p 5351 -f ip_file.jsonl -w 300 --exit-delay 10s --timeout 3s --retries 2", " "))
+
+	require.NoError(t, err)
+	require.Equal(t, true, opts.json)
+	require.Equal(t, "5351", opts.rawPortRanges)
+	require.Equal(t, "ip_file.jsonl", opts.ipFile)
+	require.Equal(t, 300, opts.workers)
+	require.Equal(t, 10*time.Second, opts.exitDelay)
+
+	require.Equal(t, 3*time.Second, opts.timeout)
+	require.Equal(t, 2, opts.retries)
+}
+
+func TestNATPMPCmdOptsParseRawOptions(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		opts     natpmpCmdOpts
+		expected []*scan.PortRange
+	}{
+		{
+			name: "Ports",
+			opts: natpmpCmdOpts{
+				genericScanCmdOpts: genericScanCmdOpts{rawPortRanges: "5351,5350", workers: 300},
+			},
+			expected: []*scan.PortRange{{StartPort: 5351, EndPort: 5351}, {StartPort: 5350, EndPort: 5350}},
+		},
+		{
+			name: "DefaultPort",
+			opts: natpmpCmdOpts{
+				genericScanCmdOpts: genericScanCmdOpts{workers: 300},
+			},
+			expected: []*scan.PortRange{{StartPort: 5351, EndPort: 5351}},
+		},
+	}
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			err := tt.opts.parseRawOptions()
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, tt.opts.portRanges)
+		})
+	}
+}
+
+func TestNATPMPCmdOptsParseRawOptionsInvalidRetries(t *testing.T) {
+	t.Parallel()
+	opts := natpmpCmdOpts{
+		genericScanCmdOpts: genericScanCmdOpts{workers: 300},
+		retries:            -1,
+	}
+
+	require.Error(t, opts.parseRawOptions())
+}
@@ -0,0 +1,75 @@
+package command
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/v-byte-cpu/sx/command/log"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+	"github.com/v-byte-cpu/sx/pkg/scan/netbios"
+)
+
+func newNetBIOSCmd() *netbiosCmd {
+	c := &netbiosCmd{}
+
+	cmd := &cobra.Command{
+		Use: "netbios [flags] [subnet]",
+		Example: strings.Join([]string{
+			"netbios -p 137 192.168.0.1/24", "netbios --timeout 500ms -p 137 10.0.0.1/16",
+			"netbios -f ip_ports_file.jsonl", "netbios -p 137 -f ips_file.jsonl"}, "\n"),
+		Short: "Perform NetBIOS name service scan",
+		Long: strings.Join([]string{
+			"Perform NetBIOS name service scan.",
+			"The node status (NBSTAT) request is sent to each target over UDP,",
+			"hosts are reported with their machine name, domain or workgroup and MAC address."}, "\n"),
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer cancel()
+
+			if err = c.opts.parseRawOptions(); err != nil {
+				return
+			}
+			scanRange, err := c.opts.parseScanRange(args)
+			if err != nil {
+				return
+			}
+
+			var logger log.Logger
+			if logger, err = c.opts.getLogger(netbios.ScanType, resultWriter); err != nil {
+				return
+			}
+
+			engine := c.opts.newNetBIOSScanEngine(ctx)
+			return c.opts.runScanEngine(ctx, cmd, engine, scanRange, logger)
+		},
+	}
+
+	c.opts.initCliFlags(cmd)
+
+	c.cmd = cmd
+	return c
+}
+
+type netbiosCmd struct {
+	cmd  *cobra.Command
+	opts netbiosCmdOpts
+}
+
+type netbiosCmdOpts struct {
+	genericScanCmdOpts
+	timeout time.Duration
+}
+
+func (o *netbiosCmdOpts) initCliFlags(cmd *cobra.Command) {
+	o.genericScanCmdOpts.initCliFlags(cmd)
+	cmd.Flags().DurationVarP(&o.timeout, "timeout", "t", 1*time.Second, "set time to wait for the response")
+}
+
+func (o *netbiosCmdOpts) newNetBIOSScanEngine(ctx context.Context) scan.EngineResulter {
+	scanner := netbios.NewScanner(netbios.WithDataTimeout(o.timeout))
+	return o.newScanEngine(ctx, scanner)
+}
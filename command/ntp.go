@@ -0,0 +1,77 @@
+package command
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/v-byte-cpu/sx/command/log"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+	"github.com/v-byte-cpu/sx/pkg/scan/ntp"
+)
+
+func newNTPCmd() *ntpCmd {
+	c := &ntpCmd{}
+
+	cmd := &cobra.Command{
+		Use: "ntp [flags] [subnet]",
+		Example: strings.Join([]string{
+			"ntp -p 123 192.168.0.1/24", "ntp --timeout 500ms -p 123 10.0.0.1/16",
+			"ntp -f ip_ports_file.jsonl", "ntp -p 123 -f ips_file.jsonl"}, "\n"),
+		Short: "Perform NTP version and monlist scan",
+		Long: strings.Join([]string{
+			"Perform NTP version and monlist scan.",
+			"The client request and the mode 7 MON_GETLIST request are sent to each target over UDP,",
+			"servers are reported with their version, stratum and reference id.",
+			"Servers that answer MON_GETLIST can be abused for amplification attacks,",
+			"the number of response packets and bytes is reported for them."}, "\n"),
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer cancel()
+
+			if err = c.opts.parseRawOptions(); err != nil {
+				return
+			}
+			scanRange, err := c.opts.parseScanRange(args)
+			if err != nil {
+				return
+			}
+
+			var logger log.Logger
+			if logger, err = c.opts.getLogger(ntp.ScanType, resultWriter); err != nil {
+				return
+			}
+
+			engine := c.opts.newNTPScanEngine(ctx)
+			return c.opts.runScanEngine(ctx, cmd, engine, scanRange, logger)
+		},
+	}
+
+	c.opts.initCliFlags(cmd)
+
+	c.cmd = cmd
+	return c
+}
+
+type ntpCmd struct {
+	cmd  *cobra.Command
+	opts ntpCmdOpts
+}
+
+type ntpCmdOpts struct {
+	genericScanCmdOpts
+	timeout time.Duration
+}
+
+func (o *ntpCmdOpts) initCliFlags(cmd *cobra.Command) {
+	o.genericScanCmdOpts.initCliFlags(cmd)
+	cmd.Flags().DurationVarP(&o.timeout, "timeout", "t", 1*time.Second, "set time to wait for responses to each request")
+}
+
+func (o *ntpCmdOpts) newNTPScanEngine(ctx context.Context) scan.EngineResulter {
+	scanner := ntp.NewScanner(ntp.WithDataTimeout(o.timeout))
+	return o.newScanEngine(ctx, scanner)
+}
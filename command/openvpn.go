@@ -0,0 +1,95 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/v-byte-cpu/sx/command/log"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+	"github.com/v-byte-cpu/sx/pkg/scan/openvpn"
+)
+
+const defaultOpenVPNPort = 1194
+
+func newOpenVPNCmd() *openvpnCmd {
+	c := &openvpnCmd{}
+
+	cmd := &cobra.Command{
+		Use: "openvpn [flags] [subnet]",
+		Example: strings.Join([]string{
+			"openvpn 192.168.0.1/24", "openvpn -p 1194,443 10.0.0.1/16",
+			"openvpn -f ip_ports_file.jsonl", "openvpn -p 1194 -f ips_file.jsonl"}, "\n"),
+		Short: "Perform OpenVPN UDP server scan",
+		Long: strings.Join([]string{
+			"Perform OpenVPN UDP server scan.",
+			"The P_CONTROL_HARD_RESET_CLIENT_V2 packet is sent to each target over UDP, port 1194 by default,",
+			"servers that answered with the server reset are reported with the session ID of the server.",
+			"Servers with tls-auth or tls-crypt drop the packet, so they are not reported."}, "\n"),
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer cancel()
+
+			if err = c.opts.parseRawOptions(); err != nil {
+				return
+			}
+			scanRange, err := c.opts.parseScanRange(args)
+			if err != nil {
+				return
+			}
+
+			var logger log.Logger
+			if logger, err = c.opts.getLogger(openvpn.ScanType, resultWriter); err != nil {
+				return
+			}
+
+			engine := c.opts.newOpenVPNScanEngine(ctx)
+			return c.opts.runScanEngine(ctx, cmd, engine, scanRange, logger)
+		},
+	}
+
+	c.opts.initCliFlags(cmd)
+
+	c.cmd = cmd
+	return c
+}
+
+type openvpnCmd struct {
+	cmd  *cobra.Command
+	opts openvpnCmdOpts
+}
+
+type openvpnCmdOpts struct {
+	genericScanCmdOpts
+	timeout time.Duration
+	retries int
+}
+
+func (o *openvpnCmdOpts) initCliFlags(cmd *cobra.Command) {
+	o.genericScanCmdOpts.initCliFlags(cmd)
+	cmd.Flags().DurationVarP(&o.timeout, "timeout", "t", 1*time.Second, "set time to wait for a response to each request")
+	cmd.Flags().IntVar(&o.retries, "retries", 1, "set number of additional requests if there is no response")
+}
+
+func (o *openvpnCmdOpts) parseRawOptions() (err error) {
+	if err = o.genericScanCmdOpts.parseRawOptions(); err != nil {
+		return
+	}
+	if o.retries < 0 {
+		return errors.New("invalid retries: non-negative number required")
+	}
+	// targets of the subnet argument are scanned on the standard port unless ports are set
+	if len(o.portRanges) == 0 && len(o.ipFile) == 0 && len(o.rawInput) == 0 {
+		o.portRanges = []*scan.PortRange{{StartPort: defaultOpenVPNPort, EndPort: defaultOpenVPNPort}}
+	}
+	return
+}
+
+func (o *openvpnCmdOpts) newOpenVPNScanEngine(ctx context.Context) scan.EngineResulter {
+	scanner := openvpn.NewScanner(openvpn.WithDataTimeout(o.timeout), openvpn.WithRetries(o.retries))
+	return o.newScanEngine(ctx, scanner)
+}
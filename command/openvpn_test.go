@@ -0,0 +1,98 @@
+package command
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+func TestOpenVPNCmdDstSubnetError(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{
+			name: "RequiredArg",
+			args: nil,
+		},
+		{
+			name: "InvalidDstSubnet",
+			args: []string{"invalid_ip_address"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := newOpenVPNCmd().cmd
+			err := cmd.RunE(cmd, tt.args)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestOpenVPNCmdOptsInitCliFlags(t *testing.T) {
+	t.Parallel()
+	var opts openvpnCmdOpts
+	cmd := &cobra.Command{}
+
+	opts.initCliFlags(cmd)
+	err := cmd.ParseFlags(strings.Split(
+		"--json -p 1194 -f ip_file.jsonl -w 300 --exit-delay 10s --timeout 3s --retries 2", " "))
+
+	require.NoError(t, err)
+	require.Equal(t, true, opts.json)
+	require.Equal(t, "1194", opts.rawPortRanges)
+	require.Equal(t, "ip_file.jsonl", opts.ipFile)
+	require.Equal(t, 300, opts.workers)
+	require.Equal(t, 10*time.Second, opts.exitDelay)
+
+	require.Equal(t, 3*time.Second, opts.timeout)
+	require.Equal(t, 2, opts.retries)
+}
+
+func TestOpenVPNCmdOptsParseRawOptions(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		opts     openvpnCmdOpts
+		expected []*scan.PortRange
+	}{
+		{
+			name: "Ports",
+			opts: openvpnCmdOpts{
+				genericScanCmdOpts: genericScanCmdOpts{rawPortRanges: "1194,443", workers: 300},
+			},
+			expected: []*scan.PortRange{{StartPort: 1194, EndPort: 1194}, {StartPort: 443, EndPort: 443}},
+		},
+		{
+			name: "DefaultPort",
+			opts: openvpnCmdOpts{
+				genericScanCmdOpts: genericScanCmdOpts{workers: 300},
+			},
+			expected: []*scan.PortRange{{StartPort: 1194, EndPort: 1194}},
+		},
+	}
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			err := tt.opts.parseRawOptions()
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, tt.opts.portRanges)
+		})
+	}
+}
+
+func TestOpenVPNCmdOptsParseRawOptionsInvalidRetries(t *testing.T) {
+	t.Parallel()
+	opts := openvpnCmdOpts{
+		genericScanCmdOpts: genericScanCmdOpts{workers: 300},
+		retries:            -1,
+	}
+
+	require.Error(t, opts.parseRawOptions())
+}
@@ -0,0 +1,78 @@
+package command
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/v-byte-cpu/sx/command/log"
+	"github.com/v-byte-cpu/sx/pkg/policy"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+// policyCmdOpts are options of scans which results are open ports,
+// so they can be checked against the expected state
+type policyCmdOpts struct {
+	policy  *policy.Policy
+	checker *policy.Checker
+
+	rawPolicyFile string
+}
+
+func (o *policyCmdOpts) initCliFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&o.rawPolicyFile, "policy", "",
+		"set YAML file with expected open ports, violations are reported after the scan")
+}
+
+func (o *policyCmdOpts) parseRawOptions() (err error) {
+	if len(o.rawPolicyFile) == 0 {
+		return
+	}
+	if o.policy, err = policy.LoadFile(o.rawPolicyFile); err != nil {
+		return
+	}
+	o.checker = policy.NewChecker(o.policy)
+	return
+}
+
+// withPolicyProbes records probed ports of requests if the policy is set,
+// so that only probed expected ports are reported closed
+func (o *policyCmdOpts) withPolicyProbes(reqgen scan.RequestGenerator) scan.RequestGenerator {
+	if o.checker == nil {
+		return reqgen
+	}
+	return scan.NewObserveRequestGenerator(reqgen, func(r *scan.Request) {
+		o.checker.Probe(r.DstIP, r.DstPort)
+	})
+}
+
+// newPolicyChecker wraps the logger to record open ports of logged results if the policy is set
+func (o *policyCmdOpts) newPolicyChecker(logger log.Logger) (log.Logger, *policy.Checker) {
+	if o.checker == nil {
+		return logger, nil
+	}
+	return log.NewFilterLogger(logger, o.checker.Record), o.checker
+}
+
+// checkPolicy logs policy violations as scan results,
+// an error with the violations exit code is returned if there are any
+func checkPolicy(cmd *cobra.Command, logger log.Logger, checker *policy.Checker) error {
+	if checker == nil {
+		return nil
+	}
+	violations := checker.Violations()
+	if len(violations) == 0 {
+		return nil
+	}
+	results := make(chan scan.Result, len(violations))
+	for _, v := range violations {
+		results <- v
+	}
+	close(results)
+	if err := logger.LogResults(context.Background(), results); err != nil {
+		return err
+	}
+
+	cmd.SilenceUsage = true
+	return &exitError{code: exitCodeViolations, err: fmt.Errorf("policy violations found: %d", len(violations))}
+}
@@ -0,0 +1,113 @@
+package command
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+	"github.com/v-byte-cpu/sx/command/log"
+	"github.com/v-byte-cpu/sx/pkg/policy"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+	"github.com/v-byte-cpu/sx/pkg/scan/tcp"
+)
+
+const testPolicy = `
+rules:
+  - name: web
+    hosts: [10.0.0.1]
+    open: [80, 443]
+`
+
+func writeTestPolicy(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "policy.yml")
+	require.NoError(t, os.WriteFile(path, []byte(testPolicy), 0600))
+	return path
+}
+
+func TestPolicyCmdOptsParseRawOptions(t *testing.T) {
+	t.Parallel()
+	opts := policyCmdOpts{rawPolicyFile: writeTestPolicy(t)}
+	require.NoError(t, opts.parseRawOptions())
+	require.NotNil(t, opts.policy)
+	require.Len(t, opts.policy.Rules, 1)
+
+	opts = policyCmdOpts{rawPolicyFile: filepath.Join(t.TempDir(), "none.yml")}
+	require.Error(t, opts.parseRawOptions())
+}
+
+func TestPolicyCmdOptsWithoutPolicy(t *testing.T) {
+	t.Parallel()
+	var opts policyCmdOpts
+	require.NoError(t, opts.parseRawOptions())
+
+	logger, err := log.NewLogger(newResultWriter(&bytes.Buffer{}, "test", false), "test")
+	require.NoError(t, err)
+	scanLogger, checker := opts.newPolicyChecker(logger)
+	require.Equal(t, logger, scanLogger)
+	require.Nil(t, checker)
+	require.NoError(t, checkPolicy(&cobra.Command{}, logger, checker))
+}
+
+func TestCheckPolicyViolations(t *testing.T) {
+	t.Parallel()
+	opts := policyCmdOpts{rawPolicyFile: writeTestPolicy(t)}
+	require.NoError(t, opts.parseRawOptions())
+
+	var buf bytes.Buffer
+	logger, err := log.NewLogger(newResultWriter(&buf, "test", true), "test")
+	require.NoError(t, err)
+	scanLogger, checker := opts.newPolicyChecker(logger)
+
+	// ports 80, 443 and 22 of the host are probed, 443 of other hosts isn't checked
+	reqgen := opts.withPolicyProbes(scan.NewIPPortGenerator(scan.NewIPGenerator(), scan.NewPortGenerator()))
+	requests, err := reqgen.GenerateRequests(context.Background(), &scan.Range{
+		DstSubnet: &net.IPNet{IP: net.IPv4(10, 0, 0, 1).To4(), Mask: net.CIDRMask(32, 32)},
+		Ports:     []*scan.PortRange{{StartPort: 22, EndPort: 22}, {StartPort: 80, EndPort: 80}, {StartPort: 443, EndPort: 443}},
+	})
+	require.NoError(t, err)
+	for request := range requests {
+		require.NoError(t, request.Err)
+	}
+
+	results := make(chan scan.Result, 2)
+	results <- &tcp.ScanResult{ScanType: tcp.SYNScanType, IP: "10.0.0.1", Port: 80}
+	results <- &tcp.ScanResult{ScanType: tcp.SYNScanType, IP: "10.0.0.1", Port: 22}
+	close(results)
+	require.NoError(t, scanLogger.LogResults(context.Background(), results))
+
+	cmd := &cobra.Command{}
+	err = checkPolicy(cmd, logger, checker)
+	var exitErr *exitError
+	require.ErrorAs(t, err, &exitErr)
+	require.Equal(t, exitCodeViolations, exitErr.code)
+	require.True(t, cmd.SilenceUsage)
+
+	require.Equal(t, []string{
+		`{"scan":"tcpsyn","ip":"10.0.0.1","port":80}`,
+		`{"scan":"tcpsyn","ip":"10.0.0.1","port":22}`,
+		`{"scan":"policy","violation":"unexpected_open","ip":"10.0.0.1","port":22,"rule":"web"}`,
+		`{"scan":"policy","violation":"expected_closed","ip":"10.0.0.1","port":443,"rule":"web"}`,
+	}, strings.Split(strings.TrimSpace(buf.String()), "\n"))
+}
+
+func TestTCPFlagsCmdOptsParseRawOptionsPolicy(t *testing.T) {
+	t.Parallel()
+	opts := tcpFlagsCmdOpts{
+		rawTCPFlags:   "fin",
+		policyCmdOpts: policyCmdOpts{rawPolicyFile: writeTestPolicy(t)},
+	}
+	opts.rawPortRanges = "80"
+	require.ErrorIs(t, opts.parseRawOptions(), errTCPflagPolicy)
+
+	opts = tcpFlagsCmdOpts{policyCmdOpts: policyCmdOpts{rawPolicyFile: writeTestPolicy(t)}}
+	opts.rawPortRanges = "80"
+	require.NoError(t, opts.parseRawOptions())
+	require.IsType(t, &policy.Policy{}, opts.policy)
+}
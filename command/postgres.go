@@ -0,0 +1,74 @@
+package command
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/v-byte-cpu/sx/command/log"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+	"github.com/v-byte-cpu/sx/pkg/scan/postgres"
+)
+
+func newPostgresCmd() *postgresCmd {
+	c := &postgresCmd{}
+
+	cmd := &cobra.Command{
+		Use: "postgres [flags] [subnet]",
+		Example: strings.Join([]string{
+			"postgres -p 5432 192.168.0.1/24", "postgres -p 5432,5433 10.0.0.1",
+			"postgres --json -p 5432 10.0.0.1/16",
+			"postgres -f ip_ports_file.jsonl", "postgres -p 5432 -f ips_file.jsonl"}, "\n"),
+		Short: "Perform PostgreSQL TLS support and authentication method scan",
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer cancel()
+
+			if err = c.opts.parseRawOptions(); err != nil {
+				return
+			}
+			scanRange, err := c.opts.parseScanRange(args)
+			if err != nil {
+				return
+			}
+
+			var logger log.Logger
+			if logger, err = c.opts.getLogger(postgres.ScanType, resultWriter); err != nil {
+				return
+			}
+
+			engine := c.opts.newPostgresScanEngine(ctx)
+			return c.opts.runScanEngine(ctx, cmd, engine, scanRange, logger)
+		},
+	}
+
+	c.opts.initCliFlags(cmd)
+
+	c.cmd = cmd
+	return c
+}
+
+type postgresCmd struct {
+	cmd  *cobra.Command
+	opts postgresCmdOpts
+}
+
+type postgresCmdOpts struct {
+	genericScanCmdOpts
+	timeout time.Duration
+}
+
+func (o *postgresCmdOpts) initCliFlags(cmd *cobra.Command) {
+	o.genericScanCmdOpts.initCliFlags(cmd)
+	cmd.Flags().DurationVarP(&o.timeout, "timeout", "t", 2*time.Second, "set connect and data timeout")
+}
+
+func (o *postgresCmdOpts) newPostgresScanEngine(ctx context.Context) scan.EngineResulter {
+	return o.newScanEngine(ctx, postgres.NewScanner(
+		postgres.WithDialTimeout(o.timeout),
+		postgres.WithDataTimeout(o.timeout),
+	))
+}
@@ -0,0 +1,212 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/spf13/cobra"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+const (
+	preflightOff   = "off"
+	preflightWarn  = "warn"
+	preflightRaise = "raise"
+
+	procDir = "/proc"
+)
+
+var errPreflightMode = errors.New("invalid preflight mode: off, warn or raise required")
+
+// preflightResult is the report of preflight checks of the running command, they run before its first connect scan
+var (
+	preflightOnce   sync.Once
+	preflightResult *preflightReport
+)
+
+// initPreflight discards the report of the previous command run, e.g. of the previous workflow stage,
+// so that the system is checked for the connections of the running command
+func initPreflight() {
+	preflightOnce = sync.Once{}
+	preflightResult = nil
+}
+
+// preflightCmdOpts are options to check limits of the system that silently break connect scans
+// with many workers: open file descriptors, the conntrack table and ephemeral ports
+type preflightCmdOpts struct {
+	preflight string
+}
+
+func (o *preflightCmdOpts) initCliFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&o.preflight, "preflight", preflightWarn,
+		strings.Join([]string{
+			"set mode of preflight checks of the open file limit, conntrack table and ephemeral ports: off, warn or raise",
+			"raise increases the open file limit to fit the number of connections where permitted"}, "\n"))
+}
+
+func (o *preflightCmdOpts) parseRawOptions() error {
+	switch o.preflight {
+	// empty mode of options created without flags means the default mode
+	case "", preflightOff, preflightWarn, preflightRaise:
+		return nil
+	default:
+		return errPreflightMode
+	}
+}
+
+// runPreflight checks the system once per command run before the first connect scan, warnings are written to stderr
+func (o *preflightCmdOpts) runPreflight(connections int) {
+	preflightOnce.Do(func() {
+		if o.preflight == preflightOff {
+			return
+		}
+		checker := &preflightChecker{
+			procDir:            procDir,
+			openFileLimit:      scan.OpenFileLimit,
+			raiseOpenFileLimit: scan.RaiseOpenFileLimit,
+		}
+		preflightResult = checker.check(connections, o.preflight == preflightRaise)
+		for _, warning := range preflightResult.warnings {
+			fmt.Fprintln(os.Stderr, "Warning: preflight:", warning)
+		}
+	})
+}
+
+// preflightReport holds the limits found by preflight checks, zero values mean the limit is unknown
+type preflightReport struct {
+	openFiles uint64
+	// raisedFrom is the open file limit before it was raised, zero if it was not raised
+	raisedFrom     uint64
+	conntrackCount int
+	conntrackMax   int
+	ephemeralPorts int
+	warnings       []string
+}
+
+func (r *preflightReport) String() string {
+	var parts []string
+	if r.openFiles > 0 {
+		part := fmt.Sprintf("open files %d", r.openFiles)
+		if r.raisedFrom > 0 {
+			part += fmt.Sprintf(" (raised from %d)", r.raisedFrom)
+		}
+		parts = append(parts, part)
+	}
+	if r.conntrackMax > 0 {
+		parts = append(parts, fmt.Sprintf("conntrack %d of %d used", r.conntrackCount, r.conntrackMax))
+	}
+	if r.ephemeralPorts > 0 {
+		parts = append(parts, fmt.Sprintf("ephemeral ports %d", r.ephemeralPorts))
+	}
+	return strings.Join(parts, ", ")
+}
+
+type preflightChecker struct {
+	procDir            string
+	openFileLimit      func() (uint64, error)
+	raiseOpenFileLimit func(limit uint64) error
+}
+
+// check compares system limits with the number of simultaneous connections of the scan,
+// checks of limits that are not available on the platform are skipped
+func (c *preflightChecker) check(connections int, raise bool) *preflightReport {
+	report := &preflightReport{}
+	c.checkOpenFiles(report, connections, raise)
+	c.checkConntrack(report, connections)
+	c.checkEphemeralPorts(report, connections)
+	return report
+}
+
+func (c *preflightChecker) checkOpenFiles(report *preflightReport, connections int, raise bool) {
+	limit, err := c.openFileLimit()
+	if err != nil || limit == 0 {
+		return
+	}
+	report.openFiles = limit
+	required := uint64(connections) + reservedFileCount
+	if limit >= required {
+		return
+	}
+	if !raise {
+		report.warnings = append(report.warnings, fmt.Sprintf(
+			"open file limit %d is below %d required for %d connections, raise it with ulimit -n or --preflight raise",
+			limit, required, connections))
+		return
+	}
+	if err = c.raiseOpenFileLimit(required); err != nil {
+		report.warnings = append(report.warnings, fmt.Sprintf(
+			"open file limit %d is below %d required for %d connections, failed to raise it: %v",
+			limit, required, connections, err))
+		return
+	}
+	report.openFiles, report.raisedFrom = required, limit
+}
+
+func (c *preflightChecker) checkConntrack(report *preflightReport, connections int) {
+	// the files are missing if the conntrack module is not loaded, then connections are not tracked at all
+	maxEntries, err := c.readInt("sys/net/netfilter/nf_conntrack_max")
+	if err != nil {
+		return
+	}
+	count, err := c.readInt("sys/net/netfilter/nf_conntrack_count")
+	if err != nil {
+		return
+	}
+	report.conntrackCount, report.conntrackMax = count, maxEntries
+	if free := maxEntries - count; free < connections {
+		report.warnings = append(report.warnings, fmt.Sprintf(
+			"conntrack table has %d free entries of %d, below %d connections, new connections are dropped when it is full,"+
+				" raise net.netfilter.nf_conntrack_max", free, maxEntries, connections))
+	}
+}
+
+func (c *preflightChecker) checkEphemeralPorts(report *preflightReport, connections int) {
+	data, err := os.ReadFile(filepath.Join(c.procDir, "sys/net/ipv4/ip_local_port_range"))
+	if err != nil {
+		return
+	}
+	fields := strings.Fields(string(data))
+	if len(fields) != 2 {
+		return
+	}
+	low, err := strconv.Atoi(fields[0])
+	if err != nil {
+		return
+	}
+	high, err := strconv.Atoi(fields[1])
+	if err != nil || high < low {
+		return
+	}
+	report.ephemeralPorts = high - low + 1
+	if report.ephemeralPorts < connections {
+		report.warnings = append(report.warnings, fmt.Sprintf(
+			"ephemeral port range %d-%d is below %d connections, widen net.ipv4.ip_local_port_range",
+			low, high, connections))
+	}
+}
+
+func (c *preflightChecker) readInt(name string) (int, error) {
+	data, err := os.ReadFile(filepath.Join(c.procDir, name))
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(strings.TrimSpace(string(data)))
+}
+
+// writePreflightReport writes limits found by preflight checks as the metadata of the run
+func writePreflightReport(w io.Writer) {
+	// synchronize with preflight checks, they are not run after this call
+	preflightOnce.Do(func() {})
+	if preflightResult == nil {
+		return
+	}
+	if report := preflightResult.String(); len(report) > 0 {
+		fmt.Fprintf(w, "preflight: %s\n", report)
+	}
+}
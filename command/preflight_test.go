@@ -0,0 +1,142 @@
+package command
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPreflightCmdOptsInitCliFlags(t *testing.T) {
+	t.Parallel()
+	cmd := &cobra.Command{}
+	var opts preflightCmdOpts
+	opts.initCliFlags(cmd)
+
+	require.Equal(t, preflightWarn, opts.preflight)
+	require.NoError(t, cmd.ParseFlags(strings.Split("--preflight raise", " ")))
+	require.NoError(t, opts.parseRawOptions())
+	require.Equal(t, preflightRaise, opts.preflight)
+}
+
+func TestPreflightCmdOptsParseRawOptionsError(t *testing.T) {
+	t.Parallel()
+	opts := preflightCmdOpts{preflight: "fix"}
+	require.ErrorIs(t, opts.parseRawOptions(), errPreflightMode)
+}
+
+func newTestProcDir(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, data := range files {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(data), 0o644))
+	}
+	return dir
+}
+
+func TestPreflightChecker(t *testing.T) {
+	t.Parallel()
+	procDir := newTestProcDir(t, map[string]string{
+		"sys/net/netfilter/nf_conntrack_max":   "262144\n",
+		"sys/net/netfilter/nf_conntrack_count": "1000\n",
+		"sys/net/ipv4/ip_local_port_range":     "32768\t60999\n",
+	})
+	checker := &preflightChecker{
+		procDir:       procDir,
+		openFileLimit: func() (uint64, error) { return 1048576, nil },
+	}
+
+	report := checker.check(1000, false)
+	require.Equal(t, &preflightReport{
+		openFiles:      1048576,
+		conntrackCount: 1000,
+		conntrackMax:   262144,
+		ephemeralPorts: 28232,
+	}, report)
+	require.Equal(t, "open files 1048576, conntrack 1000 of 262144 used, ephemeral ports 28232", report.String())
+}
+
+func TestPreflightCheckerWarnings(t *testing.T) {
+	t.Parallel()
+	procDir := newTestProcDir(t, map[string]string{
+		"sys/net/netfilter/nf_conntrack_max":   "65536\n",
+		"sys/net/netfilter/nf_conntrack_count": "60000\n",
+		"sys/net/ipv4/ip_local_port_range":     "50000 54999\n",
+	})
+	checker := &preflightChecker{
+		procDir:       procDir,
+		openFileLimit: func() (uint64, error) { return 1024, nil },
+	}
+
+	report := checker.check(10000, false)
+	require.Len(t, report.warnings, 3)
+	require.Contains(t, report.warnings[0], "open file limit 1024 is below 10064")
+	require.Contains(t, report.warnings[1], "conntrack table has 5536 free entries of 65536")
+	require.Contains(t, report.warnings[2], "ephemeral port range 50000-54999")
+}
+
+func TestPreflightCheckerRaiseOpenFileLimit(t *testing.T) {
+	t.Parallel()
+	var raised uint64
+	checker := &preflightChecker{
+		procDir:       t.TempDir(),
+		openFileLimit: func() (uint64, error) { return 1024, nil },
+		raiseOpenFileLimit: func(limit uint64) error {
+			raised = limit
+			return nil
+		},
+	}
+
+	report := checker.check(5000, true)
+	require.Equal(t, uint64(5000+reservedFileCount), raised)
+	require.Empty(t, report.warnings)
+	require.Equal(t, "open files 5064 (raised from 1024)", report.String())
+}
+
+func TestPreflightCheckerRaiseOpenFileLimitError(t *testing.T) {
+	t.Parallel()
+	checker := &preflightChecker{
+		procDir:       t.TempDir(),
+		openFileLimit: func() (uint64, error) { return 1024, nil },
+		raiseOpenFileLimit: func(uint64) error {
+			return errors.New("operation not permitted")
+		},
+	}
+
+	report := checker.check(5000, true)
+	require.Len(t, report.warnings, 1)
+	require.Contains(t, report.warnings[0], "failed to raise it: operation not permitted")
+	require.Equal(t, "open files 1024", report.String())
+}
+
+func TestPreflightCheckerUnknownLimits(t *testing.T) {
+	t.Parallel()
+	checker := &preflightChecker{
+		procDir:       t.TempDir(),
+		openFileLimit: func() (uint64, error) { return 0, nil },
+	}
+
+	report := checker.check(5000, false)
+	require.Equal(t, &preflightReport{}, report)
+	require.Empty(t, report.String())
+}
+
+func TestInitPreflight(t *testing.T) {
+	initPreflight()
+	t.Cleanup(initPreflight)
+
+	(&preflightCmdOpts{preflight: preflightOff}).runPreflight(10)
+	(&preflightCmdOpts{preflight: preflightWarn}).runPreflight(10)
+	require.Nil(t, preflightResult)
+
+	// the next command run, e.g. the next workflow stage, is checked again
+	initPreflight()
+	(&preflightCmdOpts{preflight: preflightWarn}).runPreflight(10)
+	require.NotNil(t, preflightResult)
+}
@@ -0,0 +1,97 @@
+package command
+
+import (
+	"net"
+	"net/http"
+	httppprof "net/http/pprof"
+	"os"
+	"runtime"
+	"runtime/pprof"
+
+	"github.com/spf13/cobra"
+)
+
+// profileCmdOpts are options to capture CPU and memory profiles of slow scans
+type profileCmdOpts struct {
+	pprofAddr  string
+	cpuProfile string
+	memProfile string
+
+	started  bool
+	cpuFile  *os.File
+	listener net.Listener
+}
+
+func (o *profileCmdOpts) initCliFlags(cmd *cobra.Command) {
+	cmd.PersistentFlags().StringVar(&o.pprofAddr, "pprof-addr", "",
+		"serve live pprof profiles over HTTP on the address, e.g. localhost:6060")
+	cmd.PersistentFlags().StringVar(&o.cpuProfile, "cpuprofile", "", "write CPU profile of the scan to the file")
+	cmd.PersistentFlags().StringVar(&o.memProfile, "memprofile", "", "write memory profile to the file after the scan")
+}
+
+// start starts the pprof HTTP server and CPU profiling if they are enabled
+func (o *profileCmdOpts) start() (err error) {
+	o.started = true
+	if len(o.pprofAddr) > 0 {
+		if o.listener, err = net.Listen("tcp", o.pprofAddr); err != nil {
+			return
+		}
+		mux := http.NewServeMux()
+		mux.HandleFunc("/debug/pprof/", httppprof.Index)
+		mux.HandleFunc("/debug/pprof/cmdline", httppprof.Cmdline)
+		mux.HandleFunc("/debug/pprof/profile", httppprof.Profile)
+		mux.HandleFunc("/debug/pprof/symbol", httppprof.Symbol)
+		mux.HandleFunc("/debug/pprof/trace", httppprof.Trace)
+		go func(l net.Listener) {
+			_ = http.Serve(l, mux)
+		}(o.listener)
+	}
+	if len(o.cpuProfile) > 0 {
+		if o.cpuFile, err = os.Create(o.cpuProfile); err != nil {
+			return
+		}
+		if err = pprof.StartCPUProfile(o.cpuFile); err != nil {
+			o.cpuFile.Close()
+			o.cpuFile = nil
+		}
+	}
+	return
+}
+
+// stop stops CPU profiling, writes the memory profile and shuts down the pprof HTTP server
+func (o *profileCmdOpts) stop() (err error) {
+	if !o.started {
+		return
+	}
+	o.started = false
+	if o.listener != nil {
+		o.listener.Close()
+		o.listener = nil
+	}
+	if o.cpuFile != nil {
+		pprof.StopCPUProfile()
+		err = o.cpuFile.Close()
+		o.cpuFile = nil
+	}
+	if len(o.memProfile) > 0 {
+		if memErr := writeMemProfile(o.memProfile); err == nil {
+			err = memErr
+		}
+	}
+	return
+}
+
+func writeMemProfile(path string) (err error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return
+	}
+	defer func() {
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+	}()
+	// get up-to-date statistics of allocations
+	runtime.GC()
+	return pprof.WriteHeapProfile(f)
+}
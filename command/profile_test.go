@@ -0,0 +1,76 @@
+package command
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProfileCmdOptsInitCliFlags(t *testing.T) {
+	t.Parallel()
+	var opts profileCmdOpts
+	cmd := &cobra.Command{}
+
+	opts.initCliFlags(cmd)
+	err := cmd.ParseFlags(strings.Split(
+		"--pprof-addr localhost:6060 --cpuprofile cpu.out --memprofile mem.out", " "))
+
+	require.NoError(t, err)
+	require.Equal(t, "localhost:6060", opts.pprofAddr)
+	require.Equal(t, "cpu.out", opts.cpuProfile)
+	require.Equal(t, "mem.out", opts.memProfile)
+}
+
+func TestProfileCmdOptsWithoutProfiles(t *testing.T) {
+	t.Parallel()
+	var opts profileCmdOpts
+	require.NoError(t, opts.start())
+	require.Nil(t, opts.listener)
+	require.Nil(t, opts.cpuFile)
+	require.NoError(t, opts.stop())
+}
+
+func TestProfileCmdOptsNotStarted(t *testing.T) {
+	t.Parallel()
+	opts := profileCmdOpts{memProfile: filepath.Join(t.TempDir(), "mem.out")}
+	require.NoError(t, opts.stop())
+	require.NoFileExists(t, opts.memProfile)
+}
+
+func TestProfileCmdOptsProfiles(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	opts := profileCmdOpts{
+		pprofAddr:  "127.0.0.1:0",
+		cpuProfile: filepath.Join(dir, "cpu.out"),
+		memProfile: filepath.Join(dir, "mem.out"),
+	}
+	require.NoError(t, opts.start())
+
+	resp, err := http.Get(fmt.Sprintf("http://%s/debug/pprof/", opts.listener.Addr()))
+	require.NoError(t, err)
+	_, _ = io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	require.NoError(t, opts.stop())
+	for _, path := range []string{opts.cpuProfile, opts.memProfile} {
+		info, err := os.Stat(path)
+		require.NoError(t, err)
+		require.NotZero(t, info.Size(), path)
+	}
+}
+
+func TestProfileCmdOptsInvalidCPUProfile(t *testing.T) {
+	t.Parallel()
+	opts := profileCmdOpts{cpuProfile: filepath.Join(t.TempDir(), "none", "cpu.out")}
+	require.Error(t, opts.start())
+	require.NoError(t, opts.stop())
+}
@@ -0,0 +1,99 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/v-byte-cpu/sx/command/log"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+	"github.com/v-byte-cpu/sx/pkg/scan/prometheus"
+)
+
+// defaultPrometheusPorts are ports of the Prometheus server, pushgateway, alertmanager and popular exporters:
+// node, mysqld, nginx, blackbox, redis, windows and postgres
+const defaultPrometheusPorts = "9090,9091,9093,9100,9104,9113,9115,9121,9182,9187"
+
+func newPrometheusCmd() *prometheusCmd {
+	c := &prometheusCmd{}
+
+	cmd := &cobra.Command{
+		Use: "prometheus [flags] [subnet]",
+		Example: strings.Join([]string{
+			"prometheus 192.168.0.1/24", "prometheus -p 9100 10.0.0.1/16",
+			"prometheus --proto https -p 9090 192.168.0.3",
+			"prometheus -f ip_ports_file.jsonl", "prometheus -p 9090-9200 -f ips_file.jsonl"}, "\n"),
+		Short: "Perform Prometheus server and exporter scan",
+		Long: strings.Join([]string{
+			"Perform Prometheus server and exporter scan.",
+			"The /api/v1/status/buildinfo and /metrics endpoints are requested without credentials,",
+			"common ports of Prometheus components and exporters by default: " + defaultPrometheusPorts + ".",
+			"Targets are reported with the type, e.g. prometheus or node_exporter, the build version",
+			"and the number of exposed metric families."}, "\n"),
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer cancel()
+
+			if err = c.opts.parseRawOptions(); err != nil {
+				return
+			}
+			scanRange, err := c.opts.parseScanRange(args)
+			if err != nil {
+				return
+			}
+
+			var logger log.Logger
+			if logger, err = c.opts.getLogger(prometheus.ScanType, resultWriter); err != nil {
+				return
+			}
+
+			engine := c.opts.newPrometheusScanEngine(ctx)
+			return c.opts.runScanEngine(ctx, cmd, engine, scanRange, logger)
+		},
+	}
+
+	c.opts.initCliFlags(cmd)
+
+	c.cmd = cmd
+	return c
+}
+
+type prometheusCmd struct {
+	cmd  *cobra.Command
+	opts prometheusCmdOpts
+}
+
+type prometheusCmdOpts struct {
+	genericScanCmdOpts
+	timeout time.Duration
+	proto   string
+}
+
+func (o *prometheusCmdOpts) initCliFlags(cmd *cobra.Command) {
+	o.genericScanCmdOpts.initCliFlags(cmd)
+	cmd.Flags().DurationVarP(&o.timeout, "timeout", "t", defaultTimeout, "set request timeout")
+	cmd.Flags().StringVar(&o.proto, "proto", cliHTTPProtoFlag, "set protocol to use, only http or https are valid")
+}
+
+func (o *prometheusCmdOpts) parseRawOptions() (err error) {
+	if err = o.genericScanCmdOpts.parseRawOptions(); err != nil {
+		return
+	}
+	if o.proto != cliHTTPProtoFlag && o.proto != cliHTTPSProtoFlag {
+		return errors.New("invalid HTTP proto flag: http or https required")
+	}
+	// targets of the subnet argument are scanned on standard ports unless ports are set
+	if len(o.portRanges) == 0 && len(o.ipFile) == 0 && len(o.rawInput) == 0 {
+		o.portRanges, err = parsePortRanges(defaultPrometheusPorts)
+	}
+	return
+}
+
+func (o *prometheusCmdOpts) newPrometheusScanEngine(ctx context.Context) scan.EngineResulter {
+	scanner := prometheus.NewScanner(o.proto, prometheus.WithDataTimeout(o.timeout))
+	return o.newScanEngine(ctx, scanner)
+}
@@ -0,0 +1,105 @@
+package command
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+func TestPrometheusCmdDstSubnetError(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{
+			name: "RequiredArg",
+			args: nil,
+		},
+		{
+			name: "InvalidDstSubnet",
+			args: []string{"invalid_ip_address"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := newPrometheusCmd().cmd
+			err := cmd.RunE(cmd, tt.args)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestPrometheusCmdOptsInitCliFlags(t *testing.T) {
+	t.Parallel()
+	var opts prometheusCmdOpts
+	cmd := &cobra.Command{}
+
+	opts.initCliFlags(cmd)
+	err := cmd.ParseFlags(strings.Split(
+		"--json -p 9100,9256 -f ip_file.jsonl -w 300 --exit-delay 10s --timeout 2s --proto https", " "))
+
+	require.NoError(t, err)
+	require.Equal(t, true, opts.json)
+	require.Equal(t, "9100,9256", opts.rawPortRanges)
+	require.Equal(t, "ip_file.jsonl", opts.ipFile)
+	require.Equal(t, 300, opts.workers)
+	require.Equal(t, 10*time.Second, opts.exitDelay)
+
+	require.Equal(t, 2*time.Second, opts.timeout)
+	require.Equal(t, "https", opts.proto)
+}
+
+func TestPrometheusCmdOptsParseRawOptions(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		opts     prometheusCmdOpts
+		expected []*scan.PortRange
+	}{
+		{
+			name: "Ports",
+			opts: prometheusCmdOpts{
+				genericScanCmdOpts: genericScanCmdOpts{rawPortRanges: "9100,9256", workers: 300},
+				proto:              "http",
+			},
+			expected: []*scan.PortRange{{StartPort: 9100, EndPort: 9100}, {StartPort: 9256, EndPort: 9256}},
+		},
+		{
+			name: "DefaultPort",
+			opts: prometheusCmdOpts{
+				genericScanCmdOpts: genericScanCmdOpts{workers: 300},
+				proto:              "http",
+			},
+			expected: []*scan.PortRange{
+				{StartPort: 9090, EndPort: 9090}, {StartPort: 9091, EndPort: 9091}, {StartPort: 9093, EndPort: 9093},
+				{StartPort: 9100, EndPort: 9100}, {StartPort: 9104, EndPort: 9104}, {StartPort: 9113, EndPort: 9113},
+				{StartPort: 9115, EndPort: 9115}, {StartPort: 9121, EndPort: 9121}, {StartPort: 9182, EndPort: 9182},
+				{StartPort: 9187, EndPort: 9187},
+			},
+		},
+	}
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			err := tt.opts.parseRawOptions()
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, tt.opts.portRanges)
+		})
+	}
+}
+
+func TestPrometheusCmdOptsParseRawOptionsInvalidProto(t *testing.T) {
+	t.Parallel()
+	opts := prometheusCmdOpts{
+		genericScanCmdOpts: genericScanCmdOpts{workers: 300},
+		proto:              "ftp",
+	}
+
+	require.Error(t, opts.parseRawOptions())
+}
@@ -0,0 +1,55 @@
+package command
+
+import (
+	"errors"
+	"io"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/v-byte-cpu/sx/command/log"
+)
+
+var errProxyFormatJSON = errors.New("--proxy-format and --json flags are mutually exclusive")
+
+// proxyScanCmdOpts are options of scans that detect proxies,
+// usable proxies can be written in formats of proxy lists and tools instead of scan results
+type proxyScanCmdOpts struct {
+	genericScanCmdOpts
+	proxyFormat string
+
+	proxyEncoder *log.ProxyEncoder
+}
+
+func (o *proxyScanCmdOpts) initCliFlags(cmd *cobra.Command) {
+	o.genericScanCmdOpts.initCliFlags(cmd)
+	cmd.Flags().StringVar(&o.proxyFormat, "proxy-format", "",
+		strings.Join([]string{
+			"write usable proxies instead of scan results in the format: list, proxychains or uri",
+			"list -- ip:port or ip:port:username:password", "proxychains -- socks5 ip port [username password]",
+			"uri -- socks5://username:password@ip:port"}, "\n"))
+}
+
+func (o *proxyScanCmdOpts) parseRawOptions() (err error) {
+	if err = o.genericScanCmdOpts.parseRawOptions(); err != nil {
+		return
+	}
+	if len(o.proxyFormat) == 0 {
+		return
+	}
+	if o.json {
+		return errProxyFormatJSON
+	}
+	o.proxyEncoder, err = log.NewProxyEncoder(o.proxyFormat)
+	return
+}
+
+func (o *proxyScanCmdOpts) getLogger(name string, w io.Writer) (log.Logger, error) {
+	if o.proxyEncoder == nil {
+		return o.genericScanCmdOpts.getLogger(name, w)
+	}
+	logger, err := newLogger(newEncoderResultWriter(w, name, o.proxyEncoder, "txt"), name)
+	if err != nil {
+		return nil, err
+	}
+	return o.withAlertLogger(o.withMonitorLogger(logger)), nil
+}
@@ -0,0 +1,82 @@
+package command
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+	"github.com/v-byte-cpu/sx/command/log"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+	"github.com/v-byte-cpu/sx/pkg/scan/socks4"
+	"github.com/v-byte-cpu/sx/pkg/scan/socks5"
+)
+
+func TestProxyScanCmdOptsInitCliFlags(t *testing.T) {
+	t.Parallel()
+	var opts proxyScanCmdOpts
+	cmd := &cobra.Command{}
+
+	opts.initCliFlags(cmd)
+	require.NoError(t, cmd.ParseFlags(strings.Split("-p 1080 --proxy-format proxychains", " ")))
+	require.NoError(t, opts.parseRawOptions())
+	require.Equal(t, log.ProxyFormatProxyChains, opts.proxyFormat)
+	require.NotNil(t, opts.proxyEncoder)
+}
+
+func TestProxyScanCmdOptsParseRawOptionsError(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		args     string
+		expected error
+	}{
+		{
+			name:     "InvalidFormat",
+			args:     "-p 1080 --proxy-format csv",
+			expected: log.ErrProxyFormat,
+		},
+		{
+			name:     "JSON",
+			args:     "-p 1080 --proxy-format uri --json",
+			expected: errProxyFormatJSON,
+		},
+	}
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var opts proxyScanCmdOpts
+			cmd := &cobra.Command{}
+			opts.initCliFlags(cmd)
+			require.NoError(t, cmd.ParseFlags(strings.Split(tt.args, " ")))
+			require.ErrorIs(t, opts.parseRawOptions(), tt.expected)
+		})
+	}
+}
+
+func TestProxyScanCmdOptsGetLogger(t *testing.T) {
+	t.Parallel()
+	opts := proxyScanCmdOpts{
+		genericScanCmdOpts: genericScanCmdOpts{workers: defaultWorkerCount},
+		proxyFormat:        log.ProxyFormatURI,
+	}
+	require.NoError(t, opts.parseRawOptions())
+
+	var buf bytes.Buffer
+	logger, err := opts.getLogger(socks5.ScanType, &buf)
+	require.NoError(t, err)
+
+	results := make(chan scan.Result, 3)
+	results <- &socks5.ScanResult{ScanType: socks5.ScanType, Version: socks5.SOCKSVersion, IP: "10.0.0.1", Port: 1080}
+	results <- &socks5.ScanResult{ScanType: socks5.ScanType, Version: socks5.SOCKSVersion, IP: "10.0.0.2", Port: 1080,
+		Auth: true}
+	results <- &socks4.ScanResult{ScanType: socks4.ScanType, Version: socks4.SOCKSVersion, IP: "10.0.0.3", Port: 1080,
+		Granted: true}
+	close(results)
+	require.NoError(t, logger.LogResults(context.Background(), results))
+
+	require.Equal(t, "socks5://10.0.0.1:1080\nsocks4://10.0.0.3:1080\n", buf.String())
+}
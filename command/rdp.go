@@ -0,0 +1,74 @@
+package command
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/v-byte-cpu/sx/command/log"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+	"github.com/v-byte-cpu/sx/pkg/scan/rdp"
+)
+
+func newRDPCmd() *rdpCmd {
+	c := &rdpCmd{}
+
+	cmd := &cobra.Command{
+		Use: "rdp [flags] [subnet]",
+		Example: strings.Join([]string{
+			"rdp -p 3389 192.168.0.1/24", "rdp -p 3389,3390 10.0.0.1",
+			"rdp --json -p 3389 10.0.0.1/16",
+			"rdp -f ip_ports_file.jsonl", "rdp -p 3389 -f ips_file.jsonl"}, "\n"),
+		Short: "Perform RDP security protocol and NLA scan",
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer cancel()
+
+			if err = c.opts.parseRawOptions(); err != nil {
+				return
+			}
+			scanRange, err := c.opts.parseScanRange(args)
+			if err != nil {
+				return
+			}
+
+			var logger log.Logger
+			if logger, err = c.opts.getLogger(rdp.ScanType, resultWriter); err != nil {
+				return
+			}
+
+			engine := c.opts.newRDPScanEngine(ctx)
+			return c.opts.runScanEngine(ctx, cmd, engine, scanRange, logger)
+		},
+	}
+
+	c.opts.initCliFlags(cmd)
+
+	c.cmd = cmd
+	return c
+}
+
+type rdpCmd struct {
+	cmd  *cobra.Command
+	opts rdpCmdOpts
+}
+
+type rdpCmdOpts struct {
+	genericScanCmdOpts
+	timeout time.Duration
+}
+
+func (o *rdpCmdOpts) initCliFlags(cmd *cobra.Command) {
+	o.genericScanCmdOpts.initCliFlags(cmd)
+	cmd.Flags().DurationVarP(&o.timeout, "timeout", "t", 2*time.Second, "set connect and data timeout")
+}
+
+func (o *rdpCmdOpts) newRDPScanEngine(ctx context.Context) scan.EngineResulter {
+	return o.newScanEngine(ctx, rdp.NewScanner(
+		rdp.WithDialTimeout(o.timeout),
+		rdp.WithDataTimeout(o.timeout),
+	))
+}
@@ -0,0 +1,53 @@
+package command
+
+import (
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/v-byte-cpu/sx/pkg/redact"
+)
+
+// resultRedactor is set if any redaction flag is set, results are written unchanged otherwise
+var resultRedactor *redact.Redactor
+
+// redactCmdOpts are redaction flags of results
+type redactCmdOpts struct {
+	ipMode       string
+	keyFile      string
+	stripBanners bool
+	dropFields   []string
+}
+
+func (o *redactCmdOpts) initCliFlags(cmd *cobra.Command) {
+	cmd.PersistentFlags().StringVar(&o.ipMode, "redact-ip", "",
+		strings.Join([]string{"anonymize IPs of results before they are written, e.g. to share results with vendors",
+			"hash replaces them with keyed hashes, truncate replaces them with their /24 or /48 networks"}, "\n"))
+	cmd.PersistentFlags().StringVar(&o.keyFile, "redact-key-file", "",
+		"set file with the key of IP hashes, the random key of each run is used by default")
+	cmd.PersistentFlags().BoolVar(&o.stripBanners, "strip-banners", false,
+		"remove banner, server and title fields of results with server greetings and software versions")
+	cmd.PersistentFlags().StringSliceVar(&o.dropFields, "drop-fields", nil,
+		"remove fields of results before they are written, nested fields are separated by dots, e.g. mac,meta.owner")
+}
+
+// initRedactor creates the redactor of results if any redaction flag is set
+func (o *redactCmdOpts) initRedactor() (err error) {
+	resultRedactor = nil
+	if len(o.ipMode) == 0 && !o.stripBanners && len(o.dropFields) == 0 {
+		return
+	}
+	opts := []redact.Option{redact.WithIPMode(o.ipMode), redact.WithDroppedFields(o.dropFields)}
+	if o.stripBanners {
+		opts = append(opts, redact.WithStrippedBanners())
+	}
+	if len(o.keyFile) > 0 {
+		var key []byte
+		if key, err = os.ReadFile(o.keyFile); err != nil {
+			return
+		}
+		opts = append(opts, redact.WithKey(key))
+	}
+	resultRedactor, err = redact.NewRedactor(opts...)
+	return
+}
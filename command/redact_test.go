@@ -0,0 +1,42 @@
+package command
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/v-byte-cpu/sx/pkg/redact"
+	"github.com/v-byte-cpu/sx/pkg/scan/tcp"
+)
+
+// TestInitRedactor is not parallel because it changes the global redactor
+func TestInitRedactor(t *testing.T) {
+	var opts redactCmdOpts
+	defer func() {
+		require.NoError(t, (&redactCmdOpts{}).initRedactor())
+	}()
+
+	require.NoError(t, opts.initRedactor())
+	require.Nil(t, resultRedactor)
+
+	keyFile := filepath.Join(t.TempDir(), "key")
+	require.NoError(t, os.WriteFile(keyFile, []byte("secret"), 0o600))
+	opts = redactCmdOpts{ipMode: redact.IPTruncate, keyFile: keyFile, dropFields: []string{"port"}}
+	require.NoError(t, opts.initRedactor())
+	require.NotNil(t, resultRedactor)
+
+	var buf bytes.Buffer
+	rw := newResultWriter(&buf, tcp.SYNScanType, true)
+	require.NoError(t, rw.Write(context.Background(), &tcp.ScanResult{ScanType: tcp.SYNScanType, IP: "192.168.0.3", Port: 22}))
+	require.NoError(t, rw.Close())
+	require.Equal(t, `{"scan":"tcpsyn","ip":"192.168.0.0"}`+"\n", buf.String())
+
+	opts.ipMode = "mask"
+	require.ErrorIs(t, opts.initRedactor(), redact.ErrIPMode)
+
+	opts.ipMode, opts.keyFile = redact.IPHash, filepath.Join(t.TempDir(), "missing")
+	require.Error(t, opts.initRedactor())
+}
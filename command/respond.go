@@ -0,0 +1,181 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/v-byte-cpu/sx/command/log"
+	"github.com/v-byte-cpu/sx/pkg/ip"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+	"github.com/v-byte-cpu/sx/pkg/scan/respond"
+)
+
+var errRespondMode = errors.New("at least one of --arp and --syn flags is required")
+
+func newRespondCmd() *respondCmd {
+	c := &respondCmd{}
+
+	cmd := &cobra.Command{
+		Use: "respond [flags] subnet",
+		Example: strings.Join([]string{
+			"respond --arp -i veth0 10.0.0.0/24",
+			"respond --arp --syn -p 22,80-90 -i veth0 10.0.0.0/24"}, "\n"),
+		Short: "Answer ARP requests and TCP SYNs on behalf of all IPs of the subnet",
+		Long: strings.Join([]string{
+			"Answer ARP requests and TCP SYNs on behalf of all IPs of the subnet until interrupted.",
+			"It is useful to validate scans and result pipelines in isolated labs without real targets.",
+			"Use IPs that are not assigned to the host, otherwise the OS network stack answers as well."}, "\n"),
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer cancel()
+
+			if len(args) != 1 {
+				return errors.New("requires one ip subnet argument")
+			}
+			subnet, err := ip.ParseIPNet(args[0])
+			if err != nil {
+				return
+			}
+			if err = c.opts.parseRawOptions(); err != nil {
+				return
+			}
+			var r *scan.Range
+			if r, err = c.opts.getScanRange(subnet); err != nil {
+				return
+			}
+			var logger log.Logger
+			if logger, err = c.opts.getLogger(); err != nil {
+				return
+			}
+
+			m := c.opts.newResponder(ctx, r)
+
+			return startPacketScanEngine(ctx, newPacketScanConfig(
+				withPacketScanMethod(m),
+				withPacketBPFFilter(m.BPFFilter),
+				withRateCount(c.opts.rateCount),
+				withRateWindow(c.opts.rateWindow),
+				withPacketEngineConfig(newEngineConfig(
+					withLogger(logger),
+					withScanRange(r),
+					withExitDelay(0),
+				)),
+			))
+		},
+	}
+
+	c.opts.initCliFlags(cmd)
+
+	c.cmd = cmd
+	return c
+}
+
+type respondCmd struct {
+	cmd  *cobra.Command
+	opts respondCmdOpts
+}
+
+type respondCmdOpts struct {
+	json       bool
+	arp        bool
+	syn        bool
+	iface      *net.Interface
+	srcMAC     net.HardwareAddr
+	ports      []*scan.PortRange
+	rateCount  int
+	rateWindow time.Duration
+
+	rawInterface  string
+	rawSrcMAC     string
+	rawPortRanges string
+	rawRateLimit  string
+}
+
+func (o *respondCmdOpts) initCliFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&o.json, "json", false, "enable JSON output")
+	cmd.Flags().BoolVar(&o.arp, "arp", false, "answer ARP requests")
+	cmd.Flags().BoolVar(&o.syn, "syn", false, "answer TCP SYNs with SYN-ACK for open ports and RST for others")
+	cmd.Flags().StringVarP(&o.rawInterface, "iface", "i", "", "set interface to send/receive packets")
+	cmd.Flags().StringVar(&o.rawSrcMAC, "srcmac", "", "set MAC address of answered IPs")
+	cmd.Flags().StringVarP(&o.rawPortRanges, "ports", "p", "", "set open ports, all ports are open by default")
+	cmd.Flags().StringVarP(&o.rawRateLimit, "rate", "r", "",
+		strings.Join([]string{
+			"set rate limit for reply packets",
+			`format: "rateCount/rateWindow"`,
+			"where rateCount is a number of packets, rateWindow is the time interval",
+			"e.g. 1000/s -- 1000 packets per second", "500/7s -- 500 packets per 7 seconds\n"}, "\n"))
+}
+
+func (o *respondCmdOpts) parseRawOptions() (err error) {
+	if !o.arp && !o.syn {
+		return errRespondMode
+	}
+	if len(o.rawInterface) > 0 {
+		if o.iface, err = net.InterfaceByName(o.rawInterface); err != nil {
+			return
+		}
+	}
+	if len(o.rawSrcMAC) > 0 {
+		if o.srcMAC, err = net.ParseMAC(o.rawSrcMAC); err != nil {
+			return
+		}
+	}
+	if len(o.rawPortRanges) > 0 {
+		if o.ports, err = parsePortRanges(o.rawPortRanges); err != nil {
+			return
+		}
+	}
+	if len(o.rawRateLimit) > 0 {
+		if o.rateCount, o.rateWindow, err = parseRateLimit(o.rawRateLimit); err != nil {
+			return
+		}
+	}
+	return
+}
+
+func (o *respondCmdOpts) getScanRange(subnet *net.IPNet) (*scan.Range, error) {
+	iface := o.iface
+	if iface == nil {
+		var err error
+		if iface, _, err = ip.GetDefaultInterface(); err != nil {
+			return nil, err
+		}
+	}
+	if iface == nil {
+		return nil, errSrcInterface
+	}
+	srcMAC := iface.HardwareAddr
+	if o.srcMAC != nil {
+		srcMAC = o.srcMAC
+	}
+	if srcMAC == nil {
+		return nil, errSrcMAC
+	}
+	return &scan.Range{
+		Interface: iface,
+		DstSubnet: subnet,
+		SrcMAC:    srcMAC,
+		Ports:     o.ports}, nil
+}
+
+func (o *respondCmdOpts) getLogger() (log.Logger, error) {
+	return newLogger(newResultWriter(resultWriter, "respond", o.json), "respond")
+}
+
+func (o *respondCmdOpts) newResponder(ctx context.Context, r *scan.Range) *respond.Responder {
+	var opts []respond.ResponderOption
+	if o.arp {
+		opts = append(opts, respond.WithARP())
+	}
+	if o.syn {
+		opts = append(opts, respond.WithSYN(o.ports))
+	}
+	results := scan.NewResultChan(ctx, 1000)
+	return respond.NewResponder(ctx, r.DstSubnet, r.SrcMAC, results, opts...)
+}
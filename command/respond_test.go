@@ -0,0 +1,98 @@
+package command
+
+import (
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+func TestRespondCmdDstSubnetError(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{
+			name: "RequiredArg",
+			args: nil,
+		},
+		{
+			name: "InvalidDstSubnet",
+			args: []string{"invalid_ip_address"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := newRespondCmd().cmd
+			err := cmd.RunE(cmd, tt.args)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestRespondCmdOptsInitCliFlags(t *testing.T) {
+	t.Parallel()
+	var opts respondCmdOpts
+	cmd := &cobra.Command{}
+
+	opts.initCliFlags(cmd)
+	err := cmd.ParseFlags(strings.Split(
+		"--json --arp --syn -i eth0 --srcmac 00:11:22:33:44:55 -p 22,80-90 -r 500/7s", " "))
+
+	require.NoError(t, err)
+	require.Equal(t, true, opts.json)
+	require.Equal(t, true, opts.arp)
+	require.Equal(t, true, opts.syn)
+	require.Equal(t, "eth0", opts.rawInterface)
+	require.Equal(t, "00:11:22:33:44:55", opts.rawSrcMAC)
+	require.Equal(t, "22,80-90", opts.rawPortRanges)
+	require.Equal(t, "500/7s", opts.rawRateLimit)
+}
+
+func TestRespondCmdOptsParseRawOptions(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		opts     respondCmdOpts
+		expected respondCmdOpts
+		err      error
+	}{
+		{
+			name: "NoMode",
+			opts: respondCmdOpts{rawPortRanges: "22"},
+			err:  errRespondMode,
+		},
+		{
+			name: "ARPAndSYN",
+			opts: respondCmdOpts{arp: true, syn: true, rawSrcMAC: "00:11:22:33:44:55", rawPortRanges: "22,80-90"},
+			expected: respondCmdOpts{
+				arp:    true,
+				syn:    true,
+				srcMAC: net.HardwareAddr{0x00, 0x11, 0x22, 0x33, 0x44, 0x55},
+				ports: []*scan.PortRange{
+					{StartPort: 22, EndPort: 22},
+					{StartPort: 80, EndPort: 90},
+				},
+				rawSrcMAC:     "00:11:22:33:44:55",
+				rawPortRanges: "22,80-90",
+			},
+		},
+	}
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			err := tt.opts.parseRawOptions()
+			if tt.err != nil {
+				require.ErrorIs(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, tt.opts)
+		})
+	}
+}
@@ -2,9 +2,12 @@ package command
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"math/rand"
 	"os"
+	"strings"
 	"sync"
 	"time"
 
@@ -16,20 +19,49 @@ import (
 	"go.uber.org/ratelimit"
 )
 
+// exitError is returned by commands to exit with the specific code
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string {
+	return e.err.Error()
+}
+
+func (e *exitError) Unwrap() error {
+	return e.err
+}
+
 func Main(version string) {
 	rand.Seed(time.Now().Unix())
-	if err := newRootCmd(version).Execute(); err != nil {
+	c := newRootCmd(version)
+	manifestArgs = os.Args[1:]
+	err := c.cmd.Execute()
+	c.opts.close(err)
+	if err != nil {
+		var exitErr *exitError
+		if errors.As(err, &exitErr) {
+			os.Exit(exitErr.code)
+		}
 		os.Exit(1)
 	}
 }
 
-func newRootCmd(version string) *cobra.Command {
+func newRootCmd(version string) *rootCmd {
+	c := &rootCmd{}
+
 	cmd := &cobra.Command{
 		Use:     "sx",
 		Short:   "Fast, modern, easy-to-use network scanner",
 		Version: version,
+		PersistentPreRunE: func(cmd *cobra.Command, _ []string) error {
+			return c.opts.start(cmd)
+		},
 	}
 
+	c.opts.initCliFlags(cmd)
+
 	tcpCmd := newTCPFlagsCmd().cmd
 	tcpCmd.AddCommand(
 		newTCPSYNCmd().cmd,
@@ -44,11 +76,225 @@ func newRootCmd(version string) *cobra.Command {
 		newUDPCmd().cmd,
 		tcpCmd,
 		newSocksCmd().cmd,
+		newHTTPProxyCmd().cmd,
 		newDockerCmd().cmd,
 		newElasticCmd().cmd,
+		newEtcdCmd().cmd,
+		newCouchDBCmd().cmd,
+		newInfluxCmd().cmd,
+		newPrometheusCmd().cmd,
+		newK8sCmd().cmd,
+		newFTPCmd().cmd,
+		newSMTPCmd().cmd,
+		newMongoCmd().cmd,
+		newMemcachedCmd().cmd,
+		newMySQLCmd().cmd,
+		newMSSQLCmd().cmd,
+		newPostgresCmd().cmd,
+		newCassandraCmd().cmd,
+		newKafkaCmd().cmd,
+		newAMQPCmd().cmd,
+		newModbusCmd().cmd,
+		newS7Cmd().cmd,
+		newDNP3Cmd().cmd,
+		newTLSCmd().cmd,
+		newJARMCmd().cmd,
+		newSSHCmd().cmd,
+		newSMBCmd().cmd,
+		newLDAPCmd().cmd,
+		newWinRMCmd().cmd,
+		newRDPCmd().cmd,
+		newVNCCmd().cmd,
+		newADBCmd().cmd,
+		newHTTPCmd().cmd,
+		newDetectCmd().cmd,
+		newNTPCmd().cmd,
+		newIPMICmd().cmd,
+		newBACnetCmd().cmd,
+		newSNMPCmd().cmd,
+		newSSDPCmd().cmd,
+		newMDNSCmd().cmd,
+		newNetBIOSCmd().cmd,
+		newCoAPCmd().cmd,
+		newTFTPCmd().cmd,
+		newOpenVPNCmd().cmd,
+		newWireGuardCmd().cmd,
+		newSTUNCmd().cmd,
+		newNATPMPCmd().cmd,
+		newDNSCmd().cmd,
+		newDNSRecordsCmd().cmd,
+		newRespondCmd().cmd,
+		newSimulateCmd().cmd,
+		newRerunCmd().cmd,
+		newWorkflowCmd().cmd,
+		newAggregatorCmd().cmd,
+		newHistoryCmd().cmd,
+		newCalibrateCmd().cmd,
 	)
 
-	return cmd
+	c.cmd = cmd
+	return c
+}
+
+type rootCmd struct {
+	cmd  *cobra.Command
+	opts rootCmdOpts
+}
+
+// rootCmdOpts are global options of all commands
+type rootCmdOpts struct {
+	profileCmdOpts
+	manifestCmdOpts
+	redactCmdOpts
+	historyStoreCmdOpts
+	agentCmdOpts
+	tuningCmdOpts
+	// splitOutputPrefix enables writing results of each scan type to the separate file with the prefix instead of resultWriter
+	splitOutputPrefix string
+	// resultWindow enables tagging of results with the start of the time window they are written in
+	resultWindow time.Duration
+}
+
+// rootOpts are options of the running root command, result writers and packet sources of scans are created with them
+var rootOpts = &rootCmdOpts{}
+
+func (o *rootCmdOpts) initCliFlags(cmd *cobra.Command) {
+	o.profileCmdOpts.initCliFlags(cmd)
+	o.manifestCmdOpts.initCliFlags(cmd)
+	o.redactCmdOpts.initCliFlags(cmd)
+	o.historyStoreCmdOpts.initCliFlags(cmd)
+	o.agentCmdOpts.initCliFlags(cmd)
+	o.tuningCmdOpts.initCliFlags(cmd)
+	cmd.PersistentFlags().StringVar(&o.splitOutputPrefix, "split-output", "",
+		strings.Join([]string{"write results of each scan type to a separate file with the prefix",
+			"e.g. out writes TCP SYN results to out-tcpsyn.json with --json and to out-tcpsyn.txt otherwise"}, "\n"))
+	cmd.PersistentFlags().DurationVar(&o.resultWindow, "window", 0,
+		strings.Join([]string{"tag results with the window_start field, the start of the time window of the duration",
+			"e.g. 5m tags results with the start of 5-minute windows aligned to the clock"}, "\n"))
+	cmd.PersistentFlags().StringVar(&dnsWildcardMode, "dns-wildcard", "",
+		strings.Join([]string{"detect names resolved by wildcard DNS records when hostname targets are resolved",
+			"tag adds the dns_wildcard meta field to their requests, drop skips them"}, "\n"))
+	cmd.PersistentFlags().BoolVar(&dnsLiveness, "dns-liveness", false,
+		strings.Join([]string{"check whether hostname targets still resolve before they are scanned",
+			"names that are not found are reported as skipped targets instead of being scanned"}, "\n"))
+	cmd.PersistentFlags().StringVar(&errorsFile, "errors-file", "",
+		strings.Join([]string{"write scan errors to the file in NDJSON format instead of stderr",
+			"e.g. send failures, parse errors of input lines and skipped targets with their reasons"}, "\n"))
+	cmd.PersistentFlags().BoolVar(&safeMode, "safe", false,
+		strings.Join([]string{"enable the profile of conservative defaults for production-adjacent scanning:",
+			"--rate 100/s, --retries 2 where supported, --bogon-guard and --audit-log " + defaultAuditLog + ",",
+			"the --scope file is required, flags set explicitly override the profile, commands without these flags fail"}, "\n"))
+}
+
+// start opens outputs of the command that is about to run and publishes its options to rootOpts
+func (o *rootCmdOpts) start(cmd *cobra.Command) error {
+	rootOpts = o
+	if o.resultWindow < 0 {
+		return errors.New("invalid window: non-negative duration required")
+	}
+	// the safe profile is applied first, so that the manifest records its values,
+	// the tuning profile is applied to packet scans later, when their interface is known
+	if err := o.loadTuningProfile(); err != nil {
+		return err
+	}
+	if err := applySafeProfile(cmd.Flags()); err != nil {
+		return err
+	}
+	if err := o.beginManifest(cmd); err != nil {
+		return err
+	}
+	if err := parseDNSWildcardMode(); err != nil {
+		return err
+	}
+	initDNSLiveness()
+	initInFlightLimiter()
+	initPreflight()
+	if err := o.initRedactor(); err != nil {
+		return err
+	}
+	if err := o.openHistory(); err != nil {
+		return err
+	}
+	if err := openErrorStream(); err != nil {
+		return err
+	}
+	if err := o.openAgentConn(); err != nil {
+		return err
+	}
+	return o.profileCmdOpts.start()
+}
+
+// close closes outputs of the finished command, writes the manifest and statistics of the run to stderr,
+// runErr is the error of the command
+func (o *rootCmdOpts) close(runErr error) {
+	closeAlerts()
+	writeHistoryError(finishHistoryRun())
+	if agentErr := closeAgentConn(); agentErr != nil {
+		fmt.Fprintln(os.Stderr, "Error: aggregator:", agentErr)
+	}
+	if streamErr := closeErrorStream(); streamErr != nil {
+		fmt.Fprintln(os.Stderr, "Error: errors file:", streamErr)
+	}
+	// profiles are written even if the scan fails or exits with a non-zero code
+	if profileErr := o.stop(); profileErr != nil {
+		fmt.Fprintln(os.Stderr, "Error: profile:", profileErr)
+	}
+	if manifestErr := writeManifest(runErr); manifestErr != nil {
+		fmt.Fprintln(os.Stderr, "Error: manifest:", manifestErr)
+	}
+	writeDNSCacheStats(os.Stderr)
+	writeDNSLivenessStats(os.Stderr)
+	writeInFlightStats(os.Stderr)
+	writeDedupStats(os.Stderr)
+	writeCaptureStats(os.Stderr)
+	writePreflightReport(os.Stderr)
+}
+
+// resultWriter is the output of scan results
+var resultWriter io.Writer = os.Stdout
+
+// newResultWriter writes scan results to w in JSON or plain text format,
+// scanType is the type of results that don't have their own scan type
+func newResultWriter(w io.Writer, scanType string, json bool) log.ResultWriter {
+	if json {
+		return newEncoderResultWriter(w, scanType, &log.JSONEncoder{}, "json")
+	}
+	return newEncoderResultWriter(w, scanType, &log.PlainEncoder{}, "txt")
+}
+
+// newEncoderResultWriter writes scan results to w with the encoder, ext is the extension of split output files
+func newEncoderResultWriter(w io.Writer, scanType string, enc log.ResultEncoder, ext string) log.ResultWriter {
+	var rw log.ResultWriter
+	switch {
+	case agentConn != nil:
+		rw = log.NewAgentWriter(agentConn, scanType)
+	case len(rootOpts.splitOutputPrefix) == 0:
+		rw = log.NewStreamWriter(w, enc)
+	default:
+		rw = log.NewDemuxWriter(scanType, func(scanType string) (log.ResultWriter, error) {
+			return log.NewFileWriter(fmt.Sprintf("%s-%s.%s", rootOpts.splitOutputPrefix, scanType, ext), enc)
+		})
+	}
+	if window := rootOpts.resultWindow; window > 0 {
+		rw = log.NewWindowWriter(rw, window)
+	}
+	if resultRedactor != nil {
+		rw = log.NewRedactWriter(rw, resultRedactor, scanType)
+	}
+	return rw
+}
+
+// packetSource reads and writes packets on the network interface
+type packetSource interface {
+	packet.ReadWriter
+	SetBPFFilter(bpfFilter string, maxPacketLength int) error
+	// Stats returns kernel capture counters of the socket
+	Stats() (*afpacket.Stats, error)
+	Close()
+}
+
+var newPacketSource = func(iface string, vpnMode bool) (packetSource, error) {
+	return afpacket.NewPacketSource(iface, vpnMode, afpacket.WithRingBlocks(rootOpts.ringBlocks))
 }
 
 type bpfFilterFunc func(r *scan.Range) (filter string, maxPacketLength int)
@@ -96,6 +342,7 @@ type packetScanConfig struct {
 	rateCount  int
 	rateWindow time.Duration
 	vpnMode    bool
+	sampleCmdOpts
 }
 
 type packetScanConfigOption func(c *packetScanConfig)
@@ -136,6 +383,12 @@ func withPacketVPNmode(vpnMode bool) packetScanConfigOption {
 	}
 }
 
+func withPacketSampling(opts sampleCmdOpts) packetScanConfigOption {
+	return func(c *packetScanConfig) {
+		c.sampleCmdOpts = opts
+	}
+}
+
 func newPacketScanConfig(opts ...packetScanConfigOption) *packetScanConfig {
 	c := &packetScanConfig{}
 	for _, o := range opts {
@@ -165,15 +418,22 @@ func startPacketScanEngine(ctx context.Context, conf *packetScanConfig) error {
 	r := &conf.scanRange
 
 	// setup network interface to read/write packets
-	ps, err := afpacket.NewPacketSource(r.Interface.Name, conf.vpnMode)
+	ps, err := newPacketSource(r.Interface.Name, conf.vpnMode)
 	if err != nil {
 		return err
 	}
 	defer ps.Close()
+	// counters are read before the socket is closed
+	defer recordCaptureStats(r.Interface.Name, ps)
 	err = ps.SetBPFFilter(conf.bpfFilter(r))
 	if err != nil {
 		return fmt.Errorf("BPFFilter: %w", err)
 	}
+	stopSampler, err := startPacketSampler(ctx, conf)
+	if err != nil {
+		return err
+	}
+	defer stopSampler()
 	var rw packet.ReadWriter = ps
 	// setup rate limit for sending packets
 	if conf.rateCount > 0 {
@@ -189,14 +449,7 @@ func startScanEngine(ctx context.Context, engine scan.EngineResulter, conf *engi
 	defer cancel()
 
 	logger := conf.logger
-
-	// setup result logging
-	var wg sync.WaitGroup
-	wg.Add(1)
-	go func() {
-		defer wg.Done()
-		logger.LogResults(ctx, engine.Results())
-	}()
+	recordManifestRange(&conf.scanRange)
 
 	// start scan
 	done, errc := engine.Start(ctx, &conf.scanRange)
@@ -206,6 +459,26 @@ func startScanEngine(ctx context.Context, engine scan.EngineResulter, conf *engi
 		<-time.After(conf.exitDelay)
 	}()
 
+	// setup result logging, the scan is stopped if results can't be written
+	var logErr error
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		if logErr = logger.LogResults(ctx, engine.Results()); logErr == nil {
+			return
+		}
+		cancel()
+		// pending results are discarded, so that the engine is not blocked on them
+		for {
+			select {
+			case <-done:
+				return
+			case <-engine.Results():
+			}
+		}
+	}()
+
 	// error logging
 	wg.Add(1)
 	go func() {
@@ -215,5 +488,5 @@ func startScanEngine(ctx context.Context, engine scan.EngineResulter, conf *engi
 		}
 	}()
 	wg.Wait()
-	return nil
+	return logErr
 }
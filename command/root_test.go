@@ -0,0 +1,96 @@
+package command
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+	"github.com/v-byte-cpu/sx/command/log"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+	"github.com/v-byte-cpu/sx/pkg/scan/tcp"
+)
+
+type brokenWriter struct{}
+
+func (*brokenWriter) Write([]byte) (int, error) {
+	return 0, errors.New("broken pipe")
+}
+
+// setRootOpts replaces options of the running root command for the test
+func setRootOpts(t *testing.T, opts *rootCmdOpts) {
+	t.Helper()
+	prev := rootOpts
+	rootOpts = opts
+	t.Cleanup(func() {
+		rootOpts = prev
+	})
+}
+
+type openPortScanner struct{}
+
+func (*openPortScanner) Scan(_ context.Context, r *scan.Request) (scan.Result, error) {
+	return &tcp.ScanResult{ScanType: tcp.SYNScanType, IP: r.DstIP.String(), Port: r.DstPort}, nil
+}
+
+func TestStartScanEngineWriteError(t *testing.T) {
+	t.Parallel()
+
+	done := make(chan interface{})
+	go func() {
+		defer close(done)
+		ctx := context.Background()
+
+		logger, err := log.NewLogger(newResultWriter(&brokenWriter{}, "test", false), "test",
+			log.FlushInterval(10*time.Millisecond))
+		require.NoError(t, err)
+		engine := scan.NewScanEngine(
+			scan.NewIPPortGenerator(scan.NewIPGenerator(), scan.NewPortGenerator()),
+			&openPortScanner{}, scan.NewResultChan(ctx, 100))
+
+		// the scan of the whole range would take a long time, it must be stopped by the write error
+		err = startScanEngine(ctx, engine, newEngineConfig(
+			withLogger(logger),
+			withScanRange(&scan.Range{
+				DstSubnet: &net.IPNet{IP: net.IPv4(10, 0, 0, 0), Mask: net.CIDRMask(8, 32)},
+				Ports:     []*scan.PortRange{{StartPort: 1, EndPort: 65535}},
+			}),
+			withExitDelay(0),
+		))
+		require.Error(t, err)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "test timeout")
+	}
+}
+
+func TestRunScanEngineFilters(t *testing.T) {
+	t.Parallel()
+	ctx := context.Background()
+
+	var buf bytes.Buffer
+	logger, err := log.NewLogger(newResultWriter(&buf, "test", false), "test")
+	require.NoError(t, err)
+	opts := &genericScanCmdOpts{
+		exitDelay: defaultExitDelay,
+		requests: scan.NewCountRequestGenerator(
+			scan.NewIPPortGenerator(scan.NewIPGenerator(), scan.NewPortGenerator())),
+	}
+	engine := scan.NewScanEngine(opts.requests, &openPortScanner{}, scan.NewResultChan(ctx, 100))
+
+	err = opts.runScanEngine(ctx, &cobra.Command{}, engine, &scan.Range{
+		DstSubnet: &net.IPNet{IP: net.IPv4(192, 168, 0, 1), Mask: net.CIDRMask(32, 32)},
+		Ports:     []*scan.PortRange{{StartPort: 22, EndPort: 23}},
+	}, logger, func(result scan.Result) bool {
+		return result.(*tcp.ScanResult).Port == 22
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(2), opts.requests.Count())
+	require.Equal(t, "192.168.0.1          22    \n", buf.String())
+}
@@ -0,0 +1,78 @@
+package command
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/v-byte-cpu/sx/command/log"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+	"github.com/v-byte-cpu/sx/pkg/scan/s7"
+)
+
+func newS7Cmd() *s7Cmd {
+	c := &s7Cmd{}
+
+	cmd := &cobra.Command{
+		Use: "s7 [flags] [subnet]",
+		Example: strings.Join([]string{
+			"s7 -p 102 192.168.0.1/24", "s7 --json -p 102 10.0.0.1/16",
+			"s7 -f ip_ports_file.jsonl", "s7 -p 102 -f ips_file.jsonl"}, "\n"),
+		Short: "Perform Siemens S7 PLC identification scan",
+		Long: strings.Join([]string{
+			"Perform Siemens S7 PLC identification scan.",
+			"COTP connection and S7 communication are set up with the CPU, then module and component identification",
+			"system status lists (SZL) are read to report the module type, order number, serial number and firmware version.",
+			"Only read requests are sent to PLCs."}, "\n"),
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer cancel()
+
+			if err = c.opts.parseRawOptions(); err != nil {
+				return
+			}
+			scanRange, err := c.opts.parseScanRange(args)
+			if err != nil {
+				return
+			}
+
+			var logger log.Logger
+			if logger, err = c.opts.getLogger(s7.ScanType, resultWriter); err != nil {
+				return
+			}
+
+			engine := c.opts.newS7ScanEngine(ctx)
+			return c.opts.runScanEngine(ctx, cmd, engine, scanRange, logger)
+		},
+	}
+
+	c.opts.initCliFlags(cmd)
+
+	c.cmd = cmd
+	return c
+}
+
+type s7Cmd struct {
+	cmd  *cobra.Command
+	opts s7CmdOpts
+}
+
+type s7CmdOpts struct {
+	genericScanCmdOpts
+	timeout time.Duration
+}
+
+func (o *s7CmdOpts) initCliFlags(cmd *cobra.Command) {
+	o.genericScanCmdOpts.initCliFlags(cmd)
+	cmd.Flags().DurationVarP(&o.timeout, "timeout", "t", 2*time.Second, "set connect and data timeout")
+}
+
+func (o *s7CmdOpts) newS7ScanEngine(ctx context.Context) scan.EngineResulter {
+	return o.newScanEngine(ctx, s7.NewScanner(
+		s7.WithDialTimeout(o.timeout),
+		s7.WithDataTimeout(o.timeout),
+	))
+}
@@ -0,0 +1,139 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+// defaultAuditLog is the audit log file of the safe profile
+const defaultAuditLog = "sx-audit.jsonl"
+
+// bogonSubnets are IANA special-purpose subnets that are never routed on the internet,
+// private and shared address space is allowed, so that internal networks can be scanned
+const bogonSubnets = `
+0.0.0.0/8          # "this" network
+127.0.0.0/8        # loopback
+169.254.0.0/16     # link local
+192.0.0.0/24       # IETF protocol assignments
+192.0.2.0/24       # TEST-NET-1
+198.18.0.0/15      # benchmarking
+198.51.100.0/24    # TEST-NET-2
+203.0.113.0/24     # TEST-NET-3
+224.0.0.0/4        # multicast
+240.0.0.0/4        # reserved and limited broadcast
+::/128             # unspecified address
+::1/128            # loopback
+100::/64           # discard-only
+2001:db8::/32      # documentation
+ff00::/8           # multicast
+`
+
+var (
+	errSafeScope   = errors.New("safe profile requires the --scope file with authorized targets")
+	errSafeCommand = errors.New("safe profile is not supported by the command")
+)
+
+// safeMode enables the profile of conservative defaults for scanning production-adjacent networks
+var safeMode bool
+
+// safeProfile are values of flags set by the safe profile, flags set explicitly keep their values
+var safeProfile = []struct {
+	flag  string
+	value string
+	// optional flags are set only if the command has them, e.g. only a few UDP scans support retries
+	optional bool
+}{
+	{flag: "rate", value: "100/s"},
+	{flag: "retries", value: "2", optional: true},
+	{flag: "bogon-guard", value: "true"},
+	{flag: "audit-log", value: defaultAuditLog},
+}
+
+// applySafeProfile sets flags of the command that are not set explicitly to values of the safe profile,
+// commands without any of the required flags are rejected, so that the profile is never applied partially
+func applySafeProfile(flags *pflag.FlagSet) error {
+	if !safeMode {
+		return nil
+	}
+	for _, preset := range safeProfile {
+		f := flags.Lookup(preset.flag)
+		if f == nil && preset.optional {
+			continue
+		}
+		if f == nil {
+			return fmt.Errorf("%w: the command has no --%s flag", errSafeCommand, preset.flag)
+		}
+		if f.Changed {
+			continue
+		}
+		// the flag is not marked as changed, so it is still distinguished from explicit flags
+		if err := f.Value.Set(preset.value); err != nil {
+			return fmt.Errorf("safe profile: --%s: %w", preset.flag, err)
+		}
+	}
+	return nil
+}
+
+// scopeCmdOpts are options that limit targets of the scan to authorized and routable addresses
+type scopeCmdOpts struct {
+	scopeIPs   scan.IPContainer
+	bogonIPs   scan.IPContainer
+	bogonGuard bool
+
+	rawScopeFile string
+}
+
+func (o *scopeCmdOpts) initCliFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&o.rawScopeFile, "scope", "",
+		strings.Join([]string{"set file with IPs or subnets in CIDR notation the scan is authorized for, one-per line",
+			"targets outside of them are skipped, the file is required by the --safe profile"}, "\n"))
+	cmd.Flags().BoolVar(&o.bogonGuard, "bogon-guard", false,
+		"skip loopback, link local, multicast, documentation and other IANA special-purpose addresses that are never routed")
+}
+
+func (o *scopeCmdOpts) parseRawOptions() (err error) {
+	if len(o.rawScopeFile) > 0 {
+		if o.scopeIPs, err = parseExcludeFile(func() (io.ReadCloser, error) {
+			return os.Open(o.rawScopeFile)
+		}); err != nil {
+			return
+		}
+	} else if safeMode {
+		return errSafeScope
+	}
+	if o.bogonGuard {
+		o.bogonIPs, err = parseExcludeFile(func() (io.ReadCloser, error) {
+			return io.NopCloser(strings.NewReader(bogonSubnets)), nil
+		})
+	}
+	return
+}
+
+// withScope skips requests to targets outside of the scope file and to bogon addresses if the guard is enabled
+func (o *scopeCmdOpts) withScope(reqgen scan.RequestGenerator) scan.RequestGenerator {
+	if o.scopeIPs != nil {
+		reqgen = scan.NewFilterIPRequestGenerator(reqgen, &outsideIPs{o.scopeIPs})
+	}
+	if o.bogonIPs != nil {
+		reqgen = scan.NewFilterIPRequestGenerator(reqgen, o.bogonIPs)
+	}
+	return reqgen
+}
+
+// outsideIPs contains IPs that are not contained in the delegate container
+type outsideIPs struct {
+	ips scan.IPContainer
+}
+
+func (c *outsideIPs) Contains(ip net.IP) (bool, error) {
+	contains, err := c.ips.Contains(ip)
+	return !contains, err
+}
@@ -0,0 +1,146 @@
+package command
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+// setSafeMode replaces the process-wide safe mode for the test
+func setSafeMode(t *testing.T, enabled bool) {
+	t.Helper()
+	prev := safeMode
+	safeMode = enabled
+	t.Cleanup(func() {
+		safeMode = prev
+	})
+}
+
+func newSafeProfileCmd() *cobra.Command {
+	cmd := &cobra.Command{}
+	cmd.Flags().String("rate", "", "")
+	cmd.Flags().Bool("bogon-guard", false, "")
+	cmd.Flags().String("audit-log", "", "")
+	return cmd
+}
+
+func TestApplySafeProfile(t *testing.T) {
+	setSafeMode(t, true)
+	cmd := newSafeProfileCmd()
+	require.NoError(t, cmd.ParseFlags(strings.Split("--rate 5/s --audit-log audit.jsonl", " ")))
+
+	require.NoError(t, applySafeProfile(cmd.Flags()))
+	require.Equal(t, "5/s", cmd.Flags().Lookup("rate").Value.String())
+	require.Equal(t, "true", cmd.Flags().Lookup("bogon-guard").Value.String())
+	require.False(t, cmd.Flags().Changed("bogon-guard"))
+	require.Equal(t, "audit.jsonl", cmd.Flags().Lookup("audit-log").Value.String())
+}
+
+// newSafeScanCmd adds the persistent audit log flag of the root command to the scan command
+func newSafeScanCmd(cmd *cobra.Command) *cobra.Command {
+	cmd.Flags().String("audit-log", "", "")
+	return cmd
+}
+
+func TestApplySafeProfileRetries(t *testing.T) {
+	setSafeMode(t, true)
+	cmd := newSafeScanCmd(newSNMPCmd().cmd)
+	require.NoError(t, applySafeProfile(cmd.Flags()))
+	require.Equal(t, "2", cmd.Flags().Lookup("retries").Value.String())
+	require.Equal(t, "100/s", cmd.Flags().Lookup("rate").Value.String())
+
+	cmd = newSafeScanCmd(newSNMPCmd().cmd)
+	require.NoError(t, cmd.ParseFlags(strings.Split("--retries 0", " ")))
+	require.NoError(t, applySafeProfile(cmd.Flags()))
+	require.Equal(t, "0", cmd.Flags().Lookup("retries").Value.String())
+
+	// commands without retries get the rest of the profile
+	cmd = newSafeScanCmd(newHTTPCmd().cmd)
+	require.Nil(t, cmd.Flags().Lookup("retries"))
+	require.NoError(t, applySafeProfile(cmd.Flags()))
+	require.Equal(t, "100/s", cmd.Flags().Lookup("rate").Value.String())
+}
+
+func TestApplySafeProfileMissingFlags(t *testing.T) {
+	setSafeMode(t, true)
+	cmd := &cobra.Command{}
+	cmd.Flags().String("rate", "", "")
+
+	require.ErrorIs(t, applySafeProfile(cmd.Flags()), errSafeCommand)
+}
+
+func TestApplySafeProfileDisabled(t *testing.T) {
+	setSafeMode(t, false)
+	cmd := newSafeProfileCmd()
+
+	require.NoError(t, applySafeProfile(cmd.Flags()))
+	require.Equal(t, "", cmd.Flags().Lookup("rate").Value.String())
+	require.Equal(t, "false", cmd.Flags().Lookup("bogon-guard").Value.String())
+}
+
+func TestScopeCmdOptsSafeModeRequiresScope(t *testing.T) {
+	setSafeMode(t, true)
+	var opts scopeCmdOpts
+	require.ErrorIs(t, opts.parseRawOptions(), errSafeScope)
+
+	scopeFile := filepath.Join(t.TempDir(), "scope.txt")
+	require.NoError(t, os.WriteFile(scopeFile, []byte("10.0.0.0/8\n"), 0o600))
+	opts.rawScopeFile = scopeFile
+	require.NoError(t, opts.parseRawOptions())
+}
+
+func TestScopeCmdOptsParseRawOptionsInvalidFile(t *testing.T) {
+	t.Parallel()
+	opts := scopeCmdOpts{rawScopeFile: filepath.Join(t.TempDir(), "missing.txt")}
+	require.Error(t, opts.parseRawOptions())
+}
+
+func scopeTargets(t *testing.T, opts *scopeCmdOpts, subnet string) []string {
+	t.Helper()
+	_, ipnet, err := net.ParseCIDR(subnet)
+	require.NoError(t, err)
+	reqgen := opts.withScope(scan.NewIPRequestGenerator(scan.NewIPGenerator()))
+	requests, err := reqgen.GenerateRequests(context.Background(), &scan.Range{DstSubnet: ipnet})
+	require.NoError(t, err)
+	var targets []string
+	for request := range requests {
+		require.NoError(t, request.Err)
+		targets = append(targets, request.DstIP.String())
+	}
+	return targets
+}
+
+func TestScopeCmdOptsWithScope(t *testing.T) {
+	t.Parallel()
+	scopeFile := filepath.Join(t.TempDir(), "scope.txt")
+	require.NoError(t, os.WriteFile(scopeFile, []byte("# authorized\n10.0.0.2/31\n10.0.0.5\n"), 0o600))
+	opts := scopeCmdOpts{rawScopeFile: scopeFile}
+	require.NoError(t, opts.parseRawOptions())
+
+	require.ElementsMatch(t, []string{"10.0.0.2", "10.0.0.3", "10.0.0.5"}, scopeTargets(t, &opts, "10.0.0.0/29"))
+}
+
+func TestScopeCmdOptsWithBogonGuard(t *testing.T) {
+	t.Parallel()
+	opts := scopeCmdOpts{bogonGuard: true}
+	require.NoError(t, opts.parseRawOptions())
+
+	require.Empty(t, scopeTargets(t, &opts, "127.0.0.0/30"))
+	require.Empty(t, scopeTargets(t, &opts, "224.0.0.0/30"))
+	require.ElementsMatch(t, []string{"10.0.0.0", "10.0.0.1"}, scopeTargets(t, &opts, "10.0.0.0/31"))
+}
+
+func TestScopeCmdOptsWithScopeDisabled(t *testing.T) {
+	t.Parallel()
+	var opts scopeCmdOpts
+	require.NoError(t, opts.parseRawOptions())
+
+	require.ElementsMatch(t, []string{"127.0.0.0", "127.0.0.1"}, scopeTargets(t, &opts, "127.0.0.0/31"))
+}
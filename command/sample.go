@@ -0,0 +1,103 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/v-byte-cpu/sx/pkg/packet"
+	"github.com/v-byte-cpu/sx/pkg/packet/afpacket"
+)
+
+var (
+	errSampleEvery = errors.New("invalid sample-packets: non-negative number required")
+	errSampleFile  = errors.New("--sample-file flag requires --sample-packets")
+)
+
+// sampleCmdOpts are debug options to sample received packets with decode diagnostics,
+// they help to find out why expected responses don't become scan results
+type sampleCmdOpts struct {
+	sampleEvery int
+	sampleFile  string
+}
+
+func (o *sampleCmdOpts) initCliFlags(cmd *cobra.Command) {
+	cmd.Flags().IntVar(&o.sampleEvery, "sample-packets", 0,
+		strings.Join([]string{
+			"debug: sample 1-in-N received packets, matched and unmatched by the BPF filter of the scan,",
+			"and write them with decode diagnostics in NDJSON format, e.g. 1 samples all packets"}, "\n"))
+	cmd.Flags().StringVar(&o.sampleFile, "sample-file", "",
+		"write sampled packets to the file instead of stderr, samples of consecutive port chunks are appended")
+}
+
+func (o *sampleCmdOpts) parseRawOptions() error {
+	if o.sampleEvery < 0 {
+		return errSampleEvery
+	}
+	if len(o.sampleFile) > 0 && o.sampleEvery == 0 {
+		return errSampleFile
+	}
+	return nil
+}
+
+// sampleWriter is the output of sampled packets if the sample file is not set
+var sampleWriter io.Writer = os.Stderr
+
+// startPacketSampler reads all packets of the interface with a separate packet source and samples them,
+// the returned function stops sampling
+func startPacketSampler(ctx context.Context, conf *packetScanConfig) (stop func(), err error) {
+	if conf.sampleEvery == 0 {
+		return func() {}, nil
+	}
+	r := &conf.scanRange
+	filter, maxPacketLength := conf.bpfFilter(r)
+	bpfIns, err := afpacket.CompileBPFFilter(conf.vpnMode, filter, maxPacketLength)
+	if err != nil {
+		return nil, fmt.Errorf("sample BPFFilter: %w", err)
+	}
+	matcher, err := packet.NewBPFMatcher(bpfIns)
+	if err != nil {
+		return nil, fmt.Errorf("sample BPFFilter: %w", err)
+	}
+
+	w := sampleWriter
+	var f *os.File
+	if len(conf.sampleFile) > 0 {
+		if f, err = os.OpenFile(conf.sampleFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644); err != nil {
+			return nil, err
+		}
+		w = f
+	}
+	// the sample source doesn't have a BPF filter to see packets dropped by the scan filter
+	ps, err := newPacketSource(r.Interface.Name, conf.vpnMode)
+	if err != nil {
+		if f != nil {
+			f.Close()
+		}
+		return nil, err
+	}
+
+	sampler := packet.NewSampler(w, conf.sampleEvery,
+		packet.WithSamplerMatcher(matcher), packet.WithSamplerVPNmode(conf.vpnMode))
+	ctx, cancel := context.WithCancel(ctx)
+	errc := packet.NewReceiver(ps, sampler).ReceivePackets(ctx)
+	go func() {
+		for err := range errc {
+			if ctx.Err() == nil && conf.logger != nil {
+				conf.logger.Error(fmt.Errorf("sample: %w", err))
+			}
+		}
+	}()
+	// like the packet source of the scan, the sample source is closed without waiting for the pending read
+	return func() {
+		cancel()
+		ps.Close()
+		if f != nil {
+			f.Close()
+		}
+	}, nil
+}
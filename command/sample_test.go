@@ -0,0 +1,55 @@
+package command
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSampleCmdOptsInitCliFlags(t *testing.T) {
+	t.Parallel()
+	cmd := &cobra.Command{}
+	var opts sampleCmdOpts
+	opts.initCliFlags(cmd)
+
+	require.NoError(t, cmd.ParseFlags(strings.Split("--sample-packets 100 --sample-file samples.json", " ")))
+	require.NoError(t, opts.parseRawOptions())
+	require.Equal(t, 100, opts.sampleEvery)
+	require.Equal(t, "samples.json", opts.sampleFile)
+}
+
+func TestSampleCmdOptsParseRawOptions(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		opts     sampleCmdOpts
+		expected error
+	}{
+		{
+			name: "NoSampling",
+		},
+		{
+			name: "AllPackets",
+			opts: sampleCmdOpts{sampleEvery: 1},
+		},
+		{
+			name:     "NegativeSampleEvery",
+			opts:     sampleCmdOpts{sampleEvery: -1},
+			expected: errSampleEvery,
+		},
+		{
+			name:     "FileWithoutSampling",
+			opts:     sampleCmdOpts{sampleFile: "samples.json"},
+			expected: errSampleFile,
+		},
+	}
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			require.Equal(t, tt.expected, tt.opts.parseRawOptions())
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/v-byte-cpu/sx/pkg/ip"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+	"github.com/v-byte-cpu/sx/pkg/simulate"
+)
+
+var (
+	errSimulateSubnet = errors.New("invalid subnet: IPv4 loopback subnet required, e.g. 127.1.0.0/20")
+	errSimulateRatio  = errors.New("invalid ratio: number between 0 and 1 required")
+)
+
+func newSimulateCmd() *simulateCmd {
+	c := &simulateCmd{}
+
+	cmd := &cobra.Command{
+		Use: "simulate [flags] subnet",
+		Example: strings.Join([]string{
+			"simulate --tcp-ports 22,80,443 127.1.0.0/20",
+			"simulate --socks-ports 1080 --up-ratio 0.1 --fail-ratio 0.05 127.1.0.0/16",
+			"simulate --udp-ports 53 --delay 50ms 127.2.0.0/24"}, "\n"),
+		Short: "Run a population of fake services on loopback addresses to benchmark scans",
+		Long: strings.Join([]string{
+			"Run fake services on ports of all up hosts of the loopback subnet until interrupted.",
+			"TCP ports accept connections, SOCKS ports answer SOCKS4 and SOCKS5 greetings and UDP ports echo datagrams.",
+			"It is useful to benchmark scan configurations end-to-end and to validate rate limits and retries",
+			"under controlled conditions. Counters of connections and datagrams are written to stderr on exit."}, "\n"),
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer cancel()
+
+			if len(args) != 1 {
+				return errors.New("requires one ip subnet argument")
+			}
+			if err = c.opts.parseRawOptions(); err != nil {
+				return
+			}
+			p, err := c.opts.newPopulation(args[0])
+			if err != nil {
+				return
+			}
+			if err = p.Start(); err != nil {
+				return
+			}
+			stats := p.Stats()
+			fmt.Fprintf(os.Stderr, "simulate: %d hosts up, %d listeners\n", stats.Hosts, stats.Listeners)
+
+			start := time.Now()
+			<-ctx.Done()
+			p.Close()
+			writeSimulateStats(p.Stats(), time.Since(start))
+			return nil
+		},
+	}
+
+	c.opts.initCliFlags(cmd)
+
+	c.cmd = cmd
+	return c
+}
+
+type simulateCmd struct {
+	cmd  *cobra.Command
+	opts simulateCmdOpts
+}
+
+type simulateCmdOpts struct {
+	tcpPorts   []uint16
+	socksPorts []uint16
+	udpPorts   []uint16
+	upRatio    float64
+	failRatio  float64
+	delay      time.Duration
+
+	rawTCPPorts   string
+	rawSOCKSPorts string
+	rawUDPPorts   string
+}
+
+func (o *simulateCmdOpts) initCliFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&o.rawTCPPorts, "tcp-ports", "", "set TCP ports that accept connections, e.g. 22,80-90")
+	cmd.Flags().StringVar(&o.rawSOCKSPorts, "socks-ports", "",
+		"set TCP ports that answer SOCKS5 greetings without authentication and grant SOCKS4 requests")
+	cmd.Flags().StringVar(&o.rawUDPPorts, "udp-ports", "", "set UDP ports that echo datagrams back")
+	cmd.Flags().Float64Var(&o.upRatio, "up-ratio", 1,
+		"set fraction of hosts of the subnet with services, the same hosts are up in every run")
+	cmd.Flags().Float64Var(&o.failRatio, "fail-ratio", 0,
+		"set fraction of TCP connections that are reset and UDP datagrams that are dropped")
+	cmd.Flags().DurationVar(&o.delay, "delay", 0, "set delay of SOCKS replies and UDP echoes")
+}
+
+func (o *simulateCmdOpts) parseRawOptions() (err error) {
+	if o.tcpPorts, err = parseSimulatePorts(o.rawTCPPorts); err != nil {
+		return
+	}
+	if o.socksPorts, err = parseSimulatePorts(o.rawSOCKSPorts); err != nil {
+		return
+	}
+	if o.udpPorts, err = parseSimulatePorts(o.rawUDPPorts); err != nil {
+		return
+	}
+	if len(o.tcpPorts)+len(o.socksPorts)+len(o.udpPorts) == 0 {
+		return simulate.ErrNoServices
+	}
+	if o.upRatio < 0 || o.upRatio > 1 || o.failRatio < 0 || o.failRatio > 1 {
+		return errSimulateRatio
+	}
+	return
+}
+
+func parseSimulatePorts(rawPorts string) (ports []uint16, err error) {
+	if len(rawPorts) == 0 {
+		return
+	}
+	var ranges []*scan.PortRange
+	if ranges, err = parsePortRanges(rawPorts); err != nil {
+		return
+	}
+	for _, r := range ranges {
+		for port := int(r.StartPort); port <= int(r.EndPort); port++ {
+			ports = append(ports, uint16(port))
+		}
+	}
+	return
+}
+
+func (o *simulateCmdOpts) newPopulation(rawSubnet string) (*simulate.Population, error) {
+	subnet, err := ip.ParseIPNet(rawSubnet)
+	if err != nil {
+		return nil, err
+	}
+	if subnet.IP.To4() == nil || !subnet.IP.IsLoopback() {
+		return nil, errSimulateSubnet
+	}
+	return simulate.New(subnet,
+		simulate.WithTCPPorts(o.tcpPorts...),
+		simulate.WithSOCKSPorts(o.socksPorts...),
+		simulate.WithUDPPorts(o.udpPorts...),
+		simulate.WithUpRatio(o.upRatio),
+		simulate.WithFailRatio(o.failRatio),
+		simulate.WithDelay(o.delay)), nil
+}
+
+func writeSimulateStats(stats simulate.Stats, elapsed time.Duration) {
+	fmt.Fprintf(os.Stderr, "simulate: %d connections, %d datagrams, %d failed in %s, %.1f connections/s\n",
+		stats.Connections, stats.Datagrams, stats.Failed, elapsed.Round(time.Millisecond),
+		float64(stats.Connections)/elapsed.Seconds())
+}
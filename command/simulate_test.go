@@ -0,0 +1,91 @@
+package command
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+	"github.com/v-byte-cpu/sx/pkg/simulate"
+)
+
+func TestSimulateCmdSubnetError(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{
+			name: "RequiredArg",
+			args: nil,
+		},
+		{
+			name: "InvalidSubnet",
+			args: []string{"invalid_ip_address"},
+		},
+		{
+			name: "NotLoopbackSubnet",
+			args: []string{"10.0.0.0/24"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := newSimulateCmd().cmd
+			require.NoError(t, cmd.ParseFlags([]string{"--tcp-ports", "22"}))
+			err := cmd.RunE(cmd, tt.args)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestSimulateCmdOptsInitCliFlags(t *testing.T) {
+	t.Parallel()
+	var opts simulateCmdOpts
+	cmd := &cobra.Command{}
+
+	opts.initCliFlags(cmd)
+	err := cmd.ParseFlags(strings.Split(
+		"--tcp-ports 22,80-82 --socks-ports 1080 --udp-ports 53 --up-ratio 0.1 --fail-ratio 0.05 --delay 50ms", " "))
+
+	require.NoError(t, err)
+	require.NoError(t, opts.parseRawOptions())
+	require.Equal(t, []uint16{22, 80, 81, 82}, opts.tcpPorts)
+	require.Equal(t, []uint16{1080}, opts.socksPorts)
+	require.Equal(t, []uint16{53}, opts.udpPorts)
+	require.Equal(t, 0.1, opts.upRatio)
+	require.Equal(t, 0.05, opts.failRatio)
+	require.Equal(t, 50*time.Millisecond, opts.delay)
+}
+
+func TestSimulateCmdOptsParseRawOptions(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		opts     simulateCmdOpts
+		expected error
+	}{
+		{
+			name:     "NoPorts",
+			opts:     simulateCmdOpts{upRatio: 1},
+			expected: simulate.ErrNoServices,
+		},
+		{
+			name:     "UpRatio",
+			opts:     simulateCmdOpts{rawTCPPorts: "22", upRatio: 1.5},
+			expected: errSimulateRatio,
+		},
+		{
+			name:     "FailRatio",
+			opts:     simulateCmdOpts{rawUDPPorts: "53", upRatio: 1, failRatio: -0.1},
+			expected: errSimulateRatio,
+		},
+	}
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			require.ErrorIs(t, tt.opts.parseRawOptions(), tt.expected)
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package command
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/v-byte-cpu/sx/command/log"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+	"github.com/v-byte-cpu/sx/pkg/scan/smb"
+)
+
+func newSMBCmd() *smbCmd {
+	c := &smbCmd{}
+
+	cmd := &cobra.Command{
+		Use: "smb [flags] [subnet]",
+		Example: strings.Join([]string{
+			"smb -p 445 192.168.0.1/24", "smb -p 139,445 10.0.0.1",
+			"smb --json -p 445 10.0.0.1/16",
+			"smb -f ip_ports_file.jsonl", "smb -p 445 -f ips_file.jsonl"}, "\n"),
+		Short: "Perform SMB dialect and signing scan",
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer cancel()
+
+			if err = c.opts.parseRawOptions(); err != nil {
+				return
+			}
+			scanRange, err := c.opts.parseScanRange(args)
+			if err != nil {
+				return
+			}
+
+			var logger log.Logger
+			if logger, err = c.opts.getLogger(smb.ScanType, resultWriter); err != nil {
+				return
+			}
+
+			engine := c.opts.newSMBScanEngine(ctx)
+			return c.opts.runScanEngine(ctx, cmd, engine, scanRange, logger)
+		},
+	}
+
+	c.opts.initCliFlags(cmd)
+
+	c.cmd = cmd
+	return c
+}
+
+type smbCmd struct {
+	cmd  *cobra.Command
+	opts smbCmdOpts
+}
+
+type smbCmdOpts struct {
+	genericScanCmdOpts
+	timeout time.Duration
+}
+
+func (o *smbCmdOpts) initCliFlags(cmd *cobra.Command) {
+	o.genericScanCmdOpts.initCliFlags(cmd)
+	cmd.Flags().DurationVarP(&o.timeout, "timeout", "t", 2*time.Second, "set connect and data timeout")
+}
+
+func (o *smbCmdOpts) newSMBScanEngine(ctx context.Context) scan.EngineResulter {
+	return o.newScanEngine(ctx, smb.NewScanner(
+		smb.WithDialTimeout(o.timeout),
+		smb.WithDataTimeout(o.timeout),
+	))
+}
@@ -0,0 +1,81 @@
+package command
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/v-byte-cpu/sx/command/log"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+	"github.com/v-byte-cpu/sx/pkg/scan/smtp"
+)
+
+func newSMTPCmd() *smtpCmd {
+	c := &smtpCmd{}
+
+	cmd := &cobra.Command{
+		Use: "smtp [flags] [subnet]",
+		Example: strings.Join([]string{
+			"smtp -p 25 192.168.0.1/24", "smtp -p 25,587 10.0.0.1",
+			"smtp --json --relay-check -p 25 10.0.0.1/16",
+			"smtp -f ip_ports_file.jsonl", "smtp -p 25 -f ips_file.jsonl"}, "\n"),
+		Short: "Perform SMTP banner, extension and open relay scan",
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer cancel()
+
+			if err = c.opts.parseRawOptions(); err != nil {
+				return
+			}
+			scanRange, err := c.opts.parseScanRange(args)
+			if err != nil {
+				return
+			}
+
+			var logger log.Logger
+			if logger, err = c.opts.getLogger(smtp.ScanType, resultWriter); err != nil {
+				return
+			}
+
+			engine := c.opts.newSMTPScanEngine(ctx)
+			return c.opts.runScanEngine(ctx, cmd, engine, scanRange, logger)
+		},
+	}
+
+	c.opts.initCliFlags(cmd)
+
+	c.cmd = cmd
+	return c
+}
+
+type smtpCmd struct {
+	cmd  *cobra.Command
+	opts smtpCmdOpts
+}
+
+type smtpCmdOpts struct {
+	genericScanCmdOpts
+	timeout    time.Duration
+	relayCheck bool
+}
+
+func (o *smtpCmdOpts) initCliFlags(cmd *cobra.Command) {
+	o.genericScanCmdOpts.initCliFlags(cmd)
+	cmd.Flags().DurationVarP(&o.timeout, "timeout", "t", 5*time.Second, "set connect and data timeout")
+	cmd.Flags().BoolVar(&o.relayCheck, "relay-check", false,
+		"check whether the server relays mail to external domains, the transaction is reset before any mail is sent")
+}
+
+func (o *smtpCmdOpts) newSMTPScanEngine(ctx context.Context) scan.EngineResulter {
+	opts := []smtp.ScannerOption{
+		smtp.WithDialTimeout(o.timeout),
+		smtp.WithDataTimeout(o.timeout),
+	}
+	if o.relayCheck {
+		opts = append(opts, smtp.WithRelayCheck())
+	}
+	return o.newScanEngine(ctx, smtp.NewScanner(opts...))
+}
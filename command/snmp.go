@@ -0,0 +1,112 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/v-byte-cpu/sx/command/log"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+	"github.com/v-byte-cpu/sx/pkg/scan/snmp"
+)
+
+func newSNMPCmd() *snmpCmd {
+	c := &snmpCmd{}
+
+	cmd := &cobra.Command{
+		Use: "snmp [flags] [subnet]",
+		Example: strings.Join([]string{
+			"snmp -p 161 192.168.0.1/24", "snmp --v1 -p 161 10.0.0.1",
+			"snmp --communities public,private,cisco -p 161 10.0.0.1/16",
+			"snmp -f ip_ports_file.jsonl", "snmp -p 161 -f ips_file.jsonl"}, "\n"),
+		Short: "Perform SNMP community string scan",
+		Long: strings.Join([]string{
+			"Perform SNMP community string scan.",
+			"GET requests for sysDescr and sysName are sent with each community string over UDP,",
+			"devices that answered are reported with the accepted community strings."}, "\n"),
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer cancel()
+
+			if err = c.opts.parseRawOptions(); err != nil {
+				return
+			}
+			scanRange, err := c.opts.parseScanRange(args)
+			if err != nil {
+				return
+			}
+
+			var logger log.Logger
+			if logger, err = c.opts.getLogger(snmp.ScanType, resultWriter); err != nil {
+				return
+			}
+
+			var engine scan.EngineResulter
+			if engine, err = c.opts.newSNMPScanEngine(ctx); err != nil {
+				return
+			}
+			return c.opts.runScanEngine(ctx, cmd, engine, scanRange, logger)
+		},
+	}
+
+	c.opts.initCliFlags(cmd)
+
+	c.cmd = cmd
+	return c
+}
+
+type snmpCmd struct {
+	cmd  *cobra.Command
+	opts snmpCmdOpts
+}
+
+type snmpCmdOpts struct {
+	genericScanCmdOpts
+	timeout     time.Duration
+	retries     int
+	v1          bool
+	communities []string
+}
+
+func (o *snmpCmdOpts) initCliFlags(cmd *cobra.Command) {
+	o.genericScanCmdOpts.initCliFlags(cmd)
+	cmd.Flags().DurationVarP(&o.timeout, "timeout", "t", 1*time.Second, "set time to wait for a response to each request")
+	cmd.Flags().IntVar(&o.retries, "retries", 1, "set number of additional requests with the same community if there is no response")
+	cmd.Flags().BoolVar(&o.v1, "v1", false, "send SNMPv1 requests instead of SNMPv2c")
+	cmd.Flags().StringSliceVar(&o.communities, "communities", snmp.DefaultCommunities,
+		"set comma-separated list of community strings to try")
+}
+
+func (o *snmpCmdOpts) parseRawOptions() (err error) {
+	if err = o.genericScanCmdOpts.parseRawOptions(); err != nil {
+		return
+	}
+	if o.retries < 0 {
+		return errors.New("invalid retries: non-negative number required")
+	}
+	if len(o.communities) == 0 {
+		return errors.New("invalid communities: at least one community string required")
+	}
+	return
+}
+
+func (o *snmpCmdOpts) newSNMPScanEngine(ctx context.Context) (scan.EngineResulter, error) {
+	version := snmp.Version2c
+	if o.v1 {
+		version = snmp.Version1
+	}
+	scanner, err := snmp.NewScanner(
+		snmp.WithVersion(version),
+		snmp.WithCommunities(o.communities),
+		snmp.WithDataTimeout(o.timeout),
+		snmp.WithRetries(o.retries),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return o.newScanEngine(ctx, scanner), nil
+}
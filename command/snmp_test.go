@@ -0,0 +1,98 @@
+package command
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+func TestSNMPCmdDstSubnetError(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{
+			name: "RequiredArg",
+			args: nil,
+		},
+		{
+			name: "InvalidDstSubnet",
+			args: []string{"invalid_ip_address"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := newSNMPCmd().cmd
+			err := cmd.RunE(cmd, tt.args)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestSNMPCmdOptsInitCliFlags(t *testing.T) {
+	t.Parallel()
+	var opts snmpCmdOpts
+	cmd := &cobra.Command{}
+
+	opts.initCliFlags(cmd)
+	err := cmd.ParseFlags(strings.Split(
+		"--json -p 161 -f ip_file.jsonl -w 300 --exit-delay 10s --timeout 3s --retries 2 --v1 --communities public,cisco", " "))
+
+	require.NoError(t, err)
+	require.Equal(t, true, opts.json)
+	require.Equal(t, "161", opts.rawPortRanges)
+	require.Equal(t, "ip_file.jsonl", opts.ipFile)
+	require.Equal(t, 300, opts.workers)
+	require.Equal(t, 10*time.Second, opts.exitDelay)
+
+	require.Equal(t, 3*time.Second, opts.timeout)
+	require.Equal(t, 2, opts.retries)
+	require.Equal(t, true, opts.v1)
+	require.Equal(t, []string{"public", "cisco"}, opts.communities)
+}
+
+func TestSNMPCmdOptsParseRawOptions(t *testing.T) {
+	t.Parallel()
+	opts := snmpCmdOpts{
+		genericScanCmdOpts: genericScanCmdOpts{
+			rawPortRanges: "161",
+			workers:       300,
+		},
+		communities: []string{"public"},
+	}
+
+	err := opts.parseRawOptions()
+
+	require.NoError(t, err)
+	require.Equal(t, []*scan.PortRange{{StartPort: 161, EndPort: 161}}, opts.portRanges)
+}
+
+func TestSNMPCmdOptsParseRawOptionsError(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		opts snmpCmdOpts
+	}{
+		{
+			name: "InvalidRetries",
+			opts: snmpCmdOpts{retries: -1, communities: []string{"public"}},
+		},
+		{
+			name: "NoCommunities",
+			opts: snmpCmdOpts{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := tt.opts
+			opts.genericScanCmdOpts = genericScanCmdOpts{rawPortRanges: "161", workers: 300}
+			err := opts.parseRawOptions()
+			require.Error(t, err)
+		})
+	}
+}
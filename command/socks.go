@@ -1,7 +1,10 @@
 package command
 
 import (
+	"bufio"
 	"context"
+	"errors"
+	"io"
 	"os"
 	"os/signal"
 	"strings"
@@ -10,9 +13,23 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/v-byte-cpu/sx/command/log"
 	"github.com/v-byte-cpu/sx/pkg/scan"
+	"github.com/v-byte-cpu/sx/pkg/scan/socks4"
 	"github.com/v-byte-cpu/sx/pkg/scan/socks5"
 )
 
+const (
+	cliSOCKS4Proto   = "4"
+	cliSOCKS4aProto  = "4a"
+	cliSOCKS5Proto   = "5"
+	cliSOCKSAllProto = "all"
+)
+
+var (
+	errSOCKSProto        = errors.New("invalid SOCKS protocol: 4, 4a, 5 or all required")
+	errSOCKSCredentials  = errors.New("invalid credentials: username:password format required")
+	errSOCKS4Credentials = errors.New("invalid credentials: SOCKS5 protocol required")
+)
+
 func newSocksCmd() *socksCmd {
 	c := &socksCmd{}
 
@@ -20,8 +37,10 @@ func newSocksCmd() *socksCmd {
 		Use: "socks [flags] subnet",
 		Example: strings.Join([]string{
 			"socks -p 1080 192.168.0.1/24", "socks -p 1080-4567 10.0.0.1",
+			"socks --proto all -p 1080 10.0.0.1/24", "socks --credentials-file creds.txt -p 1080 10.0.0.1/24",
 			"socks -f ip_ports_file.jsonl", "socks -p 1080-4567 -f ips_file.jsonl"}, "\n"),
-		Short: "Perform SOCKS5 scan",
+		Short: "Perform SOCKS scan",
+		Long:  "Perform SOCKS scan. SOCKS5 is used by default unless --proto option is specified",
 		RunE: func(cmd *cobra.Command, args []string) (err error) {
 			ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
 			defer cancel()
@@ -35,17 +54,12 @@ func newSocksCmd() *socksCmd {
 			}
 
 			var logger log.Logger
-			if logger, err = c.opts.getLogger(socks5.ScanType, os.Stdout); err != nil {
+			if logger, err = c.opts.getLogger(socks5.ScanType, resultWriter); err != nil {
 				return
 			}
 
 			engine := c.opts.newSOCKSScanEngine(ctx)
-			return startScanEngine(ctx, engine,
-				newEngineConfig(
-					withLogger(logger),
-					withScanRange(scanRange),
-					withExitDelay(c.opts.exitDelay),
-				))
+			return c.opts.runScanEngine(ctx, cmd, engine, scanRange, logger)
 		},
 	}
 
@@ -61,18 +75,89 @@ type socksCmd struct {
 }
 
 type socksCmdOpts struct {
-	genericScanCmdOpts
-	timeout time.Duration
+	proxyScanCmdOpts
+	timeout         time.Duration
+	proto           string
+	credentialsFile string
+
+	credentials []socks5.Credentials
 }
 
 func (o *socksCmdOpts) initCliFlags(cmd *cobra.Command) {
-	o.genericScanCmdOpts.initCliFlags(cmd)
+	o.proxyScanCmdOpts.initCliFlags(cmd)
 	cmd.Flags().DurationVarP(&o.timeout, "timeout", "t", 2*time.Second, "set connect and data timeout")
+	cmd.Flags().StringVar(&o.proto, "proto", cliSOCKS5Proto, "set SOCKS protocol version: 4, 4a, 5 or all")
+	cmd.Flags().StringVar(&o.credentialsFile, "credentials-file", "",
+		"set file with username:password pairs to try on SOCKS5 servers that require authentication")
+}
+
+func (o *socksCmdOpts) parseRawOptions() (err error) {
+	if err = o.proxyScanCmdOpts.parseRawOptions(); err != nil {
+		return
+	}
+	switch o.proto {
+	case cliSOCKS4Proto, cliSOCKS4aProto, cliSOCKS5Proto, cliSOCKSAllProto:
+	default:
+		return errSOCKSProto
+	}
+	if len(o.credentialsFile) > 0 {
+		if o.proto == cliSOCKS4Proto || o.proto == cliSOCKS4aProto {
+			return errSOCKS4Credentials
+		}
+		if o.credentials, err = parseCredentialsFile(func() (io.ReadCloser, error) {
+			return os.Open(o.credentialsFile)
+		}); err != nil {
+			return
+		}
+	}
+	return
 }
 
 func (o *socksCmdOpts) newSOCKSScanEngine(ctx context.Context) scan.EngineResulter {
-	scanner := socks5.NewScanner(
+	return o.newScanEngine(ctx, o.newSOCKSScanner())
+}
+
+func (o *socksCmdOpts) newSOCKSScanner() scan.Scanner {
+	socks5Scanner := socks5.NewScanner(
 		socks5.WithDialTimeout(o.timeout),
-		socks5.WithDataTimeout(o.timeout))
-	return o.newScanEngine(ctx, scanner)
+		socks5.WithDataTimeout(o.timeout),
+		socks5.WithCredentials(o.credentials))
+	socks4Opts := []socks4.ScannerOption{
+		socks4.WithDialTimeout(o.timeout),
+		socks4.WithDataTimeout(o.timeout),
+	}
+	switch o.proto {
+	case cliSOCKS4Proto:
+		return socks4.NewScanner(socks4Opts...)
+	case cliSOCKS4aProto:
+		return socks4.NewScanner(append(socks4Opts, socks4.WithSOCKS4a())...)
+	case cliSOCKSAllProto:
+		// SOCKS4a request detects SOCKS4 servers as well
+		return scan.NewSequenceScanner(socks5Scanner,
+			socks4.NewScanner(append(socks4Opts, socks4.WithSOCKS4a())...))
+	default:
+		return socks5Scanner
+	}
+}
+
+func parseCredentialsFile(openFile openFileFunc) (result []socks5.Credentials, err error) {
+	input, err := openFile()
+	if err != nil {
+		return
+	}
+	defer input.Close()
+	scanner := bufio.NewScanner(input)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if len(line) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+		username, password, found := strings.Cut(line, ":")
+		if !found || len(username) == 0 || len(username) > 255 || len(password) > 255 {
+			return nil, errSOCKSCredentials
+		}
+		result = append(result, socks5.Credentials{Username: username, Password: password})
+	}
+	err = scanner.Err()
+	return
 }
@@ -1,12 +1,17 @@
 package command
 
 import (
+	"errors"
+	"io"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/require"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+	"github.com/v-byte-cpu/sx/pkg/scan/socks4"
+	"github.com/v-byte-cpu/sx/pkg/scan/socks5"
 )
 
 func TestSocksCmdDstSubnetError(t *testing.T) {
@@ -40,7 +45,7 @@ func TestSocksCmdOptsInitCliFlags(t *testing.T) {
 
 	opts.initCliFlags(cmd)
 	err := cmd.ParseFlags(strings.Split(
-		"--json -p 23-57,71-2733 -f ip_file.jsonl -w 300 --exit-delay 10s --timeout 2s", " "))
+		"--json -p 23-57,71-2733 -f ip_file.jsonl -w 300 --exit-delay 10s --timeout 2s --proto 4a", " "))
 
 	require.NoError(t, err)
 	require.Equal(t, true, opts.json)
@@ -50,4 +55,157 @@ func TestSocksCmdOptsInitCliFlags(t *testing.T) {
 	require.Equal(t, 10*time.Second, opts.exitDelay)
 
 	require.Equal(t, 2*time.Second, opts.timeout)
+	require.Equal(t, "4a", opts.proto)
+}
+
+func TestSocksCmdOptsParseProto(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		proto    string
+		expected scan.Scanner
+		err      bool
+	}{
+		{
+			name:     "Default",
+			expected: &socks5.Scanner{},
+		},
+		{
+			name:     "SOCKS5",
+			proto:    "5",
+			expected: &socks5.Scanner{},
+		},
+		{
+			name:     "SOCKS4",
+			proto:    "4",
+			expected: &socks4.Scanner{},
+		},
+		{
+			name:     "SOCKS4a",
+			proto:    "4a",
+			expected: &socks4.Scanner{},
+		},
+		{
+			name:     "All",
+			proto:    "all",
+			expected: scan.NewSequenceScanner(),
+		},
+		{
+			name:  "Invalid",
+			proto: "6",
+			err:   true,
+		},
+	}
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var opts socksCmdOpts
+			cmd := &cobra.Command{}
+			opts.initCliFlags(cmd)
+			args := []string{"-p", "1080"}
+			if len(tt.proto) > 0 {
+				args = append(args, "--proto", tt.proto)
+			}
+			require.NoError(t, cmd.ParseFlags(args))
+
+			err := opts.parseRawOptions()
+			if tt.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.IsType(t, tt.expected, opts.newSOCKSScanner())
+		})
+	}
+}
+
+func TestSocksCmdOptsCredentialsProtoError(t *testing.T) {
+	t.Parallel()
+	for _, proto := range []string{"4", "4a"} {
+		var opts socksCmdOpts
+		cmd := &cobra.Command{}
+		opts.initCliFlags(cmd)
+		require.NoError(t, cmd.ParseFlags([]string{"-p", "1080", "--proto", proto,
+			"--credentials-file", "creds.txt"}))
+		require.ErrorIs(t, opts.parseRawOptions(), errSOCKS4Credentials, proto)
+	}
+}
+
+func TestParseCredentialsFileWithInvalidFile(t *testing.T) {
+	t.Parallel()
+	_, err := parseCredentialsFile(func() (io.ReadCloser, error) {
+		return nil, errors.New("open file error")
+	})
+	require.Error(t, err)
+}
+
+func TestParseCredentialsFile(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		input    string
+		expected []socks5.Credentials
+		err      bool
+	}{
+		{
+			name:     "OnePair",
+			input:    "admin:secret",
+			expected: []socks5.Credentials{{Username: "admin", Password: "secret"}},
+		},
+		{
+			name:  "TwoPairs",
+			input: "admin:secret\nroot:toor\r\n",
+			expected: []socks5.Credentials{
+				{Username: "admin", Password: "secret"},
+				{Username: "root", Password: "toor"},
+			},
+		},
+		{
+			name:     "EmptyPassword",
+			input:    "admin:",
+			expected: []socks5.Credentials{{Username: "admin"}},
+		},
+		{
+			name:     "ColonInPassword",
+			input:    "admin:se:cret",
+			expected: []socks5.Credentials{{Username: "admin", Password: "se:cret"}},
+		},
+		{
+			name:     "WithCommentAndNewLines",
+			input:    "# comment\n\nadmin:#secret\n",
+			expected: []socks5.Credentials{{Username: "admin", Password: "#secret"}},
+		},
+		{
+			name:  "NoSeparator",
+			input: "admin",
+			err:   true,
+		},
+		{
+			name:  "EmptyUsername",
+			input: ":secret",
+			err:   true,
+		},
+		{
+			name:  "LongPassword",
+			input: "admin:" + strings.Repeat("a", 256),
+			err:   true,
+		},
+	}
+
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			creds, err := parseCredentialsFile(func() (io.ReadCloser, error) {
+				return io.NopCloser(strings.NewReader(tt.input)), nil
+			})
+			if tt.err {
+				require.ErrorIs(t, err, errSOCKSCredentials)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, creds)
+		})
+	}
 }
@@ -0,0 +1,119 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/v-byte-cpu/sx/command/log"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+	"github.com/v-byte-cpu/sx/pkg/scan/ssdp"
+)
+
+const defaultSSDPPort = 1900
+
+var errSSDPMulticastTargets = errors.New("multicast mode doesn't accept subnet, file with ip/port pairs or input")
+
+func newSSDPCmd() *ssdpCmd {
+	c := &ssdpCmd{}
+
+	cmd := &cobra.Command{
+		Use: "ssdp [flags] [subnet]",
+		Example: strings.Join([]string{
+			"ssdp -p 1900 192.168.0.1/24", "ssdp --multicast", "ssdp --multicast --st upnp:rootdevice --timeout 5s",
+			"ssdp -f ip_ports_file.jsonl", "ssdp -p 1900 -f ips_file.jsonl"}, "\n"),
+		Short: "Perform SSDP/UPnP discovery scan",
+		Long: strings.Join([]string{
+			"Perform SSDP/UPnP discovery scan.",
+			"The M-SEARCH request is sent to each target over UDP, devices that responded are reported",
+			"with the location of their description, server header and search targets of responses.",
+			"In the multicast mode the request is sent to " + ssdp.MulticastIP.String() + ":1900 instead,",
+			"so all UPnP devices of the local network are discovered without the target list."}, "\n"),
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer cancel()
+
+			if err = c.opts.parseRawOptions(); err != nil {
+				return
+			}
+			scanRange, err := c.opts.parseScanRange(args)
+			if err != nil {
+				return
+			}
+
+			var logger log.Logger
+			if logger, err = c.opts.getLogger(ssdp.ScanType, resultWriter); err != nil {
+				return
+			}
+
+			engine := c.opts.newSSDPScanEngine(ctx)
+			return c.opts.runScanEngine(ctx, cmd, engine, scanRange, logger)
+		},
+	}
+
+	c.opts.initCliFlags(cmd)
+
+	c.cmd = cmd
+	return c
+}
+
+type ssdpCmd struct {
+	cmd  *cobra.Command
+	opts ssdpCmdOpts
+}
+
+type ssdpCmdOpts struct {
+	genericScanCmdOpts
+	timeout      time.Duration
+	searchTarget string
+	multicast    bool
+}
+
+func (o *ssdpCmdOpts) initCliFlags(cmd *cobra.Command) {
+	o.genericScanCmdOpts.initCliFlags(cmd)
+	cmd.Flags().DurationVarP(&o.timeout, "timeout", "t", 2*time.Second, "set time to wait for responses")
+	cmd.Flags().StringVar(&o.searchTarget, "st", ssdp.DefaultSearchTarget,
+		"set search target of requests, e.g. upnp:rootdevice or urn:schemas-upnp-org:device:InternetGatewayDevice:1")
+	cmd.Flags().BoolVar(&o.multicast, "multicast", false,
+		"discover devices of the local network with the multicast request instead of scanning targets")
+}
+
+func (o *ssdpCmdOpts) parseRawOptions() (err error) {
+	if err = o.genericScanCmdOpts.parseRawOptions(); err != nil {
+		return
+	}
+	if len(o.searchTarget) == 0 {
+		return errors.New("invalid search target: non-empty string required")
+	}
+	return
+}
+
+func (o *ssdpCmdOpts) parseScanRange(args []string) (*scan.Range, error) {
+	if !o.multicast {
+		return o.genericScanCmdOpts.parseScanRange(args)
+	}
+	if len(args) > 0 || len(o.ipFile) > 0 || len(o.rawInput) > 0 {
+		return nil, errSSDPMulticastTargets
+	}
+	ports := o.portRanges
+	if len(ports) == 0 {
+		ports = []*scan.PortRange{{StartPort: defaultSSDPPort, EndPort: defaultSSDPPort}}
+	}
+	return &scan.Range{
+		DstSubnet: &net.IPNet{IP: ssdp.MulticastIP.To4(), Mask: net.CIDRMask(32, 32)},
+		Ports:     ports,
+	}, nil
+}
+
+func (o *ssdpCmdOpts) newSSDPScanEngine(ctx context.Context) scan.EngineResulter {
+	scanner := ssdp.NewScanner(
+		ssdp.WithSearchTarget(o.searchTarget),
+		ssdp.WithDataTimeout(o.timeout),
+	)
+	return o.newScanEngine(ctx, scanner)
+}
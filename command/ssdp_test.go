@@ -0,0 +1,148 @@
+package command
+
+import (
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+func TestSSDPCmdDstSubnetError(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{
+			name: "RequiredArg",
+			args: nil,
+		},
+		{
+			name: "InvalidDstSubnet",
+			args: []string{"invalid_ip_address"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := newSSDPCmd().cmd
+			err := cmd.RunE(cmd, tt.args)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestSSDPCmdOptsInitCliFlags(t *testing.T) {
+	t.Parallel()
+	var opts ssdpCmdOpts
+	cmd := &cobra.Command{}
+
+	opts.initCliFlags(cmd)
+	err := cmd.ParseFlags(strings.Split(
+		"--json -p 1900 -f ip_file.jsonl -w 300 --exit-delay 10s --timeout 3s --st upnp:rootdevice --multicast", " "))
+
+	require.NoError(t, err)
+	require.Equal(t, true, opts.json)
+	require.Equal(t, "1900", opts.rawPortRanges)
+	require.Equal(t, "ip_file.jsonl", opts.ipFile)
+	require.Equal(t, 300, opts.workers)
+	require.Equal(t, 10*time.Second, opts.exitDelay)
+
+	require.Equal(t, 3*time.Second, opts.timeout)
+	require.Equal(t, "upnp:rootdevice", opts.searchTarget)
+	require.Equal(t, true, opts.multicast)
+}
+
+func TestSSDPCmdOptsParseRawOptionsError(t *testing.T) {
+	t.Parallel()
+	opts := ssdpCmdOpts{
+		genericScanCmdOpts: genericScanCmdOpts{
+			rawPortRanges: "1900",
+			workers:       300,
+		},
+	}
+
+	err := opts.parseRawOptions()
+
+	require.Error(t, err)
+}
+
+func TestSSDPCmdOptsParseScanRange(t *testing.T) {
+	t.Parallel()
+	multicastSubnet := &net.IPNet{IP: net.IPv4(239, 255, 255, 250).To4(), Mask: net.CIDRMask(32, 32)}
+	tests := []struct {
+		name     string
+		opts     ssdpCmdOpts
+		args     []string
+		expected *scan.Range
+	}{
+		{
+			name: "Unicast",
+			opts: ssdpCmdOpts{genericScanCmdOpts: genericScanCmdOpts{
+				portRanges: []*scan.PortRange{{StartPort: 1900, EndPort: 1900}},
+			}},
+			args: []string{"192.168.0.1/24"},
+			expected: &scan.Range{
+				DstSubnet: &net.IPNet{IP: net.IPv4(192, 168, 0, 0).To4(), Mask: net.CIDRMask(24, 32)},
+				Ports:     []*scan.PortRange{{StartPort: 1900, EndPort: 1900}},
+			},
+		},
+		{
+			name: "Multicast",
+			opts: ssdpCmdOpts{multicast: true},
+			expected: &scan.Range{
+				DstSubnet: multicastSubnet,
+				Ports:     []*scan.PortRange{{StartPort: 1900, EndPort: 1900}},
+			},
+		},
+		{
+			name: "MulticastCustomPort",
+			opts: ssdpCmdOpts{multicast: true, genericScanCmdOpts: genericScanCmdOpts{
+				portRanges: []*scan.PortRange{{StartPort: 1901, EndPort: 1901}},
+			}},
+			expected: &scan.Range{
+				DstSubnet: multicastSubnet,
+				Ports:     []*scan.PortRange{{StartPort: 1901, EndPort: 1901}},
+			},
+		},
+	}
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			r, err := tt.opts.parseScanRange(tt.args)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, r)
+		})
+	}
+}
+
+func TestSSDPCmdOptsParseScanRangeMulticastError(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		opts ssdpCmdOpts
+		args []string
+	}{
+		{
+			name: "Subnet",
+			opts: ssdpCmdOpts{multicast: true},
+			args: []string{"192.168.0.1/24"},
+		},
+		{
+			name: "IPFile",
+			opts: ssdpCmdOpts{multicast: true, genericScanCmdOpts: genericScanCmdOpts{ipFile: "ips_file.jsonl"}},
+		},
+	}
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := tt.opts.parseScanRange(tt.args)
+			require.ErrorIs(t, err, errSSDPMulticastTargets)
+		})
+	}
+}
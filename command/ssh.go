@@ -0,0 +1,86 @@
+package command
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/v-byte-cpu/sx/command/log"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+	"github.com/v-byte-cpu/sx/pkg/scan/ssh"
+)
+
+func newSSHCmd() *sshCmd {
+	c := &sshCmd{}
+
+	cmd := &cobra.Command{
+		Use: "ssh [flags] [subnet]",
+		Example: strings.Join([]string{
+			"ssh -p 22 192.168.0.1/24", "ssh -p 22,2222 10.0.0.1",
+			"ssh --host-keys --algorithms -p 22 10.0.0.1/16",
+			"ssh -f ip_ports_file.jsonl", "ssh -p 22 -f ips_file.jsonl"}, "\n"),
+		Short: "Perform SSH version and host key scan",
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer cancel()
+
+			if err = c.opts.parseRawOptions(); err != nil {
+				return
+			}
+			scanRange, err := c.opts.parseScanRange(args)
+			if err != nil {
+				return
+			}
+
+			var logger log.Logger
+			if logger, err = c.opts.getLogger(ssh.ScanType, resultWriter); err != nil {
+				return
+			}
+
+			engine := c.opts.newSSHScanEngine(ctx)
+			return c.opts.runScanEngine(ctx, cmd, engine, scanRange, logger)
+		},
+	}
+
+	c.opts.initCliFlags(cmd)
+
+	c.cmd = cmd
+	return c
+}
+
+type sshCmd struct {
+	cmd  *cobra.Command
+	opts sshCmdOpts
+}
+
+type sshCmdOpts struct {
+	genericScanCmdOpts
+	timeout    time.Duration
+	hostKeys   bool
+	algorithms bool
+}
+
+func (o *sshCmdOpts) initCliFlags(cmd *cobra.Command) {
+	o.genericScanCmdOpts.initCliFlags(cmd)
+	cmd.Flags().DurationVarP(&o.timeout, "timeout", "t", 2*time.Second, "set connect and data timeout")
+	cmd.Flags().BoolVar(&o.hostKeys, "host-keys", false,
+		"collect host key fingerprints, one connection is opened for every host key type")
+	cmd.Flags().BoolVar(&o.algorithms, "algorithms", false, "collect supported key exchange, host key, cipher, MAC and compression algorithms")
+}
+
+func (o *sshCmdOpts) newSSHScanEngine(ctx context.Context) scan.EngineResulter {
+	opts := []ssh.ScannerOption{
+		ssh.WithDialTimeout(o.timeout),
+		ssh.WithDataTimeout(o.timeout),
+	}
+	if o.hostKeys {
+		opts = append(opts, ssh.WithHostKeys())
+	}
+	if o.algorithms {
+		opts = append(opts, ssh.WithAlgorithms())
+	}
+	return o.newScanEngine(ctx, ssh.NewScanner(opts...))
+}
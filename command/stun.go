@@ -0,0 +1,96 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/v-byte-cpu/sx/command/log"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+	"github.com/v-byte-cpu/sx/pkg/scan/stun"
+)
+
+const defaultSTUNPort = 3478
+
+func newSTUNCmd() *stunCmd {
+	c := &stunCmd{}
+
+	cmd := &cobra.Command{
+		Use: "stun [flags] [subnet]",
+		Example: strings.Join([]string{
+			"stun 192.168.0.1/24", "stun -p 3478,19302 10.0.0.1/16",
+			"stun -f ip_ports_file.jsonl", "stun -p 3478 -f ips_file.jsonl"}, "\n"),
+		Short: "Perform STUN server scan",
+		Long: strings.Join([]string{
+			"Perform STUN server scan.",
+			"The Binding Request is sent to each target over UDP, port 3478 by default,",
+			"servers that answered are reported with the XOR-MAPPED-ADDRESS and the SOFTWARE attributes.",
+			"The mapped address is the address of the scanner as the server sees it, so it reveals the NAT mapping.",
+			"Servers that require authentication are reported with the error of the Binding Error Response."}, "\n"),
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer cancel()
+
+			if err = c.opts.parseRawOptions(); err != nil {
+				return
+			}
+			scanRange, err := c.opts.parseScanRange(args)
+			if err != nil {
+				return
+			}
+
+			var logger log.Logger
+			if logger, err = c.opts.getLogger(stun.ScanType, resultWriter); err != nil {
+				return
+			}
+
+			engine := c.opts.newSTUNScanEngine(ctx)
+			return c.opts.runScanEngine(ctx, cmd, engine, scanRange, logger)
+		},
+	}
+
+	c.opts.initCliFlags(cmd)
+
+	c.cmd = cmd
+	return c
+}
+
+type stunCmd struct {
+	cmd  *cobra.Command
+	opts stunCmdOpts
+}
+
+type stunCmdOpts struct {
+	genericScanCmdOpts
+	timeout time.Duration
+	retries int
+}
+
+func (o *stunCmdOpts) initCliFlags(cmd *cobra.Command) {
+	o.genericScanCmdOpts.initCliFlags(cmd)
+	cmd.Flags().DurationVarP(&o.timeout, "timeout", "t", 1*time.Second, "set time to wait for a response to each request")
+	cmd.Flags().IntVar(&o.retries, "retries", 1, "set number of additional requests if there is no response")
+}
+
+func (o *stunCmdOpts) parseRawOptions() (err error) {
+	if err = o.genericScanCmdOpts.parseRawOptions(); err != nil {
+		return
+	}
+	if o.retries < 0 {
+		return errors.New("invalid retries: non-negative number required")
+	}
+	// targets of the subnet argument are scanned on the standard port unless ports are set
+	if len(o.portRanges) == 0 && len(o.ipFile) == 0 && len(o.rawInput) == 0 {
+		o.portRanges = []*scan.PortRange{{StartPort: defaultSTUNPort, EndPort: defaultSTUNPort}}
+	}
+	return
+}
+
+func (o *stunCmdOpts) newSTUNScanEngine(ctx context.Context) scan.EngineResulter {
+	scanner := stun.NewScanner(stun.WithDataTimeout(o.timeout), stun.WithRetries(o.retries))
+	return o.newScanEngine(ctx, scanner)
+}
@@ -0,0 +1,35 @@
+package command
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+	"github.com/v-byte-cpu/sx/pkg/tagpolicy"
+)
+
+// tagPolicyCmdOpts are options of scan policies of targets selected by their tags,
+// e.g. targets tagged as ics are scanned with a low rate and a restricted set of ports
+type tagPolicyCmdOpts struct {
+	tagPolicies *tagpolicy.Config
+
+	rawTagPoliciesFile string
+}
+
+func (o *tagPolicyCmdOpts) initCliFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&o.rawTagPoliciesFile, "tag-policies", "",
+		"set YAML file with rate, retries and allowed ports of targets matching their tags or other metadata")
+}
+
+func (o *tagPolicyCmdOpts) parseRawOptions() (err error) {
+	if len(o.rawTagPoliciesFile) > 0 {
+		o.tagPolicies, err = tagpolicy.LoadFile(o.rawTagPoliciesFile)
+	}
+	return
+}
+
+// scanEngineOptions returns options of the scan engine that enforce tag policies if they are set
+func (o *tagPolicyCmdOpts) scanEngineOptions() []scan.GenericEngineOption {
+	if o.tagPolicies == nil {
+		return nil
+	}
+	return []scan.GenericEngineOption{scan.WithTargetPolicies(o.tagPolicies.TargetPolicies()...)}
+}
@@ -0,0 +1,45 @@
+package command
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+)
+
+func writeTestTagPolicies(t *testing.T, policies string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "policies.yml")
+	require.NoError(t, os.WriteFile(path, []byte(policies), 0o600))
+	return path
+}
+
+func TestTagPolicyCmdOptsInitCliFlags(t *testing.T) {
+	t.Parallel()
+	var opts tagPolicyCmdOpts
+	cmd := &cobra.Command{}
+	path := writeTestTagPolicies(t, "policies: [{meta: {tags: ics}, rate: 1/5s, ports: [502]}]")
+
+	opts.initCliFlags(cmd)
+	require.NoError(t, cmd.ParseFlags([]string{"--tag-policies", path}))
+	require.Equal(t, path, opts.rawTagPoliciesFile)
+
+	require.NoError(t, opts.parseRawOptions())
+	require.NotNil(t, opts.tagPolicies)
+	require.Len(t, opts.scanEngineOptions(), 1)
+}
+
+func TestTagPolicyCmdOptsParseRawOptionsError(t *testing.T) {
+	t.Parallel()
+	opts := tagPolicyCmdOpts{rawTagPoliciesFile: filepath.Join(t.TempDir(), "none.yml")}
+	require.Error(t, opts.parseRawOptions())
+
+	opts = tagPolicyCmdOpts{rawTagPoliciesFile: writeTestTagPolicies(t, "policies: [{rate: 1/5s}]")}
+	require.Error(t, opts.parseRawOptions())
+
+	opts = tagPolicyCmdOpts{}
+	require.NoError(t, opts.parseRawOptions())
+	require.Empty(t, opts.scanEngineOptions())
+}
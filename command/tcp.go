@@ -10,7 +10,6 @@ import (
 
 	"github.com/spf13/cobra"
 	"github.com/v-byte-cpu/sx/pkg/scan"
-	"github.com/v-byte-cpu/sx/pkg/scan/arp"
 	"github.com/v-byte-cpu/sx/pkg/scan/tcp"
 )
 
@@ -27,7 +26,9 @@ const (
 )
 
 var (
-	errTCPflag = errors.New("invalid TCP packet flag")
+	errTCPflag       = errors.New("invalid TCP packet flag")
+	errTCPflagPolicy = errors.New("invalid policy: only TCP SYN scan results can be checked, TCP flags are not allowed")
+	errTCPflagOpen   = errors.New("invalid fail-on-open: only TCP SYN scan finds open ports, TCP flags are not allowed")
 )
 
 func newTCPFlagsCmd() *tcpFlagsCmd {
@@ -48,7 +49,7 @@ func newTCPFlagsCmd() *tcpFlagsCmd {
 				return
 			}
 			if len(c.opts.tcpFlags) == 0 {
-				return newTCPSYNCmdOpts(c.opts.tcpCmdOpts).startScan(ctx, args)
+				return newTCPSYNCmdOpts(c.opts.tcpCmdOpts, c.opts.policyCmdOpts, c.opts.exitCodeCmdOpts).startScan(ctx, cmd, args)
 			}
 
 			scanName := tcp.FlagsScanType
@@ -73,6 +74,7 @@ func newTCPFlagsCmd() *tcpFlagsCmd {
 				withPacketBPFFilter(tcp.BPFFilter),
 				withRateCount(c.opts.rateCount),
 				withRateWindow(c.opts.rateWindow),
+				withPacketSampling(c.opts.sampleCmdOpts),
 				withPacketVPNmode(c.opts.vpnMode),
 				withPacketEngineConfig(newEngineConfig(
 					withLogger(c.opts.logger),
@@ -96,6 +98,9 @@ type tcpFlagsCmd struct {
 
 type tcpFlagsCmdOpts struct {
 	tcpCmdOpts
+	// policy and open ports are checked only for the default TCP SYN scan
+	policyCmdOpts
+	exitCodeCmdOpts
 	tcpFlags []string
 
 	rawTCPFlags string
@@ -103,6 +108,8 @@ type tcpFlagsCmdOpts struct {
 
 func (o *tcpFlagsCmdOpts) initCliFlags(cmd *cobra.Command) {
 	o.ipPortScanCmdOpts.initCliFlags(cmd)
+	o.policyCmdOpts.initCliFlags(cmd)
+	o.exitCodeCmdOpts.initFailOnOpenCliFlag(cmd)
 	cmd.Flags().StringVar(&o.rawTCPFlags, "flags", "", "set TCP flags")
 }
 
@@ -110,7 +117,18 @@ func (o *tcpFlagsCmdOpts) parseRawOptions() (err error) {
 	if err = o.ipPortScanCmdOpts.parseRawOptions(); err != nil {
 		return
 	}
-	o.tcpFlags, err = parseTCPFlags(o.rawTCPFlags)
+	if o.tcpFlags, err = parseTCPFlags(o.rawTCPFlags); err != nil {
+		return
+	}
+	if err = o.policyCmdOpts.parseRawOptions(); err != nil {
+		return
+	}
+	if o.policy != nil && len(o.tcpFlags) > 0 {
+		return errTCPflagPolicy
+	}
+	if o.failOnOpen && len(o.tcpFlags) > 0 {
+		return errTCPflagOpen
+	}
 	return
 }
 
@@ -151,13 +169,13 @@ func (o *tcpCmdOpts) newTCPScanMethod(ctx context.Context, opts ...tcpScanConfig
 	for _, opt := range opts {
 		opt(c)
 	}
-	reqgen := o.newIPPortGenerator()
-	if o.cache != nil {
-		reqgen = arp.NewCacheRequestGenerator(reqgen, o.gatewayMAC, o.cache)
+	reqgen := o.withDstMAC(o.newIPPortGenerator())
+	if c.requestWrapper != nil {
+		reqgen = c.requestWrapper(reqgen)
 	}
 	c.packetFillerOpts = append(c.packetFillerOpts, tcp.WithFillerVPNmode(o.vpnMode))
 	pktgen := scan.NewPacketMultiGenerator(tcp.NewPacketFiller(c.packetFillerOpts...), runtime.NumCPU())
-	psrc := scan.NewPacketSource(reqgen, pktgen)
+	psrc := scan.NewPacketSource(o.withHeartbeat(reqgen), pktgen)
 	results := scan.NewResultChan(ctx, 1000)
 	return tcp.NewScanMethod(
 		c.scanName, psrc, results,
@@ -171,6 +189,7 @@ type tcpScanConfig struct {
 	packetFillerOpts []tcp.PacketFillerOption
 	packetFilter     tcp.PacketFilterFunc
 	packetFlags      tcp.PacketFlagsFunc
+	requestWrapper   func(scan.RequestGenerator) scan.RequestGenerator
 }
 
 type tcpScanConfigOption func(c *tcpScanConfig)
@@ -198,3 +217,9 @@ func withTCPPacketFlags(packetFlags tcp.PacketFlagsFunc) tcpScanConfigOption {
 		c.packetFlags = packetFlags
 	}
 }
+
+func withTCPRequestWrapper(wrapper func(scan.RequestGenerator) scan.RequestGenerator) tcpScanConfigOption {
+	return func(c *tcpScanConfig) {
+		c.requestWrapper = wrapper
+	}
+}
@@ -42,6 +42,7 @@ func newTCPFINCmd() *tcpFINCmd {
 				withPacketBPFFilter(tcp.BPFFilter),
 				withRateCount(c.opts.rateCount),
 				withRateWindow(c.opts.rateWindow),
+				withPacketSampling(c.opts.sampleCmdOpts),
 				withPacketVPNmode(c.opts.vpnMode),
 				withPacketEngineConfig(newEngineConfig(
 					withLogger(c.opts.logger),
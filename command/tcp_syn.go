@@ -8,6 +8,7 @@ import (
 
 	"github.com/google/gopacket/layers"
 	"github.com/spf13/cobra"
+	"github.com/v-byte-cpu/sx/command/log"
 	"github.com/v-byte-cpu/sx/pkg/scan/tcp"
 )
 
@@ -25,7 +26,7 @@ func newTCPSYNCmd() *tcpSYNCmd {
 			if err = c.opts.parseRawOptions(); err != nil {
 				return
 			}
-			return c.opts.startScan(ctx, args)
+			return c.opts.startScan(ctx, cmd, args)
 		},
 	}
 
@@ -42,13 +43,28 @@ type tcpSYNCmd struct {
 
 type tcpSYNCmdOpts struct {
 	tcpCmdOpts
+	policyCmdOpts
+	exitCodeCmdOpts
 }
 
-func newTCPSYNCmdOpts(opts tcpCmdOpts) *tcpSYNCmdOpts {
-	return &tcpSYNCmdOpts{opts}
+func newTCPSYNCmdOpts(opts tcpCmdOpts, policyOpts policyCmdOpts, exitCodeOpts exitCodeCmdOpts) *tcpSYNCmdOpts {
+	return &tcpSYNCmdOpts{opts, policyOpts, exitCodeOpts}
 }
 
-func (o *tcpSYNCmdOpts) startScan(ctx context.Context, args []string) (err error) {
+func (o *tcpSYNCmdOpts) initCliFlags(cmd *cobra.Command) {
+	o.tcpCmdOpts.initCliFlags(cmd)
+	o.policyCmdOpts.initCliFlags(cmd)
+	o.exitCodeCmdOpts.initFailOnOpenCliFlag(cmd)
+}
+
+func (o *tcpSYNCmdOpts) parseRawOptions() (err error) {
+	if err = o.tcpCmdOpts.parseRawOptions(); err != nil {
+		return
+	}
+	return o.policyCmdOpts.parseRawOptions()
+}
+
+func (o *tcpSYNCmdOpts) startScan(ctx context.Context, cmd *cobra.Command, args []string) (err error) {
 	scanName := tcp.SYNScanType
 
 	if err = o.parseOptions(scanName, args); err != nil {
@@ -63,18 +79,29 @@ func (o *tcpSYNCmdOpts) startScan(ctx context.Context, args []string) (err error
 			return pkt.SYN && pkt.ACK
 		}),
 		withTCPPacketFlags(tcp.EmptyFlags),
+		withTCPRequestWrapper(o.withPolicyProbes),
 	)
 
-	return startPortScanEngine(ctx, newPacketScanConfig(
+	scanLogger, checker := o.newPolicyChecker(o.logger)
+	stats := log.NewStatsLogger(scanLogger)
+	if err = startPortScanEngine(ctx, newPacketScanConfig(
 		withPacketScanMethod(m),
 		withPacketBPFFilter(tcp.SYNACKBPFFilter),
 		withRateCount(o.rateCount),
 		withRateWindow(o.rateWindow),
+		withPacketSampling(o.sampleCmdOpts),
 		withPacketVPNmode(o.vpnMode),
 		withPacketEngineConfig(newEngineConfig(
-			withLogger(o.logger),
+			withLogger(stats),
 			withScanRange(o.scanRange),
 			withExitDelay(o.exitDelay),
 		)),
-	))
+	)); err != nil {
+		return
+	}
+	if err = checkPolicy(cmd, o.logger, checker); err != nil {
+		return
+	}
+	// packet scans don't count requests, only open ports are checked
+	return o.checkExitCode(cmd, stats, 0)
 }
@@ -0,0 +1,104 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/v-byte-cpu/sx/command/log"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+	"github.com/v-byte-cpu/sx/pkg/scan/tftp"
+)
+
+const defaultTFTPPort = 69
+
+func newTFTPCmd() *tftpCmd {
+	c := &tftpCmd{}
+
+	cmd := &cobra.Command{
+		Use: "tftp [flags] [subnet]",
+		Example: strings.Join([]string{
+			"tftp 192.168.0.1/24", "tftp --files startup-config,router-confg 10.0.0.1/16",
+			"tftp -f ip_ports_file.jsonl", "tftp -p 69 -f ips_file.jsonl"}, "\n"),
+		Short: "Perform TFTP server scan",
+		Long: strings.Join([]string{
+			"Perform TFTP server scan.",
+			"Read requests of each file are sent to each target over UDP, port 69 by default,",
+			"servers are reported with files they sent or errors they answered, e.g. File not found.",
+			"Transfers are aborted after the first block of the file."}, "\n"),
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer cancel()
+
+			if err = c.opts.parseRawOptions(); err != nil {
+				return
+			}
+			scanRange, err := c.opts.parseScanRange(args)
+			if err != nil {
+				return
+			}
+
+			var logger log.Logger
+			if logger, err = c.opts.getLogger(tftp.ScanType, resultWriter); err != nil {
+				return
+			}
+
+			engine := c.opts.newTFTPScanEngine(ctx)
+			return c.opts.runScanEngine(ctx, cmd, engine, scanRange, logger)
+		},
+	}
+
+	c.opts.initCliFlags(cmd)
+
+	c.cmd = cmd
+	return c
+}
+
+type tftpCmd struct {
+	cmd  *cobra.Command
+	opts tftpCmdOpts
+}
+
+type tftpCmdOpts struct {
+	genericScanCmdOpts
+	timeout time.Duration
+	retries int
+	files   []string
+}
+
+func (o *tftpCmdOpts) initCliFlags(cmd *cobra.Command) {
+	o.genericScanCmdOpts.initCliFlags(cmd)
+	cmd.Flags().DurationVarP(&o.timeout, "timeout", "t", 1*time.Second, "set time to wait for a response to each request")
+	cmd.Flags().IntVar(&o.retries, "retries", 1, "set number of additional requests of the same file if there is no response")
+	cmd.Flags().StringSliceVar(&o.files, "files", tftp.DefaultFiles, "set comma-separated list of files to request")
+}
+
+func (o *tftpCmdOpts) parseRawOptions() (err error) {
+	if err = o.genericScanCmdOpts.parseRawOptions(); err != nil {
+		return
+	}
+	if o.retries < 0 {
+		return errors.New("invalid retries: non-negative number required")
+	}
+	if len(o.files) == 0 {
+		return errors.New("invalid files: at least one file required")
+	}
+	// targets of the subnet argument are scanned on the standard port unless ports are set
+	if len(o.portRanges) == 0 && len(o.ipFile) == 0 && len(o.rawInput) == 0 {
+		o.portRanges = []*scan.PortRange{{StartPort: defaultTFTPPort, EndPort: defaultTFTPPort}}
+	}
+	return
+}
+
+func (o *tftpCmdOpts) newTFTPScanEngine(ctx context.Context) scan.EngineResulter {
+	scanner := tftp.NewScanner(
+		tftp.WithFiles(o.files),
+		tftp.WithDataTimeout(o.timeout),
+		tftp.WithRetries(o.retries),
+	)
+	return o.newScanEngine(ctx, scanner)
+}
@@ -0,0 +1,119 @@
+package command
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+func TestTFTPCmdDstSubnetError(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{
+			name: "RequiredArg",
+			args: nil,
+		},
+		{
+			name: "InvalidDstSubnet",
+			args: []string{"invalid_ip_address"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := newTFTPCmd().cmd
+			err := cmd.RunE(cmd, tt.args)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestTFTPCmdOptsInitCliFlags(t *testing.T) {
+	t.Parallel()
+	var opts tftpCmdOpts
+	cmd := &cobra.Command{}
+
+	opts.initCliFlags(cmd)
+	err := cmd.ParseFlags(strings.Split(
+		"--json -p 69 -f ip_file.jsonl -w 300 --exit-delay 10s --timeout 3s --retries 2 --files boot.ini,router-confg", " "))
+
+	require.NoError(t, err)
+	require.Equal(t, true, opts.json)
+	require.Equal(t, "69", opts.rawPortRanges)
+	require.Equal(t, "ip_file.jsonl", opts.ipFile)
+	require.Equal(t, 300, opts.workers)
+	require.Equal(t, 10*time.Second, opts.exitDelay)
+
+	require.Equal(t, 3*time.Second, opts.timeout)
+	require.Equal(t, 2, opts.retries)
+	require.Equal(t, []string{"boot.ini", "router-confg"}, opts.files)
+}
+
+func TestTFTPCmdOptsParseRawOptions(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		opts     tftpCmdOpts
+		expected []*scan.PortRange
+	}{
+		{
+			name: "Ports",
+			opts: tftpCmdOpts{
+				genericScanCmdOpts: genericScanCmdOpts{rawPortRanges: "69,6969", workers: 300},
+				files:              []string{"boot.ini"},
+			},
+			expected: []*scan.PortRange{{StartPort: 69, EndPort: 69}, {StartPort: 6969, EndPort: 6969}},
+		},
+		{
+			name: "DefaultPort",
+			opts: tftpCmdOpts{
+				genericScanCmdOpts: genericScanCmdOpts{workers: 300},
+				files:              []string{"boot.ini"},
+			},
+			expected: []*scan.PortRange{{StartPort: 69, EndPort: 69}},
+		},
+	}
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			err := tt.opts.parseRawOptions()
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, tt.opts.portRanges)
+		})
+	}
+}
+
+func TestTFTPCmdOptsParseRawOptionsError(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		opts tftpCmdOpts
+	}{
+		{
+			name: "NegativeRetries",
+			opts: tftpCmdOpts{
+				genericScanCmdOpts: genericScanCmdOpts{workers: 300},
+				retries:            -1,
+				files:              []string{"boot.ini"},
+			},
+		},
+		{
+			name: "NoFiles",
+			opts: tftpCmdOpts{genericScanCmdOpts: genericScanCmdOpts{workers: 300}},
+		},
+	}
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			require.Error(t, tt.opts.parseRawOptions())
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/v-byte-cpu/sx/command/log"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+	"github.com/v-byte-cpu/sx/pkg/scan/tls"
+)
+
+func newTLSCmd() *tlsCmd {
+	c := &tlsCmd{}
+
+	cmd := &cobra.Command{
+		Use: "tls [flags] [subnet]",
+		Example: strings.Join([]string{
+			"tls -p 443 192.168.0.1/24", "tls -p 443,8443 10.0.0.1",
+			"tls --expiry-days 14 --expiring-only -p 443 10.0.0.1/16",
+			"tls -f ip_ports_file.jsonl", "tls -p 443 -f ips_file.jsonl"}, "\n"),
+		Short: "Perform TLS certificate scan",
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer cancel()
+
+			if err = c.opts.parseRawOptions(); err != nil {
+				return
+			}
+			scanRange, err := c.opts.parseScanRange(args)
+			if err != nil {
+				return
+			}
+
+			var logger log.Logger
+			if logger, err = c.opts.getLogger(tls.ScanType, resultWriter); err != nil {
+				return
+			}
+			var filters []log.FilterFunc
+			if c.opts.expiringOnly {
+				filters = append(filters, tls.Expiring)
+			}
+
+			engine := c.opts.newTLSScanEngine(ctx)
+			// policy checks all open ports, not only ports with expiring certificates
+			return c.opts.runScanEngine(ctx, cmd, engine, scanRange, logger, filters...)
+		},
+	}
+
+	c.opts.initCliFlags(cmd)
+
+	c.cmd = cmd
+	return c
+}
+
+type tlsCmd struct {
+	cmd  *cobra.Command
+	opts tlsCmdOpts
+}
+
+type tlsCmdOpts struct {
+	genericScanCmdOpts
+	timeout      time.Duration
+	expiryDays   int
+	expiringOnly bool
+}
+
+func (o *tlsCmdOpts) initCliFlags(cmd *cobra.Command) {
+	o.genericScanCmdOpts.initCliFlags(cmd)
+	cmd.Flags().DurationVarP(&o.timeout, "timeout", "t", 2*time.Second, "set connect and data timeout")
+	cmd.Flags().IntVar(&o.expiryDays, "expiry-days", 30,
+		"flag certificates that expire within the specified number of days")
+	cmd.Flags().BoolVar(&o.expiringOnly, "expiring-only", false, "output only expiring and expired certificates")
+}
+
+func (o *tlsCmdOpts) parseRawOptions() (err error) {
+	if err = o.genericScanCmdOpts.parseRawOptions(); err != nil {
+		return
+	}
+	if o.expiryDays < 0 {
+		return errors.New("invalid expiry days: non-negative number required")
+	}
+	return
+}
+
+func (o *tlsCmdOpts) newTLSScanEngine(ctx context.Context) scan.EngineResulter {
+	scanner := tls.NewScanner(
+		tls.WithDialTimeout(o.timeout),
+		tls.WithDataTimeout(o.timeout),
+		tls.WithExpiryThreshold(time.Duration(o.expiryDays)*24*time.Hour))
+	return o.newScanEngine(ctx, scanner)
+}
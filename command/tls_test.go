@@ -0,0 +1,69 @@
+package command
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTLSCmdDstSubnetError(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{
+			name: "RequiredArg",
+			args: nil,
+		},
+		{
+			name: "InvalidDstSubnet",
+			args: []string{"invalid_ip_address"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := newTLSCmd().cmd
+			err := cmd.RunE(cmd, tt.args)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestTLSCmdOptsInitCliFlags(t *testing.T) {
+	t.Parallel()
+	var opts tlsCmdOpts
+	cmd := &cobra.Command{}
+
+	opts.initCliFlags(cmd)
+	err := cmd.ParseFlags(strings.Split(
+		"--json -p 443,8443 -f ip_file.jsonl -w 300 --exit-delay 10s --timeout 3s --expiry-days 14 --expiring-only", " "))
+
+	require.NoError(t, err)
+	require.Equal(t, true, opts.json)
+	require.Equal(t, "443,8443", opts.rawPortRanges)
+	require.Equal(t, "ip_file.jsonl", opts.ipFile)
+	require.Equal(t, 300, opts.workers)
+	require.Equal(t, 10*time.Second, opts.exitDelay)
+
+	require.Equal(t, 3*time.Second, opts.timeout)
+	require.Equal(t, 14, opts.expiryDays)
+	require.Equal(t, true, opts.expiringOnly)
+}
+
+func TestTLSCmdOptsParseRawOptionsInvalidExpiryDays(t *testing.T) {
+	t.Parallel()
+	opts := tlsCmdOpts{
+		genericScanCmdOpts: genericScanCmdOpts{
+			rawPortRanges: "443",
+			workers:       100,
+		},
+		expiryDays: -1,
+	}
+
+	err := opts.parseRawOptions()
+	require.Error(t, err)
+}
@@ -2,18 +2,22 @@ package command
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"os"
 	"os/signal"
 	"runtime"
+	"strconv"
 	"strings"
 
 	"github.com/spf13/cobra"
+	"github.com/v-byte-cpu/sx/command/log"
 	"github.com/v-byte-cpu/sx/pkg/scan"
-	"github.com/v-byte-cpu/sx/pkg/scan/arp"
-	"github.com/v-byte-cpu/sx/pkg/scan/icmp"
 	"github.com/v-byte-cpu/sx/pkg/scan/udp"
 )
 
+var errUDPOpen = errors.New("invalid fail-on-open: UDP scan finds open ports only with --match")
+
 func newUDPCmd() *udpCmd {
 	c := &udpCmd{}
 
@@ -24,7 +28,8 @@ func newUDPCmd() *udpCmd {
 			"udp -p 22-4567 10.0.0.1",
 			"udp --ttl 37 -p 53 192.168.0.1/24",
 			"udp --ipproto 157 -p 53 192.168.0.1/24",
-			`udp --payload '\x01\x02\x03' -p 53 192.168.0.1/24`}, "\n"),
+			`udp --payload '\x01\x02\x03' -p 53 192.168.0.1/24`,
+			"udp --match 53:dns,123:ntp,161:snmp -p 53,123,161 192.168.0.1/24"}, "\n"),
 		Short: "Perform UDP scan",
 		RunE: func(cmd *cobra.Command, args []string) (err error) {
 			ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
@@ -39,18 +44,25 @@ func newUDPCmd() *udpCmd {
 
 			m := c.opts.newUDPScanMethod(ctx)
 
-			return startPortScanEngine(ctx, newPacketScanConfig(
+			// ICMP replies of closed and filtered ports are logged, but only matched responses are open ports
+			stats := log.NewCountStatsLogger(c.opts.logger, isOpenUDPResult)
+			if err = startPortScanEngine(ctx, newPacketScanConfig(
 				withPacketScanMethod(m),
-				withPacketBPFFilter(icmp.BPFFilter),
+				withPacketBPFFilter(udp.BPFFilter(c.opts.matchers)),
 				withRateCount(c.opts.rateCount),
 				withRateWindow(c.opts.rateWindow),
+				withPacketSampling(c.opts.sampleCmdOpts),
 				withPacketVPNmode(c.opts.vpnMode),
 				withPacketEngineConfig(newEngineConfig(
-					withLogger(c.opts.logger),
+					withLogger(stats),
 					withScanRange(c.opts.scanRange),
 					withExitDelay(c.opts.exitDelay),
 				)),
-			))
+			)); err != nil {
+				return
+			}
+			// packet scans don't count requests, only open ports are checked
+			return c.opts.checkExitCode(cmd, stats, 0)
 		},
 	}
 
@@ -67,19 +79,24 @@ type udpCmd struct {
 
 type udpCmdOpts struct {
 	ipPortScanCmdOpts
+	exitCodeCmdOpts
 	ipTTL      uint8
 	ipFlags    uint8
 	ipProtocol uint8
 	ipTotalLen uint16
 
-	udpPayload []byte
+	udpPayload     []byte
+	matchers       map[uint16]*udp.Matcher
+	adaptivePacing bool
 
 	rawIPFlags    string
 	rawUDPPayload string
+	rawMatchers   string
 }
 
 func (o *udpCmdOpts) initCliFlags(cmd *cobra.Command) {
 	o.ipPortScanCmdOpts.initCliFlags(cmd)
+	o.exitCodeCmdOpts.initFailOnOpenCliFlag(cmd)
 	cmd.Flags().Uint8Var(&o.ipTTL, "ttl", 64, "set IP TTL field of generated packet")
 	cmd.Flags().Uint8Var(&o.ipProtocol, "ipproto", 17,
 		strings.Join([]string{"set IP Protocol field of generated packet", "UDP by default"}, "\n"))
@@ -89,6 +106,14 @@ func (o *udpCmdOpts) initCliFlags(cmd *cobra.Command) {
 
 	cmd.Flags().StringVar(&o.rawUDPPayload, "payload", "",
 		strings.Join([]string{"set byte payload of generated packet", "0 bytes by default"}, "\n"))
+	cmd.Flags().BoolVar(&o.adaptivePacing, "adaptive-pacing", false,
+		strings.Join([]string{"detect hosts that rate limit ICMP replies and space out probes to them",
+			"e.g. Linux hosts send about one ICMP port unreachable per second by default"}, "\n"))
+	cmd.Flags().StringVar(&o.rawMatchers, "match", "",
+		strings.Join([]string{"report ports as open if they answer with a valid response of the protocol",
+			`format: "port:matcher,..." e.g. 53:dns,123:ntp`,
+			"matchers: " + strings.Join(udp.MatcherNames(), ", "),
+			"probes of matchers are sent to their ports instead of the payload, generic-nonempty has no probe"}, "\n"))
 }
 
 func (o *udpCmdOpts) parseRawOptions() (err error) {
@@ -105,18 +130,55 @@ func (o *udpCmdOpts) parseRawOptions() (err error) {
 			return
 		}
 	}
+	if len(o.rawMatchers) > 0 {
+		if o.matchers, err = parseUDPMatchers(o.rawMatchers); err != nil {
+			return
+		}
+	}
+	if o.failOnOpen && len(o.matchers) == 0 {
+		return errUDPOpen
+	}
+	return
+}
+
+// isOpenUDPResult checks whether the result is the response accepted by the matcher of the port
+func isOpenUDPResult(result scan.Result) bool {
+	r, ok := result.(*udp.ScanResult)
+	return ok && len(r.Matcher) > 0
+}
+
+// parseUDPMatchers parses the comma-separated list of port:matcher pairs
+func parseUDPMatchers(rawMatchers string) (result map[uint16]*udp.Matcher, err error) {
+	result = make(map[uint16]*udp.Matcher)
+	for _, pair := range strings.Split(rawMatchers, ",") {
+		parts := strings.SplitN(pair, ":", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid matcher %q: port:matcher required", pair)
+		}
+		var port uint64
+		if port, err = strconv.ParseUint(parts[0], 10, 16); err != nil {
+			return nil, fmt.Errorf("invalid matcher port %q: %w", parts[0], err)
+		}
+		var m *udp.Matcher
+		if m, err = udp.GetMatcher(parts[1]); err != nil {
+			return nil, fmt.Errorf("%w: %q", err, parts[1])
+		}
+		result[uint16(port)] = m
+	}
 	return
 }
 
 func (o *udpCmdOpts) newUDPScanMethod(ctx context.Context) *udp.ScanMethod {
-	reqgen := o.newIPPortGenerator()
-	if o.cache != nil {
-		reqgen = arp.NewCacheRequestGenerator(o.newIPPortGenerator(), o.gatewayMAC, o.cache)
+	reqgen := o.withDstMAC(o.newIPPortGenerator())
+	var pacer *scan.HostPacer
+	if o.adaptivePacing {
+		pacer = scan.NewHostPacer()
+		reqgen = scan.NewPacedRequestGenerator(reqgen, pacer)
 	}
 	pktgen := scan.NewPacketMultiGenerator(udp.NewPacketFiller(o.getUDPOptions()...), runtime.NumCPU())
-	psrc := scan.NewPacketSource(reqgen, pktgen)
+	psrc := scan.NewPacketSource(o.withHeartbeat(reqgen), pktgen)
 	results := scan.NewResultChan(ctx, 1000)
-	return udp.NewScanMethod(psrc, results, o.vpnMode)
+	return udp.NewScanMethod(psrc, results, o.vpnMode, udp.WithMatchers(o.matchers), udp.WithHostPacer(pacer))
 }
 
 func (o *udpCmdOpts) getUDPOptions() (opts []udp.PacketFillerOption) {
@@ -130,5 +192,14 @@ func (o *udpCmdOpts) getUDPOptions() (opts []udp.PacketFillerOption) {
 	if len(o.udpPayload) > 0 {
 		opts = append(opts, udp.WithPayload(o.udpPayload))
 	}
+	probes := make(map[uint16][]byte)
+	for port, m := range o.matchers {
+		if len(m.Probe) > 0 {
+			probes[port] = m.Probe
+		}
+	}
+	if len(probes) > 0 {
+		opts = append(opts, udp.WithPortPayloads(probes))
+	}
 	return
 }
@@ -6,10 +6,13 @@ import (
 	"testing"
 	"time"
 
+	"github.com/google/gopacket"
 	"github.com/google/gopacket/layers"
 	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/require"
 	"github.com/v-byte-cpu/sx/pkg/scan"
+	"github.com/v-byte-cpu/sx/pkg/scan/icmp"
+	"github.com/v-byte-cpu/sx/pkg/scan/udp"
 )
 
 func TestUDPCmdDstSubnetError(t *testing.T) {
@@ -48,6 +51,7 @@ func TestUDPCmdOptsInitCliFlags(t *testing.T) {
 			"--gwmac 11:22:33:44:55:66 -f ip_file.jsonl -a arp.cache",
 			"-p 23-57,71-2733",
 			`--ttl 128 --ipproto 6 --iplen 11 --ipflags df,mf --payload \x01\x02\x03`,
+			"--match 53:dns,123:ntp --adaptive-pacing",
 		}, " "), " "))
 
 	require.NoError(t, err)
@@ -69,6 +73,8 @@ func TestUDPCmdOptsInitCliFlags(t *testing.T) {
 	require.Equal(t, uint16(11), opts.ipTotalLen)
 	require.Equal(t, "df,mf", opts.rawIPFlags)
 	require.Equal(t, `\x01\x02\x03`, opts.rawUDPPayload)
+	require.Equal(t, "53:dns,123:ntp", opts.rawMatchers)
+	require.Equal(t, true, opts.adaptivePacing)
 }
 
 func TestUDPCmdOptsParseRawOptions(t *testing.T) {
@@ -86,6 +92,7 @@ func TestUDPCmdOptsParseRawOptions(t *testing.T) {
 		},
 		rawIPFlags:    "df,mf",
 		rawUDPPayload: `\x01\x02\x03`,
+		rawMatchers:   "53:dns,5000:generic-nonempty",
 	}
 
 	err := opts.parseRawOptions()
@@ -102,4 +109,74 @@ func TestUDPCmdOptsParseRawOptions(t *testing.T) {
 
 	require.Equal(t, uint8(layers.IPv4DontFragment)|uint8(layers.IPv4MoreFragments), opts.ipFlags)
 	require.Equal(t, []byte{1, 2, 3}, opts.udpPayload)
+	require.Len(t, opts.matchers, 2)
+	require.Equal(t, "dns", opts.matchers[53].Name)
+	require.Equal(t, "generic-nonempty", opts.matchers[5000].Name)
+}
+
+func TestUDPCmdOptsParseRawOptionsFailOnOpen(t *testing.T) {
+	t.Parallel()
+	opts := &udpCmdOpts{exitCodeCmdOpts: exitCodeCmdOpts{failOnOpen: true}}
+	require.ErrorIs(t, opts.parseRawOptions(), errUDPOpen)
+
+	opts.rawMatchers = "53:dns"
+	require.NoError(t, opts.parseRawOptions())
+}
+
+func TestIsOpenUDPResult(t *testing.T) {
+	t.Parallel()
+	require.True(t, isOpenUDPResult(&udp.ScanResult{ScanType: udp.ScanType, IP: "192.168.0.1", Port: 53, Matcher: "dns"}))
+	require.False(t, isOpenUDPResult(&icmp.ScanResult{ScanType: udp.ScanType, IP: "192.168.0.1"}))
+}
+
+func TestParseUDPMatchersError(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name        string
+		rawMatchers string
+	}{
+		{
+			name:        "NoMatcher",
+			rawMatchers: "53",
+		},
+		{
+			name:        "InvalidPort",
+			rawMatchers: "65536:dns",
+		},
+		{
+			name:        "UnknownMatcher",
+			rawMatchers: "53:dns,69:tftp",
+		},
+	}
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := parseUDPMatchers(tt.rawMatchers)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestUDPCmdOptsGetUDPOptionsProbes(t *testing.T) {
+	t.Parallel()
+	matchers, err := parseUDPMatchers("53:dns,5000:generic-nonempty")
+	require.NoError(t, err)
+	opts := &udpCmdOpts{ipProtocol: uint8(layers.IPProtocolUDP), matchers: matchers, udpPayload: []byte{1}}
+
+	filler := udp.NewPacketFiller(opts.getUDPOptions()...)
+	for port, expected := range map[uint16][]byte{53: matchers[53].Probe, 5000: {1}} {
+		packet := gopacket.NewSerializeBuffer()
+		err := filler.Fill(packet, &scan.Request{
+			SrcIP:   net.IPv4(192, 168, 0, 3).To4(),
+			DstIP:   net.IPv4(192, 168, 0, 2).To4(),
+			SrcMAC:  net.HardwareAddr{0x1, 0x2, 0x3, 0x4, 0x5, 0x6},
+			DstMAC:  net.HardwareAddr{0x10, 0x11, 0x12, 0x13, 0x14, 0x15},
+			DstPort: port,
+		})
+		require.NoError(t, err)
+		udpLayer := gopacket.NewPacket(packet.Bytes(), layers.LayerTypeEthernet, gopacket.Default).Layer(layers.LayerTypeUDP)
+		require.NotNil(t, udpLayer)
+		require.Equal(t, expected, udpLayer.(*layers.UDP).Payload)
+	}
 }
@@ -0,0 +1,21 @@
+//go:build linux && uring
+// +build linux,uring
+
+package command
+
+import (
+	"os"
+
+	"github.com/v-byte-cpu/sx/pkg/packet/afpacket"
+	"github.com/v-byte-cpu/sx/pkg/uring"
+)
+
+// experimental io_uring backend replaces packet receiving and writing of scan results
+func init() {
+	newPacketSource = func(iface string, vpnMode bool) (packetSource, error) {
+		return afpacket.NewURingPacketSource(iface, vpnMode)
+	}
+	if w, err := uring.NewWriter(os.Stdout); err == nil {
+		resultWriter = w
+	}
+}
@@ -0,0 +1,74 @@
+package command
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/v-byte-cpu/sx/command/log"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+	"github.com/v-byte-cpu/sx/pkg/scan/vnc"
+)
+
+func newVNCCmd() *vncCmd {
+	c := &vncCmd{}
+
+	cmd := &cobra.Command{
+		Use: "vnc [flags] [subnet]",
+		Example: strings.Join([]string{
+			"vnc -p 5900 192.168.0.1/24", "vnc -p 5900-5910 10.0.0.1",
+			"vnc --json -p 5900,5901 10.0.0.1/16",
+			"vnc -f ip_ports_file.jsonl", "vnc -p 5900 -f ips_file.jsonl"}, "\n"),
+		Short: "Perform VNC version and security type scan",
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer cancel()
+
+			if err = c.opts.parseRawOptions(); err != nil {
+				return
+			}
+			scanRange, err := c.opts.parseScanRange(args)
+			if err != nil {
+				return
+			}
+
+			var logger log.Logger
+			if logger, err = c.opts.getLogger(vnc.ScanType, resultWriter); err != nil {
+				return
+			}
+
+			engine := c.opts.newVNCScanEngine(ctx)
+			return c.opts.runScanEngine(ctx, cmd, engine, scanRange, logger)
+		},
+	}
+
+	c.opts.initCliFlags(cmd)
+
+	c.cmd = cmd
+	return c
+}
+
+type vncCmd struct {
+	cmd  *cobra.Command
+	opts vncCmdOpts
+}
+
+type vncCmdOpts struct {
+	genericScanCmdOpts
+	timeout time.Duration
+}
+
+func (o *vncCmdOpts) initCliFlags(cmd *cobra.Command) {
+	o.genericScanCmdOpts.initCliFlags(cmd)
+	cmd.Flags().DurationVarP(&o.timeout, "timeout", "t", 2*time.Second, "set connect and data timeout")
+}
+
+func (o *vncCmdOpts) newVNCScanEngine(ctx context.Context) scan.EngineResulter {
+	return o.newScanEngine(ctx, vnc.NewScanner(
+		vnc.WithDialTimeout(o.timeout),
+		vnc.WithDataTimeout(o.timeout),
+	))
+}
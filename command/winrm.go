@@ -0,0 +1,98 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/v-byte-cpu/sx/command/log"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+	"github.com/v-byte-cpu/sx/pkg/scan/winrm"
+)
+
+const defaultWinRMPorts = "5985,5986"
+
+func newWinRMCmd() *winrmCmd {
+	c := &winrmCmd{}
+
+	cmd := &cobra.Command{
+		Use: "winrm [flags] [subnet]",
+		Example: strings.Join([]string{
+			"winrm 192.168.0.1/24", "winrm -p 5985 10.0.0.1",
+			"winrm --json --tls-ports 443 -p 80,443 10.0.0.1/16",
+			"winrm -f ip_ports_file.jsonl", "winrm -p 5985 -f ips_file.jsonl"}, "\n"),
+		Short: "Perform WinRM endpoint scan",
+		Long: strings.Join([]string{
+			"Perform WinRM endpoint scan.",
+			"The WS-Management Identify request is sent to the /wsman path of each target, ports 5985 and 5986 by default,",
+			"endpoints are reported with the protocol version, offered authentication schemes and whether",
+			"Basic authentication is allowed over unencrypted HTTP. Ports of the --tls-ports flag are requested over HTTPS."}, "\n"),
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer cancel()
+
+			if err = c.opts.parseRawOptions(); err != nil {
+				return
+			}
+			scanRange, err := c.opts.parseScanRange(args)
+			if err != nil {
+				return
+			}
+
+			var logger log.Logger
+			if logger, err = c.opts.getLogger(winrm.ScanType, resultWriter); err != nil {
+				return
+			}
+
+			engine := c.opts.newWinRMScanEngine(ctx)
+			return c.opts.runScanEngine(ctx, cmd, engine, scanRange, logger)
+		},
+	}
+
+	c.opts.initCliFlags(cmd)
+
+	c.cmd = cmd
+	return c
+}
+
+type winrmCmd struct {
+	cmd  *cobra.Command
+	opts winrmCmdOpts
+}
+
+type winrmCmdOpts struct {
+	genericScanCmdOpts
+	timeout     time.Duration
+	rawTLSPorts string
+
+	tlsPorts []uint16
+}
+
+func (o *winrmCmdOpts) initCliFlags(cmd *cobra.Command) {
+	o.genericScanCmdOpts.initCliFlags(cmd)
+	cmd.Flags().DurationVarP(&o.timeout, "timeout", "t", defaultTimeout, "set request timeout")
+	cmd.Flags().StringVar(&o.rawTLSPorts, "tls-ports", "5986", "set ports that are requested over HTTPS")
+}
+
+func (o *winrmCmdOpts) parseRawOptions() (err error) {
+	if err = o.genericScanCmdOpts.parseRawOptions(); err != nil {
+		return
+	}
+	if o.tlsPorts, err = parsePorts(o.rawTLSPorts); err != nil {
+		return fmt.Errorf("tls ports: %w", err)
+	}
+	// targets of the subnet argument are scanned on standard ports unless ports are set
+	if len(o.portRanges) == 0 && len(o.ipFile) == 0 && len(o.rawInput) == 0 {
+		o.portRanges, err = parsePortRanges(defaultWinRMPorts)
+	}
+	return
+}
+
+func (o *winrmCmdOpts) newWinRMScanEngine(ctx context.Context) scan.EngineResulter {
+	scanner := winrm.NewScanner(winrm.WithDataTimeout(o.timeout), winrm.WithTLSPorts(o.tlsPorts))
+	return o.newScanEngine(ctx, scanner)
+}
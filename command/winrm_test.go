@@ -0,0 +1,110 @@
+package command
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+func TestWinRMCmdDstSubnetError(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{
+			name: "RequiredArg",
+			args: nil,
+		},
+		{
+			name: "InvalidDstSubnet",
+			args: []string{"invalid_ip_address"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := newWinRMCmd().cmd
+			err := cmd.RunE(cmd, tt.args)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestWinRMCmdOptsInitCliFlags(t *testing.T) {
+	t.Parallel()
+	var opts winrmCmdOpts
+	cmd := &cobra.Command{}
+
+	opts.initCliFlags(cmd)
+	err := cmd.ParseFlags(strings.Split(
+		"--json -p 5985 -f ip_file.jsonl -w 300 --exit-delay 10s --timeout 3s --tls-ports 443", " "))
+
+	require.NoError(t, err)
+	require.Equal(t, true, opts.json)
+	require.Equal(t, "5985", opts.rawPortRanges)
+	require.Equal(t, "ip_file.jsonl", opts.ipFile)
+	require.Equal(t, 300, opts.workers)
+	require.Equal(t, 10*time.Second, opts.exitDelay)
+
+	require.Equal(t, 3*time.Second, opts.timeout)
+	require.Equal(t, "443", opts.rawTLSPorts)
+}
+
+func TestWinRMCmdOptsParseRawOptions(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name          string
+		opts          winrmCmdOpts
+		expectedPorts []*scan.PortRange
+		expectedTLS   []uint16
+	}{
+		{
+			name: "Ports",
+			opts: winrmCmdOpts{
+				genericScanCmdOpts: genericScanCmdOpts{rawPortRanges: "80,443-444", workers: 300},
+				rawTLSPorts:        "443-444",
+			},
+			expectedPorts: []*scan.PortRange{{StartPort: 80, EndPort: 80}, {StartPort: 443, EndPort: 444}},
+			expectedTLS:   []uint16{443, 444},
+		},
+		{
+			name: "DefaultPorts",
+			opts: winrmCmdOpts{
+				genericScanCmdOpts: genericScanCmdOpts{workers: 300},
+				rawTLSPorts:        "5986",
+			},
+			expectedPorts: []*scan.PortRange{{StartPort: 5985, EndPort: 5985}, {StartPort: 5986, EndPort: 5986}},
+			expectedTLS:   []uint16{5986},
+		},
+		{
+			name: "NoTLSPorts",
+			opts: winrmCmdOpts{
+				genericScanCmdOpts: genericScanCmdOpts{ipFile: "ip_ports_file.jsonl", workers: 300},
+			},
+		},
+	}
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			err := tt.opts.parseRawOptions()
+			require.NoError(t, err)
+			require.Equal(t, tt.expectedPorts, tt.opts.portRanges)
+			require.Equal(t, tt.expectedTLS, tt.opts.tlsPorts)
+		})
+	}
+}
+
+func TestWinRMCmdOptsParseRawOptionsInvalidTLSPorts(t *testing.T) {
+	t.Parallel()
+	opts := winrmCmdOpts{
+		genericScanCmdOpts: genericScanCmdOpts{workers: 300},
+		rawTLSPorts:        "5986-abc",
+	}
+
+	require.Error(t, opts.parseRawOptions())
+}
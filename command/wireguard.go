@@ -0,0 +1,143 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/v-byte-cpu/sx/command/log"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+	"github.com/v-byte-cpu/sx/pkg/scan/wireguard"
+)
+
+const defaultWireGuardPort = 51820
+
+func newWireGuardCmd() *wireguardCmd {
+	c := &wireguardCmd{}
+
+	cmd := &cobra.Command{
+		Use: "wireguard [flags] [subnet]",
+		Example: strings.Join([]string{
+			"wireguard 192.168.0.1/24", "wireguard -p 51820,51821 10.0.0.1/16",
+			"wireguard --public-key <server_public_key> --private-key-file peer.key 10.0.0.1",
+			"wireguard -f ip_ports_file.jsonl", "wireguard -p 51820 -f ips_file.jsonl"}, "\n"),
+		Short: "Perform WireGuard UDP endpoint scan",
+		Long: strings.Join([]string{
+			"Perform WireGuard UDP endpoint scan.",
+			"The handshake initiation is sent to each target over UDP, port 51820 by default,",
+			"each target is reported with the state by the answer:",
+			"  open|filtered - no answer, WireGuard servers silently drop initiations of unknown peers",
+			"  closed        - ICMP port unreachable, there is no WireGuard server",
+			"  unexpected    - another UDP service answered",
+			"  handshake     - the server answered the handshake initiation",
+			"Initiations are made with random keys by default, so WireGuard servers never answer them.",
+			"With the public key of the server and the private key of the peer the server answers",
+			"with the handshake response if it accepts the peer, it verifies the authorized endpoint."}, "\n"),
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer cancel()
+
+			if err = c.opts.parseRawOptions(); err != nil {
+				return
+			}
+			scanRange, err := c.opts.parseScanRange(args)
+			if err != nil {
+				return
+			}
+
+			var logger log.Logger
+			if logger, err = c.opts.getLogger(wireguard.ScanType, resultWriter); err != nil {
+				return
+			}
+
+			engine := c.opts.newWireGuardScanEngine(ctx)
+			return c.opts.runScanEngine(ctx, cmd, engine, scanRange, logger)
+		},
+	}
+
+	c.opts.initCliFlags(cmd)
+
+	c.cmd = cmd
+	return c
+}
+
+type wireguardCmd struct {
+	cmd  *cobra.Command
+	opts wireguardCmdOpts
+}
+
+type wireguardCmdOpts struct {
+	genericScanCmdOpts
+	timeout time.Duration
+	retries int
+
+	rawPublicKey   string
+	privateKeyFile string
+
+	privateKey *wireguard.Key
+	publicKey  *wireguard.Key
+}
+
+func (o *wireguardCmdOpts) initCliFlags(cmd *cobra.Command) {
+	o.genericScanCmdOpts.initCliFlags(cmd)
+	cmd.Flags().DurationVarP(&o.timeout, "timeout", "t", 1*time.Second, "set time to wait for a response to each request")
+	cmd.Flags().IntVar(&o.retries, "retries", 1, "set number of additional requests if there is no response")
+	cmd.Flags().StringVar(&o.rawPublicKey, "public-key", "", "set base64 public key of the server to send initiations with keys of the peer")
+	cmd.Flags().StringVar(&o.privateKeyFile, "private-key-file", "",
+		"set file with base64 private key of the peer to send initiations with keys of the peer")
+}
+
+func (o *wireguardCmdOpts) parseRawOptions() (err error) {
+	if err = o.genericScanCmdOpts.parseRawOptions(); err != nil {
+		return
+	}
+	if o.retries < 0 {
+		return errors.New("invalid retries: non-negative number required")
+	}
+	if err = o.parseKeys(); err != nil {
+		return
+	}
+	// targets of the subnet argument are scanned on the standard port unless ports are set
+	if len(o.portRanges) == 0 && len(o.ipFile) == 0 && len(o.rawInput) == 0 {
+		o.portRanges = []*scan.PortRange{{StartPort: defaultWireGuardPort, EndPort: defaultWireGuardPort}}
+	}
+	return
+}
+
+// parseKeys parses keys of the peer, the private key is read from the file so that it is not exposed in arguments
+func (o *wireguardCmdOpts) parseKeys() (err error) {
+	if len(o.rawPublicKey) == 0 && len(o.privateKeyFile) == 0 {
+		return
+	}
+	if len(o.rawPublicKey) == 0 || len(o.privateKeyFile) == 0 {
+		return errors.New("public-key and private-key-file options must be set together")
+	}
+	publicKey, err := wireguard.ParseKey(o.rawPublicKey)
+	if err != nil {
+		return errors.New("invalid public-key: base64 WireGuard key required")
+	}
+	data, err := os.ReadFile(o.privateKeyFile)
+	if err != nil {
+		return
+	}
+	privateKey, err := wireguard.ParseKey(strings.TrimSpace(string(data)))
+	if err != nil {
+		return errors.New("invalid private-key-file: base64 WireGuard key required")
+	}
+	o.publicKey = &publicKey
+	o.privateKey = &privateKey
+	return
+}
+
+func (o *wireguardCmdOpts) newWireGuardScanEngine(ctx context.Context) scan.EngineResulter {
+	opts := []wireguard.ScannerOption{wireguard.WithDataTimeout(o.timeout), wireguard.WithRetries(o.retries)}
+	if o.privateKey != nil {
+		opts = append(opts, wireguard.WithKeys(*o.privateKey, *o.publicKey))
+	}
+	scanner := wireguard.NewScanner(opts...)
+	return o.newScanEngine(ctx, scanner)
+}
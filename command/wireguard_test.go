@@ -0,0 +1,163 @@
+package command
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+	"github.com/v-byte-cpu/sx/pkg/scan/wireguard"
+)
+
+const (
+	testWireGuardPublicKey  = "HIgo9xNzJMWLKASShiTqIybxZ0U3wGLiUeJ1PKf8ykw="
+	testWireGuardPrivateKey = "yAnz5TF+lXXJte14tji3zlMNq+hd2rYUIgJBgB3fBmk="
+)
+
+func TestWireGuardCmdDstSubnetError(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{
+			name: "RequiredArg",
+			args: nil,
+		},
+		{
+			name: "InvalidDstSubnet",
+			args: []string{"invalid_ip_address"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := newWireGuardCmd().cmd
+			err := cmd.RunE(cmd, tt.args)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestWireGuardCmdOptsInitCliFlags(t *testing.T) {
+	t.Parallel()
+	var opts wireguardCmdOpts
+	cmd := &cobra.Command{}
+
+	opts.initCliFlags(cmd)
+	err := cmd.ParseFlags(strings.Split(
+		"--json -p 51820 -f ip_file.jsonl -w 300 --exit-delay 10s --timeout 3s --retries 2 "+
+			"--public-key "+testWireGuardPublicKey+" --private-key-file peer.key", " "))
+
+	require.NoError(t, err)
+	require.Equal(t, true, opts.json)
+	require.Equal(t, "51820", opts.rawPortRanges)
+	require.Equal(t, "ip_file.jsonl", opts.ipFile)
+	require.Equal(t, 300, opts.workers)
+	require.Equal(t, 10*time.Second, opts.exitDelay)
+
+	require.Equal(t, 3*time.Second, opts.timeout)
+	require.Equal(t, 2, opts.retries)
+	require.Equal(t, testWireGuardPublicKey, opts.rawPublicKey)
+	require.Equal(t, "peer.key", opts.privateKeyFile)
+}
+
+func TestWireGuardCmdOptsParseRawOptions(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		opts     wireguardCmdOpts
+		expected []*scan.PortRange
+	}{
+		{
+			name: "Ports",
+			opts: wireguardCmdOpts{
+				genericScanCmdOpts: genericScanCmdOpts{rawPortRanges: "51820,51821", workers: 300},
+			},
+			expected: []*scan.PortRange{{StartPort: 51820, EndPort: 51820}, {StartPort: 51821, EndPort: 51821}},
+		},
+		{
+			name: "DefaultPort",
+			opts: wireguardCmdOpts{
+				genericScanCmdOpts: genericScanCmdOpts{workers: 300},
+			},
+			expected: []*scan.PortRange{{StartPort: 51820, EndPort: 51820}},
+		},
+	}
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			err := tt.opts.parseRawOptions()
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, tt.opts.portRanges)
+			require.Nil(t, tt.opts.privateKey)
+			require.Nil(t, tt.opts.publicKey)
+		})
+	}
+}
+
+func TestWireGuardCmdOptsParseRawOptionsKeys(t *testing.T) {
+	t.Parallel()
+	keyFile := filepath.Join(t.TempDir(), "peer.key")
+	require.NoError(t, os.WriteFile(keyFile, []byte(testWireGuardPrivateKey+"\n"), 0o600))
+	opts := wireguardCmdOpts{
+		genericScanCmdOpts: genericScanCmdOpts{workers: 300},
+		rawPublicKey:       testWireGuardPublicKey,
+		privateKeyFile:     keyFile,
+	}
+
+	require.NoError(t, opts.parseRawOptions())
+	publicKey, err := wireguard.ParseKey(testWireGuardPublicKey)
+	require.NoError(t, err)
+	privateKey, err := wireguard.ParseKey(testWireGuardPrivateKey)
+	require.NoError(t, err)
+	require.Equal(t, &publicKey, opts.publicKey)
+	require.Equal(t, &privateKey, opts.privateKey)
+}
+
+func TestWireGuardCmdOptsParseRawOptionsInvalid(t *testing.T) {
+	t.Parallel()
+	keyFile := filepath.Join(t.TempDir(), "peer.key")
+	require.NoError(t, os.WriteFile(keyFile, []byte("invalid\n"), 0o600))
+	tests := []struct {
+		name string
+		opts wireguardCmdOpts
+	}{
+		{
+			name: "InvalidRetries",
+			opts: wireguardCmdOpts{retries: -1},
+		},
+		{
+			name: "PublicKeyOnly",
+			opts: wireguardCmdOpts{rawPublicKey: testWireGuardPublicKey},
+		},
+		{
+			name: "PrivateKeyFileOnly",
+			opts: wireguardCmdOpts{privateKeyFile: keyFile},
+		},
+		{
+			name: "InvalidPublicKey",
+			opts: wireguardCmdOpts{rawPublicKey: "invalid", privateKeyFile: keyFile},
+		},
+		{
+			name: "InvalidPrivateKey",
+			opts: wireguardCmdOpts{rawPublicKey: testWireGuardPublicKey, privateKeyFile: keyFile},
+		},
+		{
+			name: "MissingPrivateKeyFile",
+			opts: wireguardCmdOpts{rawPublicKey: testWireGuardPublicKey, privateKeyFile: "not_exists.key"},
+		},
+	}
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			tt.opts.workers = 300
+			require.Error(t, tt.opts.parseRawOptions())
+		})
+	}
+}
@@ -0,0 +1,255 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/v-byte-cpu/sx/pkg/workflow"
+)
+
+var errWorkflowFlag = errors.New("flag is not supported by the workflow command, set it in args of stages")
+
+// workflowOnlyStageFlags are global flags that can't be passed to all stages: results of each stage are
+// written to its result file and profiles and the errors file are written once per process
+var workflowOnlyStageFlags = map[string]bool{
+	"split-output": true,
+	"aggregator":   true,
+	"mtls-cert":    true,
+	"mtls-key":     true,
+	"mtls-ca":      true,
+	"errors-file":  true,
+	"pprof-addr":   true,
+	"cpuprofile":   true,
+	"memprofile":   true,
+}
+
+func newWorkflowCmd() *workflowCmd {
+	c := &workflowCmd{}
+
+	cmd := &cobra.Command{
+		Use:     "workflow [flags] workflow.yaml",
+		Example: strings.Join([]string{"workflow web.yaml", "workflow --dir results web.yaml"}, "\n"),
+		Short:   "Run the multi-stage scan workflow defined in the YAML file",
+		Long: strings.Join([]string{
+			"Run the multi-stage scan workflow defined in the YAML file.",
+			"Stages run one at a time in the same process, each stage runs one sx command with JSON output.",
+			"Results of earlier stages are passed to the command with the --file and --arp-cache flags,",
+			"optionally selected by their ports. The rate of the workflow is shared by all stages,",
+			"the built-in report stage merges results of its input stages into one file or stdout."}, "\n"),
+		Args: cobra.ExactArgs(1),
+		// each stage runs the persistent pre-run of the root command itself
+		PersistentPreRunE: func(*cobra.Command, []string) error { return nil },
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer cancel()
+
+			globalArgs, err := workflowGlobalArgs(cmd)
+			if err != nil {
+				return
+			}
+			wf, err := workflow.LoadFile(args[0])
+			if err != nil {
+				return
+			}
+			dir := c.dir
+			if len(dir) == 0 {
+				if dir, err = os.MkdirTemp("", "sx-workflow"); err != nil {
+					return
+				}
+				defer os.RemoveAll(dir)
+			} else if err = os.MkdirAll(dir, 0o755); err != nil {
+				return
+			}
+			cmd.SilenceUsage = true
+
+			r := &workflowRunner{
+				version:    cmd.Root().Version,
+				globalArgs: globalArgs,
+				dir:        dir,
+				rate:       wf.Rate,
+				stdout:     resultWriter,
+				results:    make(map[string]string),
+			}
+			for _, stage := range wf.Stages {
+				if err = ctx.Err(); err != nil {
+					return
+				}
+				if err = r.runStage(stage); err != nil {
+					return fmt.Errorf("stage %s: %w", stage.Name, err)
+				}
+			}
+			return
+		},
+	}
+
+	cmd.Flags().StringVar(&c.dir, "dir", "",
+		strings.Join([]string{"set directory to keep results of each stage in, e.g. results/ports.jsonl",
+			"results are written to the temporary directory removed after the run by default"}, "\n"))
+
+	c.cmd = cmd
+	return c
+}
+
+type workflowCmd struct {
+	cmd *cobra.Command
+	dir string
+}
+
+// workflowGlobalArgs returns global flags set on the workflow command, each stage runs with them,
+// since the root command of each stage starts with default values of its flags
+func workflowGlobalArgs(cmd *cobra.Command) (args []string, err error) {
+	cmd.InheritedFlags().VisitAll(func(f *pflag.Flag) {
+		if err != nil || !f.Changed {
+			return
+		}
+		if workflowOnlyStageFlags[f.Name] {
+			err = fmt.Errorf("--%s: %w", f.Name, errWorkflowFlag)
+			return
+		}
+		if v, ok := f.Value.(pflag.SliceValue); ok {
+			for _, elem := range v.GetSlice() {
+				args = append(args, "--"+f.Name+"="+elem)
+			}
+			return
+		}
+		args = append(args, "--"+f.Name+"="+f.Value.String())
+	})
+	return
+}
+
+// workflowRunner runs stages of the workflow and keeps paths of their result files
+type workflowRunner struct {
+	version string
+	// globalArgs are global flags of the workflow command passed to each stage
+	globalArgs []string
+	dir        string
+	rate       string
+	// stdout is the output of report stages without the output file
+	stdout io.Writer
+	// results are result files of stages that already ran
+	results map[string]string
+}
+
+func (r *workflowRunner) runStage(stage *workflow.Stage) (err error) {
+	if stage.IsReport() {
+		return r.writeReport(stage)
+	}
+	root := newRootCmd(r.version).cmd
+	root.SilenceErrors = true
+	root.SilenceUsage = true
+	args, err := r.stageArgs(root, stage)
+	if err != nil {
+		return
+	}
+
+	path := stage.Output
+	if len(path) == 0 {
+		path = filepath.Join(r.dir, stage.Name+".jsonl")
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+
+	fmt.Fprintf(os.Stderr, "stage %s: sx %s\n", stage.Name, strings.Join(args, " "))
+	// the stage publishes its own root options when it starts, options of the workflow are restored after it
+	prevWriter, prevOpts := resultWriter, rootOpts
+	resultWriter = f
+	defer func() {
+		resultWriter, rootOpts = prevWriter, prevOpts
+	}()
+	manifestArgs = args
+	root.SetArgs(args)
+	err = root.Execute()
+	// the manifest of each stage is written when it completes, the same file is overwritten by later stages
+	if manifestErr := writeManifest(err); manifestErr != nil {
+		fmt.Fprintln(os.Stderr, "Error: manifest:", manifestErr)
+	}
+	discardManifest()
+	if err != nil {
+		return
+	}
+	r.results[stage.Name] = path
+	return
+}
+
+// stageArgs returns command line arguments of the stage command, the shared rate goes before arguments
+// of the stage, so that the rate set by the stage overrides it
+func (r *workflowRunner) stageArgs(root *cobra.Command, stage *workflow.Stage) ([]string, error) {
+	path := stage.CommandPath()
+	cmd, _, err := root.Find(path)
+	if err != nil || cmd == root {
+		return nil, fmt.Errorf("unknown command %q", stage.Command)
+	}
+	args := append(append([]string(nil), path...), r.globalArgs...)
+	if cmd.Flags().Lookup("json") != nil {
+		args = append(args, "--json")
+	}
+	if len(r.rate) > 0 && cmd.Flags().Lookup("rate") != nil {
+		args = append(args, "--rate", r.rate)
+	}
+	args = append(args, stage.Args...)
+	if len(stage.Input) > 0 {
+		if cmd.Flags().Lookup("file") == nil {
+			return nil, fmt.Errorf("command %q doesn't read targets from files", stage.Command)
+		}
+		inputPath := filepath.Join(r.dir, stage.Name+"-input.jsonl")
+		if err = r.writeInput(inputPath, stage); err != nil {
+			return nil, err
+		}
+		args = append(args, "--file", inputPath)
+	}
+	if len(stage.ARPCache) > 0 {
+		if cmd.Flags().Lookup("arp-cache") == nil {
+			return nil, fmt.Errorf("command %q doesn't read the ARP cache", stage.Command)
+		}
+		args = append(args, "--arp-cache", r.results[stage.ARPCache])
+	}
+	return args, nil
+}
+
+// writeInput writes results of input stages selected by ports of the stage to the file
+func (r *workflowRunner) writeInput(path string, stage *workflow.Stage) (err error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return
+	}
+	defer func() {
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+	}()
+	return r.copyInput(f, stage)
+}
+
+// writeReport merges results of input stages selected by ports of the stage into the output file or stdout
+func (r *workflowRunner) writeReport(stage *workflow.Stage) (err error) {
+	if len(stage.Output) == 0 {
+		return r.copyInput(r.stdout, stage)
+	}
+	return r.writeInput(stage.Output, stage)
+}
+
+func (r *workflowRunner) copyInput(w io.Writer, stage *workflow.Stage) error {
+	for _, name := range stage.Input {
+		f, err := os.Open(r.results[name])
+		if err != nil {
+			return err
+		}
+		_, err = stage.CopyResults(w, f)
+		f.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,170 @@
+package command
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// startBannerServer starts the TCP server that sends the SSH greeting to each connection
+func startBannerServer(t *testing.T) uint16 {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			_, _ = conn.Write([]byte("SSH-2.0-OpenSSH_8.9\r\n"))
+			conn.Close()
+		}
+	}()
+	return uint16(ln.Addr().(*net.TCPAddr).Port)
+}
+
+func runWorkflow(t *testing.T, content string, args ...string) (string, error) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "workflow.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+	var out strings.Builder
+	prevWriter := resultWriter
+	resultWriter = &out
+	t.Cleanup(func() {
+		resultWriter = prevWriter
+	})
+	root := newRootCmd("test").cmd
+	root.SetArgs(append(append([]string{"workflow"}, args...), path))
+	root.SetOut(&strings.Builder{})
+	root.SetErr(&strings.Builder{})
+	err := root.Execute()
+	return out.String(), err
+}
+
+func TestWorkflowCmd(t *testing.T) {
+	setManifestState(t, nil)
+	port := startBannerServer(t)
+	dir := t.TempDir()
+	out, err := runWorkflow(t, fmt.Sprintf(`
+rate: 100/s
+stages:
+  - name: services
+    command: detect
+    args: [--exit-delay, 10ms, --manifest, %s, -p, "%d", 127.0.0.1]
+  - name: other
+    command: detect
+    args: [--exit-delay, 10ms]
+    input: [services]
+    ports: [1]
+  - name: report
+    command: report
+    input: [services, other]
+`, filepath.Join(dir, "manifest.json"), port), "--dir", dir)
+
+	require.NoError(t, err)
+	expected := fmt.Sprintf(`{"scan":"detect","ip":"127.0.0.1","port":%d,"service":"ssh","tls":false,"banner":"SSH-2.0-OpenSSH_8.9"}`+"\n", port)
+	require.Equal(t, expected, out)
+
+	data, err := os.ReadFile(filepath.Join(dir, "services.jsonl"))
+	require.NoError(t, err)
+	require.Equal(t, expected, string(data))
+	// no results of the first stage are selected by ports of the second one
+	data, err = os.ReadFile(filepath.Join(dir, "other-input.jsonl"))
+	require.NoError(t, err)
+	require.Empty(t, data)
+	// the manifest is written when the stage completes
+	data, err = os.ReadFile(filepath.Join(dir, "manifest.json"))
+	require.NoError(t, err)
+	require.Contains(t, string(data), `"command": "detect"`)
+}
+
+func TestWorkflowCmdOutput(t *testing.T) {
+	setManifestState(t, nil)
+	port := startBannerServer(t)
+	output := filepath.Join(t.TempDir(), "report.jsonl")
+	out, err := runWorkflow(t, fmt.Sprintf(`
+stages:
+  - command: detect
+    args: [--exit-delay, 10ms, -p, "%d", 127.0.0.1]
+  - command: report
+    input: [stage1]
+    ports: ["%d"]
+    output: %s
+`, port, port, output))
+
+	require.NoError(t, err)
+	require.Empty(t, out)
+	data, err := os.ReadFile(output)
+	require.NoError(t, err)
+	require.Contains(t, string(data), `"service":"ssh"`)
+}
+
+func TestWorkflowCmdGlobalFlags(t *testing.T) {
+	setManifestState(t, nil)
+	t.Cleanup(func() {
+		require.NoError(t, (&redactCmdOpts{}).initRedactor())
+	})
+	port := startBannerServer(t)
+	dir := t.TempDir()
+	manifestFile := filepath.Join(dir, "manifest.json")
+	out, err := runWorkflow(t, fmt.Sprintf(`
+stages:
+  - command: detect
+    args: [--exit-delay, 10ms, -p, "%d", 127.0.0.1]
+  - command: report
+    input: [stage1]
+`, port), "--redact-ip", "truncate", "--manifest", manifestFile, "--drop-fields", "tls,banner")
+
+	require.NoError(t, err)
+	// global flags of the workflow apply to the stage
+	require.Equal(t, fmt.Sprintf(`{"scan":"detect","ip":"127.0.0.0","port":%d,"service":"ssh"}`+"\n", port), out)
+	data, err := os.ReadFile(manifestFile)
+	require.NoError(t, err)
+	require.Contains(t, string(data), `"redact-ip": "truncate"`)
+}
+
+func TestWorkflowCmdUnsupportedFlag(t *testing.T) {
+	setManifestState(t, nil)
+	_, err := runWorkflow(t, "stages:\n  - command: vnc\n    args: [-p, '5900', 127.0.0.1]\n",
+		"--split-output", filepath.Join(t.TempDir(), "out"))
+	require.ErrorIs(t, err, errWorkflowFlag)
+}
+
+func TestWorkflowCmdError(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{
+			name:    "InvalidWorkflow",
+			content: "stages: []\n",
+		},
+		{
+			name:    "UnknownCommand",
+			content: "stages:\n  - command: unknown\n",
+		},
+		{
+			name:    "StageError",
+			content: "stages:\n  - command: vnc\n    args: [invalid_ip_address]\n",
+		},
+		{
+			name:    "CommandWithoutFile",
+			content: "stages:\n  - command: vnc\n    args: [-p, '5900', 127.0.0.1]\n  - command: rerun\n    input: [stage1]\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setManifestState(t, nil)
+			_, err := runWorkflow(t, tt.content)
+			require.Error(t, err)
+		})
+	}
+}
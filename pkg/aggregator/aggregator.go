@@ -0,0 +1,212 @@
+// Package aggregator receives scan results streamed by remote sx agents over mutual TLS
+package aggregator
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+const (
+	ScanType = "aggregator"
+
+	// maxEnvelopeSize is the maximum size of one line of the agent stream
+	maxEnvelopeSize = 1024 * 1024
+)
+
+var errInvalidEnvelope = errors.New("invalid result envelope")
+
+// Envelope is the message of one scan result sent by the agent, envelopes are sent as JSON lines
+type Envelope struct {
+	ScanType string `json:"scan"`
+	ID       string `json:"id"`
+	// Text is the result in plain text format
+	Text   string          `json:"text"`
+	Result json.RawMessage `json:"result"`
+}
+
+// Result is the scan result received from the agent
+type Result struct {
+	ScanType string
+	// Agent is the common name of the certificate of the agent
+	Agent string
+
+	id   string
+	text string
+	data json.RawMessage
+}
+
+// Assert that aggregator.Result conforms to the scan.Result interface
+var _ scan.Result = (*Result)(nil)
+
+func (r *Result) String() string {
+	return fmt.Sprintf("%s agent=%s", r.text, r.Agent)
+}
+
+// ID returns the id of the original result with its scan type, so that results of the same target
+// reported by several agents are deduplicated
+func (r *Result) ID() string {
+	return r.ScanType + " " + r.id
+}
+
+// MarshalJSON adds the agent to the "agent" field of the JSON object of the original result
+func (r *Result) MarshalJSON() ([]byte, error) {
+	data := r.data
+	if len(data) < 2 || data[0] != '{' || data[len(data)-1] != '}' {
+		return data, nil
+	}
+	agent, err := json.Marshal(r.Agent)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]byte, 0, len(data)+len(agent)+10)
+	result = append(result, data[:len(data)-1]...)
+	if len(data) > 2 {
+		result = append(result, ',')
+	}
+	result = append(result, `"agent":`...)
+	result = append(result, agent...)
+	return append(result, '}'), nil
+}
+
+// Server accepts connections of agents and merges their results into one channel
+type Server struct {
+	listener net.Listener
+	results  chan scan.Result
+	errc     chan error
+
+	mu    sync.Mutex
+	conns map[net.Conn]struct{}
+}
+
+// NewServer creates the server of the listener, the listener should require client certificates
+// of agents, e.g. with the config of ServerTLSConfig
+func NewServer(listener net.Listener) *Server {
+	return &Server{
+		listener: listener,
+		results:  make(chan scan.Result, 1000),
+		errc:     make(chan error, 100),
+		conns:    make(map[net.Conn]struct{}),
+	}
+}
+
+// Results returns the channel of results of all agents, it is closed when Serve returns
+func (s *Server) Results() <-chan scan.Result {
+	return s.results
+}
+
+// Errors returns the channel of errors of agent connections, it is closed when Serve returns
+func (s *Server) Errors() <-chan error {
+	return s.errc
+}
+
+// Serve accepts connections of agents until ctx is done
+func (s *Server) Serve(ctx context.Context) (err error) {
+	ctx, cancel := context.WithCancel(ctx)
+	var wg sync.WaitGroup
+	defer func() {
+		wg.Wait()
+		close(s.results)
+		close(s.errc)
+	}()
+	// connections are closed before the wait
+	defer cancel()
+	go func() {
+		<-ctx.Done()
+		s.listener.Close()
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		for conn := range s.conns {
+			conn.Close()
+		}
+	}()
+
+	for {
+		conn, aerr := s.listener.Accept()
+		if aerr != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return aerr
+		}
+		s.mu.Lock()
+		s.conns[conn] = struct{}{}
+		s.mu.Unlock()
+		// the connection accepted during the shutdown is missed by the closing goroutine
+		if ctx.Err() != nil {
+			conn.Close()
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.handle(ctx, conn)
+			s.mu.Lock()
+			delete(s.conns, conn)
+			s.mu.Unlock()
+		}()
+	}
+}
+
+// handle reads results of the agent connection until it is closed
+func (s *Server) handle(ctx context.Context, conn net.Conn) {
+	defer conn.Close()
+	agent, err := agentName(ctx, conn)
+	if err != nil {
+		s.error(ctx, fmt.Errorf("agent %s: %w", conn.RemoteAddr(), err))
+		return
+	}
+	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxEnvelopeSize)
+	for scanner.Scan() {
+		// empty lines keep idle connections alive
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var env Envelope
+		if err := json.Unmarshal(scanner.Bytes(), &env); err != nil || len(env.Result) == 0 {
+			s.error(ctx, fmt.Errorf("agent %s: %w", agent, errInvalidEnvelope))
+			continue
+		}
+		result := &Result{ScanType: env.ScanType, Agent: agent, id: env.ID, text: env.Text, data: env.Result}
+		select {
+		case <-ctx.Done():
+			return
+		case s.results <- result:
+		}
+	}
+	if err := scanner.Err(); err != nil && ctx.Err() == nil {
+		s.error(ctx, fmt.Errorf("agent %s: %w", agent, err))
+	}
+}
+
+func (s *Server) error(ctx context.Context, err error) {
+	select {
+	case <-ctx.Done():
+	case s.errc <- err:
+	}
+}
+
+// agentName completes the TLS handshake and returns the common name of the agent certificate,
+// the remote address is the name of agents of plain connections and certificates without the name
+func agentName(ctx context.Context, conn net.Conn) (string, error) {
+	tlsConn, ok := conn.(*tls.Conn)
+	if !ok {
+		return conn.RemoteAddr().String(), nil
+	}
+	if err := tlsConn.HandshakeContext(ctx); err != nil {
+		return "", err
+	}
+	certs := tlsConn.ConnectionState().PeerCertificates
+	if len(certs) == 0 || len(certs[0].Subject.CommonName) == 0 {
+		return conn.RemoteAddr().String(), nil
+	}
+	return certs[0].Subject.CommonName, nil
+}
@@ -0,0 +1,250 @@
+package aggregator
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	dir  string
+}
+
+// newTestCA creates the CA and writes its certificate to ca.pem of the directory
+func newTestCA(t *testing.T, dir string) *testCA {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "sx test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	writePEM(t, filepath.Join(dir, "ca.pem"), "CERTIFICATE", der)
+	return &testCA{cert: cert, key: key, dir: dir}
+}
+
+// issue writes the certificate and the key of the name signed by the CA to name.pem and name.key
+func (ca *testCA) issue(t *testing.T, name string, serial int64) (certFile, keyFile string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	certFile, keyFile = filepath.Join(ca.dir, name+".pem"), filepath.Join(ca.dir, name+".key")
+	writePEM(t, certFile, "CERTIFICATE", der)
+	writePEM(t, keyFile, "EC PRIVATE KEY", keyDER)
+	return
+}
+
+func writePEM(t *testing.T, path, blockType string, der []byte) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600))
+}
+
+// startServer starts the aggregator with the certificate of the CA and returns its address
+func startServer(ctx context.Context, t *testing.T, ca *testCA) (*Server, string) {
+	t.Helper()
+	certFile, keyFile := ca.issue(t, "aggregator", 2)
+	conf, err := ServerTLSConfig(certFile, keyFile, filepath.Join(ca.dir, "ca.pem"))
+	require.NoError(t, err)
+	ln, err := tls.Listen("tcp", "127.0.0.1:0", conf)
+	require.NoError(t, err)
+	srv := NewServer(ln)
+	done := make(chan error, 1)
+	go func() {
+		done <- srv.Serve(ctx)
+	}()
+	t.Cleanup(func() {
+		require.NoError(t, <-done)
+	})
+	return srv, ln.Addr().String()
+}
+
+func dialAgent(t *testing.T, ca *testCA, name, addr string) (*tls.Conn, error) {
+	t.Helper()
+	certFile, keyFile := ca.issue(t, name, 3)
+	conf, err := ClientTLSConfig(certFile, keyFile, filepath.Join(ca.dir, "ca.pem"))
+	require.NoError(t, err)
+	conn, err := tls.Dial("tcp", addr, conf)
+	if err != nil {
+		return nil, err
+	}
+	// the server verifies the client certificate after the client completes its part of the handshake
+	if _, err = conn.Write([]byte("\n")); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return conn, nil
+}
+
+func TestServer(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+	ca := newTestCA(t, t.TempDir())
+	srv, addr := startServer(ctx, t, ca)
+	defer cancel()
+
+	for _, agent := range []string{"dmz", "office"} {
+		conn, err := dialAgent(t, ca, agent, addr)
+		require.NoError(t, err)
+		defer conn.Close()
+		_, err = fmt.Fprintf(conn, "%s\n", `{"scan":"tcpsyn","id":"10.0.0.1:22",`+
+			`"text":"10.0.0.1 22","result":{"scan":"tcpsyn","ip":"10.0.0.1","port":22}}`)
+		require.NoError(t, err)
+	}
+
+	agents := make(map[string]bool)
+	for len(agents) < 2 {
+		select {
+		case result := <-srv.Results():
+			r := result.(*Result)
+			require.Equal(t, "tcpsyn", r.ScanType)
+			require.Equal(t, "tcpsyn 10.0.0.1:22", r.ID())
+			data, err := r.MarshalJSON()
+			require.NoError(t, err)
+			require.Equal(t, fmt.Sprintf(`{"scan":"tcpsyn","ip":"10.0.0.1","port":22,"agent":%q}`, r.Agent), string(data))
+			agents[r.Agent] = true
+		case err := <-srv.Errors():
+			require.NoError(t, err)
+		case <-time.After(5 * time.Second):
+			require.FailNow(t, "timeout")
+		}
+	}
+	require.Equal(t, map[string]bool{"dmz": true, "office": true}, agents)
+}
+
+func TestServerInvalidEnvelope(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+	ca := newTestCA(t, t.TempDir())
+	srv, addr := startServer(ctx, t, ca)
+	defer cancel()
+
+	conn, err := dialAgent(t, ca, "dmz", addr)
+	require.NoError(t, err)
+	defer conn.Close()
+	_, err = conn.Write([]byte("not json\n"))
+	require.NoError(t, err)
+
+	select {
+	case err := <-srv.Errors():
+		require.ErrorIs(t, err, errInvalidEnvelope)
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "timeout")
+	}
+}
+
+func TestServerUntrustedAgent(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+	ca := newTestCA(t, t.TempDir())
+	srv, addr := startServer(ctx, t, ca)
+	defer cancel()
+
+	// the agent certificate is signed by another CA that trusts the aggregator CA
+	other := newTestCA(t, t.TempDir())
+	certFile, keyFile := other.issue(t, "rogue", 2)
+	conf, err := ClientTLSConfig(certFile, keyFile, filepath.Join(ca.dir, "ca.pem"))
+	require.NoError(t, err)
+	conn, err := tls.Dial("tcp", addr, conf)
+	if err == nil {
+		defer conn.Close()
+		_, _ = conn.Write([]byte(`{"scan":"tcpsyn","id":"1","text":"1","result":{}}` + "\n"))
+	}
+
+	select {
+	case err := <-srv.Errors():
+		require.Error(t, err)
+	case result := <-srv.Results():
+		require.FailNow(t, "unexpected result", "%v", result)
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "timeout")
+	}
+}
+
+func TestServerShutdown(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+	ca := newTestCA(t, t.TempDir())
+	srv, addr := startServer(ctx, t, ca)
+
+	conn, err := dialAgent(t, ca, "dmz", addr)
+	require.NoError(t, err)
+	defer conn.Close()
+	cancel()
+
+	// channels are closed after connections of agents are closed
+	for range srv.Results() {
+	}
+	for range srv.Errors() {
+	}
+}
+
+func TestLoadCertsError(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	ca := newTestCA(t, dir)
+	certFile, keyFile := ca.issue(t, "agent", 2)
+
+	_, err := ClientTLSConfig(certFile, keyFile, filepath.Join(dir, "missing.pem"))
+	require.Error(t, err)
+	_, err = ServerTLSConfig(certFile, filepath.Join(dir, "missing.key"), filepath.Join(dir, "ca.pem"))
+	require.Error(t, err)
+	// the key is not a certificate
+	_, err = ServerTLSConfig(certFile, keyFile, keyFile)
+	require.ErrorIs(t, err, errNoCACerts)
+}
+
+func TestResult(t *testing.T) {
+	t.Parallel()
+	result := &Result{ScanType: "tcpsyn", Agent: "dmz", id: "10.0.0.1:22", text: "10.0.0.1 22",
+		data: []byte(`{"scan":"tcpsyn","ip":"10.0.0.1","port":22}`)}
+	require.Equal(t, "10.0.0.1 22 agent=dmz", result.String())
+	require.Equal(t, "tcpsyn 10.0.0.1:22", result.ID())
+
+	empty := &Result{Agent: "dmz", data: []byte(`{}`)}
+	data, err := empty.MarshalJSON()
+	require.NoError(t, err)
+	require.Equal(t, `{"agent":"dmz"}`, string(data))
+
+	array := &Result{Agent: "dmz", data: []byte(`[1,2]`)}
+	data, err = array.MarshalJSON()
+	require.NoError(t, err)
+	require.Equal(t, `[1,2]`, string(data))
+}
@@ -0,0 +1,52 @@
+package aggregator
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"os"
+)
+
+var errNoCACerts = errors.New("no CA certificates found")
+
+// ServerTLSConfig returns the config of the aggregator, agents must present certificates signed by the CA
+func ServerTLSConfig(certFile, keyFile, caFile string) (*tls.Config, error) {
+	cert, pool, err := loadCerts(certFile, keyFile, caFile)
+	if err != nil {
+		return nil, err
+	}
+	return &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		ClientCAs:    pool,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		MinVersion:   tls.VersionTLS12,
+	}, nil
+}
+
+// ClientTLSConfig returns the config of agents, the aggregator must present the certificate signed by the CA
+func ClientTLSConfig(certFile, keyFile, caFile string) (*tls.Config, error) {
+	cert, pool, err := loadCerts(certFile, keyFile, caFile)
+	if err != nil {
+		return nil, err
+	}
+	return &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		RootCAs:      pool,
+		MinVersion:   tls.VersionTLS12,
+	}, nil
+}
+
+func loadCerts(certFile, keyFile, caFile string) (cert tls.Certificate, pool *x509.CertPool, err error) {
+	if cert, err = tls.LoadX509KeyPair(certFile, keyFile); err != nil {
+		return
+	}
+	data, err := os.ReadFile(caFile)
+	if err != nil {
+		return
+	}
+	pool = x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		err = errNoCACerts
+	}
+	return
+}
@@ -0,0 +1,169 @@
+package alert
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+const (
+	defaultQueueSize     = 1000
+	defaultActionTimeout = 10 * time.Second
+)
+
+var errQueueFull = errors.New("alert queue is full, alert dropped")
+
+// Alert is the notification about the scan result that matched the rule
+type Alert struct {
+	Rule   string      `json:"rule"`
+	Time   time.Time   `json:"time"`
+	Target string      `json:"target"`
+	Result scan.Result `json:"result"`
+
+	rule *Rule
+}
+
+// Engine evaluates alert rules on scan results and runs actions of matched rules in the background,
+// so that slow actions don't delay the scan
+type Engine struct {
+	rules         []*Rule
+	onError       func(error)
+	actionTimeout time.Duration
+	now           func() time.Time
+
+	mu   sync.Mutex
+	seen map[string]bool
+
+	alerts    chan *Alert
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+type EngineOption func(*Engine)
+
+// WithErrorHandler sets the function called with errors of actions, errors are ignored by default
+func WithErrorHandler(onError func(error)) EngineOption {
+	return func(e *Engine) {
+		e.onError = onError
+	}
+}
+
+func WithActionTimeout(timeout time.Duration) EngineOption {
+	return func(e *Engine) {
+		e.actionTimeout = timeout
+	}
+}
+
+func NewEngine(c *Config, opts ...EngineOption) *Engine {
+	e := &Engine{
+		rules:         c.Rules,
+		onError:       func(error) {},
+		actionTimeout: defaultActionTimeout,
+		now:           time.Now,
+		seen:          make(map[string]bool),
+		alerts:        make(chan *Alert, defaultQueueSize),
+		done:          make(chan struct{}),
+	}
+	for _, o := range opts {
+		o(e)
+	}
+	go e.run()
+	return e
+}
+
+// Evaluate matches the scan result against all rules and queues alerts of matched rules.
+// It always returns true to be used as a filter of logged results.
+func (e *Engine) Evaluate(result scan.Result) bool {
+	target := scan.UnwrapResult(result).ID()
+	e.mu.Lock()
+	isNew := !e.seen[target]
+	e.seen[target] = true
+	e.mu.Unlock()
+
+	ip, port, hasAddr := parseHostPort(target)
+	meta := resultMeta(result)
+	for _, rule := range e.rules {
+		if rule.New && !isNew {
+			continue
+		}
+		if (len(rule.Hosts) > 0 || len(rule.Ports) > 0) && !hasAddr {
+			continue
+		}
+		if hasAddr && (!rule.matchHost(ip) || !rule.matchPort(port)) {
+			continue
+		}
+		if !rule.matchMeta(meta) {
+			continue
+		}
+		alert := &Alert{Rule: rule.Name, Time: e.now(), Target: target, Result: result, rule: rule}
+		select {
+		case e.alerts <- alert:
+		default:
+			e.onError(fmt.Errorf("%w: rule %s target %s", errQueueFull, rule.Name, target))
+		}
+	}
+	return true
+}
+
+// Close waits for actions of queued alerts to finish, results must not be evaluated after Close
+func (e *Engine) Close() {
+	e.closeOnce.Do(func() {
+		close(e.alerts)
+	})
+	<-e.done
+}
+
+func (e *Engine) run() {
+	defer close(e.done)
+	for alert := range e.alerts {
+		for _, action := range alert.rule.Actions {
+			if err := e.notify(action.notifier, alert); err != nil {
+				e.onError(fmt.Errorf("alert rule %s: %w", alert.Rule, err))
+			}
+		}
+	}
+}
+
+func (e *Engine) notify(n notifier, alert *Alert) error {
+	ctx, cancel := context.WithTimeout(context.Background(), e.actionTimeout)
+	defer cancel()
+	return n.notify(ctx, alert)
+}
+
+// resultMeta returns metadata of the first MetaResult wrapper of the result
+func resultMeta(result scan.Result) map[string]interface{} {
+	for {
+		if r, ok := result.(*scan.MetaResult); ok {
+			return r.Meta
+		}
+		wrapper, ok := result.(interface{ Unwrap() scan.Result })
+		if !ok {
+			return nil
+		}
+		result = wrapper.Unwrap()
+	}
+}
+
+// parseHostPort parses result identifier in the form ip:port
+func parseHostPort(id string) (net.IP, uint16, bool) {
+	i := strings.LastIndexByte(id, ':')
+	if i < 0 {
+		return nil, 0, false
+	}
+	ip := net.ParseIP(strings.Trim(id[:i], "[]"))
+	if ip == nil {
+		return nil, 0, false
+	}
+	port, err := strconv.ParseUint(id[i+1:], 10, 16)
+	if err != nil {
+		return nil, 0, false
+	}
+	return ip, uint16(port), true
+}
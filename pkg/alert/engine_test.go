@@ -0,0 +1,222 @@
+package alert
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/v-byte-cpu/sx/pkg/policy"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+	"github.com/v-byte-cpu/sx/pkg/scan/arp"
+	"github.com/v-byte-cpu/sx/pkg/scan/tcp"
+)
+
+type recordNotifier struct {
+	mu     sync.Mutex
+	alerts []*Alert
+	err    error
+}
+
+func (n *recordNotifier) notify(_ context.Context, alert *Alert) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.alerts = append(n.alerts, alert)
+	return n.err
+}
+
+func (n *recordNotifier) targets() (targets []string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	for _, alert := range n.alerts {
+		targets = append(targets, alert.Rule+" "+alert.Target)
+	}
+	return
+}
+
+func newTestRule(name string, n notifier) *Rule {
+	return &Rule{Name: name, Actions: []*Action{{notifier: n}}}
+}
+
+func TestEngineEvaluate(t *testing.T) {
+	t.Parallel()
+	n := &recordNotifier{}
+	telnet := newTestRule("telnet", n)
+	telnet.Ports = []policy.Ports{{PortRange: scan.PortRange{StartPort: 23, EndPort: 23}}}
+	lan := newTestRule("lan", n)
+	_, subnet, err := net.ParseCIDR("10.0.0.0/8")
+	require.NoError(t, err)
+	lan.Hosts, lan.subnets = []string{subnet.String()}, []*net.IPNet{subnet}
+	prod := newTestRule("prod", n)
+	prod.Meta = map[string]string{"tags.env": "prod"}
+	prod.New = true
+
+	e := NewEngine(&Config{Rules: []*Rule{telnet, lan, prod}})
+	prodMeta := map[string]interface{}{"tags": map[string]string{"env": "prod"}}
+	results := []scan.Result{
+		&tcp.ScanResult{ScanType: tcp.SYNScanType, IP: "192.168.0.1", Port: 23},
+		&tcp.ScanResult{ScanType: tcp.SYNScanType, IP: "10.0.0.1", Port: 22},
+		&scan.MetaResult{Result: &tcp.ScanResult{ScanType: tcp.SYNScanType, IP: "192.168.0.2", Port: 443}, Meta: prodMeta},
+		// the target is not new anymore
+		&scan.MetaResult{Result: &tcp.ScanResult{ScanType: tcp.SYNScanType, IP: "192.168.0.2", Port: 443}, Meta: prodMeta},
+		// results without ip:port identifier match only rules without hosts and ports
+		&arp.ScanResult{IP: "10.0.0.5"},
+	}
+	for _, result := range results {
+		require.True(t, e.Evaluate(result))
+	}
+	e.Close()
+
+	require.Equal(t, []string{"telnet 192.168.0.1:23", "lan 10.0.0.1:22", "prod 192.168.0.2:443"}, n.targets())
+	require.Equal(t, results[2], n.alerts[2].Result)
+}
+
+func TestEngineActionError(t *testing.T) {
+	t.Parallel()
+	n := &recordNotifier{err: errors.New("failed")}
+	var errs []error
+	e := NewEngine(&Config{Rules: []*Rule{newTestRule("all", n)}}, WithErrorHandler(func(err error) {
+		errs = append(errs, err)
+	}))
+	e.Evaluate(&tcp.ScanResult{ScanType: tcp.SYNScanType, IP: "192.168.0.1", Port: 23})
+	e.Close()
+
+	require.Len(t, errs, 1)
+	require.ErrorIs(t, errs[0], n.err)
+	require.Contains(t, errs[0].Error(), "all")
+}
+
+func TestEngineQueueFull(t *testing.T) {
+	t.Parallel()
+	n := &blockNotifier{release: make(chan struct{})}
+	var mu sync.Mutex
+	var errs []error
+	e := NewEngine(&Config{Rules: []*Rule{newTestRule("all", n)}}, WithErrorHandler(func(err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		errs = append(errs, err)
+	}))
+	// the first alert is taken by the blocked action, others fill the queue
+	for i := 0; i < defaultQueueSize+2; i++ {
+		e.Evaluate(&tcp.ScanResult{ScanType: tcp.SYNScanType, IP: "192.168.0.1", Port: uint16(i + 1)})
+	}
+	close(n.release)
+	e.Close()
+
+	require.NotEmpty(t, errs)
+	require.ErrorIs(t, errs[0], errQueueFull)
+}
+
+type blockNotifier struct {
+	release chan struct{}
+}
+
+func (n *blockNotifier) notify(ctx context.Context, _ *Alert) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-n.release:
+		return nil
+	}
+}
+
+func TestWebhookNotifier(t *testing.T) {
+	t.Parallel()
+	bodies := make(chan map[string]interface{}, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		if r.Method == http.MethodPost && r.Header.Get("Content-Type") == "application/json" {
+			_ = json.NewDecoder(r.Body).Decode(&body)
+		}
+		bodies <- body
+	}))
+	defer srv.Close()
+
+	n, err := newWebhookNotifier(srv.URL)
+	require.NoError(t, err)
+	alert := &Alert{Rule: "telnet", Target: "10.0.0.1:23",
+		Result: &tcp.ScanResult{ScanType: tcp.SYNScanType, IP: "10.0.0.1", Port: 23}}
+	require.NoError(t, n.notify(context.Background(), alert))
+
+	body := <-bodies
+	require.Equal(t, "telnet", body["rule"])
+	require.Equal(t, "10.0.0.1:23", body["target"])
+	require.Equal(t, map[string]interface{}{"scan": tcp.SYNScanType, "ip": "10.0.0.1", "port": float64(23)}, body["result"])
+}
+
+func TestWebhookNotifierStatusError(t *testing.T) {
+	t.Parallel()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	n, err := newWebhookNotifier(srv.URL)
+	require.NoError(t, err)
+	err = n.notify(context.Background(), &Alert{Rule: "telnet"})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "500")
+}
+
+func TestExecNotifier(t *testing.T) {
+	t.Parallel()
+	out := filepath.Join(t.TempDir(), "alert")
+	n := &execNotifier{args: []string{"sh", "-c", `echo "$SX_ALERT_RULE $SX_ALERT_TARGET" > "$0"; cat >> "$0"`, out}}
+	alert := &Alert{Rule: "telnet", Target: "10.0.0.1:23"}
+	require.NoError(t, n.notify(context.Background(), alert))
+
+	data, err := os.ReadFile(out)
+	require.NoError(t, err)
+	line, body, _ := strings.Cut(string(data), "\n")
+	require.Equal(t, "telnet 10.0.0.1:23", line)
+	require.Contains(t, body, `"rule":"telnet"`)
+}
+
+func TestExecNotifierError(t *testing.T) {
+	t.Parallel()
+	n := &execNotifier{args: []string{"sh", "-c", "echo broken >&2; exit 3"}}
+	err := n.notify(context.Background(), &Alert{Rule: "telnet"})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "broken")
+}
+
+func TestSyslogNotifier(t *testing.T) {
+	t.Parallel()
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer conn.Close()
+
+	n, err := newSyslogNotifier("udp://" + conn.LocalAddr().String())
+	require.NoError(t, err)
+	require.NoError(t, n.notify(context.Background(), &Alert{Rule: "telnet", Target: "10.0.0.1:23"}))
+
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	buf := make([]byte, 2048)
+	size, _, err := conn.ReadFrom(buf)
+	require.NoError(t, err)
+	msg := string(buf[:size])
+	require.Contains(t, msg, syslogTag)
+	require.Contains(t, msg, `"target":"10.0.0.1:23"`)
+}
+
+func TestParseHostPort(t *testing.T) {
+	t.Parallel()
+	ip, port, ok := parseHostPort("[2001:db8::1]:443")
+	require.True(t, ok)
+	require.Equal(t, net.ParseIP("2001:db8::1"), ip)
+	require.Equal(t, uint16(443), port)
+
+	for _, id := range []string{"10.0.0.1", "host:80", "10.0.0.1:http", "10.0.0.1:70000"} {
+		_, _, ok = parseHostPort(id)
+		require.False(t, ok, id)
+	}
+}
@@ -0,0 +1,68 @@
+package alert
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// notifier runs the action of the rule for the alert
+type notifier interface {
+	notify(ctx context.Context, alert *Alert) error
+}
+
+type webhookNotifier struct {
+	url string
+}
+
+// notify posts the alert in JSON format, any status other than 2xx is an error
+func (n *webhookNotifier) notify(ctx context.Context, alert *Alert) error {
+	data, err := json.Marshal(alert)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook %s: unexpected status %s", n.url, resp.Status)
+	}
+	return nil
+}
+
+type execNotifier struct {
+	args []string
+}
+
+// notify runs the command with the alert in JSON format on stdin,
+// the rule and the target are also passed in SX_ALERT_RULE and SX_ALERT_TARGET environment variables
+func (n *execNotifier) notify(ctx context.Context, alert *Alert) error {
+	data, err := json.Marshal(alert)
+	if err != nil {
+		return err
+	}
+	cmd := exec.CommandContext(ctx, n.args[0], n.args[1:]...)
+	cmd.Env = append(os.Environ(), "SX_ALERT_RULE="+alert.Rule, "SX_ALERT_TARGET="+alert.Target)
+	cmd.Stdin = bytes.NewReader(data)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err = cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); len(msg) > 0 {
+			return fmt.Errorf("exec %s: %w: %s", n.args[0], err, msg)
+		}
+		return fmt.Errorf("exec %s: %w", n.args[0], err)
+	}
+	return nil
+}
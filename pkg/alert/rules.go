@@ -0,0 +1,191 @@
+package alert
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/v-byte-cpu/sx/pkg/policy"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	ErrNoRules = errors.New("invalid alert rules: at least one rule required")
+
+	errRuleActions = errors.New("invalid alert rule: at least one action required")
+	errAction      = errors.New("invalid alert action: exactly one of webhook, exec, syslog required")
+)
+
+// Config is a set of alert rules evaluated on every scan result
+type Config struct {
+	Rules []*Rule `yaml:"rules"`
+}
+
+// Rule matches scan results and runs actions for each match, all conditions of the rule must hold
+type Rule struct {
+	Name string `yaml:"name"`
+	// Hosts are IP addresses or subnets in CIDR notation, any host matches if empty
+	Hosts []string `yaml:"hosts"`
+	// Ports are ports or port ranges, any port matches if empty
+	Ports []policy.Ports `yaml:"ports"`
+	// Meta are values of metadata fields of the result, nested fields are separated by dots, e.g. tags.env
+	Meta map[string]string `yaml:"meta"`
+	// New matches only the first result of the target since the scan started
+	New     bool      `yaml:"new"`
+	Actions []*Action `yaml:"actions"`
+
+	subnets []*net.IPNet
+}
+
+// Action is a notification about the matched result
+type Action struct {
+	// Webhook is the URL the alert is posted to in JSON format
+	Webhook string `yaml:"webhook"`
+	// Exec is the command with arguments that reads the alert in JSON format from stdin
+	Exec []string `yaml:"exec"`
+	// Syslog is "local" for the local syslog daemon or the address of the remote one, e.g. udp://10.0.0.1:514
+	Syslog string `yaml:"syslog"`
+
+	notifier notifier
+}
+
+// LoadFile reads alert rules from the YAML file
+func LoadFile(path string) (*Config, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Parse(f)
+}
+
+// Parse reads alert rules in YAML format, e.g.
+//
+//	rules:
+//	  - name: telnet-anywhere
+//	    ports: [23]
+//	    actions:
+//	      - syslog: local
+//	  - name: new-prod-port
+//	    meta: {tags.env: prod}
+//	    new: true
+//	    actions:
+//	      - webhook: https://hooks.example.com/sx
+//	      - exec: [/usr/local/bin/notify, --urgent]
+func Parse(r io.Reader) (*Config, error) {
+	var c Config
+	dec := yaml.NewDecoder(r)
+	dec.KnownFields(true)
+	if err := dec.Decode(&c); err != nil {
+		return nil, fmt.Errorf("invalid alert rules: %w", err)
+	}
+	if len(c.Rules) == 0 {
+		return nil, ErrNoRules
+	}
+	for i, rule := range c.Rules {
+		if len(rule.Name) == 0 {
+			rule.Name = fmt.Sprintf("rule%d", i+1)
+		}
+		if err := rule.parse(); err != nil {
+			return nil, err
+		}
+	}
+	return &c, nil
+}
+
+func (r *Rule) parse() error {
+	for _, host := range r.Hosts {
+		subnet, err := policy.ParseSubnet(host)
+		if err != nil {
+			return fmt.Errorf("invalid alert rule %s: %w", r.Name, err)
+		}
+		r.subnets = append(r.subnets, subnet)
+	}
+	if len(r.Actions) == 0 {
+		return fmt.Errorf("%w: %s", errRuleActions, r.Name)
+	}
+	for _, action := range r.Actions {
+		if err := action.parse(); err != nil {
+			return fmt.Errorf("invalid alert rule %s: %w", r.Name, err)
+		}
+	}
+	return nil
+}
+
+func (a *Action) parse() (err error) {
+	var count int
+	if len(a.Webhook) > 0 {
+		count++
+		a.notifier, err = newWebhookNotifier(a.Webhook)
+	}
+	if len(a.Exec) > 0 {
+		count++
+		a.notifier = &execNotifier{args: a.Exec}
+	}
+	if len(a.Syslog) > 0 {
+		count++
+		a.notifier, err = newSyslogNotifier(a.Syslog)
+	}
+	if count != 1 {
+		return errAction
+	}
+	return
+}
+
+func newWebhookNotifier(rawURL string) (notifier, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("invalid webhook URL %q: http or https scheme required", rawURL)
+	}
+	return &webhookNotifier{url: u.String()}, nil
+}
+
+// parseSyslogAddr parses the syslog address in the form network://host:port,
+// empty network and address are returned for the local syslog daemon
+func parseSyslogAddr(addr string) (network, raddr string, err error) {
+	if addr == "local" {
+		return
+	}
+	network, raddr, ok := strings.Cut(addr, "://")
+	if !ok || (network != "udp" && network != "tcp") || len(raddr) == 0 {
+		return "", "", fmt.Errorf("invalid syslog address %q: local, udp://host:port or tcp://host:port required", addr)
+	}
+	return
+}
+
+func (r *Rule) matchHost(ip net.IP) bool {
+	if len(r.subnets) == 0 {
+		return true
+	}
+	for _, subnet := range r.subnets {
+		if subnet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Rule) matchPort(port uint16) bool {
+	if len(r.Ports) == 0 {
+		return true
+	}
+	for _, p := range r.Ports {
+		if p.StartPort <= port && port <= p.EndPort {
+			return true
+		}
+	}
+	return false
+}
+
+// matchMeta checks that every metadata condition of the rule holds
+func (r *Rule) matchMeta(meta map[string]interface{}) bool {
+	return scan.MatchMeta(meta, r.Meta)
+}
@@ -0,0 +1,115 @@
+package alert
+
+import (
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/v-byte-cpu/sx/pkg/policy"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+	c, err := Parse(strings.NewReader(`
+rules:
+  - name: telnet
+    hosts: [10.0.0.0/8]
+    ports: [23, 2323-2324]
+    actions:
+      - webhook: https://hooks.example.com/sx
+      - exec: [/usr/local/bin/notify, --urgent]
+  - meta: {tags.env: prod}
+    new: true
+    actions:
+      - syslog: udp://127.0.0.1:514
+`))
+	require.NoError(t, err)
+	require.Len(t, c.Rules, 2)
+
+	telnet := c.Rules[0]
+	require.Equal(t, "telnet", telnet.Name)
+	require.Equal(t, []policy.Ports{{PortRange: scan.PortRange{StartPort: 23, EndPort: 23}},
+		{PortRange: scan.PortRange{StartPort: 2323, EndPort: 2324}}}, telnet.Ports)
+	require.True(t, telnet.matchHost(net.IPv4(10, 1, 2, 3)))
+	require.False(t, telnet.matchHost(net.IPv4(192, 168, 0, 1)))
+	require.True(t, telnet.matchPort(2324))
+	require.False(t, telnet.matchPort(22))
+	require.Len(t, telnet.Actions, 2)
+	require.Equal(t, &webhookNotifier{url: "https://hooks.example.com/sx"}, telnet.Actions[0].notifier)
+	require.Equal(t, &execNotifier{args: []string{"/usr/local/bin/notify", "--urgent"}}, telnet.Actions[1].notifier)
+
+	prod := c.Rules[1]
+	require.Equal(t, "rule2", prod.Name)
+	require.True(t, prod.New)
+	require.True(t, prod.matchHost(net.IPv4(192, 168, 0, 1)))
+	require.True(t, prod.matchPort(22))
+	require.Equal(t, map[string]string{"tags.env": "prod"}, prod.Meta)
+}
+
+func TestParseErrors(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name  string
+		rules string
+	}{
+		{name: "NoRules", rules: "rules: []"},
+		{name: "NoActions", rules: "rules: [{ports: [23]}]"},
+		{name: "EmptyAction", rules: "rules: [{actions: [{}]}]"},
+		{name: "SeveralActionTypes", rules: "rules: [{actions: [{webhook: http://localhost, syslog: local}]}]"},
+		{name: "InvalidHost", rules: "rules: [{hosts: [web.local], actions: [{syslog: local}]}]"},
+		{name: "InvalidPort", rules: "rules: [{ports: [telnet], actions: [{syslog: local}]}]"},
+		{name: "InvalidWebhookScheme", rules: "rules: [{actions: [{webhook: ftp://localhost}]}]"},
+		{name: "InvalidSyslogNetwork", rules: "rules: [{actions: [{syslog: unix:///dev/log}]}]"},
+		{name: "InvalidSyslogAddress", rules: "rules: [{actions: [{syslog: udp://}]}]"},
+		{name: "UnknownField", rules: "rules: [{scans: [tcp], actions: [{syslog: local}]}]"},
+		{name: "InvalidYAML", rules: "rules: [{"},
+	}
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := Parse(strings.NewReader(tt.rules))
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestMatchMeta(t *testing.T) {
+	t.Parallel()
+	meta := map[string]interface{}{
+		"kind": "ec2",
+		"tags": map[string]string{"env": "prod"},
+		"labels": map[string]interface{}{
+			"roles": []interface{}{"web", "db"},
+		},
+		"services": []string{"api"},
+		"port":     8080,
+	}
+	tests := []struct {
+		name     string
+		meta     map[string]string
+		expected bool
+	}{
+		{name: "Empty", expected: true},
+		{name: "Field", meta: map[string]string{"kind": "ec2"}, expected: true},
+		{name: "NestedStringMap", meta: map[string]string{"tags.env": "prod"}, expected: true},
+		{name: "NestedList", meta: map[string]string{"labels.roles": "db"}, expected: true},
+		{name: "StringList", meta: map[string]string{"services": "api"}, expected: true},
+		{name: "Number", meta: map[string]string{"port": "8080"}, expected: true},
+		{name: "AllConditions", meta: map[string]string{"kind": "ec2", "tags.env": "prod"}, expected: true},
+		{name: "OtherValue", meta: map[string]string{"tags.env": "dev"}, expected: false},
+		{name: "MissingField", meta: map[string]string{"tags.owner": "ops"}, expected: false},
+		{name: "PathBeyondValue", meta: map[string]string{"kind.name": "ec2"}, expected: false},
+		{name: "OneConditionFails", meta: map[string]string{"kind": "ec2", "tags.env": "dev"}, expected: false},
+	}
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			r := &Rule{Meta: tt.meta}
+			require.Equal(t, tt.expected, r.matchMeta(meta))
+		})
+	}
+}
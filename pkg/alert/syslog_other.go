@@ -0,0 +1,9 @@
+//go:build windows || plan9
+
+package alert
+
+import "errors"
+
+func newSyslogNotifier(string) (notifier, error) {
+	return nil, errors.New("syslog alert action is not supported on this platform")
+}
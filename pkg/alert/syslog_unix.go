@@ -0,0 +1,40 @@
+//go:build !windows && !plan9
+
+package alert
+
+import (
+	"context"
+	"encoding/json"
+	"log/syslog"
+)
+
+const syslogTag = "sx"
+
+// syslogNotifier writes alerts in JSON format with the warning severity,
+// the connection is opened on the first alert and reused afterwards
+type syslogNotifier struct {
+	network string
+	raddr   string
+	writer  *syslog.Writer
+}
+
+func newSyslogNotifier(addr string) (notifier, error) {
+	network, raddr, err := parseSyslogAddr(addr)
+	if err != nil {
+		return nil, err
+	}
+	return &syslogNotifier{network: network, raddr: raddr}, nil
+}
+
+func (n *syslogNotifier) notify(_ context.Context, alert *Alert) (err error) {
+	data, err := json.Marshal(alert)
+	if err != nil {
+		return
+	}
+	if n.writer == nil {
+		if n.writer, err = syslog.Dial(n.network, n.raddr, syslog.LOG_WARNING|syslog.LOG_DAEMON, syslogTag); err != nil {
+			return
+		}
+	}
+	return n.writer.Warning(string(data))
+}
@@ -0,0 +1,369 @@
+// Package calibrate measures the maximum sustainable packet rate of the network interface
+// by sending probes to a reflector at increasing rates, and suggests scan settings for it
+package calibrate
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/gopacket"
+	"github.com/v-byte-cpu/sx/pkg/packet"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+	"go.uber.org/ratelimit"
+)
+
+const (
+	ScanType = "calibrate"
+
+	DefaultStartRate    = 1000
+	DefaultMaxRate      = 1000000
+	DefaultStepDuration = time.Second
+	DefaultDrainTimeout = 500 * time.Millisecond
+	// DefaultMaxLoss is the fraction of lost probes of the sustainable rate
+	DefaultMaxLoss = 0.01
+	// minSendRatio is the fraction of the target rate that the sustainable rate achieves
+	minSendRatio = 0.9
+)
+
+var ErrRate = errors.New("invalid calibration rates: 0 < start rate <= max rate required")
+
+// Prober builds probes answered by the reflector and matches their replies
+type Prober interface {
+	// Probe returns the packet of the probe with the sequence number n,
+	// the packet is valid until the next call
+	Probe(n uint32) ([]byte, error)
+	// Reply returns the sequence number of the probe answered by the packet
+	Reply(data []byte) (n uint32, ok bool)
+}
+
+// Step is the measurement of one target rate
+type Step struct {
+	ScanType string `json:"scan"`
+	// Rate is the target rate in packets per second
+	Rate int `json:"rate"`
+	// SendRate is the achieved rate of sent probes in packets per second
+	SendRate int `json:"send_rate"`
+	Sent     int `json:"sent"`
+	Received int `json:"received"`
+	// Errors is the number of probes that couldn't be sent
+	Errors int     `json:"errors"`
+	Loss   float64 `json:"loss"`
+	// Dropped is the number of packets dropped by the kernel because the receive ring was full
+	Dropped uint64 `json:"dropped"`
+	// RTT is the average round-trip time of received probes
+	RTT string `json:"rtt"`
+	// OK is true if the rate is sustainable: it is achieved and the loss doesn't exceed the maximum
+	OK bool `json:"ok"`
+
+	rtt time.Duration
+}
+
+// Assert that calibrate.Step conforms to the scan.Result interface
+var _ scan.Result = (*Step)(nil)
+
+func (s *Step) String() string {
+	status := "ok"
+	if !s.OK {
+		status = "fail"
+	}
+	return fmt.Sprintf("rate=%-8d send_rate=%-8d sent=%-8d received=%-8d loss=%.2f%% dropped=%-6d rtt=%-12s %s",
+		s.Rate, s.SendRate, s.Sent, s.Received, 100*s.Loss, s.Dropped, s.RTT, status)
+}
+
+func (s *Step) ID() string {
+	return fmt.Sprintf("%s %d", ScanType, s.Rate)
+}
+
+func (s *Step) MarshalJSON() ([]byte, error) {
+	// Type definition for the recursive call
+	type JStep Step
+	// This works because JStep doesn't have a MarshalJSON function associated with it
+	return json.Marshal(JStep(*s))
+}
+
+// Calibrator doubles the rate of probes each step until the rate is not sustainable
+type Calibrator struct {
+	rw           packet.ReadWriter
+	prober       Prober
+	startRate    int
+	maxRate      int
+	stepDuration time.Duration
+	drainTimeout time.Duration
+	maxLoss      float64
+	drops        func() (uint64, error)
+	logError     func(err error)
+	// clock paces probes and measures the send rate
+	clock ratelimit.Clock
+
+	mu sync.Mutex
+	// first is the sequence number of the first probe of the current step
+	first uint32
+	// sentAt are send times of probes of the current step in nanoseconds, zero for probes that aren't sent
+	sentAt   []int64
+	received []bool
+	// sent is the number of sent probes of the current step
+	sent   int
+	count  int
+	rttSum time.Duration
+	// drained is closed when all sent probes of the current step are answered
+	drained chan struct{}
+	// next is the sequence number of the first probe of the next step
+	next uint32
+}
+
+type Option func(c *Calibrator)
+
+func WithStartRate(rate int) Option {
+	return func(c *Calibrator) {
+		c.startRate = rate
+	}
+}
+
+func WithMaxRate(rate int) Option {
+	return func(c *Calibrator) {
+		c.maxRate = rate
+	}
+}
+
+func WithStepDuration(d time.Duration) Option {
+	return func(c *Calibrator) {
+		c.stepDuration = d
+	}
+}
+
+// WithDrainTimeout sets the time to wait for replies after the last probe of each step
+func WithDrainTimeout(d time.Duration) Option {
+	return func(c *Calibrator) {
+		c.drainTimeout = d
+	}
+}
+
+// WithMaxLoss sets the maximum fraction of lost probes of sustainable rates
+func WithMaxLoss(loss float64) Option {
+	return func(c *Calibrator) {
+		c.maxLoss = loss
+	}
+}
+
+// WithDrops sets the cumulative counter of packets dropped by the kernel
+func WithDrops(drops func() (uint64, error)) Option {
+	return func(c *Calibrator) {
+		c.drops = drops
+	}
+}
+
+// WithErrorLogger sets the function that logs errors of received packets
+func WithErrorLogger(logError func(err error)) Option {
+	return func(c *Calibrator) {
+		c.logError = logError
+	}
+}
+
+func NewCalibrator(rw packet.ReadWriter, prober Prober, opts ...Option) (*Calibrator, error) {
+	c := &Calibrator{
+		rw:           rw,
+		prober:       prober,
+		startRate:    DefaultStartRate,
+		maxRate:      DefaultMaxRate,
+		stepDuration: DefaultStepDuration,
+		drainTimeout: DefaultDrainTimeout,
+		maxLoss:      DefaultMaxLoss,
+		clock:        systemClock{},
+	}
+	for _, o := range opts {
+		o(c)
+	}
+	if c.startRate <= 0 || c.maxRate < c.startRate {
+		return nil, ErrRate
+	}
+	return c, nil
+}
+
+// Calibrate measures rates from the start rate to the maximum one, the last step is the first rate
+// that is not sustainable unless the maximum rate is sustainable
+func (c *Calibrator) Calibrate(ctx context.Context) (steps []*Step, err error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	errc := packet.NewReceiver(c.rw, c).ReceivePackets(ctx)
+	go func() {
+		for err := range errc {
+			if ctx.Err() == nil && c.logError != nil {
+				c.logError(err)
+			}
+		}
+	}()
+
+	for rate := c.startRate; ; rate *= 2 {
+		if rate > c.maxRate {
+			rate = c.maxRate
+		}
+		var step *Step
+		if step, err = c.measure(ctx, rate); err != nil {
+			return
+		}
+		steps = append(steps, step)
+		if !step.OK || rate == c.maxRate {
+			return
+		}
+	}
+}
+
+func (c *Calibrator) measure(ctx context.Context, rate int) (step *Step, err error) {
+	dropsBefore, err := c.readDrops()
+	if err != nil {
+		return
+	}
+	count := int(int64(rate) * int64(c.stepDuration) / int64(time.Second))
+	if count == 0 {
+		count = 1
+	}
+	c.startStep(count)
+
+	step = &Step{ScanType: ScanType, Rate: rate}
+	limiter := ratelimit.New(rate, ratelimit.WithClock(c.clock))
+	start := c.clock.Now()
+	for i := 0; i < count; i++ {
+		if i%1024 == 0 {
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			default:
+			}
+		}
+		limiter.Take()
+		if !c.send(i) {
+			step.Errors++
+		}
+	}
+	elapsed := c.clock.Now().Sub(start)
+	step.Sent = count
+	step.SendRate = rate
+	if elapsed > 0 {
+		if perSecond := int(float64(count) / elapsed.Seconds()); perSecond < rate {
+			step.SendRate = perSecond
+		}
+	}
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-c.drainStep():
+	case <-time.After(c.drainTimeout):
+	}
+	step.Received, step.rtt = c.finishStep()
+	step.RTT = step.rtt.String()
+	step.Loss = float64(step.Sent-step.Received) / float64(step.Sent)
+	dropsAfter, err := c.readDrops()
+	if err != nil {
+		return
+	}
+	step.Dropped = dropsAfter - dropsBefore
+	step.OK = step.Loss <= c.maxLoss && float64(step.SendRate) >= minSendRatio*float64(rate)
+	return
+}
+
+func (c *Calibrator) readDrops() (uint64, error) {
+	if c.drops == nil {
+		return 0, nil
+	}
+	return c.drops()
+}
+
+func (c *Calibrator) startStep(count int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.first = c.next
+	c.next += uint32(count)
+	c.sentAt = make([]int64, count)
+	c.received = make([]bool, count)
+	c.sent = 0
+	c.count = 0
+	c.rttSum = 0
+	c.drained = nil
+}
+
+// drainStep returns the channel that is closed when all sent probes of the current step are answered,
+// so that the step doesn't wait for the drain timeout without loss
+func (c *Calibrator) drainStep() <-chan struct{} {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	drained := make(chan struct{})
+	if c.count >= c.sent {
+		close(drained)
+		return drained
+	}
+	c.drained = drained
+	return drained
+}
+
+// finishStep returns the number of probes received in the step and their average round-trip time,
+// late replies are ignored
+func (c *Calibrator) finishStep() (count int, rtt time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	count = c.count
+	if count > 0 {
+		rtt = c.rttSum / time.Duration(count)
+	}
+	c.sentAt, c.received, c.drained = nil, nil, nil
+	return
+}
+
+// send writes the i-th probe of the current step
+func (c *Calibrator) send(i int) bool {
+	data, err := c.prober.Probe(c.first + uint32(i))
+	if err != nil {
+		return false
+	}
+	now := c.clock.Now().UnixNano()
+	c.mu.Lock()
+	c.sentAt[i] = now
+	c.sent++
+	c.mu.Unlock()
+	if err = c.rw.WritePacketData(data); err != nil {
+		c.mu.Lock()
+		c.sentAt[i] = 0
+		c.sent--
+		c.mu.Unlock()
+		return false
+	}
+	return true
+}
+
+// ProcessPacketData counts replies to probes of the current step, duplicates are ignored,
+// e.g. packets of the loopback interface are captured twice
+func (c *Calibrator) ProcessPacketData(data []byte, _ *gopacket.CaptureInfo) error {
+	n, ok := c.prober.Reply(data)
+	if !ok {
+		return nil
+	}
+	now := c.clock.Now().UnixNano()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	i := int(n - c.first)
+	if i < 0 || i >= len(c.sentAt) || c.received[i] || c.sentAt[i] == 0 {
+		return nil
+	}
+	c.received[i] = true
+	c.count++
+	c.rttSum += time.Duration(now - c.sentAt[i])
+	if c.drained != nil && c.count >= c.sent {
+		close(c.drained)
+		c.drained = nil
+	}
+	return nil
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) Sleep(d time.Duration) {
+	time.Sleep(d)
+}
@@ -0,0 +1,179 @@
+package calibrate
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/gopacket"
+	"github.com/stretchr/testify/require"
+)
+
+// seqProber writes sequence numbers of probes, replies are prefixed with 'r'
+type seqProber struct {
+	buf [5]byte
+}
+
+func (p *seqProber) Probe(n uint32) ([]byte, error) {
+	p.buf[0] = 'p'
+	binary.BigEndian.PutUint32(p.buf[1:], n)
+	return p.buf[:], nil
+}
+
+func (p *seqProber) Reply(data []byte) (n uint32, ok bool) {
+	if len(data) != 5 || data[0] != 'r' {
+		return
+	}
+	return binary.BigEndian.Uint32(data[1:]), true
+}
+
+// reflector answers probes with sequence numbers below the limit twice, like the loopback interface
+type reflector struct {
+	limit   uint32
+	replies chan []byte
+
+	mu     sync.Mutex
+	closed bool
+}
+
+func newReflector(limit uint32) *reflector {
+	return &reflector{limit: limit, replies: make(chan []byte, 100000)}
+}
+
+func (r *reflector) WritePacketData(pkt []byte) error {
+	if n := binary.BigEndian.Uint32(pkt[1:]); n >= r.limit {
+		return nil
+	}
+	reply := append([]byte{'r'}, pkt[1:]...)
+	r.replies <- reply
+	r.replies <- reply
+	return nil
+}
+
+func (r *reflector) ReadPacketData() ([]byte, *gopacket.CaptureInfo, error) {
+	data, ok := <-r.replies
+	if !ok {
+		return nil, nil, io.EOF
+	}
+	return data, &gopacket.CaptureInfo{}, nil
+}
+
+func (r *reflector) Close() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.closed {
+		r.closed = true
+		close(r.replies)
+	}
+}
+
+// fakeClock advances the time by sleeps only, so that probes are sent at exactly the target rate
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) Sleep(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func TestCalibrateMaxRate(t *testing.T) {
+	t.Parallel()
+
+	rw := newReflector(1 << 30)
+	defer rw.Close()
+	var drops uint64
+	c, err := NewCalibrator(rw, &seqProber{},
+		WithStartRate(1000), WithMaxRate(5000),
+		// steps without loss don't wait for the drain timeout
+		WithStepDuration(20*time.Millisecond), WithDrainTimeout(time.Hour),
+		WithDrops(func() (uint64, error) {
+			drops++
+			return drops, nil
+		}))
+	require.NoError(t, err)
+	c.clock = &fakeClock{now: time.Unix(1600000000, 0)}
+
+	steps, err := c.Calibrate(context.Background())
+	require.NoError(t, err)
+	require.Len(t, steps, 4)
+	for i, rate := range []int{1000, 2000, 4000, 5000} {
+		step := steps[i]
+		require.Equal(t, ScanType, step.ScanType)
+		require.Equal(t, rate, step.Rate)
+		require.Equal(t, rate, step.SendRate)
+		require.Equal(t, rate/50, step.Sent)
+		require.Equal(t, step.Sent, step.Received, "duplicate replies are counted once")
+		require.Zero(t, step.Loss)
+		require.Equal(t, uint64(1), step.Dropped)
+		require.True(t, step.OK)
+	}
+}
+
+func TestCalibrateStopsAtLoss(t *testing.T) {
+	t.Parallel()
+
+	// probes of the first two steps are answered
+	rw := newReflector(20 + 40)
+	defer rw.Close()
+	c, err := NewCalibrator(rw, &seqProber{},
+		WithStartRate(1000), WithMaxRate(100000),
+		WithStepDuration(20*time.Millisecond), WithDrainTimeout(time.Second))
+	require.NoError(t, err)
+	c.clock = &fakeClock{now: time.Unix(1600000000, 0)}
+
+	steps, err := c.Calibrate(context.Background())
+	require.NoError(t, err)
+	require.Len(t, steps, 3)
+	require.True(t, steps[0].OK)
+	require.True(t, steps[1].OK)
+	require.False(t, steps[2].OK)
+	require.Equal(t, 4000, steps[2].Rate)
+	require.Equal(t, 80, steps[2].Sent)
+	require.Zero(t, steps[2].Received)
+	require.Equal(t, 1.0, steps[2].Loss)
+}
+
+func TestCalibrateCancel(t *testing.T) {
+	t.Parallel()
+
+	rw := newReflector(1 << 30)
+	defer rw.Close()
+	c, err := NewCalibrator(rw, &seqProber{}, WithStepDuration(time.Hour))
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = c.Calibrate(ctx)
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+func TestNewCalibratorInvalidRates(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewCalibrator(newReflector(0), &seqProber{}, WithStartRate(1000), WithMaxRate(500))
+	require.ErrorIs(t, err, ErrRate)
+	_, err = NewCalibrator(newReflector(0), &seqProber{}, WithStartRate(0))
+	require.ErrorIs(t, err, ErrRate)
+}
+
+func TestStepString(t *testing.T) {
+	t.Parallel()
+
+	step := &Step{ScanType: ScanType, Rate: 1000, SendRate: 990, Sent: 1000, Received: 995,
+		Loss: 0.005, RTT: "35µs", OK: true}
+	require.Equal(t, "rate=1000     send_rate=990      sent=1000     received=995      loss=0.50% dropped=0      rtt=35µs         ok",
+		step.String())
+	require.Equal(t, "calibrate 1000", step.ID())
+}
@@ -0,0 +1,107 @@
+package calibrate
+
+import (
+	"net"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+)
+
+// replyLength is the captured size of replies, only headers are parsed
+const replyLength = 128
+
+// ICMPProber sends ICMP echo requests, the sequence number of the probe is split
+// into the identifier (high 16 bits) and the sequence number (low 16 bits) of the request.
+// Probe and Reply can be called concurrently, but each of them is called from one goroutine
+type ICMPProber struct {
+	srcMAC  net.HardwareAddr
+	dstMAC  net.HardwareAddr
+	srcIP   net.IP
+	dstIP   net.IP
+	vpnMode bool
+	payload []byte
+
+	buf gopacket.SerializeBuffer
+
+	parser     *gopacket.DecodingLayerParser
+	rcvDecoded []gopacket.LayerType
+	rcvEth     layers.Ethernet
+	rcvIP      layers.IPv4
+	rcvICMP    layers.ICMPv4
+}
+
+// Assert that calibrate.ICMPProber conforms to the calibrate.Prober interface
+var _ Prober = (*ICMPProber)(nil)
+
+// NewICMPProber creates the prober of the reflector with the dstIP address,
+// Ethernet headers are not written in vpnMode
+func NewICMPProber(srcMAC, dstMAC net.HardwareAddr, srcIP, dstIP net.IP, vpnMode bool) *ICMPProber {
+	p := &ICMPProber{
+		srcMAC:  srcMAC,
+		dstMAC:  dstMAC,
+		srcIP:   srcIP.To4(),
+		dstIP:   dstIP.To4(),
+		vpnMode: vpnMode,
+		// typical payload size of ping
+		payload: make([]byte, 56),
+		buf:     gopacket.NewSerializeBuffer(),
+	}
+	layerType := layers.LayerTypeEthernet
+	if vpnMode {
+		layerType = layers.LayerTypeIPv4
+	}
+	p.parser = gopacket.NewDecodingLayerParser(layerType, &p.rcvEth, &p.rcvIP, &p.rcvICMP)
+	p.parser.IgnoreUnsupported = true
+	return p
+}
+
+func (p *ICMPProber) Probe(n uint32) ([]byte, error) {
+	ip := &layers.IPv4{
+		Version:  4,
+		IHL:      5,
+		TTL:      64,
+		Flags:    layers.IPv4DontFragment,
+		Protocol: layers.IPProtocolICMPv4,
+		SrcIP:    p.srcIP,
+		DstIP:    p.dstIP,
+	}
+	icmp := &layers.ICMPv4{
+		TypeCode: layers.CreateICMPv4TypeCode(layers.ICMPv4TypeEchoRequest, 0),
+		Id:       uint16(n >> 16),
+		Seq:      uint16(n),
+	}
+	opt := gopacket.SerializeOptions{ComputeChecksums: true, FixLengths: true}
+	var err error
+	if p.vpnMode {
+		err = gopacket.SerializeLayers(p.buf, opt, ip, icmp, gopacket.Payload(p.payload))
+	} else {
+		eth := &layers.Ethernet{
+			SrcMAC:       p.srcMAC,
+			DstMAC:       p.dstMAC,
+			EthernetType: layers.EthernetTypeIPv4,
+		}
+		err = gopacket.SerializeLayers(p.buf, opt, eth, ip, icmp, gopacket.Payload(p.payload))
+	}
+	if err != nil {
+		return nil, err
+	}
+	return p.buf.Bytes(), nil
+}
+
+func (p *ICMPProber) Reply(data []byte) (n uint32, ok bool) {
+	if err := p.parser.DecodeLayers(data, &p.rcvDecoded); err != nil {
+		return
+	}
+	if len(p.rcvDecoded) == 0 || p.rcvDecoded[len(p.rcvDecoded)-1] != layers.LayerTypeICMPv4 {
+		return
+	}
+	if p.rcvICMP.TypeCode.Type() != layers.ICMPv4TypeEchoReply || !p.rcvIP.SrcIP.Equal(p.dstIP) {
+		return
+	}
+	return uint32(p.rcvICMP.Id)<<16 | uint32(p.rcvICMP.Seq), true
+}
+
+// BPFFilter returns the filter of replies to probes
+func (p *ICMPProber) BPFFilter() (filter string, maxPacketLength int) {
+	return "icmp[icmptype] == icmp-echoreply and src host " + p.dstIP.String(), replyLength
+}
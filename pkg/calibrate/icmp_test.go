@@ -0,0 +1,94 @@
+package calibrate
+
+import (
+	"net"
+	"testing"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	"github.com/stretchr/testify/require"
+)
+
+// echoReply answers the echo request like the reflector
+func echoReply(t *testing.T, probe []byte, vpnMode bool) []byte {
+	t.Helper()
+	firstLayer := layers.LayerTypeEthernet
+	if vpnMode {
+		firstLayer = layers.LayerTypeIPv4
+	}
+	pkt := gopacket.NewPacket(probe, firstLayer, gopacket.Default)
+	ip := pkt.Layer(layers.LayerTypeIPv4).(*layers.IPv4)
+	icmp := pkt.Layer(layers.LayerTypeICMPv4).(*layers.ICMPv4)
+	require.Equal(t, uint8(layers.ICMPv4TypeEchoRequest), icmp.TypeCode.Type())
+
+	ip.SrcIP, ip.DstIP = ip.DstIP, ip.SrcIP
+	icmp.TypeCode = layers.CreateICMPv4TypeCode(layers.ICMPv4TypeEchoReply, 0)
+	buf := gopacket.NewSerializeBuffer()
+	opt := gopacket.SerializeOptions{ComputeChecksums: true, FixLengths: true}
+	if vpnMode {
+		require.NoError(t, gopacket.SerializeLayers(buf, opt, ip, icmp, gopacket.Payload(icmp.Payload)))
+	} else {
+		eth := pkt.Layer(layers.LayerTypeEthernet).(*layers.Ethernet)
+		eth.SrcMAC, eth.DstMAC = eth.DstMAC, eth.SrcMAC
+		require.NoError(t, gopacket.SerializeLayers(buf, opt, eth, ip, icmp, gopacket.Payload(icmp.Payload)))
+	}
+	return buf.Bytes()
+}
+
+func TestICMPProber(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		vpnMode bool
+	}{
+		{name: "Ethernet"},
+		{name: "VPNmode", vpnMode: true},
+	}
+
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			srcMAC := net.HardwareAddr{0x1, 0x2, 0x3, 0x4, 0x5, 0x6}
+			dstMAC := net.HardwareAddr{0x6, 0x5, 0x4, 0x3, 0x2, 0x1}
+			p := NewICMPProber(srcMAC, dstMAC, net.IPv4(192, 168, 0, 3), net.IPv4(192, 168, 0, 1), tt.vpnMode)
+
+			for _, seq := range []uint32{0, 1, 0x12345678, 0xffffffff} {
+				probe, err := p.Probe(seq)
+				require.NoError(t, err)
+				// the request itself is not a reply, e.g. outgoing packets captured on the loopback interface
+				_, ok := p.Reply(probe)
+				require.False(t, ok)
+
+				n, ok := p.Reply(echoReply(t, probe, tt.vpnMode))
+				require.True(t, ok)
+				require.Equal(t, seq, n)
+			}
+		})
+	}
+}
+
+func TestICMPProberOtherSource(t *testing.T) {
+	t.Parallel()
+
+	p := NewICMPProber(nil, nil, net.IPv4(192, 168, 0, 3), net.IPv4(192, 168, 0, 1), true)
+	other := NewICMPProber(nil, nil, net.IPv4(192, 168, 0, 3), net.IPv4(192, 168, 0, 2), true)
+	probe, err := other.Probe(5)
+	require.NoError(t, err)
+
+	_, ok := p.Reply(echoReply(t, probe, true))
+	require.False(t, ok)
+	_, ok = p.Reply([]byte{0x1, 0x2})
+	require.False(t, ok)
+}
+
+func TestICMPProberBPFFilter(t *testing.T) {
+	t.Parallel()
+
+	p := NewICMPProber(nil, nil, net.IPv4(127, 0, 0, 1), net.IPv4(127, 0, 0, 1), false)
+	filter, maxPacketLength := p.BPFFilter()
+	require.Equal(t, "icmp[icmptype] == icmp-echoreply and src host 127.0.0.1", filter)
+	require.Equal(t, replyLength, maxPacketLength)
+}
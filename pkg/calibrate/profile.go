@@ -0,0 +1,143 @@
+package calibrate
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/v-byte-cpu/sx/pkg/scan"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// MinRingBlocks is the default number of blocks of the receive ring of afpacket sockets
+	MinRingBlocks = 128
+	// MaxRingBlocks limits the suggested ring to 512 MiB of 512 KiB blocks
+	MaxRingBlocks = 1024
+	// framesPerBlock is the number of packets that fit into one block of the receive ring
+	framesPerBlock = 128
+	// ringBuffering is the time of replies at the suggested rate that fit into the receive ring
+	ringBuffering = 100 * time.Millisecond
+	// rateHeadroom is the fraction of the sustainable rate that is suggested for scans
+	rateHeadroom = 0.8
+)
+
+var ErrNoRate = errors.New("no sustainable rate: probes of the start rate are lost, check the reflector")
+
+// Profile is the local tuning profile with suggested settings of scans on the calibrated interface
+type Profile struct {
+	ScanType  string `json:"scan" yaml:"-"`
+	Interface string `json:"interface" yaml:"interface"`
+	// Reflector is the IP of the reflector that answered probes
+	Reflector  string    `json:"reflector" yaml:"reflector"`
+	MeasuredAt time.Time `json:"measured_at" yaml:"measured_at"`
+	// MaxRate is the highest sustainable rate in packets per second
+	MaxRate int `json:"max_rate" yaml:"max_rate"`
+	// Rate is the suggested value of the --rate flag
+	Rate string `json:"rate" yaml:"rate"`
+	// Workers is the suggested value of the --workers flag
+	Workers int `json:"workers" yaml:"workers"`
+	// RingBlocks is the suggested value of the --ring-blocks flag
+	RingBlocks int `json:"ring_blocks" yaml:"ring_blocks"`
+}
+
+// Assert that calibrate.Profile conforms to the scan.Result interface
+var _ scan.Result = (*Profile)(nil)
+
+func (p *Profile) String() string {
+	return fmt.Sprintf("profile max_rate=%d rate=%s workers=%d ring_blocks=%d", p.MaxRate, p.Rate, p.Workers, p.RingBlocks)
+}
+
+func (p *Profile) ID() string {
+	return fmt.Sprintf("%s profile %s", ScanType, p.Interface)
+}
+
+func (p *Profile) MarshalJSON() ([]byte, error) {
+	// Type definition for the recursive call
+	type JProfile Profile
+	// This works because JProfile doesn't have a MarshalJSON function associated with it
+	return json.Marshal(JProfile(*p))
+}
+
+// Suggest returns settings for the highest sustainable rate of steps: the rate with headroom,
+// the receive ring that holds replies of 100ms and workers that keep the rate busy for the round-trip time,
+// limited by minWorkers and maxWorkers. The ring is doubled if the kernel dropped packets at sustainable rates
+func Suggest(steps []*Step, minWorkers, maxWorkers int) (*Profile, error) {
+	var best *Step
+	var dropped uint64
+	for _, s := range steps {
+		if !s.OK {
+			continue
+		}
+		dropped += s.Dropped
+		if best == nil || s.Rate > best.Rate {
+			best = s
+		}
+	}
+	if best == nil {
+		return nil, ErrNoRate
+	}
+	maxRate := best.SendRate
+	rate := int(rateHeadroom * float64(maxRate))
+	if rate == 0 {
+		rate = 1
+	}
+
+	ringBlocks := int(math.Ceil(float64(rate) * ringBuffering.Seconds() / framesPerBlock))
+	if dropped > 0 {
+		ringBlocks *= 2
+	}
+	ringBlocks = clamp(ringBlocks, MinRingBlocks, MaxRingBlocks)
+
+	workers := int(math.Ceil(float64(rate) * best.rtt.Seconds()))
+	workers = clamp(workers, minWorkers, maxWorkers)
+
+	return &Profile{
+		ScanType:   ScanType,
+		MaxRate:    maxRate,
+		Rate:       fmt.Sprintf("%d/s", rate),
+		Workers:    workers,
+		RingBlocks: ringBlocks,
+	}, nil
+}
+
+// clamp limits v to the range, the upper bound is not set if it is zero
+func clamp(v, lower, upper int) int {
+	if v < lower {
+		return lower
+	}
+	if upper > 0 && v > upper {
+		return upper
+	}
+	return v
+}
+
+// LoadProfile reads the tuning profile from the YAML file
+func LoadProfile(path string) (*Profile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var p Profile
+	if err = yaml.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("invalid tuning profile %s: %w", path, err)
+	}
+	p.ScanType = ScanType
+	return &p, nil
+}
+
+// Save writes the tuning profile to the YAML file, parent directories are created
+func (p *Profile) Save(path string) error {
+	data, err := yaml.Marshal(p)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
@@ -0,0 +1,94 @@
+package calibrate
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSuggest(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		steps    []*Step
+		expected *Profile
+	}{
+		{
+			name: "LowRate",
+			steps: []*Step{
+				{Rate: 1000, SendRate: 1000, OK: true, rtt: 50 * time.Microsecond},
+				{Rate: 2000, SendRate: 2000, OK: false},
+			},
+			expected: &Profile{ScanType: ScanType, MaxRate: 1000, Rate: "800/s", Workers: 100, RingBlocks: MinRingBlocks},
+		},
+		{
+			name: "HighRateWithDrops",
+			steps: []*Step{
+				{Rate: 256000, SendRate: 256000, OK: true, Dropped: 3, rtt: time.Millisecond},
+				{Rate: 512000, SendRate: 500000, OK: true, rtt: 2 * time.Millisecond},
+				{Rate: 1000000, SendRate: 600000, OK: false, Dropped: 1000},
+			},
+			// 400000 pps * 100ms / 128 = 313 blocks, doubled because of drops
+			expected: &Profile{ScanType: ScanType, MaxRate: 500000, Rate: "400000/s", Workers: 800, RingBlocks: 626},
+		},
+		{
+			name: "MaxWorkers",
+			steps: []*Step{
+				{Rate: 1000000, SendRate: 1000000, OK: true, rtt: 10 * time.Millisecond},
+			},
+			expected: &Profile{ScanType: ScanType, MaxRate: 1000000, Rate: "800000/s", Workers: 1000, RingBlocks: 625},
+		},
+	}
+
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			profile, err := Suggest(tt.steps, 100, 1000)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, profile)
+		})
+	}
+}
+
+func TestSuggestNoRate(t *testing.T) {
+	t.Parallel()
+
+	_, err := Suggest([]*Step{{Rate: 1000, SendRate: 1000, Loss: 1}}, 100, 1000)
+	require.ErrorIs(t, err, ErrNoRate)
+}
+
+func TestProfileSaveLoad(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "sx", "tuning.yaml")
+	profile := &Profile{ScanType: ScanType, Interface: "eth0", Reflector: "192.168.0.1",
+		MeasuredAt: time.Date(2021, 5, 1, 10, 0, 0, 0, time.UTC),
+		MaxRate:    100000, Rate: "80000/s", Workers: 200, RingBlocks: 128}
+	require.NoError(t, profile.Save(path))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.NotContains(t, string(data), "scan")
+
+	loaded, err := LoadProfile(path)
+	require.NoError(t, err)
+	require.Equal(t, profile, loaded)
+}
+
+func TestLoadProfileInvalid(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "tuning.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("workers: many\n"), 0o644))
+	_, err := LoadProfile(path)
+	require.Error(t, err)
+
+	_, err = LoadProfile(filepath.Join(t.TempDir(), "missing.yaml"))
+	require.True(t, os.IsNotExist(err))
+}
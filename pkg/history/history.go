@@ -0,0 +1,223 @@
+// Package history keeps the compact state history of ports of scanned hosts: only transitions between
+// open, closed and filtered states are stored, so that flapping services and uptime can be analyzed
+package history
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+const (
+	ScanType = "history"
+
+	// Open is the state of ports that answered the scan
+	Open = "open"
+	// Closed is the state of ports that answered with RST
+	Closed = "closed"
+	// Filtered is the state of known ports that were scanned, but didn't answer
+	Filtered = "filtered"
+)
+
+// Transition is the change of the state of the port, the first transition of each port is its first answer
+type Transition struct {
+	ScanType string    `json:"scan"`
+	IP       string    `json:"ip"`
+	Port     uint16    `json:"port"`
+	State    string    `json:"state"`
+	Time     time.Time `json:"time"`
+}
+
+// Assert that history.Transition conforms to the scan.Result interface
+var _ scan.Result = (*Transition)(nil)
+
+func (t *Transition) String() string {
+	return fmt.Sprintf("%-20s %-5d %-8s %s", t.IP, t.Port, t.State, t.Time.UTC().Format(time.RFC3339))
+}
+
+func (t *Transition) ID() string {
+	return fmt.Sprintf("%s %s", targetKey(t.IP, t.Port), t.Time.UTC().Format(time.RFC3339Nano))
+}
+
+func (t *Transition) MarshalJSON() ([]byte, error) {
+	// Type definition for the recursive call
+	type JTransition Transition
+	// This works because JTransition doesn't have a MarshalJSON function associated with it
+	return json.Marshal(JTransition(*t))
+}
+
+type target struct {
+	ip    string
+	port  uint16
+	state string
+}
+
+// Store records states of ports in scan results of each run and appends their transitions to the file.
+// Known ports that were scanned in the run without any result become filtered.
+type Store struct {
+	path string
+	now  func() time.Time
+
+	mu      sync.Mutex
+	targets map[string]*target
+	// observed are states of ports in results of the current run, open wins over closed
+	observed map[string]*target
+	// probed are known ports scanned in the current run
+	probed map[string]bool
+}
+
+// OpenStore reads transitions of the history file to restore the last state of each port,
+// the file doesn't have to exist
+func OpenStore(path string) (*Store, error) {
+	s := &Store{path: path, now: time.Now, targets: make(map[string]*target),
+		observed: make(map[string]*target), probed: make(map[string]bool)}
+	transitions, err := LoadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	for _, t := range transitions {
+		s.targets[targetKey(t.IP, t.Port)] = &target{ip: t.IP, port: t.Port, state: t.State}
+	}
+	return s, nil
+}
+
+// Record saves the state of the port of the scan result in the current run, results without ports are ignored.
+// It always returns true to be used as a filter of logged results.
+func (s *Store) Record(result scan.Result) bool {
+	data, err := scan.UnwrapResult(result).MarshalJSON()
+	if err != nil {
+		return true
+	}
+	var r struct {
+		IP    string `json:"ip"`
+		Port  uint16 `json:"port"`
+		Flags string `json:"flags"`
+	}
+	if err = json.Unmarshal(data, &r); err != nil || len(r.IP) == 0 || r.Port == 0 {
+		return true
+	}
+	state := Open
+	if strings.Contains(r.Flags, "r") {
+		state = Closed
+	}
+	key := targetKey(r.IP, r.Port)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if tg, ok := s.observed[key]; !ok || tg.state != Open {
+		s.observed[key] = &target{ip: r.IP, port: r.Port, state: state}
+	}
+	return true
+}
+
+// Probe records that the port is scanned in the current run, only ports with known states are tracked
+func (s *Store) Probe(ip net.IP, port uint16) {
+	key := targetKey(ip.String(), port)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.targets[key]; ok {
+		s.probed[key] = true
+	}
+}
+
+// FinishRun appends transitions of the current run to the file and starts the next run
+func (s *Store) FinishRun() (err error) {
+	transitions := s.finishRun()
+	if len(transitions) == 0 {
+		return
+	}
+	f, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return
+	}
+	defer func() {
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+	}()
+	bw := bufio.NewWriter(f)
+	enc := json.NewEncoder(bw)
+	for _, t := range transitions {
+		if err = enc.Encode(t); err != nil {
+			return
+		}
+	}
+	return bw.Flush()
+}
+
+func (s *Store) finishRun() (transitions []*Transition) {
+	now := s.now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for key := range s.probed {
+		if _, ok := s.observed[key]; !ok {
+			tg := s.targets[key]
+			s.observed[key] = &target{ip: tg.ip, port: tg.port, state: Filtered}
+		}
+	}
+	for key, observed := range s.observed {
+		if tg, ok := s.targets[key]; ok && tg.state == observed.state {
+			continue
+		}
+		s.targets[key] = observed
+		transitions = append(transitions, &Transition{
+			ScanType: ScanType, IP: observed.ip, Port: observed.port, State: observed.state, Time: now})
+	}
+	sort.Slice(transitions, func(i, j int) bool {
+		return lessTarget(transitions[i].IP, transitions[i].Port, transitions[j].IP, transitions[j].Port)
+	})
+	s.observed = make(map[string]*target)
+	s.probed = make(map[string]bool)
+	return
+}
+
+// LoadFile reads transitions of the history file in the order they were written
+func LoadFile(path string) ([]*Transition, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Read(f)
+}
+
+// Read reads transitions in JSON lines format, empty lines are skipped
+func Read(r io.Reader) (transitions []*Transition, err error) {
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		if len(strings.TrimSpace(scanner.Text())) == 0 {
+			continue
+		}
+		var t Transition
+		if err = json.Unmarshal(scanner.Bytes(), &t); err != nil {
+			return nil, fmt.Errorf("invalid history line %d: %w", line, err)
+		}
+		transitions = append(transitions, &t)
+	}
+	return transitions, scanner.Err()
+}
+
+func targetKey(ip string, port uint16) string {
+	return net.JoinHostPort(ip, strconv.Itoa(int(port)))
+}
+
+// lessTarget orders ports by their IPs and port numbers
+func lessTarget(ip1 string, port1 uint16, ip2 string, port2 uint16) bool {
+	if ip1 != ip2 {
+		a, b := net.ParseIP(ip1), net.ParseIP(ip2)
+		if a == nil || b == nil {
+			return ip1 < ip2
+		}
+		return string(a.To16()) < string(b.To16())
+	}
+	return port1 < port2
+}
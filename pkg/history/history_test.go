@@ -0,0 +1,102 @@
+package history
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+	"github.com/v-byte-cpu/sx/pkg/scan/arp"
+	"github.com/v-byte-cpu/sx/pkg/scan/tcp"
+)
+
+func newResult(ip string, port uint16, flags string) scan.Result {
+	return &tcp.ScanResult{ScanType: tcp.SYNScanType, IP: ip, Port: port, Flags: flags}
+}
+
+func TestStore(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	start := time.Date(2021, 5, 1, 10, 0, 0, 0, time.UTC)
+	now := start
+	openStore := func() *Store {
+		s, err := OpenStore(path)
+		require.NoError(t, err)
+		s.now = func() time.Time { return now }
+		return s
+	}
+
+	// the first run
+	s := openStore()
+	require.True(t, s.Record(newResult("192.168.0.3", 22, "sa")))
+	require.True(t, s.Record(newResult("192.168.0.3", 80, "sa")))
+	require.True(t, s.Record(&scan.MetaResult{Result: newResult("192.168.0.5", 23, "ar")}))
+	require.True(t, s.Record(&arp.ScanResult{IP: "192.168.0.3"}))
+	require.NoError(t, s.FinishRun())
+
+	// nothing changed
+	now = start.Add(time.Hour)
+	s.Probe(net.ParseIP("192.168.0.3"), 22)
+	s.Record(newResult("192.168.0.3", 22, "sa"))
+	require.NoError(t, s.FinishRun())
+
+	// the next process: port 22 didn't answer, port 80 wasn't scanned, port 23 was opened
+	now = start.Add(2 * time.Hour)
+	s = openStore()
+	s.Probe(net.ParseIP("192.168.0.3"), 22)
+	s.Probe(net.ParseIP("192.168.0.5"), 23)
+	// unknown ports aren't tracked until they answer
+	s.Probe(net.ParseIP("192.168.0.9"), 22)
+	s.Record(newResult("192.168.0.5", 23, "ar"))
+	s.Record(newResult("192.168.0.5", 23, "sa"))
+	s.Record(newResult("192.168.0.5", 23, "ar"))
+	require.NoError(t, s.FinishRun())
+
+	transitions, err := LoadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, []*Transition{
+		{ScanType: ScanType, IP: "192.168.0.3", Port: 22, State: Open, Time: start},
+		{ScanType: ScanType, IP: "192.168.0.3", Port: 80, State: Open, Time: start},
+		{ScanType: ScanType, IP: "192.168.0.5", Port: 23, State: Closed, Time: start},
+		{ScanType: ScanType, IP: "192.168.0.3", Port: 22, State: Filtered, Time: start.Add(2 * time.Hour)},
+		{ScanType: ScanType, IP: "192.168.0.5", Port: 23, State: Open, Time: start.Add(2 * time.Hour)},
+	}, transitions)
+}
+
+func TestOpenStoreError(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	require.NoError(t, os.WriteFile(path, []byte("{\"ip\":\"192.168.0.3\"}\n\nnot json\n"), 0o600))
+	_, err := OpenStore(path)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "line 3")
+}
+
+func TestSummarize(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2021, 5, 1, 10, 0, 0, 0, time.UTC)
+	transitions, err := Read(strings.NewReader(strings.Join([]string{
+		`{"scan":"history","ip":"192.168.0.10","port":80,"state":"open","time":"2021-05-01T10:00:00Z"}`,
+		`{"scan":"history","ip":"192.168.0.9","port":443,"state":"closed","time":"2021-05-01T10:00:00Z"}`,
+		`{"scan":"history","ip":"192.168.0.10","port":80,"state":"filtered","time":"2021-05-01T11:00:00Z"}`,
+		`{"scan":"history","ip":"192.168.0.10","port":80,"state":"open","time":"2021-05-01T13:00:00Z"}`,
+	}, "\n")))
+	require.NoError(t, err)
+
+	summaries := Summarize(transitions, start.Add(4*time.Hour))
+	require.Equal(t, []*Summary{
+		{ScanType: ScanType, IP: "192.168.0.9", Port: 443, State: Closed, Since: start, FirstSeen: start},
+		{ScanType: ScanType, IP: "192.168.0.10", Port: 80, State: Open, Since: start.Add(3 * time.Hour),
+			FirstSeen: start, Changes: 2, Uptime: 0.5},
+	}, summaries)
+	require.Equal(t, "192.168.0.10         80    open     since=2021-05-01T13:00:00Z changes=2 uptime=50.00%",
+		summaries[1].String())
+	require.Equal(t, "192.168.0.10:80", summaries[1].ID())
+}
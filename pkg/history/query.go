@@ -0,0 +1,161 @@
+package history
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"time"
+
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+var ErrRun = errors.New("invalid run: number of a run of the history required")
+
+// Run is the scan run that changed states of ports, runs are numbered from 1 in the order they were written.
+// Transitions of the run have the same time since they are written when the run finishes,
+// runs that didn't change any state aren't stored.
+type Run struct {
+	ScanType string    `json:"scan"`
+	Run      int       `json:"run"`
+	Time     time.Time `json:"time"`
+	// Changes is the number of transitions of the run
+	Changes int `json:"changes"`
+	// Open is the number of open ports after the run
+	Open int `json:"open"`
+}
+
+// Assert that history.Run conforms to the scan.Result interface
+var _ scan.Result = (*Run)(nil)
+
+func (r *Run) String() string {
+	return fmt.Sprintf("%-5d %s changes=%d open=%d", r.Run, r.Time.UTC().Format(time.RFC3339), r.Changes, r.Open)
+}
+
+func (r *Run) ID() string {
+	return fmt.Sprintf("run %d", r.Run)
+}
+
+func (r *Run) MarshalJSON() ([]byte, error) {
+	// Type definition for the recursive call
+	type JRun Run
+	// This works because JRun doesn't have a MarshalJSON function associated with it
+	return json.Marshal(JRun(*r))
+}
+
+// Change is the difference of the state of the port between two runs,
+// the state is empty if the port wasn't known yet
+type Change struct {
+	ScanType string `json:"scan"`
+	IP       string `json:"ip"`
+	Port     uint16 `json:"port"`
+	From     string `json:"from"`
+	To       string `json:"to"`
+}
+
+// Assert that history.Change conforms to the scan.Result interface
+var _ scan.Result = (*Change)(nil)
+
+func (c *Change) String() string {
+	from, to := c.From, c.To
+	if len(from) == 0 {
+		from = "-"
+	}
+	if len(to) == 0 {
+		to = "-"
+	}
+	return fmt.Sprintf("%-20s %-5d %s -> %s", c.IP, c.Port, from, to)
+}
+
+func (c *Change) ID() string {
+	return targetKey(c.IP, c.Port)
+}
+
+func (c *Change) MarshalJSON() ([]byte, error) {
+	// Type definition for the recursive call
+	type JChange Change
+	// This works because JChange doesn't have a MarshalJSON function associated with it
+	return json.Marshal(JChange(*c))
+}
+
+// ListRuns returns runs of transitions in the order they were written
+func ListRuns(transitions []*Transition) (runs []*Run) {
+	states := make(map[string]string)
+	open := 0
+	for i, t := range transitions {
+		if i == 0 || !t.Time.Equal(transitions[i-1].Time) {
+			runs = append(runs, &Run{ScanType: ScanType, Run: len(runs) + 1, Time: t.Time})
+		}
+		key := targetKey(t.IP, t.Port)
+		if states[key] == Open {
+			open--
+		}
+		if t.State == Open {
+			open++
+		}
+		states[key] = t.State
+		run := runs[len(runs)-1]
+		run.Changes++
+		run.Open = open
+	}
+	return
+}
+
+// ResultsForHost returns transitions of ports of the host in the order they were written
+func ResultsForHost(transitions []*Transition, host net.IP) (result []*Transition) {
+	for _, t := range transitions {
+		if host.Equal(net.ParseIP(t.IP)) {
+			result = append(result, t)
+		}
+	}
+	return
+}
+
+// ChangesBetween returns ports which states after the run runA differ from their states after the run runB
+// ordered by IPs and ports, runs are numbers of ListRuns
+func ChangesBetween(transitions []*Transition, runA, runB int) ([]*Change, error) {
+	runs := ListRuns(transitions)
+	for _, run := range []int{runA, runB} {
+		if run < 1 || run > len(runs) {
+			return nil, fmt.Errorf("%w: %d of %d runs", ErrRun, run, len(runs))
+		}
+	}
+	from, to := statesAfter(transitions, runA), statesAfter(transitions, runB)
+	var changes []*Change
+	for key, tg := range to {
+		if fromState := from[key].stateOrEmpty(); fromState != tg.state {
+			changes = append(changes, &Change{ScanType: ScanType, IP: tg.ip, Port: tg.port, From: fromState, To: tg.state})
+		}
+	}
+	for key, tg := range from {
+		if _, ok := to[key]; !ok {
+			changes = append(changes, &Change{ScanType: ScanType, IP: tg.ip, Port: tg.port, From: tg.state})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return lessTarget(changes[i].IP, changes[i].Port, changes[j].IP, changes[j].Port)
+	})
+	return changes, nil
+}
+
+// statesAfter returns states of ports after the run
+func statesAfter(transitions []*Transition, run int) map[string]*target {
+	states := make(map[string]*target)
+	for i, t := range transitions {
+		if i > 0 && !t.Time.Equal(transitions[i-1].Time) {
+			if run--; run == 0 {
+				break
+			}
+		}
+		states[targetKey(t.IP, t.Port)] = &target{ip: t.IP, port: t.Port, state: t.State}
+	}
+	return states
+}
+
+func (t *target) stateOrEmpty() string {
+	if t == nil {
+		return ""
+	}
+	return t.state
+}
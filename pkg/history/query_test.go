@@ -0,0 +1,76 @@
+package history
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newTestTransitions(start time.Time) []*Transition {
+	return []*Transition{
+		{ScanType: ScanType, IP: "192.168.0.3", Port: 22, State: Open, Time: start},
+		{ScanType: ScanType, IP: "192.168.0.3", Port: 80, State: Open, Time: start},
+		{ScanType: ScanType, IP: "192.168.0.5", Port: 23, State: Closed, Time: start},
+		{ScanType: ScanType, IP: "192.168.0.3", Port: 22, State: Filtered, Time: start.Add(time.Hour)},
+		{ScanType: ScanType, IP: "192.168.0.5", Port: 23, State: Open, Time: start.Add(time.Hour)},
+		{ScanType: ScanType, IP: "192.168.0.3", Port: 22, State: Open, Time: start.Add(2 * time.Hour)},
+		{ScanType: ScanType, IP: "192.168.0.4", Port: 443, State: Open, Time: start.Add(2 * time.Hour)},
+	}
+}
+
+func TestListRuns(t *testing.T) {
+	t.Parallel()
+	start := time.Date(2021, 5, 1, 10, 0, 0, 0, time.UTC)
+
+	require.Equal(t, []*Run{
+		{ScanType: ScanType, Run: 1, Time: start, Changes: 3, Open: 2},
+		{ScanType: ScanType, Run: 2, Time: start.Add(time.Hour), Changes: 2, Open: 2},
+		{ScanType: ScanType, Run: 3, Time: start.Add(2 * time.Hour), Changes: 2, Open: 4},
+	}, ListRuns(newTestTransitions(start)))
+	require.Empty(t, ListRuns(nil))
+}
+
+func TestResultsForHost(t *testing.T) {
+	t.Parallel()
+	transitions := newTestTransitions(time.Date(2021, 5, 1, 10, 0, 0, 0, time.UTC))
+
+	require.Equal(t, []*Transition{transitions[2], transitions[4]}, ResultsForHost(transitions, net.ParseIP("192.168.0.5")))
+	require.Empty(t, ResultsForHost(transitions, net.ParseIP("192.168.0.9")))
+}
+
+func TestChangesBetween(t *testing.T) {
+	t.Parallel()
+	transitions := newTestTransitions(time.Date(2021, 5, 1, 10, 0, 0, 0, time.UTC))
+
+	changes, err := ChangesBetween(transitions, 1, 3)
+	require.NoError(t, err)
+	require.Equal(t, []*Change{
+		{ScanType: ScanType, IP: "192.168.0.4", Port: 443, To: Open},
+		{ScanType: ScanType, IP: "192.168.0.5", Port: 23, From: Closed, To: Open},
+	}, changes)
+
+	changes, err = ChangesBetween(transitions, 3, 2)
+	require.NoError(t, err)
+	require.Equal(t, []*Change{
+		{ScanType: ScanType, IP: "192.168.0.3", Port: 22, From: Open, To: Filtered},
+		{ScanType: ScanType, IP: "192.168.0.4", Port: 443, From: Open},
+	}, changes)
+
+	changes, err = ChangesBetween(transitions, 2, 2)
+	require.NoError(t, err)
+	require.Empty(t, changes)
+
+	for _, runs := range [][2]int{{0, 1}, {1, 4}} {
+		_, err = ChangesBetween(transitions, runs[0], runs[1])
+		require.ErrorIs(t, err, ErrRun)
+	}
+}
+
+func TestChangeString(t *testing.T) {
+	t.Parallel()
+	change := &Change{ScanType: ScanType, IP: "192.168.0.4", Port: 443, To: Open}
+	require.Equal(t, "192.168.0.4          443   - -> open", change.String())
+	require.Equal(t, "192.168.0.4:443", change.ID())
+}
@@ -0,0 +1,86 @@
+package history
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+// Summary is the uptime-style view of the state history of the port
+type Summary struct {
+	ScanType string `json:"scan"`
+	IP       string `json:"ip"`
+	Port     uint16 `json:"port"`
+	// State is the last state of the port
+	State string `json:"state"`
+	// Since is the time of the last transition
+	Since     time.Time `json:"since"`
+	FirstSeen time.Time `json:"first_seen"`
+	// Changes is the number of transitions after the first answer, flapping ports have many changes
+	Changes int `json:"changes"`
+	// Uptime is the fraction of time the port was open since it was first seen
+	Uptime float64 `json:"uptime"`
+}
+
+// Assert that history.Summary conforms to the scan.Result interface
+var _ scan.Result = (*Summary)(nil)
+
+func (s *Summary) String() string {
+	return fmt.Sprintf("%-20s %-5d %-8s since=%s changes=%d uptime=%.2f%%", s.IP, s.Port, s.State,
+		s.Since.UTC().Format(time.RFC3339), s.Changes, 100*s.Uptime)
+}
+
+func (s *Summary) ID() string {
+	return targetKey(s.IP, s.Port)
+}
+
+func (s *Summary) MarshalJSON() ([]byte, error) {
+	// Type definition for the recursive call
+	type JSummary Summary
+	// This works because JSummary doesn't have a MarshalJSON function associated with it
+	return json.Marshal(JSummary(*s))
+}
+
+// Summarize returns summaries of ports of transitions ordered by IPs and ports,
+// the uptime is computed up to now
+func Summarize(transitions []*Transition, now time.Time) []*Summary {
+	summaries := make(map[string]*Summary)
+	openSince := make(map[string]time.Time)
+	openTime := make(map[string]time.Duration)
+	for _, t := range transitions {
+		key := targetKey(t.IP, t.Port)
+		s, ok := summaries[key]
+		if !ok {
+			s = &Summary{ScanType: ScanType, IP: t.IP, Port: t.Port, FirstSeen: t.Time}
+			summaries[key] = s
+		} else {
+			s.Changes++
+		}
+		if s.State == Open {
+			openTime[key] += t.Time.Sub(openSince[key])
+		}
+		if t.State == Open {
+			openSince[key] = t.Time
+		}
+		s.State, s.Since = t.State, t.Time
+	}
+	result := make([]*Summary, 0, len(summaries))
+	for key, s := range summaries {
+		if s.State == Open {
+			openTime[key] += now.Sub(openSince[key])
+		}
+		if total := now.Sub(s.FirstSeen); total > 0 {
+			s.Uptime = float64(openTime[key]) / float64(total)
+		} else if s.State == Open {
+			s.Uptime = 1
+		}
+		result = append(result, s)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return lessTarget(result[i].IP, result[i].Port, result[j].IP, result[j].Port)
+	})
+	return result
+}
@@ -0,0 +1,50 @@
+package aws
+
+import (
+	"context"
+
+	awssdk "github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	elb "github.com/aws/aws-sdk-go-v2/service/elasticloadbalancing"
+	elbv2 "github.com/aws/aws-sdk-go-v2/service/elasticloadbalancingv2"
+)
+
+// EC2API is a subset of the EC2 client methods used to enumerate public IP addresses
+type EC2API interface {
+	ec2.DescribeInstancesAPIClient
+	DescribeAddresses(ctx context.Context, params *ec2.DescribeAddressesInput,
+		optFns ...func(*ec2.Options)) (*ec2.DescribeAddressesOutput, error)
+}
+
+// ELBAPI is a subset of the Classic Load Balancer client methods
+type ELBAPI interface {
+	elb.DescribeLoadBalancersAPIClient
+	DescribeTags(ctx context.Context, params *elb.DescribeTagsInput,
+		optFns ...func(*elb.Options)) (*elb.DescribeTagsOutput, error)
+}
+
+// ELBv2API is a subset of the Application and Network Load Balancer client methods
+type ELBv2API interface {
+	elbv2.DescribeLoadBalancersAPIClient
+	elbv2.DescribeListenersAPIClient
+	DescribeTags(ctx context.Context, params *elbv2.DescribeTagsInput,
+		optFns ...func(*elbv2.Options)) (*elbv2.DescribeTagsOutput, error)
+}
+
+// Clients groups API clients of one region
+type Clients struct {
+	Region string
+	EC2    EC2API
+	ELB    ELBAPI
+	ELBv2  ELBv2API
+}
+
+// NewClients creates API clients for the region of the provided config
+func NewClients(cfg awssdk.Config) *Clients {
+	return &Clients{
+		Region: cfg.Region,
+		EC2:    ec2.NewFromConfig(cfg),
+		ELB:    elb.NewFromConfig(cfg),
+		ELBv2:  elbv2.NewFromConfig(cfg),
+	}
+}
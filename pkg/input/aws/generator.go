@@ -0,0 +1,431 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+
+	awssdk "github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	elb "github.com/aws/aws-sdk-go-v2/service/elasticloadbalancing"
+	elbtypes "github.com/aws/aws-sdk-go-v2/service/elasticloadbalancing/types"
+	elbv2 "github.com/aws/aws-sdk-go-v2/service/elasticloadbalancingv2"
+	elbv2types "github.com/aws/aws-sdk-go-v2/service/elasticloadbalancingv2/types"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+const (
+	ResourceInstances     = "instances"
+	ResourceAddresses     = "addresses"
+	ResourceLoadBalancers = "loadbalancers"
+
+	internetFacingScheme = "internet-facing"
+	// maximum number of load balancers in one DescribeTags request
+	maxTagResources = 20
+)
+
+var (
+	// DefaultResources are enumerated when no resources are specified
+	DefaultResources = []string{ResourceInstances, ResourceAddresses, ResourceLoadBalancers}
+
+	ErrPorts    = errors.New("aws input: ports are required to scan instances and addresses")
+	errResource = fmt.Errorf("invalid aws resource, only %s are valid", strings.Join(DefaultResources, ","))
+)
+
+type LookupIPFunc func(ctx context.Context, host string) ([]net.IP, error)
+
+type RequestGenerator struct {
+	clients   []*Clients
+	resources []string
+	ports     []*scan.PortRange
+	lookupIP  LookupIPFunc
+}
+
+// Assert that aws.RequestGenerator conforms to the scan.RequestGenerator interface
+var _ scan.RequestGenerator = (*RequestGenerator)(nil)
+
+type GeneratorOption func(*RequestGenerator)
+
+// WithResources sets the list of resources to enumerate public IP addresses from
+func WithResources(resources []string) GeneratorOption {
+	return func(g *RequestGenerator) {
+		g.resources = resources
+	}
+}
+
+// WithPorts sets ports to scan on public IP addresses of instances and Elastic IPs,
+// load balancers are scanned on their listener ports
+func WithPorts(ports []*scan.PortRange) GeneratorOption {
+	return func(g *RequestGenerator) {
+		g.ports = ports
+	}
+}
+
+// WithLookupIP sets the function to resolve DNS names of load balancers
+func WithLookupIP(lookupIP LookupIPFunc) GeneratorOption {
+	return func(g *RequestGenerator) {
+		g.lookupIP = lookupIP
+	}
+}
+
+// NewRequestGenerator creates a generator of scan requests for public IP addresses
+// of running EC2 instances, Elastic IPs and internet-facing load balancers in all given regions.
+// Tags of each resource are added to the request metadata.
+func NewRequestGenerator(clients []*Clients, opts ...GeneratorOption) (*RequestGenerator, error) {
+	g := &RequestGenerator{
+		clients:   clients,
+		resources: DefaultResources,
+		lookupIP: func(ctx context.Context, host string) ([]net.IP, error) {
+			return net.DefaultResolver.LookupIP(ctx, "ip4", host)
+		},
+	}
+	for _, o := range opts {
+		o(g)
+	}
+	for _, resource := range g.resources {
+		if resource != ResourceInstances && resource != ResourceAddresses && resource != ResourceLoadBalancers {
+			return nil, errResource
+		}
+	}
+	if len(g.ports) == 0 && (g.hasResource(ResourceInstances) || g.hasResource(ResourceAddresses)) {
+		return nil, ErrPorts
+	}
+	return g, nil
+}
+
+// host is a public IP address with the list of ports to scan,
+// ports of the generator are used if the list is empty
+type host struct {
+	ip    net.IP
+	ports []uint16
+	meta  map[string]interface{}
+	err   error
+}
+
+func (g *RequestGenerator) GenerateRequests(ctx context.Context, r *scan.Range) (<-chan *scan.Request, error) {
+	var hosts []*host
+	for _, clients := range g.clients {
+		regionHosts, err := g.hosts(ctx, clients)
+		if err != nil {
+			return nil, err
+		}
+		hosts = append(hosts, regionHosts...)
+	}
+	out := make(chan *scan.Request, 100)
+	go func() {
+		defer close(out)
+		for _, h := range hosts {
+			if h.err != nil {
+				writeRequest(ctx, out, &scan.Request{Err: h.err})
+				continue
+			}
+			if len(h.ports) > 0 {
+				for _, port := range h.ports {
+					writeRequest(ctx, out, &scan.Request{
+						SrcIP: r.SrcIP, SrcMAC: r.SrcMAC, DstIP: h.ip, DstPort: port, Meta: h.meta})
+				}
+				continue
+			}
+			for _, portRange := range g.ports {
+				for port := int(portRange.StartPort); port <= int(portRange.EndPort); port++ {
+					writeRequest(ctx, out, &scan.Request{
+						SrcIP: r.SrcIP, SrcMAC: r.SrcMAC, DstIP: h.ip, DstPort: uint16(port), Meta: h.meta})
+				}
+			}
+		}
+	}()
+	return out, nil
+}
+
+func writeRequest(ctx context.Context, out chan<- *scan.Request, request *scan.Request) {
+	select {
+	case <-ctx.Done():
+	case out <- request:
+	}
+}
+
+func (g *RequestGenerator) hosts(ctx context.Context, clients *Clients) (result []*host, err error) {
+	// Elastic IPs associated with instances are skipped as duplicates
+	seen := make(map[string]bool)
+	add := func(hosts []*host) {
+		for _, h := range hosts {
+			if h.ip != nil {
+				if seen[h.ip.String()] {
+					continue
+				}
+				seen[h.ip.String()] = true
+			}
+			result = append(result, h)
+		}
+	}
+	var hosts []*host
+	if g.hasResource(ResourceInstances) {
+		if hosts, err = g.instances(ctx, clients); err != nil {
+			return
+		}
+		add(hosts)
+	}
+	if g.hasResource(ResourceAddresses) {
+		if hosts, err = g.addresses(ctx, clients); err != nil {
+			return
+		}
+		add(hosts)
+	}
+	if g.hasResource(ResourceLoadBalancers) {
+		if hosts, err = g.classicLoadBalancers(ctx, clients); err != nil {
+			return
+		}
+		add(hosts)
+		if hosts, err = g.loadBalancers(ctx, clients); err != nil {
+			return
+		}
+		add(hosts)
+	}
+	return
+}
+
+func (g *RequestGenerator) instances(ctx context.Context, clients *Clients) (result []*host, err error) {
+	paginator := ec2.NewDescribeInstancesPaginator(clients.EC2, &ec2.DescribeInstancesInput{
+		Filters: []ec2types.Filter{
+			{Name: awssdk.String("instance-state-name"), Values: []string{"running"}},
+		},
+	})
+	for paginator.HasMorePages() {
+		var page *ec2.DescribeInstancesOutput
+		if page, err = paginator.NextPage(ctx); err != nil {
+			return
+		}
+		for _, reservation := range page.Reservations {
+			for i := range reservation.Instances {
+				instance := &reservation.Instances[i]
+				ip := net.ParseIP(awssdk.ToString(instance.PublicIpAddress)).To4()
+				if ip == nil {
+					continue
+				}
+				tags := make(map[string]string, len(instance.Tags))
+				for _, tag := range instance.Tags {
+					tags[awssdk.ToString(tag.Key)] = awssdk.ToString(tag.Value)
+				}
+				result = append(result, &host{
+					ip:   ip,
+					meta: newMeta("ec2", awssdk.ToString(instance.InstanceId), clients.Region, tags),
+				})
+			}
+		}
+	}
+	return
+}
+
+func (g *RequestGenerator) addresses(ctx context.Context, clients *Clients) (result []*host, err error) {
+	var out *ec2.DescribeAddressesOutput
+	if out, err = clients.EC2.DescribeAddresses(ctx, &ec2.DescribeAddressesInput{}); err != nil {
+		return
+	}
+	for i := range out.Addresses {
+		addr := &out.Addresses[i]
+		ip := net.ParseIP(awssdk.ToString(addr.PublicIp)).To4()
+		if ip == nil {
+			continue
+		}
+		tags := make(map[string]string, len(addr.Tags))
+		for _, tag := range addr.Tags {
+			tags[awssdk.ToString(tag.Key)] = awssdk.ToString(tag.Value)
+		}
+		result = append(result, &host{
+			ip:   ip,
+			meta: newMeta("eip", awssdk.ToString(addr.AllocationId), clients.Region, tags),
+		})
+	}
+	return
+}
+
+func (g *RequestGenerator) classicLoadBalancers(ctx context.Context, clients *Clients) (result []*host, err error) {
+	var lbs []elbtypes.LoadBalancerDescription
+	paginator := elb.NewDescribeLoadBalancersPaginator(clients.ELB, &elb.DescribeLoadBalancersInput{})
+	for paginator.HasMorePages() {
+		var page *elb.DescribeLoadBalancersOutput
+		if page, err = paginator.NextPage(ctx); err != nil {
+			return
+		}
+		for _, lb := range page.LoadBalancerDescriptions {
+			if awssdk.ToString(lb.Scheme) == internetFacingScheme {
+				lbs = append(lbs, lb)
+			}
+		}
+	}
+	names := make([]string, 0, len(lbs))
+	for _, lb := range lbs {
+		names = append(names, awssdk.ToString(lb.LoadBalancerName))
+	}
+	var tags map[string]map[string]string
+	if tags, err = classicLoadBalancerTags(ctx, clients.ELB, names); err != nil {
+		return
+	}
+	for _, lb := range lbs {
+		name := awssdk.ToString(lb.LoadBalancerName)
+		ports := make([]uint16, 0, len(lb.ListenerDescriptions))
+		for _, listener := range lb.ListenerDescriptions {
+			if listener.Listener != nil {
+				ports = append(ports, uint16(listener.Listener.LoadBalancerPort))
+			}
+		}
+		meta := newMeta("elb", name, clients.Region, tags[name])
+		result = append(result, g.resolveHosts(ctx, awssdk.ToString(lb.DNSName), ports, meta)...)
+	}
+	return
+}
+
+func classicLoadBalancerTags(ctx context.Context, client ELBAPI, names []string) (map[string]map[string]string, error) {
+	result := make(map[string]map[string]string)
+	for start := 0; start < len(names); start += maxTagResources {
+		end := start + maxTagResources
+		if end > len(names) {
+			end = len(names)
+		}
+		out, err := client.DescribeTags(ctx, &elb.DescribeTagsInput{LoadBalancerNames: names[start:end]})
+		if err != nil {
+			return nil, err
+		}
+		for _, desc := range out.TagDescriptions {
+			tags := make(map[string]string, len(desc.Tags))
+			for _, tag := range desc.Tags {
+				tags[awssdk.ToString(tag.Key)] = awssdk.ToString(tag.Value)
+			}
+			result[awssdk.ToString(desc.LoadBalancerName)] = tags
+		}
+	}
+	return result, nil
+}
+
+func (g *RequestGenerator) loadBalancers(ctx context.Context, clients *Clients) (result []*host, err error) {
+	var lbs []elbv2types.LoadBalancer
+	paginator := elbv2.NewDescribeLoadBalancersPaginator(clients.ELBv2, &elbv2.DescribeLoadBalancersInput{})
+	for paginator.HasMorePages() {
+		var page *elbv2.DescribeLoadBalancersOutput
+		if page, err = paginator.NextPage(ctx); err != nil {
+			return
+		}
+		for _, lb := range page.LoadBalancers {
+			if lb.Scheme == elbv2types.LoadBalancerSchemeEnumInternetFacing {
+				lbs = append(lbs, lb)
+			}
+		}
+	}
+	arns := make([]string, 0, len(lbs))
+	for _, lb := range lbs {
+		arns = append(arns, awssdk.ToString(lb.LoadBalancerArn))
+	}
+	var tags map[string]map[string]string
+	if tags, err = loadBalancerTags(ctx, clients.ELBv2, arns); err != nil {
+		return
+	}
+	for _, lb := range lbs {
+		arn := awssdk.ToString(lb.LoadBalancerArn)
+		var ports []uint16
+		if ports, err = listenerPorts(ctx, clients.ELBv2, arn); err != nil {
+			return
+		}
+		meta := newMeta("elbv2", awssdk.ToString(lb.LoadBalancerName), clients.Region, tags[arn])
+		// Network Load Balancers may have static Elastic IPs
+		var ips []net.IP
+		for _, zone := range lb.AvailabilityZones {
+			for _, addr := range zone.LoadBalancerAddresses {
+				if ip := net.ParseIP(awssdk.ToString(addr.IpAddress)).To4(); ip != nil {
+					ips = append(ips, ip)
+				}
+			}
+		}
+		if len(ips) == 0 {
+			result = append(result, g.resolveHosts(ctx, awssdk.ToString(lb.DNSName), ports, meta)...)
+			continue
+		}
+		for _, ip := range ips {
+			result = append(result, &host{ip: ip, ports: ports, meta: meta})
+		}
+	}
+	return
+}
+
+func loadBalancerTags(ctx context.Context, client ELBv2API, arns []string) (map[string]map[string]string, error) {
+	result := make(map[string]map[string]string)
+	for start := 0; start < len(arns); start += maxTagResources {
+		end := start + maxTagResources
+		if end > len(arns) {
+			end = len(arns)
+		}
+		out, err := client.DescribeTags(ctx, &elbv2.DescribeTagsInput{ResourceArns: arns[start:end]})
+		if err != nil {
+			return nil, err
+		}
+		for _, desc := range out.TagDescriptions {
+			tags := make(map[string]string, len(desc.Tags))
+			for _, tag := range desc.Tags {
+				tags[awssdk.ToString(tag.Key)] = awssdk.ToString(tag.Value)
+			}
+			result[awssdk.ToString(desc.ResourceArn)] = tags
+		}
+	}
+	return result, nil
+}
+
+func listenerPorts(ctx context.Context, client ELBv2API, arn string) (result []uint16, err error) {
+	paginator := elbv2.NewDescribeListenersPaginator(client, &elbv2.DescribeListenersInput{
+		LoadBalancerArn: awssdk.String(arn),
+	})
+	for paginator.HasMorePages() {
+		var page *elbv2.DescribeListenersOutput
+		if page, err = paginator.NextPage(ctx); err != nil {
+			return
+		}
+		for _, listener := range page.Listeners {
+			if listener.Port != nil {
+				result = append(result, uint16(*listener.Port))
+			}
+		}
+	}
+	return
+}
+
+// resolveHosts resolves DNS name of the load balancer,
+// resolution error is passed as a host to be reported by the scan engine
+func (g *RequestGenerator) resolveHosts(ctx context.Context, name string,
+	ports []uint16, meta map[string]interface{}) []*host {
+	if len(ports) == 0 {
+		return nil
+	}
+	ips, err := g.lookupIP(ctx, name)
+	if err != nil {
+		return []*host{{err: fmt.Errorf("aws input: %w", err)}}
+	}
+	result := make([]*host, 0, len(ips))
+	for _, ip := range ips {
+		if ip = ip.To4(); ip != nil {
+			result = append(result, &host{ip: ip, ports: ports, meta: meta})
+		}
+	}
+	return result
+}
+
+func (g *RequestGenerator) hasResource(resource string) bool {
+	for _, r := range g.resources {
+		if r == resource {
+			return true
+		}
+	}
+	return false
+}
+
+func newMeta(kind, id, region string, tags map[string]string) map[string]interface{} {
+	result := map[string]interface{}{
+		"kind":   kind,
+		"id":     id,
+		"region": region,
+	}
+	if len(tags) > 0 {
+		result["tags"] = tags
+	}
+	return result
+}
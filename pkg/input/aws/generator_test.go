@@ -0,0 +1,290 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"testing"
+
+	awssdk "github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	elb "github.com/aws/aws-sdk-go-v2/service/elasticloadbalancing"
+	elbtypes "github.com/aws/aws-sdk-go-v2/service/elasticloadbalancing/types"
+	elbv2 "github.com/aws/aws-sdk-go-v2/service/elasticloadbalancingv2"
+	elbv2types "github.com/aws/aws-sdk-go-v2/service/elasticloadbalancingv2/types"
+	"github.com/stretchr/testify/require"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+type fakeEC2 struct {
+	instances []ec2types.Instance
+	addresses []ec2types.Address
+	err       error
+}
+
+func (c *fakeEC2) DescribeInstances(_ context.Context, params *ec2.DescribeInstancesInput,
+	_ ...func(*ec2.Options)) (*ec2.DescribeInstancesOutput, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+	// return one instance per page to check pagination
+	i := 0
+	if params.NextToken != nil {
+		fmt.Sscan(*params.NextToken, &i)
+	}
+	out := &ec2.DescribeInstancesOutput{
+		Reservations: []ec2types.Reservation{{Instances: c.instances[i : i+1]}},
+	}
+	if i+1 < len(c.instances) {
+		out.NextToken = awssdk.String(fmt.Sprint(i + 1))
+	}
+	return out, nil
+}
+
+func (c *fakeEC2) DescribeAddresses(context.Context, *ec2.DescribeAddressesInput,
+	...func(*ec2.Options)) (*ec2.DescribeAddressesOutput, error) {
+	return &ec2.DescribeAddressesOutput{Addresses: c.addresses}, nil
+}
+
+type fakeELB struct {
+	lbs  []elbtypes.LoadBalancerDescription
+	tags map[string][]elbtypes.Tag
+}
+
+func (c *fakeELB) DescribeLoadBalancers(context.Context, *elb.DescribeLoadBalancersInput,
+	...func(*elb.Options)) (*elb.DescribeLoadBalancersOutput, error) {
+	return &elb.DescribeLoadBalancersOutput{LoadBalancerDescriptions: c.lbs}, nil
+}
+
+func (c *fakeELB) DescribeTags(_ context.Context, params *elb.DescribeTagsInput,
+	_ ...func(*elb.Options)) (*elb.DescribeTagsOutput, error) {
+	out := &elb.DescribeTagsOutput{}
+	for _, name := range params.LoadBalancerNames {
+		out.TagDescriptions = append(out.TagDescriptions, elbtypes.TagDescription{
+			LoadBalancerName: awssdk.String(name), Tags: c.tags[name]})
+	}
+	return out, nil
+}
+
+type fakeELBv2 struct {
+	lbs       []elbv2types.LoadBalancer
+	listeners map[string][]elbv2types.Listener
+	tags      map[string][]elbv2types.Tag
+}
+
+func (c *fakeELBv2) DescribeLoadBalancers(context.Context, *elbv2.DescribeLoadBalancersInput,
+	...func(*elbv2.Options)) (*elbv2.DescribeLoadBalancersOutput, error) {
+	return &elbv2.DescribeLoadBalancersOutput{LoadBalancers: c.lbs}, nil
+}
+
+func (c *fakeELBv2) DescribeListeners(_ context.Context, params *elbv2.DescribeListenersInput,
+	_ ...func(*elbv2.Options)) (*elbv2.DescribeListenersOutput, error) {
+	return &elbv2.DescribeListenersOutput{Listeners: c.listeners[*params.LoadBalancerArn]}, nil
+}
+
+func (c *fakeELBv2) DescribeTags(_ context.Context, params *elbv2.DescribeTagsInput,
+	_ ...func(*elbv2.Options)) (*elbv2.DescribeTagsOutput, error) {
+	out := &elbv2.DescribeTagsOutput{}
+	for _, arn := range params.ResourceArns {
+		out.TagDescriptions = append(out.TagDescriptions, elbv2types.TagDescription{
+			ResourceArn: awssdk.String(arn), Tags: c.tags[arn]})
+	}
+	return out, nil
+}
+
+func newTestClients() *Clients {
+	return &Clients{
+		Region: "us-east-1",
+		EC2: &fakeEC2{
+			instances: []ec2types.Instance{
+				{
+					InstanceId:      awssdk.String("i-1"),
+					PublicIpAddress: awssdk.String("203.0.113.1"),
+					Tags:            []ec2types.Tag{{Key: awssdk.String("env"), Value: awssdk.String("prod")}},
+				},
+				// private instance
+				{InstanceId: awssdk.String("i-2")},
+				{InstanceId: awssdk.String("i-3"), PublicIpAddress: awssdk.String("203.0.113.3")},
+			},
+			addresses: []ec2types.Address{
+				// associated with i-1
+				{AllocationId: awssdk.String("eipalloc-1"), PublicIp: awssdk.String("203.0.113.1")},
+				{
+					AllocationId: awssdk.String("eipalloc-2"),
+					PublicIp:     awssdk.String("203.0.113.2"),
+					Tags:         []ec2types.Tag{{Key: awssdk.String("team"), Value: awssdk.String("ops")}},
+				},
+			},
+		},
+		ELB: &fakeELB{
+			lbs: []elbtypes.LoadBalancerDescription{
+				{
+					LoadBalancerName: awssdk.String("classic"),
+					DNSName:          awssdk.String("classic.elb.amazonaws.com"),
+					Scheme:           awssdk.String("internet-facing"),
+					ListenerDescriptions: []elbtypes.ListenerDescription{
+						{Listener: &elbtypes.Listener{LoadBalancerPort: 80}},
+					},
+				},
+				{
+					LoadBalancerName: awssdk.String("internal"),
+					DNSName:          awssdk.String("internal.elb.amazonaws.com"),
+					Scheme:           awssdk.String("internal"),
+					ListenerDescriptions: []elbtypes.ListenerDescription{
+						{Listener: &elbtypes.Listener{LoadBalancerPort: 80}},
+					},
+				},
+			},
+			tags: map[string][]elbtypes.Tag{
+				"classic": {{Key: awssdk.String("app"), Value: awssdk.String("legacy")}},
+			},
+		},
+		ELBv2: &fakeELBv2{
+			lbs: []elbv2types.LoadBalancer{
+				{
+					LoadBalancerArn:  awssdk.String("arn:alb"),
+					LoadBalancerName: awssdk.String("alb"),
+					DNSName:          awssdk.String("alb.elb.amazonaws.com"),
+					Scheme:           elbv2types.LoadBalancerSchemeEnumInternetFacing,
+				},
+				{
+					LoadBalancerArn:  awssdk.String("arn:nlb"),
+					LoadBalancerName: awssdk.String("nlb"),
+					Scheme:           elbv2types.LoadBalancerSchemeEnumInternetFacing,
+					AvailabilityZones: []elbv2types.AvailabilityZone{
+						{LoadBalancerAddresses: []elbv2types.LoadBalancerAddress{{IpAddress: awssdk.String("198.51.100.1")}}},
+					},
+				},
+			},
+			listeners: map[string][]elbv2types.Listener{
+				"arn:alb": {{Port: awssdk.Int32(443)}, {Port: awssdk.Int32(80)}},
+				"arn:nlb": {{Port: awssdk.Int32(22)}},
+			},
+			tags: map[string][]elbv2types.Tag{
+				"arn:nlb": {{Key: awssdk.String("env"), Value: awssdk.String("stage")}},
+			},
+		},
+	}
+}
+
+func testLookupIP(_ context.Context, host string) ([]net.IP, error) {
+	switch host {
+	case "classic.elb.amazonaws.com":
+		return []net.IP{net.IPv4(192, 0, 2, 1)}, nil
+	case "alb.elb.amazonaws.com":
+		return []net.IP{net.IPv4(192, 0, 2, 2), net.ParseIP("2001:db8::1")}, nil
+	}
+	return nil, errors.New("not found")
+}
+
+func generateRequests(t *testing.T, g *RequestGenerator) (result []*scan.Request) {
+	t.Helper()
+	requests, err := g.GenerateRequests(context.Background(), &scan.Range{})
+	require.NoError(t, err)
+	for r := range requests {
+		result = append(result, r)
+	}
+	return
+}
+
+func requestTargets(requests []*scan.Request) (result []string) {
+	for _, r := range requests {
+		result = append(result, fmt.Sprintf("%s %s:%d", r.Meta["kind"], r.DstIP, r.DstPort))
+	}
+	sort.Strings(result)
+	return
+}
+
+func TestRequestGeneratorAllResources(t *testing.T) {
+	t.Parallel()
+	g, err := NewRequestGenerator([]*Clients{newTestClients()},
+		WithPorts([]*scan.PortRange{{StartPort: 22, EndPort: 23}}), WithLookupIP(testLookupIP))
+	require.NoError(t, err)
+
+	requests := generateRequests(t, g)
+	require.Equal(t, []string{
+		"ec2 203.0.113.1:22",
+		"ec2 203.0.113.1:23",
+		"ec2 203.0.113.3:22",
+		"ec2 203.0.113.3:23",
+		"eip 203.0.113.2:22",
+		"eip 203.0.113.2:23",
+		"elb 192.0.2.1:80",
+		"elbv2 192.0.2.2:443",
+		"elbv2 192.0.2.2:80",
+		"elbv2 198.51.100.1:22",
+	}, requestTargets(requests))
+}
+
+func TestRequestGeneratorMeta(t *testing.T) {
+	t.Parallel()
+	g, err := NewRequestGenerator([]*Clients{newTestClients()},
+		WithPorts([]*scan.PortRange{{StartPort: 22, EndPort: 22}}), WithLookupIP(testLookupIP))
+	require.NoError(t, err)
+
+	metas := make(map[string]map[string]interface{})
+	for _, r := range generateRequests(t, g) {
+		metas[r.DstIP.String()] = r.Meta
+	}
+	require.Equal(t, map[string]interface{}{
+		"kind": "ec2", "id": "i-1", "region": "us-east-1",
+		"tags": map[string]string{"env": "prod"},
+	}, metas["203.0.113.1"])
+	require.Equal(t, map[string]interface{}{
+		"kind": "ec2", "id": "i-3", "region": "us-east-1",
+	}, metas["203.0.113.3"])
+	require.Equal(t, map[string]interface{}{
+		"kind": "eip", "id": "eipalloc-2", "region": "us-east-1",
+		"tags": map[string]string{"team": "ops"},
+	}, metas["203.0.113.2"])
+	require.Equal(t, map[string]interface{}{
+		"kind": "elb", "id": "classic", "region": "us-east-1",
+		"tags": map[string]string{"app": "legacy"},
+	}, metas["192.0.2.1"])
+	require.Equal(t, map[string]interface{}{
+		"kind": "elbv2", "id": "nlb", "region": "us-east-1",
+		"tags": map[string]string{"env": "stage"},
+	}, metas["198.51.100.1"])
+}
+
+func TestRequestGeneratorLoadBalancersOnly(t *testing.T) {
+	t.Parallel()
+	g, err := NewRequestGenerator([]*Clients{newTestClients()},
+		WithResources([]string{ResourceLoadBalancers}),
+		WithLookupIP(func(context.Context, string) ([]net.IP, error) {
+			return nil, errors.New("lookup error")
+		}))
+	require.NoError(t, err)
+
+	requests := generateRequests(t, g)
+	require.Len(t, requests, 3)
+	// classic and application load balancers can not be resolved
+	require.Error(t, requests[0].Err)
+	require.Error(t, requests[1].Err)
+	require.NoError(t, requests[2].Err)
+	require.Equal(t, net.IPv4(198, 51, 100, 1).To4(), requests[2].DstIP)
+}
+
+func TestNewRequestGeneratorErrors(t *testing.T) {
+	t.Parallel()
+	_, err := NewRequestGenerator([]*Clients{newTestClients()})
+	require.ErrorIs(t, err, ErrPorts)
+
+	_, err = NewRequestGenerator([]*Clients{newTestClients()}, WithResources([]string{"buckets"}))
+	require.Error(t, err)
+}
+
+func TestRequestGeneratorAPIError(t *testing.T) {
+	t.Parallel()
+	clients := newTestClients()
+	clients.EC2.(*fakeEC2).err = errors.New("access denied")
+	g, err := NewRequestGenerator([]*Clients{clients},
+		WithPorts([]*scan.PortRange{{StartPort: 22, EndPort: 22}}))
+	require.NoError(t, err)
+
+	_, err = g.GenerateRequests(context.Background(), &scan.Range{})
+	require.Error(t, err)
+}
@@ -0,0 +1,108 @@
+package consul
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultHost = "127.0.0.1:8500"
+
+	defaultTimeout = 30 * time.Second
+)
+
+// Client is a minimal read-only Consul catalog API client
+type Client struct {
+	address    string
+	token      string
+	datacenter string
+	client     *http.Client
+}
+
+type ClientOption func(*Client)
+
+// WithToken sets the ACL token sent with every request
+func WithToken(token string) ClientOption {
+	return func(c *Client) {
+		c.token = token
+	}
+}
+
+// WithDatacenter sets the datacenter to query, the datacenter of the agent is used by default
+func WithDatacenter(datacenter string) ClientOption {
+	return func(c *Client) {
+		c.datacenter = datacenter
+	}
+}
+
+// NewClient creates catalog API client of the agent with the given base URL, e.g. http://127.0.0.1:8500
+func NewClient(address string, opts ...ClientOption) *Client {
+	c := &Client{
+		address: strings.TrimSuffix(address, "/"),
+		client:  &http.Client{Timeout: defaultTimeout},
+	}
+	for _, o := range opts {
+		o(c)
+	}
+	return c
+}
+
+// services returns names of all registered services with their tags
+func (c *Client) services(ctx context.Context) (result map[string][]string, err error) {
+	err = c.get(ctx, "/v1/catalog/services", nil, &result)
+	return
+}
+
+// service returns all instances of the service, only instances with the tag are returned if it is not empty
+func (c *Client) service(ctx context.Context, name, tag string) (result []*catalogService, err error) {
+	query := url.Values{}
+	if len(tag) > 0 {
+		query.Set("tag", tag)
+	}
+	err = c.get(ctx, "/v1/catalog/service/"+url.PathEscape(name), query, &result)
+	return
+}
+
+func (c *Client) get(ctx context.Context, path string, query url.Values, out interface{}) (err error) {
+	if query == nil {
+		query = url.Values{}
+	}
+	if len(c.datacenter) > 0 {
+		query.Set("dc", c.datacenter)
+	}
+	rawURL := c.address + path
+	if len(query) > 0 {
+		rawURL += "?" + query.Encode()
+	}
+	var req *http.Request
+	if req, err = http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil); err != nil {
+		return
+	}
+	if len(c.token) > 0 {
+		req.Header.Set("X-Consul-Token", c.token)
+	}
+	var resp *http.Response
+	if resp, err = c.client.Do(req); err != nil {
+		return
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("consul API %s: %s", path, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+type catalogService struct {
+	Node           string   `json:"Node"`
+	Address        string   `json:"Address"`
+	ServiceID      string   `json:"ServiceID"`
+	ServiceName    string   `json:"ServiceName"`
+	ServiceAddress string   `json:"ServiceAddress"`
+	ServicePort    int      `json:"ServicePort"`
+	ServiceTags    []string `json:"ServiceTags"`
+}
@@ -0,0 +1,169 @@
+package consul
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+type LookupIPFunc func(ctx context.Context, host string) ([]net.IP, error)
+
+type RequestGenerator struct {
+	client   *Client
+	services []string
+	tag      string
+	lookupIP LookupIPFunc
+}
+
+// Assert that consul.RequestGenerator conforms to the scan.RequestGenerator interface
+var _ scan.RequestGenerator = (*RequestGenerator)(nil)
+
+type GeneratorOption func(*RequestGenerator)
+
+// WithServices limits generated requests to instances of the given services, all services are used by default
+func WithServices(services []string) GeneratorOption {
+	return func(g *RequestGenerator) {
+		g.services = services
+	}
+}
+
+// WithTag limits generated requests to service instances with the given tag
+func WithTag(tag string) GeneratorOption {
+	return func(g *RequestGenerator) {
+		g.tag = tag
+	}
+}
+
+// WithLookupIP sets the function to resolve service addresses registered as DNS names
+func WithLookupIP(lookupIP LookupIPFunc) GeneratorOption {
+	return func(g *RequestGenerator) {
+		g.lookupIP = lookupIP
+	}
+}
+
+// NewRequestGenerator creates a generator of scan requests for addresses and ports
+// of service instances registered in the Consul catalog
+func NewRequestGenerator(client *Client, opts ...GeneratorOption) *RequestGenerator {
+	g := &RequestGenerator{
+		client: client,
+		lookupIP: func(ctx context.Context, host string) ([]net.IP, error) {
+			return net.DefaultResolver.LookupIP(ctx, "ip4", host)
+		},
+	}
+	for _, o := range opts {
+		o(g)
+	}
+	return g
+}
+
+type target struct {
+	ip   net.IP
+	port uint16
+	meta map[string]interface{}
+	err  error
+}
+
+func (g *RequestGenerator) GenerateRequests(ctx context.Context, r *scan.Range) (<-chan *scan.Request, error) {
+	targets, err := g.targets(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := make(chan *scan.Request, 100)
+	go func() {
+		defer close(out)
+		for _, t := range targets {
+			request := &scan.Request{Err: t.err}
+			if t.err == nil {
+				request = &scan.Request{
+					SrcIP: r.SrcIP, SrcMAC: r.SrcMAC,
+					DstIP: t.ip, DstPort: t.port, Meta: t.meta}
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case out <- request:
+			}
+		}
+	}()
+	return out, nil
+}
+
+func (g *RequestGenerator) targets(ctx context.Context) (result []*target, err error) {
+	services := g.services
+	if len(services) == 0 {
+		var catalog map[string][]string
+		if catalog, err = g.client.services(ctx); err != nil {
+			return
+		}
+		for name := range catalog {
+			services = append(services, name)
+		}
+		sort.Strings(services)
+	}
+	seen := make(map[string]bool)
+	for _, name := range services {
+		var instances []*catalogService
+		if instances, err = g.client.service(ctx, name, g.tag); err != nil {
+			return
+		}
+		for _, inst := range instances {
+			for _, t := range g.instanceTargets(ctx, inst) {
+				if t.err == nil {
+					key := fmt.Sprintf("%s:%d", t.ip, t.port)
+					if seen[key] {
+						continue
+					}
+					seen[key] = true
+				}
+				result = append(result, t)
+			}
+		}
+	}
+	return
+}
+
+// instanceTargets returns ip/port pairs of the service instance, the node address is used
+// if the service address is empty; resolution error is passed as a target to be reported by the scan engine
+func (g *RequestGenerator) instanceTargets(ctx context.Context, inst *catalogService) []*target {
+	if inst.ServicePort <= 0 || inst.ServicePort > 0xFFFF {
+		return nil
+	}
+	addr := inst.ServiceAddress
+	if len(addr) == 0 {
+		addr = inst.Address
+	}
+	meta := newMeta(inst)
+	if ip := net.ParseIP(addr); ip != nil {
+		if ip4 := ip.To4(); ip4 != nil {
+			ip = ip4
+		}
+		return []*target{{ip: ip, port: uint16(inst.ServicePort), meta: meta}}
+	}
+	ips, err := g.lookupIP(ctx, addr)
+	if err != nil {
+		return []*target{{err: fmt.Errorf("consul input: %w", err)}}
+	}
+	result := make([]*target, 0, len(ips))
+	for _, ip := range ips {
+		if ip = ip.To4(); ip != nil {
+			result = append(result, &target{ip: ip, port: uint16(inst.ServicePort), meta: meta})
+		}
+	}
+	return result
+}
+
+func newMeta(inst *catalogService) map[string]interface{} {
+	result := map[string]interface{}{
+		"kind":    "consul",
+		"service": inst.ServiceName,
+		"id":      inst.ServiceID,
+		"node":    inst.Node,
+	}
+	if len(inst.ServiceTags) > 0 {
+		result["tags"] = inst.ServiceTags
+	}
+	return result
+}
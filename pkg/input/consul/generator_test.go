@@ -0,0 +1,151 @@
+package consul
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+const (
+	testServices = `{"consul":[],"web":["prod","v1"],"db":[]}`
+	testWeb      = `[
+{"Node":"node1","Address":"10.0.0.1","ServiceID":"web-1","ServiceName":"web","ServiceAddress":"","ServicePort":80,"ServiceTags":["prod"]},
+{"Node":"node2","Address":"10.0.0.2","ServiceID":"web-2","ServiceName":"web","ServiceAddress":"172.16.0.2","ServicePort":8080,"ServiceTags":["v1"]}
+]`
+	testDB = `[
+{"Node":"node3","Address":"10.0.0.3","ServiceID":"db-1","ServiceName":"db","ServiceAddress":"db.service.local","ServicePort":5432},
+{"Node":"node3","Address":"10.0.0.3","ServiceID":"db-2","ServiceName":"db","ServiceAddress":"replica.service.local","ServicePort":5432}
+]`
+	testConsul = `[
+{"Node":"node1","Address":"10.0.0.1","ServiceID":"consul","ServiceName":"consul","ServicePort":8300}
+]`
+)
+
+type apiServer struct {
+	*httptest.Server
+	mu      sync.Mutex
+	queries []string
+}
+
+func newAPIServer(t *testing.T) *apiServer {
+	t.Helper()
+	srv := &apiServer{}
+	srv.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		srv.mu.Lock()
+		srv.queries = append(srv.queries, r.URL.RequestURI())
+		srv.mu.Unlock()
+		if r.Header.Get("X-Consul-Token") != "secret" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/catalog/services":
+			fmt.Fprint(w, testServices)
+		case "/v1/catalog/service/web":
+			fmt.Fprint(w, testWeb)
+		case "/v1/catalog/service/db":
+			fmt.Fprint(w, testDB)
+		case "/v1/catalog/service/consul":
+			fmt.Fprint(w, testConsul)
+		default:
+			fmt.Fprint(w, "[]")
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func (s *apiServer) Queries() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.queries
+}
+
+func testLookupIP(_ context.Context, host string) ([]net.IP, error) {
+	if host == "db.service.local" {
+		return []net.IP{net.IPv4(172, 16, 0, 3), net.ParseIP("2001:db8::3")}, nil
+	}
+	return nil, errors.New("not found")
+}
+
+func generateTargets(t *testing.T, g *RequestGenerator) (result []string) {
+	t.Helper()
+	requests, err := g.GenerateRequests(context.Background(), &scan.Range{})
+	require.NoError(t, err)
+	for r := range requests {
+		if r.Err != nil {
+			result = append(result, "error")
+			continue
+		}
+		result = append(result, fmt.Sprintf("%s %s:%d", r.Meta["id"], r.DstIP, r.DstPort))
+	}
+	return
+}
+
+func TestRequestGeneratorAllServices(t *testing.T) {
+	t.Parallel()
+	srv := newAPIServer(t)
+	g := NewRequestGenerator(NewClient(srv.URL, WithToken("secret"), WithDatacenter("dc1")),
+		WithLookupIP(testLookupIP))
+
+	require.Equal(t, []string{
+		"consul 10.0.0.1:8300",
+		"db-1 172.16.0.3:5432",
+		"error",
+		"web-1 10.0.0.1:80",
+		"web-2 172.16.0.2:8080",
+	}, generateTargets(t, g))
+	require.Equal(t, []string{
+		"/v1/catalog/services?dc=dc1",
+		"/v1/catalog/service/consul?dc=dc1",
+		"/v1/catalog/service/db?dc=dc1",
+		"/v1/catalog/service/web?dc=dc1",
+	}, srv.Queries())
+}
+
+func TestRequestGeneratorServicesWithTag(t *testing.T) {
+	t.Parallel()
+	srv := newAPIServer(t)
+	g := NewRequestGenerator(NewClient(srv.URL+"/", WithToken("secret")),
+		WithServices([]string{"web"}), WithTag("prod"))
+
+	require.Equal(t, []string{
+		"web-1 10.0.0.1:80",
+		"web-2 172.16.0.2:8080",
+	}, generateTargets(t, g))
+	require.Equal(t, []string{"/v1/catalog/service/web?tag=prod"}, srv.Queries())
+}
+
+func TestRequestGeneratorMeta(t *testing.T) {
+	t.Parallel()
+	srv := newAPIServer(t)
+	g := NewRequestGenerator(NewClient(srv.URL, WithToken("secret")), WithServices([]string{"web"}))
+
+	requests, err := g.GenerateRequests(context.Background(), &scan.Range{SrcIP: net.IPv4(10, 0, 0, 100)})
+	require.NoError(t, err)
+	r := <-requests
+	require.Equal(t, &scan.Request{
+		SrcIP:   net.IPv4(10, 0, 0, 100),
+		DstIP:   net.IPv4(10, 0, 0, 1).To4(),
+		DstPort: 80,
+		Meta: map[string]interface{}{
+			"kind": "consul", "service": "web", "id": "web-1", "node": "node1", "tags": []string{"prod"}},
+	}, r)
+}
+
+func TestRequestGeneratorAPIError(t *testing.T) {
+	t.Parallel()
+	srv := newAPIServer(t)
+	g := NewRequestGenerator(NewClient(srv.URL, WithToken("invalid")))
+
+	_, err := g.GenerateRequests(context.Background(), &scan.Range{})
+	require.Error(t, err)
+}
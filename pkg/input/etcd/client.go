@@ -0,0 +1,131 @@
+package etcd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultHost = "127.0.0.1:2379"
+
+	defaultTimeout = 30 * time.Second
+)
+
+// Client is a minimal read-only etcd v3 client using the JSON gRPC gateway
+type Client struct {
+	endpoint string
+	username string
+	password string
+	client   *http.Client
+}
+
+type ClientOption func(*Client)
+
+// WithAuth sets username and password to authenticate with
+func WithAuth(username, password string) ClientOption {
+	return func(c *Client) {
+		c.username = username
+		c.password = password
+	}
+}
+
+// NewClient creates etcd client of the endpoint with the given base URL, e.g. http://127.0.0.1:2379
+func NewClient(endpoint string, opts ...ClientOption) *Client {
+	c := &Client{
+		endpoint: strings.TrimSuffix(endpoint, "/"),
+		client:   &http.Client{Timeout: defaultTimeout},
+	}
+	for _, o := range opts {
+		o(c)
+	}
+	return c
+}
+
+type keyValue struct {
+	Key   []byte `json:"key"`
+	Value []byte `json:"value"`
+}
+
+type rangeRequest struct {
+	Key      []byte `json:"key"`
+	RangeEnd []byte `json:"range_end"`
+}
+
+type rangeResponse struct {
+	Kvs []*keyValue `json:"kvs"`
+}
+
+// rangePrefix returns all keys with the prefix, all keys are returned if the prefix is empty
+func (c *Client) rangePrefix(ctx context.Context, prefix string) (result []*keyValue, err error) {
+	var token string
+	if len(c.username) > 0 {
+		if token, err = c.authenticate(ctx); err != nil {
+			return
+		}
+	}
+	var resp rangeResponse
+	if err = c.post(ctx, "/v3/kv/range", token, &rangeRequest{
+		Key: rangeKey(prefix), RangeEnd: prefixRangeEnd(prefix)}, &resp); err != nil {
+		return
+	}
+	return resp.Kvs, nil
+}
+
+func (c *Client) authenticate(ctx context.Context) (string, error) {
+	var resp struct {
+		Token string `json:"token"`
+	}
+	err := c.post(ctx, "/v3/auth/authenticate", "", map[string]string{
+		"name": c.username, "password": c.password}, &resp)
+	return resp.Token, err
+}
+
+func (c *Client) post(ctx context.Context, path, token string, in, out interface{}) (err error) {
+	var body []byte
+	if body, err = json.Marshal(in); err != nil {
+		return
+	}
+	var req *http.Request
+	if req, err = http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint+path, bytes.NewReader(body)); err != nil {
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if len(token) > 0 {
+		req.Header.Set("Authorization", token)
+	}
+	var resp *http.Response
+	if resp, err = c.client.Do(req); err != nil {
+		return
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("etcd API %s: %s", path, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// rangeKey returns the first key of the prefix range, the zero byte selects all keys
+func rangeKey(prefix string) []byte {
+	if len(prefix) == 0 {
+		return []byte{0}
+	}
+	return []byte(prefix)
+}
+
+// prefixRangeEnd returns the key following all keys with the prefix
+func prefixRangeEnd(prefix string) []byte {
+	end := []byte(prefix)
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xff {
+			end[i]++
+			return end[:i+1]
+		}
+	}
+	// no next key, range to the end of the keyspace
+	return []byte{0}
+}
@@ -0,0 +1,202 @@
+package etcd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+type LookupIPFunc func(ctx context.Context, host string) ([]net.IP, error)
+
+type RequestGenerator struct {
+	client   *Client
+	prefix   string
+	lookupIP LookupIPFunc
+}
+
+// Assert that etcd.RequestGenerator conforms to the scan.RequestGenerator interface
+var _ scan.RequestGenerator = (*RequestGenerator)(nil)
+
+type GeneratorOption func(*RequestGenerator)
+
+// WithPrefix limits read keys to the given prefix, e.g. /services/, all keys are read by default
+func WithPrefix(prefix string) GeneratorOption {
+	return func(g *RequestGenerator) {
+		g.prefix = prefix
+	}
+}
+
+// WithLookupIP sets the function to resolve service addresses registered as DNS names
+func WithLookupIP(lookupIP LookupIPFunc) GeneratorOption {
+	return func(g *RequestGenerator) {
+		g.lookupIP = lookupIP
+	}
+}
+
+// NewRequestGenerator creates a generator of scan requests for service addresses registered in etcd.
+// Values of keys are expected to be host:port pairs, URLs or JSON objects with
+// an address field (Addr, addr, address) or host and port fields, other values are skipped.
+func NewRequestGenerator(client *Client, opts ...GeneratorOption) *RequestGenerator {
+	g := &RequestGenerator{
+		client: client,
+		lookupIP: func(ctx context.Context, host string) ([]net.IP, error) {
+			return net.DefaultResolver.LookupIP(ctx, "ip4", host)
+		},
+	}
+	for _, o := range opts {
+		o(g)
+	}
+	return g
+}
+
+type target struct {
+	ip   net.IP
+	port uint16
+	meta map[string]interface{}
+	err  error
+}
+
+func (g *RequestGenerator) GenerateRequests(ctx context.Context, r *scan.Range) (<-chan *scan.Request, error) {
+	targets, err := g.targets(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := make(chan *scan.Request, 100)
+	go func() {
+		defer close(out)
+		for _, t := range targets {
+			request := &scan.Request{Err: t.err}
+			if t.err == nil {
+				request = &scan.Request{
+					SrcIP: r.SrcIP, SrcMAC: r.SrcMAC,
+					DstIP: t.ip, DstPort: t.port, Meta: t.meta}
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case out <- request:
+			}
+		}
+	}()
+	return out, nil
+}
+
+func (g *RequestGenerator) targets(ctx context.Context) (result []*target, err error) {
+	var kvs []*keyValue
+	if kvs, err = g.client.rangePrefix(ctx, g.prefix); err != nil {
+		return
+	}
+	seen := make(map[string]bool)
+	for _, kv := range kvs {
+		host, port, ok := parseAddress(kv.Value)
+		if !ok {
+			continue
+		}
+		for _, t := range g.keyTargets(ctx, string(kv.Key), host, port) {
+			if t.err == nil {
+				key := fmt.Sprintf("%s:%d", t.ip, t.port)
+				if seen[key] {
+					continue
+				}
+				seen[key] = true
+			}
+			result = append(result, t)
+		}
+	}
+	return
+}
+
+// keyTargets resolves the host of the registered address,
+// resolution error is passed as a target to be reported by the scan engine
+func (g *RequestGenerator) keyTargets(ctx context.Context, key, host string, port uint16) []*target {
+	meta := map[string]interface{}{"kind": "etcd", "key": key}
+	if ip := net.ParseIP(host); ip != nil {
+		if ip4 := ip.To4(); ip4 != nil {
+			ip = ip4
+		}
+		return []*target{{ip: ip, port: port, meta: meta}}
+	}
+	ips, err := g.lookupIP(ctx, host)
+	if err != nil {
+		return []*target{{err: fmt.Errorf("etcd input: %w", err)}}
+	}
+	result := make([]*target, 0, len(ips))
+	for _, ip := range ips {
+		if ip = ip.To4(); ip != nil {
+			result = append(result, &target{ip: ip, port: port, meta: meta})
+		}
+	}
+	return result
+}
+
+// parseAddress extracts host and port from the registration value
+func parseAddress(value []byte) (host string, port uint16, ok bool) {
+	value = []byte(strings.TrimSpace(string(value)))
+	if len(value) > 0 && value[0] == '{' {
+		return parseJSONAddress(value)
+	}
+	return splitHostPort(string(value))
+}
+
+func parseJSONAddress(value []byte) (host string, port uint16, ok bool) {
+	var fields map[string]interface{}
+	if err := json.Unmarshal(value, &fields); err != nil {
+		return
+	}
+	for _, name := range []string{"Addr", "addr", "Address", "address"} {
+		if addr, isString := fields[name].(string); isString {
+			if host, port, ok = splitHostPort(addr); ok {
+				return
+			}
+		}
+	}
+	for _, name := range []string{"Host", "host", "IP", "ip"} {
+		if h, isString := fields[name].(string); isString && len(h) > 0 {
+			host = h
+			break
+		}
+	}
+	for _, name := range []string{"Port", "port"} {
+		switch p := fields[name].(type) {
+		case float64:
+			if p > 0 && p <= 0xFFFF {
+				port = uint16(p)
+			}
+		case string:
+			if v, err := strconv.ParseUint(p, 10, 16); err == nil {
+				port = uint16(v)
+			}
+		}
+		if port > 0 {
+			break
+		}
+	}
+	ok = len(host) > 0 && port > 0
+	return
+}
+
+// splitHostPort parses host:port pair or URL with explicit port
+func splitHostPort(addr string) (host string, port uint16, ok bool) {
+	if strings.Contains(addr, "://") {
+		u, err := url.Parse(addr)
+		if err != nil {
+			return
+		}
+		addr = u.Host
+	}
+	host, rawPort, err := net.SplitHostPort(addr)
+	if err != nil || len(host) == 0 {
+		return
+	}
+	v, err := strconv.ParseUint(rawPort, 10, 16)
+	if err != nil || v == 0 {
+		return
+	}
+	return host, uint16(v), true
+}
@@ -0,0 +1,165 @@
+package etcd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+var testKeys = []*keyValue{
+	{Key: []byte("/services/web/1"), Value: []byte("10.0.0.1:80")},
+	{Key: []byte("/services/web/2"), Value: []byte(`{"Op":0,"Addr":"10.0.0.2:8080","Metadata":null}`)},
+	{Key: []byte("/services/web/3"), Value: []byte(`{"host":"web.service.local","port":"8443"}`)},
+	{Key: []byte("/services/api/1"), Value: []byte("https://10.0.0.3:443/v1")},
+	{Key: []byte("/services/api/2"), Value: []byte("missing.service.local:9000")},
+	// duplicate registration
+	{Key: []byte("/services/api/3"), Value: []byte(`{"address":"10.0.0.1:80"}`)},
+	{Key: []byte("/services/config"), Value: []byte(`{"ttl":30}`)},
+}
+
+func newAPIServer(t *testing.T, password string) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v3/auth/authenticate":
+			var req map[string]string
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+			if req["name"] != "root" || req["password"] != password {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			fmt.Fprint(w, `{"token":"root-token"}`)
+		case "/v3/kv/range":
+			if len(password) > 0 && r.Header.Get("Authorization") != "root-token" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			var req rangeRequest
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+			var resp rangeResponse
+			for _, kv := range testKeys {
+				// zero range end means all keys greater than or equal to the key
+				if string(kv.Key) >= string(req.Key) &&
+					(string(req.RangeEnd) == "\x00" || string(kv.Key) < string(req.RangeEnd)) {
+					resp.Kvs = append(resp.Kvs, kv)
+				}
+			}
+			require.NoError(t, json.NewEncoder(w).Encode(&resp))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func testLookupIP(_ context.Context, host string) ([]net.IP, error) {
+	if host == "web.service.local" {
+		return []net.IP{net.IPv4(172, 16, 0, 1), net.ParseIP("2001:db8::1")}, nil
+	}
+	return nil, errors.New("not found")
+}
+
+func generateTargets(t *testing.T, g *RequestGenerator) (result []string) {
+	t.Helper()
+	requests, err := g.GenerateRequests(context.Background(), &scan.Range{})
+	require.NoError(t, err)
+	for r := range requests {
+		if r.Err != nil {
+			result = append(result, "error")
+			continue
+		}
+		result = append(result, fmt.Sprintf("%s %s:%d", r.Meta["key"], r.DstIP, r.DstPort))
+	}
+	return
+}
+
+func TestRequestGeneratorPrefix(t *testing.T) {
+	t.Parallel()
+	srv := newAPIServer(t, "")
+	g := NewRequestGenerator(NewClient(srv.URL), WithPrefix("/services/web/"), WithLookupIP(testLookupIP))
+
+	require.Equal(t, []string{
+		"/services/web/1 10.0.0.1:80",
+		"/services/web/2 10.0.0.2:8080",
+		"/services/web/3 172.16.0.1:8443",
+	}, generateTargets(t, g))
+}
+
+func TestRequestGeneratorWithAuth(t *testing.T) {
+	t.Parallel()
+	srv := newAPIServer(t, "secret")
+	g := NewRequestGenerator(NewClient(srv.URL, WithAuth("root", "secret")),
+		WithPrefix("/services/api/"), WithLookupIP(testLookupIP))
+
+	require.Equal(t, []string{
+		"/services/api/1 10.0.0.3:443",
+		"error",
+		"/services/api/3 10.0.0.1:80",
+	}, generateTargets(t, g))
+}
+
+func TestRequestGeneratorAllKeys(t *testing.T) {
+	t.Parallel()
+	srv := newAPIServer(t, "")
+	g := NewRequestGenerator(NewClient(srv.URL+"/"), WithLookupIP(testLookupIP))
+
+	targets := generateTargets(t, g)
+	require.Len(t, targets, 5)
+	require.Equal(t, "/services/web/1 10.0.0.1:80", targets[0])
+}
+
+func TestRequestGeneratorAuthError(t *testing.T) {
+	t.Parallel()
+	srv := newAPIServer(t, "secret")
+	g := NewRequestGenerator(NewClient(srv.URL, WithAuth("root", "invalid")))
+
+	_, err := g.GenerateRequests(context.Background(), &scan.Range{})
+	require.Error(t, err)
+}
+
+func TestPrefixRangeEnd(t *testing.T) {
+	t.Parallel()
+	require.Equal(t, []byte("/services0"), prefixRangeEnd("/services/"))
+	require.Equal(t, []byte("b"), prefixRangeEnd("a\xff"))
+	require.Equal(t, []byte{0}, prefixRangeEnd("\xff"))
+	require.Equal(t, []byte{0}, prefixRangeEnd(""))
+}
+
+func TestParseAddress(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		value string
+		host  string
+		port  uint16
+		ok    bool
+	}{
+		{value: "10.0.0.1:80", host: "10.0.0.1", port: 80, ok: true},
+		{value: " [2001:db8::1]:443\n", host: "2001:db8::1", port: 443, ok: true},
+		{value: "http://example.com:8080/health", host: "example.com", port: 8080, ok: true},
+		{value: `{"Addr":"10.0.0.1:9000"}`, host: "10.0.0.1", port: 9000, ok: true},
+		{value: `{"ip":"10.0.0.1","Port":53}`, host: "10.0.0.1", port: 53, ok: true},
+		{value: `{"host":"10.0.0.1","port":70000}`},
+		{value: "http://example.com/health"},
+		{value: "10.0.0.1"},
+		{value: "10.0.0.1:0"},
+		{value: "enabled"},
+		{value: "{invalid"},
+	}
+	for _, tt := range tests {
+		host, port, ok := parseAddress([]byte(tt.value))
+		require.Equal(t, tt.ok, ok, tt.value)
+		if tt.ok {
+			require.Equal(t, tt.host, host, tt.value)
+			require.Equal(t, tt.port, port, tt.value)
+		}
+	}
+}
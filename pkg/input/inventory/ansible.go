@@ -0,0 +1,430 @@
+package inventory
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/v-byte-cpu/sx/pkg/scan"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// PortsVar is the host or group variable with the list of ports declared as open,
+	// e.g. sx_ports=80,443,8000-8080
+	PortsVar = "sx_ports"
+
+	defaultSSHPort = 22
+)
+
+var (
+	ErrGroup = errors.New("ansible inventory: group not found")
+
+	errHostPattern = errors.New("ansible inventory: invalid host pattern")
+)
+
+type ansibleGroup struct {
+	hosts    []string
+	vars     map[string]interface{}
+	children []string
+}
+
+type ansibleInventory struct {
+	groups   map[string]*ansibleGroup
+	hostVars map[string]map[string]interface{}
+	// hosts in the order of appearance
+	hosts []string
+}
+
+func newAnsibleInventory() *ansibleInventory {
+	return &ansibleInventory{
+		groups:   map[string]*ansibleGroup{"all": {vars: map[string]interface{}{}}},
+		hostVars: make(map[string]map[string]interface{}),
+	}
+}
+
+func (inv *ansibleInventory) group(name string) *ansibleGroup {
+	g, ok := inv.groups[name]
+	if !ok {
+		g = &ansibleGroup{vars: make(map[string]interface{})}
+		inv.groups[name] = g
+	}
+	return g
+}
+
+func (inv *ansibleInventory) addHost(group, name string, vars map[string]interface{}) {
+	hostVars, ok := inv.hostVars[name]
+	if !ok {
+		hostVars = make(map[string]interface{})
+		inv.hostVars[name] = hostVars
+		inv.hosts = append(inv.hosts, name)
+	}
+	for k, v := range vars {
+		hostVars[k] = v
+	}
+	g := inv.group(group)
+	g.hosts = append(g.hosts, name)
+}
+
+// LoadAnsibleInventoryFile reads hosts from the Ansible inventory file in INI or YAML format,
+// only hosts of the group are returned if the group name is not empty
+func LoadAnsibleInventoryFile(path, group string) ([]*Host, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseAnsibleInventory(bytes.NewReader(data), group)
+}
+
+// ParseAnsibleInventory reads hosts from the Ansible inventory in INI or YAML format.
+// The address of a host is taken from the ansible_host variable or the host name,
+// declared ports of a host are the SSH port (ansible_port, 22 by default) and ports of the sx_ports variable.
+// Variables of groups are inherited by their hosts with the same precedence as in Ansible.
+func ParseAnsibleInventory(r io.Reader, group string) ([]*Host, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	var inv *ansibleInventory
+	if isYAMLInventory(data) {
+		inv, err = parseAnsibleYAML(data)
+	} else {
+		inv, err = parseAnsibleINI(data)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return inv.resolveHosts(group)
+}
+
+// isYAMLInventory checks whether the first significant line is a YAML mapping key, e.g. all:
+func isYAMLInventory(data []byte) bool {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 || line[0] == '#' || line[0] == ';' || line == "---" {
+			continue
+		}
+		return strings.HasSuffix(line, ":") && !strings.HasPrefix(line, "[")
+	}
+	return false
+}
+
+func parseAnsibleINI(data []byte) (*ansibleInventory, error) {
+	inv := newAnsibleInventory()
+	group, section := "ungrouped", "hosts"
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 || line[0] == '#' || line[0] == ';' {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			group, section = line[1:len(line)-1], "hosts"
+			if i := strings.IndexByte(group, ':'); i >= 0 {
+				group, section = group[:i], group[i+1:]
+			}
+			inv.group(group)
+			continue
+		}
+		fields := strings.Fields(line)
+		switch section {
+		case "hosts":
+			names, err := expandHostPattern(fields[0])
+			if err != nil {
+				return nil, err
+			}
+			vars := parseINIVars(fields[1:])
+			for _, name := range names {
+				inv.addHost(group, name, vars)
+			}
+		case "vars":
+			for k, v := range parseINIVars([]string{line}) {
+				inv.group(group).vars[k] = v
+			}
+		case "children":
+			inv.group(fields[0])
+			g := inv.group(group)
+			g.children = append(g.children, fields[0])
+		}
+	}
+	return inv, scanner.Err()
+}
+
+// parseINIVars parses key=value pairs, quoted values are unquoted
+func parseINIVars(pairs []string) map[string]interface{} {
+	result := make(map[string]interface{}, len(pairs))
+	for _, pair := range pairs {
+		parts := strings.SplitN(pair, "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		key, value := strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])
+		if unquoted, err := strconv.Unquote(value); err == nil {
+			value = unquoted
+		} else if len(value) > 1 && value[0] == '\'' && value[len(value)-1] == '\'' {
+			value = value[1 : len(value)-1]
+		}
+		result[key] = value
+	}
+	return result
+}
+
+// expandHostPattern expands the numeric range of the host pattern, e.g. web[01:03].example.com
+func expandHostPattern(pattern string) ([]string, error) {
+	start := strings.IndexByte(pattern, '[')
+	if start < 0 {
+		return []string{pattern}, nil
+	}
+	end := strings.IndexByte(pattern[start:], ']')
+	if end < 0 {
+		return nil, errHostPattern
+	}
+	end += start
+	bounds := strings.SplitN(pattern[start+1:end], ":", 2)
+	if len(bounds) != 2 {
+		return nil, errHostPattern
+	}
+	from, err1 := strconv.Atoi(bounds[0])
+	to, err2 := strconv.Atoi(bounds[1])
+	if err1 != nil || err2 != nil || from > to {
+		return nil, errHostPattern
+	}
+	result := make([]string, 0, to-from+1)
+	for i := from; i <= to; i++ {
+		result = append(result, fmt.Sprintf("%s%0*d%s", pattern[:start], len(bounds[0]), i, pattern[end+1:]))
+	}
+	return result, nil
+}
+
+type yamlGroup struct {
+	Hosts    map[string]map[string]interface{} `yaml:"hosts"`
+	Vars     map[string]interface{}            `yaml:"vars"`
+	Children map[string]*yamlGroup             `yaml:"children"`
+}
+
+func parseAnsibleYAML(data []byte) (*ansibleInventory, error) {
+	var groups map[string]*yamlGroup
+	if err := yaml.Unmarshal(data, &groups); err != nil {
+		return nil, err
+	}
+	inv := newAnsibleInventory()
+	// sort groups to keep the order of hosts stable
+	names := make([]string, 0, len(groups))
+	for name := range groups {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		inv.addYAMLGroup(name, groups[name])
+	}
+	return inv, nil
+}
+
+func (inv *ansibleInventory) addYAMLGroup(name string, yg *yamlGroup) {
+	g := inv.group(name)
+	if yg == nil {
+		return
+	}
+	for k, v := range yg.Vars {
+		g.vars[k] = v
+	}
+	hosts := make([]string, 0, len(yg.Hosts))
+	for host := range yg.Hosts {
+		hosts = append(hosts, host)
+	}
+	sort.Strings(hosts)
+	for _, host := range hosts {
+		inv.addHost(name, host, yg.Hosts[host])
+	}
+	children := make([]string, 0, len(yg.Children))
+	for child := range yg.Children {
+		children = append(children, child)
+	}
+	sort.Strings(children)
+	for _, child := range children {
+		g.children = append(g.children, child)
+		inv.addYAMLGroup(child, yg.Children[child])
+	}
+}
+
+// parents returns the map of group names to names of their parent groups,
+// all groups without parents are children of the all group
+func (inv *ansibleInventory) parents() map[string][]string {
+	result := make(map[string][]string)
+	for name, g := range inv.groups {
+		for _, child := range g.children {
+			result[child] = append(result[child], name)
+		}
+	}
+	for name := range inv.groups {
+		if name != "all" && len(result[name]) == 0 {
+			result[name] = []string{"all"}
+		}
+	}
+	return result
+}
+
+// depth returns the longest distance from the all group, cycles are ignored
+func depth(name string, parents map[string][]string, cache map[string]int, visiting map[string]bool) int {
+	if d, ok := cache[name]; ok {
+		return d
+	}
+	if visiting[name] {
+		return 0
+	}
+	visiting[name] = true
+	d := 0
+	for _, parent := range parents[name] {
+		if pd := depth(parent, parents, cache, visiting) + 1; pd > d {
+			d = pd
+		}
+	}
+	visiting[name] = false
+	cache[name] = d
+	return d
+}
+
+// hostGroups returns all groups of the host including ancestor groups
+func (inv *ansibleInventory) hostGroups(host string, parents map[string][]string) map[string]bool {
+	result := map[string]bool{"all": true}
+	var walk func(group string)
+	walk = func(group string) {
+		if result[group] {
+			return
+		}
+		result[group] = true
+		for _, parent := range parents[group] {
+			walk(parent)
+		}
+	}
+	for name, g := range inv.groups {
+		for _, h := range g.hosts {
+			if h == host {
+				walk(name)
+				break
+			}
+		}
+	}
+	return result
+}
+
+func (inv *ansibleInventory) resolveHosts(group string) (result []*Host, err error) {
+	if _, ok := inv.groups[group]; len(group) > 0 && !ok {
+		return nil, ErrGroup
+	}
+	parents := inv.parents()
+	depths := make(map[string]int)
+	for _, host := range inv.hosts {
+		groups := inv.hostGroups(host, parents)
+		if len(group) > 0 && !groups[group] {
+			continue
+		}
+		groupNames := make([]string, 0, len(groups))
+		for name := range groups {
+			groupNames = append(groupNames, name)
+		}
+		// variables of child groups override variables of parent groups
+		sort.Slice(groupNames, func(i, j int) bool {
+			di := depth(groupNames[i], parents, depths, map[string]bool{})
+			dj := depth(groupNames[j], parents, depths, map[string]bool{})
+			if di != dj {
+				return di < dj
+			}
+			return groupNames[i] < groupNames[j]
+		})
+		vars := make(map[string]interface{})
+		for _, name := range groupNames {
+			for k, v := range inv.groups[name].vars {
+				vars[k] = v
+			}
+		}
+		for k, v := range inv.hostVars[host] {
+			vars[k] = v
+		}
+		var h *Host
+		if h, err = newAnsibleHost(host, vars, groupNames); err != nil {
+			return
+		}
+		result = append(result, h)
+	}
+	return
+}
+
+func newAnsibleHost(name string, vars map[string]interface{}, groups []string) (*Host, error) {
+	addr, sshPort := splitHostNamePort(name)
+	if v, ok := vars["ansible_host"]; ok {
+		addr = fmt.Sprint(v)
+	}
+	for _, key := range []string{"ansible_port", "ansible_ssh_port"} {
+		if v, ok := vars[key]; ok {
+			port, err := strconv.ParseUint(fmt.Sprint(v), 10, 16)
+			if err != nil || port == 0 {
+				return nil, fmt.Errorf("ansible inventory: invalid %s of host %s", key, name)
+			}
+			sshPort = uint16(port)
+			break
+		}
+	}
+	ports := []*scan.PortRange{{StartPort: sshPort, EndPort: sshPort}}
+	if v, ok := vars[PortsVar]; ok {
+		declared, err := parsePortsVar(v)
+		if err != nil {
+			return nil, fmt.Errorf("ansible inventory: invalid %s of host %s: %w", PortsVar, name, err)
+		}
+		ports = append(ports, declared...)
+	}
+	var hostGroups []string
+	for _, g := range groups {
+		if g != "all" && g != "ungrouped" {
+			hostGroups = append(hostGroups, g)
+		}
+	}
+	meta := map[string]interface{}{"kind": "ansible"}
+	if len(hostGroups) > 0 {
+		sort.Strings(hostGroups)
+		meta["groups"] = hostGroups
+	}
+	return &Host{Name: name, Addr: addr, Ports: ports, Meta: meta}, nil
+}
+
+// splitHostNamePort splits the inventory host name in the form host:port
+func splitHostNamePort(name string) (string, uint16) {
+	if i := strings.LastIndexByte(name, ':'); i > 0 && strings.Count(name, ":") == 1 {
+		if port, err := strconv.ParseUint(name[i+1:], 10, 16); err == nil && port > 0 {
+			return name[:i], uint16(port)
+		}
+	}
+	return name, defaultSSHPort
+}
+
+// parsePortsVar parses comma-separated string or list of ports and port ranges
+func parsePortsVar(v interface{}) (result []*scan.PortRange, err error) {
+	var items []string
+	switch value := v.(type) {
+	case []interface{}:
+		for _, item := range value {
+			items = append(items, fmt.Sprint(item))
+		}
+	default:
+		items = strings.Split(fmt.Sprint(value), ",")
+	}
+	for _, item := range items {
+		item = strings.Trim(strings.TrimSpace(item), "[]'\"")
+		if len(item) == 0 {
+			continue
+		}
+		var portRange *scan.PortRange
+		if portRange, err = parsePortRange(item); err != nil {
+			return
+		}
+		result = append(result, portRange)
+	}
+	return
+}
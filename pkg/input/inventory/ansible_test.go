@@ -0,0 +1,129 @@
+package inventory
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+const testAnsibleINI = `
+# ungrouped host
+bastion.example.com:2222
+
+[web]
+web[01:02].example.com
+web3 ansible_host=10.0.0.3 sx_ports=8080
+
+[web:vars]
+sx_ports="80,443"
+
+[db]
+db1 ansible_host=10.0.1.1 ansible_port=2200
+
+[prod:children]
+web
+db
+
+[prod:vars]
+sx_ports=9100
+ansible_port=2022
+`
+
+const testAnsibleYAML = `
+all:
+  vars:
+    sx_ports: [9100]
+  hosts:
+    bastion.example.com:
+  children:
+    web:
+      vars:
+        sx_ports:
+          - 80
+          - "8000-8080"
+      hosts:
+        web1:
+          ansible_host: 10.0.0.1
+        web2:
+          ansible_host: 10.0.0.2
+          ansible_port: 2222
+          sx_ports: 443
+`
+
+func portRanges(ports ...uint16) (result []*scan.PortRange) {
+	for _, port := range ports {
+		result = append(result, &scan.PortRange{StartPort: port, EndPort: port})
+	}
+	return
+}
+
+func TestParseAnsibleInventoryINI(t *testing.T) {
+	t.Parallel()
+	hosts, err := ParseAnsibleInventory(strings.NewReader(testAnsibleINI), "")
+	require.NoError(t, err)
+
+	webMeta := map[string]interface{}{"kind": "ansible", "groups": []string{"prod", "web"}}
+	require.Equal(t, []*Host{
+		{Name: "bastion.example.com:2222", Addr: "bastion.example.com",
+			Ports: portRanges(2222), Meta: map[string]interface{}{"kind": "ansible"}},
+		{Name: "web01.example.com", Addr: "web01.example.com", Ports: portRanges(2022, 80, 443), Meta: webMeta},
+		{Name: "web02.example.com", Addr: "web02.example.com", Ports: portRanges(2022, 80, 443), Meta: webMeta},
+		{Name: "web3", Addr: "10.0.0.3", Ports: portRanges(2022, 8080), Meta: webMeta},
+		{Name: "db1", Addr: "10.0.1.1", Ports: portRanges(2200, 9100),
+			Meta: map[string]interface{}{"kind": "ansible", "groups": []string{"db", "prod"}}},
+	}, hosts)
+}
+
+func TestParseAnsibleInventoryYAML(t *testing.T) {
+	t.Parallel()
+	hosts, err := ParseAnsibleInventory(strings.NewReader(testAnsibleYAML), "")
+	require.NoError(t, err)
+
+	webMeta := map[string]interface{}{"kind": "ansible", "groups": []string{"web"}}
+	require.Equal(t, []*Host{
+		{Name: "bastion.example.com", Addr: "bastion.example.com",
+			Ports: portRanges(22, 9100), Meta: map[string]interface{}{"kind": "ansible"}},
+		{Name: "web1", Addr: "10.0.0.1",
+			Ports: append(portRanges(22, 80), &scan.PortRange{StartPort: 8000, EndPort: 8080}), Meta: webMeta},
+		{Name: "web2", Addr: "10.0.0.2", Ports: portRanges(2222, 443), Meta: webMeta},
+	}, hosts)
+}
+
+func TestParseAnsibleInventoryGroup(t *testing.T) {
+	t.Parallel()
+	hosts, err := ParseAnsibleInventory(strings.NewReader(testAnsibleINI), "db")
+	require.NoError(t, err)
+	require.Len(t, hosts, 1)
+	require.Equal(t, "db1", hosts[0].Name)
+
+	hosts, err = ParseAnsibleInventory(strings.NewReader(testAnsibleINI), "prod")
+	require.NoError(t, err)
+	require.Len(t, hosts, 4)
+
+	_, err = ParseAnsibleInventory(strings.NewReader(testAnsibleINI), "unknown")
+	require.ErrorIs(t, err, ErrGroup)
+}
+
+func TestParseAnsibleInventoryErrors(t *testing.T) {
+	t.Parallel()
+	tests := []string{
+		"web[03:01]",
+		"web[01:02",
+		"web ansible_port=abc",
+		"web sx_ports=80,http",
+		"all:\n  hosts: [web]\n",
+	}
+	for _, inventory := range tests {
+		_, err := ParseAnsibleInventory(strings.NewReader(inventory), "")
+		require.Error(t, err, inventory)
+	}
+}
+
+func TestExpandHostPattern(t *testing.T) {
+	t.Parallel()
+	hosts, err := expandHostPattern("db-[8:10].local")
+	require.NoError(t, err)
+	require.Equal(t, []string{"db-8.local", "db-9.local", "db-10.local"}, hosts)
+}
@@ -0,0 +1,151 @@
+package inventory
+
+import (
+	"context"
+	"fmt"
+	"net"
+
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+// Host is an inventory host with the list of ports declared as open
+type Host struct {
+	// Name is the inventory host name or the resource address
+	Name string
+	// Addr is IP address or DNS name of the host
+	Addr string
+	// Ports are declared ports of the host
+	Ports []*scan.PortRange
+	Meta  map[string]interface{}
+}
+
+type LookupIPFunc func(ctx context.Context, host string) ([]net.IP, error)
+
+type RequestGenerator struct {
+	hosts    []*Host
+	ports    []*scan.PortRange
+	lookupIP LookupIPFunc
+}
+
+// Assert that inventory.RequestGenerator conforms to the scan.RequestGenerator interface
+var _ scan.RequestGenerator = (*RequestGenerator)(nil)
+
+type GeneratorOption func(*RequestGenerator)
+
+// WithPorts sets additional ports to scan on every host, open ports that are not declared
+// by the inventory are marked as drift
+func WithPorts(ports []*scan.PortRange) GeneratorOption {
+	return func(g *RequestGenerator) {
+		g.ports = ports
+	}
+}
+
+// WithLookupIP sets the function to resolve hosts with DNS names
+func WithLookupIP(lookupIP LookupIPFunc) GeneratorOption {
+	return func(g *RequestGenerator) {
+		g.lookupIP = lookupIP
+	}
+}
+
+// NewRequestGenerator creates a generator of scan requests for declared ports of inventory hosts
+// and additional ports. Request metadata contains the drift flag that is set for ports not declared
+// by the inventory, so every open port with the flag is a drift from the declared state.
+func NewRequestGenerator(hosts []*Host, opts ...GeneratorOption) *RequestGenerator {
+	g := &RequestGenerator{
+		hosts: hosts,
+		lookupIP: func(ctx context.Context, host string) ([]net.IP, error) {
+			return net.DefaultResolver.LookupIP(ctx, "ip4", host)
+		},
+	}
+	for _, o := range opts {
+		o(g)
+	}
+	return g
+}
+
+func (g *RequestGenerator) GenerateRequests(ctx context.Context, r *scan.Range) (<-chan *scan.Request, error) {
+	out := make(chan *scan.Request, 100)
+	go func() {
+		defer close(out)
+		seen := make(map[string]bool)
+		for _, h := range g.hosts {
+			ips, err := g.resolve(ctx, h.Addr)
+			if err != nil {
+				writeRequest(ctx, out, &scan.Request{Err: fmt.Errorf("inventory host %s: %w", h.Name, err)})
+				continue
+			}
+			for _, ip := range ips {
+				if seen[ip.String()] {
+					continue
+				}
+				seen[ip.String()] = true
+				g.writeHostRequests(ctx, out, r, h, ip)
+			}
+		}
+	}()
+	return out, nil
+}
+
+func (g *RequestGenerator) writeHostRequests(ctx context.Context, out chan<- *scan.Request,
+	r *scan.Range, h *Host, ip net.IP) {
+	seen := make(map[uint16]bool)
+	for _, ports := range [][]*scan.PortRange{h.Ports, g.ports} {
+		for _, portRange := range ports {
+			for port := int(portRange.StartPort); port <= int(portRange.EndPort); port++ {
+				if seen[uint16(port)] {
+					continue
+				}
+				seen[uint16(port)] = true
+				writeRequest(ctx, out, &scan.Request{
+					SrcIP: r.SrcIP, SrcMAC: r.SrcMAC, DstIP: ip, DstPort: uint16(port),
+					Meta: newMeta(h, !containsPort(h.Ports, uint16(port)))})
+			}
+		}
+	}
+}
+
+func (g *RequestGenerator) resolve(ctx context.Context, addr string) ([]net.IP, error) {
+	if ip := net.ParseIP(addr); ip != nil {
+		if ip4 := ip.To4(); ip4 != nil {
+			ip = ip4
+		}
+		return []net.IP{ip}, nil
+	}
+	ips, err := g.lookupIP(ctx, addr)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]net.IP, 0, len(ips))
+	for _, ip := range ips {
+		if ip = ip.To4(); ip != nil {
+			result = append(result, ip)
+		}
+	}
+	return result, nil
+}
+
+func writeRequest(ctx context.Context, out chan<- *scan.Request, request *scan.Request) {
+	select {
+	case <-ctx.Done():
+	case out <- request:
+	}
+}
+
+func containsPort(ports []*scan.PortRange, port uint16) bool {
+	for _, r := range ports {
+		if r.StartPort <= port && port <= r.EndPort {
+			return true
+		}
+	}
+	return false
+}
+
+func newMeta(h *Host, drift bool) map[string]interface{} {
+	result := make(map[string]interface{}, len(h.Meta)+2)
+	for k, v := range h.Meta {
+		result[k] = v
+	}
+	result["host"] = h.Name
+	result["drift"] = drift
+	return result
+}
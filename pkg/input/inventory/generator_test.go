@@ -0,0 +1,80 @@
+package inventory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+func testLookupIP(_ context.Context, host string) ([]net.IP, error) {
+	if host == "web.example.com" {
+		return []net.IP{net.IPv4(10, 0, 0, 1), net.ParseIP("2001:db8::1")}, nil
+	}
+	return nil, errors.New("not found")
+}
+
+func generateTargets(t *testing.T, g *RequestGenerator) (result []string) {
+	t.Helper()
+	requests, err := g.GenerateRequests(context.Background(), &scan.Range{})
+	require.NoError(t, err)
+	for r := range requests {
+		if r.Err != nil {
+			result = append(result, "error")
+			continue
+		}
+		result = append(result, fmt.Sprintf("%s %s:%d drift=%v", r.Meta["host"], r.DstIP, r.DstPort, r.Meta["drift"]))
+	}
+	return
+}
+
+func TestRequestGeneratorDrift(t *testing.T) {
+	t.Parallel()
+	hosts := []*Host{
+		{Name: "web", Addr: "web.example.com", Ports: portRanges(22, 80)},
+		{Name: "db", Addr: "10.0.0.2", Ports: portRanges(5432)},
+		// duplicate address
+		{Name: "web-alias", Addr: "10.0.0.1", Ports: portRanges(8080)},
+		{Name: "unknown", Addr: "unknown.example.com", Ports: portRanges(22)},
+	}
+	g := NewRequestGenerator(hosts, WithLookupIP(testLookupIP),
+		WithPorts([]*scan.PortRange{{StartPort: 21, EndPort: 22}}))
+
+	require.Equal(t, []string{
+		"web 10.0.0.1:22 drift=false",
+		"web 10.0.0.1:80 drift=false",
+		"web 10.0.0.1:21 drift=true",
+		"db 10.0.0.2:5432 drift=false",
+		"db 10.0.0.2:21 drift=true",
+		"db 10.0.0.2:22 drift=true",
+		"error",
+	}, generateTargets(t, g))
+}
+
+func TestRequestGeneratorMeta(t *testing.T) {
+	t.Parallel()
+	hosts := []*Host{{
+		Name:  "web",
+		Addr:  "192.168.0.1",
+		Ports: portRanges(80),
+		Meta:  map[string]interface{}{"kind": "ansible", "groups": []string{"web"}},
+	}}
+	g := NewRequestGenerator(hosts)
+
+	requests, err := g.GenerateRequests(context.Background(), &scan.Range{SrcIP: net.IPv4(192, 168, 0, 100)})
+	require.NoError(t, err)
+	r := <-requests
+	require.Equal(t, &scan.Request{
+		SrcIP:   net.IPv4(192, 168, 0, 100),
+		DstIP:   net.IPv4(192, 168, 0, 1).To4(),
+		DstPort: 80,
+		Meta: map[string]interface{}{
+			"kind": "ansible", "groups": []string{"web"}, "host": "web", "drift": false},
+	}, r)
+	// host metadata is not modified
+	require.Len(t, hosts[0].Meta, 2)
+}
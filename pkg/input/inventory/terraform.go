@@ -0,0 +1,190 @@
+package inventory
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+var ErrStateVersion = errors.New("terraform state: only version 4 is supported")
+
+// addressAttributes are resource attributes with IP addresses of hosts
+var addressAttributes = []string{
+	"public_ip", "private_ip", "ipv4_address", "ipv4_address_private",
+	"access_ip_v4", "ip_address", "nat_ip", "network_ip",
+}
+
+type terraformState struct {
+	Version   int `json:"version"`
+	Resources []struct {
+		Module    string `json:"module"`
+		Mode      string `json:"mode"`
+		Type      string `json:"type"`
+		Name      string `json:"name"`
+		Instances []struct {
+			IndexKey   interface{}            `json:"index_key"`
+			Attributes map[string]interface{} `json:"attributes"`
+		} `json:"instances"`
+	} `json:"resources"`
+}
+
+// LoadTerraformStateFile reads hosts from the Terraform state file
+func LoadTerraformStateFile(path string) ([]*Host, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ParseTerraformState(f)
+}
+
+// ParseTerraformState reads hosts from IP address attributes of managed resources,
+// e.g. public_ip and private_ip of aws_instance. Ports allowed by ingress rules of
+// security groups and firewalls are declared for all hosts of the state.
+func ParseTerraformState(r io.Reader) (result []*Host, err error) {
+	var state terraformState
+	if err = json.NewDecoder(r).Decode(&state); err != nil {
+		return
+	}
+	if state.Version != 4 {
+		return nil, ErrStateVersion
+	}
+	var ports []*scan.PortRange
+	for _, res := range state.Resources {
+		if res.Mode != "managed" {
+			continue
+		}
+		for _, inst := range res.Instances {
+			ports = append(ports, ingressPorts(res.Type, inst.Attributes)...)
+			address := resourceAddress(res.Module, res.Type, res.Name, inst.IndexKey)
+			for _, addr := range instanceAddresses(inst.Attributes) {
+				result = append(result, &Host{
+					Name: address,
+					Addr: addr,
+					Meta: map[string]interface{}{"kind": "terraform"},
+				})
+			}
+		}
+	}
+	for _, h := range result {
+		h.Ports = ports
+	}
+	return
+}
+
+func resourceAddress(module, resourceType, name string, indexKey interface{}) string {
+	address := resourceType + "." + name
+	if len(module) > 0 {
+		address = module + "." + address
+	}
+	switch key := indexKey.(type) {
+	case float64:
+		address += fmt.Sprintf("[%d]", int(key))
+	case string:
+		address += fmt.Sprintf("[%q]", key)
+	}
+	return address
+}
+
+// instanceAddresses returns unique non-empty IP addresses from top-level attributes
+// and network interfaces of the resource instance
+func instanceAddresses(attrs map[string]interface{}) (result []string) {
+	seen := make(map[string]bool)
+	add := func(attrs map[string]interface{}) {
+		for _, name := range addressAttributes {
+			if addr, ok := attrs[name].(string); ok && len(addr) > 0 && !seen[addr] {
+				seen[addr] = true
+				result = append(result, addr)
+			}
+		}
+	}
+	add(attrs)
+	for _, iface := range objects(attrs["network_interface"]) {
+		add(iface)
+		for _, accessConfig := range objects(iface["access_config"]) {
+			add(accessConfig)
+		}
+	}
+	return
+}
+
+// ingressPorts returns ports allowed by ingress rules, rules for all protocols are skipped
+// since they are commonly used for traffic within security groups
+func ingressPorts(resourceType string, attrs map[string]interface{}) (result []*scan.PortRange) {
+	switch resourceType {
+	case "aws_security_group":
+		for _, rule := range objects(attrs["ingress"]) {
+			result = appendPortRange(result, rule)
+		}
+	case "aws_security_group_rule":
+		if attrs["type"] == "ingress" {
+			result = appendPortRange(result, attrs)
+		}
+	case "aws_vpc_security_group_ingress_rule":
+		result = appendPortRange(result, attrs)
+	case "google_compute_firewall":
+		if direction, _ := attrs["direction"].(string); direction != "" && direction != "INGRESS" {
+			return
+		}
+		for _, allow := range objects(attrs["allow"]) {
+			ports, _ := allow["ports"].([]interface{})
+			for _, rawPorts := range ports {
+				if s, ok := rawPorts.(string); ok {
+					if portRange, err := parsePortRange(s); err == nil {
+						result = append(result, portRange)
+					}
+				}
+			}
+		}
+	}
+	return
+}
+
+func appendPortRange(ports []*scan.PortRange, rule map[string]interface{}) []*scan.PortRange {
+	protocol := fmt.Sprint(rule["protocol"])
+	if protocol == "-1" || protocol == "all" || strings.HasPrefix(protocol, "icmp") {
+		return ports
+	}
+	from, ok1 := rule["from_port"].(float64)
+	to, ok2 := rule["to_port"].(float64)
+	if !ok1 || !ok2 || from < 1 || to > 0xFFFF || from > to {
+		return ports
+	}
+	return append(ports, &scan.PortRange{StartPort: uint16(from), EndPort: uint16(to)})
+}
+
+// parsePortRange parses port or port range in the form start-end
+func parsePortRange(s string) (*scan.PortRange, error) {
+	parts := strings.SplitN(strings.TrimSpace(s), "-", 2)
+	start, err := strconv.ParseUint(parts[0], 10, 16)
+	if err != nil {
+		return nil, err
+	}
+	end := start
+	if len(parts) > 1 {
+		if end, err = strconv.ParseUint(parts[1], 10, 16); err != nil {
+			return nil, err
+		}
+	}
+	if start == 0 || start > end {
+		return nil, fmt.Errorf("invalid port range %q", s)
+	}
+	return &scan.PortRange{StartPort: uint16(start), EndPort: uint16(end)}, nil
+}
+
+// objects returns JSON objects of the list attribute
+func objects(v interface{}) (result []map[string]interface{}) {
+	list, _ := v.([]interface{})
+	for _, item := range list {
+		if obj, ok := item.(map[string]interface{}); ok {
+			result = append(result, obj)
+		}
+	}
+	return
+}
@@ -0,0 +1,97 @@
+package inventory
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+const testTerraformState = `{
+  "version": 4,
+  "terraform_version": "1.3.0",
+  "resources": [
+    {
+      "mode": "data",
+      "type": "aws_instance",
+      "name": "existing",
+      "instances": [{"attributes": {"public_ip": "198.51.100.1"}}]
+    },
+    {
+      "mode": "managed",
+      "type": "aws_instance",
+      "name": "web",
+      "instances": [
+        {"index_key": 0, "attributes": {"public_ip": "203.0.113.1", "private_ip": "10.0.0.1"}},
+        {"index_key": 1, "attributes": {"public_ip": "", "private_ip": "10.0.0.2"}}
+      ]
+    },
+    {
+      "module": "module.db",
+      "mode": "managed",
+      "type": "google_compute_instance",
+      "name": "db",
+      "instances": [{"index_key": "primary", "attributes": {
+        "network_interface": [{"network_ip": "10.1.0.1", "access_config": [{"nat_ip": "203.0.113.2"}]}]
+      }}]
+    },
+    {
+      "mode": "managed",
+      "type": "aws_security_group",
+      "name": "web",
+      "instances": [{"attributes": {"ingress": [
+        {"from_port": 443, "to_port": 443, "protocol": "tcp"},
+        {"from_port": 0, "to_port": 0, "protocol": "-1"}
+      ]}}]
+    },
+    {
+      "mode": "managed",
+      "type": "aws_security_group_rule",
+      "name": "ssh",
+      "instances": [{"attributes": {"type": "ingress", "from_port": 22, "to_port": 22, "protocol": "tcp"}}]
+    },
+    {
+      "mode": "managed",
+      "type": "aws_security_group_rule",
+      "name": "egress",
+      "instances": [{"attributes": {"type": "egress", "from_port": 0, "to_port": 65535, "protocol": "tcp"}}]
+    },
+    {
+      "mode": "managed",
+      "type": "google_compute_firewall",
+      "name": "db",
+      "instances": [{"attributes": {"direction": "INGRESS", "allow": [{"protocol": "tcp", "ports": ["5432", "8000-8080"]}]}}]
+    }
+  ]
+}`
+
+func TestParseTerraformState(t *testing.T) {
+	t.Parallel()
+	hosts, err := ParseTerraformState(strings.NewReader(testTerraformState))
+	require.NoError(t, err)
+
+	ports := []*scan.PortRange{
+		{StartPort: 443, EndPort: 443},
+		{StartPort: 22, EndPort: 22},
+		{StartPort: 5432, EndPort: 5432},
+		{StartPort: 8000, EndPort: 8080},
+	}
+	meta := map[string]interface{}{"kind": "terraform"}
+	require.Equal(t, []*Host{
+		{Name: "aws_instance.web[0]", Addr: "203.0.113.1", Ports: ports, Meta: meta},
+		{Name: "aws_instance.web[0]", Addr: "10.0.0.1", Ports: ports, Meta: meta},
+		{Name: "aws_instance.web[1]", Addr: "10.0.0.2", Ports: ports, Meta: meta},
+		{Name: `module.db.google_compute_instance.db["primary"]`, Addr: "10.1.0.1", Ports: ports, Meta: meta},
+		{Name: `module.db.google_compute_instance.db["primary"]`, Addr: "203.0.113.2", Ports: ports, Meta: meta},
+	}, hosts)
+}
+
+func TestParseTerraformStateErrors(t *testing.T) {
+	t.Parallel()
+	_, err := ParseTerraformState(strings.NewReader(`{"version": 3, "modules": []}`))
+	require.ErrorIs(t, err, ErrStateVersion)
+
+	_, err = ParseTerraformState(strings.NewReader(`{"version":`))
+	require.Error(t, err)
+}
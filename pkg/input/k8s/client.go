@@ -0,0 +1,233 @@
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultTimeout = 30 * time.Second
+	// defaultNamespace is the namespace of contexts without the namespace like in kubectl
+	defaultNamespace = "default"
+	// listLimit is the maximum number of objects of one page of the list
+	listLimit = 500
+)
+
+var errServer = errors.New("kubeconfig cluster server is empty")
+
+// Client is a minimal read-only Kubernetes API client
+type Client struct {
+	server    string
+	namespace string
+	token     string
+	username  string
+	password  string
+	client    *http.Client
+}
+
+// NewClient creates API client for the kubeconfig context with the given name,
+// the current context is used if the name is empty
+func NewClient(conf *Config, contextName string) (*Client, error) {
+	kctx, err := conf.context(contextName)
+	if err != nil {
+		return nil, err
+	}
+	cluster, err := conf.cluster(kctx.Cluster)
+	if err != nil {
+		return nil, err
+	}
+	if len(cluster.Server) == 0 {
+		return nil, errServer
+	}
+	user := conf.user(kctx.User)
+	tlsConf, err := conf.tlsConfig(cluster, user)
+	if err != nil {
+		return nil, err
+	}
+	token, err := conf.token(user)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{
+		server:    strings.TrimSuffix(cluster.Server, "/"),
+		namespace: kctx.Namespace,
+		token:     token,
+		username:  user.Username,
+		password:  user.Password,
+		client: &http.Client{
+			Timeout:   defaultTimeout,
+			Transport: &http.Transport{TLSClientConfig: tlsConf},
+		},
+	}, nil
+}
+
+// Namespace returns the default namespace of the kubeconfig context, the default namespace
+// is used if the context doesn't set it
+func (c *Client) Namespace() string {
+	if len(c.namespace) == 0 {
+		return defaultNamespace
+	}
+	return c.namespace
+}
+
+// objectList is the list of objects read in pages
+type objectList interface {
+	// decodePage appends objects of the page and returns the continue token of the next page,
+	// the token is empty on the last page
+	decodePage(dec *json.Decoder) (string, error)
+}
+
+type listMeta struct {
+	Continue string `json:"continue"`
+}
+
+// list retrieves all objects of the resource in the namespace page by page,
+// objects from all namespaces are returned if the namespace is empty
+func (c *Client) list(ctx context.Context, namespace, resource string, out objectList) error {
+	path := "/api/v1/" + resource
+	if len(namespace) > 0 {
+		path = fmt.Sprintf("/api/v1/namespaces/%s/%s", url.PathEscape(namespace), resource)
+	}
+	var token string
+	for {
+		query := url.Values{"limit": []string{strconv.Itoa(listLimit)}}
+		if len(token) > 0 {
+			query.Set("continue", token)
+		}
+		var err error
+		if token, err = c.listPage(ctx, path, query, out); err != nil || len(token) == 0 {
+			return err
+		}
+	}
+}
+
+func (c *Client) listPage(ctx context.Context, path string, query url.Values, out objectList) (token string, err error) {
+	var req *http.Request
+	if req, err = http.NewRequestWithContext(ctx, http.MethodGet, c.server+path+"?"+query.Encode(), nil); err != nil {
+		return
+	}
+	req.Header.Set("Accept", "application/json")
+	if len(c.token) > 0 {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	} else if len(c.username) > 0 {
+		req.SetBasicAuth(c.username, c.password)
+	}
+	var resp *http.Response
+	if resp, err = c.client.Do(req); err != nil {
+		return
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("kubernetes API %s: %s", path, resp.Status)
+	}
+	return out.decodePage(json.NewDecoder(resp.Body))
+}
+
+type objectMeta struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+}
+
+type servicePort struct {
+	Name     string `json:"name"`
+	Protocol string `json:"protocol"`
+	Port     int    `json:"port"`
+	NodePort int    `json:"nodePort"`
+}
+
+type service struct {
+	Metadata objectMeta `json:"metadata"`
+	Spec     struct {
+		Type        string        `json:"type"`
+		ClusterIP   string        `json:"clusterIP"`
+		ExternalIPs []string      `json:"externalIPs"`
+		Ports       []servicePort `json:"ports"`
+	} `json:"spec"`
+	Status struct {
+		LoadBalancer struct {
+			Ingress []struct {
+				IP string `json:"ip"`
+			} `json:"ingress"`
+		} `json:"loadBalancer"`
+	} `json:"status"`
+}
+
+type serviceList struct {
+	Metadata listMeta   `json:"metadata"`
+	Items    []*service `json:"items"`
+}
+
+func (l *serviceList) decodePage(dec *json.Decoder) (string, error) {
+	var page serviceList
+	if err := dec.Decode(&page); err != nil {
+		return "", err
+	}
+	l.Items = append(l.Items, page.Items...)
+	return page.Metadata.Continue, nil
+}
+
+type endpointPort struct {
+	Name     string `json:"name"`
+	Protocol string `json:"protocol"`
+	Port     int    `json:"port"`
+}
+
+type endpoints struct {
+	Metadata objectMeta `json:"metadata"`
+	Subsets  []struct {
+		Addresses []struct {
+			IP string `json:"ip"`
+		} `json:"addresses"`
+		Ports []endpointPort `json:"ports"`
+	} `json:"subsets"`
+}
+
+type endpointsList struct {
+	Metadata listMeta     `json:"metadata"`
+	Items    []*endpoints `json:"items"`
+}
+
+func (l *endpointsList) decodePage(dec *json.Decoder) (string, error) {
+	var page endpointsList
+	if err := dec.Decode(&page); err != nil {
+		return "", err
+	}
+	l.Items = append(l.Items, page.Items...)
+	return page.Metadata.Continue, nil
+}
+
+type node struct {
+	Metadata objectMeta `json:"metadata"`
+	Status   struct {
+		Addresses []struct {
+			Type    string `json:"type"`
+			Address string `json:"address"`
+		} `json:"addresses"`
+		DaemonEndpoints struct {
+			KubeletEndpoint struct {
+				Port int `json:"Port"`
+			} `json:"kubeletEndpoint"`
+		} `json:"daemonEndpoints"`
+	} `json:"status"`
+}
+
+type nodeList struct {
+	Metadata listMeta `json:"metadata"`
+	Items    []*node  `json:"items"`
+}
+
+func (l *nodeList) decodePage(dec *json.Decoder) (string, error) {
+	var page nodeList
+	if err := dec.Decode(&page); err != nil {
+		return "", err
+	}
+	l.Items = append(l.Items, page.Items...)
+	return page.Metadata.Continue, nil
+}
@@ -0,0 +1,195 @@
+package k8s
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	ErrNoContext = errors.New("kubeconfig context not found")
+	ErrNoCluster = errors.New("kubeconfig cluster not found")
+	errCAData    = errors.New("invalid certificate authority data")
+)
+
+// Config is a subset of kubeconfig fields required to connect to the API server.
+// Exec and auth provider plugins are not supported.
+type Config struct {
+	Clusters       []*NamedCluster `yaml:"clusters"`
+	Users          []*NamedUser    `yaml:"users"`
+	Contexts       []*NamedContext `yaml:"contexts"`
+	CurrentContext string          `yaml:"current-context"`
+
+	// directory of the kubeconfig file to resolve relative paths
+	dir string
+}
+
+type NamedCluster struct {
+	Name    string  `yaml:"name"`
+	Cluster Cluster `yaml:"cluster"`
+}
+
+type Cluster struct {
+	Server                   string `yaml:"server"`
+	CertificateAuthority     string `yaml:"certificate-authority"`
+	CertificateAuthorityData string `yaml:"certificate-authority-data"`
+	InsecureSkipTLSVerify    bool   `yaml:"insecure-skip-tls-verify"`
+}
+
+type NamedUser struct {
+	Name string   `yaml:"name"`
+	User AuthInfo `yaml:"user"`
+}
+
+type AuthInfo struct {
+	ClientCertificate     string `yaml:"client-certificate"`
+	ClientCertificateData string `yaml:"client-certificate-data"`
+	ClientKey             string `yaml:"client-key"`
+	ClientKeyData         string `yaml:"client-key-data"`
+	Token                 string `yaml:"token"`
+	TokenFile             string `yaml:"tokenFile"`
+	Username              string `yaml:"username"`
+	Password              string `yaml:"password"`
+}
+
+type NamedContext struct {
+	Name    string  `yaml:"name"`
+	Context Context `yaml:"context"`
+}
+
+type Context struct {
+	Cluster   string `yaml:"cluster"`
+	User      string `yaml:"user"`
+	Namespace string `yaml:"namespace"`
+}
+
+// DefaultConfigPath returns the first path from the KUBECONFIG environment variable
+// or ~/.kube/config if the variable is not set
+func DefaultConfigPath() string {
+	if paths := filepath.SplitList(os.Getenv("KUBECONFIG")); len(paths) > 0 {
+		return paths[0]
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".kube", "config")
+}
+
+func LoadConfigFile(path string) (*Config, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	conf, err := ParseConfig(f)
+	if err != nil {
+		return nil, err
+	}
+	conf.dir = filepath.Dir(path)
+	return conf, nil
+}
+
+func ParseConfig(r io.Reader) (*Config, error) {
+	var conf Config
+	if err := yaml.NewDecoder(r).Decode(&conf); err != nil {
+		return nil, fmt.Errorf("kubeconfig: %w", err)
+	}
+	return &conf, nil
+}
+
+// context returns the context with the given name, current context is used if the name is empty
+func (c *Config) context(name string) (*Context, error) {
+	if len(name) == 0 {
+		name = c.CurrentContext
+	}
+	for _, ctx := range c.Contexts {
+		if ctx.Name == name {
+			return &ctx.Context, nil
+		}
+	}
+	return nil, ErrNoContext
+}
+
+func (c *Config) cluster(name string) (*Cluster, error) {
+	for _, cluster := range c.Clusters {
+		if cluster.Name == name {
+			return &cluster.Cluster, nil
+		}
+	}
+	return nil, ErrNoCluster
+}
+
+// user returns user credentials, missing user means anonymous access
+func (c *Config) user(name string) *AuthInfo {
+	for _, user := range c.Users {
+		if user.Name == name {
+			return &user.User
+		}
+	}
+	return &AuthInfo{}
+}
+
+func (c *Config) readData(data, path string) ([]byte, error) {
+	if len(data) > 0 {
+		return base64.StdEncoding.DecodeString(data)
+	}
+	if len(path) == 0 {
+		return nil, nil
+	}
+	if !filepath.IsAbs(path) && len(c.dir) > 0 {
+		path = filepath.Join(c.dir, path)
+	}
+	return os.ReadFile(path)
+}
+
+func (c *Config) tlsConfig(cluster *Cluster, user *AuthInfo) (*tls.Config, error) {
+	conf := &tls.Config{
+		InsecureSkipVerify: cluster.InsecureSkipTLSVerify,
+	}
+	caData, err := c.readData(cluster.CertificateAuthorityData, cluster.CertificateAuthority)
+	if err != nil {
+		return nil, err
+	}
+	if len(caData) > 0 {
+		conf.RootCAs = x509.NewCertPool()
+		if !conf.RootCAs.AppendCertsFromPEM(caData) {
+			return nil, errCAData
+		}
+	}
+	certData, err := c.readData(user.ClientCertificateData, user.ClientCertificate)
+	if err != nil {
+		return nil, err
+	}
+	keyData, err := c.readData(user.ClientKeyData, user.ClientKey)
+	if err != nil {
+		return nil, err
+	}
+	if len(certData) > 0 && len(keyData) > 0 {
+		cert, err := tls.X509KeyPair(certData, keyData)
+		if err != nil {
+			return nil, err
+		}
+		conf.Certificates = []tls.Certificate{cert}
+	}
+	return conf, nil
+}
+
+func (c *Config) token(user *AuthInfo) (string, error) {
+	if len(user.Token) > 0 || len(user.TokenFile) == 0 {
+		return user.Token, nil
+	}
+	data, err := c.readData("", user.TokenFile)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(data)), nil
+}
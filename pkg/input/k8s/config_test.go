@@ -0,0 +1,109 @@
+package k8s
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testKubeconfig = `
+apiVersion: v1
+kind: Config
+current-context: dev
+clusters:
+- name: dev-cluster
+  cluster:
+    server: https://10.0.0.1:6443/
+    insecure-skip-tls-verify: true
+- name: prod-cluster
+  cluster:
+    server: https://10.0.1.1:6443
+    certificate-authority-data: aW52YWxpZA==
+contexts:
+- name: dev
+  context:
+    cluster: dev-cluster
+    user: dev-user
+    namespace: web
+- name: prod
+  context:
+    cluster: prod-cluster
+    user: prod-user
+- name: broken
+  context:
+    cluster: unknown
+users:
+- name: dev-user
+  user:
+    token: secret
+- name: prod-user
+  user:
+    tokenFile: token
+`
+
+func TestParseConfig(t *testing.T) {
+	t.Parallel()
+	conf, err := ParseConfig(strings.NewReader(testKubeconfig))
+	require.NoError(t, err)
+	require.Equal(t, "dev", conf.CurrentContext)
+	require.Len(t, conf.Clusters, 2)
+	require.Len(t, conf.Contexts, 3)
+	require.Len(t, conf.Users, 2)
+	require.Equal(t, Cluster{Server: "https://10.0.0.1:6443/", InsecureSkipTLSVerify: true}, conf.Clusters[0].Cluster)
+	require.Equal(t, Context{Cluster: "dev-cluster", User: "dev-user", Namespace: "web"}, conf.Contexts[0].Context)
+	require.Equal(t, AuthInfo{TokenFile: "token"}, conf.Users[1].User)
+}
+
+func TestParseConfigInvalid(t *testing.T) {
+	t.Parallel()
+	_, err := ParseConfig(strings.NewReader("clusters: abc"))
+	require.Error(t, err)
+}
+
+func TestNewClientCurrentContext(t *testing.T) {
+	t.Parallel()
+	conf, err := ParseConfig(strings.NewReader(testKubeconfig))
+	require.NoError(t, err)
+
+	client, err := NewClient(conf, "")
+	require.NoError(t, err)
+	require.Equal(t, "https://10.0.0.1:6443", client.server)
+	require.Equal(t, "web", client.Namespace())
+	require.Equal(t, "secret", client.token)
+}
+
+func TestNewClientErrors(t *testing.T) {
+	t.Parallel()
+	conf, err := ParseConfig(strings.NewReader(testKubeconfig))
+	require.NoError(t, err)
+
+	_, err = NewClient(conf, "unknown")
+	require.ErrorIs(t, err, ErrNoContext)
+
+	_, err = NewClient(conf, "broken")
+	require.ErrorIs(t, err, ErrNoCluster)
+
+	// invalid certificate authority data
+	_, err = NewClient(conf, "prod")
+	require.Error(t, err)
+}
+
+func TestLoadConfigFileRelativePaths(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	kubeconfig := strings.Replace(testKubeconfig, "certificate-authority-data: aW52YWxpZA==",
+		"insecure-skip-tls-verify: true", 1)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "config"), []byte(kubeconfig), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "token"), []byte("file-secret\n"), 0600))
+
+	conf, err := LoadConfigFile(filepath.Join(dir, "config"))
+	require.NoError(t, err)
+	client, err := NewClient(conf, "prod")
+	require.NoError(t, err)
+	require.Equal(t, "file-secret", client.token)
+	// the context without the namespace uses the default one like kubectl
+	require.Equal(t, "default", client.Namespace())
+}
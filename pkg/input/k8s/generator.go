@@ -0,0 +1,263 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+const (
+	ResourceServices  = "services"
+	ResourceEndpoints = "endpoints"
+	ResourceNodes     = "nodes"
+)
+
+var (
+	// DefaultResources are listed when no resources are specified
+	DefaultResources = []string{ResourceServices, ResourceEndpoints, ResourceNodes}
+
+	errResource = fmt.Errorf("invalid kubernetes resource, only %s are valid", strings.Join(DefaultResources, ","))
+)
+
+type RequestGenerator struct {
+	client        *Client
+	namespace     string
+	allNamespaces bool
+	resources     []string
+	protocol      string
+}
+
+// Assert that k8s.RequestGenerator conforms to the scan.RequestGenerator interface
+var _ scan.RequestGenerator = (*RequestGenerator)(nil)
+
+type GeneratorOption func(*RequestGenerator)
+
+// WithNamespace limits listed objects to one namespace,
+// the default namespace of the kubeconfig context is used by default
+func WithNamespace(namespace string) GeneratorOption {
+	return func(g *RequestGenerator) {
+		g.namespace = namespace
+	}
+}
+
+// WithAllNamespaces enables listing objects from all namespaces
+func WithAllNamespaces() GeneratorOption {
+	return func(g *RequestGenerator) {
+		g.allNamespaces = true
+	}
+}
+
+// WithResources sets the list of resources to generate requests from
+func WithResources(resources []string) GeneratorOption {
+	return func(g *RequestGenerator) {
+		g.resources = resources
+	}
+}
+
+// WithProtocol sets the port protocol (TCP, UDP or SCTP) to generate requests for, TCP by default
+func WithProtocol(protocol string) GeneratorOption {
+	return func(g *RequestGenerator) {
+		g.protocol = strings.ToUpper(protocol)
+	}
+}
+
+// NewRequestGenerator creates a generator of scan requests for IP addresses and ports of
+// Services (cluster, external and load balancer IPs), Endpoints and Nodes (kubelet and node ports)
+func NewRequestGenerator(client *Client, opts ...GeneratorOption) (*RequestGenerator, error) {
+	g := &RequestGenerator{
+		client:    client,
+		namespace: client.Namespace(),
+		resources: DefaultResources,
+		protocol:  "TCP",
+	}
+	for _, o := range opts {
+		o(g)
+	}
+	for _, resource := range g.resources {
+		if resource != ResourceServices && resource != ResourceEndpoints && resource != ResourceNodes {
+			return nil, errResource
+		}
+	}
+	return g, nil
+}
+
+type target struct {
+	ip   net.IP
+	port uint16
+	meta map[string]interface{}
+}
+
+// targetSet keeps unique ip/port pairs in the order of addition
+type targetSet struct {
+	seen    map[string]bool
+	targets []*target
+}
+
+func newTargetSet() *targetSet {
+	return &targetSet{seen: make(map[string]bool)}
+}
+
+func (s *targetSet) add(rawIP string, port int, meta map[string]interface{}) {
+	ip := net.ParseIP(rawIP)
+	if ip == nil || port <= 0 || port > 0xFFFF {
+		return
+	}
+	if ip4 := ip.To4(); ip4 != nil {
+		ip = ip4
+	}
+	key := fmt.Sprintf("%s:%d", ip, port)
+	if s.seen[key] {
+		return
+	}
+	s.seen[key] = true
+	s.targets = append(s.targets, &target{ip: ip, port: uint16(port), meta: meta})
+}
+
+func (g *RequestGenerator) GenerateRequests(ctx context.Context, r *scan.Range) (<-chan *scan.Request, error) {
+	targets, err := g.targets(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := make(chan *scan.Request, 100)
+	go func() {
+		defer close(out)
+		for _, t := range targets {
+			select {
+			case <-ctx.Done():
+				return
+			case out <- &scan.Request{
+				SrcIP: r.SrcIP, SrcMAC: r.SrcMAC,
+				DstIP: t.ip, DstPort: t.port, Meta: t.meta}:
+			}
+		}
+	}()
+	return out, nil
+}
+
+func (g *RequestGenerator) targets(ctx context.Context) ([]*target, error) {
+	namespace := g.namespace
+	if g.allNamespaces {
+		namespace = ""
+	}
+	var services serviceList
+	// services are required for node ports
+	if g.hasResource(ResourceServices) || g.hasResource(ResourceNodes) {
+		if err := g.client.list(ctx, namespace, ResourceServices, &services); err != nil {
+			return nil, err
+		}
+	}
+	var endpoints endpointsList
+	if g.hasResource(ResourceEndpoints) {
+		if err := g.client.list(ctx, namespace, ResourceEndpoints, &endpoints); err != nil {
+			return nil, err
+		}
+	}
+	var nodes nodeList
+	if g.hasResource(ResourceNodes) {
+		// nodes are cluster-scoped objects
+		if err := g.client.list(ctx, "", ResourceNodes, &nodes); err != nil {
+			return nil, err
+		}
+	}
+
+	set := newTargetSet()
+	if g.hasResource(ResourceServices) {
+		g.addServices(set, &services)
+	}
+	g.addEndpoints(set, &endpoints)
+	g.addNodes(set, &nodes, g.nodePorts(&services))
+	return set.targets, nil
+}
+
+func (g *RequestGenerator) addServices(set *targetSet, services *serviceList) {
+	for _, svc := range services.Items {
+		meta := newMeta("service", svc.Metadata)
+		for _, port := range svc.Spec.Ports {
+			if !g.matchProtocol(port.Protocol) {
+				continue
+			}
+			set.add(svc.Spec.ClusterIP, port.Port, meta)
+			for _, ip := range svc.Spec.ExternalIPs {
+				set.add(ip, port.Port, meta)
+			}
+			for _, ingress := range svc.Status.LoadBalancer.Ingress {
+				set.add(ingress.IP, port.Port, meta)
+			}
+		}
+	}
+}
+
+func (g *RequestGenerator) addEndpoints(set *targetSet, endpoints *endpointsList) {
+	for _, ep := range endpoints.Items {
+		meta := newMeta("endpoints", ep.Metadata)
+		for _, subset := range ep.Subsets {
+			for _, port := range subset.Ports {
+				if !g.matchProtocol(port.Protocol) {
+					continue
+				}
+				for _, addr := range subset.Addresses {
+					set.add(addr.IP, port.Port, meta)
+				}
+			}
+		}
+	}
+}
+
+func (g *RequestGenerator) addNodes(set *targetSet, nodes *nodeList, nodePorts []int) {
+	for _, n := range nodes.Items {
+		meta := newMeta("node", n.Metadata)
+		for _, addr := range n.Status.Addresses {
+			if addr.Type != "InternalIP" && addr.Type != "ExternalIP" {
+				continue
+			}
+			if g.protocol == "TCP" {
+				set.add(addr.Address, n.Status.DaemonEndpoints.KubeletEndpoint.Port, meta)
+			}
+			for _, port := range nodePorts {
+				set.add(addr.Address, port, meta)
+			}
+		}
+	}
+}
+
+func (g *RequestGenerator) nodePorts(services *serviceList) (result []int) {
+	for _, svc := range services.Items {
+		for _, port := range svc.Spec.Ports {
+			if port.NodePort > 0 && g.matchProtocol(port.Protocol) {
+				result = append(result, port.NodePort)
+			}
+		}
+	}
+	return
+}
+
+func (g *RequestGenerator) hasResource(resource string) bool {
+	for _, r := range g.resources {
+		if r == resource {
+			return true
+		}
+	}
+	return false
+}
+
+// matchProtocol checks port protocol, empty protocol means TCP
+func (g *RequestGenerator) matchProtocol(protocol string) bool {
+	if len(protocol) == 0 {
+		protocol = "TCP"
+	}
+	return protocol == g.protocol
+}
+
+func newMeta(kind string, meta objectMeta) map[string]interface{} {
+	result := map[string]interface{}{
+		"kind": kind,
+		"name": meta.Name,
+	}
+	if len(meta.Namespace) > 0 {
+		result["namespace"] = meta.Namespace
+	}
+	return result
+}
@@ -0,0 +1,207 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+const (
+	testServices = `{"items":[
+{"metadata":{"name":"web","namespace":"default"},
+ "spec":{"type":"LoadBalancer","clusterIP":"10.96.0.10","externalIPs":["192.168.0.10"],
+  "ports":[{"name":"http","protocol":"TCP","port":80,"nodePort":30080},{"name":"dns","protocol":"UDP","port":53,"nodePort":30053}]},
+ "status":{"loadBalancer":{"ingress":[{"ip":"203.0.113.10"},{"hostname":"lb.example.com"}]}}},
+{"metadata":{"name":"headless","namespace":"default"},
+ "spec":{"type":"ClusterIP","clusterIP":"None","ports":[{"port":8080}]}}
+]}`
+	testEndpoints = `{"items":[
+{"metadata":{"name":"web","namespace":"default"},
+ "subsets":[{"addresses":[{"ip":"10.244.0.5"},{"ip":"10.244.0.6"}],"ports":[{"name":"http","port":8080,"protocol":"TCP"}]}]}
+]}`
+	testNodes = `{"items":[
+{"metadata":{"name":"node1"},
+ "status":{"addresses":[{"type":"InternalIP","address":"172.16.0.1"},{"type":"Hostname","address":"node1"}],
+  "daemonEndpoints":{"kubeletEndpoint":{"Port":10250}}}}
+]}`
+)
+
+type apiServer struct {
+	*httptest.Server
+	mu    sync.Mutex
+	paths []string
+}
+
+func newAPIServer(t *testing.T) *apiServer {
+	t.Helper()
+	srv := &apiServer{}
+	srv.Server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		srv.mu.Lock()
+		srv.paths = append(srv.paths, r.URL.Path)
+		srv.mu.Unlock()
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/services"):
+			fmt.Fprint(w, testServices)
+		case strings.HasSuffix(r.URL.Path, "/endpoints"):
+			fmt.Fprint(w, testEndpoints)
+		case r.URL.Path == "/api/v1/nodes":
+			fmt.Fprint(w, testNodes)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func (s *apiServer) Paths() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.paths
+}
+
+func newTestClient(t *testing.T, server, token string) *Client {
+	t.Helper()
+	conf, err := ParseConfig(strings.NewReader(fmt.Sprintf(`
+current-context: test
+clusters:
+- name: test
+  cluster:
+    server: %s
+    insecure-skip-tls-verify: true
+contexts:
+- name: test
+  context:
+    cluster: test
+    user: test
+    namespace: default
+users:
+- name: test
+  user:
+    token: %s
+`, server, token)))
+	require.NoError(t, err)
+	client, err := NewClient(conf, "")
+	require.NoError(t, err)
+	return client
+}
+
+func generateTargets(t *testing.T, g *RequestGenerator) (result []string) {
+	t.Helper()
+	requests, err := g.GenerateRequests(context.Background(), &scan.Range{})
+	require.NoError(t, err)
+	for r := range requests {
+		require.NoError(t, r.Err)
+		result = append(result, fmt.Sprintf("%s %s:%d", r.Meta["kind"], r.DstIP, r.DstPort))
+	}
+	return
+}
+
+func TestRequestGeneratorAllResources(t *testing.T) {
+	t.Parallel()
+	srv := newAPIServer(t)
+	g, err := NewRequestGenerator(newTestClient(t, srv.URL, "secret"))
+	require.NoError(t, err)
+
+	require.Equal(t, []string{
+		"service 10.96.0.10:80",
+		"service 192.168.0.10:80",
+		"service 203.0.113.10:80",
+		"endpoints 10.244.0.5:8080",
+		"endpoints 10.244.0.6:8080",
+		"node 172.16.0.1:10250",
+		"node 172.16.0.1:30080",
+	}, generateTargets(t, g))
+	require.Equal(t, []string{
+		"/api/v1/namespaces/default/services",
+		"/api/v1/namespaces/default/endpoints",
+		"/api/v1/nodes",
+	}, srv.Paths())
+}
+
+func TestRequestGeneratorUDPServicesAllNamespaces(t *testing.T) {
+	t.Parallel()
+	srv := newAPIServer(t)
+	g, err := NewRequestGenerator(newTestClient(t, srv.URL, "secret"),
+		WithResources([]string{ResourceServices}), WithProtocol("udp"), WithAllNamespaces())
+	require.NoError(t, err)
+
+	require.Equal(t, []string{
+		"service 10.96.0.10:53",
+		"service 192.168.0.10:53",
+		"service 203.0.113.10:53",
+	}, generateTargets(t, g))
+	require.Equal(t, []string{"/api/v1/services"}, srv.Paths())
+}
+
+func TestRequestGeneratorMeta(t *testing.T) {
+	t.Parallel()
+	srv := newAPIServer(t)
+	g, err := NewRequestGenerator(newTestClient(t, srv.URL, "secret"),
+		WithResources([]string{ResourceEndpoints}))
+	require.NoError(t, err)
+
+	requests, err := g.GenerateRequests(context.Background(), &scan.Range{SrcIP: net.IPv4(10, 0, 0, 1)})
+	require.NoError(t, err)
+	r := <-requests
+	require.Equal(t, &scan.Request{
+		SrcIP:   net.IPv4(10, 0, 0, 1),
+		DstIP:   net.IPv4(10, 244, 0, 5).To4(),
+		DstPort: 8080,
+		Meta:    map[string]interface{}{"kind": "endpoints", "name": "web", "namespace": "default"},
+	}, r)
+}
+
+func TestRequestGeneratorPages(t *testing.T) {
+	t.Parallel()
+	var mu sync.Mutex
+	var queries []string
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		queries = append(queries, r.URL.RawQuery)
+		mu.Unlock()
+		if r.URL.Query().Get("continue") == "" {
+			fmt.Fprint(w, `{"metadata":{"continue":"page2"},"items":[
+{"metadata":{"name":"a"},"subsets":[{"addresses":[{"ip":"10.244.0.5"}],"ports":[{"port":80}]}]}]}`)
+			return
+		}
+		fmt.Fprint(w, `{"metadata":{},"items":[
+{"metadata":{"name":"b"},"subsets":[{"addresses":[{"ip":"10.244.0.6"}],"ports":[{"port":80}]}]}]}`)
+	}))
+	t.Cleanup(srv.Close)
+	g, err := NewRequestGenerator(newTestClient(t, srv.URL, "secret"), WithResources([]string{ResourceEndpoints}))
+	require.NoError(t, err)
+
+	require.Equal(t, []string{"endpoints 10.244.0.5:80", "endpoints 10.244.0.6:80"}, generateTargets(t, g))
+	require.Equal(t, []string{"limit=500", "continue=page2&limit=500"}, queries)
+}
+
+func TestRequestGeneratorInvalidResource(t *testing.T) {
+	t.Parallel()
+	srv := newAPIServer(t)
+	_, err := NewRequestGenerator(newTestClient(t, srv.URL, "secret"),
+		WithResources([]string{"pods"}))
+	require.Error(t, err)
+}
+
+func TestRequestGeneratorAPIError(t *testing.T) {
+	t.Parallel()
+	srv := newAPIServer(t)
+	g, err := NewRequestGenerator(newTestClient(t, srv.URL, "invalid"))
+	require.NoError(t, err)
+
+	_, err = g.GenerateRequests(context.Background(), &scan.Range{})
+	require.Error(t, err)
+}
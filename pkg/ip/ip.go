@@ -4,32 +4,79 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"strconv"
 )
 
 var ErrInvalidAddr = errors.New("invalid IP subnet/host")
 
+// v4MappedPrefixLen is the length of the ::ffff:0:0/96 prefix of IPv4-mapped IPv6 addresses
+const v4MappedPrefixLen = 96
+
+// ParseIPNet parses the subnet in CIDR notation or the host IP address in any notation accepted by ParseIP,
+// subnets of IPv4-mapped IPv6 addresses are converted to IPv4 subnets
 func ParseIPNet(subnet string) (*net.IPNet, error) {
 	_, result, err := net.ParseCIDR(subnet)
 	if err == nil {
+		ones, bits := result.Mask.Size()
+		if ip4 := result.IP.To4(); ip4 != nil && bits == 8*net.IPv6len && ones >= v4MappedPrefixLen {
+			return &net.IPNet{IP: ip4, Mask: net.CIDRMask(ones-v4MappedPrefixLen, 32)}, nil
+		}
 		return result, err
 	}
 	// try to parse host IP address instead
-	ipAddr := net.ParseIP(subnet)
+	ipAddr := ParseIP(subnet)
 	if ipAddr == nil {
 		return nil, ErrInvalidAddr
 	}
-	return &net.IPNet{IP: ipAddr.To4(), Mask: net.CIDRMask(32, 32)}, nil
+	if ipv4 := ipAddr.To4(); ipv4 != nil {
+		return &net.IPNet{IP: ipv4, Mask: net.CIDRMask(32, 32)}, nil
+	}
+	return &net.IPNet{IP: ipAddr, Mask: net.CIDRMask(128, 128)}, nil
 }
 
+// ParseIP parses the IP address in the canonical notation, the IPv4-mapped IPv6 notation, e.g. ::ffff:1.2.3.4,
+// or the IPv4 address as the decimal or hexadecimal integer, e.g. 16909060 or 0x01020304.
+// IPv4 addresses are returned in the 4-byte form, so that their string form is always dotted decimal.
+// It returns nil if the address is invalid.
+func ParseIP(s string) net.IP {
+	addr := net.ParseIP(s)
+	if addr == nil {
+		addr = parseIntegerIP(s)
+	}
+	if ip4 := addr.To4(); ip4 != nil {
+		return ip4
+	}
+	return addr
+}
+
+func parseIntegerIP(s string) net.IP {
+	base := 10
+	if len(s) > 2 && (s[:2] == "0x" || s[:2] == "0X") {
+		s, base = s[2:], 16
+	}
+	n, err := strconv.ParseUint(s, base, 32)
+	if err != nil {
+		return nil
+	}
+	return net.IPv4(byte(n>>24), byte(n>>16), byte(n>>8), byte(n)).To4()
+}
+
+// GetInterfaceIP returns the first IPv4 address of the interface, IPv6 addresses are skipped
 func GetInterfaceIP(iface *net.Interface) (ifaceIP net.IP, err error) {
 	var addrs []net.Addr
-	if addrs, err = iface.Addrs(); err != nil || len(addrs) == 0 {
+	if addrs, err = iface.Addrs(); err != nil {
 		return
 	}
-	if ipnet, ok := addrs[0].(*net.IPNet); ok {
-		return ipnet.IP, nil
+	for _, addr := range addrs {
+		ipnet, ok := addr.(*net.IPNet)
+		if !ok {
+			return nil, fmt.Errorf("invalid IP address: %v", addr)
+		}
+		if ipnet.IP.To4() != nil {
+			return ipnet.IP, nil
+		}
 	}
-	return nil, fmt.Errorf("invalid IP address: %v", addrs[0])
+	return
 }
 
 func GetLocalSubnetInterface(dstSubnet *net.IPNet) (iface *net.Interface, ifaceIP net.IP, err error) {
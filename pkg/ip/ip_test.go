@@ -36,6 +36,38 @@ func TestParseIPNet(t *testing.T) {
 				Mask: net.CIDRMask(32, 32),
 			},
 		},
+		{
+			name: "IPv4MappedSubnet",
+			in:   "::ffff:10.0.0.1/104",
+			expected: &net.IPNet{
+				IP:   net.IPv4(10, 0, 0, 0).To4(),
+				Mask: net.CIDRMask(8, 32),
+			},
+		},
+		{
+			name: "IntegerHost",
+			in:   "167772161",
+			expected: &net.IPNet{
+				IP:   net.IPv4(10, 0, 0, 1).To4(),
+				Mask: net.CIDRMask(32, 32),
+			},
+		},
+		{
+			name: "IPv6Subnet",
+			in:   "2001:db8::1/64",
+			expected: &net.IPNet{
+				IP:   net.ParseIP("2001:db8::"),
+				Mask: net.CIDRMask(64, 128),
+			},
+		},
+		{
+			name: "IPv6Host",
+			in:   "2001:db8::1",
+			expected: &net.IPNet{
+				IP:   net.ParseIP("2001:db8::1"),
+				Mask: net.CIDRMask(128, 128),
+			},
+		},
 	}
 	for _, vtt := range tests {
 		tt := vtt
@@ -47,3 +79,37 @@ func TestParseIPNet(t *testing.T) {
 		})
 	}
 }
+
+func TestParseIP(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		in       string
+		expected net.IP
+	}{
+		{in: "1.2.3.4", expected: net.IP{1, 2, 3, 4}},
+		{in: "::ffff:1.2.3.4", expected: net.IP{1, 2, 3, 4}},
+		{in: "::FFFF:0102:0304", expected: net.IP{1, 2, 3, 4}},
+		{in: "16909060", expected: net.IP{1, 2, 3, 4}},
+		{in: "0x01020304", expected: net.IP{1, 2, 3, 4}},
+		{in: "0X1020304", expected: net.IP{1, 2, 3, 4}},
+		{in: "4294967295", expected: net.IP{255, 255, 255, 255}},
+		{in: "0", expected: net.IP{0, 0, 0, 0}},
+		{in: "2001:DB8::1", expected: net.ParseIP("2001:db8::1")},
+		{in: ""},
+		{in: "0x"},
+		{in: "4294967296"},
+		{in: "0x1ffffffff"},
+		{in: "-1"},
+		{in: "+1"},
+		{in: "1_000"},
+		{in: "0xgg"},
+		{in: "1.2.3"},
+		{in: "localhost"},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, ParseIP(tt.in), tt.in)
+	}
+	// IPv4 addresses are always written in the dotted decimal notation
+	assert.Equal(t, "1.2.3.4", ParseIP("::ffff:1.2.3.4").String())
+	assert.Equal(t, "2001:db8::1", ParseIP("2001:0DB8:0:0::1").String())
+}
@@ -0,0 +1,57 @@
+package monitor
+
+import (
+	"encoding/binary"
+	"hash/maphash"
+	"net"
+
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+// DiffFilter excludes stable hosts from fast runs of differential rescans. Every fullEvery-th run,
+// starting with the first one, scans all hosts. Other runs scan only hosts that changed within
+// the last fullEvery runs and a random sample of stable hosts, which is chosen anew in each run.
+type DiffFilter struct {
+	tracker   *Tracker
+	fullEvery int
+	sample    float64
+	seed      maphash.Seed
+}
+
+// Assert that monitor.DiffFilter conforms to the scan.IPContainer interface
+var _ scan.IPContainer = (*DiffFilter)(nil)
+
+// NewDiffFilter creates the filter of hosts based on the state of the tracker,
+// sample is the fraction of stable hosts scanned in fast runs
+func NewDiffFilter(tracker *Tracker, fullEvery int, sample float64) *DiffFilter {
+	return &DiffFilter{tracker: tracker, fullEvery: fullEvery, sample: sample, seed: maphash.MakeSeed()}
+}
+
+// Contains reports whether the host is excluded from the current run, excluded hosts are skipped by the tracker
+func (f *DiffFilter) Contains(ip net.IP) (bool, error) {
+	run := f.tracker.Run()
+	if f.fullRun(run) {
+		return false, nil
+	}
+	host := ip.String()
+	if f.tracker.Changed(host, f.fullEvery) || f.sampled(host, run) {
+		return false, nil
+	}
+	f.tracker.Skip(host)
+	return true, nil
+}
+
+func (f *DiffFilter) fullRun(run int) bool {
+	return f.fullEvery <= 1 || (run-1)%f.fullEvery == 0
+}
+
+// sampled chooses the host with the sample probability, all ports of the host get the same choice in the run
+func (f *DiffFilter) sampled(host string, run int) bool {
+	var h maphash.Hash
+	h.SetSeed(f.seed)
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], uint64(run))
+	_, _ = h.Write(buf[:])
+	_, _ = h.WriteString(host)
+	return float64(h.Sum64()>>11)/(1<<53) < f.sample
+}
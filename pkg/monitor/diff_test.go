@@ -0,0 +1,83 @@
+package monitor
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffFilterFullRuns(t *testing.T) {
+	t.Parallel()
+	tracker, _ := newTestTracker(3)
+	f := NewDiffFilter(tracker, 3, 0)
+	ip := net.IPv4(192, 168, 0, 1)
+
+	var excluded []bool
+	for run := 1; run <= 7; run++ {
+		tracker.StartRun()
+		contains, err := f.Contains(ip)
+		require.NoError(t, err)
+		excluded = append(excluded, contains)
+	}
+	// runs 1, 4 and 7 are full runs
+	require.Equal(t, []bool{false, true, true, false, true, true, false}, excluded)
+}
+
+func TestDiffFilterChangedHosts(t *testing.T) {
+	t.Parallel()
+	tracker, _ := newTestTracker(2)
+	f := NewDiffFilter(tracker, 5, 0)
+	changed := net.IPv4(192, 168, 0, 1)
+	stable := net.IPv4(192, 168, 0, 2)
+
+	for run := 1; run <= 10; run++ {
+		tracker.StartRun()
+		tracker.Record(openPort("192.168.0.2", 22))
+		if run == 7 {
+			tracker.Record(openPort("192.168.0.1", 80))
+		}
+	}
+	// the port of the changed host appeared within the last 5 runs
+	contains, err := f.Contains(changed)
+	require.NoError(t, err)
+	require.False(t, contains)
+
+	// the stable host is skipped and its port isn't reported as closed
+	contains, err = f.Contains(stable)
+	require.NoError(t, err)
+	require.True(t, contains)
+	require.Empty(t, tracker.StartRun())
+}
+
+func TestDiffFilterSample(t *testing.T) {
+	t.Parallel()
+	tracker, _ := newTestTracker(3)
+	tracker.StartRun()
+	tracker.StartRun()
+
+	count := func(f *DiffFilter) (scanned int) {
+		for i := 0; i < 1000; i++ {
+			contains, err := f.Contains(net.IPv4(10, 0, byte(i>>8), byte(i)))
+			require.NoError(t, err)
+			if !contains {
+				scanned++
+			}
+		}
+		return
+	}
+	require.Equal(t, 0, count(NewDiffFilter(tracker, 5, 0)))
+	require.Equal(t, 1000, count(NewDiffFilter(tracker, 5, 1)))
+	scanned := count(NewDiffFilter(tracker, 5, 0.1))
+	require.Greater(t, scanned, 50)
+	require.Less(t, scanned, 150)
+
+	// the sample is the same for all ports of the host in the run
+	f := NewDiffFilter(tracker, 5, 0.5)
+	for i := 0; i < 100; i++ {
+		ip := net.IPv4(10, 0, 0, byte(i))
+		first, _ := f.Contains(ip)
+		second, _ := f.Contains(ip)
+		require.Equal(t, first, second)
+	}
+}
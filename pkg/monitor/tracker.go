@@ -0,0 +1,183 @@
+package monitor
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+const (
+	ScanType = "monitor"
+
+	// Closed is the event of the target that stopped responding
+	Closed = "closed"
+)
+
+// Event is a change of the lifecycle of the target between runs of the monitoring mode
+type Event struct {
+	ScanType string `json:"scan"`
+	Event    string `json:"event"`
+	// Target is the identifier of results of the target, e.g. ip:port
+	Target    string    `json:"target"`
+	FirstSeen time.Time `json:"first_seen"`
+	LastSeen  time.Time `json:"last_seen"`
+	// MissedRuns is the number of consecutive runs the target didn't respond in
+	MissedRuns int `json:"missed_runs"`
+}
+
+// Assert that monitor.Event conforms to the scan.Result interface
+var _ scan.Result = (*Event)(nil)
+
+func (e *Event) String() string {
+	return fmt.Sprintf("%-26s %-8s first_seen=%s last_seen=%s", e.Target, e.Event,
+		e.FirstSeen.UTC().Format(time.RFC3339), e.LastSeen.UTC().Format(time.RFC3339))
+}
+
+func (e *Event) ID() string {
+	return fmt.Sprintf("%s %s", e.Event, e.Target)
+}
+
+func (e *Event) MarshalJSON() ([]byte, error) {
+	// Type definition for the recursive call
+	type JEvent Event
+	// This works because JEvent doesn't have a MarshalJSON function associated with it
+	return json.Marshal(JEvent(*e))
+}
+
+type target struct {
+	host      string
+	firstSeen time.Time
+	lastSeen  time.Time
+	// lastRun is the number of the last run the target responded in
+	lastRun int
+}
+
+// noRun is the number of the run of hosts that were never changed or skipped
+const noRun = -1
+
+// host is the state of the host of targets used by differential rescans
+type host struct {
+	// changedRun is the number of the last run a target of the host appeared, missed or closed in
+	changedRun int
+	// skippedRun is the number of the last run the host wasn't scanned in
+	skippedRun int
+}
+
+// Tracker records targets of scan results in each run of the monitoring mode
+// and reports targets that didn't respond for several consecutive runs as closed
+type Tracker struct {
+	closedAfter int
+	now         func() time.Time
+
+	mu      sync.Mutex
+	run     int
+	targets map[string]*target
+	hosts   map[string]*host
+}
+
+// NewTracker creates the tracker that reports targets as closed after closedAfter consecutive missed runs
+func NewTracker(closedAfter int) *Tracker {
+	return &Tracker{closedAfter: closedAfter, now: time.Now,
+		targets: make(map[string]*target), hosts: make(map[string]*host)}
+}
+
+// Record saves the target of the scan result in the current run.
+// It always returns true to be used as a filter of logged results.
+func (t *Tracker) Record(result scan.Result) bool {
+	id := scan.UnwrapResult(result).ID()
+	now := t.now()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	tg, ok := t.targets[id]
+	if !ok {
+		tg = &target{host: targetHost(id), firstSeen: now}
+		t.targets[id] = tg
+		t.host(tg.host).changedRun = t.run
+	}
+	tg.lastSeen = now
+	tg.lastRun = t.run
+	return true
+}
+
+// StartRun finishes the current run and starts the next one, targets that reached the number
+// of missed runs are returned as closed events in the order of their last response and forgotten,
+// so that they are tracked anew if they respond again. Targets of hosts skipped in the run
+// keep their state since they weren't scanned.
+func (t *Tracker) StartRun() (events []*Event) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for id, tg := range t.targets {
+		h := t.host(tg.host)
+		if h.skippedRun == t.run {
+			tg.lastRun = t.run
+		}
+		missed := t.run - tg.lastRun
+		if missed > 0 {
+			h.changedRun = t.run
+		}
+		if missed < t.closedAfter {
+			continue
+		}
+		events = append(events, &Event{
+			ScanType:   ScanType,
+			Event:      Closed,
+			Target:     id,
+			FirstSeen:  tg.firstSeen,
+			LastSeen:   tg.lastSeen,
+			MissedRuns: missed,
+		})
+		delete(t.targets, id)
+	}
+	sort.Slice(events, func(i, j int) bool {
+		if !events[i].LastSeen.Equal(events[j].LastSeen) {
+			return events[i].LastSeen.Before(events[j].LastSeen)
+		}
+		return events[i].Target < events[j].Target
+	})
+	t.run++
+	return
+}
+
+// Run returns the number of the current run
+func (t *Tracker) Run() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.run
+}
+
+// Skip records that the host isn't scanned in the current run
+func (t *Tracker) Skip(hostname string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.host(hostname).skippedRun = t.run
+}
+
+// Changed reports whether targets of the host appeared, missed or closed within the last runs
+func (t *Tracker) Changed(hostname string, runs int) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	h, ok := t.hosts[hostname]
+	return ok && h.changedRun != noRun && t.run-h.changedRun < runs
+}
+
+func (t *Tracker) host(hostname string) *host {
+	h, ok := t.hosts[hostname]
+	if !ok {
+		h = &host{changedRun: noRun, skippedRun: noRun}
+		t.hosts[hostname] = h
+	}
+	return h
+}
+
+// targetHost returns the host of the target identifier in the form host:port
+func targetHost(id string) string {
+	if hostname, _, err := net.SplitHostPort(id); err == nil {
+		return hostname
+	}
+	return id
+}
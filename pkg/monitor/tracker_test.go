@@ -0,0 +1,162 @@
+package monitor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+	"github.com/v-byte-cpu/sx/pkg/scan/tcp"
+)
+
+func newTestTracker(closedAfter int) (*Tracker, *time.Time) {
+	now := time.Date(2021, 5, 1, 10, 0, 0, 0, time.UTC)
+	tracker := NewTracker(closedAfter)
+	tracker.now = func() time.Time { return now }
+	return tracker, &now
+}
+
+func openPort(ip string, port uint16) scan.Result {
+	return &tcp.ScanResult{ScanType: tcp.SYNScanType, IP: ip, Port: port}
+}
+
+func TestTrackerClosedAfterMissedRuns(t *testing.T) {
+	t.Parallel()
+	tracker, now := newTestTracker(2)
+	start := *now
+
+	// run 1: both ports are open
+	require.Empty(t, tracker.StartRun())
+	require.True(t, tracker.Record(openPort("192.168.0.1", 22)))
+	require.True(t, tracker.Record(openPort("192.168.0.1", 80)))
+
+	// run 2: only port 22 responds
+	*now = now.Add(time.Minute)
+	require.Empty(t, tracker.StartRun())
+	tracker.Record(openPort("192.168.0.1", 22))
+
+	// run 3: port 80 missed 1 run, port 22 responds
+	*now = now.Add(time.Minute)
+	require.Empty(t, tracker.StartRun())
+	tracker.Record(openPort("192.168.0.1", 22))
+
+	// run 4: port 80 missed 2 runs
+	*now = now.Add(time.Minute)
+	require.Equal(t, []*Event{{
+		ScanType:   ScanType,
+		Event:      Closed,
+		Target:     "192.168.0.1:80",
+		FirstSeen:  start,
+		LastSeen:   start,
+		MissedRuns: 2,
+	}}, tracker.StartRun())
+
+	// the closed port is reported once
+	*now = now.Add(time.Minute)
+	require.Empty(t, tracker.StartRun())
+}
+
+func TestTrackerReopened(t *testing.T) {
+	t.Parallel()
+	tracker, now := newTestTracker(1)
+
+	tracker.StartRun()
+	tracker.Record(openPort("192.168.0.1", 22))
+	tracker.StartRun()
+	events := tracker.StartRun()
+	require.Len(t, events, 1)
+
+	// the port is tracked anew after it responds again
+	*now = now.Add(time.Hour)
+	tracker.Record(openPort("192.168.0.1", 22))
+	events = tracker.StartRun()
+	require.Empty(t, events)
+	events = tracker.StartRun()
+	require.Len(t, events, 1)
+	require.Equal(t, *now, events[0].FirstSeen)
+}
+
+func TestTrackerEventOrder(t *testing.T) {
+	t.Parallel()
+	tracker, now := newTestTracker(1)
+
+	tracker.StartRun()
+	tracker.Record(openPort("192.168.0.2", 22))
+	tracker.Record(openPort("192.168.0.1", 22))
+	*now = now.Add(time.Second)
+	tracker.Record(openPort("192.168.0.0", 22))
+	tracker.StartRun()
+
+	events := tracker.StartRun()
+	require.Len(t, events, 3)
+	require.Equal(t, "192.168.0.1:22", events[0].Target)
+	require.Equal(t, "192.168.0.2:22", events[1].Target)
+	require.Equal(t, "192.168.0.0:22", events[2].Target)
+}
+
+func TestEventString(t *testing.T) {
+	t.Parallel()
+	event := &Event{
+		ScanType:   ScanType,
+		Event:      Closed,
+		Target:     "192.168.0.1:80",
+		FirstSeen:  time.Date(2021, 5, 1, 10, 0, 0, 0, time.UTC),
+		LastSeen:   time.Date(2021, 5, 1, 12, 0, 0, 0, time.UTC),
+		MissedRuns: 3,
+	}
+	require.Equal(t, "192.168.0.1:80             closed   first_seen=2021-05-01T10:00:00Z last_seen=2021-05-01T12:00:00Z",
+		event.String())
+	require.Equal(t, "closed 192.168.0.1:80", event.ID())
+}
+
+func TestTrackerSkippedHostKeepsTargets(t *testing.T) {
+	t.Parallel()
+	tracker, _ := newTestTracker(1)
+
+	tracker.StartRun()
+	tracker.Record(openPort("192.168.0.1", 22))
+	tracker.Record(openPort("192.168.0.2", 22))
+
+	// run 2: the first host isn't scanned, the second one doesn't respond
+	tracker.StartRun()
+	tracker.Skip("192.168.0.1")
+
+	events := tracker.StartRun()
+	require.Len(t, events, 1)
+	require.Equal(t, "192.168.0.2:22", events[0].Target)
+}
+
+func TestTrackerChanged(t *testing.T) {
+	t.Parallel()
+	tracker, _ := newTestTracker(3)
+
+	// run 1: the port appears
+	tracker.StartRun()
+	tracker.Record(openPort("192.168.0.1", 22))
+	require.True(t, tracker.Changed("192.168.0.1", 2))
+	require.False(t, tracker.Changed("192.168.0.2", 2))
+
+	// run 2: the port responds
+	tracker.StartRun()
+	tracker.Record(openPort("192.168.0.1", 22))
+	require.True(t, tracker.Changed("192.168.0.1", 2))
+
+	// run 3: the port responds, the change is older than 2 runs
+	tracker.StartRun()
+	tracker.Record(openPort("192.168.0.1", 22))
+	require.False(t, tracker.Changed("192.168.0.1", 2))
+
+	// run 4: the port missed run 3
+	tracker.StartRun()
+	require.False(t, tracker.Changed("192.168.0.1", 2))
+	tracker.StartRun()
+	require.True(t, tracker.Changed("192.168.0.1", 2))
+	require.Equal(t, 5, tracker.Run())
+}
+
+func TestTargetHost(t *testing.T) {
+	t.Parallel()
+	require.Equal(t, "192.168.0.1", targetHost("192.168.0.1:22"))
+	require.Equal(t, "2001:db8::1", targetHost("[2001:db8::1]:443"))
+	require.Equal(t, "192.168.0.1", targetHost("192.168.0.1"))
+}
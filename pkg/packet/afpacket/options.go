@@ -0,0 +1,16 @@
+package afpacket
+
+// Option configures the socket of the packet source
+type Option func(c *config)
+
+type config struct {
+	ringBlocks int
+}
+
+// WithRingBlocks sets the number of 512 KiB blocks of the receive ring,
+// the default ring of 128 blocks is used if it is zero
+func WithRingBlocks(blocks int) Option {
+	return func(c *config) {
+		c.ringBlocks = blocks
+	}
+}
@@ -20,8 +20,16 @@ type Source struct {
 // Assert that AfPacketSource conforms to the packet.ReadWriter interface
 var _ packet.ReadWriter = (*Source)(nil)
 
-func NewPacketSource(iface string, vpnMode bool) (*Source, error) {
-	handle, err := afp.NewTPacket(afp.SocketRaw, afp.OptInterface(iface))
+func NewPacketSource(iface string, vpnMode bool, opts ...Option) (*Source, error) {
+	var c config
+	for _, o := range opts {
+		o(&c)
+	}
+	tpacketOpts := []interface{}{afp.SocketRaw, afp.OptInterface(iface)}
+	if c.ringBlocks > 0 {
+		tpacketOpts = append(tpacketOpts, afp.OptNumBlocks(c.ringBlocks))
+	}
+	handle, err := afp.NewTPacket(tpacketOpts...)
 	if err != nil {
 		return nil, err
 	}
@@ -36,10 +44,27 @@ func NewPacketSource(iface string, vpnMode bool) (*Source, error) {
 // pcap calls it "snaplen" and default value used in tcpdump is 262144 bytes,
 // that is redundant for most scans, see pcap(3) and tcpdump(1) for more info
 func (s *Source) SetBPFFilter(bpfFilter string, maxPacketLength int) error {
-	pcapBPF, err := pcap.CompileBPFFilter(s.linkType, maxPacketLength, bpfFilter)
+	bpfIns, err := compileBPFFilter(s.linkType, maxPacketLength, bpfFilter)
 	if err != nil {
 		return err
 	}
+	return s.handle.SetBPF(bpfIns)
+}
+
+// CompileBPFFilter compiles the filter for packets of the interface in the same way as SetBPFFilter
+func CompileBPFFilter(vpnMode bool, bpfFilter string, maxPacketLength int) ([]bpf.RawInstruction, error) {
+	linkType := layers.LinkTypeEthernet
+	if vpnMode {
+		linkType = layers.LinkTypeIPv4
+	}
+	return compileBPFFilter(linkType, maxPacketLength, bpfFilter)
+}
+
+func compileBPFFilter(linkType layers.LinkType, maxPacketLength int, bpfFilter string) ([]bpf.RawInstruction, error) {
+	pcapBPF, err := pcap.CompileBPFFilter(linkType, maxPacketLength, bpfFilter)
+	if err != nil {
+		return nil, err
+	}
 	bpfIns := make([]bpf.RawInstruction, 0, len(pcapBPF))
 	for _, ins := range pcapBPF {
 		rawIns := bpf.RawInstruction{
@@ -50,13 +75,27 @@ func (s *Source) SetBPFFilter(bpfFilter string, maxPacketLength int) error {
 		}
 		bpfIns = append(bpfIns, rawIns)
 	}
-	return s.handle.SetBPF(bpfIns)
+	return bpfIns, nil
 }
 
 func (s *Source) Close() {
 	s.handle.Close()
 }
 
+// Stats returns kernel counters of the socket since it was opened
+func (s *Source) Stats() (*Stats, error) {
+	stats, statsV3, err := s.handle.SocketStats()
+	if err != nil {
+		return nil, err
+	}
+	// only counters of the TPACKET version of the socket are set
+	return &Stats{
+		Received:     uint64(stats.Packets() + statsV3.Packets()),
+		Dropped:      uint64(stats.Drops() + statsV3.Drops()),
+		QueueFreezes: uint64(statsV3.QueueFreezes()),
+	}, nil
+}
+
 func (s *Source) ReadPacketData() ([]byte, *gopacket.CaptureInfo, error) {
 	data, ci, err := s.handle.ZeroCopyReadPacketData()
 	return data, &ci, err
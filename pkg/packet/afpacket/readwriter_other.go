@@ -8,6 +8,7 @@ import (
 
 	"github.com/google/gopacket"
 	"github.com/v-byte-cpu/sx/pkg/packet"
+	"golang.org/x/net/bpf"
 )
 
 var ErrOS = errors.New("afpacket is not supported on your OS platform")
@@ -17,7 +18,7 @@ type Source struct{}
 // Assert that AfPacketSource conforms to the packet.ReadWriter interface
 var _ packet.ReadWriter = (*Source)(nil)
 
-func NewPacketSource(iface string, vpnMode bool) (*Source, error) {
+func NewPacketSource(iface string, vpnMode bool, opts ...Option) (*Source, error) {
 	return nil, ErrOS
 }
 
@@ -25,8 +26,16 @@ func (s *Source) SetBPFFilter(bpfFilter string, maxPacketLength int) error {
 	return ErrOS
 }
 
+func CompileBPFFilter(vpnMode bool, bpfFilter string, maxPacketLength int) ([]bpf.RawInstruction, error) {
+	return nil, ErrOS
+}
+
 func (s *Source) Close() {}
 
+func (s *Source) Stats() (*Stats, error) {
+	return nil, ErrOS
+}
+
 func (s *Source) ReadPacketData() (data []byte, info *gopacket.CaptureInfo, err error) {
 	err = ErrOS
 	return
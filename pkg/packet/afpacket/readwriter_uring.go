@@ -0,0 +1,104 @@
+//go:build linux && uring
+// +build linux,uring
+
+package afpacket
+
+import (
+	"net"
+	"time"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	"github.com/v-byte-cpu/sx/pkg/packet"
+	"github.com/v-byte-cpu/sx/pkg/uring"
+	"golang.org/x/sys/unix"
+)
+
+// URingSource reads packets from AF_PACKET socket with the experimental io_uring backend
+type URingSource struct {
+	fd       int
+	ifindex  int
+	linkType layers.LinkType
+	reader   *uring.PacketReader
+}
+
+// Assert that URingSource conforms to the packet.ReadWriter interface
+var _ packet.ReadWriter = (*URingSource)(nil)
+
+func NewURingPacketSource(iface string, vpnMode bool) (s *URingSource, err error) {
+	netIface, err := net.InterfaceByName(iface)
+	if err != nil {
+		return nil, err
+	}
+	proto := htons(unix.ETH_P_ALL)
+	fd, err := unix.Socket(unix.AF_PACKET, unix.SOCK_RAW, int(proto))
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			unix.Close(fd)
+		}
+	}()
+	if err = unix.Bind(fd, &unix.SockaddrLinklayer{Protocol: proto, Ifindex: netIface.Index}); err != nil {
+		return
+	}
+	var reader *uring.PacketReader
+	if reader, err = uring.NewPacketReader(fd); err != nil {
+		return
+	}
+	linkType := layers.LinkTypeEthernet
+	if vpnMode {
+		linkType = layers.LinkTypeIPv4
+	}
+	return &URingSource{fd: fd, ifindex: netIface.Index, linkType: linkType, reader: reader}, nil
+}
+
+func htons(v uint16) uint16 {
+	return v<<8 | v>>8
+}
+
+func (s *URingSource) SetBPFFilter(bpfFilter string, maxPacketLength int) error {
+	bpfIns, err := compileBPFFilter(s.linkType, maxPacketLength, bpfFilter)
+	if err != nil {
+		return err
+	}
+	filter := make([]unix.SockFilter, 0, len(bpfIns))
+	for _, ins := range bpfIns {
+		filter = append(filter, unix.SockFilter{Code: ins.Op, Jt: ins.Jt, Jf: ins.Jf, K: ins.K})
+	}
+	return unix.SetsockoptSockFprog(s.fd, unix.SOL_SOCKET, unix.SO_ATTACH_FILTER,
+		&unix.SockFprog{Len: uint16(len(filter)), Filter: &filter[0]})
+}
+
+func (s *URingSource) Close() {
+	s.reader.Close()
+	unix.Close(s.fd)
+}
+
+// Stats returns kernel counters of the socket since it was opened, counters are reset on each call
+func (s *URingSource) Stats() (*Stats, error) {
+	stats, err := unix.GetsockoptTpacketStats(s.fd, unix.SOL_PACKET, unix.PACKET_STATISTICS)
+	if err != nil {
+		return nil, err
+	}
+	return &Stats{Received: uint64(stats.Packets), Dropped: uint64(stats.Drops)}, nil
+}
+
+func (s *URingSource) ReadPacketData() ([]byte, *gopacket.CaptureInfo, error) {
+	data, err := s.reader.Read()
+	if err != nil {
+		return nil, nil, err
+	}
+	return data, &gopacket.CaptureInfo{
+		Timestamp:      time.Now(),
+		CaptureLength:  len(data),
+		Length:         len(data),
+		InterfaceIndex: s.ifindex,
+	}, nil
+}
+
+func (s *URingSource) WritePacketData(pkt []byte) error {
+	_, err := unix.Write(s.fd, pkt)
+	return err
+}
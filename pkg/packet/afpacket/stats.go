@@ -0,0 +1,11 @@
+package afpacket
+
+// Stats are kernel counters of the AF_PACKET socket
+type Stats struct {
+	// Received is the number of packets that passed the BPF filter, including dropped packets
+	Received uint64
+	// Dropped is the number of packets dropped because the socket buffer or the ring was full
+	Dropped uint64
+	// QueueFreezes is the number of times the ring was full and the kernel stopped filling it
+	QueueFreezes uint64
+}
@@ -0,0 +1,186 @@
+package packet
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	"golang.org/x/net/bpf"
+)
+
+var errBPFInstructions = errors.New("unsupported BPF instructions")
+
+// Matcher returns the number of bytes of the packet accepted by the filter, zero if the packet is dropped
+type Matcher func(data []byte) (int, error)
+
+// NewBPFMatcher evaluates compiled BPF instructions in userspace the same way the kernel filters received packets
+func NewBPFMatcher(bpfIns []bpf.RawInstruction) (Matcher, error) {
+	ins, allDecoded := bpf.Disassemble(bpfIns)
+	if !allDecoded {
+		return nil, errBPFInstructions
+	}
+	vm, err := bpf.NewVM(ins)
+	if err != nil {
+		return nil, err
+	}
+	// VM is not safe for concurrent use
+	var mu sync.Mutex
+	return func(data []byte) (int, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		return vm.Run(data)
+	}, nil
+}
+
+// SampledPacket is the debug record of the sampled received packet with its decode diagnostics
+type SampledPacket struct {
+	Time time.Time `json:"time"`
+	// Seq is the number of the packet among all received packets, starting from 1
+	Seq    uint64 `json:"seq"`
+	Length int    `json:"length"`
+	// Matched is true if the packet passes the BPF filter of the scan, nil if the filter is unknown
+	Matched *bool `json:"matched,omitempty"`
+	// Captured is the number of bytes of the matched packet delivered to the scan,
+	// packets are truncated if it is less than the length
+	Captured    int      `json:"captured,omitempty"`
+	FilterError string   `json:"filter_error,omitempty"`
+	Layers      []string `json:"layers"`
+	SrcMAC      string   `json:"src_mac,omitempty"`
+	DstMAC      string   `json:"dst_mac,omitempty"`
+	SrcIP       string   `json:"src_ip,omitempty"`
+	DstIP       string   `json:"dst_ip,omitempty"`
+	Protocol    string   `json:"protocol,omitempty"`
+	SrcPort     uint16   `json:"src_port,omitempty"`
+	DstPort     uint16   `json:"dst_port,omitempty"`
+	TCPFlags    string   `json:"tcp_flags,omitempty"`
+	ICMP        string   `json:"icmp,omitempty"`
+	DecodeError string   `json:"decode_error,omitempty"`
+}
+
+// Sampler writes every N-th received packet with decode diagnostics in NDJSON format,
+// it helps to find out why expected responses don't become scan results
+type Sampler struct {
+	every     uint64
+	matcher   Matcher
+	firstType gopacket.LayerType
+
+	mu    sync.Mutex
+	enc   *json.Encoder
+	count uint64
+}
+
+// Assert that Sampler conforms to the Processor interface
+var _ Processor = (*Sampler)(nil)
+
+type SamplerOption func(s *Sampler)
+
+// WithSamplerMatcher sets the filter of the scan to report whether sampled packets match it
+func WithSamplerMatcher(m Matcher) SamplerOption {
+	return func(s *Sampler) {
+		s.matcher = m
+	}
+}
+
+// WithSamplerVPNmode decodes packets starting from the IPv4 layer instead of Ethernet
+func WithSamplerVPNmode(vpnMode bool) SamplerOption {
+	return func(s *Sampler) {
+		if vpnMode {
+			s.firstType = layers.LayerTypeIPv4
+		}
+	}
+}
+
+// NewSampler samples one in every received packets, all packets are sampled if every is 1
+func NewSampler(w io.Writer, every int, opts ...SamplerOption) *Sampler {
+	if every < 1 {
+		every = 1
+	}
+	s := &Sampler{
+		every:     uint64(every),
+		firstType: layers.LayerTypeEthernet,
+		enc:       json.NewEncoder(w),
+	}
+	for _, o := range opts {
+		o(s)
+	}
+	return s
+}
+
+func (s *Sampler) ProcessPacketData(data []byte, ci *gopacket.CaptureInfo) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.count++
+	if (s.count-1)%s.every != 0 {
+		return nil
+	}
+	return s.enc.Encode(s.sample(data, ci))
+}
+
+func (s *Sampler) sample(data []byte, ci *gopacket.CaptureInfo) *SampledPacket {
+	p := &SampledPacket{Time: ci.Timestamp, Seq: s.count, Length: len(data)}
+	if ci.Length > 0 {
+		p.Length = ci.Length
+	}
+	if s.matcher != nil {
+		captured, err := s.matcher(data)
+		if err != nil {
+			p.FilterError = err.Error()
+		} else {
+			matched := captured > 0
+			p.Matched = &matched
+			if matched && captured < p.Length {
+				p.Captured = captured
+			}
+		}
+	}
+
+	pkt := gopacket.NewPacket(data, s.firstType, gopacket.DecodeOptions{Lazy: true, NoCopy: true})
+	for _, layer := range pkt.Layers() {
+		p.Layers = append(p.Layers, layer.LayerType().String())
+		switch l := layer.(type) {
+		case *layers.Ethernet:
+			p.SrcMAC, p.DstMAC = l.SrcMAC.String(), l.DstMAC.String()
+		case *layers.ARP:
+			p.Protocol = "arp"
+		case *layers.IPv4:
+			p.SrcIP, p.DstIP = l.SrcIP.String(), l.DstIP.String()
+		case *layers.IPv6:
+			p.SrcIP, p.DstIP = l.SrcIP.String(), l.DstIP.String()
+		case *layers.TCP:
+			p.Protocol = "tcp"
+			p.SrcPort, p.DstPort = uint16(l.SrcPort), uint16(l.DstPort)
+			p.TCPFlags = tcpFlags(l)
+		case *layers.UDP:
+			p.Protocol = "udp"
+			p.SrcPort, p.DstPort = uint16(l.SrcPort), uint16(l.DstPort)
+		case *layers.ICMPv4:
+			p.Protocol = "icmp"
+			p.ICMP = l.TypeCode.String()
+		}
+	}
+	if errLayer := pkt.ErrorLayer(); errLayer != nil {
+		p.DecodeError = errLayer.Error().Error()
+	}
+	return p
+}
+
+func tcpFlags(l *layers.TCP) string {
+	var flags []string
+	for _, f := range []struct {
+		set  bool
+		name string
+	}{
+		{l.SYN, "SYN"}, {l.ACK, "ACK"}, {l.FIN, "FIN"}, {l.RST, "RST"},
+		{l.PSH, "PSH"}, {l.URG, "URG"}, {l.ECE, "ECE"}, {l.CWR, "CWR"},
+	} {
+		if f.set {
+			flags = append(flags, f.name)
+		}
+	}
+	return strings.Join(flags, "|")
+}
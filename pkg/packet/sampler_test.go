@@ -0,0 +1,149 @@
+package packet
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"net"
+	"testing"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/bpf"
+)
+
+func newSampleTCPPacket(t *testing.T) []byte {
+	t.Helper()
+	eth := &layers.Ethernet{
+		SrcMAC:       net.HardwareAddr{0x10, 0x11, 0x12, 0x13, 0x14, 0x15},
+		DstMAC:       net.HardwareAddr{0x20, 0x21, 0x22, 0x23, 0x24, 0x25},
+		EthernetType: layers.EthernetTypeIPv4,
+	}
+	ip := &layers.IPv4{
+		Version:  4,
+		TTL:      64,
+		Protocol: layers.IPProtocolTCP,
+		SrcIP:    net.IPv4(192, 168, 0, 3).To4(),
+		DstIP:    net.IPv4(192, 168, 0, 2).To4(),
+	}
+	tcp := &layers.TCP{SrcPort: 22, DstPort: 40000, SYN: true, ACK: true, Window: 1024}
+	require.NoError(t, tcp.SetNetworkLayerForChecksum(ip))
+	buf := gopacket.NewSerializeBuffer()
+	require.NoError(t, gopacket.SerializeLayers(buf,
+		gopacket.SerializeOptions{FixLengths: true, ComputeChecksums: true}, eth, ip, tcp))
+	return buf.Bytes()
+}
+
+func newSampleARPPacket(t *testing.T) []byte {
+	t.Helper()
+	eth := &layers.Ethernet{
+		SrcMAC:       net.HardwareAddr{0x10, 0x11, 0x12, 0x13, 0x14, 0x15},
+		DstMAC:       layers.EthernetBroadcast,
+		EthernetType: layers.EthernetTypeARP,
+	}
+	arp := &layers.ARP{
+		AddrType:          layers.LinkTypeEthernet,
+		Protocol:          layers.EthernetTypeIPv4,
+		HwAddressSize:     6,
+		ProtAddressSize:   4,
+		Operation:         layers.ARPRequest,
+		SourceHwAddress:   []byte{0x10, 0x11, 0x12, 0x13, 0x14, 0x15},
+		SourceProtAddress: []byte{192, 168, 0, 3},
+		DstHwAddress:      []byte{0, 0, 0, 0, 0, 0},
+		DstProtAddress:    []byte{192, 168, 0, 2},
+	}
+	buf := gopacket.NewSerializeBuffer()
+	require.NoError(t, gopacket.SerializeLayers(buf, gopacket.SerializeOptions{}, eth, arp))
+	return buf.Bytes()
+}
+
+// newIPv4Matcher accepts the first 40 bytes of IPv4 packets
+func newIPv4Matcher(t *testing.T) Matcher {
+	t.Helper()
+	ins, err := bpf.Assemble([]bpf.Instruction{
+		bpf.LoadAbsolute{Off: 12, Size: 2},
+		bpf.JumpIf{Cond: bpf.JumpEqual, Val: uint32(layers.EthernetTypeIPv4), SkipFalse: 1},
+		bpf.RetConstant{Val: 40},
+		bpf.RetConstant{Val: 0},
+	})
+	require.NoError(t, err)
+	m, err := NewBPFMatcher(ins)
+	require.NoError(t, err)
+	return m
+}
+
+func readSampledPackets(t *testing.T, data []byte) []*SampledPacket {
+	t.Helper()
+	var result []*SampledPacket
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		var p SampledPacket
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &p))
+		result = append(result, &p)
+	}
+	return result
+}
+
+func TestSamplerEvery(t *testing.T) {
+	t.Parallel()
+	var buf bytes.Buffer
+	s := NewSampler(&buf, 2)
+	data := newSampleTCPPacket(t)
+	for i := 0; i < 5; i++ {
+		require.NoError(t, s.ProcessPacketData(data, newCaptureInfo()))
+	}
+	samples := readSampledPackets(t, buf.Bytes())
+	require.Len(t, samples, 3)
+	for i, p := range samples {
+		require.Equal(t, uint64(2*i+1), p.Seq)
+		require.Nil(t, p.Matched, "matched without filter")
+	}
+}
+
+func TestSamplerDiagnostics(t *testing.T) {
+	t.Parallel()
+	var buf bytes.Buffer
+	s := NewSampler(&buf, 1, WithSamplerMatcher(newIPv4Matcher(t)))
+	tcpData := newSampleTCPPacket(t)
+	require.NoError(t, s.ProcessPacketData(tcpData, newCaptureInfo()))
+	require.NoError(t, s.ProcessPacketData(newSampleARPPacket(t), newCaptureInfo()))
+	// the TCP header is cut off
+	require.NoError(t, s.ProcessPacketData(tcpData[:40], newCaptureInfo()))
+
+	samples := readSampledPackets(t, buf.Bytes())
+	require.Len(t, samples, 3)
+
+	tcp := samples[0]
+	require.NotNil(t, tcp.Matched)
+	require.True(t, *tcp.Matched)
+	require.Equal(t, 40, tcp.Captured)
+	require.Equal(t, len(tcpData), tcp.Length)
+	require.Equal(t, []string{"Ethernet", "IPv4", "TCP"}, tcp.Layers)
+	require.Equal(t, "10:11:12:13:14:15", tcp.SrcMAC)
+	require.Equal(t, "192.168.0.3", tcp.SrcIP)
+	require.Equal(t, "192.168.0.2", tcp.DstIP)
+	require.Equal(t, "tcp", tcp.Protocol)
+	require.Equal(t, uint16(22), tcp.SrcPort)
+	require.Equal(t, uint16(40000), tcp.DstPort)
+	require.Equal(t, "SYN|ACK", tcp.TCPFlags)
+	require.Empty(t, tcp.DecodeError)
+
+	arp := samples[1]
+	require.NotNil(t, arp.Matched)
+	require.False(t, *arp.Matched)
+	require.Zero(t, arp.Captured)
+	require.Equal(t, "arp", arp.Protocol)
+
+	truncated := samples[2]
+	require.True(t, *truncated.Matched)
+	require.Zero(t, truncated.Captured)
+	require.NotEmpty(t, truncated.DecodeError)
+}
+
+func TestNewBPFMatcherInvalid(t *testing.T) {
+	t.Parallel()
+	// the filter doesn't return
+	_, err := NewBPFMatcher([]bpf.RawInstruction{{Op: 0x20, K: 12}})
+	require.Error(t, err)
+}
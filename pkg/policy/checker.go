@@ -0,0 +1,183 @@
+package policy
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+const (
+	ScanType = "policy"
+
+	UnexpectedOpen = "unexpected_open"
+	ExpectedClosed = "expected_closed"
+)
+
+// Violation is a difference between the expected state and the scan results
+type Violation struct {
+	ScanType  string `json:"scan"`
+	Violation string `json:"violation"`
+	IP        string `json:"ip"`
+	Port      uint16 `json:"port"`
+	// Rule is a comma-separated list of rules matching the host, empty if the host doesn't match any rule
+	Rule string `json:"rule,omitempty"`
+}
+
+// Assert that policy.Violation conforms to the scan.Result interface
+var _ scan.Result = (*Violation)(nil)
+
+func (v *Violation) String() string {
+	return fmt.Sprintf("%-20s %-5d %-16s %s", v.IP, v.Port, v.Violation, v.Rule)
+}
+
+func (v *Violation) ID() string {
+	return fmt.Sprintf("%s %s:%d", v.Violation, v.IP, v.Port)
+}
+
+func (v *Violation) MarshalJSON() ([]byte, error) {
+	// Type definition for the recursive call
+	type JViolation Violation
+	// This works because JViolation doesn't have a MarshalJSON function associated with it
+	return json.Marshal(JViolation(*v))
+}
+
+type hostPort struct {
+	ip   net.IP
+	port uint16
+}
+
+// Checker records probed and open ports from scan results and compares them with the policy
+type Checker struct {
+	policy *Policy
+
+	mu     sync.Mutex
+	seen   map[string]bool
+	probed map[string]bool
+	open   []*hostPort
+}
+
+func NewChecker(p *Policy) *Checker {
+	return &Checker{policy: p, seen: make(map[string]bool), probed: make(map[string]bool)}
+}
+
+// Probe saves the probed port, expected ports are reported closed only if they were probed
+func (c *Checker) Probe(ip net.IP, port uint16) {
+	if ip4 := ip.To4(); ip4 != nil {
+		ip = ip4
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.probed[fmt.Sprintf("%s:%d", ip, port)] = true
+}
+
+// Record saves the open port of the scan result, results without ip:port identifier are ignored.
+// It always returns true to be used as a filter of logged results.
+func (c *Checker) Record(result scan.Result) bool {
+	hp, ok := parseHostPort(scan.UnwrapResult(result).ID())
+	if !ok {
+		return true
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	key := fmt.Sprintf("%s:%d", hp.ip, hp.port)
+	if !c.seen[key] {
+		c.seen[key] = true
+		c.open = append(c.open, hp)
+	}
+	return true
+}
+
+// Violations returns unexpected open ports in the order of recording
+// followed by expected ports that were probed and not found open
+func (c *Checker) Violations() (result []*Violation) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, hp := range c.open {
+		var rules []string
+		allowed := false
+		for _, rule := range c.policy.Rules {
+			if !rule.matchHost(hp.ip) {
+				continue
+			}
+			rules = append(rules, rule.Name)
+			if containsPort(rule.Open, hp.port) || containsPort(rule.Allowed, hp.port) {
+				allowed = true
+			}
+		}
+		if !allowed {
+			result = append(result, &Violation{
+				ScanType:  ScanType,
+				Violation: UnexpectedOpen,
+				IP:        hp.ip.String(),
+				Port:      hp.port,
+				Rule:      strings.Join(rules, ","),
+			})
+		}
+	}
+	for _, rule := range c.policy.Rules {
+		result = append(result, c.closedPorts(rule)...)
+	}
+	return
+}
+
+func (c *Checker) closedPorts(rule *Rule) (result []*Violation) {
+	for _, subnet := range rule.subnets {
+		for addr := subnet.IP.Mask(subnet.Mask); subnet.Contains(addr); addr = nextIP(addr) {
+			for _, ports := range rule.Open {
+				for port := int(ports.StartPort); port <= int(ports.EndPort); port++ {
+					key := fmt.Sprintf("%s:%d", addr, port)
+					if !c.probed[key] || c.seen[key] {
+						continue
+					}
+					result = append(result, &Violation{
+						ScanType:  ScanType,
+						Violation: ExpectedClosed,
+						IP:        addr.String(),
+						Port:      uint16(port),
+						Rule:      rule.Name,
+					})
+				}
+			}
+		}
+	}
+	return
+}
+
+// nextIP returns a copy of the IP address incremented by one,
+// the zero address is returned on overflow
+func nextIP(addr net.IP) net.IP {
+	next := make(net.IP, len(addr))
+	copy(next, addr)
+	for i := len(next) - 1; i >= 0; i-- {
+		next[i]++
+		if next[i] != 0 {
+			break
+		}
+	}
+	return next
+}
+
+// parseHostPort parses result identifier in the form ip:port
+func parseHostPort(id string) (*hostPort, bool) {
+	i := strings.LastIndexByte(id, ':')
+	if i < 0 {
+		return nil, false
+	}
+	addr := net.ParseIP(strings.Trim(id[:i], "[]"))
+	if addr == nil {
+		return nil, false
+	}
+	port, err := strconv.ParseUint(id[i+1:], 10, 16)
+	if err != nil {
+		return nil, false
+	}
+	if ip4 := addr.To4(); ip4 != nil {
+		addr = ip4
+	}
+	return &hostPort{ip: addr, port: uint16(port)}, true
+}
@@ -0,0 +1,104 @@
+package policy
+
+import (
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+	"github.com/v-byte-cpu/sx/pkg/scan/arp"
+	"github.com/v-byte-cpu/sx/pkg/scan/http"
+	"github.com/v-byte-cpu/sx/pkg/scan/tcp"
+)
+
+const testPolicy = `
+rules:
+  - name: web
+    hosts: [10.0.0.0/31]
+    open: [80, 443]
+    allowed: [8000-8100]
+  - name: admin
+    hosts: [10.0.0.1]
+    allowed: [22]
+`
+
+func TestCheckerViolations(t *testing.T) {
+	t.Parallel()
+	p, err := Parse(strings.NewReader(testPolicy))
+	require.NoError(t, err)
+	c := NewChecker(p)
+	for _, ip := range []string{"10.0.0.0", "10.0.0.1"} {
+		for _, port := range []uint16{22, 80, 443} {
+			c.Probe(net.ParseIP(ip), port)
+		}
+	}
+
+	results := []scan.Result{
+		&tcp.ScanResult{ScanType: tcp.SYNScanType, IP: "10.0.0.0", Port: 80},
+		&tcp.ScanResult{ScanType: tcp.SYNScanType, IP: "10.0.0.0", Port: 443},
+		&tcp.ScanResult{ScanType: tcp.SYNScanType, IP: "10.0.0.0", Port: 22},
+		&tcp.ScanResult{ScanType: tcp.SYNScanType, IP: "10.0.0.0", Port: 22},
+		&scan.MetaResult{
+			Result: &http.ScanResult{ScanType: http.ScanType, Host: "10.0.0.1:8080"},
+			Meta:   map[string]interface{}{"host": "web1"},
+		},
+		&tcp.ScanResult{ScanType: tcp.SYNScanType, IP: "10.0.0.1", Port: 22},
+		&tcp.ScanResult{ScanType: tcp.SYNScanType, IP: "192.168.0.1", Port: 3306},
+		// results without ports are ignored
+		&arp.ScanResult{IP: "10.0.0.5"},
+	}
+	for _, result := range results {
+		require.True(t, c.Record(result))
+	}
+
+	require.Equal(t, []*Violation{
+		{ScanType: ScanType, Violation: UnexpectedOpen, IP: "10.0.0.0", Port: 22, Rule: "web"},
+		{ScanType: ScanType, Violation: UnexpectedOpen, IP: "192.168.0.1", Port: 3306},
+		{ScanType: ScanType, Violation: ExpectedClosed, IP: "10.0.0.1", Port: 80, Rule: "web"},
+		{ScanType: ScanType, Violation: ExpectedClosed, IP: "10.0.0.1", Port: 443, Rule: "web"},
+	}, c.Violations())
+}
+
+func TestCheckerNoViolations(t *testing.T) {
+	t.Parallel()
+	p, err := Parse(strings.NewReader("rules: [{hosts: [10.0.0.1], open: [80]}]"))
+	require.NoError(t, err)
+	c := NewChecker(p)
+	c.Record(&tcp.ScanResult{ScanType: tcp.SYNScanType, IP: "10.0.0.1", Port: 80})
+	require.Empty(t, c.Violations())
+}
+
+func TestCheckerNotProbed(t *testing.T) {
+	t.Parallel()
+	p, err := Parse(strings.NewReader(testPolicy))
+	require.NoError(t, err)
+	c := NewChecker(p)
+	// only 10.0.0.1:80 was scanned, other expected ports are not checked
+	c.Probe(net.ParseIP("10.0.0.1"), 80)
+	require.Equal(t, []*Violation{
+		{ScanType: ScanType, Violation: ExpectedClosed, IP: "10.0.0.1", Port: 80, Rule: "web"},
+	}, c.Violations())
+}
+
+func TestViolationMarshalJSON(t *testing.T) {
+	t.Parallel()
+	v := &Violation{ScanType: ScanType, Violation: UnexpectedOpen, IP: "10.0.0.1", Port: 22}
+	data, err := v.MarshalJSON()
+	require.NoError(t, err)
+	require.Equal(t, `{"scan":"policy","violation":"unexpected_open","ip":"10.0.0.1","port":22}`, string(data))
+	require.Equal(t, "unexpected_open 10.0.0.1:22", v.ID())
+}
+
+func TestParseHostPort(t *testing.T) {
+	t.Parallel()
+	hp, ok := parseHostPort("[2001:db8::1]:443")
+	require.True(t, ok)
+	require.Equal(t, "2001:db8::1", hp.ip.String())
+	require.Equal(t, uint16(443), hp.port)
+
+	for _, id := range []string{"10.0.0.1", "example.com:80", "10.0.0.1:http", "example.com A 10.0.0.1"} {
+		_, ok = parseHostPort(id)
+		require.False(t, ok, id)
+	}
+}
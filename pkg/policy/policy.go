@@ -0,0 +1,157 @@
+package policy
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/v-byte-cpu/sx/pkg/scan"
+	"gopkg.in/yaml.v3"
+)
+
+// maxExpectedHosts limits the number of addresses of subnets with expected open ports
+// since every address is checked for closed ports
+const maxExpectedHosts = 1 << 16
+
+var (
+	ErrNoRules = errors.New("invalid policy: at least one rule required")
+
+	errRuleHosts = errors.New("invalid policy rule: hosts required")
+)
+
+// Policy declares expected state of hosts: ports that must be open and ports that are allowed to be open,
+// all other open ports are violations
+type Policy struct {
+	Rules []*Rule `yaml:"rules"`
+}
+
+// Rule declares expected open ports of the group of hosts
+type Rule struct {
+	Name string `yaml:"name"`
+	// Hosts are IP addresses or subnets in CIDR notation
+	Hosts []string `yaml:"hosts"`
+	// Open ports must be open on every host of the rule
+	Open []Ports `yaml:"open"`
+	// Allowed ports may be open on hosts of the rule
+	Allowed []Ports `yaml:"allowed"`
+
+	subnets []*net.IPNet
+}
+
+// Ports is a port or port range in the form start-end
+type Ports struct {
+	scan.PortRange
+}
+
+func (p *Ports) UnmarshalYAML(node *yaml.Node) (err error) {
+	parts := strings.SplitN(node.Value, "-", 2)
+	var start, end uint64
+	if start, err = strconv.ParseUint(strings.TrimSpace(parts[0]), 10, 16); err != nil {
+		return fmt.Errorf("invalid policy port %q", node.Value)
+	}
+	end = start
+	if len(parts) > 1 {
+		if end, err = strconv.ParseUint(strings.TrimSpace(parts[1]), 10, 16); err != nil {
+			return fmt.Errorf("invalid policy port %q", node.Value)
+		}
+	}
+	if start == 0 || start > end {
+		return fmt.Errorf("invalid policy port range %q", node.Value)
+	}
+	p.StartPort, p.EndPort = uint16(start), uint16(end)
+	return nil
+}
+
+// LoadFile reads policy from the YAML file
+func LoadFile(path string) (*Policy, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Parse(f)
+}
+
+// Parse reads policy in YAML format, e.g.
+//
+//	rules:
+//	  - name: web
+//	    hosts: [10.0.0.1, 10.0.1.0/28]
+//	    open: [80, 443]
+//	    allowed: [22, 8000-8100]
+func Parse(r io.Reader) (*Policy, error) {
+	var p Policy
+	dec := yaml.NewDecoder(r)
+	dec.KnownFields(true)
+	if err := dec.Decode(&p); err != nil {
+		return nil, fmt.Errorf("invalid policy: %w", err)
+	}
+	if len(p.Rules) == 0 {
+		return nil, ErrNoRules
+	}
+	for i, rule := range p.Rules {
+		if len(rule.Name) == 0 {
+			rule.Name = fmt.Sprintf("rule%d", i+1)
+		}
+		if err := rule.parseHosts(); err != nil {
+			return nil, err
+		}
+	}
+	return &p, nil
+}
+
+func (r *Rule) parseHosts() error {
+	if len(r.Hosts) == 0 {
+		return fmt.Errorf("%w: %s", errRuleHosts, r.Name)
+	}
+	for _, host := range r.Hosts {
+		subnet, err := ParseSubnet(host)
+		if err != nil {
+			return fmt.Errorf("invalid policy rule %s: %w", r.Name, err)
+		}
+		if ones, bits := subnet.Mask.Size(); len(r.Open) > 0 && bits-ones > 16 {
+			return fmt.Errorf("invalid policy rule %s: subnet %s with open ports has more than %d hosts",
+				r.Name, host, maxExpectedHosts)
+		}
+		r.subnets = append(r.subnets, subnet)
+	}
+	return nil
+}
+
+// ParseSubnet parses IP address or subnet in CIDR notation
+func ParseSubnet(host string) (*net.IPNet, error) {
+	if strings.Contains(host, "/") {
+		_, subnet, err := net.ParseCIDR(host)
+		return subnet, err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return nil, fmt.Errorf("invalid host %q", host)
+	}
+	if ip4 := ip.To4(); ip4 != nil {
+		return &net.IPNet{IP: ip4, Mask: net.CIDRMask(32, 32)}, nil
+	}
+	return &net.IPNet{IP: ip, Mask: net.CIDRMask(128, 128)}, nil
+}
+
+func (r *Rule) matchHost(ip net.IP) bool {
+	for _, subnet := range r.subnets {
+		if subnet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsPort(ports []Ports, port uint16) bool {
+	for _, r := range ports {
+		if r.StartPort <= port && port <= r.EndPort {
+			return true
+		}
+	}
+	return false
+}
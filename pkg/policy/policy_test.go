@@ -0,0 +1,63 @@
+package policy
+
+import (
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+	p, err := Parse(strings.NewReader(`
+rules:
+  - name: web
+    hosts: [10.0.0.1, 10.0.1.0/30]
+    open: [80, "443"]
+    allowed: [22, 8000-8100]
+  - hosts: [0.0.0.0/0]
+    allowed: [22]
+`))
+	require.NoError(t, err)
+	require.Len(t, p.Rules, 2)
+
+	web := p.Rules[0]
+	require.Equal(t, "web", web.Name)
+	require.Equal(t, []Ports{{scan.PortRange{StartPort: 80, EndPort: 80}}, {scan.PortRange{StartPort: 443, EndPort: 443}}}, web.Open)
+	require.Equal(t, []Ports{{scan.PortRange{StartPort: 22, EndPort: 22}}, {scan.PortRange{StartPort: 8000, EndPort: 8100}}}, web.Allowed)
+	require.True(t, web.matchHost(net.IPv4(10, 0, 0, 1)))
+	require.True(t, web.matchHost(net.IPv4(10, 0, 1, 3)))
+	require.False(t, web.matchHost(net.IPv4(10, 0, 1, 4)))
+
+	require.Equal(t, "rule2", p.Rules[1].Name)
+	require.True(t, p.Rules[1].matchHost(net.IPv4(192, 168, 0, 1)))
+}
+
+func TestParseErrors(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name   string
+		policy string
+	}{
+		{name: "NoRules", policy: "rules: []"},
+		{name: "NoHosts", policy: "rules: [{name: web, open: [80]}]"},
+		{name: "InvalidHost", policy: "rules: [{hosts: [web.local], open: [80]}]"},
+		{name: "InvalidSubnet", policy: "rules: [{hosts: [10.0.0.0/33], open: [80]}]"},
+		{name: "LargeSubnet", policy: "rules: [{hosts: [10.0.0.0/8], open: [80]}]"},
+		{name: "InvalidPort", policy: "rules: [{hosts: [10.0.0.1], open: [http]}]"},
+		{name: "ZeroPort", policy: "rules: [{hosts: [10.0.0.1], open: [0]}]"},
+		{name: "InvalidPortRange", policy: "rules: [{hosts: [10.0.0.1], allowed: [100-10]}]"},
+		{name: "UnknownField", policy: "rules: [{hosts: [10.0.0.1], closed: [80]}]"},
+		{name: "InvalidYAML", policy: "rules: [{"},
+	}
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := Parse(strings.NewReader(tt.policy))
+			require.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package redact
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+var errInvalidJSON = errors.New("invalid JSON of the result")
+
+// object is the JSON object that keeps the order of its fields, so that redacted results
+// are written with fields in the same order as the original ones
+type object struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+func (o *object) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, key := range o.keys {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		name, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(o.values[key])
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(name)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// decode parses JSON with objects of ordered fields and numbers of json.Number type
+func decode(data []byte) (interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	value, err := decodeValue(dec)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errInvalidJSON, err)
+	}
+	if dec.More() {
+		return nil, errInvalidJSON
+	}
+	return value, nil
+}
+
+func decodeValue(dec *json.Decoder) (interface{}, error) {
+	token, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	switch token {
+	case json.Delim('{'):
+		obj := &object{values: make(map[string]interface{})}
+		for dec.More() {
+			if token, err = dec.Token(); err != nil {
+				return nil, err
+			}
+			key := token.(string)
+			value, err := decodeValue(dec)
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := obj.values[key]; !ok {
+				obj.keys = append(obj.keys, key)
+			}
+			obj.values[key] = value
+		}
+		_, err = dec.Token()
+		return obj, err
+	case json.Delim('['):
+		array := []interface{}{}
+		for dec.More() {
+			value, err := decodeValue(dec)
+			if err != nil {
+				return nil, err
+			}
+			array = append(array, value)
+		}
+		_, err = dec.Token()
+		return array, err
+	}
+	return token, nil
+}
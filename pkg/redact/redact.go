@@ -0,0 +1,388 @@
+// Package redact anonymizes scan results before they are written, so that they can be shared
+// without exposing addresses and software versions of the scanned network
+package redact
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+// IP redaction modes
+const (
+	// IPHash replaces IPs with their keyed hashes, the same IP has the same hash with the same key
+	IPHash = "hash"
+	// IPTruncate replaces IPs with their networks, /24 for IPv4 and /48 for IPv6
+	IPTruncate = "truncate"
+)
+
+const (
+	ipv4PrefixLen = 24
+	ipv6PrefixLen = 48
+	// hashSize is the number of bytes of the keyed hash in IP replacements
+	hashSize = 8
+	keySize  = 32
+)
+
+var (
+	ErrIPMode        = errors.New("invalid IP redaction mode: hash or truncate required")
+	errInvalidResult = errors.New("invalid result: redacted copy of the result is not a result")
+)
+
+// BannerFields are fields of results with server greetings and software versions removed by WithStrippedBanners
+var BannerFields = []string{"banner", "server", "title"}
+
+// ipRegexp matches IPv6 and IPv4 addresses embedded in strings, e.g. in URLs and banners,
+// IPv6 candidates are validated by net.ParseIP, so that times and MAC addresses are kept
+var ipRegexp = regexp.MustCompile(`(?i)[0-9a-f]*:[0-9a-f:.]*:[0-9a-f.]*|\b(?:\d{1,3}\.){3}\d{1,3}\b`)
+
+// Redactor rewrites JSON objects of results: IPs in string values are hashed or truncated,
+// stripped fields are removed at any depth and dropped fields are removed by their paths
+type Redactor struct {
+	ipMode   string
+	key      []byte
+	stripped map[string]bool
+	dropped  [][]string
+}
+
+type Option func(*Redactor)
+
+// WithIPMode sets the redaction mode of IPs, IPs are kept if the mode is empty
+func WithIPMode(mode string) Option {
+	return func(r *Redactor) {
+		r.ipMode = mode
+	}
+}
+
+// WithKey sets the key of IP hashes, the random key is used by default,
+// so hashes are consistent only within one run
+func WithKey(key []byte) Option {
+	return func(r *Redactor) {
+		r.key = key
+	}
+}
+
+// WithStrippedBanners removes banner fields at any depth of results
+func WithStrippedBanners() Option {
+	return func(r *Redactor) {
+		for _, name := range BannerFields {
+			r.stripped[name] = true
+		}
+	}
+}
+
+// WithDroppedFields removes fields by their dot-separated paths, e.g. mac or meta.owner
+func WithDroppedFields(paths []string) Option {
+	return func(r *Redactor) {
+		for _, path := range paths {
+			if path = strings.TrimSpace(path); len(path) > 0 {
+				r.dropped = append(r.dropped, strings.Split(path, "."))
+			}
+		}
+	}
+}
+
+func NewRedactor(opts ...Option) (*Redactor, error) {
+	r := &Redactor{stripped: make(map[string]bool)}
+	for _, o := range opts {
+		o(r)
+	}
+	switch r.ipMode {
+	case "", IPTruncate:
+	case IPHash:
+		if len(r.key) == 0 {
+			r.key = make([]byte, keySize)
+			if _, err := rand.Read(r.key); err != nil {
+				return nil, err
+			}
+		}
+	default:
+		return nil, ErrIPMode
+	}
+	return r, nil
+}
+
+// Result is the redacted scan result, its plain text form and id are the original ones
+// with redacted IPs and without string values of removed fields
+type Result struct {
+	ScanType string
+	id       string
+	text     string
+	data     json.RawMessage
+}
+
+// Assert that redact.Result conforms to the scan.Result interface
+var _ scan.Result = (*Result)(nil)
+
+func (r *Result) String() string {
+	return r.text
+}
+
+func (r *Result) ID() string {
+	return r.id
+}
+
+func (r *Result) MarshalJSON() ([]byte, error) {
+	return r.data, nil
+}
+
+// Redact returns the redacted result, scanType is kept for writers that split results by their types
+func (r *Redactor) Redact(result scan.Result, scanType string) (*Result, error) {
+	data, err := result.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	value, err := decode(data)
+	if err != nil {
+		return nil, err
+	}
+	s := &redaction{Redactor: r, ips: make(map[string]string)}
+	for _, path := range r.dropped {
+		s.drop(value, path)
+	}
+	value = s.redact(value)
+	if data, err = json.Marshal(value); err != nil {
+		return nil, err
+	}
+	redacted, ok := s.redactResult(result)
+	if !ok {
+		return nil, errInvalidResult
+	}
+	return &Result{
+		ScanType: scanType,
+		id:       redacted.ID(),
+		text:     redacted.String(),
+		data:     data,
+	}, nil
+}
+
+// redaction keeps replacements of IPs of one result, so that the same IP is replaced once
+type redaction struct {
+	*Redactor
+	// ips are replacements of original IPs
+	ips map[string]string
+}
+
+func (s *redaction) drop(value interface{}, path []string) {
+	obj, ok := value.(*object)
+	if !ok {
+		return
+	}
+	if len(path) == 1 {
+		s.remove(obj, path[0])
+		return
+	}
+	if child, ok := obj.values[path[0]]; ok {
+		s.drop(child, path[1:])
+	}
+}
+
+func (s *redaction) remove(obj *object, key string) {
+	if _, ok := obj.values[key]; !ok {
+		return
+	}
+	delete(obj.values, key)
+	for i, k := range obj.keys {
+		if k == key {
+			obj.keys = append(obj.keys[:i], obj.keys[i+1:]...)
+			break
+		}
+	}
+}
+
+func (s *redaction) redact(value interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		return s.redactString(v)
+	case []interface{}:
+		for i, item := range v {
+			v[i] = s.redact(item)
+		}
+	case *object:
+		for _, key := range append([]string(nil), v.keys...) {
+			if s.stripped[key] {
+				s.remove(v, key)
+				continue
+			}
+			v.values[key] = s.redact(v.values[key])
+		}
+	}
+	return value
+}
+
+// redactString replaces IPs, IPs with ports and IP addresses embedded in the string
+func (s *redaction) redactString(v string) string {
+	if len(s.ipMode) == 0 || len(v) == 0 {
+		return v
+	}
+	if ip := net.ParseIP(v); ip != nil {
+		return s.replaceIP(v, ip)
+	}
+	if host, port, err := net.SplitHostPort(v); err == nil {
+		if ip := net.ParseIP(host); ip != nil {
+			return net.JoinHostPort(s.replaceIP(host, ip), port)
+		}
+	}
+	return ipRegexp.ReplaceAllStringFunc(v, func(match string) string {
+		if ip := net.ParseIP(match); ip != nil && !ip.IsUnspecified() {
+			return s.replaceIP(match, ip)
+		}
+		// IPv6 addresses followed by colons, e.g. in "fe80::1: link up"
+		if trimmed := strings.TrimRight(match, ":"); strings.Contains(trimmed, ":") {
+			if ip := net.ParseIP(trimmed); ip != nil && !ip.IsUnspecified() {
+				return s.replaceIP(trimmed, ip) + match[len(trimmed):]
+			}
+		}
+		return match
+	})
+}
+
+func (s *redaction) replaceIP(v string, ip net.IP) string {
+	if replacement, ok := s.ips[v]; ok {
+		return replacement
+	}
+	var replacement string
+	if s.ipMode == IPHash {
+		replacement = s.hashIP(ip)
+	} else {
+		replacement = truncateIP(ip)
+	}
+	s.ips[v] = replacement
+	return replacement
+}
+
+func (s *redaction) hashIP(ip net.IP) string {
+	if ip4 := ip.To4(); ip4 != nil {
+		ip = ip4
+	}
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write(ip)
+	return hex.EncodeToString(mac.Sum(nil)[:hashSize])
+}
+
+func truncateIP(ip net.IP) string {
+	if ip4 := ip.To4(); ip4 != nil {
+		return ip4.Mask(net.CIDRMask(ipv4PrefixLen, 8*net.IPv4len)).String()
+	}
+	return ip.Mask(net.CIDRMask(ipv6PrefixLen, 8*net.IPv6len)).String()
+}
+
+// redactResult returns the copy of the result with redacted string values at any depth,
+// stripped and dropped fields are zeroed, so that plain text forms of the copy are built by the result itself
+func (s *redaction) redactResult(result scan.Result) (scan.Result, bool) {
+	copied, ok := s.redactValue(reflect.ValueOf(result), s.dropped).Interface().(scan.Result)
+	return copied, ok
+}
+
+// redactValue returns the redacted copy of the value, dropped are paths of fields relative to the value
+func (s *redaction) redactValue(v reflect.Value, dropped [][]string) reflect.Value {
+	switch v.Kind() {
+	case reflect.String:
+		return reflect.ValueOf(s.redactString(v.String())).Convert(v.Type())
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v
+		}
+		copied := reflect.New(v.Type().Elem())
+		copied.Elem().Set(s.redactValue(v.Elem(), dropped))
+		return copied
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		copied := reflect.New(v.Type()).Elem()
+		copied.Set(s.redactValue(v.Elem(), dropped))
+		return copied
+	case reflect.Slice:
+		if v.IsNil() || v.Type().Elem().Kind() == reflect.Uint8 {
+			return v
+		}
+		copied := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			copied.Index(i).Set(s.redactValue(v.Index(i), nil))
+		}
+		return copied
+	case reflect.Map:
+		if v.IsNil() || v.Type().Key().Kind() != reflect.String {
+			return v
+		}
+		copied := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			key := iter.Key().String()
+			if s.isRemoved(key, dropped) {
+				continue
+			}
+			copied.SetMapIndex(iter.Key(), s.redactValue(iter.Value(), childPaths(key, dropped)))
+		}
+		return copied
+	case reflect.Struct:
+		copied := reflect.New(v.Type()).Elem()
+		copied.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			if len(field.PkgPath) > 0 {
+				continue
+			}
+			// fields of embedded values are written at the level of the struct
+			if field.Anonymous {
+				copied.Field(i).Set(s.redactValue(v.Field(i), dropped))
+				continue
+			}
+			key := fieldKey(field)
+			if s.isRemoved(key, dropped) {
+				copied.Field(i).Set(reflect.Zero(field.Type))
+				continue
+			}
+			copied.Field(i).Set(s.redactValue(v.Field(i), childPaths(key, dropped)))
+		}
+		return copied
+	}
+	return v
+}
+
+func (s *redaction) isRemoved(key string, dropped [][]string) bool {
+	for name := range s.stripped {
+		if sameKey(name, key) {
+			return true
+		}
+	}
+	for _, path := range dropped {
+		if len(path) == 1 && sameKey(path[0], key) {
+			return true
+		}
+	}
+	return false
+}
+
+// childPaths returns dropped paths relative to the field with the key
+func childPaths(key string, dropped [][]string) (paths [][]string) {
+	for _, path := range dropped {
+		if len(path) > 1 && sameKey(path[0], key) {
+			paths = append(paths, path[1:])
+		}
+	}
+	return
+}
+
+// fieldKey returns the JSON name of the struct field
+func fieldKey(field reflect.StructField) string {
+	if name := strings.Split(field.Tag.Get("json"), ",")[0]; len(name) > 0 && name != "-" {
+		return name
+	}
+	return field.Name
+}
+
+// sameKey compares JSON names with names of fields without JSON tags, e.g. window_start and WindowStart
+func sameKey(name, key string) bool {
+	return strings.EqualFold(strings.ReplaceAll(name, "_", ""), strings.ReplaceAll(key, "_", ""))
+}
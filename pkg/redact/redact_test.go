@@ -0,0 +1,155 @@
+package redact
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+	"github.com/v-byte-cpu/sx/pkg/scan/detect"
+	"github.com/v-byte-cpu/sx/pkg/scan/http"
+)
+
+func newDetectResult() *detect.ScanResult {
+	return &detect.ScanResult{
+		ScanType: detect.ScanType,
+		IP:       "192.168.0.3",
+		Port:     22,
+		Service:  detect.ServiceSSH,
+		Banner:   "SSH-2.0-OpenSSH_8.4p1 Debian-5",
+	}
+}
+
+func TestRedactorTruncate(t *testing.T) {
+	t.Parallel()
+
+	r, err := NewRedactor(WithIPMode(IPTruncate))
+	require.NoError(t, err)
+	result, err := r.Redact(newDetectResult(), detect.ScanType)
+	require.NoError(t, err)
+
+	require.Equal(t, detect.ScanType, result.ScanType)
+	data, err := result.MarshalJSON()
+	require.NoError(t, err)
+	require.Equal(t, `{"scan":"detect","ip":"192.168.0.0","port":22,"service":"ssh","tls":false,`+
+		`"banner":"SSH-2.0-OpenSSH_8.4p1 Debian-5"}`, string(data))
+	require.Equal(t, "192.168.0.0:22", result.ID())
+	require.Equal(t, `192.168.0.0          22    ssh     "SSH-2.0-OpenSSH_8.4p1 Debian-5"`, result.String())
+}
+
+func TestRedactorHash(t *testing.T) {
+	t.Parallel()
+
+	r, err := NewRedactor(WithIPMode(IPHash), WithKey([]byte("secret")))
+	require.NoError(t, err)
+	result, err := r.Redact(&http.ScanResult{
+		ScanType: http.ScanType,
+		Proto:    "http",
+		Host:     "192.168.0.3:8080",
+		Status:   200,
+		URL:      "http://192.168.0.3:8080/login",
+		Server:   "nginx/1.18.0",
+	}, http.ScanType)
+	require.NoError(t, err)
+
+	hash := (&redaction{Redactor: r}).hashIP(net.IPv4(192, 168, 0, 3))
+	require.Len(t, hash, 2*hashSize)
+	data, err := result.MarshalJSON()
+	require.NoError(t, err)
+	require.Equal(t, `{"scan":"http","proto":"http","host":"`+hash+`:8080","status":200,`+
+		`"url":"http://`+hash+`:8080/login","server":"nginx/1.18.0"}`, string(data))
+	require.NotContains(t, result.String(), "192.168.0.3")
+	require.NotContains(t, result.ID(), "192.168.0.3")
+
+	// the same key gives the same hash
+	r2, err := NewRedactor(WithIPMode(IPHash), WithKey([]byte("secret")))
+	require.NoError(t, err)
+	require.Equal(t, hash, (&redaction{Redactor: r2}).hashIP(net.ParseIP("192.168.0.3")))
+	// the random key is used by default
+	r3, err := NewRedactor(WithIPMode(IPHash))
+	require.NoError(t, err)
+	require.Len(t, r3.key, keySize)
+	require.NotEqual(t, hash, (&redaction{Redactor: r3}).hashIP(net.IPv4(192, 168, 0, 3)))
+}
+
+func TestRedactorStripAndDrop(t *testing.T) {
+	t.Parallel()
+
+	r, err := NewRedactor(WithStrippedBanners(), WithDroppedFields([]string{"service", " meta.owner ", "unknown.field", ""}))
+	require.NoError(t, err)
+	result, err := r.Redact(&scan.MetaResult{
+		Result: newDetectResult(),
+		Meta:   map[string]interface{}{"owner": "alice", "env": "prod"},
+	}, detect.ScanType)
+	require.NoError(t, err)
+
+	data, err := result.MarshalJSON()
+	require.NoError(t, err)
+	require.Equal(t, `{"scan":"detect","ip":"192.168.0.3","port":22,"tls":false,"meta":{"env":"prod"}}`, string(data))
+	require.Equal(t, "192.168.0.3          22            env=prod", result.String())
+}
+
+func TestRedactorPlainTextFromResult(t *testing.T) {
+	t.Parallel()
+
+	r, err := NewRedactor(WithIPMode(IPTruncate), WithDroppedFields([]string{"service"}))
+	require.NoError(t, err)
+	result, err := r.Redact(&detect.ScanResult{
+		ScanType: detect.ScanType,
+		IP:       "2001:db8:1:2::3",
+		Port:     22,
+		Service:  detect.ServiceSSH,
+		Banner:   "SSH-2.0-OpenSSH_8.4p1 ssh from 2001:db8:1:2::3 at 10:00:00 via aa:bb:cc:dd:ee:ff",
+	}, detect.ScanType)
+	require.NoError(t, err)
+
+	data, err := result.MarshalJSON()
+	require.NoError(t, err)
+	require.Equal(t, `{"scan":"detect","ip":"2001:db8:1::","port":22,"tls":false,`+
+		`"banner":"SSH-2.0-OpenSSH_8.4p1 ssh from 2001:db8:1:: at 10:00:00 via aa:bb:cc:dd:ee:ff"}`, string(data))
+	require.Equal(t, "2001:db8:1:::22", result.ID())
+	// the dropped service value is kept in other fields
+	require.Equal(t, `2001:db8:1::         22            "SSH-2.0-OpenSSH_8.4p1 ssh from 2001:db8:1:: at 10:00:00 via aa:bb:cc:dd:ee:ff"`,
+		result.String())
+}
+
+func TestRedactorIPv6(t *testing.T) {
+	t.Parallel()
+
+	r, err := NewRedactor(WithIPMode(IPTruncate))
+	require.NoError(t, err)
+	result, err := r.Redact(&http.ScanResult{
+		ScanType: http.ScanType,
+		Proto:    "https",
+		Host:     "[2001:db8:1:2::3]:443",
+		Status:   200,
+		URL:      "https://[2001:db8:1:2::3]/",
+		Title:    "Router 10.0.0.1",
+	}, http.ScanType)
+	require.NoError(t, err)
+
+	data, err := result.MarshalJSON()
+	require.NoError(t, err)
+	require.Equal(t, `{"scan":"http","proto":"https","host":"[2001:db8:1::]:443","status":200,`+
+		`"url":"https://[2001:db8:1::]/","title":"Router 10.0.0.0"}`, string(data))
+}
+
+func TestRedactorNotObject(t *testing.T) {
+	t.Parallel()
+
+	r, err := NewRedactor(WithIPMode(IPTruncate))
+	require.NoError(t, err)
+	result, err := r.Redact(scan.MultiResult{newDetectResult(), newDetectResult()}, detect.ScanType)
+	require.NoError(t, err)
+	data, err := result.MarshalJSON()
+	require.NoError(t, err)
+	require.Contains(t, string(data), `"ip":"192.168.0.0"`)
+	require.NotContains(t, string(data), "192.168.0.3")
+}
+
+func TestNewRedactorError(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewRedactor(WithIPMode("mask"))
+	require.ErrorIs(t, err, ErrIPMode)
+}
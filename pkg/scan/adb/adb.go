@@ -0,0 +1,234 @@
+package adb
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+const (
+	ScanType = "adb"
+
+	defaultDialTimeout = 2 * time.Second
+	defaultDataTimeout = 2 * time.Second
+
+	// getpropCommand reads the Android version and the SDK level, one per line
+	getpropCommand = "shell:getprop ro.build.version.release;getprop ro.build.version.sdk\x00"
+	localID        = 1
+	// maxShellOutput limits the output of the shell service, the output of getprop is a few bytes
+	maxShellOutput = 4096
+)
+
+// states of ADB daemons
+const (
+	// StateDevice means the daemon accepted the connection without authentication
+	StateDevice = "device"
+	// StateAuth means the daemon requires the RSA key authorized on the device
+	StateAuth = "auth"
+	// StateTLS means the daemon requires TLS of wireless debugging
+	StateTLS = "tls"
+)
+
+var errNotADB = errors.New("not an ADB daemon")
+
+type ScanResult struct {
+	ScanType string `json:"scan"`
+	IP       string `json:"ip"`
+	Port     uint16 `json:"port"`
+	State    string `json:"state"`
+	// System is the mode of the device from the banner: device, recovery, sideload, etc
+	System string `json:"system,omitempty"`
+	// Name, Model and Device are ro.product.* properties from the banner
+	Name           string   `json:"name,omitempty"`
+	Model          string   `json:"model,omitempty"`
+	Device         string   `json:"device,omitempty"`
+	Features       []string `json:"features,omitempty"`
+	AndroidVersion string   `json:"android_version,omitempty"`
+	SDK            string   `json:"sdk,omitempty"`
+}
+
+func (r *ScanResult) String() string {
+	result := fmt.Sprintf("%-20s %-5d %s", r.IP, r.Port, r.State)
+	if len(r.Model) > 0 {
+		result += fmt.Sprintf(" model %q", r.Model)
+	}
+	if len(r.AndroidVersion) > 0 {
+		result += " android " + r.AndroidVersion
+	}
+	return result
+}
+
+func (r *ScanResult) ID() string {
+	return fmt.Sprintf("%s:%d", r.IP, r.Port)
+}
+
+func (r *ScanResult) MarshalJSON() ([]byte, error) {
+	// Type definition for the recursive call
+	type JScanResult ScanResult
+	// This works because JScanResult doesn't have a MarshalJSON function associated with it
+	return json.Marshal(JScanResult(*r))
+}
+
+// Scanner performs the CNXN handshake of the ADB transport protocol and reports the state of the daemon
+// with device properties from its banner. With getprop the Android version is read through the shell service
+// of daemons that accepted the connection without authentication.
+type Scanner struct {
+	dialer      *net.Dialer
+	dataTimeout time.Duration
+	getprop     bool
+}
+
+// Assert that adb.Scanner conforms to the scan.Scanner interface
+var _ scan.Scanner = (*Scanner)(nil)
+
+type ScannerOption func(*Scanner)
+
+func WithDialTimeout(timeout time.Duration) ScannerOption {
+	return func(s *Scanner) {
+		s.dialer.Timeout = timeout
+	}
+}
+
+func WithDataTimeout(timeout time.Duration) ScannerOption {
+	return func(s *Scanner) {
+		s.dataTimeout = timeout
+	}
+}
+
+// WithGetprop enables reading the Android version with the getprop command of the shell service
+func WithGetprop(getprop bool) ScannerOption {
+	return func(s *Scanner) {
+		s.getprop = getprop
+	}
+}
+
+func NewScanner(opts ...ScannerOption) *Scanner {
+	s := &Scanner{
+		dialer: &net.Dialer{
+			Timeout: defaultDialTimeout,
+		},
+		dataTimeout: defaultDataTimeout,
+	}
+	for _, o := range opts {
+		o(s)
+	}
+	return s
+}
+
+func (s *Scanner) Scan(ctx context.Context, r *scan.Request) (scan.Result, error) {
+	result, err := s.scanTarget(ctx, r)
+	return result, scan.WrapError(err)
+}
+
+func (s *Scanner) scanTarget(ctx context.Context, r *scan.Request) (result scan.Result, err error) {
+	addr := net.JoinHostPort(r.DstIP.String(), strconv.Itoa(int(r.DstPort)))
+	var conn net.Conn
+	if conn, err = s.dialer.DialContext(ctx, "tcp", addr); err != nil {
+		return
+	}
+	defer conn.Close()
+	if err = conn.SetDeadline(time.Now().Add(s.dataTimeout)); err != nil {
+		return
+	}
+
+	if _, err = conn.Write(connectMessage().marshal()); err != nil {
+		return
+	}
+	reader := bufio.NewReader(conn)
+	var msg *message
+	if msg, err = readMessage(reader); err != nil {
+		return
+	}
+	res := &ScanResult{
+		ScanType: ScanType,
+		IP:       r.DstIP.String(),
+		Port:     r.DstPort,
+	}
+	switch msg.command {
+	case cmdAUTH:
+		res.State = StateAuth
+		return res, nil
+	case cmdSTLS:
+		res.State = StateTLS
+		return res, nil
+	case cmdCNXN:
+		res.State = StateDevice
+	default:
+		return nil, errNotADB
+	}
+	parseBanner(res, string(msg.payload))
+
+	if s.getprop {
+		// the device is reported even if the shell service is not available
+		if output, err := s.shell(conn, reader); err == nil {
+			lines := strings.Split(strings.TrimSpace(output), "\n")
+			res.AndroidVersion = strings.TrimSpace(lines[0])
+			if len(lines) > 1 {
+				res.SDK = strings.TrimSpace(lines[1])
+			}
+		}
+	}
+	return res, nil
+}
+
+// shell runs the getprop command with the shell service and returns its output
+func (s *Scanner) shell(conn net.Conn, reader *bufio.Reader) (string, error) {
+	open := &message{command: cmdOPEN, arg0: localID, payload: []byte(getpropCommand)}
+	if _, err := conn.Write(open.marshal()); err != nil {
+		return "", err
+	}
+	var output strings.Builder
+	for {
+		msg, err := readMessage(reader)
+		if err != nil {
+			return output.String(), err
+		}
+		if msg.arg1 != localID {
+			continue
+		}
+		switch msg.command {
+		case cmdWRTE:
+			output.Write(msg.payload)
+			if output.Len() > maxShellOutput {
+				return output.String(), nil
+			}
+			// the daemon sends the next data after the acknowledgement
+			okay := &message{command: cmdOKAY, arg0: localID, arg1: msg.arg0}
+			if _, err = conn.Write(okay.marshal()); err != nil {
+				return output.String(), err
+			}
+		case cmdCLSE:
+			return output.String(), nil
+		}
+	}
+}
+
+// parseBanner parses the banner of the CNXN message, e.g.
+// device::ro.product.name=sdk_gphone_x86;ro.product.model=Android SDK built for x86;features=shell_v2,cmd
+func parseBanner(res *ScanResult, banner string) {
+	system, props, _ := strings.Cut(strings.TrimRight(banner, "\x00"), "::")
+	res.System = system
+	for _, prop := range strings.Split(props, ";") {
+		key, value, _ := strings.Cut(prop, "=")
+		switch key {
+		case "ro.product.name":
+			res.Name = value
+		case "ro.product.model":
+			res.Model = value
+		case "ro.product.device":
+			res.Device = value
+		case "features":
+			if len(value) > 0 {
+				res.Features = strings.Split(value, ",")
+			}
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package adb
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+const (
+	testBanner = "device::ro.product.name=sdk_gphone_x86;ro.product.model=Android SDK built for x86;" +
+		"ro.product.device=generic_x86;features=shell_v2,cmd\x00"
+	remoteID = 42
+)
+
+type testServer struct {
+	// reply is the command of the answer to CNXN
+	reply uint32
+	// shell is set if the shell service is available, the output is sent in two messages
+	shell bool
+}
+
+func serveADB(t *testing.T, server *testServer) *scan.Request {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { l.Close() })
+
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go server.handle(conn)
+		}
+	}()
+	addr := l.Addr().(*net.TCPAddr)
+	return &scan.Request{DstIP: addr.IP, DstPort: uint16(addr.Port)}
+}
+
+func (server *testServer) handle(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	msg, err := readMessage(reader)
+	if err != nil || msg.command != cmdCNXN {
+		return
+	}
+	switch server.reply {
+	case cmdAUTH:
+		_, _ = conn.Write((&message{command: cmdAUTH, arg0: 1, payload: make([]byte, 20)}).marshal())
+		return
+	case cmdCNXN:
+		_, _ = conn.Write((&message{command: cmdCNXN, arg0: protocolVersion, arg1: maxPayloadSize,
+			payload: []byte(testBanner)}).marshal())
+	default:
+		_, _ = conn.Write((&message{command: server.reply}).marshal())
+		return
+	}
+
+	if msg, err = readMessage(reader); err != nil || msg.command != cmdOPEN {
+		return
+	}
+	if !server.shell || string(msg.payload) != getpropCommand {
+		_, _ = conn.Write((&message{command: cmdCLSE, arg1: msg.arg0}).marshal())
+		return
+	}
+	local := msg.arg0
+	_, _ = conn.Write((&message{command: cmdOKAY, arg0: remoteID, arg1: local}).marshal())
+	for _, output := range []string{"12\n", "31\n"} {
+		_, _ = conn.Write((&message{command: cmdWRTE, arg0: remoteID, arg1: local, payload: []byte(output)}).marshal())
+		if msg, err = readMessage(reader); err != nil || msg.command != cmdOKAY || msg.arg1 != remoteID {
+			return
+		}
+	}
+	_, _ = conn.Write((&message{command: cmdCLSE, arg0: remoteID, arg1: local}).marshal())
+}
+
+func TestScan(t *testing.T) {
+	t.Parallel()
+	device := ScanResult{State: StateDevice, System: "device", Name: "sdk_gphone_x86",
+		Model: "Android SDK built for x86", Device: "generic_x86", Features: []string{"shell_v2", "cmd"}}
+	deviceWithVersion := device
+	deviceWithVersion.AndroidVersion = "12"
+	deviceWithVersion.SDK = "31"
+
+	tests := []struct {
+		name     string
+		server   *testServer
+		getprop  bool
+		expected ScanResult
+	}{
+		{
+			name:     "Device",
+			server:   &testServer{reply: cmdCNXN, shell: true},
+			expected: device,
+		},
+		{
+			name:     "Getprop",
+			server:   &testServer{reply: cmdCNXN, shell: true},
+			getprop:  true,
+			expected: deviceWithVersion,
+		},
+		{
+			name:     "GetpropWithoutShell",
+			server:   &testServer{reply: cmdCNXN},
+			getprop:  true,
+			expected: device,
+		},
+		{
+			name:     "Auth",
+			server:   &testServer{reply: cmdAUTH},
+			expected: ScanResult{State: StateAuth},
+		},
+		{
+			name:     "TLS",
+			server:   &testServer{reply: cmdSTLS},
+			expected: ScanResult{State: StateTLS},
+		},
+	}
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			req := serveADB(t, tt.server)
+			result, err := NewScanner(WithDataTimeout(time.Second), WithGetprop(tt.getprop)).
+				Scan(context.Background(), req)
+			require.NoError(t, err)
+
+			expected := tt.expected
+			expected.ScanType = ScanType
+			expected.IP = req.DstIP.String()
+			expected.Port = req.DstPort
+			require.Equal(t, &expected, result)
+		})
+	}
+}
+
+func TestScanNotADB(t *testing.T) {
+	t.Parallel()
+	req := serveADB(t, &testServer{reply: cmdOKAY})
+	_, err := NewScanner().Scan(context.Background(), req)
+	require.ErrorIs(t, err, errNotADB)
+}
+
+func TestScanResultString(t *testing.T) {
+	t.Parallel()
+	result := &ScanResult{ScanType: ScanType, IP: "192.168.0.1", Port: 5555, State: StateDevice,
+		Model: "Pixel 5", AndroidVersion: "12"}
+	require.Equal(t, `192.168.0.1          5555  device model "Pixel 5" android 12`, result.String())
+	require.Equal(t, "192.168.0.1:5555", result.ID())
+}
@@ -0,0 +1,78 @@
+package adb
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+// commands of the ADB transport protocol, see protocol.txt of the adb sources
+const (
+	cmdCNXN = 0x4e584e43
+	cmdAUTH = 0x48545541
+	cmdSTLS = 0x534c5453
+	cmdOPEN = 0x4e45504f
+	cmdOKAY = 0x59414b4f
+	cmdWRTE = 0x45545257
+	cmdCLSE = 0x45534c43
+
+	// protocolVersion is the version of devices without checksums of payloads
+	protocolVersion = 0x01000001
+	maxPayloadSize  = 256 * 1024
+	headerSize      = 24
+)
+
+var errMessage = scan.NewError(scan.ErrParse, errors.New("invalid ADB message"))
+
+type message struct {
+	command uint32
+	arg0    uint32
+	arg1    uint32
+	payload []byte
+}
+
+// marshal returns the message with the checksum of the payload, devices of protocol versions
+// before 0x01000001 reject messages without it
+func (m *message) marshal() []byte {
+	result := make([]byte, headerSize, headerSize+len(m.payload))
+	binary.LittleEndian.PutUint32(result, m.command)
+	binary.LittleEndian.PutUint32(result[4:], m.arg0)
+	binary.LittleEndian.PutUint32(result[8:], m.arg1)
+	binary.LittleEndian.PutUint32(result[12:], uint32(len(m.payload)))
+	var checksum uint32
+	for _, b := range m.payload {
+		checksum += uint32(b)
+	}
+	binary.LittleEndian.PutUint32(result[16:], checksum)
+	binary.LittleEndian.PutUint32(result[20:], m.command^0xffffffff)
+	return append(result, m.payload...)
+}
+
+// readMessage reads the message, checksums of payloads are not verified since new devices don't set them
+func readMessage(r io.Reader) (*message, error) {
+	header := make([]byte, headerSize)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, err
+	}
+	m := &message{
+		command: binary.LittleEndian.Uint32(header),
+		arg0:    binary.LittleEndian.Uint32(header[4:]),
+		arg1:    binary.LittleEndian.Uint32(header[8:]),
+	}
+	length := binary.LittleEndian.Uint32(header[12:])
+	if binary.LittleEndian.Uint32(header[20:]) != m.command^0xffffffff || length > maxPayloadSize {
+		return nil, errMessage
+	}
+	m.payload = make([]byte, length)
+	if _, err := io.ReadFull(r, m.payload); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// connectMessage returns the CNXN message of the host without features
+func connectMessage() *message {
+	return &message{command: cmdCNXN, arg0: protocolVersion, arg1: maxPayloadSize, payload: []byte("host::\x00")}
+}
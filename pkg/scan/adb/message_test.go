@@ -0,0 +1,57 @@
+package adb
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMessageMarshal(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, []byte{
+		0x43, 0x4e, 0x58, 0x4e, 0x01, 0x00, 0x00, 0x01, 0x00, 0x00, 0x04, 0x00,
+		0x07, 0x00, 0x00, 0x00, 0x32, 0x02, 0x00, 0x00, 0xbc, 0xb1, 0xa7, 0xb1,
+		'h', 'o', 's', 't', ':', ':', 0x00,
+	}, connectMessage().marshal())
+}
+
+func TestReadMessage(t *testing.T) {
+	t.Parallel()
+
+	msg := &message{command: cmdWRTE, arg0: 7, arg1: localID, payload: []byte("12\n")}
+	result, err := readMessage(bytes.NewReader(msg.marshal()))
+	require.NoError(t, err)
+	require.Equal(t, msg, result)
+}
+
+func TestReadMessageInvalid(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{
+			name: "InvalidMagic",
+			data: []byte("HTTP/1.1 400 Bad Request\r\n\r\n"),
+		},
+		{
+			name: "TooLarge",
+			data: (&message{command: cmdCNXN}).marshal(),
+		},
+	}
+	// the payload length is larger than the maximum payload size
+	tests[1].data[14] = 0x10
+
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := readMessage(bytes.NewReader(tt.data))
+			require.ErrorIs(t, err, errMessage)
+		})
+	}
+}
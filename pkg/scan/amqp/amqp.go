@@ -0,0 +1,230 @@
+package amqp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+const (
+	ScanType = "amqp"
+
+	defaultDialTimeout    = 2 * time.Second
+	defaultDataTimeout    = 2 * time.Second
+	defaultManagementPort = 15672
+
+	protocolVersion = "0-9-1"
+	guestUser       = "guest"
+	guestPassword   = "guest"
+)
+
+type ScanResult struct {
+	ScanType string `json:"scan"`
+	IP       string `json:"ip"`
+	Port     uint16 `json:"port"`
+	// Protocol is the AMQP protocol version of the server, e.g. 0-9-1 or 1-0-0 for AMQP 1.0 servers
+	Protocol string `json:"protocol"`
+	// Product, Version, Platform and ClusterName are server properties of the Connection.Start method
+	Product     string `json:"product,omitempty"`
+	Version     string `json:"version,omitempty"`
+	Platform    string `json:"platform,omitempty"`
+	ClusterName string `json:"cluster_name,omitempty"`
+	// Mechanisms are SASL mechanisms offered by the server, e.g. PLAIN AMQPLAIN
+	Mechanisms []string `json:"mechanisms,omitempty"`
+	// Management is set if the RabbitMQ management API responded on the management port,
+	// ManagementGuest is set if it accepted the default guest/guest credentials
+	Management      bool `json:"management,omitempty"`
+	ManagementGuest bool `json:"management_guest,omitempty"`
+}
+
+func (r *ScanResult) String() string {
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "%-20s %-5d AMQP %s", r.IP, r.Port, r.Protocol)
+	if len(r.Product) > 0 {
+		fmt.Fprintf(&buf, " %s", r.Product)
+	}
+	if len(r.Version) > 0 {
+		fmt.Fprintf(&buf, " %s", r.Version)
+	}
+	if len(r.Mechanisms) > 0 {
+		fmt.Fprintf(&buf, " mechanisms %s", strings.Join(r.Mechanisms, ","))
+	}
+	if len(r.ClusterName) > 0 {
+		fmt.Fprintf(&buf, " cluster %q", r.ClusterName)
+	}
+	if r.ManagementGuest {
+		buf.WriteString(" management guest")
+	} else if r.Management {
+		buf.WriteString(" management")
+	}
+	return buf.String()
+}
+
+func (r *ScanResult) ID() string {
+	return fmt.Sprintf("%s:%d", r.IP, r.Port)
+}
+
+func (r *ScanResult) MarshalJSON() ([]byte, error) {
+	// Type definition for the recursive call
+	type JScanResult ScanResult
+	// This works because JScanResult doesn't have a MarshalJSON function associated with it
+	return json.Marshal(JScanResult(*r))
+}
+
+// Scanner sends the AMQP 0-9-1 protocol header and reads the Connection.Start method,
+// the RabbitMQ management API is checked optionally
+type Scanner struct {
+	dialer         *net.Dialer
+	dataTimeout    time.Duration
+	management     bool
+	managementPort uint16
+	client         *http.Client
+}
+
+// Assert that amqp.Scanner conforms to the scan.Scanner interface
+var _ scan.Scanner = (*Scanner)(nil)
+
+type ScannerOption func(*Scanner)
+
+func WithDialTimeout(timeout time.Duration) ScannerOption {
+	return func(s *Scanner) {
+		s.dialer.Timeout = timeout
+	}
+}
+
+func WithDataTimeout(timeout time.Duration) ScannerOption {
+	return func(s *Scanner) {
+		s.dataTimeout = timeout
+	}
+}
+
+// WithManagement enables checking of the RabbitMQ management API with the default guest/guest credentials
+func WithManagement() ScannerOption {
+	return func(s *Scanner) {
+		s.management = true
+	}
+}
+
+func WithManagementPort(port uint16) ScannerOption {
+	return func(s *Scanner) {
+		s.managementPort = port
+	}
+}
+
+func NewScanner(opts ...ScannerOption) *Scanner {
+	s := &Scanner{
+		dialer: &net.Dialer{
+			Timeout: defaultDialTimeout,
+		},
+		dataTimeout:    defaultDataTimeout,
+		managementPort: defaultManagementPort,
+	}
+	for _, o := range opts {
+		o(s)
+	}
+	s.client = &http.Client{
+		Transport: &http.Transport{
+			DialContext:       s.dialer.DialContext,
+			MaxConnsPerHost:   1,
+			DisableKeepAlives: true,
+		},
+	}
+	return s
+}
+
+func (s *Scanner) Scan(ctx context.Context, r *scan.Request) (scan.Result, error) {
+	result, err := s.scanTarget(ctx, r)
+	return result, scan.WrapError(err)
+}
+
+func (s *Scanner) scanTarget(ctx context.Context, r *scan.Request) (scan.Result, error) {
+	result, err := s.scan(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	if s.management {
+		result.Management, result.ManagementGuest = s.checkManagement(ctx, r.DstIP)
+	}
+	return result, nil
+}
+
+func (s *Scanner) scan(ctx context.Context, r *scan.Request) (*ScanResult, error) {
+	addr := net.JoinHostPort(r.DstIP.String(), strconv.Itoa(int(r.DstPort)))
+	conn, err := s.dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	if err = conn.SetDeadline(time.Now().Add(s.dataTimeout)); err != nil {
+		return nil, err
+	}
+	if _, err = conn.Write(protocolHeader); err != nil {
+		return nil, err
+	}
+
+	result := &ScanResult{
+		ScanType: ScanType,
+		IP:       r.DstIP.String(),
+		Port:     r.DstPort,
+		Protocol: protocolVersion,
+	}
+	start, err := readConnectionStart(conn)
+	var protoErr *protocolError
+	// servers of other protocol versions, e.g. AMQP 1.0, reply with their protocol header
+	if errors.As(err, &protoErr) {
+		result.Protocol = protoErr.version
+		return result, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	result.Product = stringProperty(start.properties, "product")
+	result.Version = stringProperty(start.properties, "version")
+	result.Platform = stringProperty(start.properties, "platform")
+	result.ClusterName = stringProperty(start.properties, "cluster_name")
+	result.Mechanisms = start.mechanisms
+	return result, nil
+}
+
+func stringProperty(properties map[string]interface{}, name string) string {
+	v, _ := properties[name].(string)
+	return v
+}
+
+// checkManagement requests the current user of the management API with guest credentials,
+// the API is detected by the unauthorized response if the credentials are not accepted
+func (s *Scanner) checkManagement(ctx context.Context, ip net.IP) (management, guest bool) {
+	ctx, cancel := context.WithTimeout(ctx, s.dataTimeout)
+	defer cancel()
+	url := fmt.Sprintf("http://%s/api/whoami", net.JoinHostPort(ip.String(), strconv.Itoa(int(s.managementPort))))
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return
+	}
+	req.SetBasicAuth(guestUser, guestPassword)
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+		var user struct {
+			Name string `json:"name"`
+		}
+		if err = json.NewDecoder(resp.Body).Decode(&user); err == nil && user.Name == guestUser {
+			return true, true
+		}
+	case http.StatusUnauthorized:
+		return true, false
+	}
+	return
+}
@@ -0,0 +1,197 @@
+package amqp
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+// startFakeServer starts the server that replies to the protocol header with the response
+func startFakeServer(t *testing.T, response []byte) *scan.Request {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { l.Close() })
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				header := make([]byte, len(protocolHeader))
+				if _, err := io.ReadFull(conn, header); err != nil {
+					return
+				}
+				_, _ = conn.Write(response)
+			}()
+		}
+	}()
+	addr := l.Addr().(*net.TCPAddr)
+	return &scan.Request{DstIP: addr.IP, DstPort: uint16(addr.Port)}
+}
+
+// startFakeManagement starts the management API that accepts the user with the guest password
+func startFakeManagement(t *testing.T, user string) uint16 {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name, password, ok := r.BasicAuth()
+		if r.URL.Path != "/api/whoami" || !ok || name != user || password != guestPassword {
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"error":"not_authorized","reason":"Login failed"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"name":"guest","tags":["administrator"]}`))
+	}))
+	t.Cleanup(srv.Close)
+	return uint16(srv.Listener.Addr().(*net.TCPAddr).Port)
+}
+
+func TestScan(t *testing.T) {
+	t.Parallel()
+	start := connectionStartFrame("AMQPLAIN PLAIN", "product", "RabbitMQ", "version", "3.12.4",
+		"platform", "Erlang/OTP 26.0.2", "cluster_name", "rabbit@mq1")
+	tests := []struct {
+		name     string
+		response []byte
+		expected *ScanResult
+	}{
+		{
+			name:     "RabbitMQ",
+			response: start,
+			expected: &ScanResult{
+				Protocol:    "0-9-1",
+				Product:     "RabbitMQ",
+				Version:     "3.12.4",
+				Platform:    "Erlang/OTP 26.0.2",
+				ClusterName: "rabbit@mq1",
+				Mechanisms:  []string{"AMQPLAIN", "PLAIN"},
+			},
+		},
+		{
+			name:     "AMQP10",
+			response: []byte{'A', 'M', 'Q', 'P', 0, 1, 0, 0},
+			expected: &ScanResult{
+				Protocol: "1-0-0",
+			},
+		},
+	}
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			req := startFakeServer(t, tt.response)
+
+			result, err := NewScanner().Scan(context.Background(), req)
+			require.NoError(t, err)
+			expected := *tt.expected
+			expected.ScanType = ScanType
+			expected.IP = req.DstIP.String()
+			expected.Port = req.DstPort
+			require.Equal(t, &expected, result)
+		})
+	}
+}
+
+func TestScanManagement(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name            string
+		user            string
+		management      bool
+		managementGuest bool
+	}{
+		{
+			name:            "Guest",
+			user:            guestUser,
+			management:      true,
+			managementGuest: true,
+		},
+		{
+			name:       "GuestNotAllowed",
+			user:       "admin",
+			management: true,
+		},
+	}
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			req := startFakeServer(t, connectionStartFrame("PLAIN", "product", "RabbitMQ"))
+			port := startFakeManagement(t, tt.user)
+
+			result, err := NewScanner(WithManagement(), WithManagementPort(port)).Scan(context.Background(), req)
+			require.NoError(t, err)
+			require.Equal(t, tt.management, result.(*ScanResult).Management)
+			require.Equal(t, tt.managementGuest, result.(*ScanResult).ManagementGuest)
+		})
+	}
+}
+
+func TestScanManagementNotAvailable(t *testing.T) {
+	t.Parallel()
+	req := startFakeServer(t, connectionStartFrame("PLAIN", "product", "RabbitMQ"))
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	port := uint16(l.Addr().(*net.TCPAddr).Port)
+	l.Close()
+
+	result, err := NewScanner(WithManagement(), WithManagementPort(port)).Scan(context.Background(), req)
+	require.NoError(t, err)
+	require.False(t, result.(*ScanResult).Management)
+	require.Equal(t, "RabbitMQ", result.(*ScanResult).Product)
+}
+
+func TestScanNotAMQPServer(t *testing.T) {
+	t.Parallel()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			_, _ = conn.Write([]byte("SSH-2.0-OpenSSH_8.4p1\r\n"))
+			conn.Close()
+		}
+	}()
+	addr := l.Addr().(*net.TCPAddr)
+
+	result, err := NewScanner().Scan(context.Background(), &scan.Request{DstIP: addr.IP, DstPort: uint16(addr.Port)})
+	require.Error(t, err)
+	require.Nil(t, result)
+}
+
+func TestScanTimeout(t *testing.T) {
+	t.Parallel()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+	done := make(chan interface{})
+	defer close(done)
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		<-done
+	}()
+	addr := l.Addr().(*net.TCPAddr)
+
+	s := NewScanner(WithDataTimeout(50 * time.Millisecond))
+	result, err := s.Scan(context.Background(), &scan.Request{DstIP: addr.IP, DstPort: uint16(addr.Port)})
+	require.Error(t, err)
+	require.ErrorIs(t, err, scan.ErrTimeout)
+	require.Nil(t, result)
+}
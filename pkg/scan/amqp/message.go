@@ -0,0 +1,231 @@
+package amqp
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+// AMQP 0-9-1 frames, see https://www.rabbitmq.com/resources/specs/amqp0-9-1.pdf
+const (
+	frameMethod = 1
+	frameEnd    = 0xce
+
+	classConnection = 10
+	methodStart     = 10
+
+	frameHeaderSize = 7
+	maxFrameSize    = 128 * 1024
+)
+
+// protocolHeader is the AMQP 0-9-1 protocol header sent by the client before frames
+var protocolHeader = []byte{'A', 'M', 'Q', 'P', 0, 0, 9, 1}
+
+var (
+	errInvalidFrame    = scan.NewError(scan.ErrParse, errors.New("invalid AMQP frame"))
+	errUnexpectedFrame = scan.NewError(scan.ErrParse, errors.New("unexpected AMQP frame"))
+)
+
+// protocolError is returned if the server replied with the header of the protocol it supports
+type protocolError struct {
+	version string
+}
+
+func (e *protocolError) Error() string {
+	return fmt.Sprintf("unsupported AMQP protocol, server protocol %s", e.version)
+}
+
+type connectionStart struct {
+	versionMajor byte
+	versionMinor byte
+	properties   map[string]interface{}
+	mechanisms   []string
+	locales      []string
+}
+
+// readConnectionStart reads the Connection.Start method frame sent by the server in response to the protocol header
+func readConnectionStart(r io.Reader) (*connectionStart, error) {
+	header := make([]byte, frameHeaderSize)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, err
+	}
+	if bytes.HasPrefix(header, protocolHeader[:4]) {
+		// the protocol header is one byte longer than the frame header
+		revision := make([]byte, 1)
+		if _, err := io.ReadFull(r, revision); err != nil {
+			return nil, err
+		}
+		return nil, &protocolError{fmt.Sprintf("%d-%d-%d", header[5], header[6], revision[0])}
+	}
+	if header[0] != frameMethod || binary.BigEndian.Uint16(header[1:3]) != 0 {
+		return nil, fmt.Errorf("%w: type %d", errUnexpectedFrame, header[0])
+	}
+	size := binary.BigEndian.Uint32(header[3:7])
+	if size > maxFrameSize {
+		return nil, fmt.Errorf("%w: size %d", errInvalidFrame, size)
+	}
+	payload := make([]byte, size+1)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return nil, err
+	}
+	if payload[size] != frameEnd {
+		return nil, fmt.Errorf("%w: frame end %#x", errInvalidFrame, payload[size])
+	}
+	return parseConnectionStart(payload[:size])
+}
+
+func parseConnectionStart(payload []byte) (*connectionStart, error) {
+	rd := &reader{data: payload}
+	classID, methodID := rd.uint16(), rd.uint16()
+	if rd.err == nil && (classID != classConnection || methodID != methodStart) {
+		return nil, fmt.Errorf("%w: method %d.%d", errUnexpectedFrame, classID, methodID)
+	}
+	result := &connectionStart{versionMajor: rd.byte(), versionMinor: rd.byte()}
+	result.properties = rd.table()
+	result.mechanisms = strings.Fields(rd.longString())
+	result.locales = strings.Fields(rd.longString())
+	if rd.err != nil {
+		return nil, rd.err
+	}
+	return result, nil
+}
+
+// reader decodes AMQP field values
+type reader struct {
+	data []byte
+	err  error
+}
+
+func (r *reader) next(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if n < 0 || n > len(r.data) {
+		r.err = fmt.Errorf("%w: truncated", errInvalidFrame)
+		return nil
+	}
+	b := r.data[:n]
+	r.data = r.data[n:]
+	return b
+}
+
+func (r *reader) byte() byte {
+	if b := r.next(1); b != nil {
+		return b[0]
+	}
+	return 0
+}
+
+func (r *reader) uint16() uint16 {
+	if b := r.next(2); b != nil {
+		return binary.BigEndian.Uint16(b)
+	}
+	return 0
+}
+
+func (r *reader) uint32() uint32 {
+	if b := r.next(4); b != nil {
+		return binary.BigEndian.Uint32(b)
+	}
+	return 0
+}
+
+func (r *reader) uint64() uint64 {
+	if b := r.next(8); b != nil {
+		return binary.BigEndian.Uint64(b)
+	}
+	return 0
+}
+
+func (r *reader) shortString() string {
+	return string(r.next(int(r.byte())))
+}
+
+func (r *reader) longString() string {
+	return string(r.next(int(r.uint32())))
+}
+
+// table decodes the field table with RabbitMQ field types, see https://www.rabbitmq.com/amqp-0-9-1-errata.html
+func (r *reader) table() map[string]interface{} {
+	data := r.next(int(r.uint32()))
+	if r.err != nil {
+		return nil
+	}
+	tr := &reader{data: data}
+	result := make(map[string]interface{})
+	for len(tr.data) > 0 && tr.err == nil {
+		name := tr.shortString()
+		result[name] = tr.value()
+	}
+	if tr.err != nil {
+		r.err = tr.err
+		return nil
+	}
+	return result
+}
+
+func (r *reader) array() []interface{} {
+	data := r.next(int(r.uint32()))
+	if r.err != nil {
+		return nil
+	}
+	ar := &reader{data: data}
+	var result []interface{}
+	for len(ar.data) > 0 && ar.err == nil {
+		result = append(result, ar.value())
+	}
+	if ar.err != nil {
+		r.err = ar.err
+		return nil
+	}
+	return result
+}
+
+func (r *reader) value() interface{} {
+	switch t := r.byte(); t {
+	case 't':
+		return r.byte() != 0
+	case 'b':
+		return int64(int8(r.byte()))
+	case 'B':
+		return int64(r.byte())
+	case 's':
+		return int64(int16(r.uint16()))
+	case 'u':
+		return int64(r.uint16())
+	case 'I':
+		return int64(int32(r.uint32()))
+	case 'i':
+		return int64(r.uint32())
+	case 'l':
+		return int64(r.uint64())
+	case 'T':
+		return r.uint64()
+	case 'f':
+		return float64(math.Float32frombits(r.uint32()))
+	case 'd':
+		return math.Float64frombits(r.uint64())
+	case 'D':
+		scale := r.byte()
+		return float64(int32(r.uint32())) / math.Pow10(int(scale))
+	case 'S', 'x':
+		return r.longString()
+	case 'A':
+		return r.array()
+	case 'F':
+		return r.table()
+	case 'V':
+		return nil
+	default:
+		if r.err == nil {
+			r.err = fmt.Errorf("%w: field type %#x", errInvalidFrame, t)
+		}
+		return nil
+	}
+}
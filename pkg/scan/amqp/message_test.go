@@ -0,0 +1,149 @@
+package amqp
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func appendShortString(b []byte, s string) []byte {
+	b = append(b, byte(len(s)))
+	return append(b, s...)
+}
+
+func appendLongString(b []byte, s string) []byte {
+	b = binary.BigEndian.AppendUint32(b, uint32(len(s)))
+	return append(b, s...)
+}
+
+// appendTable appends the field table with long string values
+func appendTable(b []byte, fields ...string) []byte {
+	var data []byte
+	for i := 0; i+1 < len(fields); i += 2 {
+		data = appendShortString(data, fields[i])
+		data = append(data, 'S')
+		data = appendLongString(data, fields[i+1])
+	}
+	b = binary.BigEndian.AppendUint32(b, uint32(len(data)))
+	return append(b, data...)
+}
+
+func methodFrame(payload []byte) []byte {
+	frame := []byte{frameMethod, 0, 0}
+	frame = binary.BigEndian.AppendUint32(frame, uint32(len(payload)))
+	frame = append(frame, payload...)
+	return append(frame, frameEnd)
+}
+
+func connectionStartFrame(mechanisms string, properties ...string) []byte {
+	payload := binary.BigEndian.AppendUint16(nil, classConnection)
+	payload = binary.BigEndian.AppendUint16(payload, methodStart)
+	payload = append(payload, 0, 9)
+	payload = appendTable(payload, properties...)
+	payload = appendLongString(payload, mechanisms)
+	payload = appendLongString(payload, "en_US")
+	return methodFrame(payload)
+}
+
+func TestReadConnectionStart(t *testing.T) {
+	t.Parallel()
+	start, err := readConnectionStart(bytes.NewReader(connectionStartFrame("AMQPLAIN PLAIN",
+		"product", "RabbitMQ", "version", "3.12.4")))
+	require.NoError(t, err)
+	require.Equal(t, &connectionStart{
+		versionMajor: 0,
+		versionMinor: 9,
+		properties:   map[string]interface{}{"product": "RabbitMQ", "version": "3.12.4"},
+		mechanisms:   []string{"AMQPLAIN", "PLAIN"},
+		locales:      []string{"en_US"},
+	}, start)
+}
+
+func TestReadConnectionStartProtocolHeader(t *testing.T) {
+	t.Parallel()
+	_, err := readConnectionStart(bytes.NewReader([]byte{'A', 'M', 'Q', 'P', 0, 1, 0, 0}))
+	var protoErr *protocolError
+	require.ErrorAs(t, err, &protoErr)
+	require.Equal(t, "1-0-0", protoErr.version)
+}
+
+func TestReadConnectionStartError(t *testing.T) {
+	t.Parallel()
+	frame := connectionStartFrame("PLAIN")
+	invalidEnd := append([]byte{}, frame...)
+	invalidEnd[len(invalidEnd)-1] = 0
+	tests := []struct {
+		name     string
+		data     []byte
+		expected error
+	}{
+		{
+			name:     "FrameEnd",
+			data:     invalidEnd,
+			expected: errInvalidFrame,
+		},
+		{
+			name:     "TooLarge",
+			data:     []byte{frameMethod, 0, 0, 0x7f, 0xff, 0xff, 0xff},
+			expected: errInvalidFrame,
+		},
+		{
+			name:     "FrameType",
+			data:     []byte{8, 0, 0, 0, 0, 0, 0, frameEnd},
+			expected: errUnexpectedFrame,
+		},
+		{
+			name:     "Method",
+			data:     methodFrame([]byte{0, 10, 0, 11}),
+			expected: errUnexpectedFrame,
+		},
+		{
+			name:     "Truncated",
+			data:     methodFrame([]byte{0, 10, 0, 10, 0, 9, 0, 0, 0, 10}),
+			expected: errInvalidFrame,
+		},
+	}
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := readConnectionStart(bytes.NewReader(tt.data))
+			require.ErrorIs(t, err, tt.expected)
+		})
+	}
+}
+
+func TestReaderTable(t *testing.T) {
+	t.Parallel()
+	var data []byte
+	data = appendShortString(data, "capabilities")
+	data = append(data, 'F')
+	var capabilities []byte
+	capabilities = appendShortString(capabilities, "publisher_confirms")
+	capabilities = append(capabilities, 't', 1)
+	data = binary.BigEndian.AppendUint32(data, uint32(len(capabilities)))
+	data = append(data, capabilities...)
+	data = appendShortString(data, "channel_max")
+	data = append(data, 'u', 0x07, 0xff)
+	data = appendShortString(data, "ports")
+	data = append(data, 'A', 0, 0, 0, 10, 'I', 0, 0, 0x16, 0x28, 'I', 0, 0, 0x3d, 0x38)
+	data = appendShortString(data, "none")
+	data = append(data, 'V')
+
+	r := &reader{data: binary.BigEndian.AppendUint32(nil, uint32(len(data)))}
+	r.data = append(r.data, data...)
+	result := r.table()
+	require.NoError(t, r.err)
+	require.Equal(t, map[string]interface{}{
+		"capabilities": map[string]interface{}{"publisher_confirms": true},
+		"channel_max":  int64(2047),
+		"ports":        []interface{}{int64(5672), int64(15672)},
+		"none":         nil,
+	}, result)
+
+	r = &reader{data: []byte{0, 0, 0, 3, 1, 'a', '?'}}
+	r.table()
+	require.ErrorIs(t, r.err, errInvalidFrame)
+}
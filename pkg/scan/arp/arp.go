@@ -81,10 +81,17 @@ func NewPacketFiller() *PacketFiller {
 	return &PacketFiller{}
 }
 
+var broadcastMAC = net.HardwareAddr{0xff, 0xff, 0xff, 0xff, 0xff, 0xff}
+
+// Fill creates the ARP request, it is broadcasted unless the request has the destination MAC address
 func (*PacketFiller) Fill(packet gopacket.SerializeBuffer, r *scan.Request) error {
+	dstMAC := r.DstMAC
+	if dstMAC == nil {
+		dstMAC = broadcastMAC
+	}
 	eth := &layers.Ethernet{
 		SrcMAC:       r.SrcMAC,
-		DstMAC:       net.HardwareAddr{0xff, 0xff, 0xff, 0xff, 0xff, 0xff},
+		DstMAC:       dstMAC,
 		EthernetType: layers.EthernetTypeARP,
 	}
 
@@ -70,3 +70,60 @@ func TestProcessPacketData(t *testing.T) {
 		t.Fatal("test timeout")
 	}
 }
+
+func TestPacketFillerDstMAC(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		dstMAC   net.HardwareAddr
+		expected net.HardwareAddr
+	}{
+		{
+			name:     "Broadcast",
+			expected: net.HardwareAddr{0xff, 0xff, 0xff, 0xff, 0xff, 0xff},
+		},
+		{
+			name:     "CustomDstMAC",
+			dstMAC:   net.HardwareAddr{0x01, 0x00, 0x5e, 0x00, 0x00, 0x01},
+			expected: net.HardwareAddr{0x01, 0x00, 0x5e, 0x00, 0x00, 0x01},
+		},
+	}
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			packet := gopacket.NewSerializeBuffer()
+			err := NewPacketFiller().Fill(packet, &scan.Request{
+				SrcIP:  net.IPv4(192, 168, 0, 3).To4(),
+				DstIP:  net.IPv4(192, 168, 0, 2).To4(),
+				SrcMAC: net.HardwareAddr{0x1, 0x2, 0x3, 0x4, 0x5, 0x6},
+				DstMAC: tt.dstMAC,
+			})
+			require.NoError(t, err)
+
+			pkt := gopacket.NewPacket(packet.Bytes(), layers.LayerTypeEthernet, gopacket.Default)
+			eth := pkt.Layer(layers.LayerTypeEthernet).(*layers.Ethernet)
+			require.Equal(t, tt.expected, eth.DstMAC)
+			a := pkt.Layer(layers.LayerTypeARP).(*layers.ARP)
+			require.Equal(t, []byte(net.IPv4(192, 168, 0, 2).To4()), a.DstProtAddress)
+		})
+	}
+}
+
+func BenchmarkPacketFiller(b *testing.B) {
+	b.ReportAllocs()
+	filler := NewPacketFiller()
+	packet := gopacket.NewSerializeBuffer()
+	r := &scan.Request{
+		SrcIP:   net.IPv4(192, 168, 0, 3).To4(),
+		DstIP:   net.IPv4(192, 168, 0, 2).To4(),
+		SrcMAC:  net.HardwareAddr{0x1, 0x2, 0x3, 0x4, 0x5, 0x6},
+		DstMAC:  net.HardwareAddr{0x10, 0x11, 0x12, 0x13, 0x14, 0x15},
+		DstPort: 22,
+	}
+	for i := 0; i < b.N; i++ {
+		if err := filler.Fill(packet, r); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
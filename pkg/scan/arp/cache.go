@@ -3,7 +3,6 @@ package arp
 import (
 	"bufio"
 	"context"
-	"errors"
 	"fmt"
 	"io"
 	"net"
@@ -48,7 +47,7 @@ func FillCache(cache *Cache, r io.Reader) error {
 		}
 		ip := net.ParseIP(entry.IP)
 		if ip == nil {
-			return errors.New("invalid IP")
+			return scan.ErrIP
 		}
 		mac, err := net.ParseMAC(entry.MAC)
 		if err != nil {
@@ -75,6 +74,14 @@ func NewCacheRequestGenerator(reqgen scan.RequestGenerator, gatewayMAC net.Hardw
 	return result
 }
 
+// NewDstMACRequestGenerator sets the same destination MAC address for all requests,
+// e.g. broadcast or multicast address, regardless of the ARP cache and the gateway
+func NewDstMACRequestGenerator(reqgen scan.RequestGenerator, dstMAC net.HardwareAddr) scan.RequestGenerator {
+	return &cacheReqGenerator{reqgen: reqgen, getMAC: func(net.IP) net.HardwareAddr {
+		return dstMAC
+	}}
+}
+
 func (g *cacheReqGenerator) GenerateRequests(ctx context.Context, r *scan.Range) (<-chan *scan.Request, error) {
 	requests, err := g.reqgen.GenerateRequests(ctx, r)
 	if err != nil {
@@ -87,7 +94,8 @@ func (g *cacheReqGenerator) GenerateRequests(ctx context.Context, r *scan.Range)
 			if mac := g.getMAC(request.DstIP); mac != nil {
 				request.DstMAC = mac
 			} else {
-				request.Err = fmt.Errorf("no destination MAC address for %s", request.DstIP)
+				request.Err = scan.NewError(scan.ErrUnreachable,
+					fmt.Errorf("no destination MAC address for %s", request.DstIP))
 			}
 			result <- request
 		}
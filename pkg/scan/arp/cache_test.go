@@ -227,7 +227,8 @@ func TestCacheRequestGenerator(t *testing.T) {
 			expectedRequests: []*scan.Request{
 				{
 					DstIP: net.IPv4(10, 168, 0, 2).To4(),
-					Err:   fmt.Errorf("no destination MAC address for %s", net.IPv4(10, 168, 0, 2)),
+					Err: scan.NewError(scan.ErrUnreachable,
+						fmt.Errorf("no destination MAC address for %s", net.IPv4(10, 168, 0, 2))),
 				},
 			},
 		},
@@ -294,3 +295,29 @@ func TestCacheRequestGeneratorReturnsError(t *testing.T) {
 	_, err := cachegen.GenerateRequests(context.Background(), &scan.Range{})
 	require.Error(t, err)
 }
+
+func TestDstMACRequestGenerator(t *testing.T) {
+	t.Parallel()
+	dstMAC := net.HardwareAddr{0xff, 0xff, 0xff, 0xff, 0xff, 0xff}
+	requestsCh := make(chan *scan.Request, 2)
+	requestsCh <- &scan.Request{DstIP: net.IPv4(10, 168, 0, 2).To4()}
+	requestsCh <- &scan.Request{DstIP: net.IPv4(10, 168, 0, 3).To4()}
+	close(requestsCh)
+
+	ctrl := gomock.NewController(t)
+	reqgen := NewMockRequestGenerator(ctrl)
+	reqgen.EXPECT().GenerateRequests(gomock.Any(), gomock.Any()).Return(requestsCh, nil)
+
+	macgen := NewDstMACRequestGenerator(reqgen, dstMAC)
+	results, err := macgen.GenerateRequests(context.Background(), &scan.Range{})
+	require.NoError(t, err)
+
+	var requests []*scan.Request
+	for request := range results {
+		requests = append(requests, request)
+	}
+	require.Equal(t, []*scan.Request{
+		{DstIP: net.IPv4(10, 168, 0, 2).To4(), DstMAC: dstMAC},
+		{DstIP: net.IPv4(10, 168, 0, 3).To4(), DstMAC: dstMAC},
+	}, requests)
+}
@@ -0,0 +1,213 @@
+// Package bacnet enumerates BACnet/IP devices by reading properties of their device objects
+package bacnet
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+const (
+	ScanType = "bacnet"
+
+	defaultDataTimeout = 1 * time.Second
+	defaultRetries     = 1
+	maxPacketSize      = 1500
+)
+
+type ScanResult struct {
+	ScanType string `json:"scan"`
+	IP       string `json:"ip"`
+	Port     uint16 `json:"port"`
+	// Instance is the instance number of the device object, it is unique in the BACnet internetwork
+	Instance   uint32 `json:"instance"`
+	ObjectName string `json:"object_name,omitempty"`
+	// VendorID is the vendor identifier assigned by ASHRAE
+	VendorID   uint32 `json:"vendor_id"`
+	VendorName string `json:"vendor_name,omitempty"`
+	ModelName  string `json:"model_name,omitempty"`
+	Firmware   string `json:"firmware,omitempty"`
+	// Software is the application software version of the device
+	Software string `json:"software,omitempty"`
+}
+
+func (r *ScanResult) String() string {
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "%-20s %-5d instance %d vendor %d %q", r.IP, r.Port, r.Instance, r.VendorID, r.VendorName)
+	if len(r.ModelName) > 0 {
+		fmt.Fprintf(&buf, " model %q", r.ModelName)
+	}
+	if len(r.Firmware) > 0 {
+		fmt.Fprintf(&buf, " firmware %q", r.Firmware)
+	}
+	if len(r.ObjectName) > 0 {
+		fmt.Fprintf(&buf, " name %q", r.ObjectName)
+	}
+	return buf.String()
+}
+
+func (r *ScanResult) ID() string {
+	return fmt.Sprintf("%s:%d", r.IP, r.Port)
+}
+
+func (r *ScanResult) MarshalJSON() ([]byte, error) {
+	// Type definition for the recursive call
+	type JScanResult ScanResult
+	// This works because JScanResult doesn't have a MarshalJSON function associated with it
+	return json.Marshal(JScanResult(*r))
+}
+
+// Scanner sends ReadProperty requests of the device object to each target over UDP.
+// The object identifier is read first, devices that don't answer it are not reported.
+// Other properties are optional, so properties the device doesn't answer are left empty.
+type Scanner struct {
+	dataTimeout time.Duration
+	retries     int
+}
+
+// Assert that bacnet.Scanner conforms to the scan.Scanner interface
+var _ scan.Scanner = (*Scanner)(nil)
+
+type ScannerOption func(*Scanner)
+
+// WithDataTimeout sets the time to wait for the response to each request
+func WithDataTimeout(timeout time.Duration) ScannerOption {
+	return func(s *Scanner) {
+		s.dataTimeout = timeout
+	}
+}
+
+// WithRetries sets the number of additional requests of each property if there is no response
+func WithRetries(retries int) ScannerOption {
+	return func(s *Scanner) {
+		s.retries = retries
+	}
+}
+
+func NewScanner(opts ...ScannerOption) *Scanner {
+	s := &Scanner{
+		dataTimeout: defaultDataTimeout,
+		retries:     defaultRetries,
+	}
+	for _, o := range opts {
+		o(s)
+	}
+	return s
+}
+
+func (s *Scanner) Scan(ctx context.Context, r *scan.Request) (scan.Result, error) {
+	result, err := s.scanTarget(ctx, r)
+	return result, scan.WrapError(err)
+}
+
+func (s *Scanner) scanTarget(ctx context.Context, r *scan.Request) (result scan.Result, err error) {
+	addr := net.JoinHostPort(r.DstIP.String(), strconv.Itoa(int(r.DstPort)))
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "udp", addr)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	var invokeID byte
+	value, err := s.readProperty(ctx, conn, &invokeID, propObjectIdentifier)
+	if err != nil || value == nil {
+		return nil, err
+	}
+	res := &ScanResult{
+		ScanType: ScanType,
+		IP:       r.DstIP.String(),
+		Port:     r.DstPort,
+	}
+	if res.Instance, err = valueInstance(value); err != nil {
+		return nil, err
+	}
+
+	textProps := []struct {
+		property byte
+		field    *string
+	}{
+		{propObjectName, &res.ObjectName},
+		{propVendorName, &res.VendorName},
+		{propModelName, &res.ModelName},
+		{propFirmwareRevision, &res.Firmware},
+		{propApplicationSoftwareVersion, &res.Software},
+	}
+	for _, prop := range textProps {
+		if value, err = s.readProperty(ctx, conn, &invokeID, prop.property); err != nil {
+			return nil, err
+		}
+		if value != nil {
+			*prop.field, _ = valueString(value)
+		}
+	}
+	if value, err = s.readProperty(ctx, conn, &invokeID, propVendorIdentifier); err != nil {
+		return nil, err
+	}
+	if value != nil {
+		res.VendorID, _ = valueUint(value)
+	}
+	return res, nil
+}
+
+// readProperty reads the property of the device object and returns its value,
+// no value is returned if the device doesn't answer or answers with the error
+func (s *Scanner) readProperty(ctx context.Context, conn net.Conn, invokeID *byte, property byte) ([]byte, error) {
+	for i := 0; i <= s.retries; i++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		*invokeID++
+		value, answered, err := s.exchange(conn, *invokeID, property)
+		if err != nil || answered {
+			return value, err
+		}
+	}
+	return nil, nil
+}
+
+// exchange sends the ReadProperty request and reads responses until the response of the invoke id,
+// answered is false if there is no response within the data timeout
+func (s *Scanner) exchange(conn net.Conn, invokeID, property byte) (value []byte, answered bool, err error) {
+	if _, err = conn.Write(readPropertyRequest(invokeID, property)); err != nil {
+		return
+	}
+	if err = conn.SetReadDeadline(time.Now().Add(s.dataTimeout)); err != nil {
+		return
+	}
+	buf := make([]byte, maxPacketSize)
+	for {
+		n, rerr := conn.Read(buf)
+		if isTimeout(rerr) {
+			return nil, false, nil
+		}
+		if rerr != nil {
+			return nil, false, rerr
+		}
+		apdu, perr := parseAPDU(buf[:n])
+		if perr != nil {
+			continue
+		}
+		id, data, perr := parseReadPropertyAck(apdu)
+		if id != invokeID || errors.Is(perr, errInvalidMessage) {
+			continue
+		}
+		if perr != nil {
+			return nil, true, nil
+		}
+		// the value refers to the read buffer
+		return append([]byte(nil), data...), true, nil
+	}
+}
+
+func isTimeout(err error) bool {
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
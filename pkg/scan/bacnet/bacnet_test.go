@@ -0,0 +1,141 @@
+package bacnet
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+type fakeDevice struct {
+	instance uint32
+	// properties are values of device object properties, other properties are answered with the error
+	properties map[byte][]byte
+	// drop is the number of first requests that are not answered
+	drop int
+
+	mu       sync.Mutex
+	requests int
+}
+
+func startFakeDevice(t *testing.T, dev *fakeDevice) *scan.Request {
+	t.Helper()
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	go dev.serve(conn)
+	addr := conn.LocalAddr().(*net.UDPAddr)
+	return &scan.Request{DstIP: addr.IP, DstPort: uint16(addr.Port)}
+}
+
+func (d *fakeDevice) serve(conn net.PacketConn) {
+	buf := make([]byte, maxPacketSize)
+	for {
+		n, addr, err := conn.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+		apdu, err := parseAPDU(buf[:n])
+		if err != nil || len(apdu) < 11 || apdu[0] != pduConfirmedRequest || apdu[3] != serviceReadProperty {
+			continue
+		}
+		d.mu.Lock()
+		d.requests++
+		dropped := d.requests <= d.drop
+		d.mu.Unlock()
+		if dropped {
+			continue
+		}
+		invokeID, property := apdu[2], apdu[10]
+		response := []byte{pduError, invokeID, serviceReadProperty, 0x91, 2, 0x91, 32}
+		if value, ok := d.properties[property]; ok {
+			response = readPropertyAck(invokeID, d.instance, property, value)
+		}
+		// the device routes the response from the remote network
+		npdu := append([]byte{npduVersion, npduSrcSpecified, 0, 5, 1, 0x0a}, response...)
+		_, _ = conn.WriteTo(bvlc(bvlcOriginalUnicast, npdu), addr)
+	}
+}
+
+var controllerProperties = map[byte][]byte{
+	propObjectIdentifier:           objectIDValue(389001),
+	propObjectName:                 stringValue("AHU-3 Controller"),
+	propVendorIdentifier:           uintValue(7),
+	propVendorName:                 stringValue("Siemens Building Technologies"),
+	propModelName:                  stringValue("PXC Modular"),
+	propFirmwareRevision:           stringValue("3.2.1"),
+	propApplicationSoftwareVersion: stringValue("PXC 3.2"),
+}
+
+func TestScan(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		dev      *fakeDevice
+		expected *ScanResult
+	}{
+		{
+			name: "AllProperties",
+			dev:  &fakeDevice{instance: 389001, properties: controllerProperties},
+			expected: &ScanResult{Instance: 389001, ObjectName: "AHU-3 Controller", VendorID: 7,
+				VendorName: "Siemens Building Technologies", ModelName: "PXC Modular", Firmware: "3.2.1",
+				Software: "PXC 3.2"},
+		},
+		{
+			name: "RequiredProperties",
+			dev: &fakeDevice{instance: 12, properties: map[byte][]byte{
+				propObjectIdentifier: objectIDValue(12),
+				propVendorIdentifier: uintValue(260),
+			}},
+			expected: &ScanResult{Instance: 12, VendorID: 260},
+		},
+		{
+			name:     "Retry",
+			dev:      &fakeDevice{instance: 12, drop: 1, properties: map[byte][]byte{propObjectIdentifier: objectIDValue(12)}},
+			expected: &ScanResult{Instance: 12},
+		},
+	}
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			req := startFakeDevice(t, tt.dev)
+			result, err := NewScanner(WithDataTimeout(200*time.Millisecond)).Scan(context.Background(), req)
+			require.NoError(t, err)
+
+			expected := tt.expected
+			expected.ScanType = ScanType
+			expected.IP = req.DstIP.String()
+			expected.Port = req.DstPort
+			require.Equal(t, expected, result)
+		})
+	}
+}
+
+func TestScanNoResponse(t *testing.T) {
+	t.Parallel()
+	req := startFakeDevice(t, &fakeDevice{drop: 2, properties: controllerProperties})
+	result, err := NewScanner(WithDataTimeout(100*time.Millisecond)).Scan(context.Background(), req)
+	require.NoError(t, err)
+	require.Nil(t, result)
+}
+
+func TestScanNoRetries(t *testing.T) {
+	t.Parallel()
+	req := startFakeDevice(t, &fakeDevice{drop: 1, properties: controllerProperties})
+	result, err := NewScanner(WithDataTimeout(100*time.Millisecond), WithRetries(0)).Scan(context.Background(), req)
+	require.NoError(t, err)
+	require.Nil(t, result)
+}
+
+func TestScanResultString(t *testing.T) {
+	t.Parallel()
+	result := &ScanResult{ScanType: ScanType, IP: "192.168.0.1", Port: 47808, Instance: 389001, VendorID: 7,
+		VendorName: "Siemens Building Technologies", ModelName: "PXC Modular", Firmware: "3.2.1"}
+	require.Equal(t, `192.168.0.1          47808 instance 389001 vendor 7 "Siemens Building Technologies" model "PXC Modular" firmware "3.2.1"`,
+		result.String())
+}
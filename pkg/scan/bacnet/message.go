@@ -0,0 +1,277 @@
+package bacnet
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+// BACnet Virtual Link Control, see ANSI/ASHRAE 135 Annex J.2
+const (
+	bvlcTypeBACnetIP      = 0x81
+	bvlcForwardedNPDU     = 0x04
+	bvlcOriginalUnicast   = 0x0a
+	bvlcOriginalBroadcast = 0x0b
+	bvlcHeaderSize        = 4
+	// forwardedAddrSize is the size of the original source address of forwarded NPDUs
+	forwardedAddrSize = 6
+)
+
+// network layer control flags, see clause 6.2.2
+const (
+	npduVersion        = 0x01
+	npduNetworkMessage = 0x80
+	npduDstSpecified   = 0x20
+	npduSrcSpecified   = 0x08
+	npduExpectingReply = 0x04
+	npduHeaderSize     = 2
+)
+
+// APDU types and ReadProperty service, see clauses 20.1 and 15.5
+const (
+	pduConfirmedRequest = 0x00
+	pduComplexAck       = 0x30
+	pduError            = 0x50
+	pduReject           = 0x60
+	pduAbort            = 0x70
+
+	// maxAPDU1476 accepts unsegmented responses up to 1476 bytes
+	maxAPDU1476         = 0x05
+	serviceReadProperty = 0x0c
+)
+
+// object types and property identifiers, see clause 21
+const (
+	objectDevice = 8
+	// wildcardInstance addresses the device object of the device that receives the request
+	wildcardInstance = 0x3fffff
+	instanceMask     = 0x3fffff
+
+	propApplicationSoftwareVersion = 12
+	propFirmwareRevision           = 44
+	propModelName                  = 70
+	propObjectIdentifier           = 75
+	propObjectName                 = 77
+	propVendorIdentifier           = 120
+	propVendorName                 = 121
+)
+
+// application tags of property values, see clause 20.2.1.4
+const (
+	tagUnsignedInt      = 2
+	tagCharacterString  = 7
+	tagObjectIdentifier = 12
+
+	tagOpening = 0x0e
+	tagClosing = 0x0f
+	// charsetUTF8 is the ANSI X3.4 character set, UTF-8 in the current standard
+	charsetUTF8     = 0
+	charsetUCS2     = 4
+	charsetISO88591 = 5
+)
+
+var (
+	errInvalidMessage = scan.NewError(scan.ErrParse, errors.New("invalid BACnet message"))
+	errPropertyError  = errors.New("BACnet property read failed")
+)
+
+// readPropertyRequest returns the ReadProperty request of the property of the device object
+func readPropertyRequest(invokeID byte, property byte) []byte {
+	packet := []byte{bvlcTypeBACnetIP, bvlcOriginalUnicast, 0, 0}
+	packet = append(packet, npduVersion, npduExpectingReply)
+	packet = append(packet, pduConfirmedRequest, maxAPDU1476, invokeID, serviceReadProperty)
+	// context tag 0 of 4 bytes with the object identifier
+	packet = append(packet, 0x0c)
+	packet = binary.BigEndian.AppendUint32(packet, objectDevice<<22|wildcardInstance)
+	// context tag 1 of 1 byte with the property identifier
+	packet = append(packet, 0x19, property)
+	binary.BigEndian.PutUint16(packet[2:4], uint16(len(packet)))
+	return packet
+}
+
+// parseAPDU returns the APDU of the BACnet/IP packet
+func parseAPDU(data []byte) ([]byte, error) {
+	if len(data) < bvlcHeaderSize || data[0] != bvlcTypeBACnetIP {
+		return nil, fmt.Errorf("%w: not BACnet/IP", errInvalidMessage)
+	}
+	length := int(binary.BigEndian.Uint16(data[2:4]))
+	if length < bvlcHeaderSize {
+		return nil, fmt.Errorf("%w: invalid BVLC length %d", errInvalidMessage, length)
+	}
+	if length < len(data) {
+		data = data[:length]
+	}
+	npdu := data[bvlcHeaderSize:]
+	switch data[1] {
+	case bvlcOriginalUnicast, bvlcOriginalBroadcast:
+	case bvlcForwardedNPDU:
+		if len(npdu) < forwardedAddrSize {
+			return nil, fmt.Errorf("%w: short forwarded NPDU", errInvalidMessage)
+		}
+		npdu = npdu[forwardedAddrSize:]
+	default:
+		return nil, fmt.Errorf("%w: unknown BVLC function %#02x", errInvalidMessage, data[1])
+	}
+
+	if len(npdu) < npduHeaderSize || npdu[0] != npduVersion || npdu[1]&npduNetworkMessage != 0 {
+		return nil, fmt.Errorf("%w: not application NPDU", errInvalidMessage)
+	}
+	control := npdu[1]
+	offset := npduHeaderSize
+	// network numbers and MAC addresses of remote networks precede the APDU
+	for _, flag := range []byte{npduDstSpecified, npduSrcSpecified} {
+		if control&flag == 0 {
+			continue
+		}
+		if len(npdu) < offset+3 {
+			return nil, fmt.Errorf("%w: short NPDU", errInvalidMessage)
+		}
+		offset += 3 + int(npdu[offset+2])
+	}
+	if control&npduDstSpecified != 0 {
+		// hop count
+		offset++
+	}
+	if len(npdu) <= offset {
+		return nil, fmt.Errorf("%w: short NPDU", errInvalidMessage)
+	}
+	return npdu[offset:], nil
+}
+
+// parseReadPropertyAck returns the invoke id and the application tagged value of the ReadProperty response
+func parseReadPropertyAck(apdu []byte) (invokeID byte, value []byte, err error) {
+	if len(apdu) < 3 {
+		return 0, nil, fmt.Errorf("%w: short APDU", errInvalidMessage)
+	}
+	invokeID = apdu[1]
+	switch apdu[0] & 0xf0 {
+	case pduComplexAck:
+	case pduError, pduReject, pduAbort:
+		return invokeID, nil, errPropertyError
+	default:
+		return invokeID, nil, fmt.Errorf("%w: unexpected APDU type %#02x", errInvalidMessage, apdu[0])
+	}
+	if apdu[0]&0x08 != 0 || apdu[2] != serviceReadProperty {
+		return invokeID, nil, fmt.Errorf("%w: unexpected complex ACK", errInvalidMessage)
+	}
+	// object identifier and property identifier are followed by the property value in opening and closing tags
+	data := apdu[3:]
+	for len(data) > 0 && data[0] != (3<<4|tagOpening) {
+		var size int
+		if _, _, size, err = parseTag(data); err != nil {
+			return
+		}
+		data = data[size:]
+	}
+	if len(data) == 0 {
+		return invokeID, nil, fmt.Errorf("%w: no property value", errInvalidMessage)
+	}
+	return invokeID, data[1:], nil
+}
+
+// parseTag parses the tag header and returns the tag number, the content and the total size of the tag
+func parseTag(data []byte) (tag byte, content []byte, size int, err error) {
+	if len(data) == 0 {
+		return 0, nil, 0, fmt.Errorf("%w: empty tag", errInvalidMessage)
+	}
+	tag = data[0] >> 4
+	length := int(data[0] & 0x07)
+	offset := 1
+	if tag == 0x0f {
+		return 0, nil, 0, fmt.Errorf("%w: extended tag number", errInvalidMessage)
+	}
+	if data[0]&0x08 != 0 && (length == 6 || length == 7) {
+		// opening and closing tags have no content
+		return tag, nil, 1, nil
+	}
+	if length == 5 {
+		if len(data) < 2 {
+			return 0, nil, 0, fmt.Errorf("%w: short tag", errInvalidMessage)
+		}
+		length = int(data[1])
+		offset = 2
+		if length == 254 {
+			if len(data) < 4 {
+				return 0, nil, 0, fmt.Errorf("%w: short tag", errInvalidMessage)
+			}
+			length = int(binary.BigEndian.Uint16(data[2:4]))
+			offset = 4
+		}
+	}
+	if data[0]&0x08 == 0 && tag == 1 {
+		// application boolean values are stored in the length field
+		length = 0
+	}
+	if len(data) < offset+length {
+		return 0, nil, 0, fmt.Errorf("%w: truncated tag", errInvalidMessage)
+	}
+	return tag, data[offset : offset+length], offset + length, nil
+}
+
+// valueString returns the character string value
+func valueString(value []byte) (string, error) {
+	tag, content, _, err := parseTag(value)
+	if err != nil {
+		return "", err
+	}
+	if tag != tagCharacterString || len(content) == 0 {
+		return "", fmt.Errorf("%w: not character string", errInvalidMessage)
+	}
+	text := content[1:]
+	switch content[0] {
+	case charsetUTF8:
+		if utf8.Valid(text) {
+			return string(text), nil
+		}
+	case charsetUCS2:
+		if len(text)%2 == 0 {
+			runes := make([]rune, 0, len(text)/2)
+			for i := 0; i < len(text); i += 2 {
+				runes = append(runes, rune(binary.BigEndian.Uint16(text[i:i+2])))
+			}
+			return string(runes), nil
+		}
+	case charsetISO88591:
+		runes := make([]rune, 0, len(text))
+		for _, b := range text {
+			runes = append(runes, rune(b))
+		}
+		return string(runes), nil
+	}
+	return "", fmt.Errorf("%w: invalid string of character set %d", errInvalidMessage, content[0])
+}
+
+// valueUint returns the unsigned integer value
+func valueUint(value []byte) (uint32, error) {
+	tag, content, _, err := parseTag(value)
+	if err != nil {
+		return 0, err
+	}
+	if tag != tagUnsignedInt || len(content) == 0 || len(content) > 4 {
+		return 0, fmt.Errorf("%w: not unsigned integer", errInvalidMessage)
+	}
+	var result uint32
+	for _, b := range content {
+		result = result<<8 | uint32(b)
+	}
+	return result, nil
+}
+
+// valueInstance returns the instance number of the device object identifier value
+func valueInstance(value []byte) (uint32, error) {
+	tag, content, _, err := parseTag(value)
+	if err != nil {
+		return 0, err
+	}
+	if tag != tagObjectIdentifier || len(content) != 4 {
+		return 0, fmt.Errorf("%w: not object identifier", errInvalidMessage)
+	}
+	id := binary.BigEndian.Uint32(content)
+	if id>>22 != objectDevice {
+		return 0, fmt.Errorf("%w: not device object", errInvalidMessage)
+	}
+	return id & instanceMask, nil
+}
@@ -0,0 +1,211 @@
+package bacnet
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+// bvlc returns the BACnet/IP packet of the NPDU
+func bvlc(function byte, npdu []byte) []byte {
+	packet := []byte{bvlcTypeBACnetIP, function, 0, 0}
+	packet = append(packet, npdu...)
+	binary.BigEndian.PutUint16(packet[2:4], uint16(len(packet)))
+	return packet
+}
+
+// readPropertyAck returns the APDU of the ReadProperty response of the device object with the value
+func readPropertyAck(invokeID byte, instance uint32, property byte, value []byte) []byte {
+	apdu := []byte{pduComplexAck, invokeID, serviceReadProperty, 0x0c}
+	apdu = binary.BigEndian.AppendUint32(apdu, objectDevice<<22|instance)
+	apdu = append(apdu, 0x19, property, 0x3e)
+	apdu = append(apdu, value...)
+	return append(apdu, 0x3f)
+}
+
+func stringValue(s string) []byte {
+	if len(s)+1 < 5 {
+		return append([]byte{tagCharacterString<<4 | byte(len(s)+1), charsetUTF8}, s...)
+	}
+	return append([]byte{tagCharacterString<<4 | 5, byte(len(s) + 1), charsetUTF8}, s...)
+}
+
+func uintValue(v uint16) []byte {
+	return binary.BigEndian.AppendUint16([]byte{tagUnsignedInt<<4 | 2}, v)
+}
+
+func objectIDValue(instance uint32) []byte {
+	return binary.BigEndian.AppendUint32([]byte{tagObjectIdentifier<<4 | 4}, objectDevice<<22|instance)
+}
+
+func TestReadPropertyRequest(t *testing.T) {
+	t.Parallel()
+	expected, err := hex.DecodeString("810a001101040005010c0c023fffff1978")
+	require.NoError(t, err)
+	require.Equal(t, expected, readPropertyRequest(1, propVendorIdentifier))
+}
+
+func TestParseAPDU(t *testing.T) {
+	t.Parallel()
+	apdu := []byte{pduComplexAck, 1, serviceReadProperty}
+	tests := []struct {
+		name   string
+		packet []byte
+	}{
+		{
+			name:   "Local",
+			packet: bvlc(bvlcOriginalUnicast, append([]byte{npduVersion, 0}, apdu...)),
+		},
+		{
+			name: "RemoteSource",
+			// source network 5 and MAC address of 1 byte
+			packet: bvlc(bvlcOriginalUnicast, append([]byte{npduVersion, npduSrcSpecified, 0, 5, 1, 0x0a}, apdu...)),
+		},
+		{
+			name: "RemoteDestination",
+			// destination network 0xffff with broadcast address, source network 5 and hop count
+			packet: bvlc(bvlcOriginalUnicast, append([]byte{npduVersion, npduDstSpecified | npduSrcSpecified,
+				0xff, 0xff, 0, 0, 5, 2, 0x0a, 0x0b, 0xfe}, apdu...)),
+		},
+		{
+			name: "Forwarded",
+			packet: bvlc(bvlcForwardedNPDU, append([]byte{192, 168, 0, 10, 0xba, 0xc0, npduVersion, 0},
+				apdu...)),
+		},
+	}
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			result, err := parseAPDU(tt.packet)
+			require.NoError(t, err)
+			require.Equal(t, apdu, result)
+		})
+	}
+}
+
+func TestParseAPDUError(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name   string
+		packet []byte
+	}{
+		{
+			name:   "NotBACnetIP",
+			packet: []byte{0x82, bvlcOriginalUnicast, 0, 6, npduVersion, 0},
+		},
+		{
+			name:   "ShortBVLCLength",
+			packet: []byte{bvlcTypeBACnetIP, 0x30, 0, 0},
+		},
+		{
+			name:   "UnknownFunction",
+			packet: bvlc(0x05, []byte{npduVersion, 0, pduComplexAck}),
+		},
+		{
+			name:   "NetworkMessage",
+			packet: bvlc(bvlcOriginalUnicast, []byte{npduVersion, npduNetworkMessage, 0x01}),
+		},
+		{
+			name:   "ShortNPDU",
+			packet: bvlc(bvlcOriginalUnicast, []byte{npduVersion, npduSrcSpecified, 0}),
+		},
+	}
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := parseAPDU(tt.packet)
+			require.ErrorIs(t, err, errInvalidMessage)
+			require.ErrorIs(t, err, scan.ErrParse)
+		})
+	}
+}
+
+func TestParseReadPropertyAck(t *testing.T) {
+	t.Parallel()
+	invokeID, value, err := parseReadPropertyAck(readPropertyAck(7, 1234, propVendorName, stringValue("Siemens")))
+	require.NoError(t, err)
+	require.Equal(t, byte(7), invokeID)
+	name, err := valueString(value)
+	require.NoError(t, err)
+	require.Equal(t, "Siemens", name)
+
+	invokeID, _, err = parseReadPropertyAck([]byte{pduError, 8, serviceReadProperty, 0x91, 2, 0x91, 32})
+	require.Equal(t, byte(8), invokeID)
+	require.ErrorIs(t, err, errPropertyError)
+
+	_, _, err = parseReadPropertyAck([]byte{0x20, 8, serviceReadProperty})
+	require.ErrorIs(t, err, errInvalidMessage)
+
+	_, _, err = parseReadPropertyAck([]byte{pduComplexAck, 8, serviceReadProperty, 0x0c, 0x02, 0x00})
+	require.ErrorIs(t, err, errInvalidMessage)
+}
+
+func TestValueString(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		value    []byte
+		expected string
+	}{
+		{
+			name:     "UTF8",
+			value:    stringValue("PXC Modular"),
+			expected: "PXC Modular",
+		},
+		{
+			name:     "UCS2",
+			value:    []byte{tagCharacterString<<4 | 5, 5, charsetUCS2, 0, 'O', 0, 'K'},
+			expected: "OK",
+		},
+		{
+			name:     "ISO88591",
+			value:    []byte{tagCharacterString<<4 | 3, charsetISO88591, 'A', 0xe9},
+			expected: "Aé",
+		},
+	}
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			result, err := valueString(tt.value)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, result)
+		})
+	}
+
+	_, err := valueString(uintValue(7))
+	require.ErrorIs(t, err, errInvalidMessage)
+	_, err = valueString([]byte{tagCharacterString<<4 | 2, 3, 'A'})
+	require.ErrorIs(t, err, errInvalidMessage)
+}
+
+func TestValueUint(t *testing.T) {
+	t.Parallel()
+	result, err := valueUint(uintValue(260))
+	require.NoError(t, err)
+	require.Equal(t, uint32(260), result)
+
+	_, err = valueUint(stringValue("7"))
+	require.ErrorIs(t, err, errInvalidMessage)
+}
+
+func TestValueInstance(t *testing.T) {
+	t.Parallel()
+	result, err := valueInstance(objectIDValue(389001))
+	require.NoError(t, err)
+	require.Equal(t, uint32(389001), result)
+
+	_, err = valueInstance(binary.BigEndian.AppendUint32([]byte{tagObjectIdentifier<<4 | 4}, 1<<22|5))
+	require.ErrorIs(t, err, errInvalidMessage)
+}
+
+func TestParseTagTruncated(t *testing.T) {
+	t.Parallel()
+	_, _, _, err := parseTag([]byte{tagCharacterString<<4 | 5, 10, charsetUTF8, 'A'})
+	require.ErrorIs(t, err, errInvalidMessage)
+}
@@ -0,0 +1,219 @@
+package cassandra
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+const (
+	ScanType = "cassandra"
+
+	defaultDialTimeout = 2 * time.Second
+	defaultDataTimeout = 2 * time.Second
+)
+
+type ScanResult struct {
+	ScanType string `json:"scan"`
+	IP       string `json:"ip"`
+	Port     uint16 `json:"port"`
+	// ProtocolVersion is the version of the CQL native protocol the server answered with
+	ProtocolVersion int `json:"protocol_version"`
+	// CQLVersions are CQL versions supported by the server, e.g. 3.4.5
+	CQLVersions []string `json:"cql_versions,omitempty"`
+	// AuthRequired is set if the server requested authentication after the STARTUP message
+	AuthRequired bool `json:"auth_required"`
+	// Authenticator is the class name of the server authenticator, e.g. org.apache.cassandra.auth.PasswordAuthenticator
+	Authenticator string `json:"authenticator,omitempty"`
+	// ClusterName and Version are read from the system.local table if authentication is not required
+	ClusterName string `json:"cluster_name,omitempty"`
+	Version     string `json:"version,omitempty"`
+	// Error is sent by the server instead of the STARTUP response
+	Error string `json:"error,omitempty"`
+}
+
+func (r *ScanResult) String() string {
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "%-20s %-5d protocol v%d", r.IP, r.Port, r.ProtocolVersion)
+	if len(r.Error) > 0 {
+		fmt.Fprintf(&buf, " error %q", r.Error)
+		return buf.String()
+	}
+	if r.AuthRequired {
+		fmt.Fprintf(&buf, " auth %s", r.Authenticator)
+	} else {
+		buf.WriteString(" noauth")
+	}
+	if len(r.Version) > 0 {
+		fmt.Fprintf(&buf, " %s", r.Version)
+	}
+	if len(r.ClusterName) > 0 {
+		fmt.Fprintf(&buf, " cluster %q", r.ClusterName)
+	}
+	return buf.String()
+}
+
+func (r *ScanResult) ID() string {
+	return fmt.Sprintf("%s:%d", r.IP, r.Port)
+}
+
+func (r *ScanResult) MarshalJSON() ([]byte, error) {
+	// Type definition for the recursive call
+	type JScanResult ScanResult
+	// This works because JScanResult doesn't have a MarshalJSON function associated with it
+	return json.Marshal(JScanResult(*r))
+}
+
+// Scanner performs the CQL OPTIONS and STARTUP exchange with Cassandra servers,
+// the cluster name is queried only if the server doesn't require authentication
+type Scanner struct {
+	dialer      *net.Dialer
+	dataTimeout time.Duration
+}
+
+// Assert that cassandra.Scanner conforms to the scan.Scanner interface
+var _ scan.Scanner = (*Scanner)(nil)
+
+type ScannerOption func(*Scanner)
+
+func WithDialTimeout(timeout time.Duration) ScannerOption {
+	return func(s *Scanner) {
+		s.dialer.Timeout = timeout
+	}
+}
+
+func WithDataTimeout(timeout time.Duration) ScannerOption {
+	return func(s *Scanner) {
+		s.dataTimeout = timeout
+	}
+}
+
+func NewScanner(opts ...ScannerOption) *Scanner {
+	s := &Scanner{
+		dialer: &net.Dialer{
+			Timeout: defaultDialTimeout,
+		},
+		dataTimeout: defaultDataTimeout,
+	}
+	for _, o := range opts {
+		o(s)
+	}
+	return s
+}
+
+func (s *Scanner) Scan(ctx context.Context, r *scan.Request) (scan.Result, error) {
+	result, err := s.scanTarget(ctx, r)
+	return result, scan.WrapError(err)
+}
+
+func (s *Scanner) scanTarget(ctx context.Context, r *scan.Request) (scan.Result, error) {
+	result, err := s.scan(ctx, r, protocolVersion)
+	var serverErr *serverError
+	// servers close the connection after the protocol error, so the fallback version requires a new one
+	if errors.As(err, &serverErr) && serverErr.code == errCodeProtocol {
+		if err = ctx.Err(); err != nil {
+			return nil, err
+		}
+		result, err = s.scan(ctx, r, fallbackProtocolVersion)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (s *Scanner) scan(ctx context.Context, r *scan.Request, version byte) (*ScanResult, error) {
+	addr := net.JoinHostPort(r.DstIP.String(), strconv.Itoa(int(r.DstPort)))
+	conn, err := s.dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	if err = conn.SetDeadline(time.Now().Add(s.dataTimeout)); err != nil {
+		return nil, err
+	}
+
+	resp, err := exchange(conn, optionsRequest(version), opSupported)
+	if err != nil {
+		return nil, err
+	}
+	supported, err := parseSupported(resp.body)
+	if err != nil {
+		return nil, err
+	}
+	result := &ScanResult{
+		ScanType:        ScanType,
+		IP:              r.DstIP.String(),
+		Port:            r.DstPort,
+		ProtocolVersion: int(resp.version),
+		CQLVersions:     supported["CQL_VERSION"],
+	}
+	cqlVersion := defaultCQLVersion
+	if len(result.CQLVersions) > 0 {
+		cqlVersion = result.CQLVersions[0]
+	}
+
+	if _, err = conn.Write(startupRequest(version, cqlVersion)); err != nil {
+		return nil, err
+	}
+	if resp, err = readFrame(conn); err != nil {
+		return nil, err
+	}
+	switch resp.opcode {
+	case opReady:
+		// cluster name is optional, e.g. the anonymous user may not have access to system tables
+		if row, err := s.queryLocal(conn, version); err == nil {
+			result.ClusterName = row["cluster_name"]
+			result.Version = row["release_version"]
+		}
+	case opAuthenticate:
+		result.AuthRequired = true
+		if result.Authenticator, err = parseAuthenticate(resp.body); err != nil {
+			return nil, err
+		}
+	case opError:
+		err = parseError(resp.body)
+		var serverErr *serverError
+		if !errors.As(err, &serverErr) {
+			return nil, err
+		}
+		result.Error = serverErr.Error()
+	default:
+		return nil, fmt.Errorf("%w: opcode %#x", errUnexpectedOp, resp.opcode)
+	}
+	return result, nil
+}
+
+func (*Scanner) queryLocal(conn net.Conn, version byte) (map[string]string, error) {
+	resp, err := exchange(conn, queryRequest(version, localQuery), opResult)
+	if err != nil {
+		return nil, err
+	}
+	return parseRows(resp.body)
+}
+
+// exchange sends the request and reads the response with the expected opcode,
+// ERROR responses are returned as *serverError
+func exchange(conn net.Conn, request []byte, opcode byte) (*frame, error) {
+	if _, err := conn.Write(request); err != nil {
+		return nil, err
+	}
+	resp, err := readFrame(conn)
+	if err != nil {
+		return nil, err
+	}
+	if resp.opcode == opError {
+		return nil, parseError(resp.body)
+	}
+	if resp.opcode != opcode {
+		return nil, fmt.Errorf("%w: opcode %#x", errUnexpectedOp, resp.opcode)
+	}
+	return resp, nil
+}
@@ -0,0 +1,198 @@
+package cassandra
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+type fakeServer struct {
+	// maxVersion is the highest supported protocol version
+	maxVersion    byte
+	authenticator string
+	startupErr    []byte
+}
+
+// readRequest reads the frame sent by the client
+func readRequest(conn net.Conn) (*frame, error) {
+	header := make([]byte, headerSize)
+	if _, err := io.ReadFull(conn, header); err != nil {
+		return nil, err
+	}
+	body := make([]byte, binary.BigEndian.Uint32(header[5:9]))
+	if _, err := io.ReadFull(conn, body); err != nil {
+		return nil, err
+	}
+	return &frame{version: header[0], opcode: header[4], body: body}, nil
+}
+
+func (s *fakeServer) serve(conn net.Conn) {
+	defer conn.Close()
+	for {
+		req, err := readRequest(conn)
+		if err != nil {
+			return
+		}
+		if req.version > s.maxVersion {
+			_, _ = conn.Write(responseFrame(s.maxVersion, opError,
+				errorBody(errCodeProtocol, "Invalid or unsupported protocol version")))
+			return
+		}
+		var resp []byte
+		switch req.opcode {
+		case opOptions:
+			resp = responseFrame(req.version, opSupported, supportedBody("3.4.5"))
+		case opStartup:
+			switch {
+			case s.startupErr != nil:
+				resp = responseFrame(req.version, opError, s.startupErr)
+			case len(s.authenticator) > 0:
+				resp = responseFrame(req.version, opAuthenticate, appendString(nil, s.authenticator))
+			default:
+				resp = responseFrame(req.version, opReady, nil)
+			}
+		case opQuery:
+			resp = responseFrame(req.version, opResult, rowsBody(true,
+				[]string{"cluster_name", "release_version"}, []string{"Test Cluster", "4.1.3"}))
+		default:
+			return
+		}
+		if _, err = conn.Write(resp); err != nil {
+			return
+		}
+	}
+}
+
+func startFakeServer(t *testing.T, srv *fakeServer) *scan.Request {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { l.Close() })
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go srv.serve(conn)
+		}
+	}()
+	addr := l.Addr().(*net.TCPAddr)
+	return &scan.Request{DstIP: addr.IP, DstPort: uint16(addr.Port)}
+}
+
+func TestScan(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		srv      *fakeServer
+		expected *ScanResult
+	}{
+		{
+			name: "NoAuth",
+			srv:  &fakeServer{maxVersion: 4},
+			expected: &ScanResult{
+				ProtocolVersion: 4,
+				CQLVersions:     []string{"3.4.5"},
+				ClusterName:     "Test Cluster",
+				Version:         "4.1.3",
+			},
+		},
+		{
+			name: "Auth",
+			srv:  &fakeServer{maxVersion: 4, authenticator: "org.apache.cassandra.auth.PasswordAuthenticator"},
+			expected: &ScanResult{
+				ProtocolVersion: 4,
+				CQLVersions:     []string{"3.4.5"},
+				AuthRequired:    true,
+				Authenticator:   "org.apache.cassandra.auth.PasswordAuthenticator",
+			},
+		},
+		{
+			name: "FallbackVersion",
+			srv:  &fakeServer{maxVersion: 3},
+			expected: &ScanResult{
+				ProtocolVersion: 3,
+				CQLVersions:     []string{"3.4.5"},
+				ClusterName:     "Test Cluster",
+				Version:         "4.1.3",
+			},
+		},
+		{
+			name: "StartupError",
+			srv:  &fakeServer{maxVersion: 4, startupErr: errorBody(0x0100, "Unauthorized")},
+			expected: &ScanResult{
+				ProtocolVersion: 4,
+				CQLVersions:     []string{"3.4.5"},
+				Error:           "0x100: Unauthorized",
+			},
+		},
+	}
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			req := startFakeServer(t, tt.srv)
+
+			result, err := NewScanner().Scan(context.Background(), req)
+			require.NoError(t, err)
+			expected := *tt.expected
+			expected.ScanType = ScanType
+			expected.IP = req.DstIP.String()
+			expected.Port = req.DstPort
+			require.Equal(t, &expected, result)
+		})
+	}
+}
+
+func TestScanNotCassandraServer(t *testing.T) {
+	t.Parallel()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			_, _ = conn.Write([]byte("SSH-2.0-OpenSSH_8.4p1\r\n"))
+			conn.Close()
+		}
+	}()
+	addr := l.Addr().(*net.TCPAddr)
+
+	result, err := NewScanner().Scan(context.Background(), &scan.Request{DstIP: addr.IP, DstPort: uint16(addr.Port)})
+	require.Error(t, err)
+	require.Nil(t, result)
+}
+
+func TestScanTimeout(t *testing.T) {
+	t.Parallel()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+	done := make(chan interface{})
+	defer close(done)
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		<-done
+	}()
+	addr := l.Addr().(*net.TCPAddr)
+
+	s := NewScanner(WithDataTimeout(50 * time.Millisecond))
+	result, err := s.Scan(context.Background(), &scan.Request{DstIP: addr.IP, DstPort: uint16(addr.Port)})
+	require.Error(t, err)
+	require.ErrorIs(t, err, scan.ErrTimeout)
+	require.Nil(t, result)
+}
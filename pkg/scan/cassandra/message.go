@@ -0,0 +1,248 @@
+package cassandra
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+// CQL binary protocol frames, see native_protocol_v4.spec
+const (
+	headerSize      = 9
+	maxBodySize     = 256 * 1024
+	flagResponse    = 0x80
+	protocolVersion = 4
+	// fallbackProtocolVersion is used if the server doesn't support protocolVersion, e.g. Cassandra 2.1
+	fallbackProtocolVersion = 3
+
+	opError        = 0x00
+	opStartup      = 0x01
+	opReady        = 0x02
+	opAuthenticate = 0x03
+	opOptions      = 0x05
+	opSupported    = 0x06
+	opQuery        = 0x07
+	opResult       = 0x08
+
+	errCodeProtocol   = 0x000a
+	consistencyOne    = 0x0001
+	resultKindRows    = 0x0002
+	rowsGlobalTables  = 0x0001
+	rowsHasMorePages  = 0x0002
+	rowsNoMetadata    = 0x0004
+	defaultCQLVersion = "3.0.0"
+
+	localQuery = "SELECT cluster_name, release_version FROM system.local"
+)
+
+var (
+	errInvalidFrame = scan.NewError(scan.ErrParse, errors.New("invalid CQL frame"))
+	errUnexpectedOp = scan.NewError(scan.ErrParse, errors.New("unexpected CQL response"))
+)
+
+type frame struct {
+	version byte
+	opcode  byte
+	body    []byte
+}
+
+// serverError is the ERROR response of the server
+type serverError struct {
+	code    uint32
+	message string
+}
+
+func (e *serverError) Error() string {
+	return fmt.Sprintf("%#x: %s", e.code, e.message)
+}
+
+func newFrame(version, opcode byte, body []byte) []byte {
+	packet := make([]byte, 0, headerSize+len(body))
+	// flags and stream id
+	packet = append(packet, version, 0, 0, 0, opcode)
+	packet = binary.BigEndian.AppendUint32(packet, uint32(len(body)))
+	return append(packet, body...)
+}
+
+func optionsRequest(version byte) []byte {
+	return newFrame(version, opOptions, nil)
+}
+
+func startupRequest(version byte, cqlVersion string) []byte {
+	body := binary.BigEndian.AppendUint16(nil, 1)
+	body = appendString(body, "CQL_VERSION")
+	body = appendString(body, cqlVersion)
+	return newFrame(version, opStartup, body)
+}
+
+func queryRequest(version byte, query string) []byte {
+	body := binary.BigEndian.AppendUint32(nil, uint32(len(query)))
+	body = append(body, query...)
+	body = binary.BigEndian.AppendUint16(body, consistencyOne)
+	// query flags
+	body = append(body, 0)
+	return newFrame(version, opQuery, body)
+}
+
+func appendString(b []byte, s string) []byte {
+	b = binary.BigEndian.AppendUint16(b, uint16(len(s)))
+	return append(b, s...)
+}
+
+func readFrame(r io.Reader) (*frame, error) {
+	header := make([]byte, headerSize)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, err
+	}
+	if header[0]&flagResponse == 0 {
+		return nil, fmt.Errorf("%w: request direction", errInvalidFrame)
+	}
+	length := binary.BigEndian.Uint32(header[5:9])
+	if length > maxBodySize {
+		return nil, fmt.Errorf("%w: body length %d", errInvalidFrame, length)
+	}
+	body := make([]byte, length)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return nil, err
+	}
+	return &frame{version: header[0] &^ flagResponse, opcode: header[4], body: body}, nil
+}
+
+// reader decodes CQL notation types from the frame body
+type reader struct {
+	data []byte
+	err  error
+}
+
+func (r *reader) next(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if n < 0 || n > len(r.data) {
+		r.err = fmt.Errorf("%w: truncated body", errInvalidFrame)
+		return nil
+	}
+	b := r.data[:n]
+	r.data = r.data[n:]
+	return b
+}
+
+func (r *reader) short() uint16 {
+	if b := r.next(2); b != nil {
+		return binary.BigEndian.Uint16(b)
+	}
+	return 0
+}
+
+func (r *reader) int() int32 {
+	if b := r.next(4); b != nil {
+		return int32(binary.BigEndian.Uint32(b))
+	}
+	return 0
+}
+
+func (r *reader) string() string {
+	return string(r.next(int(r.short())))
+}
+
+// bytes returns nil for the null value
+func (r *reader) bytes() []byte {
+	n := r.int()
+	if n < 0 {
+		return nil
+	}
+	return r.next(int(n))
+}
+
+func (r *reader) stringList() []string {
+	n := int(r.short())
+	var result []string
+	for i := 0; i < n && r.err == nil; i++ {
+		result = append(result, r.string())
+	}
+	return result
+}
+
+func (r *reader) stringMultimap() map[string][]string {
+	n := int(r.short())
+	result := make(map[string][]string, n)
+	for i := 0; i < n && r.err == nil; i++ {
+		key := r.string()
+		result[key] = r.stringList()
+	}
+	return result
+}
+
+// option skips the column type, only primitive types are expected in system.local columns
+func (r *reader) option() {
+	id := r.short()
+	// custom type
+	if id == 0x0000 {
+		r.string()
+	}
+}
+
+func parseError(body []byte) error {
+	r := &reader{data: body}
+	e := &serverError{code: uint32(r.int()), message: r.string()}
+	if r.err != nil {
+		return r.err
+	}
+	return e
+}
+
+func parseSupported(body []byte) (map[string][]string, error) {
+	r := &reader{data: body}
+	result := r.stringMultimap()
+	return result, r.err
+}
+
+func parseAuthenticate(body []byte) (string, error) {
+	r := &reader{data: body}
+	result := r.string()
+	return result, r.err
+}
+
+// parseRows returns the first row of the Rows result as column name to text value map
+func parseRows(body []byte) (map[string]string, error) {
+	r := &reader{data: body}
+	if kind := r.int(); r.err == nil && kind != resultKindRows {
+		return nil, fmt.Errorf("%w: result kind %d", errUnexpectedOp, kind)
+	}
+	flags := r.int()
+	columnCount := int(r.int())
+	if flags&rowsHasMorePages != 0 {
+		r.bytes()
+	}
+	if flags&rowsNoMetadata != 0 {
+		return nil, fmt.Errorf("%w: no metadata", errUnexpectedOp)
+	}
+	if flags&rowsGlobalTables != 0 {
+		r.string()
+		r.string()
+	}
+	var columns []string
+	for i := 0; i < columnCount && r.err == nil; i++ {
+		if flags&rowsGlobalTables == 0 {
+			r.string()
+			r.string()
+		}
+		columns = append(columns, r.string())
+		r.option()
+	}
+	rowCount := r.int()
+	if r.err != nil {
+		return nil, r.err
+	}
+	result := make(map[string]string, columnCount)
+	if rowCount < 1 {
+		return result, nil
+	}
+	for _, column := range columns {
+		result[column] = string(r.bytes())
+	}
+	return result, r.err
+}
@@ -0,0 +1,189 @@
+package cassandra
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func responseFrame(version, opcode byte, body []byte) []byte {
+	return newFrame(version|flagResponse, opcode, body)
+}
+
+func supportedBody(cqlVersions ...string) []byte {
+	body := binary.BigEndian.AppendUint16(nil, 2)
+	body = appendString(body, "CQL_VERSION")
+	body = binary.BigEndian.AppendUint16(body, uint16(len(cqlVersions)))
+	for _, v := range cqlVersions {
+		body = appendString(body, v)
+	}
+	body = appendString(body, "COMPRESSION")
+	body = binary.BigEndian.AppendUint16(body, 2)
+	body = appendString(body, "snappy")
+	return appendString(body, "lz4")
+}
+
+func errorBody(code uint32, message string) []byte {
+	body := binary.BigEndian.AppendUint32(nil, code)
+	return appendString(body, message)
+}
+
+// rowsBody returns the Rows result with varchar columns and one row of values
+func rowsBody(globalTables bool, columns []string, values []string) []byte {
+	body := binary.BigEndian.AppendUint32(nil, resultKindRows)
+	var flags uint32
+	if globalTables {
+		flags = rowsGlobalTables
+	}
+	body = binary.BigEndian.AppendUint32(body, flags)
+	body = binary.BigEndian.AppendUint32(body, uint32(len(columns)))
+	if globalTables {
+		body = appendString(body, "system")
+		body = appendString(body, "local")
+	}
+	for _, column := range columns {
+		if !globalTables {
+			body = appendString(body, "system")
+			body = appendString(body, "local")
+		}
+		body = appendString(body, column)
+		// varchar
+		body = binary.BigEndian.AppendUint16(body, 0x000d)
+	}
+	if values == nil {
+		return binary.BigEndian.AppendUint32(body, 0)
+	}
+	body = binary.BigEndian.AppendUint32(body, 1)
+	for _, value := range values {
+		body = binary.BigEndian.AppendUint32(body, uint32(len(value)))
+		body = append(body, value...)
+	}
+	return body
+}
+
+func TestStartupRequest(t *testing.T) {
+	t.Parallel()
+	require.Equal(t, []byte{
+		0x04, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x16,
+		0x00, 0x01,
+		0x00, 0x0b, 'C', 'Q', 'L', '_', 'V', 'E', 'R', 'S', 'I', 'O', 'N',
+		0x00, 0x05, '3', '.', '0', '.', '0',
+	}, startupRequest(4, "3.0.0"))
+}
+
+func TestQueryRequest(t *testing.T) {
+	t.Parallel()
+	require.Equal(t, []byte{
+		0x03, 0x00, 0x00, 0x00, 0x07, 0x00, 0x00, 0x00, 0x0b,
+		0x00, 0x00, 0x00, 0x04, 'S', 'E', 'L', 'X',
+		0x00, 0x01,
+		0x00,
+	}, queryRequest(3, "SELX"))
+}
+
+func TestReadFrame(t *testing.T) {
+	t.Parallel()
+	f, err := readFrame(bytes.NewReader(responseFrame(4, opReady, nil)))
+	require.NoError(t, err)
+	require.Equal(t, &frame{version: 4, opcode: opReady, body: []byte{}}, f)
+}
+
+func TestReadFrameError(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{
+			name: "Request",
+			data: optionsRequest(4),
+		},
+		{
+			name: "TooLarge",
+			data: []byte{0x84, 0x00, 0x00, 0x00, opResult, 0x7f, 0xff, 0xff, 0xff},
+		},
+	}
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := readFrame(bytes.NewReader(tt.data))
+			require.ErrorIs(t, err, errInvalidFrame)
+		})
+	}
+}
+
+func TestParseSupported(t *testing.T) {
+	t.Parallel()
+	result, err := parseSupported(supportedBody("3.4.5"))
+	require.NoError(t, err)
+	require.Equal(t, map[string][]string{
+		"CQL_VERSION": {"3.4.5"},
+		"COMPRESSION": {"snappy", "lz4"},
+	}, result)
+
+	_, err = parseSupported(supportedBody("3.4.5")[:10])
+	require.ErrorIs(t, err, errInvalidFrame)
+}
+
+func TestParseError(t *testing.T) {
+	t.Parallel()
+	err := parseError(errorBody(errCodeProtocol, "Invalid or unsupported protocol version (4)"))
+	require.Equal(t, &serverError{code: errCodeProtocol, message: "Invalid or unsupported protocol version (4)"}, err)
+	require.Equal(t, "0xa: Invalid or unsupported protocol version (4)", err.Error())
+
+	require.ErrorIs(t, parseError([]byte{0x00}), errInvalidFrame)
+}
+
+func TestParseRows(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		body     []byte
+		expected map[string]string
+	}{
+		{
+			name: "GlobalTables",
+			body: rowsBody(true, []string{"cluster_name", "release_version"}, []string{"Test Cluster", "4.1.3"}),
+			expected: map[string]string{
+				"cluster_name":    "Test Cluster",
+				"release_version": "4.1.3",
+			},
+		},
+		{
+			name: "ColumnTables",
+			body: rowsBody(false, []string{"cluster_name", "release_version"}, []string{"prod", "3.11.16"}),
+			expected: map[string]string{
+				"cluster_name":    "prod",
+				"release_version": "3.11.16",
+			},
+		},
+		{
+			name:     "NoRows",
+			body:     rowsBody(true, []string{"cluster_name"}, nil),
+			expected: map[string]string{},
+		},
+	}
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			result, err := parseRows(tt.body)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestParseRowsError(t *testing.T) {
+	t.Parallel()
+	// Void result
+	_, err := parseRows([]byte{0x00, 0x00, 0x00, 0x01})
+	require.ErrorIs(t, err, errUnexpectedOp)
+
+	body := rowsBody(true, []string{"cluster_name"}, []string{"Test Cluster"})
+	_, err = parseRows(body[:len(body)-4])
+	require.ErrorIs(t, err, errInvalidFrame)
+}
@@ -0,0 +1,193 @@
+package coap
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+const (
+	ScanType = "coap"
+
+	defaultDataTimeout = 2 * time.Second
+	// DefaultMaxBlocks limits the size of resource listings of block-wise responses
+	DefaultMaxBlocks = 16
+	maxPacketSize    = 1500
+	tokenSize        = 4
+)
+
+type ScanResult struct {
+	ScanType string `json:"scan"`
+	IP       string `json:"ip"`
+	Port     uint16 `json:"port"`
+	// Code is the response code of the discovery request, e.g. 2.05 for Content or 4.04 for Not Found
+	Code      string      `json:"code"`
+	Resources []*Resource `json:"resources,omitempty"`
+}
+
+func (r *ScanResult) String() string {
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "%-20s %-5d %s", r.IP, r.Port, r.Code)
+	if len(r.Resources) > 0 {
+		uris := make([]string, 0, len(r.Resources))
+		for _, res := range r.Resources {
+			uris = append(uris, res.URI)
+		}
+		fmt.Fprintf(&buf, " %s", strings.Join(uris, ","))
+	}
+	return buf.String()
+}
+
+func (r *ScanResult) ID() string {
+	return fmt.Sprintf("%s:%d", r.IP, r.Port)
+}
+
+func (r *ScanResult) MarshalJSON() ([]byte, error) {
+	// Type definition for the recursive call
+	type JScanResult ScanResult
+	// This works because JScanResult doesn't have a MarshalJSON function associated with it
+	return json.Marshal(JScanResult(*r))
+}
+
+// Scanner sends the CoAP resource discovery request (GET /.well-known/core) to each target over UDP,
+// servers that answered are reported with their response code and advertised resources
+type Scanner struct {
+	dataTimeout time.Duration
+	maxBlocks   int
+}
+
+// Assert that coap.Scanner conforms to the scan.Scanner interface
+var _ scan.Scanner = (*Scanner)(nil)
+
+type ScannerOption func(*Scanner)
+
+// WithDataTimeout sets the time to wait for each response
+func WithDataTimeout(timeout time.Duration) ScannerOption {
+	return func(s *Scanner) {
+		s.dataTimeout = timeout
+	}
+}
+
+// WithMaxBlocks sets the maximum number of blocks of block-wise responses to read
+func WithMaxBlocks(maxBlocks int) ScannerOption {
+	return func(s *Scanner) {
+		s.maxBlocks = maxBlocks
+	}
+}
+
+func NewScanner(opts ...ScannerOption) *Scanner {
+	s := &Scanner{
+		dataTimeout: defaultDataTimeout,
+		maxBlocks:   DefaultMaxBlocks,
+	}
+	for _, o := range opts {
+		o(s)
+	}
+	return s
+}
+
+func (s *Scanner) Scan(ctx context.Context, r *scan.Request) (scan.Result, error) {
+	result, err := s.scanTarget(ctx, r)
+	return result, scan.WrapError(err)
+}
+
+func (s *Scanner) scanTarget(ctx context.Context, r *scan.Request) (result scan.Result, err error) {
+	addr := net.JoinHostPort(r.DstIP.String(), strconv.Itoa(int(r.DstPort)))
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "udp", addr)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	token := make([]byte, tokenSize)
+	rand.Read(token)
+	messageID := uint16(rand.Uint32())
+	var payload []byte
+	var code uint8
+	var next *block
+	for i := 0; i < s.maxBlocks; i++ {
+		if _, err = conn.Write(discoveryRequest(messageID, token, next)); err != nil {
+			return
+		}
+		var resp *message
+		if resp, err = s.readResponse(conn, messageID, token); err != nil {
+			return
+		}
+		if resp == nil {
+			// blocks after the first one are optional
+			if i == 0 {
+				return nil, nil
+			}
+			break
+		}
+		code = resp.code
+		if format, ok := resp.option(optionContentFormat); ok && parseUint(format) != contentFormatLinkFormat {
+			break
+		}
+		payload = append(payload, resp.payload...)
+		b := resp.block2()
+		if b == nil || !b.more {
+			break
+		}
+		next = &block{num: b.num + 1, szx: b.szx}
+		messageID++
+	}
+	return &ScanResult{
+		ScanType:  ScanType,
+		IP:        r.DstIP.String(),
+		Port:      r.DstPort,
+		Code:      codeString(code),
+		Resources: parseLinkFormat(string(payload)),
+	}, nil
+}
+
+// readResponse returns the piggybacked response of the request or the separate response with its token,
+// separate confirmable responses are acknowledged. It returns nil if there is no response until the timeout
+func (s *Scanner) readResponse(conn net.Conn, messageID uint16, token []byte) (*message, error) {
+	if err := conn.SetReadDeadline(time.Now().Add(s.dataTimeout)); err != nil {
+		return nil, err
+	}
+	buf := make([]byte, maxPacketSize)
+	for {
+		n, err := conn.Read(buf)
+		if isTimeout(err) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		msg, err := parseMessage(buf[:n])
+		if err != nil {
+			continue
+		}
+		switch {
+		case msg.typ == typeACK && msg.messageID == messageID && msg.code == codeEmpty:
+			// the response is sent separately later
+			continue
+		case msg.typ == typeACK && msg.messageID == messageID && bytes.Equal(msg.token, token):
+			return msg, nil
+		case (msg.typ == typeCON || msg.typ == typeNON) && msg.code != codeEmpty && bytes.Equal(msg.token, token):
+			if msg.typ == typeCON {
+				if _, err = conn.Write(emptyACK(msg.messageID)); err != nil {
+					return nil, err
+				}
+			}
+			return msg, nil
+		}
+	}
+}
+
+func isTimeout(err error) bool {
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
@@ -0,0 +1,166 @@
+package coap
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+const testLinks = `</sensors/temp>;rt="temperature-c";if="sensor";obs,</light>;rt="light-lux"`
+
+type fakeServer struct {
+	conn net.PacketConn
+	// separate sends the empty ACK first and the response as a separate confirmable message
+	separate bool
+	// blockSize splits the payload into blocks of the size if it is not zero
+	blockSize int
+	code      uint8
+	payload   string
+	silent    bool
+	// acks receives message IDs of acknowledgements of separate responses
+	acks chan uint16
+}
+
+// startFakeServer starts UDP CoAP server that answers discovery requests
+func startFakeServer(t *testing.T, s *fakeServer) *scan.Request {
+	t.Helper()
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	s.conn = conn
+	s.acks = make(chan uint16, 10)
+	go s.serve()
+	addr := conn.LocalAddr().(*net.UDPAddr)
+	return &scan.Request{DstIP: addr.IP, DstPort: uint16(addr.Port)}
+}
+
+func (s *fakeServer) serve() {
+	buf := make([]byte, maxPacketSize)
+	for {
+		n, addr, err := s.conn.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+		req, err := parseMessage(buf[:n])
+		if err != nil || s.silent {
+			continue
+		}
+		if req.typ == typeACK {
+			s.acks <- req.messageID
+			continue
+		}
+		resp := &message{typ: typeACK, code: s.code, messageID: req.messageID, token: req.token,
+			options: []option{{number: optionContentFormat, value: uintValue(contentFormatLinkFormat)}},
+			payload: []byte(s.payload)}
+		if s.blockSize > 0 {
+			num := uint32(0)
+			if b := req.block2(); b != nil {
+				num = b.num
+			}
+			start := int(num) * s.blockSize
+			end := start + s.blockSize
+			if end > len(s.payload) {
+				end = len(s.payload)
+			}
+			resp.payload = []byte(s.payload[start:end])
+			resp.options = append(resp.options, option{number: optionBlock2,
+				value: uintValue((&block{num: num, more: end < len(s.payload), szx: 0}).value())})
+		}
+		if s.separate {
+			_, _ = s.conn.WriteTo(emptyACK(req.messageID), addr)
+			// unrelated response is skipped by the scanner
+			other := *resp
+			other.typ, other.messageID, other.token = typeCON, 1, []byte{0xff}
+			_, _ = s.conn.WriteTo(other.marshal(), addr)
+			resp.typ, resp.messageID = typeCON, req.messageID+1000
+		}
+		_, _ = s.conn.WriteTo(resp.marshal(), addr)
+	}
+}
+
+func TestScan(t *testing.T) {
+	t.Parallel()
+
+	expectedResources := []*Resource{
+		{URI: "/sensors/temp", ResourceType: "temperature-c", Interface: "sensor", Observable: true},
+		{URI: "/light", ResourceType: "light-lux"},
+	}
+	tests := []struct {
+		name   string
+		server *fakeServer
+	}{
+		{name: "Piggybacked", server: &fakeServer{code: 0x45, payload: testLinks}},
+		{name: "Separate", server: &fakeServer{code: 0x45, payload: testLinks, separate: true}},
+		// blocks of 16 bytes are requested with szx 0
+		{name: "Blockwise", server: &fakeServer{code: 0x45, payload: testLinks, blockSize: 16}},
+	}
+
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			req := startFakeServer(t, tt.server)
+			s := NewScanner(WithDataTimeout(time.Second))
+			result, err := s.Scan(context.Background(), req)
+			require.NoError(t, err)
+			require.Equal(t, &ScanResult{
+				ScanType:  ScanType,
+				IP:        req.DstIP.String(),
+				Port:      req.DstPort,
+				Code:      "2.05",
+				Resources: expectedResources,
+			}, result)
+			if tt.server.separate {
+				select {
+				case <-tt.server.acks:
+				case <-time.After(time.Second):
+					require.Fail(t, "separate response is not acknowledged")
+				}
+			}
+		})
+	}
+}
+
+func TestScanMaxBlocks(t *testing.T) {
+	t.Parallel()
+
+	req := startFakeServer(t, &fakeServer{code: 0x45, payload: testLinks, blockSize: 16})
+	s := NewScanner(WithDataTimeout(time.Second), WithMaxBlocks(1))
+	result, err := s.Scan(context.Background(), req)
+	require.NoError(t, err)
+	require.Equal(t, []*Resource{{URI: "/sensors/temp"}}, result.(*ScanResult).Resources)
+}
+
+func TestScanNotFound(t *testing.T) {
+	t.Parallel()
+
+	req := startFakeServer(t, &fakeServer{code: 0x84})
+	s := NewScanner(WithDataTimeout(time.Second))
+	result, err := s.Scan(context.Background(), req)
+	require.NoError(t, err)
+	require.Equal(t, &ScanResult{ScanType: ScanType, IP: req.DstIP.String(), Port: req.DstPort, Code: "4.04"}, result)
+}
+
+func TestScanNoResponse(t *testing.T) {
+	t.Parallel()
+
+	req := startFakeServer(t, &fakeServer{silent: true})
+	s := NewScanner(WithDataTimeout(50 * time.Millisecond))
+	result, err := s.Scan(context.Background(), req)
+	require.NoError(t, err)
+	require.Nil(t, result)
+}
+
+func TestScanResultString(t *testing.T) {
+	t.Parallel()
+
+	result := &ScanResult{ScanType: ScanType, IP: "192.168.0.1", Port: 5683, Code: "2.05",
+		Resources: []*Resource{{URI: "/sensors/temp"}, {URI: "/light"}}}
+	require.Equal(t, "192.168.0.1          5683  2.05 /sensors/temp,/light", result.String())
+	require.Equal(t, "192.168.0.1:5683", result.ID())
+}
@@ -0,0 +1,76 @@
+package coap
+
+import "strings"
+
+// contentFormatLinkFormat is the application/link-format content format of discovery responses
+const contentFormatLinkFormat = 40
+
+// Resource is the link of the resource advertised by the CoRE link format, see RFC 6690
+type Resource struct {
+	URI string `json:"uri"`
+	// ResourceType is the rt attribute, e.g. temperature-c
+	ResourceType string `json:"rt,omitempty"`
+	// Interface is the if attribute, e.g. sensor
+	Interface string `json:"if,omitempty"`
+	// ContentType is the ct attribute with content formats of the resource
+	ContentType string `json:"ct,omitempty"`
+	Title       string `json:"title,omitempty"`
+	// Observable is true if the resource can be observed for changes
+	Observable bool `json:"obs,omitempty"`
+}
+
+// parseLinkFormat returns links of the link-value list, e.g. </sensors/temp>;rt="temperature-c";if="sensor",</light>,
+// malformed links are skipped
+func parseLinkFormat(payload string) (resources []*Resource) {
+	for _, link := range splitQuoted(payload, ',') {
+		link = strings.TrimSpace(link)
+		end := strings.IndexByte(link, '>')
+		if !strings.HasPrefix(link, "<") || end < 0 {
+			continue
+		}
+		res := &Resource{URI: link[1:end]}
+		for _, param := range splitQuoted(link[end+1:], ';') {
+			name, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+			value = strings.Trim(value, `"`)
+			switch strings.ToLower(name) {
+			case "rt":
+				res.ResourceType = value
+			case "if":
+				res.Interface = value
+			case "ct":
+				res.ContentType = value
+			case "title":
+				res.Title = value
+			case "obs":
+				res.Observable = true
+			}
+		}
+		resources = append(resources, res)
+	}
+	return
+}
+
+// splitQuoted splits s by the separator outside of quoted strings
+func splitQuoted(s string, sep byte) (parts []string) {
+	var quoted bool
+	start := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '"':
+			quoted = !quoted
+		case '\\':
+			if quoted {
+				i++
+			}
+		case sep:
+			if !quoted {
+				parts = append(parts, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	if start < len(s) {
+		parts = append(parts, s[start:])
+	}
+	return
+}
@@ -0,0 +1,52 @@
+package coap
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLinkFormat(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		payload  string
+		expected []*Resource
+	}{
+		{
+			name:    "Attributes",
+			payload: `</sensors/temp>;rt="temperature-c";if="sensor";ct=0;obs,</light>;title="Lamp, kitchen; main"`,
+			expected: []*Resource{
+				{URI: "/sensors/temp", ResourceType: "temperature-c", Interface: "sensor", ContentType: "0", Observable: true},
+				{URI: "/light", Title: "Lamp, kitchen; main"},
+			},
+		},
+		{
+			name:    "Whitespace",
+			payload: "</a>,\n </b>;RT=x",
+			expected: []*Resource{
+				{URI: "/a"},
+				{URI: "/b", ResourceType: "x"},
+			},
+		},
+		{
+			name:     "Malformed",
+			payload:  `sensors,<unterminated,</ok>`,
+			expected: []*Resource{{URI: "/ok"}},
+		},
+		{
+			name:    "Empty",
+			payload: "",
+		},
+	}
+
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			require.Equal(t, tt.expected, parseLinkFormat(tt.payload))
+		})
+	}
+}
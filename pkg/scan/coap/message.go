@@ -0,0 +1,239 @@
+package coap
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+// CoAP message fields, see RFC 7252 section 3
+const (
+	version    = 1
+	headerSize = 4
+	maxToken   = 8
+
+	typeCON = 0
+	typeNON = 1
+	typeACK = 2
+	typeRST = 3
+
+	codeEmpty = 0x00
+	codeGET   = 0x01
+
+	optionURIPath       = 11
+	optionContentFormat = 12
+	// optionBlock2 is the block-wise transfer option of responses, see RFC 7959
+	optionBlock2 = 23
+
+	payloadMarker = 0xff
+
+	// blockSZX is the size exponent of requested blocks: 2^(4+6) = 1024 bytes
+	blockSZX = 6
+)
+
+var errMessage = scan.NewError(scan.ErrParse, errors.New("invalid CoAP message"))
+
+// block is the value of the Block2 option
+type block struct {
+	num  uint32
+	more bool
+	szx  uint8
+}
+
+func (b *block) value() uint32 {
+	v := b.num<<4 | uint32(b.szx)
+	if b.more {
+		v |= 0x8
+	}
+	return v
+}
+
+func parseBlock(v uint32) *block {
+	return &block{num: v >> 4, more: v&0x8 != 0, szx: uint8(v & 0x7)}
+}
+
+type option struct {
+	number uint16
+	value  []byte
+}
+
+type message struct {
+	typ       uint8
+	code      uint8
+	messageID uint16
+	token     []byte
+	options   []option
+	payload   []byte
+}
+
+// codeString returns the code in the c.dd form, e.g. 2.05 for Content
+func codeString(code uint8) string {
+	return fmt.Sprintf("%d.%02d", code>>5, code&0x1f)
+}
+
+// discoveryRequest returns the confirmable GET request of /.well-known/core,
+// the block is requested if it is not nil
+func discoveryRequest(messageID uint16, token []byte, b *block) []byte {
+	msg := &message{
+		typ:       typeCON,
+		code:      codeGET,
+		messageID: messageID,
+		token:     token,
+		options: []option{
+			{number: optionURIPath, value: []byte(".well-known")},
+			{number: optionURIPath, value: []byte("core")},
+		},
+	}
+	if b != nil {
+		msg.options = append(msg.options, option{number: optionBlock2, value: uintValue(b.value())})
+	}
+	return msg.marshal()
+}
+
+// emptyACK returns the acknowledgement of the confirmable message
+func emptyACK(messageID uint16) []byte {
+	return (&message{typ: typeACK, code: codeEmpty, messageID: messageID}).marshal()
+}
+
+// marshal encodes the message, options are sorted by their numbers
+func (m *message) marshal() []byte {
+	data := make([]byte, headerSize, 64)
+	data[0] = version<<6 | m.typ<<4 | uint8(len(m.token))
+	data[1] = m.code
+	binary.BigEndian.PutUint16(data[2:4], m.messageID)
+	data = append(data, m.token...)
+	var prev uint16
+	for _, opt := range m.options {
+		data = appendOption(data, opt.number-prev, opt.value)
+		prev = opt.number
+	}
+	if len(m.payload) > 0 {
+		data = append(data, payloadMarker)
+		data = append(data, m.payload...)
+	}
+	return data
+}
+
+// appendOption appends the option with its delta and length nibbles and their extended bytes
+func appendOption(data []byte, delta uint16, value []byte) []byte {
+	deltaNibble, deltaExt := optionNibble(uint32(delta))
+	lengthNibble, lengthExt := optionNibble(uint32(len(value)))
+	data = append(data, deltaNibble<<4|lengthNibble)
+	data = append(data, deltaExt...)
+	data = append(data, lengthExt...)
+	return append(data, value...)
+}
+
+func optionNibble(v uint32) (nibble uint8, ext []byte) {
+	switch {
+	case v < 13:
+		return uint8(v), nil
+	case v < 269:
+		return 13, []byte{uint8(v - 13)}
+	default:
+		ext = make([]byte, 2)
+		binary.BigEndian.PutUint16(ext, uint16(v-269))
+		return 14, ext
+	}
+}
+
+// uintValue returns the shortest big-endian encoding of the option value
+func uintValue(v uint32) []byte {
+	var data []byte
+	for ; v > 0; v >>= 8 {
+		data = append([]byte{uint8(v)}, data...)
+	}
+	return data
+}
+
+func parseUint(data []byte) (v uint32) {
+	for _, b := range data {
+		v = v<<8 | uint32(b)
+	}
+	return
+}
+
+func parseMessage(data []byte) (*message, error) {
+	if len(data) < headerSize || data[0]>>6 != version {
+		return nil, errMessage
+	}
+	tokenLength := int(data[0] & 0x0f)
+	if tokenLength > maxToken || len(data) < headerSize+tokenLength {
+		return nil, errMessage
+	}
+	msg := &message{
+		typ:       (data[0] >> 4) & 0x3,
+		code:      data[1],
+		messageID: binary.BigEndian.Uint16(data[2:4]),
+		token:     data[headerSize : headerSize+tokenLength],
+	}
+	data = data[headerSize+tokenLength:]
+	var number uint32
+	for len(data) > 0 {
+		if data[0] == payloadMarker {
+			if len(data) == 1 {
+				return nil, errMessage
+			}
+			msg.payload = data[1:]
+			break
+		}
+		delta, length := uint32(data[0]>>4), uint32(data[0]&0x0f)
+		data = data[1:]
+		var err error
+		if delta, data, err = parseExtended(delta, data); err != nil {
+			return nil, err
+		}
+		if length, data, err = parseExtended(length, data); err != nil {
+			return nil, err
+		}
+		if uint32(len(data)) < length {
+			return nil, errMessage
+		}
+		number += delta
+		if number > 0xffff {
+			return nil, errMessage
+		}
+		msg.options = append(msg.options, option{number: uint16(number), value: data[:length]})
+		data = data[length:]
+	}
+	return msg, nil
+}
+
+func parseExtended(nibble uint32, data []byte) (uint32, []byte, error) {
+	switch nibble {
+	case 13:
+		if len(data) < 1 {
+			return 0, nil, errMessage
+		}
+		return uint32(data[0]) + 13, data[1:], nil
+	case 14:
+		if len(data) < 2 {
+			return 0, nil, errMessage
+		}
+		return uint32(binary.BigEndian.Uint16(data)) + 269, data[2:], nil
+	case 15:
+		return 0, nil, errMessage
+	}
+	return nibble, data, nil
+}
+
+// option returns the value of the first option with the number
+func (m *message) option(number uint16) ([]byte, bool) {
+	for _, opt := range m.options {
+		if opt.number == number {
+			return opt.value, true
+		}
+	}
+	return nil, false
+}
+
+// block2 returns the Block2 option of the response, nil if it is not set
+func (m *message) block2() *block {
+	value, ok := m.option(optionBlock2)
+	if !ok || len(value) > 3 {
+		return nil
+	}
+	return parseBlock(parseUint(value))
+}
@@ -0,0 +1,91 @@
+package coap
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiscoveryRequest(t *testing.T) {
+	t.Parallel()
+
+	data := discoveryRequest(0x1234, []byte{1, 2, 3, 4}, nil)
+	expected := append([]byte{0x44, 0x01, 0x12, 0x34, 1, 2, 3, 4, 0xbb}, ".well-known"...)
+	expected = append(expected, 0x04)
+	expected = append(expected, "core"...)
+	require.Equal(t, expected, data)
+
+	data = discoveryRequest(0x1234, []byte{1, 2, 3, 4}, &block{num: 1, szx: blockSZX})
+	require.Equal(t, append(expected, 0xc1, 0x16), data)
+}
+
+func TestMessageMarshalParse(t *testing.T) {
+	t.Parallel()
+
+	msg := &message{
+		typ:       typeACK,
+		code:      0x45,
+		messageID: 7,
+		token:     []byte{0xa, 0xb},
+		options: []option{
+			{number: optionContentFormat, value: uintValue(contentFormatLinkFormat)},
+			{number: optionBlock2, value: uintValue((&block{num: 300, more: true, szx: 2}).value())},
+			// extended delta and length
+			{number: 1000, value: bytes.Repeat([]byte{'x'}, 20)},
+			{number: 2000, value: bytes.Repeat([]byte{'y'}, 300)},
+		},
+		payload: []byte("</sensors>"),
+	}
+
+	parsed, err := parseMessage(msg.marshal())
+	require.NoError(t, err)
+	require.Equal(t, msg, parsed)
+	require.Equal(t, &block{num: 300, more: true, szx: 2}, parsed.block2())
+	value, ok := parsed.option(optionContentFormat)
+	require.True(t, ok)
+	require.Equal(t, uint32(contentFormatLinkFormat), parseUint(value))
+	require.Equal(t, "2.05", codeString(parsed.code))
+}
+
+func TestEmptyACK(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, []byte{0x60, 0x00, 0x00, 0x09}, emptyACK(9))
+}
+
+func TestParseMessageInvalid(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{name: "Short", data: []byte{0x40, 0x01}},
+		{name: "Version", data: []byte{0x80, 0x01, 0x00, 0x01}},
+		{name: "TokenLength", data: []byte{0x49, 0x01, 0x00, 0x01}},
+		{name: "ShortToken", data: []byte{0x44, 0x01, 0x00, 0x01, 0x01}},
+		{name: "ShortOption", data: []byte{0x40, 0x01, 0x00, 0x01, 0xb5, 'c'}},
+		{name: "ShortExtendedDelta", data: []byte{0x40, 0x01, 0x00, 0x01, 0xd0}},
+		{name: "ReservedDelta", data: []byte{0x40, 0x01, 0x00, 0x01, 0xf0}},
+		{name: "EmptyPayload", data: []byte{0x40, 0x01, 0x00, 0x01, 0xff}},
+	}
+
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := parseMessage(tt.data)
+			require.ErrorIs(t, err, errMessage)
+		})
+	}
+}
+
+func TestCodeString(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, "2.05", codeString(0x45))
+	require.Equal(t, "4.04", codeString(0x84))
+	require.Equal(t, "5.03", codeString(0xa3))
+}
@@ -0,0 +1,186 @@
+package couchdb
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+const (
+	ScanType = "couchdb"
+
+	defaultDataTimeout = 5 * time.Second
+
+	// maxResponseSize limits the size of read responses, database lists of big servers fit in it
+	maxResponseSize = 1 << 20
+	// adminRole is the role of server admins, anonymous users have it if there are no admins ("admin party")
+	adminRole = "_admin"
+)
+
+var (
+	errNotCouchDB = errors.New("not a CouchDB server")
+	errStatus     = errors.New("unexpected HTTP status")
+)
+
+type ScanResult struct {
+	ScanType string   `json:"scan"`
+	Proto    string   `json:"proto"`
+	Host     string   `json:"host"`
+	Version  string   `json:"version"`
+	Vendor   string   `json:"vendor,omitempty"`
+	Features []string `json:"features,omitempty"`
+	// AdminParty is true if anonymous users are server admins, CouchDB before 3.0 starts without admins
+	AdminParty bool `json:"admin_party"`
+	// DatabasesReadable is true if the database list is read without credentials
+	DatabasesReadable bool     `json:"dbs_readable"`
+	Databases         []string `json:"dbs,omitempty"`
+}
+
+func (r *ScanResult) String() string {
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "%s://%s %s", r.Proto, r.Host, r.Version)
+	if r.AdminParty {
+		buf.WriteString(" admin-party")
+	}
+	if r.DatabasesReadable {
+		fmt.Fprintf(&buf, " dbs:%d", len(r.Databases))
+	}
+	return buf.String()
+}
+
+func (r *ScanResult) ID() string {
+	return r.Host
+}
+
+func (r *ScanResult) MarshalJSON() ([]byte, error) {
+	// Type definition for the recursive call
+	type JScanResult ScanResult
+	// This works because JScanResult doesn't have a MarshalJSON function associated with it
+	return json.Marshal(JScanResult(*r))
+}
+
+type Scanner struct {
+	client      *http.Client
+	proto       string
+	dataTimeout time.Duration
+}
+
+// Assert that couchdb.Scanner conforms to the scan.Scanner interface
+var _ scan.Scanner = (*Scanner)(nil)
+
+type ScannerOption func(*Scanner)
+
+func WithDataTimeout(timeout time.Duration) ScannerOption {
+	return func(s *Scanner) {
+		s.dataTimeout = timeout
+	}
+}
+
+func NewScanner(proto string, opts ...ScannerOption) *Scanner {
+	tr := &http.Transport{
+		MaxConnsPerHost:   1,
+		DisableKeepAlives: true,
+		TLSClientConfig: &tls.Config{
+			InsecureSkipVerify: true,
+		},
+	}
+	s := &Scanner{
+		client:      &http.Client{Transport: tr},
+		proto:       proto,
+		dataTimeout: defaultDataTimeout,
+	}
+	for _, o := range opts {
+		o(s)
+	}
+	return s
+}
+
+// Scan requests the welcome message of the CouchDB server, the session and the database list without credentials
+func (s *Scanner) Scan(ctx context.Context, r *scan.Request) (scan.Result, error) {
+	result, err := s.scanTarget(ctx, r)
+	return result, scan.WrapError(err)
+}
+
+func (s *Scanner) scanTarget(ctx context.Context, r *scan.Request) (result scan.Result, err error) {
+	host := fmt.Sprintf("%s:%d", r.DstIP.String(), r.DstPort)
+	baseURL := fmt.Sprintf("%s://%s", s.proto, host)
+
+	var welcome struct {
+		CouchDB  string   `json:"couchdb"`
+		Version  string   `json:"version"`
+		Features []string `json:"features"`
+		Vendor   struct {
+			Name string `json:"name"`
+		} `json:"vendor"`
+	}
+	// fields of other types are skipped by the decoder, other servers are told apart by the welcome message
+	var typeErr *json.UnmarshalTypeError
+	if _, err = s.do(ctx, baseURL+"/", &welcome); err != nil && !errors.As(err, &typeErr) {
+		return
+	}
+	if welcome.CouchDB != "Welcome" {
+		return nil, errNotCouchDB
+	}
+	res := &ScanResult{
+		ScanType: ScanType,
+		Proto:    s.proto,
+		Host:     host,
+		Version:  welcome.Version,
+		Vendor:   welcome.Vendor.Name,
+		Features: welcome.Features,
+	}
+
+	// only the error of the welcome request is returned, other checks depend on the configuration
+	var session struct {
+		UserCtx struct {
+			Roles []string `json:"roles"`
+		} `json:"userCtx"`
+	}
+	if status, err := s.do(ctx, baseURL+"/_session", &session); err == nil && status == http.StatusOK {
+		for _, role := range session.UserCtx.Roles {
+			if role == adminRole {
+				res.AdminParty = true
+			}
+		}
+	}
+	var dbs []string
+	if status, err := s.do(ctx, baseURL+"/_all_dbs", &dbs); err == nil && status == http.StatusOK {
+		res.DatabasesReadable = true
+		res.Databases = dbs
+	}
+	return res, nil
+}
+
+// do sends the GET request and decodes the JSON response body into data,
+// responses with statuses other than 2xx, 401 and 403 are errors
+func (s *Scanner) do(ctx context.Context, url string, data interface{}) (status int, err error) {
+	ctx, cancel := context.WithTimeout(ctx, s.dataTimeout)
+	defer cancel()
+	var req *http.Request
+	if req, err = http.NewRequestWithContext(ctx, http.MethodGet, url, nil); err != nil {
+		return
+	}
+	req.Header.Set("Accept", "application/json")
+	var resp *http.Response
+	if resp, err = s.client.Do(req); err != nil {
+		return
+	}
+	defer resp.Body.Close()
+	status = resp.StatusCode
+	switch {
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
+		return
+	case status < 200 || status >= 300:
+		return status, fmt.Errorf("%w: %s", errStatus, resp.Status)
+	}
+	err = json.NewDecoder(io.LimitReader(resp.Body, maxResponseSize)).Decode(data)
+	return
+}
@@ -0,0 +1,134 @@
+package couchdb
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+type fakeServer struct {
+	// adminParty means there are no server admins, anonymous users are admins
+	adminParty bool
+	// publicDBs means the database list is readable by anyone like in CouchDB before 3.0
+	publicDBs bool
+}
+
+func (s *fakeServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	switch r.URL.Path {
+	case "/":
+		fmt.Fprint(w, `{"couchdb":"Welcome","version":"2.3.1","git_sha":"c298091a4",`+
+			`"uuid":"a9b5f2d1","features":["pluggable-storage-engines","scheduler"],`+
+			`"vendor":{"name":"The Apache Software Foundation"}}`)
+	case "/_session":
+		roles := `[]`
+		if s.adminParty {
+			roles = `["_admin"]`
+		}
+		fmt.Fprintf(w, `{"ok":true,"userCtx":{"name":null,"roles":%s},"info":{"authenticated":"default"}}`, roles)
+	case "/_all_dbs":
+		if !s.adminParty && !s.publicDBs {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"error":"unauthorized","reason":"You are not a server admin."}`)
+			return
+		}
+		fmt.Fprint(w, `["_replicator","_users","customers"]`)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func startServer(t *testing.T, h http.Handler) (*scan.Request, string) {
+	t.Helper()
+	srv := httptest.NewServer(h)
+	t.Cleanup(srv.Close)
+	addr := srv.Listener.Addr().(*net.TCPAddr)
+	return &scan.Request{DstIP: addr.IP, DstPort: uint16(addr.Port)}, addr.String()
+}
+
+func TestScan(t *testing.T) {
+	t.Parallel()
+	dbs := []string{"_replicator", "_users", "customers"}
+	tests := []struct {
+		name     string
+		srv      *fakeServer
+		expected ScanResult
+	}{
+		{
+			name:     "AdminParty",
+			srv:      &fakeServer{adminParty: true},
+			expected: ScanResult{AdminParty: true, DatabasesReadable: true, Databases: dbs},
+		},
+		{
+			name:     "PublicDatabases",
+			srv:      &fakeServer{publicDBs: true},
+			expected: ScanResult{DatabasesReadable: true, Databases: dbs},
+		},
+		{
+			name:     "Auth",
+			srv:      &fakeServer{},
+			expected: ScanResult{},
+		},
+	}
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			req, host := startServer(t, tt.srv)
+			result, err := NewScanner("http").Scan(context.Background(), req)
+			require.NoError(t, err)
+
+			expected := tt.expected
+			expected.ScanType = ScanType
+			expected.Proto = "http"
+			expected.Host = host
+			expected.Version = "2.3.1"
+			expected.Vendor = "The Apache Software Foundation"
+			expected.Features = []string{"pluggable-storage-engines", "scheduler"}
+			require.Equal(t, &expected, result)
+		})
+	}
+}
+
+func TestScanNotCouchDBServer(t *testing.T) {
+	t.Parallel()
+	req, _ := startServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/" {
+			fmt.Fprint(w, `{"name":"node-1","version":{"number":"7.10.2"}}`)
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	_, err := NewScanner("http").Scan(context.Background(), req)
+	require.ErrorIs(t, err, errNotCouchDB)
+
+	req, _ = startServer(t, http.NotFoundHandler())
+	_, err = NewScanner("http").Scan(context.Background(), req)
+	require.ErrorIs(t, err, errStatus)
+}
+
+func TestScanTimeout(t *testing.T) {
+	t.Parallel()
+	done := make(chan struct{})
+	defer close(done)
+	req, _ := startServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-done
+	}))
+	_, err := NewScanner("http", WithDataTimeout(100*time.Millisecond)).Scan(context.Background(), req)
+	require.Error(t, err)
+}
+
+func TestScanResultString(t *testing.T) {
+	t.Parallel()
+	result := &ScanResult{ScanType: ScanType, Proto: "http", Host: "192.168.0.1:5984", Version: "2.3.1",
+		AdminParty: true, DatabasesReadable: true, Databases: []string{"_replicator", "_users"}}
+	require.Equal(t, "http://192.168.0.1:5984 2.3.1 admin-party dbs:2", result.String())
+	require.Equal(t, "192.168.0.1:5984", result.ID())
+}
@@ -0,0 +1,110 @@
+package scan
+
+import (
+	"context"
+	"encoding/binary"
+	"hash/fnv"
+	"net"
+	"sync/atomic"
+)
+
+type multiRequestGenerator struct {
+	delegates []RequestGenerator
+}
+
+// NewMultiRequestGenerator creates a generator that combines requests of several inputs,
+// requests of each delegate are generated after requests of the previous one
+func NewMultiRequestGenerator(delegates ...RequestGenerator) RequestGenerator {
+	return &multiRequestGenerator{delegates}
+}
+
+func (rg *multiRequestGenerator) GenerateRequests(ctx context.Context, r *Range) (<-chan *Request, error) {
+	inputs := make([]<-chan *Request, 0, len(rg.delegates))
+	for _, delegate := range rg.delegates {
+		requests, err := delegate.GenerateRequests(ctx, r)
+		if err != nil {
+			return nil, err
+		}
+		inputs = append(inputs, requests)
+	}
+	out := make(chan *Request, 1024)
+	go func() {
+		defer close(out)
+		for _, requests := range inputs {
+			for {
+				request, ok := readRequest(ctx, requests)
+				if !ok {
+					break
+				}
+				writeRequest(ctx, out, request)
+			}
+			if ctx.Err() != nil {
+				return
+			}
+		}
+	}()
+	return out, nil
+}
+
+// DedupRequestGenerator skips requests with the same destination ip and port as one of the previous requests.
+// Memory is bounded by the maximum number of remembered targets, once it is reached new targets
+// are not remembered and their duplicates are scanned again.
+type DedupRequestGenerator struct {
+	delegate   RequestGenerator
+	maxTargets int
+	skipped    int64
+}
+
+func NewDedupRequestGenerator(delegate RequestGenerator, maxTargets int) *DedupRequestGenerator {
+	return &DedupRequestGenerator{delegate: delegate, maxTargets: maxTargets}
+}
+
+func (rg *DedupRequestGenerator) GenerateRequests(ctx context.Context, r *Range) (<-chan *Request, error) {
+	requests, err := rg.delegate.GenerateRequests(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	out := make(chan *Request, cap(requests))
+	go func() {
+		defer close(out)
+		seen := make(map[uint64]struct{})
+		var request *Request
+		var ok bool
+		for {
+			if request, ok = readRequest(ctx, requests); !ok {
+				return
+			}
+			if request.Err != nil {
+				writeRequest(ctx, out, request)
+				continue
+			}
+			key := targetKey(request.DstIP, request.DstPort)
+			if _, ok = seen[key]; ok {
+				atomic.AddInt64(&rg.skipped, 1)
+				continue
+			}
+			if len(seen) < rg.maxTargets {
+				seen[key] = struct{}{}
+			}
+			writeRequest(ctx, out, request)
+		}
+	}()
+	return out, nil
+}
+
+// Skipped returns the number of skipped duplicate requests
+func (rg *DedupRequestGenerator) Skipped() int64 {
+	return atomic.LoadInt64(&rg.skipped)
+}
+
+// targetKey packs IPv4 targets into the lower 48 bits exactly, IPv6 targets are hashed with the top bit set,
+// so that 8 bytes per target are remembered regardless of the address family
+func targetKey(ip net.IP, port uint16) uint64 {
+	if ip4 := ip.To4(); ip4 != nil {
+		return uint64(binary.BigEndian.Uint32(ip4))<<16 | uint64(port)
+	}
+	h := fnv.New64a()
+	_, _ = h.Write(ip.To16())
+	_, _ = h.Write([]byte{byte(port >> 8), byte(port)})
+	return h.Sum64() | 1<<63
+}
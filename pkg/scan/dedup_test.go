@@ -0,0 +1,171 @@
+package scan
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func requestChan(requests ...*Request) <-chan *Request {
+	out := make(chan *Request, len(requests))
+	for _, request := range requests {
+		out <- request
+	}
+	close(out)
+	return out
+}
+
+func TestMultiRequestGenerator(t *testing.T) {
+	t.Parallel()
+
+	done := make(chan interface{})
+	go func() {
+		defer close(done)
+
+		ctrl := gomock.NewController(t)
+		first := NewMockRequestGenerator(ctrl)
+		second := NewMockRequestGenerator(ctrl)
+		r := newScanRange()
+
+		first.EXPECT().GenerateRequests(gomock.Not(gomock.Nil()), r).Return(requestChan(
+			newScanRequest(withDstIP(net.IPv4(10, 0, 1, 1).To4()), withDstPort(22)),
+			newScanRequest(withDstIP(net.IPv4(10, 0, 1, 2).To4()), withDstPort(22)),
+		), nil)
+		second.EXPECT().GenerateRequests(gomock.Not(gomock.Nil()), r).Return(requestChan(
+			newScanRequest(withDstIP(net.IPv4(10, 0, 2, 1).To4()), withDstPort(80)),
+		), nil)
+
+		requests, err := NewMultiRequestGenerator(first, second).GenerateRequests(context.Background(), r)
+		require.NoError(t, err)
+		result := chanToSlice(t, chanPairToGeneric(requests), 3)
+		require.Equal(t, []interface{}{
+			newScanRequest(withDstIP(net.IPv4(10, 0, 1, 1).To4()), withDstPort(22)),
+			newScanRequest(withDstIP(net.IPv4(10, 0, 1, 2).To4()), withDstPort(22)),
+			newScanRequest(withDstIP(net.IPv4(10, 0, 2, 1).To4()), withDstPort(80)),
+		}, result)
+	}()
+	waitDone(t, done)
+}
+
+func TestMultiRequestGeneratorWithGeneratorError(t *testing.T) {
+	t.Parallel()
+
+	done := make(chan interface{})
+	go func() {
+		defer close(done)
+
+		ctrl := gomock.NewController(t)
+		first := NewMockRequestGenerator(ctrl)
+		second := NewMockRequestGenerator(ctrl)
+		r := newScanRange()
+
+		first.EXPECT().GenerateRequests(gomock.Not(gomock.Nil()), r).Return(requestChan(), nil)
+		second.EXPECT().GenerateRequests(gomock.Not(gomock.Nil()), r).Return(nil, errors.New("generate error"))
+
+		_, err := NewMultiRequestGenerator(first, second).GenerateRequests(context.Background(), r)
+		require.Error(t, err)
+	}()
+	waitDone(t, done)
+}
+
+func TestDedupRequestGenerator(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		maxTargets int
+		input      []*Request
+		expected   []interface{}
+		skipped    int64
+	}{
+		{
+			name:       "NoDuplicates",
+			maxTargets: 10,
+			input: []*Request{
+				newScanRequest(withDstIP(net.IPv4(10, 0, 1, 1).To4()), withDstPort(22)),
+				newScanRequest(withDstIP(net.IPv4(10, 0, 1, 1).To4()), withDstPort(80)),
+				newScanRequest(withDstIP(net.IPv4(10, 0, 1, 2).To4()), withDstPort(22)),
+			},
+			expected: []interface{}{
+				newScanRequest(withDstIP(net.IPv4(10, 0, 1, 1).To4()), withDstPort(22)),
+				newScanRequest(withDstIP(net.IPv4(10, 0, 1, 1).To4()), withDstPort(80)),
+				newScanRequest(withDstIP(net.IPv4(10, 0, 1, 2).To4()), withDstPort(22)),
+			},
+		},
+		{
+			name:       "Duplicates",
+			maxTargets: 10,
+			input: []*Request{
+				newScanRequest(withDstIP(net.IPv4(10, 0, 1, 1).To4()), withDstPort(22)),
+				newScanRequest(withDstIP(net.IPv4(10, 0, 1, 2).To4()), withDstPort(22)),
+				newScanRequest(withDstIP(net.IPv4(10, 0, 1, 1)), withDstPort(22)),
+				newScanRequest(withDstIP(net.ParseIP("2001:db8::1")), withDstPort(443)),
+				newScanRequest(withDstIP(net.ParseIP("2001:db8::1")), withDstPort(443)),
+			},
+			expected: []interface{}{
+				newScanRequest(withDstIP(net.IPv4(10, 0, 1, 1).To4()), withDstPort(22)),
+				newScanRequest(withDstIP(net.IPv4(10, 0, 1, 2).To4()), withDstPort(22)),
+				newScanRequest(withDstIP(net.ParseIP("2001:db8::1")), withDstPort(443)),
+			},
+			skipped: 2,
+		},
+		{
+			name:       "MaxTargets",
+			maxTargets: 1,
+			input: []*Request{
+				newScanRequest(withDstIP(net.IPv4(10, 0, 1, 1).To4()), withDstPort(22)),
+				newScanRequest(withDstIP(net.IPv4(10, 0, 1, 2).To4()), withDstPort(22)),
+				newScanRequest(withDstIP(net.IPv4(10, 0, 1, 1).To4()), withDstPort(22)),
+				newScanRequest(withDstIP(net.IPv4(10, 0, 1, 2).To4()), withDstPort(22)),
+			},
+			expected: []interface{}{
+				newScanRequest(withDstIP(net.IPv4(10, 0, 1, 1).To4()), withDstPort(22)),
+				newScanRequest(withDstIP(net.IPv4(10, 0, 1, 2).To4()), withDstPort(22)),
+				newScanRequest(withDstIP(net.IPv4(10, 0, 1, 2).To4()), withDstPort(22)),
+			},
+			skipped: 1,
+		},
+		{
+			name:       "Errors",
+			maxTargets: 10,
+			input: []*Request{
+				{Err: ErrIP},
+				{Err: ErrIP},
+			},
+			expected: []interface{}{
+				&Request{Err: ErrIP},
+				&Request{Err: ErrIP},
+			},
+		},
+	}
+
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			done := make(chan interface{})
+			go func() {
+				defer close(done)
+
+				ctrl := gomock.NewController(t)
+				delegate := NewMockRequestGenerator(ctrl)
+				r := newScanRange()
+				delegate.EXPECT().GenerateRequests(gomock.Not(gomock.Nil()), r).
+					Return(requestChan(tt.input...), nil)
+
+				reqgen := NewDedupRequestGenerator(delegate, tt.maxTargets)
+				requests, err := reqgen.GenerateRequests(context.Background(), r)
+				require.NoError(t, err)
+				result := chanToSlice(t, chanPairToGeneric(requests), len(tt.expected))
+				require.Equal(t, tt.expected, result)
+				require.Equal(t, tt.skipped, reqgen.Skipped())
+			}()
+			waitDone(t, done)
+		})
+	}
+}
@@ -0,0 +1,307 @@
+// Package detect labels services of open TCP ports with a decision tree of TLS, HTTP and banner probes
+package detect
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+const (
+	ScanType = "detect"
+
+	defaultDialTimeout   = 2 * time.Second
+	defaultDataTimeout   = 2 * time.Second
+	defaultBannerTimeout = 1 * time.Second
+
+	maxResponseSize = 4096
+	maxBannerSize   = 128
+)
+
+// services labeled by the scanner
+const (
+	ServiceSSH     = "ssh"
+	ServiceFTP     = "ftp"
+	ServiceSMTP    = "smtp"
+	ServicePOP3    = "pop3"
+	ServiceIMAP    = "imap"
+	ServiceMySQL   = "mysql"
+	ServiceVNC     = "vnc"
+	ServiceHTTP    = "http"
+	ServiceHTTPS   = "https"
+	ServiceTLS     = "tls"
+	ServiceUnknown = "unknown"
+)
+
+const (
+	tlsRecordAlert     = 0x15
+	tlsRecordHandshake = 0x16
+	mysqlProtocol10    = 0x0a
+	mysqlErrPacket     = 0xff
+)
+
+type ScanResult struct {
+	ScanType string `json:"scan"`
+	IP       string `json:"ip"`
+	Port     uint16 `json:"port"`
+	Service  string `json:"service"`
+	// TLS is set if the service answered the TLS handshake
+	TLS bool `json:"tls"`
+	// Banner is the first line of the server greeting or the HTTP status line, non-printable characters are replaced with dots
+	Banner string `json:"banner,omitempty"`
+}
+
+func (r *ScanResult) String() string {
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "%-20s %-5d %-7s", r.IP, r.Port, r.Service)
+	if len(r.Banner) > 0 {
+		fmt.Fprintf(&buf, " %q", r.Banner)
+	}
+	return buf.String()
+}
+
+func (r *ScanResult) ID() string {
+	return fmt.Sprintf("%s:%d", r.IP, r.Port)
+}
+
+func (r *ScanResult) MarshalJSON() ([]byte, error) {
+	// Type definition for the recursive call
+	type JScanResult ScanResult
+	// This works because JScanResult doesn't have a MarshalJSON function associated with it
+	return json.Marshal(JScanResult(*r))
+}
+
+// Scanner labels the service of each target over one or two connections. The greeting of server-first
+// protocols is read first, silent servers are sent the TLS handshake on the same connection and the HTTP
+// request over it. The plain HTTP request is sent over the second connection if the TLS handshake wasn't answered.
+type Scanner struct {
+	dialer        *net.Dialer
+	dataTimeout   time.Duration
+	bannerTimeout time.Duration
+}
+
+// Assert that detect.Scanner conforms to the scan.Scanner interface
+var _ scan.Scanner = (*Scanner)(nil)
+
+type ScannerOption func(*Scanner)
+
+func WithDialTimeout(timeout time.Duration) ScannerOption {
+	return func(s *Scanner) {
+		s.dialer.Timeout = timeout
+	}
+}
+
+func WithDataTimeout(timeout time.Duration) ScannerOption {
+	return func(s *Scanner) {
+		s.dataTimeout = timeout
+	}
+}
+
+// WithBannerTimeout sets the time to wait for the greeting of server-first protocols
+// before the server is considered silent
+func WithBannerTimeout(timeout time.Duration) ScannerOption {
+	return func(s *Scanner) {
+		s.bannerTimeout = timeout
+	}
+}
+
+func NewScanner(opts ...ScannerOption) *Scanner {
+	s := &Scanner{
+		dialer: &net.Dialer{
+			Timeout: defaultDialTimeout,
+		},
+		dataTimeout:   defaultDataTimeout,
+		bannerTimeout: defaultBannerTimeout,
+	}
+	for _, o := range opts {
+		o(s)
+	}
+	return s
+}
+
+func (s *Scanner) Scan(ctx context.Context, r *scan.Request) (scan.Result, error) {
+	result, err := s.scanTarget(ctx, r)
+	return result, scan.WrapError(err)
+}
+
+func (s *Scanner) scanTarget(ctx context.Context, r *scan.Request) (scan.Result, error) {
+	addr := net.JoinHostPort(r.DstIP.String(), strconv.Itoa(int(r.DstPort)))
+	service, tlsOK, banner, err := s.detect(ctx, addr)
+	if err != nil {
+		return nil, err
+	}
+	return &ScanResult{
+		ScanType: ScanType,
+		IP:       r.DstIP.String(),
+		Port:     r.DstPort,
+		Service:  service,
+		TLS:      tlsOK,
+		Banner:   banner,
+	}, nil
+}
+
+func (s *Scanner) detect(ctx context.Context, addr string) (service string, tlsOK bool, banner string, err error) {
+	conn, err := s.dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	// server-first protocols
+	greeting, err := readResponse(conn, s.bannerTimeout)
+	if err != nil {
+		return
+	}
+	if len(greeting) > 0 {
+		service, banner = classify(greeting)
+		return
+	}
+
+	// silent server, the TLS handshake is sent on the same connection
+	if err = conn.SetDeadline(time.Now().Add(s.dataTimeout)); err != nil {
+		return
+	}
+	rec := &recordingConn{Conn: conn}
+	tlsConn := tls.Client(rec, &tls.Config{
+		InsecureSkipVerify: true,
+	})
+	if herr := tlsConn.HandshakeContext(ctx); herr == nil {
+		tlsOK = true
+		service = ServiceTLS
+		if status := httpStatus(tlsConn, s.dataTimeout, addr); len(status) > 0 {
+			service, banner = ServiceHTTPS, status
+		}
+		return
+	}
+	switch reply := rec.buf.Bytes(); {
+	case len(reply) > 0 && (reply[0] == tlsRecordAlert || reply[0] == tlsRecordHandshake):
+		// TLS server that rejected the handshake, e.g. without shared ciphers
+		return ServiceTLS, true, "", nil
+	case len(reply) > 0:
+		// plain text servers usually answer garbage with an error, e.g. HTTP 400
+		service, banner = classify(reply)
+		return
+	}
+	if err = ctx.Err(); err != nil {
+		return
+	}
+
+	// the plain HTTP request is sent over the second connection
+	conn2, err := s.dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return
+	}
+	defer conn2.Close()
+	if status := httpStatus(conn2, s.dataTimeout, addr); len(status) > 0 {
+		return ServiceHTTP, false, status, nil
+	}
+	return ServiceUnknown, false, "", nil
+}
+
+// readResponse reads the first data of the server, no data is returned if it doesn't send anything within the timeout
+func readResponse(conn net.Conn, timeout time.Duration) ([]byte, error) {
+	if err := conn.SetReadDeadline(time.Now().Add(timeout)); err != nil {
+		return nil, err
+	}
+	buf := make([]byte, maxResponseSize)
+	n, err := conn.Read(buf)
+	var netErr net.Error
+	if n > 0 || err == io.EOF || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return buf[:n], nil
+	}
+	return nil, err
+}
+
+// httpStatus sends the HTTP request and returns the status line of the response,
+// the empty status is returned if the response is not HTTP
+func httpStatus(conn net.Conn, timeout time.Duration, host string) string {
+	if err := conn.SetDeadline(time.Now().Add(timeout)); err != nil {
+		return ""
+	}
+	if _, err := fmt.Fprintf(conn, "GET / HTTP/1.0\r\nHost: %s\r\nAccept: */*\r\n\r\n", host); err != nil {
+		return ""
+	}
+	resp, err := readResponse(conn, timeout)
+	if err != nil || !bytes.HasPrefix(resp, []byte("HTTP/")) {
+		return ""
+	}
+	return bannerLine(resp)
+}
+
+// classify labels the service by the first data it sent
+func classify(data []byte) (service, banner string) {
+	banner = bannerLine(data)
+	switch {
+	case bytes.HasPrefix(data, []byte("SSH-")):
+		return ServiceSSH, banner
+	case bytes.HasPrefix(data, []byte("HTTP/")):
+		return ServiceHTTP, banner
+	case bytes.HasPrefix(data, []byte("220")):
+		upper := strings.ToUpper(banner)
+		if strings.Contains(upper, "SMTP") || strings.Contains(upper, "MAIL") {
+			return ServiceSMTP, banner
+		}
+		return ServiceFTP, banner
+	case bytes.HasPrefix(data, []byte("+OK")):
+		return ServicePOP3, banner
+	case bytes.HasPrefix(data, []byte("* OK")):
+		return ServiceIMAP, banner
+	case bytes.HasPrefix(data, []byte("RFB ")):
+		return ServiceVNC, banner
+	}
+	// MySQL packets start with the 3-byte length and the sequence number 0
+	if len(data) > 5 && data[3] == 0 && int(data[0])|int(data[1])<<8|int(data[2])<<16 == len(data)-4 {
+		switch data[4] {
+		case mysqlProtocol10:
+			version := data[5:]
+			if end := bytes.IndexByte(version, 0); end != -1 {
+				version = version[:end]
+			}
+			return ServiceMySQL, bannerLine(version)
+		case mysqlErrPacket:
+			return ServiceMySQL, banner
+		}
+	}
+	return ServiceUnknown, banner
+}
+
+// bannerLine returns the first line of the data with non-printable characters replaced with dots
+func bannerLine(data []byte) string {
+	if end := bytes.IndexAny(data, "\r\n"); end != -1 {
+		data = data[:end]
+	}
+	if len(data) > maxBannerSize {
+		data = data[:maxBannerSize]
+	}
+	return strings.Map(func(r rune) rune {
+		if r > unicode.MaxASCII || !unicode.IsPrint(r) {
+			return '.'
+		}
+		return r
+	}, string(data))
+}
+
+// recordingConn records data read from the connection
+type recordingConn struct {
+	net.Conn
+	buf bytes.Buffer
+}
+
+func (c *recordingConn) Read(b []byte) (int, error) {
+	n, err := c.Conn.Read(b)
+	if c.buf.Len() < maxResponseSize {
+		c.buf.Write(b[:n])
+	}
+	return n, err
+}
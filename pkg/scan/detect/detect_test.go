@@ -0,0 +1,187 @@
+package detect
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+// startServer starts TCP server that handles each connection with the number of the connection
+func startServer(t *testing.T, handle func(conn net.Conn, n int)) *scan.Request {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for n := 1; ; n++ {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func(n int) {
+				defer conn.Close()
+				handle(conn, n)
+			}(n)
+		}
+	}()
+	return listenerRequest(ln.Addr())
+}
+
+func listenerRequest(addr net.Addr) *scan.Request {
+	tcpAddr := addr.(*net.TCPAddr)
+	return &scan.Request{DstIP: tcpAddr.IP, DstPort: uint16(tcpAddr.Port)}
+}
+
+func greeting(banner string) func(conn net.Conn, n int) {
+	return func(conn net.Conn, _ int) {
+		_, _ = conn.Write([]byte(banner))
+		_, _ = bufio.NewReader(conn).ReadByte()
+	}
+}
+
+func mysqlHandshake(version string) string {
+	payload := "\x0a" + version + "\x00\x08\x00\x00\x00"
+	return string([]byte{byte(len(payload)), 0, 0, 0}) + payload
+}
+
+func newScanner() *Scanner {
+	return NewScanner(WithBannerTimeout(100*time.Millisecond), WithDataTimeout(time.Second))
+}
+
+func TestScanGreeting(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		banner  string
+		service string
+		expect  string
+	}{
+		{
+			name:    "SSH",
+			banner:  "SSH-2.0-OpenSSH_8.9p1 Ubuntu-3\r\n",
+			service: ServiceSSH,
+			expect:  "SSH-2.0-OpenSSH_8.9p1 Ubuntu-3",
+		},
+		{
+			name:    "SMTP",
+			banner:  "220 mail.example.com ESMTP Postfix\r\n",
+			service: ServiceSMTP,
+			expect:  "220 mail.example.com ESMTP Postfix",
+		},
+		{
+			name:    "FTP",
+			banner:  "220 (vsFTPd 3.0.3)\r\n",
+			service: ServiceFTP,
+			expect:  "220 (vsFTPd 3.0.3)",
+		},
+		{
+			name:    "MySQL",
+			banner:  mysqlHandshake("8.0.28"),
+			service: ServiceMySQL,
+			expect:  "8.0.28",
+		},
+		{
+			name:    "Unknown",
+			banner:  "hello\x01\r\n",
+			service: ServiceUnknown,
+			expect:  "hello.",
+		},
+	}
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			req := startServer(t, greeting(tt.banner))
+			result, err := newScanner().Scan(context.Background(), req)
+			require.NoError(t, err)
+			require.Equal(t, &ScanResult{ScanType: ScanType, IP: req.DstIP.String(), Port: req.DstPort,
+				Service: tt.service, Banner: tt.expect}, result)
+		})
+	}
+}
+
+func TestScanHTTPS(t *testing.T) {
+	t.Parallel()
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "ok")
+	}))
+	defer srv.Close()
+	req := listenerRequest(srv.Listener.Addr())
+
+	result, err := newScanner().Scan(context.Background(), req)
+	require.NoError(t, err)
+	require.Equal(t, &ScanResult{ScanType: ScanType, IP: req.DstIP.String(), Port: req.DstPort,
+		Service: ServiceHTTPS, TLS: true, Banner: "HTTP/1.0 200 OK"}, result)
+}
+
+func TestScanHTTP(t *testing.T) {
+	t.Parallel()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "ok")
+	}))
+	defer srv.Close()
+	req := listenerRequest(srv.Listener.Addr())
+
+	// the server answers the TLS handshake with the HTTP error
+	result, err := newScanner().Scan(context.Background(), req)
+	require.NoError(t, err)
+	require.Equal(t, ServiceHTTP, result.(*ScanResult).Service)
+	require.False(t, result.(*ScanResult).TLS)
+	require.Equal(t, "HTTP/1.1 400 Bad Request", result.(*ScanResult).Banner)
+}
+
+func TestScanHTTPSecondConnection(t *testing.T) {
+	t.Parallel()
+	req := startServer(t, func(conn net.Conn, n int) {
+		// the first connection is closed without answer
+		if n == 1 {
+			return
+		}
+		line, err := bufio.NewReader(conn).ReadString('\n')
+		if err == nil && strings.HasPrefix(line, "GET / HTTP/1.0") {
+			_, _ = conn.Write([]byte("HTTP/1.1 404 Not Found\r\nContent-Length: 0\r\n\r\n"))
+		}
+	})
+	result, err := newScanner().Scan(context.Background(), req)
+	require.NoError(t, err)
+	require.Equal(t, &ScanResult{ScanType: ScanType, IP: req.DstIP.String(), Port: req.DstPort,
+		Service: ServiceHTTP, Banner: "HTTP/1.1 404 Not Found"}, result)
+}
+
+func TestScanUnknown(t *testing.T) {
+	t.Parallel()
+	req := startServer(t, func(conn net.Conn, _ int) {
+		_, _ = bufio.NewReader(conn).ReadByte()
+	})
+	result, err := newScanner().Scan(context.Background(), req)
+	require.NoError(t, err)
+	require.Equal(t, ServiceUnknown, result.(*ScanResult).Service)
+}
+
+func TestClassify(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		data    string
+		service string
+	}{
+		{data: "+OK Dovecot ready.\r\n", service: ServicePOP3},
+		{data: "* OK [CAPABILITY IMAP4rev1] Dovecot ready.\r\n", service: ServiceIMAP},
+		{data: "RFB 003.008\n", service: ServiceVNC},
+		{data: "HTTP/1.1 400 Bad Request\r\n", service: ServiceHTTP},
+		{data: "\x16\x00\x00\x00\xffj\x04Host is not allowed", service: ServiceMySQL},
+		{data: "\x05\x00\x00\x00\x0a8.0", service: ServiceUnknown},
+	}
+	for _, tt := range tests {
+		service, _ := classify([]byte(tt.data))
+		require.Equal(t, tt.service, service, tt.data)
+	}
+}
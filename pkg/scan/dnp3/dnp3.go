@@ -0,0 +1,177 @@
+// Package dnp3 detects DNP3 outstations by their answers to Request Link Status frames
+package dnp3
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+const (
+	ScanType = "dnp3"
+
+	defaultDialTimeout = 2 * time.Second
+	defaultDataTimeout = 2 * time.Second
+	// maxDefaultAddress is the last outstation address requested by default
+	maxDefaultAddress = 100
+)
+
+type ScanResult struct {
+	ScanType string `json:"scan"`
+	IP       string `json:"ip"`
+	Port     uint16 `json:"port"`
+	// Addresses are link addresses of outstations that answered, in the order of their answers
+	Addresses []uint16 `json:"addresses"`
+}
+
+func (r *ScanResult) String() string {
+	addresses := make([]string, 0, len(r.Addresses))
+	for _, addr := range r.Addresses {
+		addresses = append(addresses, strconv.Itoa(int(addr)))
+	}
+	return fmt.Sprintf("%-20s %-5d outstations %s", r.IP, r.Port, strings.Join(addresses, ","))
+}
+
+func (r *ScanResult) ID() string {
+	return fmt.Sprintf("%s:%d", r.IP, r.Port)
+}
+
+func (r *ScanResult) MarshalJSON() ([]byte, error) {
+	// Type definition for the recursive call
+	type JScanResult ScanResult
+	// This works because JScanResult doesn't have a MarshalJSON function associated with it
+	return json.Marshal(JScanResult(*r))
+}
+
+// Scanner sends Request Link Status frames to each outstation address over one connection
+// and reports addresses that answered. Outstations ignore frames to other addresses,
+// so answers are read until the data timeout expires or all addresses answered.
+type Scanner struct {
+	dialer        *net.Dialer
+	dataTimeout   time.Duration
+	addresses     []uint16
+	masterAddress uint16
+}
+
+// Assert that dnp3.Scanner conforms to the scan.Scanner interface
+var _ scan.Scanner = (*Scanner)(nil)
+
+type ScannerOption func(*Scanner)
+
+func WithDialTimeout(timeout time.Duration) ScannerOption {
+	return func(s *Scanner) {
+		s.dialer.Timeout = timeout
+	}
+}
+
+func WithDataTimeout(timeout time.Duration) ScannerOption {
+	return func(s *Scanner) {
+		s.dataTimeout = timeout
+	}
+}
+
+// WithAddresses sets outstation addresses that are requested, 0-100 by default
+func WithAddresses(addresses []uint16) ScannerOption {
+	return func(s *Scanner) {
+		s.addresses = addresses
+	}
+}
+
+// WithMasterAddress sets the source address of requests, 0 by default
+func WithMasterAddress(addr uint16) ScannerOption {
+	return func(s *Scanner) {
+		s.masterAddress = addr
+	}
+}
+
+func NewScanner(opts ...ScannerOption) *Scanner {
+	s := &Scanner{
+		dialer: &net.Dialer{
+			Timeout: defaultDialTimeout,
+		},
+		dataTimeout: defaultDataTimeout,
+	}
+	for addr := 0; addr <= maxDefaultAddress; addr++ {
+		s.addresses = append(s.addresses, uint16(addr))
+	}
+	for _, o := range opts {
+		o(s)
+	}
+	return s
+}
+
+func (s *Scanner) Scan(ctx context.Context, r *scan.Request) (scan.Result, error) {
+	result, err := s.scanTarget(ctx, r)
+	return result, scan.WrapError(err)
+}
+
+func (s *Scanner) scanTarget(ctx context.Context, r *scan.Request) (result scan.Result, err error) {
+	addr := net.JoinHostPort(r.DstIP.String(), strconv.Itoa(int(r.DstPort)))
+	conn, err := s.dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	addresses, err := s.linkStatus(conn)
+	if len(addresses) == 0 {
+		return nil, err
+	}
+	return &ScanResult{
+		ScanType:  ScanType,
+		IP:        r.DstIP.String(),
+		Port:      r.DstPort,
+		Addresses: addresses,
+	}, nil
+}
+
+// linkStatus requests the link status of all addresses at once and returns addresses that answered
+func (s *Scanner) linkStatus(conn net.Conn) (addresses []uint16, err error) {
+	if err = conn.SetDeadline(time.Now().Add(s.dataTimeout)); err != nil {
+		return
+	}
+	requests := make([]byte, 0, len(s.addresses)*linkHeaderSize)
+	for _, addr := range s.addresses {
+		requests = append(requests, linkStatusRequest(addr, s.masterAddress)...)
+	}
+	if _, err = conn.Write(requests); err != nil {
+		return
+	}
+	requested := make(map[uint16]bool, len(s.addresses))
+	for _, addr := range s.addresses {
+		requested[addr] = true
+	}
+	r := bufio.NewReader(conn)
+	for len(requested) > 0 {
+		header, rerr := readFrame(r)
+		if rerr != nil {
+			// devices that are not outstations answer with other data or don't answer at all
+			if !isTimeout(rerr) && !errors.Is(rerr, io.EOF) && !errors.Is(rerr, io.ErrUnexpectedEOF) &&
+				!errors.Is(rerr, errInvalidFrame) {
+				err = rerr
+			}
+			return
+		}
+		// only secondary frames from outstations to the master are answers
+		if header.control&(ctrlDir|ctrlPrm) != 0 || header.dst != s.masterAddress || !requested[header.src] {
+			continue
+		}
+		delete(requested, header.src)
+		addresses = append(addresses, header.src)
+	}
+	return
+}
+
+func isTimeout(err error) bool {
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
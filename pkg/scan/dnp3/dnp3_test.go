@@ -0,0 +1,150 @@
+package dnp3
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+type fakeOutstation struct {
+	// addresses are link addresses of outstations behind the connection, frames to other addresses are ignored
+	addresses map[uint16]bool
+	// unsolicited makes outstations send the unsolicited response to the master before answers
+	unsolicited bool
+	// garbage is sent instead of answers
+	garbage []byte
+}
+
+func startFakeOutstation(t *testing.T, o *fakeOutstation) *scan.Request {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go o.serve(conn)
+		}
+	}()
+	addr := ln.Addr().(*net.TCPAddr)
+	return &scan.Request{DstIP: addr.IP, DstPort: uint16(addr.Port)}
+}
+
+func (o *fakeOutstation) serve(conn net.Conn) {
+	defer conn.Close()
+	if len(o.garbage) > 0 {
+		_, _ = conn.Write(o.garbage)
+		return
+	}
+	for {
+		header, err := readFrame(conn)
+		if err != nil {
+			return
+		}
+		if header.control != ctrlDir|ctrlPrm|funcLinkStatus || !o.addresses[header.dst] {
+			continue
+		}
+		if o.unsolicited {
+			// unsolicited response of the application layer is the primary frame with user data
+			_, _ = conn.Write(frame(0x44, header.src, header.dst, []byte{0xc0, 0xf0, 0x82, 0x00, 0x00}))
+		}
+		// Link Status response
+		_, _ = conn.Write(frame(0x0b, header.src, header.dst, nil))
+	}
+}
+
+func TestScan(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		o        *fakeOutstation
+		opts     []ScannerOption
+		expected []uint16
+	}{
+		{
+			name:     "DefaultAddresses",
+			o:        &fakeOutstation{addresses: map[uint16]bool{10: true, 1: true, 1024: true}},
+			expected: []uint16{1, 10},
+		},
+		{
+			name:     "Addresses",
+			o:        &fakeOutstation{addresses: map[uint16]bool{10: true, 1024: true}},
+			opts:     []ScannerOption{WithAddresses([]uint16{1024, 3, 10})},
+			expected: []uint16{1024, 10},
+		},
+		{
+			name:     "MasterAddress",
+			o:        &fakeOutstation{addresses: map[uint16]bool{4: true}, unsolicited: true},
+			opts:     []ScannerOption{WithMasterAddress(3)},
+			expected: []uint16{4},
+		},
+	}
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			req := startFakeOutstation(t, tt.o)
+			opts := append([]ScannerOption{WithDataTimeout(200 * time.Millisecond)}, tt.opts...)
+			result, err := NewScanner(opts...).Scan(context.Background(), req)
+			require.NoError(t, err)
+			require.Equal(t, &ScanResult{
+				ScanType:  ScanType,
+				IP:        req.DstIP.String(),
+				Port:      req.DstPort,
+				Addresses: tt.expected,
+			}, result)
+		})
+	}
+}
+
+func TestScanAllAddressesAnswered(t *testing.T) {
+	t.Parallel()
+	req := startFakeOutstation(t, &fakeOutstation{addresses: map[uint16]bool{1: true}})
+	start := time.Now()
+	result, err := NewScanner(WithAddresses([]uint16{1}), WithDataTimeout(5*time.Second)).
+		Scan(context.Background(), req)
+	require.NoError(t, err)
+	require.NotNil(t, result)
+	// the scan doesn't wait for the data timeout
+	require.Less(t, time.Since(start), 2*time.Second)
+}
+
+func TestScanNoOutstations(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		o    *fakeOutstation
+	}{
+		{
+			name: "NoAnswer",
+			o:    &fakeOutstation{addresses: map[uint16]bool{1024: true}},
+		},
+		{
+			name: "OtherService",
+			o:    &fakeOutstation{garbage: []byte("SSH-2.0-OpenSSH_8.9\r\n")},
+		},
+	}
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			req := startFakeOutstation(t, tt.o)
+			result, err := NewScanner(WithDataTimeout(200*time.Millisecond)).Scan(context.Background(), req)
+			require.NoError(t, err)
+			require.Nil(t, result)
+		})
+	}
+}
+
+func TestScanResultString(t *testing.T) {
+	t.Parallel()
+	result := &ScanResult{ScanType: ScanType, IP: "192.168.0.1", Port: 20000, Addresses: []uint16{1, 10}}
+	require.Equal(t, "192.168.0.1          20000 outstations 1,10", result.String())
+}
@@ -0,0 +1,88 @@
+package dnp3
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+// data link layer frame, see IEEE 1815-2012 section 9.2
+const (
+	startByte1     = 0x05
+	startByte2     = 0x64
+	linkHeaderSize = 10
+	minLinkLength  = 5
+	userBlockSize  = 16
+	crcSize        = 2
+	crcPolynomial  = 0xa6bc
+	ctrlDir        = 0x80
+	ctrlPrm        = 0x40
+	funcLinkStatus = 0x09
+)
+
+var errInvalidFrame = scan.NewError(scan.ErrParse, errors.New("invalid DNP3 frame"))
+
+var crcTable = makeCRCTable()
+
+func makeCRCTable() (table [256]uint16) {
+	for i := range table {
+		crc := uint16(i)
+		for j := 0; j < 8; j++ {
+			if crc&1 != 0 {
+				crc = crc>>1 ^ crcPolynomial
+			} else {
+				crc >>= 1
+			}
+		}
+		table[i] = crc
+	}
+	return
+}
+
+// crc returns the DNP3 CRC-16 of the data
+func crc(data []byte) uint16 {
+	var result uint16
+	for _, b := range data {
+		result = result>>8 ^ crcTable[byte(result)^b]
+	}
+	return ^result
+}
+
+type linkHeader struct {
+	control byte
+	dst     uint16
+	src     uint16
+}
+
+// linkStatusRequest returns the Request Link Status frame of the master to the outstation address
+func linkStatusRequest(dst, src uint16) []byte {
+	frame := []byte{startByte1, startByte2, minLinkLength, ctrlDir | ctrlPrm | funcLinkStatus}
+	frame = binary.LittleEndian.AppendUint16(frame, dst)
+	frame = binary.LittleEndian.AppendUint16(frame, src)
+	return binary.LittleEndian.AppendUint16(frame, crc(frame))
+}
+
+// readFrame reads the data link frame and returns its header, user data is skipped
+func readFrame(r io.Reader) (*linkHeader, error) {
+	header := make([]byte, linkHeaderSize)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, err
+	}
+	if header[0] != startByte1 || header[1] != startByte2 || header[2] < minLinkLength ||
+		binary.LittleEndian.Uint16(header[8:]) != crc(header[:8]) {
+		return nil, errInvalidFrame
+	}
+	// user data blocks of up to 16 bytes are followed by their CRCs
+	dataSize := int(header[2]) - minLinkLength
+	dataSize += (dataSize + userBlockSize - 1) / userBlockSize * crcSize
+	if _, err := io.CopyN(io.Discard, r, int64(dataSize)); err != nil {
+		return nil, err
+	}
+	return &linkHeader{
+		control: header[3],
+		dst:     binary.LittleEndian.Uint16(header[4:6]),
+		src:     binary.LittleEndian.Uint16(header[6:8]),
+	}, nil
+}
@@ -0,0 +1,83 @@
+package dnp3
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// frame returns the data link frame with user data split into blocks followed by their CRCs
+func frame(control byte, dst, src uint16, data []byte) []byte {
+	result := []byte{startByte1, startByte2, byte(minLinkLength + len(data)), control}
+	result = binary.LittleEndian.AppendUint16(result, dst)
+	result = binary.LittleEndian.AppendUint16(result, src)
+	result = binary.LittleEndian.AppendUint16(result, crc(result))
+	for len(data) > 0 {
+		block := data
+		if len(block) > userBlockSize {
+			block = block[:userBlockSize]
+		}
+		result = append(result, block...)
+		result = binary.LittleEndian.AppendUint16(result, crc(block))
+		data = data[len(block):]
+	}
+	return result
+}
+
+func TestCRC(t *testing.T) {
+	t.Parallel()
+	require.Equal(t, uint16(0xea82), crc([]byte("123456789")))
+	// Reset Link States frame from the master 1024 to the outstation 1
+	require.Equal(t, uint16(0x21e9), crc([]byte{0x05, 0x64, 0x05, 0xc0, 0x01, 0x00, 0x00, 0x04}))
+}
+
+func TestLinkStatusRequest(t *testing.T) {
+	t.Parallel()
+	require.Equal(t, []byte{0x05, 0x64, 0x05, 0xc9, 0x01, 0x00, 0x00, 0x00, 0xde, 0x8e}, linkStatusRequest(1, 0))
+	require.Equal(t, frame(0xc9, 10, 3, nil), linkStatusRequest(10, 3))
+}
+
+func TestReadFrame(t *testing.T) {
+	t.Parallel()
+	data := bytes.Repeat([]byte{0xc0, 0xc1}, 10)
+	var buf bytes.Buffer
+	buf.Write(frame(0x44, 0, 10, data))
+	buf.Write(frame(0x0b, 0, 1, nil))
+
+	header, err := readFrame(&buf)
+	require.NoError(t, err)
+	require.Equal(t, &linkHeader{control: 0x44, dst: 0, src: 10}, header)
+	// user data is skipped
+	header, err = readFrame(&buf)
+	require.NoError(t, err)
+	require.Equal(t, &linkHeader{control: 0x0b, dst: 0, src: 1}, header)
+	_, err = readFrame(&buf)
+	require.ErrorIs(t, err, io.EOF)
+}
+
+func TestReadFrameError(t *testing.T) {
+	t.Parallel()
+	invalidCRC := frame(0x0b, 0, 1, nil)
+	invalidCRC[8]++
+	shortLength := frame(0x0b, 0, 1, nil)
+	shortLength[2] = 4
+	tests := []struct {
+		name  string
+		input []byte
+	}{
+		{name: "InvalidStart", input: []byte("HTTP/1.1 400 Bad Request\r\n")},
+		{name: "InvalidCRC", input: invalidCRC},
+		{name: "ShortLength", input: shortLength},
+	}
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := readFrame(bytes.NewReader(tt.input))
+			require.ErrorIs(t, err, errInvalidFrame)
+		})
+	}
+}
@@ -0,0 +1,183 @@
+package dns
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+const (
+	defaultNegativeTTL = 30 * time.Second
+	defaultMaxTTL      = time.Hour
+)
+
+type cacheEntry struct {
+	ips     []net.IP
+	err     error
+	done    bool
+	expires time.Time
+	// ready is closed when the lookup is done, concurrent lookups of the same name wait for it
+	ready chan struct{}
+}
+
+// CacheStats are lookup counters of the DNS cache
+type CacheStats struct {
+	Hits   uint64
+	Misses uint64
+}
+
+// HitRate returns the share of lookups answered from the cache
+func (s CacheStats) HitRate() float64 {
+	if s.Hits+s.Misses == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(s.Hits+s.Misses)
+}
+
+// Cache resolves IPv4 addresses of DNS names with the resolver pool and caches
+// positive and negative answers according to their TTL. Failed lookups, e.g. timeouts
+// or SERVFAIL responses, are not cached.
+type Cache struct {
+	pool        *ResolverPool
+	negativeTTL time.Duration
+	maxTTL      time.Duration
+	now         func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]*cacheEntry
+	stats   CacheStats
+}
+
+type CacheOption func(*Cache)
+
+// WithNegativeTTL sets the time to cache nonexistent names if the response doesn't contain SOA record
+func WithNegativeTTL(ttl time.Duration) CacheOption {
+	return func(c *Cache) {
+		c.negativeTTL = ttl
+	}
+}
+
+// WithMaxTTL limits the time to cache any answer
+func WithMaxTTL(ttl time.Duration) CacheOption {
+	return func(c *Cache) {
+		c.maxTTL = ttl
+	}
+}
+
+func NewCache(pool *ResolverPool, opts ...CacheOption) *Cache {
+	c := &Cache{
+		pool:        pool,
+		negativeTTL: defaultNegativeTTL,
+		maxTTL:      defaultMaxTTL,
+		now:         time.Now,
+		entries:     make(map[string]*cacheEntry),
+	}
+	for _, o := range opts {
+		o(c)
+	}
+	return c
+}
+
+// LookupIP returns IPv4 addresses of the host, IP address literals are returned as is
+func (c *Cache) LookupIP(ctx context.Context, host string) ([]net.IP, error) {
+	if ip := net.ParseIP(host); ip != nil {
+		return []net.IP{ip}, nil
+	}
+	key := strings.ToLower(strings.TrimSuffix(host, "."))
+
+	c.mu.Lock()
+	e, ok := c.entries[key]
+	if ok && (!e.done || c.now().Before(e.expires)) {
+		c.stats.Hits++
+		c.mu.Unlock()
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-e.ready:
+		}
+		return e.ips, e.err
+	}
+	c.stats.Misses++
+	e = &cacheEntry{ready: make(chan struct{})}
+	c.entries[key] = e
+	c.mu.Unlock()
+
+	ips, ttl, err := c.lookup(ctx, key)
+
+	c.mu.Lock()
+	e.ips, e.err, e.done = ips, err, true
+	e.expires = c.now().Add(ttl)
+	if ttl <= 0 && c.entries[key] == e {
+		delete(c.entries, key)
+	}
+	c.mu.Unlock()
+	close(e.ready)
+	return ips, err
+}
+
+// Stats returns lookup counters of the cache
+func (c *Cache) Stats() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.stats
+}
+
+// lookup queries A records of the name and returns the time to cache the answer,
+// zero time is returned for failed lookups
+func (c *Cache) lookup(ctx context.Context, name string) (ips []net.IP, ttl time.Duration, err error) {
+	qname, err := dnsmessage.NewName(fqdn(name))
+	if err != nil {
+		return nil, 0, ErrName
+	}
+	var resp *dnsmessage.Message
+	if resp, err = c.pool.Exchange(ctx, &dnsmessage.Message{
+		Header: dnsmessage.Header{RecursionDesired: true},
+		Questions: []dnsmessage.Question{
+			{Name: qname, Type: dnsmessage.TypeA, Class: dnsmessage.ClassINET},
+		},
+	}); err != nil {
+		return
+	}
+	if resp.RCode != dnsmessage.RCodeSuccess && resp.RCode != dnsmessage.RCodeNameError {
+		return nil, 0, fmt.Errorf("lookup %s: DNS response %s", name, resp.RCode)
+	}
+
+	minTTL := uint32(math.MaxUint32)
+	for _, answer := range resp.Answers {
+		if answer.Header.TTL < minTTL {
+			minTTL = answer.Header.TTL
+		}
+		if a, ok := answer.Body.(*dnsmessage.AResource); ok {
+			ips = append(ips, net.IP(a.A[:]).To4())
+		}
+	}
+	if len(ips) > 0 {
+		return ips, c.limitTTL(time.Duration(minTTL) * time.Second), nil
+	}
+
+	// negative answers are cached for the minimum of SOA TTL and SOA minimum field, see RFC 2308
+	ttl = c.negativeTTL
+	for _, auth := range resp.Authorities {
+		if soa, ok := auth.Body.(*dnsmessage.SOAResource); ok {
+			ttl = time.Duration(auth.Header.TTL) * time.Second
+			if soa.MinTTL < auth.Header.TTL {
+				ttl = time.Duration(soa.MinTTL) * time.Second
+			}
+			break
+		}
+	}
+	return nil, c.limitTTL(ttl), &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
+}
+
+func (c *Cache) limitTTL(ttl time.Duration) time.Duration {
+	if ttl > c.maxTTL {
+		return c.maxTTL
+	}
+	return ttl
+}
@@ -0,0 +1,138 @@
+package dns
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+func newCache(t *testing.T, handler fakeHandler, opts ...CacheOption) (*Cache, *fakeServer) {
+	t.Helper()
+	srv := newFakeServer(t, handler)
+	pool, err := NewResolverPool([]string{srv.Addr()}, WithResolverTimeout(time.Second), WithRetries(0))
+	require.NoError(t, err)
+	return NewCache(pool, opts...), srv
+}
+
+func TestCacheLookupIP(t *testing.T) {
+	t.Parallel()
+	cache, srv := newCache(t, func(q dnsmessage.Question) (dnsmessage.RCode, []dnsmessage.Resource) {
+		return dnsmessage.RCodeSuccess, []dnsmessage.Resource{
+			newAResource(q.Name.String(), 300, net.IPv4(192, 168, 0, 1)),
+			newAResource(q.Name.String(), 60, net.IPv4(192, 168, 0, 2)),
+		}
+	})
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+
+	expected := []net.IP{net.IPv4(192, 168, 0, 1).To4(), net.IPv4(192, 168, 0, 2).To4()}
+	for i := 0; i < 3; i++ {
+		ips, err := cache.LookupIP(context.Background(), "Example.com.")
+		require.NoError(t, err)
+		require.Equal(t, expected, ips)
+	}
+	require.Equal(t, 1, srv.Requests())
+	require.Equal(t, CacheStats{Hits: 2, Misses: 1}, cache.Stats())
+	require.InDelta(t, 2.0/3, cache.Stats().HitRate(), 1e-9)
+
+	// the minimum TTL of answers is used
+	now = now.Add(61 * time.Second)
+	_, err := cache.LookupIP(context.Background(), "example.com")
+	require.NoError(t, err)
+	require.Equal(t, 2, srv.Requests())
+}
+
+func TestCacheLookupIPNegative(t *testing.T) {
+	t.Parallel()
+	cache, srv := newCache(t, func(dnsmessage.Question) (dnsmessage.RCode, []dnsmessage.Resource) {
+		return dnsmessage.RCodeNameError, nil
+	}, WithNegativeTTL(10*time.Second))
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		_, err := cache.LookupIP(context.Background(), "none.example.com")
+		var dnsErr *net.DNSError
+		require.ErrorAs(t, err, &dnsErr)
+		require.True(t, dnsErr.IsNotFound)
+	}
+	require.Equal(t, 1, srv.Requests())
+
+	now = now.Add(11 * time.Second)
+	_, err := cache.LookupIP(context.Background(), "none.example.com")
+	require.Error(t, err)
+	require.Equal(t, 2, srv.Requests())
+}
+
+func TestCacheLookupIPFailureIsNotCached(t *testing.T) {
+	t.Parallel()
+	cache, srv := newCache(t, func(dnsmessage.Question) (dnsmessage.RCode, []dnsmessage.Resource) {
+		return dnsmessage.RCodeServerFailure, nil
+	})
+
+	for i := 0; i < 2; i++ {
+		_, err := cache.LookupIP(context.Background(), "example.com")
+		require.Error(t, err)
+	}
+	require.Equal(t, 2, srv.Requests())
+	require.Equal(t, CacheStats{Misses: 2}, cache.Stats())
+}
+
+func TestCacheLookupIPMaxTTL(t *testing.T) {
+	t.Parallel()
+	cache, srv := newCache(t, func(q dnsmessage.Question) (dnsmessage.RCode, []dnsmessage.Resource) {
+		return dnsmessage.RCodeSuccess, []dnsmessage.Resource{
+			newAResource(q.Name.String(), 3600, net.IPv4(192, 168, 0, 1)),
+		}
+	}, WithMaxTTL(time.Minute))
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+
+	_, err := cache.LookupIP(context.Background(), "example.com")
+	require.NoError(t, err)
+	now = now.Add(2 * time.Minute)
+	_, err = cache.LookupIP(context.Background(), "example.com")
+	require.NoError(t, err)
+	require.Equal(t, 2, srv.Requests())
+}
+
+func TestCacheLookupIPLiteral(t *testing.T) {
+	t.Parallel()
+	cache, srv := newCache(t, func(dnsmessage.Question) (dnsmessage.RCode, []dnsmessage.Resource) {
+		return dnsmessage.RCodeServerFailure, nil
+	})
+
+	ips, err := cache.LookupIP(context.Background(), "10.0.0.1")
+	require.NoError(t, err)
+	require.Equal(t, []net.IP{net.ParseIP("10.0.0.1")}, ips)
+	require.Equal(t, 0, srv.Requests())
+	require.Equal(t, CacheStats{}, cache.Stats())
+}
+
+func TestCacheLookupIPConcurrent(t *testing.T) {
+	t.Parallel()
+	cache, srv := newCache(t, func(q dnsmessage.Question) (dnsmessage.RCode, []dnsmessage.Resource) {
+		time.Sleep(50 * time.Millisecond)
+		return dnsmessage.RCodeSuccess, []dnsmessage.Resource{
+			newAResource(q.Name.String(), 300, net.IPv4(192, 168, 0, 1)),
+		}
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ips, err := cache.LookupIP(context.Background(), "example.com")
+			require.NoError(t, err)
+			require.Len(t, ips, 1)
+		}()
+	}
+	wg.Wait()
+	require.Equal(t, 1, srv.Requests())
+}
@@ -0,0 +1,232 @@
+package dns
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+const (
+	defaultLivenessWorkers = 16
+	// livenessBatchSize is the maximum number of requests buffered by the generator to check their names at once
+	livenessBatchSize = 256
+)
+
+var ErrDeadName = errors.New("name no longer resolves")
+
+// LivenessStats are counters of names checked by the filter
+type LivenessStats struct {
+	Checked int
+	Dead    int
+}
+
+// LivenessFilter checks whether names still resolve before their targets are scanned.
+// Names that are not found or resolve to no addresses are remembered as dead, so they are not
+// resolved again in the run. Other lookup errors, e.g. timeouts, are not remembered and don't mark names as dead.
+type LivenessFilter struct {
+	lookupIP LookupIPFunc
+	workers  int
+
+	mu sync.Mutex
+	// names are alive and dead names by name
+	names map[string]bool
+	dead  int
+}
+
+type LivenessOption func(*LivenessFilter)
+
+// WithLivenessWorkers sets the number of concurrent lookups of the batch of names
+func WithLivenessWorkers(workers int) LivenessOption {
+	return func(f *LivenessFilter) {
+		f.workers = workers
+	}
+}
+
+func NewLivenessFilter(lookupIP LookupIPFunc, opts ...LivenessOption) *LivenessFilter {
+	f := &LivenessFilter{
+		lookupIP: lookupIP,
+		workers:  defaultLivenessWorkers,
+		names:    make(map[string]bool),
+	}
+	for _, o := range opts {
+		o(f)
+	}
+	if f.workers < 1 {
+		f.workers = 1
+	}
+	return f
+}
+
+// LookupIP resolves the host, ErrDeadName is returned for names that don't resolve anymore
+func (f *LivenessFilter) LookupIP(ctx context.Context, host string) ([]net.IP, error) {
+	if net.ParseIP(host) != nil {
+		return f.lookupIP(ctx, host)
+	}
+	if f.Dead(host) {
+		return nil, fmt.Errorf("%s: %w", host, ErrDeadName)
+	}
+	ips, err := f.lookupIP(ctx, host)
+	var dnsErr *net.DNSError
+	switch {
+	case err == nil && len(ips) > 0:
+		f.remember(host, true)
+	case err == nil, errors.As(err, &dnsErr) && dnsErr.IsNotFound:
+		f.remember(host, false)
+		return nil, fmt.Errorf("%s: %w", host, ErrDeadName)
+	}
+	return ips, err
+}
+
+// Check resolves names that were not checked yet concurrently and returns dead ones
+func (f *LivenessFilter) Check(ctx context.Context, names []string) map[string]bool {
+	namec := make(chan string)
+	var wg sync.WaitGroup
+	workers := f.workers
+	if workers > len(names) {
+		workers = len(names)
+	}
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for name := range namec {
+				_, _ = f.LookupIP(ctx, name)
+			}
+		}()
+	}
+	for _, name := range names {
+		if f.checked(name) {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+		case namec <- name:
+		}
+	}
+	close(namec)
+	wg.Wait()
+
+	dead := make(map[string]bool)
+	for _, name := range names {
+		if f.Dead(name) {
+			dead[name] = true
+		}
+	}
+	return dead
+}
+
+// Dead returns true if the name was checked and it doesn't resolve anymore
+func (f *LivenessFilter) Dead(name string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	alive, ok := f.names[name]
+	return ok && !alive
+}
+
+func (f *LivenessFilter) Stats() LivenessStats {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return LivenessStats{Checked: len(f.names), Dead: f.dead}
+}
+
+func (f *LivenessFilter) checked(name string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	_, ok := f.names[name]
+	return ok
+}
+
+func (f *LivenessFilter) remember(name string, alive bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.names[name]; ok {
+		return
+	}
+	f.names[name] = alive
+	if !alive {
+		f.dead++
+	}
+}
+
+type livenessGenerator struct {
+	delegate scan.RequestGenerator
+	filter   *LivenessFilter
+}
+
+// NewLivenessGenerator checks names of delegate requests in batches before they are scanned,
+// requests to dead names are replaced with errors, so they are reported as skipped targets
+func NewLivenessGenerator(delegate scan.RequestGenerator, filter *LivenessFilter) scan.RequestGenerator {
+	return &livenessGenerator{delegate: delegate, filter: filter}
+}
+
+func (g *livenessGenerator) GenerateRequests(ctx context.Context, r *scan.Range) (<-chan *scan.Request, error) {
+	requests, err := g.delegate.GenerateRequests(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	out := make(chan *scan.Request, cap(requests))
+	go func() {
+		defer close(out)
+		for {
+			batch := readBatch(ctx, requests)
+			if len(batch) == 0 {
+				return
+			}
+			var names []string
+			seen := make(map[string]bool)
+			for _, request := range batch {
+				if isNameRequest(request) && !seen[request.DstName] {
+					seen[request.DstName] = true
+					names = append(names, request.DstName)
+				}
+			}
+			dead := g.filter.Check(ctx, names)
+			for _, request := range batch {
+				if isNameRequest(request) && dead[request.DstName] {
+					request.Err = fmt.Errorf("%s: %w", request.DstName, ErrDeadName)
+				}
+				select {
+				case <-ctx.Done():
+					return
+				case out <- request:
+				}
+			}
+		}
+	}()
+	return out, nil
+}
+
+func isNameRequest(request *scan.Request) bool {
+	return request.Err == nil && request.DstIP == nil && len(request.DstName) > 0
+}
+
+// readBatch waits for the first request and reads the following ones that are ready
+// without waiting, up to livenessBatchSize requests. An empty batch is returned when requests are done.
+func readBatch(ctx context.Context, requests <-chan *scan.Request) []*scan.Request {
+	var batch []*scan.Request
+	select {
+	case <-ctx.Done():
+		return nil
+	case request, ok := <-requests:
+		if !ok {
+			return nil
+		}
+		batch = append(batch, request)
+	}
+	for len(batch) < livenessBatchSize {
+		select {
+		case request, ok := <-requests:
+			if !ok {
+				return batch
+			}
+			batch = append(batch, request)
+		default:
+			return batch
+		}
+	}
+	return batch
+}
@@ -0,0 +1,99 @@
+package dns
+
+import (
+	"context"
+	"io"
+	"net"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+func TestLivenessFilterLookupIP(t *testing.T) {
+	t.Parallel()
+	lookup := newFakeLookup()
+	f := NewLivenessFilter(lookup.lookupIP)
+
+	ips, err := f.LookupIP(context.Background(), "www.example.com")
+	require.NoError(t, err)
+	require.Equal(t, []net.IP{net.IPv4(192, 168, 0, 1).To4()}, ips)
+
+	_, err = f.LookupIP(context.Background(), "old.example.com")
+	require.ErrorIs(t, err, ErrDeadName)
+	require.True(t, f.Dead("old.example.com"))
+	// dead names are not resolved again
+	_, err = f.LookupIP(context.Background(), "old.example.com")
+	require.ErrorIs(t, err, ErrDeadName)
+	require.Equal(t, int64(2), atomic.LoadInt64(&lookup.lookups))
+
+	require.Equal(t, LivenessStats{Checked: 2, Dead: 1}, f.Stats())
+}
+
+func TestLivenessFilterTemporaryError(t *testing.T) {
+	t.Parallel()
+	errTimeout := &net.DNSError{Err: "i/o timeout", Name: "www.example.com", IsTimeout: true}
+	f := NewLivenessFilter(func(context.Context, string) ([]net.IP, error) {
+		return nil, errTimeout
+	})
+	_, err := f.LookupIP(context.Background(), "www.example.com")
+	require.ErrorIs(t, err, errTimeout)
+	require.False(t, f.Dead("www.example.com"))
+	require.Equal(t, LivenessStats{}, f.Stats())
+}
+
+func TestLivenessFilterEmptyAnswer(t *testing.T) {
+	t.Parallel()
+	f := NewLivenessFilter(func(context.Context, string) ([]net.IP, error) {
+		return nil, nil
+	})
+	_, err := f.LookupIP(context.Background(), "www.example.com")
+	require.ErrorIs(t, err, ErrDeadName)
+}
+
+func TestLivenessFilterCheck(t *testing.T) {
+	t.Parallel()
+	lookup := newFakeLookup()
+	f := NewLivenessFilter(lookup.lookupIP, WithLivenessWorkers(2))
+
+	dead := f.Check(context.Background(),
+		[]string{"www.example.com", "old.example.com", "api.wild.example", "gone.example.com"})
+	require.Equal(t, map[string]bool{"old.example.com": true, "gone.example.com": true}, dead)
+	require.Equal(t, int64(4), atomic.LoadInt64(&lookup.lookups))
+
+	// checked names are not resolved again
+	dead = f.Check(context.Background(), []string{"www.example.com", "old.example.com"})
+	require.Equal(t, map[string]bool{"old.example.com": true}, dead)
+	require.Equal(t, int64(4), atomic.LoadInt64(&lookup.lookups))
+}
+
+func TestLivenessGenerator(t *testing.T) {
+	t.Parallel()
+	lookup := newFakeLookup()
+	names := "www.example.com\nold.example.com\n"
+	reqgen := scan.NewFileNamePortGenerator(func() (io.ReadCloser, error) {
+		return io.NopCloser(strings.NewReader(names)), nil
+	}, scan.NewPortGenerator())
+	reqgen = NewLivenessGenerator(reqgen, NewLivenessFilter(lookup.lookupIP))
+
+	requests, err := reqgen.GenerateRequests(context.Background(), &scan.Range{
+		Ports: []*scan.PortRange{{StartPort: 1, EndPort: 2}}})
+	require.NoError(t, err)
+
+	var alive, dead int
+	for request := range requests {
+		switch request.DstName {
+		case "www.example.com":
+			require.NoError(t, request.Err)
+			alive++
+		case "old.example.com":
+			require.ErrorIs(t, request.Err, ErrDeadName)
+			dead++
+		}
+	}
+	require.Equal(t, 2, alive)
+	require.Equal(t, 2, dead)
+	require.Equal(t, int64(2), atomic.LoadInt64(&lookup.lookups))
+}
@@ -0,0 +1,116 @@
+package dns
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/v-byte-cpu/sx/pkg/scan"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+const (
+	ResolverScanType = "dns"
+
+	DefaultQueryName    = "example.com"
+	defaultQueryTimeout = 2 * time.Second
+)
+
+type ResolverResult struct {
+	ScanType string `json:"scan"`
+	IP       string `json:"ip"`
+	Port     uint16 `json:"port"`
+	// Recursive is true if the response has the RA (Recursion Available) flag set
+	Recursive bool     `json:"recursive"`
+	RCode     string   `json:"rcode"`
+	Answers   []string `json:"answers,omitempty"`
+}
+
+func (r *ResolverResult) String() string {
+	recursion := "-"
+	if r.Recursive {
+		recursion = "recursive"
+	}
+	return strings.TrimSpace(fmt.Sprintf("%-20s %-5d %-9s %-14s %s",
+		r.IP, r.Port, recursion, r.RCode, strings.Join(r.Answers, ", ")))
+}
+
+func (r *ResolverResult) ID() string {
+	return fmt.Sprintf("%s:%d", r.IP, r.Port)
+}
+
+func (r *ResolverResult) MarshalJSON() ([]byte, error) {
+	// Type definition for the recursive call
+	type JResolverResult ResolverResult
+	// This works because JResolverResult doesn't have a MarshalJSON function associated with it
+	return json.Marshal(JResolverResult(*r))
+}
+
+// ResolverScanner sends a recursive A query to each target over UDP to find open resolvers,
+// any response is reported, open resolvers have the recursive flag set and answers for the query
+type ResolverScanner struct {
+	question dnsmessage.Question
+	timeout  time.Duration
+}
+
+// Assert that dns.ResolverScanner conforms to the scan.Scanner interface
+var _ scan.Scanner = (*ResolverScanner)(nil)
+
+type ResolverScannerOption func(*ResolverScanner)
+
+// WithQueryTimeout sets the time to wait for a response from each target
+func WithQueryTimeout(timeout time.Duration) ResolverScannerOption {
+	return func(s *ResolverScanner) {
+		s.timeout = timeout
+	}
+}
+
+// NewResolverScanner creates a scanner that queries A records of the name
+func NewResolverScanner(name string, opts ...ResolverScannerOption) (*ResolverScanner, error) {
+	qname, err := dnsmessage.NewName(fqdn(name))
+	if err != nil || len(name) == 0 {
+		return nil, ErrName
+	}
+	s := &ResolverScanner{
+		question: dnsmessage.Question{Name: qname, Type: dnsmessage.TypeA, Class: dnsmessage.ClassINET},
+		timeout:  defaultQueryTimeout,
+	}
+	for _, o := range opts {
+		o(s)
+	}
+	return s, nil
+}
+
+func (s *ResolverScanner) Scan(ctx context.Context, r *scan.Request) (scan.Result, error) {
+	result, err := s.scanTarget(ctx, r)
+	return result, scan.WrapError(err)
+}
+
+func (s *ResolverScanner) scanTarget(ctx context.Context, r *scan.Request) (result scan.Result, err error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	addr := net.JoinHostPort(r.DstIP.String(), strconv.Itoa(int(r.DstPort)))
+	var resp *dnsmessage.Message
+	if resp, err = exchange(ctx, addr, &dnsmessage.Message{
+		Header:    dnsmessage.Header{RecursionDesired: true},
+		Questions: []dnsmessage.Question{s.question},
+	}); err != nil {
+		return
+	}
+	res := &ResolverResult{
+		ScanType:  ResolverScanType,
+		IP:        r.DstIP.String(),
+		Port:      r.DstPort,
+		Recursive: resp.RecursionAvailable,
+		RCode:     strings.TrimPrefix(resp.RCode.String(), "RCode"),
+	}
+	for _, answer := range resp.Answers {
+		res.Answers = append(res.Answers,
+			fmt.Sprintf("%s %s", RecordTypeName(answer.Header.Type), recordValue(answer.Body)))
+	}
+	return res, nil
+}
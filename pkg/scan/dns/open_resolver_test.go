@@ -0,0 +1,90 @@
+package dns
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+func newServerRequest(t *testing.T, srv *fakeServer) *scan.Request {
+	t.Helper()
+	addr := srv.conn.LocalAddr().(*net.UDPAddr)
+	return &scan.Request{DstIP: addr.IP, DstPort: uint16(addr.Port)}
+}
+
+func TestResolverScanner(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name      string
+		recursion bool
+		handler   fakeHandler
+		expected  *ResolverResult
+	}{
+		{
+			name:      "OpenResolver",
+			recursion: true,
+			handler: func(q dnsmessage.Question) (dnsmessage.RCode, []dnsmessage.Resource) {
+				return dnsmessage.RCodeSuccess, []dnsmessage.Resource{
+					newAResource(q.Name.String(), 300, net.IPv4(93, 184, 216, 34)),
+				}
+			},
+			expected: &ResolverResult{
+				Recursive: true,
+				RCode:     "Success",
+				Answers:   []string{"A 93.184.216.34"},
+			},
+		},
+		{
+			name: "RefusedRecursion",
+			handler: func(dnsmessage.Question) (dnsmessage.RCode, []dnsmessage.Resource) {
+				return dnsmessage.RCodeRefused, nil
+			},
+			expected: &ResolverResult{
+				RCode: "Refused",
+			},
+		},
+	}
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			srv := startFakeServer(t, &fakeServer{handler: tt.handler, recursion: tt.recursion})
+			req := newServerRequest(t, srv)
+			s, err := NewResolverScanner("example.com")
+			require.NoError(t, err)
+
+			result, err := s.Scan(context.Background(), req)
+			require.NoError(t, err)
+			expected := *tt.expected
+			expected.ScanType = ResolverScanType
+			expected.IP = req.DstIP.String()
+			expected.Port = req.DstPort
+			require.Equal(t, &expected, result)
+		})
+	}
+}
+
+func TestResolverScannerTimeout(t *testing.T) {
+	t.Parallel()
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer conn.Close()
+	addr := conn.LocalAddr().(*net.UDPAddr)
+
+	s, err := NewResolverScanner("example.com", WithQueryTimeout(100*time.Millisecond))
+	require.NoError(t, err)
+	result, err := s.Scan(context.Background(), &scan.Request{DstIP: addr.IP, DstPort: uint16(addr.Port)})
+	require.Error(t, err)
+	require.Nil(t, result)
+}
+
+func TestNewResolverScannerInvalidName(t *testing.T) {
+	t.Parallel()
+	_, err := NewResolverScanner("")
+	require.ErrorIs(t, err, ErrName)
+}
@@ -0,0 +1,166 @@
+package dns
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/v-byte-cpu/sx/pkg/scan"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+const RecordScanType = "dnsrecord"
+
+var (
+	ErrRecordType = errors.New("invalid DNS record type")
+	ErrName       = errors.New("invalid DNS name")
+)
+
+var recordTypes = map[string]dnsmessage.Type{
+	"A":     dnsmessage.TypeA,
+	"NS":    dnsmessage.TypeNS,
+	"CNAME": dnsmessage.TypeCNAME,
+	"SOA":   dnsmessage.TypeSOA,
+	"PTR":   dnsmessage.TypePTR,
+	"MX":    dnsmessage.TypeMX,
+	"TXT":   dnsmessage.TypeTXT,
+	"AAAA":  dnsmessage.TypeAAAA,
+	"SRV":   dnsmessage.TypeSRV,
+}
+
+// ParseRecordType parses DNS record type name like MX or AAAA,
+// other types are accepted in the numeric form, e.g. 257 for CAA records
+func ParseRecordType(name string) (dnsmessage.Type, error) {
+	if t, ok := recordTypes[strings.ToUpper(name)]; ok {
+		return t, nil
+	}
+	t, err := strconv.ParseUint(name, 10, 16)
+	if err != nil || t == 0 {
+		return 0, ErrRecordType
+	}
+	return dnsmessage.Type(t), nil
+}
+
+// RecordTypeName returns DNS record type name without the Type prefix
+func RecordTypeName(t dnsmessage.Type) string {
+	return strings.TrimPrefix(t.String(), "Type")
+}
+
+type RecordResult struct {
+	ScanType string `json:"scan"`
+	Name     string `json:"name"`
+	Type     string `json:"type"`
+	TTL      uint32 `json:"ttl"`
+	Value    string `json:"value"`
+}
+
+func (r *RecordResult) String() string {
+	return fmt.Sprintf("%-30s %-6s %-7d %s", r.Name, r.Type, r.TTL, r.Value)
+}
+
+func (r *RecordResult) ID() string {
+	return fmt.Sprintf("%s %s %s", r.Name, r.Type, r.Value)
+}
+
+func (r *RecordResult) MarshalJSON() ([]byte, error) {
+	// Type definition for the recursive call
+	type JRecordResult RecordResult
+	// This works because JRecordResult doesn't have a MarshalJSON function associated with it
+	return json.Marshal(JRecordResult(*r))
+}
+
+// RecordScanner queries DNS records of the request name,
+// the record type is passed in the request port field
+type RecordScanner struct {
+	pool *ResolverPool
+}
+
+// Assert that dns.RecordScanner conforms to the scan.Scanner interface
+var _ scan.Scanner = (*RecordScanner)(nil)
+
+func NewRecordScanner(pool *ResolverPool) *RecordScanner {
+	return &RecordScanner{pool: pool}
+}
+
+func (s *RecordScanner) Scan(ctx context.Context, r *scan.Request) (scan.Result, error) {
+	result, err := s.scanTarget(ctx, r)
+	return result, scan.WrapError(err)
+}
+
+func (s *RecordScanner) scanTarget(ctx context.Context, r *scan.Request) (result scan.Result, err error) {
+	name, err := dnsmessage.NewName(fqdn(r.DstName))
+	if err != nil {
+		return nil, ErrName
+	}
+	qtype := dnsmessage.Type(r.DstPort)
+	var resp *dnsmessage.Message
+	if resp, err = s.pool.Exchange(ctx, &dnsmessage.Message{
+		Header: dnsmessage.Header{RecursionDesired: true},
+		Questions: []dnsmessage.Question{
+			{Name: name, Type: qtype, Class: dnsmessage.ClassINET},
+		},
+	}); err != nil {
+		return
+	}
+	// NXDOMAIN and other errors mean no records
+	if resp.RCode != dnsmessage.RCodeSuccess {
+		return
+	}
+	results := make(scan.MultiResult, 0, len(resp.Answers))
+	for _, answer := range resp.Answers {
+		if answer.Header.Type != qtype {
+			continue
+		}
+		results = append(results, &RecordResult{
+			ScanType: RecordScanType,
+			Name:     strings.TrimSuffix(r.DstName, "."),
+			Type:     RecordTypeName(qtype),
+			TTL:      answer.Header.TTL,
+			Value:    recordValue(answer.Body),
+		})
+	}
+	if len(results) == 0 {
+		return
+	}
+	return results, nil
+}
+
+func fqdn(name string) string {
+	if strings.HasSuffix(name, ".") {
+		return name
+	}
+	return name + "."
+}
+
+func recordValue(body dnsmessage.ResourceBody) string {
+	switch b := body.(type) {
+	case *dnsmessage.AResource:
+		return net.IP(b.A[:]).String()
+	case *dnsmessage.AAAAResource:
+		return net.IP(b.AAAA[:]).String()
+	case *dnsmessage.MXResource:
+		return fmt.Sprintf("%d %s", b.Pref, b.MX.String())
+	case *dnsmessage.TXTResource:
+		return strings.Join(b.TXT, "")
+	case *dnsmessage.NSResource:
+		return b.NS.String()
+	case *dnsmessage.CNAMEResource:
+		return b.CNAME.String()
+	case *dnsmessage.PTRResource:
+		return b.PTR.String()
+	case *dnsmessage.SOAResource:
+		return fmt.Sprintf("%s %s %d %d %d %d %d", b.NS.String(), b.MBox.String(),
+			b.Serial, b.Refresh, b.Retry, b.Expire, b.MinTTL)
+	case *dnsmessage.SRVResource:
+		return fmt.Sprintf("%d %d %d %s", b.Priority, b.Weight, b.Port, b.Target.String())
+	case *dnsmessage.UnknownResource:
+		return hex.EncodeToString(b.Data)
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,193 @@
+package dns
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+func TestParseRecordType(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		input    string
+		expected dnsmessage.Type
+		err      bool
+	}{
+		{input: "A", expected: dnsmessage.TypeA},
+		{input: "aaaa", expected: dnsmessage.TypeAAAA},
+		{input: "Mx", expected: dnsmessage.TypeMX},
+		{input: "TXT", expected: dnsmessage.TypeTXT},
+		{input: "257", expected: dnsmessage.Type(257)},
+		{input: "0", err: true},
+		{input: "ABC", err: true},
+		{input: "", err: true},
+	}
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.input, func(t *testing.T) {
+			t.Parallel()
+			result, err := ParseRecordType(tt.input)
+			if tt.err {
+				require.ErrorIs(t, err, ErrRecordType)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestRecordTypeName(t *testing.T) {
+	t.Parallel()
+	require.Equal(t, "AAAA", RecordTypeName(dnsmessage.TypeAAAA))
+	require.Equal(t, "MX", RecordTypeName(dnsmessage.TypeMX))
+}
+
+func newRecordScanner(t *testing.T, handler fakeHandler) *RecordScanner {
+	t.Helper()
+	srv := newFakeServer(t, handler)
+	pool, err := NewResolverPool([]string{srv.Addr()})
+	require.NoError(t, err)
+	return NewRecordScanner(pool)
+}
+
+func TestRecordScannerMultipleRecords(t *testing.T) {
+	t.Parallel()
+	s := newRecordScanner(t, func(q dnsmessage.Question) (dnsmessage.RCode, []dnsmessage.Resource) {
+		header := dnsmessage.ResourceHeader{
+			Name: q.Name, Type: dnsmessage.TypeMX, Class: dnsmessage.ClassINET, TTL: 300}
+		return dnsmessage.RCodeSuccess, []dnsmessage.Resource{
+			{Header: header, Body: &dnsmessage.MXResource{Pref: 10, MX: dnsmessage.MustNewName("mx1.example.com.")}},
+			{Header: header, Body: &dnsmessage.MXResource{Pref: 20, MX: dnsmessage.MustNewName("mx2.example.com.")}},
+		}
+	})
+
+	result, err := s.Scan(context.Background(), &scan.Request{
+		DstName: "example.com", DstPort: uint16(dnsmessage.TypeMX)})
+	require.NoError(t, err)
+	require.Equal(t, scan.MultiResult{
+		&RecordResult{ScanType: RecordScanType, Name: "example.com", Type: "MX", TTL: 300, Value: "10 mx1.example.com."},
+		&RecordResult{ScanType: RecordScanType, Name: "example.com", Type: "MX", TTL: 300, Value: "20 mx2.example.com."},
+	}, result)
+}
+
+func TestRecordScannerValues(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		qtype    dnsmessage.Type
+		body     dnsmessage.ResourceBody
+		expected string
+	}{
+		{
+			name:     "A",
+			qtype:    dnsmessage.TypeA,
+			body:     &dnsmessage.AResource{A: [4]byte{192, 168, 0, 1}},
+			expected: "192.168.0.1",
+		},
+		{
+			name:  "AAAA",
+			qtype: dnsmessage.TypeAAAA,
+			body: &dnsmessage.AAAAResource{AAAA: [16]byte{
+				0x20, 0x01, 0x0d, 0xb8, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1}},
+			expected: "2001:db8::1",
+		},
+		{
+			name:     "TXT",
+			qtype:    dnsmessage.TypeTXT,
+			body:     &dnsmessage.TXTResource{TXT: []string{"v=spf1 ", "-all"}},
+			expected: "v=spf1 -all",
+		},
+		{
+			name:     "NS",
+			qtype:    dnsmessage.TypeNS,
+			body:     &dnsmessage.NSResource{NS: dnsmessage.MustNewName("ns1.example.com.")},
+			expected: "ns1.example.com.",
+		},
+	}
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			s := newRecordScanner(t, func(q dnsmessage.Question) (dnsmessage.RCode, []dnsmessage.Resource) {
+				return dnsmessage.RCodeSuccess, []dnsmessage.Resource{{
+					Header: dnsmessage.ResourceHeader{
+						Name: q.Name, Type: q.Type, Class: dnsmessage.ClassINET, TTL: 60},
+					Body: tt.body,
+				}}
+			})
+			result, err := s.Scan(context.Background(), &scan.Request{
+				DstName: "example.com.", DstPort: uint16(tt.qtype)})
+			require.NoError(t, err)
+			require.Equal(t, scan.MultiResult{
+				&RecordResult{ScanType: RecordScanType, Name: "example.com",
+					Type: tt.name, TTL: 60, Value: tt.expected},
+			}, result)
+		})
+	}
+}
+
+func TestRecordScannerSkipsOtherTypes(t *testing.T) {
+	t.Parallel()
+	s := newRecordScanner(t, func(q dnsmessage.Question) (dnsmessage.RCode, []dnsmessage.Resource) {
+		return dnsmessage.RCodeSuccess, []dnsmessage.Resource{
+			{
+				Header: dnsmessage.ResourceHeader{
+					Name: q.Name, Type: dnsmessage.TypeCNAME, Class: dnsmessage.ClassINET, TTL: 60},
+				Body: &dnsmessage.CNAMEResource{CNAME: dnsmessage.MustNewName("www.example.com.")},
+			},
+			newAResource("www.example.com.", 60, net.IPv4(10, 0, 0, 1)),
+		}
+	})
+	result, err := s.Scan(context.Background(), &scan.Request{
+		DstName: "example.com", DstPort: uint16(dnsmessage.TypeA)})
+	require.NoError(t, err)
+	require.Equal(t, scan.MultiResult{
+		&RecordResult{ScanType: RecordScanType, Name: "example.com", Type: "A", TTL: 60, Value: "10.0.0.1"},
+	}, result)
+}
+
+func TestRecordScannerNoRecords(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		rcode dnsmessage.RCode
+	}{
+		{name: "NXDOMAIN", rcode: dnsmessage.RCodeNameError},
+		{name: "EmptyAnswer", rcode: dnsmessage.RCodeSuccess},
+	}
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			s := newRecordScanner(t, func(q dnsmessage.Question) (dnsmessage.RCode, []dnsmessage.Resource) {
+				return tt.rcode, nil
+			})
+			result, err := s.Scan(context.Background(), &scan.Request{
+				DstName: "example.com", DstPort: uint16(dnsmessage.TypeA)})
+			require.NoError(t, err)
+			require.Nil(t, result)
+		})
+	}
+}
+
+func TestRecordScannerInvalidName(t *testing.T) {
+	t.Parallel()
+	s := newRecordScanner(t, func(q dnsmessage.Question) (dnsmessage.RCode, []dnsmessage.Resource) {
+		return dnsmessage.RCodeSuccess, nil
+	})
+	name := make([]byte, 300)
+	for i := range name {
+		name[i] = 'a'
+	}
+	_, err := s.Scan(context.Background(), &scan.Request{
+		DstName: string(name), DstPort: uint16(dnsmessage.TypeA)})
+	require.ErrorIs(t, err, ErrName)
+}
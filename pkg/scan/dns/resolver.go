@@ -0,0 +1,190 @@
+package dns
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/v-byte-cpu/sx/pkg/scan"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+const (
+	defaultResolverTimeout = 2 * time.Second
+	defaultRetries         = 2
+
+	resolvConfPath = "/etc/resolv.conf"
+	// maximum size of DNS message over UDP without EDNS0
+	maxUDPMessageSize = 512
+)
+
+var (
+	ErrNoResolvers = errors.New("no DNS resolvers")
+	errQuestion    = scan.NewError(scan.ErrParse, errors.New("DNS response question mismatch"))
+)
+
+// ResolverPool distributes DNS queries among a set of resolvers in round-robin order.
+// A failed query is retried with the next resolver from the pool.
+type ResolverPool struct {
+	servers []string
+	next    uint32
+	timeout time.Duration
+	retries int
+}
+
+type ResolverOption func(*ResolverPool)
+
+// WithResolverTimeout sets the time to wait for a response from one resolver
+func WithResolverTimeout(timeout time.Duration) ResolverOption {
+	return func(p *ResolverPool) {
+		p.timeout = timeout
+	}
+}
+
+// WithRetries sets the number of additional attempts for a failed query
+func WithRetries(retries int) ResolverOption {
+	return func(p *ResolverPool) {
+		p.retries = retries
+	}
+}
+
+// NewResolverPool creates a pool of resolvers, each server is an IP address
+// with an optional port, port 53 is used by default
+func NewResolverPool(servers []string, opts ...ResolverOption) (*ResolverPool, error) {
+	if len(servers) == 0 {
+		return nil, ErrNoResolvers
+	}
+	p := &ResolverPool{
+		timeout: defaultResolverTimeout,
+		retries: defaultRetries,
+	}
+	for _, server := range servers {
+		p.servers = append(p.servers, resolverAddr(server))
+	}
+	for _, o := range opts {
+		o(p)
+	}
+	return p, nil
+}
+
+func resolverAddr(server string) string {
+	if net.ParseIP(server) != nil {
+		return net.JoinHostPort(server, "53")
+	}
+	return server
+}
+
+// SystemResolvers returns nameservers from /etc/resolv.conf
+func SystemResolvers() ([]string, error) {
+	f, err := os.Open(resolvConfPath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return parseResolvConf(f)
+}
+
+func parseResolvConf(r io.Reader) (result []string, err error) {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || fields[0] != "nameserver" {
+			continue
+		}
+		if net.ParseIP(fields[1]) != nil {
+			result = append(result, fields[1])
+		}
+	}
+	if err = scanner.Err(); err != nil {
+		return
+	}
+	if len(result) == 0 {
+		return nil, ErrNoResolvers
+	}
+	return
+}
+
+// Exchange sends DNS query to the next resolver from the pool and returns its response
+func (p *ResolverPool) Exchange(ctx context.Context, msg *dnsmessage.Message) (resp *dnsmessage.Message, err error) {
+	for i := 0; i <= p.retries; i++ {
+		server := p.servers[int(atomic.AddUint32(&p.next, 1)-1)%len(p.servers)]
+		if resp, err = p.exchange(ctx, server, msg); err == nil {
+			return
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+	}
+	return
+}
+
+func (p *ResolverPool) exchange(ctx context.Context, server string, msg *dnsmessage.Message) (*dnsmessage.Message, error) {
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+	return exchange(ctx, server, msg)
+}
+
+// exchange sends DNS query with a random ID over UDP and waits for the matching response
+func exchange(ctx context.Context, server string, msg *dnsmessage.Message) (*dnsmessage.Message, error) {
+	query := *msg
+	query.ID = uint16(rand.Uint32())
+	data, err := query.Pack()
+	if err != nil {
+		return nil, err
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "udp", server)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		if err = conn.SetDeadline(deadline); err != nil {
+			return nil, err
+		}
+	}
+	if _, err = conn.Write(data); err != nil {
+		return nil, err
+	}
+
+	buf := make([]byte, maxUDPMessageSize)
+	for {
+		n, err := conn.Read(buf)
+		if err != nil {
+			return nil, err
+		}
+		var resp dnsmessage.Message
+		if err = resp.Unpack(buf[:n]); err != nil {
+			continue
+		}
+		// ignore stale or spoofed responses
+		if resp.ID != query.ID || !resp.Response {
+			continue
+		}
+		if !sameQuestions(query.Questions, resp.Questions) {
+			return nil, errQuestion
+		}
+		return &resp, nil
+	}
+}
+
+func sameQuestions(q1, q2 []dnsmessage.Question) bool {
+	if len(q1) != len(q2) {
+		return false
+	}
+	for i := range q1 {
+		if q1[i].Type != q2[i].Type || q1[i].Class != q2[i].Class ||
+			!strings.EqualFold(q1[i].Name.String(), q2[i].Name.String()) {
+			return false
+		}
+	}
+	return true
+}
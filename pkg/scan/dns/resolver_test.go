@@ -0,0 +1,230 @@
+package dns
+
+import (
+	"context"
+	"net"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+type fakeHandler func(q dnsmessage.Question) (dnsmessage.RCode, []dnsmessage.Resource)
+
+type fakeServer struct {
+	conn     net.PacketConn
+	handler  fakeHandler
+	requests int32
+	// recursion is the RA (Recursion Available) flag of responses
+	recursion bool
+}
+
+// newFakeServer starts UDP DNS server that answers queries with the handler
+func newFakeServer(t *testing.T, handler fakeHandler) *fakeServer {
+	t.Helper()
+	return startFakeServer(t, &fakeServer{handler: handler, recursion: true})
+}
+
+func startFakeServer(t *testing.T, srv *fakeServer) *fakeServer {
+	t.Helper()
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	srv.conn = conn
+	go srv.serve()
+	t.Cleanup(func() {
+		conn.Close()
+	})
+	return srv
+}
+
+func (s *fakeServer) Addr() string {
+	return s.conn.LocalAddr().String()
+}
+
+func (s *fakeServer) Requests() int {
+	return int(atomic.LoadInt32(&s.requests))
+}
+
+func (s *fakeServer) serve() {
+	buf := make([]byte, maxUDPMessageSize)
+	for {
+		n, addr, err := s.conn.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+		atomic.AddInt32(&s.requests, 1)
+		var msg dnsmessage.Message
+		if err = msg.Unpack(buf[:n]); err != nil || len(msg.Questions) != 1 {
+			continue
+		}
+		rcode, answers := s.handler(msg.Questions[0])
+		resp := dnsmessage.Message{
+			Header: dnsmessage.Header{
+				ID: msg.ID, Response: true, RecursionDesired: msg.RecursionDesired,
+				RecursionAvailable: s.recursion, RCode: rcode},
+			Questions: msg.Questions,
+			Answers:   answers,
+		}
+		data, err := resp.Pack()
+		if err != nil {
+			continue
+		}
+		if _, err = s.conn.WriteTo(data, addr); err != nil {
+			return
+		}
+	}
+}
+
+func newQuery(t *testing.T, name string, qtype dnsmessage.Type) *dnsmessage.Message {
+	t.Helper()
+	return &dnsmessage.Message{
+		Questions: []dnsmessage.Question{
+			{Name: dnsmessage.MustNewName(name), Type: qtype, Class: dnsmessage.ClassINET},
+		},
+	}
+}
+
+func newAResource(name string, ttl uint32, ip net.IP) dnsmessage.Resource {
+	var a [4]byte
+	copy(a[:], ip.To4())
+	return dnsmessage.Resource{
+		Header: dnsmessage.ResourceHeader{
+			Name: dnsmessage.MustNewName(name), Type: dnsmessage.TypeA,
+			Class: dnsmessage.ClassINET, TTL: ttl},
+		Body: &dnsmessage.AResource{A: a},
+	}
+}
+
+func TestParseResolvConf(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		input    string
+		expected []string
+		err      bool
+	}{
+		{
+			name:     "OneNameserver",
+			input:    "nameserver 8.8.8.8",
+			expected: []string{"8.8.8.8"},
+		},
+		{
+			name: "TwoNameserversWithOptions",
+			input: strings.Join([]string{
+				"# generated",
+				"search example.com",
+				"nameserver 1.1.1.1",
+				"options edns0",
+				"nameserver 2001:4860:4860::8888",
+			}, "\n"),
+			expected: []string{"1.1.1.1", "2001:4860:4860::8888"},
+		},
+		{
+			name:  "InvalidNameserver",
+			input: "nameserver abc",
+			err:   true,
+		},
+		{
+			name:  "Empty",
+			input: "",
+			err:   true,
+		},
+	}
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			result, err := parseResolvConf(strings.NewReader(tt.input))
+			if tt.err {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestNewResolverPoolWithoutServers(t *testing.T) {
+	t.Parallel()
+	_, err := NewResolverPool(nil)
+	require.ErrorIs(t, err, ErrNoResolvers)
+}
+
+func TestNewResolverPoolDefaultPort(t *testing.T) {
+	t.Parallel()
+	pool, err := NewResolverPool([]string{"8.8.8.8", "::1", "127.0.0.1:5353"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"8.8.8.8:53", "[::1]:53", "127.0.0.1:5353"}, pool.servers)
+}
+
+func TestResolverPoolExchange(t *testing.T) {
+	t.Parallel()
+	srv := newFakeServer(t, func(q dnsmessage.Question) (dnsmessage.RCode, []dnsmessage.Resource) {
+		return dnsmessage.RCodeSuccess, []dnsmessage.Resource{
+			newAResource(q.Name.String(), 60, net.IPv4(192, 168, 0, 1)),
+		}
+	})
+	pool, err := NewResolverPool([]string{srv.Addr()})
+	require.NoError(t, err)
+
+	resp, err := pool.Exchange(context.Background(), newQuery(t, "example.com.", dnsmessage.TypeA))
+	require.NoError(t, err)
+	require.Len(t, resp.Answers, 1)
+	require.Equal(t, &dnsmessage.AResource{A: [4]byte{192, 168, 0, 1}}, resp.Answers[0].Body)
+}
+
+func TestResolverPoolRoundRobin(t *testing.T) {
+	t.Parallel()
+	handler := func(q dnsmessage.Question) (dnsmessage.RCode, []dnsmessage.Resource) {
+		return dnsmessage.RCodeNameError, nil
+	}
+	srv1 := newFakeServer(t, handler)
+	srv2 := newFakeServer(t, handler)
+	pool, err := NewResolverPool([]string{srv1.Addr(), srv2.Addr()})
+	require.NoError(t, err)
+
+	for i := 0; i < 4; i++ {
+		_, err := pool.Exchange(context.Background(), newQuery(t, "example.com.", dnsmessage.TypeA))
+		require.NoError(t, err)
+	}
+	require.Equal(t, 2, srv1.Requests())
+	require.Equal(t, 2, srv2.Requests())
+}
+
+func TestResolverPoolRetriesNextServer(t *testing.T) {
+	t.Parallel()
+	// server that never responds
+	silent, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer silent.Close()
+
+	srv := newFakeServer(t, func(q dnsmessage.Question) (dnsmessage.RCode, []dnsmessage.Resource) {
+		return dnsmessage.RCodeSuccess, nil
+	})
+	pool, err := NewResolverPool([]string{silent.LocalAddr().String(), srv.Addr()},
+		WithResolverTimeout(100*time.Millisecond), WithRetries(1))
+	require.NoError(t, err)
+
+	_, err = pool.Exchange(context.Background(), newQuery(t, "example.com.", dnsmessage.TypeA))
+	require.NoError(t, err)
+	require.Equal(t, 1, srv.Requests())
+}
+
+func TestResolverPoolTimeout(t *testing.T) {
+	t.Parallel()
+	silent, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer silent.Close()
+
+	pool, err := NewResolverPool([]string{silent.LocalAddr().String()},
+		WithResolverTimeout(50*time.Millisecond), WithRetries(1))
+	require.NoError(t, err)
+
+	_, err = pool.Exchange(context.Background(), newQuery(t, "example.com.", dnsmessage.TypeA))
+	require.Error(t, err)
+}
@@ -0,0 +1,190 @@
+package dns
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net"
+	"strings"
+	"sync"
+
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+// MetaWildcard is the meta field of requests to addresses of wildcard DNS records, its value is the domain of the record
+const MetaWildcard = "dns_wildcard"
+
+var ErrWildcard = errors.New("resolved by wildcard DNS record")
+
+// LookupIPFunc resolves IP addresses of the host
+type LookupIPFunc func(ctx context.Context, host string) ([]net.IP, error)
+
+type wildcardEntry struct {
+	// ips are addresses of the wildcard record, empty if the domain doesn't have it
+	ips map[string]bool
+	// ready is closed when the random label is resolved, concurrent lookups of the same domain wait for it
+	ready chan struct{}
+}
+
+// WildcardDetector detects names resolved by wildcard DNS records, e.g. *.example.com.
+// The parent domain of each name is checked once by resolving a random label in it,
+// the name is resolved by the wildcard record if all its addresses are returned for the random label too.
+type WildcardDetector struct {
+	lookupIP    LookupIPFunc
+	drop        bool
+	randomLabel func() string
+
+	mu      sync.Mutex
+	domains map[string]*wildcardEntry
+	// tagged are domains of wildcard records by resolved addresses
+	tagged map[string]string
+}
+
+type WildcardOption func(*WildcardDetector)
+
+// WithWildcardDrop enables returning ErrWildcard for names resolved by wildcard records,
+// their addresses are returned and remembered for tagging of requests by default
+func WithWildcardDrop() WildcardOption {
+	return func(d *WildcardDetector) {
+		d.drop = true
+	}
+}
+
+func NewWildcardDetector(lookupIP LookupIPFunc, opts ...WildcardOption) *WildcardDetector {
+	d := &WildcardDetector{
+		lookupIP: lookupIP,
+		randomLabel: func() string {
+			return fmt.Sprintf("sx-%012x", rand.Int63n(1<<48))
+		},
+		domains: make(map[string]*wildcardEntry),
+		tagged:  make(map[string]string),
+	}
+	for _, o := range opts {
+		o(d)
+	}
+	return d
+}
+
+// LookupIP resolves the host and checks whether it is resolved by the wildcard record of its parent domain
+func (d *WildcardDetector) LookupIP(ctx context.Context, host string) ([]net.IP, error) {
+	ips, err := d.lookupIP(ctx, host)
+	if err != nil || len(ips) == 0 || net.ParseIP(host) != nil {
+		return ips, err
+	}
+	domain, err := d.Wildcard(ctx, host, ips)
+	if err != nil || len(domain) == 0 {
+		return ips, err
+	}
+	if d.drop {
+		return nil, fmt.Errorf("%s: %w *.%s", host, ErrWildcard, domain)
+	}
+	d.mu.Lock()
+	for _, ip := range ips {
+		d.tagged[ip.String()] = domain
+	}
+	d.mu.Unlock()
+	return ips, nil
+}
+
+// Wildcard returns the parent domain of the name if all ips of the name are addresses of its wildcard record,
+// an empty domain is returned otherwise
+func (d *WildcardDetector) Wildcard(ctx context.Context, name string, ips []net.IP) (string, error) {
+	name = strings.ToLower(strings.TrimSuffix(name, "."))
+	labels := strings.SplitN(name, ".", 2)
+	// top-level domains are not checked
+	if len(labels) < 2 || !strings.Contains(labels[1], ".") {
+		return "", nil
+	}
+	domain := labels[1]
+
+	d.mu.Lock()
+	e, ok := d.domains[domain]
+	if !ok {
+		e = &wildcardEntry{ready: make(chan struct{})}
+		d.domains[domain] = e
+	}
+	d.mu.Unlock()
+	if ok {
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-e.ready:
+		}
+	} else {
+		e.ips = d.resolveWildcard(ctx, domain)
+		close(e.ready)
+	}
+
+	if len(e.ips) == 0 {
+		return "", nil
+	}
+	for _, ip := range ips {
+		if !e.ips[ip.String()] {
+			return "", nil
+		}
+	}
+	return domain, nil
+}
+
+// resolveWildcard returns addresses of the random label of the domain,
+// any lookup error means there is no wildcard record
+func (d *WildcardDetector) resolveWildcard(ctx context.Context, domain string) map[string]bool {
+	ips, err := d.lookupIP(ctx, d.randomLabel()+"."+domain)
+	if err != nil {
+		return nil
+	}
+	result := make(map[string]bool, len(ips))
+	for _, ip := range ips {
+		result[ip.String()] = true
+	}
+	return result
+}
+
+// Domain returns the domain of the wildcard record that resolved to the ip, if any
+func (d *WildcardDetector) Domain(ip net.IP) string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.tagged[ip.String()]
+}
+
+type wildcardTagGenerator struct {
+	delegate scan.RequestGenerator
+	detector *WildcardDetector
+}
+
+// NewWildcardTagGenerator adds the MetaWildcard field to requests to addresses of wildcard records
+// resolved by the detector, such requests are usually generated from names resolved by delegate inputs
+func NewWildcardTagGenerator(delegate scan.RequestGenerator, detector *WildcardDetector) scan.RequestGenerator {
+	return &wildcardTagGenerator{delegate: delegate, detector: detector}
+}
+
+func (g *wildcardTagGenerator) GenerateRequests(ctx context.Context, r *scan.Range) (<-chan *scan.Request, error) {
+	requests, err := g.delegate.GenerateRequests(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	out := make(chan *scan.Request, cap(requests))
+	go func() {
+		defer close(out)
+		for request := range requests {
+			if request.Err == nil && request.DstIP != nil {
+				if domain := g.detector.Domain(request.DstIP); len(domain) > 0 {
+					// meta maps may be shared by requests of the same host
+					meta := make(map[string]interface{}, len(request.Meta)+1)
+					for k, v := range request.Meta {
+						meta[k] = v
+					}
+					meta[MetaWildcard] = domain
+					request.Meta = meta
+				}
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case out <- request:
+			}
+		}
+	}()
+	return out, nil
+}
@@ -0,0 +1,159 @@
+package dns
+
+import (
+	"context"
+	"net"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+// fakeLookup resolves names of the zone, names of wildcard domains that are not in the zone
+// are resolved to addresses of the wildcard record
+type fakeLookup struct {
+	zone      map[string][]net.IP
+	wildcards map[string][]net.IP
+	lookups   int64
+}
+
+func (l *fakeLookup) lookupIP(_ context.Context, host string) ([]net.IP, error) {
+	atomic.AddInt64(&l.lookups, 1)
+	if ips, ok := l.zone[host]; ok {
+		return ips, nil
+	}
+	for domain, ips := range l.wildcards {
+		if len(host) > len(domain) && host[len(host)-len(domain)-1:] == "."+domain {
+			return ips, nil
+		}
+	}
+	return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+}
+
+func newFakeLookup() *fakeLookup {
+	return &fakeLookup{
+		zone: map[string][]net.IP{
+			"www.example.com":   {net.IPv4(192, 168, 0, 1).To4()},
+			"api.wild.example":  {net.IPv4(10, 0, 0, 2).To4()},
+			"mail.wild.example": {net.IPv4(10, 0, 0, 1).To4()},
+		},
+		wildcards: map[string][]net.IP{
+			"wild.example": {net.IPv4(10, 0, 0, 1).To4()},
+		},
+	}
+}
+
+func TestWildcardDetectorLookupIP(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		host     string
+		expected []net.IP
+		domain   string
+	}{
+		{
+			name:     "NoWildcard",
+			host:     "www.example.com",
+			expected: []net.IP{net.IPv4(192, 168, 0, 1).To4()},
+		},
+		{
+			name:     "WildcardDomainOwnAddress",
+			host:     "api.wild.example",
+			expected: []net.IP{net.IPv4(10, 0, 0, 2).To4()},
+		},
+		{
+			name:     "WildcardAddress",
+			host:     "mail.wild.example",
+			expected: []net.IP{net.IPv4(10, 0, 0, 1).To4()},
+			domain:   "wild.example",
+		},
+		{
+			name:     "RandomName",
+			host:     "anything.wild.example",
+			expected: []net.IP{net.IPv4(10, 0, 0, 1).To4()},
+			domain:   "wild.example",
+		},
+	}
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			d := NewWildcardDetector(newFakeLookup().lookupIP)
+
+			ips, err := d.LookupIP(context.Background(), tt.host)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, ips)
+			require.Equal(t, tt.domain, d.Domain(ips[0]))
+		})
+	}
+}
+
+func TestWildcardDetectorDrop(t *testing.T) {
+	t.Parallel()
+	d := NewWildcardDetector(newFakeLookup().lookupIP, WithWildcardDrop())
+
+	_, err := d.LookupIP(context.Background(), "mail.wild.example")
+	require.ErrorIs(t, err, ErrWildcard)
+	require.EqualError(t, err, "mail.wild.example: resolved by wildcard DNS record *.wild.example")
+
+	ips, err := d.LookupIP(context.Background(), "api.wild.example")
+	require.NoError(t, err)
+	require.Equal(t, []net.IP{net.IPv4(10, 0, 0, 2).To4()}, ips)
+}
+
+func TestWildcardDetectorChecksDomainOnce(t *testing.T) {
+	t.Parallel()
+	lookup := newFakeLookup()
+	d := NewWildcardDetector(lookup.lookupIP)
+
+	for _, host := range []string{"a.wild.example", "b.wild.example", "c.wild.example"} {
+		_, err := d.LookupIP(context.Background(), host)
+		require.NoError(t, err)
+	}
+	// three names and one random label
+	require.Equal(t, int64(4), atomic.LoadInt64(&lookup.lookups))
+}
+
+func TestWildcardDetectorTopLevelDomain(t *testing.T) {
+	t.Parallel()
+	lookup := &fakeLookup{wildcards: map[string][]net.IP{"example": {net.IPv4(10, 0, 0, 1).To4()}}}
+	d := NewWildcardDetector(lookup.lookupIP)
+
+	domain, err := d.Wildcard(context.Background(), "www.example", []net.IP{net.IPv4(10, 0, 0, 1).To4()})
+	require.NoError(t, err)
+	require.Empty(t, domain)
+}
+
+type requestChan <-chan *scan.Request
+
+func (c requestChan) GenerateRequests(context.Context, *scan.Range) (<-chan *scan.Request, error) {
+	return c, nil
+}
+
+func TestWildcardTagGenerator(t *testing.T) {
+	t.Parallel()
+	d := NewWildcardDetector(newFakeLookup().lookupIP)
+	_, err := d.LookupIP(context.Background(), "mail.wild.example")
+	require.NoError(t, err)
+
+	meta := map[string]interface{}{"env": "prod"}
+	requests := make(chan *scan.Request, 2)
+	requests <- &scan.Request{DstIP: net.IPv4(10, 0, 0, 1).To4(), DstPort: 80, Meta: meta}
+	requests <- &scan.Request{DstIP: net.IPv4(10, 0, 0, 2).To4(), DstPort: 80, Meta: meta}
+	close(requests)
+	reqgen := NewWildcardTagGenerator(requestChan(requests), d)
+
+	out, err := reqgen.GenerateRequests(context.Background(), &scan.Range{})
+	require.NoError(t, err)
+	var result []*scan.Request
+	for r := range out {
+		result = append(result, r)
+	}
+	require.Equal(t, []*scan.Request{
+		{DstIP: net.IPv4(10, 0, 0, 1).To4(), DstPort: 80, Meta: map[string]interface{}{"env": "prod", MetaWildcard: "wild.example"}},
+		{DstIP: net.IPv4(10, 0, 0, 2).To4(), DstPort: 80, Meta: meta},
+	}, result)
+	// shared meta is not changed
+	require.Equal(t, map[string]interface{}{"env": "prod"}, meta)
+}
@@ -81,7 +81,12 @@ func NewScanner(proto string, opts ...ScannerOption) *Scanner {
 	return s
 }
 
-func (s *Scanner) Scan(ctx context.Context, r *scan.Request) (result scan.Result, err error) {
+func (s *Scanner) Scan(ctx context.Context, r *scan.Request) (scan.Result, error) {
+	result, err := s.scanTarget(ctx, r)
+	return result, scan.WrapError(err)
+}
+
+func (s *Scanner) scanTarget(ctx context.Context, r *scan.Request) (result scan.Result, err error) {
 	ctx, cancel := context.WithTimeout(ctx, s.dataTimeout)
 	defer cancel()
 	// TODO DNS names
@@ -78,7 +78,12 @@ func NewScanner(proto string, opts ...ScannerOption) *Scanner {
 	return s
 }
 
-func (s *Scanner) Scan(ctx context.Context, r *scan.Request) (result scan.Result, err error) {
+func (s *Scanner) Scan(ctx context.Context, r *scan.Request) (scan.Result, error) {
+	result, err := s.scanTarget(ctx, r)
+	return result, scan.WrapError(err)
+}
+
+func (s *Scanner) scanTarget(ctx context.Context, r *scan.Request) (result scan.Result, err error) {
 	// TODO DNS names
 	host := fmt.Sprintf("%s:%d", r.DstIP.String(), r.DstPort)
 	// retrieve main info
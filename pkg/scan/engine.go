@@ -4,6 +4,7 @@ package scan
 
 import (
 	"context"
+	"errors"
 	"net"
 	"sync"
 	"time"
@@ -103,7 +104,7 @@ func mergeErrChan(ctx context.Context, channels ...<-chan error) <-chan error {
 				if !ok {
 					return
 				}
-				writeError(ctx, out, e)
+				writeError(ctx, out, WrapError(e))
 			}
 		}
 	}
@@ -131,6 +132,7 @@ func SetupPacketEngine(rw packet.ReadWriter, m PacketMethod) EngineResulter {
 }
 
 type Scanner interface {
+	// Scan scans the target of the request, scanners of sx mark errors with their kinds, e.g. ErrTimeout or ErrParse
 	Scan(ctx context.Context, r *Request) (Result, error)
 }
 
@@ -153,11 +155,37 @@ func (s *rateLimitScanner) Scan(ctx context.Context, r *Request) (Result, error)
 	return s.Scanner.Scan(ctx, r)
 }
 
+type sequenceScanner struct {
+	scanners []Scanner
+}
+
+// NewSequenceScanner creates a scanner that tries scanners in order until one of them returns a result.
+// The sequence is stopped on a dial error since other scanners can't connect either.
+func NewSequenceScanner(scanners ...Scanner) Scanner {
+	return &sequenceScanner{scanners: scanners}
+}
+
+func (s *sequenceScanner) Scan(ctx context.Context, r *Request) (result Result, err error) {
+	for _, scanner := range s.scanners {
+		if result, err = scanner.Scan(ctx, r); result != nil || isDialError(err) {
+			return
+		}
+	}
+	return
+}
+
+func isDialError(err error) bool {
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
 type GenericEngine struct {
-	reqgen      RequestGenerator
-	scanner     Scanner
-	results     ResultChan
-	workerCount int
+	reqgen          RequestGenerator
+	scanner         Scanner
+	results         ResultChan
+	workerCount     int
+	hostConcurrency int
+	policies        []*TargetPolicy
 }
 
 // Assert that GenericEngine conforms to the scan.EngineResulter interface
@@ -171,6 +199,22 @@ func WithScanWorkerCount(workerCount int) GenericEngineOption {
 	}
 }
 
+// WithHostConcurrency limits the number of concurrent scan requests to one host,
+// requests of other hosts are scanned meanwhile
+func WithHostConcurrency(limit int) GenericEngineOption {
+	return func(s *GenericEngine) {
+		s.hostConcurrency = limit
+	}
+}
+
+// WithTargetPolicies sets policies of targets matching request metadata,
+// the first matching policy limits the rate, ports and retries of the target
+func WithTargetPolicies(policies ...*TargetPolicy) GenericEngineOption {
+	return func(s *GenericEngine) {
+		s.policies = policies
+	}
+}
+
 func NewScanEngine(reqgen RequestGenerator,
 	scanner Scanner, results ResultChan, opts ...GenericEngineOption) *GenericEngine {
 	s := &GenericEngine{
@@ -194,18 +238,26 @@ func (e *GenericEngine) Start(ctx context.Context, r *Range) (<-chan interface{}
 	errc := make(chan error, 100)
 	requests, err := e.reqgen.GenerateRequests(ctx, r)
 	if err != nil {
-		errc <- err
+		errc <- WrapError(err)
 		close(errc)
 		close(done)
 		return done, errc
 	}
+	if len(e.policies) > 0 {
+		requests = newPolicyScheduler(e.policies, 10*e.workerCount).schedule(ctx, requests)
+	}
+	var sched *hostScheduler
+	if e.hostConcurrency > 0 {
+		sched = newHostScheduler(e.hostConcurrency, 10*e.workerCount)
+		requests = sched.schedule(ctx, requests)
+	}
 	go func() {
 		defer close(done)
 		defer close(errc)
 		var wg sync.WaitGroup
 		for i := 1; i <= e.workerCount; i++ {
 			wg.Add(1)
-			go e.worker(ctx, &wg, requests, errc)
+			go e.worker(ctx, &wg, requests, errc, sched)
 		}
 		wg.Wait()
 	}()
@@ -213,7 +265,7 @@ func (e *GenericEngine) Start(ctx context.Context, r *Range) (<-chan interface{}
 }
 
 func (e *GenericEngine) worker(ctx context.Context, wg *sync.WaitGroup,
-	requests <-chan *Request, errc chan<- error) {
+	requests <-chan *Request, errc chan<- error, sched *hostScheduler) {
 	defer wg.Done()
 	for {
 		select {
@@ -223,20 +275,64 @@ func (e *GenericEngine) worker(ctx context.Context, wg *sync.WaitGroup,
 			if !ok {
 				return
 			}
-			if r.Err != nil {
-				writeError(ctx, errc, r.Err)
-				continue
+			e.scan(ctx, r, errc)
+			if sched != nil {
+				sched.release(ctx, r)
 			}
-			result, err := e.scanner.Scan(ctx, r)
-			if err != nil {
-				writeError(ctx, errc, err)
-				continue
-			}
-			if result != nil {
-				e.results.Put(result)
+		}
+	}
+}
+
+func (e *GenericEngine) scan(ctx context.Context, r *Request, errc chan<- error) {
+	if r.Err != nil {
+		writeError(ctx, errc, NewTargetError(r, true, WrapError(r.Err)))
+		return
+	}
+	result, err := e.scanner.Scan(ctx, r)
+	if err != nil {
+		result, err = e.retry(ctx, r, err)
+	}
+	if err != nil {
+		writeError(ctx, errc, NewTargetError(r, false, WrapError(err)))
+		return
+	}
+	e.putResult(result, r.Meta)
+}
+
+// retry scans the failed request again if its target policy allows retries,
+// attempts are spaced out by the policy interval
+func (e *GenericEngine) retry(ctx context.Context, r *Request, err error) (result Result, _ error) {
+	p := matchPolicy(e.policies, r)
+	if p == nil {
+		return nil, err
+	}
+	for i := 0; i < p.Retries && err != nil; i++ {
+		if p.Interval > 0 {
+			select {
+			case <-ctx.Done():
+				return nil, err
+			case <-time.After(p.Interval):
 			}
 		}
+		result, err = e.scanner.Scan(ctx, r)
+	}
+	return result, err
+}
+
+func (e *GenericEngine) putResult(result Result, meta map[string]interface{}) {
+	if results, ok := result.(MultiResult); ok {
+		for _, r := range results {
+			e.putResult(r, meta)
+		}
+		return
+	}
+	if result == nil {
+		return
+	}
+	if meta != nil {
+		result = &MetaResult{Result: result, Meta: meta}
 	}
+	e.results.Put(result)
 }
 
 func writeError(ctx context.Context, out chan<- error, err error) {
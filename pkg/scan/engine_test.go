@@ -6,6 +6,7 @@ import (
 	"context"
 	"errors"
 	"net"
+	"os"
 	"sort"
 	"testing"
 	"time"
@@ -233,6 +234,73 @@ func TestRateLimitScanner(t *testing.T) {
 	waitDone(t, done)
 }
 
+func TestSequenceScanner(t *testing.T) {
+	t.Parallel()
+
+	req := &Request{DstIP: net.IPv4(192, 168, 0, 1), DstPort: 1080}
+	dialErr := &net.OpError{Op: "dial", Err: errors.New("connection refused")}
+	readErr := &net.OpError{Op: "read", Err: errors.New("connection reset by peer")}
+	expectedResult := &mockScanResult{"id1"}
+
+	tests := []struct {
+		name     string
+		first    []interface{}
+		second   []interface{}
+		expected Result
+		err      bool
+	}{
+		{
+			name:     "FirstResult",
+			first:    []interface{}{expectedResult, nil},
+			expected: expectedResult,
+		},
+		{
+			name:     "SecondResult",
+			first:    []interface{}{nil, nil},
+			second:   []interface{}{expectedResult, nil},
+			expected: expectedResult,
+		},
+		{
+			name:     "SecondResultAfterReadError",
+			first:    []interface{}{nil, readErr},
+			second:   []interface{}{expectedResult, nil},
+			expected: expectedResult,
+		},
+		{
+			name:  "DialError",
+			first: []interface{}{nil, dialErr},
+			err:   true,
+		},
+		{
+			name:   "NoResult",
+			first:  []interface{}{nil, nil},
+			second: []interface{}{nil, nil},
+		},
+	}
+
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+			first := NewMockScanner(ctrl)
+			second := NewMockScanner(ctrl)
+			first.EXPECT().Scan(gomock.Not(gomock.Nil()), req).Return(tt.first...)
+			if tt.second != nil {
+				second.EXPECT().Scan(gomock.Not(gomock.Nil()), req).Return(tt.second...)
+			}
+
+			result, err := NewSequenceScanner(first, second).Scan(context.Background(), req)
+			if tt.err {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Equal(t, tt.expected, result)
+		})
+	}
+}
+
 func TestScanEngineWithRequestGeneratorError(t *testing.T) {
 	t.Parallel()
 
@@ -277,7 +345,9 @@ func TestScanEngineWithRequestError(t *testing.T) {
 
 		_, errc := engine.Start(ctx, &Range{})
 		err := <-errc
-		require.Error(t, err)
+		var targetErr *TargetError
+		require.ErrorAs(t, err, &targetErr)
+		require.True(t, targetErr.Skipped)
 	}()
 	waitDone(t, done)
 }
@@ -305,7 +375,43 @@ func TestScanEngineWithScannerError(t *testing.T) {
 
 		_, errc := engine.Start(ctx, &Range{})
 		err := <-errc
-		require.Error(t, err)
+		var targetErr *TargetError
+		require.ErrorAs(t, err, &targetErr)
+		require.Equal(t, req1.DstIP, targetErr.IP)
+		require.Equal(t, uint16(22), targetErr.Port)
+		require.False(t, targetErr.Skipped)
+		require.Equal(t, "scan error", err.Error())
+	}()
+	waitDone(t, done)
+}
+
+func TestScanEngineWithScannerTimeoutError(t *testing.T) {
+	t.Parallel()
+
+	done := make(chan interface{})
+	go func() {
+		defer close(done)
+
+		ctrl := gomock.NewController(t)
+		reqgen := NewMockRequestGenerator(ctrl)
+		scanner := NewMockScanner(ctrl)
+		ctx := context.Background()
+
+		requests := make(chan *Request, 1)
+		req1 := &Request{DstIP: net.IPv4(192, 168, 0, 1), DstPort: 22}
+		requests <- req1
+		close(requests)
+		reqgen.EXPECT().GenerateRequests(gomock.Not(gomock.Nil()), &Range{}).
+			Return(requests, nil)
+		scanErr := &net.OpError{Op: "read", Net: "tcp", Err: os.ErrDeadlineExceeded}
+		scanner.EXPECT().Scan(gomock.Not(gomock.Nil()), req1).Return(nil, scanErr)
+		engine := NewScanEngine(reqgen, scanner, NewResultChan(ctx, 10))
+
+		_, errc := engine.Start(ctx, &Range{})
+		err := <-errc
+		require.ErrorIs(t, err, ErrTimeout)
+		require.ErrorIs(t, err, os.ErrDeadlineExceeded)
+		require.Equal(t, scanErr.Error(), err.Error())
 	}()
 	waitDone(t, done)
 }
@@ -363,6 +469,88 @@ func TestScanEngineWithResults(t *testing.T) {
 	waitDone(t, done)
 }
 
+func TestScanEngineWithMultiResult(t *testing.T) {
+	t.Parallel()
+
+	done := make(chan interface{})
+	go func() {
+		defer close(done)
+
+		ctrl := gomock.NewController(t)
+		reqgen := NewMockRequestGenerator(ctrl)
+		scanner := NewMockScanner(ctrl)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		requests := make(chan *Request, 1)
+		req1 := &Request{DstName: "example.com", DstPort: 1}
+		requests <- req1
+		close(requests)
+		reqgen.EXPECT().GenerateRequests(gomock.Not(gomock.Nil()), &Range{}).
+			Return(requests, nil)
+
+		scanner.EXPECT().Scan(gomock.Not(gomock.Nil()), req1).
+			Return(MultiResult{&mockScanResult{"id1"}, &mockScanResult{"id2"}}, nil)
+
+		resultCh := NewResultChan(ctx, 10)
+		engine := NewScanEngine(reqgen, scanner, resultCh)
+
+		done, errc := engine.Start(ctx, &Range{})
+		<-done
+		results := make([]Result, 2)
+		results[0] = <-resultCh.Chan()
+		results[1] = <-resultCh.Chan()
+		cancel()
+		require.Zero(t, len(errc), "error channel is not empty")
+		result, ok := <-resultCh.Chan()
+		if ok {
+			require.Fail(t, "result channel contains more elements than expected: ", result)
+		}
+		require.Equal(t, []Result{
+			&mockScanResult{"id1"},
+			&mockScanResult{"id2"},
+		}, results)
+	}()
+	waitDone(t, done)
+}
+
+func TestScanEngineWithRequestMeta(t *testing.T) {
+	t.Parallel()
+
+	done := make(chan interface{})
+	go func() {
+		defer close(done)
+
+		ctrl := gomock.NewController(t)
+		reqgen := NewMockRequestGenerator(ctrl)
+		scanner := NewMockScanner(ctrl)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		meta := map[string]interface{}{"kind": "ec2"}
+		requests := make(chan *Request, 1)
+		req1 := &Request{DstIP: net.IPv4(192, 168, 0, 1), DstPort: 22, Meta: meta}
+		requests <- req1
+		close(requests)
+		reqgen.EXPECT().GenerateRequests(gomock.Not(gomock.Nil()), &Range{}).
+			Return(requests, nil)
+
+		scanner.EXPECT().Scan(gomock.Not(gomock.Nil()), req1).
+			Return(&mockScanResult{"id1"}, nil)
+
+		resultCh := NewResultChan(ctx, 10)
+		engine := NewScanEngine(reqgen, scanner, resultCh)
+
+		done, errc := engine.Start(ctx, &Range{})
+		<-done
+		result := <-resultCh.Chan()
+		cancel()
+		require.Zero(t, len(errc), "error channel is not empty")
+		require.Equal(t, &MetaResult{Result: &mockScanResult{"id1"}, Meta: meta}, result)
+	}()
+	waitDone(t, done)
+}
+
 type mockScanResult struct {
 	id string
 }
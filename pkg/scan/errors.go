@@ -0,0 +1,108 @@
+package scan
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"os"
+	"strconv"
+	"syscall"
+)
+
+// Error kinds that library users can check with errors.Is
+var (
+	ErrTimeout     = errors.New("timeout")
+	ErrUnreachable = errors.New("unreachable")
+	ErrPermission  = errors.New("permission denied")
+	ErrParse       = errors.New("parse error")
+)
+
+// Error is an error of the particular kind, e.g. ErrTimeout.
+// It keeps the message of the underlying error, so
+// errors.Is matches both the kind and the wrapped error chain.
+type Error struct {
+	Kind error
+	Err  error
+}
+
+// NewError returns err marked with the given kind.
+func NewError(kind, err error) *Error {
+	return &Error{Kind: kind, Err: err}
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+func (e *Error) Is(target error) bool {
+	return e.Kind == target
+}
+
+// TargetError is the error of the scan request of the particular target
+type TargetError struct {
+	IP   net.IP
+	Port uint16
+	// Name is the DNS name of the target, see Request.DstName
+	Name string
+	// Skipped is set if the target was not scanned because of the error of the request itself,
+	// e.g. the invalid line of the input file or the unreachable host
+	Skipped bool
+	Err     error
+}
+
+// NewTargetError returns err with the target of the request r
+func NewTargetError(r *Request, skipped bool, err error) *TargetError {
+	return &TargetError{IP: r.DstIP, Port: r.DstPort, Name: r.DstName, Skipped: skipped, Err: err}
+}
+
+func (e *TargetError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *TargetError) Unwrap() error {
+	return e.Err
+}
+
+// WrapError classifies err and marks it with the corresponding kind,
+// errors of unknown kind are returned as is.
+func WrapError(err error) error {
+	var scanErr *Error
+	if err == nil || errors.As(err, &scanErr) {
+		return err
+	}
+	if kind := errorKind(err); kind != nil {
+		return NewError(kind, err)
+	}
+	return err
+}
+
+func errorKind(err error) error {
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, os.ErrDeadlineExceeded) ||
+		(errors.As(err, &netErr) && netErr.Timeout()) {
+		return ErrTimeout
+	}
+	if errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.EHOSTUNREACH) ||
+		errors.Is(err, syscall.ENETUNREACH) || errors.Is(err, syscall.EHOSTDOWN) {
+		return ErrUnreachable
+	}
+	if errors.Is(err, os.ErrPermission) {
+		return ErrPermission
+	}
+	var (
+		syntaxErr *json.SyntaxError
+		typeErr   *json.UnmarshalTypeError
+		numErr    *strconv.NumError
+		parseErr  *net.ParseError
+	)
+	if errors.As(err, &syntaxErr) || errors.As(err, &typeErr) ||
+		errors.As(err, &numErr) || errors.As(err, &parseErr) {
+		return ErrParse
+	}
+	return nil
+}
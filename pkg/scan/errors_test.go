@@ -0,0 +1,120 @@
+package scan
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWrapError(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		err  error
+		kind error
+	}{
+		{
+			name: "ContextDeadline",
+			err:  context.DeadlineExceeded,
+			kind: ErrTimeout,
+		},
+		{
+			name: "DialTimeout",
+			err:  &net.OpError{Op: "dial", Net: "tcp", Err: os.ErrDeadlineExceeded},
+			kind: ErrTimeout,
+		},
+		{
+			name: "ConnectionRefused",
+			err:  &net.OpError{Op: "dial", Net: "tcp", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)},
+			kind: ErrUnreachable,
+		},
+		{
+			name: "HostUnreachable",
+			err:  fmt.Errorf("send: %w", syscall.EHOSTUNREACH),
+			kind: ErrUnreachable,
+		},
+		{
+			name: "NetworkUnreachable",
+			err:  syscall.ENETUNREACH,
+			kind: ErrUnreachable,
+		},
+		{
+			name: "OperationNotPermitted",
+			err:  os.NewSyscallError("socket", syscall.EPERM),
+			kind: ErrPermission,
+		},
+		{
+			name: "AccessDenied",
+			err:  &os.PathError{Op: "open", Path: "ips.jsonl", Err: syscall.EACCES},
+			kind: ErrPermission,
+		},
+		{
+			name: "JSONSyntax",
+			err:  json.Unmarshal([]byte("{"), &struct{}{}),
+			kind: ErrParse,
+		},
+		{
+			name: "Number",
+			err:  func() error { _, err := strconv.Atoi("abc"); return err }(),
+			kind: ErrParse,
+		},
+		{
+			name: "InputError",
+			err:  ErrJSON,
+			kind: ErrParse,
+		},
+	}
+
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			err := WrapError(tt.err)
+			require.ErrorIs(t, err, tt.kind)
+			require.ErrorIs(t, err, tt.err)
+			require.Equal(t, tt.err.Error(), err.Error())
+			for _, kind := range []error{ErrTimeout, ErrUnreachable, ErrPermission, ErrParse} {
+				if kind != tt.kind {
+					require.NotErrorIs(t, err, kind)
+				}
+			}
+		})
+	}
+}
+
+func TestWrapErrorUnknownKind(t *testing.T) {
+	t.Parallel()
+	err := errors.New("scan error")
+	require.Same(t, err, WrapError(err))
+	require.NoError(t, WrapError(nil))
+}
+
+func TestWrapErrorKeepsKind(t *testing.T) {
+	t.Parallel()
+	err := NewError(ErrUnreachable, context.DeadlineExceeded)
+	wrapped := WrapError(fmt.Errorf("scan: %w", err))
+	require.ErrorIs(t, wrapped, ErrUnreachable)
+	require.NotErrorIs(t, wrapped, ErrTimeout)
+}
+
+func TestTargetError(t *testing.T) {
+	t.Parallel()
+	r := &Request{DstIP: net.IPv4(192, 168, 0, 1).To4(), DstPort: 22, DstName: "example.com"}
+	err := NewTargetError(r, true, NewError(ErrTimeout, os.ErrDeadlineExceeded))
+
+	require.Equal(t, &TargetError{IP: r.DstIP, Port: 22, Name: "example.com", Skipped: true,
+		Err: NewError(ErrTimeout, os.ErrDeadlineExceeded)}, err)
+	require.Equal(t, os.ErrDeadlineExceeded.Error(), err.Error())
+	require.ErrorIs(t, err, ErrTimeout)
+	require.ErrorIs(t, err, os.ErrDeadlineExceeded)
+	// the target error is not classified again
+	require.Equal(t, err, WrapError(err))
+}
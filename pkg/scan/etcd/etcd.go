@@ -0,0 +1,257 @@
+package etcd
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+const (
+	ScanType = "etcd"
+
+	defaultDataTimeout = 5 * time.Second
+
+	// maxResponseSize limits the size of read responses, member lists of big clusters fit in it
+	maxResponseSize = 1 << 20
+)
+
+var (
+	errNotEtcd = errors.New("not an etcd server")
+	errStatus  = errors.New("unexpected HTTP status")
+)
+
+type ScanResult struct {
+	ScanType       string `json:"scan"`
+	Proto          string `json:"proto"`
+	Host           string `json:"host"`
+	Version        string `json:"version"`
+	ClusterVersion string `json:"cluster_version,omitempty"`
+	// API is the key-value API available on the client port: v3, v3beta (etcd 3.3) or v2
+	API         string `json:"api,omitempty"`
+	AuthEnabled bool   `json:"auth_enabled"`
+	// KeysReadable is true if keys are read without credentials, KeyCount is the number of keys of the v3 API
+	KeysReadable bool      `json:"keys_readable"`
+	KeyCount     int64     `json:"key_count,omitempty"`
+	Members      []*Member `json:"members,omitempty"`
+}
+
+type Member struct {
+	ID         string   `json:"id"`
+	Name       string   `json:"name"`
+	PeerURLs   []string `json:"peer_urls,omitempty"`
+	ClientURLs []string `json:"client_urls,omitempty"`
+}
+
+func (r *ScanResult) String() string {
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "%s://%s %s", r.Proto, r.Host, r.Version)
+	if len(r.API) > 0 {
+		fmt.Fprintf(&buf, " api:%s", r.API)
+	}
+	if r.AuthEnabled {
+		buf.WriteString(" auth")
+	} else {
+		buf.WriteString(" no-auth")
+	}
+	if r.KeysReadable {
+		fmt.Fprintf(&buf, " keys:%d", r.KeyCount)
+	}
+	fmt.Fprintf(&buf, " members:%d", len(r.Members))
+	return buf.String()
+}
+
+func (r *ScanResult) ID() string {
+	return r.Host
+}
+
+func (r *ScanResult) MarshalJSON() ([]byte, error) {
+	// Type definition for the recursive call
+	type JScanResult ScanResult
+	// This works because JScanResult doesn't have a MarshalJSON function associated with it
+	return json.Marshal(JScanResult(*r))
+}
+
+type Scanner struct {
+	client      *http.Client
+	proto       string
+	dataTimeout time.Duration
+}
+
+// Assert that etcd.Scanner conforms to the scan.Scanner interface
+var _ scan.Scanner = (*Scanner)(nil)
+
+type ScannerOption func(*Scanner)
+
+func WithDataTimeout(timeout time.Duration) ScannerOption {
+	return func(s *Scanner) {
+		s.dataTimeout = timeout
+	}
+}
+
+func NewScanner(proto string, opts ...ScannerOption) *Scanner {
+	tr := &http.Transport{
+		MaxConnsPerHost:   1,
+		DisableKeepAlives: true,
+		TLSClientConfig: &tls.Config{
+			InsecureSkipVerify: true,
+		},
+	}
+	s := &Scanner{
+		client:      &http.Client{Transport: tr},
+		proto:       proto,
+		dataTimeout: defaultDataTimeout,
+	}
+	for _, o := range opts {
+		o(s)
+	}
+	return s
+}
+
+// Scan requests the version and the member list of the etcd server without credentials.
+// The v3 API is accessed through the JSON gateway of gRPC services on the client port,
+// the v2 API is checked only if the v3 API is not available
+func (s *Scanner) Scan(ctx context.Context, r *scan.Request) (scan.Result, error) {
+	result, err := s.scanTarget(ctx, r)
+	return result, scan.WrapError(err)
+}
+
+func (s *Scanner) scanTarget(ctx context.Context, r *scan.Request) (result scan.Result, err error) {
+	host := fmt.Sprintf("%s:%d", r.DstIP.String(), r.DstPort)
+	baseURL := fmt.Sprintf("%s://%s", s.proto, host)
+
+	var version struct {
+		Server  string `json:"etcdserver"`
+		Cluster string `json:"etcdcluster"`
+	}
+	if _, err = s.do(ctx, http.MethodGet, baseURL+"/version", nil, &version); err != nil {
+		return
+	}
+	if len(version.Server) == 0 {
+		return nil, errNotEtcd
+	}
+	res := &ScanResult{
+		ScanType:       ScanType,
+		Proto:          s.proto,
+		Host:           host,
+		Version:        version.Server,
+		ClusterVersion: version.Cluster,
+	}
+	// only the error of the version request is returned, other checks depend on the version and configuration
+	if !s.scanV3(ctx, baseURL, res) {
+		s.scanV2(ctx, baseURL, res)
+	}
+	return res, nil
+}
+
+// scanV3 returns false if the v3 API is not available
+func (s *Scanner) scanV3(ctx context.Context, baseURL string, res *ScanResult) bool {
+	for _, api := range []string{"v3", "v3beta"} {
+		var members struct {
+			Members []struct {
+				ID         uint64   `json:"ID,string"`
+				Name       string   `json:"name"`
+				PeerURLs   []string `json:"peerURLs"`
+				ClientURLs []string `json:"clientURLs"`
+			} `json:"members"`
+		}
+		status, err := s.do(ctx, http.MethodPost, fmt.Sprintf("%s/%s/cluster/member/list", baseURL, api),
+			[]byte("{}"), &members)
+		if err != nil {
+			continue
+		}
+		res.API = api
+		if status == http.StatusOK {
+			for _, m := range members.Members {
+				res.Members = append(res.Members, &Member{
+					ID: fmt.Sprintf("%x", m.ID), Name: m.Name, PeerURLs: m.PeerURLs, ClientURLs: m.ClientURLs})
+			}
+		}
+
+		// count all keys from the empty key to the end of the keyspace
+		var keys struct {
+			Count int64 `json:"count,string"`
+		}
+		status, err = s.do(ctx, http.MethodPost, fmt.Sprintf("%s/%s/kv/range", baseURL, api),
+			[]byte(`{"key":"AA==","range_end":"AA==","count_only":true}`), &keys)
+		switch {
+		case status == http.StatusUnauthorized || status == http.StatusForbidden:
+			res.AuthEnabled = true
+		case err == nil && status == http.StatusOK:
+			res.KeysReadable = true
+			res.KeyCount = keys.Count
+		}
+		return true
+	}
+	return false
+}
+
+func (s *Scanner) scanV2(ctx context.Context, baseURL string, res *ScanResult) {
+	var members struct {
+		Members []struct {
+			ID         string   `json:"id"`
+			Name       string   `json:"name"`
+			PeerURLs   []string `json:"peerURLs"`
+			ClientURLs []string `json:"clientURLs"`
+		} `json:"members"`
+	}
+	status, err := s.do(ctx, http.MethodGet, baseURL+"/v2/members", nil, &members)
+	if err != nil || status != http.StatusOK {
+		return
+	}
+	res.API = "v2"
+	for _, m := range members.Members {
+		res.Members = append(res.Members, &Member{
+			ID: m.ID, Name: m.Name, PeerURLs: m.PeerURLs, ClientURLs: m.ClientURLs})
+	}
+
+	var auth struct {
+		Enabled bool `json:"enabled"`
+	}
+	if status, err = s.do(ctx, http.MethodGet, baseURL+"/v2/auth/enable", nil, &auth); err == nil &&
+		status == http.StatusOK {
+		res.AuthEnabled = auth.Enabled
+	}
+	if status, err = s.do(ctx, http.MethodGet, baseURL+"/v2/keys/", nil, nil); err == nil &&
+		status == http.StatusOK {
+		res.KeysReadable = true
+	}
+}
+
+// do sends the request and decodes the JSON response body into data if it is not nil,
+// responses with statuses other than 2xx, 401 and 403 are errors
+func (s *Scanner) do(ctx context.Context, method, url string, body []byte, data interface{}) (status int, err error) {
+	ctx, cancel := context.WithTimeout(ctx, s.dataTimeout)
+	defer cancel()
+	var req *http.Request
+	if req, err = http.NewRequestWithContext(ctx, method, url, bytes.NewReader(body)); err != nil {
+		return
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	var resp *http.Response
+	if resp, err = s.client.Do(req); err != nil {
+		return
+	}
+	defer resp.Body.Close()
+	status = resp.StatusCode
+	switch {
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
+		return
+	case status < 200 || status >= 300:
+		return status, fmt.Errorf("%w: %s", errStatus, resp.Status)
+	case data == nil:
+		return
+	}
+	err = json.NewDecoder(io.LimitReader(resp.Body, maxResponseSize)).Decode(data)
+	return
+}
@@ -0,0 +1,150 @@
+package etcd
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+type fakeServer struct {
+	// api is the available key-value API: v3, v3beta or v2
+	api  string
+	auth bool
+}
+
+func (s *fakeServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	v3 := "/" + s.api
+	switch {
+	case r.URL.Path == "/version":
+		fmt.Fprint(w, `{"etcdserver":"3.4.16","etcdcluster":"3.4.0"}`)
+	case r.URL.Path == v3+"/cluster/member/list" && r.Method == http.MethodPost && s.api != "v2":
+		fmt.Fprint(w, `{"header":{"cluster_id":"14841639068965178418"},"members":[`+
+			`{"ID":"10276657743932975437","name":"default","peerURLs":["http://localhost:2380"],`+
+			`"clientURLs":["http://localhost:2379"]}]}`)
+	case r.URL.Path == v3+"/kv/range" && r.Method == http.MethodPost && s.api != "v2":
+		if s.auth {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"error":"etcdserver: user name is empty","code":16}`)
+			return
+		}
+		fmt.Fprint(w, `{"header":{"revision":"5"},"count":"3"}`)
+	case r.URL.Path == "/v2/members" && s.api == "v2":
+		fmt.Fprint(w, `{"members":[{"id":"8e9e05c52164694d","name":"default",`+
+			`"peerURLs":["http://localhost:2380"],"clientURLs":["http://localhost:2379"]}]}`)
+	case r.URL.Path == "/v2/auth/enable" && s.api == "v2":
+		fmt.Fprintf(w, `{"enabled":%t}`, s.auth)
+	case r.URL.Path == "/v2/keys/" && s.api == "v2":
+		if s.auth {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprint(w, `{"action":"get","node":{"dir":true}}`)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func startServer(t *testing.T, h http.Handler) (*scan.Request, string) {
+	t.Helper()
+	srv := httptest.NewServer(h)
+	t.Cleanup(srv.Close)
+	addr := srv.Listener.Addr().(*net.TCPAddr)
+	return &scan.Request{DstIP: addr.IP, DstPort: uint16(addr.Port)}, addr.String()
+}
+
+func TestScan(t *testing.T) {
+	t.Parallel()
+	v3Member := &Member{ID: "8e9e05c52164694d", Name: "default",
+		PeerURLs: []string{"http://localhost:2380"}, ClientURLs: []string{"http://localhost:2379"}}
+	tests := []struct {
+		name     string
+		srv      *fakeServer
+		expected ScanResult
+	}{
+		{
+			name: "V3NoAuth",
+			srv:  &fakeServer{api: "v3"},
+			expected: ScanResult{API: "v3", KeysReadable: true, KeyCount: 3,
+				Members: []*Member{v3Member}},
+		},
+		{
+			name:     "V3Auth",
+			srv:      &fakeServer{api: "v3", auth: true},
+			expected: ScanResult{API: "v3", AuthEnabled: true, Members: []*Member{v3Member}},
+		},
+		{
+			name: "V3Beta",
+			srv:  &fakeServer{api: "v3beta"},
+			expected: ScanResult{API: "v3beta", KeysReadable: true, KeyCount: 3,
+				Members: []*Member{v3Member}},
+		},
+		{
+			name:     "V2NoAuth",
+			srv:      &fakeServer{api: "v2"},
+			expected: ScanResult{API: "v2", KeysReadable: true, Members: []*Member{v3Member}},
+		},
+		{
+			name:     "V2Auth",
+			srv:      &fakeServer{api: "v2", auth: true},
+			expected: ScanResult{API: "v2", AuthEnabled: true, Members: []*Member{v3Member}},
+		},
+		{
+			name:     "NoAPI",
+			srv:      &fakeServer{api: "v4"},
+			expected: ScanResult{},
+		},
+	}
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			req, host := startServer(t, tt.srv)
+			result, err := NewScanner("http").Scan(context.Background(), req)
+			require.NoError(t, err)
+
+			expected := tt.expected
+			expected.ScanType = ScanType
+			expected.Proto = "http"
+			expected.Host = host
+			expected.Version = "3.4.16"
+			expected.ClusterVersion = "3.4.0"
+			require.Equal(t, &expected, result)
+		})
+	}
+}
+
+func TestScanNotEtcdServer(t *testing.T) {
+	t.Parallel()
+	req, _ := startServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/version" {
+			fmt.Fprint(w, `{"version":"1.0"}`)
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	_, err := NewScanner("http").Scan(context.Background(), req)
+	require.ErrorIs(t, err, errNotEtcd)
+
+	req, _ = startServer(t, http.NotFoundHandler())
+	_, err = NewScanner("http").Scan(context.Background(), req)
+	require.ErrorIs(t, err, errStatus)
+}
+
+func TestScanTimeout(t *testing.T) {
+	t.Parallel()
+	done := make(chan struct{})
+	defer close(done)
+	req, _ := startServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-done
+	}))
+	_, err := NewScanner("http", WithDataTimeout(100*time.Millisecond)).Scan(context.Background(), req)
+	require.Error(t, err)
+}
@@ -0,0 +1,194 @@
+package ftp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+const (
+	ScanType = "ftp"
+
+	defaultDialTimeout = 2 * time.Second
+	defaultDataTimeout = 2 * time.Second
+
+	anonymousUser     = "anonymous"
+	anonymousPassword = "guest@"
+)
+
+type ScanResult struct {
+	ScanType string `json:"scan"`
+	IP       string `json:"ip"`
+	Port     uint16 `json:"port"`
+	// Banner is the text of the 220 greeting, lines of multi-line greetings are separated by newlines
+	Banner string `json:"banner"`
+	// Anonymous is set if the server accepted the anonymous login
+	Anonymous bool `json:"anonymous"`
+	// List holds the first lines of the root directory listing of the anonymous session
+	List []string `json:"list,omitempty"`
+}
+
+func (r *ScanResult) String() string {
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "%-20s %-5d %q", r.IP, r.Port, r.Banner)
+	if r.Anonymous {
+		buf.WriteString(" anonymous")
+	}
+	if len(r.List) > 0 {
+		fmt.Fprintf(&buf, " %q", r.List)
+	}
+	return buf.String()
+}
+
+func (r *ScanResult) ID() string {
+	return fmt.Sprintf("%s:%d", r.IP, r.Port)
+}
+
+func (r *ScanResult) MarshalJSON() ([]byte, error) {
+	// Type definition for the recursive call
+	type JScanResult ScanResult
+	// This works because JScanResult doesn't have a MarshalJSON function associated with it
+	return json.Marshal(JScanResult(*r))
+}
+
+// Scanner reads the greeting of FTP servers and tries to log in as the anonymous user,
+// the root directory is listed in the anonymous session if the list sample is enabled
+type Scanner struct {
+	dialer      *net.Dialer
+	dataTimeout time.Duration
+	listSample  int
+}
+
+// Assert that ftp.Scanner conforms to the scan.Scanner interface
+var _ scan.Scanner = (*Scanner)(nil)
+
+type ScannerOption func(*Scanner)
+
+func WithDialTimeout(timeout time.Duration) ScannerOption {
+	return func(s *Scanner) {
+		s.dialer.Timeout = timeout
+	}
+}
+
+func WithDataTimeout(timeout time.Duration) ScannerOption {
+	return func(s *Scanner) {
+		s.dataTimeout = timeout
+	}
+}
+
+// WithListSample sets the maximum number of lines of the root directory listing
+// to read after the anonymous login, zero disables listing
+func WithListSample(lines int) ScannerOption {
+	return func(s *Scanner) {
+		s.listSample = lines
+	}
+}
+
+func NewScanner(opts ...ScannerOption) *Scanner {
+	s := &Scanner{
+		dialer: &net.Dialer{
+			Timeout: defaultDialTimeout,
+		},
+		dataTimeout: defaultDataTimeout,
+	}
+	for _, o := range opts {
+		o(s)
+	}
+	return s
+}
+
+func (s *Scanner) Scan(ctx context.Context, r *scan.Request) (scan.Result, error) {
+	result, err := s.scanTarget(ctx, r)
+	return result, scan.WrapError(err)
+}
+
+func (s *Scanner) scanTarget(ctx context.Context, r *scan.Request) (scan.Result, error) {
+	addr := net.JoinHostPort(r.DstIP.String(), strconv.Itoa(int(r.DstPort)))
+	conn, err := s.dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	if err = conn.SetDeadline(time.Now().Add(s.dataTimeout)); err != nil {
+		return nil, err
+	}
+	tp := textproto.NewConn(conn)
+
+	_, banner, err := readReply(tp, codeReady)
+	if err != nil {
+		return nil, err
+	}
+	result := &ScanResult{
+		ScanType: ScanType,
+		IP:       r.DstIP.String(),
+		Port:     r.DstPort,
+		Banner:   banner,
+	}
+	// the server is detected at this point, so login and listing failures are not reported as errors
+	if result.Anonymous = login(tp); !result.Anonymous || s.listSample == 0 {
+		return result, nil
+	}
+	if err = conn.SetDeadline(time.Now().Add(s.dataTimeout)); err != nil {
+		return result, nil
+	}
+	result.List, _ = s.list(ctx, tp, r.DstIP)
+	return result, nil
+}
+
+// login tries to log in as the anonymous user, some servers accept it without a password
+func login(tp *textproto.Conn) bool {
+	code, _, err := command(tp, 0, "USER %s", anonymousUser)
+	if err != nil {
+		return false
+	}
+	if code == codeLoggedIn {
+		return true
+	}
+	if code != codeNeedPassword {
+		return false
+	}
+	if code, _, err = command(tp, 0, "PASS %s", anonymousPassword); err != nil {
+		return false
+	}
+	return code == codeLoggedIn || code == codeSuperfluous
+}
+
+// list reads the first lines of the root directory listing over a passive data connection.
+// The data connection is opened to the address of the control connection rather than the one
+// in the reply, since servers behind NAT often advertise private addresses.
+func (s *Scanner) list(ctx context.Context, tp *textproto.Conn, ip net.IP) ([]string, error) {
+	_, msg, err := command(tp, codePassiveMode, "PASV")
+	if err != nil {
+		return nil, err
+	}
+	port, err := parsePassivePort(msg)
+	if err != nil {
+		return nil, err
+	}
+	dataConn, err := s.dialer.DialContext(ctx, "tcp", net.JoinHostPort(ip.String(), strconv.Itoa(int(port))))
+	if err != nil {
+		return nil, err
+	}
+	defer dataConn.Close()
+	if err = dataConn.SetDeadline(time.Now().Add(s.dataTimeout)); err != nil {
+		return nil, err
+	}
+	if _, _, err = command(tp, classPreliminary, "LIST"); err != nil {
+		return nil, err
+	}
+
+	var lines []string
+	scanner := bufio.NewScanner(dataConn)
+	for len(lines) < s.listSample && scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	return lines, scanner.Err()
+}
@@ -0,0 +1,247 @@
+package ftp
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+type testServer struct {
+	banner string
+	// userReply is sent in response to the USER command
+	userReply string
+	// passReply is sent in response to the PASS command
+	passReply string
+	// list is sent over the data connection in response to the LIST command
+	list []string
+}
+
+func serveFTP(t *testing.T, server *testServer) *scan.Request {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { l.Close() })
+
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		server.handle(t, conn)
+	}()
+	addr := l.Addr().(*net.TCPAddr)
+	return &scan.Request{DstIP: addr.IP, DstPort: uint16(addr.Port)}
+}
+
+func (server *testServer) handle(t *testing.T, conn net.Conn) {
+	defer conn.Close()
+	if _, err := fmt.Fprint(conn, server.banner); err != nil {
+		return
+	}
+	var dataListener net.Listener
+	defer func() {
+		if dataListener != nil {
+			dataListener.Close()
+		}
+	}()
+	scanner := bufio.NewScanner(conn)
+	for scanner.Scan() {
+		var reply string
+		switch cmd := scanner.Text(); {
+		case strings.HasPrefix(cmd, "USER "):
+			reply = server.userReply
+		case cmd == "PASS "+anonymousPassword:
+			reply = server.passReply
+		case cmd == "PASV":
+			var err error
+			if dataListener, err = net.Listen("tcp", "127.0.0.1:0"); err != nil {
+				return
+			}
+			port := dataListener.Addr().(*net.TCPAddr).Port
+			// the advertised address must be ignored by the scanner
+			reply = fmt.Sprintf("227 Entering Passive Mode (10,0,0,1,%d,%d).\r\n", port>>8, port&0xff)
+		case cmd == "LIST" && dataListener != nil:
+			if _, err := fmt.Fprint(conn, "150 Here comes the directory listing.\r\n"); err != nil {
+				return
+			}
+			dataConn, err := dataListener.Accept()
+			if err != nil {
+				return
+			}
+			for _, line := range server.list {
+				fmt.Fprintf(dataConn, "%s\r\n", line)
+			}
+			dataConn.Close()
+			reply = "226 Directory send OK.\r\n"
+		default:
+			reply = "500 Unknown command.\r\n"
+		}
+		if _, err := fmt.Fprint(conn, reply); err != nil {
+			return
+		}
+	}
+}
+
+func TestScan(t *testing.T) {
+	t.Parallel()
+	list := []string{
+		"drwxr-xr-x    2 0        0            4096 Jan 01  2021 incoming",
+		"drwxr-xr-x    2 0        0            4096 Jan 01  2021 pub",
+		"-rw-r--r--    1 0        0             123 Jan 01  2021 README",
+	}
+	tests := []struct {
+		name       string
+		server     *testServer
+		listSample int
+		expected   *ScanResult
+	}{
+		{
+			name: "Anonymous",
+			server: &testServer{
+				banner:    "220 (vsFTPd 3.0.3)\r\n",
+				userReply: "331 Please specify the password.\r\n",
+				passReply: "230 Login successful.\r\n",
+			},
+			expected: &ScanResult{
+				Banner:    "(vsFTPd 3.0.3)",
+				Anonymous: true,
+			},
+		},
+		{
+			name: "AnonymousWithoutPassword",
+			server: &testServer{
+				banner:    "220 FTP server ready\r\n",
+				userReply: "230 Anonymous access granted.\r\n",
+			},
+			expected: &ScanResult{
+				Banner:    "FTP server ready",
+				Anonymous: true,
+			},
+		},
+		{
+			name: "LoginFailed",
+			server: &testServer{
+				banner:    "220-Welcome to Pure-FTPd\r\n220-You are user number 1 of 50 allowed.\r\n220 Local time is now 10:00.\r\n",
+				userReply: "331 User anonymous OK. Password required\r\n",
+				passReply: "530 Login authentication failed\r\n",
+			},
+			expected: &ScanResult{
+				Banner: "Welcome to Pure-FTPd\nYou are user number 1 of 50 allowed.\nLocal time is now 10:00.",
+			},
+		},
+		{
+			name: "AnonymousDisabled",
+			server: &testServer{
+				banner:    "220 ProFTPD Server\r\n",
+				userReply: "530 Anonymous login is not allowed\r\n",
+			},
+			listSample: 10,
+			expected: &ScanResult{
+				Banner: "ProFTPD Server",
+			},
+		},
+		{
+			name: "List",
+			server: &testServer{
+				banner:    "220 (vsFTPd 3.0.3)\r\n",
+				userReply: "331 Please specify the password.\r\n",
+				passReply: "230 Login successful.\r\n",
+				list:      list,
+			},
+			listSample: 10,
+			expected: &ScanResult{
+				Banner:    "(vsFTPd 3.0.3)",
+				Anonymous: true,
+				List:      list,
+			},
+		},
+		{
+			name: "ListSample",
+			server: &testServer{
+				banner:    "220 (vsFTPd 3.0.3)\r\n",
+				userReply: "331 Please specify the password.\r\n",
+				passReply: "230 Login successful.\r\n",
+				list:      list,
+			},
+			listSample: 2,
+			expected: &ScanResult{
+				Banner:    "(vsFTPd 3.0.3)",
+				Anonymous: true,
+				List:      list[:2],
+			},
+		},
+	}
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			req := serveFTP(t, tt.server)
+			result, err := NewScanner(WithListSample(tt.listSample)).Scan(context.Background(), req)
+			require.NoError(t, err)
+
+			tt.expected.ScanType = ScanType
+			tt.expected.IP = req.DstIP.String()
+			tt.expected.Port = req.DstPort
+			require.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestScanNotFTPServer(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name   string
+		banner string
+	}{
+		{
+			name:   "SSH",
+			banner: "SSH-2.0-OpenSSH_8.4p1 Debian-5\r\n",
+		},
+		{
+			name:   "UnexpectedCode",
+			banner: "421 Too many connections\r\n",
+		},
+	}
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			req := serveFTP(t, &testServer{banner: tt.banner})
+			result, err := NewScanner().Scan(context.Background(), req)
+			require.ErrorIs(t, err, errReply)
+			require.ErrorIs(t, err, scan.ErrParse)
+			require.Nil(t, result)
+		})
+	}
+}
+
+func TestScanTimeout(t *testing.T) {
+	t.Parallel()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+	done := make(chan interface{})
+	defer close(done)
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		<-done
+	}()
+	addr := l.Addr().(*net.TCPAddr)
+
+	s := NewScanner(WithDataTimeout(50 * time.Millisecond))
+	result, err := s.Scan(context.Background(), &scan.Request{DstIP: addr.IP, DstPort: uint16(addr.Port)})
+	require.Error(t, err)
+	require.ErrorIs(t, err, scan.ErrTimeout)
+	require.Nil(t, result)
+}
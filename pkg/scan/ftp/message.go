@@ -0,0 +1,79 @@
+package ftp
+
+import (
+	"errors"
+	"fmt"
+	"net/textproto"
+	"strconv"
+	"strings"
+
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+// FTP reply codes, see RFC 959 section 4.2
+const (
+	codeReady        = 220
+	codeLoggedIn     = 230
+	codeSuperfluous  = 202
+	codeNeedPassword = 331
+	codePassiveMode  = 227
+	// reply class of positive preliminary replies
+	classPreliminary = 1
+)
+
+var errReply = scan.NewError(scan.ErrParse, errors.New("invalid FTP reply"))
+
+// readReply reads a reply of the server, a reply with an unexpected code is returned as errReply
+func readReply(tp *textproto.Conn, expectCode int) (code int, msg string, err error) {
+	// textproto waits for continuation lines of banners like SSH-2.0, so the code is checked in advance
+	prefix, err := tp.R.Peek(3)
+	if err != nil {
+		return
+	}
+	if _, err = strconv.ParseUint(string(prefix), 10, 16); err != nil {
+		return 0, "", fmt.Errorf("%w: %q", errReply, prefix)
+	}
+	code, msg, err = tp.ReadResponse(expectCode)
+	var protoErr textproto.ProtocolError
+	var replyErr *textproto.Error
+	if errors.As(err, &protoErr) || errors.As(err, &replyErr) {
+		err = fmt.Errorf("%w: %v", errReply, err)
+	}
+	return
+}
+
+// command sends the command and reads the reply of the server
+func command(tp *textproto.Conn, expectCode int, format string, args ...interface{}) (int, string, error) {
+	if _, err := tp.Cmd(format, args...); err != nil {
+		return 0, "", err
+	}
+	return readReply(tp, expectCode)
+}
+
+// parsePassivePort parses the data port of the 227 reply, e.g. Entering Passive Mode (10,0,0,1,195,80).
+// Some servers omit parentheses, so the first sequence of digits and commas is parsed.
+func parsePassivePort(msg string) (uint16, error) {
+	start := strings.IndexAny(msg, "0123456789")
+	if start < 0 {
+		return 0, fmt.Errorf("%w: %q", errReply, msg)
+	}
+	end := strings.IndexFunc(msg[start:], func(r rune) bool {
+		return (r < '0' || r > '9') && r != ','
+	})
+	if end < 0 {
+		end = len(msg) - start
+	}
+	fields := strings.Split(msg[start:start+end], ",")
+	if len(fields) != 6 {
+		return 0, fmt.Errorf("%w: %q", errReply, msg)
+	}
+	high, err := strconv.ParseUint(fields[4], 10, 8)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %q", errReply, msg)
+	}
+	low, err := strconv.ParseUint(fields[5], 10, 8)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %q", errReply, msg)
+	}
+	return uint16(high<<8 | low), nil
+}
@@ -0,0 +1,39 @@
+package ftp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePassivePort(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		msg  string
+		port uint16
+	}{
+		{msg: "Entering Passive Mode (10,0,0,1,195,80).", port: 195<<8 | 80},
+		{msg: "Entering Passive Mode (192,168,0,1,4,1)", port: 1025},
+		{msg: "Entering Passive Mode 10,0,0,1,0,21", port: 21},
+		{msg: "=127,0,0,1,255,255", port: 65535},
+	}
+	for _, tt := range tests {
+		port, err := parsePassivePort(tt.msg)
+		require.NoError(t, err, tt.msg)
+		require.Equal(t, tt.port, port, tt.msg)
+	}
+}
+
+func TestParsePassivePortError(t *testing.T) {
+	t.Parallel()
+	tests := []string{
+		"Entering Passive Mode",
+		"Entering Passive Mode (10,0,0,1,195)",
+		"Entering Passive Mode (10,0,0,1,256,80)",
+		"Entering Passive Mode (10,0,0,1,,80)",
+	}
+	for _, msg := range tests {
+		_, err := parsePassivePort(msg)
+		require.ErrorIs(t, err, errReply, msg)
+	}
+}
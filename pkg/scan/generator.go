@@ -39,12 +39,12 @@ func (g *packetGenerator) Packets(ctx context.Context, in <-chan *Request) <-cha
 					return
 				}
 				if r.Err != nil {
-					writeBufToChan(ctx, out, &packet.BufferData{Err: r.Err})
+					writeBufToChan(ctx, out, &packet.BufferData{Err: NewTargetError(r, true, r.Err)})
 					continue
 				}
 				buf := packet.NewSerializeBuffer()
 				if err := g.filler.Fill(buf, r); err != nil {
-					writeBufToChan(ctx, out, &packet.BufferData{Err: err})
+					writeBufToChan(ctx, out, &packet.BufferData{Err: NewTargetError(r, true, err)})
 					continue
 				}
 				writeBufToChan(ctx, out, &packet.BufferData{Buf: buf})
@@ -0,0 +1,149 @@
+package scan
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+)
+
+const (
+	defaultHeartbeatInterval    = 5 * time.Second
+	defaultHeartbeatMaxFailures = 3
+)
+
+// ErrHeartbeat is reported when the heartbeat target stops responding and the scan is paused
+var ErrHeartbeat = errors.New("heartbeat target is not responding, scan is paused")
+
+// Prober checks that a known-good reference target is reachable
+type Prober interface {
+	Probe(ctx context.Context) error
+}
+
+type dialProber struct {
+	dialer *net.Dialer
+	addr   string
+}
+
+// NewDialProber creates a Prober that opens a TCP connection to addr
+// using the OS network stack, i.e. out of band of packet scans
+func NewDialProber(addr string, timeout time.Duration) Prober {
+	return &dialProber{dialer: &net.Dialer{Timeout: timeout}, addr: addr}
+}
+
+func (p *dialProber) Probe(ctx context.Context) error {
+	conn, err := p.dialer.DialContext(ctx, "tcp", p.addr)
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+type heartbeatRequestGenerator struct {
+	delegate    RequestGenerator
+	prober      Prober
+	interval    time.Duration
+	maxFailures int
+}
+
+type HeartbeatOption func(*heartbeatRequestGenerator)
+
+func WithHeartbeatInterval(interval time.Duration) HeartbeatOption {
+	return func(g *heartbeatRequestGenerator) {
+		g.interval = interval
+	}
+}
+
+// WithHeartbeatMaxFailures sets the number of consecutive failed probes after which the scan is paused
+func WithHeartbeatMaxFailures(maxFailures int) HeartbeatOption {
+	return func(g *heartbeatRequestGenerator) {
+		g.maxFailures = maxFailures
+	}
+}
+
+// NewHeartbeatRequestGenerator creates a RequestGenerator that periodically probes
+// a reference target and stops passing requests through while the target doesn't respond,
+// so that an interface failure or upstream filtering doesn't produce a sea of false negative results.
+// The pause is reported with a request of the ErrHeartbeat error, requests are passed
+// through again as soon as the target responds.
+func NewHeartbeatRequestGenerator(rg RequestGenerator, prober Prober, opts ...HeartbeatOption) RequestGenerator {
+	g := &heartbeatRequestGenerator{
+		delegate:    rg,
+		prober:      prober,
+		interval:    defaultHeartbeatInterval,
+		maxFailures: defaultHeartbeatMaxFailures,
+	}
+	for _, o := range opts {
+		o(g)
+	}
+	return g
+}
+
+func (g *heartbeatRequestGenerator) GenerateRequests(ctx context.Context, r *Range) (<-chan *Request, error) {
+	requests, err := g.delegate.GenerateRequests(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	out := make(chan *Request, cap(requests))
+	done := make(chan interface{})
+	probes := g.probe(ctx, done)
+	go func() {
+		defer close(out)
+		defer close(done)
+		failures := 0
+		for {
+			in := requests
+			// paused, wait for the successful probe
+			if failures >= g.maxFailures {
+				in = nil
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case err := <-probes:
+				if err == nil {
+					failures = 0
+					continue
+				}
+				if failures++; failures == g.maxFailures {
+					writeRequest(ctx, out, &Request{
+						Err: NewError(ErrUnreachable, fmt.Errorf("%w: %v", ErrHeartbeat, err))})
+				}
+			case request, ok := <-in:
+				if !ok {
+					return
+				}
+				writeRequest(ctx, out, request)
+			}
+		}
+	}()
+	return out, nil
+}
+
+// probe sends probe results every interval until ctx or done is closed
+func (g *heartbeatRequestGenerator) probe(ctx context.Context, done <-chan interface{}) <-chan error {
+	out := make(chan error)
+	go func() {
+		ticker := time.NewTicker(g.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+			err := g.prober.Probe(ctx)
+			select {
+			case <-ctx.Done():
+				return
+			case <-done:
+				return
+			case out <- err:
+			}
+		}
+	}()
+	return out
+}
@@ -0,0 +1,145 @@
+package scan
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+type proberFunc func(ctx context.Context) error
+
+func (f proberFunc) Probe(ctx context.Context) error {
+	return f(ctx)
+}
+
+func TestHeartbeatRequestGeneratorPassesRequests(t *testing.T) {
+	t.Parallel()
+
+	done := make(chan interface{})
+	go func() {
+		defer close(done)
+
+		ctrl := gomock.NewController(t)
+		reqgen := NewMockRequestGenerator(ctrl)
+		requests := make(chan *Request, 2)
+		requests <- &Request{DstIP: net.IPv4(192, 168, 0, 1), DstPort: 22}
+		requests <- &Request{DstIP: net.IPv4(192, 168, 0, 2), DstPort: 22}
+		close(requests)
+		reqgen.EXPECT().GenerateRequests(gomock.Not(gomock.Nil()), &Range{}).Return(requests, nil)
+
+		prober := proberFunc(func(ctx context.Context) error { return nil })
+		rg := NewHeartbeatRequestGenerator(reqgen, prober, WithHeartbeatInterval(time.Millisecond))
+		out, err := rg.GenerateRequests(context.Background(), &Range{})
+		require.NoError(t, err)
+
+		result := chanToSlice(t, chanPairToGeneric(out), 2)
+		require.Equal(t, []interface{}{
+			&Request{DstIP: net.IPv4(192, 168, 0, 1), DstPort: 22},
+			&Request{DstIP: net.IPv4(192, 168, 0, 2), DstPort: 22},
+		}, result)
+	}()
+	waitDone(t, done)
+}
+
+func TestHeartbeatRequestGeneratorError(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	reqgen := NewMockRequestGenerator(ctrl)
+	reqgen.EXPECT().GenerateRequests(gomock.Not(gomock.Nil()), &Range{}).
+		Return(nil, errors.New("generate error"))
+
+	rg := NewHeartbeatRequestGenerator(reqgen, proberFunc(func(ctx context.Context) error { return nil }))
+	_, err := rg.GenerateRequests(context.Background(), &Range{})
+	require.Error(t, err)
+}
+
+func TestHeartbeatRequestGeneratorPausesScan(t *testing.T) {
+	t.Parallel()
+
+	done := make(chan interface{})
+	go func() {
+		defer close(done)
+
+		ctrl := gomock.NewController(t)
+		reqgen := NewMockRequestGenerator(ctrl)
+		requests := make(chan *Request)
+		reqgen.EXPECT().GenerateRequests(gomock.Not(gomock.Nil()), &Range{}).Return(requests, nil)
+
+		var alive int32
+		probeErr := errors.New("connection refused")
+		prober := proberFunc(func(ctx context.Context) error {
+			if atomic.LoadInt32(&alive) == 1 {
+				return nil
+			}
+			return probeErr
+		})
+		rg := NewHeartbeatRequestGenerator(reqgen, prober,
+			WithHeartbeatInterval(time.Millisecond), WithHeartbeatMaxFailures(2))
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		out, err := rg.GenerateRequests(ctx, &Range{})
+		require.NoError(t, err)
+
+		// the pause is reported once
+		alert := <-out
+		require.ErrorIs(t, alert.Err, ErrHeartbeat)
+		require.ErrorIs(t, alert.Err, ErrUnreachable)
+		require.Contains(t, alert.Err.Error(), probeErr.Error())
+
+		// requests are not passed through while the target doesn't respond
+		request := &Request{DstIP: net.IPv4(192, 168, 0, 1), DstPort: 22}
+		select {
+		case requests <- request:
+			require.Fail(t, "request is accepted during the pause")
+		case <-time.After(50 * time.Millisecond):
+		}
+
+		atomic.StoreInt32(&alive, 1)
+		requests <- request
+		require.Equal(t, request, <-out)
+		close(requests)
+		_, ok := <-out
+		require.False(t, ok)
+	}()
+	waitDone(t, done)
+}
+
+func TestHeartbeatRequestGeneratorContextExit(t *testing.T) {
+	t.Parallel()
+
+	done := make(chan interface{})
+	go func() {
+		defer close(done)
+
+		prober := proberFunc(func(ctx context.Context) error { return errors.New("probe error") })
+		rg := NewHeartbeatRequestGenerator(NewIPPortGenerator(NewIPGenerator(), NewPortGenerator()), prober,
+			WithHeartbeatInterval(time.Millisecond), WithHeartbeatMaxFailures(1))
+		ctx, cancel := context.WithCancel(context.Background())
+		out, err := rg.GenerateRequests(ctx, newScanRange())
+		require.NoError(t, err)
+		cancel()
+		for range out {
+		}
+	}()
+	waitDone(t, done)
+}
+
+func TestDialProber(t *testing.T) {
+	t.Parallel()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := ln.Addr().String()
+
+	prober := NewDialProber(addr, time.Second)
+	require.NoError(t, prober.Probe(context.Background()))
+
+	ln.Close()
+	err = prober.Probe(context.Background())
+	require.ErrorIs(t, WrapError(err), ErrUnreachable)
+}
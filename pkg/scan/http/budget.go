@@ -0,0 +1,46 @@
+package http
+
+import (
+	"encoding/binary"
+	"net"
+	"sync"
+)
+
+// hostBudget limits the total number of requests sent to each host during the whole scan.
+// Requests to a host are spread over the scan since the scan goes over all hosts for each port,
+// so spent counts are never evicted and IPv4 hosts are stored compactly to bound memory.
+type hostBudget struct {
+	limit int
+	mu    sync.Mutex
+	// spent4 are spent counts of IPv4 hosts
+	spent4 map[uint32]uint32
+	// spent are spent counts of IPv6 hosts and DNS names
+	spent map[string]uint32
+}
+
+func newHostBudget(limit int) *hostBudget {
+	return &hostBudget{limit: limit, spent4: make(map[uint32]uint32), spent: make(map[string]uint32)}
+}
+
+// Take reserves one request for the host, it returns false if the host budget is exhausted.
+// Zero limit means unlimited budget.
+func (b *hostBudget) Take(host string) bool {
+	if b.limit <= 0 {
+		return true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if ip := net.ParseIP(host).To4(); ip != nil {
+		key := binary.BigEndian.Uint32(ip)
+		if int(b.spent4[key]) >= b.limit {
+			return false
+		}
+		b.spent4[key]++
+		return true
+	}
+	if int(b.spent[host]) >= b.limit {
+		return false
+	}
+	b.spent[host]++
+	return true
+}
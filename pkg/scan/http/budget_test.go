@@ -0,0 +1,46 @@
+package http
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHostBudget(t *testing.T) {
+	t.Parallel()
+	b := newHostBudget(2)
+
+	require.True(t, b.Take("10.0.0.1"))
+	require.True(t, b.Take("10.0.0.1"))
+	require.False(t, b.Take("10.0.0.1"))
+
+	require.True(t, b.Take("10.0.0.2"))
+}
+
+func TestHostBudgetUnlimited(t *testing.T) {
+	t.Parallel()
+	b := newHostBudget(0)
+
+	for i := 0; i < 100; i++ {
+		require.True(t, b.Take("10.0.0.1"))
+	}
+}
+
+func TestHostBudgetAcrossPorts(t *testing.T) {
+	t.Parallel()
+	b := newHostBudget(2)
+	hosts := []string{"10.0.0.1", "10.0.0.2", "fd00::1", "web.test"}
+
+	// the scan goes over all hosts for the first port and then over all hosts for the next port
+	for _, host := range hosts {
+		require.True(t, b.Take(host), host)
+	}
+	for i := 0; i < 10000; i++ {
+		require.True(t, b.Take(fmt.Sprintf("10.1.%d.%d", i/256, i%256)))
+	}
+	for _, host := range hosts {
+		require.True(t, b.Take(host), host)
+		require.False(t, b.Take(host), host)
+	}
+}
@@ -0,0 +1,367 @@
+package http
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"net"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+const (
+	ScanType = "http"
+
+	defaultDataTimeout = 5 * time.Second
+	defaultMethod      = http.MethodGet
+	// favicons are small images, do not read more than 1 MB of data
+	maxFaviconSize = 1 << 20
+	// the title is expected at the beginning of HTML pages
+	maxTitleBodySize = 64 << 10
+	maxTitleLength   = 256
+	// unread response body up to this size is discarded to reuse the connection,
+	// connections with larger responses are closed
+	maxDrainSize = 64 << 10
+	// maxIdleConns limits the number of idle keep-alive connections to all hosts,
+	// one idle connection is kept per host:port since requests to it are sequential
+	maxIdleConns = 1000
+)
+
+var titleRegexp = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title>`)
+
+// DefaultPaths is a small list of paths that often disclose
+// sensitive information about a web server
+var DefaultPaths = []string{"/robots.txt", "/.git/HEAD", "/server-status"}
+
+type PathResult struct {
+	Path   string `json:"path"`
+	Status int    `json:"status"`
+}
+
+type ScanResult struct {
+	ScanType      string        `json:"scan"`
+	Proto         string        `json:"proto"`
+	Host          string        `json:"host"`
+	Status        int           `json:"status"`
+	URL           string        `json:"url,omitempty"`
+	Server        string        `json:"server,omitempty"`
+	ContentLength *int64        `json:"content_length,omitempty"`
+	Title         string        `json:"title,omitempty"`
+	FaviconHash   *int32        `json:"favicon_hash,omitempty"`
+	Paths         []*PathResult `json:"paths,omitempty"`
+}
+
+func (r *ScanResult) String() string {
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "%s://%s %d", r.Proto, r.Host, r.Status)
+	if len(r.URL) > 0 {
+		fmt.Fprintf(&buf, " %s", r.URL)
+	}
+	if len(r.Server) > 0 {
+		fmt.Fprintf(&buf, " %q", r.Server)
+	}
+	if len(r.Title) > 0 {
+		fmt.Fprintf(&buf, " %q", r.Title)
+	}
+	if r.FaviconHash != nil {
+		fmt.Fprintf(&buf, " %d", *r.FaviconHash)
+	}
+	for _, p := range r.Paths {
+		fmt.Fprintf(&buf, " %s:%d", p.Path, p.Status)
+	}
+	return buf.String()
+}
+
+func (r *ScanResult) ID() string {
+	return r.Host
+}
+
+func (r *ScanResult) MarshalJSON() ([]byte, error) {
+	// Type definition for the recursive call
+	type JScanResult ScanResult
+	// This works because JScanResult doesn't have a MarshalJSON function associated with it
+	return json.Marshal(JScanResult(*r))
+}
+
+// LookupIPFunc resolves IP addresses of the host
+type LookupIPFunc func(ctx context.Context, host string) ([]net.IP, error)
+
+type Scanner struct {
+	client *http.Client
+	// probeClient is used for favicon and path probes, it never follows redirects
+	probeClient  *http.Client
+	proto        string
+	method       string
+	maxRedirects int
+	dataTimeout  time.Duration
+	favicon      bool
+	paths        []string
+	budget       *hostBudget
+	idleTimeout  time.Duration
+	lookupIP     LookupIPFunc
+}
+
+// Assert that http.Scanner conforms to the scan.Scanner interface
+var _ scan.Scanner = (*Scanner)(nil)
+
+type ScannerOption func(*Scanner)
+
+func WithDataTimeout(timeout time.Duration) ScannerOption {
+	return func(s *Scanner) {
+		s.dataTimeout = timeout
+	}
+}
+
+// WithMethod sets the HTTP method of the main request to each target, GET by default,
+// favicon and path probes always use GET
+func WithMethod(method string) ScannerOption {
+	return func(s *Scanner) {
+		s.method = method
+	}
+}
+
+// WithMaxRedirects sets the maximum number of redirects to follow for the main request,
+// zero means redirects are not followed and the redirect response is recorded
+func WithMaxRedirects(maxRedirects int) ScannerOption {
+	return func(s *Scanner) {
+		s.maxRedirects = maxRedirects
+	}
+}
+
+// WithFavicon enables fetching of /favicon.ico and computing its hash
+func WithFavicon(favicon bool) ScannerOption {
+	return func(s *Scanner) {
+		s.favicon = favicon
+	}
+}
+
+// WithPaths sets the list of paths to probe on each target, status code of each path is recorded
+func WithPaths(paths []string) ScannerOption {
+	return func(s *Scanner) {
+		s.paths = paths
+	}
+}
+
+// WithHostBudget limits the total number of additional requests (favicon and path probes)
+// sent to one IP address across all ports, zero means no limit
+func WithHostBudget(maxRequests int) ScannerOption {
+	return func(s *Scanner) {
+		s.budget = newHostBudget(maxRequests)
+	}
+}
+
+// WithKeepAlive enables reuse of TCP/TLS connections to the same host:port for subsequent requests,
+// e.g. favicon and path probes, idle connections are closed after the timeout, zero disables keep-alive
+func WithKeepAlive(idleTimeout time.Duration) ScannerOption {
+	return func(s *Scanner) {
+		s.idleTimeout = idleTimeout
+	}
+}
+
+// WithLookupIP sets the function to resolve DNS names of scan requests,
+// the system resolver is used by default
+func WithLookupIP(lookupIP LookupIPFunc) ScannerOption {
+	return func(s *Scanner) {
+		s.lookupIP = lookupIP
+	}
+}
+
+func NewScanner(proto string, opts ...ScannerOption) *Scanner {
+	s := &Scanner{
+		proto:       proto,
+		method:      defaultMethod,
+		dataTimeout: defaultDataTimeout,
+		budget:      newHostBudget(0),
+	}
+	for _, o := range opts {
+		o(s)
+	}
+	tr := &http.Transport{
+		MaxConnsPerHost:     1,
+		MaxIdleConns:        maxIdleConns,
+		MaxIdleConnsPerHost: 1,
+		DisableKeepAlives:   s.idleTimeout <= 0,
+		IdleConnTimeout:     s.idleTimeout,
+		TLSClientConfig: &tls.Config{
+			InsecureSkipVerify: true,
+		},
+	}
+	if s.lookupIP != nil {
+		tr.DialContext = s.dialContext
+	}
+	s.client = &http.Client{
+		Transport: tr,
+		CheckRedirect: func(_ *http.Request, via []*http.Request) error {
+			if len(via) > s.maxRedirects {
+				return http.ErrUseLastResponse
+			}
+			return nil
+		},
+	}
+	s.probeClient = &http.Client{
+		Transport: tr,
+		// do not follow redirects
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	return s
+}
+
+// dialContext connects to the first resolved IP address of the host
+func (s *Scanner) dialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+	if net.ParseIP(host) == nil {
+		var ips []net.IP
+		if ips, err = s.lookupIP(ctx, host); err != nil {
+			return nil, err
+		}
+		if len(ips) == 0 {
+			return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+		}
+		addr = net.JoinHostPort(ips[0].String(), port)
+	}
+	var dialer net.Dialer
+	return dialer.DialContext(ctx, network, addr)
+}
+
+// Scan sends requests to the IP address of the request or to its DNS name if the IP address is not set,
+// the name is used as the Host header and TLS server name
+func (s *Scanner) Scan(ctx context.Context, r *scan.Request) (scan.Result, error) {
+	result, err := s.scanTarget(ctx, r)
+	return result, scan.WrapError(err)
+}
+
+func (s *Scanner) scanTarget(ctx context.Context, r *scan.Request) (result scan.Result, err error) {
+	ipAddr := r.DstName
+	if r.DstIP != nil {
+		ipAddr = r.DstIP.String()
+	}
+	host := fmt.Sprintf("%s:%d", ipAddr, r.DstPort)
+
+	url := fmt.Sprintf("%s://%s/", s.proto, host)
+	var resp *response
+	if resp, err = s.do(ctx, s.client, s.method, url, maxTitleBodySize); err != nil {
+		return
+	}
+	res := &ScanResult{
+		ScanType:      ScanType,
+		Proto:         s.proto,
+		Host:          host,
+		Status:        resp.status,
+		Server:        resp.header.Get("Server"),
+		ContentLength: resp.contentLength,
+	}
+	if resp.url != url {
+		res.URL = resp.url
+	}
+	if isHTML(resp.header.Get("Content-Type")) {
+		res.Title = parseTitle(resp.body)
+	}
+	if s.favicon && s.budget.Take(ipAddr) {
+		// retrieve favicon ignoring error
+		res.FaviconHash, _ = s.getFaviconHash(ctx, host)
+	}
+	res.Paths = s.probePaths(ctx, ipAddr, host)
+	return res, nil
+}
+
+func (s *Scanner) probePaths(ctx context.Context, ipAddr, host string) (result []*PathResult) {
+	for _, path := range s.paths {
+		if !s.budget.Take(ipAddr) {
+			return
+		}
+		// skip unavailable paths ignoring error
+		resp, err := s.do(ctx, s.probeClient, http.MethodGet, fmt.Sprintf("%s://%s%s", s.proto, host, path), 0)
+		if err != nil {
+			continue
+		}
+		result = append(result, &PathResult{Path: path, Status: resp.status})
+	}
+	return
+}
+
+func (s *Scanner) getFaviconHash(ctx context.Context, host string) (hash *int32, err error) {
+	var resp *response
+	if resp, err = s.do(ctx, s.probeClient, http.MethodGet,
+		fmt.Sprintf("%s://%s/favicon.ico", s.proto, host), maxFaviconSize); err != nil {
+		return
+	}
+	if resp.status != http.StatusOK || len(resp.body) == 0 {
+		return
+	}
+	h := FaviconHash(resp.body)
+	return &h, nil
+}
+
+type response struct {
+	status int
+	// url is the URL of the last request if redirects were followed
+	url           string
+	header        http.Header
+	contentLength *int64
+	body          []byte
+}
+
+// do performs the request and reads at most maxBodySize bytes of the response body
+func (s *Scanner) do(ctx context.Context, client *http.Client,
+	method, url string, maxBodySize int64) (result *response, err error) {
+	ctx, cancel := context.WithTimeout(ctx, s.dataTimeout)
+	defer cancel()
+	var req *http.Request
+	if req, err = http.NewRequestWithContext(ctx, method, url, nil); err != nil {
+		return
+	}
+	var resp *http.Response
+	if resp, err = client.Do(req); err != nil {
+		return
+	}
+	defer resp.Body.Close()
+	result = &response{
+		status: resp.StatusCode,
+		url:    resp.Request.URL.String(),
+		header: resp.Header,
+	}
+	// -1 means unknown length
+	if resp.ContentLength >= 0 {
+		contentLength := resp.ContentLength
+		result.contentLength = &contentLength
+	}
+	if maxBodySize > 0 {
+		if result.body, err = io.ReadAll(io.LimitReader(resp.Body, maxBodySize)); err != nil {
+			return
+		}
+	}
+	if s.idleTimeout > 0 {
+		// the connection is returned to the pool only if the body is read to EOF
+		_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, maxDrainSize))
+	}
+	return
+}
+
+func isHTML(contentType string) bool {
+	return len(contentType) == 0 || strings.Contains(strings.ToLower(contentType), "html")
+}
+
+// parseTitle returns the text of the title element of the HTML page with collapsed whitespace
+func parseTitle(body []byte) string {
+	m := titleRegexp.FindSubmatch(body)
+	if m == nil {
+		return ""
+	}
+	title := strings.Join(strings.Fields(html.UnescapeString(string(m[1]))), " ")
+	if len(title) > maxTitleLength {
+		title = strings.ToValidUTF8(title[:maxTitleLength], "")
+	}
+	return title
+}
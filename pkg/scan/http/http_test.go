@@ -0,0 +1,348 @@
+package http
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+func int64Ptr(v int64) *int64 {
+	return &v
+}
+
+func newServerRequest(t *testing.T, handler http.Handler) (*httptest.Server, *scan.Request) {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	addr := srv.Listener.Addr().(*net.TCPAddr)
+	return srv, &scan.Request{DstIP: addr.IP, DstPort: uint16(addr.Port)}
+}
+
+func TestScanFavicon(t *testing.T) {
+	t.Parallel()
+	favicon := []byte{0x00, 0x00, 0x01, 0x00, 0x01, 0x00, 0x10, 0x10}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/favicon.ico", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write(favicon)
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	})
+	srv, req := newServerRequest(t, mux)
+	defer srv.Close()
+
+	tests := []struct {
+		name        string
+		favicon     bool
+		faviconHash *int32
+	}{
+		{
+			name: "FaviconDisabled",
+		},
+		{
+			name:        "FaviconEnabled",
+			favicon:     true,
+			faviconHash: func() *int32 { h := FaviconHash(favicon); return &h }(),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewScanner("http", WithFavicon(tt.favicon))
+			result, err := s.Scan(context.Background(), req)
+			require.NoError(t, err)
+			require.Equal(t, &ScanResult{
+				ScanType:      ScanType,
+				Proto:         "http",
+				Host:          srv.Listener.Addr().String(),
+				Status:        http.StatusForbidden,
+				ContentLength: int64Ptr(0),
+				FaviconHash:   tt.faviconHash,
+			}, result)
+		})
+	}
+}
+
+func TestScanFaviconNotFound(t *testing.T) {
+	t.Parallel()
+	srv, req := newServerRequest(t, http.NotFoundHandler())
+	defer srv.Close()
+
+	s := NewScanner("http", WithFavicon(true))
+	result, err := s.Scan(context.Background(), req)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusNotFound, result.(*ScanResult).Status)
+	require.Nil(t, result.(*ScanResult).FaviconHash)
+}
+
+func TestScanPaths(t *testing.T) {
+	t.Parallel()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/robots.txt", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("User-agent: *\nDisallow: /admin\n"))
+	})
+	mux.HandleFunc("/server-status", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+		}
+	})
+	srv, req := newServerRequest(t, mux)
+	defer srv.Close()
+
+	tests := []struct {
+		name     string
+		budget   int
+		expected []*PathResult
+	}{
+		{
+			name: "UnlimitedBudget",
+			expected: []*PathResult{
+				{Path: "/robots.txt", Status: http.StatusOK},
+				{Path: "/.git/HEAD", Status: http.StatusNotFound},
+				{Path: "/server-status", Status: http.StatusForbidden},
+			},
+		},
+		{
+			name:   "LimitedBudget",
+			budget: 2,
+			expected: []*PathResult{
+				{Path: "/robots.txt", Status: http.StatusOK},
+				{Path: "/.git/HEAD", Status: http.StatusNotFound},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewScanner("http", WithPaths(DefaultPaths), WithHostBudget(tt.budget))
+			result, err := s.Scan(context.Background(), req)
+			require.NoError(t, err)
+			require.Equal(t, http.StatusOK, result.(*ScanResult).Status)
+			require.Equal(t, tt.expected, result.(*ScanResult).Paths)
+		})
+	}
+}
+
+func TestScanHostBudgetExhausted(t *testing.T) {
+	t.Parallel()
+	srv, req := newServerRequest(t, http.NotFoundHandler())
+	defer srv.Close()
+
+	s := NewScanner("http", WithPaths(DefaultPaths), WithHostBudget(2))
+	result, err := s.Scan(context.Background(), req)
+	require.NoError(t, err)
+	require.Len(t, result.(*ScanResult).Paths, 2)
+
+	result, err = s.Scan(context.Background(), req)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusNotFound, result.(*ScanResult).Status)
+	require.Empty(t, result.(*ScanResult).Paths)
+}
+
+func TestScanKeepAlive(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name        string
+		idleTimeout time.Duration
+		conns       int64
+	}{
+		{
+			name:  "KeepAliveDisabled",
+			conns: 4,
+		},
+		{
+			name:        "KeepAliveEnabled",
+			idleTimeout: 10 * time.Second,
+			conns:       1,
+		},
+	}
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				_, _ = w.Write([]byte("response body"))
+			}))
+			var conns int64
+			srv.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+				if state == http.StateNew {
+					atomic.AddInt64(&conns, 1)
+				}
+			}
+			srv.Start()
+			defer srv.Close()
+			addr := srv.Listener.Addr().(*net.TCPAddr)
+			req := &scan.Request{DstIP: addr.IP, DstPort: uint16(addr.Port)}
+
+			s := NewScanner("http", WithPaths(DefaultPaths), WithKeepAlive(tt.idleTimeout))
+			result, err := s.Scan(context.Background(), req)
+			require.NoError(t, err)
+			require.Len(t, result.(*ScanResult).Paths, len(DefaultPaths))
+			require.Equal(t, tt.conns, atomic.LoadInt64(&conns))
+		})
+	}
+}
+
+func TestScanDNSName(t *testing.T) {
+	t.Parallel()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.Host, "web.test:") {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer srv.Close()
+	addr := srv.Listener.Addr().(*net.TCPAddr)
+
+	var lookups []string
+	s := NewScanner("http", WithLookupIP(func(_ context.Context, host string) ([]net.IP, error) {
+		lookups = append(lookups, host)
+		return []net.IP{addr.IP}, nil
+	}))
+	result, err := s.Scan(context.Background(), &scan.Request{DstName: "web.test", DstPort: uint16(addr.Port)})
+	require.NoError(t, err)
+	require.Equal(t, &ScanResult{
+		ScanType:      ScanType,
+		Proto:         "http",
+		Host:          fmt.Sprintf("web.test:%d", addr.Port),
+		Status:        http.StatusOK,
+		ContentLength: int64Ptr(0),
+	}, result)
+	require.Equal(t, []string{"web.test"}, lookups)
+}
+
+func TestScanMetadata(t *testing.T) {
+	t.Parallel()
+	body := "<html><head><TITLE lang=\"en\">\n  Welcome to\n nginx &amp; co!</TITLE></head></html>"
+	srv, req := newServerRequest(t, http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Server", "nginx/1.18.0")
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write([]byte(body))
+	}))
+	defer srv.Close()
+
+	s := NewScanner("http")
+	result, err := s.Scan(context.Background(), req)
+	require.NoError(t, err)
+	require.Equal(t, &ScanResult{
+		ScanType:      ScanType,
+		Proto:         "http",
+		Host:          srv.Listener.Addr().String(),
+		Status:        http.StatusOK,
+		Server:        "nginx/1.18.0",
+		ContentLength: int64Ptr(int64(len(body))),
+		Title:         "Welcome to nginx & co!",
+	}, result)
+}
+
+func TestScanMethod(t *testing.T) {
+	t.Parallel()
+	var methods []string
+	var mu sync.Mutex
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		methods = append(methods, r.Method)
+		mu.Unlock()
+		if r.Method != http.MethodHead {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	})
+	srv, req := newServerRequest(t, mux)
+	defer srv.Close()
+
+	s := NewScanner("http", WithMethod(http.MethodHead), WithPaths([]string{"/robots.txt"}))
+	result, err := s.Scan(context.Background(), req)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, result.(*ScanResult).Status)
+	require.Equal(t, []*PathResult{{Path: "/robots.txt", Status: http.StatusMethodNotAllowed}}, result.(*ScanResult).Paths)
+	require.Equal(t, []string{http.MethodHead, http.MethodGet}, methods)
+}
+
+func TestScanMaxRedirects(t *testing.T) {
+	t.Parallel()
+	mux := http.NewServeMux()
+	mux.Handle("/", http.RedirectHandler("/first", http.StatusFound))
+	mux.Handle("/first", http.RedirectHandler("/second", http.StatusMovedPermanently))
+	mux.HandleFunc("/second", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("<title>Second</title>"))
+	})
+	srv, req := newServerRequest(t, mux)
+	defer srv.Close()
+
+	tests := []struct {
+		name         string
+		maxRedirects int
+		status       int
+		url          string
+		title        string
+	}{
+		{
+			name:   "NoRedirects",
+			status: http.StatusFound,
+		},
+		{
+			name:         "RedirectLimit",
+			maxRedirects: 1,
+			status:       http.StatusMovedPermanently,
+			url:          srv.URL + "/first",
+		},
+		{
+			name:         "AllRedirects",
+			maxRedirects: 5,
+			status:       http.StatusOK,
+			url:          srv.URL + "/second",
+			title:        "Second",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewScanner("http", WithMaxRedirects(tt.maxRedirects))
+			result, err := s.Scan(context.Background(), req)
+			require.NoError(t, err)
+			require.Equal(t, tt.status, result.(*ScanResult).Status)
+			require.Equal(t, tt.url, result.(*ScanResult).URL)
+			require.Equal(t, tt.title, result.(*ScanResult).Title)
+		})
+	}
+}
+
+func TestParseTitle(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		body     string
+		expected string
+	}{
+		{
+			name: "NoTitle",
+			body: "<html><body>hello</body></html>",
+		},
+		{
+			name:     "Title",
+			body:     "<html><title>Index of /</title></html>",
+			expected: "Index of /",
+		},
+		{
+			name:     "TooLongTitle",
+			body:     "<title>" + strings.Repeat("a", 2*maxTitleLength) + "</title>",
+			expected: strings.Repeat("a", maxTitleLength),
+		},
+	}
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			require.Equal(t, tt.expected, parseTitle([]byte(tt.body)))
+		})
+	}
+}
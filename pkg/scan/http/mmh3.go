@@ -0,0 +1,70 @@
+package http
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"math/bits"
+	"strings"
+)
+
+// FaviconHash computes the favicon hash in the same way as Shodan does:
+// the MurmurHash3 (x86, 32-bit) of the base64 encoded favicon data
+// with a newline inserted after every 76 characters and at the end, see RFC 2045.
+func FaviconHash(data []byte) int32 {
+	encoded := base64.StdEncoding.EncodeToString(data)
+	var buf strings.Builder
+	buf.Grow(len(encoded) + len(encoded)/76 + 1)
+	for len(encoded) > 76 {
+		buf.WriteString(encoded[:76])
+		buf.WriteByte('\n')
+		encoded = encoded[76:]
+	}
+	buf.WriteString(encoded)
+	buf.WriteByte('\n')
+	return int32(murmur3([]byte(buf.String()), 0))
+}
+
+// murmur3 is the 32-bit x86 variant of the MurmurHash3 function
+func murmur3(data []byte, seed uint32) uint32 {
+	const (
+		c1 = 0xcc9e2d51
+		c2 = 0x1b873593
+	)
+	h := seed
+	nblocks := len(data) / 4
+	for i := 0; i < nblocks; i++ {
+		k := binary.LittleEndian.Uint32(data[i*4:])
+		k *= c1
+		k = bits.RotateLeft32(k, 15)
+		k *= c2
+
+		h ^= k
+		h = bits.RotateLeft32(h, 13)
+		h = h*5 + 0xe6546b64
+	}
+
+	var k uint32
+	tail := data[nblocks*4:]
+	switch len(tail) {
+	case 3:
+		k ^= uint32(tail[2]) << 16
+		fallthrough
+	case 2:
+		k ^= uint32(tail[1]) << 8
+		fallthrough
+	case 1:
+		k ^= uint32(tail[0])
+		k *= c1
+		k = bits.RotateLeft32(k, 15)
+		k *= c2
+		h ^= k
+	}
+
+	h ^= uint32(len(data))
+	h ^= h >> 16
+	h *= 0x85ebca6b
+	h ^= h >> 13
+	h *= 0xc2b2ae35
+	h ^= h >> 16
+	return h
+}
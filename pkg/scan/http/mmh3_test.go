@@ -0,0 +1,66 @@
+package http
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMurmur3(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		data     string
+		seed     uint32
+		expected int32
+	}{
+		{
+			name:     "emptyData",
+			data:     "",
+			expected: 0,
+		},
+		{
+			name:     "emptyDataWithSeed",
+			data:     "",
+			seed:     1,
+			expected: 0x514e28b7,
+		},
+		{
+			name:     "oneByteTail",
+			data:     "hello",
+			expected: 613153351,
+		},
+		{
+			name:     "threeBytesTail",
+			data:     "foo",
+			expected: -156908512,
+		},
+		{
+			name:     "fullBlocks",
+			data:     "abcdefgh",
+			expected: 1239272644,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expected, int32(murmur3([]byte(tt.data), tt.seed)))
+		})
+	}
+}
+
+func TestFaviconHashLineWrapping(t *testing.T) {
+	t.Parallel()
+	data := bytes.Repeat([]byte{0xde, 0xad, 0xbe}, 100)
+	// 300 bytes => 400 base64 characters => 5 full lines of 76 characters and 1 line of 20 characters
+	var expected bytes.Buffer
+	line := bytes.Repeat([]byte("3q2+"), 19)
+	for i := 0; i < 5; i++ {
+		expected.Write(line)
+		expected.WriteByte('\n')
+	}
+	expected.Write(bytes.Repeat([]byte("3q2+"), 5))
+	expected.WriteByte('\n')
+
+	require.Equal(t, int32(murmur3(expected.Bytes(), 0)), FaviconHash(data))
+}
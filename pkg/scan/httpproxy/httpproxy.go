@@ -0,0 +1,364 @@
+package httpproxy
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+const (
+	ScanType = "httpproxy"
+	// DefaultCheckURL echoes request headers, so the anonymity level can be detected
+	DefaultCheckURL = "http://httpbin.org/headers"
+
+	defaultDialTimeout = 2 * time.Second
+	defaultDataTimeout = 2 * time.Second
+	// maxBodySize limits the size of the check URL response read through the proxy
+	maxBodySize = 64 * 1024
+)
+
+// Anonymity levels of proxies relaying GET requests
+const (
+	// Transparent proxy reveals the client IP address to the destination
+	Transparent = "transparent"
+	// Anonymous proxy hides the client IP address but reveals the use of a proxy
+	Anonymous = "anonymous"
+	// Elite proxy doesn't add any proxy headers
+	Elite = "elite"
+)
+
+var (
+	// clientHeaders reveal the client IP address
+	clientHeaders = []string{"x-forwarded-for", "x-real-ip", "forwarded", "client-ip"}
+	// proxyHeaders reveal the use of a proxy
+	proxyHeaders = []string{"via", "proxy-connection", "x-proxy-id"}
+)
+
+type ScanResult struct {
+	ScanType string `json:"scan"`
+	IP       string `json:"ip"`
+	Port     uint16 `json:"port"`
+	// Connect is set if the proxy relays traffic to the check URL through CONNECT tunnel
+	Connect bool `json:"connect"`
+	// Get is set if the proxy relays GET request with the absolute check URL
+	Get       bool   `json:"get"`
+	Anonymity string `json:"anonymity,omitempty"`
+}
+
+func (r *ScanResult) String() string {
+	var methods []string
+	if r.Connect {
+		methods = append(methods, http.MethodConnect)
+	}
+	if r.Get {
+		methods = append(methods, http.MethodGet)
+	}
+	return fmt.Sprintf("%-20s %-5d %-11s %s", r.IP, r.Port, strings.Join(methods, ","), r.Anonymity)
+}
+
+func (r *ScanResult) ID() string {
+	return fmt.Sprintf("%s:%d", r.IP, r.Port)
+}
+
+// ProxyURL returns the URL of the proxy, nil is returned if the proxy relays neither CONNECT nor GET requests
+func (r *ScanResult) ProxyURL() *url.URL {
+	if !r.Connect && !r.Get {
+		return nil
+	}
+	return &url.URL{Scheme: "http", Host: net.JoinHostPort(r.IP, strconv.Itoa(int(r.Port)))}
+}
+
+func (r *ScanResult) MarshalJSON() ([]byte, error) {
+	// Type definition for the recursive call
+	type JScanResult ScanResult
+	// This works because JScanResult doesn't have a MarshalJSON function associated with it
+	return json.Marshal(JScanResult(*r))
+}
+
+type Scanner struct {
+	dialer      *net.Dialer
+	dataTimeout time.Duration
+	checkURL    *url.URL
+	checkString []byte
+	get         bool
+}
+
+// Assert that httpproxy.Scanner conforms to the scan.Scanner interface
+var _ scan.Scanner = (*Scanner)(nil)
+
+type ScannerOption func(*Scanner)
+
+func WithDialTimeout(timeout time.Duration) ScannerOption {
+	return func(s *Scanner) {
+		s.dialer.Timeout = timeout
+	}
+}
+
+func WithDataTimeout(timeout time.Duration) ScannerOption {
+	return func(s *Scanner) {
+		s.dataTimeout = timeout
+	}
+}
+
+// WithCheckURL sets the http or https URL requested through the proxy, DefaultCheckURL is used by default
+func WithCheckURL(checkURL *url.URL) ScannerOption {
+	return func(s *Scanner) {
+		s.checkURL = checkURL
+	}
+}
+
+// WithCheckString sets the string that must be present in the check URL response,
+// so that proxies replying with their own pages are not reported
+func WithCheckString(checkString string) ScannerOption {
+	return func(s *Scanner) {
+		s.checkString = []byte(checkString)
+	}
+}
+
+// WithGET enables sending GET request with the absolute check URL to the proxy
+// and detecting the anonymity level, only http check URLs are supported
+func WithGET(get bool) ScannerOption {
+	return func(s *Scanner) {
+		s.get = get
+	}
+}
+
+func NewScanner(opts ...ScannerOption) *Scanner {
+	checkURL, _ := url.Parse(DefaultCheckURL)
+	s := &Scanner{
+		dialer: &net.Dialer{
+			Timeout: defaultDialTimeout,
+		},
+		dataTimeout: defaultDataTimeout,
+		checkURL:    checkURL,
+	}
+	for _, o := range opts {
+		o(s)
+	}
+	return s
+}
+
+// Scan requests the check URL through CONNECT tunnel and optionally with GET request on a new connection,
+// the proxy is reported if it relays traffic with any of the methods
+func (s *Scanner) Scan(ctx context.Context, r *scan.Request) (scan.Result, error) {
+	result, err := s.scanTarget(ctx, r)
+	return result, scan.WrapError(err)
+}
+
+func (s *Scanner) scanTarget(ctx context.Context, r *scan.Request) (result scan.Result, err error) {
+	proxyAddr := net.JoinHostPort(r.DstIP.String(), strconv.Itoa(int(r.DstPort)))
+	var connect, get bool
+	var anonymity string
+	connect, err = s.checkConnect(ctx, proxyAddr)
+	// GET request is not sent to closed ports
+	var opErr *net.OpError
+	if s.get && s.checkURL.Scheme == "http" && !(errors.As(err, &opErr) && opErr.Op == "dial") {
+		var getErr error
+		if get, anonymity, getErr = s.checkGET(ctx, proxyAddr); !connect {
+			err = getErr
+		}
+	}
+	if !connect && !get {
+		return
+	}
+	return &ScanResult{
+		ScanType:  ScanType,
+		IP:        r.DstIP.String(),
+		Port:      r.DstPort,
+		Connect:   connect,
+		Get:       get,
+		Anonymity: anonymity,
+	}, nil
+}
+
+func (s *Scanner) checkConnect(ctx context.Context, proxyAddr string) (ok bool, err error) {
+	var conn net.Conn
+	if conn, err = s.dial(ctx, proxyAddr); err != nil {
+		return
+	}
+	defer conn.Close()
+
+	target := checkURLAddr(s.checkURL)
+	connectReq := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: target},
+		Host:   target,
+		Header: make(http.Header),
+	}
+	connectReq = connectReq.WithContext(ctx)
+	if err = connectReq.Write(conn); err != nil {
+		return
+	}
+	br := bufio.NewReader(conn)
+	var resp *http.Response
+	if resp, err = http.ReadResponse(br, connectReq); err != nil {
+		return
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return
+	}
+
+	var tunnel net.Conn = &bufferedConn{Conn: conn, r: br}
+	if s.checkURL.Scheme == "https" {
+		tlsConn := tls.Client(tunnel, &tls.Config{
+			ServerName:         s.checkURL.Hostname(),
+			InsecureSkipVerify: true,
+		})
+		if err = tlsConn.HandshakeContext(ctx); err != nil {
+			return
+		}
+		tunnel = tlsConn
+	}
+	req := s.newCheckRequest(ctx)
+	if err = req.Write(tunnel); err != nil {
+		return
+	}
+	ok, _, err = s.readCheckResponse(bufio.NewReader(tunnel), req)
+	return
+}
+
+func (s *Scanner) checkGET(ctx context.Context, proxyAddr string) (ok bool, anonymity string, err error) {
+	var conn net.Conn
+	if conn, err = s.dial(ctx, proxyAddr); err != nil {
+		return
+	}
+	defer conn.Close()
+
+	req := s.newCheckRequest(ctx)
+	if err = req.WriteProxy(conn); err != nil {
+		return
+	}
+	var body []byte
+	if ok, body, err = s.readCheckResponse(bufio.NewReader(conn), req); err != nil || !ok {
+		return
+	}
+	return ok, anonymityLevel(body), nil
+}
+
+func (s *Scanner) newCheckRequest(ctx context.Context) *http.Request {
+	req := &http.Request{
+		Method:     http.MethodGet,
+		URL:        s.checkURL,
+		Host:       s.checkURL.Host,
+		Header:     make(http.Header),
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Close:      true,
+	}
+	return req.WithContext(ctx)
+}
+
+// readCheckResponse validates that the response is sent by the check URL server
+func (s *Scanner) readCheckResponse(r *bufio.Reader, req *http.Request) (ok bool, body []byte, err error) {
+	var resp *http.Response
+	if resp, err = http.ReadResponse(r, req); err != nil {
+		return
+	}
+	defer resp.Body.Close()
+	if body, err = io.ReadAll(io.LimitReader(resp.Body, maxBodySize)); err != nil {
+		return
+	}
+	ok = resp.StatusCode == http.StatusOK && bytes.Contains(body, s.checkString)
+	return
+}
+
+// dial connects to the proxy, the connection is closed on ctx.Done without waiting read/write timeout
+func (s *Scanner) dial(ctx context.Context, addr string) (net.Conn, error) {
+	conn, err := s.dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	dconn := &deadlineConn{Conn: conn, timeout: s.dataTimeout, done: make(chan interface{})}
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.Close()
+		case <-dconn.done:
+		}
+	}()
+	return dconn, nil
+}
+
+// anonymityLevel detects proxy headers in the response of the check URL which echoes request headers
+func anonymityLevel(body []byte) string {
+	body = bytes.ToLower(body)
+	if containsHeader(body, clientHeaders) {
+		return Transparent
+	}
+	if containsHeader(body, proxyHeaders) {
+		return Anonymous
+	}
+	return Elite
+}
+
+func containsHeader(body []byte, headers []string) bool {
+	for _, header := range headers {
+		// plain text "name: value" or JSON "name": "value"
+		if bytes.Contains(body, []byte(header+":")) || bytes.Contains(body, []byte(`"`+header+`"`)) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkURLAddr returns host:port of the URL with the default port of the scheme
+func checkURLAddr(u *url.URL) string {
+	port := u.Port()
+	if len(port) == 0 {
+		port = "80"
+		if u.Scheme == "https" {
+			port = "443"
+		}
+	}
+	return net.JoinHostPort(u.Hostname(), port)
+}
+
+type deadlineConn struct {
+	net.Conn
+	timeout time.Duration
+	done    chan interface{}
+}
+
+func (c *deadlineConn) Read(p []byte) (n int, err error) {
+	if err = c.Conn.SetReadDeadline(time.Now().Add(c.timeout)); err != nil {
+		return
+	}
+	return c.Conn.Read(p)
+}
+
+func (c *deadlineConn) Write(p []byte) (n int, err error) {
+	if err = c.Conn.SetWriteDeadline(time.Now().Add(c.timeout)); err != nil {
+		return
+	}
+	return c.Conn.Write(p)
+}
+
+func (c *deadlineConn) Close() error {
+	close(c.done)
+	return c.Conn.Close()
+}
+
+// bufferedConn reads data buffered after the CONNECT response first
+type bufferedConn struct {
+	net.Conn
+	r io.Reader
+}
+
+func (c *bufferedConn) Read(p []byte) (int, error) {
+	return c.r.Read(p)
+}
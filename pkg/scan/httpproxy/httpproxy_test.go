@@ -0,0 +1,260 @@
+package httpproxy
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+// newCheckServer starts a server that echoes request headers
+func newCheckServer(t *testing.T) *url.URL {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.Header.Write(w)
+		_, _ = io.WriteString(w, "sx-check\n")
+	}))
+	t.Cleanup(srv.Close)
+	u, err := url.Parse(srv.URL + "/headers")
+	require.NoError(t, err)
+	return u
+}
+
+type proxyOptions struct {
+	connect bool
+	get     bool
+	headers http.Header
+	// fake replies with its own page instead of relaying requests
+	fake bool
+}
+
+// newProxy starts a forward proxy supporting CONNECT and GET methods
+func newProxy(t *testing.T, opts proxyOptions) *net.TCPAddr {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if opts.fake {
+			_, _ = io.WriteString(w, "welcome")
+			return
+		}
+		if r.Method == http.MethodConnect {
+			if !opts.connect {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+				return
+			}
+			dst, err := net.Dial("tcp", r.Host)
+			if err != nil {
+				w.WriteHeader(http.StatusBadGateway)
+				return
+			}
+			defer dst.Close()
+			conn, bufrw, err := w.(http.Hijacker).Hijack()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+			_, _ = io.WriteString(conn, "HTTP/1.1 200 Connection established\r\n\r\n")
+			go func() { _, _ = io.Copy(dst, bufrw) }()
+			_, _ = io.Copy(conn, dst)
+			return
+		}
+		if !opts.get || !r.URL.IsAbs() {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		req, err := http.NewRequestWithContext(r.Context(), r.Method, r.URL.String(), nil)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		for name, values := range opts.headers {
+			req.Header[name] = values
+		}
+		resp, err := http.DefaultTransport.RoundTrip(req)
+		if err != nil {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		defer resp.Body.Close()
+		w.WriteHeader(resp.StatusCode)
+		_, _ = io.Copy(w, resp.Body)
+	}))
+	t.Cleanup(srv.Close)
+	return srv.Listener.Addr().(*net.TCPAddr)
+}
+
+func TestScan(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		proxy    proxyOptions
+		opts     []ScannerOption
+		expected *ScanResult
+	}{
+		{
+			name:     "Connect",
+			proxy:    proxyOptions{connect: true},
+			expected: &ScanResult{Connect: true},
+		},
+		{
+			name:  "ConnectWithoutGET",
+			proxy: proxyOptions{connect: true, get: true},
+			opts:  []ScannerOption{WithGET(false)},
+			expected: &ScanResult{
+				Connect: true,
+			},
+		},
+		{
+			name:     "GetElite",
+			proxy:    proxyOptions{get: true},
+			opts:     []ScannerOption{WithGET(true)},
+			expected: &ScanResult{Get: true, Anonymity: Elite},
+		},
+		{
+			name:     "GetAnonymous",
+			proxy:    proxyOptions{get: true, headers: http.Header{"Via": {"1.1 squid"}}},
+			opts:     []ScannerOption{WithGET(true)},
+			expected: &ScanResult{Get: true, Anonymity: Anonymous},
+		},
+		{
+			name: "ConnectAndGetTransparent",
+			proxy: proxyOptions{connect: true, get: true, headers: http.Header{
+				"Via": {"1.1 squid"}, "X-Forwarded-For": {"10.0.0.1"}}},
+			opts:     []ScannerOption{WithGET(true)},
+			expected: &ScanResult{Connect: true, Get: true, Anonymity: Transparent},
+		},
+		{
+			name:  "NoRelay",
+			proxy: proxyOptions{},
+			opts:  []ScannerOption{WithGET(true)},
+		},
+		{
+			name:  "FakeProxy",
+			proxy: proxyOptions{fake: true},
+			opts:  []ScannerOption{WithGET(true), WithCheckString("sx-check")},
+		},
+	}
+
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			checkURL := newCheckServer(t)
+			addr := newProxy(t, tt.proxy)
+			s := NewScanner(append([]ScannerOption{
+				WithCheckURL(checkURL), WithDataTimeout(time.Second)}, tt.opts...)...)
+
+			result, err := s.Scan(context.Background(), &scan.Request{DstIP: addr.IP, DstPort: uint16(addr.Port)})
+			require.NoError(t, err)
+			if tt.expected == nil {
+				require.Nil(t, result)
+				return
+			}
+			tt.expected.ScanType = ScanType
+			tt.expected.IP = addr.IP.String()
+			tt.expected.Port = uint16(addr.Port)
+			require.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestScanHTTPSCheckURL(t *testing.T) {
+	t.Parallel()
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, "sx-check")
+	}))
+	defer srv.Close()
+	checkURL, err := url.Parse(srv.URL)
+	require.NoError(t, err)
+	addr := newProxy(t, proxyOptions{connect: true, get: true})
+
+	s := NewScanner(WithCheckURL(checkURL), WithCheckString("sx-check"), WithGET(true))
+	result, err := s.Scan(context.Background(), &scan.Request{DstIP: addr.IP, DstPort: uint16(addr.Port)})
+	require.NoError(t, err)
+	// GET request is not sent with https check URL
+	require.Equal(t, &ScanResult{ScanType: ScanType, IP: addr.IP.String(), Port: uint16(addr.Port), Connect: true}, result)
+}
+
+func TestScanClosedPort(t *testing.T) {
+	t.Parallel()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := ln.Addr().(*net.TCPAddr)
+	ln.Close()
+
+	result, err := NewScanner(WithGET(true)).Scan(context.Background(),
+		&scan.Request{DstIP: addr.IP, DstPort: uint16(addr.Port)})
+	require.Error(t, err)
+	require.Nil(t, result)
+}
+
+func TestScanNotProxy(t *testing.T) {
+	t.Parallel()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			_, _ = io.WriteString(conn, "SSH-2.0-OpenSSH_8.2p1\r\n")
+			conn.Close()
+		}
+	}()
+	addr := ln.Addr().(*net.TCPAddr)
+
+	result, err := NewScanner(WithGET(true)).Scan(context.Background(),
+		&scan.Request{DstIP: addr.IP, DstPort: uint16(addr.Port)})
+	require.Error(t, err)
+	require.Nil(t, result)
+}
+
+func TestAnonymityLevel(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		body     string
+		expected string
+	}{
+		{
+			name:     "Elite",
+			body:     `{"headers": {"Host": "httpbin.org", "User-Agent": "Go-http-client/1.1"}}`,
+			expected: Elite,
+		},
+		{
+			name:     "AnonymousJSON",
+			body:     `{"headers": {"Host": "httpbin.org", "Via": "1.1 squid"}}`,
+			expected: Anonymous,
+		},
+		{
+			name:     "AnonymousPlain",
+			body:     "Host: example.com\r\nProxy-Connection: keep-alive\r\n",
+			expected: Anonymous,
+		},
+		{
+			name:     "Transparent",
+			body:     `{"headers": {"X-Forwarded-For": "10.0.0.1", "Via": "1.1 squid"}}`,
+			expected: Transparent,
+		},
+		{
+			name:     "WordInText",
+			body:     "request forwarded via proxy",
+			expected: Elite,
+		},
+	}
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			require.Equal(t, tt.expected, anonymityLevel([]byte(tt.body)))
+		})
+	}
+}
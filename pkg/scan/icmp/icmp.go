@@ -5,6 +5,7 @@ package icmp
 import (
 	"fmt"
 	"math/rand"
+	"net"
 
 	"github.com/google/gopacket"
 	"github.com/google/gopacket/layers"
@@ -44,8 +45,8 @@ type ScanMethod struct {
 // Assert that icmp.ScanMethod conforms to the scan.PacketMethod interface
 var _ scan.PacketMethod = (*ScanMethod)(nil)
 
-func NewScanMethod(psrc scan.PacketSource, results scan.ResultChan, vpnMode bool) *ScanMethod {
-	pp := NewPacketProcessor(ScanType, results, vpnMode)
+func NewScanMethod(psrc scan.PacketSource, results scan.ResultChan, vpnMode bool, opts ...PacketProcessorOption) *ScanMethod {
+	pp := NewPacketProcessor(ScanType, results, vpnMode, opts...)
 	return &ScanMethod{
 		PacketSource: psrc,
 		Processor:    pp,
@@ -57,6 +58,7 @@ type PacketProcessor struct {
 	scanType string
 	results  scan.ResultChan
 	parser   *gopacket.DecodingLayerParser
+	observer ReplyObserver
 
 	rcvDecoded []gopacket.LayerType
 	rcvEth     layers.Ethernet
@@ -64,8 +66,25 @@ type PacketProcessor struct {
 	rcvICMP    layers.ICMPv4
 }
 
-func NewPacketProcessor(scanType string, results scan.ResultChan, vpnMode bool) *PacketProcessor {
+// ReplyObserver is notified of the source of each ICMP reply, e.g. scan.HostPacer or LiveHosts
+type ReplyObserver interface {
+	Response(ip net.IP)
+}
+
+type PacketProcessorOption func(p *PacketProcessor)
+
+// WithReplyObserver sets the observer of ICMP replies
+func WithReplyObserver(observer ReplyObserver) PacketProcessorOption {
+	return func(p *PacketProcessor) {
+		p.observer = observer
+	}
+}
+
+func NewPacketProcessor(scanType string, results scan.ResultChan, vpnMode bool, opts ...PacketProcessorOption) *PacketProcessor {
 	p := &PacketProcessor{scanType: scanType, results: results}
+	for _, o := range opts {
+		o(p)
+	}
 
 	layerType := layers.LayerTypeEthernet
 	if vpnMode {
@@ -88,6 +107,9 @@ func (p *PacketProcessor) ProcessPacketData(data []byte, _ *gopacket.CaptureInfo
 	if !validPacket(p.rcvDecoded) {
 		return
 	}
+	if p.observer != nil {
+		p.observer.Response(p.rcvIP.SrcIP)
+	}
 
 	p.results.Put(&ScanResult{
 		ScanType: p.scanType,
@@ -114,6 +136,8 @@ type PacketFiller struct {
 	code    uint8
 	payload []byte
 	vpnMode bool
+	// requestType is true if the ICMP type is taken from the request DstPort
+	requestType bool
 }
 
 // Assert that icmp.PacketFiller conforms to the scan.PacketFiller interface
@@ -171,6 +195,14 @@ func WithVPNmode(vpnMode bool) PacketFillerOption {
 	}
 }
 
+// WithRequestType takes the ICMP type of each packet from the request DstPort,
+// see NewMultiProbeRequestGenerator
+func WithRequestType() PacketFillerOption {
+	return func(f *PacketFiller) {
+		f.requestType = true
+	}
+}
+
 func NewPacketFiller(opts ...PacketFillerOption) *PacketFiller {
 	payload := make([]byte, 48)
 	rand.Read(payload)
@@ -207,10 +239,15 @@ func (f *PacketFiller) Fill(packet gopacket.SerializeBuffer, r *scan.Request) (e
 		DstIP:    r.DstIP,
 	}
 
+	typ, payload := f.typ, f.payload
+	if f.requestType {
+		typ = uint8(r.DstPort)
+		payload = probePayload(typ, payload)
+	}
 	icmp := &layers.ICMPv4{
 		Id:       uint16(1 + rand.Intn(65535)),
 		Seq:      1,
-		TypeCode: layers.CreateICMPv4TypeCode(f.typ, f.code),
+		TypeCode: layers.CreateICMPv4TypeCode(typ, f.code),
 	}
 
 	opt := gopacket.SerializeOptions{ComputeChecksums: true}
@@ -219,12 +256,12 @@ func (f *PacketFiller) Fill(packet gopacket.SerializeBuffer, r *scan.Request) (e
 	}
 
 	if f.vpnMode {
-		return gopacket.SerializeLayers(packet, opt, ip, icmp, gopacket.Payload(f.payload))
+		return gopacket.SerializeLayers(packet, opt, ip, icmp, gopacket.Payload(payload))
 	}
 	eth := &layers.Ethernet{
 		SrcMAC:       r.SrcMAC,
 		DstMAC:       r.DstMAC,
 		EthernetType: layers.EthernetTypeIPv4,
 	}
-	return gopacket.SerializeLayers(packet, opt, eth, ip, icmp, gopacket.Payload(f.payload))
+	return gopacket.SerializeLayers(packet, opt, eth, ip, icmp, gopacket.Payload(payload))
 }
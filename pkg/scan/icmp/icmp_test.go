@@ -122,6 +122,36 @@ func TestPacketFillerPayload(t *testing.T) {
 	require.Equal(t, []byte("abc"), icmp.Payload)
 }
 
+func TestPacketFillerRequestType(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		typ        uint8
+		payloadLen int
+	}{
+		{typ: layers.ICMPv4TypeEchoRequest, payloadLen: 48},
+		{typ: layers.ICMPv4TypeTimestampRequest, payloadLen: 12},
+		{typ: layers.ICMPv4TypeInfoRequest, payloadLen: 0},
+		{typ: layers.ICMPv4TypeAddressMaskRequest, payloadLen: 4},
+	}
+	filler := NewPacketFiller(WithRequestType(), WithVPNmode(true))
+	for _, tt := range tests {
+		packet := gopacket.NewSerializeBuffer()
+		err := filler.Fill(packet, &scan.Request{
+			SrcIP:   net.IPv4(192, 168, 0, 3).To4(),
+			DstIP:   net.IPv4(192, 168, 0, 2).To4(),
+			DstPort: uint16(tt.typ),
+		})
+		require.NoError(t, err)
+
+		resultPacket := gopacket.NewPacket(packet.Bytes(), layers.LayerTypeIPv4, gopacket.Default)
+		icmpLayer := resultPacket.Layer(layers.LayerTypeICMPv4)
+		require.NotNil(t, icmpLayer, "icmp layer is empty")
+		icmp := icmpLayer.(*layers.ICMPv4)
+		require.Equal(t, tt.typ, icmp.TypeCode.Type())
+		require.Equal(t, tt.payloadLen, len(icmp.Payload))
+	}
+}
+
 func TestPacketFillerTTL(t *testing.T) {
 	t.Parallel()
 
@@ -344,3 +374,57 @@ func TestProcessPacketDataIPv4(t *testing.T) {
 		t.Fatal("test timeout")
 	}
 }
+
+type replyObserver struct {
+	ips []net.IP
+}
+
+func (o *replyObserver) Response(ip net.IP) {
+	o.ips = append(o.ips, ip)
+}
+
+func TestProcessPacketDataReplyObserver(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	results := scan.NewResultChan(ctx, 1000)
+	observer := &replyObserver{}
+	p := NewPacketProcessor(ScanType, results, true, WithReplyObserver(observer))
+
+	packet := gopacket.NewSerializeBuffer()
+	ip := &layers.IPv4{
+		Version:  4,
+		TTL:      64,
+		Protocol: layers.IPProtocolICMPv4,
+		SrcIP:    net.IPv4(192, 168, 0, 2).To4(),
+		DstIP:    net.IPv4(192, 168, 0, 3).To4(),
+	}
+	icmp := &layers.ICMPv4{
+		TypeCode: layers.CreateICMPv4TypeCode(layers.ICMPv4TypeDestinationUnreachable, layers.ICMPv4CodePort),
+	}
+	opt := gopacket.SerializeOptions{FixLengths: true, ComputeChecksums: true}
+	require.NoError(t, gopacket.SerializeLayers(packet, opt, ip, icmp))
+
+	require.NoError(t, p.ProcessPacketData(packet.Bytes(), &gopacket.CaptureInfo{}))
+	require.Len(t, observer.ips, 1)
+	require.Equal(t, net.IPv4(192, 168, 0, 2).To4(), observer.ips[0].To4())
+}
+
+func BenchmarkPacketFiller(b *testing.B) {
+	b.ReportAllocs()
+	filler := NewPacketFiller()
+	packet := gopacket.NewSerializeBuffer()
+	r := &scan.Request{
+		SrcIP:   net.IPv4(192, 168, 0, 3).To4(),
+		DstIP:   net.IPv4(192, 168, 0, 2).To4(),
+		SrcMAC:  net.HardwareAddr{0x1, 0x2, 0x3, 0x4, 0x5, 0x6},
+		DstMAC:  net.HardwareAddr{0x10, 0x11, 0x12, 0x13, 0x14, 0x15},
+		DstPort: 22,
+	}
+	for i := 0; i < b.N; i++ {
+		if err := filler.Fill(packet, r); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
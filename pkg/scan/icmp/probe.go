@@ -0,0 +1,171 @@
+package icmp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/gopacket/layers"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+const defaultProbeInterval = 1 * time.Second
+
+var ErrProbe = errors.New("invalid ICMP probe")
+
+// probeTypes are ICMP request types of the multi-probe host discovery,
+// hosts that filter echo requests often still answer timestamp or address mask requests
+var probeTypes = map[string]uint8{
+	"echo":         layers.ICMPv4TypeEchoRequest,
+	"timestamp":    layers.ICMPv4TypeTimestampRequest,
+	"info":         layers.ICMPv4TypeInfoRequest,
+	"address-mask": layers.ICMPv4TypeAddressMaskRequest,
+}
+
+// ProbeNames returns names of ICMP probe types in the order of their ICMP types
+func ProbeNames() []string {
+	return []string{"echo", "timestamp", "info", "address-mask"}
+}
+
+// ParseProbes parses the comma-separated list of probe names into ICMP types
+func ParseProbes(probes string) ([]uint8, error) {
+	var result []uint8
+	for _, name := range strings.Split(probes, ",") {
+		typ, ok := probeTypes[strings.TrimSpace(name)]
+		if !ok {
+			return nil, fmt.Errorf("%w: %q", ErrProbe, name)
+		}
+		result = append(result, typ)
+	}
+	return result, nil
+}
+
+// probePayload returns the payload of the request type, echo requests carry the default payload
+func probePayload(typ uint8, payload []byte) []byte {
+	switch typ {
+	case layers.ICMPv4TypeTimestampRequest:
+		// originate, receive and transmit timestamps
+		return make([]byte, 12)
+	case layers.ICMPv4TypeAddressMaskRequest:
+		return make([]byte, 4)
+	case layers.ICMPv4TypeInfoRequest:
+		return nil
+	}
+	return payload
+}
+
+// LiveHosts is the table of hosts that answered any probe, it is shared by the packet processor
+// that marks hosts as live and the request generator that stops probing them
+type LiveHosts struct {
+	mu    sync.RWMutex
+	hosts map[string]struct{}
+}
+
+// Assert that icmp.LiveHosts conforms to the ReplyObserver interface
+var _ ReplyObserver = (*LiveHosts)(nil)
+
+func NewLiveHosts() *LiveHosts {
+	return &LiveHosts{hosts: make(map[string]struct{})}
+}
+
+// Response marks the host as live
+func (h *LiveHosts) Response(ip net.IP) {
+	key := ip.String()
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.hosts[key] = struct{}{}
+}
+
+func (h *LiveHosts) Alive(ip net.IP) bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	_, ok := h.hosts[ip.String()]
+	return ok
+}
+
+type multiProbeRequestGenerator struct {
+	delegate scan.RequestGenerator
+	probes   []uint8
+	hosts    *LiveHosts
+	interval time.Duration
+}
+
+type MultiProbeOption func(rg *multiProbeRequestGenerator)
+
+// WithProbeInterval sets the time to wait for replies before the next probe round
+func WithProbeInterval(interval time.Duration) MultiProbeOption {
+	return func(rg *multiProbeRequestGenerator) {
+		rg.interval = interval
+	}
+}
+
+// NewMultiProbeRequestGenerator sends each probe type to all hosts of the delegate generator in rounds,
+// hosts that answered any of the previous probes are skipped in the next rounds.
+// The ICMP type of the probe is passed in the DstPort field of requests since ICMP has no ports,
+// the PacketFiller takes it from there with the WithRequestType option.
+func NewMultiProbeRequestGenerator(delegate scan.RequestGenerator,
+	probes []uint8, hosts *LiveHosts, opts ...MultiProbeOption) scan.RequestGenerator {
+	rg := &multiProbeRequestGenerator{
+		delegate: delegate,
+		probes:   probes,
+		hosts:    hosts,
+		interval: defaultProbeInterval,
+	}
+	for _, o := range opts {
+		o(rg)
+	}
+	return rg
+}
+
+func (rg *multiProbeRequestGenerator) GenerateRequests(ctx context.Context, r *scan.Range) (<-chan *scan.Request, error) {
+	requests, err := rg.delegate.GenerateRequests(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	out := make(chan *scan.Request, cap(requests))
+	go func() {
+		defer close(out)
+		for i, typ := range rg.probes {
+			if i > 0 {
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(rg.interval):
+				}
+				if requests, err = rg.delegate.GenerateRequests(ctx, r); err != nil {
+					writeRequest(ctx, out, &scan.Request{Err: err})
+					return
+				}
+			}
+			for request := range requests {
+				if request.Err != nil {
+					// errors are the same in each round
+					if i == 0 {
+						writeRequest(ctx, out, request)
+					}
+					continue
+				}
+				if rg.hosts.Alive(request.DstIP) {
+					continue
+				}
+				request.DstPort = uint16(typ)
+				writeRequest(ctx, out, request)
+			}
+			if ctx.Err() != nil {
+				return
+			}
+		}
+	}()
+	return out, nil
+}
+
+func writeRequest(ctx context.Context, out chan<- *scan.Request, request *scan.Request) {
+	select {
+	case <-ctx.Done():
+	case out <- request:
+	}
+}
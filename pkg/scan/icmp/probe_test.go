@@ -0,0 +1,104 @@
+package icmp
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/google/gopacket/layers"
+	"github.com/stretchr/testify/require"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+func TestParseProbes(t *testing.T) {
+	t.Parallel()
+
+	probes, err := ParseProbes("echo,timestamp, address-mask,info")
+	require.NoError(t, err)
+	require.Equal(t, []uint8{layers.ICMPv4TypeEchoRequest, layers.ICMPv4TypeTimestampRequest,
+		layers.ICMPv4TypeAddressMaskRequest, layers.ICMPv4TypeInfoRequest}, probes)
+
+	for _, name := range ProbeNames() {
+		_, err := ParseProbes(name)
+		require.NoError(t, err)
+	}
+
+	_, err = ParseProbes("echo,tcp-syn")
+	require.ErrorIs(t, err, ErrProbe)
+	_, err = ParseProbes("")
+	require.ErrorIs(t, err, ErrProbe)
+}
+
+func TestLiveHosts(t *testing.T) {
+	t.Parallel()
+	hosts := NewLiveHosts()
+	require.False(t, hosts.Alive(net.IPv4(192, 168, 0, 1)))
+
+	hosts.Response(net.IPv4(192, 168, 0, 1).To4())
+	require.True(t, hosts.Alive(net.IPv4(192, 168, 0, 1)))
+	require.False(t, hosts.Alive(net.IPv4(192, 168, 0, 2)))
+}
+
+func TestMultiProbeRequestGenerator(t *testing.T) {
+	t.Parallel()
+	hosts := NewLiveHosts()
+	reqgen := NewMultiProbeRequestGenerator(scan.NewIPRequestGenerator(scan.NewIPGenerator()),
+		[]uint8{layers.ICMPv4TypeEchoRequest, layers.ICMPv4TypeTimestampRequest, layers.ICMPv4TypeAddressMaskRequest},
+		hosts, WithProbeInterval(10*time.Millisecond))
+
+	requests, err := reqgen.GenerateRequests(context.Background(), &scan.Range{
+		DstSubnet: &net.IPNet{IP: net.IPv4(192, 168, 0, 0).To4(), Mask: net.CIDRMask(30, 32)},
+	})
+	require.NoError(t, err)
+
+	probes := make(map[string][]uint8)
+	done := make(chan interface{})
+	go func() {
+		defer close(done)
+		for request := range requests {
+			ip := request.DstIP.String()
+			probes[ip] = append(probes[ip], uint8(request.DstPort))
+			// .1 answers the echo request, .2 answers the timestamp request
+			if (ip == "192.168.0.1" && request.DstPort == layers.ICMPv4TypeEchoRequest) ||
+				(ip == "192.168.0.2" && request.DstPort == layers.ICMPv4TypeTimestampRequest) {
+				hosts.Response(request.DstIP)
+			}
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(3 * time.Second):
+		require.Fail(t, "test timeout")
+	}
+
+	all := []uint8{layers.ICMPv4TypeEchoRequest, layers.ICMPv4TypeTimestampRequest, layers.ICMPv4TypeAddressMaskRequest}
+	require.Equal(t, map[string][]uint8{
+		"192.168.0.0": all,
+		"192.168.0.1": {layers.ICMPv4TypeEchoRequest},
+		"192.168.0.2": {layers.ICMPv4TypeEchoRequest, layers.ICMPv4TypeTimestampRequest},
+		"192.168.0.3": all,
+	}, probes)
+}
+
+func TestMultiProbeRequestGeneratorContextExit(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+	reqgen := NewMultiProbeRequestGenerator(scan.NewIPRequestGenerator(scan.NewIPGenerator()),
+		[]uint8{layers.ICMPv4TypeEchoRequest, layers.ICMPv4TypeTimestampRequest}, NewLiveHosts(),
+		WithProbeInterval(time.Hour))
+
+	requests, err := reqgen.GenerateRequests(ctx, &scan.Range{
+		DstSubnet: &net.IPNet{IP: net.IPv4(192, 168, 0, 1).To4(), Mask: net.CIDRMask(32, 32)},
+	})
+	require.NoError(t, err)
+	<-requests
+	cancel()
+
+	select {
+	case _, ok := <-requests:
+		require.False(t, ok)
+	case <-time.After(3 * time.Second):
+		require.Fail(t, "test timeout")
+	}
+}
@@ -0,0 +1,90 @@
+package scan
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+// InFlightLimiter is a semaphore that caps the number of scan requests in flight. One limiter is shared
+// by all connect scanners of the process, so the number of open sockets stays below the file descriptor limit
+// regardless of the number of workers and scanners.
+type InFlightLimiter struct {
+	sem       chan struct{}
+	queued    int64
+	maxQueued int64
+	waited    int64
+}
+
+// InFlightStats are queue metrics of the limiter
+type InFlightStats struct {
+	Limit    int
+	InFlight int
+	// Queued is the number of requests waiting for a free slot
+	Queued int64
+	// MaxQueued is the maximum queue length
+	MaxQueued int64
+	// Waited is the total number of requests that waited in the queue
+	Waited int64
+}
+
+func NewInFlightLimiter(limit int) *InFlightLimiter {
+	return &InFlightLimiter{sem: make(chan struct{}, limit)}
+}
+
+// Acquire blocks until the number of requests in flight is below the limit or the context is done
+func (l *InFlightLimiter) Acquire(ctx context.Context) error {
+	select {
+	case l.sem <- struct{}{}:
+		return nil
+	default:
+	}
+	queued := atomic.AddInt64(&l.queued, 1)
+	defer atomic.AddInt64(&l.queued, -1)
+	atomic.AddInt64(&l.waited, 1)
+	for {
+		maxQueued := atomic.LoadInt64(&l.maxQueued)
+		if queued <= maxQueued || atomic.CompareAndSwapInt64(&l.maxQueued, maxQueued, queued) {
+			break
+		}
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case l.sem <- struct{}{}:
+		return nil
+	}
+}
+
+// Release frees the slot of the finished request
+func (l *InFlightLimiter) Release() {
+	<-l.sem
+}
+
+func (l *InFlightLimiter) Stats() InFlightStats {
+	return InFlightStats{
+		Limit:     cap(l.sem),
+		InFlight:  len(l.sem),
+		Queued:    atomic.LoadInt64(&l.queued),
+		MaxQueued: atomic.LoadInt64(&l.maxQueued),
+		Waited:    atomic.LoadInt64(&l.waited),
+	}
+}
+
+type inFlightScanner struct {
+	Scanner
+	limiter *InFlightLimiter
+}
+
+// NewInFlightScanner creates a scanner that holds a slot of the limiter while the delegate scanner
+// processes the request, requests wait in the queue if all slots are taken
+func NewInFlightScanner(delegate Scanner, limiter *InFlightLimiter) Scanner {
+	return &inFlightScanner{Scanner: delegate, limiter: limiter}
+}
+
+func (s *inFlightScanner) Scan(ctx context.Context, r *Request) (Result, error) {
+	if err := s.limiter.Acquire(ctx); err != nil {
+		return nil, err
+	}
+	defer s.limiter.Release()
+	return s.Scanner.Scan(ctx, r)
+}
@@ -0,0 +1,97 @@
+package scan
+
+import (
+	"context"
+	"net"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInFlightLimiter(t *testing.T) {
+	t.Parallel()
+	limiter := NewInFlightLimiter(2)
+	require.NoError(t, limiter.Acquire(context.Background()))
+	require.NoError(t, limiter.Acquire(context.Background()))
+	require.Equal(t, InFlightStats{Limit: 2, InFlight: 2}, limiter.Stats())
+
+	acquired := make(chan error)
+	go func() {
+		acquired <- limiter.Acquire(context.Background())
+	}()
+	require.Eventually(t, func() bool {
+		return limiter.Stats().Queued == 1
+	}, time.Second, time.Millisecond)
+
+	limiter.Release()
+	require.NoError(t, <-acquired)
+	require.Equal(t, InFlightStats{Limit: 2, InFlight: 2, MaxQueued: 1, Waited: 1}, limiter.Stats())
+
+	limiter.Release()
+	limiter.Release()
+	require.Equal(t, InFlightStats{Limit: 2, MaxQueued: 1, Waited: 1}, limiter.Stats())
+}
+
+func TestInFlightLimiterContextDone(t *testing.T) {
+	t.Parallel()
+	limiter := NewInFlightLimiter(1)
+	require.NoError(t, limiter.Acquire(context.Background()))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(t, limiter.Acquire(ctx), context.DeadlineExceeded)
+	require.Equal(t, InFlightStats{Limit: 1, InFlight: 1, MaxQueued: 1, Waited: 1}, limiter.Stats())
+}
+
+func TestInFlightScanner(t *testing.T) {
+	t.Parallel()
+
+	done := make(chan interface{})
+	go func() {
+		defer close(done)
+
+		ctrl := gomock.NewController(t)
+		scanner := NewMockScanner(ctrl)
+
+		req := &Request{DstIP: net.IPv4(192, 168, 0, 1), DstPort: 22}
+		expectedResult := &mockScanResult{"id1"}
+		var inFlight, maxInFlight int32
+		scanner.EXPECT().Scan(gomock.Not(gomock.Nil()), req).
+			DoAndReturn(func(context.Context, *Request) (Result, error) {
+				n := atomic.AddInt32(&inFlight, 1)
+				defer atomic.AddInt32(&inFlight, -1)
+				for {
+					max := atomic.LoadInt32(&maxInFlight)
+					if n <= max || atomic.CompareAndSwapInt32(&maxInFlight, max, n) {
+						break
+					}
+				}
+				time.Sleep(time.Millisecond)
+				return expectedResult, nil
+			}).Times(20)
+
+		limiter := NewInFlightLimiter(3)
+		inFlightScanner := NewInFlightScanner(scanner, limiter)
+		var wg sync.WaitGroup
+		for i := 0; i < 20; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				result, err := inFlightScanner.Scan(context.Background(), req)
+				require.NoError(t, err)
+				require.Equal(t, expectedResult, result)
+			}()
+		}
+		wg.Wait()
+
+		require.LessOrEqual(t, atomic.LoadInt32(&maxInFlight), int32(3))
+		stats := limiter.Stats()
+		require.Zero(t, stats.InFlight)
+		require.Zero(t, stats.Queued)
+	}()
+	waitDone(t, done)
+}
@@ -0,0 +1,210 @@
+package influx
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+const (
+	ScanType = "influx"
+
+	defaultDataTimeout = 5 * time.Second
+
+	// maxResponseSize limits the size of read responses, database lists of big servers fit in it
+	maxResponseSize = 1 << 20
+	versionHeader   = "X-Influxdb-Version"
+	buildHeader     = "X-Influxdb-Build"
+)
+
+var (
+	errNotInflux = errors.New("not an InfluxDB server")
+	errStatus    = errors.New("unexpected HTTP status")
+	errAuth      = errors.New("authentication required")
+)
+
+type ScanResult struct {
+	ScanType string `json:"scan"`
+	Proto    string `json:"proto"`
+	Host     string `json:"host"`
+	Version  string `json:"version"`
+	// Build is OSS or ENT for the enterprise edition
+	Build       string `json:"build,omitempty"`
+	AuthEnabled bool   `json:"auth_enabled"`
+	// DatabasesReadable is true if the SHOW DATABASES query is executed without credentials
+	DatabasesReadable bool     `json:"dbs_readable"`
+	Databases         []string `json:"dbs,omitempty"`
+}
+
+func (r *ScanResult) String() string {
+	result := fmt.Sprintf("%s://%s %s %s", r.Proto, r.Host, r.Version, r.Build)
+	switch {
+	case r.AuthEnabled:
+		result += " auth"
+	case r.DatabasesReadable:
+		result += fmt.Sprintf(" no-auth dbs:%d", len(r.Databases))
+	}
+	return result
+}
+
+func (r *ScanResult) ID() string {
+	return r.Host
+}
+
+func (r *ScanResult) MarshalJSON() ([]byte, error) {
+	// Type definition for the recursive call
+	type JScanResult ScanResult
+	// This works because JScanResult doesn't have a MarshalJSON function associated with it
+	return json.Marshal(JScanResult(*r))
+}
+
+type Scanner struct {
+	influx *influxClient
+	proto  string
+}
+
+// Assert that influx.Scanner conforms to the scan.Scanner interface
+var _ scan.Scanner = (*Scanner)(nil)
+
+type ScannerOption func(*Scanner)
+
+func WithDataTimeout(timeout time.Duration) ScannerOption {
+	return func(s *Scanner) {
+		s.influx.dataTimeout = timeout
+	}
+}
+
+func NewScanner(proto string, opts ...ScannerOption) *Scanner {
+	tr := &http.Transport{
+		MaxConnsPerHost:   1,
+		DisableKeepAlives: true,
+		TLSClientConfig: &tls.Config{
+			InsecureSkipVerify: true,
+		},
+	}
+	ic := &influxClient{
+		client: &http.Client{
+			Transport: tr,
+		},
+		proto:       proto,
+		dataTimeout: defaultDataTimeout,
+	}
+	s := &Scanner{ic, proto}
+	for _, o := range opts {
+		o(s)
+	}
+	return s
+}
+
+func (s *Scanner) Scan(ctx context.Context, r *scan.Request) (scan.Result, error) {
+	result, err := s.scanTarget(ctx, r)
+	return result, scan.WrapError(err)
+}
+
+func (s *Scanner) scanTarget(ctx context.Context, r *scan.Request) (result scan.Result, err error) {
+	host := fmt.Sprintf("%s:%d", r.DstIP.String(), r.DstPort)
+	// retrieve version headers
+	var header http.Header
+	if header, err = s.influx.Ping(ctx, host); err != nil {
+		return
+	}
+	res := &ScanResult{
+		ScanType: ScanType,
+		Proto:    s.proto,
+		Host:     host,
+		Version:  header.Get(versionHeader),
+		Build:    header.Get(buildHeader),
+	}
+	// retrieve all databases ignoring other errors
+	databases, err := s.influx.ShowDatabases(ctx, host)
+	res.AuthEnabled = errors.Is(err, errAuth)
+	res.DatabasesReadable = err == nil
+	res.Databases = databases
+	return res, nil
+}
+
+type influxClient struct {
+	client      *http.Client
+	proto       string
+	dataTimeout time.Duration
+}
+
+// Ping returns headers of the /ping endpoint, InfluxDB servers set the version header in all responses
+func (c *influxClient) Ping(ctx context.Context, host string) (header http.Header, err error) {
+	var status int
+	if status, header, _, err = c.Get(ctx, fmt.Sprintf("%s://%s/ping", c.proto, host)); err != nil {
+		return
+	}
+	if len(header.Get(versionHeader)) == 0 {
+		return nil, errNotInflux
+	}
+	if status != http.StatusNoContent && status != http.StatusOK {
+		return nil, fmt.Errorf("%w: %d", errStatus, status)
+	}
+	return
+}
+
+// ShowDatabases executes the SHOW DATABASES query of the InfluxDB 1.x API without credentials,
+// InfluxDB 2.x requires the token for this API
+func (c *influxClient) ShowDatabases(ctx context.Context, host string) (databases []string, err error) {
+	status, _, body, err := c.Get(ctx, fmt.Sprintf("%s://%s/query?q=SHOW+DATABASES", c.proto, host))
+	if err != nil {
+		return
+	}
+	switch status {
+	case http.StatusOK:
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return nil, errAuth
+	default:
+		return nil, fmt.Errorf("%w: %d", errStatus, status)
+	}
+
+	var data struct {
+		Results []struct {
+			Series []struct {
+				Values [][]interface{} `json:"values"`
+			} `json:"series"`
+			Error string `json:"error"`
+		} `json:"results"`
+	}
+	if err = json.Unmarshal(body, &data); err != nil {
+		return
+	}
+	for _, r := range data.Results {
+		// InfluxDB 1.x with auth returns the error of the statement if the user is not an admin
+		if len(r.Error) > 0 {
+			return nil, errAuth
+		}
+		for _, series := range r.Series {
+			for _, value := range series.Values {
+				if len(value) > 0 {
+					databases = append(databases, fmt.Sprint(value[0]))
+				}
+			}
+		}
+	}
+	return
+}
+
+func (c *influxClient) Get(ctx context.Context, url string) (status int, header http.Header, body []byte, err error) {
+	ctx, cancel := context.WithTimeout(ctx, c.dataTimeout)
+	defer cancel()
+	var req *http.Request
+	if req, err = http.NewRequestWithContext(ctx, "GET", url, nil); err != nil {
+		return
+	}
+	var resp *http.Response
+	if resp, err = c.client.Do(req); err != nil {
+		return
+	}
+	defer resp.Body.Close()
+	body, err = io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	return resp.StatusCode, resp.Header, body, err
+}
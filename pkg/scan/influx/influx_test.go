@@ -0,0 +1,128 @@
+package influx
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+type fakeServer struct {
+	version string
+	// auth is 401 for requests without credentials, statementAuth is the error of the query
+	// like InfluxDB 1.x returns for users without privileges
+	auth          bool
+	statementAuth bool
+}
+
+func (s *fakeServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set(versionHeader, s.version)
+	w.Header().Set(buildHeader, "OSS")
+	switch {
+	case r.URL.Path == "/ping":
+		w.WriteHeader(http.StatusNoContent)
+	case r.URL.Path == "/query" && r.URL.Query().Get("q") == "SHOW DATABASES":
+		switch {
+		case s.auth:
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"error":"unable to parse authentication credentials"}`)
+		case s.statementAuth:
+			fmt.Fprint(w, `{"results":[{"statement_id":0,"error":"error authorizing query: requires admin privilege"}]}`)
+		default:
+			fmt.Fprint(w, `{"results":[{"statement_id":0,"series":[{"name":"databases","columns":["name"],`+
+				`"values":[["_internal"],["telegraf"]]}]}]}`)
+		}
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func startServer(t *testing.T, h http.Handler) (*scan.Request, string) {
+	t.Helper()
+	srv := httptest.NewServer(h)
+	t.Cleanup(srv.Close)
+	addr := srv.Listener.Addr().(*net.TCPAddr)
+	return &scan.Request{DstIP: addr.IP, DstPort: uint16(addr.Port)}, addr.String()
+}
+
+func TestScan(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		srv      *fakeServer
+		expected ScanResult
+	}{
+		{
+			name: "NoAuth",
+			srv:  &fakeServer{version: "1.8.10"},
+			expected: ScanResult{Version: "1.8.10", DatabasesReadable: true,
+				Databases: []string{"_internal", "telegraf"}},
+		},
+		{
+			name:     "Auth",
+			srv:      &fakeServer{version: "1.8.10", auth: true},
+			expected: ScanResult{Version: "1.8.10", AuthEnabled: true},
+		},
+		{
+			name:     "StatementAuth",
+			srv:      &fakeServer{version: "1.8.10", statementAuth: true},
+			expected: ScanResult{Version: "1.8.10", AuthEnabled: true},
+		},
+		{
+			name:     "V2",
+			srv:      &fakeServer{version: "v2.7.1", auth: true},
+			expected: ScanResult{Version: "v2.7.1", AuthEnabled: true},
+		},
+	}
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			req, host := startServer(t, tt.srv)
+			result, err := NewScanner("http").Scan(context.Background(), req)
+			require.NoError(t, err)
+
+			expected := tt.expected
+			expected.ScanType = ScanType
+			expected.Proto = "http"
+			expected.Host = host
+			expected.Build = "OSS"
+			require.Equal(t, &expected, result)
+		})
+	}
+}
+
+func TestScanNotInfluxServer(t *testing.T) {
+	t.Parallel()
+	req, _ := startServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	_, err := NewScanner("http").Scan(context.Background(), req)
+	require.ErrorIs(t, err, errNotInflux)
+}
+
+func TestScanTimeout(t *testing.T) {
+	t.Parallel()
+	done := make(chan struct{})
+	defer close(done)
+	req, _ := startServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-done
+	}))
+	_, err := NewScanner("http", WithDataTimeout(100*time.Millisecond)).Scan(context.Background(), req)
+	require.Error(t, err)
+}
+
+func TestScanResultString(t *testing.T) {
+	t.Parallel()
+	result := &ScanResult{ScanType: ScanType, Proto: "http", Host: "192.168.0.1:8086", Version: "1.8.10",
+		Build: "OSS", DatabasesReadable: true, Databases: []string{"_internal", "telegraf"}}
+	require.Equal(t, "http://192.168.0.1:8086 1.8.10 OSS no-auth dbs:2", result.String())
+	require.Equal(t, "192.168.0.1:8086", result.ID())
+}
@@ -0,0 +1,269 @@
+package ipmi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+const (
+	ScanType = "ipmi"
+
+	defaultDataTimeout = 1 * time.Second
+	maxPacketSize      = 1500
+)
+
+// DefaultUsers are user names checked for RAKP hash disclosure, the empty name is the null user
+var DefaultUsers = []string{"", "ADMIN", "admin", "root", "Administrator"}
+
+type ScanResult struct {
+	ScanType string `json:"scan"`
+	IP       string `json:"ip"`
+	Port     uint16 `json:"port"`
+	// Version is the highest IPMI version supported by the BMC, 1.5 or 2.0
+	Version   string   `json:"version"`
+	AuthTypes []string `json:"auth_types,omitempty"`
+	// NullUsers is true if users with empty names are enabled
+	NullUsers      bool   `json:"null_users"`
+	AnonymousLogin bool   `json:"anonymous_login"`
+	OEMID          uint32 `json:"oem_id,omitempty"`
+	// CipherZero is true if the BMC opens RMCP+ sessions with cipher suite 0,
+	// such sessions are not authenticated, so any password is accepted for a valid user name
+	CipherZero bool `json:"cipher_zero"`
+	// RAKPUsers are user names the BMC returned the password HMAC for in RAKP Message 2,
+	// the HMAC can be cracked offline to recover the password
+	RAKPUsers []string `json:"rakp_users,omitempty"`
+}
+
+func (r *ScanResult) String() string {
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "%-20s %-5d v%s", r.IP, r.Port, r.Version)
+	if len(r.AuthTypes) > 0 {
+		fmt.Fprintf(&buf, " auth %s", strings.Join(r.AuthTypes, ","))
+	}
+	if r.AnonymousLogin {
+		buf.WriteString(" anonymous")
+	}
+	if r.CipherZero {
+		buf.WriteString(" cipher-zero")
+	}
+	if len(r.RAKPUsers) > 0 {
+		users := make([]string, 0, len(r.RAKPUsers))
+		for _, user := range r.RAKPUsers {
+			users = append(users, strconv.Quote(user))
+		}
+		fmt.Fprintf(&buf, " rakp %s", strings.Join(users, ","))
+	}
+	return buf.String()
+}
+
+func (r *ScanResult) ID() string {
+	return fmt.Sprintf("%s:%d", r.IP, r.Port)
+}
+
+func (r *ScanResult) MarshalJSON() ([]byte, error) {
+	// Type definition for the recursive call
+	type JScanResult ScanResult
+	// This works because JScanResult doesn't have a MarshalJSON function associated with it
+	return json.Marshal(JScanResult(*r))
+}
+
+// Scanner sends the Get Channel Authentication Capabilities request to each target over UDP.
+// RMCP+ sessions are opened with BMCs that support IPMI v2.0 to check cipher suite 0 and RAKP hash disclosure.
+// Sessions are never activated, so no user is logged in.
+type Scanner struct {
+	dataTimeout time.Duration
+	users       []string
+}
+
+// Assert that ipmi.Scanner conforms to the scan.Scanner interface
+var _ scan.Scanner = (*Scanner)(nil)
+
+type ScannerOption func(*Scanner)
+
+// WithDataTimeout sets the time to wait for responses to each request
+func WithDataTimeout(timeout time.Duration) ScannerOption {
+	return func(s *Scanner) {
+		s.dataTimeout = timeout
+	}
+}
+
+// WithUsers sets user names checked for RAKP hash disclosure, no names disable the check
+func WithUsers(users []string) ScannerOption {
+	return func(s *Scanner) {
+		s.users = users
+	}
+}
+
+func NewScanner(opts ...ScannerOption) *Scanner {
+	s := &Scanner{
+		dataTimeout: defaultDataTimeout,
+		users:       DefaultUsers,
+	}
+	for _, o := range opts {
+		o(s)
+	}
+	return s
+}
+
+func (s *Scanner) Scan(ctx context.Context, r *scan.Request) (scan.Result, error) {
+	result, err := s.scanTarget(ctx, r)
+	return result, scan.WrapError(err)
+}
+
+func (s *Scanner) scanTarget(ctx context.Context, r *scan.Request) (result scan.Result, err error) {
+	addr := net.JoinHostPort(r.DstIP.String(), strconv.Itoa(int(r.DstPort)))
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "udp", addr)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	caps, err := s.capabilities(conn)
+	if err != nil || caps == nil {
+		return nil, err
+	}
+	res := &ScanResult{
+		ScanType:       ScanType,
+		IP:             r.DstIP.String(),
+		Port:           r.DstPort,
+		Version:        "1.5",
+		AuthTypes:      caps.authTypes,
+		NullUsers:      caps.nullUsers,
+		AnonymousLogin: caps.anonymous,
+		OEMID:          caps.oemID,
+	}
+	if !caps.ipmi20 {
+		return res, nil
+	}
+	res.Version = "2.0"
+
+	if err = ctx.Err(); err != nil {
+		return nil, err
+	}
+	var bmcID uint32
+	if bmcID, err = s.openSession(conn, algNone, algNone, algNone); err != nil {
+		return nil, err
+	}
+	res.CipherZero = bmcID != 0
+
+	for _, user := range s.users {
+		if err = ctx.Err(); err != nil {
+			return nil, err
+		}
+		var disclosed, ok bool
+		if disclosed, ok, err = s.rakp(conn, user); err != nil {
+			return nil, err
+		}
+		if !ok {
+			break
+		}
+		if disclosed {
+			res.RAKPUsers = append(res.RAKPUsers, user)
+		}
+	}
+	return res, nil
+}
+
+// capabilities requests authentication capabilities with IPMI v2.0 extended data,
+// the request is repeated without it if the BMC rejects it. Nil capabilities mean no answer.
+func (s *Scanner) capabilities(conn net.Conn) (*authCapabilities, error) {
+	var result *authCapabilities
+	var rejected bool
+	for seq, extended := range []bool{true, false} {
+		rejected = false
+		answered, err := s.exchange(conn, authCapabilitiesRequest(byte(seq+1), extended), func(data []byte) bool {
+			caps, perr := parseAuthCapabilities(data, byte(seq+1))
+			if errors.Is(perr, errCompletionCode) {
+				rejected = true
+				return true
+			}
+			result = caps
+			return perr == nil
+		})
+		if err != nil || !answered || !rejected {
+			return result, err
+		}
+	}
+	return result, nil
+}
+
+// openSession sends the Open Session Request with the algorithms and returns the BMC session id,
+// zero id means the BMC didn't accept algorithms or didn't answer
+func (s *Scanner) openSession(conn net.Conn, auth, integrity, confidentiality byte) (bmcID uint32, err error) {
+	tag := byte(rand.Intn(256))
+	consoleID := rand.Uint32() | 1
+	_, err = s.exchange(conn, openSessionRequest(tag, consoleID, auth, integrity, confidentiality),
+		func(data []byte) bool {
+			resp, perr := parseOpenSessionResponse(data)
+			if perr != nil || resp.tag != tag || resp.consoleID != consoleID {
+				return false
+			}
+			if resp.status == 0 {
+				bmcID = resp.bmcID
+			}
+			return true
+		})
+	return
+}
+
+// rakp opens the session with cipher suite 3 and sends RAKP Message 1 with the user name,
+// disclosed is true if RAKP Message 2 contains the password HMAC. The result is not ok
+// if the BMC doesn't open sessions, so other user names are not checked.
+func (s *Scanner) rakp(conn net.Conn, user string) (disclosed, ok bool, err error) {
+	var bmcID uint32
+	if bmcID, err = s.openSession(conn, algRAKPHMACSHA1, algHMACSHA196, algAESCBC128); err != nil || bmcID == 0 {
+		return
+	}
+	ok = true
+	tag := byte(rand.Intn(256))
+	random := make([]byte, randomSize)
+	_, _ = rand.Read(random)
+	_, err = s.exchange(conn, rakp1Request(tag, bmcID, random, user), func(data []byte) bool {
+		resp, perr := parseRAKP2Response(data)
+		if perr != nil || resp.tag != tag {
+			return false
+		}
+		disclosed = resp.status == 0 && len(resp.authCode) > 0
+		return true
+	})
+	return
+}
+
+// exchange sends the request and reads responses until the handler accepts one,
+// answered is false if no response was accepted within the data timeout
+func (s *Scanner) exchange(conn net.Conn, request []byte, handle func(data []byte) bool) (answered bool, err error) {
+	if _, err = conn.Write(request); err != nil {
+		return
+	}
+	if err = conn.SetReadDeadline(time.Now().Add(s.dataTimeout)); err != nil {
+		return
+	}
+	buf := make([]byte, maxPacketSize)
+	for {
+		n, rerr := conn.Read(buf)
+		if isTimeout(rerr) {
+			return false, nil
+		}
+		if rerr != nil {
+			return false, rerr
+		}
+		if handle(buf[:n]) {
+			return true, nil
+		}
+	}
+}
+
+func isTimeout(err error) bool {
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
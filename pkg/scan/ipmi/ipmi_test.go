@@ -0,0 +1,149 @@
+package ipmi
+
+import (
+	"context"
+	"encoding/binary"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+type fakeBMC struct {
+	conn net.PacketConn
+	// ipmi15Only makes the BMC reject requests of IPMI v2.0 extended data
+	ipmi15Only bool
+	cipherZero bool
+	// users are user names the BMC returns RAKP Message 2 for
+	users map[string]bool
+	// silent disables all answers
+	silent bool
+}
+
+// startFakeBMC starts UDP IPMI server
+func startFakeBMC(t *testing.T, bmc *fakeBMC) *scan.Request {
+	t.Helper()
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	bmc.conn = conn
+	go bmc.serve()
+	addr := conn.LocalAddr().(*net.UDPAddr)
+	return &scan.Request{DstIP: addr.IP, DstPort: uint16(addr.Port)}
+}
+
+func (b *fakeBMC) serve() {
+	buf := make([]byte, maxPacketSize)
+	for {
+		n, addr, err := b.conn.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+		if resp := b.answer(buf[:n]); resp != nil && !b.silent {
+			_, _ = b.conn.WriteTo(resp, addr)
+		}
+	}
+}
+
+func (b *fakeBMC) answer(data []byte) []byte {
+	if len(data) < 16 {
+		return nil
+	}
+	if data[4] == authTypeNone {
+		msg := data[14:]
+		seq, channel := msg[4]>>2, msg[6]
+		switch {
+		case channel&extendedCapabilities != 0 && b.ipmi15Only:
+			return capabilitiesResponse(seq, 0xcc, nil)
+		case b.ipmi15Only:
+			return capabilitiesResponse(seq, completionOK, []byte{0x01, 0x14, 0x04, 0x00, 0, 0, 0, 0})
+		default:
+			return capabilitiesResponse(seq, completionOK, []byte{0x01, 0x96, 0x04, 0x03, 0xa2, 0x02, 0x00, 0x00})
+		}
+	}
+	payload := data[16:]
+	switch data[5] {
+	case payloadOpenSessionRequest:
+		consoleID := binary.LittleEndian.Uint32(payload[4:8])
+		if payload[12] == algNone && !b.cipherZero {
+			// no matching cipher suite
+			return openSessionResponsePacket(payload[0], 0x11, consoleID, 0)
+		}
+		return openSessionResponsePacket(payload[0], 0, consoleID, 0x0a0b0c0d)
+	case payloadRAKP1:
+		user := string(payload[28 : 28+int(payload[27])])
+		if !b.users[user] {
+			// unauthorized name
+			return rakp2ResponsePacket(payload[0], 0x0d, 1, nil)
+		}
+		return rakp2ResponsePacket(payload[0], 0, 1, make([]byte, 20))
+	}
+	return nil
+}
+
+func TestScan(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		bmc      *fakeBMC
+		opts     []ScannerOption
+		expected *ScanResult
+	}{
+		{
+			name: "IPMI20",
+			bmc:  &fakeBMC{users: map[string]bool{"ADMIN": true, "root": true}},
+			expected: &ScanResult{Version: "2.0", AuthTypes: []string{"md2", "md5", "password"}, OEMID: 674,
+				RAKPUsers: []string{"ADMIN", "root"}},
+		},
+		{
+			name: "CipherZero",
+			bmc:  &fakeBMC{cipherZero: true, users: map[string]bool{"": true}},
+			expected: &ScanResult{Version: "2.0", AuthTypes: []string{"md2", "md5", "password"}, OEMID: 674,
+				CipherZero: true, RAKPUsers: []string{""}},
+		},
+		{
+			name: "Users",
+			bmc:  &fakeBMC{users: map[string]bool{"ADMIN": true, "operator": true}},
+			opts: []ScannerOption{WithUsers([]string{"operator"})},
+			expected: &ScanResult{Version: "2.0", AuthTypes: []string{"md2", "md5", "password"}, OEMID: 674,
+				RAKPUsers: []string{"operator"}},
+		},
+		{
+			name:     "IPMI15",
+			bmc:      &fakeBMC{ipmi15Only: true},
+			expected: &ScanResult{Version: "1.5", AuthTypes: []string{"md5", "password"}},
+		},
+		{
+			name: "Silent",
+			bmc:  &fakeBMC{silent: true},
+		},
+	}
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			req := startFakeBMC(t, tt.bmc)
+			opts := append([]ScannerOption{WithDataTimeout(200 * time.Millisecond)}, tt.opts...)
+			result, err := NewScanner(opts...).Scan(context.Background(), req)
+			require.NoError(t, err)
+			if tt.expected == nil {
+				require.Nil(t, result)
+				return
+			}
+			expected := tt.expected
+			expected.ScanType = ScanType
+			expected.IP = req.DstIP.String()
+			expected.Port = req.DstPort
+			require.Equal(t, expected, result)
+		})
+	}
+}
+
+func TestScanResultString(t *testing.T) {
+	t.Parallel()
+	r := &ScanResult{IP: "10.0.0.1", Port: 623, Version: "2.0", AuthTypes: []string{"md5", "password"},
+		CipherZero: true, RAKPUsers: []string{"", "ADMIN"}}
+	require.Equal(t, `10.0.0.1             623   v2.0 auth md5,password cipher-zero rakp "","ADMIN"`, r.String())
+}
@@ -0,0 +1,266 @@
+package ipmi
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+// RMCP header fields, see IPMI v2.0 specification section 13.1.3
+const (
+	rmcpVersion    = 0x06
+	rmcpNoAck      = 0xff
+	rmcpClassIPMI  = 0x07
+	rmcpHeaderSize = 4
+)
+
+// session header auth types and RMCP+ payload types, see sections 13.6 and 13.27.3
+const (
+	authTypeNone  = 0x00
+	authTypeRMCPP = 0x06
+
+	payloadOpenSessionRequest  = 0x10
+	payloadOpenSessionResponse = 0x11
+	payloadRAKP1               = 0x12
+	payloadRAKP2               = 0x13
+)
+
+// Get Channel Authentication Capabilities command, see section 22.13
+const (
+	bmcAddr              = 0x20
+	remoteAddr           = 0x81
+	netFnApp             = 0x06
+	cmdGetChannelAuthCap = 0x38
+	// currentChannel requests capabilities of the channel the request is received on
+	currentChannel = 0x0e
+	// extendedCapabilities requests IPMI v2.0 extended data, BMCs that support only IPMI v1.5 reject it
+	extendedCapabilities = 0x80
+	privilegeAdmin       = 0x04
+	// privilegeNameOnly makes the BMC look up the user by the name only, without the requested privilege
+	privilegeNameOnly = 0x10
+
+	completionOK = 0x00
+)
+
+// RMCP+ authentication, integrity and confidentiality algorithms, see section 13.28
+const (
+	algNone         = 0x00
+	algRAKPHMACSHA1 = 0x01
+	algHMACSHA196   = 0x01
+	algAESCBC128    = 0x01
+)
+
+// MaxUserNameSize is the maximum length of user names in RAKP Message 1
+const MaxUserNameSize = 16
+
+const (
+	randomSize = 16
+	// rakp2AuthOffset is the offset of the key exchange auth code in RAKP Message 2
+	rakp2AuthOffset = 8 + 2*randomSize
+)
+
+var (
+	errInvalidMessage = scan.NewError(scan.ErrParse, errors.New("invalid IPMI message"))
+	errCompletionCode = errors.New("IPMI command failed")
+)
+
+// authTypeNames are authentication types of the bits of the Authentication Type Support field
+var authTypeNames = []string{"none", "md2", "md5", "", "password", "oem"}
+
+type authCapabilities struct {
+	// ipmi15 and ipmi20 are set if the BMC supports IPMI v1.5 and v2.0 connections
+	ipmi15    bool
+	ipmi20    bool
+	authTypes []string
+	// nullUsers is set if users with empty names are enabled
+	nullUsers bool
+	anonymous bool
+	oemID     uint32
+}
+
+func rmcpHeader() []byte {
+	return []byte{rmcpVersion, 0, rmcpNoAck, rmcpClassIPMI}
+}
+
+// checksum returns the two's complement of the sum of bytes
+func checksum(data []byte) byte {
+	var sum byte
+	for _, b := range data {
+		sum += b
+	}
+	return -sum
+}
+
+// authCapabilitiesRequest returns the Get Channel Authentication Capabilities request
+// in the IPMI v1.5 session without authentication
+func authCapabilitiesRequest(seq byte, extended bool) []byte {
+	channel := byte(currentChannel)
+	if extended {
+		channel |= extendedCapabilities
+	}
+	msg := []byte{bmcAddr, netFnApp << 2}
+	msg = append(msg, checksum(msg))
+	body := []byte{remoteAddr, seq << 2, cmdGetChannelAuthCap, channel, privilegeAdmin}
+	msg = append(msg, body...)
+	msg = append(msg, checksum(body))
+
+	packet := rmcpHeader()
+	// auth type, session sequence number and session id
+	packet = append(packet, authTypeNone, 0, 0, 0, 0, 0, 0, 0, 0, byte(len(msg)))
+	return append(packet, msg...)
+}
+
+// parseAuthCapabilities parses the response to the Get Channel Authentication Capabilities request
+func parseAuthCapabilities(data []byte, seq byte) (*authCapabilities, error) {
+	if len(data) < rmcpHeaderSize || data[0] != rmcpVersion || data[3]&0x1f != rmcpClassIPMI {
+		return nil, errInvalidMessage
+	}
+	data = data[rmcpHeaderSize:]
+	if len(data) < 10 {
+		return nil, errInvalidMessage
+	}
+	headerSize := 10
+	if data[0] != authTypeNone {
+		// 16-byte auth code follows the session id
+		headerSize += 16
+	}
+	if len(data) < headerSize {
+		return nil, errInvalidMessage
+	}
+	msg := data[headerSize:]
+	if msgSize := int(data[headerSize-1]); msgSize < len(msg) {
+		msg = msg[:msgSize]
+	}
+	// rqAddr, netFn, checksum, rsAddr, seq, cmd, completion code
+	if len(msg) < 7 || msg[1]>>2 != netFnApp+1 || msg[5] != cmdGetChannelAuthCap || msg[4]>>2 != seq {
+		return nil, errInvalidMessage
+	}
+	if msg[6] != completionOK {
+		return nil, fmt.Errorf("%w: completion code %#x", errCompletionCode, msg[6])
+	}
+	// channel, auth type support, auth status, extended capabilities, OEM id and aux data
+	body := msg[7:]
+	if len(body) < 8 {
+		return nil, fmt.Errorf("%w: short capabilities", errInvalidMessage)
+	}
+	result := &authCapabilities{
+		ipmi15:    true,
+		nullUsers: body[2]&0x02 != 0,
+		anonymous: body[2]&0x01 != 0,
+		oemID:     uint32(body[4]) | uint32(body[5])<<8 | uint32(body[6])<<16,
+	}
+	for i, name := range authTypeNames {
+		if len(name) > 0 && body[1]&(1<<i) != 0 {
+			result.authTypes = append(result.authTypes, name)
+		}
+	}
+	if body[1]&0x80 != 0 {
+		result.ipmi15 = body[3]&0x01 != 0
+		result.ipmi20 = body[3]&0x02 != 0
+	}
+	return result, nil
+}
+
+// rmcpPlusPacket returns the RMCP+ packet of the payload outside of the session
+func rmcpPlusPacket(payloadType byte, payload []byte) []byte {
+	packet := rmcpHeader()
+	packet = append(packet, authTypeRMCPP, payloadType)
+	// session id and session sequence number
+	packet = append(packet, 0, 0, 0, 0, 0, 0, 0, 0)
+	packet = binary.LittleEndian.AppendUint16(packet, uint16(len(payload)))
+	return append(packet, payload...)
+}
+
+// parseRMCPPlus returns the payload of the RMCP+ packet of the payload type
+func parseRMCPPlus(data []byte, payloadType byte) ([]byte, error) {
+	const headerSize = rmcpHeaderSize + 12
+	if len(data) < headerSize || data[0] != rmcpVersion || data[3]&0x1f != rmcpClassIPMI ||
+		data[4] != authTypeRMCPP || data[5]&0x3f != payloadType {
+		return nil, errInvalidMessage
+	}
+	size := int(binary.LittleEndian.Uint16(data[headerSize-2 : headerSize]))
+	if headerSize+size > len(data) {
+		return nil, errInvalidMessage
+	}
+	return data[headerSize : headerSize+size], nil
+}
+
+// openSessionRequest returns the RMCP+ Open Session Request with the algorithms of the cipher suite
+func openSessionRequest(tag byte, consoleID uint32, auth, integrity, confidentiality byte) []byte {
+	// requested maximum privilege is the highest level matching proposed algorithms
+	payload := []byte{tag, 0, 0, 0}
+	payload = binary.LittleEndian.AppendUint32(payload, consoleID)
+	for i, alg := range []byte{auth, integrity, confidentiality} {
+		payload = append(payload, byte(i), 0, 0, 8, alg, 0, 0, 0)
+	}
+	return rmcpPlusPacket(payloadOpenSessionRequest, payload)
+}
+
+type openSessionResponse struct {
+	tag       byte
+	status    byte
+	consoleID uint32
+	bmcID     uint32
+}
+
+func parseOpenSessionResponse(data []byte) (*openSessionResponse, error) {
+	payload, err := parseRMCPPlus(data, payloadOpenSessionResponse)
+	if err != nil {
+		return nil, err
+	}
+	if len(payload) < 8 {
+		return nil, fmt.Errorf("%w: short open session response", errInvalidMessage)
+	}
+	result := &openSessionResponse{
+		tag:       payload[0],
+		status:    payload[1],
+		consoleID: binary.LittleEndian.Uint32(payload[4:8]),
+	}
+	if result.status == 0 {
+		if len(payload) < 12 {
+			return nil, fmt.Errorf("%w: short open session response", errInvalidMessage)
+		}
+		result.bmcID = binary.LittleEndian.Uint32(payload[8:12])
+	}
+	return result, nil
+}
+
+// rakp1Request returns RAKP Message 1 with the user name looked up by the name only
+func rakp1Request(tag byte, bmcID uint32, random []byte, user string) []byte {
+	payload := []byte{tag, 0, 0, 0}
+	payload = binary.LittleEndian.AppendUint32(payload, bmcID)
+	payload = append(payload, random...)
+	payload = append(payload, privilegeNameOnly|privilegeAdmin, 0, 0, byte(len(user)))
+	payload = append(payload, user...)
+	return rmcpPlusPacket(payloadRAKP1, payload)
+}
+
+type rakp2Response struct {
+	tag       byte
+	status    byte
+	consoleID uint32
+	// authCode is the HMAC of the session parameters keyed by the user password
+	authCode []byte
+}
+
+func parseRAKP2Response(data []byte) (*rakp2Response, error) {
+	payload, err := parseRMCPPlus(data, payloadRAKP2)
+	if err != nil {
+		return nil, err
+	}
+	if len(payload) < 8 {
+		return nil, fmt.Errorf("%w: short RAKP message 2", errInvalidMessage)
+	}
+	result := &rakp2Response{
+		tag:       payload[0],
+		status:    payload[1],
+		consoleID: binary.LittleEndian.Uint32(payload[4:8]),
+	}
+	// BMC random and GUID precede the auth code
+	if len(payload) > rakp2AuthOffset {
+		result.authCode = payload[rakp2AuthOffset:]
+	}
+	return result, nil
+}
@@ -0,0 +1,158 @@
+package ipmi
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// capabilitiesResponse returns the Get Channel Authentication Capabilities response with the data
+func capabilitiesResponse(seq, completionCode byte, data []byte) []byte {
+	msg := []byte{remoteAddr, (netFnApp + 1) << 2}
+	msg = append(msg, checksum(msg))
+	body := append([]byte{bmcAddr, seq << 2, cmdGetChannelAuthCap, completionCode}, data...)
+	msg = append(msg, body...)
+	msg = append(msg, checksum(body))
+
+	packet := rmcpHeader()
+	packet = append(packet, authTypeNone, 0, 0, 0, 0, 0, 0, 0, 0, byte(len(msg)))
+	return append(packet, msg...)
+}
+
+func openSessionResponsePacket(tag, status byte, consoleID, bmcID uint32) []byte {
+	payload := []byte{tag, status, privilegeAdmin, 0}
+	payload = binary.LittleEndian.AppendUint32(payload, consoleID)
+	if status == 0 {
+		payload = binary.LittleEndian.AppendUint32(payload, bmcID)
+		payload = append(payload, make([]byte, 24)...)
+	}
+	return rmcpPlusPacket(payloadOpenSessionResponse, payload)
+}
+
+func rakp2ResponsePacket(tag, status byte, consoleID uint32, authCode []byte) []byte {
+	payload := []byte{tag, status, 0, 0}
+	payload = binary.LittleEndian.AppendUint32(payload, consoleID)
+	if status == 0 {
+		payload = append(payload, make([]byte, 2*randomSize)...)
+		payload = append(payload, authCode...)
+	}
+	return rmcpPlusPacket(payloadRAKP2, payload)
+}
+
+func TestAuthCapabilitiesRequest(t *testing.T) {
+	t.Parallel()
+	require.Equal(t, []byte{
+		0x06, 0x00, 0xff, 0x07,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x09,
+		0x20, 0x18, 0xc8, 0x81, 0x00, 0x38, 0x8e, 0x04, 0xb5,
+	}, authCapabilitiesRequest(0, true))
+
+	packet := authCapabilitiesRequest(1, false)
+	require.Equal(t, byte(0x04), packet[18])
+	require.Equal(t, byte(currentChannel), packet[20])
+	require.Equal(t, byte(0), checksum(packet[17:]))
+}
+
+func TestParseAuthCapabilities(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		data     []byte
+		expected *authCapabilities
+	}{
+		{
+			name: "IPMI20",
+			data: capabilitiesResponse(1, completionOK, []byte{0x01, 0x97, 0x06, 0x03, 0xa2, 0x02, 0x00, 0x00}),
+			expected: &authCapabilities{ipmi15: true, ipmi20: true,
+				authTypes: []string{"none", "md2", "md5", "password"}, nullUsers: true, oemID: 674},
+		},
+		{
+			name:     "IPMI20Only",
+			data:     capabilitiesResponse(1, completionOK, []byte{0x01, 0x80, 0x01, 0x02, 0, 0, 0, 0}),
+			expected: &authCapabilities{ipmi20: true, anonymous: true},
+		},
+		{
+			name:     "IPMI15",
+			data:     capabilitiesResponse(1, completionOK, []byte{0x01, 0x14, 0x04, 0x00, 0, 0, 0, 0}),
+			expected: &authCapabilities{ipmi15: true, authTypes: []string{"md5", "password"}},
+		},
+	}
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			result, err := parseAuthCapabilities(tt.data, 1)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestParseAuthCapabilitiesError(t *testing.T) {
+	t.Parallel()
+	data := []byte{0x01, 0x80, 0x01, 0x02, 0, 0, 0, 0}
+
+	_, err := parseAuthCapabilities(capabilitiesResponse(1, 0xcc, nil), 1)
+	require.ErrorIs(t, err, errCompletionCode)
+
+	_, err = parseAuthCapabilities(capabilitiesResponse(2, completionOK, data), 1)
+	require.ErrorIs(t, err, errInvalidMessage)
+
+	_, err = parseAuthCapabilities(capabilitiesResponse(1, completionOK, data[:4]), 1)
+	require.ErrorIs(t, err, errInvalidMessage)
+
+	_, err = parseAuthCapabilities([]byte{0x06, 0x00, 0xff, 0x06}, 1)
+	require.ErrorIs(t, err, errInvalidMessage)
+}
+
+func TestOpenSessionRequest(t *testing.T) {
+	t.Parallel()
+	packet := openSessionRequest(7, 0x11223344, algRAKPHMACSHA1, algHMACSHA196, algAESCBC128)
+	payload, err := parseRMCPPlus(packet, payloadOpenSessionRequest)
+	require.NoError(t, err)
+	require.Equal(t, []byte{
+		0x07, 0x00, 0x00, 0x00, 0x44, 0x33, 0x22, 0x11,
+		0x00, 0x00, 0x00, 0x08, 0x01, 0x00, 0x00, 0x00,
+		0x01, 0x00, 0x00, 0x08, 0x01, 0x00, 0x00, 0x00,
+		0x02, 0x00, 0x00, 0x08, 0x01, 0x00, 0x00, 0x00,
+	}, payload)
+}
+
+func TestParseOpenSessionResponse(t *testing.T) {
+	t.Parallel()
+	resp, err := parseOpenSessionResponse(openSessionResponsePacket(7, 0, 0x11223344, 0x0a0b0c0d))
+	require.NoError(t, err)
+	require.Equal(t, &openSessionResponse{tag: 7, consoleID: 0x11223344, bmcID: 0x0a0b0c0d}, resp)
+
+	resp, err = parseOpenSessionResponse(openSessionResponsePacket(7, 0x11, 0x11223344, 0))
+	require.NoError(t, err)
+	require.Equal(t, &openSessionResponse{tag: 7, status: 0x11, consoleID: 0x11223344}, resp)
+
+	_, err = parseOpenSessionResponse(rakp2ResponsePacket(7, 0, 0x11223344, nil))
+	require.ErrorIs(t, err, errInvalidMessage)
+}
+
+func TestRAKP1Request(t *testing.T) {
+	t.Parallel()
+	random := make([]byte, randomSize)
+	payload, err := parseRMCPPlus(rakp1Request(3, 0x0a0b0c0d, random, "ADMIN"), payloadRAKP1)
+	require.NoError(t, err)
+	require.Len(t, payload, 28+len("ADMIN"))
+	require.Equal(t, []byte{0x03, 0x00, 0x00, 0x00, 0x0d, 0x0c, 0x0b, 0x0a}, payload[:8])
+	require.Equal(t, []byte{0x14, 0x00, 0x00, 0x05}, payload[24:28])
+	require.Equal(t, "ADMIN", string(payload[28:]))
+}
+
+func TestParseRAKP2Response(t *testing.T) {
+	t.Parallel()
+	authCode := make([]byte, 20)
+	authCode[0] = 0xff
+	resp, err := parseRAKP2Response(rakp2ResponsePacket(3, 0, 0x11223344, authCode))
+	require.NoError(t, err)
+	require.Equal(t, &rakp2Response{tag: 3, consoleID: 0x11223344, authCode: authCode}, resp)
+
+	resp, err = parseRAKP2Response(rakp2ResponsePacket(3, 0x0d, 0x11223344, nil))
+	require.NoError(t, err)
+	require.Equal(t, &rakp2Response{tag: 3, status: 0x0d, consoleID: 0x11223344}, resp)
+}
@@ -0,0 +1,288 @@
+package scan
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// IPv6 targeted-sweep strategies, see RFC 7707 section 4.1.
+// Each strategy generates host parts of addresses that are commonly assigned manually,
+// so that a subnet like /64 is scanned with thousands of requests instead of 2^64.
+const (
+	// IPv6LowByte generates addresses with only the lowest bits of the interface identifier set, e.g. ::1, ::2
+	IPv6LowByte = "low-byte"
+	// IPv6EmbeddedIPv4 generates addresses that embed IPv4 addresses of the configured subnet,
+	// e.g. ::192.168.0.1 and ::192:168:0:1
+	IPv6EmbeddedIPv4 = "embedded-ipv4"
+	// IPv6Wordy generates addresses with hex words and service ports, e.g. ::cafe, ::dead:beef, ::80
+	IPv6Wordy = "wordy"
+	// IPv6Hitlist generates addresses with host parts of known addresses read from the hitlist file
+	IPv6Hitlist = "hitlist"
+
+	defaultLowByteCount = 255
+)
+
+var (
+	ErrIPv6Strategy = NewError(ErrParse, errors.New("invalid IPv6 strategy"))
+	ErrIPv6Subnet   = NewError(ErrParse, errors.New("invalid IPv6 subnet"))
+)
+
+// IPv6Strategies returns names of all IPv6 targeted-sweep strategies
+func IPv6Strategies() []string {
+	return []string{IPv6LowByte, IPv6EmbeddedIPv4, IPv6Wordy, IPv6Hitlist}
+}
+
+// wordyHostParts are interface identifiers with hex words and service ports written in decimal
+var wordyHostParts = []string{
+	"::cafe", "::babe", "::beef", "::dead", "::face", "::feed", "::f00d", "::c0de", "::bad", "::ace",
+	"::add", "::bee", "::b00c", "::ba5e", "::c0c0", "::d00d", "::fade", "::abba",
+	"::dead:beef", "::cafe:babe", "::face:b00c", "::bad:c0de", "::c0ff:ee", "::dead:c0de", "::bad:cafe",
+	"::21", "::22", "::25", "::53", "::80", "::110", "::143", "::443", "::993", "::995",
+	"::3306", "::5432", "::8080", "::8443",
+}
+
+type IPv6GeneratorOption func(*ipv6Generator)
+
+// WithIPv6Strategies sets strategies of the generator, addresses are generated in the order of strategies
+func WithIPv6Strategies(strategies ...string) IPv6GeneratorOption {
+	return func(g *ipv6Generator) {
+		g.strategies = strategies
+	}
+}
+
+// WithLowByteCount sets the number of low-byte addresses, ::1 through ::ff by default
+func WithLowByteCount(count int) IPv6GeneratorOption {
+	return func(g *ipv6Generator) {
+		g.lowByteCount = count
+	}
+}
+
+// WithEmbeddedIPv4Subnet sets IPv4 addresses embedded into interface identifiers by the IPv6EmbeddedIPv4 strategy
+func WithEmbeddedIPv4Subnet(subnet *net.IPNet) IPv6GeneratorOption {
+	return func(g *ipv6Generator) {
+		g.embeddedSubnet = subnet
+	}
+}
+
+// WithHitlist sets the file with known IPv6 addresses for the IPv6Hitlist strategy,
+// one address per line, blank lines and comments starting with # are ignored
+func WithHitlist(openFile OpenFileFunc) IPv6GeneratorOption {
+	return func(g *ipv6Generator) {
+		g.openHitlist = openFile
+	}
+}
+
+type ipv6Generator struct {
+	strategies     []string
+	lowByteCount   int
+	embeddedSubnet *net.IPNet
+	openHitlist    OpenFileFunc
+}
+
+// NewIPv6Generator creates an IPGenerator that sweeps IPv6 subnets with targeted strategies,
+// host parts that don't fit into the subnet are skipped and duplicate addresses are generated once
+func NewIPv6Generator(opts ...IPv6GeneratorOption) (IPGenerator, error) {
+	g := &ipv6Generator{
+		strategies:   []string{IPv6LowByte, IPv6Wordy},
+		lowByteCount: defaultLowByteCount,
+	}
+	for _, o := range opts {
+		o(g)
+	}
+	if len(g.strategies) == 0 {
+		return nil, ErrIPv6Strategy
+	}
+	for _, strategy := range g.strategies {
+		switch strategy {
+		case IPv6LowByte, IPv6Wordy:
+		case IPv6EmbeddedIPv4:
+			if g.embeddedSubnet == nil || g.embeddedSubnet.IP.To4() == nil {
+				return nil, fmt.Errorf("%w: %s requires IPv4 subnet", ErrIPv6Strategy, strategy)
+			}
+		case IPv6Hitlist:
+			if g.openHitlist == nil {
+				return nil, fmt.Errorf("%w: %s requires hitlist file", ErrIPv6Strategy, strategy)
+			}
+		default:
+			return nil, fmt.Errorf("%w: %s", ErrIPv6Strategy, strategy)
+		}
+	}
+	if g.lowByteCount <= 0 {
+		return nil, fmt.Errorf("%w: invalid low-byte count", ErrIPv6Strategy)
+	}
+	return g, nil
+}
+
+func (g *ipv6Generator) IPs(ctx context.Context, r *Range) (<-chan IPGetter, error) {
+	if r.DstSubnet == nil || len(r.DstSubnet.IP) != net.IPv6len || len(r.DstSubnet.Mask) != net.IPv6len {
+		return nil, ErrIPv6Subnet
+	}
+	var hitlist io.ReadCloser
+	if g.hasStrategy(IPv6Hitlist) {
+		var err error
+		if hitlist, err = g.openHitlist(); err != nil {
+			return nil, err
+		}
+	}
+	s := &ipv6Sweep{ctx: ctx, subnet: r.DstSubnet, out: make(chan IPGetter, 100), seen: make(map[string]struct{})}
+	go func() {
+		defer close(s.out)
+		if hitlist != nil {
+			defer hitlist.Close()
+		}
+		g.sweep(s, hitlist)
+	}()
+	return s.out, nil
+}
+
+func (g *ipv6Generator) hasStrategy(name string) bool {
+	for _, strategy := range g.strategies {
+		if strategy == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (g *ipv6Generator) sweep(s *ipv6Sweep, hitlist io.Reader) {
+	for _, strategy := range g.strategies {
+		var ok bool
+		switch strategy {
+		case IPv6LowByte:
+			ok = g.lowByte(s)
+		case IPv6EmbeddedIPv4:
+			ok = g.embeddedIPv4(s)
+		case IPv6Wordy:
+			ok = wordy(s)
+		case IPv6Hitlist:
+			ok = readHitlist(s, hitlist)
+		}
+		if !ok {
+			return
+		}
+	}
+}
+
+func (g *ipv6Generator) lowByte(s *ipv6Sweep) bool {
+	hostPart := make(net.IP, net.IPv6len)
+	for i := 1; i <= g.lowByteCount; i++ {
+		big.NewInt(int64(i)).FillBytes(hostPart[8:])
+		if !s.write(hostPart) {
+			return false
+		}
+	}
+	return true
+}
+
+func (g *ipv6Generator) embeddedIPv4(s *ipv6Sweep) bool {
+	subnet := g.embeddedSubnet
+	ones, bits := subnet.Mask.Size()
+	base := big.NewInt(0).SetBytes(subnet.IP.To4().Mask(subnet.Mask))
+	size := big.NewInt(1)
+	size.Lsh(size, uint(bits-ones))
+	ipv4 := make(net.IP, net.IPv4len)
+	for i := big.NewInt(0); i.Cmp(size) < 0; i.Add(i, big.NewInt(1)) {
+		big.NewInt(0).Add(base, i).FillBytes(ipv4)
+		// ::192.168.0.1
+		hostPart := make(net.IP, net.IPv6len)
+		copy(hostPart[12:], ipv4)
+		if !s.write(hostPart) {
+			return false
+		}
+		// ::192:168:0:1 where each decimal octet is written as a hex group
+		hostPart = make(net.IP, net.IPv6len)
+		for j, octet := range ipv4 {
+			group, _ := strconv.ParseUint(strconv.Itoa(int(octet)), 16, 16)
+			hostPart[8+2*j] = byte(group >> 8)
+			hostPart[9+2*j] = byte(group)
+		}
+		if !s.write(hostPart) {
+			return false
+		}
+	}
+	return true
+}
+
+func wordy(s *ipv6Sweep) bool {
+	for _, addr := range wordyHostParts {
+		if !s.write(net.ParseIP(addr)) {
+			return false
+		}
+	}
+	return true
+}
+
+// readHitlist applies host parts of known addresses to the subnet,
+// so hosts of other subnets with the same addressing scheme are found
+func readHitlist(s *ipv6Sweep, hitlist io.Reader) bool {
+	scanner := bufio.NewScanner(hitlist)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if comment := strings.Index(line, "#"); comment != -1 {
+			line = line[:comment]
+		}
+		if line = strings.TrimSpace(line); len(line) == 0 {
+			continue
+		}
+		seed := net.ParseIP(line)
+		if seed == nil || seed.To4() != nil {
+			if !s.send(&ipError{error: ErrIP}) {
+				return false
+			}
+			continue
+		}
+		hostPart := make(net.IP, net.IPv6len)
+		for i := range hostPart {
+			hostPart[i] = seed[i] &^ s.subnet.Mask[i]
+		}
+		if !s.write(hostPart) {
+			return false
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return s.send(&ipError{error: err})
+	}
+	return true
+}
+
+// ipv6Sweep writes unique addresses of the subnet to the output channel
+type ipv6Sweep struct {
+	ctx    context.Context
+	subnet *net.IPNet
+	out    chan IPGetter
+	seen   map[string]struct{}
+}
+
+// write combines the host part with the subnet prefix, it returns false if the context is done
+func (s *ipv6Sweep) write(hostPart net.IP) bool {
+	addr := make(net.IP, net.IPv6len)
+	for i := range addr {
+		// the host part doesn't fit into the subnet
+		if hostPart[i]&s.subnet.Mask[i] != 0 {
+			return true
+		}
+		addr[i] = s.subnet.IP[i]&s.subnet.Mask[i] | hostPart[i]
+	}
+	key := string(addr)
+	if _, ok := s.seen[key]; ok {
+		return true
+	}
+	s.seen[key] = struct{}{}
+	return s.send(WrapIP(addr))
+}
+
+func (s *ipv6Sweep) send(ip IPGetter) bool {
+	select {
+	case <-s.ctx.Done():
+		return false
+	case s.out <- ip:
+		return true
+	}
+}
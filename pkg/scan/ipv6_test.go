@@ -0,0 +1,203 @@
+package scan
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func ipv6Subnet(t *testing.T, subnet string) *Range {
+	t.Helper()
+	_, ipnet, err := net.ParseCIDR(subnet)
+	require.NoError(t, err)
+	return &Range{DstSubnet: ipnet}
+}
+
+func wrapIPs(addrs ...string) []interface{} {
+	result := make([]interface{}, 0, len(addrs))
+	for _, addr := range addrs {
+		result = append(result, WrapIP(net.ParseIP(addr)))
+	}
+	return result
+}
+
+func TestIPv6Generator(t *testing.T) {
+	t.Parallel()
+	_, embeddedSubnet, err := net.ParseCIDR("192.168.0.1/31")
+	require.NoError(t, err)
+	hitlist := strings.Join([]string{
+		"# known hosts",
+		"2001:db8:1::1:2",
+		"",
+		"2001:db8:2::cafe  # duplicate of wordy address",
+		"2001:db8:3::abcd:1:2:3",
+	}, "\n")
+
+	tests := []struct {
+		name      string
+		opts      []IPv6GeneratorOption
+		scanRange string
+		expected  []interface{}
+	}{
+		{
+			name:      "LowByte",
+			opts:      []IPv6GeneratorOption{WithIPv6Strategies(IPv6LowByte), WithLowByteCount(3)},
+			scanRange: "2001:db8::/64",
+			expected:  wrapIPs("2001:db8::1", "2001:db8::2", "2001:db8::3"),
+		},
+		{
+			name:      "LowByteSmallSubnet",
+			opts:      []IPv6GeneratorOption{WithIPv6Strategies(IPv6LowByte), WithLowByteCount(5)},
+			scanRange: "2001:db8::/126",
+			expected:  wrapIPs("2001:db8::1", "2001:db8::2", "2001:db8::3"),
+		},
+		{
+			name: "EmbeddedIPv4",
+			opts: []IPv6GeneratorOption{
+				WithIPv6Strategies(IPv6EmbeddedIPv4), WithEmbeddedIPv4Subnet(embeddedSubnet)},
+			scanRange: "2001:db8::/64",
+			expected: wrapIPs("2001:db8::192.168.0.0", "2001:db8::192:168:0:0",
+				"2001:db8::192.168.0.1", "2001:db8::192:168:0:1"),
+		},
+		{
+			name:      "WordyDeduplicatesLowByte",
+			opts:      []IPv6GeneratorOption{WithIPv6Strategies(IPv6Wordy, IPv6LowByte), WithLowByteCount(0x22)},
+			scanRange: "2001:db8::/120",
+			expected: append(wrapIPs("2001:db8::21", "2001:db8::22", "2001:db8::25", "2001:db8::53", "2001:db8::80"),
+				wrapIPs(lowBytes("2001:db8::", 0x20)...)...),
+		},
+		{
+			name: "Hitlist",
+			opts: []IPv6GeneratorOption{WithIPv6Strategies(IPv6Wordy, IPv6Hitlist), WithHitlist(func() (io.ReadCloser, error) {
+				return io.NopCloser(strings.NewReader(hitlist)), nil
+			})},
+			scanRange: "2001:db8:ff::/64",
+			expected: append(wrapIPs(withPrefix("2001:db8:ff::", wordyHostParts)...),
+				wrapIPs("2001:db8:ff::1:2", "2001:db8:ff:0:abcd:1:2:3")...),
+		},
+		{
+			name: "HitlistSmallSubnet",
+			opts: []IPv6GeneratorOption{WithIPv6Strategies(IPv6Hitlist), WithHitlist(func() (io.ReadCloser, error) {
+				return io.NopCloser(strings.NewReader(hitlist)), nil
+			})},
+			scanRange: "2001:db8:ff::/112",
+			expected:  wrapIPs("2001:db8:ff::2", "2001:db8:ff::cafe", "2001:db8:ff::3"),
+		},
+	}
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			done := make(chan interface{})
+			go func() {
+				defer close(done)
+				ipgen, err := NewIPv6Generator(tt.opts...)
+				require.NoError(t, err)
+				ips, err := ipgen.IPs(context.Background(), ipv6Subnet(t, tt.scanRange))
+				require.NoError(t, err)
+				result := chanToSlice(t, chanIPToGeneric(ips), len(tt.expected))
+				require.Equal(t, tt.expected, result)
+			}()
+			waitDone(t, done)
+		})
+	}
+}
+
+// lowBytes returns low-byte addresses of the prefix from 1 to count except the already generated wordy ones
+func lowBytes(prefix string, count int) []string {
+	var result []string
+	for i := 1; i <= count; i++ {
+		addr := net.ParseIP(prefix)
+		addr[15] = byte(i)
+		if i == 0x21 || i == 0x22 {
+			continue
+		}
+		result = append(result, addr.String())
+	}
+	return result
+}
+
+// withPrefix returns addresses of the prefix with the host parts
+func withPrefix(prefix string, hostParts []string) []string {
+	result := make([]string, 0, len(hostParts))
+	for _, hostPart := range hostParts {
+		addr := net.ParseIP(prefix)
+		for i, b := range net.ParseIP(hostPart) {
+			addr[i] |= b
+		}
+		result = append(result, addr.String())
+	}
+	return result
+}
+
+func TestIPv6GeneratorInvalidHitlistAddress(t *testing.T) {
+	t.Parallel()
+	ipgen, err := NewIPv6Generator(WithIPv6Strategies(IPv6Hitlist), WithHitlist(func() (io.ReadCloser, error) {
+		return io.NopCloser(strings.NewReader("10.0.0.1\ninvalid\n2001:db8::1")), nil
+	}))
+	require.NoError(t, err)
+
+	ips, err := ipgen.IPs(context.Background(), ipv6Subnet(t, "2001:db8::/64"))
+	require.NoError(t, err)
+	result := chanToSlice(t, chanIPToGeneric(ips), 3)
+	require.Equal(t, []interface{}{
+		&ipError{error: ErrIP},
+		&ipError{error: ErrIP},
+		WrapIP(net.ParseIP("2001:db8::1")),
+	}, result)
+}
+
+func TestIPv6GeneratorErrors(t *testing.T) {
+	t.Parallel()
+
+	_, err := NewIPv6Generator(WithIPv6Strategies())
+	require.ErrorIs(t, err, ErrIPv6Strategy)
+	_, err = NewIPv6Generator(WithIPv6Strategies("random"))
+	require.ErrorIs(t, err, ErrIPv6Strategy)
+	_, err = NewIPv6Generator(WithIPv6Strategies(IPv6EmbeddedIPv4))
+	require.ErrorIs(t, err, ErrIPv6Strategy)
+	_, err = NewIPv6Generator(WithIPv6Strategies(IPv6Hitlist))
+	require.ErrorIs(t, err, ErrIPv6Strategy)
+	_, err = NewIPv6Generator(WithLowByteCount(0))
+	require.ErrorIs(t, err, ErrIPv6Strategy)
+
+	ipgen, err := NewIPv6Generator()
+	require.NoError(t, err)
+	_, err = ipgen.IPs(context.Background(), &Range{})
+	require.ErrorIs(t, err, ErrIPv6Subnet)
+	_, err = ipgen.IPs(context.Background(), ipv6Subnet(t, "192.168.0.0/24"))
+	require.ErrorIs(t, err, ErrIPv6Subnet)
+
+	openErr := errors.New("open error")
+	ipgen, err = NewIPv6Generator(WithIPv6Strategies(IPv6Hitlist), WithHitlist(func() (io.ReadCloser, error) {
+		return nil, openErr
+	}))
+	require.NoError(t, err)
+	_, err = ipgen.IPs(context.Background(), ipv6Subnet(t, "2001:db8::/64"))
+	require.ErrorIs(t, err, openErr)
+}
+
+func TestIPv6GeneratorContextExit(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+	ipgen, err := NewIPv6Generator(WithLowByteCount(1000))
+	require.NoError(t, err)
+	ips, err := ipgen.IPs(ctx, ipv6Subnet(t, "2001:db8::/64"))
+	require.NoError(t, err)
+	<-ips
+	cancel()
+
+	done := make(chan interface{})
+	go func() {
+		defer close(done)
+		for range ips {
+		}
+	}()
+	waitDone(t, done)
+}
@@ -0,0 +1,149 @@
+package jarm
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+const (
+	// emptyAnswer is the answer of a probe without ServerHello
+	emptyAnswer = "|||"
+	// emptyHash is the hash of a target that didn't answer any probe
+	emptyHash = "00000000000000000000000000000000000000000000000000000000000000"
+)
+
+// hashCiphers are ordered by the cipher value, the hash contains the cipher position
+var hashCiphers = []uint16{
+	0x0004, 0x0005, 0x0007, 0x000a, 0x0016, 0x002f, 0x0033, 0x0035, 0x0039, 0x003c, 0x003d, 0x0041,
+	0x0045, 0x0067, 0x006b, 0x0084, 0x0088, 0x009a, 0x009c, 0x009d, 0x009e, 0x009f, 0x00ba, 0x00be,
+	0x00c0, 0x00c4, 0xc007, 0xc008, 0xc009, 0xc00a, 0xc011, 0xc012, 0xc013, 0xc014, 0xc023, 0xc024,
+	0xc027, 0xc028, 0xc02b, 0xc02c, 0xc02f, 0xc030, 0xc060, 0xc061, 0xc072, 0xc073, 0xc076, 0xc077,
+	0xc09c, 0xc09d, 0xc09e, 0xc09f, 0xc0a0, 0xc0a1, 0xc0a2, 0xc0a3, 0xc0ac, 0xc0ad, 0xc0ae, 0xc0af,
+	0xcc13, 0xcc14, 0xcca8, 0xcca9, 0x1301, 0x1302, 0x1303, 0x1304, 0x1305,
+}
+
+// parseServerHello returns the probe answer "cipher|version|alpn|extensions"
+// from the first bytes received after the ClientHello
+func parseServerHello(data []byte) string {
+	// ServerHello is expected: handshake record with server_hello message
+	if len(data) < 44 || data[0] != 0x16 || data[5] != 0x02 {
+		return emptyAnswer
+	}
+	helloLength := int(binary.BigEndian.Uint16(data[3:5]))
+	sessionIDLength := int(data[43])
+	cipher := sliceAt(data, sessionIDLength+44, sessionIDLength+46)
+	version := data[9:11]
+	extensions, ok := parseExtensions(data, sessionIDLength, helloLength)
+	// answers truncated in the middle of the extension list are treated as no answer at all
+	if !ok {
+		return emptyAnswer
+	}
+	return hex.EncodeToString(cipher) + "|" + hex.EncodeToString(version) + "|" + extensions
+}
+
+// parseExtensions returns "alpn|type-type-..." of the ServerHello extensions
+func parseExtensions(data []byte, sessionIDLength, helloLength int) (string, bool) {
+	offset := sessionIDLength + 47
+	if offset >= len(data) || data[offset] == 0x0b ||
+		string(sliceAt(data, offset+3, offset+6)) == "\x0e\xac\x0b" ||
+		string(sliceAt(data, 82, 85)) == "\x0f\xf0\x0b" ||
+		sessionIDLength+42 >= helloLength {
+		return "|", true
+	}
+	count := offset + 2
+	length, ok := readLength(sliceAt(data, offset, offset+2))
+	if !ok {
+		return "", false
+	}
+	maximum := length + count - 1
+	var types []string
+	var alpn string
+	alpnFound := false
+	for count < maximum {
+		extType := hex.EncodeToString(sliceAt(data, count, count+2))
+		extLength, ok := readLength(sliceAt(data, count+2, count+4))
+		if !ok {
+			return "", false
+		}
+		// the first ALPN extension contains the selected protocol after the list and string lengths
+		if extType == "0010" && !alpnFound {
+			alpnFound = true
+			value := sliceAt(data, count+4, count+4+extLength)
+			alpn = string(sliceAt(value, 3, len(value)))
+		}
+		types = append(types, extType)
+		count += extLength + 4
+	}
+	return alpn + "|" + strings.Join(types, "-"), true
+}
+
+// sliceAt returns data[from:to] truncated to the data length
+func sliceAt(data []byte, from, to int) []byte {
+	if from > len(data) {
+		from = len(data)
+	}
+	if to > len(data) {
+		to = len(data)
+	}
+	return data[from:to]
+}
+
+// readLength reads big-endian length of one or two bytes,
+// one byte remains at the end of truncated data
+func readLength(data []byte) (int, bool) {
+	switch len(data) {
+	case 0:
+		return 0, false
+	case 1:
+		return int(data[0]), true
+	default:
+		return int(binary.BigEndian.Uint16(data)), true
+	}
+}
+
+// hash computes the JARM fingerprint from answers of all probes
+func hash(answers []string) string {
+	var fuzzy, alpnsAndExtensions strings.Builder
+	empty := true
+	for _, answer := range answers {
+		if answer != emptyAnswer {
+			empty = false
+		}
+		parts := strings.SplitN(answer, "|", 4)
+		fuzzy.WriteString(cipherByte(parts[0]))
+		fuzzy.WriteString(versionByte(parts[1]))
+		alpnsAndExtensions.WriteString(parts[2])
+		alpnsAndExtensions.WriteString(parts[3])
+	}
+	if empty {
+		return emptyHash
+	}
+	sum := sha256.Sum256([]byte(alpnsAndExtensions.String()))
+	return fuzzy.String() + hex.EncodeToString(sum[:])[:32]
+}
+
+// cipherByte returns the 1-based position of the cipher in hashCiphers
+func cipherByte(cipher string) string {
+	if len(cipher) == 0 {
+		return "00"
+	}
+	count := 1
+	for _, c := range hashCiphers {
+		if cipher == fmt.Sprintf("%04x", c) {
+			break
+		}
+		count++
+	}
+	return fmt.Sprintf("%02x", count)
+}
+
+func versionByte(version string) string {
+	const options = "abcdef"
+	if len(version) < 4 || version[3] < '0' || int(version[3]-'0') >= len(options) {
+		return "0"
+	}
+	return string(options[version[3]-'0'])
+}
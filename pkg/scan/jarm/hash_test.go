@@ -0,0 +1,137 @@
+package jarm
+
+import (
+	"encoding/binary"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// newServerHello builds ServerHello record with the given session id length and extensions
+func newServerHello(sessionIDLength int, cipher uint16, extensions ...[]byte) []byte {
+	hello := []byte{0x03, 0x03}
+	hello = append(hello, make([]byte, 32)...)
+	hello = append(hello, byte(sessionIDLength))
+	hello = append(hello, make([]byte, sessionIDLength)...)
+	hello = binary.BigEndian.AppendUint16(hello, cipher)
+	// compression method
+	hello = append(hello, 0x00)
+	var ext []byte
+	for _, e := range extensions {
+		ext = append(ext, e...)
+	}
+	hello = binary.BigEndian.AppendUint16(hello, uint16(len(ext)))
+	hello = append(hello, ext...)
+
+	record := []byte{0x16, 0x03, 0x03}
+	record = binary.BigEndian.AppendUint16(record, uint16(len(hello)+4))
+	record = append(record, 0x02, 0x00)
+	record = binary.BigEndian.AppendUint16(record, uint16(len(hello)))
+	return append(record, hello...)
+}
+
+var (
+	extRenegotiation = []byte{0xff, 0x01, 0x00, 0x01, 0x00}
+	extALPNh2        = []byte{0x00, 0x10, 0x00, 0x05, 0x00, 0x03, 0x02, 'h', '2'}
+	extECPoints      = []byte{0x00, 0x0b, 0x00, 0x02, 0x01, 0x00}
+	extEmpty         = []byte{0x00, 0x17, 0x00, 0x00}
+)
+
+func TestParseServerHello(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		data     []byte
+		expected string
+	}{
+		{
+			name:     "Extensions",
+			data:     newServerHello(0, 0xc02f, extRenegotiation, extALPNh2, extECPoints),
+			expected: "c02f|0303|h2|ff01-0010-000b",
+		},
+		{
+			name:     "SessionID",
+			data:     newServerHello(32, 0x1301, extEmpty, extALPNh2),
+			expected: "1301|0303|h2|0017-0010",
+		},
+		{
+			name:     "WithoutALPN",
+			data:     newServerHello(32, 0x009c, extRenegotiation),
+			expected: "009c|0303||ff01",
+		},
+		{
+			name:     "WithoutExtensions",
+			data:     newServerHello(0, 0xc02f)[:48],
+			expected: "c02f|0303||",
+		},
+		{
+			name:     "Alert",
+			data:     []byte{0x15, 0x03, 0x03, 0x00, 0x02, 0x02, 0x28},
+			expected: emptyAnswer,
+		},
+		{
+			name:     "NoData",
+			expected: emptyAnswer,
+		},
+		{
+			name:     "NotServerHello",
+			data:     append([]byte{0x16, 0x03, 0x03, 0x00, 0x40, 0x0b}, make([]byte, 64)...),
+			expected: emptyAnswer,
+		},
+		{
+			name: "TruncatedExtensions",
+			data: func() []byte {
+				data := newServerHello(0, 0xc02f, extRenegotiation, extALPNh2, extECPoints)
+				return data[:len(data)-len(extECPoints)-len(extALPNh2)+1]
+			}(),
+			expected: emptyAnswer,
+		},
+	}
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			require.Equal(t, tt.expected, parseServerHello(tt.data))
+		})
+	}
+}
+
+func TestCipherByte(t *testing.T) {
+	t.Parallel()
+	require.Equal(t, "00", cipherByte(""))
+	require.Equal(t, "01", cipherByte("0004"))
+	require.Equal(t, "29", cipherByte("c02f"))
+	require.Equal(t, "45", cipherByte("1305"))
+	require.Equal(t, "46", cipherByte("ffff"))
+}
+
+func TestVersionByte(t *testing.T) {
+	t.Parallel()
+	require.Equal(t, "0", versionByte(""))
+	require.Equal(t, "b", versionByte("0301"))
+	require.Equal(t, "d", versionByte("0303"))
+	require.Equal(t, "e", versionByte("0304"))
+	require.Equal(t, "0", versionByte("0309"))
+}
+
+func TestHash(t *testing.T) {
+	t.Parallel()
+
+	answers := make([]string, len(probes))
+	for i := range answers {
+		answers[i] = emptyAnswer
+	}
+	require.Equal(t, emptyHash, hash(answers))
+	require.Len(t, emptyHash, 62)
+
+	answers[0] = "c02f|0303|h2|ff01-0010-000b"
+	answers[6] = "1301|0303|h2|0017-0010"
+	fingerprint := hash(answers)
+	require.Len(t, fingerprint, 62)
+	require.Equal(t, "29d"+strings.Repeat("000", 5)+"41d"+strings.Repeat("000", 3), fingerprint[:30])
+	require.NotEqual(t, strings.Repeat("0", 32), fingerprint[30:])
+
+	// the same answers produce the same hash
+	require.Equal(t, fingerprint, hash(answers))
+}
@@ -0,0 +1,171 @@
+package jarm
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+const (
+	ScanType = "jarm"
+
+	defaultDialTimeout = 2 * time.Second
+	defaultDataTimeout = 2 * time.Second
+	// maxAnswerSize is the number of bytes read after the ClientHello as in the reference implementation
+	maxAnswerSize = 1484
+)
+
+type ScanResult struct {
+	ScanType string `json:"scan"`
+	IP       string `json:"ip"`
+	Port     uint16 `json:"port"`
+	JARM     string `json:"jarm"`
+}
+
+func (r *ScanResult) String() string {
+	return fmt.Sprintf("%-20s %-5d %s", r.IP, r.Port, r.JARM)
+}
+
+func (r *ScanResult) ID() string {
+	return fmt.Sprintf("%s:%d", r.IP, r.Port)
+}
+
+func (r *ScanResult) MarshalJSON() ([]byte, error) {
+	// Type definition for the recursive call
+	type JScanResult ScanResult
+	// This works because JScanResult doesn't have a MarshalJSON function associated with it
+	return json.Marshal(JScanResult(*r))
+}
+
+type Scanner struct {
+	dialer      *net.Dialer
+	dataTimeout time.Duration
+	limiter     scan.RateLimiter
+}
+
+// Assert that jarm.Scanner conforms to the scan.Scanner interface
+var _ scan.Scanner = (*Scanner)(nil)
+
+type ScannerOption func(*Scanner)
+
+func WithDialTimeout(timeout time.Duration) ScannerOption {
+	return func(s *Scanner) {
+		s.dialer.Timeout = timeout
+	}
+}
+
+func WithDataTimeout(timeout time.Duration) ScannerOption {
+	return func(s *Scanner) {
+		s.dataTimeout = timeout
+	}
+}
+
+// WithRateLimiter limits the rate of probe connections,
+// each target takes one connection per probe
+func WithRateLimiter(limiter scan.RateLimiter) ScannerOption {
+	return func(s *Scanner) {
+		s.limiter = limiter
+	}
+}
+
+func NewScanner(opts ...ScannerOption) *Scanner {
+	s := &Scanner{
+		dialer: &net.Dialer{
+			Timeout: defaultDialTimeout,
+		},
+		dataTimeout: defaultDataTimeout,
+	}
+	for _, o := range opts {
+		o(s)
+	}
+	return s
+}
+
+// Scan sends all JARM probes to the target one by one, so that the target
+// has at most one probe connection at a time. Targets that don't answer
+// any probe with ServerHello are not reported.
+func (s *Scanner) Scan(ctx context.Context, r *scan.Request) (scan.Result, error) {
+	result, err := s.scanTarget(ctx, r)
+	return result, scan.WrapError(err)
+}
+
+func (s *Scanner) scanTarget(ctx context.Context, r *scan.Request) (result scan.Result, err error) {
+	host := r.DstIP.String()
+	addr := net.JoinHostPort(host, strconv.Itoa(int(r.DstPort)))
+	answers := make([]string, len(probes))
+	for i, p := range probes {
+		var data []byte
+		if data, err = s.sendProbe(ctx, addr, p.clientHello(host)); err != nil {
+			// the port is closed or the target stopped answering at all
+			if i == 0 || ctx.Err() != nil || isTimeout(err) {
+				return nil, err
+			}
+			// refused or reset probe connection is a valid probe answer
+			data, err = nil, nil
+		}
+		answers[i] = parseServerHello(data)
+	}
+	fingerprint := hash(answers)
+	if fingerprint == emptyHash {
+		return
+	}
+	return &ScanResult{
+		ScanType: ScanType,
+		IP:       host,
+		Port:     r.DstPort,
+		JARM:     fingerprint,
+	}, nil
+}
+
+func (s *Scanner) sendProbe(ctx context.Context, addr string, hello []byte) (data []byte, err error) {
+	if s.limiter != nil {
+		s.limiter.Take()
+	}
+	var conn net.Conn
+	if conn, err = s.dialer.DialContext(ctx, "tcp", addr); err != nil {
+		return
+	}
+	defer conn.Close()
+	if err = conn.SetDeadline(time.Now().Add(s.dataTimeout)); err != nil {
+		return
+	}
+	if _, err = conn.Write(hello); err != nil {
+		return
+	}
+	return readAnswer(conn)
+}
+
+// readAnswer reads the first TLS record up to maxAnswerSize bytes,
+// the connection closed by the server is a valid answer as well
+func readAnswer(r io.Reader) ([]byte, error) {
+	buf := make([]byte, maxAnswerSize)
+	n, err := io.ReadAtLeast(r, buf, 5)
+	if err == nil && buf[0] == 0x16 {
+		size := 5 + int(binary.BigEndian.Uint16(buf[3:5]))
+		if size > len(buf) {
+			size = len(buf)
+		}
+		if n < size {
+			var m int
+			m, err = io.ReadFull(r, buf[n:size])
+			n += m
+		}
+	}
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		err = nil
+	}
+	return buf[:n], err
+}
+
+func isTimeout(err error) bool {
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
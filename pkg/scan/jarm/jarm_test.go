@@ -0,0 +1,110 @@
+package jarm
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+func newRequest(addr net.Addr) *scan.Request {
+	tcpAddr := addr.(*net.TCPAddr)
+	return &scan.Request{DstIP: tcpAddr.IP, DstPort: uint16(tcpAddr.Port)}
+}
+
+type countLimiter struct {
+	count int32
+}
+
+func (l *countLimiter) Take() time.Time {
+	atomic.AddInt32(&l.count, 1)
+	return time.Now()
+}
+
+func TestScanTLSServer(t *testing.T) {
+	t.Parallel()
+	srv := httptest.NewTLSServer(http.NotFoundHandler())
+	defer srv.Close()
+	req := newRequest(srv.Listener.Addr())
+
+	limiter := &countLimiter{}
+	s := NewScanner(WithDialTimeout(time.Second), WithDataTimeout(time.Second), WithRateLimiter(limiter))
+	result, err := s.Scan(context.Background(), req)
+	require.NoError(t, err)
+	require.IsType(t, &ScanResult{}, result)
+	jarmResult := result.(*ScanResult)
+	require.Equal(t, ScanType, jarmResult.ScanType)
+	require.Equal(t, req.DstIP.String(), jarmResult.IP)
+	require.Equal(t, req.DstPort, jarmResult.Port)
+	require.Len(t, jarmResult.JARM, 62)
+	require.NotEqual(t, emptyHash, jarmResult.JARM)
+	// one connection per probe
+	require.Equal(t, int32(len(probes)), atomic.LoadInt32(&limiter.count))
+
+	// random fields of ClientHello don't change the fingerprint
+	result2, err := s.Scan(context.Background(), req)
+	require.NoError(t, err)
+	require.Equal(t, jarmResult.JARM, result2.(*ScanResult).JARM)
+}
+
+func TestScanNotTLSServer(t *testing.T) {
+	t.Parallel()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			_, _ = conn.Write([]byte("SSH-2.0-OpenSSH_8.4\r\n"))
+			conn.Close()
+		}
+	}()
+
+	s := NewScanner(WithDialTimeout(time.Second), WithDataTimeout(time.Second))
+	result, err := s.Scan(context.Background(), newRequest(ln.Addr()))
+	require.NoError(t, err)
+	require.Nil(t, result)
+}
+
+func TestScanTimeout(t *testing.T) {
+	t.Parallel()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+
+	s := NewScanner(WithDialTimeout(time.Second), WithDataTimeout(50*time.Millisecond))
+	result, err := s.Scan(context.Background(), newRequest(ln.Addr()))
+	require.Error(t, err)
+	require.Nil(t, result)
+}
+
+func TestScanClosedPort(t *testing.T) {
+	t.Parallel()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := ln.Addr()
+	ln.Close()
+
+	s := NewScanner(WithDialTimeout(time.Second), WithDataTimeout(time.Second))
+	result, err := s.Scan(context.Background(), newRequest(addr))
+	require.Error(t, err)
+	require.Nil(t, result)
+}
@@ -0,0 +1,273 @@
+package jarm
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"math/big"
+)
+
+// Probes, ciphers and extensions follow the reference implementation
+// https://github.com/salesforce/jarm so that hashes are comparable
+
+const (
+	versionTLS11 = 0x0302
+	versionTLS12 = 0x0303
+	versionTLS13 = 0x0304
+)
+
+type order int
+
+const (
+	forward order = iota
+	reverse
+	topHalf
+	bottomHalf
+	middleOut
+)
+
+type support int
+
+const (
+	noSupport support = iota
+	tls12Support
+	tls13Support
+)
+
+type probe struct {
+	version        uint16
+	noTLS13Ciphers bool
+	cipherOrder    order
+	grease         bool
+	rareALPN       bool
+	support        support
+	extensionOrder order
+}
+
+// probes are sent to every target in this order
+var probes = []*probe{
+	{version: versionTLS12, cipherOrder: forward, support: tls12Support, extensionOrder: reverse},
+	{version: versionTLS12, cipherOrder: reverse, support: tls12Support, extensionOrder: forward},
+	{version: versionTLS12, cipherOrder: topHalf, extensionOrder: forward},
+	{version: versionTLS12, cipherOrder: bottomHalf, rareALPN: true, extensionOrder: forward},
+	{version: versionTLS12, cipherOrder: middleOut, grease: true, rareALPN: true, extensionOrder: reverse},
+	{version: versionTLS11, cipherOrder: forward, extensionOrder: forward},
+	{version: versionTLS13, cipherOrder: forward, support: tls13Support, extensionOrder: reverse},
+	{version: versionTLS13, cipherOrder: reverse, support: tls13Support, extensionOrder: forward},
+	{version: versionTLS13, noTLS13Ciphers: true, cipherOrder: forward, support: tls13Support, extensionOrder: forward},
+	{version: versionTLS13, cipherOrder: middleOut, grease: true, support: tls13Support, extensionOrder: reverse},
+}
+
+var allCiphers = []uint16{
+	0x0016, 0x0033, 0x0067, 0xc09e, 0xc0a2, 0x009e, 0x0039, 0x006b, 0xc09f, 0xc0a3, 0x009f, 0x0045,
+	0x00be, 0x0088, 0x00c4, 0x009a, 0xc008, 0xc009, 0xc023, 0xc0ac, 0xc0ae, 0xc02b, 0xc00a, 0xc024,
+	0xc0ad, 0xc0af, 0xc02c, 0xc072, 0xc073, 0xcca9, 0x1302, 0x1301, 0xcc14, 0xc007, 0xc012, 0xc013,
+	0xc027, 0xc02f, 0xc014, 0xc028, 0xc030, 0xc060, 0xc061, 0xc076, 0xc077, 0xcca8, 0x1305, 0x1304,
+	0x1303, 0xcc13, 0xc011, 0x000a, 0x002f, 0x003c, 0xc09c, 0xc0a0, 0x009c, 0x0035, 0x003d, 0xc09d,
+	0xc0a1, 0x009d, 0x0041, 0x00ba, 0x0084, 0x00c0, 0x0007, 0x0004, 0x0005,
+}
+
+var alpns = []string{"http/0.9", "http/1.0", "http/1.1", "spdy/1", "spdy/2", "spdy/3", "h2", "h2c", "hq"}
+
+// rareALPNs don't contain h2 and http/1.1
+var rareALPNs = []string{"http/0.9", "http/1.0", "spdy/1", "spdy/2", "spdy/3", "h2c", "hq"}
+
+var greaseValues = []uint16{
+	0x0a0a, 0x1a1a, 0x2a2a, 0x3a3a, 0x4a4a, 0x5a5a, 0x6a6a, 0x7a7a,
+	0x8a8a, 0x9a9a, 0xaaaa, 0xbaba, 0xcaca, 0xdada, 0xeaea, 0xfafa,
+}
+
+// static extensions sent between server_name and ALPN extensions
+var (
+	extExtendedMasterSecret = []byte{0x00, 0x17, 0x00, 0x00}
+	extMaxFragmentLength    = []byte{0x00, 0x01, 0x00, 0x01, 0x01}
+	extRenegotiationInfo    = []byte{0xff, 0x01, 0x00, 0x01, 0x00}
+	extSupportedGroups      = []byte{0x00, 0x0a, 0x00, 0x0a, 0x00, 0x08, 0x00, 0x1d, 0x00, 0x17, 0x00, 0x18, 0x00, 0x19}
+	extECPointFormats       = []byte{0x00, 0x0b, 0x00, 0x02, 0x01, 0x00}
+	extSessionTicket        = []byte{0x00, 0x23, 0x00, 0x00}
+	extSignatureAlgorithms  = []byte{0x00, 0x0d, 0x00, 0x14, 0x00, 0x12, 0x04, 0x03, 0x08, 0x04, 0x04,
+		0x01, 0x05, 0x03, 0x08, 0x05, 0x05, 0x01, 0x08, 0x06, 0x06, 0x01, 0x02, 0x01}
+	extPSKKeyExchangeModes = []byte{0x00, 0x2d, 0x00, 0x02, 0x01, 0x01}
+)
+
+// clientHello builds the TLS record with the ClientHello message of the probe
+func (p *probe) clientHello(host string) []byte {
+	recordVersion, helloVersion := p.version, p.version
+	if p.version == versionTLS13 {
+		recordVersion, helloVersion = 0x0301, versionTLS12
+	}
+
+	hello := binary.BigEndian.AppendUint16(nil, helloVersion)
+	// random
+	hello = append(hello, randomBytes(32)...)
+	// session id
+	hello = append(hello, 32)
+	hello = append(hello, randomBytes(32)...)
+
+	ciphers := p.ciphers()
+	hello = binary.BigEndian.AppendUint16(hello, uint16(2*len(ciphers)))
+	for _, c := range ciphers {
+		hello = binary.BigEndian.AppendUint16(hello, c)
+	}
+	// compression methods: null
+	hello = append(hello, 0x01, 0x00)
+	hello = append(hello, p.extensions(host)...)
+
+	record := []byte{0x16}
+	record = binary.BigEndian.AppendUint16(record, recordVersion)
+	record = binary.BigEndian.AppendUint16(record, uint16(len(hello)+4))
+	// handshake type: client_hello, 3 bytes length
+	record = append(record, 0x01, 0x00)
+	record = binary.BigEndian.AppendUint16(record, uint16(len(hello)))
+	return append(record, hello...)
+}
+
+func (p *probe) ciphers() []uint16 {
+	ciphers := allCiphers
+	if p.noTLS13Ciphers {
+		ciphers = make([]uint16, 0, len(allCiphers))
+		for _, c := range allCiphers {
+			if c>>8 != 0x13 {
+				ciphers = append(ciphers, c)
+			}
+		}
+	}
+	ciphers = reorder(ciphers, p.cipherOrder)
+	if p.grease {
+		ciphers = append([]uint16{randomGrease()}, ciphers...)
+	}
+	return ciphers
+}
+
+func (p *probe) extensions(host string) []byte {
+	var ext []byte
+	if p.grease {
+		ext = binary.BigEndian.AppendUint16(ext, randomGrease())
+		ext = append(ext, 0x00, 0x00)
+	}
+	ext = append(ext, serverNameExtension(host)...)
+	ext = append(ext, extExtendedMasterSecret...)
+	ext = append(ext, extMaxFragmentLength...)
+	ext = append(ext, extRenegotiationInfo...)
+	ext = append(ext, extSupportedGroups...)
+	ext = append(ext, extECPointFormats...)
+	ext = append(ext, extSessionTicket...)
+	ext = append(ext, p.alpnExtension()...)
+	ext = append(ext, extSignatureAlgorithms...)
+	ext = append(ext, p.keyShareExtension()...)
+	ext = append(ext, extPSKKeyExchangeModes...)
+	if p.version == versionTLS13 || p.support == tls12Support {
+		ext = append(ext, p.supportedVersionsExtension()...)
+	}
+	return append(binary.BigEndian.AppendUint16(nil, uint16(len(ext))), ext...)
+}
+
+func serverNameExtension(host string) []byte {
+	ext := []byte{0x00, 0x00}
+	ext = binary.BigEndian.AppendUint16(ext, uint16(len(host)+5))
+	ext = binary.BigEndian.AppendUint16(ext, uint16(len(host)+3))
+	// name type: host_name
+	ext = append(ext, 0x00)
+	ext = binary.BigEndian.AppendUint16(ext, uint16(len(host)))
+	return append(ext, host...)
+}
+
+func (p *probe) alpnExtension() []byte {
+	names := alpns
+	if p.rareALPN {
+		names = rareALPNs
+	}
+	names = reorder(names, p.extensionOrder)
+	var list []byte
+	for _, name := range names {
+		list = append(list, byte(len(name)))
+		list = append(list, name...)
+	}
+	ext := []byte{0x00, 0x10}
+	ext = binary.BigEndian.AppendUint16(ext, uint16(len(list)+2))
+	ext = binary.BigEndian.AppendUint16(ext, uint16(len(list)))
+	return append(ext, list...)
+}
+
+func (p *probe) keyShareExtension() []byte {
+	var share []byte
+	if p.grease {
+		share = binary.BigEndian.AppendUint16(share, randomGrease())
+		share = append(share, 0x00, 0x01, 0x00)
+	}
+	// x25519 group with 32 bytes key
+	share = append(share, 0x00, 0x1d, 0x00, 0x20)
+	share = append(share, randomBytes(32)...)
+	ext := []byte{0x00, 0x33}
+	ext = binary.BigEndian.AppendUint16(ext, uint16(len(share)+2))
+	ext = binary.BigEndian.AppendUint16(ext, uint16(len(share)))
+	return append(ext, share...)
+}
+
+func (p *probe) supportedVersionsExtension() []byte {
+	versions := []uint16{0x0301, versionTLS11, versionTLS12, versionTLS13}
+	if p.support == tls12Support {
+		versions = versions[:3]
+	}
+	versions = reorder(versions, p.extensionOrder)
+	if p.grease {
+		versions = append([]uint16{randomGrease()}, versions...)
+	}
+	ext := []byte{0x00, 0x2b}
+	ext = binary.BigEndian.AppendUint16(ext, uint16(2*len(versions)+1))
+	ext = append(ext, byte(2*len(versions)))
+	for _, v := range versions {
+		ext = binary.BigEndian.AppendUint16(ext, v)
+	}
+	return ext
+}
+
+// reorder returns a new slice of elements in the given order
+func reorder[T any](items []T, o order) []T {
+	n := len(items)
+	result := make([]T, 0, n)
+	switch o {
+	case reverse:
+		for i := n - 1; i >= 0; i-- {
+			result = append(result, items[i])
+		}
+	case bottomHalf:
+		result = append(result, items[n/2+n%2:]...)
+	case topHalf:
+		// the top half gets the middle element
+		if n%2 == 1 {
+			result = append(result, items[n/2])
+		}
+		result = append(result, reorder(reorder(items, reverse), bottomHalf)...)
+	case middleOut:
+		middle := n / 2
+		if n%2 == 1 {
+			// start with the center, second half before the first half
+			result = append(result, items[middle])
+			for i := 1; i <= middle; i++ {
+				result = append(result, items[middle+i], items[middle-i])
+			}
+		} else {
+			for i := 1; i <= middle; i++ {
+				result = append(result, items[middle-1+i], items[middle-i])
+			}
+		}
+	default:
+		result = append(result, items...)
+	}
+	return result
+}
+
+func randomBytes(n int) []byte {
+	buf := make([]byte, n)
+	// crypto/rand doesn't fail on supported platforms
+	_, _ = rand.Read(buf)
+	return buf
+}
+
+func randomGrease() uint16 {
+	i, err := rand.Int(rand.Reader, big.NewInt(int64(len(greaseValues))))
+	if err != nil {
+		return greaseValues[0]
+	}
+	return greaseValues[i.Int64()]
+}
@@ -0,0 +1,113 @@
+package jarm
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReorder(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		items    []int
+		order    order
+		expected []int
+	}{
+		{name: "Forward", items: []int{1, 2, 3, 4, 5}, order: forward, expected: []int{1, 2, 3, 4, 5}},
+		{name: "Reverse", items: []int{1, 2, 3, 4, 5}, order: reverse, expected: []int{5, 4, 3, 2, 1}},
+		{name: "BottomHalfOdd", items: []int{1, 2, 3, 4, 5}, order: bottomHalf, expected: []int{4, 5}},
+		{name: "BottomHalfEven", items: []int{1, 2, 3, 4}, order: bottomHalf, expected: []int{3, 4}},
+		{name: "TopHalfOdd", items: []int{1, 2, 3, 4, 5}, order: topHalf, expected: []int{3, 2, 1}},
+		{name: "TopHalfEven", items: []int{1, 2, 3, 4}, order: topHalf, expected: []int{2, 1}},
+		{name: "MiddleOutOdd", items: []int{1, 2, 3, 4, 5}, order: middleOut, expected: []int{3, 4, 2, 5, 1}},
+		{name: "MiddleOutEven", items: []int{1, 2, 3, 4}, order: middleOut, expected: []int{3, 2, 4, 1}},
+	}
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			require.Equal(t, tt.expected, reorder(tt.items, tt.order))
+		})
+	}
+}
+
+func TestProbeCiphers(t *testing.T) {
+	t.Parallel()
+
+	p := &probe{cipherOrder: forward}
+	require.Equal(t, allCiphers, p.ciphers())
+
+	p = &probe{cipherOrder: forward, noTLS13Ciphers: true}
+	ciphers := p.ciphers()
+	require.Len(t, ciphers, len(allCiphers)-5)
+	for _, c := range ciphers {
+		require.NotEqual(t, uint16(0x13), c>>8)
+	}
+
+	p = &probe{cipherOrder: reverse, grease: true}
+	ciphers = p.ciphers()
+	require.Len(t, ciphers, len(allCiphers)+1)
+	require.Contains(t, greaseValues, ciphers[0])
+	require.Equal(t, allCiphers[len(allCiphers)-1], ciphers[1])
+}
+
+func TestProbeClientHelloLengths(t *testing.T) {
+	t.Parallel()
+	for i, p := range probes {
+		hello := p.clientHello("10.0.0.1")
+		require.Equal(t, byte(0x16), hello[0], i)
+		require.Equal(t, len(hello)-5, int(binary.BigEndian.Uint16(hello[3:5])), i)
+		require.Equal(t, byte(0x01), hello[5], i)
+		require.Equal(t, len(hello)-9, int(binary.BigEndian.Uint16(hello[7:9])), i)
+
+		recordVersion := binary.BigEndian.Uint16(hello[1:3])
+		helloVersion := binary.BigEndian.Uint16(hello[9:11])
+		if p.version == versionTLS13 {
+			require.Equal(t, uint16(0x0301), recordVersion, i)
+			require.Equal(t, uint16(versionTLS12), helloVersion, i)
+		} else {
+			require.Equal(t, p.version, recordVersion, i)
+			require.Equal(t, p.version, helloVersion, i)
+		}
+
+		// random and session id
+		offset := 11 + 32
+		require.Equal(t, byte(32), hello[offset], i)
+		offset += 33
+		ciphersLength := int(binary.BigEndian.Uint16(hello[offset:]))
+		require.Equal(t, 2*len(p.ciphers()), ciphersLength, i)
+		offset += 2 + ciphersLength
+		require.Equal(t, []byte{0x01, 0x00}, hello[offset:offset+2], i)
+		offset += 2
+		require.Equal(t, len(hello)-offset-2, int(binary.BigEndian.Uint16(hello[offset:])), i)
+	}
+}
+
+func TestServerNameExtension(t *testing.T) {
+	t.Parallel()
+	require.Equal(t, []byte{0x00, 0x00, 0x00, 0x06, 0x00, 0x04, 0x00, 0x00, 0x01, 'a'}, serverNameExtension("a"))
+}
+
+func TestALPNExtension(t *testing.T) {
+	t.Parallel()
+	p := &probe{rareALPN: true, extensionOrder: forward}
+	ext := p.alpnExtension()
+	require.Equal(t, []byte{0x00, 0x10}, ext[:2])
+	require.Equal(t, len(ext)-4, int(binary.BigEndian.Uint16(ext[2:4])))
+	require.Equal(t, len(ext)-6, int(binary.BigEndian.Uint16(ext[4:6])))
+	require.Equal(t, append([]byte{8}, "http/0.9"...), ext[6:15])
+	require.NotContains(t, string(ext), "http/1.1")
+}
+
+func TestSupportedVersionsExtension(t *testing.T) {
+	t.Parallel()
+	p := &probe{support: tls12Support, extensionOrder: reverse}
+	require.Equal(t, []byte{0x00, 0x2b, 0x00, 0x07, 0x06, 0x03, 0x03, 0x03, 0x02, 0x03, 0x01},
+		p.supportedVersionsExtension())
+
+	p = &probe{support: tls13Support, extensionOrder: forward}
+	require.Equal(t, []byte{0x00, 0x2b, 0x00, 0x09, 0x08, 0x03, 0x01, 0x03, 0x02, 0x03, 0x03, 0x03, 0x04},
+		p.supportedVersionsExtension())
+}
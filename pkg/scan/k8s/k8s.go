@@ -0,0 +1,297 @@
+package k8s
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+const (
+	ScanType = "k8s"
+
+	// ComponentAPIServer is the Kubernetes API server, usually on ports 6443 or 8443
+	ComponentAPIServer = "apiserver"
+	// ComponentKubelet is the node agent, 10250 is the authenticated port and 10255 is the read-only port
+	ComponentKubelet = "kubelet"
+
+	// AccessNone means requests without credentials are rejected
+	AccessNone = "none"
+	// AccessAnonymous means anonymous requests are authenticated, but resources are forbidden
+	AccessAnonymous = "anonymous"
+	// AccessRead means namespaces of the API server or pods of the kubelet are readable without credentials
+	AccessRead = "read"
+
+	defaultDataTimeout = 5 * time.Second
+
+	// maxResponseSize limits the size of read responses, pod lists of busy nodes fit in it
+	maxResponseSize = 16 << 20
+)
+
+var (
+	errNotFound = errors.New("k8s endpoint not found")
+	errNotK8s   = errors.New("not a Kubernetes component")
+	errStatus   = errors.New("unexpected HTTP status")
+)
+
+type ScanResult struct {
+	ScanType  string `json:"scan"`
+	Proto     string `json:"proto"`
+	Host      string `json:"host"`
+	Component string `json:"component"`
+	Access    string `json:"access"`
+	// Version is the git version of the API server, e.g. v1.21.2
+	Version  string `json:"version,omitempty"`
+	Platform string `json:"platform,omitempty"`
+	// Namespaces is the number of namespaces of the API server readable without credentials
+	Namespaces int `json:"namespaces,omitempty"`
+	// Pods is the number of pods of the kubelet readable without credentials
+	Pods int `json:"pods,omitempty"`
+}
+
+func (r *ScanResult) String() string {
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "%s://%s %s", r.Proto, r.Host, r.Component)
+	if len(r.Version) > 0 {
+		fmt.Fprintf(&buf, " %s", r.Version)
+	}
+	fmt.Fprintf(&buf, " access:%s", r.Access)
+	if r.Access == AccessRead {
+		switch r.Component {
+		case ComponentAPIServer:
+			fmt.Fprintf(&buf, " namespaces:%d", r.Namespaces)
+		case ComponentKubelet:
+			fmt.Fprintf(&buf, " pods:%d", r.Pods)
+		}
+	}
+	return buf.String()
+}
+
+func (r *ScanResult) ID() string {
+	return r.Host
+}
+
+func (r *ScanResult) MarshalJSON() ([]byte, error) {
+	// Type definition for the recursive call
+	type JScanResult ScanResult
+	// This works because JScanResult doesn't have a MarshalJSON function associated with it
+	return json.Marshal(JScanResult(*r))
+}
+
+type Scanner struct {
+	client      *http.Client
+	apiServer   bool
+	kubelet     bool
+	dataTimeout time.Duration
+}
+
+// Assert that k8s.Scanner conforms to the scan.Scanner interface
+var _ scan.Scanner = (*Scanner)(nil)
+
+type ScannerOption func(*Scanner)
+
+func WithDataTimeout(timeout time.Duration) ScannerOption {
+	return func(s *Scanner) {
+		s.dataTimeout = timeout
+	}
+}
+
+// WithComponents sets the components to probe, both the API server and the kubelet are probed by default
+func WithComponents(components ...string) ScannerOption {
+	return func(s *Scanner) {
+		s.apiServer, s.kubelet = false, false
+		for _, c := range components {
+			switch c {
+			case ComponentAPIServer:
+				s.apiServer = true
+			case ComponentKubelet:
+				s.kubelet = true
+			}
+		}
+	}
+}
+
+func NewScanner(opts ...ScannerOption) *Scanner {
+	tr := &http.Transport{
+		MaxConnsPerHost:   1,
+		DisableKeepAlives: true,
+		TLSClientConfig: &tls.Config{
+			InsecureSkipVerify: true,
+		},
+	}
+	s := &Scanner{
+		client:      &http.Client{Transport: tr},
+		apiServer:   true,
+		kubelet:     true,
+		dataTimeout: defaultDataTimeout,
+	}
+	for _, o := range opts {
+		o(s)
+	}
+	return s
+}
+
+// Scan probes the components without credentials, the component of the well-known port is probed first,
+// the other one is probed only if endpoints of the first one are not found
+func (s *Scanner) Scan(ctx context.Context, r *scan.Request) (scan.Result, error) {
+	result, err := s.scanTarget(ctx, r)
+	return result, scan.WrapError(err)
+}
+
+func (s *Scanner) scanTarget(ctx context.Context, r *scan.Request) (result scan.Result, err error) {
+	host := fmt.Sprintf("%s:%d", r.DstIP.String(), r.DstPort)
+	c := &client{Scanner: s, host: host, proto: "https"}
+	for _, component := range s.components(r.DstPort) {
+		switch component {
+		case ComponentAPIServer:
+			result, err = c.probeAPIServer(ctx)
+		case ComponentKubelet:
+			result, err = c.probeKubelet(ctx)
+		}
+		if !errors.Is(err, errNotFound) {
+			return
+		}
+	}
+	return nil, err
+}
+
+func (s *Scanner) components(port uint16) []string {
+	order := []string{ComponentAPIServer, ComponentKubelet}
+	if port == 10250 || port == 10255 {
+		order = []string{ComponentKubelet, ComponentAPIServer}
+	}
+	result := make([]string, 0, len(order))
+	for _, c := range order {
+		if (c == ComponentAPIServer && s.apiServer) || (c == ComponentKubelet && s.kubelet) {
+			result = append(result, c)
+		}
+	}
+	return result
+}
+
+// client sends requests of a single scan, proto is switched to http if the port doesn't speak TLS
+type client struct {
+	*Scanner
+	host  string
+	proto string
+}
+
+// status is the Kubernetes error response
+type status struct {
+	Kind string `json:"kind"`
+}
+
+func (c *client) probeAPIServer(ctx context.Context) (*ScanResult, error) {
+	code, body, err := c.get(ctx, "/version")
+	if err != nil {
+		return nil, err
+	}
+	result := &ScanResult{ScanType: ScanType, Host: c.host, Component: ComponentAPIServer}
+	switch code {
+	case http.StatusOK:
+		var version struct {
+			GitVersion string `json:"gitVersion"`
+			Platform   string `json:"platform"`
+		}
+		if err = json.Unmarshal(body, &version); err != nil || len(version.GitVersion) == 0 {
+			return nil, errNotK8s
+		}
+		result.Version, result.Platform = version.GitVersion, version.Platform
+		result.Access = AccessAnonymous
+	default:
+		// only the API server responds with Status objects
+		var st status
+		if err = json.Unmarshal(body, &st); err != nil || st.Kind != "Status" {
+			return nil, errNotK8s
+		}
+		result.Access = accessLevel(code)
+	}
+	result.Proto = c.proto
+	if result.Access == AccessNone {
+		return result, nil
+	}
+
+	if code, body, err = c.get(ctx, "/api/v1/namespaces"); err != nil || code != http.StatusOK {
+		return result, nil
+	}
+	var namespaces struct {
+		Items []json.RawMessage `json:"items"`
+	}
+	if err = json.Unmarshal(body, &namespaces); err == nil {
+		result.Access = AccessRead
+		result.Namespaces = len(namespaces.Items)
+	}
+	return result, nil
+}
+
+func (c *client) probeKubelet(ctx context.Context) (*ScanResult, error) {
+	code, body, err := c.get(ctx, "/pods")
+	if err != nil {
+		return nil, err
+	}
+	result := &ScanResult{ScanType: ScanType, Proto: c.proto, Host: c.host, Component: ComponentKubelet,
+		Access: accessLevel(code)}
+	if code != http.StatusOK {
+		return result, nil
+	}
+	var pods struct {
+		Kind  string            `json:"kind"`
+		Items []json.RawMessage `json:"items"`
+	}
+	if err = json.Unmarshal(body, &pods); err != nil || pods.Kind != "PodList" {
+		return nil, errNotK8s
+	}
+	result.Access = AccessRead
+	result.Pods = len(pods.Items)
+	return result, nil
+}
+
+func accessLevel(code int) string {
+	if code == http.StatusForbidden {
+		return AccessAnonymous
+	}
+	return AccessNone
+}
+
+// get returns the status code and the body of responses with 200, 401 and 403 statuses,
+// the first request switches to http if the server responds with plain HTTP to the TLS handshake
+func (c *client) get(ctx context.Context, path string) (code int, body []byte, err error) {
+	code, body, err = c.do(ctx, path)
+	if err != nil && c.proto == "https" && strings.Contains(err.Error(), "server gave HTTP response to HTTPS client") {
+		c.proto = "http"
+		code, body, err = c.do(ctx, path)
+	}
+	return
+}
+
+func (c *client) do(ctx context.Context, path string) (code int, body []byte, err error) {
+	ctx, cancel := context.WithTimeout(ctx, c.dataTimeout)
+	defer cancel()
+	var req *http.Request
+	if req, err = http.NewRequestWithContext(ctx, http.MethodGet,
+		fmt.Sprintf("%s://%s%s", c.proto, c.host, path), nil); err != nil {
+		return
+	}
+	var resp *http.Response
+	if resp, err = c.client.Do(req); err != nil {
+		return
+	}
+	defer resp.Body.Close()
+	code = resp.StatusCode
+	switch code {
+	case http.StatusOK, http.StatusUnauthorized, http.StatusForbidden:
+	case http.StatusNotFound:
+		return code, nil, errNotFound
+	default:
+		return code, nil, fmt.Errorf("%w: %s", errStatus, resp.Status)
+	}
+	body, err = io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+	return
+}
@@ -0,0 +1,169 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+const unauthorizedStatus = `{"kind":"Status","apiVersion":"v1","status":"Failure","message":"Unauthorized","code":401}`
+
+type fakeAPIServer struct {
+	// code is the status of responses to anonymous requests of resources
+	code int
+}
+
+func (s *fakeAPIServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	switch {
+	case s.code == http.StatusUnauthorized:
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Fprint(w, unauthorizedStatus)
+	case r.URL.Path == "/version":
+		fmt.Fprint(w, `{"major":"1","minor":"21","gitVersion":"v1.21.2","platform":"linux/amd64"}`)
+	case r.URL.Path == "/api/v1/namespaces" && s.code == http.StatusOK:
+		fmt.Fprint(w, `{"kind":"NamespaceList","apiVersion":"v1","items":[{"metadata":{"name":"default"}},`+
+			`{"metadata":{"name":"kube-system"}}]}`)
+	case r.URL.Path == "/api/v1/namespaces":
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprint(w, `{"kind":"Status","apiVersion":"v1","status":"Failure","reason":"Forbidden","code":403}`)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+type fakeKubelet struct {
+	code int
+}
+
+func (s *fakeKubelet) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/pods" {
+		http.NotFound(w, r)
+		return
+	}
+	switch s.code {
+	case http.StatusOK:
+		fmt.Fprint(w, `{"kind":"PodList","apiVersion":"v1","metadata":{},"items":[{"metadata":{"name":"nginx"}}]}`)
+	case http.StatusUnauthorized:
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+	default:
+		http.Error(w, "Forbidden (user=system:anonymous, verb=get, resource=nodes, subresource=proxy)",
+			http.StatusForbidden)
+	}
+}
+
+func startServer(t *testing.T, h http.Handler, useTLS bool) (*scan.Request, string) {
+	t.Helper()
+	var srv *httptest.Server
+	if useTLS {
+		srv = httptest.NewTLSServer(h)
+	} else {
+		srv = httptest.NewServer(h)
+	}
+	t.Cleanup(srv.Close)
+	addr := srv.Listener.Addr().(*net.TCPAddr)
+	return &scan.Request{DstIP: addr.IP, DstPort: uint16(addr.Port)}, addr.String()
+}
+
+func TestScan(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		handler  http.Handler
+		useTLS   bool
+		opts     []ScannerOption
+		expected ScanResult
+	}{
+		{
+			name:    "APIServerAnonymous",
+			handler: &fakeAPIServer{code: http.StatusForbidden},
+			useTLS:  true,
+			expected: ScanResult{Proto: "https", Component: ComponentAPIServer, Access: AccessAnonymous,
+				Version: "v1.21.2", Platform: "linux/amd64"},
+		},
+		{
+			name:    "APIServerRead",
+			handler: &fakeAPIServer{code: http.StatusOK},
+			useTLS:  true,
+			expected: ScanResult{Proto: "https", Component: ComponentAPIServer, Access: AccessRead,
+				Version: "v1.21.2", Platform: "linux/amd64", Namespaces: 2},
+		},
+		{
+			name:     "APIServerNone",
+			handler:  &fakeAPIServer{code: http.StatusUnauthorized},
+			useTLS:   true,
+			expected: ScanResult{Proto: "https", Component: ComponentAPIServer, Access: AccessNone},
+		},
+		{
+			name:     "KubeletRead",
+			handler:  &fakeKubelet{code: http.StatusOK},
+			useTLS:   true,
+			expected: ScanResult{Proto: "https", Component: ComponentKubelet, Access: AccessRead, Pods: 1},
+		},
+		{
+			name:     "KubeletReadOnlyPort",
+			handler:  &fakeKubelet{code: http.StatusOK},
+			expected: ScanResult{Proto: "http", Component: ComponentKubelet, Access: AccessRead, Pods: 1},
+		},
+		{
+			name:     "KubeletAnonymous",
+			handler:  &fakeKubelet{code: http.StatusForbidden},
+			useTLS:   true,
+			opts:     []ScannerOption{WithComponents(ComponentKubelet)},
+			expected: ScanResult{Proto: "https", Component: ComponentKubelet, Access: AccessAnonymous},
+		},
+		{
+			name:     "KubeletNone",
+			handler:  &fakeKubelet{code: http.StatusUnauthorized},
+			useTLS:   true,
+			expected: ScanResult{Proto: "https", Component: ComponentKubelet, Access: AccessNone},
+		},
+	}
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			req, host := startServer(t, tt.handler, tt.useTLS)
+			result, err := NewScanner(tt.opts...).Scan(context.Background(), req)
+			require.NoError(t, err)
+
+			expected := tt.expected
+			expected.ScanType = ScanType
+			expected.Host = host
+			require.Equal(t, &expected, result)
+		})
+	}
+}
+
+func TestScanNotK8s(t *testing.T) {
+	t.Parallel()
+	req, _ := startServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"version":"1.0"}`)
+	}), false)
+	_, err := NewScanner().Scan(context.Background(), req)
+	require.ErrorIs(t, err, errNotK8s)
+
+	req, _ = startServer(t, http.NotFoundHandler(), false)
+	_, err = NewScanner().Scan(context.Background(), req)
+	require.ErrorIs(t, err, errNotFound)
+
+	// kubelet endpoints are not probed
+	req, _ = startServer(t, &fakeKubelet{code: http.StatusOK}, true)
+	_, err = NewScanner(WithComponents(ComponentAPIServer)).Scan(context.Background(), req)
+	require.ErrorIs(t, err, errNotFound)
+}
+
+func TestScannerComponents(t *testing.T) {
+	t.Parallel()
+	s := NewScanner()
+	require.Equal(t, []string{ComponentAPIServer, ComponentKubelet}, s.components(6443))
+	require.Equal(t, []string{ComponentKubelet, ComponentAPIServer}, s.components(10250))
+	require.Equal(t, []string{ComponentKubelet, ComponentAPIServer}, s.components(10255))
+	require.Equal(t, []string{ComponentKubelet}, NewScanner(WithComponents(ComponentKubelet)).components(6443))
+}
@@ -0,0 +1,192 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+const (
+	ScanType = "kafka"
+
+	defaultDialTimeout = 2 * time.Second
+	defaultDataTimeout = 2 * time.Second
+)
+
+// APIVersion is the range of versions of the API supported by the broker
+type APIVersion struct {
+	Key  int16  `json:"key"`
+	Name string `json:"name,omitempty"`
+	Min  int16  `json:"min"`
+	Max  int16  `json:"max"`
+}
+
+type ScanResult struct {
+	ScanType string `json:"scan"`
+	IP       string `json:"ip"`
+	Port     uint16 `json:"port"`
+	// APIVersions are version ranges of all APIs supported by the broker, they identify the Kafka release
+	APIVersions []*APIVersion `json:"api_versions"`
+	// AuthRequired is set if the broker closed the connection on the Metadata request,
+	// SASL listeners accept only ApiVersions and SASL requests before authentication
+	AuthRequired bool   `json:"auth_required"`
+	ClusterID    string `json:"cluster_id,omitempty"`
+	// Brokers are advertised addresses of cluster brokers
+	Brokers []string `json:"brokers,omitempty"`
+	// TopicsListable is set if topics are returned by the Metadata request without authentication
+	TopicsListable bool     `json:"topics_listable"`
+	Topics         []string `json:"topics,omitempty"`
+}
+
+func (r *ScanResult) String() string {
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "%-20s %-5d apis %d", r.IP, r.Port, len(r.APIVersions))
+	for _, v := range r.APIVersions {
+		if v.Key == apiKeyMetadata {
+			fmt.Fprintf(&buf, " metadata v%d-v%d", v.Min, v.Max)
+		}
+	}
+	if r.AuthRequired {
+		buf.WriteString(" auth")
+		return buf.String()
+	}
+	if len(r.ClusterID) > 0 {
+		fmt.Fprintf(&buf, " cluster %s", r.ClusterID)
+	}
+	fmt.Fprintf(&buf, " brokers %d", len(r.Brokers))
+	if r.TopicsListable {
+		fmt.Fprintf(&buf, " topics %d", len(r.Topics))
+	}
+	return buf.String()
+}
+
+func (r *ScanResult) ID() string {
+	return fmt.Sprintf("%s:%d", r.IP, r.Port)
+}
+
+func (r *ScanResult) MarshalJSON() ([]byte, error) {
+	// Type definition for the recursive call
+	type JScanResult ScanResult
+	// This works because JScanResult doesn't have a MarshalJSON function associated with it
+	return json.Marshal(JScanResult(*r))
+}
+
+// Scanner sends ApiVersions and Metadata requests to Kafka brokers
+type Scanner struct {
+	dialer      *net.Dialer
+	dataTimeout time.Duration
+}
+
+// Assert that kafka.Scanner conforms to the scan.Scanner interface
+var _ scan.Scanner = (*Scanner)(nil)
+
+type ScannerOption func(*Scanner)
+
+func WithDialTimeout(timeout time.Duration) ScannerOption {
+	return func(s *Scanner) {
+		s.dialer.Timeout = timeout
+	}
+}
+
+func WithDataTimeout(timeout time.Duration) ScannerOption {
+	return func(s *Scanner) {
+		s.dataTimeout = timeout
+	}
+}
+
+func NewScanner(opts ...ScannerOption) *Scanner {
+	s := &Scanner{
+		dialer: &net.Dialer{
+			Timeout: defaultDialTimeout,
+		},
+		dataTimeout: defaultDataTimeout,
+	}
+	for _, o := range opts {
+		o(s)
+	}
+	return s
+}
+
+func (s *Scanner) Scan(ctx context.Context, r *scan.Request) (scan.Result, error) {
+	result, err := s.scanTarget(ctx, r)
+	return result, scan.WrapError(err)
+}
+
+func (s *Scanner) scanTarget(ctx context.Context, r *scan.Request) (scan.Result, error) {
+	addr := net.JoinHostPort(r.DstIP.String(), strconv.Itoa(int(r.DstPort)))
+	conn, err := s.dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	if err = conn.SetDeadline(time.Now().Add(s.dataTimeout)); err != nil {
+		return nil, err
+	}
+
+	body, err := exchange(conn, apiVersionsRequest(1), 1)
+	if err != nil {
+		return nil, err
+	}
+	versions, err := parseApiVersions(body)
+	if err != nil {
+		return nil, err
+	}
+	result := &ScanResult{
+		ScanType:    ScanType,
+		IP:          r.DstIP.String(),
+		Port:        r.DstPort,
+		APIVersions: make([]*APIVersion, 0, len(versions)),
+	}
+	version := int16(-1)
+	for _, v := range versions {
+		result.APIVersions = append(result.APIVersions, &APIVersion{
+			Key: v.key, Name: apiKeyNames[v.key], Min: v.minVersion, Max: v.maxVersion})
+		if v.key == apiKeyMetadata && v.minVersion <= metadataVersion && v.maxVersion >= minMetadataVersion {
+			version = v.maxVersion
+			if version > metadataVersion {
+				version = metadataVersion
+			}
+		}
+	}
+	// brokers that don't support non-flexible Metadata versions are reported without metadata
+	if version < 0 {
+		return result, nil
+	}
+
+	body, err = exchange(conn, metadataRequest(version, 2), 2)
+	if errors.Is(err, io.EOF) || errors.Is(err, syscall.ECONNRESET) {
+		result.AuthRequired = true
+		return result, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	meta, err := parseMetadata(body, version)
+	if err != nil {
+		return nil, err
+	}
+	result.ClusterID = meta.clusterID
+	for _, b := range meta.brokers {
+		result.Brokers = append(result.Brokers, net.JoinHostPort(b.host, strconv.Itoa(int(b.port))))
+	}
+	result.Topics = meta.topics
+	result.TopicsListable = len(meta.topics) > 0
+	return result, nil
+}
+
+// exchange sends the request and reads the response body with the same correlation id
+func exchange(conn net.Conn, request []byte, correlationID int32) ([]byte, error) {
+	if _, err := conn.Write(request); err != nil {
+		return nil, err
+	}
+	return readResponse(conn, correlationID)
+}
@@ -0,0 +1,183 @@
+package kafka
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+type fakeServer struct {
+	metadataMax int16
+	// sasl closes the connection on requests other than ApiVersions like SASL listeners do
+	sasl   bool
+	topics []string
+}
+
+// readRequest reads the request sent by the client and returns its api key and correlation id
+func readRequest(conn net.Conn) (apiKey int16, correlationID int32, err error) {
+	size := make([]byte, 4)
+	if _, err = io.ReadFull(conn, size); err != nil {
+		return
+	}
+	data := make([]byte, binary.BigEndian.Uint32(size))
+	if _, err = io.ReadFull(conn, data); err != nil {
+		return
+	}
+	return int16(binary.BigEndian.Uint16(data)), int32(binary.BigEndian.Uint32(data[4:8])), nil
+}
+
+func (s *fakeServer) serve(conn net.Conn) {
+	defer conn.Close()
+	for {
+		apiKey, correlationID, err := readRequest(conn)
+		if err != nil {
+			return
+		}
+		var resp []byte
+		switch {
+		case apiKey == apiKeyApiVersions:
+			resp = response(correlationID, apiVersionsBody(0,
+				&apiVersion{key: apiKeyMetadata, minVersion: 0, maxVersion: s.metadataMax},
+				&apiVersion{key: apiKeyApiVersions, minVersion: 0, maxVersion: 3},
+				&apiVersion{key: 1000, minVersion: 0, maxVersion: 0}))
+		case apiKey == apiKeyMetadata && !s.sasl:
+			resp = response(correlationID, metadataBody("Xy3qT2pTRa6Fa8MvNQb7sA", s.topics...))
+		default:
+			return
+		}
+		if _, err = conn.Write(resp); err != nil {
+			return
+		}
+	}
+}
+
+func startFakeServer(t *testing.T, srv *fakeServer) *scan.Request {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { l.Close() })
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go srv.serve(conn)
+		}
+	}()
+	addr := l.Addr().(*net.TCPAddr)
+	return &scan.Request{DstIP: addr.IP, DstPort: uint16(addr.Port)}
+}
+
+func apiVersions(metadataMax int16) []*APIVersion {
+	return []*APIVersion{
+		{Key: apiKeyMetadata, Name: "Metadata", Min: 0, Max: metadataMax},
+		{Key: apiKeyApiVersions, Name: "ApiVersions", Min: 0, Max: 3},
+		{Key: 1000, Min: 0, Max: 0},
+	}
+}
+
+func TestScan(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		srv      *fakeServer
+		expected *ScanResult
+	}{
+		{
+			name: "Topics",
+			srv:  &fakeServer{metadataMax: 12, topics: []string{"orders", "events"}},
+			expected: &ScanResult{
+				APIVersions:    apiVersions(12),
+				ClusterID:      "Xy3qT2pTRa6Fa8MvNQb7sA",
+				Brokers:        []string{"kafka-1.local:9092"},
+				TopicsListable: true,
+				Topics:         []string{"orders", "events"},
+			},
+		},
+		{
+			name: "TopicsNotAuthorized",
+			srv:  &fakeServer{metadataMax: 12, topics: []string{"orders-auth"}},
+			expected: &ScanResult{
+				APIVersions: apiVersions(12),
+				ClusterID:   "Xy3qT2pTRa6Fa8MvNQb7sA",
+				Brokers:     []string{"kafka-1.local:9092"},
+			},
+		},
+		{
+			name: "SASL",
+			srv:  &fakeServer{metadataMax: 12, sasl: true},
+			expected: &ScanResult{
+				APIVersions:  apiVersions(12),
+				AuthRequired: true,
+			},
+		},
+	}
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			req := startFakeServer(t, tt.srv)
+
+			result, err := NewScanner().Scan(context.Background(), req)
+			require.NoError(t, err)
+			expected := *tt.expected
+			expected.ScanType = ScanType
+			expected.IP = req.DstIP.String()
+			expected.Port = req.DstPort
+			require.Equal(t, &expected, result)
+		})
+	}
+}
+
+func TestScanNotKafkaServer(t *testing.T) {
+	t.Parallel()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			_, _ = conn.Write([]byte("SSH-2.0-OpenSSH_8.4p1\r\n"))
+			conn.Close()
+		}
+	}()
+	addr := l.Addr().(*net.TCPAddr)
+
+	result, err := NewScanner().Scan(context.Background(), &scan.Request{DstIP: addr.IP, DstPort: uint16(addr.Port)})
+	require.Error(t, err)
+	require.Nil(t, result)
+}
+
+func TestScanTimeout(t *testing.T) {
+	t.Parallel()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+	done := make(chan interface{})
+	defer close(done)
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		<-done
+	}()
+	addr := l.Addr().(*net.TCPAddr)
+
+	s := NewScanner(WithDataTimeout(50 * time.Millisecond))
+	result, err := s.Scan(context.Background(), &scan.Request{DstIP: addr.IP, DstPort: uint16(addr.Port)})
+	require.Error(t, err)
+	require.ErrorIs(t, err, scan.ErrTimeout)
+	require.Nil(t, result)
+}
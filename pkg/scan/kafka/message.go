@@ -0,0 +1,225 @@
+package kafka
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+// Kafka protocol requests, see https://kafka.apache.org/protocol
+const (
+	apiKeyMetadata    = 3
+	apiKeyApiVersions = 18
+
+	// metadataVersion is the highest Metadata version with the non-flexible encoding that returns the cluster id
+	metadataVersion    = 2
+	minMetadataVersion = 1
+
+	maxResponseSize = 4 * 1024 * 1024
+	clientID        = "sx"
+)
+
+var errInvalidResponse = scan.NewError(scan.ErrParse, errors.New("invalid Kafka response"))
+
+// apiKeyNames are names of API keys that are reported by their names instead of numbers
+var apiKeyNames = map[int16]string{
+	0:  "Produce",
+	1:  "Fetch",
+	2:  "ListOffsets",
+	3:  "Metadata",
+	8:  "OffsetCommit",
+	9:  "OffsetFetch",
+	10: "FindCoordinator",
+	11: "JoinGroup",
+	17: "SaslHandshake",
+	18: "ApiVersions",
+	19: "CreateTopics",
+	20: "DeleteTopics",
+	32: "DescribeConfigs",
+	36: "SaslAuthenticate",
+}
+
+type apiVersion struct {
+	key        int16
+	minVersion int16
+	maxVersion int16
+}
+
+type broker struct {
+	host string
+	port int32
+}
+
+type metadata struct {
+	brokers   []*broker
+	clusterID string
+	topics    []string
+}
+
+// kafkaError is the error code of the response
+type kafkaError int16
+
+func (e kafkaError) Error() string {
+	return fmt.Sprintf("kafka error code %d", int16(e))
+}
+
+func newRequest(apiKey, apiVersion int16, correlationID int32, body []byte) []byte {
+	header := binary.BigEndian.AppendUint16(nil, uint16(apiKey))
+	header = binary.BigEndian.AppendUint16(header, uint16(apiVersion))
+	header = binary.BigEndian.AppendUint32(header, uint32(correlationID))
+	header = binary.BigEndian.AppendUint16(header, uint16(len(clientID)))
+	header = append(header, clientID...)
+
+	packet := binary.BigEndian.AppendUint32(nil, uint32(len(header)+len(body)))
+	packet = append(packet, header...)
+	return append(packet, body...)
+}
+
+func apiVersionsRequest(correlationID int32) []byte {
+	return newRequest(apiKeyApiVersions, 0, correlationID, nil)
+}
+
+// metadataRequest requests metadata of all topics
+func metadataRequest(version int16, correlationID int32) []byte {
+	// null array of topics
+	body := binary.BigEndian.AppendUint32(nil, 0xffffffff)
+	return newRequest(apiKeyMetadata, version, correlationID, body)
+}
+
+// readResponse reads the response with the correlation id and returns its body
+func readResponse(r io.Reader, correlationID int32) ([]byte, error) {
+	header := make([]byte, 8)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, err
+	}
+	size := int32(binary.BigEndian.Uint32(header[:4]))
+	if size < 4 || size > maxResponseSize {
+		return nil, fmt.Errorf("%w: size %d", errInvalidResponse, size)
+	}
+	if id := int32(binary.BigEndian.Uint32(header[4:8])); id != correlationID {
+		return nil, fmt.Errorf("%w: correlation id %d", errInvalidResponse, id)
+	}
+	body := make([]byte, size-4)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return nil, err
+	}
+	return body, nil
+}
+
+// reader decodes primitive types of the Kafka protocol
+type reader struct {
+	data []byte
+	err  error
+}
+
+func (r *reader) next(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if n < 0 || n > len(r.data) {
+		r.err = fmt.Errorf("%w: truncated", errInvalidResponse)
+		return nil
+	}
+	b := r.data[:n]
+	r.data = r.data[n:]
+	return b
+}
+
+func (r *reader) int16() int16 {
+	if b := r.next(2); b != nil {
+		return int16(binary.BigEndian.Uint16(b))
+	}
+	return 0
+}
+
+func (r *reader) int32() int32 {
+	if b := r.next(4); b != nil {
+		return int32(binary.BigEndian.Uint32(b))
+	}
+	return 0
+}
+
+// string returns the empty string for the null value
+func (r *reader) string() string {
+	n := r.int16()
+	if n < 0 {
+		return ""
+	}
+	return string(r.next(int(n)))
+}
+
+// arrayLen returns the number of elements of the array, zero for the null array
+func (r *reader) arrayLen() int {
+	n := int(r.int32())
+	// every element takes at least one byte
+	if n > len(r.data) {
+		r.err = fmt.Errorf("%w: array length %d", errInvalidResponse, n)
+		return 0
+	}
+	if n < 0 {
+		return 0
+	}
+	return n
+}
+
+func parseApiVersions(body []byte) ([]*apiVersion, error) {
+	r := &reader{data: body}
+	if code := r.int16(); r.err == nil && code != 0 {
+		return nil, kafkaError(code)
+	}
+	n := r.arrayLen()
+	result := make([]*apiVersion, 0, n)
+	for i := 0; i < n && r.err == nil; i++ {
+		result = append(result, &apiVersion{key: r.int16(), minVersion: r.int16(), maxVersion: r.int16()})
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+	return result, nil
+}
+
+// parseMetadata parses the Metadata response of version 1 or 2, topics with errors are skipped,
+// e.g. topics the client is not authorized to describe
+func parseMetadata(body []byte, version int16) (*metadata, error) {
+	r := &reader{data: body}
+	result := &metadata{}
+	n := r.arrayLen()
+	for i := 0; i < n && r.err == nil; i++ {
+		// node id
+		r.int32()
+		b := &broker{host: r.string(), port: r.int32()}
+		// rack
+		r.string()
+		result.brokers = append(result.brokers, b)
+	}
+	if version >= 2 {
+		result.clusterID = r.string()
+	}
+	// controller id
+	r.int32()
+	n = r.arrayLen()
+	for i := 0; i < n && r.err == nil; i++ {
+		code := r.int16()
+		name := r.string()
+		// is internal
+		r.next(1)
+		partitions := r.arrayLen()
+		for j := 0; j < partitions && r.err == nil; j++ {
+			// error code, partition index and leader id
+			r.next(10)
+			// replica and isr nodes
+			r.next(4 * r.arrayLen())
+			r.next(4 * r.arrayLen())
+		}
+		if code == 0 {
+			result.topics = append(result.topics, name)
+		}
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+	return result, nil
+}
@@ -0,0 +1,151 @@
+package kafka
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func appendString(b []byte, s string) []byte {
+	b = binary.BigEndian.AppendUint16(b, uint16(len(s)))
+	return append(b, s...)
+}
+
+func response(correlationID int32, body []byte) []byte {
+	packet := binary.BigEndian.AppendUint32(nil, uint32(len(body)+4))
+	packet = binary.BigEndian.AppendUint32(packet, uint32(correlationID))
+	return append(packet, body...)
+}
+
+func apiVersionsBody(code int16, versions ...*apiVersion) []byte {
+	body := binary.BigEndian.AppendUint16(nil, uint16(code))
+	body = binary.BigEndian.AppendUint32(body, uint32(len(versions)))
+	for _, v := range versions {
+		body = binary.BigEndian.AppendUint16(body, uint16(v.key))
+		body = binary.BigEndian.AppendUint16(body, uint16(v.minVersion))
+		body = binary.BigEndian.AppendUint16(body, uint16(v.maxVersion))
+	}
+	return body
+}
+
+// metadataBody returns the Metadata v2 response with one broker, topics have one partition,
+// topics with the auth suffix have the TOPIC_AUTHORIZATION_FAILED error
+func metadataBody(clusterID string, topics ...string) []byte {
+	body := binary.BigEndian.AppendUint32(nil, 1)
+	body = binary.BigEndian.AppendUint32(body, 1)
+	body = appendString(body, "kafka-1.local")
+	body = binary.BigEndian.AppendUint32(body, 9092)
+	// null rack
+	body = binary.BigEndian.AppendUint16(body, 0xffff)
+	body = appendString(body, clusterID)
+	// controller id
+	body = binary.BigEndian.AppendUint32(body, 1)
+	body = binary.BigEndian.AppendUint32(body, uint32(len(topics)))
+	for _, topic := range topics {
+		var code uint16
+		if strings.HasSuffix(topic, "auth") {
+			code = 29
+		}
+		body = binary.BigEndian.AppendUint16(body, code)
+		body = appendString(body, topic)
+		body = append(body, 0)
+		body = binary.BigEndian.AppendUint32(body, 1)
+		// error code, partition index and leader id
+		body = append(body, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1)
+		// replica and isr nodes
+		body = binary.BigEndian.AppendUint32(body, 1)
+		body = binary.BigEndian.AppendUint32(body, 1)
+		body = binary.BigEndian.AppendUint32(body, 1)
+		body = binary.BigEndian.AppendUint32(body, 1)
+	}
+	return body
+}
+
+func TestApiVersionsRequest(t *testing.T) {
+	t.Parallel()
+	require.Equal(t, []byte{
+		0x00, 0x00, 0x00, 0x0c,
+		0x00, 0x12, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x01,
+		0x00, 0x02, 's', 'x',
+	}, apiVersionsRequest(1))
+}
+
+func TestMetadataRequest(t *testing.T) {
+	t.Parallel()
+	require.Equal(t, []byte{
+		0x00, 0x00, 0x00, 0x10,
+		0x00, 0x03, 0x00, 0x02,
+		0x00, 0x00, 0x00, 0x02,
+		0x00, 0x02, 's', 'x',
+		0xff, 0xff, 0xff, 0xff,
+	}, metadataRequest(2, 2))
+}
+
+func TestReadResponse(t *testing.T) {
+	t.Parallel()
+	body, err := readResponse(bytes.NewReader(response(1, []byte{0x01, 0x02})), 1)
+	require.NoError(t, err)
+	require.Equal(t, []byte{0x01, 0x02}, body)
+}
+
+func TestReadResponseError(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{
+			name: "CorrelationID",
+			data: response(2, nil),
+		},
+		{
+			name: "TooLarge",
+			data: []byte{0x7f, 0xff, 0xff, 0xff, 0x00, 0x00, 0x00, 0x01},
+		},
+		{
+			name: "TooSmall",
+			data: []byte{0x00, 0x00, 0x00, 0x02, 0x00, 0x00, 0x00, 0x01},
+		},
+	}
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := readResponse(bytes.NewReader(tt.data), 1)
+			require.ErrorIs(t, err, errInvalidResponse)
+		})
+	}
+}
+
+func TestParseApiVersions(t *testing.T) {
+	t.Parallel()
+	versions := []*apiVersion{{key: 3, minVersion: 0, maxVersion: 12}, {key: 18, minVersion: 0, maxVersion: 3}}
+	result, err := parseApiVersions(apiVersionsBody(0, versions...))
+	require.NoError(t, err)
+	require.Equal(t, versions, result)
+
+	_, err = parseApiVersions(apiVersionsBody(35))
+	require.ErrorIs(t, err, kafkaError(35))
+
+	_, err = parseApiVersions(apiVersionsBody(0, versions...)[:10])
+	require.ErrorIs(t, err, errInvalidResponse)
+}
+
+func TestParseMetadata(t *testing.T) {
+	t.Parallel()
+	result, err := parseMetadata(metadataBody("Xy3qT2pTRa6Fa8MvNQb7sA", "orders", "payments-auth", "events"), 2)
+	require.NoError(t, err)
+	require.Equal(t, &metadata{
+		brokers:   []*broker{{host: "kafka-1.local", port: 9092}},
+		clusterID: "Xy3qT2pTRa6Fa8MvNQb7sA",
+		topics:    []string{"orders", "events"},
+	}, result)
+
+	body := metadataBody("Xy3qT2pTRa6Fa8MvNQb7sA", "orders")
+	_, err = parseMetadata(body[:len(body)-3], 2)
+	require.ErrorIs(t, err, errInvalidResponse)
+}
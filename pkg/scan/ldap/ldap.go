@@ -0,0 +1,241 @@
+package ldap
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+const (
+	ScanType = "ldap"
+
+	defaultDialTimeout = 2 * time.Second
+	defaultDataTimeout = 2 * time.Second
+
+	bindMessageID   = 1
+	searchMessageID = 2
+)
+
+// DefaultTLSPorts are ports of LDAP over TLS (LDAPS) and of the Global Catalog over TLS
+var DefaultTLSPorts = []uint16{636, 3269}
+
+type ScanResult struct {
+	ScanType string `json:"scan"`
+	IP       string `json:"ip"`
+	Port     uint16 `json:"port"`
+	// TLS is set if the connection is made over TLS
+	TLS bool `json:"tls"`
+	// Anonymous is set if the server accepted the anonymous simple bind
+	Anonymous bool `json:"anonymous"`
+	// BindResult is the result code of the rejected bind, e.g. inappropriateAuthentication
+	BindResult string `json:"bind_result,omitempty"`
+	// NamingContexts are DNs of the directory trees of the server, e.g. dc=example,dc=com
+	NamingContexts []string `json:"naming_contexts,omitempty"`
+	LDAPVersions   []string `json:"ldap_versions,omitempty"`
+	Vendor         string   `json:"vendor,omitempty"`
+	VendorVersion  string   `json:"vendor_version,omitempty"`
+}
+
+func (r *ScanResult) String() string {
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "%-20s %-5d", r.IP, r.Port)
+	if r.TLS {
+		buf.WriteString(" tls")
+	}
+	if r.Anonymous {
+		buf.WriteString(" anonymous")
+	} else {
+		fmt.Fprintf(&buf, " bind:%s", r.BindResult)
+	}
+	if len(r.Vendor) > 0 {
+		fmt.Fprintf(&buf, " %q", strings.TrimSpace(r.Vendor+" "+r.VendorVersion))
+	}
+	if len(r.NamingContexts) > 0 {
+		fmt.Fprintf(&buf, " %s", strings.Join(r.NamingContexts, ";"))
+	}
+	return buf.String()
+}
+
+func (r *ScanResult) ID() string {
+	return fmt.Sprintf("%s:%d", r.IP, r.Port)
+}
+
+func (r *ScanResult) MarshalJSON() ([]byte, error) {
+	// Type definition for the recursive call
+	type JScanResult ScanResult
+	// This works because JScanResult doesn't have a MarshalJSON function associated with it
+	return json.Marshal(JScanResult(*r))
+}
+
+// Scanner sends the anonymous simple bind and reads naming contexts, supported versions and vendor
+// attributes of the rootDSE. The rootDSE is read even if the bind is rejected since many servers allow it
+// without authentication.
+type Scanner struct {
+	dialer      *net.Dialer
+	dataTimeout time.Duration
+	tlsPorts    map[uint16]bool
+}
+
+// Assert that ldap.Scanner conforms to the scan.Scanner interface
+var _ scan.Scanner = (*Scanner)(nil)
+
+type ScannerOption func(*Scanner)
+
+func WithDialTimeout(timeout time.Duration) ScannerOption {
+	return func(s *Scanner) {
+		s.dialer.Timeout = timeout
+	}
+}
+
+func WithDataTimeout(timeout time.Duration) ScannerOption {
+	return func(s *Scanner) {
+		s.dataTimeout = timeout
+	}
+}
+
+// WithTLSPorts sets ports that are connected over TLS, DefaultTLSPorts are used by default
+func WithTLSPorts(ports []uint16) ScannerOption {
+	return func(s *Scanner) {
+		s.tlsPorts = tlsPortSet(ports)
+	}
+}
+
+func NewScanner(opts ...ScannerOption) *Scanner {
+	s := &Scanner{
+		dialer: &net.Dialer{
+			Timeout: defaultDialTimeout,
+		},
+		dataTimeout: defaultDataTimeout,
+		tlsPorts:    tlsPortSet(DefaultTLSPorts),
+	}
+	for _, o := range opts {
+		o(s)
+	}
+	return s
+}
+
+func tlsPortSet(ports []uint16) map[uint16]bool {
+	result := make(map[uint16]bool, len(ports))
+	for _, port := range ports {
+		result[port] = true
+	}
+	return result
+}
+
+func (s *Scanner) Scan(ctx context.Context, r *scan.Request) (scan.Result, error) {
+	result, err := s.scanTarget(ctx, r)
+	return result, scan.WrapError(err)
+}
+
+func (s *Scanner) scanTarget(ctx context.Context, r *scan.Request) (scan.Result, error) {
+	addr := net.JoinHostPort(r.DstIP.String(), strconv.Itoa(int(r.DstPort)))
+	conn, err := s.dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	if err = conn.SetDeadline(time.Now().Add(s.dataTimeout)); err != nil {
+		return nil, err
+	}
+	result := &ScanResult{
+		ScanType: ScanType,
+		IP:       r.DstIP.String(),
+		Port:     r.DstPort,
+		TLS:      s.tlsPorts[r.DstPort],
+	}
+	if result.TLS {
+		conn = tls.Client(conn, &tls.Config{
+			InsecureSkipVerify: true,
+		})
+	}
+	reader := bufio.NewReader(conn)
+
+	if _, err = conn.Write(bindRequest(bindMessageID)); err != nil {
+		return nil, err
+	}
+	msg, err := readResponse(reader, bindMessageID)
+	if err != nil {
+		return nil, err
+	}
+	if msg.opTag != tagBindResponse {
+		return nil, fmt.Errorf("%w: unexpected operation 0x%02x", errMessage, msg.opTag)
+	}
+	bind, err := parseResult(msg.op)
+	if err != nil {
+		return nil, err
+	}
+	result.Anonymous = bind.code == resultSuccess
+	if !result.Anonymous {
+		result.BindResult = bind.codeString()
+	}
+
+	// the server is reported even if it closes the connection or refuses the search
+	if attrs, err := readRootDSE(conn, reader); err == nil {
+		result.NamingContexts = attrs["namingContexts"]
+		result.LDAPVersions = attrs["supportedLDAPVersion"]
+		result.Vendor = firstValue(attrs["vendorName"])
+		result.VendorVersion = firstValue(attrs["vendorVersion"])
+	}
+	return result, nil
+}
+
+// readRootDSE sends the rootDSE search request and returns attributes of the entry
+func readRootDSE(conn net.Conn, reader *bufio.Reader) (attrs map[string][]string, err error) {
+	if _, err = conn.Write(rootDSERequest(searchMessageID, rootDSEAttributes)); err != nil {
+		return
+	}
+	for {
+		var msg *message
+		if msg, err = readResponse(reader, searchMessageID); err != nil {
+			return
+		}
+		switch msg.opTag {
+		case tagSearchEntry:
+			if attrs, err = parseSearchEntry(msg.op); err != nil {
+				return
+			}
+		case tagSearchRef:
+			continue
+		case tagSearchDone:
+			return attrs, nil
+		default:
+			return nil, fmt.Errorf("%w: unexpected operation 0x%02x", errMessage, msg.opTag)
+		}
+	}
+}
+
+// readResponse reads messages until the response to the request with the message ID arrives,
+// the notice of disconnection aborts the request
+func readResponse(reader *bufio.Reader, messageID int) (*message, error) {
+	for {
+		msg, err := readMessage(reader)
+		if err != nil {
+			return nil, err
+		}
+		if msg.messageID == 0 && msg.opTag == tagExtendedResult {
+			result, err := parseResult(msg.op)
+			if err != nil {
+				return nil, err
+			}
+			return nil, fmt.Errorf("ldap: disconnected: %s %s", result.codeString(), result.diagnosticMessage)
+		}
+		if msg.messageID == messageID {
+			return msg, nil
+		}
+	}
+}
+
+func firstValue(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
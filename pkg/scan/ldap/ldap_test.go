@@ -0,0 +1,176 @@
+package ldap
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+// testServer answers the bind with the result code and the rootDSE search with the entry,
+// the search is refused if the entry is nil
+type testServer struct {
+	tlsConfig *tls.Config
+	bindCode  int
+	rootDSE   map[string][]string
+}
+
+func serveLDAP(t *testing.T, srv *testServer) *scan.Request {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { l.Close() })
+
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go srv.handle(conn)
+		}
+	}()
+	addr := l.Addr().(*net.TCPAddr)
+	return &scan.Request{DstIP: addr.IP, DstPort: uint16(addr.Port)}
+}
+
+func (srv *testServer) handle(conn net.Conn) {
+	defer conn.Close()
+	if srv.tlsConfig != nil {
+		conn = tls.Server(conn, srv.tlsConfig)
+	}
+	reader := bufio.NewReader(conn)
+	for {
+		msg, err := readMessage(reader)
+		if err != nil {
+			return
+		}
+		var resp []byte
+		switch msg.opTag {
+		case tagBindRequest:
+			resp = encodeMessage(msg.messageID, tagBindResponse, ldapResultOp(srv.bindCode, ""))
+		case tagSearchRequest:
+			if srv.rootDSE == nil {
+				resp = encodeMessage(msg.messageID, tagSearchDone, ldapResultOp(50, "access denied"))
+				break
+			}
+			// unrelated message is skipped by the scanner
+			resp = encodeMessage(msg.messageID+1, tagSearchDone, ldapResultOp(0, ""))
+			resp = append(resp, encodeMessage(msg.messageID, tagSearchEntry, searchEntry(srv.rootDSE))...)
+			resp = append(resp, encodeMessage(msg.messageID, tagSearchDone, ldapResultOp(0, ""))...)
+		default:
+			return
+		}
+		if _, err = conn.Write(resp); err != nil {
+			return
+		}
+	}
+}
+
+func newTestTLSConfig(t *testing.T) *tls.Config {
+	t.Helper()
+	srv := httptest.NewTLSServer(http.NotFoundHandler())
+	defer srv.Close()
+	return &tls.Config{Certificates: srv.TLS.Certificates}
+}
+
+func TestScan(t *testing.T) {
+	t.Parallel()
+
+	rootDSE := map[string][]string{
+		"namingContexts":       {"dc=example,dc=com"},
+		"supportedLDAPVersion": {"2", "3"},
+		"vendorName":           {"OpenLDAP"},
+		"vendorVersion":        {"2.6.3"},
+	}
+	tests := []struct {
+		name     string
+		server   *testServer
+		tls      bool
+		expected *ScanResult
+	}{
+		{
+			name:   "Anonymous",
+			server: &testServer{rootDSE: rootDSE},
+			expected: &ScanResult{Anonymous: true, NamingContexts: []string{"dc=example,dc=com"},
+				LDAPVersions: []string{"2", "3"}, Vendor: "OpenLDAP", VendorVersion: "2.6.3"},
+		},
+		{
+			name:   "BindRejected",
+			server: &testServer{bindCode: 48, rootDSE: map[string][]string{"namingContexts": {"dc=corp,dc=local"}}},
+			expected: &ScanResult{BindResult: "inappropriateAuthentication",
+				NamingContexts: []string{"dc=corp,dc=local"}},
+		},
+		{
+			name:     "SearchRefused",
+			server:   &testServer{bindCode: 53},
+			expected: &ScanResult{BindResult: "unwillingToPerform"},
+		},
+		{
+			name:   "TLS",
+			server: &testServer{tlsConfig: newTestTLSConfig(t), rootDSE: rootDSE},
+			tls:    true,
+			expected: &ScanResult{TLS: true, Anonymous: true, NamingContexts: []string{"dc=example,dc=com"},
+				LDAPVersions: []string{"2", "3"}, Vendor: "OpenLDAP", VendorVersion: "2.6.3"},
+		},
+	}
+
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			req := serveLDAP(t, tt.server)
+			opts := []ScannerOption{WithDataTimeout(time.Second)}
+			if tt.tls {
+				opts = append(opts, WithTLSPorts([]uint16{req.DstPort}))
+			}
+			result, err := NewScanner(opts...).Scan(context.Background(), req)
+			require.NoError(t, err)
+			tt.expected.ScanType = ScanType
+			tt.expected.IP = req.DstIP.String()
+			tt.expected.Port = req.DstPort
+			require.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestScanNotLDAP(t *testing.T) {
+	t.Parallel()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		_, _ = conn.Write([]byte("SSH-2.0-OpenSSH_8.9\r\n"))
+	}()
+	addr := l.Addr().(*net.TCPAddr)
+
+	result, err := NewScanner(WithDataTimeout(time.Second)).Scan(context.Background(),
+		&scan.Request{DstIP: addr.IP, DstPort: uint16(addr.Port)})
+	require.ErrorIs(t, err, errMessage)
+	require.Nil(t, result)
+}
+
+func TestScanResultString(t *testing.T) {
+	t.Parallel()
+
+	result := &ScanResult{ScanType: ScanType, IP: "192.168.0.1", Port: 636, TLS: true, Anonymous: true,
+		NamingContexts: []string{"dc=example,dc=com", "cn=config"}, Vendor: "OpenLDAP", VendorVersion: "2.6.3"}
+	require.Equal(t, `192.168.0.1          636   tls anonymous "OpenLDAP 2.6.3" dc=example,dc=com;cn=config`, result.String())
+	result = &ScanResult{ScanType: ScanType, IP: "192.168.0.2", Port: 389, BindResult: "inappropriateAuthentication"}
+	require.Equal(t, "192.168.0.2          389   bind:inappropriateAuthentication", result.String())
+	require.Equal(t, "192.168.0.2:389", result.ID())
+}
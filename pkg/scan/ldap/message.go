@@ -0,0 +1,295 @@
+package ldap
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+// BER tags of LDAP messages, see RFC 4511
+const (
+	tagBoolean        = 0x01
+	tagInteger        = 0x02
+	tagOctetString    = 0x04
+	tagEnumerated     = 0x0a
+	tagSequence       = 0x30
+	tagSet            = 0x31
+	tagBindRequest    = 0x60
+	tagBindResponse   = 0x61
+	tagSearchRequest  = 0x63
+	tagSearchEntry    = 0x64
+	tagSearchDone     = 0x65
+	tagSearchRef      = 0x73
+	tagAuthSimple     = 0x80
+	tagFilterPresent  = 0x87
+	tagExtendedResult = 0x78
+
+	ldapVersion = 3
+	// maxMessageSize limits the size of responses, rootDSE entries are much smaller
+	maxMessageSize = 1 << 20
+)
+
+// result codes of LDAPResult
+const (
+	resultSuccess = 0
+)
+
+var resultCodes = map[int]string{
+	0:  "success",
+	1:  "operationsError",
+	2:  "protocolError",
+	7:  "authMethodNotSupported",
+	8:  "strongerAuthRequired",
+	13: "confidentialityRequired",
+	48: "inappropriateAuthentication",
+	49: "invalidCredentials",
+	50: "insufficientAccessRights",
+	52: "unavailable",
+	53: "unwillingToPerform",
+}
+
+var errMessage = scan.NewError(scan.ErrParse, errors.New("invalid LDAP message"))
+
+// rootDSEAttributes are requested from the rootDSE
+var rootDSEAttributes = []string{
+	"namingContexts", "supportedLDAPVersion", "vendorName", "vendorVersion",
+}
+
+// bindRequest returns the anonymous simple BindRequest with the empty name and password
+func bindRequest(messageID int) []byte {
+	var op []byte
+	op = append(op, encodeTLV(tagInteger, encodeInteger(ldapVersion))...)
+	op = append(op, encodeTLV(tagOctetString, nil)...)
+	op = append(op, encodeTLV(tagAuthSimple, nil)...)
+	return encodeMessage(messageID, tagBindRequest, op)
+}
+
+// rootDSERequest returns the SearchRequest of the entry with the empty DN and base scope
+func rootDSERequest(messageID int, attributes []string) []byte {
+	var attrs []byte
+	for _, attr := range attributes {
+		attrs = append(attrs, encodeTLV(tagOctetString, []byte(attr))...)
+	}
+	var op []byte
+	// baseObject
+	op = append(op, encodeTLV(tagOctetString, nil)...)
+	// scope baseObject and derefAliases neverDerefAliases
+	op = append(op, encodeTLV(tagEnumerated, encodeInteger(0))...)
+	op = append(op, encodeTLV(tagEnumerated, encodeInteger(0))...)
+	// sizeLimit and timeLimit
+	op = append(op, encodeTLV(tagInteger, encodeInteger(0))...)
+	op = append(op, encodeTLV(tagInteger, encodeInteger(0))...)
+	// typesOnly
+	op = append(op, encodeTLV(tagBoolean, []byte{0})...)
+	op = append(op, encodeTLV(tagFilterPresent, []byte("objectClass"))...)
+	op = append(op, encodeTLV(tagSequence, attrs)...)
+	return encodeMessage(messageID, tagSearchRequest, op)
+}
+
+func encodeMessage(messageID int, opTag byte, op []byte) []byte {
+	var msg []byte
+	msg = append(msg, encodeTLV(tagInteger, encodeInteger(int64(messageID)))...)
+	msg = append(msg, encodeTLV(opTag, op)...)
+	return encodeTLV(tagSequence, msg)
+}
+
+type message struct {
+	messageID int
+	opTag     byte
+	op        []byte
+}
+
+// readMessage reads the LDAPMessage envelope and returns its message ID and protocol operation
+func readMessage(r *bufio.Reader) (*message, error) {
+	tag, err := r.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	if tag != tagSequence {
+		return nil, fmt.Errorf("%w: unexpected tag 0x%02x", errMessage, tag)
+	}
+	length, err := readLength(r)
+	if err != nil {
+		return nil, err
+	}
+	data := make([]byte, length)
+	if _, err = io.ReadFull(r, data); err != nil {
+		return nil, err
+	}
+	value, data, err := expectTLV(data, tagInteger)
+	if err != nil {
+		return nil, err
+	}
+	msg := &message{messageID: int(decodeInteger(value))}
+	// controls after the protocol operation are ignored
+	if msg.opTag, msg.op, _, err = parseTLV(data); err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+func readLength(r *bufio.Reader) (int, error) {
+	b, err := r.ReadByte()
+	if err != nil {
+		return 0, err
+	}
+	if b&0x80 == 0 {
+		return int(b), nil
+	}
+	n := int(b & 0x7f)
+	if n == 0 || n > 4 {
+		return 0, errMessage
+	}
+	length := 0
+	for i := 0; i < n; i++ {
+		if b, err = r.ReadByte(); err != nil {
+			return 0, err
+		}
+		length = length<<8 | int(b)
+	}
+	if length > maxMessageSize {
+		return 0, fmt.Errorf("%w: message size %d", errMessage, length)
+	}
+	return length, nil
+}
+
+type ldapResult struct {
+	code              int
+	diagnosticMessage string
+}
+
+// codeString returns the name of the result code, e.g. success or inappropriateAuthentication
+func (r *ldapResult) codeString() string {
+	if name, ok := resultCodes[r.code]; ok {
+		return name
+	}
+	return strconv.Itoa(r.code)
+}
+
+func parseResult(op []byte) (*ldapResult, error) {
+	value, op, err := expectTLV(op, tagEnumerated)
+	if err != nil {
+		return nil, err
+	}
+	result := &ldapResult{code: int(decodeInteger(value))}
+	// matchedDN
+	if _, op, err = expectTLV(op, tagOctetString); err != nil {
+		return nil, err
+	}
+	if value, _, err = expectTLV(op, tagOctetString); err != nil {
+		return nil, err
+	}
+	result.diagnosticMessage = string(value)
+	return result, nil
+}
+
+// parseSearchEntry returns values of attributes of the SearchResultEntry
+func parseSearchEntry(op []byte) (map[string][]string, error) {
+	// objectName
+	_, op, err := expectTLV(op, tagOctetString)
+	if err != nil {
+		return nil, err
+	}
+	var attrs []byte
+	if attrs, _, err = expectTLV(op, tagSequence); err != nil {
+		return nil, err
+	}
+	result := make(map[string][]string)
+	for len(attrs) > 0 {
+		var attr, value []byte
+		if attr, attrs, err = expectTLV(attrs, tagSequence); err != nil {
+			return nil, err
+		}
+		if value, attr, err = expectTLV(attr, tagOctetString); err != nil {
+			return nil, err
+		}
+		name := string(value)
+		var values []byte
+		if values, _, err = expectTLV(attr, tagSet); err != nil {
+			return nil, err
+		}
+		for len(values) > 0 {
+			if value, values, err = expectTLV(values, tagOctetString); err != nil {
+				return nil, err
+			}
+			result[name] = append(result[name], string(value))
+		}
+	}
+	return result, nil
+}
+
+func encodeTLV(tag byte, value []byte) []byte {
+	result := []byte{tag}
+	length := len(value)
+	if length < 0x80 {
+		result = append(result, byte(length))
+	} else {
+		// long form: the number of length bytes followed by the length in big-endian order
+		var lengthBytes []byte
+		for ; length > 0; length >>= 8 {
+			lengthBytes = append([]byte{byte(length)}, lengthBytes...)
+		}
+		result = append(result, 0x80|byte(len(lengthBytes)))
+		result = append(result, lengthBytes...)
+	}
+	return append(result, value...)
+}
+
+func parseTLV(data []byte) (tag byte, value, rest []byte, err error) {
+	if len(data) < 2 {
+		return 0, nil, nil, errMessage
+	}
+	tag = data[0]
+	length := int(data[1])
+	data = data[2:]
+	if length&0x80 != 0 {
+		n := length & 0x7f
+		if n == 0 || n > 4 || len(data) < n {
+			return 0, nil, nil, errMessage
+		}
+		length = 0
+		for _, b := range data[:n] {
+			length = length<<8 | int(b)
+		}
+		data = data[n:]
+	}
+	if length < 0 || len(data) < length {
+		return 0, nil, nil, errMessage
+	}
+	return tag, data[:length], data[length:], nil
+}
+
+func expectTLV(data []byte, expectedTag byte) (value, rest []byte, err error) {
+	var tag byte
+	if tag, value, rest, err = parseTLV(data); err != nil {
+		return
+	}
+	if tag != expectedTag {
+		return nil, nil, fmt.Errorf("%w: unexpected tag 0x%02x", errMessage, tag)
+	}
+	return
+}
+
+// encodeInteger returns the minimal two's complement encoding of the integer
+func encodeInteger(v int64) []byte {
+	result := []byte{byte(v)}
+	for v > 0x7f || v < -0x80 {
+		v >>= 8
+		result = append([]byte{byte(v)}, result...)
+	}
+	return result
+}
+
+func decodeInteger(data []byte) (v int64) {
+	for i, b := range data {
+		if i == 0 && b&0x80 != 0 {
+			v = -1
+		}
+		v = v<<8 | int64(b)
+	}
+	return
+}
@@ -0,0 +1,121 @@
+package ldap
+
+import (
+	"bufio"
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBindRequest(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, []byte{
+		0x30, 0x0c, 0x02, 0x01, 0x01,
+		0x60, 0x07, 0x02, 0x01, 0x03, 0x04, 0x00, 0x80, 0x00,
+	}, bindRequest(1))
+}
+
+func TestRootDSERequest(t *testing.T) {
+	t.Parallel()
+
+	msg, err := readMessage(bufio.NewReader(bytes.NewReader(rootDSERequest(2, []string{"namingContexts"}))))
+	require.NoError(t, err)
+	require.Equal(t, 2, msg.messageID)
+	require.Equal(t, byte(tagSearchRequest), msg.opTag)
+
+	expected := []byte{
+		0x04, 0x00, 0x0a, 0x01, 0x00, 0x0a, 0x01, 0x00, 0x02, 0x01, 0x00, 0x02, 0x01, 0x00, 0x01, 0x01, 0x00,
+		0x87, 0x0b}
+	expected = append(expected, "objectClass"...)
+	expected = append(expected, 0x30, 0x10, 0x04, 0x0e)
+	expected = append(expected, "namingContexts"...)
+	require.Equal(t, expected, msg.op)
+}
+
+func TestParseSearchEntry(t *testing.T) {
+	t.Parallel()
+
+	entry := searchEntry(map[string][]string{
+		"namingContexts":       {"dc=example,dc=com", "cn=config"},
+		"supportedLDAPVersion": {"3"},
+	})
+	msg, err := readMessage(bufio.NewReader(bytes.NewReader(encodeMessage(2, tagSearchEntry, entry))))
+	require.NoError(t, err)
+	attrs, err := parseSearchEntry(msg.op)
+	require.NoError(t, err)
+	require.Equal(t, map[string][]string{
+		"namingContexts":       {"dc=example,dc=com", "cn=config"},
+		"supportedLDAPVersion": {"3"},
+	}, attrs)
+}
+
+func TestParseResult(t *testing.T) {
+	t.Parallel()
+
+	result, err := parseResult(ldapResultOp(48, "anonymous bind disallowed"))
+	require.NoError(t, err)
+	require.Equal(t, &ldapResult{code: 48, diagnosticMessage: "anonymous bind disallowed"}, result)
+	require.Equal(t, "inappropriateAuthentication", result.codeString())
+	require.Equal(t, "80", (&ldapResult{code: 80}).codeString())
+}
+
+func TestReadMessageLongLength(t *testing.T) {
+	t.Parallel()
+
+	msg := encodeMessage(3, tagSearchEntry, bytes.Repeat([]byte{'x'}, 300))
+	require.Equal(t, []byte{0x30, 0x82}, msg[:2])
+	parsed, err := readMessage(bufio.NewReader(bytes.NewReader(msg)))
+	require.NoError(t, err)
+	require.Equal(t, &message{messageID: 3, opTag: tagSearchEntry, op: bytes.Repeat([]byte{'x'}, 300)}, parsed)
+}
+
+func TestReadMessageInvalid(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{name: "Tag", data: []byte("HTTP/1.1 400 Bad Request\r\n")},
+		{name: "MessageSize", data: []byte{0x30, 0x84, 0x7f, 0xff, 0xff, 0xff}},
+		{name: "IndefiniteLength", data: []byte{0x30, 0x80}},
+		{name: "MessageID", data: []byte{0x30, 0x03, 0x04, 0x01, 0x01}},
+		{name: "ShortOperation", data: []byte{0x30, 0x05, 0x02, 0x01, 0x01, 0x61, 0x05}},
+	}
+
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := readMessage(bufio.NewReader(bytes.NewReader(tt.data)))
+			require.ErrorIs(t, err, errMessage)
+		})
+	}
+}
+
+func ldapResultOp(code int, diagnosticMessage string) []byte {
+	var op []byte
+	op = append(op, encodeTLV(tagEnumerated, encodeInteger(int64(code)))...)
+	op = append(op, encodeTLV(tagOctetString, nil)...)
+	return append(op, encodeTLV(tagOctetString, []byte(diagnosticMessage))...)
+}
+
+func searchEntry(attrs map[string][]string) []byte {
+	var list []byte
+	for _, name := range []string{"namingContexts", "supportedLDAPVersion", "vendorName", "vendorVersion"} {
+		values, ok := attrs[name]
+		if !ok {
+			continue
+		}
+		var set []byte
+		for _, v := range values {
+			set = append(set, encodeTLV(tagOctetString, []byte(v))...)
+		}
+		attr := append(encodeTLV(tagOctetString, []byte(name)), encodeTLV(tagSet, set)...)
+		list = append(list, encodeTLV(tagSequence, attr)...)
+	}
+	return append(encodeTLV(tagOctetString, nil), encodeTLV(tagSequence, list)...)
+}
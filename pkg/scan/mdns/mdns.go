@@ -0,0 +1,245 @@
+package mdns
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+const (
+	ScanType = "mdns"
+
+	defaultDataTimeout = 2 * time.Second
+	// maxMessageSize is the maximum mDNS message size over Ethernet, see RFC 6762 section 17
+	maxMessageSize = 9000
+	// maxPendingResponses limits buffered responses of one scan request, other responses are dropped
+	maxPendingResponses = 64
+)
+
+// MulticastIP is the mDNS multicast address, queries to it are answered by all responders of the local network
+var MulticastIP = net.IPv4(224, 0, 0, 251)
+
+type ScanResult struct {
+	ScanType string `json:"scan"`
+	IP       string `json:"ip"`
+	Port     uint16 `json:"port"`
+	Hostname string `json:"hostname,omitempty"`
+	// Services are advertised DNS-SD service types, e.g. _http._tcp.local.
+	Services []string `json:"services,omitempty"`
+}
+
+func (r *ScanResult) String() string {
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "%-20s %-5d %s", r.IP, r.Port, r.Hostname)
+	if len(r.Services) > 0 {
+		fmt.Fprintf(&buf, " %s", strings.Join(r.Services, ","))
+	}
+	return buf.String()
+}
+
+func (r *ScanResult) ID() string {
+	return fmt.Sprintf("%s:%d", r.IP, r.Port)
+}
+
+func (r *ScanResult) MarshalJSON() ([]byte, error) {
+	// Type definition for the recursive call
+	type JScanResult ScanResult
+	// This works because JScanResult doesn't have a MarshalJSON function associated with it
+	return json.Marshal(JScanResult(*r))
+}
+
+type packet struct {
+	ip   net.IP
+	data []byte
+}
+
+// Scanner sends DNS-SD service type enumeration queries from one UDP socket,
+// the listener goroutine reads responses of all targets and correlates them back to scan requests by source IP.
+// Queries to the multicast address are answered by all responders of the local network,
+// each responder is reported as a separate result.
+type Scanner struct {
+	conn        net.PacketConn
+	dataTimeout time.Duration
+
+	mu sync.Mutex
+	// waiters are channels of pending scan requests by target IP, the empty key receives responses of all IPs
+	waiters map[string][]chan *packet
+}
+
+// Assert that mdns.Scanner conforms to the scan.Scanner interface
+var _ scan.Scanner = (*Scanner)(nil)
+
+type ScannerOption func(*Scanner)
+
+// WithDataTimeout sets the time to wait for responses
+func WithDataTimeout(timeout time.Duration) ScannerOption {
+	return func(s *Scanner) {
+		s.dataTimeout = timeout
+	}
+}
+
+// NewScanner opens the UDP socket and starts the listener of responses,
+// the socket is closed when ctx is done
+func NewScanner(ctx context.Context, opts ...ScannerOption) (*Scanner, error) {
+	var lc net.ListenConfig
+	conn, err := lc.ListenPacket(ctx, "udp4", ":0")
+	if err != nil {
+		return nil, err
+	}
+	s := &Scanner{
+		conn:        conn,
+		dataTimeout: defaultDataTimeout,
+		waiters:     make(map[string][]chan *packet),
+	}
+	for _, o := range opts {
+		o(s)
+	}
+	go func() {
+		<-ctx.Done()
+		conn.Close()
+	}()
+	go s.listen()
+	return s, nil
+}
+
+// listen reads responses until the socket is closed and passes them to waiting scan requests
+func (s *Scanner) listen() {
+	buf := make([]byte, maxMessageSize)
+	for {
+		n, addr, err := s.conn.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+		udpAddr, ok := addr.(*net.UDPAddr)
+		if !ok {
+			continue
+		}
+		p := &packet{ip: udpAddr.IP, data: append([]byte(nil), buf[:n]...)}
+		s.mu.Lock()
+		for _, key := range []string{udpAddr.IP.String(), ""} {
+			for _, c := range s.waiters[key] {
+				// the waiter is not blocked, so the response is dropped if the buffer is full
+				select {
+				case c <- p:
+				default:
+				}
+			}
+		}
+		s.mu.Unlock()
+	}
+}
+
+func (s *Scanner) wait(key string) chan *packet {
+	c := make(chan *packet, maxPendingResponses)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.waiters[key] = append(s.waiters[key], c)
+	return c
+}
+
+func (s *Scanner) done(key string, c chan *packet) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	waiters := s.waiters[key]
+	for i, w := range waiters {
+		if w == c {
+			waiters = append(waiters[:i], waiters[i+1:]...)
+			break
+		}
+	}
+	if len(waiters) == 0 {
+		delete(s.waiters, key)
+		return
+	}
+	s.waiters[key] = waiters
+}
+
+func (s *Scanner) Scan(ctx context.Context, r *scan.Request) (scan.Result, error) {
+	result, err := s.scanTarget(ctx, r)
+	return result, scan.WrapError(err)
+}
+
+func (s *Scanner) scanTarget(ctx context.Context, r *scan.Request) (scan.Result, error) {
+	multicast := r.DstIP.IsMulticast()
+	var key string
+	var reverseIP net.IP
+	if !multicast {
+		key = r.DstIP.String()
+		reverseIP = r.DstIP
+	}
+	msg, err := query(reverseIP)
+	if err != nil {
+		return nil, err
+	}
+	c := s.wait(key)
+	defer s.done(key, c)
+	if _, err = s.conn.WriteTo(msg, &net.UDPAddr{IP: r.DstIP, Port: int(r.DstPort)}); err != nil {
+		return nil, err
+	}
+
+	timer := time.NewTimer(s.dataTimeout)
+	defer timer.Stop()
+	responders := make(map[string]*ScanResult)
+	var results []*ScanResult
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-timer.C:
+			return toResult(results), nil
+		case p := <-c:
+			resp, err := parseResponse(p.data, p.ip)
+			if err != nil {
+				continue
+			}
+			ip := p.ip.String()
+			res, ok := responders[ip]
+			if !ok {
+				res = &ScanResult{ScanType: ScanType, IP: ip, Port: r.DstPort}
+				responders[ip] = res
+				results = append(results, res)
+			}
+			res.merge(resp)
+		}
+	}
+}
+
+func (r *ScanResult) merge(resp *response) {
+	if len(r.Hostname) == 0 {
+		r.Hostname = resp.hostname
+	}
+	for _, service := range resp.services {
+		if !contains(r.Services, service) {
+			r.Services = append(r.Services, service)
+		}
+	}
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+func toResult(results []*ScanResult) scan.Result {
+	switch len(results) {
+	case 0:
+		return nil
+	case 1:
+		return results[0]
+	}
+	multi := make(scan.MultiResult, 0, len(results))
+	for _, res := range results {
+		multi = append(multi, res)
+	}
+	return multi
+}
@@ -0,0 +1,140 @@
+package mdns
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// startFakeResponder starts UDP mDNS responder that answers legacy unicast queries with the services
+func startFakeResponder(t *testing.T, ip string, hostname string, services ...string) *scan.Request {
+	t.Helper()
+	conn, err := net.ListenPacket("udp4", ip+":0")
+	if err != nil {
+		t.Skip(ip, "is not available:", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	go func() {
+		buf := make([]byte, maxMessageSize)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			var msg dnsmessage.Message
+			if err := msg.Unpack(buf[:n]); err != nil || msg.Header.Response {
+				continue
+			}
+			var answers []dnsmessage.Resource
+			for _, service := range services {
+				answers = append(answers, ptrRecord(ServicesQueryName, service))
+			}
+			additionals := []dnsmessage.Resource{aRecord(hostname, net.ParseIP(ip))}
+			resp := dnsmessage.Message{
+				Header:      dnsmessage.Header{ID: msg.Header.ID, Response: true, Authoritative: true},
+				Answers:     answers,
+				Additionals: additionals,
+			}
+			data, err := resp.Pack()
+			if err != nil {
+				return
+			}
+			// services are split into two responses
+			_, _ = conn.WriteTo(data, addr)
+			_, _ = conn.WriteTo(data, addr)
+		}
+	}()
+	addr := conn.LocalAddr().(*net.UDPAddr)
+	return &scan.Request{DstIP: addr.IP, DstPort: uint16(addr.Port)}
+}
+
+func TestScanCorrelatesResponses(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	reqA := startFakeResponder(t, "127.0.0.1", "printer.local.", "_ipp._tcp.local.", "_http._tcp.local.")
+	reqB := startFakeResponder(t, "127.0.0.2", "tv.local.", "_airplay._tcp.local.")
+	s, err := NewScanner(ctx, WithDataTimeout(200*time.Millisecond))
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	results := make([]scan.Result, 2)
+	for i, req := range []*scan.Request{reqA, reqB} {
+		wg.Add(1)
+		go func(i int, req *scan.Request) {
+			defer wg.Done()
+			result, err := s.Scan(ctx, req)
+			require.NoError(t, err)
+			results[i] = result
+		}(i, req)
+	}
+	wg.Wait()
+
+	require.Equal(t, &ScanResult{ScanType: ScanType, IP: "127.0.0.1", Port: reqA.DstPort,
+		Hostname: "printer.local.", Services: []string{"_ipp._tcp.local.", "_http._tcp.local."}}, results[0])
+	require.Equal(t, &ScanResult{ScanType: ScanType, IP: "127.0.0.2", Port: reqB.DstPort,
+		Hostname: "tv.local.", Services: []string{"_airplay._tcp.local."}}, results[1])
+}
+
+func TestScanNoResponse(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	conn, err := net.ListenPacket("udp4", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer conn.Close()
+	addr := conn.LocalAddr().(*net.UDPAddr)
+
+	s, err := NewScanner(ctx, WithDataTimeout(50*time.Millisecond))
+	require.NoError(t, err)
+	result, err := s.Scan(ctx, &scan.Request{DstIP: addr.IP, DstPort: uint16(addr.Port)})
+	require.NoError(t, err)
+	require.Nil(t, result)
+	require.Empty(t, s.waiters)
+}
+
+func TestListenerPassesAllResponsesToMulticastWaiter(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	s, err := NewScanner(ctx)
+	require.NoError(t, err)
+	all := s.wait("")
+	defer s.done("", all)
+	one := s.wait("127.0.0.1")
+	defer s.done("127.0.0.1", one)
+
+	sender, err := net.ListenPacket("udp4", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer sender.Close()
+	_, err = sender.WriteTo([]byte("response"), &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: s.conn.LocalAddr().(*net.UDPAddr).Port})
+	require.NoError(t, err)
+
+	for _, c := range []chan *packet{all, one} {
+		select {
+		case p := <-c:
+			require.Equal(t, []byte("response"), p.data)
+			require.Equal(t, "127.0.0.1", p.ip.String())
+		case <-time.After(3 * time.Second):
+			t.Fatal("test timeout")
+		}
+	}
+}
+
+func TestToResult(t *testing.T) {
+	t.Parallel()
+	a := &ScanResult{ScanType: ScanType, IP: "192.168.0.1", Port: 5353}
+	b := &ScanResult{ScanType: ScanType, IP: "192.168.0.2", Port: 5353}
+	require.Nil(t, toResult(nil))
+	require.Equal(t, a, toResult([]*ScanResult{a}))
+	require.Equal(t, scan.MultiResult{a, b}, toResult([]*ScanResult{a, b}))
+}
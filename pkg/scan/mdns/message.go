@@ -0,0 +1,104 @@
+package mdns
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/v-byte-cpu/sx/pkg/scan"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+const (
+	// ServicesQueryName is the DNS-SD service type enumeration name, see RFC 6763 section 9
+	ServicesQueryName = "_services._dns-sd._udp.local."
+
+	// unicastResponseBit is the top bit of the question class that asks for the unicast response,
+	// see RFC 6762 section 5.4
+	unicastResponseBit = 1 << 15
+)
+
+var errResponse = scan.NewError(scan.ErrParse, errors.New("mDNS message is not a response"))
+
+// query returns the PTR query of service types and, if ip is not nil, the reverse PTR query of the hostname
+func query(ip net.IP) ([]byte, error) {
+	msg := dnsmessage.Message{
+		Questions: []dnsmessage.Question{
+			{
+				Name:  dnsmessage.MustNewName(ServicesQueryName),
+				Type:  dnsmessage.TypePTR,
+				Class: dnsmessage.ClassINET | unicastResponseBit,
+			},
+		},
+	}
+	if ip4 := ip.To4(); ip4 != nil {
+		name, err := dnsmessage.NewName(reverseName(ip4))
+		if err != nil {
+			return nil, err
+		}
+		msg.Questions = append(msg.Questions, dnsmessage.Question{
+			Name:  name,
+			Type:  dnsmessage.TypePTR,
+			Class: dnsmessage.ClassINET | unicastResponseBit,
+		})
+	}
+	return msg.Pack()
+}
+
+func reverseName(ip4 net.IP) string {
+	return fmt.Sprintf("%d.%d.%d.%d.in-addr.arpa.", ip4[3], ip4[2], ip4[1], ip4[0])
+}
+
+type response struct {
+	services []string
+	hostname string
+}
+
+// parseResponse extracts service types and the hostname of the responder with the IP
+// from answers and additional records of the response
+func parseResponse(data []byte, ip net.IP) (*response, error) {
+	var msg dnsmessage.Message
+	if err := msg.Unpack(data); err != nil {
+		return nil, err
+	}
+	if !msg.Header.Response {
+		return nil, errResponse
+	}
+	resp := &response{}
+	// the hostname is taken from the reverse PTR answer, the A record of the IP or the SRV target in this order
+	var reverseHostname, addrHostname, srvHostname string
+	var reverse string
+	if ip4 := ip.To4(); ip4 != nil {
+		reverse = reverseName(ip4)
+	}
+	for _, records := range [][]dnsmessage.Resource{msg.Answers, msg.Additionals} {
+		for _, rr := range records {
+			name := rr.Header.Name.String()
+			switch body := rr.Body.(type) {
+			case *dnsmessage.PTRResource:
+				switch {
+				case strings.EqualFold(name, ServicesQueryName):
+					resp.services = append(resp.services, body.PTR.String())
+				case len(reverse) > 0 && strings.EqualFold(name, reverse):
+					reverseHostname = body.PTR.String()
+				}
+			case *dnsmessage.AResource:
+				if net.IP(body.A[:]).Equal(ip) && len(addrHostname) == 0 {
+					addrHostname = name
+				}
+			case *dnsmessage.SRVResource:
+				if len(srvHostname) == 0 {
+					srvHostname = body.Target.String()
+				}
+			}
+		}
+	}
+	for _, hostname := range []string{reverseHostname, addrHostname, srvHostname} {
+		if len(hostname) > 0 {
+			resp.hostname = hostname
+			break
+		}
+	}
+	return resp, nil
+}
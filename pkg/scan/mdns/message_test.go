@@ -0,0 +1,133 @@
+package mdns
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+func mustName(name string) dnsmessage.Name {
+	return dnsmessage.MustNewName(name)
+}
+
+func ptrRecord(name, ptr string) dnsmessage.Resource {
+	return dnsmessage.Resource{
+		Header: dnsmessage.ResourceHeader{Name: mustName(name), Type: dnsmessage.TypePTR, Class: dnsmessage.ClassINET},
+		Body:   &dnsmessage.PTRResource{PTR: mustName(ptr)},
+	}
+}
+
+func aRecord(name string, ip net.IP) dnsmessage.Resource {
+	var a [4]byte
+	copy(a[:], ip.To4())
+	return dnsmessage.Resource{
+		Header: dnsmessage.ResourceHeader{Name: mustName(name), Type: dnsmessage.TypeA, Class: dnsmessage.ClassINET},
+		Body:   &dnsmessage.AResource{A: a},
+	}
+}
+
+func srvRecord(name, target string) dnsmessage.Resource {
+	return dnsmessage.Resource{
+		Header: dnsmessage.ResourceHeader{Name: mustName(name), Type: dnsmessage.TypeSRV, Class: dnsmessage.ClassINET},
+		Body:   &dnsmessage.SRVResource{Target: mustName(target), Port: 80},
+	}
+}
+
+func packResponse(t *testing.T, answers, additionals []dnsmessage.Resource) []byte {
+	t.Helper()
+	msg := dnsmessage.Message{
+		Header:      dnsmessage.Header{Response: true, Authoritative: true},
+		Answers:     answers,
+		Additionals: additionals,
+	}
+	data, err := msg.Pack()
+	require.NoError(t, err)
+	return data
+}
+
+func TestQuery(t *testing.T) {
+	t.Parallel()
+
+	data, err := query(net.IPv4(192, 168, 0, 10))
+	require.NoError(t, err)
+	var msg dnsmessage.Message
+	require.NoError(t, msg.Unpack(data))
+	require.Len(t, msg.Questions, 2)
+	require.Equal(t, ServicesQueryName, msg.Questions[0].Name.String())
+	require.Equal(t, dnsmessage.TypePTR, msg.Questions[0].Type)
+	require.Equal(t, dnsmessage.ClassINET|unicastResponseBit, msg.Questions[0].Class)
+	require.Equal(t, "10.0.168.192.in-addr.arpa.", msg.Questions[1].Name.String())
+
+	data, err = query(nil)
+	require.NoError(t, err)
+	require.NoError(t, msg.Unpack(data))
+	require.Len(t, msg.Questions, 1)
+}
+
+func TestParseResponse(t *testing.T) {
+	t.Parallel()
+	ip := net.IPv4(192, 168, 0, 10)
+	tests := []struct {
+		name        string
+		answers     []dnsmessage.Resource
+		additionals []dnsmessage.Resource
+		expected    *response
+	}{
+		{
+			name: "ReverseHostname",
+			answers: []dnsmessage.Resource{
+				ptrRecord(ServicesQueryName, "_http._tcp.local."),
+				ptrRecord(ServicesQueryName, "_ipp._tcp.local."),
+				ptrRecord("10.0.168.192.in-addr.arpa.", "printer.local."),
+			},
+			additionals: []dnsmessage.Resource{
+				aRecord("other.local.", ip),
+			},
+			expected: &response{
+				services: []string{"_http._tcp.local.", "_ipp._tcp.local."},
+				hostname: "printer.local.",
+			},
+		},
+		{
+			name:    "AddressHostname",
+			answers: []dnsmessage.Resource{ptrRecord(ServicesQueryName, "_airplay._tcp.local.")},
+			additionals: []dnsmessage.Resource{
+				srvRecord("tv._airplay._tcp.local.", "tv-srv.local."),
+				aRecord("neighbor.local.", net.IPv4(192, 168, 0, 11)),
+				aRecord("tv.local.", ip),
+			},
+			expected: &response{
+				services: []string{"_airplay._tcp.local."},
+				hostname: "tv.local.",
+			},
+		},
+		{
+			name:        "SRVHostname",
+			additionals: []dnsmessage.Resource{srvRecord("nas._smb._tcp.local.", "nas.local.")},
+			expected:    &response{hostname: "nas.local."},
+		},
+	}
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			resp, err := parseResponse(packResponse(t, tt.answers, tt.additionals), ip)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, resp)
+		})
+	}
+}
+
+func TestParseResponseError(t *testing.T) {
+	t.Parallel()
+
+	data, err := query(nil)
+	require.NoError(t, err)
+	_, err = parseResponse(data, net.IPv4(192, 168, 0, 10))
+	require.ErrorIs(t, err, errResponse)
+
+	_, err = parseResponse([]byte{0x1, 0x2}, net.IPv4(192, 168, 0, 10))
+	require.Error(t, err)
+}
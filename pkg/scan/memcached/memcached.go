@@ -0,0 +1,198 @@
+package memcached
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+const (
+	ScanType = "memcached"
+
+	defaultDialTimeout = 2 * time.Second
+	defaultDataTimeout = 2 * time.Second
+	maxPacketSize      = 1500
+)
+
+type ScanResult struct {
+	ScanType string `json:"scan"`
+	IP       string `json:"ip"`
+	Port     uint16 `json:"port"`
+	// Version is the version of the server, e.g. 1.6.9
+	Version string `json:"version"`
+	// CurrItems is the number of items stored by the server
+	CurrItems uint64 `json:"curr_items"`
+	// TCP is set if the server answered the stats command over TCP
+	TCP bool `json:"tcp"`
+	// UDP is set if the server answered the stats command over UDP, such servers can be abused for amplification attacks
+	UDP        bool `json:"udp"`
+	UDPPackets int  `json:"udp_packets,omitempty"`
+	UDPBytes   int  `json:"udp_bytes,omitempty"`
+}
+
+func (r *ScanResult) String() string {
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "%-20s %-5d %s items %d", r.IP, r.Port, r.Version, r.CurrItems)
+	if r.TCP {
+		buf.WriteString(" tcp")
+	}
+	if r.UDP {
+		fmt.Fprintf(&buf, " udp %d packets %d bytes", r.UDPPackets, r.UDPBytes)
+	}
+	return buf.String()
+}
+
+func (r *ScanResult) ID() string {
+	return fmt.Sprintf("%s:%d", r.IP, r.Port)
+}
+
+func (r *ScanResult) MarshalJSON() ([]byte, error) {
+	// Type definition for the recursive call
+	type JScanResult ScanResult
+	// This works because JScanResult doesn't have a MarshalJSON function associated with it
+	return json.Marshal(JScanResult(*r))
+}
+
+// Scanner sends the stats command to each target over TCP and over UDP in the UDP frame,
+// targets that answered any of them are reported
+type Scanner struct {
+	dialer      *net.Dialer
+	dataTimeout time.Duration
+}
+
+// Assert that memcached.Scanner conforms to the scan.Scanner interface
+var _ scan.Scanner = (*Scanner)(nil)
+
+type ScannerOption func(*Scanner)
+
+func WithDialTimeout(timeout time.Duration) ScannerOption {
+	return func(s *Scanner) {
+		s.dialer.Timeout = timeout
+	}
+}
+
+// WithDataTimeout sets the time to wait for the TCP response and for UDP datagrams
+func WithDataTimeout(timeout time.Duration) ScannerOption {
+	return func(s *Scanner) {
+		s.dataTimeout = timeout
+	}
+}
+
+func NewScanner(opts ...ScannerOption) *Scanner {
+	s := &Scanner{
+		dialer: &net.Dialer{
+			Timeout: defaultDialTimeout,
+		},
+		dataTimeout: defaultDataTimeout,
+	}
+	for _, o := range opts {
+		o(s)
+	}
+	return s
+}
+
+func (s *Scanner) Scan(ctx context.Context, r *scan.Request) (scan.Result, error) {
+	result, err := s.scanTarget(ctx, r)
+	return result, scan.WrapError(err)
+}
+
+func (s *Scanner) scanTarget(ctx context.Context, r *scan.Request) (scan.Result, error) {
+	addr := net.JoinHostPort(r.DstIP.String(), strconv.Itoa(int(r.DstPort)))
+	res := &ScanResult{
+		ScanType: ScanType,
+		IP:       r.DstIP.String(),
+		Port:     r.DstPort,
+	}
+	stats, tcpErr := s.tcpStats(ctx, addr)
+	if tcpErr == nil {
+		res.TCP = true
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	udpStats, packets, size, err := s.udpStats(ctx, addr)
+	if err != nil {
+		return nil, err
+	}
+	if packets > 0 {
+		res.UDP = true
+		res.UDPPackets = packets
+		res.UDPBytes = size
+	}
+	if !res.TCP && !res.UDP {
+		return nil, tcpErr
+	}
+	if stats == nil {
+		stats = udpStats
+	}
+	res.Version = stats["version"]
+	res.CurrItems, _ = strconv.ParseUint(stats["curr_items"], 10, 64)
+	return res, nil
+}
+
+func (s *Scanner) tcpStats(ctx context.Context, addr string) (map[string]string, error) {
+	conn, err := s.dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	if err = conn.SetDeadline(time.Now().Add(s.dataTimeout)); err != nil {
+		return nil, err
+	}
+	if _, err = conn.Write([]byte(statsCommand)); err != nil {
+		return nil, err
+	}
+	return readStats(bufio.NewReaderSize(conn, maxLineLength))
+}
+
+// udpStats sends the stats command over UDP and reads response datagrams until the last one,
+// stats are nil if no datagrams arrived or some of them were lost
+func (s *Scanner) udpStats(ctx context.Context, addr string) (stats map[string]string, packets, size int, err error) {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "udp", addr)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	requestID := uint16(rand.Uint32())
+	if _, err = conn.Write(udpRequest(requestID)); err != nil {
+		return
+	}
+	if err = conn.SetReadDeadline(time.Now().Add(s.dataTimeout)); err != nil {
+		return
+	}
+	frames := make(map[uint16]*udpFrame)
+	buf := make([]byte, maxPacketSize)
+	for {
+		n, rerr := conn.Read(buf)
+		// timeouts and ICMP port unreachable errors mean no answer
+		if rerr != nil {
+			break
+		}
+		frame, ok := parseUDPFrame(buf[:n])
+		if !ok || frame.requestID != requestID {
+			continue
+		}
+		packets++
+		size += n
+		frame.payload = append([]byte(nil), frame.payload...)
+		frames[frame.seq] = frame
+		if len(frames) == int(frame.total) {
+			break
+		}
+	}
+	if data, ok := joinUDPFrames(frames); ok {
+		stats, _ = readStats(bufio.NewReader(bytes.NewReader(data)))
+	}
+	return stats, packets, size, nil
+}
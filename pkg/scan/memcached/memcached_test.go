@@ -0,0 +1,151 @@
+package memcached
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+const testStats = "STAT pid 1\r\nSTAT version 1.6.9\r\nSTAT curr_items 42\r\nEND\r\n"
+
+// serveTCP replies to the stats command with the response
+func serveTCP(t *testing.T, addr, response string) *net.TCPAddr {
+	t.Helper()
+	l, err := net.Listen("tcp", addr)
+	require.NoError(t, err)
+	t.Cleanup(func() { l.Close() })
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		if _, err := bufio.NewReader(conn).ReadString('\n'); err != nil {
+			return
+		}
+		_, _ = conn.Write([]byte(response))
+	}()
+	return l.Addr().(*net.TCPAddr)
+}
+
+// serveUDP replies to the stats request with datagrams of the response split into parts,
+// empty parts are lost datagrams
+func serveUDP(t *testing.T, addr string, parts ...string) *net.UDPAddr {
+	t.Helper()
+	conn, err := net.ListenPacket("udp", addr)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	go func() {
+		buf := make([]byte, maxPacketSize)
+		n, raddr, err := conn.ReadFrom(buf)
+		if err != nil || n < udpHeaderSize {
+			return
+		}
+		for i, part := range parts {
+			if len(part) == 0 {
+				continue
+			}
+			frame := make([]byte, udpHeaderSize)
+			copy(frame, buf[:2])
+			binary.BigEndian.PutUint16(frame[2:], uint16(i))
+			binary.BigEndian.PutUint16(frame[4:], uint16(len(parts)))
+			if _, err := conn.WriteTo(append(frame, part...), raddr); err != nil {
+				return
+			}
+		}
+	}()
+	return conn.LocalAddr().(*net.UDPAddr)
+}
+
+func TestScan(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		tcp      bool
+		udp      []string
+		expected *ScanResult
+	}{
+		{
+			name:     "TCP",
+			tcp:      true,
+			expected: &ScanResult{Version: "1.6.9", CurrItems: 42, TCP: true},
+		},
+		{
+			name: "TCPAndUDP",
+			tcp:  true,
+			udp:  []string{"STAT pid 1\r\nSTAT version 1.6.9\r\n", "STAT curr_items 42\r\nEND\r\n"},
+			expected: &ScanResult{Version: "1.6.9", CurrItems: 42, TCP: true,
+				UDP: true, UDPPackets: 2, UDPBytes: len(testStats) + 2*udpHeaderSize},
+		},
+		{
+			name: "UDP",
+			udp:  []string{testStats},
+			expected: &ScanResult{Version: "1.6.9", CurrItems: 42,
+				UDP: true, UDPPackets: 1, UDPBytes: len(testStats) + udpHeaderSize},
+		},
+		{
+			name:     "UDPLostDatagram",
+			udp:      []string{"STAT pid 1\r\n", ""},
+			expected: &ScanResult{UDP: true, UDPPackets: 1, UDPBytes: len("STAT pid 1\r\n") + udpHeaderSize},
+		},
+	}
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			// reserve the port for both protocols
+			addr := serveUDP(t, "127.0.0.1:0", tt.udp...)
+			if tt.tcp {
+				serveTCP(t, addr.String(), testStats)
+			}
+			req := &scan.Request{DstIP: addr.IP, DstPort: uint16(addr.Port)}
+			s := NewScanner(WithDataTimeout(200 * time.Millisecond))
+			result, err := s.Scan(context.Background(), req)
+			require.NoError(t, err)
+
+			tt.expected.ScanType = ScanType
+			tt.expected.IP = req.DstIP.String()
+			tt.expected.Port = req.DstPort
+			require.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestScanNotMemcachedServer(t *testing.T) {
+	t.Parallel()
+	addr := serveTCP(t, "127.0.0.1:0", "SSH-2.0-OpenSSH_8.4p1\r\n")
+	s := NewScanner(WithDataTimeout(100 * time.Millisecond))
+	result, err := s.Scan(context.Background(), &scan.Request{DstIP: addr.IP, DstPort: uint16(addr.Port)})
+	require.ErrorIs(t, err, errProtocol)
+	require.Nil(t, result)
+}
+
+func TestScanTimeout(t *testing.T) {
+	t.Parallel()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+	done := make(chan interface{})
+	defer close(done)
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		<-done
+	}()
+	addr := l.Addr().(*net.TCPAddr)
+
+	s := NewScanner(WithDataTimeout(50 * time.Millisecond))
+	result, err := s.Scan(context.Background(), &scan.Request{DstIP: addr.IP, DstPort: uint16(addr.Port)})
+	require.Error(t, err)
+	require.ErrorIs(t, err, scan.ErrTimeout)
+	require.Nil(t, result)
+}
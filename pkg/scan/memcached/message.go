@@ -0,0 +1,118 @@
+package memcached
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+// memcached text protocol, see https://github.com/memcached/memcached/blob/master/doc/protocol.txt
+const (
+	statsCommand = "stats\r\n"
+	statsEnd     = "END"
+	statPrefix   = "STAT "
+	// udpHeaderSize is the size of the frame header of each UDP datagram
+	udpHeaderSize = 8
+	maxLineLength = 4096
+	maxStats      = 1024
+)
+
+var errProtocol = scan.NewError(scan.ErrParse, errors.New("invalid memcached response"))
+
+// udpFrame is the frame header of UDP datagrams followed by the part of the text protocol message
+type udpFrame struct {
+	requestID uint16
+	seq       uint16
+	total     uint16
+	payload   []byte
+}
+
+// udpRequest frames the stats command into the single UDP datagram
+func udpRequest(requestID uint16) []byte {
+	data := make([]byte, udpHeaderSize, udpHeaderSize+len(statsCommand))
+	binary.BigEndian.PutUint16(data, requestID)
+	// sequence number is 0 and the request consists of 1 datagram
+	binary.BigEndian.PutUint16(data[4:], 1)
+	return append(data, statsCommand...)
+}
+
+func parseUDPFrame(data []byte) (*udpFrame, bool) {
+	if len(data) < udpHeaderSize {
+		return nil, false
+	}
+	frame := &udpFrame{
+		requestID: binary.BigEndian.Uint16(data),
+		seq:       binary.BigEndian.Uint16(data[2:]),
+		total:     binary.BigEndian.Uint16(data[4:]),
+		payload:   data[udpHeaderSize:],
+	}
+	if frame.total == 0 || frame.seq >= frame.total {
+		return nil, false
+	}
+	return frame, true
+}
+
+// joinUDPFrames joins payloads of all datagrams of the response in sequence order,
+// false is returned if some datagrams are missing
+func joinUDPFrames(frames map[uint16]*udpFrame) ([]byte, bool) {
+	if len(frames) == 0 {
+		return nil, false
+	}
+	seqs := make([]int, 0, len(frames))
+	var total uint16
+	for seq, frame := range frames {
+		seqs = append(seqs, int(seq))
+		total = frame.total
+	}
+	if len(frames) != int(total) {
+		return nil, false
+	}
+	sort.Ints(seqs)
+	var buf bytes.Buffer
+	for _, seq := range seqs {
+		buf.Write(frames[uint16(seq)].payload)
+	}
+	return buf.Bytes(), true
+}
+
+// readStats reads STAT lines of the stats response until END
+func readStats(r *bufio.Reader) (map[string]string, error) {
+	stats := make(map[string]string)
+	for {
+		line, err := readLine(r)
+		if err != nil {
+			return nil, err
+		}
+		if line == statsEnd {
+			return stats, nil
+		}
+		if !strings.HasPrefix(line, statPrefix) {
+			return nil, fmt.Errorf("%w: %q", errProtocol, line)
+		}
+		name, value, _ := strings.Cut(line[len(statPrefix):], " ")
+		if len(stats) < maxStats {
+			stats[name] = value
+		}
+	}
+}
+
+func readLine(r *bufio.Reader) (string, error) {
+	line, err := r.ReadSlice('\n')
+	if errors.Is(err, bufio.ErrBufferFull) {
+		return "", fmt.Errorf("%w: line too long", errProtocol)
+	}
+	if err != nil {
+		if errors.Is(err, io.EOF) && len(line) > 0 {
+			err = io.ErrUnexpectedEOF
+		}
+		return "", err
+	}
+	return strings.TrimRight(string(line), "\r\n"), nil
+}
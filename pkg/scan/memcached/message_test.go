@@ -0,0 +1,85 @@
+package memcached
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUDPRequest(t *testing.T) {
+	t.Parallel()
+	require.Equal(t, append([]byte{0x12, 0x34, 0, 0, 0, 1, 0, 0}, "stats\r\n"...), udpRequest(0x1234))
+}
+
+func TestParseUDPFrame(t *testing.T) {
+	t.Parallel()
+	frame, ok := parseUDPFrame(append([]byte{0x12, 0x34, 0, 1, 0, 2, 0, 0}, "END\r\n"...))
+	require.True(t, ok)
+	require.Equal(t, &udpFrame{requestID: 0x1234, seq: 1, total: 2, payload: []byte("END\r\n")}, frame)
+
+	_, ok = parseUDPFrame([]byte{0x12, 0x34, 0, 0})
+	require.False(t, ok)
+	_, ok = parseUDPFrame([]byte{0x12, 0x34, 0, 2, 0, 2, 0, 0})
+	require.False(t, ok)
+}
+
+func TestJoinUDPFrames(t *testing.T) {
+	t.Parallel()
+	frames := map[uint16]*udpFrame{
+		1: {seq: 1, total: 2, payload: []byte("END\r\n")},
+		0: {seq: 0, total: 2, payload: []byte("STAT pid 1\r\n")},
+	}
+	data, ok := joinUDPFrames(frames)
+	require.True(t, ok)
+	require.Equal(t, "STAT pid 1\r\nEND\r\n", string(data))
+
+	delete(frames, 0)
+	_, ok = joinUDPFrames(frames)
+	require.False(t, ok)
+
+	_, ok = joinUDPFrames(nil)
+	require.False(t, ok)
+}
+
+func TestReadStats(t *testing.T) {
+	t.Parallel()
+	stats, err := readStats(bufio.NewReader(strings.NewReader(
+		"STAT pid 1\r\nSTAT version 1.6.9\r\nSTAT curr_items 42\r\nEND\r\n")))
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"pid": "1", "version": "1.6.9", "curr_items": "42"}, stats)
+}
+
+func TestReadStatsError(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{
+			name:  "Error",
+			input: "ERROR\r\n",
+		},
+		{
+			name:  "NotMemcached",
+			input: "SSH-2.0-OpenSSH_8.4p1\r\n",
+		},
+		{
+			name:  "Truncated",
+			input: "STAT pid 1\r\nSTAT version",
+		},
+		{
+			name:  "LongLine",
+			input: "STAT version " + strings.Repeat("1", maxLineLength) + "\r\n",
+		},
+	}
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := readStats(bufio.NewReaderSize(strings.NewReader(tt.input), maxLineLength))
+			require.Error(t, err)
+		})
+	}
+}
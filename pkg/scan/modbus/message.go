@@ -0,0 +1,122 @@
+package modbus
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+// Modbus/TCP application protocol header, see Modbus Messaging on TCP/IP Implementation Guide section 3.1.3
+const (
+	mbapHeaderSize = 7
+	protocolModbus = 0
+	// maxADUSize is the maximum size of the Modbus/TCP message
+	maxADUSize = 260
+)
+
+// Read Device Identification request, see Modbus Application Protocol Specification section 6.21
+const (
+	funcEncapsulatedInterface = 0x2b
+	meiReadDeviceID           = 0x0e
+	readDeviceIDBasic         = 0x01
+	exceptionFlag             = 0x80
+	moreFollows               = 0xff
+
+	objectVendorName  = 0x00
+	objectProductCode = 0x01
+	objectRevision    = 0x02
+)
+
+var errInvalidResponse = scan.NewError(scan.ErrParse, errors.New("invalid Modbus response"))
+
+// exceptionNames are names of Modbus exception codes
+var exceptionNames = map[byte]string{
+	0x01: "illegal function",
+	0x02: "illegal data address",
+	0x03: "illegal data value",
+	0x04: "server device failure",
+	0x06: "server device busy",
+	0x0a: "gateway path unavailable",
+	0x0b: "gateway target device failed to respond",
+}
+
+type deviceID struct {
+	objects map[byte]string
+	more    bool
+	nextID  byte
+}
+
+// exceptionError is the exception response of the device
+type exceptionError struct {
+	code byte
+}
+
+func (e *exceptionError) Error() string {
+	return "Modbus exception: " + exceptionName(e.code)
+}
+
+func exceptionName(code byte) string {
+	if name, ok := exceptionNames[code]; ok {
+		return name
+	}
+	return fmt.Sprintf("code %#x", code)
+}
+
+// readDeviceIDRequest returns the Read Device Identification request of basic objects from the object id
+func readDeviceIDRequest(transactionID uint16, unitID, objectID byte) []byte {
+	pdu := []byte{funcEncapsulatedInterface, meiReadDeviceID, readDeviceIDBasic, objectID}
+	packet := make([]byte, 0, mbapHeaderSize+len(pdu))
+	packet = binary.BigEndian.AppendUint16(packet, transactionID)
+	packet = binary.BigEndian.AppendUint16(packet, protocolModbus)
+	packet = binary.BigEndian.AppendUint16(packet, uint16(len(pdu)+1))
+	packet = append(packet, unitID)
+	return append(packet, pdu...)
+}
+
+// readResponse reads the Modbus/TCP message and returns its transaction id and PDU
+func readResponse(r io.Reader) (transactionID uint16, pdu []byte, err error) {
+	header := make([]byte, mbapHeaderSize)
+	if _, err = io.ReadFull(r, header); err != nil {
+		return
+	}
+	if binary.BigEndian.Uint16(header[2:4]) != protocolModbus {
+		return 0, nil, fmt.Errorf("%w: unknown protocol id", errInvalidResponse)
+	}
+	length := int(binary.BigEndian.Uint16(header[4:6]))
+	if length < 2 || mbapHeaderSize-1+length > maxADUSize {
+		return 0, nil, fmt.Errorf("%w: invalid length %d", errInvalidResponse, length)
+	}
+	pdu = make([]byte, length-1)
+	if _, err = io.ReadFull(r, pdu); err != nil {
+		return
+	}
+	return binary.BigEndian.Uint16(header[0:2]), pdu, nil
+}
+
+// parseDeviceID parses the PDU of the Read Device Identification response
+func parseDeviceID(pdu []byte) (*deviceID, error) {
+	if len(pdu) == 2 && pdu[0] == funcEncapsulatedInterface|exceptionFlag {
+		return nil, &exceptionError{code: pdu[1]}
+	}
+	// function, MEI type, read device id code, conformity level, more follows, next object id, number of objects
+	if len(pdu) < 7 || pdu[0] != funcEncapsulatedInterface || pdu[1] != meiReadDeviceID {
+		return nil, errInvalidResponse
+	}
+	result := &deviceID{
+		objects: make(map[byte]string),
+		more:    pdu[4] == moreFollows,
+		nextID:  pdu[5],
+	}
+	data := pdu[7:]
+	for i := 0; i < int(pdu[6]); i++ {
+		if len(data) < 2 || len(data) < 2+int(data[1]) {
+			return nil, fmt.Errorf("%w: truncated object", errInvalidResponse)
+		}
+		result.objects[data[0]] = string(data[2 : 2+int(data[1])])
+		data = data[2+int(data[1]):]
+	}
+	return result, nil
+}
@@ -0,0 +1,82 @@
+package modbus
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// deviceIDResponse returns the PDU of the Read Device Identification response with objects in the order of ids
+func deviceIDResponse(more bool, nextID byte, objects map[byte]string) []byte {
+	pdu := []byte{funcEncapsulatedInterface, meiReadDeviceID, readDeviceIDBasic, 0x81, 0, nextID, byte(len(objects))}
+	if more {
+		pdu[4] = moreFollows
+	}
+	for id := 0; id < 256; id++ {
+		if value, ok := objects[byte(id)]; ok {
+			pdu = append(pdu, byte(id), byte(len(value)))
+			pdu = append(pdu, value...)
+		}
+	}
+	return pdu
+}
+
+func adu(transactionID uint16, unitID byte, pdu []byte) []byte {
+	packet := binary.BigEndian.AppendUint16(nil, transactionID)
+	packet = binary.BigEndian.AppendUint16(packet, protocolModbus)
+	packet = binary.BigEndian.AppendUint16(packet, uint16(len(pdu)+1))
+	packet = append(packet, unitID)
+	return append(packet, pdu...)
+}
+
+func TestReadDeviceIDRequest(t *testing.T) {
+	t.Parallel()
+	require.Equal(t, []byte{0x00, 0x01, 0x00, 0x00, 0x00, 0x05, 0xff, 0x2b, 0x0e, 0x01, 0x00},
+		readDeviceIDRequest(1, 255, 0))
+}
+
+func TestReadResponse(t *testing.T) {
+	t.Parallel()
+	id, pdu, err := readResponse(bytes.NewReader(adu(7, 1, []byte{0xab, 0x01})))
+	require.NoError(t, err)
+	require.Equal(t, uint16(7), id)
+	require.Equal(t, []byte{0xab, 0x01}, pdu)
+
+	packet := adu(7, 1, []byte{0xab, 0x01})
+	packet[3] = 1
+	_, _, err = readResponse(bytes.NewReader(packet))
+	require.ErrorIs(t, err, errInvalidResponse)
+
+	packet = adu(7, 1, []byte{0xab, 0x01})
+	binary.BigEndian.PutUint16(packet[4:6], 1000)
+	_, _, err = readResponse(bytes.NewReader(packet))
+	require.ErrorIs(t, err, errInvalidResponse)
+}
+
+func TestParseDeviceID(t *testing.T) {
+	t.Parallel()
+	objects := map[byte]string{objectVendorName: "Schneider Electric", objectProductCode: "BMX P34 2020",
+		objectRevision: "v2.8"}
+	resp, err := parseDeviceID(deviceIDResponse(false, 0, objects))
+	require.NoError(t, err)
+	require.Equal(t, &deviceID{objects: objects}, resp)
+
+	resp, err = parseDeviceID(deviceIDResponse(true, 2, map[byte]string{objectVendorName: "ACME"}))
+	require.NoError(t, err)
+	require.True(t, resp.more)
+	require.Equal(t, byte(2), resp.nextID)
+
+	_, err = parseDeviceID([]byte{0xab, 0x01})
+	var exception *exceptionError
+	require.ErrorAs(t, err, &exception)
+	require.Equal(t, "Modbus exception: illegal function", exception.Error())
+
+	pdu := deviceIDResponse(false, 0, objects)
+	_, err = parseDeviceID(pdu[:len(pdu)-1])
+	require.ErrorIs(t, err, errInvalidResponse)
+
+	_, err = parseDeviceID([]byte{0x03, 0x02, 0x00, 0x00, 0x00, 0x00, 0x00})
+	require.ErrorIs(t, err, errInvalidResponse)
+}
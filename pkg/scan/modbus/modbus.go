@@ -0,0 +1,210 @@
+package modbus
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+const (
+	ScanType = "modbus"
+
+	defaultDialTimeout = 2 * time.Second
+	defaultDataTimeout = 2 * time.Second
+
+	// maxRequests limits requests of one unit if the device answers with more follows
+	maxRequests = 4
+)
+
+// DefaultUnitIDs are unit ids tried in order, devices usually answer 255 and gateways forward 1 to the serial device
+var DefaultUnitIDs = []uint8{255, 1}
+
+type ScanResult struct {
+	ScanType string `json:"scan"`
+	IP       string `json:"ip"`
+	Port     uint16 `json:"port"`
+	// UnitID is the unit id the device answered
+	UnitID      uint8  `json:"unit_id"`
+	Vendor      string `json:"vendor,omitempty"`
+	ProductCode string `json:"product_code,omitempty"`
+	Revision    string `json:"revision,omitempty"`
+	// Exception is the exception of the device that doesn't support device identification
+	Exception string `json:"exception,omitempty"`
+}
+
+func (r *ScanResult) String() string {
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "%-20s %-5d unit %d", r.IP, r.Port, r.UnitID)
+	if len(r.Exception) > 0 {
+		fmt.Fprintf(&buf, " exception %q", r.Exception)
+		return buf.String()
+	}
+	fmt.Fprintf(&buf, " %q %q %q", r.Vendor, r.ProductCode, r.Revision)
+	return buf.String()
+}
+
+func (r *ScanResult) ID() string {
+	return fmt.Sprintf("%s:%d", r.IP, r.Port)
+}
+
+func (r *ScanResult) MarshalJSON() ([]byte, error) {
+	// Type definition for the recursive call
+	type JScanResult ScanResult
+	// This works because JScanResult doesn't have a MarshalJSON function associated with it
+	return json.Marshal(JScanResult(*r))
+}
+
+// Scanner sends the Read Device Identification request with each unit id until the device answers it.
+// Devices that answer only with exceptions are reported with the exception of the first unit id.
+type Scanner struct {
+	dialer      *net.Dialer
+	dataTimeout time.Duration
+	unitIDs     []uint8
+}
+
+// Assert that modbus.Scanner conforms to the scan.Scanner interface
+var _ scan.Scanner = (*Scanner)(nil)
+
+type ScannerOption func(*Scanner)
+
+func WithDialTimeout(timeout time.Duration) ScannerOption {
+	return func(s *Scanner) {
+		s.dialer.Timeout = timeout
+	}
+}
+
+func WithDataTimeout(timeout time.Duration) ScannerOption {
+	return func(s *Scanner) {
+		s.dataTimeout = timeout
+	}
+}
+
+// WithUnitIDs sets unit ids tried in order
+func WithUnitIDs(unitIDs []uint8) ScannerOption {
+	return func(s *Scanner) {
+		s.unitIDs = unitIDs
+	}
+}
+
+func NewScanner(opts ...ScannerOption) *Scanner {
+	s := &Scanner{
+		dialer: &net.Dialer{
+			Timeout: defaultDialTimeout,
+		},
+		dataTimeout: defaultDataTimeout,
+		unitIDs:     DefaultUnitIDs,
+	}
+	for _, o := range opts {
+		o(s)
+	}
+	return s
+}
+
+func (s *Scanner) Scan(ctx context.Context, r *scan.Request) (scan.Result, error) {
+	result, err := s.scanTarget(ctx, r)
+	return result, scan.WrapError(err)
+}
+
+func (s *Scanner) scanTarget(ctx context.Context, r *scan.Request) (result scan.Result, err error) {
+	addr := net.JoinHostPort(r.DstIP.String(), strconv.Itoa(int(r.DstPort)))
+	var conn net.Conn
+	defer func() {
+		if conn != nil {
+			conn.Close()
+		}
+	}()
+
+	var firstException *ScanResult
+	var transactionID uint16
+	for _, unitID := range s.unitIDs {
+		if err = ctx.Err(); err != nil {
+			return nil, err
+		}
+		// devices close connections or ignore requests to unknown units,
+		// the next unit id is tried over the new connection then
+		if conn == nil {
+			if conn, err = s.dialer.DialContext(ctx, "tcp", addr); err != nil {
+				return nil, err
+			}
+		}
+		res := &ScanResult{
+			ScanType: ScanType,
+			IP:       r.DstIP.String(),
+			Port:     r.DstPort,
+			UnitID:   unitID,
+		}
+		objects, rerr := s.readDeviceID(conn, &transactionID, unitID)
+		var exception *exceptionError
+		switch {
+		case rerr == nil:
+			res.Vendor = objects[objectVendorName]
+			res.ProductCode = objects[objectProductCode]
+			res.Revision = objects[objectRevision]
+			return res, nil
+		case errors.As(rerr, &exception):
+			if firstException == nil {
+				res.Exception = exceptionName(exception.code)
+				firstException = res
+			}
+		default:
+			err = rerr
+			conn.Close()
+			conn = nil
+		}
+	}
+	if firstException != nil {
+		return firstException, nil
+	}
+	return nil, err
+}
+
+// readDeviceID reads basic device identification objects of the unit
+func (s *Scanner) readDeviceID(conn net.Conn, transactionID *uint16, unitID uint8) (map[byte]string, error) {
+	if err := conn.SetDeadline(time.Now().Add(s.dataTimeout)); err != nil {
+		return nil, err
+	}
+	objects := make(map[byte]string)
+	var objectID byte
+	for i := 0; i < maxRequests; i++ {
+		*transactionID++
+		if _, err := conn.Write(readDeviceIDRequest(*transactionID, unitID, objectID)); err != nil {
+			return nil, err
+		}
+		pdu, err := s.readPDU(conn, *transactionID)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := parseDeviceID(pdu)
+		if err != nil {
+			return nil, err
+		}
+		for id, value := range resp.objects {
+			objects[id] = value
+		}
+		if !resp.more || resp.nextID <= objectID {
+			break
+		}
+		objectID = resp.nextID
+	}
+	return objects, nil
+}
+
+// readPDU reads responses until the response of the transaction, responses of other transactions are skipped
+func (s *Scanner) readPDU(conn net.Conn, transactionID uint16) ([]byte, error) {
+	for {
+		id, pdu, err := readResponse(conn)
+		if err != nil {
+			return nil, err
+		}
+		if id == transactionID {
+			return pdu, nil
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package modbus
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+type fakeDevice struct {
+	// units are objects of unit ids, requests to other units are answered with the exception
+	// or the connection is closed if closeUnknown is set
+	units        map[byte]map[byte]string
+	exception    byte
+	closeUnknown bool
+	// split makes the device return one object per response
+	split bool
+}
+
+func startFakeDevice(t *testing.T, dev *fakeDevice) *scan.Request {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go dev.serve(conn)
+		}
+	}()
+	addr := ln.Addr().(*net.TCPAddr)
+	return &scan.Request{DstIP: addr.IP, DstPort: uint16(addr.Port)}
+}
+
+func (d *fakeDevice) serve(conn net.Conn) {
+	defer conn.Close()
+	for {
+		req := make([]byte, 11)
+		if _, err := io.ReadFull(conn, req); err != nil {
+			return
+		}
+		transactionID, unitID, objectID := binary.BigEndian.Uint16(req[0:2]), req[6], req[10]
+		objects, ok := d.units[unitID]
+		switch {
+		case !ok && d.closeUnknown:
+			return
+		case !ok:
+			_, _ = conn.Write(adu(transactionID, unitID, []byte{0xab, d.exception}))
+		case d.split && int(objectID) < len(objects)-1:
+			_, _ = conn.Write(adu(transactionID, unitID, deviceIDResponse(true, objectID+1,
+				map[byte]string{objectID: objects[objectID]})))
+		case d.split:
+			_, _ = conn.Write(adu(transactionID, unitID, deviceIDResponse(false, 0,
+				map[byte]string{objectID: objects[objectID]})))
+		default:
+			_, _ = conn.Write(adu(transactionID, unitID, deviceIDResponse(false, 0, objects)))
+		}
+	}
+}
+
+var plcObjects = map[byte]string{objectVendorName: "Schneider Electric", objectProductCode: "BMX P34 2020",
+	objectRevision: "v2.8"}
+
+func TestScan(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		dev      *fakeDevice
+		opts     []ScannerOption
+		expected *ScanResult
+	}{
+		{
+			name: "UnitID255",
+			dev:  &fakeDevice{units: map[byte]map[byte]string{255: plcObjects}},
+			expected: &ScanResult{UnitID: 255, Vendor: "Schneider Electric", ProductCode: "BMX P34 2020",
+				Revision: "v2.8"},
+		},
+		{
+			name: "UnitID1",
+			dev:  &fakeDevice{units: map[byte]map[byte]string{1: plcObjects}, exception: 0x0b},
+			expected: &ScanResult{UnitID: 1, Vendor: "Schneider Electric", ProductCode: "BMX P34 2020",
+				Revision: "v2.8"},
+		},
+		{
+			name: "ClosedUnknownUnit",
+			dev:  &fakeDevice{units: map[byte]map[byte]string{1: plcObjects}, closeUnknown: true},
+			expected: &ScanResult{UnitID: 1, Vendor: "Schneider Electric", ProductCode: "BMX P34 2020",
+				Revision: "v2.8"},
+		},
+		{
+			name: "MoreFollows",
+			dev:  &fakeDevice{units: map[byte]map[byte]string{255: plcObjects}, split: true},
+			expected: &ScanResult{UnitID: 255, Vendor: "Schneider Electric", ProductCode: "BMX P34 2020",
+				Revision: "v2.8"},
+		},
+		{
+			name:     "UnitIDs",
+			dev:      &fakeDevice{units: map[byte]map[byte]string{0: plcObjects, 255: {objectVendorName: "ACME"}}},
+			opts:     []ScannerOption{WithUnitIDs([]uint8{0})},
+			expected: &ScanResult{Vendor: "Schneider Electric", ProductCode: "BMX P34 2020", Revision: "v2.8"},
+		},
+		{
+			name:     "Exception",
+			dev:      &fakeDevice{exception: 0x01},
+			expected: &ScanResult{UnitID: 255, Exception: "illegal function"},
+		},
+	}
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			req := startFakeDevice(t, tt.dev)
+			opts := append([]ScannerOption{WithDataTimeout(time.Second)}, tt.opts...)
+			result, err := NewScanner(opts...).Scan(context.Background(), req)
+			require.NoError(t, err)
+
+			expected := tt.expected
+			expected.ScanType = ScanType
+			expected.IP = req.DstIP.String()
+			expected.Port = req.DstPort
+			require.Equal(t, expected, result)
+		})
+	}
+}
+
+func TestScanNotModbus(t *testing.T) {
+	t.Parallel()
+	req := startFakeDevice(t, &fakeDevice{closeUnknown: true})
+	_, err := NewScanner().Scan(context.Background(), req)
+	require.Error(t, err)
+}
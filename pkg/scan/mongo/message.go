@@ -0,0 +1,292 @@
+package mongo
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"time"
+
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+// MongoDB wire protocol fields, see https://www.mongodb.com/docs/manual/reference/mongodb-wire-protocol/
+const (
+	headerSize     = 16
+	opMsg          = 2013
+	sectionBody    = 0
+	maxMessageSize = 1 << 20
+)
+
+// BSON element types, see https://bsonspec.org/spec.html
+const (
+	bsonDouble     = 0x01
+	bsonString     = 0x02
+	bsonDocument   = 0x03
+	bsonArray      = 0x04
+	bsonBinary     = 0x05
+	bsonUndefined  = 0x06
+	bsonObjectID   = 0x07
+	bsonBool       = 0x08
+	bsonDateTime   = 0x09
+	bsonNull       = 0x0A
+	bsonInt32      = 0x10
+	bsonTimestamp  = 0x11
+	bsonInt64      = 0x12
+	bsonDecimal128 = 0x13
+	bsonMinKey     = 0xFF
+	bsonMaxKey     = 0x7F
+)
+
+var (
+	errMessage  = scan.NewError(scan.ErrParse, errors.New("invalid MongoDB message"))
+	errDocument = scan.NewError(scan.ErrParse, errors.New("invalid BSON document"))
+)
+
+// element is a key-value pair of the BSON document, the order of elements matters
+// since the first key of the command document is the command name
+type element struct {
+	key   string
+	value interface{}
+}
+
+// document is a decoded BSON document
+type document map[string]interface{}
+
+// encodeDocument encodes elements with int32, bool or string values into BSON
+func encodeDocument(elements ...element) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.Write(make([]byte, 4))
+	for _, e := range elements {
+		switch v := e.value.(type) {
+		case int32:
+			buf.WriteByte(bsonInt32)
+			writeCString(&buf, e.key)
+			_ = binary.Write(&buf, binary.LittleEndian, v)
+		case bool:
+			buf.WriteByte(bsonBool)
+			writeCString(&buf, e.key)
+			if v {
+				buf.WriteByte(1)
+			} else {
+				buf.WriteByte(0)
+			}
+		case string:
+			buf.WriteByte(bsonString)
+			writeCString(&buf, e.key)
+			_ = binary.Write(&buf, binary.LittleEndian, int32(len(v)+1))
+			writeCString(&buf, v)
+		default:
+			return nil, fmt.Errorf("unsupported BSON value type %T", v)
+		}
+	}
+	buf.WriteByte(0)
+	data := buf.Bytes()
+	binary.LittleEndian.PutUint32(data, uint32(len(data)))
+	return data, nil
+}
+
+func writeCString(buf *bytes.Buffer, s string) {
+	buf.WriteString(s)
+	buf.WriteByte(0)
+}
+
+// writeCommand writes the OP_MSG message with the single body section holding the command document
+func writeCommand(w io.Writer, requestID int32, elements ...element) error {
+	doc, err := encodeDocument(elements...)
+	if err != nil {
+		return err
+	}
+	msg := make([]byte, headerSize+5, headerSize+5+len(doc))
+	binary.LittleEndian.PutUint32(msg[0:], uint32(cap(msg)))
+	binary.LittleEndian.PutUint32(msg[4:], uint32(requestID))
+	binary.LittleEndian.PutUint32(msg[12:], opMsg)
+	// flagBits are zero and followed by the kind of the section
+	msg[headerSize+4] = sectionBody
+	msg = append(msg, doc...)
+	_, err = w.Write(msg)
+	return err
+}
+
+// readReply reads the OP_MSG reply and decodes the document of its body section
+func readReply(r io.Reader) (document, error) {
+	header := make([]byte, headerSize)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, err
+	}
+	length := binary.LittleEndian.Uint32(header)
+	opCode := binary.LittleEndian.Uint32(header[12:])
+	if opCode != opMsg || length < headerSize+5 || length > maxMessageSize {
+		return nil, fmt.Errorf("%w: length %d, opcode %d", errMessage, length, opCode)
+	}
+	body := make([]byte, length-headerSize)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return nil, err
+	}
+	// skip flagBits, the body section is the only one a server sends in replies
+	if body[4] != sectionBody {
+		return nil, fmt.Errorf("%w: section kind %d", errMessage, body[4])
+	}
+	doc, _, err := decodeDocument(body[5:])
+	return doc, err
+}
+
+// decodeDocument decodes the BSON document at the start of data and returns its size
+func decodeDocument(data []byte) (doc document, size int, err error) {
+	if len(data) < 5 {
+		return nil, 0, errDocument
+	}
+	size = int(binary.LittleEndian.Uint32(data))
+	if size < 5 || size > len(data) || data[size-1] != 0 {
+		return nil, 0, errDocument
+	}
+	doc = make(document)
+	elements := data[4 : size-1]
+	for len(elements) > 0 {
+		elementType := elements[0]
+		key, n, err := readCString(elements[1:])
+		if err != nil {
+			return nil, 0, err
+		}
+		elements = elements[1+n:]
+		value, n, err := decodeValue(elementType, elements)
+		if err != nil {
+			return nil, 0, err
+		}
+		doc[key] = value
+		elements = elements[n:]
+	}
+	return doc, size, nil
+}
+
+func readCString(data []byte) (s string, size int, err error) {
+	i := bytes.IndexByte(data, 0)
+	if i < 0 {
+		return "", 0, errDocument
+	}
+	return string(data[:i]), i + 1, nil
+}
+
+// decodeValue decodes the value of the given type and returns its size
+func decodeValue(elementType byte, data []byte) (value interface{}, size int, err error) {
+	fixed := func(n int) error {
+		if len(data) < n {
+			return errDocument
+		}
+		size = n
+		return nil
+	}
+	switch elementType {
+	case bsonDouble:
+		if err = fixed(8); err == nil {
+			value = math.Float64frombits(binary.LittleEndian.Uint64(data))
+		}
+	case bsonString:
+		if err = fixed(4); err != nil {
+			return
+		}
+		length := int(int32(binary.LittleEndian.Uint32(data)))
+		if length < 1 || 4+length > len(data) || data[4+length-1] != 0 {
+			return nil, 0, errDocument
+		}
+		return string(data[4 : 4+length-1]), 4 + length, nil
+	case bsonDocument:
+		return decodeDocument(data)
+	case bsonArray:
+		var doc document
+		if doc, size, err = decodeDocument(data); err != nil {
+			return
+		}
+		// array documents have keys "0", "1", ...
+		array := make([]interface{}, len(doc))
+		for i := range array {
+			array[i] = doc[fmt.Sprint(i)]
+		}
+		value = array
+	case bsonBinary:
+		if err = fixed(5); err != nil {
+			return
+		}
+		length := int(int32(binary.LittleEndian.Uint32(data)))
+		if length < 0 || 5+length > len(data) {
+			return nil, 0, errDocument
+		}
+		return data[5 : 5+length], 5 + length, nil
+	case bsonUndefined, bsonNull, bsonMinKey, bsonMaxKey:
+		return nil, 0, nil
+	case bsonObjectID:
+		if err = fixed(12); err == nil {
+			value = hex.EncodeToString(data[:12])
+		}
+	case bsonBool:
+		if err = fixed(1); err == nil {
+			value = data[0] != 0
+		}
+	case bsonDateTime:
+		if err = fixed(8); err == nil {
+			value = time.UnixMilli(int64(binary.LittleEndian.Uint64(data))).UTC()
+		}
+	case bsonInt32:
+		if err = fixed(4); err == nil {
+			value = int32(binary.LittleEndian.Uint32(data))
+		}
+	case bsonTimestamp:
+		if err = fixed(8); err == nil {
+			value = binary.LittleEndian.Uint64(data)
+		}
+	case bsonInt64:
+		if err = fixed(8); err == nil {
+			value = int64(binary.LittleEndian.Uint64(data))
+		}
+	case bsonDecimal128:
+		if err = fixed(16); err == nil {
+			value = data[:16]
+		}
+	default:
+		err = fmt.Errorf("%w: unsupported element type %#x", errDocument, elementType)
+	}
+	return
+}
+
+// checkReply returns the error of the command reply, the ok field is 1 for successful commands
+func checkReply(reply document) error {
+	if toFloat(reply["ok"]) == 1 {
+		return nil
+	}
+	return &commandError{code: int32(toFloat(reply["code"])), message: toString(reply["errmsg"])}
+}
+
+// commandError is returned by the server for failed commands, e.g. code 13 (Unauthorized)
+type commandError struct {
+	code    int32
+	message string
+}
+
+func (e *commandError) Error() string {
+	return fmt.Sprintf("command failed with code %d: %s", e.code, e.message)
+}
+
+// toFloat converts numeric BSON values to float64
+func toFloat(value interface{}) float64 {
+	switch v := value.(type) {
+	case float64:
+		return v
+	case int32:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case bool:
+		if v {
+			return 1
+		}
+	}
+	return 0
+}
+
+func toString(value interface{}) string {
+	s, _ := value.(string)
+	return s
+}
@@ -0,0 +1,201 @@
+package mongo
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// array is encoded as the BSON array by encodeTestDocument
+type array []interface{}
+
+// encodeTestDocument encodes elements into BSON, unlike encodeDocument it supports
+// nested documents of []element values, arrays and doubles to build server replies
+func encodeTestDocument(t *testing.T, elements ...element) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	buf.Write(make([]byte, 4))
+	for _, e := range elements {
+		switch v := e.value.(type) {
+		case float64:
+			buf.WriteByte(bsonDouble)
+			writeCString(&buf, e.key)
+			_ = binary.Write(&buf, binary.LittleEndian, math.Float64bits(v))
+		case []element:
+			buf.WriteByte(bsonDocument)
+			writeCString(&buf, e.key)
+			buf.Write(encodeTestDocument(t, v...))
+		case array:
+			buf.WriteByte(bsonArray)
+			writeCString(&buf, e.key)
+			items := make([]element, len(v))
+			for i, item := range v {
+				items[i] = element{string(rune('0' + i)), item}
+			}
+			buf.Write(encodeTestDocument(t, items...))
+		default:
+			data, err := encodeDocument(e)
+			require.NoError(t, err)
+			buf.Write(data[4 : len(data)-1])
+		}
+	}
+	buf.WriteByte(0)
+	data := buf.Bytes()
+	binary.LittleEndian.PutUint32(data, uint32(len(data)))
+	return data
+}
+
+func TestEncodeDocument(t *testing.T) {
+	t.Parallel()
+	data, err := encodeDocument(element{"hello", int32(1)}, element{"nameOnly", true}, element{"$db", "admin"})
+	require.NoError(t, err)
+	require.Equal(t, []byte{
+		42, 0, 0, 0,
+		bsonInt32, 'h', 'e', 'l', 'l', 'o', 0, 1, 0, 0, 0,
+		bsonBool, 'n', 'a', 'm', 'e', 'O', 'n', 'l', 'y', 0, 1,
+		bsonString, '$', 'd', 'b', 0, 6, 0, 0, 0, 'a', 'd', 'm', 'i', 'n', 0,
+		0,
+	}, data)
+
+	_, err = encodeDocument(element{"value", 1.5})
+	require.Error(t, err)
+}
+
+func TestDecodeDocument(t *testing.T) {
+	t.Parallel()
+	data := encodeTestDocument(t,
+		element{"ok", 1.0},
+		element{"version", "6.0.3"},
+		element{"maxWireVersion", int32(17)},
+		element{"readOnly", false},
+		element{"databases", array{[]element{{"name", "admin"}}, []element{{"name", "local"}}}},
+	)
+	doc, size, err := decodeDocument(data)
+	require.NoError(t, err)
+	require.Equal(t, len(data), size)
+	require.Equal(t, document{
+		"ok":             1.0,
+		"version":        "6.0.3",
+		"maxWireVersion": int32(17),
+		"readOnly":       false,
+		"databases":      []interface{}{document{"name": "admin"}, document{"name": "local"}},
+	}, doc)
+}
+
+func TestDecodeValue(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name        string
+		elementType byte
+		data        []byte
+		expected    interface{}
+		size        int
+	}{
+		{
+			name:        "Int64",
+			elementType: bsonInt64,
+			data:        []byte{1, 0, 0, 0, 0, 0, 0, 0},
+			expected:    int64(1),
+			size:        8,
+		},
+		{
+			name:        "DateTime",
+			elementType: bsonDateTime,
+			data:        []byte{0xe8, 0x03, 0, 0, 0, 0, 0, 0},
+			expected:    time.Unix(1, 0).UTC(),
+			size:        8,
+		},
+		{
+			name:        "ObjectID",
+			elementType: bsonObjectID,
+			data:        []byte{0x63, 0x8f, 0x1c, 0x2a, 1, 2, 3, 4, 5, 6, 7, 8},
+			expected:    "638f1c2a0102030405060708",
+			size:        12,
+		},
+		{
+			name:        "Binary",
+			elementType: bsonBinary,
+			data:        []byte{2, 0, 0, 0, 0, 0xab, 0xcd},
+			expected:    []byte{0xab, 0xcd},
+			size:        7,
+		},
+		{
+			name:        "Null",
+			elementType: bsonNull,
+			data:        []byte{},
+		},
+	}
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			value, size, err := decodeValue(tt.elementType, tt.data)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, value)
+			require.Equal(t, tt.size, size)
+		})
+	}
+}
+
+func TestDecodeDocumentError(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{
+			name: "Short",
+			data: []byte{5, 0, 0},
+		},
+		{
+			name: "InvalidSize",
+			data: []byte{10, 0, 0, 0, 0},
+		},
+		{
+			name: "TruncatedString",
+			data: []byte{12, 0, 0, 0, bsonString, 'a', 0, 10, 0, 0, 0, 0},
+		},
+		{
+			name: "UnknownType",
+			data: []byte{8, 0, 0, 0, 0x42, 'a', 0, 0},
+		},
+	}
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, _, err := decodeDocument(tt.data)
+			require.ErrorIs(t, err, errDocument)
+		})
+	}
+}
+
+func TestWriteCommandReadReply(t *testing.T) {
+	t.Parallel()
+	var buf bytes.Buffer
+	require.NoError(t, writeCommand(&buf, 7, element{"buildInfo", int32(1)}, element{"$db", "admin"}))
+	require.Equal(t, uint32(buf.Len()), binary.LittleEndian.Uint32(buf.Bytes()))
+	require.Equal(t, uint32(7), binary.LittleEndian.Uint32(buf.Bytes()[4:]))
+
+	doc, err := readReply(&buf)
+	require.NoError(t, err)
+	require.Equal(t, document{"buildInfo": int32(1), "$db": "admin"}, doc)
+}
+
+func TestReadReplyError(t *testing.T) {
+	t.Parallel()
+	_, err := readReply(bytes.NewReader([]byte("HTTP/1.0 400 Bad Request\r\n")))
+	require.ErrorIs(t, err, errMessage)
+}
+
+func TestCheckReply(t *testing.T) {
+	t.Parallel()
+	require.NoError(t, checkReply(document{"ok": 1.0}))
+
+	err := checkReply(document{"ok": 0.0, "code": int32(13), "errmsg": "command listDatabases requires authentication"})
+	require.EqualError(t, err, "command failed with code 13: command listDatabases requires authentication")
+}
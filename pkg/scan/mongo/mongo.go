@@ -0,0 +1,175 @@
+package mongo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+const (
+	ScanType = "mongo"
+
+	defaultDialTimeout = 2 * time.Second
+	defaultDataTimeout = 2 * time.Second
+
+	// adminDB is the database all commands of the scanner run against
+	adminDB = "admin"
+)
+
+type ScanResult struct {
+	ScanType string `json:"scan"`
+	IP       string `json:"ip"`
+	Port     uint16 `json:"port"`
+	// Version is the server version reported by buildInfo, e.g. 6.0.3
+	Version string `json:"version"`
+	// SetName is the name of the replica set the server is a member of
+	SetName string `json:"set_name,omitempty"`
+	// AuthRequired is set if the server refused to list databases without authentication
+	AuthRequired bool `json:"auth_required"`
+	// Databases are names of databases listed without authentication
+	Databases []string `json:"databases,omitempty"`
+}
+
+func (r *ScanResult) String() string {
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "%-20s %-5d %s", r.IP, r.Port, r.Version)
+	if len(r.SetName) > 0 {
+		fmt.Fprintf(&buf, " rs:%s", r.SetName)
+	}
+	if r.AuthRequired {
+		buf.WriteString(" auth")
+	} else {
+		fmt.Fprintf(&buf, " no-auth dbs:%s", strings.Join(r.Databases, ","))
+	}
+	return buf.String()
+}
+
+func (r *ScanResult) ID() string {
+	return fmt.Sprintf("%s:%d", r.IP, r.Port)
+}
+
+func (r *ScanResult) MarshalJSON() ([]byte, error) {
+	// Type definition for the recursive call
+	type JScanResult ScanResult
+	// This works because JScanResult doesn't have a MarshalJSON function associated with it
+	return json.Marshal(JScanResult(*r))
+}
+
+// Scanner sends hello, buildInfo and listDatabases commands in OP_MSG messages
+// to find out the version of MongoDB servers and whether they enforce authentication
+type Scanner struct {
+	dialer      *net.Dialer
+	dataTimeout time.Duration
+}
+
+// Assert that mongo.Scanner conforms to the scan.Scanner interface
+var _ scan.Scanner = (*Scanner)(nil)
+
+type ScannerOption func(*Scanner)
+
+func WithDialTimeout(timeout time.Duration) ScannerOption {
+	return func(s *Scanner) {
+		s.dialer.Timeout = timeout
+	}
+}
+
+func WithDataTimeout(timeout time.Duration) ScannerOption {
+	return func(s *Scanner) {
+		s.dataTimeout = timeout
+	}
+}
+
+func NewScanner(opts ...ScannerOption) *Scanner {
+	s := &Scanner{
+		dialer: &net.Dialer{
+			Timeout: defaultDialTimeout,
+		},
+		dataTimeout: defaultDataTimeout,
+	}
+	for _, o := range opts {
+		o(s)
+	}
+	return s
+}
+
+func (s *Scanner) Scan(ctx context.Context, r *scan.Request) (scan.Result, error) {
+	result, err := s.scanTarget(ctx, r)
+	return result, scan.WrapError(err)
+}
+
+func (s *Scanner) scanTarget(ctx context.Context, r *scan.Request) (scan.Result, error) {
+	addr := net.JoinHostPort(r.DstIP.String(), strconv.Itoa(int(r.DstPort)))
+	conn, err := s.dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	if err = conn.SetDeadline(time.Now().Add(s.dataTimeout)); err != nil {
+		return nil, err
+	}
+	c := &client{conn: conn}
+
+	// hello replaced isMaster in MongoDB 5.0, older servers reject it as an unknown command
+	hello, err := c.command(element{"hello", int32(1)})
+	var cmdErr *commandError
+	if errors.As(err, &cmdErr) {
+		hello, err = c.command(element{"isMaster", int32(1)})
+	}
+	if err != nil {
+		return nil, err
+	}
+	buildInfo, err := c.command(element{"buildInfo", int32(1)})
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ScanResult{
+		ScanType: ScanType,
+		IP:       r.DstIP.String(),
+		Port:     r.DstPort,
+		Version:  toString(buildInfo["version"]),
+		SetName:  toString(hello["setName"]),
+	}
+	databases, err := c.command(element{"listDatabases", int32(1)}, element{"nameOnly", true})
+	if errors.As(err, &cmdErr) {
+		result.AuthRequired = true
+		return result, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	list, _ := databases["databases"].([]interface{})
+	for _, db := range list {
+		if db, ok := db.(document); ok {
+			result.Databases = append(result.Databases, toString(db["name"]))
+		}
+	}
+	return result, nil
+}
+
+type client struct {
+	conn      net.Conn
+	requestID int32
+}
+
+// command runs the command against the admin database, the first element is the command name.
+// Failed commands are reported as commandError.
+func (c *client) command(elements ...element) (document, error) {
+	c.requestID++
+	elements = append(elements, element{"$db", adminDB})
+	if err := writeCommand(c.conn, c.requestID, elements...); err != nil {
+		return nil, err
+	}
+	reply, err := readReply(c.conn)
+	if err != nil {
+		return nil, err
+	}
+	return reply, checkReply(reply)
+}
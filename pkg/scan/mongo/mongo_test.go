@@ -0,0 +1,185 @@
+package mongo
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+// serveMongo replies to commands by the command name, unknown commands
+// are rejected with the CommandNotFound error
+func serveMongo(t *testing.T, replies map[string][]element) *scan.Request {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { l.Close() })
+
+	notFound := encodeTestDocument(t, element{"ok", 0.0}, element{"errmsg", "no such command"},
+		element{"code", int32(59)}, element{"codeName", "CommandNotFound"})
+	encoded := make(map[string][]byte)
+	for name, reply := range replies {
+		encoded[name] = encodeTestDocument(t, reply...)
+	}
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for {
+			header := make([]byte, headerSize)
+			if _, err := io.ReadFull(conn, header); err != nil {
+				return
+			}
+			body := make([]byte, binary.LittleEndian.Uint32(header)-headerSize)
+			if _, err := io.ReadFull(conn, body); err != nil {
+				return
+			}
+			// flagBits, section kind, document size and type of the first element precede the command name
+			name, _, err := readCString(body[10:])
+			if err != nil {
+				return
+			}
+			doc, ok := encoded[name]
+			if !ok {
+				doc = notFound
+			}
+			reply := make([]byte, headerSize+5, headerSize+5+len(doc))
+			binary.LittleEndian.PutUint32(reply, uint32(cap(reply)))
+			copy(reply[8:], header[4:8])
+			binary.LittleEndian.PutUint32(reply[12:], opMsg)
+			if _, err := conn.Write(append(reply, doc...)); err != nil {
+				return
+			}
+		}
+	}()
+	addr := l.Addr().(*net.TCPAddr)
+	return &scan.Request{DstIP: addr.IP, DstPort: uint16(addr.Port)}
+}
+
+func TestScan(t *testing.T) {
+	t.Parallel()
+	buildInfo := []element{{"version", "6.0.3"}, {"ok", 1.0}}
+	tests := []struct {
+		name     string
+		replies  map[string][]element
+		expected *ScanResult
+	}{
+		{
+			name: "NoAuth",
+			replies: map[string][]element{
+				"hello":     {{"isWritablePrimary", true}, {"maxWireVersion", int32(17)}, {"ok", 1.0}},
+				"buildInfo": buildInfo,
+				"listDatabases": {
+					{"databases", array{[]element{{"name", "admin"}}, []element{{"name", "shop"}}}},
+					{"ok", 1.0},
+				},
+			},
+			expected: &ScanResult{
+				Version:   "6.0.3",
+				Databases: []string{"admin", "shop"},
+			},
+		},
+		{
+			name: "AuthRequired",
+			replies: map[string][]element{
+				"hello":     {{"setName", "rs0"}, {"ok", 1.0}},
+				"buildInfo": buildInfo,
+				"listDatabases": {
+					{"ok", 0.0}, {"errmsg", "command listDatabases requires authentication"},
+					{"code", int32(13)}, {"codeName", "Unauthorized"},
+				},
+			},
+			expected: &ScanResult{
+				Version:      "6.0.3",
+				SetName:      "rs0",
+				AuthRequired: true,
+			},
+		},
+		{
+			name: "IsMaster",
+			replies: map[string][]element{
+				"isMaster":      {{"ismaster", true}, {"setName", "rs1"}, {"ok", 1.0}},
+				"buildInfo":     {{"version", "4.2.8"}, {"ok", 1.0}},
+				"listDatabases": {{"databases", array{}}, {"ok", 1.0}},
+			},
+			expected: &ScanResult{
+				Version: "4.2.8",
+				SetName: "rs1",
+			},
+		},
+	}
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			req := serveMongo(t, tt.replies)
+			result, err := NewScanner().Scan(context.Background(), req)
+			require.NoError(t, err)
+
+			tt.expected.ScanType = ScanType
+			tt.expected.IP = req.DstIP.String()
+			tt.expected.Port = req.DstPort
+			require.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestScanNotMongoServer(t *testing.T) {
+	t.Parallel()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		_, _ = conn.Write([]byte("SSH-2.0-OpenSSH_8.4p1\r\n"))
+	}()
+	addr := l.Addr().(*net.TCPAddr)
+
+	result, err := NewScanner().Scan(context.Background(), &scan.Request{DstIP: addr.IP, DstPort: uint16(addr.Port)})
+	require.ErrorIs(t, err, errMessage)
+	require.Nil(t, result)
+}
+
+func TestScanTimeout(t *testing.T) {
+	t.Parallel()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+	done := make(chan interface{})
+	defer close(done)
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		<-done
+	}()
+	addr := l.Addr().(*net.TCPAddr)
+
+	s := NewScanner(WithDataTimeout(50 * time.Millisecond))
+	result, err := s.Scan(context.Background(), &scan.Request{DstIP: addr.IP, DstPort: uint16(addr.Port)})
+	require.Error(t, err)
+	require.ErrorIs(t, err, scan.ErrTimeout)
+	require.Nil(t, result)
+}
+
+func TestScanResultString(t *testing.T) {
+	t.Parallel()
+	result := &ScanResult{IP: "192.168.0.1", Port: 27017, Version: "6.0.3", Databases: []string{"admin", "shop"}}
+	require.Equal(t, "192.168.0.1          27017 6.0.3 no-auth dbs:admin,shop", result.String())
+
+	result = &ScanResult{IP: "192.168.0.2", Port: 27017, Version: "5.0.14", SetName: "rs0", AuthRequired: true}
+	require.Equal(t, "192.168.0.2          27017 5.0.14 rs:rs0 auth", result.String())
+}
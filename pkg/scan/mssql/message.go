@@ -0,0 +1,201 @@
+package mssql
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+// SQL Server Resolution Protocol messages, see [MC-SQLR] section 2.2
+const (
+	clntBcastEx = 0x02
+	svrResp     = 0x05
+)
+
+// TDS PRELOGIN messages, see [MS-TDS] section 2.2.6.5
+const (
+	tdsHeaderSize       = 8
+	tdsTypePrelogin     = 0x12
+	tdsTypeResponse     = 0x04
+	tdsStatusEOM        = 0x01
+	maxPreloginSize     = 4096
+	optionVersion       = 0x00
+	optionEncryption    = 0x01
+	optionTerminator    = 0xff
+	optionTokenSize     = 5
+	preloginVersionSize = 6
+)
+
+var (
+	errInvalidResponse = scan.NewError(scan.ErrParse, errors.New("invalid SQL Server Browser response"))
+	errInvalidPrelogin = scan.NewError(scan.ErrParse, errors.New("invalid TDS PRELOGIN response"))
+)
+
+// encryptionModes are values of the ENCRYPTION option of the PRELOGIN response
+var encryptionModes = []string{"off", "on", "not-supported", "required"}
+
+type instance struct {
+	serverName   string
+	instanceName string
+	clustered    bool
+	version      string
+	tcpPort      uint16
+	namedPipe    string
+}
+
+func browserRequest() []byte {
+	return []byte{clntBcastEx}
+}
+
+// parseBrowserResponse parses the SVR_RESP message with the list of instances in the form
+// ServerName;HOST;InstanceName;SQLEXPRESS;IsClustered;No;Version;15.0.2000.5;tcp;1433;;
+func parseBrowserResponse(data []byte) ([]*instance, error) {
+	if len(data) < 3 || data[0] != svrResp {
+		return nil, errInvalidResponse
+	}
+	size := int(binary.LittleEndian.Uint16(data[1:3]))
+	data = data[3:]
+	if size > len(data) {
+		return nil, errInvalidResponse
+	}
+	var result []*instance
+	for _, rawInstance := range strings.Split(string(data[:size]), ";;") {
+		if len(rawInstance) == 0 {
+			continue
+		}
+		inst, err := parseInstance(rawInstance)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, inst)
+	}
+	if len(result) == 0 {
+		return nil, errInvalidResponse
+	}
+	return result, nil
+}
+
+func parseInstance(data string) (*instance, error) {
+	fields := strings.Split(data, ";")
+	if len(fields)%2 != 0 {
+		return nil, fmt.Errorf("%w: odd number of instance fields", errInvalidResponse)
+	}
+	inst := &instance{}
+	for i := 0; i < len(fields); i += 2 {
+		value := fields[i+1]
+		switch strings.ToLower(fields[i]) {
+		case "servername":
+			inst.serverName = value
+		case "instancename":
+			inst.instanceName = value
+		case "isclustered":
+			inst.clustered = strings.EqualFold(value, "yes")
+		case "version":
+			inst.version = value
+		case "tcp":
+			port, err := strconv.ParseUint(value, 10, 16)
+			if err != nil {
+				return nil, fmt.Errorf("%w: invalid tcp port %q", errInvalidResponse, value)
+			}
+			inst.tcpPort = uint16(port)
+		case "np":
+			inst.namedPipe = value
+		}
+	}
+	if len(inst.instanceName) == 0 {
+		return nil, fmt.Errorf("%w: instance name required", errInvalidResponse)
+	}
+	return inst, nil
+}
+
+type prelogin struct {
+	version    string
+	encryption string
+}
+
+// preloginRequest returns the PRELOGIN message with the VERSION and ENCRYPTION options,
+// the client advertises that encryption is off to learn the server encryption mode
+func preloginRequest() []byte {
+	const optionsSize = 2*optionTokenSize + 1
+	const payloadSize = optionsSize + preloginVersionSize + 1
+	packet := make([]byte, 0, tdsHeaderSize+payloadSize)
+	packet = append(packet, tdsTypePrelogin, tdsStatusEOM)
+	packet = binary.BigEndian.AppendUint16(packet, tdsHeaderSize+payloadSize)
+	// SPID, packet id and window
+	packet = append(packet, 0, 0, 1, 0)
+
+	packet = append(packet, optionVersion)
+	packet = binary.BigEndian.AppendUint16(packet, optionsSize)
+	packet = binary.BigEndian.AppendUint16(packet, preloginVersionSize)
+	packet = append(packet, optionEncryption)
+	packet = binary.BigEndian.AppendUint16(packet, optionsSize+preloginVersionSize)
+	packet = binary.BigEndian.AppendUint16(packet, 1)
+	packet = append(packet, optionTerminator)
+
+	packet = append(packet, make([]byte, preloginVersionSize)...)
+	// ENCRYPT_OFF
+	packet = append(packet, 0)
+	return packet
+}
+
+// readPreloginResponse reads the payload of the TDS response message
+func readPreloginResponse(r io.Reader) ([]byte, error) {
+	header := make([]byte, tdsHeaderSize)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, err
+	}
+	if header[0] != tdsTypeResponse {
+		return nil, fmt.Errorf("%w: unexpected message type %#x", errInvalidPrelogin, header[0])
+	}
+	length := int(binary.BigEndian.Uint16(header[2:4]))
+	if length < tdsHeaderSize || length > maxPreloginSize {
+		return nil, fmt.Errorf("%w: invalid message length %d", errInvalidPrelogin, length)
+	}
+	payload := make([]byte, length-tdsHeaderSize)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return nil, err
+	}
+	return payload, nil
+}
+
+func parsePrelogin(payload []byte) (*prelogin, error) {
+	result := &prelogin{}
+	for i := 0; ; i += optionTokenSize {
+		if i >= len(payload) {
+			return nil, fmt.Errorf("%w: no terminator", errInvalidPrelogin)
+		}
+		if payload[i] == optionTerminator {
+			return result, nil
+		}
+		if i+optionTokenSize > len(payload) {
+			return nil, fmt.Errorf("%w: truncated option", errInvalidPrelogin)
+		}
+		offset := int(binary.BigEndian.Uint16(payload[i+1 : i+3]))
+		length := int(binary.BigEndian.Uint16(payload[i+3 : i+5]))
+		if offset+length > len(payload) {
+			return nil, fmt.Errorf("%w: option out of bounds", errInvalidPrelogin)
+		}
+		data := payload[offset : offset+length]
+		switch payload[i] {
+		case optionVersion:
+			if length < preloginVersionSize {
+				return nil, fmt.Errorf("%w: short version", errInvalidPrelogin)
+			}
+			result.version = fmt.Sprintf("%d.%d.%d", data[0], data[1], binary.BigEndian.Uint16(data[2:4]))
+		case optionEncryption:
+			if length < 1 {
+				return nil, fmt.Errorf("%w: short encryption", errInvalidPrelogin)
+			}
+			if int(data[0]) < len(encryptionModes) {
+				result.encryption = encryptionModes[data[0]]
+			} else {
+				result.encryption = fmt.Sprintf("unknown(%d)", data[0])
+			}
+		}
+	}
+}
@@ -0,0 +1,197 @@
+package mssql
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func browserResponse(data string) []byte {
+	packet := []byte{svrResp}
+	packet = binary.LittleEndian.AppendUint16(packet, uint16(len(data)))
+	return append(packet, data...)
+}
+
+// preloginResponse returns the TDS PRELOGIN response with the VERSION and ENCRYPTION options
+func preloginResponse(major, minor byte, build uint16, encryption byte) []byte {
+	packet := preloginRequest()
+	packet[0] = tdsTypeResponse
+	payload := packet[tdsHeaderSize:]
+	version := payload[2*optionTokenSize+1:]
+	version[0] = major
+	version[1] = minor
+	binary.BigEndian.PutUint16(version[2:4], build)
+	payload[len(payload)-1] = encryption
+	return packet
+}
+
+func TestBrowserRequest(t *testing.T) {
+	t.Parallel()
+	require.Equal(t, []byte{0x02}, browserRequest())
+}
+
+func TestParseBrowserResponse(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		data     []byte
+		expected []*instance
+	}{
+		{
+			name: "OneInstance",
+			data: browserResponse("ServerName;DB01;InstanceName;MSSQLSERVER;IsClustered;No;Version;15.0.2000.5;tcp;1433;;"),
+			expected: []*instance{
+				{serverName: "DB01", instanceName: "MSSQLSERVER", version: "15.0.2000.5", tcpPort: 1433},
+			},
+		},
+		{
+			name: "TwoInstances",
+			data: browserResponse("ServerName;DB01;InstanceName;MSSQLSERVER;IsClustered;No;Version;15.0.2000.5;tcp;1433;;" +
+				"ServerName;DB01;InstanceName;SQLEXPRESS;IsClustered;Yes;Version;14.0.1000.169;np;\\\\DB01\\pipe\\MSSQL$SQLEXPRESS\\sql\\query;;"),
+			expected: []*instance{
+				{serverName: "DB01", instanceName: "MSSQLSERVER", version: "15.0.2000.5", tcpPort: 1433},
+				{serverName: "DB01", instanceName: "SQLEXPRESS", clustered: true, version: "14.0.1000.169",
+					namedPipe: "\\\\DB01\\pipe\\MSSQL$SQLEXPRESS\\sql\\query"},
+			},
+		},
+	}
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			result, err := parseBrowserResponse(tt.data)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestParseBrowserResponseError(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{
+			name: "Empty",
+			data: []byte{},
+		},
+		{
+			name: "InvalidType",
+			data: append([]byte{0x04}, browserResponse("ServerName;DB01;InstanceName;A;;")[1:]...),
+		},
+		{
+			name: "Truncated",
+			data: browserResponse("ServerName;DB01;InstanceName;A;;")[:10],
+		},
+		{
+			name: "NoInstances",
+			data: browserResponse(""),
+		},
+		{
+			name: "OddFields",
+			data: browserResponse("ServerName;DB01;InstanceName;;"),
+		},
+		{
+			name: "NoInstanceName",
+			data: browserResponse("ServerName;DB01;;"),
+		},
+		{
+			name: "InvalidPort",
+			data: browserResponse("ServerName;DB01;InstanceName;A;tcp;70000;;"),
+		},
+	}
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := parseBrowserResponse(tt.data)
+			require.ErrorIs(t, err, errInvalidResponse)
+		})
+	}
+}
+
+func TestPreloginRequest(t *testing.T) {
+	t.Parallel()
+	packet := preloginRequest()
+	require.Equal(t, []byte{
+		0x12, 0x01, 0x00, 0x1a, 0x00, 0x00, 0x01, 0x00,
+		0x00, 0x00, 0x0b, 0x00, 0x06,
+		0x01, 0x00, 0x11, 0x00, 0x01,
+		0xff,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00,
+	}, packet)
+}
+
+func TestParsePrelogin(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		data     []byte
+		expected *prelogin
+	}{
+		{
+			name:     "EncryptionOff",
+			data:     preloginResponse(15, 0, 2000, 0),
+			expected: &prelogin{version: "15.0.2000", encryption: "off"},
+		},
+		{
+			name:     "EncryptionRequired",
+			data:     preloginResponse(16, 0, 1000, 3),
+			expected: &prelogin{version: "16.0.1000", encryption: "required"},
+		},
+		{
+			name:     "UnknownEncryption",
+			data:     preloginResponse(16, 0, 1000, 0x20),
+			expected: &prelogin{version: "16.0.1000", encryption: "unknown(32)"},
+		},
+	}
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			result, err := parsePrelogin(tt.data[tdsHeaderSize:])
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestParsePreloginError(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{
+			name: "Empty",
+			data: []byte{},
+		},
+		{
+			name: "NoTerminator",
+			data: []byte{0x00, 0x00, 0x05, 0x00, 0x00},
+		},
+		{
+			name: "TruncatedOption",
+			data: []byte{0x00, 0x00},
+		},
+		{
+			name: "OutOfBounds",
+			data: []byte{0x00, 0x00, 0x06, 0x00, 0x06, 0xff},
+		},
+		{
+			name: "ShortVersion",
+			data: []byte{0x00, 0x00, 0x06, 0x00, 0x01, 0xff, 0x0f},
+		},
+	}
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := parsePrelogin(tt.data)
+			require.ErrorIs(t, err, errInvalidPrelogin)
+		})
+	}
+}
@@ -0,0 +1,219 @@
+package mssql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+const (
+	ScanType = "mssql"
+
+	defaultDialTimeout = 2 * time.Second
+	defaultDataTimeout = 2 * time.Second
+	maxPacketSize      = 65535
+)
+
+type ScanResult struct {
+	ScanType  string      `json:"scan"`
+	IP        string      `json:"ip"`
+	Port      uint16      `json:"port"`
+	Instances []*Instance `json:"instances"`
+}
+
+// Instance is the SQL Server instance announced by the SQL Server Browser service
+type Instance struct {
+	ServerName string `json:"server_name,omitempty"`
+	Name       string `json:"name"`
+	Clustered  bool   `json:"clustered"`
+	// Version is the version announced by the browser service, e.g. 15.0.2000.5
+	Version string `json:"version,omitempty"`
+	// TCPPort is zero if the instance doesn't listen on TCP
+	TCPPort   uint16 `json:"tcp_port,omitempty"`
+	NamedPipe string `json:"named_pipe,omitempty"`
+	// PreloginVersion is the version from the TDS PRELOGIN response on the TCP port
+	PreloginVersion string `json:"prelogin_version,omitempty"`
+	// Encryption is the encryption mode from the TDS PRELOGIN response: off, on, not-supported or required
+	Encryption string `json:"encryption,omitempty"`
+}
+
+func (r *ScanResult) String() string {
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "%-20s %-5d", r.IP, r.Port)
+	for _, inst := range r.Instances {
+		fmt.Fprintf(&buf, " %s\\%s %s", inst.ServerName, inst.Name, inst.Version)
+		if inst.TCPPort > 0 {
+			fmt.Fprintf(&buf, " tcp/%d", inst.TCPPort)
+		}
+		if len(inst.Encryption) > 0 {
+			fmt.Fprintf(&buf, " encryption %s", inst.Encryption)
+		}
+		if inst.Clustered {
+			buf.WriteString(" clustered")
+		}
+		buf.WriteString(";")
+	}
+	return strings.TrimSuffix(buf.String(), ";")
+}
+
+func (r *ScanResult) ID() string {
+	return fmt.Sprintf("%s:%d", r.IP, r.Port)
+}
+
+func (r *ScanResult) MarshalJSON() ([]byte, error) {
+	// Type definition for the recursive call
+	type JScanResult ScanResult
+	// This works because JScanResult doesn't have a MarshalJSON function associated with it
+	return json.Marshal(JScanResult(*r))
+}
+
+// Scanner sends the CLNT_BCAST_EX request to the SQL Server Browser service over UDP
+// and reports instances from the response
+type Scanner struct {
+	dialer      *net.Dialer
+	dataTimeout time.Duration
+	prelogin    bool
+}
+
+// Assert that mssql.Scanner conforms to the scan.Scanner interface
+var _ scan.Scanner = (*Scanner)(nil)
+
+type ScannerOption func(*Scanner)
+
+// WithDialTimeout sets the timeout of TCP connections to instances, see WithPrelogin
+func WithDialTimeout(timeout time.Duration) ScannerOption {
+	return func(s *Scanner) {
+		s.dialer.Timeout = timeout
+	}
+}
+
+// WithDataTimeout sets the time to wait for the browser response and the PRELOGIN response
+func WithDataTimeout(timeout time.Duration) ScannerOption {
+	return func(s *Scanner) {
+		s.dataTimeout = timeout
+	}
+}
+
+// WithPrelogin enables sending the TDS PRELOGIN request to the TCP port of every discovered instance
+// to collect its version and encryption mode
+func WithPrelogin() ScannerOption {
+	return func(s *Scanner) {
+		s.prelogin = true
+	}
+}
+
+func NewScanner(opts ...ScannerOption) *Scanner {
+	s := &Scanner{
+		dialer: &net.Dialer{
+			Timeout: defaultDialTimeout,
+		},
+		dataTimeout: defaultDataTimeout,
+	}
+	for _, o := range opts {
+		o(s)
+	}
+	return s
+}
+
+func (s *Scanner) Scan(ctx context.Context, r *scan.Request) (scan.Result, error) {
+	result, err := s.scanTarget(ctx, r)
+	return result, scan.WrapError(err)
+}
+
+func (s *Scanner) scanTarget(ctx context.Context, r *scan.Request) (scan.Result, error) {
+	instances, err := s.browse(ctx, r)
+	if err != nil || len(instances) == 0 {
+		return nil, err
+	}
+
+	result := &ScanResult{
+		ScanType: ScanType,
+		IP:       r.DstIP.String(),
+		Port:     r.DstPort,
+	}
+	for _, inst := range instances {
+		instance := &Instance{
+			ServerName: inst.serverName,
+			Name:       inst.instanceName,
+			Clustered:  inst.clustered,
+			Version:    inst.version,
+			TCPPort:    inst.tcpPort,
+			NamedPipe:  inst.namedPipe,
+		}
+		if s.prelogin && inst.tcpPort > 0 {
+			if err = ctx.Err(); err != nil {
+				return nil, err
+			}
+			// instances are reported even if their TCP port is filtered
+			if p, err := s.preloginInstance(ctx, r.DstIP, inst.tcpPort); err == nil {
+				instance.PreloginVersion = p.version
+				instance.Encryption = p.encryption
+			}
+		}
+		result.Instances = append(result.Instances, instance)
+	}
+	return result, nil
+}
+
+// browse sends the browser request, no instances are returned if there is no answer
+func (s *Scanner) browse(ctx context.Context, r *scan.Request) ([]*instance, error) {
+	addr := net.JoinHostPort(r.DstIP.String(), strconv.Itoa(int(r.DstPort)))
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "udp", addr)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if _, err = conn.Write(browserRequest()); err != nil {
+		return nil, err
+	}
+	if err = conn.SetReadDeadline(time.Now().Add(s.dataTimeout)); err != nil {
+		return nil, err
+	}
+	buf := make([]byte, maxPacketSize)
+	for {
+		n, err := conn.Read(buf)
+		if isTimeout(err) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if instances, err := parseBrowserResponse(buf[:n]); err == nil {
+			return instances, nil
+		}
+	}
+}
+
+func (s *Scanner) preloginInstance(ctx context.Context, ip net.IP, port uint16) (*prelogin, error) {
+	addr := net.JoinHostPort(ip.String(), strconv.Itoa(int(port)))
+	conn, err := s.dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	if err = conn.SetDeadline(time.Now().Add(s.dataTimeout)); err != nil {
+		return nil, err
+	}
+	if _, err = conn.Write(preloginRequest()); err != nil {
+		return nil, err
+	}
+	payload, err := readPreloginResponse(conn)
+	if err != nil {
+		return nil, err
+	}
+	return parsePrelogin(payload)
+}
+
+func isTimeout(err error) bool {
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
@@ -0,0 +1,139 @@
+package mssql
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+type fakeServer struct {
+	conn net.PacketConn
+	// response is the browser response, nil disables answers
+	response []byte
+}
+
+// startFakeServer starts UDP SQL Server Browser service
+func startFakeServer(t *testing.T, srv *fakeServer) *scan.Request {
+	t.Helper()
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	srv.conn = conn
+	go srv.serve()
+	addr := conn.LocalAddr().(*net.UDPAddr)
+	return &scan.Request{DstIP: addr.IP, DstPort: uint16(addr.Port)}
+}
+
+func (s *fakeServer) serve() {
+	buf := make([]byte, maxPacketSize)
+	for {
+		n, addr, err := s.conn.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+		if n != 1 || buf[0] != clntBcastEx || s.response == nil {
+			continue
+		}
+		// garbage is skipped by the scanner
+		_, _ = s.conn.WriteTo([]byte{0x00}, addr)
+		_, _ = s.conn.WriteTo(s.response, addr)
+	}
+}
+
+// startTDSServer starts TCP server that answers the PRELOGIN request and returns its port
+func startTDSServer(t *testing.T, response []byte) uint16 {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				buf := make([]byte, len(preloginRequest()))
+				if _, err := io.ReadFull(conn, buf); err != nil {
+					return
+				}
+				_, _ = conn.Write(response)
+			}()
+		}
+	}()
+	return uint16(ln.Addr().(*net.TCPAddr).Port)
+}
+
+func TestScan(t *testing.T) {
+	t.Parallel()
+	req := startFakeServer(t, &fakeServer{
+		response: browserResponse("ServerName;DB01;InstanceName;MSSQLSERVER;IsClustered;No;Version;15.0.2000.5;tcp;1433;;"),
+	})
+	s := NewScanner(WithDataTimeout(200 * time.Millisecond))
+
+	result, err := s.Scan(context.Background(), req)
+	require.NoError(t, err)
+	require.Equal(t, &ScanResult{
+		ScanType: ScanType,
+		IP:       req.DstIP.String(),
+		Port:     req.DstPort,
+		Instances: []*Instance{
+			{ServerName: "DB01", Name: "MSSQLSERVER", Version: "15.0.2000.5", TCPPort: 1433},
+		},
+	}, result)
+}
+
+func TestScanPrelogin(t *testing.T) {
+	t.Parallel()
+	port := startTDSServer(t, preloginResponse(15, 0, 2000, 3))
+	// the listener is closed immediately, so the PRELOGIN request fails for the second instance
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	closedPort := ln.Addr().(*net.TCPAddr).Port
+	ln.Close()
+
+	req := startFakeServer(t, &fakeServer{
+		response: browserResponse(fmt.Sprintf("ServerName;DB01;InstanceName;A;IsClustered;No;Version;15.0.2000.5;tcp;%d;;"+
+			"ServerName;DB01;InstanceName;B;IsClustered;No;Version;15.0.2000.5;tcp;%d;;", port, closedPort)),
+	})
+	s := NewScanner(WithDataTimeout(200*time.Millisecond), WithPrelogin())
+
+	result, err := s.Scan(context.Background(), req)
+	require.NoError(t, err)
+	require.Equal(t, []*Instance{
+		{ServerName: "DB01", Name: "A", Version: "15.0.2000.5", TCPPort: port,
+			PreloginVersion: "15.0.2000", Encryption: "required"},
+		{ServerName: "DB01", Name: "B", Version: "15.0.2000.5", TCPPort: uint16(closedPort)},
+	}, result.(*ScanResult).Instances)
+}
+
+func TestScanNoResponse(t *testing.T) {
+	t.Parallel()
+	req := startFakeServer(t, &fakeServer{})
+	s := NewScanner(WithDataTimeout(50 * time.Millisecond))
+
+	result, err := s.Scan(context.Background(), req)
+	require.NoError(t, err)
+	require.Nil(t, result)
+}
+
+func TestScanResultString(t *testing.T) {
+	t.Parallel()
+	result := &ScanResult{
+		IP:   "192.168.0.1",
+		Port: 1434,
+		Instances: []*Instance{
+			{ServerName: "DB01", Name: "MSSQLSERVER", Version: "15.0.2000.5", TCPPort: 1433, Encryption: "off"},
+			{ServerName: "DB01", Name: "SQLEXPRESS", Version: "14.0.1000.169", Clustered: true},
+		},
+	}
+	require.Equal(t, "192.168.0.1          1434  DB01\\MSSQLSERVER 15.0.2000.5 tcp/1433 encryption off;"+
+		" DB01\\SQLEXPRESS 14.0.1000.169 clustered", result.String())
+}
@@ -0,0 +1,171 @@
+package mysql
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+// MySQL client/server protocol fields, see
+// https://dev.mysql.com/doc/dev/mysql-server/latest/page_protocol_connection_phase_packets_protocol_handshake_v10.html
+const (
+	headerSize = 4
+	// maxGreetingSize limits the size of the greeting packet, real greetings are about 100 bytes
+	maxGreetingSize = 1 << 14
+	maxErrorLength  = 1024
+
+	protocolVersion9  = 9
+	protocolVersion10 = 10
+	errPacketHeader   = 0xff
+
+	clientSSL        = 0x00000800
+	clientPluginAuth = 0x00080000
+
+	// authDataPart1Size is the size of the first part of the auth plugin data (the scramble)
+	authDataPart1Size = 8
+	reservedSize      = 10
+	minAuthDataPart2  = 13
+
+	// mariaDBVersionPrefix is prepended to versions of MariaDB 10+ servers for the sake of old replication clients
+	mariaDBVersionPrefix = "5.5.5-"
+)
+
+var (
+	errPacket       = scan.NewError(scan.ErrParse, errors.New("invalid MySQL packet"))
+	errProtocol     = errors.New("unsupported MySQL protocol version")
+	errGreetingSize = scan.NewError(scan.ErrParse, errors.New("MySQL greeting packet is too large"))
+)
+
+// handshake is the initial greeting packet of the server
+type handshake struct {
+	protocolVersion int
+	serverVersion   string
+	connectionID    uint32
+	capabilities    uint32
+	authPlugin      string
+}
+
+// serverError is the error packet the server sends instead of the greeting, e.g. if the client host is not allowed
+type serverError struct {
+	code    uint16
+	message string
+}
+
+func (e *serverError) Error() string {
+	return fmt.Sprintf("%d: %s", e.code, e.message)
+}
+
+// readPacket reads the payload of the packet, the sequence number is ignored
+// since the greeting is the first packet of the connection
+func readPacket(r io.Reader) ([]byte, error) {
+	header := make([]byte, headerSize)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, err
+	}
+	size := int(header[0]) | int(header[1])<<8 | int(header[2])<<16
+	if size == 0 {
+		return nil, fmt.Errorf("%w: empty payload", errPacket)
+	}
+	if size > maxGreetingSize {
+		return nil, errGreetingSize
+	}
+	payload := make([]byte, size)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return nil, err
+	}
+	return payload, nil
+}
+
+// parseGreeting parses the handshake packet or the error packet of the server,
+// the latter is returned as serverError
+func parseGreeting(payload []byte) (*handshake, error) {
+	switch payload[0] {
+	case errPacketHeader:
+		return nil, parseError(payload)
+	case protocolVersion9, protocolVersion10:
+	default:
+		return nil, fmt.Errorf("%w: %d", errProtocol, payload[0])
+	}
+	h := &handshake{protocolVersion: int(payload[0])}
+	data := payload[1:]
+	version, data, ok := readNullString(data)
+	if !ok {
+		return nil, fmt.Errorf("%w: server version", errPacket)
+	}
+	h.serverVersion = version
+	if len(data) < 4 {
+		return nil, fmt.Errorf("%w: connection id", errPacket)
+	}
+	h.connectionID = binary.LittleEndian.Uint32(data)
+	data = data[4:]
+	// protocol 9 ends with the null-terminated scramble
+	if h.protocolVersion == protocolVersion9 {
+		return h, nil
+	}
+	// auth plugin data part 1 and the filler
+	if len(data) < authDataPart1Size+1 {
+		return nil, fmt.Errorf("%w: auth plugin data", errPacket)
+	}
+	data = data[authDataPart1Size+1:]
+	if len(data) < 2 {
+		return nil, fmt.Errorf("%w: capability flags", errPacket)
+	}
+	h.capabilities = uint32(binary.LittleEndian.Uint16(data))
+	data = data[2:]
+	// character set, status flags, upper capability flags, auth plugin data length, reserved bytes
+	// are optional in old servers
+	if len(data) < 1+2+2+1+reservedSize {
+		return h, nil
+	}
+	h.capabilities |= uint32(binary.LittleEndian.Uint16(data[3:])) << 16
+	authDataLength := int(data[5])
+	data = data[6+reservedSize:]
+	if h.capabilities&clientPluginAuth == 0 {
+		return h, nil
+	}
+	part2 := authDataLength - authDataPart1Size
+	if part2 < minAuthDataPart2 {
+		part2 = minAuthDataPart2
+	}
+	if len(data) < part2 {
+		return nil, fmt.Errorf("%w: auth plugin data", errPacket)
+	}
+	data = data[part2:]
+	// some servers omit the null terminator of the plugin name at the end of the packet
+	if i := bytes.IndexByte(data, 0); i >= 0 {
+		data = data[:i]
+	}
+	h.authPlugin = string(data)
+	return h, nil
+}
+
+// parseError parses the error packet: the header, the error code, the optional SQL state and the message
+func parseError(payload []byte) error {
+	if len(payload) < 3 {
+		return fmt.Errorf("%w: error packet", errPacket)
+	}
+	e := &serverError{code: binary.LittleEndian.Uint16(payload[1:])}
+	message := payload[3:]
+	// the SQL state marker and the 5 bytes state are sent only after the handshake
+	if len(message) >= 6 && message[0] == '#' {
+		message = message[6:]
+	}
+	if len(message) > maxErrorLength {
+		message = message[:maxErrorLength]
+	}
+	e.message = strings.TrimSpace(string(message))
+	return e
+}
+
+func readNullString(data []byte) (value string, rest []byte, ok bool) {
+	i := bytes.IndexByte(data, 0)
+	if i < 0 {
+		return "", nil, false
+	}
+	return string(data[:i]), data[i+1:], true
+}
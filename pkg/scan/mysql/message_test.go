@@ -0,0 +1,158 @@
+package mysql
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// newGreeting builds the protocol 10 handshake packet payload
+func newGreeting(version string, capabilities uint32, authPlugin string) []byte {
+	var buf bytes.Buffer
+	buf.WriteByte(protocolVersion10)
+	buf.WriteString(version)
+	buf.WriteByte(0)
+	_ = binary.Write(&buf, binary.LittleEndian, uint32(42))
+	buf.WriteString("12345678")
+	buf.WriteByte(0)
+	_ = binary.Write(&buf, binary.LittleEndian, uint16(capabilities))
+	// utf8mb4 character set and autocommit status
+	buf.WriteByte(0xff)
+	_ = binary.Write(&buf, binary.LittleEndian, uint16(2))
+	_ = binary.Write(&buf, binary.LittleEndian, uint16(capabilities>>16))
+	buf.WriteByte(21)
+	buf.Write(make([]byte, reservedSize))
+	buf.WriteString("123456789012")
+	buf.WriteByte(0)
+	if len(authPlugin) > 0 {
+		buf.WriteString(authPlugin)
+		buf.WriteByte(0)
+	}
+	return buf.Bytes()
+}
+
+// newPacket adds the packet header to the payload
+func newPacket(payload []byte) []byte {
+	size := len(payload)
+	return append([]byte{byte(size), byte(size >> 8), byte(size >> 16), 0}, payload...)
+}
+
+func TestReadPacket(t *testing.T) {
+	t.Parallel()
+	payload, err := readPacket(bytes.NewReader(newPacket([]byte{10, 1, 2})))
+	require.NoError(t, err)
+	require.Equal(t, []byte{10, 1, 2}, payload)
+
+	_, err = readPacket(bytes.NewReader([]byte{0, 0, 0, 0}))
+	require.ErrorIs(t, err, errPacket)
+
+	_, err = readPacket(bytes.NewReader([]byte{0, 0, 1, 0}))
+	require.ErrorIs(t, err, errGreetingSize)
+
+	_, err = readPacket(bytes.NewReader([]byte{5, 0, 0, 0, 10}))
+	require.Error(t, err)
+}
+
+func TestParseGreeting(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		payload  []byte
+		expected *handshake
+	}{
+		{
+			name:    "MySQL8",
+			payload: newGreeting("8.0.32", clientSSL|clientPluginAuth|0xf7ff, "caching_sha2_password"),
+			expected: &handshake{
+				protocolVersion: protocolVersion10,
+				serverVersion:   "8.0.32",
+				connectionID:    42,
+				capabilities:    clientSSL | clientPluginAuth | 0xf7ff,
+				authPlugin:      "caching_sha2_password",
+			},
+		},
+		{
+			name:    "NoPluginAuth",
+			payload: newGreeting("5.0.96", 0xf7ff&^clientSSL, ""),
+			expected: &handshake{
+				protocolVersion: protocolVersion10,
+				serverVersion:   "5.0.96",
+				connectionID:    42,
+				capabilities:    0xf7ff &^ clientSSL,
+			},
+		},
+		{
+			name: "PluginNameWithoutTerminator",
+			payload: func() []byte {
+				data := newGreeting("10.6.12-MariaDB", clientPluginAuth, "mysql_native_password")
+				return data[:len(data)-1]
+			}(),
+			expected: &handshake{
+				protocolVersion: protocolVersion10,
+				serverVersion:   "10.6.12-MariaDB",
+				connectionID:    42,
+				capabilities:    clientPluginAuth,
+				authPlugin:      "mysql_native_password",
+			},
+		},
+		{
+			name:    "ShortGreeting",
+			payload: append([]byte{protocolVersion10}, "3.23.58\x00\x01\x00\x00\x00abcdefgh\x00\x2c\xa2"...),
+			expected: &handshake{
+				protocolVersion: protocolVersion10,
+				serverVersion:   "3.23.58",
+				connectionID:    1,
+				capabilities:    0xa22c,
+			},
+		},
+		{
+			name:    "Protocol9",
+			payload: append([]byte{protocolVersion9}, "3.22.32\x00\x07\x00\x00\x00abcdefgh\x00"...),
+			expected: &handshake{
+				protocolVersion: protocolVersion9,
+				serverVersion:   "3.22.32",
+				connectionID:    7,
+			},
+		},
+	}
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			h, err := parseGreeting(tt.payload)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, h)
+		})
+	}
+}
+
+func TestParseGreetingError(t *testing.T) {
+	t.Parallel()
+	_, err := parseGreeting([]byte("SSH-2.0-OpenSSH_8.4p1\r\n"))
+	require.ErrorIs(t, err, errProtocol)
+
+	_, err = parseGreeting([]byte{protocolVersion10, '8', '.', '0'})
+	require.ErrorIs(t, err, errPacket)
+
+	_, err = parseGreeting(append([]byte{protocolVersion10}, "8.0.32\x00\x01\x00"...))
+	require.ErrorIs(t, err, errPacket)
+
+	greeting := newGreeting("8.0.32", clientPluginAuth, "caching_sha2_password")
+	_, err = parseGreeting(greeting[:len(greeting)-30])
+	require.ErrorIs(t, err, errPacket)
+
+	_, err = parseGreeting(append([]byte{errPacketHeader, 0x6a, 0x04}, "Host '10.0.0.1' is not allowed to connect to this MySQL server"...))
+	var serverErr *serverError
+	require.ErrorAs(t, err, &serverErr)
+	require.Equal(t, &serverError{code: 1130, message: "Host '10.0.0.1' is not allowed to connect to this MySQL server"}, serverErr)
+	require.Equal(t, "1130: Host '10.0.0.1' is not allowed to connect to this MySQL server", serverErr.Error())
+
+	_, err = parseGreeting(append([]byte{errPacketHeader, 0x10, 0x04}, "#08004Too many connections"...))
+	require.ErrorAs(t, err, &serverErr)
+	require.Equal(t, &serverError{code: 1040, message: "Too many connections"}, serverErr)
+
+	_, err = parseGreeting([]byte{errPacketHeader, 0x10})
+	require.ErrorIs(t, err, errPacket)
+}
@@ -0,0 +1,142 @@
+package mysql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+const (
+	ScanType = "mysql"
+
+	defaultDialTimeout = 2 * time.Second
+	defaultDataTimeout = 2 * time.Second
+)
+
+type ScanResult struct {
+	ScanType string `json:"scan"`
+	IP       string `json:"ip"`
+	Port     uint16 `json:"port"`
+	// Version is the server version from the greeting, e.g. 8.0.32 or 10.6.12-MariaDB
+	Version         string `json:"version,omitempty"`
+	ProtocolVersion int    `json:"protocol_version,omitempty"`
+	// AuthPlugin is the default authentication method of the server, e.g. caching_sha2_password
+	AuthPlugin string `json:"auth_plugin,omitempty"`
+	// TLS is set if the server supports upgrading the connection to TLS
+	TLS bool `json:"tls"`
+	// Error is sent by the server instead of the greeting, e.g. if the host of the scanner is not allowed to connect
+	Error string `json:"error,omitempty"`
+}
+
+func (r *ScanResult) String() string {
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "%-20s %-5d", r.IP, r.Port)
+	if len(r.Error) > 0 {
+		fmt.Fprintf(&buf, " error %q", r.Error)
+		return buf.String()
+	}
+	fmt.Fprintf(&buf, " %s protocol %d", r.Version, r.ProtocolVersion)
+	if len(r.AuthPlugin) > 0 {
+		fmt.Fprintf(&buf, " %s", r.AuthPlugin)
+	}
+	if r.TLS {
+		buf.WriteString(" tls")
+	}
+	return buf.String()
+}
+
+func (r *ScanResult) ID() string {
+	return fmt.Sprintf("%s:%d", r.IP, r.Port)
+}
+
+func (r *ScanResult) MarshalJSON() ([]byte, error) {
+	// Type definition for the recursive call
+	type JScanResult ScanResult
+	// This works because JScanResult doesn't have a MarshalJSON function associated with it
+	return json.Marshal(JScanResult(*r))
+}
+
+// Scanner reads the greeting packet MySQL and MariaDB servers send right after the connection,
+// the connection is closed without authentication
+type Scanner struct {
+	dialer      *net.Dialer
+	dataTimeout time.Duration
+}
+
+// Assert that mysql.Scanner conforms to the scan.Scanner interface
+var _ scan.Scanner = (*Scanner)(nil)
+
+type ScannerOption func(*Scanner)
+
+func WithDialTimeout(timeout time.Duration) ScannerOption {
+	return func(s *Scanner) {
+		s.dialer.Timeout = timeout
+	}
+}
+
+func WithDataTimeout(timeout time.Duration) ScannerOption {
+	return func(s *Scanner) {
+		s.dataTimeout = timeout
+	}
+}
+
+func NewScanner(opts ...ScannerOption) *Scanner {
+	s := &Scanner{
+		dialer: &net.Dialer{
+			Timeout: defaultDialTimeout,
+		},
+		dataTimeout: defaultDataTimeout,
+	}
+	for _, o := range opts {
+		o(s)
+	}
+	return s
+}
+
+func (s *Scanner) Scan(ctx context.Context, r *scan.Request) (scan.Result, error) {
+	result, err := s.scanTarget(ctx, r)
+	return result, scan.WrapError(err)
+}
+
+func (s *Scanner) scanTarget(ctx context.Context, r *scan.Request) (scan.Result, error) {
+	addr := net.JoinHostPort(r.DstIP.String(), strconv.Itoa(int(r.DstPort)))
+	conn, err := s.dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	if err = conn.SetDeadline(time.Now().Add(s.dataTimeout)); err != nil {
+		return nil, err
+	}
+	payload, err := readPacket(conn)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ScanResult{
+		ScanType: ScanType,
+		IP:       r.DstIP.String(),
+		Port:     r.DstPort,
+	}
+	h, err := parseGreeting(payload)
+	var serverErr *serverError
+	if errors.As(err, &serverErr) {
+		result.Error = serverErr.Error()
+		return result, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	result.Version = strings.TrimPrefix(h.serverVersion, mariaDBVersionPrefix)
+	result.ProtocolVersion = h.protocolVersion
+	result.AuthPlugin = h.authPlugin
+	result.TLS = h.capabilities&clientSSL != 0
+	return result, nil
+}
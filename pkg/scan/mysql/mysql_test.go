@@ -0,0 +1,112 @@
+package mysql
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+// serveMySQL sends the greeting to the first connection
+func serveMySQL(t *testing.T, greeting []byte) *scan.Request {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { l.Close() })
+
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		_, _ = conn.Write(greeting)
+	}()
+	addr := l.Addr().(*net.TCPAddr)
+	return &scan.Request{DstIP: addr.IP, DstPort: uint16(addr.Port)}
+}
+
+func TestScan(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		greeting []byte
+		expected *ScanResult
+	}{
+		{
+			name:     "MySQL",
+			greeting: newPacket(newGreeting("8.0.32", clientSSL|clientPluginAuth, "caching_sha2_password")),
+			expected: &ScanResult{
+				Version:         "8.0.32",
+				ProtocolVersion: 10,
+				AuthPlugin:      "caching_sha2_password",
+				TLS:             true,
+			},
+		},
+		{
+			name:     "MariaDB",
+			greeting: newPacket(newGreeting("5.5.5-10.6.12-MariaDB-0ubuntu0.22.04.1", clientPluginAuth, "mysql_native_password")),
+			expected: &ScanResult{
+				Version:         "10.6.12-MariaDB-0ubuntu0.22.04.1",
+				ProtocolVersion: 10,
+				AuthPlugin:      "mysql_native_password",
+			},
+		},
+		{
+			name:     "HostNotAllowed",
+			greeting: newPacket(append([]byte{errPacketHeader, 0x6a, 0x04}, "Host '10.0.0.1' is not allowed to connect to this MySQL server"...)),
+			expected: &ScanResult{
+				Error: "1130: Host '10.0.0.1' is not allowed to connect to this MySQL server",
+			},
+		},
+	}
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			req := serveMySQL(t, tt.greeting)
+			result, err := NewScanner().Scan(context.Background(), req)
+			require.NoError(t, err)
+
+			tt.expected.ScanType = ScanType
+			tt.expected.IP = req.DstIP.String()
+			tt.expected.Port = req.DstPort
+			require.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestScanNotMySQLServer(t *testing.T) {
+	t.Parallel()
+	req := serveMySQL(t, []byte("SSH-2.0-OpenSSH_8.4p1\r\n"))
+	result, err := NewScanner().Scan(context.Background(), req)
+	require.Error(t, err)
+	require.Nil(t, result)
+}
+
+func TestScanTimeout(t *testing.T) {
+	t.Parallel()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+	done := make(chan interface{})
+	defer close(done)
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		<-done
+	}()
+	addr := l.Addr().(*net.TCPAddr)
+
+	s := NewScanner(WithDataTimeout(50 * time.Millisecond))
+	result, err := s.Scan(context.Background(), &scan.Request{DstIP: addr.IP, DstPort: uint16(addr.Port)})
+	require.Error(t, err)
+	require.ErrorIs(t, err, scan.ErrTimeout)
+	require.Nil(t, result)
+}
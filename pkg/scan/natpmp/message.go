@@ -0,0 +1,116 @@
+package natpmp
+
+import (
+	"encoding/binary"
+	"errors"
+	"net"
+	"strconv"
+
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+// versions, opcodes and sizes of RFC 6886 NAT Port Mapping Protocol and RFC 6887 Port Control Protocol
+const (
+	natpmpVersion = 0
+	pcpVersion    = 2
+
+	opExternalAddress = 0
+	opAnnounce        = 0
+	// opResponse is added to the opcode of the request in responses
+	opResponse = 128
+
+	externalAddressResponseSize = 12
+	// unsupportedVersionSize is the size of NAT-PMP responses without the opcode specific payload
+	unsupportedVersionSize = 8
+	pcpHeaderSize          = 24
+)
+
+// result codes of both protocols
+const (
+	resultSuccess            = 0
+	resultUnsupportedVersion = 1
+)
+
+var errMessage = scan.NewError(scan.ErrParse, errors.New("invalid NAT-PMP or PCP message"))
+
+var natpmpResults = []string{
+	"SUCCESS", "UNSUPP_VERSION", "NOT_AUTHORIZED", "NETWORK_FAILURE", "NO_RESOURCES", "UNSUPP_OPCODE",
+}
+
+var pcpResults = []string{
+	"SUCCESS", "UNSUPP_VERSION", "NOT_AUTHORIZED", "MALFORMED_REQUEST", "UNSUPP_OPCODE", "UNSUPP_OPTION",
+	"MALFORMED_OPTION", "NETWORK_FAILURE", "NO_RESOURCES", "UNSUPP_PROTOCOL", "USER_EX_QUOTA",
+	"CANNOT_PROVIDE_EXTERNAL", "ADDRESS_MISMATCH", "EXCESSIVE_REMOTE_PEERS",
+}
+
+func resultName(names []string, code int) string {
+	if code < len(names) {
+		return names[code]
+	}
+	return strconv.Itoa(code)
+}
+
+// externalAddressRequest returns the NAT-PMP request of the external IPv4 address
+func externalAddressRequest() []byte {
+	return []byte{natpmpVersion, opExternalAddress}
+}
+
+type externalAddressResponse struct {
+	resultCode int
+	// epoch is seconds since the mapping table was initialized
+	epoch      uint32
+	externalIP net.IP
+}
+
+// parseExternalAddressResponse parses the NAT-PMP response to the external address request,
+// responses with errors have no external address
+func parseExternalAddressResponse(data []byte) (*externalAddressResponse, error) {
+	if len(data) < unsupportedVersionSize || data[0] != natpmpVersion || data[1] != opResponse+opExternalAddress {
+		return nil, errMessage
+	}
+	resp := &externalAddressResponse{
+		resultCode: int(binary.BigEndian.Uint16(data[2:])),
+		epoch:      binary.BigEndian.Uint32(data[4:]),
+	}
+	if resp.resultCode != resultSuccess {
+		return resp, nil
+	}
+	if len(data) < externalAddressResponseSize {
+		return nil, errMessage
+	}
+	resp.externalIP = net.IP(data[8:externalAddressResponseSize]).To4()
+	return resp, nil
+}
+
+// announceRequest returns the PCP ANNOUNCE request of the client address, the request doesn't create mappings
+func announceRequest(clientIP net.IP) []byte {
+	result := make([]byte, pcpHeaderSize)
+	result[0] = pcpVersion
+	result[1] = opAnnounce
+	copy(result[8:], clientIP.To16())
+	return result
+}
+
+type announceResponse struct {
+	// version is 0 if the server supports only NAT-PMP
+	version    int
+	resultCode int
+	epoch      uint32
+}
+
+// parseAnnounceResponse parses the PCP response to the ANNOUNCE request,
+// NAT-PMP servers answer the unsupported version in the NAT-PMP format
+func parseAnnounceResponse(data []byte) (*announceResponse, error) {
+	if len(data) >= unsupportedVersionSize && data[0] == natpmpVersion && data[1] == opResponse+opAnnounce &&
+		binary.BigEndian.Uint16(data[2:]) == resultUnsupportedVersion {
+		return &announceResponse{version: natpmpVersion, resultCode: resultUnsupportedVersion}, nil
+	}
+	if len(data) < pcpHeaderSize || data[0] != pcpVersion || data[1] != opResponse+opAnnounce {
+		return nil, errMessage
+	}
+	return &announceResponse{
+		version:    pcpVersion,
+		resultCode: int(data[3]),
+		epoch:      binary.BigEndian.Uint32(data[8:]),
+	}, nil
+}
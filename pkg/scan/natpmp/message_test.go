@@ -0,0 +1,167 @@
+package natpmp
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExternalAddressRequest(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, []byte{0x00, 0x00}, externalAddressRequest())
+}
+
+func TestParseExternalAddressResponse(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		data     []byte
+		expected *externalAddressResponse
+	}{
+		{
+			name: "Success",
+			data: []byte{0x00, 0x80, 0x00, 0x00, 0x00, 0x00, 0x0e, 0x10, 203, 0, 113, 5},
+			expected: &externalAddressResponse{resultCode: resultSuccess, epoch: 3600,
+				externalIP: net.IPv4(203, 0, 113, 5).To4()},
+		},
+		{
+			name:     "NotAuthorized",
+			data:     []byte{0x00, 0x80, 0x00, 0x02, 0x00, 0x00, 0x00, 0x10},
+			expected: &externalAddressResponse{resultCode: 2, epoch: 16},
+		},
+	}
+
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			resp, err := parseExternalAddressResponse(tt.data)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, resp)
+		})
+	}
+}
+
+func TestParseExternalAddressResponseInvalid(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{
+			name: "Short",
+			data: []byte{0x00, 0x80, 0x00, 0x00},
+		},
+		{
+			name: "Request",
+			data: []byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x0e, 0x10, 203, 0, 113, 5},
+		},
+		{
+			name: "PCP",
+			data: []byte{0x02, 0x80, 0x00, 0x00, 0x00, 0x00, 0x0e, 0x10, 203, 0, 113, 5},
+		},
+		{
+			name: "NoAddress",
+			data: []byte{0x00, 0x80, 0x00, 0x00, 0x00, 0x00, 0x0e, 0x10},
+		},
+	}
+
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := parseExternalAddressResponse(tt.data)
+			require.ErrorIs(t, err, errMessage)
+		})
+	}
+}
+
+func TestAnnounceRequest(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, []byte{
+		0x02, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0xff, 0xff, 192, 168, 0, 10,
+	}, announceRequest(net.IPv4(192, 168, 0, 10).To4()))
+}
+
+func TestParseAnnounceResponse(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		data     []byte
+		expected *announceResponse
+	}{
+		{
+			name: "AddressMismatch",
+			data: []byte{
+				0x02, 0x80, 0x00, 0x0c, 0x00, 0x00, 0x07, 0x08, 0x00, 0x00, 0x0e, 0x10,
+				0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+			},
+			expected: &announceResponse{version: pcpVersion, resultCode: 12, epoch: 3600},
+		},
+		{
+			name:     "NATPMP",
+			data:     []byte{0x00, 0x80, 0x00, 0x01, 0x00, 0x00, 0x0e, 0x10},
+			expected: &announceResponse{version: natpmpVersion, resultCode: resultUnsupportedVersion},
+		},
+	}
+
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			resp, err := parseAnnounceResponse(tt.data)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, resp)
+		})
+	}
+}
+
+func TestParseAnnounceResponseInvalid(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{
+			name: "Short",
+			data: []byte{0x02, 0x80, 0x00, 0x00, 0x00, 0x00, 0x0e, 0x10},
+		},
+		{
+			name: "Request",
+			data: announceRequest(net.IPv4(192, 168, 0, 10)),
+		},
+		{
+			name: "ExternalAddressResponse",
+			data: []byte{0x00, 0x80, 0x00, 0x00, 0x00, 0x00, 0x0e, 0x10, 203, 0, 113, 5},
+		},
+	}
+
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := parseAnnounceResponse(tt.data)
+			require.ErrorIs(t, err, errMessage)
+		})
+	}
+}
+
+func TestResultName(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, "ADDRESS_MISMATCH", resultName(pcpResults, 12))
+	require.Equal(t, "NO_RESOURCES", resultName(natpmpResults, 4))
+	require.Equal(t, "42", resultName(natpmpResults, 42))
+}
@@ -0,0 +1,190 @@
+package natpmp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+const (
+	ScanType = "natpmp"
+
+	defaultDataTimeout = 1 * time.Second
+	defaultRetries     = 1
+	// maxPacketSize is the maximum size of PCP messages
+	maxPacketSize = 1100
+)
+
+type ScanResult struct {
+	ScanType string `json:"scan"`
+	IP       string `json:"ip"`
+	Port     uint16 `json:"port"`
+	// NATPMP is the result code of the NAT-PMP external address request
+	NATPMP     string `json:"natpmp,omitempty"`
+	ExternalIP string `json:"external_ip,omitempty"`
+	// PCP is the result code of the PCP ANNOUNCE request
+	PCP string `json:"pcp,omitempty"`
+	// Epoch is seconds since the gateway initialized the mapping table, it is usually the uptime of the gateway
+	Epoch uint32 `json:"epoch"`
+}
+
+func (r *ScanResult) String() string {
+	result := fmt.Sprintf("%-20s %-5d", r.IP, r.Port)
+	if len(r.NATPMP) > 0 {
+		result += " natpmp " + r.NATPMP
+	}
+	if len(r.ExternalIP) > 0 {
+		result += " external " + r.ExternalIP
+	}
+	if len(r.PCP) > 0 {
+		result += " pcp " + r.PCP
+	}
+	return result + fmt.Sprintf(" epoch %d", r.Epoch)
+}
+
+func (r *ScanResult) ID() string {
+	return fmt.Sprintf("%s:%d", r.IP, r.Port)
+}
+
+func (r *ScanResult) MarshalJSON() ([]byte, error) {
+	// Type definition for the recursive call
+	type JScanResult ScanResult
+	// This works because JScanResult doesn't have a MarshalJSON function associated with it
+	return json.Marshal(JScanResult(*r))
+}
+
+// Scanner sends the NAT-PMP external address request and the PCP ANNOUNCE request to each target over UDP
+// and reports gateways that answered any of them. Neither request creates port mappings on the gateway.
+// Gateways must not answer these requests on the WAN side, so every result from the internet is a misconfiguration.
+type Scanner struct {
+	dataTimeout time.Duration
+	retries     int
+}
+
+// Assert that natpmp.Scanner conforms to the scan.Scanner interface
+var _ scan.Scanner = (*Scanner)(nil)
+
+type ScannerOption func(*Scanner)
+
+// WithDataTimeout sets the time to wait for a response to each request
+func WithDataTimeout(timeout time.Duration) ScannerOption {
+	return func(s *Scanner) {
+		s.dataTimeout = timeout
+	}
+}
+
+// WithRetries sets the number of additional requests if there is no response
+func WithRetries(retries int) ScannerOption {
+	return func(s *Scanner) {
+		s.retries = retries
+	}
+}
+
+func NewScanner(opts ...ScannerOption) *Scanner {
+	s := &Scanner{
+		dataTimeout: defaultDataTimeout,
+		retries:     defaultRetries,
+	}
+	for _, o := range opts {
+		o(s)
+	}
+	return s
+}
+
+func (s *Scanner) Scan(ctx context.Context, r *scan.Request) (scan.Result, error) {
+	result, err := s.scanTarget(ctx, r)
+	return result, scan.WrapError(err)
+}
+
+func (s *Scanner) scanTarget(ctx context.Context, r *scan.Request) (result scan.Result, err error) {
+	addr := net.JoinHostPort(r.DstIP.String(), strconv.Itoa(int(r.DstPort)))
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "udp", addr)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	res := &ScanResult{ScanType: ScanType, IP: r.DstIP.String(), Port: r.DstPort}
+	var natpmpResp *externalAddressResponse
+	if err = s.request(ctx, conn, externalAddressRequest(), func(data []byte) (err error) {
+		natpmpResp, err = parseExternalAddressResponse(data)
+		return
+	}); err != nil {
+		return
+	}
+	if natpmpResp != nil {
+		res.NATPMP = resultName(natpmpResults, natpmpResp.resultCode)
+		res.Epoch = natpmpResp.epoch
+		if natpmpResp.externalIP != nil {
+			res.ExternalIP = natpmpResp.externalIP.String()
+		}
+	}
+
+	// the client address is the address of the scanner, NAT between the scanner and the gateway
+	// is reported with the ADDRESS_MISMATCH result code
+	clientIP := conn.LocalAddr().(*net.UDPAddr).IP
+	var pcpResp *announceResponse
+	if err = s.request(ctx, conn, announceRequest(clientIP), func(data []byte) (err error) {
+		pcpResp, err = parseAnnounceResponse(data)
+		return
+	}); err != nil {
+		return
+	}
+	if pcpResp != nil && pcpResp.version == pcpVersion {
+		res.PCP = resultName(pcpResults, pcpResp.resultCode)
+		if natpmpResp == nil {
+			res.Epoch = pcpResp.epoch
+		}
+	}
+
+	if natpmpResp == nil && pcpResp == nil {
+		return nil, nil
+	}
+	return res, nil
+}
+
+// request sends the request until the parse function accepts a response, the ICMP port unreachable message
+// is returned as the error. There is no error if there is no response.
+func (s *Scanner) request(ctx context.Context, conn net.Conn, request []byte, parse func(data []byte) error) (err error) {
+	buf := make([]byte, maxPacketSize)
+	for i := 0; i <= s.retries; i++ {
+		if _, err = conn.Write(request); err != nil {
+			return
+		}
+		if err = conn.SetReadDeadline(time.Now().Add(s.dataTimeout)); err != nil {
+			return
+		}
+		if err = readResponse(conn, buf, parse); err == nil {
+			return
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		var netErr net.Error
+		// ICMP port unreachable and other errors are not retried
+		if !errors.As(err, &netErr) || !netErr.Timeout() {
+			return
+		}
+	}
+	return nil
+}
+
+// readResponse reads datagrams until the parse function accepts one, other datagrams are skipped
+func readResponse(conn net.Conn, buf []byte, parse func(data []byte) error) error {
+	for {
+		n, err := conn.Read(buf)
+		if err != nil {
+			return err
+		}
+		if err = parse(buf[:n]); err == nil {
+			return nil
+		}
+	}
+}
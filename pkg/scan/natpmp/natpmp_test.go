@@ -0,0 +1,120 @@
+package natpmp
+
+import (
+	"context"
+	"encoding/binary"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+type fakeGateway struct {
+	natpmp bool
+	pcp    bool
+}
+
+// startFakeServer starts UDP gateway that answers the NAT-PMP requests and the PCP requests it supports,
+// the gateway without both protocols drops requests like filtered ports
+func startFakeServer(t *testing.T, gateway fakeGateway) *scan.Request {
+	t.Helper()
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	go func() {
+		buf := make([]byte, maxPacketSize)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			if n < 2 {
+				continue
+			}
+			var resp []byte
+			switch {
+			case buf[0] == natpmpVersion && gateway.natpmp:
+				resp = []byte{0x00, 0x80, 0x00, 0x00, 0x00, 0x00, 0x0e, 0x10, 203, 0, 113, 5}
+			case buf[0] == pcpVersion && gateway.pcp:
+				resp = make([]byte, pcpHeaderSize)
+				resp[0] = pcpVersion
+				resp[1] = opResponse + opAnnounce
+				binary.BigEndian.PutUint32(resp[8:], 7200)
+				// the client address of the request must be the address of the scanner
+				if !net.IP(buf[8:pcpHeaderSize]).Equal(addr.(*net.UDPAddr).IP) {
+					resp[3] = 12
+				}
+			case buf[0] == pcpVersion && gateway.natpmp:
+				resp = []byte{0x00, 0x80, 0x00, 0x01, 0x00, 0x00, 0x0e, 0x10}
+			default:
+				continue
+			}
+			_, _ = conn.WriteTo(resp, addr)
+		}
+	}()
+	addr := conn.LocalAddr().(*net.UDPAddr)
+	return &scan.Request{DstIP: addr.IP, DstPort: uint16(addr.Port)}
+}
+
+func TestScan(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		gateway  fakeGateway
+		expected *ScanResult
+	}{
+		{
+			name:     "NATPMP",
+			gateway:  fakeGateway{natpmp: true},
+			expected: &ScanResult{NATPMP: "SUCCESS", ExternalIP: "203.0.113.5", Epoch: 3600},
+		},
+		{
+			name:     "PCP",
+			gateway:  fakeGateway{pcp: true},
+			expected: &ScanResult{PCP: "SUCCESS", Epoch: 7200},
+		},
+		{
+			name:     "Both",
+			gateway:  fakeGateway{natpmp: true, pcp: true},
+			expected: &ScanResult{NATPMP: "SUCCESS", ExternalIP: "203.0.113.5", PCP: "SUCCESS", Epoch: 3600},
+		},
+	}
+
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			req := startFakeServer(t, tt.gateway)
+			result, err := NewScanner(WithDataTimeout(100*time.Millisecond)).Scan(context.Background(), req)
+			require.NoError(t, err)
+			tt.expected.ScanType = ScanType
+			tt.expected.IP = req.DstIP.String()
+			tt.expected.Port = req.DstPort
+			require.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestScanNoResponse(t *testing.T) {
+	t.Parallel()
+
+	req := startFakeServer(t, fakeGateway{})
+	result, err := NewScanner(WithDataTimeout(50*time.Millisecond)).Scan(context.Background(), req)
+	require.NoError(t, err)
+	require.Nil(t, result)
+}
+
+func TestScanResultString(t *testing.T) {
+	t.Parallel()
+
+	result := &ScanResult{ScanType: ScanType, IP: "192.168.0.1", Port: 5351,
+		NATPMP: "SUCCESS", ExternalIP: "203.0.113.5", PCP: "ADDRESS_MISMATCH", Epoch: 3600}
+	require.Equal(t, "192.168.0.1          5351  natpmp SUCCESS external 203.0.113.5 pcp ADDRESS_MISMATCH epoch 3600",
+		result.String())
+	require.Equal(t, "192.168.0.1:5351", result.ID())
+}
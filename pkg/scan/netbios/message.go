@@ -0,0 +1,133 @@
+package netbios
+
+import (
+	"encoding/binary"
+	"errors"
+	"net"
+	"strings"
+
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+// NetBIOS name service fields, see RFC 1002 section 4.2
+const (
+	headerSize = 12
+	// encodedNameSize is the length of the first-level encoded name: 16 bytes in two halves each
+	encodedNameSize = 32
+	typeNBSTAT      = 0x21
+	classIN         = 0x01
+	flagResponse    = 0x8000
+	// nameSize is the size of the NODE_NAME entry: 15 bytes of name, the suffix byte and flags
+	nameSize  = 18
+	flagGroup = 0x8000
+	// suffixWorkstation is the suffix of the machine name and the workgroup name
+	suffixWorkstation = 0x00
+)
+
+var errResponse = scan.NewError(scan.ErrParse, errors.New("invalid NBSTAT response"))
+
+// nbstatRequest returns the node status request for the wildcard name "*"
+func nbstatRequest(transactionID uint16) []byte {
+	packet := make([]byte, headerSize, headerSize+encodedNameSize+6)
+	binary.BigEndian.PutUint16(packet[0:2], transactionID)
+	// QDCOUNT
+	binary.BigEndian.PutUint16(packet[4:6], 1)
+	packet = append(packet, encodedNameSize)
+	packet = append(packet, encodeName("*")...)
+	packet = append(packet, 0)
+	packet = binary.BigEndian.AppendUint16(packet, typeNBSTAT)
+	return binary.BigEndian.AppendUint16(packet, classIN)
+}
+
+// encodeName returns the first-level encoding of the name padded with zero bytes to 16 bytes:
+// each half-byte is added to 'A'
+func encodeName(name string) []byte {
+	padded := make([]byte, 16)
+	copy(padded, name)
+	result := make([]byte, 0, encodedNameSize)
+	for _, b := range padded {
+		result = append(result, 'A'+(b>>4), 'A'+(b&0x0f))
+	}
+	return result
+}
+
+type nodeName struct {
+	name   string
+	suffix byte
+	group  bool
+}
+
+type nodeStatus struct {
+	names []*nodeName
+	mac   net.HardwareAddr
+}
+
+func parseNodeStatus(data []byte, transactionID uint16) (*nodeStatus, error) {
+	if len(data) < headerSize ||
+		binary.BigEndian.Uint16(data[0:2]) != transactionID ||
+		binary.BigEndian.Uint16(data[2:4])&flagResponse == 0 ||
+		binary.BigEndian.Uint16(data[6:8]) == 0 {
+		return nil, errResponse
+	}
+	data = data[headerSize:]
+	// RR_NAME is either the encoded name or the pointer to it
+	if len(data) == 0 {
+		return nil, errResponse
+	}
+	switch {
+	case data[0]&0xc0 == 0xc0 && len(data) >= 2:
+		data = data[2:]
+	case int(data[0]) == encodedNameSize && len(data) >= encodedNameSize+2:
+		data = data[encodedNameSize+2:]
+	default:
+		return nil, errResponse
+	}
+	// RR_TYPE, RR_CLASS, TTL and RDLENGTH
+	if len(data) < 10 || binary.BigEndian.Uint16(data[0:2]) != typeNBSTAT {
+		return nil, errResponse
+	}
+	rdLength := int(binary.BigEndian.Uint16(data[8:10]))
+	data = data[10:]
+	if len(data) < rdLength || rdLength == 0 {
+		return nil, errResponse
+	}
+	data = data[:rdLength]
+	numNames := int(data[0])
+	data = data[1:]
+	if len(data) < numNames*nameSize {
+		return nil, errResponse
+	}
+	status := &nodeStatus{}
+	for i := 0; i < numNames; i++ {
+		entry := data[i*nameSize : (i+1)*nameSize]
+		status.names = append(status.names, &nodeName{
+			name:   strings.TrimRight(string(entry[:15]), " \x00"),
+			suffix: entry[15],
+			group:  binary.BigEndian.Uint16(entry[16:18])&flagGroup != 0,
+		})
+	}
+	// statistics start with the unit ID that is the MAC address of the adapter
+	if stats := data[numNames*nameSize:]; len(stats) >= 6 {
+		status.mac = net.HardwareAddr(append([]byte(nil), stats[:6]...))
+	}
+	return status, nil
+}
+
+// machineName returns the unique workstation name
+func (s *nodeStatus) machineName() string {
+	return s.firstName(false)
+}
+
+// workgroup returns the group workstation name that is the domain or workgroup of the machine
+func (s *nodeStatus) workgroup() string {
+	return s.firstName(true)
+}
+
+func (s *nodeStatus) firstName(group bool) string {
+	for _, n := range s.names {
+		if n.suffix == suffixWorkstation && n.group == group {
+			return n.name
+		}
+	}
+	return ""
+}
@@ -0,0 +1,118 @@
+package netbios
+
+import (
+	"encoding/binary"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testName struct {
+	name   string
+	suffix byte
+	group  bool
+}
+
+// nodeStatusResponse returns the NBSTAT response with the names and the MAC address,
+// the name of the resource record is the pointer to the question if pointer is true
+func nodeStatusResponse(transactionID uint16, pointer bool, names []testName, mac net.HardwareAddr) []byte {
+	packet := make([]byte, headerSize)
+	binary.BigEndian.PutUint16(packet[0:2], transactionID)
+	binary.BigEndian.PutUint16(packet[2:4], flagResponse|0x0400)
+	// ANCOUNT
+	binary.BigEndian.PutUint16(packet[6:8], 1)
+	if pointer {
+		packet = append(packet, 0xc0, headerSize)
+	} else {
+		packet = append(packet, encodedNameSize)
+		packet = append(packet, encodeName("*")...)
+		packet = append(packet, 0)
+	}
+	packet = binary.BigEndian.AppendUint16(packet, typeNBSTAT)
+	packet = binary.BigEndian.AppendUint16(packet, classIN)
+	// TTL
+	packet = binary.BigEndian.AppendUint32(packet, 0)
+
+	rdata := []byte{byte(len(names))}
+	for _, n := range names {
+		entry := []byte(n.name + "               ")[:15]
+		entry = append(entry, n.suffix)
+		var flags uint16 = 0x0400
+		if n.group {
+			flags |= flagGroup
+		}
+		rdata = append(rdata, binary.BigEndian.AppendUint16(entry, flags)...)
+	}
+	// unit ID followed by other statistics
+	rdata = append(rdata, mac...)
+	rdata = append(rdata, make([]byte, 40)...)
+	packet = binary.BigEndian.AppendUint16(packet, uint16(len(rdata)))
+	return append(packet, rdata...)
+}
+
+var testNames = []testName{
+	{name: "WORKGROUP", suffix: 0x00, group: true},
+	{name: "DESKTOP-01", suffix: 0x20},
+	{name: "DESKTOP-01", suffix: 0x00},
+	{name: "WORKGROUP", suffix: 0x1e, group: true},
+}
+
+func TestNBSTATRequest(t *testing.T) {
+	t.Parallel()
+	packet := nbstatRequest(0x1234)
+
+	require.Equal(t, []byte{0x12, 0x34, 0, 0, 0, 1, 0, 0, 0, 0, 0, 0, 0x20, 'C', 'K'}, packet[:15])
+	require.Equal(t, []byte("AAAAAAAAAAAAAAAAAAAAAAAAAAAAAA"), packet[15:45])
+	require.Equal(t, []byte{0, 0, 0x21, 0, 1}, packet[45:])
+}
+
+func TestParseNodeStatus(t *testing.T) {
+	t.Parallel()
+	mac := net.HardwareAddr{0x00, 0x0c, 0x29, 0x01, 0x02, 0x03}
+	for _, pointer := range []bool{false, true} {
+		status, err := parseNodeStatus(nodeStatusResponse(0x1234, pointer, testNames, mac), 0x1234)
+		require.NoError(t, err)
+		require.Len(t, status.names, 4)
+		require.Equal(t, "DESKTOP-01", status.machineName())
+		require.Equal(t, "WORKGROUP", status.workgroup())
+		require.Equal(t, mac, status.mac)
+	}
+}
+
+func TestParseNodeStatusError(t *testing.T) {
+	t.Parallel()
+	valid := nodeStatusResponse(0x1234, true, testNames, make(net.HardwareAddr, 6))
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{
+			name: "Empty",
+		},
+		{
+			name: "Request",
+			data: nbstatRequest(0x1234),
+		},
+		{
+			name: "TransactionID",
+			data: nodeStatusResponse(0x4321, true, testNames, nil),
+		},
+		{
+			name: "TruncatedNamePointer",
+			data: []byte("\x12\x34\xff000000000\xff"),
+		},
+		{
+			name: "TruncatedNames",
+			data: valid[:headerSize+2+10+1+nameSize],
+		},
+	}
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := parseNodeStatus(tt.data, 0x1234)
+			require.ErrorIs(t, err, errResponse)
+		})
+	}
+}
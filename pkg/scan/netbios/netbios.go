@@ -0,0 +1,142 @@
+//go:generate easyjson -output_filename result_easyjson.go netbios.go
+
+package netbios
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+const (
+	ScanType = "netbios"
+
+	defaultDataTimeout = 1 * time.Second
+	maxPacketSize      = 1500
+)
+
+//easyjson:json
+type ScanResult struct {
+	ScanType string `json:"scan"`
+	IP       string `json:"ip"`
+	Port     uint16 `json:"port"`
+	// Name is the machine name, e.g. WORKSTATION01
+	Name string `json:"name,omitempty"`
+	// Workgroup is the domain or workgroup name, e.g. WORKGROUP
+	Workgroup string `json:"workgroup,omitempty"`
+	// MAC is empty if the host reported the zero address like Samba does
+	MAC string `json:"mac,omitempty"`
+}
+
+func (r *ScanResult) String() string {
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "%-20s %-5d %s", r.IP, r.Port, r.Name)
+	if len(r.Workgroup) > 0 {
+		fmt.Fprintf(&buf, " %s", r.Workgroup)
+	}
+	if len(r.MAC) > 0 {
+		fmt.Fprintf(&buf, " %s", r.MAC)
+	}
+	return buf.String()
+}
+
+func (r *ScanResult) ID() string {
+	return fmt.Sprintf("%s:%d", r.IP, r.Port)
+}
+
+// Scanner sends the NetBIOS node status (NBSTAT) request to each target over UDP,
+// hosts that answered are reported with their machine name, workgroup and MAC address
+type Scanner struct {
+	dataTimeout time.Duration
+}
+
+// Assert that netbios.Scanner conforms to the scan.Scanner interface
+var _ scan.Scanner = (*Scanner)(nil)
+
+type ScannerOption func(*Scanner)
+
+// WithDataTimeout sets the time to wait for the response
+func WithDataTimeout(timeout time.Duration) ScannerOption {
+	return func(s *Scanner) {
+		s.dataTimeout = timeout
+	}
+}
+
+func NewScanner(opts ...ScannerOption) *Scanner {
+	s := &Scanner{
+		dataTimeout: defaultDataTimeout,
+	}
+	for _, o := range opts {
+		o(s)
+	}
+	return s
+}
+
+func (s *Scanner) Scan(ctx context.Context, r *scan.Request) (scan.Result, error) {
+	result, err := s.scanTarget(ctx, r)
+	return result, scan.WrapError(err)
+}
+
+func (s *Scanner) scanTarget(ctx context.Context, r *scan.Request) (result scan.Result, err error) {
+	addr := net.JoinHostPort(r.DstIP.String(), strconv.Itoa(int(r.DstPort)))
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "udp", addr)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	transactionID := uint16(rand.Uint32())
+	if _, err = conn.Write(nbstatRequest(transactionID)); err != nil {
+		return
+	}
+	if err = conn.SetReadDeadline(time.Now().Add(s.dataTimeout)); err != nil {
+		return
+	}
+	buf := make([]byte, maxPacketSize)
+	for {
+		n, err := conn.Read(buf)
+		if isTimeout(err) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		status, err := parseNodeStatus(buf[:n], transactionID)
+		if err != nil {
+			continue
+		}
+		res := &ScanResult{
+			ScanType:  ScanType,
+			IP:        r.DstIP.String(),
+			Port:      r.DstPort,
+			Name:      status.machineName(),
+			Workgroup: status.workgroup(),
+		}
+		if status.mac != nil && !isZero(status.mac) {
+			res.MAC = status.mac.String()
+		}
+		return res, nil
+	}
+}
+
+func isZero(data []byte) bool {
+	for _, b := range data {
+		if b != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func isTimeout(err error) bool {
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
@@ -0,0 +1,83 @@
+package netbios
+
+import (
+	"context"
+	"encoding/binary"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+// startFakeServer starts UDP NetBIOS name service that answers NBSTAT requests with the MAC address
+func startFakeServer(t *testing.T, mac net.HardwareAddr, silent bool) *scan.Request {
+	t.Helper()
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	go func() {
+		buf := make([]byte, maxPacketSize)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			if n < headerSize || silent {
+				continue
+			}
+			transactionID := binary.BigEndian.Uint16(buf[0:2])
+			// unrelated response is skipped by the scanner
+			_, _ = conn.WriteTo(nodeStatusResponse(transactionID+1, true, testNames, mac), addr)
+			_, _ = conn.WriteTo(nodeStatusResponse(transactionID, true, testNames, mac), addr)
+		}
+	}()
+	addr := conn.LocalAddr().(*net.UDPAddr)
+	return &scan.Request{DstIP: addr.IP, DstPort: uint16(addr.Port)}
+}
+
+func TestScan(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		mac      net.HardwareAddr
+		expected *ScanResult
+	}{
+		{
+			name: "MAC",
+			mac:  net.HardwareAddr{0x00, 0x0c, 0x29, 0x01, 0x02, 0x03},
+			expected: &ScanResult{ScanType: ScanType, IP: "127.0.0.1",
+				Name: "DESKTOP-01", Workgroup: "WORKGROUP", MAC: "00:0c:29:01:02:03"},
+		},
+		{
+			name: "ZeroMAC",
+			mac:  make(net.HardwareAddr, 6),
+			expected: &ScanResult{ScanType: ScanType, IP: "127.0.0.1",
+				Name: "DESKTOP-01", Workgroup: "WORKGROUP"},
+		},
+	}
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			req := startFakeServer(t, tt.mac, false)
+			tt.expected.Port = req.DstPort
+
+			s := NewScanner(WithDataTimeout(time.Second))
+			result, err := s.Scan(context.Background(), req)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestScanNoResponse(t *testing.T) {
+	t.Parallel()
+	req := startFakeServer(t, nil, true)
+
+	s := NewScanner(WithDataTimeout(50 * time.Millisecond))
+	result, err := s.Scan(context.Background(), req)
+	require.NoError(t, err)
+	require.Nil(t, result)
+}
@@ -0,0 +1,189 @@
+package ntp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+const (
+	ScanType = "ntp"
+
+	defaultDataTimeout = 1 * time.Second
+	maxPacketSize      = 1500
+)
+
+type ScanResult struct {
+	ScanType string `json:"scan"`
+	IP       string `json:"ip"`
+	Port     uint16 `json:"port"`
+	// Version is the NTP version of the server response, zero if the server didn't answer the client request
+	Version uint8  `json:"version,omitempty"`
+	Stratum uint8  `json:"stratum"`
+	RefID   string `json:"refid,omitempty"`
+	// Monlist is true if the server answered the MON_GETLIST request, such servers can be abused for amplification attacks
+	Monlist        bool `json:"monlist"`
+	MonlistPackets int  `json:"monlist_packets,omitempty"`
+	MonlistBytes   int  `json:"monlist_bytes,omitempty"`
+}
+
+func (r *ScanResult) String() string {
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "%-20s %-5d v%d stratum %d", r.IP, r.Port, r.Version, r.Stratum)
+	if len(r.RefID) > 0 {
+		fmt.Fprintf(&buf, " %s", r.RefID)
+	}
+	if r.Monlist {
+		fmt.Fprintf(&buf, " monlist %d packets %d bytes", r.MonlistPackets, r.MonlistBytes)
+	}
+	return buf.String()
+}
+
+func (r *ScanResult) ID() string {
+	return fmt.Sprintf("%s:%d", r.IP, r.Port)
+}
+
+func (r *ScanResult) MarshalJSON() ([]byte, error) {
+	// Type definition for the recursive call
+	type JScanResult ScanResult
+	// This works because JScanResult doesn't have a MarshalJSON function associated with it
+	return json.Marshal(JScanResult(*r))
+}
+
+// Scanner sends the NTP client request and the mode 7 MON_GETLIST request to each target over UDP,
+// targets that answered any of them are reported
+type Scanner struct {
+	dataTimeout time.Duration
+}
+
+// Assert that ntp.Scanner conforms to the scan.Scanner interface
+var _ scan.Scanner = (*Scanner)(nil)
+
+type ScannerOption func(*Scanner)
+
+// WithDataTimeout sets the time to wait for responses to each request
+func WithDataTimeout(timeout time.Duration) ScannerOption {
+	return func(s *Scanner) {
+		s.dataTimeout = timeout
+	}
+}
+
+func NewScanner(opts ...ScannerOption) *Scanner {
+	s := &Scanner{
+		dataTimeout: defaultDataTimeout,
+	}
+	for _, o := range opts {
+		o(s)
+	}
+	return s
+}
+
+func (s *Scanner) Scan(ctx context.Context, r *scan.Request) (scan.Result, error) {
+	result, err := s.scanTarget(ctx, r)
+	return result, scan.WrapError(err)
+}
+
+func (s *Scanner) scanTarget(ctx context.Context, r *scan.Request) (result scan.Result, err error) {
+	addr := net.JoinHostPort(r.DstIP.String(), strconv.Itoa(int(r.DstPort)))
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "udp", addr)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	res := &ScanResult{
+		ScanType: ScanType,
+		IP:       r.DstIP.String(),
+		Port:     r.DstPort,
+	}
+	var answered bool
+	var resp *serverResponse
+	if resp, err = s.query(conn); err != nil {
+		return nil, err
+	}
+	if resp != nil {
+		answered = true
+		res.Version = resp.version
+		res.Stratum = resp.stratum
+		res.RefID = resp.refID
+	}
+	if err = ctx.Err(); err != nil {
+		return nil, err
+	}
+	if res.MonlistPackets, res.MonlistBytes, err = s.monlist(conn); err != nil {
+		return nil, err
+	}
+	if res.MonlistPackets > 0 {
+		answered = true
+		res.Monlist = true
+	}
+	if !answered {
+		return nil, nil
+	}
+	return res, nil
+}
+
+// query sends the client request, nil response means no answer
+func (s *Scanner) query(conn net.Conn) (*serverResponse, error) {
+	if _, err := conn.Write(clientRequest()); err != nil {
+		return nil, err
+	}
+	if err := conn.SetReadDeadline(time.Now().Add(s.dataTimeout)); err != nil {
+		return nil, err
+	}
+	buf := make([]byte, maxPacketSize)
+	for {
+		n, err := conn.Read(buf)
+		if isTimeout(err) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if resp, ok := parseServerResponse(buf[:n]); ok {
+			return resp, nil
+		}
+	}
+}
+
+// monlist sends the MON_GETLIST request and counts response packets and their size until the last packet
+func (s *Scanner) monlist(conn net.Conn) (packets, size int, err error) {
+	if _, err = conn.Write(monlistRequest()); err != nil {
+		return
+	}
+	if err = conn.SetReadDeadline(time.Now().Add(s.dataTimeout)); err != nil {
+		return
+	}
+	buf := make([]byte, maxPacketSize)
+	for {
+		n, rerr := conn.Read(buf)
+		if isTimeout(rerr) {
+			return
+		}
+		if rerr != nil {
+			return 0, 0, rerr
+		}
+		more, ok := parseMonlistResponse(buf[:n])
+		if !ok {
+			continue
+		}
+		packets++
+		size += n
+		if !more {
+			return
+		}
+	}
+}
+
+func isTimeout(err error) bool {
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
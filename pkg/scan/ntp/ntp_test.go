@@ -0,0 +1,142 @@
+package ntp
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+func serverPacket(version, stratum uint8, refID []byte) []byte {
+	packet := make([]byte, packetSize)
+	packet[0] = version<<3 | modeServer
+	packet[1] = stratum
+	copy(packet[12:16], refID)
+	return packet
+}
+
+func monlistPacket(more bool, errCode byte) []byte {
+	// header and one 72-byte item
+	packet := make([]byte, 8+72)
+	packet[0] = flagResponse | 2<<3 | modePrivate
+	if more {
+		packet[0] |= flagMore
+	}
+	packet[2] = implXNTPD
+	packet[3] = reqMonGetList1
+	packet[4] = errCode << 4
+	packet[5] = 1
+	return packet
+}
+
+type fakeServer struct {
+	conn net.PacketConn
+	// monlistPackets is the number of MON_GETLIST response packets, zero disables monlist
+	monlistPackets int
+	// silent disables answers to client requests
+	silent bool
+}
+
+// startFakeServer starts UDP NTP server
+func startFakeServer(t *testing.T, srv *fakeServer) *scan.Request {
+	t.Helper()
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	srv.conn = conn
+	go srv.serve()
+	addr := conn.LocalAddr().(*net.UDPAddr)
+	return &scan.Request{DstIP: addr.IP, DstPort: uint16(addr.Port)}
+}
+
+func (s *fakeServer) serve() {
+	buf := make([]byte, maxPacketSize)
+	for {
+		n, addr, err := s.conn.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+		if n < 4 {
+			continue
+		}
+		switch buf[0] & 0x07 {
+		case modeClient:
+			if !s.silent {
+				_, _ = s.conn.WriteTo(serverPacket(4, 2, []byte{10, 0, 0, 1}), addr)
+			}
+		case modePrivate:
+			for i := 0; i < s.monlistPackets; i++ {
+				_, _ = s.conn.WriteTo(monlistPacket(i < s.monlistPackets-1, 0), addr)
+			}
+		}
+	}
+}
+
+func TestScan(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		srv      *fakeServer
+		expected *ScanResult
+	}{
+		{
+			name: "Version",
+			srv:  &fakeServer{},
+			expected: &ScanResult{
+				Version: 4,
+				Stratum: 2,
+				RefID:   "10.0.0.1",
+			},
+		},
+		{
+			name: "Monlist",
+			srv:  &fakeServer{monlistPackets: 3},
+			expected: &ScanResult{
+				Version:        4,
+				Stratum:        2,
+				RefID:          "10.0.0.1",
+				Monlist:        true,
+				MonlistPackets: 3,
+				MonlistBytes:   3 * 80,
+			},
+		},
+		{
+			name: "MonlistOnly",
+			srv:  &fakeServer{monlistPackets: 1, silent: true},
+			expected: &ScanResult{
+				Monlist:        true,
+				MonlistPackets: 1,
+				MonlistBytes:   80,
+			},
+		},
+	}
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			req := startFakeServer(t, tt.srv)
+			s := NewScanner(WithDataTimeout(200 * time.Millisecond))
+
+			result, err := s.Scan(context.Background(), req)
+			require.NoError(t, err)
+			expected := *tt.expected
+			expected.ScanType = ScanType
+			expected.IP = req.DstIP.String()
+			expected.Port = req.DstPort
+			require.Equal(t, &expected, result)
+		})
+	}
+}
+
+func TestScanNoResponse(t *testing.T) {
+	t.Parallel()
+	req := startFakeServer(t, &fakeServer{silent: true})
+	s := NewScanner(WithDataTimeout(50 * time.Millisecond))
+
+	result, err := s.Scan(context.Background(), req)
+	require.NoError(t, err)
+	require.Nil(t, result)
+}
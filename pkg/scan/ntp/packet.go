@@ -0,0 +1,79 @@
+package ntp
+
+import (
+	"net"
+	"strings"
+)
+
+// NTP packet fields, see RFC 5905 section 7.3
+const (
+	packetSize    = 48
+	clientVersion = 4
+	modeClient    = 3
+	modeServer    = 4
+	// modePrivate is the mode of ntpd control requests like MON_GETLIST,
+	// it is not standardized but widely implemented by ntpd
+	modePrivate = 7
+
+	// mode 7 header fields
+	flagResponse   = 0x80
+	flagMore       = 0x40
+	implXNTPD      = 3
+	reqMonGetList1 = 42
+)
+
+type serverResponse struct {
+	version uint8
+	stratum uint8
+	refID   string
+}
+
+func clientRequest() []byte {
+	packet := make([]byte, packetSize)
+	// leap indicator 0, version and mode
+	packet[0] = clientVersion<<3 | modeClient
+	return packet
+}
+
+// monlistRequest returns the mode 7 MON_GETLIST_1 request with the zero data area,
+// ntpd doesn't answer shorter requests
+func monlistRequest() []byte {
+	packet := make([]byte, packetSize)
+	packet[0] = 2<<3 | modePrivate
+	packet[2] = implXNTPD
+	packet[3] = reqMonGetList1
+	return packet
+}
+
+func parseServerResponse(data []byte) (*serverResponse, bool) {
+	if len(data) < packetSize || data[0]&0x07 != modeServer {
+		return nil, false
+	}
+	return &serverResponse{
+		version: (data[0] >> 3) & 0x07,
+		stratum: data[1],
+		refID:   refID(data[1], data[12:16]),
+	}, true
+}
+
+// refID returns the reference identifier, it is a four-character ASCII code of the reference clock
+// for stratum 0 (kiss code) and 1 servers and IPv4 address of the upstream server otherwise
+func refID(stratum uint8, data []byte) string {
+	if stratum <= 1 {
+		return strings.TrimRight(string(data), "\x00")
+	}
+	return net.IP(data).String()
+}
+
+// parseMonlistResponse checks that the packet is a successful MON_GETLIST response
+// and returns true if more packets follow
+func parseMonlistResponse(data []byte) (more, ok bool) {
+	if len(data) < 8 || data[0]&flagResponse == 0 || data[0]&0x07 != modePrivate || data[3] != reqMonGetList1 {
+		return false, false
+	}
+	// the error code is in the high 4 bits of the item count field
+	if data[4]>>4 != 0 {
+		return false, false
+	}
+	return data[0]&flagMore != 0, true
+}
@@ -0,0 +1,102 @@
+package ntp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientRequest(t *testing.T) {
+	t.Parallel()
+	packet := clientRequest()
+	require.Len(t, packet, packetSize)
+	require.Equal(t, byte(0x23), packet[0])
+}
+
+func TestMonlistRequest(t *testing.T) {
+	t.Parallel()
+	packet := monlistRequest()
+	require.Len(t, packet, packetSize)
+	require.Equal(t, []byte{0x17, 0x00, 0x03, 0x2a}, packet[:4])
+}
+
+func TestParseServerResponse(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		data     []byte
+		expected *serverResponse
+	}{
+		{
+			name:     "Stratum1",
+			data:     serverPacket(4, 1, []byte("GPS\x00")),
+			expected: &serverResponse{version: 4, stratum: 1, refID: "GPS"},
+		},
+		{
+			name:     "Stratum2",
+			data:     serverPacket(3, 2, []byte{192, 168, 0, 1}),
+			expected: &serverResponse{version: 3, stratum: 2, refID: "192.168.0.1"},
+		},
+		{
+			name:     "KissOfDeath",
+			data:     serverPacket(4, 0, []byte("RATE")),
+			expected: &serverResponse{version: 4, stratum: 0, refID: "RATE"},
+		},
+		{
+			name: "ClientMode",
+			data: clientRequest(),
+		},
+		{
+			name: "TooShort",
+			data: serverPacket(4, 1, []byte("GPS\x00"))[:20],
+		},
+	}
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			resp, ok := parseServerResponse(tt.data)
+			require.Equal(t, tt.expected != nil, ok)
+			require.Equal(t, tt.expected, resp)
+		})
+	}
+}
+
+func TestParseMonlistResponse(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		data []byte
+		more bool
+		ok   bool
+	}{
+		{
+			name: "LastPacket",
+			data: monlistPacket(false, 0),
+			ok:   true,
+		},
+		{
+			name: "MorePackets",
+			data: monlistPacket(true, 0),
+			more: true,
+			ok:   true,
+		},
+		{
+			name: "Error",
+			data: monlistPacket(false, 4),
+		},
+		{
+			name: "Request",
+			data: monlistRequest(),
+		},
+	}
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			more, ok := parseMonlistResponse(tt.data)
+			require.Equal(t, tt.more, more)
+			require.Equal(t, tt.ok, ok)
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package openvpn
+
+import (
+	"encoding/binary"
+	"errors"
+
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+// opcodes of OpenVPN control channel packets, the opcode is stored in the high 5 bits of the first byte
+// and the key ID in the low 3 bits
+const (
+	opControlHardResetClientV2 = 7
+	opControlHardResetServerV2 = 8
+
+	sessionIDSize = 8
+	packetIDSize  = 4
+	opcodeShift   = 3
+)
+
+var errPacket = scan.NewError(scan.ErrParse, errors.New("invalid OpenVPN packet"))
+
+// hardResetClient returns the P_CONTROL_HARD_RESET_CLIENT_V2 packet without the tls-auth HMAC:
+// the opcode with key ID 0, the session ID, the empty ACK array and the message packet ID 0
+func hardResetClient(sessionID uint64) []byte {
+	result := make([]byte, 1+sessionIDSize+1+packetIDSize)
+	result[0] = opControlHardResetClientV2 << opcodeShift
+	binary.BigEndian.PutUint64(result[1:], sessionID)
+	return result
+}
+
+type hardResetServer struct {
+	sessionID uint64
+	// acks are packet IDs acknowledged by the server
+	acks []uint32
+	// remoteSessionID is the session ID of the client, it is present if there are ACKs
+	remoteSessionID uint64
+}
+
+// parseHardResetServer parses the P_CONTROL_HARD_RESET_SERVER_V2 packet without the tls-auth HMAC
+func parseHardResetServer(data []byte) (*hardResetServer, error) {
+	if len(data) < 1+sessionIDSize+1 || data[0]>>opcodeShift != opControlHardResetServerV2 {
+		return nil, errPacket
+	}
+	resp := &hardResetServer{sessionID: binary.BigEndian.Uint64(data[1:])}
+	data = data[1+sessionIDSize:]
+	ackCount := int(data[0])
+	data = data[1:]
+	if ackCount == 0 {
+		return resp, nil
+	}
+	if len(data) < ackCount*packetIDSize+sessionIDSize {
+		return nil, errPacket
+	}
+	for i := 0; i < ackCount; i++ {
+		resp.acks = append(resp.acks, binary.BigEndian.Uint32(data[i*packetIDSize:]))
+	}
+	resp.remoteSessionID = binary.BigEndian.Uint64(data[ackCount*packetIDSize:])
+	return resp, nil
+}
@@ -0,0 +1,80 @@
+package openvpn
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHardResetClient(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, []byte{
+		0x38, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08,
+		0x00, 0x00, 0x00, 0x00, 0x00,
+	}, hardResetClient(0x0102030405060708))
+}
+
+func TestParseHardResetServer(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		data     []byte
+		expected *hardResetServer
+	}{
+		{
+			name: "Acked",
+			data: []byte{
+				0x40, 0xa1, 0xa2, 0xa3, 0xa4, 0xa5, 0xa6, 0xa7, 0xa8,
+				0x01, 0x00, 0x00, 0x00, 0x00,
+				0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08,
+				0x00, 0x00, 0x00, 0x00,
+			},
+			expected: &hardResetServer{sessionID: 0xa1a2a3a4a5a6a7a8, acks: []uint32{0},
+				remoteSessionID: 0x0102030405060708},
+		},
+		{
+			name: "NotAcked",
+			data: []byte{
+				0x41, 0xa1, 0xa2, 0xa3, 0xa4, 0xa5, 0xa6, 0xa7, 0xa8,
+				0x00, 0x00, 0x00, 0x00, 0x00,
+			},
+			expected: &hardResetServer{sessionID: 0xa1a2a3a4a5a6a7a8},
+		},
+	}
+
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			resp, err := parseHardResetServer(tt.data)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, resp)
+		})
+	}
+}
+
+func TestParseHardResetServerInvalid(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{name: "Short", data: []byte{0x40, 0xa1, 0xa2}},
+		{name: "ClientReset", data: hardResetClient(1)},
+		{name: "ShortACKs", data: []byte{0x40, 0xa1, 0xa2, 0xa3, 0xa4, 0xa5, 0xa6, 0xa7, 0xa8, 0x02, 0x00, 0x00, 0x00, 0x00}},
+	}
+
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := parseHardResetServer(tt.data)
+			require.ErrorIs(t, err, errPacket)
+		})
+	}
+}
@@ -0,0 +1,152 @@
+package openvpn
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+const (
+	ScanType = "openvpn"
+
+	defaultDataTimeout = 1 * time.Second
+	defaultRetries     = 1
+	// maxPacketSize is enough for the server reset, TLS records are not sent before the client acknowledges it
+	maxPacketSize = 1500
+)
+
+type ScanResult struct {
+	ScanType string `json:"scan"`
+	IP       string `json:"ip"`
+	Port     uint16 `json:"port"`
+	// SessionID is the hex session ID of the server reset
+	SessionID string `json:"session_id"`
+	// Acked is set if the server acknowledged the client reset with the session ID of the client
+	Acked bool `json:"acked"`
+}
+
+func (r *ScanResult) String() string {
+	result := fmt.Sprintf("%-20s %-5d session %s", r.IP, r.Port, r.SessionID)
+	if r.Acked {
+		result += " acked"
+	}
+	return result
+}
+
+func (r *ScanResult) ID() string {
+	return fmt.Sprintf("%s:%d", r.IP, r.Port)
+}
+
+func (r *ScanResult) MarshalJSON() ([]byte, error) {
+	// Type definition for the recursive call
+	type JScanResult ScanResult
+	// This works because JScanResult doesn't have a MarshalJSON function associated with it
+	return json.Marshal(JScanResult(*r))
+}
+
+// Scanner sends the P_CONTROL_HARD_RESET_CLIENT_V2 packet to each target over UDP and reports servers
+// that answered with the P_CONTROL_HARD_RESET_SERVER_V2 packet. Servers with tls-auth or tls-crypt
+// silently drop packets without the valid HMAC, so they can't be told apart from filtered ports
+// and are not reported.
+type Scanner struct {
+	dataTimeout time.Duration
+	retries     int
+}
+
+// Assert that openvpn.Scanner conforms to the scan.Scanner interface
+var _ scan.Scanner = (*Scanner)(nil)
+
+type ScannerOption func(*Scanner)
+
+// WithDataTimeout sets the time to wait for a response to each request
+func WithDataTimeout(timeout time.Duration) ScannerOption {
+	return func(s *Scanner) {
+		s.dataTimeout = timeout
+	}
+}
+
+// WithRetries sets the number of additional requests if there is no response
+func WithRetries(retries int) ScannerOption {
+	return func(s *Scanner) {
+		s.retries = retries
+	}
+}
+
+func NewScanner(opts ...ScannerOption) *Scanner {
+	s := &Scanner{
+		dataTimeout: defaultDataTimeout,
+		retries:     defaultRetries,
+	}
+	for _, o := range opts {
+		o(s)
+	}
+	return s
+}
+
+func (s *Scanner) Scan(ctx context.Context, r *scan.Request) (scan.Result, error) {
+	result, err := s.scanTarget(ctx, r)
+	return result, scan.WrapError(err)
+}
+
+func (s *Scanner) scanTarget(ctx context.Context, r *scan.Request) (result scan.Result, err error) {
+	addr := net.JoinHostPort(r.DstIP.String(), strconv.Itoa(int(r.DstPort)))
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "udp", addr)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	sessionID := rand.Uint64()
+	buf := make([]byte, maxPacketSize)
+	for i := 0; i <= s.retries; i++ {
+		if _, err = conn.Write(hardResetClient(sessionID)); err != nil {
+			return
+		}
+		if err = conn.SetReadDeadline(time.Now().Add(s.dataTimeout)); err != nil {
+			return
+		}
+		var resp *hardResetServer
+		if resp, err = readResponse(conn, buf, sessionID); err == nil {
+			return &ScanResult{
+				ScanType:  ScanType,
+				IP:        r.DstIP.String(),
+				Port:      r.DstPort,
+				SessionID: fmt.Sprintf("%016x", resp.sessionID),
+				Acked:     len(resp.acks) > 0,
+			}, nil
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		var netErr net.Error
+		// ICMP port unreachable and other errors are not retried
+		if !errors.As(err, &netErr) || !netErr.Timeout() {
+			return nil, err
+		}
+	}
+	return nil, nil
+}
+
+// readResponse reads datagrams until the server reset arrives, resets that acknowledge other sessions
+// and other datagrams are skipped
+func readResponse(conn net.Conn, buf []byte, sessionID uint64) (*hardResetServer, error) {
+	for {
+		n, err := conn.Read(buf)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := parseHardResetServer(buf[:n])
+		if err != nil || (len(resp.acks) > 0 && resp.remoteSessionID != sessionID) {
+			continue
+		}
+		return resp, nil
+	}
+}
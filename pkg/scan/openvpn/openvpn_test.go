@@ -0,0 +1,80 @@
+package openvpn
+
+import (
+	"context"
+	"encoding/binary"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+// startFakeServer starts UDP OpenVPN server without tls-auth that answers client resets,
+// the silent server drops them like servers with tls-auth
+func startFakeServer(t *testing.T, silent bool) *scan.Request {
+	t.Helper()
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	go func() {
+		buf := make([]byte, maxPacketSize)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			if silent || n < 1+sessionIDSize || buf[0]>>opcodeShift != opControlHardResetClientV2 {
+				continue
+			}
+			clientSessionID := binary.BigEndian.Uint64(buf[1:])
+			// reset of another session is skipped by the scanner
+			_, _ = conn.WriteTo(serverReset(clientSessionID+1), addr)
+			_, _ = conn.WriteTo(serverReset(clientSessionID), addr)
+		}
+	}()
+	addr := conn.LocalAddr().(*net.UDPAddr)
+	return &scan.Request{DstIP: addr.IP, DstPort: uint16(addr.Port)}
+}
+
+func serverReset(clientSessionID uint64) []byte {
+	data := []byte{opControlHardResetServerV2 << opcodeShift}
+	data = binary.BigEndian.AppendUint64(data, 0xa1a2a3a4a5a6a7a8)
+	data = append(data, 1, 0, 0, 0, 0)
+	data = binary.BigEndian.AppendUint64(data, clientSessionID)
+	return append(data, 0, 0, 0, 0)
+}
+
+func TestScan(t *testing.T) {
+	t.Parallel()
+
+	req := startFakeServer(t, false)
+	result, err := NewScanner(WithDataTimeout(time.Second)).Scan(context.Background(), req)
+	require.NoError(t, err)
+	require.Equal(t, &ScanResult{
+		ScanType:  ScanType,
+		IP:        req.DstIP.String(),
+		Port:      req.DstPort,
+		SessionID: "a1a2a3a4a5a6a7a8",
+		Acked:     true,
+	}, result)
+}
+
+func TestScanNoResponse(t *testing.T) {
+	t.Parallel()
+
+	req := startFakeServer(t, true)
+	result, err := NewScanner(WithDataTimeout(50*time.Millisecond)).Scan(context.Background(), req)
+	require.NoError(t, err)
+	require.Nil(t, result)
+}
+
+func TestScanResultString(t *testing.T) {
+	t.Parallel()
+
+	result := &ScanResult{ScanType: ScanType, IP: "192.168.0.1", Port: 1194, SessionID: "a1a2a3a4a5a6a7a8", Acked: true}
+	require.Equal(t, "192.168.0.1          1194  session a1a2a3a4a5a6a7a8 acked", result.String())
+	require.Equal(t, "192.168.0.1:1194", result.ID())
+}
@@ -0,0 +1,259 @@
+package scan
+
+import (
+	"container/heap"
+	"context"
+	"net"
+	"sync"
+	"time"
+)
+
+const (
+	defaultPacerWindow      = 1 * time.Second
+	defaultPacerMaxInterval = 2 * time.Second
+	defaultPacerMaxPending  = 10000
+	// minPacerProbes is the minimum number of probes in the window to evaluate the response rate
+	minPacerProbes = 4
+)
+
+type hostPace struct {
+	windowStart time.Time
+	probes      int
+	responses   int
+	// interval is the delay between probes to the host, zero if the host is not rate limited
+	interval time.Duration
+	// next is the earliest time of the next probe to the host
+	next time.Time
+}
+
+// HostPacer detects hosts that rate limit ICMP replies and spaces out probes to them.
+// Rate limited hosts answer a burst of probes and then only a few probes per second,
+// e.g. Linux sends about one ICMP error per second to the same destination by default (net.ipv4.icmp_ratelimit),
+// so probes sent faster than that get no reply and closed ports look open or filtered.
+// A host is considered rate limited if it answered less than half of the probes of the observation window,
+// but still answered some of them. The delay between probes to the host is then set to the observed
+// interval between its responses, and it is decreased by a quarter after windows without losses.
+// Hosts that silently drop most probes and answer only a few of them are slowed down as well,
+// it costs scan time but no results.
+type HostPacer struct {
+	mu          sync.Mutex
+	window      time.Duration
+	maxInterval time.Duration
+	hosts       map[string]*hostPace
+	lastSweep   time.Time
+	now         func() time.Time
+}
+
+type HostPacerOption func(p *HostPacer)
+
+// WithPacerWindow sets the observation window of the response rate
+func WithPacerWindow(window time.Duration) HostPacerOption {
+	return func(p *HostPacer) {
+		p.window = window
+	}
+}
+
+// WithPacerMaxInterval sets the maximum delay between probes to one host
+func WithPacerMaxInterval(maxInterval time.Duration) HostPacerOption {
+	return func(p *HostPacer) {
+		p.maxInterval = maxInterval
+	}
+}
+
+func NewHostPacer(opts ...HostPacerOption) *HostPacer {
+	p := &HostPacer{
+		window:      defaultPacerWindow,
+		maxInterval: defaultPacerMaxInterval,
+		hosts:       make(map[string]*hostPace),
+		now:         time.Now,
+	}
+	for _, o := range opts {
+		o(p)
+	}
+	return p
+}
+
+// Reserve returns the time to send the next probe to the host and counts the probe
+func (p *HostPacer) Reserve(ip net.IP) time.Time {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	now := p.now()
+	p.sweep(now)
+	key := ip.String()
+	h, ok := p.hosts[key]
+	if !ok {
+		h = &hostPace{windowStart: now}
+		p.hosts[key] = h
+	}
+	at := now
+	if h.next.After(now) {
+		at = h.next
+	}
+	if at.Sub(h.windowStart) >= p.window {
+		p.adapt(h)
+		h.windowStart = at
+	}
+	h.probes++
+	h.next = at.Add(h.interval)
+	return at
+}
+
+// Response counts the response of the host
+func (p *HostPacer) Response(ip net.IP) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if h, ok := p.hosts[ip.String()]; ok {
+		h.responses++
+	}
+}
+
+// Interval returns the current delay between probes to the host
+func (p *HostPacer) Interval(ip net.IP) time.Duration {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if h, ok := p.hosts[ip.String()]; ok {
+		return h.interval
+	}
+	return 0
+}
+
+// adapt evaluates the response rate of the finished window and starts a new window
+func (p *HostPacer) adapt(h *hostPace) {
+	defer func() {
+		h.probes = 0
+		h.responses = 0
+	}()
+	if h.probes < minPacerProbes && h.interval == 0 {
+		return
+	}
+	if h.responses > 0 && h.responses*2 < h.probes {
+		interval := p.window / time.Duration(h.responses)
+		if interval > h.interval {
+			h.interval = interval
+		}
+		if h.interval > p.maxInterval {
+			h.interval = p.maxInterval
+		}
+		return
+	}
+	// the delay is decreased slowly to probe whether the host allows a higher rate again
+	if h.responses >= h.probes {
+		h.interval -= h.interval / 4
+		if h.interval < p.window/100 {
+			h.interval = 0
+		}
+	}
+}
+
+// sweep removes idle hosts without the probe delay, so that memory doesn't grow with the number of targets
+func (p *HostPacer) sweep(now time.Time) {
+	if now.Sub(p.lastSweep) < 4*p.window {
+		return
+	}
+	p.lastSweep = now
+	for key, h := range p.hosts {
+		if h.interval == 0 && now.Sub(h.next) >= 4*p.window {
+			delete(p.hosts, key)
+		}
+	}
+}
+
+type pacedRequest struct {
+	at      time.Time
+	request *Request
+}
+
+// pacedQueue is a min-heap of requests by their send time
+type pacedQueue []*pacedRequest
+
+func (q pacedQueue) Len() int            { return len(q) }
+func (q pacedQueue) Less(i, j int) bool  { return q[i].at.Before(q[j].at) }
+func (q pacedQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *pacedQueue) Push(x interface{}) { *q = append(*q, x.(*pacedRequest)) }
+func (q *pacedQueue) Pop() interface{} {
+	old := *q
+	n := len(old)
+	item := old[n-1]
+	old[n-1] = nil
+	*q = old[:n-1]
+	return item
+}
+
+// NewPacedRequestGenerator delays requests to rate limited hosts according to the pacer,
+// requests to other hosts are passed through without delay
+func NewPacedRequestGenerator(delegate RequestGenerator, pacer *HostPacer) RequestGenerator {
+	return &pacedRequestGenerator{delegate: delegate, pacer: pacer, maxPending: defaultPacerMaxPending}
+}
+
+type pacedRequestGenerator struct {
+	delegate RequestGenerator
+	pacer    *HostPacer
+	// maxPending limits the number of delayed requests
+	maxPending int
+}
+
+func (rg *pacedRequestGenerator) GenerateRequests(ctx context.Context, r *Range) (<-chan *Request, error) {
+	in, err := rg.delegate.GenerateRequests(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	out := make(chan *Request)
+	go func() {
+		defer close(out)
+		var delayed pacedQueue
+		// ready is a FIFO queue of requests that can be sent now
+		var ready []*Request
+
+		for in != nil || len(delayed) > 0 || len(ready) > 0 {
+			var outc chan<- *Request
+			var next *Request
+			if len(ready) > 0 {
+				outc = out
+				next = ready[0]
+			}
+			inc := in
+			if len(delayed)+len(ready) >= rg.maxPending {
+				inc = nil
+			}
+			var timer *time.Timer
+			var timerc <-chan time.Time
+			if len(delayed) > 0 {
+				timer = time.NewTimer(time.Until(delayed[0].at))
+				timerc = timer.C
+			}
+
+			select {
+			case <-ctx.Done():
+				if timer != nil {
+					timer.Stop()
+				}
+				return
+			case request, ok := <-inc:
+				if !ok {
+					in = nil
+					break
+				}
+				if request.Err != nil || request.DstIP == nil {
+					ready = append(ready, request)
+					break
+				}
+				at := rg.pacer.Reserve(request.DstIP)
+				if time.Until(at) <= 0 {
+					ready = append(ready, request)
+					break
+				}
+				heap.Push(&delayed, &pacedRequest{at: at, request: request})
+			case outc <- next:
+				ready = ready[1:]
+			case <-timerc:
+				for len(delayed) > 0 && time.Until(delayed[0].at) <= 0 {
+					ready = append(ready, heap.Pop(&delayed).(*pacedRequest).request)
+				}
+			}
+			if timer != nil {
+				timer.Stop()
+			}
+		}
+	}()
+	return out, nil
+}
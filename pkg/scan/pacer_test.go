@@ -0,0 +1,180 @@
+package scan
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func newTestHostPacer(opts ...HostPacerOption) (*HostPacer, *fakeClock) {
+	clock := &fakeClock{now: time.Date(2021, 5, 1, 10, 0, 0, 0, time.UTC)}
+	p := NewHostPacer(opts...)
+	p.now = clock.Now
+	return p, clock
+}
+
+func TestHostPacerDetectsRateLimit(t *testing.T) {
+	t.Parallel()
+	p, clock := newTestHostPacer()
+	host := net.IPv4(192, 168, 0, 1).To4()
+
+	start := clock.now
+	for i := 0; i < 10; i++ {
+		require.Equal(t, start, p.Reserve(host))
+	}
+	// the burst of responses, other probes are lost
+	p.Response(host)
+	p.Response(host)
+	require.Zero(t, p.Interval(host))
+
+	clock.now = start.Add(time.Second)
+	require.Equal(t, clock.now, p.Reserve(host))
+	require.Equal(t, 500*time.Millisecond, p.Interval(host))
+	require.Equal(t, clock.now.Add(500*time.Millisecond), p.Reserve(host))
+	require.Equal(t, clock.now.Add(time.Second), p.Reserve(host))
+
+	// other hosts are not delayed
+	other := net.IPv4(192, 168, 0, 2).To4()
+	require.Equal(t, clock.now, p.Reserve(other))
+	require.Equal(t, clock.now, p.Reserve(other))
+}
+
+func TestHostPacerRecovers(t *testing.T) {
+	t.Parallel()
+	p, clock := newTestHostPacer()
+	host := net.IPv4(192, 168, 0, 1).To4()
+
+	for i := 0; i < 8; i++ {
+		p.Reserve(host)
+	}
+	p.Response(host)
+	clock.now = clock.now.Add(time.Second)
+	at := p.Reserve(host)
+	require.Equal(t, time.Second, p.Interval(host))
+
+	// all probes of the window are answered
+	p.Response(host)
+	clock.now = at.Add(time.Second)
+	p.Reserve(host)
+	require.Equal(t, 750*time.Millisecond, p.Interval(host))
+}
+
+func TestHostPacerNotRateLimited(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name      string
+		probes    int
+		responses int
+	}{
+		{
+			name:      "NoResponses",
+			probes:    10,
+			responses: 0,
+		},
+		{
+			name:      "AllResponses",
+			probes:    10,
+			responses: 10,
+		},
+		{
+			name:      "FewProbes",
+			probes:    minPacerProbes - 1,
+			responses: 1,
+		},
+	}
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			p, clock := newTestHostPacer()
+			host := net.IPv4(192, 168, 0, 1).To4()
+			for i := 0; i < tt.probes; i++ {
+				p.Reserve(host)
+			}
+			for i := 0; i < tt.responses; i++ {
+				p.Response(host)
+			}
+			clock.now = clock.now.Add(time.Second)
+			require.Equal(t, clock.now, p.Reserve(host))
+			require.Zero(t, p.Interval(host))
+		})
+	}
+}
+
+func TestHostPacerMaxInterval(t *testing.T) {
+	t.Parallel()
+	p, clock := newTestHostPacer(WithPacerWindow(4*time.Second), WithPacerMaxInterval(time.Second))
+	host := net.IPv4(192, 168, 0, 1).To4()
+
+	for i := 0; i < 10; i++ {
+		p.Reserve(host)
+	}
+	p.Response(host)
+	clock.now = clock.now.Add(4 * time.Second)
+	p.Reserve(host)
+	require.Equal(t, time.Second, p.Interval(host))
+}
+
+func TestHostPacerSweepsIdleHosts(t *testing.T) {
+	t.Parallel()
+	p, clock := newTestHostPacer()
+
+	p.Reserve(net.IPv4(192, 168, 0, 1).To4())
+	clock.now = clock.now.Add(5 * time.Second)
+	p.Reserve(net.IPv4(192, 168, 0, 2).To4())
+	require.Len(t, p.hosts, 1)
+	require.Contains(t, p.hosts, "192.168.0.2")
+}
+
+func TestPacedRequestGenerator(t *testing.T) {
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	limited := net.IPv4(192, 168, 0, 1).To4()
+	reqA1 := &Request{DstIP: limited, DstPort: 22}
+	reqA2 := &Request{DstIP: limited, DstPort: 80}
+	reqA3 := &Request{DstIP: limited, DstPort: 443}
+	reqB := &Request{DstIP: net.IPv4(192, 168, 0, 2).To4(), DstPort: 22}
+	reqErr := &Request{Err: errors.New("invalid ip")}
+
+	reqgen := NewMockRequestGenerator(ctrl)
+	reqgen.EXPECT().GenerateRequests(gomock.Any(), gomock.Any()).
+		Return(newRequestChan(reqA1, reqA2, reqA3, reqB, reqErr), nil)
+
+	const interval = 100 * time.Millisecond
+	pacer := NewHostPacer()
+	pacer.hosts[limited.String()] = &hostPace{windowStart: time.Now(), interval: interval}
+
+	requests, err := NewPacedRequestGenerator(reqgen, pacer).GenerateRequests(ctx, &Range{})
+	require.NoError(t, err)
+
+	start := time.Now()
+	require.Equal(t, reqA1, readScheduledRequest(t, requests))
+	// requests to other hosts and errors are not delayed
+	require.Equal(t, reqB, readScheduledRequest(t, requests))
+	require.Equal(t, reqErr, readScheduledRequest(t, requests))
+	require.Less(t, time.Since(start), interval)
+
+	require.Equal(t, reqA2, readScheduledRequest(t, requests))
+	require.GreaterOrEqual(t, time.Since(start), interval)
+	require.Equal(t, reqA3, readScheduledRequest(t, requests))
+	require.GreaterOrEqual(t, time.Since(start), 2*interval)
+
+	_, ok := <-requests
+	require.False(t, ok, "requests channel is not closed")
+}
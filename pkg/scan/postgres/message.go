@@ -0,0 +1,177 @@
+package postgres
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+// PostgreSQL frontend/backend protocol fields, see https://www.postgresql.org/docs/current/protocol-message-formats.html
+const (
+	sslRequestCode  = 80877103
+	protocolVersion = 3 << 16
+	sslAccepted     = 'S'
+	sslRefused      = 'N'
+
+	msgAuthentication  = 'R'
+	msgErrorResponse   = 'E'
+	msgParameterStatus = 'S'
+	msgReadyForQuery   = 'Z'
+	msgTerminate       = 'X'
+
+	authOK                = 0
+	authKerberosV5        = 2
+	authCleartextPassword = 3
+	authMD5Password       = 5
+	authGSS               = 7
+	authSSPI              = 9
+	authSASL              = 10
+
+	// maxMessageSize limits the size of messages read before authentication
+	maxMessageSize = 1 << 16
+	// invalidAuthorization is the SQLSTATE of errors of the client authentication, e.g. a missing pg_hba.conf entry
+	invalidAuthorization = "28000"
+)
+
+var errProtocol = scan.NewError(scan.ErrParse, errors.New("invalid PostgreSQL response"))
+
+// authMethods are names of authentication request codes
+var authMethods = map[uint32]string{
+	authOK:                "trust",
+	authKerberosV5:        "kerberos5",
+	authCleartextPassword: "password",
+	authMD5Password:       "md5",
+	authGSS:               "gss",
+	authSSPI:              "sspi",
+	authSASL:              "sasl",
+}
+
+func authMethodName(code uint32) string {
+	if name, ok := authMethods[code]; ok {
+		return name
+	}
+	return fmt.Sprintf("unknown(%d)", code)
+}
+
+// serverError is the ErrorResponse message of the server
+type serverError struct {
+	code    string
+	message string
+}
+
+func (e *serverError) Error() string {
+	return fmt.Sprintf("%s: %s", e.code, e.message)
+}
+
+// encryptionRequired reports whether the server rejected the connection only because it wasn't encrypted,
+// pg_hba.conf has hostssl entries for the client then
+func (e *serverError) encryptionRequired() bool {
+	return e.code == invalidAuthorization &&
+		(strings.Contains(e.message, "no encryption") || strings.Contains(e.message, "SSL off"))
+}
+
+func writeSSLRequest(w io.Writer) error {
+	data := make([]byte, 8)
+	binary.BigEndian.PutUint32(data, 8)
+	binary.BigEndian.PutUint32(data[4:], sslRequestCode)
+	_, err := w.Write(data)
+	return err
+}
+
+// writeStartup writes the StartupMessage with user and database parameters
+func writeStartup(w io.Writer, user, database string) error {
+	var buf bytes.Buffer
+	buf.Write(make([]byte, 4))
+	_ = binary.Write(&buf, binary.BigEndian, uint32(protocolVersion))
+	for _, s := range []string{"user", user, "database", database} {
+		buf.WriteString(s)
+		buf.WriteByte(0)
+	}
+	buf.WriteByte(0)
+	data := buf.Bytes()
+	binary.BigEndian.PutUint32(data, uint32(len(data)))
+	_, err := w.Write(data)
+	return err
+}
+
+func writeTerminate(w io.Writer) error {
+	_, err := w.Write([]byte{msgTerminate, 0, 0, 0, 4})
+	return err
+}
+
+// readMessage reads the type and the payload of the backend message
+func readMessage(r *bufio.Reader) (msgType byte, payload []byte, err error) {
+	header := make([]byte, 5)
+	if _, err = io.ReadFull(r, header); err != nil {
+		return
+	}
+	msgType = header[0]
+	size := binary.BigEndian.Uint32(header[1:])
+	if size < 4 || size > maxMessageSize {
+		return 0, nil, fmt.Errorf("%w: message size %d", errProtocol, size)
+	}
+	payload = make([]byte, size-4)
+	_, err = io.ReadFull(r, payload)
+	return
+}
+
+// authRequest is the Authentication message of the server
+type authRequest struct {
+	code uint32
+	// mechanisms are SASL authentication mechanisms, e.g. SCRAM-SHA-256
+	mechanisms []string
+}
+
+func parseAuthRequest(payload []byte) (*authRequest, error) {
+	if len(payload) < 4 {
+		return nil, fmt.Errorf("%w: authentication request", errProtocol)
+	}
+	auth := &authRequest{code: binary.BigEndian.Uint32(payload)}
+	if auth.code != authSASL {
+		return auth, nil
+	}
+	auth.mechanisms = splitStrings(payload[4:])
+	return auth, nil
+}
+
+// parseError parses fields of the ErrorResponse message, each field is the type byte and the string
+func parseError(payload []byte) *serverError {
+	e := &serverError{}
+	for _, field := range splitStrings(payload) {
+		switch field[0] {
+		case 'C':
+			e.code = field[1:]
+		case 'M':
+			e.message = field[1:]
+		}
+	}
+	return e
+}
+
+// parseParameterStatus parses the name and the value of the run-time parameter
+func parseParameterStatus(payload []byte) (name, value string) {
+	params := splitStrings(payload)
+	if len(params) < 2 {
+		return
+	}
+	return params[0], params[1]
+}
+
+// splitStrings splits the list of null-terminated strings until the empty string or the end of data
+func splitStrings(data []byte) (result []string) {
+	for len(data) > 0 {
+		i := bytes.IndexByte(data, 0)
+		if i <= 0 {
+			return
+		}
+		result = append(result, string(data[:i]))
+		data = data[i+1:]
+	}
+	return
+}
@@ -0,0 +1,109 @@
+package postgres
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// newMessage builds the backend message with the type and the payload of null-terminated strings
+func newMessage(msgType byte, payload []byte) []byte {
+	data := make([]byte, 5, 5+len(payload))
+	data[0] = msgType
+	binary.BigEndian.PutUint32(data[1:], uint32(4+len(payload)))
+	return append(data, payload...)
+}
+
+func newAuthMessage(code uint32, extra ...string) []byte {
+	payload := binary.BigEndian.AppendUint32(nil, code)
+	for _, s := range extra {
+		payload = append(append(payload, s...), 0)
+	}
+	if code == authSASL {
+		payload = append(payload, 0)
+	}
+	return newMessage(msgAuthentication, payload)
+}
+
+func newErrorMessage(code, message string) []byte {
+	payload := []byte("SFATAL\x00VFATAL\x00C" + code + "\x00M" + message + "\x00Fauth.c\x00\x00")
+	return newMessage(msgErrorResponse, payload)
+}
+
+func newParameterStatus(name, value string) []byte {
+	return newMessage(msgParameterStatus, []byte(name+"\x00"+value+"\x00"))
+}
+
+func TestWriteSSLRequest(t *testing.T) {
+	t.Parallel()
+	var buf bytes.Buffer
+	require.NoError(t, writeSSLRequest(&buf))
+	require.Equal(t, []byte{0, 0, 0, 8, 0x04, 0xd2, 0x16, 0x2f}, buf.Bytes())
+}
+
+func TestWriteStartup(t *testing.T) {
+	t.Parallel()
+	var buf bytes.Buffer
+	require.NoError(t, writeStartup(&buf, "postgres", "db"))
+	expected := append([]byte{0, 0, 0, 35, 0, 3, 0, 0}, "user\x00postgres\x00database\x00db\x00\x00"...)
+	require.Equal(t, expected, buf.Bytes())
+}
+
+func TestReadMessage(t *testing.T) {
+	t.Parallel()
+	r := bufio.NewReader(bytes.NewReader(append(newAuthMessage(authMD5Password, "salt"), 'Z', 0, 0, 0, 1)))
+	msgType, payload, err := readMessage(r)
+	require.NoError(t, err)
+	require.Equal(t, byte(msgAuthentication), msgType)
+	require.Equal(t, []byte{0, 0, 0, 5, 's', 'a', 'l', 't', 0}, payload)
+
+	_, _, err = readMessage(r)
+	require.ErrorIs(t, err, errProtocol)
+
+	_, _, err = readMessage(bufio.NewReader(bytes.NewReader([]byte("HTTP/1.1 400 Bad Request\r\n"))))
+	require.ErrorIs(t, err, errProtocol)
+}
+
+func TestParseAuthRequest(t *testing.T) {
+	t.Parallel()
+	auth, err := parseAuthRequest(newAuthMessage(authSASL, "SCRAM-SHA-256-PLUS", "SCRAM-SHA-256")[5:])
+	require.NoError(t, err)
+	require.Equal(t, &authRequest{code: authSASL, mechanisms: []string{"SCRAM-SHA-256-PLUS", "SCRAM-SHA-256"}}, auth)
+
+	auth, err = parseAuthRequest(newAuthMessage(authMD5Password)[5:])
+	require.NoError(t, err)
+	require.Equal(t, &authRequest{code: authMD5Password}, auth)
+
+	_, err = parseAuthRequest([]byte{0, 0})
+	require.ErrorIs(t, err, errProtocol)
+}
+
+func TestParseError(t *testing.T) {
+	t.Parallel()
+	e := parseError(newErrorMessage(invalidAuthorization,
+		`no pg_hba.conf entry for host "10.0.0.1", user "postgres", database "postgres", no encryption`)[5:])
+	require.Equal(t, invalidAuthorization, e.code)
+	require.True(t, e.encryptionRequired())
+	require.Equal(t, `28000: no pg_hba.conf entry for host "10.0.0.1", user "postgres", database "postgres", no encryption`, e.Error())
+
+	e = parseError(newErrorMessage(invalidAuthorization,
+		`no pg_hba.conf entry for host "10.0.0.1", user "postgres", database "postgres", SSL off`)[5:])
+	require.True(t, e.encryptionRequired())
+
+	e = parseError(newErrorMessage(invalidAuthorization,
+		`no pg_hba.conf entry for host "10.0.0.1", user "postgres", database "postgres", SSL encryption`)[5:])
+	require.False(t, e.encryptionRequired())
+
+	e = parseError(newErrorMessage("53300", "sorry, too many clients already")[5:])
+	require.False(t, e.encryptionRequired())
+}
+
+func TestAuthMethodName(t *testing.T) {
+	t.Parallel()
+	require.Equal(t, "trust", authMethodName(authOK))
+	require.Equal(t, "md5", authMethodName(authMD5Password))
+	require.Equal(t, "unknown(42)", authMethodName(42))
+}
@@ -0,0 +1,268 @@
+//go:generate easyjson -output_filename result_easyjson.go postgres.go
+
+package postgres
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+const (
+	ScanType = "postgres"
+
+	defaultDialTimeout = 2 * time.Second
+	defaultDataTimeout = 2 * time.Second
+
+	// defaultUser is the user of the StartupMessage, the server requests authentication without checking it
+	defaultUser = "postgres"
+)
+
+//easyjson:json
+type ScanResult struct {
+	ScanType string `json:"scan"`
+	IP       string `json:"ip"`
+	Port     uint16 `json:"port"`
+	// TLS is set if the server accepted the SSLRequest
+	TLS bool `json:"tls"`
+	// TLSRequired is set if the server rejected the unencrypted connection of the client
+	TLSRequired bool `json:"tls_required"`
+	// Auth is the authentication method requested by the server, e.g. trust, md5, scram-sha-256
+	Auth string `json:"auth,omitempty"`
+	// SASLMechanisms are offered by the server for the sasl authentication method
+	SASLMechanisms []string `json:"sasl_mechanisms,omitempty"`
+	// Version is reported by servers that don't require authentication
+	Version string `json:"version,omitempty"`
+	// Error is sent by the server instead of the authentication request, e.g. if pg_hba.conf rejects the client
+	Error string `json:"error,omitempty"`
+}
+
+func (r *ScanResult) String() string {
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "%-20s %-5d", r.IP, r.Port)
+	switch {
+	case r.TLSRequired:
+		buf.WriteString(" tls-required")
+	case r.TLS:
+		buf.WriteString(" tls")
+	default:
+		buf.WriteString(" no-tls")
+	}
+	if len(r.Auth) > 0 {
+		fmt.Fprintf(&buf, " auth %s", r.Auth)
+	}
+	if len(r.Version) > 0 {
+		fmt.Fprintf(&buf, " %s", r.Version)
+	}
+	if len(r.Error) > 0 {
+		fmt.Fprintf(&buf, " error %q", r.Error)
+	}
+	return buf.String()
+}
+
+func (r *ScanResult) ID() string {
+	return fmt.Sprintf("%s:%d", r.IP, r.Port)
+}
+
+// Scanner sends the SSLRequest to find out whether PostgreSQL servers support TLS
+// and the StartupMessage to find out the authentication method they request,
+// the connection is closed before authentication
+type Scanner struct {
+	dialer      *net.Dialer
+	dataTimeout time.Duration
+}
+
+// Assert that postgres.Scanner conforms to the scan.Scanner interface
+var _ scan.Scanner = (*Scanner)(nil)
+
+type ScannerOption func(*Scanner)
+
+func WithDialTimeout(timeout time.Duration) ScannerOption {
+	return func(s *Scanner) {
+		s.dialer.Timeout = timeout
+	}
+}
+
+func WithDataTimeout(timeout time.Duration) ScannerOption {
+	return func(s *Scanner) {
+		s.dataTimeout = timeout
+	}
+}
+
+func NewScanner(opts ...ScannerOption) *Scanner {
+	s := &Scanner{
+		dialer: &net.Dialer{
+			Timeout: defaultDialTimeout,
+		},
+		dataTimeout: defaultDataTimeout,
+	}
+	for _, o := range opts {
+		o(s)
+	}
+	return s
+}
+
+// Scan sends the SSLRequest first. If the server refuses TLS, the StartupMessage is sent over
+// the same connection. Otherwise the StartupMessage is sent over TLS and then over another
+// unencrypted connection to find out whether the server requires TLS.
+func (s *Scanner) Scan(ctx context.Context, r *scan.Request) (scan.Result, error) {
+	result, err := s.scanTarget(ctx, r)
+	return result, scan.WrapError(err)
+}
+
+func (s *Scanner) scanTarget(ctx context.Context, r *scan.Request) (scan.Result, error) {
+	addr := net.JoinHostPort(r.DstIP.String(), strconv.Itoa(int(r.DstPort)))
+	conn, err := s.dial(ctx, addr)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	if err = writeSSLRequest(conn); err != nil {
+		return nil, err
+	}
+	resp := make([]byte, 1)
+	if _, err = conn.Read(resp); err != nil {
+		return nil, err
+	}
+
+	result := &ScanResult{
+		ScanType: ScanType,
+		IP:       r.DstIP.String(),
+		Port:     r.DstPort,
+	}
+	var info *startupInfo
+	switch resp[0] {
+	case sslRefused:
+		info, err = startup(conn)
+	case sslAccepted:
+		result.TLS = true
+		info, err = s.tlsStartup(ctx, addr, conn, result)
+	default:
+		return nil, fmt.Errorf("%w: SSLRequest response %q", errProtocol, resp)
+	}
+	if err = result.fill(info, err); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// tlsStartup sends the StartupMessage over TLS and over the unencrypted connection,
+// the response of the latter is reported unless the server requires TLS
+func (s *Scanner) tlsStartup(ctx context.Context, addr string, conn net.Conn, result *ScanResult) (*startupInfo, error) {
+	tlsInfo, tlsErr := startup(tls.Client(conn, &tls.Config{
+		InsecureSkipVerify: true,
+	}))
+	info, err := s.plainStartup(ctx, addr)
+	var serverErr *serverError
+	if errors.As(err, &serverErr) && serverErr.encryptionRequired() {
+		result.TLSRequired = true
+		return tlsInfo, tlsErr
+	}
+	if err != nil && !errors.As(err, &serverErr) && tlsErr == nil {
+		return tlsInfo, nil
+	}
+	return info, err
+}
+
+func (s *Scanner) dial(ctx context.Context, addr string) (net.Conn, error) {
+	conn, err := s.dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	if err = conn.SetDeadline(time.Now().Add(s.dataTimeout)); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return conn, nil
+}
+
+func (s *Scanner) plainStartup(ctx context.Context, addr string) (*startupInfo, error) {
+	conn, err := s.dial(ctx, addr)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	return startup(conn)
+}
+
+// fill sets the authentication method of the startup, errors of the server are reported in the result
+func (r *ScanResult) fill(info *startupInfo, err error) error {
+	var serverErr *serverError
+	if errors.As(err, &serverErr) {
+		r.Error = serverErr.Error()
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	r.Auth = authMethodName(info.auth.code)
+	r.SASLMechanisms = info.auth.mechanisms
+	for _, mechanism := range info.auth.mechanisms {
+		if mechanism == "SCRAM-SHA-256" {
+			r.Auth = "scram-sha-256"
+			break
+		}
+	}
+	r.Version = info.version
+	return nil
+}
+
+// startupInfo is the response of the server to the StartupMessage
+type startupInfo struct {
+	auth *authRequest
+	// version is the server_version parameter sent to clients that don't need to authenticate
+	version string
+}
+
+// startup sends the StartupMessage and reads the authentication request,
+// the ErrorResponse of the server is returned as serverError
+func startup(conn net.Conn) (*startupInfo, error) {
+	if err := writeStartup(conn, defaultUser, defaultUser); err != nil {
+		return nil, err
+	}
+	r := bufio.NewReader(conn)
+	msgType, payload, err := readMessage(r)
+	if err != nil {
+		return nil, err
+	}
+	switch msgType {
+	case msgErrorResponse:
+		return nil, parseError(payload)
+	case msgAuthentication:
+	default:
+		return nil, fmt.Errorf("%w: message type %q", errProtocol, msgType)
+	}
+	info := &startupInfo{}
+	if info.auth, err = parseAuthRequest(payload); err != nil {
+		return nil, err
+	}
+	if info.auth.code == authOK {
+		info.version = readServerVersion(r)
+		_ = writeTerminate(conn)
+	}
+	return info, nil
+}
+
+// readServerVersion reads parameters of the session until the server is ready for queries
+func readServerVersion(r *bufio.Reader) (version string) {
+	for {
+		msgType, payload, err := readMessage(r)
+		if err != nil || msgType == msgReadyForQuery || msgType == msgErrorResponse {
+			return
+		}
+		if msgType != msgParameterStatus {
+			continue
+		}
+		if name, value := parseParameterStatus(payload); name == "server_version" {
+			version = value
+		}
+	}
+}
@@ -0,0 +1,232 @@
+package postgres
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+// testServer replies to startup messages with plain or TLS responses,
+// the SSLRequest is accepted if the TLS config is set
+type testServer struct {
+	tlsConfig *tls.Config
+	plain     []byte
+	tls       []byte
+}
+
+func newTestTLSConfig(t *testing.T) *tls.Config {
+	t.Helper()
+	srv := httptest.NewTLSServer(http.NotFoundHandler())
+	defer srv.Close()
+	return &tls.Config{Certificates: srv.TLS.Certificates}
+}
+
+func servePostgres(t *testing.T, srv *testServer) *scan.Request {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { l.Close() })
+
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go srv.handle(conn)
+		}
+	}()
+	addr := l.Addr().(*net.TCPAddr)
+	return &scan.Request{DstIP: addr.IP, DstPort: uint16(addr.Port)}
+}
+
+func (srv *testServer) handle(conn net.Conn) {
+	defer conn.Close()
+	code, err := readStartupPacket(conn)
+	if err != nil {
+		return
+	}
+	if code != sslRequestCode {
+		_, _ = conn.Write(srv.plain)
+		return
+	}
+	if srv.tlsConfig == nil {
+		if _, err = conn.Write([]byte{sslRefused}); err != nil {
+			return
+		}
+		if _, err = readStartupPacket(conn); err == nil {
+			_, _ = conn.Write(srv.plain)
+		}
+		return
+	}
+	if _, err = conn.Write([]byte{sslAccepted}); err != nil {
+		return
+	}
+	tlsConn := tls.Server(conn, srv.tlsConfig)
+	if _, err = readStartupPacket(tlsConn); err == nil {
+		_, _ = tlsConn.Write(srv.tls)
+	}
+}
+
+// readStartupPacket reads the SSLRequest or the StartupMessage and returns its code
+func readStartupPacket(r io.Reader) (uint32, error) {
+	header := make([]byte, 8)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return 0, err
+	}
+	size := binary.BigEndian.Uint32(header)
+	if _, err := io.CopyN(io.Discard, r, int64(size)-8); err != nil {
+		return 0, err
+	}
+	return binary.BigEndian.Uint32(header[4:]), nil
+}
+
+func TestScan(t *testing.T) {
+	t.Parallel()
+	tlsConfig := newTestTLSConfig(t)
+	hbaReject := newErrorMessage(invalidAuthorization,
+		`no pg_hba.conf entry for host "127.0.0.1", user "postgres", database "postgres", no encryption`)
+	tests := []struct {
+		name     string
+		server   *testServer
+		expected *ScanResult
+	}{
+		{
+			name:   "NoTLSMD5",
+			server: &testServer{plain: newAuthMessage(authMD5Password, "salt")},
+			expected: &ScanResult{
+				Auth: "md5",
+			},
+		},
+		{
+			name: "TLSScram",
+			server: &testServer{
+				tlsConfig: tlsConfig,
+				plain:     newAuthMessage(authSASL, "SCRAM-SHA-256"),
+				tls:       newAuthMessage(authSASL, "SCRAM-SHA-256-PLUS", "SCRAM-SHA-256"),
+			},
+			expected: &ScanResult{
+				TLS:            true,
+				Auth:           "scram-sha-256",
+				SASLMechanisms: []string{"SCRAM-SHA-256"},
+			},
+		},
+		{
+			name: "TLSRequired",
+			server: &testServer{
+				tlsConfig: tlsConfig,
+				plain:     hbaReject,
+				tls:       newAuthMessage(authSASL, "SCRAM-SHA-256-PLUS", "SCRAM-SHA-256"),
+			},
+			expected: &ScanResult{
+				TLS:            true,
+				TLSRequired:    true,
+				Auth:           "scram-sha-256",
+				SASLMechanisms: []string{"SCRAM-SHA-256-PLUS", "SCRAM-SHA-256"},
+			},
+		},
+		{
+			name: "Trust",
+			server: &testServer{
+				plain: concat(newAuthMessage(authOK),
+					newParameterStatus("client_encoding", "UTF8"),
+					newParameterStatus("server_version", "15.2 (Debian 15.2-1.pgdg110+1)"),
+					newMessage(msgReadyForQuery, []byte{'I'})),
+			},
+			expected: &ScanResult{
+				Auth:    "trust",
+				Version: "15.2 (Debian 15.2-1.pgdg110+1)",
+			},
+		},
+		{
+			name:   "Rejected",
+			server: &testServer{plain: newErrorMessage("53300", "sorry, too many clients already")},
+			expected: &ScanResult{
+				Error: "53300: sorry, too many clients already",
+			},
+		},
+	}
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			req := servePostgres(t, tt.server)
+			result, err := NewScanner().Scan(context.Background(), req)
+			require.NoError(t, err)
+
+			tt.expected.ScanType = ScanType
+			tt.expected.IP = req.DstIP.String()
+			tt.expected.Port = req.DstPort
+			require.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func concat(messages ...[]byte) (result []byte) {
+	for _, m := range messages {
+		result = append(result, m...)
+	}
+	return
+}
+
+func TestScanNotPostgresServer(t *testing.T) {
+	t.Parallel()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			_, _ = conn.Write([]byte("SSH-2.0-OpenSSH_8.4p1\r\n"))
+			conn.Close()
+		}
+	}()
+	addr := l.Addr().(*net.TCPAddr)
+
+	result, err := NewScanner().Scan(context.Background(), &scan.Request{DstIP: addr.IP, DstPort: uint16(addr.Port)})
+	require.ErrorIs(t, err, errProtocol)
+	require.Nil(t, result)
+}
+
+func TestScanTimeout(t *testing.T) {
+	t.Parallel()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+	done := make(chan interface{})
+	defer close(done)
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		<-done
+	}()
+	addr := l.Addr().(*net.TCPAddr)
+
+	s := NewScanner(WithDataTimeout(50 * time.Millisecond))
+	result, err := s.Scan(context.Background(), &scan.Request{DstIP: addr.IP, DstPort: uint16(addr.Port)})
+	require.Error(t, err)
+	require.ErrorIs(t, err, scan.ErrTimeout)
+	require.Nil(t, result)
+}
+
+func TestScanResultMarshalJSON(t *testing.T) {
+	t.Parallel()
+	data, err := (&ScanResult{ScanType: ScanType, IP: "192.168.0.1", Port: 5432, TLS: true, Auth: "md5"}).MarshalJSON()
+	require.NoError(t, err)
+	require.JSONEq(t, `{"scan":"postgres","ip":"192.168.0.1","port":5432,"tls":true,"tls_required":false,"auth":"md5"}`, string(data))
+}
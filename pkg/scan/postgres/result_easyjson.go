@@ -0,0 +1,171 @@
+// Code generated by easyjson for marshaling/unmarshaling. DO NOT EDIT.
+
+package postgres
+
+import (
+	json "encoding/json"
+	easyjson "github.com/mailru/easyjson"
+	jlexer "github.com/mailru/easyjson/jlexer"
+	jwriter "github.com/mailru/easyjson/jwriter"
+)
+
+// suppress unused package warning
+var (
+	_ *json.RawMessage
+	_ *jlexer.Lexer
+	_ *jwriter.Writer
+	_ easyjson.Marshaler
+)
+
+func easyjsonD3b49167DecodeGithubComVByteCpuSxPkgScanPostgres(in *jlexer.Lexer, out *ScanResult) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "scan":
+			out.ScanType = string(in.String())
+		case "ip":
+			out.IP = string(in.String())
+		case "port":
+			out.Port = uint16(in.Uint16())
+		case "tls":
+			out.TLS = bool(in.Bool())
+		case "tls_required":
+			out.TLSRequired = bool(in.Bool())
+		case "auth":
+			out.Auth = string(in.String())
+		case "sasl_mechanisms":
+			if in.IsNull() {
+				in.Skip()
+				out.SASLMechanisms = nil
+			} else {
+				in.Delim('[')
+				if out.SASLMechanisms == nil {
+					if !in.IsDelim(']') {
+						out.SASLMechanisms = make([]string, 0, 4)
+					} else {
+						out.SASLMechanisms = []string{}
+					}
+				} else {
+					out.SASLMechanisms = (out.SASLMechanisms)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v1 string
+					v1 = string(in.String())
+					out.SASLMechanisms = append(out.SASLMechanisms, v1)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		case "version":
+			out.Version = string(in.String())
+		case "error":
+			out.Error = string(in.String())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonD3b49167EncodeGithubComVByteCpuSxPkgScanPostgres(out *jwriter.Writer, in ScanResult) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"scan\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.ScanType))
+	}
+	{
+		const prefix string = ",\"ip\":"
+		out.RawString(prefix)
+		out.String(string(in.IP))
+	}
+	{
+		const prefix string = ",\"port\":"
+		out.RawString(prefix)
+		out.Uint16(uint16(in.Port))
+	}
+	{
+		const prefix string = ",\"tls\":"
+		out.RawString(prefix)
+		out.Bool(bool(in.TLS))
+	}
+	{
+		const prefix string = ",\"tls_required\":"
+		out.RawString(prefix)
+		out.Bool(bool(in.TLSRequired))
+	}
+	if in.Auth != "" {
+		const prefix string = ",\"auth\":"
+		out.RawString(prefix)
+		out.String(string(in.Auth))
+	}
+	if len(in.SASLMechanisms) != 0 {
+		const prefix string = ",\"sasl_mechanisms\":"
+		out.RawString(prefix)
+		{
+			out.RawByte('[')
+			for v2, v3 := range in.SASLMechanisms {
+				if v2 > 0 {
+					out.RawByte(',')
+				}
+				out.String(string(v3))
+			}
+			out.RawByte(']')
+		}
+	}
+	if in.Version != "" {
+		const prefix string = ",\"version\":"
+		out.RawString(prefix)
+		out.String(string(in.Version))
+	}
+	if in.Error != "" {
+		const prefix string = ",\"error\":"
+		out.RawString(prefix)
+		out.String(string(in.Error))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v ScanResult) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonD3b49167EncodeGithubComVByteCpuSxPkgScanPostgres(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v ScanResult) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD3b49167EncodeGithubComVByteCpuSxPkgScanPostgres(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *ScanResult) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonD3b49167DecodeGithubComVByteCpuSxPkgScanPostgres(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *ScanResult) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD3b49167DecodeGithubComVByteCpuSxPkgScanPostgres(l, v)
+}
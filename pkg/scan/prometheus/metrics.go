@@ -0,0 +1,75 @@
+package prometheus
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+)
+
+const (
+	buildInfoSuffix = "_build_info"
+	// goBuildInfo is exposed by client libraries of all Go exporters, it is not the build of the exporter
+	goBuildInfo = "go_build_info"
+)
+
+type metrics struct {
+	// families is the number of metric families declared with TYPE comments
+	families int
+	// exporter is the name of the first build info metric without the suffix, e.g. node_exporter
+	exporter  string
+	buildInfo map[string]string
+}
+
+// parseMetrics parses the Prometheus text exposition format, only TYPE comments
+// and the build info metric are parsed
+func parseMetrics(data []byte) *metrics {
+	result := &metrics{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(nil, len(data)+1)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "# TYPE ") {
+			result.families++
+			continue
+		}
+		if len(result.exporter) > 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+		name, labels, found := strings.Cut(line, "{")
+		if !found || !strings.HasSuffix(name, buildInfoSuffix) || name == goBuildInfo {
+			continue
+		}
+		result.exporter = strings.TrimSuffix(name, buildInfoSuffix)
+		result.buildInfo = parseLabels(labels)
+	}
+	return result
+}
+
+// parseLabels parses label pairs of the sample after the opening brace, e.g. version="1.3.1",revision="a2321e7"} 1
+func parseLabels(s string) map[string]string {
+	result := make(map[string]string)
+	for {
+		s = strings.TrimLeft(s, ", ")
+		name, rest, found := strings.Cut(s, `="`)
+		if !found || strings.Contains(name, "}") {
+			return result
+		}
+		var value strings.Builder
+		i := 0
+		for ; i < len(rest) && rest[i] != '"'; i++ {
+			if rest[i] == '\\' && i+1 < len(rest) {
+				i++
+				if rest[i] == 'n' {
+					value.WriteByte('\n')
+					continue
+				}
+			}
+			value.WriteByte(rest[i])
+		}
+		if i == len(rest) {
+			return result
+		}
+		result[name] = value.String()
+		s = rest[i+1:]
+	}
+}
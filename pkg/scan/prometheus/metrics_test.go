@@ -0,0 +1,104 @@
+package prometheus
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const nodeExporterMetrics = `# HELP go_build_info Build information about the main Go module.
+# TYPE go_build_info gauge
+go_build_info{checksum="",path="github.com/prometheus/node_exporter",version="(devel)"} 1
+# HELP go_goroutines Number of goroutines that currently exist.
+# TYPE go_goroutines gauge
+go_goroutines 8
+# HELP node_exporter_build_info A metric with a constant '1' value labeled by version, revision, branch, and goversion from which node_exporter was built.
+# TYPE node_exporter_build_info gauge
+node_exporter_build_info{branch="HEAD",goversion="go1.17.3",revision="a2321e7b940ddcff26873612bccdf7cd4c42b6b6",version="1.3.1"} 1
+# HELP node_load1 1m load average.
+# TYPE node_load1 gauge
+node_load1 0.21
+`
+
+func TestParseMetrics(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		data     string
+		expected *metrics
+	}{
+		{
+			name: "NodeExporter",
+			data: nodeExporterMetrics,
+			expected: &metrics{families: 4, exporter: "node_exporter", buildInfo: map[string]string{
+				"branch": "HEAD", "goversion": "go1.17.3",
+				"revision": "a2321e7b940ddcff26873612bccdf7cd4c42b6b6", "version": "1.3.1",
+			}},
+		},
+		{
+			name:     "NoBuildInfo",
+			data:     "# HELP up Target is up.\n# TYPE up gauge\nup 1\n",
+			expected: &metrics{families: 1},
+		},
+		{
+			name:     "HTML",
+			data:     "<html><body>Not Found</body></html>",
+			expected: &metrics{},
+		},
+	}
+
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			require.Equal(t, tt.expected, parseMetrics([]byte(tt.data)))
+		})
+	}
+}
+
+func TestParseLabels(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		data     string
+		expected map[string]string
+	}{
+		{
+			name:     "Labels",
+			data:     `version="1.3.1",revision="a2321e7"} 1`,
+			expected: map[string]string{"version": "1.3.1", "revision": "a2321e7"},
+		},
+		{
+			name:     "TrailingComma",
+			data:     `version="1.3.1",} 1`,
+			expected: map[string]string{"version": "1.3.1"},
+		},
+		{
+			name:     "Escaped",
+			data:     `path="C:\\exporter",tag="a \"b\"\nc"} 1`,
+			expected: map[string]string{"path": `C:\exporter`, "tag": "a \"b\"\nc"},
+		},
+		{
+			name:     "Empty",
+			data:     `} 1`,
+			expected: map[string]string{},
+		},
+		{
+			name:     "Unterminated",
+			data:     `version="1.3.1",revision="a23`,
+			expected: map[string]string{"version": "1.3.1"},
+		},
+	}
+
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			require.Equal(t, tt.expected, parseLabels(tt.data))
+		})
+	}
+}
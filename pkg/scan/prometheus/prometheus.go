@@ -0,0 +1,176 @@
+package prometheus
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+const (
+	ScanType = "prometheus"
+
+	defaultDataTimeout = 5 * time.Second
+
+	// maxResponseSize limits the size of read responses, metrics of node exporters fit in it
+	maxResponseSize = 4 << 20
+	// serverType is the type of Prometheus servers, other types are names of exporters
+	serverType = "prometheus"
+)
+
+var (
+	errNotPrometheus = errors.New("not a Prometheus server or exporter")
+	errStatus        = errors.New("unexpected HTTP status")
+)
+
+type ScanResult struct {
+	ScanType string `json:"scan"`
+	Proto    string `json:"proto"`
+	Host     string `json:"host"`
+	// Type is prometheus for Prometheus servers or the name of the exporter from its build info metric,
+	// e.g. node_exporter, it is empty for exporters without the build info
+	Type      string `json:"type,omitempty"`
+	Version   string `json:"version,omitempty"`
+	Revision  string `json:"revision,omitempty"`
+	GoVersion string `json:"go_version,omitempty"`
+	// Metrics is the number of metric families exposed on /metrics
+	Metrics int `json:"metrics"`
+}
+
+func (r *ScanResult) String() string {
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "%s://%s", r.Proto, r.Host)
+	if len(r.Type) > 0 {
+		fmt.Fprintf(&buf, " %s", r.Type)
+	}
+	if len(r.Version) > 0 {
+		fmt.Fprintf(&buf, " %s", r.Version)
+	}
+	fmt.Fprintf(&buf, " metrics:%d", r.Metrics)
+	return buf.String()
+}
+
+func (r *ScanResult) ID() string {
+	return r.Host
+}
+
+func (r *ScanResult) MarshalJSON() ([]byte, error) {
+	// Type definition for the recursive call
+	type JScanResult ScanResult
+	// This works because JScanResult doesn't have a MarshalJSON function associated with it
+	return json.Marshal(JScanResult(*r))
+}
+
+type Scanner struct {
+	client      *http.Client
+	proto       string
+	dataTimeout time.Duration
+}
+
+// Assert that prometheus.Scanner conforms to the scan.Scanner interface
+var _ scan.Scanner = (*Scanner)(nil)
+
+type ScannerOption func(*Scanner)
+
+func WithDataTimeout(timeout time.Duration) ScannerOption {
+	return func(s *Scanner) {
+		s.dataTimeout = timeout
+	}
+}
+
+func NewScanner(proto string, opts ...ScannerOption) *Scanner {
+	tr := &http.Transport{
+		MaxConnsPerHost:   1,
+		DisableKeepAlives: true,
+		TLSClientConfig: &tls.Config{
+			InsecureSkipVerify: true,
+		},
+	}
+	s := &Scanner{
+		client:      &http.Client{Transport: tr},
+		proto:       proto,
+		dataTimeout: defaultDataTimeout,
+	}
+	for _, o := range opts {
+		o(s)
+	}
+	return s
+}
+
+// Scan requests the build info of the Prometheus server and metrics of the target, the build info
+// of exporters is taken from their *_build_info metric. Targets that answered neither are not reported.
+func (s *Scanner) Scan(ctx context.Context, r *scan.Request) (scan.Result, error) {
+	result, err := s.scanTarget(ctx, r)
+	return result, scan.WrapError(err)
+}
+
+func (s *Scanner) scanTarget(ctx context.Context, r *scan.Request) (result scan.Result, err error) {
+	host := fmt.Sprintf("%s:%d", r.DstIP.String(), r.DstPort)
+	baseURL := fmt.Sprintf("%s://%s", s.proto, host)
+	res := &ScanResult{
+		ScanType: ScanType,
+		Proto:    s.proto,
+		Host:     host,
+	}
+
+	var buildInfo struct {
+		Status string `json:"status"`
+		Data   struct {
+			Version   string `json:"version"`
+			Revision  string `json:"revision"`
+			GoVersion string `json:"goVersion"`
+		} `json:"data"`
+	}
+	body, buildInfoErr := s.get(ctx, baseURL+"/api/v1/status/buildinfo")
+	if buildInfoErr == nil && json.Unmarshal(body, &buildInfo) == nil && buildInfo.Status == "success" {
+		res.Type = serverType
+		res.Version = buildInfo.Data.Version
+		res.Revision = buildInfo.Data.Revision
+		res.GoVersion = buildInfo.Data.GoVersion
+	}
+
+	if body, err = s.get(ctx, baseURL+"/metrics"); err != nil {
+		if res.Type == serverType {
+			return res, nil
+		}
+		return
+	}
+	m := parseMetrics(body)
+	res.Metrics = m.families
+	if len(res.Type) == 0 && len(m.exporter) > 0 {
+		res.Type = m.exporter
+		res.Version = m.buildInfo["version"]
+		res.Revision = m.buildInfo["revision"]
+		res.GoVersion = m.buildInfo["goversion"]
+	}
+	if len(res.Type) == 0 && res.Metrics == 0 {
+		return nil, errNotPrometheus
+	}
+	return res, nil
+}
+
+// get sends the GET request and returns the response body, responses with statuses other than 2xx are errors
+func (s *Scanner) get(ctx context.Context, url string) (body []byte, err error) {
+	ctx, cancel := context.WithTimeout(ctx, s.dataTimeout)
+	defer cancel()
+	var req *http.Request
+	if req, err = http.NewRequestWithContext(ctx, http.MethodGet, url, nil); err != nil {
+		return
+	}
+	var resp *http.Response
+	if resp, err = s.client.Do(req); err != nil {
+		return
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("%w: %s", errStatus, resp.Status)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+}
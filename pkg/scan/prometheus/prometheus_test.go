@@ -0,0 +1,132 @@
+package prometheus
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+func startServer(t *testing.T, h http.Handler) (*scan.Request, string) {
+	t.Helper()
+	srv := httptest.NewServer(h)
+	t.Cleanup(srv.Close)
+	addr := srv.Listener.Addr().(*net.TCPAddr)
+	return &scan.Request{DstIP: addr.IP, DstPort: uint16(addr.Port)}, addr.String()
+}
+
+func TestScan(t *testing.T) {
+	t.Parallel()
+	serverMetrics := "# HELP prometheus_build_info Build info.\n# TYPE prometheus_build_info gauge\n" +
+		`prometheus_build_info{branch="HEAD",goversion="go1.17.1",revision="9a9d1d",version="2.30.0"} 1` + "\n"
+	tests := []struct {
+		name     string
+		handler  http.HandlerFunc
+		expected ScanResult
+	}{
+		{
+			name: "Server",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/api/v1/status/buildinfo":
+					fmt.Fprint(w, `{"status":"success","data":{"version":"2.30.0","revision":"9a9d1d",`+
+						`"branch":"HEAD","buildUser":"root@buildhost","goVersion":"go1.17.1"}}`)
+				case "/metrics":
+					fmt.Fprint(w, serverMetrics)
+				default:
+					http.NotFound(w, r)
+				}
+			},
+			expected: ScanResult{Type: "prometheus", Version: "2.30.0", Revision: "9a9d1d",
+				GoVersion: "go1.17.1", Metrics: 1},
+		},
+		{
+			name: "ServerWithoutMetrics",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == "/api/v1/status/buildinfo" {
+					fmt.Fprint(w, `{"status":"success","data":{"version":"2.30.0"}}`)
+					return
+				}
+				http.NotFound(w, r)
+			},
+			expected: ScanResult{Type: "prometheus", Version: "2.30.0"},
+		},
+		{
+			name: "NodeExporter",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == "/metrics" {
+					fmt.Fprint(w, nodeExporterMetrics)
+					return
+				}
+				http.NotFound(w, r)
+			},
+			expected: ScanResult{Type: "node_exporter", Version: "1.3.1",
+				Revision: "a2321e7b940ddcff26873612bccdf7cd4c42b6b6", GoVersion: "go1.17.3", Metrics: 4},
+		},
+		{
+			name: "ExporterWithoutBuildInfo",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				// the single page application answers all paths
+				if r.URL.Path == "/metrics" {
+					fmt.Fprint(w, "# HELP up Target is up.\n# TYPE up gauge\nup 1\n")
+					return
+				}
+				fmt.Fprint(w, "<html></html>")
+			},
+			expected: ScanResult{Metrics: 1},
+		},
+	}
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			req, host := startServer(t, tt.handler)
+			result, err := NewScanner("http").Scan(context.Background(), req)
+			require.NoError(t, err)
+
+			expected := tt.expected
+			expected.ScanType = ScanType
+			expected.Proto = "http"
+			expected.Host = host
+			require.Equal(t, &expected, result)
+		})
+	}
+}
+
+func TestScanNotPrometheus(t *testing.T) {
+	t.Parallel()
+	req, _ := startServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "<html><body>It works!</body></html>")
+	}))
+	_, err := NewScanner("http").Scan(context.Background(), req)
+	require.ErrorIs(t, err, errNotPrometheus)
+
+	req, _ = startServer(t, http.NotFoundHandler())
+	_, err = NewScanner("http").Scan(context.Background(), req)
+	require.ErrorIs(t, err, errStatus)
+}
+
+func TestScanTimeout(t *testing.T) {
+	t.Parallel()
+	done := make(chan struct{})
+	defer close(done)
+	req, _ := startServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-done
+	}))
+	_, err := NewScanner("http", WithDataTimeout(100*time.Millisecond)).Scan(context.Background(), req)
+	require.Error(t, err)
+}
+
+func TestScanResultString(t *testing.T) {
+	t.Parallel()
+	result := &ScanResult{ScanType: ScanType, Proto: "http", Host: "192.168.0.1:9100", Type: "node_exporter",
+		Version: "1.3.1", Metrics: 42}
+	require.Equal(t, "http://192.168.0.1:9100 node_exporter 1.3.1 metrics:42", result.String())
+	require.Equal(t, "192.168.0.1:9100", result.ID())
+}
@@ -0,0 +1,126 @@
+package rdp
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+// Security protocols of the RDP negotiation request, see [MS-RDPBCGR] section 2.2.1.1.1
+const (
+	ProtocolRDP      = 0x00000000
+	ProtocolSSL      = 0x00000001
+	ProtocolHybrid   = 0x00000002
+	ProtocolHybridEx = 0x00000008
+)
+
+// Failure codes of the RDP negotiation failure, see [MS-RDPBCGR] section 2.2.1.2.2
+const (
+	FailureSSLRequired             = 0x00000001
+	FailureSSLNotAllowed           = 0x00000002
+	FailureSSLCertNotOnServer      = 0x00000003
+	FailureInconsistentFlags       = 0x00000004
+	FailureHybridRequired          = 0x00000005
+	FailureSSLWithUserAuthRequired = 0x00000006
+)
+
+const (
+	tpktVersion      = 3
+	tpktHeaderSize   = 4
+	x224ConnRequest  = 0xe0
+	x224ConnConfirm  = 0xd0
+	x224HeaderSize   = 7
+	negRequest       = 0x01
+	negResponse      = 0x02
+	negFailure       = 0x03
+	negStructureSize = 8
+)
+
+var errResponse = scan.NewError(scan.ErrParse, errors.New("invalid RDP response"))
+
+// ConnectionRequest is the X.224 Connection Request PDU with the RDP Negotiation Request.
+// From [MS-RDPBCGR] section 2.2.1.1:
+// +------+-----------+-------------------+-------------------+
+// | TPKT | X.224 CR  | type, flags, len  | requestedProtocols|
+// +------+-----------+-------------------+-------------------+
+// |  4   |     7     |         4         |         4         |
+// +------+-----------+-------------------+-------------------+
+type ConnectionRequest struct {
+	RequestedProtocols uint32
+}
+
+func (r *ConnectionRequest) Len() int64 {
+	return tpktHeaderSize + x224HeaderSize + negStructureSize
+}
+
+func (r *ConnectionRequest) WriteTo(w io.Writer) (int64, error) {
+	buf := make([]byte, 0, r.Len())
+	// TPKT header: version, reserved and the total length
+	buf = append(buf, tpktVersion, 0)
+	buf = binary.BigEndian.AppendUint16(buf, uint16(r.Len()))
+	// X.224 length indicator doesn't include itself, followed by the CR code,
+	// destination and source references and the class option
+	buf = append(buf, byte(x224HeaderSize+negStructureSize-1), x224ConnRequest, 0, 0, 0, 0, 0)
+	buf = append(buf, negRequest, 0)
+	buf = binary.LittleEndian.AppendUint16(buf, negStructureSize)
+	buf = binary.LittleEndian.AppendUint32(buf, r.RequestedProtocols)
+	n, err := w.Write(buf)
+	return int64(n), err
+}
+
+// ConnectionConfirm is the X.224 Connection Confirm PDU with the RDP Negotiation Response or Failure.
+// Servers that don't support the negotiation send the confirm without them,
+// in this case only the standard RDP security is supported.
+type ConnectionConfirm struct {
+	// Failure is set if the server rejected the requested protocols
+	Failure bool
+	// SelectedProtocol is the protocol selected by the server if the negotiation succeeded
+	SelectedProtocol uint32
+	// FailureCode is the reason of the failure
+	FailureCode uint32
+}
+
+func (c *ConnectionConfirm) ReadFrom(r io.Reader) (n int64, err error) {
+	header := make([]byte, tpktHeaderSize)
+	var nn int
+	nn, err = io.ReadFull(r, header)
+	n += int64(nn)
+	if err != nil {
+		return
+	}
+	length := int(binary.BigEndian.Uint16(header[2:4]))
+	if header[0] != tpktVersion || length < tpktHeaderSize+x224HeaderSize {
+		return n, errResponse
+	}
+	data := make([]byte, length-tpktHeaderSize)
+	nn, err = io.ReadFull(r, data)
+	n += int64(nn)
+	if err != nil {
+		return
+	}
+	// the low nibble of the CC code is the credit
+	if data[1]&0xf0 != x224ConnConfirm || int(data[0])+1 > len(data) {
+		return n, errResponse
+	}
+	neg := data[x224HeaderSize:]
+	if len(neg) == 0 {
+		*c = ConnectionConfirm{SelectedProtocol: ProtocolRDP}
+		return
+	}
+	if len(neg) < negStructureSize || binary.LittleEndian.Uint16(neg[2:4]) != negStructureSize {
+		return n, errResponse
+	}
+	value := binary.LittleEndian.Uint32(neg[4:8])
+	switch neg[0] {
+	case negResponse:
+		*c = ConnectionConfirm{SelectedProtocol: value}
+	case negFailure:
+		*c = ConnectionConfirm{Failure: true, FailureCode: value}
+	default:
+		return n, fmt.Errorf("%w: unknown negotiation type 0x%02x", errResponse, neg[0])
+	}
+	return
+}
@@ -0,0 +1,107 @@
+package rdp
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// connectionConfirm returns the X.224 Connection Confirm with the negotiation structure of the type,
+// the negotiation structure is omitted if negType is zero
+func connectionConfirm(negType byte, value uint32) []byte {
+	x224 := []byte{x224HeaderSize - 1, x224ConnConfirm, 0, 0, 0x12, 0x34, 0}
+	if negType != 0 {
+		x224[0] += negStructureSize
+		x224 = append(x224, negType, 0)
+		x224 = binary.LittleEndian.AppendUint16(x224, negStructureSize)
+		x224 = binary.LittleEndian.AppendUint32(x224, value)
+	}
+	packet := []byte{tpktVersion, 0}
+	packet = binary.BigEndian.AppendUint16(packet, uint16(tpktHeaderSize+len(x224)))
+	return append(packet, x224...)
+}
+
+func TestWriteConnectionRequest(t *testing.T) {
+	t.Parallel()
+	var buf bytes.Buffer
+	req := &ConnectionRequest{RequestedProtocols: ProtocolSSL | ProtocolHybrid}
+	n, err := req.WriteTo(&buf)
+	require.NoError(t, err)
+	require.Equal(t, req.Len(), n)
+	require.Equal(t, []byte{
+		0x03, 0x00, 0x00, 0x13,
+		0x0e, 0xe0, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x01, 0x00, 0x08, 0x00, 0x03, 0x00, 0x00, 0x00,
+	}, buf.Bytes())
+}
+
+func TestReadConnectionConfirm(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		data     []byte
+		expected *ConnectionConfirm
+	}{
+		{
+			name:     "Response",
+			data:     connectionConfirm(negResponse, ProtocolHybrid),
+			expected: &ConnectionConfirm{SelectedProtocol: ProtocolHybrid},
+		},
+		{
+			name:     "Failure",
+			data:     connectionConfirm(negFailure, FailureHybridRequired),
+			expected: &ConnectionConfirm{Failure: true, FailureCode: FailureHybridRequired},
+		},
+		{
+			name:     "NoNegotiation",
+			data:     connectionConfirm(0, 0),
+			expected: &ConnectionConfirm{SelectedProtocol: ProtocolRDP},
+		},
+	}
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			confirm := &ConnectionConfirm{}
+			n, err := confirm.ReadFrom(bytes.NewReader(tt.data))
+			require.NoError(t, err)
+			require.Equal(t, int64(len(tt.data)), n)
+			require.Equal(t, tt.expected, confirm)
+		})
+	}
+}
+
+func TestReadConnectionConfirmError(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{
+			name: "HTTP",
+			data: []byte("HTTP/1.1 400 Bad Request\r\n\r\n"),
+		},
+		{
+			name: "ConnectionRequest",
+			data: func() []byte {
+				var buf bytes.Buffer
+				_, _ = (&ConnectionRequest{}).WriteTo(&buf)
+				return buf.Bytes()
+			}(),
+		},
+		{
+			name: "UnknownType",
+			data: connectionConfirm(0x07, 0),
+		},
+	}
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := (&ConnectionConfirm{}).ReadFrom(bytes.NewReader(tt.data))
+			require.ErrorIs(t, err, errResponse)
+		})
+	}
+}
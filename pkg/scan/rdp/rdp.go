@@ -0,0 +1,192 @@
+package rdp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+const (
+	ScanType = "rdp"
+
+	defaultDialTimeout = 2 * time.Second
+	defaultDataTimeout = 2 * time.Second
+)
+
+const (
+	SecurityRDP      = "rdp"
+	SecurityTLS      = "tls"
+	SecurityHybrid   = "hybrid"
+	SecurityHybridEx = "hybrid_ex"
+)
+
+// probes are requested protocols of each connection from the weakest to the strongest,
+// the server selects the strongest requested protocol it supports
+var probes = []struct {
+	requested uint32
+	selected  uint32
+}{
+	{ProtocolRDP, ProtocolRDP},
+	{ProtocolSSL, ProtocolSSL},
+	{ProtocolSSL | ProtocolHybrid, ProtocolHybrid},
+	{ProtocolSSL | ProtocolHybrid | ProtocolHybridEx, ProtocolHybridEx},
+}
+
+var protocolNames = map[uint32]string{
+	ProtocolRDP:      SecurityRDP,
+	ProtocolSSL:      SecurityTLS,
+	ProtocolHybrid:   SecurityHybrid,
+	ProtocolHybridEx: SecurityHybridEx,
+}
+
+// failureSecurity maps failure codes to the security protocol required by the server
+var failureSecurity = map[uint32]string{
+	FailureSSLRequired:             SecurityTLS,
+	FailureSSLWithUserAuthRequired: SecurityTLS,
+	FailureHybridRequired:          SecurityHybrid,
+}
+
+type ScanResult struct {
+	ScanType string `json:"scan"`
+	IP       string `json:"ip"`
+	Port     uint16 `json:"port"`
+	// Protocols are all security protocols accepted by the server:
+	// standard RDP security, TLS, CredSSP (hybrid) and CredSSP with early user authorization (hybrid_ex)
+	Protocols []string `json:"protocols,omitempty"`
+	// Security is the weakest security protocol accepted by the server, i.e. the one required from clients
+	Security string `json:"security"`
+	// NLA is set if the server requires Network Level Authentication with CredSSP
+	NLA bool `json:"nla"`
+}
+
+func (r *ScanResult) String() string {
+	result := fmt.Sprintf("%-20s %-5d %s", r.IP, r.Port, strings.Join(r.Protocols, ","))
+	if r.NLA {
+		result += " nla"
+	}
+	return result
+}
+
+func (r *ScanResult) ID() string {
+	return fmt.Sprintf("%s:%d", r.IP, r.Port)
+}
+
+func (r *ScanResult) MarshalJSON() ([]byte, error) {
+	// Type definition for the recursive call
+	type JScanResult ScanResult
+	// This works because JScanResult doesn't have a MarshalJSON function associated with it
+	return json.Marshal(JScanResult(*r))
+}
+
+// Scanner sends the X.224 Connection Request with different requested protocols over separate connections
+// and collects security protocols selected by the server
+type Scanner struct {
+	dialer      *net.Dialer
+	dataTimeout time.Duration
+}
+
+// Assert that rdp.Scanner conforms to the scan.Scanner interface
+var _ scan.Scanner = (*Scanner)(nil)
+
+type ScannerOption func(*Scanner)
+
+func WithDialTimeout(timeout time.Duration) ScannerOption {
+	return func(s *Scanner) {
+		s.dialer.Timeout = timeout
+	}
+}
+
+func WithDataTimeout(timeout time.Duration) ScannerOption {
+	return func(s *Scanner) {
+		s.dataTimeout = timeout
+	}
+}
+
+func NewScanner(opts ...ScannerOption) *Scanner {
+	s := &Scanner{
+		dialer: &net.Dialer{
+			Timeout: defaultDialTimeout,
+		},
+		dataTimeout: defaultDataTimeout,
+	}
+	for _, o := range opts {
+		o(s)
+	}
+	return s
+}
+
+func (s *Scanner) Scan(ctx context.Context, r *scan.Request) (scan.Result, error) {
+	result, err := s.scanTarget(ctx, r)
+	return result, scan.WrapError(err)
+}
+
+func (s *Scanner) scanTarget(ctx context.Context, r *scan.Request) (scan.Result, error) {
+	addr := net.JoinHostPort(r.DstIP.String(), strconv.Itoa(int(r.DstPort)))
+	result := &ScanResult{
+		ScanType: ScanType,
+		IP:       r.DstIP.String(),
+		Port:     r.DstPort,
+	}
+	for i, probe := range probes {
+		confirm, err := s.connect(ctx, addr, probe.requested)
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if err != nil {
+			// the first connection tells whether the target is an RDP server,
+			// the following ones may be closed by servers that don't support requested protocols
+			if i == 0 {
+				return nil, err
+			}
+			continue
+		}
+		if confirm.Failure {
+			if i == 0 {
+				result.Security = failureSecurity[confirm.FailureCode]
+			}
+			continue
+		}
+		if confirm.SelectedProtocol != probe.selected {
+			continue
+		}
+		result.Protocols = append(result.Protocols, protocolNames[probe.selected])
+		// servers without the negotiation support only the standard RDP security
+		if i == 0 && confirm.SelectedProtocol == ProtocolRDP {
+			result.Security = SecurityRDP
+		}
+	}
+	if len(result.Security) == 0 && len(result.Protocols) > 0 {
+		result.Security = result.Protocols[0]
+	}
+	if len(result.Security) == 0 {
+		return nil, errResponse
+	}
+	result.NLA = result.Security == SecurityHybrid || result.Security == SecurityHybridEx
+	return result, nil
+}
+
+func (s *Scanner) connect(ctx context.Context, addr string, requested uint32) (*ConnectionConfirm, error) {
+	conn, err := s.dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	if err = conn.SetDeadline(time.Now().Add(s.dataTimeout)); err != nil {
+		return nil, err
+	}
+	req := &ConnectionRequest{RequestedProtocols: requested}
+	if _, err = req.WriteTo(conn); err != nil {
+		return nil, err
+	}
+	confirm := &ConnectionConfirm{}
+	if _, err = confirm.ReadFrom(conn); err != nil {
+		return nil, err
+	}
+	return confirm, nil
+}
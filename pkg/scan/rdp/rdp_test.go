@@ -0,0 +1,169 @@
+package rdp
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+type testServer struct {
+	// supported protocols in the order of preference, nil means no negotiation support
+	protocols []uint32
+	// failureCode is sent for requests without supported protocols
+	failureCode uint32
+}
+
+func serveRDP(t *testing.T, server *testServer) *scan.Request {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { l.Close() })
+
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go server.handle(conn)
+		}
+	}()
+	addr := l.Addr().(*net.TCPAddr)
+	return &scan.Request{DstIP: addr.IP, DstPort: uint16(addr.Port)}
+}
+
+func (server *testServer) handle(conn net.Conn) {
+	defer conn.Close()
+	var req ConnectionRequest
+	buf := make([]byte, req.Len())
+	if _, err := io.ReadFull(conn, buf); err != nil {
+		return
+	}
+	if server.protocols == nil {
+		_, _ = conn.Write(connectionConfirm(0, 0))
+		return
+	}
+	requested := binary.LittleEndian.Uint32(buf[15:19])
+	for _, protocol := range server.protocols {
+		if protocol == ProtocolRDP && requested == ProtocolRDP || requested&protocol != 0 {
+			_, _ = conn.Write(connectionConfirm(negResponse, protocol))
+			return
+		}
+	}
+	_, _ = conn.Write(connectionConfirm(negFailure, server.failureCode))
+}
+
+func TestScan(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		server   *testServer
+		expected *ScanResult
+	}{
+		{
+			name:   "NoNegotiation",
+			server: &testServer{},
+			expected: &ScanResult{
+				Protocols: []string{SecurityRDP},
+				Security:  SecurityRDP,
+			},
+		},
+		{
+			name: "AllProtocols",
+			server: &testServer{
+				protocols: []uint32{ProtocolHybridEx, ProtocolHybrid, ProtocolSSL, ProtocolRDP},
+			},
+			expected: &ScanResult{
+				Protocols: []string{SecurityRDP, SecurityTLS, SecurityHybrid, SecurityHybridEx},
+				Security:  SecurityRDP,
+			},
+		},
+		{
+			name: "TLSRequired",
+			server: &testServer{
+				protocols:   []uint32{ProtocolSSL},
+				failureCode: FailureSSLRequired,
+			},
+			expected: &ScanResult{
+				Protocols: []string{SecurityTLS},
+				Security:  SecurityTLS,
+			},
+		},
+		{
+			name: "NLARequired",
+			server: &testServer{
+				protocols:   []uint32{ProtocolHybrid},
+				failureCode: FailureHybridRequired,
+			},
+			expected: &ScanResult{
+				Protocols: []string{SecurityHybrid},
+				Security:  SecurityHybrid,
+				NLA:       true,
+			},
+		},
+	}
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			req := serveRDP(t, tt.server)
+			result, err := NewScanner().Scan(context.Background(), req)
+			require.NoError(t, err)
+
+			tt.expected.ScanType = ScanType
+			tt.expected.IP = req.DstIP.String()
+			tt.expected.Port = req.DstPort
+			require.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestScanNotRDPServer(t *testing.T) {
+	t.Parallel()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		_, _ = conn.Write([]byte("SSH-2.0-OpenSSH_8.4p1 Debian-5\r\n"))
+	}()
+	addr := l.Addr().(*net.TCPAddr)
+
+	result, err := NewScanner().Scan(context.Background(), &scan.Request{DstIP: addr.IP, DstPort: uint16(addr.Port)})
+	require.ErrorIs(t, err, errResponse)
+	require.Nil(t, result)
+}
+
+func TestScanTimeout(t *testing.T) {
+	t.Parallel()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+	done := make(chan interface{})
+	defer close(done)
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		<-done
+	}()
+	addr := l.Addr().(*net.TCPAddr)
+
+	s := NewScanner(WithDataTimeout(50 * time.Millisecond))
+	result, err := s.Scan(context.Background(), &scan.Request{DstIP: addr.IP, DstPort: uint16(addr.Port)})
+	require.Error(t, err)
+	require.ErrorIs(t, err, scan.ErrTimeout)
+	require.Nil(t, result)
+}
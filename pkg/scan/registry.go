@@ -0,0 +1,52 @@
+package scan
+
+import (
+	"net/url"
+	"sort"
+	"sync"
+)
+
+// GeneratorFactory creates a request generator from the input URI, the scheme of the URI is the name
+// of the generator and the rest are its options, e.g. queue://db.local:5432/targets?batch=100
+type GeneratorFactory func(u *url.URL) (RequestGenerator, error)
+
+var (
+	generatorsMu sync.RWMutex
+	generators   = make(map[string]GeneratorFactory)
+)
+
+// RegisterGenerator makes the request generator available by the name, so embedders and plugins
+// can supply custom sources of targets, e.g. database-backed queues, to the --input option.
+// It is intended to be called from init functions, it panics if the factory is nil
+// or the name is already registered.
+func RegisterGenerator(name string, factory GeneratorFactory) {
+	generatorsMu.Lock()
+	defer generatorsMu.Unlock()
+	if factory == nil {
+		panic("scan: RegisterGenerator factory is nil")
+	}
+	if _, dup := generators[name]; dup {
+		panic("scan: RegisterGenerator called twice for generator " + name)
+	}
+	generators[name] = factory
+}
+
+// LookupGenerator returns the factory of the registered request generator
+func LookupGenerator(name string) (GeneratorFactory, bool) {
+	generatorsMu.RLock()
+	defer generatorsMu.RUnlock()
+	factory, ok := generators[name]
+	return factory, ok
+}
+
+// Generators returns sorted names of registered request generators
+func Generators() []string {
+	generatorsMu.RLock()
+	defer generatorsMu.RUnlock()
+	result := make([]string, 0, len(generators))
+	for name := range generators {
+		result = append(result, name)
+	}
+	sort.Strings(result)
+	return result
+}
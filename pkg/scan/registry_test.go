@@ -0,0 +1,40 @@
+package scan
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegisterGenerator(t *testing.T) {
+	t.Parallel()
+	reqgen := NewIPPortGenerator(NewIPGenerator(), NewPortGenerator())
+	RegisterGenerator("test-registry", func(u *url.URL) (RequestGenerator, error) {
+		return reqgen, nil
+	})
+
+	factory, ok := LookupGenerator("test-registry")
+	require.True(t, ok)
+	result, err := factory(&url.URL{Scheme: "test-registry"})
+	require.NoError(t, err)
+	require.Same(t, reqgen, result)
+	require.Contains(t, Generators(), "test-registry")
+
+	_, ok = LookupGenerator("test-unknown")
+	require.False(t, ok)
+}
+
+func TestRegisterGeneratorPanics(t *testing.T) {
+	t.Parallel()
+	factory := func(u *url.URL) (RequestGenerator, error) {
+		return nil, nil
+	}
+	RegisterGenerator("test-duplicate", factory)
+	require.Panics(t, func() {
+		RegisterGenerator("test-duplicate", factory)
+	})
+	require.Panics(t, func() {
+		RegisterGenerator("test-nil", nil)
+	})
+}
@@ -7,18 +7,24 @@ import (
 	"bufio"
 	"context"
 	"errors"
+	"fmt"
 	"io"
 	"math/big"
 	"net"
+	"strings"
+	"sync/atomic"
 	"time"
+
+	"github.com/v-byte-cpu/sx/pkg/ip"
 )
 
+// Input errors are of the ErrParse kind
 var (
-	ErrPortRange = errors.New("invalid port range")
-	ErrSubnet    = errors.New("invalid subnet")
-	ErrIP        = errors.New("invalid ip")
-	ErrPort      = errors.New("invalid port")
-	ErrJSON      = errors.New("invalid json")
+	ErrPortRange = NewError(ErrParse, errors.New("invalid port range"))
+	ErrSubnet    = NewError(ErrParse, errors.New("invalid subnet"))
+	ErrIP        = NewError(ErrParse, errors.New("invalid ip"))
+	ErrPort      = NewError(ErrParse, errors.New("invalid port"))
+	ErrJSON      = NewError(ErrParse, errors.New("invalid json"))
 )
 
 type Request struct {
@@ -28,6 +34,8 @@ type Request struct {
 	SrcMAC  []byte
 	DstMAC  []byte
 	DstPort uint16
+	// DstName is a DNS name of the target for scans that operate on names instead of IP addresses
+	DstName string
 	Err     error
 }
 
@@ -49,6 +57,10 @@ func (err *portError) GetPort() (uint16, error) {
 	return 0, err
 }
 
+// PortGenerator generates ports of the scan range.
+// Ports returns an error if the range is invalid, otherwise it writes ports to the returned channel
+// in a separate goroutine and closes the channel when all ports are written or the context is done.
+// Errors of individual ports are written as PortGetters that return the error.
 type PortGenerator interface {
 	Ports(ctx context.Context, r *Range) (<-chan PortGetter, error)
 }
@@ -74,7 +86,11 @@ func (*portGenerator) Ports(ctx context.Context, r *Range) (<-chan PortGetter, e
 			}
 			basePort := int64(portRange.StartPort) - 1
 			for {
-				writePort(ctx, out, WrapPort(basePort+it.Int().Int64()))
+				select {
+				case <-ctx.Done():
+					return
+				case out <- WrapPort(basePort + it.Int().Int64()):
+				}
 				if !it.Next() {
 					break
 				}
@@ -114,6 +130,11 @@ func (i WrapIP) GetIP() (net.IP, error) {
 	return net.IP(i), nil
 }
 
+// IPGenerator generates destination IP addresses of the scan range.
+// It follows the PortGenerator contract: the returned channel is closed when all addresses are written
+// or the context is done, errors of individual addresses are written as IPGetters that return the error.
+// IPs may be called several times, e.g. once per port, and each call must start from the first address.
+// IPGetters that implement MetaGetter attach metadata to requests of the address.
 type IPGenerator interface {
 	IPs(ctx context.Context, r *Range) (<-chan IPGetter, error)
 }
@@ -144,11 +165,14 @@ func (*ipGenerator) IPs(ctx context.Context, r *Range) (<-chan IPGetter, error)
 		for {
 			i := it.Int()
 			baseIP.Add(baseIP, i)
-			// TODO IPv6
-			ipaddr := baseIP.FillBytes(make([]byte, 4))
+			ipaddr := baseIP.FillBytes(make([]byte, len(ipnet.Mask)))
 			baseIP.Sub(baseIP, i)
 
-			writeIP(ctx, out, WrapIP(ipaddr))
+			select {
+			case <-ctx.Done():
+				return
+			case out <- WrapIP(ipaddr):
+			}
 
 			if !it.Next() {
 				return
@@ -158,6 +182,13 @@ func (*ipGenerator) IPs(ctx context.Context, r *Range) (<-chan IPGetter, error)
 	return out, nil
 }
 
+// RequestGenerator generates scan requests, it is the source of targets of the scan engine.
+// GenerateRequests returns an error if the generator can't start, e.g. the input file doesn't exist,
+// otherwise it writes requests to the returned channel in a separate goroutine and closes the channel
+// when all requests are written or the context is done. Sending to the channel must not block
+// after the context is done. Errors of individual targets are written as requests with the Err field,
+// the engine reports them and continues. GenerateRequests may be called several times, e.g. by live scans,
+// and each call must generate all requests again. The Meta field of requests is added to scan results.
 type RequestGenerator interface {
 	GenerateRequests(ctx context.Context, r *Range) (<-chan *Request, error)
 }
@@ -193,7 +224,10 @@ func (rg *ipPortGenerator) GenerateRequests(ctx context.Context, r *Range) (<-ch
 				dstip, err := ipaddr.GetIP()
 				writeRequest(ctx, out, &Request{
 					SrcIP: r.SrcIP, SrcMAC: r.SrcMAC,
-					DstIP: dstip, DstPort: port, Err: err})
+					DstIP: dstip, DstPort: port, Meta: getMeta(ipaddr), Err: err})
+			}
+			if ctx.Err() != nil {
+				return
 			}
 			if ips, err = rg.ipgen.IPs(ctx, r); err != nil {
 				writeRequest(ctx, out, &Request{Err: err})
@@ -232,7 +266,7 @@ func (rg *ipRequestGenerator) GenerateRequests(ctx context.Context, r *Range) (<
 			dstip, err := ipaddr.GetIP()
 			writeRequest(ctx, out, &Request{
 				SrcIP: r.SrcIP, SrcMAC: r.SrcMAC, DstIP: dstip,
-				Err: err,
+				Meta: getMeta(ipaddr), Err: err,
 			})
 		}
 	}()
@@ -246,13 +280,117 @@ type IPPort struct {
 }
 
 type fileIPPortGenerator struct {
+	fileSource
 	openFile OpenFileFunc
 }
 
 type OpenFileFunc func() (io.ReadCloser, error)
 
-func NewFileIPPortGenerator(openFile OpenFileFunc) RequestGenerator {
-	return &fileIPPortGenerator{openFile}
+func NewFileIPPortGenerator(openFile OpenFileFunc, opts ...FileGeneratorOption) RequestGenerator {
+	rg := &fileIPPortGenerator{openFile: openFile}
+	for _, o := range opts {
+		o(&rg.fileSource)
+	}
+	return rg
+}
+
+// Metadata keys of the request source
+const (
+	MetaSource = "source"
+	MetaLine   = "line"
+)
+
+type FileGeneratorOption func(*fileSource)
+
+// WithSource enables adding the input file name and the line number of each target to request metadata,
+// so results can be traced back to the exact input record
+func WithSource(name string) FileGeneratorOption {
+	return func(s *fileSource) {
+		s.name = name
+	}
+}
+
+type fileSource struct {
+	name string
+}
+
+// meta returns request metadata of the input line, it is nil if the source is disabled
+func (s *fileSource) meta(line int) map[string]interface{} {
+	if len(s.name) == 0 {
+		return nil
+	}
+	return map[string]interface{}{MetaSource: s.name, MetaLine: line}
+}
+
+// MetaGetter is implemented by IPGetters that carry request metadata, e.g. the source of the IP
+type MetaGetter interface {
+	GetMeta() map[string]interface{}
+}
+
+type metaIP struct {
+	WrapIP
+	meta map[string]interface{}
+}
+
+func (i *metaIP) GetMeta() map[string]interface{} {
+	return i.meta
+}
+
+func getMeta(ip IPGetter) map[string]interface{} {
+	if g, ok := ip.(MetaGetter); ok {
+		return g.GetMeta()
+	}
+	return nil
+}
+
+// MatchMeta checks that every condition holds for the metadata, conditions are values of metadata fields
+// with nested fields separated by dots, e.g. tags.env, list values match if any of their elements is equal
+// to the expected value
+func MatchMeta(meta map[string]interface{}, conditions map[string]string) bool {
+	for path, expected := range conditions {
+		if !matchValue(lookupMeta(meta, path), expected) {
+			return false
+		}
+	}
+	return true
+}
+
+func lookupMeta(meta map[string]interface{}, path string) interface{} {
+	var value interface{} = meta
+	for _, key := range strings.Split(path, ".") {
+		switch m := value.(type) {
+		case map[string]interface{}:
+			value = m[key]
+		case map[string]string:
+			value = m[key]
+		default:
+			return nil
+		}
+	}
+	return value
+}
+
+func matchValue(value interface{}, expected string) bool {
+	switch v := value.(type) {
+	case nil:
+		return false
+	case []string:
+		for _, e := range v {
+			if e == expected {
+				return true
+			}
+		}
+		return false
+	case []interface{}:
+		for _, e := range v {
+			if matchValue(e, expected) {
+				return true
+			}
+		}
+		return false
+	default:
+		return fmt.Sprint(v) == expected
+	}
 }
 
 func (rg *fileIPPortGenerator) GenerateRequests(ctx context.Context, r *Range) (<-chan *Request, error) {
@@ -266,15 +404,17 @@ func (rg *fileIPPortGenerator) GenerateRequests(ctx context.Context, r *Range) (
 		defer input.Close()
 		scanner := bufio.NewScanner(input)
 		var entry IPPort
+		var line int
 		for scanner.Scan() {
+			line++
 			entry.IP = ""
 			entry.Port = 0
 			if err := entry.UnmarshalJSON(scanner.Bytes()); err != nil {
 				writeRequest(ctx, out, &Request{Err: ErrJSON})
 				return
 			}
-			ip := net.ParseIP(entry.IP)
-			if ip == nil {
+			dstIP := ip.ParseIP(entry.IP)
+			if dstIP == nil {
 				writeRequest(ctx, out, &Request{Err: ErrIP})
 				continue
 			}
@@ -283,7 +423,8 @@ func (rg *fileIPPortGenerator) GenerateRequests(ctx context.Context, r *Range) (
 				continue
 			}
 			writeRequest(ctx, out, &Request{
-				SrcIP: r.SrcIP, SrcMAC: r.SrcMAC, DstIP: ip, DstPort: uint16(entry.Port)})
+				SrcIP: r.SrcIP, SrcMAC: r.SrcMAC, DstIP: dstIP, DstPort: uint16(entry.Port),
+				Meta: rg.meta(line)})
 		}
 		if err = scanner.Err(); err != nil {
 			writeRequest(ctx, out, &Request{Err: err})
@@ -305,11 +446,16 @@ func (err *ipError) GetIP() (net.IP, error) {
 }
 
 type fileIPGenerator struct {
+	fileSource
 	openFile OpenFileFunc
 }
 
-func NewFileIPGenerator(openFile OpenFileFunc) IPGenerator {
-	return &fileIPGenerator{openFile}
+func NewFileIPGenerator(openFile OpenFileFunc, opts ...FileGeneratorOption) IPGenerator {
+	g := &fileIPGenerator{openFile: openFile}
+	for _, o := range opts {
+		o(&g.fileSource)
+	}
+	return g
 }
 
 func (g *fileIPGenerator) IPs(ctx context.Context, _ *Range) (<-chan IPGetter, error) {
@@ -323,17 +469,23 @@ func (g *fileIPGenerator) IPs(ctx context.Context, _ *Range) (<-chan IPGetter, e
 		defer input.Close()
 		scanner := bufio.NewScanner(input)
 		var entry IPPort
+		var line int
 		for scanner.Scan() {
+			line++
 			if err := entry.UnmarshalJSON(scanner.Bytes()); err != nil {
 				writeIP(ctx, out, &ipError{error: ErrJSON})
 				return
 			}
-			ip := net.ParseIP(entry.IP)
-			if ip == nil {
+			dstIP := ip.ParseIP(entry.IP)
+			if dstIP == nil {
 				writeIP(ctx, out, &ipError{error: ErrIP})
 				return
 			}
-			writeIP(ctx, out, WrapIP(ip))
+			if meta := g.meta(line); meta != nil {
+				writeIP(ctx, out, &metaIP{WrapIP: WrapIP(dstIP), meta: meta})
+				continue
+			}
+			writeIP(ctx, out, WrapIP(dstIP))
 		}
 		if err = scanner.Err(); err != nil {
 			writeIP(ctx, out, &ipError{error: err})
@@ -353,13 +505,23 @@ func writeIP(ctx context.Context, out chan<- IPGetter, ip IPGetter) {
 type liveRequestGenerator struct {
 	delegate      RequestGenerator
 	rescanTimeout time.Duration
+	onRun         func(ctx context.Context, run int)
 }
 
 func NewLiveRequestGenerator(rg RequestGenerator, rescanTimeout time.Duration) RequestGenerator {
-	return &liveRequestGenerator{rg, rescanTimeout}
+	return &liveRequestGenerator{delegate: rg, rescanTimeout: rescanTimeout}
+}
+
+// NewMonitorRequestGenerator repeats requests of the delegate like the live generator,
+// onRun is called with the 1-based number of each run before requests of the run are generated
+func NewMonitorRequestGenerator(rg RequestGenerator, interval time.Duration,
+	onRun func(ctx context.Context, run int)) RequestGenerator {
+	return &liveRequestGenerator{delegate: rg, rescanTimeout: interval, onRun: onRun}
 }
 
 func (rg *liveRequestGenerator) GenerateRequests(ctx context.Context, r *Range) (<-chan *Request, error) {
+	run := 1
+	rg.startRun(ctx, run)
 	requests, err := rg.delegate.GenerateRequests(ctx, r)
 	if err != nil {
 		return nil, err
@@ -378,6 +540,8 @@ func (rg *liveRequestGenerator) GenerateRequests(ctx context.Context, r *Range)
 			case <-ctx.Done():
 				return
 			case <-time.After(rg.rescanTimeout):
+				run++
+				rg.startRun(ctx, run)
 				requests, _ = rg.delegate.GenerateRequests(ctx, r)
 			}
 		}
@@ -385,6 +549,12 @@ func (rg *liveRequestGenerator) GenerateRequests(ctx context.Context, r *Range)
 	return out, nil
 }
 
+func (rg *liveRequestGenerator) startRun(ctx context.Context, run int) {
+	if rg.onRun != nil {
+		rg.onRun(ctx, run)
+	}
+}
+
 func readRequest(ctx context.Context, requests <-chan *Request) (request *Request, ok bool) {
 	select {
 	case <-ctx.Done():
@@ -393,6 +563,66 @@ func readRequest(ctx context.Context, requests <-chan *Request) (request *Reques
 	return
 }
 
+type fileNamePortGenerator struct {
+	fileSource
+	openFile OpenFileFunc
+	portgen  PortGenerator
+}
+
+// NewFileNamePortGenerator creates a RequestGenerator that pairs each DNS name
+// read from the file with each port of the scan range. The file contains one name per line,
+// blank lines and comments starting with # are ignored. Scans that operate on DNS names
+// may reuse the port concept for other numeric parameters, e.g. DNS record types.
+func NewFileNamePortGenerator(openFile OpenFileFunc, portgen PortGenerator, opts ...FileGeneratorOption) RequestGenerator {
+	rg := &fileNamePortGenerator{openFile: openFile, portgen: portgen}
+	for _, o := range opts {
+		o(&rg.fileSource)
+	}
+	return rg
+}
+
+func (rg *fileNamePortGenerator) GenerateRequests(ctx context.Context, r *Range) (<-chan *Request, error) {
+	portc, err := rg.portgen.Ports(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	var ports []PortGetter
+	for p := range portc {
+		ports = append(ports, p)
+	}
+	input, err := rg.openFile()
+	if err != nil {
+		return nil, err
+	}
+	out := make(chan *Request, 100)
+	go func() {
+		defer close(out)
+		defer input.Close()
+		scanner := bufio.NewScanner(input)
+		var line int
+		for scanner.Scan() {
+			line++
+			name := scanner.Text()
+			if comment := strings.Index(name, "#"); comment != -1 {
+				name = name[:comment]
+			}
+			if name = strings.TrimSpace(name); len(name) == 0 {
+				continue
+			}
+			for _, p := range ports {
+				port, err := p.GetPort()
+				writeRequest(ctx, out, &Request{
+					SrcIP: r.SrcIP, SrcMAC: r.SrcMAC,
+					DstName: name, DstPort: port, Meta: rg.meta(line), Err: err})
+			}
+		}
+		if err = scanner.Err(); err != nil {
+			writeRequest(ctx, out, &Request{Err: err})
+		}
+	}()
+	return out, nil
+}
+
 type IPContainer interface {
 	Contains(ip net.IP) (bool, error)
 }
@@ -434,3 +664,73 @@ func (rg *filterIPRequestGenerator) GenerateRequests(ctx context.Context, r *Ran
 	}()
 	return out, nil
 }
+
+// CountRequestGenerator counts requests of the delegate generator
+type CountRequestGenerator struct {
+	delegate RequestGenerator
+	count    int64
+}
+
+func NewCountRequestGenerator(delegate RequestGenerator) *CountRequestGenerator {
+	return &CountRequestGenerator{delegate: delegate}
+}
+
+func (rg *CountRequestGenerator) GenerateRequests(ctx context.Context, r *Range) (<-chan *Request, error) {
+	requests, err := rg.delegate.GenerateRequests(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	out := make(chan *Request, cap(requests))
+	go func() {
+		defer close(out)
+		var request *Request
+		var ok bool
+		for {
+			if request, ok = readRequest(ctx, requests); !ok {
+				return
+			}
+			atomic.AddInt64(&rg.count, 1)
+			writeRequest(ctx, out, request)
+		}
+	}()
+	return out, nil
+}
+
+// Count returns the number of generated requests
+func (rg *CountRequestGenerator) Count() int64 {
+	return atomic.LoadInt64(&rg.count)
+}
+
+type observeRequestGenerator struct {
+	delegate RequestGenerator
+	observe  func(r *Request)
+}
+
+// NewObserveRequestGenerator calls observe with each request of the delegate generator without the error
+// before the request is scanned, e.g. to record targets scanned in the run
+func NewObserveRequestGenerator(delegate RequestGenerator, observe func(r *Request)) RequestGenerator {
+	return &observeRequestGenerator{delegate: delegate, observe: observe}
+}
+
+func (rg *observeRequestGenerator) GenerateRequests(ctx context.Context, r *Range) (<-chan *Request, error) {
+	requests, err := rg.delegate.GenerateRequests(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+	out := make(chan *Request, cap(requests))
+	go func() {
+		defer close(out)
+		var request *Request
+		var ok bool
+		for {
+			if request, ok = readRequest(ctx, requests); !ok {
+				return
+			}
+			if request.Err == nil {
+				rg.observe(request)
+			}
+			writeRequest(ctx, out, request)
+		}
+	}()
+	return out, nil
+}
@@ -4,11 +4,13 @@ import (
 	"bytes"
 	"context"
 	"errors"
+	"fmt"
 	"io"
 	"math/big"
 	"net"
 	"sort"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -329,6 +331,16 @@ func TestIPGenerator(t *testing.T) {
 				WrapIP(net.IPv4(10, 0, 0, 3).To4()),
 			},
 		},
+		{
+			name: "IPv6TwoIPs",
+			scanRange: newScanRange(
+				withSubnet(&net.IPNet{IP: net.ParseIP("2001:db8::1"), Mask: net.CIDRMask(127, 128)}),
+			),
+			expected: []interface{}{
+				WrapIP(net.ParseIP("2001:db8::")),
+				WrapIP(net.ParseIP("2001:db8::1")),
+			},
+		},
 	}
 
 	for _, vtt := range tests {
@@ -500,6 +512,28 @@ func TestIPPortGenerator(t *testing.T) {
 	}
 }
 
+func TestIPPortGeneratorContextExit(t *testing.T) {
+	t.Parallel()
+
+	done := make(chan interface{})
+	go func() {
+		defer close(done)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		reqgen := NewIPPortGenerator(NewIPGenerator(), NewPortGenerator())
+		requests, err := reqgen.GenerateRequests(ctx, newScanRange(
+			withSubnet(&net.IPNet{IP: net.IPv4(10, 0, 0, 0), Mask: net.CIDRMask(8, 32)}),
+			withPorts([]*PortRange{{StartPort: 1, EndPort: 65535}}),
+		))
+		require.NoError(t, err)
+		<-requests
+		cancel()
+		for range requests {
+		}
+	}()
+	waitDone(t, done)
+}
+
 func TestIPPortGeneratorError(t *testing.T) {
 	t.Parallel()
 
@@ -645,14 +679,14 @@ func TestFileIPPortGenerator(t *testing.T) {
 			name:  "OneIPPort",
 			input: `{"ip":"192.168.0.1","port":888}`,
 			expected: []interface{}{
-				&Request{DstIP: net.IPv4(192, 168, 0, 1), DstPort: 888},
+				&Request{DstIP: net.IPv4(192, 168, 0, 1).To4(), DstPort: 888},
 			},
 		},
 		{
 			name:  "OneIPPortWithUnknownField",
 			input: `{"ip":"192.168.0.1","port":888,"abc":"field"}`,
 			expected: []interface{}{
-				&Request{DstIP: net.IPv4(192, 168, 0, 1), DstPort: 888},
+				&Request{DstIP: net.IPv4(192, 168, 0, 1).To4(), DstPort: 888},
 			},
 		},
 		{
@@ -662,8 +696,21 @@ func TestFileIPPortGenerator(t *testing.T) {
 				`{"ip":"192.168.0.2","port":222}`,
 			}, "\n"),
 			expected: []interface{}{
-				&Request{DstIP: net.IPv4(192, 168, 0, 1), DstPort: 888},
-				&Request{DstIP: net.IPv4(192, 168, 0, 2), DstPort: 222},
+				&Request{DstIP: net.IPv4(192, 168, 0, 1).To4(), DstPort: 888},
+				&Request{DstIP: net.IPv4(192, 168, 0, 2).To4(), DstPort: 222},
+			},
+		},
+		{
+			name: "MixedNotation",
+			input: strings.Join([]string{
+				`{"ip":"::ffff:192.168.0.1","port":888}`,
+				`{"ip":"3232235522","port":888}`,
+				`{"ip":"0xc0a80003","port":888}`,
+			}, "\n"),
+			expected: []interface{}{
+				&Request{DstIP: net.IPv4(192, 168, 0, 1).To4(), DstPort: 888},
+				&Request{DstIP: net.IPv4(192, 168, 0, 2).To4(), DstPort: 888},
+				&Request{DstIP: net.IPv4(192, 168, 0, 3).To4(), DstPort: 888},
 			},
 		},
 		{
@@ -680,7 +727,7 @@ func TestFileIPPortGenerator(t *testing.T) {
 				`{"ip":"192`,
 			}, "\n"),
 			expected: []interface{}{
-				&Request{DstIP: net.IPv4(192, 168, 0, 1), DstPort: 888},
+				&Request{DstIP: net.IPv4(192, 168, 0, 1).To4(), DstPort: 888},
 				&Request{Err: ErrJSON},
 			},
 		},
@@ -692,7 +739,7 @@ func TestFileIPPortGenerator(t *testing.T) {
 				`{"ip":"192.168.0.3","port":888}`,
 			}, "\n"),
 			expected: []interface{}{
-				&Request{DstIP: net.IPv4(192, 168, 0, 1), DstPort: 888},
+				&Request{DstIP: net.IPv4(192, 168, 0, 1).To4(), DstPort: 888},
 				&Request{Err: ErrJSON},
 			},
 		},
@@ -717,7 +764,7 @@ func TestFileIPPortGenerator(t *testing.T) {
 				`{"ip":"192.168.0.3"}`,
 			}, "\n"),
 			expected: []interface{}{
-				&Request{DstIP: net.IPv4(192, 168, 0, 1), DstPort: 888},
+				&Request{DstIP: net.IPv4(192, 168, 0, 1).To4(), DstPort: 888},
 				&Request{Err: ErrPort},
 			},
 		},
@@ -728,7 +775,7 @@ func TestFileIPPortGenerator(t *testing.T) {
 				`{"port":888}`,
 			}, "\n"),
 			expected: []interface{}{
-				&Request{DstIP: net.IPv4(192, 168, 0, 1), DstPort: 888},
+				&Request{DstIP: net.IPv4(192, 168, 0, 1).To4(), DstPort: 888},
 				&Request{Err: ErrIP},
 			},
 		},
@@ -736,14 +783,14 @@ func TestFileIPPortGenerator(t *testing.T) {
 			name:  "OneIPPortWithSrcIPandSrcMAC",
 			input: `{"ip":"192.168.0.1","port":888}`,
 			scanRange: &Range{
-				SrcIP:  net.IPv4(192, 168, 0, 3),
+				SrcIP:  net.IPv4(192, 168, 0, 3).To4(),
 				SrcMAC: net.HardwareAddr{0x01, 0x02, 0x03, 0x04, 0x05, 0x06},
 			},
 			expected: []interface{}{
 				&Request{
-					SrcIP:   net.IPv4(192, 168, 0, 3),
+					SrcIP:   net.IPv4(192, 168, 0, 3).To4(),
 					SrcMAC:  net.HardwareAddr{0x01, 0x02, 0x03, 0x04, 0x05, 0x06},
-					DstIP:   net.IPv4(192, 168, 0, 1),
+					DstIP:   net.IPv4(192, 168, 0, 1).To4(),
 					DstPort: 888,
 				},
 			},
@@ -774,6 +821,171 @@ func TestFileIPPortGenerator(t *testing.T) {
 	}
 }
 
+func TestFileIPPortGeneratorWithSource(t *testing.T) {
+	t.Parallel()
+
+	done := make(chan interface{})
+	go func() {
+		defer close(done)
+
+		reqgen := NewFileIPPortGenerator(func() (io.ReadCloser, error) {
+			return io.NopCloser(strings.NewReader(strings.Join([]string{
+				`{"ip":"192.168.0.1","port":888}`,
+				`{"ip":"192.168.0.1111","port":888}`,
+				`{"ip":"192.168.0.3","port":888}`,
+			}, "\n"))), nil
+		}, WithSource("targets.jsonl"))
+		requests, err := reqgen.GenerateRequests(context.Background(), &Range{})
+		require.NoError(t, err)
+		result := chanToSlice(t, chanPairToGeneric(requests), 3)
+		require.Equal(t, []interface{}{
+			&Request{DstIP: net.IPv4(192, 168, 0, 1).To4(), DstPort: 888,
+				Meta: map[string]interface{}{MetaSource: "targets.jsonl", MetaLine: 1}},
+			&Request{Err: ErrIP},
+			&Request{DstIP: net.IPv4(192, 168, 0, 3).To4(), DstPort: 888,
+				Meta: map[string]interface{}{MetaSource: "targets.jsonl", MetaLine: 3}},
+		}, result)
+	}()
+	waitDone(t, done)
+}
+
+func TestIPPortGeneratorWithFileIPSource(t *testing.T) {
+	t.Parallel()
+
+	done := make(chan interface{})
+	go func() {
+		defer close(done)
+
+		ipgen := NewFileIPGenerator(func() (io.ReadCloser, error) {
+			return io.NopCloser(strings.NewReader(strings.Join([]string{
+				`{"ip":"192.168.0.1"}`,
+				`{"ip":"192.168.0.2"}`,
+			}, "\n"))), nil
+		}, WithSource("ips.jsonl"))
+		reqgen := NewIPPortGenerator(ipgen, NewPortGenerator())
+		requests, err := reqgen.GenerateRequests(context.Background(), &Range{
+			Ports: []*PortRange{{StartPort: 22, EndPort: 22}},
+		})
+		require.NoError(t, err)
+		result := chanToSlice(t, chanPairToGeneric(requests), 2)
+		require.Equal(t, []interface{}{
+			&Request{DstIP: net.IPv4(192, 168, 0, 1).To4(), DstPort: 22,
+				Meta: map[string]interface{}{MetaSource: "ips.jsonl", MetaLine: 1}},
+			&Request{DstIP: net.IPv4(192, 168, 0, 2).To4(), DstPort: 22,
+				Meta: map[string]interface{}{MetaSource: "ips.jsonl", MetaLine: 2}},
+		}, result)
+	}()
+	waitDone(t, done)
+}
+
+func TestFileNamePortGeneratorWithInvalidFile(t *testing.T) {
+	t.Parallel()
+
+	reqgen := NewFileNamePortGenerator(func() (io.ReadCloser, error) {
+		return nil, errors.New("open file error")
+	}, NewPortGenerator())
+	_, err := reqgen.GenerateRequests(context.Background(), &Range{
+		Ports: []*PortRange{{StartPort: 1, EndPort: 1}},
+	})
+	require.Error(t, err)
+}
+
+func TestFileNamePortGeneratorWithInvalidPorts(t *testing.T) {
+	t.Parallel()
+
+	reqgen := NewFileNamePortGenerator(func() (io.ReadCloser, error) {
+		return io.NopCloser(strings.NewReader("example.com")), nil
+	}, NewPortGenerator())
+	_, err := reqgen.GenerateRequests(context.Background(), &Range{})
+	require.Error(t, err)
+}
+
+func TestFileNamePortGenerator(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		input    string
+		ports    []*PortRange
+		expected []interface{}
+	}{
+		{
+			name:  "OneNameOnePort",
+			input: "example.com",
+			ports: []*PortRange{{StartPort: 1, EndPort: 1}},
+			expected: []interface{}{
+				&Request{DstName: "example.com", DstPort: 1},
+			},
+		},
+		{
+			name:  "OneNameTwoPorts",
+			input: "example.com",
+			ports: []*PortRange{{StartPort: 1, EndPort: 1}, {StartPort: 28, EndPort: 28}},
+			expected: []interface{}{
+				&Request{DstName: "example.com", DstPort: 1},
+				&Request{DstName: "example.com", DstPort: 28},
+			},
+		},
+		{
+			name: "TwoNamesWithCommentsAndBlankLines",
+			input: strings.Join([]string{
+				"# targets",
+				"example.com",
+				"",
+				"  example.org  # second",
+			}, "\n"),
+			ports: []*PortRange{{StartPort: 15, EndPort: 15}},
+			expected: []interface{}{
+				&Request{DstName: "example.com", DstPort: 15},
+				&Request{DstName: "example.org", DstPort: 15},
+			},
+		},
+	}
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			done := make(chan interface{})
+			go func() {
+				defer close(done)
+
+				reqgen := NewFileNamePortGenerator(func() (io.ReadCloser, error) {
+					return io.NopCloser(strings.NewReader(tt.input)), nil
+				}, NewPortGenerator())
+				requests, err := reqgen.GenerateRequests(context.Background(), &Range{Ports: tt.ports})
+				require.NoError(t, err)
+				result := chanToSlice(t, chanPairToGeneric(requests), len(tt.expected))
+				require.Equal(t, tt.expected, result)
+			}()
+			waitDone(t, done)
+		})
+	}
+}
+
+func TestFileNamePortGeneratorWithSource(t *testing.T) {
+	t.Parallel()
+
+	done := make(chan interface{})
+	go func() {
+		defer close(done)
+
+		reqgen := NewFileNamePortGenerator(func() (io.ReadCloser, error) {
+			return io.NopCloser(strings.NewReader("# targets\n\nexample.com")), nil
+		}, NewPortGenerator(), WithSource("names.txt"))
+		requests, err := reqgen.GenerateRequests(context.Background(), &Range{
+			Ports: []*PortRange{{StartPort: 1, EndPort: 1}},
+		})
+		require.NoError(t, err)
+		result := chanToSlice(t, chanPairToGeneric(requests), 1)
+		require.Equal(t, []interface{}{
+			&Request{DstName: "example.com", DstPort: 1,
+				Meta: map[string]interface{}{MetaSource: "names.txt", MetaLine: 3}},
+		}, result)
+	}()
+	waitDone(t, done)
+}
+
 func TestFileIPGeneratorWithInvalidFile(t *testing.T) {
 	t.Parallel()
 
@@ -796,14 +1008,14 @@ func TestFileIPGenerator(t *testing.T) {
 			name:  "OneIP",
 			input: `{"ip":"192.168.0.1"}`,
 			expected: []interface{}{
-				WrapIP(net.IPv4(192, 168, 0, 1)),
+				WrapIP(net.IPv4(192, 168, 0, 1).To4()),
 			},
 		},
 		{
 			name:  "OneIPWithUnknownField",
 			input: `{"ip":"192.168.0.1","abc":"field"}`,
 			expected: []interface{}{
-				WrapIP(net.IPv4(192, 168, 0, 1)),
+				WrapIP(net.IPv4(192, 168, 0, 1).To4()),
 			},
 		},
 		{
@@ -813,8 +1025,8 @@ func TestFileIPGenerator(t *testing.T) {
 				`{"ip":"192.168.0.2"}`,
 			}, "\n"),
 			expected: []interface{}{
-				WrapIP(net.IPv4(192, 168, 0, 1)),
-				WrapIP(net.IPv4(192, 168, 0, 2)),
+				WrapIP(net.IPv4(192, 168, 0, 1).To4()),
+				WrapIP(net.IPv4(192, 168, 0, 2).To4()),
 			},
 		},
 		{
@@ -831,7 +1043,7 @@ func TestFileIPGenerator(t *testing.T) {
 				`{"ip":"192`,
 			}, "\n"),
 			expected: []interface{}{
-				WrapIP(net.IPv4(192, 168, 0, 1)),
+				WrapIP(net.IPv4(192, 168, 0, 1).To4()),
 				&ipError{error: ErrJSON},
 			},
 		},
@@ -843,7 +1055,7 @@ func TestFileIPGenerator(t *testing.T) {
 				`{"ip":"192.168.0.3","port":888}`,
 			}, "\n"),
 			expected: []interface{}{
-				WrapIP(net.IPv4(192, 168, 0, 1)),
+				WrapIP(net.IPv4(192, 168, 0, 1).To4()),
 				&ipError{error: ErrJSON},
 			},
 		},
@@ -900,6 +1112,41 @@ loop:
 	}
 }
 
+func TestMonitorRequestGenerator(t *testing.T) {
+	t.Parallel()
+
+	done := make(chan interface{})
+	go func() {
+		defer close(done)
+		var runs []int
+		var mu sync.Mutex
+		reqgen := NewIPPortGenerator(NewIPGenerator(), NewPortGenerator())
+		rg := NewMonitorRequestGenerator(reqgen, 10*time.Millisecond, func(_ context.Context, run int) {
+			mu.Lock()
+			defer mu.Unlock()
+			runs = append(runs, run)
+		})
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		requests, err := rg.GenerateRequests(ctx, &Range{
+			DstSubnet: &net.IPNet{IP: net.IPv4(192, 168, 0, 1), Mask: net.CIDRMask(32, 32)},
+			Ports:     []*PortRange{{StartPort: 22, EndPort: 22}},
+		})
+		require.NoError(t, err)
+		for i := 1; i <= 3; i++ {
+			request := <-requests
+			require.Equal(t, net.IPv4(192, 168, 0, 1).To4(), request.DstIP.To4())
+			require.Equal(t, uint16(22), request.DstPort)
+			mu.Lock()
+			// the next run may start before the request is checked
+			require.GreaterOrEqual(t, len(runs), i)
+			require.Equal(t, i, runs[i-1])
+			mu.Unlock()
+		}
+	}()
+	waitDone(t, done)
+}
+
 func TestFilterIPRequestGenerator(t *testing.T) {
 	t.Parallel()
 
@@ -1062,3 +1309,166 @@ func TestFilterIPRequestGeneratorWithIPContainerError(t *testing.T) {
 	}()
 	waitDone(t, done)
 }
+
+func TestCountRequestGenerator(t *testing.T) {
+	t.Parallel()
+
+	done := make(chan interface{})
+	go func() {
+		defer close(done)
+
+		ctrl := gomock.NewController(t)
+		delegate := NewMockRequestGenerator(ctrl)
+
+		input := make(chan *Request, 3)
+		input <- newScanRequest(withDstIP(net.IPv4(10, 0, 1, 1).To4()))
+		input <- newScanRequest(withDstIP(net.IPv4(10, 0, 1, 2).To4()))
+		input <- &Request{Err: errors.New("generate error")}
+		close(input)
+		r := newScanRange()
+		delegate.EXPECT().GenerateRequests(gomock.Not(gomock.Nil()), r).Return(input, nil)
+
+		reqgen := NewCountRequestGenerator(delegate)
+		require.Equal(t, int64(0), reqgen.Count())
+		requests, err := reqgen.GenerateRequests(context.Background(), r)
+		require.NoError(t, err)
+
+		result := chanToSlice(t, chanPairToGeneric(requests), 3)
+		require.Len(t, result, 3)
+		require.Equal(t, int64(3), reqgen.Count())
+	}()
+	waitDone(t, done)
+}
+
+func TestCountRequestGeneratorWithGeneratorError(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	delegate := NewMockRequestGenerator(ctrl)
+	r := newScanRange()
+	delegate.EXPECT().GenerateRequests(gomock.Not(gomock.Nil()), r).
+		Return(nil, errors.New("generate error"))
+
+	_, err := NewCountRequestGenerator(delegate).GenerateRequests(context.Background(), r)
+	require.Error(t, err)
+}
+
+func TestObserveRequestGenerator(t *testing.T) {
+	t.Parallel()
+
+	done := make(chan interface{})
+	go func() {
+		defer close(done)
+
+		ctrl := gomock.NewController(t)
+		delegate := NewMockRequestGenerator(ctrl)
+
+		input := make(chan *Request, 3)
+		input <- newScanRequest(withDstIP(net.IPv4(10, 0, 1, 1).To4()))
+		input <- &Request{Err: errors.New("generate error")}
+		input <- newScanRequest(withDstIP(net.IPv4(10, 0, 1, 2).To4()))
+		close(input)
+		r := newScanRange()
+		delegate.EXPECT().GenerateRequests(gomock.Not(gomock.Nil()), r).Return(input, nil)
+
+		var observed []net.IP
+		reqgen := NewObserveRequestGenerator(delegate, func(r *Request) {
+			observed = append(observed, r.DstIP)
+		})
+		requests, err := reqgen.GenerateRequests(context.Background(), r)
+		require.NoError(t, err)
+
+		result := chanToSlice(t, chanPairToGeneric(requests), 3)
+		require.Len(t, result, 3)
+		require.Equal(t, []net.IP{net.IPv4(10, 0, 1, 1).To4(), net.IPv4(10, 0, 1, 2).To4()}, observed)
+	}()
+	waitDone(t, done)
+}
+
+func TestObserveRequestGeneratorWithGeneratorError(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	delegate := NewMockRequestGenerator(ctrl)
+	r := newScanRange()
+	delegate.EXPECT().GenerateRequests(gomock.Not(gomock.Nil()), r).
+		Return(nil, errors.New("generate error"))
+
+	_, err := NewObserveRequestGenerator(delegate, func(*Request) {}).GenerateRequests(context.Background(), r)
+	require.Error(t, err)
+}
+
+// benchSubnet returns the smallest subnet with at least n ip addresses
+func benchSubnet(n int) *net.IPNet {
+	ones := 32
+	for ones > 8 && 1<<(32-ones) < n {
+		ones--
+	}
+	return &net.IPNet{IP: net.IPv4(10, 0, 0, 0), Mask: net.CIDRMask(ones, 32)}
+}
+
+// Generators are drained with the stopped timer so that they don't run in the background
+// during the next benchmarks.
+
+func BenchmarkIPGenerator(b *testing.B) {
+	b.ReportAllocs()
+	ips, err := NewIPGenerator().IPs(context.Background(), newScanRange(withSubnet(benchSubnet(b.N))))
+	require.NoError(b, err)
+	n := 0
+	for range ips {
+		if n++; n == b.N {
+			b.StopTimer()
+		}
+	}
+}
+
+func BenchmarkPortGenerator(b *testing.B) {
+	b.ReportAllocs()
+	portgen := NewPortGenerator()
+	scanRange := newScanRange(withPorts([]*PortRange{{StartPort: 1, EndPort: 65535}}))
+	for n := 0; n < b.N; {
+		ports, err := portgen.Ports(context.Background(), scanRange)
+		require.NoError(b, err)
+		for range ports {
+			if n++; n == b.N {
+				b.StopTimer()
+			}
+		}
+	}
+}
+
+func BenchmarkIPPortGenerator(b *testing.B) {
+	b.ReportAllocs()
+	const portCount = 16
+	reqgen := NewIPPortGenerator(NewIPGenerator(), NewPortGenerator())
+	requests, err := reqgen.GenerateRequests(context.Background(), newScanRange(
+		withSubnet(benchSubnet(b.N/portCount+1)),
+		withPorts([]*PortRange{{StartPort: 1, EndPort: portCount}}),
+	))
+	require.NoError(b, err)
+	n := 0
+	for range requests {
+		if n++; n == b.N {
+			b.StopTimer()
+		}
+	}
+}
+
+func BenchmarkFileIPPortGenerator(b *testing.B) {
+	b.ReportAllocs()
+	var input strings.Builder
+	for i := 0; i < b.N; i++ {
+		fmt.Fprintf(&input, `{"ip":"10.0.%d.%d","port":%d}`+"\n", (i>>8)&0xff, i&0xff, i%65535+1)
+	}
+	reqgen := NewFileIPPortGenerator(func() (io.ReadCloser, error) {
+		return io.NopCloser(strings.NewReader(input.String())), nil
+	})
+	b.ResetTimer()
+	requests, err := reqgen.GenerateRequests(context.Background(), newScanRange())
+	require.NoError(b, err)
+	for r := range requests {
+		if r.Err != nil {
+			b.Fatal(r.Err)
+		}
+	}
+}
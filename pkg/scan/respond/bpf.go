@@ -0,0 +1,24 @@
+package respond
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+// Set to typical maximum Ethernet frame size = MTU (1500 bytes)
+// + Ethernet header (14 bytes) + FCS (4 bytes)
+const MaxPacketLength = 1518
+
+// BPFFilter captures ARP requests and TCP SYNs to the virtual hosts of the responder subnet
+func (r *Responder) BPFFilter(*scan.Range) (filter string, maxPacketLength int) {
+	var filters []string
+	if r.arp {
+		filters = append(filters, fmt.Sprintf("(arp dst net %s)", r.subnet))
+	}
+	if r.syn {
+		filters = append(filters, fmt.Sprintf("(tcp and dst net %s and tcp[13] & 0x16 == 0x02)", r.subnet))
+	}
+	return strings.Join(filters, " or "), MaxPacketLength
+}
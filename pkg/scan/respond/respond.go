@@ -0,0 +1,259 @@
+//go:generate easyjson -output_filename result_easyjson.go respond.go
+
+package respond
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"net"
+	"strconv"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	"github.com/v-byte-cpu/sx/pkg/packet"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+const (
+	ProtoARP = "arp"
+	ProtoTCP = "tcp"
+
+	ReplyARP    = "is-at"
+	ReplySYNACK = "syn-ack"
+	ReplyRST    = "rst"
+)
+
+//easyjson:json
+type Result struct {
+	Proto   string `json:"proto"`
+	SrcIP   string `json:"srcip"`
+	DstIP   string `json:"dstip"`
+	DstPort uint16 `json:"dstport,omitempty"`
+	Reply   string `json:"reply"`
+}
+
+func (r *Result) String() string {
+	return fmt.Sprintf("%-5s %-20s %-22s %s", r.Proto, r.SrcIP, r.dst(), r.Reply)
+}
+
+func (r *Result) ID() string {
+	return fmt.Sprintf("%s:%s:%s", r.Proto, r.SrcIP, r.dst())
+}
+
+func (r *Result) dst() string {
+	if r.DstPort == 0 {
+		return r.DstIP
+	}
+	return net.JoinHostPort(r.DstIP, strconv.Itoa(int(r.DstPort)))
+}
+
+// Responder answers ARP requests and TCP SYNs for the virtual hosts of the subnet,
+// every answered packet is reported as a Result
+type Responder struct {
+	ctx     context.Context
+	subnet  *net.IPNet
+	mac     net.HardwareAddr
+	arp     bool
+	syn     bool
+	ports   []*scan.PortRange
+	results scan.ResultChan
+	replies chan *packet.BufferData
+	parser  *gopacket.DecodingLayerParser
+
+	rcvDecoded []gopacket.LayerType
+	rcvEth     layers.Ethernet
+	rcvARP     layers.ARP
+	rcvIP      layers.IPv4
+	rcvTCP     layers.TCP
+}
+
+// Assert that respond.Responder conforms to the scan.PacketMethod interface
+var _ scan.PacketMethod = (*Responder)(nil)
+
+type ResponderOption func(r *Responder)
+
+// WithARP enables replies to ARP requests
+func WithARP() ResponderOption {
+	return func(r *Responder) {
+		r.arp = true
+	}
+}
+
+// WithSYN enables replies to TCP SYNs: SYN-ACK for open ports and RST for others,
+// all ports are open if no port ranges are given
+func WithSYN(openPorts []*scan.PortRange) ResponderOption {
+	return func(r *Responder) {
+		r.syn = true
+		r.ports = openPorts
+	}
+}
+
+// NewResponder creates a Responder that answers on behalf of all IPs of the subnet with the given MAC address
+func NewResponder(ctx context.Context, subnet *net.IPNet, mac net.HardwareAddr,
+	results scan.ResultChan, opts ...ResponderOption) *Responder {
+	r := &Responder{
+		ctx:     ctx,
+		subnet:  subnet,
+		mac:     mac,
+		results: results,
+		replies: make(chan *packet.BufferData, 1000),
+	}
+	for _, o := range opts {
+		o(r)
+	}
+	parser := gopacket.NewDecodingLayerParser(layers.LayerTypeEthernet, &r.rcvEth, &r.rcvARP, &r.rcvIP, &r.rcvTCP)
+	parser.IgnoreUnsupported = true
+	r.parser = parser
+	return r
+}
+
+// Packets returns reply packets, the channel is never closed since
+// the responder works until the context is done
+func (r *Responder) Packets(context.Context, *scan.Range) <-chan *packet.BufferData {
+	return r.replies
+}
+
+func (r *Responder) Results() <-chan scan.Result {
+	return r.results.Chan()
+}
+
+func (r *Responder) ProcessPacketData(data []byte, _ *gopacket.CaptureInfo) error {
+	if err := r.parser.DecodeLayers(data, &r.rcvDecoded); err != nil {
+		return err
+	}
+	switch {
+	case r.arp && len(r.rcvDecoded) == 2 && r.rcvDecoded[1] == layers.LayerTypeARP:
+		return r.processARP()
+	case r.syn && len(r.rcvDecoded) == 3 && r.rcvDecoded[2] == layers.LayerTypeTCP:
+		return r.processTCP()
+	}
+	return nil
+}
+
+func (r *Responder) processARP() error {
+	req := &r.rcvARP
+	if req.Operation != layers.ARPRequest || !r.subnet.Contains(req.DstProtAddress) {
+		return nil
+	}
+	eth := &layers.Ethernet{
+		SrcMAC:       r.mac,
+		DstMAC:       req.SourceHwAddress,
+		EthernetType: layers.EthernetTypeARP,
+	}
+	reply := &layers.ARP{
+		AddrType:          layers.LinkTypeEthernet,
+		Protocol:          layers.EthernetTypeIPv4,
+		HwAddressSize:     uint8(6),
+		ProtAddressSize:   uint8(4),
+		Operation:         layers.ARPReply,
+		SourceHwAddress:   r.mac,
+		SourceProtAddress: req.DstProtAddress,
+		DstHwAddress:      req.SourceHwAddress,
+		DstProtAddress:    req.SourceProtAddress,
+	}
+	if err := r.reply(gopacket.SerializeOptions{}, eth, reply); err != nil {
+		return err
+	}
+	r.results.Put(&Result{
+		Proto: ProtoARP,
+		SrcIP: net.IP(req.SourceProtAddress).String(),
+		DstIP: net.IP(req.DstProtAddress).String(),
+		Reply: ReplyARP,
+	})
+	return nil
+}
+
+func (r *Responder) processTCP() error {
+	req := &r.rcvTCP
+	if !req.SYN || req.ACK || req.RST || !r.subnet.Contains(r.rcvIP.DstIP) {
+		return nil
+	}
+	eth := &layers.Ethernet{
+		SrcMAC:       r.mac,
+		DstMAC:       r.rcvEth.SrcMAC,
+		EthernetType: layers.EthernetTypeIPv4,
+	}
+	ip := &layers.IPv4{
+		Version:  4,
+		Id:       uint16(1 + rand.Intn(65535)),
+		Flags:    layers.IPv4DontFragment,
+		TTL:      64,
+		Protocol: layers.IPProtocolTCP,
+		SrcIP:    r.rcvIP.DstIP,
+		DstIP:    r.rcvIP.SrcIP,
+	}
+	tcp := &layers.TCP{
+		SrcPort: req.DstPort,
+		DstPort: req.SrcPort,
+		Ack:     req.Seq + 1,
+		ACK:     true,
+	}
+	result := ReplyRST
+	if r.isOpenPort(uint16(req.DstPort)) {
+		result = ReplySYNACK
+		tcp.SYN = true
+		tcp.Seq = rand.Uint32()
+		tcp.Window = 64240
+		// emulate typical Linux TCP options
+		tcp.Options = []layers.TCPOption{
+			{
+				OptionType:   layers.TCPOptionKindMSS,
+				OptionLength: 4,
+				OptionData:   []byte{0x05, 0xb4}, // 1460
+			},
+			{
+				OptionType:   layers.TCPOptionKindSACKPermitted,
+				OptionLength: 2,
+			},
+			{
+				OptionType:   layers.TCPOptionKindWindowScale,
+				OptionLength: 3,
+				OptionData:   []byte{7},
+			},
+		}
+	} else {
+		tcp.RST = true
+	}
+	if err := tcp.SetNetworkLayerForChecksum(ip); err != nil {
+		return err
+	}
+	opt := gopacket.SerializeOptions{FixLengths: true, ComputeChecksums: true}
+	if err := r.reply(opt, eth, ip, tcp); err != nil {
+		return err
+	}
+	r.results.Put(&Result{
+		Proto:   ProtoTCP,
+		SrcIP:   r.rcvIP.SrcIP.String(),
+		DstIP:   r.rcvIP.DstIP.String(),
+		DstPort: uint16(req.DstPort),
+		Reply:   result,
+	})
+	return nil
+}
+
+func (r *Responder) isOpenPort(port uint16) bool {
+	if len(r.ports) == 0 {
+		return true
+	}
+	for _, pr := range r.ports {
+		if port >= pr.StartPort && port <= pr.EndPort {
+			return true
+		}
+	}
+	return false
+}
+
+// reply serializes layers to a new buffer and queues it for sending,
+// so that the received packet data is not referenced after processing
+func (r *Responder) reply(opt gopacket.SerializeOptions, ls ...gopacket.SerializableLayer) error {
+	buf := packet.NewSerializeBuffer()
+	if err := gopacket.SerializeLayers(buf, opt, ls...); err != nil {
+		return err
+	}
+	select {
+	case <-r.ctx.Done():
+	case r.replies <- &packet.BufferData{Buf: buf}:
+	}
+	return nil
+}
@@ -0,0 +1,274 @@
+package respond
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	"github.com/stretchr/testify/require"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+var (
+	responderMAC = net.HardwareAddr{0x10, 0x11, 0x12, 0x13, 0x14, 0x15}
+	scannerMAC   = net.HardwareAddr{0x1, 0x2, 0x3, 0x4, 0x5, 0x6}
+	scannerIP    = net.IPv4(192, 168, 0, 3).To4()
+)
+
+func newTestResponder(ctx context.Context, opts ...ResponderOption) *Responder {
+	_, subnet, _ := net.ParseCIDR("10.0.0.0/24")
+	return NewResponder(ctx, subnet, responderMAC, scan.NewResultChan(ctx, 1000), opts...)
+}
+
+func arpRequest(t *testing.T, dstIP net.IP) []byte {
+	t.Helper()
+	buf := gopacket.NewSerializeBuffer()
+	err := gopacket.SerializeLayers(buf, gopacket.SerializeOptions{},
+		&layers.Ethernet{
+			SrcMAC:       scannerMAC,
+			DstMAC:       net.HardwareAddr{0xff, 0xff, 0xff, 0xff, 0xff, 0xff},
+			EthernetType: layers.EthernetTypeARP,
+		},
+		&layers.ARP{
+			AddrType:          layers.LinkTypeEthernet,
+			Protocol:          layers.EthernetTypeIPv4,
+			HwAddressSize:     uint8(6),
+			ProtAddressSize:   uint8(4),
+			Operation:         layers.ARPRequest,
+			SourceHwAddress:   scannerMAC,
+			SourceProtAddress: scannerIP,
+			DstHwAddress:      net.HardwareAddr{0x00, 0x00, 0x00, 0x00, 0x00, 0x00},
+			DstProtAddress:    dstIP.To4(),
+		})
+	require.NoError(t, err)
+	return buf.Bytes()
+}
+
+func tcpPacket(t *testing.T, dstIP net.IP, dstPort uint16, syn, ack bool) []byte {
+	t.Helper()
+	ip := &layers.IPv4{
+		Version:  4,
+		TTL:      64,
+		Protocol: layers.IPProtocolTCP,
+		SrcIP:    scannerIP,
+		DstIP:    dstIP.To4(),
+	}
+	tcp := &layers.TCP{
+		SrcPort: 40000,
+		DstPort: layers.TCPPort(dstPort),
+		Seq:     100,
+		SYN:     syn,
+		ACK:     ack,
+	}
+	require.NoError(t, tcp.SetNetworkLayerForChecksum(ip))
+	buf := gopacket.NewSerializeBuffer()
+	err := gopacket.SerializeLayers(buf, gopacket.SerializeOptions{FixLengths: true, ComputeChecksums: true},
+		&layers.Ethernet{
+			SrcMAC:       scannerMAC,
+			DstMAC:       responderMAC,
+			EthernetType: layers.EthernetTypeIPv4,
+		}, ip, tcp)
+	require.NoError(t, err)
+	return buf.Bytes()
+}
+
+func readReply(t *testing.T, r *Responder) gopacket.Packet {
+	t.Helper()
+	select {
+	case data := <-r.Packets(context.Background(), nil):
+		return gopacket.NewPacket(data.Buf.Bytes(), layers.LayerTypeEthernet, gopacket.Default)
+	case <-time.After(3 * time.Second):
+		require.FailNow(t, "reply timeout")
+	}
+	return nil
+}
+
+func readResult(t *testing.T, r *Responder) *Result {
+	t.Helper()
+	select {
+	case result := <-r.Results():
+		return result.(*Result)
+	case <-time.After(3 * time.Second):
+		require.FailNow(t, "result timeout")
+	}
+	return nil
+}
+
+func requireNoReply(t *testing.T, r *Responder) {
+	t.Helper()
+	select {
+	case <-r.Packets(context.Background(), nil):
+		require.FailNow(t, "unexpected reply")
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestResponderARP(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	r := newTestResponder(ctx, WithARP())
+
+	err := r.ProcessPacketData(arpRequest(t, net.IPv4(10, 0, 0, 5)), &gopacket.CaptureInfo{})
+	require.NoError(t, err)
+
+	reply := readReply(t, r)
+	eth := reply.Layer(layers.LayerTypeEthernet).(*layers.Ethernet)
+	require.Equal(t, responderMAC, eth.SrcMAC)
+	require.Equal(t, scannerMAC, eth.DstMAC)
+	arp := reply.Layer(layers.LayerTypeARP).(*layers.ARP)
+	require.Equal(t, uint16(layers.ARPReply), arp.Operation)
+	require.Equal(t, []byte(responderMAC), arp.SourceHwAddress)
+	require.Equal(t, []byte(net.IPv4(10, 0, 0, 5).To4()), arp.SourceProtAddress)
+	require.Equal(t, []byte(scannerMAC), arp.DstHwAddress)
+	require.Equal(t, []byte(scannerIP), arp.DstProtAddress)
+
+	require.Equal(t, &Result{Proto: ProtoARP, SrcIP: "192.168.0.3", DstIP: "10.0.0.5", Reply: ReplyARP},
+		readResult(t, r))
+}
+
+func TestResponderSYN(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		port     uint16
+		expected string
+	}{
+		{
+			name:     "OpenPort",
+			port:     22,
+			expected: ReplySYNACK,
+		},
+		{
+			name:     "ClosedPort",
+			port:     23,
+			expected: ReplyRST,
+		},
+	}
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			r := newTestResponder(ctx, WithSYN([]*scan.PortRange{{StartPort: 22, EndPort: 22}, {StartPort: 80, EndPort: 90}}))
+
+			err := r.ProcessPacketData(tcpPacket(t, net.IPv4(10, 0, 0, 5), tt.port, true, false), &gopacket.CaptureInfo{})
+			require.NoError(t, err)
+
+			reply := readReply(t, r)
+			eth := reply.Layer(layers.LayerTypeEthernet).(*layers.Ethernet)
+			require.Equal(t, responderMAC, eth.SrcMAC)
+			require.Equal(t, scannerMAC, eth.DstMAC)
+			ip := reply.Layer(layers.LayerTypeIPv4).(*layers.IPv4)
+			require.Equal(t, net.IPv4(10, 0, 0, 5).To4(), ip.SrcIP)
+			require.Equal(t, scannerIP, ip.DstIP)
+			tcp := reply.Layer(layers.LayerTypeTCP).(*layers.TCP)
+			require.Equal(t, layers.TCPPort(tt.port), tcp.SrcPort)
+			require.Equal(t, layers.TCPPort(40000), tcp.DstPort)
+			require.Equal(t, uint32(101), tcp.Ack)
+			require.True(t, tcp.ACK)
+			require.Equal(t, tt.expected == ReplySYNACK, tcp.SYN)
+			require.Equal(t, tt.expected == ReplyRST, tcp.RST)
+
+			require.Equal(t, &Result{Proto: ProtoTCP, SrcIP: "192.168.0.3", DstIP: "10.0.0.5",
+				DstPort: tt.port, Reply: tt.expected}, readResult(t, r))
+		})
+	}
+}
+
+func TestResponderIgnoresPackets(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		opts []ResponderOption
+		data func(t *testing.T) []byte
+	}{
+		{
+			name: "ARPOutOfSubnet",
+			opts: []ResponderOption{WithARP()},
+			data: func(t *testing.T) []byte {
+				return arpRequest(t, net.IPv4(10, 0, 1, 5))
+			},
+		},
+		{
+			name: "ARPDisabled",
+			opts: []ResponderOption{WithSYN(nil)},
+			data: func(t *testing.T) []byte {
+				return arpRequest(t, net.IPv4(10, 0, 0, 5))
+			},
+		},
+		{
+			name: "SYNOutOfSubnet",
+			opts: []ResponderOption{WithSYN(nil)},
+			data: func(t *testing.T) []byte {
+				return tcpPacket(t, net.IPv4(10, 0, 1, 5), 22, true, false)
+			},
+		},
+		{
+			name: "SYNDisabled",
+			opts: []ResponderOption{WithARP()},
+			data: func(t *testing.T) []byte {
+				return tcpPacket(t, net.IPv4(10, 0, 0, 5), 22, true, false)
+			},
+		},
+		{
+			name: "SYNACK",
+			opts: []ResponderOption{WithSYN(nil)},
+			data: func(t *testing.T) []byte {
+				return tcpPacket(t, net.IPv4(10, 0, 0, 5), 22, true, true)
+			},
+		},
+	}
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			r := newTestResponder(ctx, tt.opts...)
+
+			err := r.ProcessPacketData(tt.data(t), &gopacket.CaptureInfo{})
+			require.NoError(t, err)
+			requireNoReply(t, r)
+		})
+	}
+}
+
+func TestResponderBPFFilter(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		opts     []ResponderOption
+		expected string
+	}{
+		{
+			name:     "ARP",
+			opts:     []ResponderOption{WithARP()},
+			expected: "(arp dst net 10.0.0.0/24)",
+		},
+		{
+			name:     "SYN",
+			opts:     []ResponderOption{WithSYN(nil)},
+			expected: "(tcp and dst net 10.0.0.0/24 and tcp[13] & 0x16 == 0x02)",
+		},
+		{
+			name:     "ARPAndSYN",
+			opts:     []ResponderOption{WithARP(), WithSYN(nil)},
+			expected: "(arp dst net 10.0.0.0/24) or (tcp and dst net 10.0.0.0/24 and tcp[13] & 0x16 == 0x02)",
+		},
+	}
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			r := newTestResponder(context.Background(), tt.opts...)
+			filter, maxPacketLength := r.BPFFilter(nil)
+			require.Equal(t, tt.expected, filter)
+			require.Equal(t, MaxPacketLength, maxPacketLength)
+		})
+	}
+}
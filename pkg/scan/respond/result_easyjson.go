@@ -0,0 +1,113 @@
+// Code generated by easyjson for marshaling/unmarshaling. DO NOT EDIT.
+
+package respond
+
+import (
+	json "encoding/json"
+	easyjson "github.com/mailru/easyjson"
+	jlexer "github.com/mailru/easyjson/jlexer"
+	jwriter "github.com/mailru/easyjson/jwriter"
+)
+
+// suppress unused package warning
+var (
+	_ *json.RawMessage
+	_ *jlexer.Lexer
+	_ *jwriter.Writer
+	_ easyjson.Marshaler
+)
+
+func easyjsonD3b49167DecodeGithubComVByteCpuSxPkgScanRespond(in *jlexer.Lexer, out *Result) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "proto":
+			out.Proto = string(in.String())
+		case "srcip":
+			out.SrcIP = string(in.String())
+		case "dstip":
+			out.DstIP = string(in.String())
+		case "dstport":
+			out.DstPort = uint16(in.Uint16())
+		case "reply":
+			out.Reply = string(in.String())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonD3b49167EncodeGithubComVByteCpuSxPkgScanRespond(out *jwriter.Writer, in Result) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"proto\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.Proto))
+	}
+	{
+		const prefix string = ",\"srcip\":"
+		out.RawString(prefix)
+		out.String(string(in.SrcIP))
+	}
+	{
+		const prefix string = ",\"dstip\":"
+		out.RawString(prefix)
+		out.String(string(in.DstIP))
+	}
+	if in.DstPort != 0 {
+		const prefix string = ",\"dstport\":"
+		out.RawString(prefix)
+		out.Uint16(uint16(in.DstPort))
+	}
+	{
+		const prefix string = ",\"reply\":"
+		out.RawString(prefix)
+		out.String(string(in.Reply))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v Result) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonD3b49167EncodeGithubComVByteCpuSxPkgScanRespond(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v Result) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD3b49167EncodeGithubComVByteCpuSxPkgScanRespond(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *Result) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonD3b49167DecodeGithubComVByteCpuSxPkgScanRespond(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *Result) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD3b49167DecodeGithubComVByteCpuSxPkgScanRespond(l, v)
+}
@@ -1,9 +1,14 @@
 package scan
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 )
 
 type Result interface {
@@ -12,6 +17,125 @@ type Result interface {
 	ID() string
 }
 
+// MultiResult is a set of results produced by one scan request,
+// the scan engine emits each result separately
+type MultiResult []Result
+
+func (r MultiResult) String() string {
+	var buf strings.Builder
+	for i, result := range r {
+		if i > 0 {
+			buf.WriteByte('\n')
+		}
+		buf.WriteString(result.String())
+	}
+	return buf.String()
+}
+
+func (r MultiResult) ID() string {
+	ids := make([]string, 0, len(r))
+	for _, result := range r {
+		ids = append(ids, result.ID())
+	}
+	return strings.Join(ids, ",")
+}
+
+func (r MultiResult) MarshalJSON() ([]byte, error) {
+	return json.Marshal([]Result(r))
+}
+
+// MetaResult attaches metadata of the scan request, e.g. tags of the target host, to the scan result
+type MetaResult struct {
+	Result
+	Meta map[string]interface{}
+}
+
+// Unwrap returns the original scan result
+func (r *MetaResult) Unwrap() Result {
+	return r.Result
+}
+
+func (r *MetaResult) String() string {
+	var buf strings.Builder
+	buf.WriteString(r.Result.String())
+	keys := make([]string, 0, len(r.Meta))
+	for key := range r.Meta {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fmt.Fprintf(&buf, " %s=%v", key, r.Meta[key])
+	}
+	return buf.String()
+}
+
+// MarshalJSON adds metadata to the "meta" field of the JSON object of the original result
+func (r *MetaResult) MarshalJSON() ([]byte, error) {
+	if len(r.Meta) == 0 {
+		return marshalWithField(r.Result, "meta", nil)
+	}
+	return marshalWithField(r.Result, "meta", r.Meta)
+}
+
+// WindowResult attaches the start of the time window, in which the result was written, to the scan result
+type WindowResult struct {
+	Result
+	WindowStart time.Time
+}
+
+// Unwrap returns the original scan result
+func (r *WindowResult) Unwrap() Result {
+	return r.Result
+}
+
+func (r *WindowResult) String() string {
+	return fmt.Sprintf("%s window_start=%s", r.Result.String(), r.WindowStart.UTC().Format(time.RFC3339))
+}
+
+// MarshalJSON adds the window start to the "window_start" field of the JSON object of the original result
+func (r *WindowResult) MarshalJSON() ([]byte, error) {
+	return marshalWithField(r.Result, "window_start", r.WindowStart.UTC().Format(time.RFC3339))
+}
+
+// marshalWithField adds the field to the JSON object of the result,
+// the result is returned unchanged if it is not an object or the value is nil
+func marshalWithField(result Result, name string, value interface{}) ([]byte, error) {
+	data, err := result.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	data = bytes.TrimSpace(data)
+	if len(data) < 2 || data[0] != '{' || data[len(data)-1] != '}' || value == nil {
+		return data, nil
+	}
+	field, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	buf.Grow(len(data) + len(name) + len(field) + 4)
+	buf.Write(data[:len(data)-1])
+	if len(bytes.TrimSpace(data[1:len(data)-1])) > 0 {
+		buf.WriteByte(',')
+	}
+	buf.WriteString(strconv.Quote(name))
+	buf.WriteByte(':')
+	buf.Write(field)
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// UnwrapResult returns the original scan result without metadata wrappers
+func UnwrapResult(result Result) Result {
+	for {
+		wrapper, ok := result.(interface{ Unwrap() Result })
+		if !ok {
+			return result
+		}
+		result = wrapper.Unwrap()
+	}
+}
+
 type ResultChan interface {
 	Put(r Result)
 	Chan() <-chan Result
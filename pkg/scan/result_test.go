@@ -134,3 +134,97 @@ func TestResultChanWriteAfterCloseContext(t *testing.T) {
 		t.Fatal("test timeout")
 	}
 }
+
+type jsonResult struct {
+	data string
+}
+
+func (r *jsonResult) String() string {
+	return r.data
+}
+
+func (r *jsonResult) MarshalJSON() ([]byte, error) {
+	return []byte(r.data), nil
+}
+
+func (r *jsonResult) ID() string {
+	return r.data
+}
+
+func TestMetaResultMarshalJSON(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		result   Result
+		meta     map[string]interface{}
+		expected string
+	}{
+		{
+			name:     "Object",
+			result:   &jsonResult{`{"ip":"192.168.0.1"}`},
+			meta:     map[string]interface{}{"kind": "ec2", "tags": map[string]string{"env": "prod"}},
+			expected: `{"ip":"192.168.0.1","meta":{"kind":"ec2","tags":{"env":"prod"}}}`,
+		},
+		{
+			name:     "EmptyObject",
+			result:   &jsonResult{`{ }`},
+			meta:     map[string]interface{}{"kind": "ec2"},
+			expected: `{ "meta":{"kind":"ec2"}}`,
+		},
+		{
+			name:     "EmptyMeta",
+			result:   &jsonResult{`{"ip":"192.168.0.1"}`},
+			meta:     map[string]interface{}{},
+			expected: `{"ip":"192.168.0.1"}`,
+		},
+		{
+			name:     "NotObject",
+			result:   &jsonResult{`"192.168.0.1"`},
+			meta:     map[string]interface{}{"kind": "ec2"},
+			expected: `"192.168.0.1"`,
+		},
+	}
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			data, err := (&MetaResult{Result: tt.result, Meta: tt.meta}).MarshalJSON()
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, string(data))
+		})
+	}
+}
+
+func TestMetaResultString(t *testing.T) {
+	t.Parallel()
+	result := &MetaResult{
+		Result: newResult("192.168.0.1"),
+		Meta:   map[string]interface{}{"kind": "ec2", "id": "i-123"},
+	}
+	require.Equal(t, "192.168.0.1 id=i-123 kind=ec2", result.String())
+	require.Equal(t, "192.168.0.1", result.ID())
+}
+
+func TestWindowResult(t *testing.T) {
+	t.Parallel()
+	windowStart := time.Date(2021, 5, 1, 10, 5, 0, 0, time.UTC)
+	result := &WindowResult{Result: &jsonResult{`{"ip":"192.168.0.1"}`}, WindowStart: windowStart}
+
+	data, err := result.MarshalJSON()
+	require.NoError(t, err)
+	require.Equal(t, `{"ip":"192.168.0.1","window_start":"2021-05-01T10:05:00Z"}`, string(data))
+
+	result = &WindowResult{Result: newResult("192.168.0.1"), WindowStart: windowStart.In(time.FixedZone("UTC+3", 3*3600))}
+	require.Equal(t, "192.168.0.1 window_start=2021-05-01T10:05:00Z", result.String())
+	require.Equal(t, "192.168.0.1", result.ID())
+}
+
+func TestUnwrapResult(t *testing.T) {
+	t.Parallel()
+	original := newResult("data")
+	require.Equal(t, original, UnwrapResult(original))
+	require.Equal(t, original, UnwrapResult(&MetaResult{Result: original}))
+	require.Equal(t, original, UnwrapResult(&MetaResult{Result: &MetaResult{Result: original}}))
+	require.Equal(t, original, UnwrapResult(&WindowResult{Result: &MetaResult{Result: original}}))
+}
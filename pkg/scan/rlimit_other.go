@@ -0,0 +1,15 @@
+//go:build !linux && !darwin
+
+package scan
+
+import "errors"
+
+// OpenFileLimit returns zero since the limit of open file descriptors is not available on the platform
+func OpenFileLimit() (uint64, error) {
+	return 0, nil
+}
+
+// RaiseOpenFileLimit is not supported on the platform
+func RaiseOpenFileLimit(uint64) error {
+	return errors.New("open file limit is not supported")
+}
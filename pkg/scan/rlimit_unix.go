@@ -0,0 +1,29 @@
+//go:build linux || darwin
+
+package scan
+
+import "syscall"
+
+// OpenFileLimit returns the soft limit of open file descriptors of the process (RLIMIT_NOFILE),
+// the unlimited value is reported as a very large number
+func OpenFileLimit() (uint64, error) {
+	var rlimit syscall.Rlimit
+	if err := syscall.Getrlimit(syscall.RLIMIT_NOFILE, &rlimit); err != nil {
+		return 0, err
+	}
+	return uint64(rlimit.Cur), nil
+}
+
+// RaiseOpenFileLimit raises the soft limit of open file descriptors of the process, the hard limit is raised
+// as well if it is lower, which requires the CAP_SYS_RESOURCE capability
+func RaiseOpenFileLimit(limit uint64) error {
+	var rlimit syscall.Rlimit
+	if err := syscall.Getrlimit(syscall.RLIMIT_NOFILE, &rlimit); err != nil {
+		return err
+	}
+	rlimit.Cur = limit
+	if rlimit.Max < limit {
+		rlimit.Max = limit
+	}
+	return syscall.Setrlimit(syscall.RLIMIT_NOFILE, &rlimit)
+}
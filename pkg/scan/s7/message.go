@@ -0,0 +1,249 @@
+package s7
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+// ISO transport over TCP (RFC 1006) and ISO 8073 COTP, see RFC 1006 section 6
+const (
+	tpktVersion    = 0x03
+	tpktHeaderSize = 4
+	maxTPKTSize    = 4096
+
+	cotpConnectionRequest = 0xe0
+	cotpConnectionConfirm = 0xd0
+	cotpData              = 0xf0
+	cotpLastDataUnit      = 0x80
+
+	cotpParamTPDUSize = 0xc0
+	cotpParamSrcTSAP  = 0xc1
+	cotpParamDstTSAP  = 0xc2
+	// tpduSize1024 is the TPDU size 2^10
+	tpduSize1024 = 0x0a
+	// srcTSAP is the TSAP of the programming device
+	srcTSAP = 0x0100
+)
+
+// S7 communication header and functions
+const (
+	s7ProtocolID = 0x32
+
+	rosctrJob      = 0x01
+	rosctrAck      = 0x02
+	rosctrAckData  = 0x03
+	rosctrUserData = 0x07
+
+	funcSetupCommunication = 0xf0
+	// pduSize is the PDU size proposed in Setup Communication
+	pduSize = 480
+
+	// userdata parameters of the Read SZL request of CPU functions
+	userDataRequest = 0x11
+	cpuFunctions    = 0x44
+	subfuncReadSZL  = 0x01
+
+	returnCodeSuccess = 0xff
+	transportOctets   = 0x09
+)
+
+// System status lists (SZL) and their element indexes
+const (
+	// szlModuleID is the module identification list, its elements are order numbers and versions
+	szlModuleID      = 0x0011
+	moduleIDModule   = 0x0001
+	moduleIDFirmware = 0x0007
+
+	// szlComponentID is the component identification list, its elements are names of the station and the module
+	szlComponentID          = 0x001c
+	componentSystemName     = 0x0001
+	componentPlantID        = 0x0003
+	componentSerial         = 0x0005
+	componentModuleTypeName = 0x0007
+)
+
+var (
+	errInvalidMessage = scan.NewError(scan.ErrParse, errors.New("invalid S7 message"))
+	errNotConfirmed   = errors.New("COTP connection is not confirmed")
+	errSZLFailed      = errors.New("S7 SZL read failed")
+)
+
+// tpkt returns the TPKT packet of the payload
+func tpkt(payload []byte) []byte {
+	packet := []byte{tpktVersion, 0}
+	packet = binary.BigEndian.AppendUint16(packet, uint16(tpktHeaderSize+len(payload)))
+	return append(packet, payload...)
+}
+
+// readTPKT reads the TPKT packet and returns its payload
+func readTPKT(r io.Reader) ([]byte, error) {
+	header := make([]byte, tpktHeaderSize)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, err
+	}
+	if header[0] != tpktVersion {
+		return nil, fmt.Errorf("%w: unknown TPKT version", errInvalidMessage)
+	}
+	length := int(binary.BigEndian.Uint16(header[2:4]))
+	if length <= tpktHeaderSize || length > maxTPKTSize {
+		return nil, fmt.Errorf("%w: invalid TPKT length %d", errInvalidMessage, length)
+	}
+	payload := make([]byte, length-tpktHeaderSize)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return nil, err
+	}
+	return payload, nil
+}
+
+// connectionRequest returns the COTP Connection Request to the TSAP of the CPU
+func connectionRequest(dstTSAP uint16) []byte {
+	// PDU type, destination and source references, class 0
+	cotp := []byte{0, cotpConnectionRequest, 0, 0, 0, 1, 0}
+	cotp = append(cotp, cotpParamTPDUSize, 1, tpduSize1024)
+	cotp = append(cotp, cotpParamSrcTSAP, 2)
+	cotp = binary.BigEndian.AppendUint16(cotp, srcTSAP)
+	cotp = append(cotp, cotpParamDstTSAP, 2)
+	cotp = binary.BigEndian.AppendUint16(cotp, dstTSAP)
+	// the length indicator doesn't include itself
+	cotp[0] = byte(len(cotp) - 1)
+	return tpkt(cotp)
+}
+
+// parseConnectionConfirm checks that the payload is the COTP Connection Confirm
+func parseConnectionConfirm(payload []byte) error {
+	if len(payload) < 2 || int(payload[0]) >= len(payload) {
+		return fmt.Errorf("%w: short COTP header", errInvalidMessage)
+	}
+	if payload[1] != cotpConnectionConfirm {
+		return errNotConfirmed
+	}
+	return nil
+}
+
+// s7Packet returns the S7 message in the COTP Data TPDU
+func s7Packet(rosctr byte, params, data []byte) []byte {
+	packet := []byte{2, cotpData, cotpLastDataUnit, s7ProtocolID, rosctr, 0, 0, 0, 0}
+	packet = binary.BigEndian.AppendUint16(packet, uint16(len(params)))
+	packet = binary.BigEndian.AppendUint16(packet, uint16(len(data)))
+	packet = append(packet, params...)
+	return tpkt(append(packet, data...))
+}
+
+// setupCommunicationRequest returns the Setup Communication job with one parallel job and the proposed PDU size
+func setupCommunicationRequest() []byte {
+	params := []byte{funcSetupCommunication, 0, 0, 1, 0, 1}
+	params = binary.BigEndian.AppendUint16(params, pduSize)
+	return s7Packet(rosctrJob, params, nil)
+}
+
+// readSZLRequest returns the userdata request to read the system status list
+func readSZLRequest(id, index uint16) []byte {
+	params := []byte{0, 1, 0x12, 4, userDataRequest, cpuFunctions, subfuncReadSZL, 0}
+	data := []byte{returnCodeSuccess, transportOctets, 0, 4}
+	data = binary.BigEndian.AppendUint16(data, id)
+	data = binary.BigEndian.AppendUint16(data, index)
+	return s7Packet(rosctrUserData, params, data)
+}
+
+type s7Message struct {
+	rosctr byte
+	// errorCode is the error class and code of acknowledgements
+	errorCode uint16
+	params    []byte
+	data      []byte
+}
+
+// parseS7Message parses the S7 message of the COTP Data TPDU payload
+func parseS7Message(payload []byte) (*s7Message, error) {
+	if len(payload) < 2 || payload[1] != cotpData || int(payload[0]) >= len(payload) {
+		return nil, fmt.Errorf("%w: not COTP data", errInvalidMessage)
+	}
+	msg := payload[payload[0]+1:]
+	if len(msg) < 10 || msg[0] != s7ProtocolID {
+		return nil, fmt.Errorf("%w: unknown protocol id", errInvalidMessage)
+	}
+	result := &s7Message{rosctr: msg[1]}
+	paramLen := int(binary.BigEndian.Uint16(msg[6:8]))
+	dataLen := int(binary.BigEndian.Uint16(msg[8:10]))
+	headerSize := 10
+	if result.rosctr == rosctrAck || result.rosctr == rosctrAckData {
+		headerSize = 12
+		if len(msg) < headerSize {
+			return nil, fmt.Errorf("%w: short header", errInvalidMessage)
+		}
+		result.errorCode = binary.BigEndian.Uint16(msg[10:12])
+	}
+	if len(msg) < headerSize+paramLen+dataLen {
+		return nil, fmt.Errorf("%w: truncated message", errInvalidMessage)
+	}
+	result.params = msg[headerSize : headerSize+paramLen]
+	result.data = msg[headerSize+paramLen : headerSize+paramLen+dataLen]
+	return result, nil
+}
+
+// parseSetupCommunication checks that the message acknowledges Setup Communication
+func parseSetupCommunication(msg *s7Message) error {
+	if msg.rosctr != rosctrAckData || len(msg.params) < 1 || msg.params[0] != funcSetupCommunication {
+		return fmt.Errorf("%w: not Setup Communication acknowledgement", errInvalidMessage)
+	}
+	if msg.errorCode != 0 {
+		return fmt.Errorf("%w: Setup Communication error %#04x", errInvalidMessage, msg.errorCode)
+	}
+	return nil
+}
+
+// szlElements returns elements of the system status list of the Read SZL response by their indexes,
+// the index is the first word of each element
+func szlElements(msg *s7Message, id uint16) (map[uint16][]byte, error) {
+	if msg.rosctr != rosctrUserData {
+		return nil, fmt.Errorf("%w: not userdata", errInvalidMessage)
+	}
+	data := msg.data
+	if len(data) < 4 {
+		return nil, fmt.Errorf("%w: short SZL response", errInvalidMessage)
+	}
+	if data[0] != returnCodeSuccess {
+		return nil, fmt.Errorf("%w: return code %#02x", errSZLFailed, data[0])
+	}
+	// return code, transport size, data length, SZL id, index, element length and count
+	if len(data) < 12 || binary.BigEndian.Uint16(data[4:6]) != id {
+		return nil, fmt.Errorf("%w: unexpected SZL", errInvalidMessage)
+	}
+	elemLen := int(binary.BigEndian.Uint16(data[8:10]))
+	count := int(binary.BigEndian.Uint16(data[10:12]))
+	if elemLen < 2 {
+		return nil, fmt.Errorf("%w: invalid SZL element length %d", errInvalidMessage, elemLen)
+	}
+	elements := make(map[uint16][]byte)
+	data = data[12:]
+	// responses split into several data units contain the first elements only
+	for i := 0; i < count && len(data) >= elemLen; i++ {
+		elements[binary.BigEndian.Uint16(data[0:2])] = data[2:elemLen]
+		data = data[elemLen:]
+	}
+	return elements, nil
+}
+
+// szlString returns the text of the element without padding
+func szlString(elem []byte) string {
+	if end := bytes.IndexByte(elem, 0); end != -1 {
+		elem = elem[:end]
+	}
+	return string(bytes.TrimSpace(elem))
+}
+
+// moduleVersion returns the version of the module identification element,
+// the 20-byte order number and the module type are followed by the version in two words, e.g. 'V' 3 2 6
+func moduleVersion(elem []byte) string {
+	const size = 20 + 2 + 4
+	if len(elem) < size {
+		return ""
+	}
+	version := elem[size-4:]
+	return fmt.Sprintf("%d.%d.%d", version[1], version[2], version[3])
+}
@@ -0,0 +1,142 @@
+package s7
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func mustDecodeHex(t *testing.T, s string) []byte {
+	t.Helper()
+	data, err := hex.DecodeString(s)
+	require.NoError(t, err)
+	return data
+}
+
+// s7Response returns the TPKT payload of the S7 response, error codes are set for acknowledgements only
+func s7Response(rosctr byte, errorCode uint16, params, data []byte) []byte {
+	packet := s7Packet(rosctr, params, data)[tpktHeaderSize:]
+	if rosctr != rosctrAck && rosctr != rosctrAckData {
+		return packet
+	}
+	// the error class and code follow the data length of the header
+	const headerEnd = 3 + 10
+	result := append([]byte{}, packet[:headerEnd]...)
+	result = binary.BigEndian.AppendUint16(result, errorCode)
+	return append(result, packet[headerEnd:]...)
+}
+
+func setupCommunicationResponse(errorCode uint16) []byte {
+	return s7Response(rosctrAckData, errorCode, []byte{funcSetupCommunication, 0, 0, 1, 0, 1, 0x00, 0xf0}, nil)
+}
+
+// szlElement returns the SZL element of the index with the padded text and the version words
+func szlElement(index uint16, text string, size int, version []byte) []byte {
+	elem := binary.BigEndian.AppendUint16(nil, index)
+	elem = append(elem, []byte(text)...)
+	elem = append(elem, bytes.Repeat([]byte{' '}, size-len(elem)-len(version))...)
+	return append(elem, version...)
+}
+
+// szlResponse returns the userdata response with elements of the system status list
+func szlResponse(id uint16, elemLen int, elements ...[]byte) []byte {
+	params := []byte{0, 1, 0x12, 8, 0x12, 0x84, subfuncReadSZL, 1, 0, 0, 0, 0}
+	szl := binary.BigEndian.AppendUint16(nil, id)
+	szl = binary.BigEndian.AppendUint16(szl, 0)
+	szl = binary.BigEndian.AppendUint16(szl, uint16(elemLen))
+	szl = binary.BigEndian.AppendUint16(szl, uint16(len(elements)))
+	for _, elem := range elements {
+		szl = append(szl, elem...)
+	}
+	data := []byte{returnCodeSuccess, transportOctets}
+	data = binary.BigEndian.AppendUint16(data, uint16(len(szl)))
+	return s7Response(rosctrUserData, 0, params, append(data, szl...))
+}
+
+func TestConnectionRequest(t *testing.T) {
+	t.Parallel()
+	require.Equal(t, mustDecodeHex(t, "0300001611e00000000100c0010ac1020100c2020102"), connectionRequest(0x0102))
+}
+
+func TestSetupCommunicationRequest(t *testing.T) {
+	t.Parallel()
+	require.Equal(t, mustDecodeHex(t, "0300001902f08032010000000000080000f0000001000101e0"), setupCommunicationRequest())
+}
+
+func TestReadSZLRequest(t *testing.T) {
+	t.Parallel()
+	require.Equal(t, mustDecodeHex(t, "0300002102f080320700000000000800080001120411440100ff09000400110001"),
+		readSZLRequest(szlModuleID, moduleIDModule))
+}
+
+func TestReadTPKT(t *testing.T) {
+	t.Parallel()
+	payload, err := readTPKT(bytes.NewReader(tpkt([]byte{1, 2, 3})))
+	require.NoError(t, err)
+	require.Equal(t, []byte{1, 2, 3}, payload)
+
+	_, err = readTPKT(bytes.NewReader([]byte{0x16, 0x03, 0x01, 0x00, 0x10}))
+	require.ErrorIs(t, err, errInvalidMessage)
+
+	_, err = readTPKT(bytes.NewReader([]byte{tpktVersion, 0, 0, 2}))
+	require.ErrorIs(t, err, errInvalidMessage)
+}
+
+func TestParseConnectionConfirm(t *testing.T) {
+	t.Parallel()
+	require.NoError(t, parseConnectionConfirm(mustDecodeHex(t, "11d00001000100c0010ac1020100c2020102")))
+	require.ErrorIs(t, parseConnectionConfirm(mustDecodeHex(t, "0680000100010000")), errNotConfirmed)
+	require.ErrorIs(t, parseConnectionConfirm([]byte{0x11}), errInvalidMessage)
+}
+
+func TestParseSetupCommunication(t *testing.T) {
+	t.Parallel()
+	msg, err := parseS7Message(setupCommunicationResponse(0))
+	require.NoError(t, err)
+	require.NoError(t, parseSetupCommunication(msg))
+
+	msg, err = parseS7Message(setupCommunicationResponse(0x8104))
+	require.NoError(t, err)
+	require.ErrorIs(t, parseSetupCommunication(msg), errInvalidMessage)
+
+	_, err = parseS7Message([]byte{2, cotpData, cotpLastDataUnit, 0x72, 1})
+	require.ErrorIs(t, err, errInvalidMessage)
+}
+
+func TestSZLElements(t *testing.T) {
+	t.Parallel()
+	payload := szlResponse(szlModuleID, 28,
+		szlElement(moduleIDModule, "6ES7 315-2EH14-0AB0 ", 28, []byte{0, 0xc0, 0, 4}),
+		szlElement(moduleIDFirmware, "", 28, []byte{'V', 3, 2, 6}))
+	msg, err := parseS7Message(payload)
+	require.NoError(t, err)
+
+	elements, err := szlElements(msg, szlModuleID)
+	require.NoError(t, err)
+	require.Len(t, elements, 2)
+	require.Equal(t, "6ES7 315-2EH14-0AB0", szlString(elements[moduleIDModule]))
+	require.Equal(t, "3.2.6", moduleVersion(elements[moduleIDFirmware]))
+	require.Empty(t, moduleVersion(elements[0x0006]))
+
+	_, err = szlElements(msg, szlComponentID)
+	require.ErrorIs(t, err, errInvalidMessage)
+}
+
+func TestSZLElementsFailed(t *testing.T) {
+	t.Parallel()
+	params := []byte{0, 1, 0x12, 8, 0x12, 0x84, subfuncReadSZL, 1, 0, 0, 0xd4, 0x01}
+	msg, err := parseS7Message(s7Response(rosctrUserData, 0, params, []byte{0x0a, 0, 0, 0}))
+	require.NoError(t, err)
+
+	_, err = szlElements(msg, szlModuleID)
+	require.ErrorIs(t, err, errSZLFailed)
+}
+
+func TestSZLString(t *testing.T) {
+	t.Parallel()
+	require.Equal(t, "S C-C2UR28922012", szlString([]byte("S C-C2UR28922012\x00\x00\x00")))
+	require.Equal(t, "CPU 315-2 PN/DP", szlString([]byte("CPU 315-2 PN/DP   ")))
+}
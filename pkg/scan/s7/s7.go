@@ -0,0 +1,207 @@
+// Package s7 identifies Siemens PLCs with S7 communication over ISO-on-TCP
+package s7
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+const (
+	ScanType = "s7"
+
+	defaultDialTimeout = 2 * time.Second
+	defaultDataTimeout = 2 * time.Second
+)
+
+// dstTSAPs are TSAPs of the CPU tried in order: rack 0 slot 2 of S7-300/400
+// and the TSAP of S7-1200/1500 and other CPUs that don't accept the rack and slot
+var dstTSAPs = []uint16{0x0102, 0x0200}
+
+type ScanResult struct {
+	ScanType string `json:"scan"`
+	IP       string `json:"ip"`
+	Port     uint16 `json:"port"`
+	// ModuleType is the type name of the CPU, e.g. CPU 315-2 PN/DP
+	ModuleType string `json:"module_type,omitempty"`
+	// Module is the order number of the CPU, e.g. 6ES7 315-2EH14-0AB0
+	Module     string `json:"module,omitempty"`
+	Serial     string `json:"serial,omitempty"`
+	Firmware   string `json:"firmware,omitempty"`
+	SystemName string `json:"system_name,omitempty"`
+	PlantID    string `json:"plant_id,omitempty"`
+}
+
+func (r *ScanResult) String() string {
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "%-20s %-5d %q %q", r.IP, r.Port, r.ModuleType, r.Module)
+	if len(r.Serial) > 0 {
+		fmt.Fprintf(&buf, " serial %q", r.Serial)
+	}
+	if len(r.Firmware) > 0 {
+		fmt.Fprintf(&buf, " firmware v%s", r.Firmware)
+	}
+	if len(r.SystemName) > 0 {
+		fmt.Fprintf(&buf, " system %q", r.SystemName)
+	}
+	return buf.String()
+}
+
+func (r *ScanResult) ID() string {
+	return fmt.Sprintf("%s:%d", r.IP, r.Port)
+}
+
+func (r *ScanResult) MarshalJSON() ([]byte, error) {
+	// Type definition for the recursive call
+	type JScanResult ScanResult
+	// This works because JScanResult doesn't have a MarshalJSON function associated with it
+	return json.Marshal(JScanResult(*r))
+}
+
+// Scanner connects to the CPU with COTP, sets up S7 communication and reads
+// the module and component identification system status lists. Only read requests are sent.
+type Scanner struct {
+	dialer      *net.Dialer
+	dataTimeout time.Duration
+}
+
+// Assert that s7.Scanner conforms to the scan.Scanner interface
+var _ scan.Scanner = (*Scanner)(nil)
+
+type ScannerOption func(*Scanner)
+
+func WithDialTimeout(timeout time.Duration) ScannerOption {
+	return func(s *Scanner) {
+		s.dialer.Timeout = timeout
+	}
+}
+
+func WithDataTimeout(timeout time.Duration) ScannerOption {
+	return func(s *Scanner) {
+		s.dataTimeout = timeout
+	}
+}
+
+func NewScanner(opts ...ScannerOption) *Scanner {
+	s := &Scanner{
+		dialer: &net.Dialer{
+			Timeout: defaultDialTimeout,
+		},
+		dataTimeout: defaultDataTimeout,
+	}
+	for _, o := range opts {
+		o(s)
+	}
+	return s
+}
+
+func (s *Scanner) Scan(ctx context.Context, r *scan.Request) (scan.Result, error) {
+	result, err := s.scanTarget(ctx, r)
+	return result, scan.WrapError(err)
+}
+
+func (s *Scanner) scanTarget(ctx context.Context, r *scan.Request) (scan.Result, error) {
+	addr := net.JoinHostPort(r.DstIP.String(), strconv.Itoa(int(r.DstPort)))
+	conn, err := s.connect(ctx, addr)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if err = conn.SetDeadline(time.Now().Add(s.dataTimeout)); err != nil {
+		return nil, err
+	}
+	if _, err = conn.Write(setupCommunicationRequest()); err != nil {
+		return nil, err
+	}
+	msg, err := readS7Message(conn)
+	if err != nil {
+		return nil, err
+	}
+	if err = parseSetupCommunication(msg); err != nil {
+		return nil, err
+	}
+
+	res := &ScanResult{
+		ScanType: ScanType,
+		IP:       r.DstIP.String(),
+		Port:     r.DstPort,
+	}
+	// CPUs that don't have the list answer with the error, the other list is still read
+	modules, err := s.readSZL(conn, szlModuleID, moduleIDModule)
+	if err != nil && !errors.Is(err, errSZLFailed) {
+		return nil, err
+	}
+	res.Module = szlString(modules[moduleIDModule])
+	res.Firmware = moduleVersion(modules[moduleIDFirmware])
+
+	components, err := s.readSZL(conn, szlComponentID, componentSystemName)
+	if err != nil && !errors.Is(err, errSZLFailed) {
+		return nil, err
+	}
+	res.ModuleType = szlString(components[componentModuleTypeName])
+	res.Serial = szlString(components[componentSerial])
+	res.SystemName = szlString(components[componentSystemName])
+	res.PlantID = szlString(components[componentPlantID])
+	return res, nil
+}
+
+// connect sends the COTP Connection Request with each TSAP of the CPU until the connection is confirmed,
+// CPUs close connections to unknown TSAPs, so each TSAP is tried over the new connection
+func (s *Scanner) connect(ctx context.Context, addr string) (conn net.Conn, err error) {
+	for _, tsap := range dstTSAPs {
+		if conn, err = s.dialer.DialContext(ctx, "tcp", addr); err != nil {
+			return
+		}
+		if err = s.confirmConnection(conn, tsap); err == nil {
+			return
+		}
+		conn.Close()
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+	}
+	return nil, err
+}
+
+func (s *Scanner) confirmConnection(conn net.Conn, tsap uint16) error {
+	if err := conn.SetDeadline(time.Now().Add(s.dataTimeout)); err != nil {
+		return err
+	}
+	if _, err := conn.Write(connectionRequest(tsap)); err != nil {
+		return err
+	}
+	payload, err := readTPKT(conn)
+	if err != nil {
+		return err
+	}
+	return parseConnectionConfirm(payload)
+}
+
+// readSZL reads elements of the system status list, the index is ignored by
+// the module and component identification lists, all their elements are returned
+func (s *Scanner) readSZL(conn net.Conn, id, index uint16) (map[uint16][]byte, error) {
+	if _, err := conn.Write(readSZLRequest(id, index)); err != nil {
+		return nil, err
+	}
+	msg, err := readS7Message(conn)
+	if err != nil {
+		return nil, err
+	}
+	return szlElements(msg, id)
+}
+
+func readS7Message(conn net.Conn) (*s7Message, error) {
+	payload, err := readTPKT(conn)
+	if err != nil {
+		return nil, err
+	}
+	return parseS7Message(payload)
+}
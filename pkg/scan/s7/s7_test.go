@@ -0,0 +1,144 @@
+package s7
+
+import (
+	"context"
+	"encoding/binary"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+type fakePLC struct {
+	// tsap is the only TSAP the PLC confirms connections to, connections to other TSAPs are closed
+	tsap uint16
+	// lists are responses to Read SZL requests by SZL ids, other lists are answered with the error
+	lists map[uint16][]byte
+}
+
+func startFakePLC(t *testing.T, plc *fakePLC) *scan.Request {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go plc.serve(conn)
+		}
+	}()
+	addr := ln.Addr().(*net.TCPAddr)
+	return &scan.Request{DstIP: addr.IP, DstPort: uint16(addr.Port)}
+}
+
+func (p *fakePLC) serve(conn net.Conn) {
+	defer conn.Close()
+	payload, err := readTPKT(conn)
+	if err != nil || len(payload) < 18 || payload[1] != cotpConnectionRequest ||
+		binary.BigEndian.Uint16(payload[16:18]) != p.tsap {
+		return
+	}
+	_, _ = conn.Write(tpkt([]byte{6, cotpConnectionConfirm, 0, 1, 0, 1, 0}))
+	for {
+		if payload, err = readTPKT(conn); err != nil {
+			return
+		}
+		msg, err := parseS7Message(payload)
+		if err != nil {
+			return
+		}
+		switch {
+		case msg.rosctr == rosctrJob:
+			_, _ = conn.Write(tpkt(setupCommunicationResponse(0)))
+		case msg.rosctr == rosctrUserData && len(msg.data) >= 8:
+			response, ok := p.lists[binary.BigEndian.Uint16(msg.data[4:6])]
+			if !ok {
+				params := []byte{0, 1, 0x12, 8, 0x12, 0x84, subfuncReadSZL, 1, 0, 0, 0xd4, 0x01}
+				response = s7Response(rosctrUserData, 0, params, []byte{0x0a, 0, 0, 0})
+			}
+			_, _ = conn.Write(tpkt(response))
+		default:
+			return
+		}
+	}
+}
+
+var (
+	moduleList = szlResponse(szlModuleID, 28,
+		szlElement(moduleIDModule, "6ES7 315-2EH14-0AB0 ", 28, []byte{0, 0xc0, 0, 4}),
+		szlElement(0x0006, "6ES7 315-2EH14-0AB0 ", 28, []byte{0, 0xc0, 0, 4}),
+		szlElement(moduleIDFirmware, "", 28, []byte{'V', 3, 2, 6}))
+	componentList = szlResponse(szlComponentID, 34,
+		szlElement(componentSystemName, "SIMATIC 300(1)", 34, nil),
+		szlElement(0x0002, "CPU 315-2 PN/DP", 34, nil),
+		szlElement(componentPlantID, "Line 4", 34, nil),
+		szlElement(componentSerial, "S C-C2UR28922012", 34, nil),
+		szlElement(componentModuleTypeName, "CPU 315-2 PN/DP", 34, nil))
+)
+
+func TestScan(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		plc      *fakePLC
+		expected *ScanResult
+	}{
+		{
+			name: "RackSlot",
+			plc:  &fakePLC{tsap: 0x0102, lists: map[uint16][]byte{szlModuleID: moduleList, szlComponentID: componentList}},
+			expected: &ScanResult{ModuleType: "CPU 315-2 PN/DP", Module: "6ES7 315-2EH14-0AB0",
+				Serial: "S C-C2UR28922012", Firmware: "3.2.6", SystemName: "SIMATIC 300(1)", PlantID: "Line 4"},
+		},
+		{
+			name: "AlternativeTSAP",
+			plc:  &fakePLC{tsap: 0x0200, lists: map[uint16][]byte{szlModuleID: moduleList, szlComponentID: componentList}},
+			expected: &ScanResult{ModuleType: "CPU 315-2 PN/DP", Module: "6ES7 315-2EH14-0AB0",
+				Serial: "S C-C2UR28922012", Firmware: "3.2.6", SystemName: "SIMATIC 300(1)", PlantID: "Line 4"},
+		},
+		{
+			name:     "NoComponentList",
+			plc:      &fakePLC{tsap: 0x0102, lists: map[uint16][]byte{szlModuleID: moduleList}},
+			expected: &ScanResult{Module: "6ES7 315-2EH14-0AB0", Firmware: "3.2.6"},
+		},
+		{
+			name:     "NoLists",
+			plc:      &fakePLC{tsap: 0x0102},
+			expected: &ScanResult{},
+		},
+	}
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			req := startFakePLC(t, tt.plc)
+			result, err := NewScanner(WithDataTimeout(time.Second)).Scan(context.Background(), req)
+			require.NoError(t, err)
+
+			expected := tt.expected
+			expected.ScanType = ScanType
+			expected.IP = req.DstIP.String()
+			expected.Port = req.DstPort
+			require.Equal(t, expected, result)
+		})
+	}
+}
+
+func TestScanNotS7(t *testing.T) {
+	t.Parallel()
+	req := startFakePLC(t, &fakePLC{tsap: 0x0301})
+	_, err := NewScanner(WithDataTimeout(time.Second)).Scan(context.Background(), req)
+	require.Error(t, err)
+}
+
+func TestScanResultString(t *testing.T) {
+	t.Parallel()
+	result := &ScanResult{ScanType: ScanType, IP: "192.168.0.1", Port: 102, ModuleType: "CPU 315-2 PN/DP",
+		Module: "6ES7 315-2EH14-0AB0", Serial: "S C-C2UR28922012", Firmware: "3.2.6"}
+	require.Equal(t, `192.168.0.1          102   "CPU 315-2 PN/DP" "6ES7 315-2EH14-0AB0" serial "S C-C2UR28922012" firmware v3.2.6`,
+		result.String())
+}
@@ -0,0 +1,118 @@
+package scan
+
+import "context"
+
+type hostQueue struct {
+	requests []*Request
+	inflight int
+	ready    bool
+}
+
+// hostScheduler limits the number of concurrent requests to one host,
+// requests of different hosts are dispatched in round-robin order,
+// so a host with many requests doesn't delay scanning of other hosts
+type hostScheduler struct {
+	limit int
+	// maxPending limits the number of requests read ahead from the request generator
+	maxPending int
+	released   chan *Request
+}
+
+func newHostScheduler(limit, maxPending int) *hostScheduler {
+	return &hostScheduler{
+		limit:      limit,
+		maxPending: maxPending,
+		released:   make(chan *Request),
+	}
+}
+
+// hostKey returns the target host of the request, empty key is returned for
+// requests without target, e.g. requests with errors, they are not limited
+func hostKey(r *Request) string {
+	if r.DstIP != nil {
+		return r.DstIP.String()
+	}
+	return r.DstName
+}
+
+// schedule reorders requests of the input channel to meet the host concurrency limit,
+// every request of the output channel must be released after the scan
+func (s *hostScheduler) schedule(ctx context.Context, in <-chan *Request) <-chan *Request {
+	out := make(chan *Request)
+	go func() {
+		defer close(out)
+		hosts := make(map[string]*hostQueue)
+		// ready is a round-robin queue of hosts that have pending requests and free slots
+		var ready []string
+		var pending, inflight int
+
+		enqueue := func(key string, q *hostQueue) {
+			if q.ready || len(q.requests) == 0 || (len(key) > 0 && q.inflight >= s.limit) {
+				return
+			}
+			q.ready = true
+			ready = append(ready, key)
+		}
+
+		for in != nil || pending > 0 || inflight > 0 {
+			var outc chan<- *Request
+			var next *Request
+			if len(ready) > 0 {
+				outc = out
+				next = hosts[ready[0]].requests[0]
+			}
+			inc := in
+			if pending >= s.maxPending {
+				inc = nil
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case r, ok := <-inc:
+				if !ok {
+					in = nil
+					continue
+				}
+				key := hostKey(r)
+				q, ok := hosts[key]
+				if !ok {
+					q = &hostQueue{}
+					hosts[key] = q
+				}
+				q.requests = append(q.requests, r)
+				pending++
+				enqueue(key, q)
+			case outc <- next:
+				key := ready[0]
+				ready = ready[1:]
+				q := hosts[key]
+				q.ready = false
+				q.requests = q.requests[1:]
+				q.inflight++
+				pending--
+				inflight++
+				enqueue(key, q)
+			case r := <-s.released:
+				key := hostKey(r)
+				q := hosts[key]
+				q.inflight--
+				inflight--
+				if q.inflight == 0 && len(q.requests) == 0 {
+					delete(hosts, key)
+					continue
+				}
+				enqueue(key, q)
+			}
+		}
+	}()
+	return out
+}
+
+// release frees the host slot of the scanned request
+func (s *hostScheduler) release(ctx context.Context, r *Request) {
+	select {
+	case <-ctx.Done():
+	case s.released <- r:
+	}
+}
@@ -0,0 +1,163 @@
+package scan
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func newRequestChan(requests ...*Request) <-chan *Request {
+	out := make(chan *Request, len(requests))
+	for _, r := range requests {
+		out <- r
+	}
+	close(out)
+	return out
+}
+
+func readScheduledRequest(t *testing.T, requests <-chan *Request) *Request {
+	t.Helper()
+	select {
+	case r, ok := <-requests:
+		require.True(t, ok, "requests channel is closed")
+		return r
+	case <-time.After(waitTimeout):
+		require.Fail(t, "test timeout")
+	}
+	return nil
+}
+
+func TestHostSchedulerRoundRobin(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	reqA1 := &Request{DstIP: net.IPv4(192, 168, 0, 1).To4(), DstPort: 22}
+	reqA2 := &Request{DstIP: net.IPv4(192, 168, 0, 1).To4(), DstPort: 80}
+	reqA3 := &Request{DstIP: net.IPv4(192, 168, 0, 1).To4(), DstPort: 443}
+	reqB1 := &Request{DstIP: net.IPv4(192, 168, 0, 2).To4(), DstPort: 22}
+	reqB2 := &Request{DstIP: net.IPv4(192, 168, 0, 2).To4(), DstPort: 80}
+
+	sched := newHostScheduler(1, 100)
+	requests := sched.schedule(ctx, newRequestChan(reqA1, reqA2, reqA3, reqB1, reqB2))
+
+	require.Equal(t, reqA1, readScheduledRequest(t, requests))
+	require.Equal(t, reqB1, readScheduledRequest(t, requests))
+
+	// both hosts are busy
+	select {
+	case r := <-requests:
+		require.Fail(t, "unexpected request", r)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	sched.release(ctx, reqA1)
+	require.Equal(t, reqA2, readScheduledRequest(t, requests))
+	sched.release(ctx, reqB1)
+	require.Equal(t, reqB2, readScheduledRequest(t, requests))
+	sched.release(ctx, reqA2)
+	require.Equal(t, reqA3, readScheduledRequest(t, requests))
+	sched.release(ctx, reqB2)
+	sched.release(ctx, reqA3)
+
+	_, ok := <-requests
+	require.False(t, ok, "requests channel is not closed")
+}
+
+func TestHostSchedulerRequestErrorsAreNotLimited(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	req1 := &Request{Err: errors.New("request error")}
+	req2 := &Request{Err: errors.New("request error")}
+
+	sched := newHostScheduler(1, 100)
+	requests := sched.schedule(ctx, newRequestChan(req1, req2))
+
+	require.Equal(t, req1, readScheduledRequest(t, requests))
+	require.Equal(t, req2, readScheduledRequest(t, requests))
+}
+
+func TestHostSchedulerContextExit(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+
+	sched := newHostScheduler(1, 100)
+	requests := sched.schedule(ctx, make(chan *Request))
+	cancel()
+
+	done := make(chan interface{})
+	go func() {
+		defer close(done)
+		for range requests {
+		}
+	}()
+	waitDone(t, done)
+}
+
+type concurrencyScanner struct {
+	mu       sync.Mutex
+	inflight map[string]int
+	max      map[string]int
+}
+
+func (s *concurrencyScanner) Scan(_ context.Context, r *Request) (Result, error) {
+	key := r.DstIP.String()
+	s.mu.Lock()
+	s.inflight[key]++
+	if s.inflight[key] > s.max[key] {
+		s.max[key] = s.inflight[key]
+	}
+	s.mu.Unlock()
+
+	time.Sleep(time.Millisecond)
+
+	s.mu.Lock()
+	s.inflight[key]--
+	s.mu.Unlock()
+	return &mockScanResult{key}, nil
+}
+
+func TestScanEngineWithHostConcurrency(t *testing.T) {
+	t.Parallel()
+
+	done := make(chan interface{})
+	go func() {
+		defer close(done)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		var requests []*Request
+		for port := uint16(1); port <= 20; port++ {
+			for i := byte(1); i <= 3; i++ {
+				requests = append(requests, &Request{DstIP: net.IPv4(192, 168, 0, i).To4(), DstPort: port})
+			}
+		}
+		reqgen := NewMockRequestGenerator(gomock.NewController(t))
+		reqgen.EXPECT().GenerateRequests(gomock.Not(gomock.Nil()), &Range{}).
+			Return(newRequestChan(requests...), nil)
+		scanner := &concurrencyScanner{inflight: make(map[string]int), max: make(map[string]int)}
+
+		resultCh := NewResultChan(ctx, 100)
+		engine := NewScanEngine(reqgen, scanner, resultCh,
+			WithScanWorkerCount(10), WithHostConcurrency(2))
+
+		done, errc := engine.Start(ctx, &Range{})
+		<-done
+		require.Zero(t, len(errc), "error channel is not empty")
+		require.Len(t, resultCh.Chan(), len(requests))
+
+		require.Len(t, scanner.max, 3)
+		for host, max := range scanner.max {
+			require.LessOrEqual(t, max, 2, host)
+		}
+	}()
+	waitDone(t, done)
+}
@@ -0,0 +1,330 @@
+package smb
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"unicode/utf16"
+
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+// SMB2 fields, see [MS-SMB2] section 2.2
+const (
+	smb2HeaderSize        = 64
+	smb2CommandNegotiate  = 0x0000
+	smb2NegotiateSize     = 36
+	smb2NegotiateRespSize = 65
+
+	smb2SigningEnabled  = 0x01
+	smb2SigningRequired = 0x02
+
+	contextPreauthIntegrity = 0x0001
+	contextEncryption       = 0x0002
+	hashSHA512              = 0x0001
+	cipherAES128CCM         = 0x0001
+	cipherAES128GCM         = 0x0002
+)
+
+// SMB1 fields, see [MS-CIFS] section 2.2
+const (
+	smb1HeaderSize          = 32
+	smb1CommandNegotiate    = 0x72
+	smb1CommandSessionSetup = 0x73
+	smb1FlagsCaseless       = 0x08
+	smb1FlagsCanonical      = 0x10
+	smb1Flags2LongNames     = 0x0001
+	smb1Flags2NTStatus      = 0x4000
+	smb1Flags2Unicode       = 0x8000
+	smb1CapUnicode          = 0x00000004
+	smb1CapNTStatus         = 0x00000040
+
+	smb1SigningEnabled  = 0x04
+	smb1SigningRequired = 0x08
+
+	// DialectNTLM is the only SMB1 dialect of modern clients
+	DialectNTLM = "NT LM 0.12"
+)
+
+// maxMessageSize limits NetBIOS session messages, negotiate and session setup responses are much smaller
+const maxMessageSize = 64 * 1024
+
+// smb2Dialects are SMB2 dialect revisions in ascending order
+var smb2Dialects = []struct {
+	name     string
+	revision uint16
+}{
+	{"2.0.2", 0x0202},
+	{"2.1", 0x0210},
+	{"3.0", 0x0300},
+	{"3.0.2", 0x0302},
+	{"3.1.1", 0x0311},
+}
+
+var (
+	smb1Protocol = []byte{0xff, 'S', 'M', 'B'}
+	smb2Protocol = []byte{0xfe, 'S', 'M', 'B'}
+
+	errMessage = scan.NewError(scan.ErrParse, errors.New("invalid SMB message"))
+)
+
+// writeMessage writes the message with the NetBIOS session service header
+func writeMessage(w io.Writer, msg []byte) error {
+	packet := make([]byte, 4, 4+len(msg))
+	// the message type is zero, the length takes three bytes
+	packet[1] = byte(len(msg) >> 16)
+	binary.BigEndian.PutUint16(packet[2:4], uint16(len(msg)))
+	_, err := w.Write(append(packet, msg...))
+	return err
+}
+
+// readMessage reads the message of the NetBIOS session service, keep-alive messages are skipped
+func readMessage(r io.Reader) ([]byte, error) {
+	header := make([]byte, 4)
+	for {
+		if _, err := io.ReadFull(r, header); err != nil {
+			return nil, err
+		}
+		length := int(header[1])<<16 | int(binary.BigEndian.Uint16(header[2:4]))
+		if length > maxMessageSize {
+			return nil, fmt.Errorf("%w: message is too long", errMessage)
+		}
+		msg := make([]byte, length)
+		if _, err := io.ReadFull(r, msg); err != nil {
+			return nil, err
+		}
+		// session keep-alive
+		if header[0] == 0x85 {
+			continue
+		}
+		if header[0] != 0 {
+			return nil, fmt.Errorf("%w: unexpected session message type 0x%02x", errMessage, header[0])
+		}
+		return msg, nil
+	}
+}
+
+// smb2NegotiateRequest returns the NEGOTIATE request with the only dialect,
+// negotiate contexts are added for the 3.1.1 dialect since servers require the preauth integrity context
+func smb2NegotiateRequest(revision uint16) []byte {
+	msg := make([]byte, smb2HeaderSize, 256)
+	copy(msg, smb2Protocol)
+	binary.LittleEndian.PutUint16(msg[4:6], smb2HeaderSize)
+	binary.LittleEndian.PutUint16(msg[12:14], smb2CommandNegotiate)
+	// CreditRequest
+	binary.LittleEndian.PutUint16(msg[14:16], 1)
+
+	body := make([]byte, smb2NegotiateSize)
+	binary.LittleEndian.PutUint16(body[0:2], smb2NegotiateSize)
+	// DialectCount
+	binary.LittleEndian.PutUint16(body[2:4], 1)
+	binary.LittleEndian.PutUint16(body[4:6], smb2SigningEnabled)
+	// ClientGuid
+	_, _ = rand.Read(body[12:28])
+	msg = append(msg, body...)
+	msg = binary.LittleEndian.AppendUint16(msg, revision)
+	if revision < 0x0311 {
+		return msg
+	}
+
+	// NegotiateContextOffset and NegotiateContextCount replace ClientStartTime
+	msg = pad8(msg)
+	bodyStart := smb2HeaderSize
+	binary.LittleEndian.PutUint32(msg[bodyStart+28:bodyStart+32], uint32(len(msg)))
+	binary.LittleEndian.PutUint16(msg[bodyStart+32:bodyStart+34], 2)
+
+	salt := make([]byte, 32)
+	_, _ = rand.Read(salt)
+	preauth := binary.LittleEndian.AppendUint16(nil, 1)
+	preauth = binary.LittleEndian.AppendUint16(preauth, uint16(len(salt)))
+	preauth = binary.LittleEndian.AppendUint16(preauth, hashSHA512)
+	preauth = append(preauth, salt...)
+	msg = appendNegotiateContext(msg, contextPreauthIntegrity, preauth)
+
+	msg = pad8(msg)
+	encryption := binary.LittleEndian.AppendUint16(nil, 2)
+	encryption = binary.LittleEndian.AppendUint16(encryption, cipherAES128GCM)
+	encryption = binary.LittleEndian.AppendUint16(encryption, cipherAES128CCM)
+	return appendNegotiateContext(msg, contextEncryption, encryption)
+}
+
+func appendNegotiateContext(msg []byte, contextType uint16, data []byte) []byte {
+	msg = binary.LittleEndian.AppendUint16(msg, contextType)
+	msg = binary.LittleEndian.AppendUint16(msg, uint16(len(data)))
+	// Reserved
+	msg = binary.LittleEndian.AppendUint32(msg, 0)
+	return append(msg, data...)
+}
+
+// pad8 aligns the message to 8 bytes
+func pad8(msg []byte) []byte {
+	for len(msg)%8 != 0 {
+		msg = append(msg, 0)
+	}
+	return msg
+}
+
+type smb2NegotiateResponse struct {
+	securityMode uint16
+	revision     uint16
+}
+
+func parseSMB2NegotiateResponse(msg []byte) (*smb2NegotiateResponse, error) {
+	if len(msg) < smb2HeaderSize+6 || !bytes.Equal(msg[:4], smb2Protocol) ||
+		binary.LittleEndian.Uint16(msg[12:14]) != smb2CommandNegotiate {
+		return nil, errMessage
+	}
+	if status := binary.LittleEndian.Uint32(msg[8:12]); status != 0 {
+		return nil, fmt.Errorf("%w: status 0x%08x", errMessage, status)
+	}
+	body := msg[smb2HeaderSize:]
+	if binary.LittleEndian.Uint16(body[0:2]) != smb2NegotiateRespSize {
+		return nil, errMessage
+	}
+	return &smb2NegotiateResponse{
+		securityMode: binary.LittleEndian.Uint16(body[2:4]),
+		revision:     binary.LittleEndian.Uint16(body[4:6]),
+	}, nil
+}
+
+func smb1Header(command byte) []byte {
+	header := make([]byte, smb1HeaderSize)
+	copy(header, smb1Protocol)
+	header[4] = command
+	header[9] = smb1FlagsCaseless | smb1FlagsCanonical
+	binary.LittleEndian.PutUint16(header[10:12], smb1Flags2Unicode|smb1Flags2NTStatus|smb1Flags2LongNames)
+	// PIDLow
+	binary.LittleEndian.PutUint16(header[26:28], 0xfeff)
+	return header
+}
+
+// smb1NegotiateRequest returns the NEGOTIATE request with the NT LM 0.12 dialect without extended security,
+// so that the server returns its domain in the response and accepts the anonymous session setup
+func smb1NegotiateRequest() []byte {
+	msg := smb1Header(smb1CommandNegotiate)
+	dialects := append([]byte{0x02}, DialectNTLM...)
+	dialects = append(dialects, 0)
+	// WordCount
+	msg = append(msg, 0)
+	msg = binary.LittleEndian.AppendUint16(msg, uint16(len(dialects)))
+	return append(msg, dialects...)
+}
+
+type smb1NegotiateResponse struct {
+	securityMode byte
+	sessionKey   uint32
+	domain       string
+}
+
+func parseSMB1Header(msg []byte, command byte) (params, data []byte, unicode bool, err error) {
+	if len(msg) < smb1HeaderSize+3 || !bytes.Equal(msg[:4], smb1Protocol) || msg[4] != command {
+		return nil, nil, false, errMessage
+	}
+	if status := binary.LittleEndian.Uint32(msg[5:9]); status != 0 {
+		return nil, nil, false, fmt.Errorf("%w: status 0x%08x", errMessage, status)
+	}
+	unicode = binary.LittleEndian.Uint16(msg[10:12])&smb1Flags2Unicode != 0
+	wordCount := int(msg[smb1HeaderSize])
+	rest := msg[smb1HeaderSize+1:]
+	if len(rest) < 2*wordCount+2 {
+		return nil, nil, false, errMessage
+	}
+	params = rest[:2*wordCount]
+	byteCount := int(binary.LittleEndian.Uint16(rest[2*wordCount:]))
+	data = rest[2*wordCount+2:]
+	if len(data) < byteCount {
+		return nil, nil, false, errMessage
+	}
+	return params, data[:byteCount], unicode, nil
+}
+
+func parseSMB1NegotiateResponse(msg []byte) (*smb1NegotiateResponse, error) {
+	params, data, unicode, err := parseSMB1Header(msg, smb1CommandNegotiate)
+	if err != nil {
+		return nil, err
+	}
+	// the NT LM 0.12 response has 17 words, the dialect index is 0xffff if no dialect is supported
+	if len(params) != 34 || binary.LittleEndian.Uint16(params[0:2]) != 0 {
+		return nil, fmt.Errorf("%w: dialect is not supported", errMessage)
+	}
+	resp := &smb1NegotiateResponse{
+		securityMode: params[2],
+		sessionKey:   binary.LittleEndian.Uint32(params[15:19]),
+	}
+	challengeLength := int(params[33])
+	if len(data) >= challengeLength {
+		// the domain name follows the challenge, it is aligned to 2 bytes relative to the header in practice
+		resp.domain, _ = readString(data[challengeLength:], unicode)
+	}
+	return resp, nil
+}
+
+// smb1SessionSetupRequest returns the anonymous SESSION_SETUP_ANDX request without extended security
+func smb1SessionSetupRequest(sessionKey uint32) []byte {
+	msg := smb1Header(smb1CommandSessionSetup)
+	params := make([]byte, 26)
+	// AndXCommand: no further commands
+	params[0] = 0xff
+	// MaxBufferSize
+	binary.LittleEndian.PutUint16(params[4:6], 0xffff)
+	// MaxMpxCount
+	binary.LittleEndian.PutUint16(params[6:8], 1)
+	binary.LittleEndian.PutUint32(params[10:14], sessionKey)
+	binary.LittleEndian.PutUint32(params[22:26], smb1CapUnicode|smb1CapNTStatus)
+	msg = append(msg, byte(len(params)/2))
+	msg = append(msg, params...)
+	// empty passwords, the pad byte to align unicode strings, empty account name,
+	// primary domain, native OS and native LAN manager
+	data := make([]byte, 1+4*2)
+	msg = binary.LittleEndian.AppendUint16(msg, uint16(len(data)))
+	return append(msg, data...)
+}
+
+type smb1SessionSetupResponse struct {
+	nativeOS     string
+	nativeLanMan string
+	domain       string
+}
+
+func parseSMB1SessionSetupResponse(msg []byte) (*smb1SessionSetupResponse, error) {
+	params, data, unicode, err := parseSMB1Header(msg, smb1CommandSessionSetup)
+	if err != nil {
+		return nil, err
+	}
+	if len(params) != 6 {
+		return nil, errMessage
+	}
+	// unicode strings are aligned to 2 bytes relative to the header: header, word count, words and byte count
+	if unicode && (smb1HeaderSize+1+len(params)+2)%2 != 0 && len(data) > 0 {
+		data = data[1:]
+	}
+	resp := &smb1SessionSetupResponse{}
+	resp.nativeOS, data = readString(data, unicode)
+	resp.nativeLanMan, data = readString(data, unicode)
+	resp.domain, _ = readString(data, unicode)
+	return resp, nil
+}
+
+// readString reads the null-terminated string in UTF-16LE or OEM encoding
+func readString(data []byte, unicode bool) (string, []byte) {
+	if !unicode {
+		end := bytes.IndexByte(data, 0)
+		if end == -1 {
+			return string(data), nil
+		}
+		return string(data[:end]), data[end+1:]
+	}
+	var chars []uint16
+	for len(data) >= 2 {
+		c := binary.LittleEndian.Uint16(data)
+		data = data[2:]
+		if c == 0 {
+			break
+		}
+		chars = append(chars, c)
+	}
+	return string(utf16.Decode(chars)), data
+}
@@ -0,0 +1,214 @@
+package smb
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+	"unicode/utf16"
+
+	"github.com/stretchr/testify/require"
+)
+
+func encodeUnicode(s string) []byte {
+	var result []byte
+	for _, c := range utf16.Encode([]rune(s)) {
+		result = binary.LittleEndian.AppendUint16(result, c)
+	}
+	return append(result, 0, 0)
+}
+
+func smb2NegotiateResponseMsg(status uint32, revision, securityMode uint16) []byte {
+	msg := make([]byte, smb2HeaderSize)
+	copy(msg, smb2Protocol)
+	binary.LittleEndian.PutUint16(msg[4:6], smb2HeaderSize)
+	binary.LittleEndian.PutUint32(msg[8:12], status)
+	// response flag
+	msg[16] = 0x01
+	body := make([]byte, smb2NegotiateRespSize-1)
+	binary.LittleEndian.PutUint16(body[0:2], smb2NegotiateRespSize)
+	binary.LittleEndian.PutUint16(body[2:4], securityMode)
+	binary.LittleEndian.PutUint16(body[4:6], revision)
+	return append(msg, body...)
+}
+
+func smb1NegotiateResponseMsg(securityMode byte, sessionKey uint32, domain string) []byte {
+	msg := smb1Header(smb1CommandNegotiate)
+	params := make([]byte, 34)
+	params[2] = securityMode
+	binary.LittleEndian.PutUint32(params[15:19], sessionKey)
+	// challenge length
+	params[33] = 8
+	data := append(make([]byte, 8), encodeUnicode(domain)...)
+	data = append(data, encodeUnicode("SERVER")...)
+	msg = append(msg, byte(len(params)/2))
+	msg = append(msg, params...)
+	msg = binary.LittleEndian.AppendUint16(msg, uint16(len(data)))
+	return append(msg, data...)
+}
+
+func smb1SessionSetupResponseMsg(nativeOS, nativeLanMan, domain string) []byte {
+	msg := smb1Header(smb1CommandSessionSetup)
+	params := make([]byte, 6)
+	params[0] = 0xff
+	// pad byte to align unicode strings
+	data := []byte{0}
+	data = append(data, encodeUnicode(nativeOS)...)
+	data = append(data, encodeUnicode(nativeLanMan)...)
+	data = append(data, encodeUnicode(domain)...)
+	msg = append(msg, byte(len(params)/2))
+	msg = append(msg, params...)
+	msg = binary.LittleEndian.AppendUint16(msg, uint16(len(data)))
+	return append(msg, data...)
+}
+
+func TestWriteReadMessage(t *testing.T) {
+	t.Parallel()
+	var buf bytes.Buffer
+	// session keep-alive
+	buf.Write([]byte{0x85, 0, 0, 0})
+	require.NoError(t, writeMessage(&buf, []byte("message")))
+	require.Equal(t, []byte{0, 0, 0, 7}, buf.Bytes()[4:8])
+
+	msg, err := readMessage(&buf)
+	require.NoError(t, err)
+	require.Equal(t, []byte("message"), msg)
+}
+
+func TestReadMessageTooLong(t *testing.T) {
+	t.Parallel()
+	_, err := readMessage(bytes.NewReader([]byte{0, 0x01, 0, 1}))
+	require.ErrorIs(t, err, errMessage)
+}
+
+func TestSMB2NegotiateRequest(t *testing.T) {
+	t.Parallel()
+	msg := smb2NegotiateRequest(0x0210)
+	require.Equal(t, smb2Protocol, msg[:4])
+	require.Len(t, msg, smb2HeaderSize+smb2NegotiateSize+2)
+	require.Equal(t, uint16(1), binary.LittleEndian.Uint16(msg[smb2HeaderSize+2:]))
+	require.Equal(t, uint16(0x0210), binary.LittleEndian.Uint16(msg[smb2HeaderSize+smb2NegotiateSize:]))
+}
+
+func TestSMB2NegotiateRequestContexts(t *testing.T) {
+	t.Parallel()
+	msg := smb2NegotiateRequest(0x0311)
+	body := msg[smb2HeaderSize:]
+	offset := binary.LittleEndian.Uint32(body[28:32])
+	require.Equal(t, uint32(104), offset)
+	require.Equal(t, uint16(2), binary.LittleEndian.Uint16(body[32:34]))
+
+	contexts := msg[offset:]
+	require.Equal(t, uint16(contextPreauthIntegrity), binary.LittleEndian.Uint16(contexts[0:2]))
+	// hash algorithm count, salt length, SHA-512 and salt
+	require.Equal(t, uint16(38), binary.LittleEndian.Uint16(contexts[2:4]))
+	require.Equal(t, uint16(hashSHA512), binary.LittleEndian.Uint16(contexts[12:14]))
+
+	// the next context is aligned to 8 bytes
+	contexts = contexts[48:]
+	require.Equal(t, uint16(contextEncryption), binary.LittleEndian.Uint16(contexts[0:2]))
+	require.Equal(t, uint16(6), binary.LittleEndian.Uint16(contexts[2:4]))
+	require.Len(t, contexts, 14)
+}
+
+func TestParseSMB2NegotiateResponse(t *testing.T) {
+	t.Parallel()
+	resp, err := parseSMB2NegotiateResponse(smb2NegotiateResponseMsg(0, 0x0311, smb2SigningEnabled|smb2SigningRequired))
+	require.NoError(t, err)
+	require.Equal(t, &smb2NegotiateResponse{
+		securityMode: smb2SigningEnabled | smb2SigningRequired,
+		revision:     0x0311,
+	}, resp)
+}
+
+func TestParseSMB2NegotiateResponseError(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{
+			name: "Empty",
+		},
+		{
+			name: "SMB1",
+			data: smb1NegotiateResponseMsg(0, 0, ""),
+		},
+		{
+			name: "NotSupported",
+			data: smb2NegotiateResponseMsg(0xc00000bb, 0, 0),
+		},
+		{
+			name: "Truncated",
+			data: smb2NegotiateResponseMsg(0, 0x0202, 0)[:smb2HeaderSize+4],
+		},
+	}
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := parseSMB2NegotiateResponse(tt.data)
+			require.ErrorIs(t, err, errMessage)
+		})
+	}
+}
+
+func TestSMB1NegotiateRequest(t *testing.T) {
+	t.Parallel()
+	msg := smb1NegotiateRequest()
+	require.Equal(t, smb1Protocol, msg[:4])
+	require.Equal(t, byte(smb1CommandNegotiate), msg[4])
+	require.Equal(t, []byte("\x00\x0c\x00\x02NT LM 0.12\x00"), msg[smb1HeaderSize:])
+}
+
+func TestParseSMB1NegotiateResponse(t *testing.T) {
+	t.Parallel()
+	resp, err := parseSMB1NegotiateResponse(smb1NegotiateResponseMsg(smb1SigningEnabled, 0x12345678, "WORKGROUP"))
+	require.NoError(t, err)
+	require.Equal(t, &smb1NegotiateResponse{
+		securityMode: smb1SigningEnabled,
+		sessionKey:   0x12345678,
+		domain:       "WORKGROUP",
+	}, resp)
+}
+
+func TestParseSMB1NegotiateResponseNoDialect(t *testing.T) {
+	t.Parallel()
+	msg := smb1Header(smb1CommandNegotiate)
+	msg = append(msg, 1, 0xff, 0xff, 0, 0)
+	_, err := parseSMB1NegotiateResponse(msg)
+	require.ErrorIs(t, err, errMessage)
+}
+
+func TestSMB1SessionSetupRequest(t *testing.T) {
+	t.Parallel()
+	msg := smb1SessionSetupRequest(0x12345678)
+	params, data, unicode, err := parseSMB1Header(msg, smb1CommandSessionSetup)
+	require.NoError(t, err)
+	require.True(t, unicode)
+	require.Len(t, params, 26)
+	require.Equal(t, uint32(0x12345678), binary.LittleEndian.Uint32(params[10:14]))
+	require.Len(t, data, 9)
+}
+
+func TestParseSMB1SessionSetupResponse(t *testing.T) {
+	t.Parallel()
+	resp, err := parseSMB1SessionSetupResponse(
+		smb1SessionSetupResponseMsg("Windows 5.1", "Windows 2000 LAN Manager", "WORKGROUP"))
+	require.NoError(t, err)
+	require.Equal(t, &smb1SessionSetupResponse{
+		nativeOS:     "Windows 5.1",
+		nativeLanMan: "Windows 2000 LAN Manager",
+		domain:       "WORKGROUP",
+	}, resp)
+}
+
+func TestReadString(t *testing.T) {
+	t.Parallel()
+	s, rest := readString([]byte("Unix\x00Samba\x00"), false)
+	require.Equal(t, "Unix", s)
+	require.Equal(t, []byte("Samba\x00"), rest)
+
+	s, rest = readString(encodeUnicode("Windows"), true)
+	require.Equal(t, "Windows", s)
+	require.Empty(t, rest)
+}
@@ -0,0 +1,180 @@
+// Code generated by easyjson for marshaling/unmarshaling. DO NOT EDIT.
+
+package smb
+
+import (
+	json "encoding/json"
+	easyjson "github.com/mailru/easyjson"
+	jlexer "github.com/mailru/easyjson/jlexer"
+	jwriter "github.com/mailru/easyjson/jwriter"
+)
+
+// suppress unused package warning
+var (
+	_ *json.RawMessage
+	_ *jlexer.Lexer
+	_ *jwriter.Writer
+	_ easyjson.Marshaler
+)
+
+func easyjsonD3b49167DecodeGithubComVByteCpuSxPkgScanSmb(in *jlexer.Lexer, out *ScanResult) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "scan":
+			out.ScanType = string(in.String())
+		case "ip":
+			out.IP = string(in.String())
+		case "port":
+			out.Port = uint16(in.Uint16())
+		case "dialects":
+			if in.IsNull() {
+				in.Skip()
+				out.Dialects = nil
+			} else {
+				in.Delim('[')
+				if out.Dialects == nil {
+					if !in.IsDelim(']') {
+						out.Dialects = make([]string, 0, 4)
+					} else {
+						out.Dialects = []string{}
+					}
+				} else {
+					out.Dialects = (out.Dialects)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v1 string
+					v1 = string(in.String())
+					out.Dialects = append(out.Dialects, v1)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		case "smb1":
+			out.SMB1 = bool(in.Bool())
+		case "signing_enabled":
+			out.SigningEnabled = bool(in.Bool())
+		case "signing_required":
+			out.SigningRequired = bool(in.Bool())
+		case "os":
+			out.OS = string(in.String())
+		case "lanman":
+			out.LanMan = string(in.String())
+		case "domain":
+			out.Domain = string(in.String())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonD3b49167EncodeGithubComVByteCpuSxPkgScanSmb(out *jwriter.Writer, in ScanResult) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"scan\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.ScanType))
+	}
+	{
+		const prefix string = ",\"ip\":"
+		out.RawString(prefix)
+		out.String(string(in.IP))
+	}
+	{
+		const prefix string = ",\"port\":"
+		out.RawString(prefix)
+		out.Uint16(uint16(in.Port))
+	}
+	{
+		const prefix string = ",\"dialects\":"
+		out.RawString(prefix)
+		if in.Dialects == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+			out.RawString("null")
+		} else {
+			out.RawByte('[')
+			for v2, v3 := range in.Dialects {
+				if v2 > 0 {
+					out.RawByte(',')
+				}
+				out.String(string(v3))
+			}
+			out.RawByte(']')
+		}
+	}
+	{
+		const prefix string = ",\"smb1\":"
+		out.RawString(prefix)
+		out.Bool(bool(in.SMB1))
+	}
+	{
+		const prefix string = ",\"signing_enabled\":"
+		out.RawString(prefix)
+		out.Bool(bool(in.SigningEnabled))
+	}
+	{
+		const prefix string = ",\"signing_required\":"
+		out.RawString(prefix)
+		out.Bool(bool(in.SigningRequired))
+	}
+	if in.OS != "" {
+		const prefix string = ",\"os\":"
+		out.RawString(prefix)
+		out.String(string(in.OS))
+	}
+	if in.LanMan != "" {
+		const prefix string = ",\"lanman\":"
+		out.RawString(prefix)
+		out.String(string(in.LanMan))
+	}
+	if in.Domain != "" {
+		const prefix string = ",\"domain\":"
+		out.RawString(prefix)
+		out.String(string(in.Domain))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v ScanResult) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonD3b49167EncodeGithubComVByteCpuSxPkgScanSmb(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v ScanResult) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD3b49167EncodeGithubComVByteCpuSxPkgScanSmb(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *ScanResult) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonD3b49167DecodeGithubComVByteCpuSxPkgScanSmb(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *ScanResult) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD3b49167DecodeGithubComVByteCpuSxPkgScanSmb(l, v)
+}
@@ -0,0 +1,232 @@
+//go:generate easyjson -output_filename result_easyjson.go smb.go
+
+package smb
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+const (
+	ScanType = "smb"
+
+	defaultDialTimeout = 2 * time.Second
+	defaultDataTimeout = 2 * time.Second
+)
+
+//easyjson:json
+type ScanResult struct {
+	ScanType string `json:"scan"`
+	IP       string `json:"ip"`
+	Port     uint16 `json:"port"`
+	// Dialects are supported dialects in ascending order, e.g. NT LM 0.12, 2.0.2, 2.1, 3.0, 3.0.2, 3.1.1
+	Dialects []string `json:"dialects"`
+	// SMB1 is true if the server accepted the NT LM 0.12 dialect
+	SMB1 bool `json:"smb1"`
+	// SigningEnabled and SigningRequired are taken from the negotiate response of the highest dialect
+	SigningEnabled  bool `json:"signing_enabled"`
+	SigningRequired bool `json:"signing_required"`
+	// OS, LanMan and Domain are reported by SMB1 servers that accept the anonymous session setup
+	OS     string `json:"os,omitempty"`
+	LanMan string `json:"lanman,omitempty"`
+	Domain string `json:"domain,omitempty"`
+}
+
+func (r *ScanResult) String() string {
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "%-20s %-5d %s", r.IP, r.Port, strings.Join(r.Dialects, ","))
+	switch {
+	case r.SigningRequired:
+		buf.WriteString(" signing required")
+	case r.SigningEnabled:
+		buf.WriteString(" signing enabled")
+	default:
+		buf.WriteString(" signing disabled")
+	}
+	if len(r.OS) > 0 {
+		fmt.Fprintf(&buf, " %q", r.OS)
+	}
+	return buf.String()
+}
+
+func (r *ScanResult) ID() string {
+	return fmt.Sprintf("%s:%d", r.IP, r.Port)
+}
+
+// Scanner negotiates each SMB2 dialect over a separate connection and the SMB1 NT LM 0.12 dialect
+// followed by the anonymous session setup to get OS and LAN manager strings
+type Scanner struct {
+	dialer      *net.Dialer
+	dataTimeout time.Duration
+}
+
+// Assert that smb.Scanner conforms to the scan.Scanner interface
+var _ scan.Scanner = (*Scanner)(nil)
+
+type ScannerOption func(*Scanner)
+
+func WithDialTimeout(timeout time.Duration) ScannerOption {
+	return func(s *Scanner) {
+		s.dialer.Timeout = timeout
+	}
+}
+
+func WithDataTimeout(timeout time.Duration) ScannerOption {
+	return func(s *Scanner) {
+		s.dataTimeout = timeout
+	}
+}
+
+func NewScanner(opts ...ScannerOption) *Scanner {
+	s := &Scanner{
+		dialer: &net.Dialer{
+			Timeout: defaultDialTimeout,
+		},
+		dataTimeout: defaultDataTimeout,
+	}
+	for _, o := range opts {
+		o(s)
+	}
+	return s
+}
+
+func (s *Scanner) Scan(ctx context.Context, r *scan.Request) (scan.Result, error) {
+	result, err := s.scanTarget(ctx, r)
+	return result, scan.WrapError(err)
+}
+
+func (s *Scanner) scanTarget(ctx context.Context, r *scan.Request) (scan.Result, error) {
+	addr := net.JoinHostPort(r.DstIP.String(), strconv.Itoa(int(r.DstPort)))
+	result := &ScanResult{
+		ScanType: ScanType,
+		IP:       r.DstIP.String(),
+		Port:     r.DstPort,
+	}
+
+	// the first connection tells whether the port is open, errors of the following connections
+	// mean that the dialect is not supported, since servers just close the connection in this case
+	smb1, err := s.negotiateSMB1(ctx, addr, result)
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	if isDialError(err) {
+		return nil, err
+	}
+	lastErr := err
+	if smb1 != nil {
+		result.SMB1 = true
+		result.Dialects = append(result.Dialects, DialectNTLM)
+		result.SigningEnabled = smb1.securityMode&smb1SigningEnabled != 0
+		result.SigningRequired = smb1.securityMode&smb1SigningRequired != 0
+	}
+
+	for _, dialect := range smb2Dialects {
+		resp, err := s.negotiateSMB2(ctx, addr, dialect.revision)
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if resp.revision != dialect.revision {
+			continue
+		}
+		result.Dialects = append(result.Dialects, dialect.name)
+		result.SigningEnabled = resp.securityMode&smb2SigningEnabled != 0
+		result.SigningRequired = resp.securityMode&smb2SigningRequired != 0
+	}
+	if len(result.Dialects) == 0 {
+		return nil, lastErr
+	}
+	// signing is always enabled if it is required
+	result.SigningEnabled = result.SigningEnabled || result.SigningRequired
+	return result, nil
+}
+
+// negotiateSMB1 negotiates the NT LM 0.12 dialect and fills OS and LAN manager strings of the result,
+// the session setup is optional, its errors are ignored
+func (s *Scanner) negotiateSMB1(ctx context.Context, addr string, result *ScanResult) (*smb1NegotiateResponse, error) {
+	c, err := s.dial(ctx, addr)
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+
+	if err = writeMessage(c, smb1NegotiateRequest()); err != nil {
+		return nil, err
+	}
+	msg, err := readMessage(c)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := parseSMB1NegotiateResponse(msg)
+	if err != nil {
+		return nil, err
+	}
+	result.Domain = resp.domain
+
+	if err = writeMessage(c, smb1SessionSetupRequest(resp.sessionKey)); err != nil {
+		return resp, nil
+	}
+	if msg, err = readMessage(c); err != nil {
+		return resp, nil
+	}
+	if setup, err := parseSMB1SessionSetupResponse(msg); err == nil {
+		result.OS = setup.nativeOS
+		result.LanMan = setup.nativeLanMan
+		if len(setup.domain) > 0 {
+			result.Domain = setup.domain
+		}
+	}
+	return resp, nil
+}
+
+func (s *Scanner) negotiateSMB2(ctx context.Context, addr string, revision uint16) (*smb2NegotiateResponse, error) {
+	c, err := s.dial(ctx, addr)
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+
+	if err = writeMessage(c, smb2NegotiateRequest(revision)); err != nil {
+		return nil, err
+	}
+	msg, err := readMessage(c)
+	if err != nil {
+		return nil, err
+	}
+	return parseSMB2NegotiateResponse(msg)
+}
+
+func (s *Scanner) dial(ctx context.Context, addr string) (net.Conn, error) {
+	c, err := s.dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, &dialError{err}
+	}
+	if err = c.SetDeadline(time.Now().Add(s.dataTimeout)); err != nil {
+		c.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+type dialError struct {
+	error
+}
+
+func (e *dialError) Unwrap() error {
+	return e.error
+}
+
+func isDialError(err error) bool {
+	var dialErr *dialError
+	return errors.As(err, &dialErr)
+}
@@ -0,0 +1,181 @@
+package smb
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+type testServer struct {
+	smb1         bool
+	revisions    map[uint16]bool
+	securityMode uint16
+}
+
+// serveSMB answers negotiate requests of the supported dialects, connections with other dialects are closed
+func serveSMB(t *testing.T, server *testServer) (addr string) {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { l.Close() })
+
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go server.handle(conn)
+		}
+	}()
+	return l.Addr().String()
+}
+
+func (server *testServer) handle(conn net.Conn) {
+	defer conn.Close()
+	msg, err := readMessage(conn)
+	if err != nil {
+		return
+	}
+	if bytes.HasPrefix(msg, smb2Protocol) && len(msg) >= smb2HeaderSize+smb2NegotiateSize+2 {
+		revision := binary.LittleEndian.Uint16(msg[smb2HeaderSize+smb2NegotiateSize:])
+		if server.revisions[revision] {
+			_ = writeMessage(conn, smb2NegotiateResponseMsg(0, revision, server.securityMode))
+		}
+		return
+	}
+	if !server.smb1 {
+		return
+	}
+	if err = writeMessage(conn, smb1NegotiateResponseMsg(byte(server.securityMode), 0x12345678, "WORKGROUP")); err != nil {
+		return
+	}
+	if _, err = readMessage(conn); err != nil {
+		return
+	}
+	_ = writeMessage(conn, smb1SessionSetupResponseMsg("Windows 5.1", "Windows 2000 LAN Manager", "WORKGROUP"))
+}
+
+func newTestRequest(t *testing.T, addr string) *scan.Request {
+	t.Helper()
+	tcpAddr, err := net.ResolveTCPAddr("tcp", addr)
+	require.NoError(t, err)
+	return &scan.Request{DstIP: tcpAddr.IP, DstPort: uint16(tcpAddr.Port)}
+}
+
+func TestScan(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		server   *testServer
+		expected *ScanResult
+	}{
+		{
+			name: "SMB1",
+			server: &testServer{
+				smb1:         true,
+				securityMode: smb1SigningEnabled,
+			},
+			expected: &ScanResult{
+				Dialects:       []string{DialectNTLM},
+				SMB1:           true,
+				SigningEnabled: true,
+				OS:             "Windows 5.1",
+				LanMan:         "Windows 2000 LAN Manager",
+				Domain:         "WORKGROUP",
+			},
+		},
+		{
+			name: "SMB2",
+			server: &testServer{
+				revisions:    map[uint16]bool{0x0202: true, 0x0210: true, 0x0300: true, 0x0302: true, 0x0311: true},
+				securityMode: smb2SigningEnabled | smb2SigningRequired,
+			},
+			expected: &ScanResult{
+				Dialects:        []string{"2.0.2", "2.1", "3.0", "3.0.2", "3.1.1"},
+				SigningEnabled:  true,
+				SigningRequired: true,
+			},
+		},
+		{
+			name: "SMB3Only",
+			server: &testServer{
+				revisions: map[uint16]bool{0x0300: true, 0x0311: true},
+			},
+			expected: &ScanResult{
+				Dialects: []string{"3.0", "3.1.1"},
+			},
+		},
+	}
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			req := newTestRequest(t, serveSMB(t, tt.server))
+			s := NewScanner()
+			result, err := s.Scan(context.Background(), req)
+			require.NoError(t, err)
+
+			tt.expected.ScanType = ScanType
+			tt.expected.IP = req.DstIP.String()
+			tt.expected.Port = req.DstPort
+			require.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestScanNotSMBServer(t *testing.T) {
+	t.Parallel()
+	req := newTestRequest(t, serveSMB(t, &testServer{}))
+	s := NewScanner()
+	result, err := s.Scan(context.Background(), req)
+	require.Error(t, err)
+	require.Nil(t, result)
+}
+
+func TestScanClosedPort(t *testing.T) {
+	t.Parallel()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	req := newTestRequest(t, l.Addr().String())
+	l.Close()
+
+	s := NewScanner()
+	result, err := s.Scan(context.Background(), req)
+	require.Error(t, err)
+	require.True(t, isDialError(err))
+	require.Nil(t, result)
+}
+
+func TestScanTimeout(t *testing.T) {
+	t.Parallel()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+	done := make(chan interface{})
+	defer close(done)
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				<-done
+			}()
+		}
+	}()
+
+	s := NewScanner(WithDataTimeout(50 * time.Millisecond))
+	result, err := s.Scan(context.Background(), newTestRequest(t, l.Addr().String()))
+	require.Error(t, err)
+	require.ErrorIs(t, err, scan.ErrTimeout)
+	require.Nil(t, result)
+}
@@ -0,0 +1,77 @@
+package smtp
+
+import (
+	"errors"
+	"fmt"
+	"net/textproto"
+	"strconv"
+	"strings"
+
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+// SMTP reply codes, see RFC 5321 section 4.2
+const (
+	codeReady        = 220
+	codeOK           = 250
+	codeUserNotLocal = 251
+	classCompletion  = 2
+)
+
+var errReply = scan.NewError(scan.ErrParse, errors.New("invalid SMTP reply"))
+
+// readReply reads a reply of the server, a reply with an unexpected code is returned as errReply
+func readReply(tp *textproto.Conn, expectCode int) (code int, msg string, err error) {
+	// textproto waits for continuation lines of banners like SSH-2.0, so the code is checked in advance
+	prefix, err := tp.R.Peek(3)
+	if err != nil {
+		return
+	}
+	if _, err = strconv.ParseUint(string(prefix), 10, 16); err != nil {
+		return 0, "", fmt.Errorf("%w: %q", errReply, prefix)
+	}
+	code, msg, err = tp.ReadResponse(expectCode)
+	var protoErr textproto.ProtocolError
+	var replyErr *textproto.Error
+	if errors.As(err, &protoErr) || errors.As(err, &replyErr) {
+		err = fmt.Errorf("%w: %v", errReply, err)
+	}
+	return
+}
+
+// command sends the command and reads the reply of the server
+func command(tp *textproto.Conn, expectCode int, format string, args ...interface{}) (int, string, error) {
+	if _, err := tp.Cmd(format, args...); err != nil {
+		return 0, "", err
+	}
+	return readReply(tp, expectCode)
+}
+
+// ehloReply holds service extensions of the EHLO reply, see RFC 5321 section 4.1.1.1
+type ehloReply struct {
+	// extensions are lines of the reply after the greeting, e.g. SIZE 35882577
+	extensions []string
+	startTLS   bool
+	// auth holds SASL mechanisms of the AUTH extension
+	auth []string
+}
+
+func parseEHLOReply(msg string) *ehloReply {
+	reply := &ehloReply{}
+	lines := strings.Split(msg, "\n")
+	for _, line := range lines[1:] {
+		line = strings.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+		reply.extensions = append(reply.extensions, line)
+		fields := strings.Fields(line)
+		switch strings.ToUpper(fields[0]) {
+		case "STARTTLS":
+			reply.startTLS = true
+		case "AUTH":
+			reply.auth = append(reply.auth, fields[1:]...)
+		}
+	}
+	return reply
+}
@@ -0,0 +1,22 @@
+package smtp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseEHLOReply(t *testing.T) {
+	t.Parallel()
+	msg := "mail.example.org Hello example.com\nPIPELINING\nSIZE 35882577\nSTARTTLS\nAUTH PLAIN LOGIN\n8BITMIME"
+	reply := parseEHLOReply(msg)
+	require.Equal(t, []string{"PIPELINING", "SIZE 35882577", "STARTTLS", "AUTH PLAIN LOGIN", "8BITMIME"}, reply.extensions)
+	require.True(t, reply.startTLS)
+	require.Equal(t, []string{"PLAIN", "LOGIN"}, reply.auth)
+}
+
+func TestParseEHLOReplyNoExtensions(t *testing.T) {
+	t.Parallel()
+	reply := parseEHLOReply("mail.example.org")
+	require.Equal(t, &ehloReply{}, reply)
+}
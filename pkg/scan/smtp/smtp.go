@@ -0,0 +1,182 @@
+package smtp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+const (
+	ScanType = "smtp"
+
+	defaultDialTimeout = 2 * time.Second
+	defaultDataTimeout = 5 * time.Second
+
+	// heloDomain is sent in EHLO/HELO commands
+	heloDomain = "example.com"
+	// relayFrom and relayTo are addresses of domains the server is not responsible for,
+	// so accepting the recipient means relaying mail for anyone
+	relayFrom = "sx@example.com"
+	relayTo   = "sx@example.net"
+)
+
+type ScanResult struct {
+	ScanType string `json:"scan"`
+	IP       string `json:"ip"`
+	Port     uint16 `json:"port"`
+	// Banner is the text of the 220 greeting, lines of multi-line greetings are separated by newlines
+	Banner string `json:"banner"`
+	// Extensions are service extensions of the EHLO reply, e.g. SIZE 35882577 or 8BITMIME
+	Extensions []string `json:"extensions,omitempty"`
+	StartTLS   bool     `json:"starttls"`
+	// Auth holds SASL mechanisms of the AUTH extension, e.g. PLAIN or LOGIN
+	Auth []string `json:"auth,omitempty"`
+	// OpenRelay is set if the relay check is enabled and the server accepted a recipient of an external domain
+	OpenRelay bool `json:"open_relay,omitempty"`
+}
+
+func (r *ScanResult) String() string {
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "%-20s %-5d %q", r.IP, r.Port, r.Banner)
+	if r.StartTLS {
+		buf.WriteString(" starttls")
+	}
+	if len(r.Auth) > 0 {
+		fmt.Fprintf(&buf, " auth:%s", strings.Join(r.Auth, ","))
+	}
+	if r.OpenRelay {
+		buf.WriteString(" open-relay")
+	}
+	return buf.String()
+}
+
+func (r *ScanResult) ID() string {
+	return fmt.Sprintf("%s:%d", r.IP, r.Port)
+}
+
+func (r *ScanResult) MarshalJSON() ([]byte, error) {
+	// Type definition for the recursive call
+	type JScanResult ScanResult
+	// This works because JScanResult doesn't have a MarshalJSON function associated with it
+	return json.Marshal(JScanResult(*r))
+}
+
+// Scanner reads the greeting of SMTP servers and enumerates their service extensions with EHLO.
+// The relay check sends MAIL FROM and RCPT TO commands with external addresses and resets the transaction
+// with RSET, no mail is delivered since the DATA command is never sent.
+type Scanner struct {
+	dialer      *net.Dialer
+	dataTimeout time.Duration
+	relayCheck  bool
+}
+
+// Assert that smtp.Scanner conforms to the scan.Scanner interface
+var _ scan.Scanner = (*Scanner)(nil)
+
+type ScannerOption func(*Scanner)
+
+func WithDialTimeout(timeout time.Duration) ScannerOption {
+	return func(s *Scanner) {
+		s.dialer.Timeout = timeout
+	}
+}
+
+func WithDataTimeout(timeout time.Duration) ScannerOption {
+	return func(s *Scanner) {
+		s.dataTimeout = timeout
+	}
+}
+
+// WithRelayCheck enables the non-delivering open relay check
+func WithRelayCheck() ScannerOption {
+	return func(s *Scanner) {
+		s.relayCheck = true
+	}
+}
+
+func NewScanner(opts ...ScannerOption) *Scanner {
+	s := &Scanner{
+		dialer: &net.Dialer{
+			Timeout: defaultDialTimeout,
+		},
+		dataTimeout: defaultDataTimeout,
+	}
+	for _, o := range opts {
+		o(s)
+	}
+	return s
+}
+
+func (s *Scanner) Scan(ctx context.Context, r *scan.Request) (scan.Result, error) {
+	result, err := s.scanTarget(ctx, r)
+	return result, scan.WrapError(err)
+}
+
+func (s *Scanner) scanTarget(ctx context.Context, r *scan.Request) (scan.Result, error) {
+	addr := net.JoinHostPort(r.DstIP.String(), strconv.Itoa(int(r.DstPort)))
+	conn, err := s.dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	if err = conn.SetDeadline(time.Now().Add(s.dataTimeout)); err != nil {
+		return nil, err
+	}
+	tp := textproto.NewConn(conn)
+
+	_, banner, err := readReply(tp, codeReady)
+	if err != nil {
+		return nil, err
+	}
+	result := &ScanResult{
+		ScanType: ScanType,
+		IP:       r.DstIP.String(),
+		Port:     r.DstPort,
+		Banner:   banner,
+	}
+	// the server is detected at this point, so failures of further commands are not reported as errors
+	defer func() {
+		_, _ = tp.Cmd("QUIT")
+	}()
+	if !hello(tp, result) || !s.relayCheck {
+		return result, nil
+	}
+	result.OpenRelay = openRelay(tp)
+	return result, nil
+}
+
+// hello sends EHLO to enumerate service extensions, old servers that don't support it are greeted with HELO
+func hello(tp *textproto.Conn, result *ScanResult) bool {
+	code, msg, err := command(tp, 0, "EHLO %s", heloDomain)
+	if err != nil {
+		return false
+	}
+	if code == codeOK {
+		reply := parseEHLOReply(msg)
+		result.Extensions, result.StartTLS, result.Auth = reply.extensions, reply.startTLS, reply.auth
+		return true
+	}
+	_, _, err = command(tp, codeOK, "HELO %s", heloDomain)
+	return err == nil
+}
+
+// openRelay checks whether the server accepts the recipient of an external domain,
+// the mail transaction is reset after the recipient
+func openRelay(tp *textproto.Conn) bool {
+	if _, _, err := command(tp, classCompletion, "MAIL FROM:<%s>", relayFrom); err != nil {
+		return false
+	}
+	code, _, err := command(tp, 0, "RCPT TO:<%s>", relayTo)
+	if err != nil {
+		return false
+	}
+	_, _, _ = command(tp, 0, "RSET")
+	return code == codeOK || code == codeUserNotLocal
+}
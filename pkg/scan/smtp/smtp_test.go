@@ -0,0 +1,216 @@
+package smtp
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+type testServer struct {
+	banner string
+	// replies to commands by the command name, unknown commands are rejected with 500
+	replies map[string]string
+	// commands receives all commands of the client after the connection is closed
+	commands chan []string
+}
+
+func serveSMTP(t *testing.T, server *testServer) *scan.Request {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { l.Close() })
+
+	server.commands = make(chan []string, 1)
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		server.handle(conn)
+	}()
+	addr := l.Addr().(*net.TCPAddr)
+	return &scan.Request{DstIP: addr.IP, DstPort: uint16(addr.Port)}
+}
+
+func (server *testServer) handle(conn net.Conn) {
+	defer conn.Close()
+	var commands []string
+	defer func() { server.commands <- commands }()
+	if _, err := fmt.Fprint(conn, server.banner); err != nil {
+		return
+	}
+	scanner := bufio.NewScanner(conn)
+	for scanner.Scan() {
+		cmd := scanner.Text()
+		commands = append(commands, cmd)
+		name := strings.ToUpper(strings.SplitN(cmd, " ", 2)[0])
+		if name == "QUIT" {
+			_, _ = fmt.Fprint(conn, "221 Bye\r\n")
+			return
+		}
+		reply, ok := server.replies[name]
+		if !ok {
+			reply = "500 Command unrecognized\r\n"
+		}
+		if _, err := fmt.Fprint(conn, reply); err != nil {
+			return
+		}
+	}
+}
+
+func TestScan(t *testing.T) {
+	t.Parallel()
+	ehloReply := "250-mail.example.org Hello example.com\r\n250-PIPELINING\r\n250-SIZE 35882577\r\n" +
+		"250-STARTTLS\r\n250-AUTH PLAIN LOGIN\r\n250 8BITMIME\r\n"
+	tests := []struct {
+		name       string
+		server     *testServer
+		relayCheck bool
+		expected   *ScanResult
+		commands   []string
+	}{
+		{
+			name: "EHLO",
+			server: &testServer{
+				banner:  "220 mail.example.org ESMTP Postfix\r\n",
+				replies: map[string]string{"EHLO": ehloReply},
+			},
+			expected: &ScanResult{
+				Banner:     "mail.example.org ESMTP Postfix",
+				Extensions: []string{"PIPELINING", "SIZE 35882577", "STARTTLS", "AUTH PLAIN LOGIN", "8BITMIME"},
+				StartTLS:   true,
+				Auth:       []string{"PLAIN", "LOGIN"},
+			},
+			commands: []string{"EHLO example.com", "QUIT"},
+		},
+		{
+			name: "HELO",
+			server: &testServer{
+				banner: "220-mail.example.org\r\n220 old SMTP server\r\n",
+				replies: map[string]string{
+					"HELO": "250 mail.example.org\r\n",
+					"MAIL": "250 OK\r\n",
+					"RCPT": "550 Relaying denied\r\n",
+					"RSET": "250 OK\r\n",
+				},
+			},
+			relayCheck: true,
+			expected: &ScanResult{
+				Banner: "mail.example.org\nold SMTP server",
+			},
+			commands: []string{"EHLO example.com", "HELO example.com",
+				"MAIL FROM:<sx@example.com>", "RCPT TO:<sx@example.net>", "RSET", "QUIT"},
+		},
+		{
+			name: "OpenRelay",
+			server: &testServer{
+				banner: "220 relay.example.org ESMTP\r\n",
+				replies: map[string]string{
+					"EHLO": "250 relay.example.org\r\n",
+					"MAIL": "250 2.1.0 OK\r\n",
+					"RCPT": "250 2.1.5 OK\r\n",
+					"RSET": "250 2.0.0 OK\r\n",
+				},
+			},
+			relayCheck: true,
+			expected: &ScanResult{
+				Banner:    "relay.example.org ESMTP",
+				OpenRelay: true,
+			},
+			commands: []string{"EHLO example.com",
+				"MAIL FROM:<sx@example.com>", "RCPT TO:<sx@example.net>", "RSET", "QUIT"},
+		},
+		{
+			name: "SenderRejected",
+			server: &testServer{
+				banner: "220 mail.example.org ESMTP\r\n",
+				replies: map[string]string{
+					"EHLO": "250 mail.example.org\r\n",
+					"MAIL": "530 5.7.0 Authentication required\r\n",
+				},
+			},
+			relayCheck: true,
+			expected: &ScanResult{
+				Banner: "mail.example.org ESMTP",
+			},
+			commands: []string{"EHLO example.com", "MAIL FROM:<sx@example.com>", "QUIT"},
+		},
+	}
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			req := serveSMTP(t, tt.server)
+			var opts []ScannerOption
+			if tt.relayCheck {
+				opts = append(opts, WithRelayCheck())
+			}
+			result, err := NewScanner(opts...).Scan(context.Background(), req)
+			require.NoError(t, err)
+
+			tt.expected.ScanType = ScanType
+			tt.expected.IP = req.DstIP.String()
+			tt.expected.Port = req.DstPort
+			require.Equal(t, tt.expected, result)
+			require.Equal(t, tt.commands, <-tt.server.commands)
+		})
+	}
+}
+
+func TestScanNotSMTPServer(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name   string
+		banner string
+	}{
+		{
+			name:   "SSH",
+			banner: "SSH-2.0-OpenSSH_8.4p1 Debian-5\r\n",
+		},
+		{
+			name:   "ServiceNotAvailable",
+			banner: "554 No SMTP service here\r\n",
+		},
+	}
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			req := serveSMTP(t, &testServer{banner: tt.banner})
+			result, err := NewScanner().Scan(context.Background(), req)
+			require.ErrorIs(t, err, errReply)
+			require.Nil(t, result)
+		})
+	}
+}
+
+func TestScanTimeout(t *testing.T) {
+	t.Parallel()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+	done := make(chan interface{})
+	defer close(done)
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		<-done
+	}()
+	addr := l.Addr().(*net.TCPAddr)
+
+	s := NewScanner(WithDataTimeout(50 * time.Millisecond))
+	result, err := s.Scan(context.Background(), &scan.Request{DstIP: addr.IP, DstPort: uint16(addr.Port)})
+	require.Error(t, err)
+	require.ErrorIs(t, err, scan.ErrTimeout)
+	require.Nil(t, result)
+}
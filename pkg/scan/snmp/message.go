@@ -0,0 +1,243 @@
+package snmp
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+// BER tags of SNMP messages, see RFC 1157 and RFC 3416
+const (
+	tagInteger     = 0x02
+	tagOctetString = 0x04
+	tagNull        = 0x05
+	tagOID         = 0x06
+	tagSequence    = 0x30
+	tagGetRequest  = 0xa0
+	tagGetResponse = 0xa2
+)
+
+var errMessage = scan.NewError(scan.ErrParse, errors.New("invalid SNMP message"))
+
+var (
+	oidSysDescr = []int{1, 3, 6, 1, 2, 1, 1, 1, 0}
+	oidSysName  = []int{1, 3, 6, 1, 2, 1, 1, 5, 0}
+)
+
+// getRequest returns the GetRequest message for the OIDs with NULL values
+func getRequest(version int, community string, requestID int32, oids ...[]int) []byte {
+	var varbinds []byte
+	for _, oid := range oids {
+		varbinds = append(varbinds, encodeTLV(tagSequence,
+			append(encodeTLV(tagOID, encodeOID(oid)), encodeTLV(tagNull, nil)...))...)
+	}
+	var pdu []byte
+	pdu = append(pdu, encodeTLV(tagInteger, encodeInteger(int64(requestID)))...)
+	// error-status and error-index
+	pdu = append(pdu, encodeTLV(tagInteger, encodeInteger(0))...)
+	pdu = append(pdu, encodeTLV(tagInteger, encodeInteger(0))...)
+	pdu = append(pdu, encodeTLV(tagSequence, varbinds)...)
+
+	var msg []byte
+	msg = append(msg, encodeTLV(tagInteger, encodeInteger(int64(version)))...)
+	msg = append(msg, encodeTLV(tagOctetString, []byte(community))...)
+	msg = append(msg, encodeTLV(tagGetRequest, pdu)...)
+	return encodeTLV(tagSequence, msg)
+}
+
+type variable struct {
+	oid   string
+	tag   byte
+	value []byte
+}
+
+type getResponse struct {
+	version     int
+	community   string
+	requestID   int32
+	errorStatus int
+	variables   []*variable
+}
+
+func parseGetResponse(data []byte) (*getResponse, error) {
+	msg, rest, err := expectTLV(data, tagSequence)
+	if err != nil {
+		return nil, err
+	}
+	if len(rest) > 0 {
+		return nil, errMessage
+	}
+	resp := &getResponse{}
+	var value []byte
+	if value, msg, err = expectTLV(msg, tagInteger); err != nil {
+		return nil, err
+	}
+	resp.version = int(decodeInteger(value))
+	if value, msg, err = expectTLV(msg, tagOctetString); err != nil {
+		return nil, err
+	}
+	resp.community = string(value)
+	var pdu []byte
+	if pdu, _, err = expectTLV(msg, tagGetResponse); err != nil {
+		return nil, err
+	}
+	if value, pdu, err = expectTLV(pdu, tagInteger); err != nil {
+		return nil, err
+	}
+	resp.requestID = int32(decodeInteger(value))
+	if value, pdu, err = expectTLV(pdu, tagInteger); err != nil {
+		return nil, err
+	}
+	resp.errorStatus = int(decodeInteger(value))
+	// error-index
+	if _, pdu, err = expectTLV(pdu, tagInteger); err != nil {
+		return nil, err
+	}
+	var varbinds []byte
+	if varbinds, _, err = expectTLV(pdu, tagSequence); err != nil {
+		return nil, err
+	}
+	for len(varbinds) > 0 {
+		var varbind []byte
+		if varbind, varbinds, err = expectTLV(varbinds, tagSequence); err != nil {
+			return nil, err
+		}
+		var oid []byte
+		if oid, varbind, err = expectTLV(varbind, tagOID); err != nil {
+			return nil, err
+		}
+		var tag byte
+		if tag, value, _, err = parseTLV(varbind); err != nil {
+			return nil, err
+		}
+		resp.variables = append(resp.variables, &variable{oid: decodeOID(oid), tag: tag, value: value})
+	}
+	return resp, nil
+}
+
+// stringValue returns the value of the variable with the OID if it is an octet string,
+// SNMPv2 exceptions like noSuchObject are skipped
+func (r *getResponse) stringValue(oid []int) string {
+	name := oidString(oid)
+	for _, v := range r.variables {
+		if v.oid == name && v.tag == tagOctetString {
+			return string(v.value)
+		}
+	}
+	return ""
+}
+
+func encodeTLV(tag byte, value []byte) []byte {
+	result := []byte{tag}
+	length := len(value)
+	if length < 0x80 {
+		result = append(result, byte(length))
+	} else {
+		// long form: the number of length bytes followed by the length in big-endian order
+		var lengthBytes []byte
+		for ; length > 0; length >>= 8 {
+			lengthBytes = append([]byte{byte(length)}, lengthBytes...)
+		}
+		result = append(result, 0x80|byte(len(lengthBytes)))
+		result = append(result, lengthBytes...)
+	}
+	return append(result, value...)
+}
+
+func parseTLV(data []byte) (tag byte, value, rest []byte, err error) {
+	if len(data) < 2 {
+		return 0, nil, nil, errMessage
+	}
+	tag = data[0]
+	length := int(data[1])
+	data = data[2:]
+	if length&0x80 != 0 {
+		n := length & 0x7f
+		// lengths of UDP datagrams fit into 2 bytes
+		if n == 0 || n > 2 || len(data) < n {
+			return 0, nil, nil, errMessage
+		}
+		length = 0
+		for _, b := range data[:n] {
+			length = length<<8 | int(b)
+		}
+		data = data[n:]
+	}
+	if len(data) < length {
+		return 0, nil, nil, errMessage
+	}
+	return tag, data[:length], data[length:], nil
+}
+
+func expectTLV(data []byte, expectedTag byte) (value, rest []byte, err error) {
+	var tag byte
+	if tag, value, rest, err = parseTLV(data); err != nil {
+		return
+	}
+	if tag != expectedTag {
+		return nil, nil, fmt.Errorf("%w: unexpected tag 0x%02x", errMessage, tag)
+	}
+	return
+}
+
+// encodeInteger returns the minimal two's complement encoding of the integer
+func encodeInteger(v int64) []byte {
+	result := []byte{byte(v)}
+	for v > 0x7f || v < -0x80 {
+		v >>= 8
+		result = append([]byte{byte(v)}, result...)
+	}
+	return result
+}
+
+func decodeInteger(data []byte) (v int64) {
+	for i, b := range data {
+		if i == 0 && b&0x80 != 0 {
+			v = -1
+		}
+		v = v<<8 | int64(b)
+	}
+	return
+}
+
+func encodeOID(oid []int) []byte {
+	result := []byte{byte(40*oid[0] + oid[1])}
+	for _, arc := range oid[2:] {
+		// base 128 with the high bit set on all bytes except the last one
+		encoded := []byte{byte(arc & 0x7f)}
+		for arc >>= 7; arc > 0; arc >>= 7 {
+			encoded = append([]byte{byte(arc&0x7f) | 0x80}, encoded...)
+		}
+		result = append(result, encoded...)
+	}
+	return result
+}
+
+func decodeOID(data []byte) string {
+	if len(data) == 0 {
+		return ""
+	}
+	arcs := []int{int(data[0]) / 40, int(data[0]) % 40}
+	arc := 0
+	for _, b := range data[1:] {
+		arc = arc<<7 | int(b&0x7f)
+		if b&0x80 == 0 {
+			arcs = append(arcs, arc)
+			arc = 0
+		}
+	}
+	return oidString(arcs)
+}
+
+func oidString(oid []int) string {
+	var result []byte
+	for i, arc := range oid {
+		if i > 0 {
+			result = append(result, '.')
+		}
+		result = strconv.AppendInt(result, int64(arc), 10)
+	}
+	return string(result)
+}
@@ -0,0 +1,98 @@
+package snmp
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncodeDecodeInteger(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		value    int64
+		expected []byte
+	}{
+		{value: 0, expected: []byte{0x00}},
+		{value: 127, expected: []byte{0x7f}},
+		{value: 128, expected: []byte{0x00, 0x80}},
+		{value: 256, expected: []byte{0x01, 0x00}},
+		{value: -1, expected: []byte{0xff}},
+		{value: -129, expected: []byte{0xff, 0x7f}},
+		{value: 2147483647, expected: []byte{0x7f, 0xff, 0xff, 0xff}},
+	}
+	for _, tt := range tests {
+		require.Equal(t, tt.expected, encodeInteger(tt.value), "value %d", tt.value)
+		require.Equal(t, tt.value, decodeInteger(tt.expected), "value %d", tt.value)
+	}
+}
+
+func TestEncodeDecodeOID(t *testing.T) {
+	t.Parallel()
+	oid := []int{1, 3, 6, 1, 4, 1, 2021, 10, 1, 3, 1}
+	data := encodeOID(oid)
+	require.Equal(t, []byte{0x2b, 0x06, 0x01, 0x04, 0x01, 0x8f, 0x65, 0x0a, 0x01, 0x03, 0x01}, data)
+	require.Equal(t, "1.3.6.1.4.1.2021.10.1.3.1", decodeOID(data))
+}
+
+func TestTLVLongForm(t *testing.T) {
+	t.Parallel()
+	value := bytes.Repeat([]byte{0xab}, 300)
+	data := encodeTLV(tagOctetString, value)
+	require.Equal(t, []byte{tagOctetString, 0x82, 0x01, 0x2c}, data[:4])
+
+	tag, result, rest, err := parseTLV(append(data, 0x01))
+	require.NoError(t, err)
+	require.Equal(t, byte(tagOctetString), tag)
+	require.Equal(t, value, result)
+	require.Equal(t, []byte{0x01}, rest)
+}
+
+func TestParseTLVError(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{name: "Empty"},
+		{name: "TruncatedValue", data: []byte{tagOctetString, 0x05, 0x01}},
+		{name: "TruncatedLength", data: []byte{tagOctetString, 0x82, 0x01}},
+		{name: "TooLongLength", data: []byte{tagOctetString, 0x85, 0x01, 0x01, 0x01, 0x01, 0x01}},
+	}
+	for _, tt := range tests {
+		_, _, _, err := parseTLV(tt.data)
+		require.ErrorIs(t, err, errMessage, tt.name)
+	}
+}
+
+func TestParseGetResponse(t *testing.T) {
+	t.Parallel()
+	data := getResponseMessage(1, "public", 42, map[string]string{
+		"1.3.6.1.2.1.1.1.0": "Linux router 5.10",
+		"1.3.6.1.2.1.1.5.0": "router",
+	})
+
+	resp, err := parseGetResponse(data)
+	require.NoError(t, err)
+	require.Equal(t, 1, resp.version)
+	require.Equal(t, "public", resp.community)
+	require.Equal(t, int32(42), resp.requestID)
+	require.Equal(t, "Linux router 5.10", resp.stringValue(oidSysDescr))
+	require.Equal(t, "router", resp.stringValue(oidSysName))
+}
+
+func TestParseGetResponseNoSuchObject(t *testing.T) {
+	t.Parallel()
+	varbind := encodeTLV(tagSequence, append(encodeTLV(tagOID, encodeOID(oidSysName)), 0x80, 0x00))
+	data := responseMessage(1, "public", 7, varbind)
+
+	resp, err := parseGetResponse(data)
+	require.NoError(t, err)
+	require.Empty(t, resp.stringValue(oidSysName))
+}
+
+func TestParseGetResponseRequest(t *testing.T) {
+	t.Parallel()
+	_, err := parseGetResponse(getRequest(1, "public", 1, oidSysDescr))
+	require.ErrorIs(t, err, errMessage)
+}
@@ -0,0 +1,212 @@
+package snmp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+const (
+	ScanType = "snmp"
+
+	Version1  = "1"
+	Version2c = "2c"
+
+	defaultDataTimeout = 1 * time.Second
+	defaultRetries     = 1
+	// maxMessageSize is the UDP payload size of the Ethernet frame, responses with two variables are much smaller
+	maxMessageSize = 1472
+)
+
+// DefaultCommunities are community strings that are often left unchanged on devices
+var DefaultCommunities = []string{"public", "private"}
+
+var ErrVersion = errors.New("invalid SNMP version: 1 or 2c required")
+
+var versions = map[string]int{
+	Version1:  0,
+	Version2c: 1,
+}
+
+type ScanResult struct {
+	ScanType string `json:"scan"`
+	IP       string `json:"ip"`
+	Port     uint16 `json:"port"`
+	Version  string `json:"version"`
+	// Communities are the community strings the device answered to
+	Communities []string `json:"communities"`
+	SysDescr    string   `json:"sys_descr,omitempty"`
+	SysName     string   `json:"sys_name,omitempty"`
+}
+
+func (r *ScanResult) String() string {
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "%-20s %-5d %-3s %s", r.IP, r.Port, r.Version, strings.Join(r.Communities, ","))
+	if len(r.SysName) > 0 {
+		fmt.Fprintf(&buf, " %q", r.SysName)
+	}
+	if len(r.SysDescr) > 0 {
+		fmt.Fprintf(&buf, " %q", r.SysDescr)
+	}
+	return buf.String()
+}
+
+func (r *ScanResult) ID() string {
+	return fmt.Sprintf("%s:%d", r.IP, r.Port)
+}
+
+func (r *ScanResult) MarshalJSON() ([]byte, error) {
+	// Type definition for the recursive call
+	type JScanResult ScanResult
+	// This works because JScanResult doesn't have a MarshalJSON function associated with it
+	return json.Marshal(JScanResult(*r))
+}
+
+// Scanner sends GET requests for sysDescr and sysName with each community string over UDP.
+// Agents silently drop requests with unknown communities, so only devices that answered are reported.
+type Scanner struct {
+	version     string
+	communities []string
+	dataTimeout time.Duration
+	retries     int
+}
+
+// Assert that snmp.Scanner conforms to the scan.Scanner interface
+var _ scan.Scanner = (*Scanner)(nil)
+
+type ScannerOption func(*Scanner)
+
+// WithVersion sets the SNMP version of requests, Version1 or Version2c
+func WithVersion(version string) ScannerOption {
+	return func(s *Scanner) {
+		s.version = version
+	}
+}
+
+// WithCommunities sets the community strings to try, DefaultCommunities are used by default
+func WithCommunities(communities []string) ScannerOption {
+	return func(s *Scanner) {
+		s.communities = communities
+	}
+}
+
+// WithDataTimeout sets the time to wait for a response to each request
+func WithDataTimeout(timeout time.Duration) ScannerOption {
+	return func(s *Scanner) {
+		s.dataTimeout = timeout
+	}
+}
+
+// WithRetries sets the number of additional requests with the same community if there is no response
+func WithRetries(retries int) ScannerOption {
+	return func(s *Scanner) {
+		s.retries = retries
+	}
+}
+
+func NewScanner(opts ...ScannerOption) (*Scanner, error) {
+	s := &Scanner{
+		version:     Version2c,
+		communities: DefaultCommunities,
+		dataTimeout: defaultDataTimeout,
+		retries:     defaultRetries,
+	}
+	for _, o := range opts {
+		o(s)
+	}
+	if _, ok := versions[s.version]; !ok {
+		return nil, ErrVersion
+	}
+	return s, nil
+}
+
+func (s *Scanner) Scan(ctx context.Context, r *scan.Request) (scan.Result, error) {
+	result, err := s.scanTarget(ctx, r)
+	return result, scan.WrapError(err)
+}
+
+func (s *Scanner) scanTarget(ctx context.Context, r *scan.Request) (result scan.Result, err error) {
+	addr := net.JoinHostPort(r.DstIP.String(), strconv.Itoa(int(r.DstPort)))
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "udp", addr)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	var res *ScanResult
+	for _, community := range s.communities {
+		var resp *getResponse
+		if resp, err = s.get(ctx, conn, community); err != nil {
+			return nil, err
+		}
+		if resp == nil {
+			continue
+		}
+		if res == nil {
+			res = &ScanResult{
+				ScanType: ScanType,
+				IP:       r.DstIP.String(),
+				Port:     r.DstPort,
+				Version:  s.version,
+				SysDescr: resp.stringValue(oidSysDescr),
+				SysName:  resp.stringValue(oidSysName),
+			}
+		}
+		res.Communities = append(res.Communities, community)
+	}
+	if res == nil {
+		return nil, nil
+	}
+	return res, nil
+}
+
+// get sends the request with the community and waits for the response, nil response means no answer
+func (s *Scanner) get(ctx context.Context, conn net.Conn, community string) (*getResponse, error) {
+	buf := make([]byte, maxMessageSize)
+	for i := 0; i <= s.retries; i++ {
+		requestID := rand.Int31()
+		if _, err := conn.Write(getRequest(versions[s.version], community, requestID, oidSysDescr, oidSysName)); err != nil {
+			return nil, err
+		}
+		if err := conn.SetReadDeadline(time.Now().Add(s.dataTimeout)); err != nil {
+			return nil, err
+		}
+		resp, err := readResponse(conn, buf, requestID)
+		if err == nil {
+			return resp, nil
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		var netErr net.Error
+		// ICMP port unreachable and other errors are not retried
+		if !errors.As(err, &netErr) || !netErr.Timeout() {
+			return nil, err
+		}
+	}
+	return nil, nil
+}
+
+// readResponse reads datagrams until the response to the request arrives, other datagrams are skipped
+func readResponse(conn net.Conn, buf []byte, requestID int32) (*getResponse, error) {
+	for {
+		n, err := conn.Read(buf)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := parseGetResponse(buf[:n])
+		if err != nil || resp.requestID != requestID {
+			continue
+		}
+		return resp, nil
+	}
+}
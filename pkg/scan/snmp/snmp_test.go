@@ -0,0 +1,185 @@
+package snmp
+
+import (
+	"context"
+	"net"
+	"sort"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+func responseMessage(version int, community string, requestID int32, varbinds []byte) []byte {
+	var pdu []byte
+	pdu = append(pdu, encodeTLV(tagInteger, encodeInteger(int64(requestID)))...)
+	pdu = append(pdu, encodeTLV(tagInteger, encodeInteger(0))...)
+	pdu = append(pdu, encodeTLV(tagInteger, encodeInteger(0))...)
+	pdu = append(pdu, encodeTLV(tagSequence, varbinds)...)
+	var msg []byte
+	msg = append(msg, encodeTLV(tagInteger, encodeInteger(int64(version)))...)
+	msg = append(msg, encodeTLV(tagOctetString, []byte(community))...)
+	msg = append(msg, encodeTLV(tagGetResponse, pdu)...)
+	return encodeTLV(tagSequence, msg)
+}
+
+func getResponseMessage(version int, community string, requestID int32, values map[string]string) []byte {
+	oids := make([]string, 0, len(values))
+	for oid := range values {
+		oids = append(oids, oid)
+	}
+	sort.Strings(oids)
+	var varbinds []byte
+	for _, oid := range oids {
+		varbinds = append(varbinds, encodeTLV(tagSequence,
+			append(encodeTLV(tagOID, encodeOID(parseOID(oid))), encodeTLV(tagOctetString, []byte(values[oid]))...))...)
+	}
+	return responseMessage(version, community, requestID, varbinds)
+}
+
+func parseOID(oid string) (result []int) {
+	arc := 0
+	for _, c := range oid + "." {
+		if c == '.' {
+			result = append(result, arc)
+			arc = 0
+			continue
+		}
+		arc = arc*10 + int(c-'0')
+	}
+	return
+}
+
+type fakeAgent struct {
+	conn      net.PacketConn
+	community string
+	// drops is the number of first requests that are not answered
+	drops    int32
+	requests int32
+}
+
+// startFakeAgent starts UDP SNMP agent that answers requests with the community
+func startFakeAgent(t *testing.T, agent *fakeAgent) *scan.Request {
+	t.Helper()
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	agent.conn = conn
+	go agent.serve()
+	addr := conn.LocalAddr().(*net.UDPAddr)
+	return &scan.Request{DstIP: addr.IP, DstPort: uint16(addr.Port)}
+}
+
+func (a *fakeAgent) serve() {
+	buf := make([]byte, maxMessageSize)
+	for {
+		n, addr, err := a.conn.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+		if atomic.AddInt32(&a.requests, 1) <= a.drops {
+			continue
+		}
+		version, community, requestID, ok := parseGetRequest(buf[:n])
+		if !ok || community != a.community {
+			continue
+		}
+		// a stale response is sent first to check that responses are matched by request ID
+		_, _ = a.conn.WriteTo(getResponseMessage(version, community, requestID+1, nil), addr)
+		_, _ = a.conn.WriteTo(getResponseMessage(version, community, requestID, map[string]string{
+			"1.3.6.1.2.1.1.1.0": "Linux router 5.10",
+			"1.3.6.1.2.1.1.5.0": "router",
+		}), addr)
+	}
+}
+
+func parseGetRequest(data []byte) (version int, community string, requestID int32, ok bool) {
+	msg, _, err := expectTLV(data, tagSequence)
+	if err != nil {
+		return
+	}
+	var value []byte
+	if value, msg, err = expectTLV(msg, tagInteger); err != nil {
+		return
+	}
+	version = int(decodeInteger(value))
+	if value, msg, err = expectTLV(msg, tagOctetString); err != nil {
+		return
+	}
+	community = string(value)
+	var pdu []byte
+	if pdu, _, err = expectTLV(msg, tagGetRequest); err != nil {
+		return
+	}
+	if value, _, err = expectTLV(pdu, tagInteger); err != nil {
+		return
+	}
+	return version, community, int32(decodeInteger(value)), true
+}
+
+func TestScan(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name    string
+		version string
+		drops   int32
+	}{
+		{
+			name:    "Version2c",
+			version: Version2c,
+		},
+		{
+			name:    "Version1",
+			version: Version1,
+		},
+		{
+			// both requests with the public community and the first one with the private community are dropped
+			name:    "Retry",
+			version: Version2c,
+			drops:   3,
+		},
+	}
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			req := startFakeAgent(t, &fakeAgent{community: "private", drops: tt.drops})
+			s, err := NewScanner(WithVersion(tt.version), WithDataTimeout(200*time.Millisecond))
+			require.NoError(t, err)
+
+			result, err := s.Scan(context.Background(), req)
+			require.NoError(t, err)
+			require.Equal(t, &ScanResult{
+				ScanType:    ScanType,
+				IP:          req.DstIP.String(),
+				Port:        req.DstPort,
+				Version:     tt.version,
+				Communities: []string{"private"},
+				SysDescr:    "Linux router 5.10",
+				SysName:     "router",
+			}, result)
+		})
+	}
+}
+
+func TestScanNoResponse(t *testing.T) {
+	t.Parallel()
+	agent := &fakeAgent{community: "secret"}
+	req := startFakeAgent(t, agent)
+	s, err := NewScanner(WithCommunities([]string{"public", "private"}),
+		WithDataTimeout(50*time.Millisecond), WithRetries(2))
+	require.NoError(t, err)
+
+	result, err := s.Scan(context.Background(), req)
+	require.NoError(t, err)
+	require.Nil(t, result)
+	require.Equal(t, int32(6), atomic.LoadInt32(&agent.requests))
+}
+
+func TestNewScannerInvalidVersion(t *testing.T) {
+	t.Parallel()
+	_, err := NewScanner(WithVersion("3"))
+	require.ErrorIs(t, err, ErrVersion)
+}
@@ -0,0 +1,90 @@
+package socks4
+
+import (
+	"encoding/binary"
+	"io"
+)
+
+const (
+	CmdConnect = 1
+
+	ReplyGranted        = 90
+	ReplyRejected       = 91
+	ReplyIdentdFailed   = 92
+	ReplyIdentdMismatch = 93
+)
+
+// ConnectRequest is a request to establish a TCP connection to the destination.
+// From SOCKS4 protocol specification:
+// +----+----+----+----+----+----+----+----+----+----+....+----+
+// | VN | CD | DSTPORT |      DSTIP        | USERID       |NULL|
+// +----+----+----+----+----+----+----+----+----+----+....+----+
+// | 1  | 1  |    2    |         4         | variable     | 1  |
+// +----+----+----+----+----+----+----+----+----+----+....+----+
+// SOCKS4a extension sets DSTIP to 0.0.0.x with nonzero x and appends
+// the destination domain name terminated by NULL after USERID.
+type ConnectRequest struct {
+	DstPort uint16
+	DstIP   [4]byte
+	UserID  string
+	// Domain is the destination name resolved by the SOCKS4a server
+	Domain string
+}
+
+func NewConnectRequest(ip [4]byte, port uint16) *ConnectRequest {
+	return &ConnectRequest{DstIP: ip, DstPort: port}
+}
+
+func NewDomainConnectRequest(domain string, port uint16) *ConnectRequest {
+	return &ConnectRequest{DstIP: [4]byte{0, 0, 0, 1}, DstPort: port, Domain: domain}
+}
+
+func (r *ConnectRequest) Len() int64 {
+	n := 8 + int64(len(r.UserID)) + 1
+	if len(r.Domain) > 0 {
+		n += int64(len(r.Domain)) + 1
+	}
+	return n
+}
+
+func (r *ConnectRequest) WriteTo(w io.Writer) (int64, error) {
+	buf := make([]byte, 0, r.Len())
+	buf = append(buf, SOCKSVersion, CmdConnect)
+	buf = append(buf, byte(r.DstPort>>8), byte(r.DstPort))
+	buf = append(buf, r.DstIP[:]...)
+	buf = append(buf, r.UserID...)
+	buf = append(buf, 0)
+	if len(r.Domain) > 0 {
+		buf = append(buf, r.Domain...)
+		buf = append(buf, 0)
+	}
+	n, err := w.Write(buf)
+	return int64(n), err
+}
+
+// Reply is a reply to the connect request.
+// From SOCKS4 protocol specification:
+// +----+----+----+----+----+----+----+----+
+// | VN | CD | DSTPORT |      DSTIP        |
+// +----+----+----+----+----+----+----+----+
+// | 1  | 1  |    2    |         4         |
+// +----+----+----+----+----+----+----+----+
+type Reply struct {
+	Ver     byte // version of the reply code, must be 0
+	Code    byte // result code
+	DstPort uint16
+	DstIP   [4]byte
+}
+
+func (*Reply) Len() int64 {
+	return 8
+}
+
+func (r *Reply) ReadFrom(in io.Reader) (int64, error) {
+	return r.Len(), binary.Read(in, binary.BigEndian, r)
+}
+
+// Valid returns true if the reply is sent by a SOCKS4 server
+func (r *Reply) Valid() bool {
+	return r.Ver == 0 && r.Code >= ReplyGranted && r.Code <= ReplyIdentdMismatch
+}
@@ -0,0 +1,90 @@
+package socks4
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteConnectRequest(t *testing.T) {
+	tests := []struct {
+		name     string
+		request  *ConnectRequest
+		expected []byte
+	}{
+		{
+			name:     "ip",
+			request:  NewConnectRequest([4]byte{192, 168, 0, 1}, 1080),
+			expected: []byte{4, 1, 0x04, 0x38, 192, 168, 0, 1, 0},
+		},
+		{
+			name:     "userID",
+			request:  &ConnectRequest{DstIP: [4]byte{10, 0, 0, 1}, DstPort: 80, UserID: "sx"},
+			expected: []byte{4, 1, 0, 80, 10, 0, 0, 1, 's', 'x', 0},
+		},
+		{
+			name:     "domain",
+			request:  NewDomainConnectRequest("localhost", 80),
+			expected: append([]byte{4, 1, 0, 80, 0, 0, 0, 1, 0}, append([]byte("localhost"), 0)...),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			n, err := tt.request.WriteTo(&buf)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, buf.Bytes())
+			require.Equal(t, tt.request.Len(), n)
+		})
+	}
+}
+
+func TestReadReply(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    []byte
+		expected *Reply
+		valid    bool
+	}{
+		{
+			name:     "granted",
+			input:    []byte{0, 90, 0, 80, 10, 0, 0, 1},
+			expected: &Reply{Ver: 0, Code: ReplyGranted, DstPort: 80, DstIP: [4]byte{10, 0, 0, 1}},
+			valid:    true,
+		},
+		{
+			name:     "rejected",
+			input:    []byte{0, 91, 0, 0, 0, 0, 0, 0},
+			expected: &Reply{Ver: 0, Code: ReplyRejected},
+			valid:    true,
+		},
+		{
+			name:     "invalidVersion",
+			input:    []byte{5, 90, 0, 0, 0, 0, 0, 0},
+			expected: &Reply{Ver: 5, Code: ReplyGranted},
+		},
+		{
+			name:     "invalidCode",
+			input:    []byte{0, 1, 0, 0, 0, 0, 0, 0},
+			expected: &Reply{Ver: 0, Code: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reply := &Reply{}
+			_, err := reply.ReadFrom(bytes.NewReader(tt.input))
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, reply)
+			require.Equal(t, tt.valid, reply.Valid())
+		})
+	}
+}
+
+func TestReadReplyShort(t *testing.T) {
+	reply := &Reply{}
+	_, err := reply.ReadFrom(bytes.NewReader([]byte{0, 90, 0}))
+	require.Error(t, err)
+}
@@ -0,0 +1,189 @@
+package socks4
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+const (
+	ScanType     = "socks"
+	SOCKSVersion = 4
+
+	defaultDialTimeout = 2 * time.Second
+	defaultDataTimeout = 2 * time.Second
+	// domainTarget is the destination name of SOCKS4a connect requests
+	domainTarget = "localhost"
+)
+
+type ScanResult struct {
+	ScanType string `json:"scan"`
+	Version  int    `json:"version"`
+	IP       string `json:"ip"`
+	Port     uint16 `json:"port"`
+	// SOCKS4a is set if the server resolved the destination name
+	SOCKS4a bool `json:"socks4a,omitempty"`
+	// Granted is set if the server accepted the connect request without authentication
+	Granted bool `json:"granted"`
+}
+
+func (r *ScanResult) String() string {
+	proto := "socks4"
+	if r.SOCKS4a {
+		proto = "socks4a"
+	}
+	return fmt.Sprintf("%-20s %-5d %-7s %t", r.IP, r.Port, proto, r.Granted)
+}
+
+func (r *ScanResult) ID() string {
+	return fmt.Sprintf("%s:%d", r.IP, r.Port)
+}
+
+// ProxyURL returns the URL of the proxy, nil is returned if the server didn't accept the connect request
+func (r *ScanResult) ProxyURL() *url.URL {
+	if !r.Granted {
+		return nil
+	}
+	scheme := "socks4"
+	if r.SOCKS4a {
+		scheme = "socks4a"
+	}
+	return &url.URL{Scheme: scheme, Host: net.JoinHostPort(r.IP, strconv.Itoa(int(r.Port)))}
+}
+
+func (r *ScanResult) MarshalJSON() ([]byte, error) {
+	// Type definition for the recursive call
+	type JScanResult ScanResult
+	// This works because JScanResult doesn't have a MarshalJSON function associated with it
+	return json.Marshal(JScanResult(*r))
+}
+
+type Scanner struct {
+	dataTimeout time.Duration
+	dialer      *net.Dialer
+	socks4a     bool
+}
+
+// Assert that socks4.Scanner conforms to the scan.Scanner interface
+var _ scan.Scanner = (*Scanner)(nil)
+
+type ScannerOption func(*Scanner)
+
+func WithDialTimeout(timeout time.Duration) ScannerOption {
+	return func(s *Scanner) {
+		s.dialer.Timeout = timeout
+	}
+}
+
+func WithDataTimeout(timeout time.Duration) ScannerOption {
+	return func(s *Scanner) {
+		s.dataTimeout = timeout
+	}
+}
+
+// WithSOCKS4a enables SOCKS4a connect requests with the destination name instead of IP address
+func WithSOCKS4a() ScannerOption {
+	return func(s *Scanner) {
+		s.socks4a = true
+	}
+}
+
+func NewScanner(opts ...ScannerOption) *Scanner {
+	s := &Scanner{
+		dialer: &net.Dialer{
+			Timeout: defaultDialTimeout,
+		},
+		dataTimeout: defaultDataTimeout,
+	}
+	for _, o := range opts {
+		o(s)
+	}
+	return s
+}
+
+// Scan sends the connect request to the proxy itself, so the destination is reachable
+// from the server. Any valid SOCKS4 reply is a result, granted or not.
+func (s *Scanner) Scan(ctx context.Context, r *scan.Request) (scan.Result, error) {
+	result, err := s.scanTarget(ctx, r)
+	return result, scan.WrapError(err)
+}
+
+func (s *Scanner) scanTarget(ctx context.Context, r *scan.Request) (result scan.Result, err error) {
+	ip4 := r.DstIP.To4()
+	if ip4 == nil {
+		return nil, fmt.Errorf("socks4: IPv4 address required: %s", r.DstIP)
+	}
+	var conn net.Conn
+	if conn, err = s.dialer.DialContext(ctx, "tcp", fmt.Sprintf("%s:%d", r.DstIP, r.DstPort)); err != nil {
+		return
+	}
+	defer conn.Close()
+	// see socks5.Scanner for details
+	if err = conn.(*net.TCPConn).SetLinger(1); err != nil {
+		return
+	}
+
+	done := make(chan interface{})
+	defer close(done)
+	go func() {
+		select {
+		// return on ctx.Done without waiting read/write timeout
+		case <-ctx.Done():
+			conn.Close()
+		case <-done:
+		}
+	}()
+	sconn := &socksConn{conn: conn, timeout: s.dataTimeout}
+
+	var dstIP [4]byte
+	copy(dstIP[:], ip4)
+	req := NewConnectRequest(dstIP, r.DstPort)
+	if s.socks4a {
+		req = NewDomainConnectRequest(domainTarget, r.DstPort)
+	}
+	if _, err = req.WriteTo(sconn); err != nil {
+		return
+	}
+
+	reply := &Reply{}
+	if _, err = reply.ReadFrom(sconn); err != nil {
+		return
+	}
+	if !reply.Valid() {
+		return
+	}
+	granted := reply.Code == ReplyGranted
+	return &ScanResult{
+		ScanType: ScanType,
+		Version:  SOCKSVersion,
+		IP:       r.DstIP.String(),
+		Port:     r.DstPort,
+		SOCKS4a:  s.socks4a && granted,
+		Granted:  granted,
+	}, nil
+}
+
+type socksConn struct {
+	conn    net.Conn
+	timeout time.Duration
+}
+
+func (c *socksConn) Read(p []byte) (n int, err error) {
+	if err = c.conn.SetReadDeadline(time.Now().Add(c.timeout)); err != nil {
+		return
+	}
+	return c.conn.Read(p)
+}
+
+func (c *socksConn) Write(p []byte) (n int, err error) {
+	if err = c.conn.SetWriteDeadline(time.Now().Add(c.timeout)); err != nil {
+		return
+	}
+	return c.conn.Write(p)
+}
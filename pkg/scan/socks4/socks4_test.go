@@ -0,0 +1,132 @@
+package socks4
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+// startServer starts a fake SOCKS4 server that replies with the given code,
+// requests with domain names are rejected unless socks4a is set
+func startServer(t *testing.T, code byte, socks4a bool) *net.TCPAddr {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { ln.Close() })
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				r := bufio.NewReader(conn)
+				header := make([]byte, 8)
+				if _, err := io.ReadFull(r, header); err != nil {
+					return
+				}
+				if _, err := r.ReadString(0); err != nil {
+					return
+				}
+				replyCode := code
+				if header[4] == 0 && header[5] == 0 && header[6] == 0 && header[7] != 0 {
+					if _, err := r.ReadString(0); err != nil {
+						return
+					}
+					if !socks4a {
+						replyCode = ReplyRejected
+					}
+				}
+				_, _ = conn.Write([]byte{0, replyCode, 0, 0, 0, 0, 0, 0})
+			}()
+		}
+	}()
+	return ln.Addr().(*net.TCPAddr)
+}
+
+func TestScan(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		code     byte
+		socks4a  bool
+		opts     []ScannerOption
+		expected *ScanResult
+	}{
+		{
+			name:     "granted",
+			code:     ReplyGranted,
+			expected: &ScanResult{Granted: true},
+		},
+		{
+			name:     "rejected",
+			code:     ReplyRejected,
+			expected: &ScanResult{},
+		},
+		{
+			name:     "socks4a",
+			code:     ReplyGranted,
+			socks4a:  true,
+			opts:     []ScannerOption{WithSOCKS4a()},
+			expected: &ScanResult{Granted: true, SOCKS4a: true},
+		},
+		{
+			name:     "socks4aUnsupported",
+			code:     ReplyGranted,
+			opts:     []ScannerOption{WithSOCKS4a()},
+			expected: &ScanResult{},
+		},
+	}
+
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			addr := startServer(t, tt.code, tt.socks4a)
+			s := NewScanner(append(tt.opts, WithDataTimeout(time.Second))...)
+
+			result, err := s.Scan(context.Background(), &scan.Request{DstIP: addr.IP, DstPort: uint16(addr.Port)})
+			require.NoError(t, err)
+
+			tt.expected.ScanType = ScanType
+			tt.expected.Version = SOCKSVersion
+			tt.expected.IP = addr.IP.String()
+			tt.expected.Port = uint16(addr.Port)
+			require.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestScanInvalidReply(t *testing.T) {
+	t.Parallel()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		_, _ = conn.Write([]byte("HTTP/1.1 400 Bad Request\r\n\r\n"))
+	}()
+	addr := ln.Addr().(*net.TCPAddr)
+
+	result, err := NewScanner().Scan(context.Background(), &scan.Request{DstIP: addr.IP, DstPort: uint16(addr.Port)})
+	require.NoError(t, err)
+	require.Nil(t, result)
+}
+
+func TestScanIPv6(t *testing.T) {
+	t.Parallel()
+	_, err := NewScanner().Scan(context.Background(), &scan.Request{DstIP: net.IPv6loopback, DstPort: 1080})
+	require.Error(t, err)
+}
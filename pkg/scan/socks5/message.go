@@ -2,10 +2,21 @@ package socks5
 
 import (
 	"encoding/binary"
+	"errors"
 	"io"
 )
 
-const MethodNoAuth = 0
+const (
+	MethodNoAuth       = 0
+	MethodGSSAPI       = 1
+	MethodUserPass     = 2
+	MethodNoAcceptable = 0xFF
+
+	UserPassVersion = 1
+	UserPassSuccess = 0
+)
+
+var errUserPassLen = errors.New("invalid credentials: username and password must not exceed 255 bytes")
 
 // MethodRequest is a negotiation request for the authentication method to be used.
 // It is the initial message that the client sends to the SOCKS5 server.
@@ -61,3 +72,63 @@ func (*MethodReply) Len() int64 {
 func (r *MethodReply) ReadFrom(in io.Reader) (int64, error) {
 	return r.Len(), binary.Read(in, binary.BigEndian, r)
 }
+
+// UserPassRequest is a username/password authentication request
+// sent after the server has selected MethodUserPass.
+// From RFC1929:
+// +----+------+----------+------+----------+
+// |VER | ULEN |  UNAME   | PLEN |  PASSWD  |
+// +----+------+----------+------+----------+
+// | 1  |  1   | 1 to 255 |  1   | 1 to 255 |
+// +----+------+----------+------+----------+
+type UserPassRequest struct {
+	Ver      byte // version of the subnegotiation
+	Username string
+	Password string
+}
+
+func NewUserPassRequest(username, password string) *UserPassRequest {
+	return &UserPassRequest{
+		Ver:      UserPassVersion,
+		Username: username,
+		Password: password,
+	}
+}
+
+func (r *UserPassRequest) Len() int64 {
+	return 3 + int64(len(r.Username)) + int64(len(r.Password))
+}
+
+func (r *UserPassRequest) WriteTo(w io.Writer) (int64, error) {
+	if len(r.Username) > 255 || len(r.Password) > 255 {
+		return 0, errUserPassLen
+	}
+	buf := make([]byte, 0, r.Len())
+	buf = append(buf, r.Ver)
+	buf = append(buf, byte(len(r.Username)))
+	buf = append(buf, r.Username...)
+	buf = append(buf, byte(len(r.Password)))
+	buf = append(buf, r.Password...)
+	n, err := w.Write(buf)
+	return int64(n), err
+}
+
+// UserPassReply is a username/password authentication reply.
+// From RFC1929:
+// +----+--------+
+// |VER | STATUS |
+// +----+--------+
+// | 1  |   1    |
+// +----+--------+
+type UserPassReply struct {
+	Ver    byte // version of the subnegotiation
+	Status byte // UserPassSuccess indicates success, any other value is a failure
+}
+
+func (*UserPassReply) Len() int64 {
+	return 2
+}
+
+func (r *UserPassReply) ReadFrom(in io.Reader) (int64, error) {
+	return r.Len(), binary.Read(in, binary.BigEndian, r)
+}
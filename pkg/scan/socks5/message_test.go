@@ -2,6 +2,7 @@ package socks5
 
 import (
 	"bytes"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -67,3 +68,49 @@ func TestReadMethodReply(t *testing.T) {
 		})
 	}
 }
+
+func TestWriteUserPassRequest(t *testing.T) {
+	tests := []struct {
+		name     string
+		request  *UserPassRequest
+		expected []byte
+	}{
+		{
+			name:     "credentials",
+			request:  NewUserPassRequest("usr", "pw"),
+			expected: []byte{1, 3, 'u', 's', 'r', 2, 'p', 'w'},
+		},
+		{
+			name:     "emptyPassword",
+			request:  NewUserPassRequest("usr", ""),
+			expected: []byte{1, 3, 'u', 's', 'r', 0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			_, err := tt.request.WriteTo(&buf)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, buf.Bytes())
+		})
+	}
+}
+
+func TestWriteUserPassRequestTooLong(t *testing.T) {
+	var buf bytes.Buffer
+	_, err := NewUserPassRequest(strings.Repeat("a", 256), "pw").WriteTo(&buf)
+	require.Error(t, err)
+	require.Zero(t, buf.Len())
+}
+
+func TestReadUserPassReply(t *testing.T) {
+	var buf bytes.Buffer
+	_, err := buf.Write([]byte{UserPassVersion, 1})
+	require.NoError(t, err)
+
+	reply := &UserPassReply{}
+	_, err = reply.ReadFrom(&buf)
+	require.NoError(t, err)
+	require.Equal(t, &UserPassReply{Ver: UserPassVersion, Status: 1}, reply)
+}
@@ -4,7 +4,11 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net"
+	"net/url"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/v-byte-cpu/sx/pkg/scan"
@@ -18,22 +22,54 @@ const (
 	defaultDataTimeout = 2 * time.Second
 )
 
+const (
+	AuthMethodNoAuth   = "noauth"
+	AuthMethodGSSAPI   = "gssapi"
+	AuthMethodUserPass = "userpass"
+)
+
+// authMethods are offered to the server in order of preference
+var authMethods = []byte{MethodNoAuth, MethodGSSAPI, MethodUserPass}
+
+var authMethodNames = map[byte]string{
+	MethodNoAuth:   AuthMethodNoAuth,
+	MethodGSSAPI:   AuthMethodGSSAPI,
+	MethodUserPass: AuthMethodUserPass,
+}
+
 type ScanResult struct {
 	ScanType string `json:"scan"`
 	Version  int    `json:"version"`
 	IP       string `json:"ip"`
 	Port     uint16 `json:"port"`
-	Auth     bool   `json:"auth,omitempty"`
+	// Auth is set if the server requires authentication
+	Auth bool `json:"auth,omitempty"`
+	// Methods are all authentication methods accepted by the server
+	Methods  []string `json:"methods,omitempty"`
+	Username string   `json:"username,omitempty"`
+	Password string   `json:"password,omitempty"`
 }
 
 func (r *ScanResult) String() string {
-	return fmt.Sprintf("%-20s %-5d", r.IP, r.Port)
+	return fmt.Sprintf("%-20s %-5d %s", r.IP, r.Port, strings.Join(r.Methods, ","))
 }
 
 func (r *ScanResult) ID() string {
 	return fmt.Sprintf("%s:%d", r.IP, r.Port)
 }
 
+// ProxyURL returns the URL of the proxy with found credentials,
+// nil is returned if the server requires authentication and credentials were not found
+func (r *ScanResult) ProxyURL() *url.URL {
+	u := &url.URL{Scheme: "socks5", Host: net.JoinHostPort(r.IP, strconv.Itoa(int(r.Port)))}
+	if len(r.Username) > 0 {
+		u.User = url.UserPassword(r.Username, r.Password)
+	} else if r.Auth {
+		return nil
+	}
+	return u
+}
+
 func (r *ScanResult) MarshalJSON() ([]byte, error) {
 	// Type definition for the recursive call
 	type JScanResult ScanResult
@@ -41,9 +77,16 @@ func (r *ScanResult) MarshalJSON() ([]byte, error) {
 	return json.Marshal(JScanResult(*r))
 }
 
+// Credentials are username and password for the username/password authentication
+type Credentials struct {
+	Username string
+	Password string
+}
+
 type Scanner struct {
 	dataTimeout time.Duration
 	dialer      *net.Dialer
+	credentials []Credentials
 }
 
 // Assert that socks5.Scanner conforms to the scan.Scanner interface
@@ -63,6 +106,14 @@ func WithDataTimeout(timeout time.Duration) ScannerOption {
 	}
 }
 
+// WithCredentials sets credentials that are tried one by one
+// on servers accepting the username/password authentication
+func WithCredentials(credentials []Credentials) ScannerOption {
+	return func(s *Scanner) {
+		s.credentials = credentials
+	}
+}
+
 func NewScanner(opts ...ScannerOption) *Scanner {
 	s := &Scanner{
 		dialer: &net.Dialer{
@@ -76,7 +127,93 @@ func NewScanner(opts ...ScannerOption) *Scanner {
 	return s
 }
 
-func (s *Scanner) Scan(ctx context.Context, r *scan.Request) (result scan.Result, err error) {
+func (s *Scanner) Scan(ctx context.Context, r *scan.Request) (scan.Result, error) {
+	result, err := s.scanTarget(ctx, r)
+	return result, scan.WrapError(err)
+}
+
+func (s *Scanner) scanTarget(ctx context.Context, r *scan.Request) (result scan.Result, err error) {
+	var method byte
+	if method, err = s.negotiate(ctx, r, authMethods...); err != nil || method == MethodNoAcceptable {
+		return
+	}
+
+	// the server selects only one method, so other methods are checked on new connections
+	var methods []string
+	for _, m := range authMethods {
+		if m != method {
+			var selected byte
+			if selected, err = s.negotiate(ctx, r, m); err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				err = nil
+				continue
+			}
+			if selected != m {
+				continue
+			}
+		}
+		methods = append(methods, authMethodNames[m])
+	}
+
+	scanResult := &ScanResult{
+		ScanType: ScanType,
+		Version:  SOCKSVersion,
+		IP:       r.DstIP.String(),
+		Port:     r.DstPort,
+		Auth:     method != MethodNoAuth,
+		Methods:  methods,
+	}
+	if scanResult.Auth && hasMethod(methods, AuthMethodUserPass) {
+		var creds *Credentials
+		if creds, err = s.findCredentials(ctx, r); err != nil {
+			return
+		}
+		if creds != nil {
+			scanResult.Username = creds.Username
+			scanResult.Password = creds.Password
+		}
+	}
+	return scanResult, nil
+}
+
+// negotiate offers methods to the server and returns the selected one,
+// MethodNoAcceptable is returned for non-SOCKS5 servers as well
+func (s *Scanner) negotiate(ctx context.Context, r *scan.Request, methods ...byte) (method byte, err error) {
+	err = s.withConn(ctx, r, func(conn io.ReadWriter) (err error) {
+		method, err = negotiate(conn, methods...)
+		return
+	})
+	return
+}
+
+func (s *Scanner) findCredentials(ctx context.Context, r *scan.Request) (*Credentials, error) {
+	for i := range s.credentials {
+		creds := &s.credentials[i]
+		var ok bool
+		if err := s.withConn(ctx, r, func(conn io.ReadWriter) (err error) {
+			var method byte
+			if method, err = negotiate(conn, MethodUserPass); err != nil || method != MethodUserPass {
+				return
+			}
+			ok, err = authenticate(conn, creds)
+			return
+		}); err != nil {
+			// servers usually close the connection after a failed authentication
+			if ctx.Err() != nil {
+				return nil, err
+			}
+			continue
+		}
+		if ok {
+			return creds, nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *Scanner) withConn(ctx context.Context, r *scan.Request, f func(conn io.ReadWriter) error) (err error) {
 	var conn net.Conn
 	if conn, err = s.dialer.DialContext(ctx, "tcp", fmt.Sprintf("%s:%d", r.DstIP, r.DstPort)); err != nil {
 		return
@@ -103,27 +240,43 @@ func (s *Scanner) Scan(ctx context.Context, r *scan.Request) (result scan.Result
 		case <-done:
 		}
 	}()
-	sconn := &socksConn{conn: conn, timeout: s.dataTimeout}
+	return f(&socksConn{conn: conn, timeout: s.dataTimeout})
+}
 
-	req := NewMethodRequest(SOCKSVersion, MethodNoAuth)
-	if _, err = req.WriteTo(sconn); err != nil {
+func negotiate(conn io.ReadWriter, methods ...byte) (method byte, err error) {
+	req := NewMethodRequest(SOCKSVersion, methods...)
+	if _, err = req.WriteTo(conn); err != nil {
 		return
 	}
-
 	reply := &MethodReply{}
-	if _, err = reply.ReadFrom(sconn); err != nil {
+	if _, err = reply.ReadFrom(conn); err != nil {
+		return
+	}
+	if reply.Ver != SOCKSVersion {
+		return MethodNoAcceptable, nil
+	}
+	return reply.Method, nil
+}
+
+func authenticate(conn io.ReadWriter, creds *Credentials) (ok bool, err error) {
+	req := NewUserPassRequest(creds.Username, creds.Password)
+	if _, err = req.WriteTo(conn); err != nil {
+		return
+	}
+	reply := &UserPassReply{}
+	if _, err = reply.ReadFrom(conn); err != nil {
 		return
 	}
+	return reply.Ver == UserPassVersion && reply.Status == UserPassSuccess, nil
+}
 
-	if reply.Ver == SOCKSVersion && reply.Method == MethodNoAuth {
-		result = &ScanResult{
-			ScanType: ScanType,
-			Version:  SOCKSVersion,
-			IP:       r.DstIP.String(),
-			Port:     r.DstPort,
+func hasMethod(methods []string, method string) bool {
+	for _, m := range methods {
+		if m == method {
+			return true
 		}
 	}
-	return
+	return false
 }
 
 type socksConn struct {
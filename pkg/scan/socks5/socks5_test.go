@@ -0,0 +1,191 @@
+package socks5
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+// startServer starts a fake SOCKS5 server that selects the first offered method
+// from the methods list, username/password authentication succeeds only for creds
+func startServer(t *testing.T, methods []byte, creds *Credentials) *net.TCPAddr {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { ln.Close() })
+
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				header := make([]byte, 2)
+				if _, err := io.ReadFull(conn, header); err != nil {
+					return
+				}
+				offered := make([]byte, header[1])
+				if _, err := io.ReadFull(conn, offered); err != nil {
+					return
+				}
+				selected := selectMethod(methods, offered)
+				if _, err := conn.Write([]byte{SOCKSVersion, selected}); err != nil {
+					return
+				}
+				if selected != MethodUserPass {
+					return
+				}
+				username, password, err := readUserPass(conn)
+				if err != nil {
+					return
+				}
+				status := byte(1)
+				if creds != nil && creds.Username == username && creds.Password == password {
+					status = UserPassSuccess
+				}
+				_, _ = conn.Write([]byte{UserPassVersion, status})
+			}()
+		}
+	}()
+	return ln.Addr().(*net.TCPAddr)
+}
+
+func selectMethod(methods, offered []byte) byte {
+	for _, m := range methods {
+		for _, o := range offered {
+			if m == o {
+				return m
+			}
+		}
+	}
+	return MethodNoAcceptable
+}
+
+func readUserPass(r io.Reader) (username, password string, err error) {
+	header := make([]byte, 2)
+	if _, err = io.ReadFull(r, header); err != nil {
+		return
+	}
+	buf := make([]byte, header[1]+1)
+	if _, err = io.ReadFull(r, buf); err != nil {
+		return
+	}
+	username = string(buf[:header[1]])
+	passwd := make([]byte, buf[header[1]])
+	if _, err = io.ReadFull(r, passwd); err != nil {
+		return
+	}
+	return username, string(passwd), nil
+}
+
+func TestScan(t *testing.T) {
+	t.Parallel()
+	validCreds := Credentials{Username: "admin", Password: "secret"}
+	tests := []struct {
+		name     string
+		methods  []byte
+		opts     []ScannerOption
+		expected *ScanResult
+	}{
+		{
+			name:     "noAuth",
+			methods:  []byte{MethodNoAuth},
+			expected: &ScanResult{Methods: []string{AuthMethodNoAuth}},
+		},
+		{
+			name:     "noAuthAndUserPass",
+			methods:  []byte{MethodNoAuth, MethodUserPass},
+			expected: &ScanResult{Methods: []string{AuthMethodNoAuth, AuthMethodUserPass}},
+		},
+		{
+			name:    "userPassPreferred",
+			methods: []byte{MethodUserPass, MethodGSSAPI, MethodNoAuth},
+			expected: &ScanResult{Auth: true,
+				Methods: []string{AuthMethodNoAuth, AuthMethodGSSAPI, AuthMethodUserPass}},
+		},
+		{
+			name:     "gssapi",
+			methods:  []byte{MethodGSSAPI},
+			expected: &ScanResult{Auth: true, Methods: []string{AuthMethodGSSAPI}},
+		},
+		{
+			name:     "userPassWithoutCredentials",
+			methods:  []byte{MethodUserPass},
+			expected: &ScanResult{Auth: true, Methods: []string{AuthMethodUserPass}},
+		},
+		{
+			name:    "validCredentials",
+			methods: []byte{MethodUserPass},
+			opts: []ScannerOption{WithCredentials([]Credentials{
+				{Username: "admin", Password: "admin"}, validCreds, {Username: "root", Password: "root"},
+			})},
+			expected: &ScanResult{Auth: true, Methods: []string{AuthMethodUserPass},
+				Username: validCreds.Username, Password: validCreds.Password},
+		},
+		{
+			name:    "invalidCredentials",
+			methods: []byte{MethodUserPass},
+			opts: []ScannerOption{WithCredentials([]Credentials{
+				{Username: "admin", Password: "admin"},
+			})},
+			expected: &ScanResult{Auth: true, Methods: []string{AuthMethodUserPass}},
+		},
+		{
+			name:    "noAuthWithCredentials",
+			methods: []byte{MethodNoAuth, MethodUserPass},
+			opts:    []ScannerOption{WithCredentials([]Credentials{validCreds})},
+			expected: &ScanResult{
+				Methods: []string{AuthMethodNoAuth, AuthMethodUserPass}},
+		},
+	}
+
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			addr := startServer(t, tt.methods, &validCreds)
+			s := NewScanner(append([]ScannerOption{WithDialTimeout(time.Second),
+				WithDataTimeout(time.Second)}, tt.opts...)...)
+
+			result, err := s.Scan(context.Background(), &scan.Request{DstIP: addr.IP, DstPort: uint16(addr.Port)})
+			require.NoError(t, err)
+
+			expected := *tt.expected
+			expected.ScanType = ScanType
+			expected.Version = SOCKSVersion
+			expected.IP = addr.IP.String()
+			expected.Port = uint16(addr.Port)
+			require.Equal(t, &expected, result)
+		})
+	}
+}
+
+func TestScanNoAcceptableMethods(t *testing.T) {
+	t.Parallel()
+	addr := startServer(t, nil, nil)
+	s := NewScanner(WithDialTimeout(time.Second), WithDataTimeout(time.Second))
+
+	result, err := s.Scan(context.Background(), &scan.Request{DstIP: addr.IP, DstPort: uint16(addr.Port)})
+	require.NoError(t, err)
+	require.Nil(t, result)
+}
+
+func TestScanClosedPort(t *testing.T) {
+	t.Parallel()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := ln.Addr().(*net.TCPAddr)
+	ln.Close()
+
+	s := NewScanner(WithDialTimeout(time.Second), WithDataTimeout(time.Second))
+	result, err := s.Scan(context.Background(), &scan.Request{DstIP: addr.IP, DstPort: uint16(addr.Port)})
+	require.Error(t, err)
+	require.Nil(t, result)
+}
@@ -0,0 +1,65 @@
+package ssdp
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+// maxMX is the maximum MX value allowed by the specification
+const maxMX = 5
+
+var errResponse = scan.NewError(scan.ErrParse, errors.New("invalid SSDP response"))
+
+type searchResponse struct {
+	location string
+	server   string
+	st       string
+}
+
+// searchRequest returns the M-SEARCH request, see UPnP Device Architecture 1.1 section 1.3.2.
+// Multicast requests include the MX header, the maximum number of seconds devices wait before responding
+// to spread responses of many devices over time, it is derived from the timeout.
+// Unicast requests are answered immediately and have no MX header.
+func searchRequest(dst *net.UDPAddr, searchTarget string, timeout time.Duration) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("M-SEARCH * HTTP/1.1\r\n")
+	fmt.Fprintf(&buf, "HOST: %s\r\n", net.JoinHostPort(dst.IP.String(), strconv.Itoa(dst.Port)))
+	buf.WriteString("MAN: \"ssdp:discover\"\r\n")
+	if dst.IP.IsMulticast() {
+		mx := int(timeout / time.Second)
+		if mx < 1 {
+			mx = 1
+		}
+		if mx > maxMX {
+			mx = maxMX
+		}
+		fmt.Fprintf(&buf, "MX: %d\r\n", mx)
+	}
+	fmt.Fprintf(&buf, "ST: %s\r\n\r\n", searchTarget)
+	return buf.Bytes()
+}
+
+// parseSearchResponse parses the HTTP over UDP response to M-SEARCH
+func parseSearchResponse(data []byte) (*searchResponse, error) {
+	resp, err := http.ReadResponse(bufio.NewReader(bytes.NewReader(data)), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: status %d", errResponse, resp.StatusCode)
+	}
+	return &searchResponse{
+		location: resp.Header.Get("Location"),
+		server:   resp.Header.Get("Server"),
+		st:       resp.Header.Get("St"),
+	}, nil
+}
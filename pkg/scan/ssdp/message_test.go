@@ -0,0 +1,105 @@
+package ssdp
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearchRequest(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		dst      *net.UDPAddr
+		timeout  time.Duration
+		expected string
+	}{
+		{
+			name:    "Unicast",
+			dst:     &net.UDPAddr{IP: net.IPv4(192, 168, 0, 1), Port: 1900},
+			timeout: 2 * time.Second,
+			expected: "M-SEARCH * HTTP/1.1\r\nHOST: 192.168.0.1:1900\r\n" +
+				"MAN: \"ssdp:discover\"\r\nST: ssdp:all\r\n\r\n",
+		},
+		{
+			name:    "Multicast",
+			dst:     &net.UDPAddr{IP: MulticastIP, Port: 1900},
+			timeout: 2 * time.Second,
+			expected: "M-SEARCH * HTTP/1.1\r\nHOST: 239.255.255.250:1900\r\n" +
+				"MAN: \"ssdp:discover\"\r\nMX: 2\r\nST: ssdp:all\r\n\r\n",
+		},
+		{
+			name:    "MulticastShortTimeout",
+			dst:     &net.UDPAddr{IP: MulticastIP, Port: 1900},
+			timeout: 500 * time.Millisecond,
+			expected: "M-SEARCH * HTTP/1.1\r\nHOST: 239.255.255.250:1900\r\n" +
+				"MAN: \"ssdp:discover\"\r\nMX: 1\r\nST: ssdp:all\r\n\r\n",
+		},
+		{
+			name:    "MulticastLongTimeout",
+			dst:     &net.UDPAddr{IP: MulticastIP, Port: 1900},
+			timeout: 10 * time.Second,
+			expected: "M-SEARCH * HTTP/1.1\r\nHOST: 239.255.255.250:1900\r\n" +
+				"MAN: \"ssdp:discover\"\r\nMX: 5\r\nST: ssdp:all\r\n\r\n",
+		},
+	}
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			require.Equal(t, tt.expected, string(searchRequest(tt.dst, DefaultSearchTarget, tt.timeout)))
+		})
+	}
+}
+
+func TestParseSearchResponse(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		data     string
+		expected *searchResponse
+	}{
+		{
+			name: "Response",
+			data: "HTTP/1.1 200 OK\r\nCACHE-CONTROL: max-age=120\r\nEXT:\r\n" +
+				"LOCATION: http://192.168.0.1:49152/rootDesc.xml\r\nSERVER: Linux/5.4 UPnP/1.1 MiniUPnPd/2.2.1\r\n" +
+				"ST: upnp:rootdevice\r\nUSN: uuid:1234::upnp:rootdevice\r\n\r\n",
+			expected: &searchResponse{
+				location: "http://192.168.0.1:49152/rootDesc.xml",
+				server:   "Linux/5.4 UPnP/1.1 MiniUPnPd/2.2.1",
+				st:       "upnp:rootdevice",
+			},
+		},
+		{
+			name: "LowercaseHeaders",
+			data: "HTTP/1.1 200 OK\r\nlocation: http://192.168.0.2/desc.xml\r\nst: ssdp:all\r\n\r\n",
+			expected: &searchResponse{
+				location: "http://192.168.0.2/desc.xml",
+				st:       "ssdp:all",
+			},
+		},
+		{
+			name: "NotFound",
+			data: "HTTP/1.1 404 Not Found\r\n\r\n",
+		},
+		{
+			name: "Request",
+			data: "M-SEARCH * HTTP/1.1\r\nHOST: 239.255.255.250:1900\r\n\r\n",
+		},
+	}
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			resp, err := parseSearchResponse([]byte(tt.data))
+			if tt.expected == nil {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, resp)
+		})
+	}
+}
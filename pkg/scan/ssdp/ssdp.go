@@ -0,0 +1,196 @@
+package ssdp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+const (
+	ScanType = "ssdp"
+
+	// DefaultSearchTarget asks all devices and services to respond
+	DefaultSearchTarget = "ssdp:all"
+
+	defaultDataTimeout = 2 * time.Second
+	maxResponseSize    = 2048
+)
+
+// MulticastIP is the SSDP multicast address, requests to it are answered by all UPnP devices of the local network
+var MulticastIP = net.IPv4(239, 255, 255, 250)
+
+type ScanResult struct {
+	ScanType string `json:"scan"`
+	IP       string `json:"ip"`
+	Port     uint16 `json:"port"`
+	// Location is the URL of the UPnP device description
+	Location string `json:"location,omitempty"`
+	Server   string `json:"server,omitempty"`
+	// Services are search targets of responses, e.g. device types and service types
+	Services []string `json:"services,omitempty"`
+}
+
+func (r *ScanResult) String() string {
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "%-20s %-5d %s", r.IP, r.Port, r.Location)
+	if len(r.Server) > 0 {
+		fmt.Fprintf(&buf, " %q", r.Server)
+	}
+	if len(r.Services) > 0 {
+		fmt.Fprintf(&buf, " %s", strings.Join(r.Services, ","))
+	}
+	return buf.String()
+}
+
+func (r *ScanResult) ID() string {
+	return fmt.Sprintf("%s:%d", r.IP, r.Port)
+}
+
+func (r *ScanResult) MarshalJSON() ([]byte, error) {
+	// Type definition for the recursive call
+	type JScanResult ScanResult
+	// This works because JScanResult doesn't have a MarshalJSON function associated with it
+	return json.Marshal(JScanResult(*r))
+}
+
+// Scanner sends the M-SEARCH request to each target over UDP and reports devices that responded.
+// Requests to the multicast address discover all devices of the local network,
+// each responding device is reported as a separate result.
+type Scanner struct {
+	searchTarget string
+	dataTimeout  time.Duration
+}
+
+// Assert that ssdp.Scanner conforms to the scan.Scanner interface
+var _ scan.Scanner = (*Scanner)(nil)
+
+type ScannerOption func(*Scanner)
+
+// WithSearchTarget sets the ST header of requests, e.g. upnp:rootdevice, DefaultSearchTarget is used by default
+func WithSearchTarget(searchTarget string) ScannerOption {
+	return func(s *Scanner) {
+		s.searchTarget = searchTarget
+	}
+}
+
+// WithDataTimeout sets the time to wait for responses
+func WithDataTimeout(timeout time.Duration) ScannerOption {
+	return func(s *Scanner) {
+		s.dataTimeout = timeout
+	}
+}
+
+func NewScanner(opts ...ScannerOption) *Scanner {
+	s := &Scanner{
+		searchTarget: DefaultSearchTarget,
+		dataTimeout:  defaultDataTimeout,
+	}
+	for _, o := range opts {
+		o(s)
+	}
+	return s
+}
+
+func (s *Scanner) Scan(ctx context.Context, r *scan.Request) (scan.Result, error) {
+	result, err := s.scanTarget(ctx, r)
+	return result, scan.WrapError(err)
+}
+
+func (s *Scanner) scanTarget(ctx context.Context, r *scan.Request) (result scan.Result, err error) {
+	var lc net.ListenConfig
+	// devices respond from arbitrary ports and multicast requests are answered by many devices,
+	// so the socket is not connected to the target
+	conn, err := lc.ListenPacket(ctx, "udp4", ":0")
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	dst := &net.UDPAddr{IP: r.DstIP, Port: int(r.DstPort)}
+	if _, err = conn.WriteTo(searchRequest(dst, s.searchTarget, s.dataTimeout), dst); err != nil {
+		return
+	}
+	if err = conn.SetReadDeadline(time.Now().Add(s.dataTimeout)); err != nil {
+		return
+	}
+	var srcIP net.IP
+	if !r.DstIP.IsMulticast() {
+		srcIP = r.DstIP
+	}
+	results, err := collect(conn, srcIP, r.DstPort)
+	if err != nil {
+		return nil, err
+	}
+	switch len(results) {
+	case 0:
+		return nil, nil
+	case 1:
+		return results[0], nil
+	}
+	multi := make(scan.MultiResult, 0, len(results))
+	for _, res := range results {
+		multi = append(multi, res)
+	}
+	return multi, nil
+}
+
+// collect reads responses until the read deadline and merges them by the responding device,
+// responses from IPs other than srcIP are skipped unless srcIP is nil
+func collect(conn net.PacketConn, srcIP net.IP, port uint16) (results []*ScanResult, err error) {
+	devices := make(map[string]*ScanResult)
+	buf := make([]byte, maxResponseSize)
+	for {
+		n, addr, rerr := conn.ReadFrom(buf)
+		if isTimeout(rerr) {
+			return
+		}
+		if rerr != nil {
+			return nil, rerr
+		}
+		udpAddr, ok := addr.(*net.UDPAddr)
+		if !ok || (srcIP != nil && !udpAddr.IP.Equal(srcIP)) {
+			continue
+		}
+		resp, perr := parseSearchResponse(buf[:n])
+		if perr != nil {
+			continue
+		}
+		ip := udpAddr.IP.String()
+		res, ok := devices[ip]
+		if !ok {
+			res = &ScanResult{ScanType: ScanType, IP: ip, Port: port}
+			devices[ip] = res
+			results = append(results, res)
+		}
+		res.merge(resp)
+	}
+}
+
+func (r *ScanResult) merge(resp *searchResponse) {
+	if len(r.Location) == 0 {
+		r.Location = resp.location
+	}
+	if len(r.Server) == 0 {
+		r.Server = resp.server
+	}
+	if len(resp.st) == 0 {
+		return
+	}
+	for _, service := range r.Services {
+		if service == resp.st {
+			return
+		}
+	}
+	r.Services = append(r.Services, resp.st)
+}
+
+func isTimeout(err error) bool {
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
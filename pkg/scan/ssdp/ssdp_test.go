@@ -0,0 +1,133 @@
+package ssdp
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+func searchResponseData(location, st string) []byte {
+	return []byte(fmt.Sprintf("HTTP/1.1 200 OK\r\nLOCATION: %s\r\nSERVER: Linux UPnP/1.0 test/1.0\r\nST: %s\r\n\r\n", location, st))
+}
+
+type fakeDevice struct {
+	conn net.PacketConn
+	// services are search targets of responses, one response per service
+	services []string
+	// foreign answers from another loopback address in addition to the device address
+	foreign net.PacketConn
+}
+
+// startFakeDevice starts UDP SSDP device
+func startFakeDevice(t *testing.T, device *fakeDevice) *scan.Request {
+	t.Helper()
+	conn, err := net.ListenPacket("udp4", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	device.conn = conn
+	go device.serve()
+	addr := conn.LocalAddr().(*net.UDPAddr)
+	return &scan.Request{DstIP: addr.IP, DstPort: uint16(addr.Port)}
+}
+
+func (d *fakeDevice) serve() {
+	buf := make([]byte, maxResponseSize)
+	for {
+		n, addr, err := d.conn.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+		if !strings.HasPrefix(string(buf[:n]), "M-SEARCH * HTTP/1.1\r\n") {
+			continue
+		}
+		if d.foreign != nil {
+			_, _ = d.foreign.WriteTo(searchResponseData("http://127.0.0.2/desc.xml", "upnp:rootdevice"), addr)
+		}
+		for _, service := range d.services {
+			_, _ = d.conn.WriteTo(searchResponseData("http://127.0.0.1/desc.xml", service), addr)
+		}
+	}
+}
+
+func TestScan(t *testing.T) {
+	t.Parallel()
+	foreign, err := net.ListenPacket("udp4", "127.0.0.2:0")
+	if err != nil {
+		t.Skip("127.0.0.2 is not available:", err)
+	}
+	defer foreign.Close()
+
+	device := &fakeDevice{
+		services: []string{"upnp:rootdevice", "urn:schemas-upnp-org:device:InternetGatewayDevice:1", "upnp:rootdevice"},
+		foreign:  foreign,
+	}
+	req := startFakeDevice(t, device)
+	s := NewScanner(WithDataTimeout(200 * time.Millisecond))
+
+	result, err := s.Scan(context.Background(), req)
+	require.NoError(t, err)
+	require.Equal(t, &ScanResult{
+		ScanType: ScanType,
+		IP:       req.DstIP.String(),
+		Port:     req.DstPort,
+		Location: "http://127.0.0.1/desc.xml",
+		Server:   "Linux UPnP/1.0 test/1.0",
+		Services: []string{"upnp:rootdevice", "urn:schemas-upnp-org:device:InternetGatewayDevice:1"},
+	}, result)
+}
+
+func TestScanNoResponse(t *testing.T) {
+	t.Parallel()
+	req := startFakeDevice(t, &fakeDevice{})
+	s := NewScanner(WithDataTimeout(50 * time.Millisecond))
+
+	result, err := s.Scan(context.Background(), req)
+	require.NoError(t, err)
+	require.Nil(t, result)
+}
+
+func TestCollectAllDevices(t *testing.T) {
+	t.Parallel()
+	conn, err := net.ListenPacket("udp4", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer conn.Close()
+
+	responses := []struct {
+		ip       string
+		location string
+		st       string
+	}{
+		{ip: "127.0.0.1", location: "http://127.0.0.1/desc.xml", st: "upnp:rootdevice"},
+		{ip: "127.0.0.2", location: "http://127.0.0.2/desc.xml", st: "upnp:rootdevice"},
+		{ip: "127.0.0.1", location: "http://127.0.0.1/other.xml", st: "urn:schemas-upnp-org:service:WANIPConnection:1"},
+	}
+	for _, resp := range responses {
+		device, err := net.ListenPacket("udp4", resp.ip+":0")
+		if err != nil {
+			t.Skip(resp.ip, "is not available:", err)
+		}
+		defer device.Close()
+		_, err = device.WriteTo(searchResponseData(resp.location, resp.st), conn.LocalAddr())
+		require.NoError(t, err)
+	}
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(100*time.Millisecond)))
+
+	results, err := collect(conn, nil, 1900)
+	require.NoError(t, err)
+	require.Equal(t, []*ScanResult{
+		{
+			ScanType: ScanType, IP: "127.0.0.1", Port: 1900, Location: "http://127.0.0.1/desc.xml",
+			Server: "Linux UPnP/1.0 test/1.0", Services: []string{"upnp:rootdevice", "urn:schemas-upnp-org:service:WANIPConnection:1"},
+		},
+		{
+			ScanType: ScanType, IP: "127.0.0.2", Port: 1900, Location: "http://127.0.0.2/desc.xml",
+			Server: "Linux UPnP/1.0 test/1.0", Services: []string{"upnp:rootdevice"},
+		},
+	}, results)
+}
@@ -0,0 +1,294 @@
+package ssh
+
+import (
+	"bufio"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+// Message numbers, see RFC 4253 section 12 and RFC 5656 section 7.1
+const (
+	msgDisconnect    = 1
+	msgIgnore        = 2
+	msgDebug         = 4
+	msgKexInit       = 20
+	msgKexECDHInit   = 30
+	msgKexECDHReply  = 31
+	kexInitCookieLen = 16
+	// maxIdentificationLength is the maximum length of the identification string including CR LF, see RFC 4253 section 4.2
+	maxIdentificationLength = 255
+	// maxPreambleLines limits the number of lines the server may send before the identification string
+	maxPreambleLines = 32
+	maxPacketLength  = 256 * 1024
+
+	clientIdentification = "SSH-2.0-sx"
+)
+
+var (
+	errIdentification = scan.NewError(scan.ErrParse, errors.New("invalid SSH identification string"))
+	errPacket         = scan.NewError(scan.ErrParse, errors.New("invalid SSH packet"))
+	errDisconnect     = errors.New("SSH server disconnected")
+)
+
+// kexCurves are key exchange methods used to collect host keys in the order of preference,
+// nil curve is curve25519 (RFC 8731)
+var kexCurves = []struct {
+	name  string
+	curve elliptic.Curve
+}{
+	{name: "curve25519-sha256"},
+	{name: "curve25519-sha256@libssh.org"},
+	{name: "ecdh-sha2-nistp256", curve: elliptic.P256()},
+	{name: "ecdh-sha2-nistp384", curve: elliptic.P384()},
+	{name: "ecdh-sha2-nistp521", curve: elliptic.P521()},
+}
+
+// readIdentification reads the server identification string "SSH-protoversion-softwareversion SP comments",
+// the server may send other lines before it
+func readIdentification(r *bufio.Reader) (string, error) {
+	for i := 0; i < maxPreambleLines; i++ {
+		line, err := readLine(r)
+		if err != nil {
+			return "", err
+		}
+		if strings.HasPrefix(line, "SSH-") {
+			return line, nil
+		}
+	}
+	return "", errIdentification
+}
+
+func readLine(r *bufio.Reader) (string, error) {
+	var line []byte
+	for len(line) < maxIdentificationLength {
+		b, err := r.ReadByte()
+		if err != nil {
+			return "", err
+		}
+		if b == '\n' {
+			return strings.TrimSuffix(string(line), "\r"), nil
+		}
+		line = append(line, b)
+	}
+	return "", errIdentification
+}
+
+// parseIdentification splits the identification string into the protocol version, software version and comments
+func parseIdentification(banner string) (proto, software, comments string, err error) {
+	parts := strings.SplitN(strings.TrimPrefix(banner, "SSH-"), "-", 2)
+	if len(parts) != 2 {
+		return "", "", "", errIdentification
+	}
+	proto = parts[0]
+	software, comments, _ = strings.Cut(parts[1], " ")
+	return
+}
+
+// readPacket reads the payload of the unencrypted binary packet, see RFC 4253 section 6
+func readPacket(r io.Reader) ([]byte, error) {
+	var header [5]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return nil, err
+	}
+	length := binary.BigEndian.Uint32(header[:4])
+	padding := uint32(header[4])
+	if length > maxPacketLength || padding+1 > length {
+		return nil, errPacket
+	}
+	packet := make([]byte, length-1)
+	if _, err := io.ReadFull(r, packet); err != nil {
+		return nil, err
+	}
+	return packet[:length-1-padding], nil
+}
+
+// readMessage reads the next packet skipping ignore and debug messages
+func readMessage(r io.Reader) ([]byte, error) {
+	for {
+		payload, err := readPacket(r)
+		if err != nil {
+			return nil, err
+		}
+		if len(payload) == 0 {
+			return nil, errPacket
+		}
+		switch payload[0] {
+		case msgIgnore, msgDebug:
+			continue
+		case msgDisconnect:
+			return nil, errDisconnect
+		}
+		return payload, nil
+	}
+}
+
+func writePacket(w io.Writer, payload []byte) error {
+	// the packet length is a multiple of 8 with at least 4 bytes of padding
+	padding := 8 - (5+len(payload))%8
+	if padding < 4 {
+		padding += 8
+	}
+	packet := binary.BigEndian.AppendUint32(nil, uint32(1+len(payload)+padding))
+	packet = append(packet, byte(padding))
+	packet = append(packet, payload...)
+	packet = append(packet, make([]byte, padding)...)
+	_, err := w.Write(packet)
+	return err
+}
+
+// kexInit is the SSH_MSG_KEXINIT message, see RFC 4253 section 7.1
+type kexInit struct {
+	KexAlgos                []string
+	HostKeyAlgos            []string
+	CiphersClientServer     []string
+	CiphersServerClient     []string
+	MACsClientServer        []string
+	MACsServerClient        []string
+	CompressionClientServer []string
+	CompressionServerClient []string
+	LanguagesClientServer   []string
+	LanguagesServerClient   []string
+}
+
+func (k *kexInit) nameLists() []*[]string {
+	return []*[]string{
+		&k.KexAlgos, &k.HostKeyAlgos,
+		&k.CiphersClientServer, &k.CiphersServerClient,
+		&k.MACsClientServer, &k.MACsServerClient,
+		&k.CompressionClientServer, &k.CompressionServerClient,
+		&k.LanguagesClientServer, &k.LanguagesServerClient,
+	}
+}
+
+func parseKexInit(payload []byte) (*kexInit, error) {
+	if len(payload) < 1+kexInitCookieLen || payload[0] != msgKexInit {
+		return nil, errPacket
+	}
+	data := payload[1+kexInitCookieLen:]
+	k := &kexInit{}
+	for _, list := range k.nameLists() {
+		var value []byte
+		var ok bool
+		if value, data, ok = parseString(data); !ok {
+			return nil, errPacket
+		}
+		if len(value) > 0 {
+			*list = strings.Split(string(value), ",")
+		}
+	}
+	return k, nil
+}
+
+func (k *kexInit) marshal() []byte {
+	msg := []byte{msgKexInit}
+	msg = append(msg, randomBytes(kexInitCookieLen)...)
+	for _, list := range k.nameLists() {
+		msg = appendString(msg, []byte(strings.Join(*list, ",")))
+	}
+	// first_kex_packet_follows and reserved fields
+	return append(msg, 0, 0, 0, 0, 0)
+}
+
+// clientKexInit returns the client KEXINIT that accepts only the given key exchange and host key algorithms,
+// other algorithms are the first ones preferred by the server
+func clientKexInit(server *kexInit, kex, hostKeyAlgo string) *kexInit {
+	first := func(names []string) []string {
+		if len(names) == 0 {
+			return nil
+		}
+		return names[:1]
+	}
+	return &kexInit{
+		KexAlgos:                []string{kex},
+		HostKeyAlgos:            []string{hostKeyAlgo},
+		CiphersClientServer:     first(server.CiphersClientServer),
+		CiphersServerClient:     first(server.CiphersServerClient),
+		MACsClientServer:        first(server.MACsClientServer),
+		MACsServerClient:        first(server.MACsServerClient),
+		CompressionClientServer: first(server.CompressionClientServer),
+		CompressionServerClient: first(server.CompressionServerClient),
+	}
+}
+
+// chooseKex returns the first supported key exchange method offered by the server
+func chooseKex(server *kexInit) (name string, curve elliptic.Curve, ok bool) {
+	for _, kex := range kexCurves {
+		for _, algo := range server.KexAlgos {
+			if algo == kex.name {
+				return kex.name, kex.curve, true
+			}
+		}
+	}
+	return "", nil, false
+}
+
+// kexECDHInit returns the SSH_MSG_KEX_ECDH_INIT message with the client ephemeral public key.
+// The key exchange is never completed, so the private key is not kept and the curve25519 public key
+// is just random bytes, that is a valid X25519 public key as well.
+func kexECDHInit(curve elliptic.Curve) ([]byte, error) {
+	var publicKey []byte
+	if curve == nil {
+		publicKey = randomBytes(32)
+	} else {
+		key, err := ecdsa.GenerateKey(curve, rand.Reader)
+		if err != nil {
+			return nil, err
+		}
+		// uncompressed point encoding, see RFC 5656 section 3.1
+		publicKey = elliptic.Marshal(curve, key.X, key.Y)
+	}
+	return appendString([]byte{msgKexECDHInit}, publicKey), nil
+}
+
+// parseKexECDHReply returns the server public host key blob of SSH_MSG_KEX_ECDH_REPLY message
+func parseKexECDHReply(payload []byte) ([]byte, error) {
+	if payload[0] != msgKexECDHReply {
+		return nil, fmt.Errorf("%w: unexpected message %d", errPacket, payload[0])
+	}
+	hostKey, _, ok := parseString(payload[1:])
+	if !ok {
+		return nil, errPacket
+	}
+	return hostKey, nil
+}
+
+// hostKeyType returns the key type of the public host key blob, e.g. ssh-ed25519
+func hostKeyType(hostKey []byte) (string, error) {
+	keyType, _, ok := parseString(hostKey)
+	if !ok {
+		return "", errPacket
+	}
+	return string(keyType), nil
+}
+
+func parseString(data []byte) (value, rest []byte, ok bool) {
+	if len(data) < 4 {
+		return
+	}
+	length := binary.BigEndian.Uint32(data)
+	data = data[4:]
+	if uint32(len(data)) < length {
+		return
+	}
+	return data[:length], data[length:], true
+}
+
+func appendString(data, value []byte) []byte {
+	data = binary.BigEndian.AppendUint32(data, uint32(len(value)))
+	return append(data, value...)
+}
+
+func randomBytes(n int) []byte {
+	buf := make([]byte, n)
+	// crypto/rand doesn't fail on supported platforms
+	_, _ = rand.Read(buf)
+	return buf
+}
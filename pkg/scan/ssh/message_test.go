@@ -0,0 +1,253 @@
+package ssh
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/elliptic"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadIdentification(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+		err      error
+	}{
+		{
+			name:     "Banner",
+			input:    "SSH-2.0-OpenSSH_8.4p1 Debian-5\r\n",
+			expected: "SSH-2.0-OpenSSH_8.4p1 Debian-5",
+		},
+		{
+			name:     "BannerWithoutCR",
+			input:    "SSH-2.0-dropbear_2020.81\n",
+			expected: "SSH-2.0-dropbear_2020.81",
+		},
+		{
+			name:     "Preamble",
+			input:    "Welcome\r\nto the server\r\nSSH-1.99-Cisco-1.25\r\n",
+			expected: "SSH-1.99-Cisco-1.25",
+		},
+		{
+			name:  "TooLongLine",
+			input: "SSH-2.0-" + strings.Repeat("a", maxIdentificationLength) + "\r\n",
+			err:   errIdentification,
+		},
+		{
+			name:  "TooManyLines",
+			input: strings.Repeat("line\r\n", maxPreambleLines+1),
+			err:   errIdentification,
+		},
+	}
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			banner, err := readIdentification(bufio.NewReader(strings.NewReader(tt.input)))
+			if tt.err != nil {
+				require.ErrorIs(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, banner)
+		})
+	}
+}
+
+func TestParseIdentification(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		banner   string
+		proto    string
+		software string
+		comments string
+		err      error
+	}{
+		{
+			banner:   "SSH-2.0-OpenSSH_8.4p1 Debian-5",
+			proto:    "2.0",
+			software: "OpenSSH_8.4p1",
+			comments: "Debian-5",
+		},
+		{
+			banner:   "SSH-2.0-OpenSSH_for_Windows_8.1",
+			proto:    "2.0",
+			software: "OpenSSH_for_Windows_8.1",
+		},
+		{
+			banner:   "SSH-1.99-Cisco-1.25",
+			proto:    "1.99",
+			software: "Cisco-1.25",
+		},
+		{
+			banner: "SSH-2.0",
+			err:    errIdentification,
+		},
+	}
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.banner, func(t *testing.T) {
+			t.Parallel()
+			proto, software, comments, err := parseIdentification(tt.banner)
+			if tt.err != nil {
+				require.ErrorIs(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.proto, proto)
+			require.Equal(t, tt.software, software)
+			require.Equal(t, tt.comments, comments)
+		})
+	}
+}
+
+func TestWriteReadPacket(t *testing.T) {
+	t.Parallel()
+	for _, size := range []int{0, 1, 3, 4, 7, 8, 100} {
+		payload := bytes.Repeat([]byte{0xab}, size)
+		var buf bytes.Buffer
+		err := writePacket(&buf, payload)
+		require.NoError(t, err)
+		require.Zero(t, buf.Len()%8, "packet length must be a multiple of 8")
+		require.GreaterOrEqual(t, int(buf.Bytes()[4]), 4, "padding must be at least 4 bytes")
+
+		result, err := readPacket(&buf)
+		require.NoError(t, err)
+		require.Equal(t, payload, result)
+	}
+}
+
+func TestReadPacketInvalidLength(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name  string
+		input []byte
+	}{
+		{
+			name:  "TooLong",
+			input: []byte{0x10, 0x00, 0x00, 0x00, 0x04},
+		},
+		{
+			name:  "PaddingTooLong",
+			input: []byte{0x00, 0x00, 0x00, 0x04, 0x04},
+		},
+	}
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := readPacket(bytes.NewReader(tt.input))
+			require.ErrorIs(t, err, errPacket)
+		})
+	}
+}
+
+func TestReadMessageSkipsIgnoreAndDebug(t *testing.T) {
+	t.Parallel()
+	var buf bytes.Buffer
+	require.NoError(t, writePacket(&buf, []byte{msgIgnore, 0, 0, 0, 0}))
+	require.NoError(t, writePacket(&buf, []byte{msgDebug, 0}))
+	require.NoError(t, writePacket(&buf, []byte{msgKexInit}))
+
+	payload, err := readMessage(&buf)
+	require.NoError(t, err)
+	require.Equal(t, []byte{msgKexInit}, payload)
+}
+
+func TestReadMessageDisconnect(t *testing.T) {
+	t.Parallel()
+	var buf bytes.Buffer
+	require.NoError(t, writePacket(&buf, []byte{msgDisconnect, 0, 0, 0, 2}))
+
+	_, err := readMessage(&buf)
+	require.ErrorIs(t, err, errDisconnect)
+}
+
+func TestKexInitMarshalParse(t *testing.T) {
+	t.Parallel()
+	expected := &kexInit{
+		KexAlgos:                []string{"curve25519-sha256", "ecdh-sha2-nistp256"},
+		HostKeyAlgos:            []string{"ssh-ed25519"},
+		CiphersClientServer:     []string{"aes128-ctr"},
+		CiphersServerClient:     []string{"aes256-ctr"},
+		MACsClientServer:        []string{"hmac-sha2-256"},
+		MACsServerClient:        []string{"hmac-sha2-512"},
+		CompressionClientServer: []string{"none"},
+		CompressionServerClient: []string{"none", "zlib"},
+	}
+	result, err := parseKexInit(expected.marshal())
+	require.NoError(t, err)
+	require.Equal(t, expected, result)
+}
+
+func TestParseKexInitTruncated(t *testing.T) {
+	t.Parallel()
+	data := testServerKexInit.marshal()
+	_, err := parseKexInit(data[:40])
+	require.ErrorIs(t, err, errPacket)
+}
+
+func TestChooseKex(t *testing.T) {
+	t.Parallel()
+	name, curve, ok := chooseKex(&kexInit{KexAlgos: []string{"diffie-hellman-group14-sha256", "ecdh-sha2-nistp384"}})
+	require.True(t, ok)
+	require.Equal(t, "ecdh-sha2-nistp384", name)
+	require.Equal(t, elliptic.P384(), curve)
+
+	_, _, ok = chooseKex(&kexInit{KexAlgos: []string{"diffie-hellman-group14-sha256"}})
+	require.False(t, ok)
+}
+
+func TestKexECDHInit(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name      string
+		curve     elliptic.Curve
+		keyLength int
+	}{
+		{
+			name:      "Curve25519",
+			keyLength: 32,
+		},
+		{
+			name:      "P256",
+			curve:     elliptic.P256(),
+			keyLength: 65,
+		},
+	}
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			msg, err := kexECDHInit(tt.curve)
+			require.NoError(t, err)
+			require.Equal(t, byte(msgKexECDHInit), msg[0])
+			key, rest, ok := parseString(msg[1:])
+			require.True(t, ok)
+			require.Empty(t, rest)
+			require.Len(t, key, tt.keyLength)
+		})
+	}
+}
+
+func TestParseKexECDHReply(t *testing.T) {
+	t.Parallel()
+	blob := appendString(appendString(nil, []byte("ssh-ed25519")), []byte("key"))
+	reply := appendString([]byte{msgKexECDHReply}, blob)
+	reply = appendString(reply, []byte("server key"))
+
+	hostKey, err := parseKexECDHReply(reply)
+	require.NoError(t, err)
+	require.Equal(t, blob, hostKey)
+	keyType, err := hostKeyType(hostKey)
+	require.NoError(t, err)
+	require.Equal(t, "ssh-ed25519", keyType)
+
+	_, err = parseKexECDHReply([]byte{msgKexInit})
+	require.ErrorIs(t, err, errPacket)
+}
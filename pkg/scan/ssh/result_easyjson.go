@@ -0,0 +1,518 @@
+// Code generated by easyjson for marshaling/unmarshaling. DO NOT EDIT.
+
+package ssh
+
+import (
+	json "encoding/json"
+	easyjson "github.com/mailru/easyjson"
+	jlexer "github.com/mailru/easyjson/jlexer"
+	jwriter "github.com/mailru/easyjson/jwriter"
+)
+
+// suppress unused package warning
+var (
+	_ *json.RawMessage
+	_ *jlexer.Lexer
+	_ *jwriter.Writer
+	_ easyjson.Marshaler
+)
+
+func easyjsonD3b49167DecodeGithubComVByteCpuSxPkgScanSsh(in *jlexer.Lexer, out *ScanResult) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "scan":
+			out.ScanType = string(in.String())
+		case "ip":
+			out.IP = string(in.String())
+		case "port":
+			out.Port = uint16(in.Uint16())
+		case "banner":
+			out.Banner = string(in.String())
+		case "proto":
+			out.Proto = string(in.String())
+		case "software":
+			out.Software = string(in.String())
+		case "comments":
+			out.Comments = string(in.String())
+		case "host_keys":
+			if in.IsNull() {
+				in.Skip()
+				out.HostKeys = nil
+			} else {
+				in.Delim('[')
+				if out.HostKeys == nil {
+					if !in.IsDelim(']') {
+						out.HostKeys = make([]*HostKey, 0, 8)
+					} else {
+						out.HostKeys = []*HostKey{}
+					}
+				} else {
+					out.HostKeys = (out.HostKeys)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v1 *HostKey
+					if in.IsNull() {
+						in.Skip()
+						v1 = nil
+					} else {
+						if v1 == nil {
+							v1 = new(HostKey)
+						}
+						(*v1).UnmarshalEasyJSON(in)
+					}
+					out.HostKeys = append(out.HostKeys, v1)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		case "algorithms":
+			if in.IsNull() {
+				in.Skip()
+				out.Algorithms = nil
+			} else {
+				if out.Algorithms == nil {
+					out.Algorithms = new(Algorithms)
+				}
+				(*out.Algorithms).UnmarshalEasyJSON(in)
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonD3b49167EncodeGithubComVByteCpuSxPkgScanSsh(out *jwriter.Writer, in ScanResult) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"scan\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.ScanType))
+	}
+	{
+		const prefix string = ",\"ip\":"
+		out.RawString(prefix)
+		out.String(string(in.IP))
+	}
+	{
+		const prefix string = ",\"port\":"
+		out.RawString(prefix)
+		out.Uint16(uint16(in.Port))
+	}
+	{
+		const prefix string = ",\"banner\":"
+		out.RawString(prefix)
+		out.String(string(in.Banner))
+	}
+	{
+		const prefix string = ",\"proto\":"
+		out.RawString(prefix)
+		out.String(string(in.Proto))
+	}
+	{
+		const prefix string = ",\"software\":"
+		out.RawString(prefix)
+		out.String(string(in.Software))
+	}
+	if in.Comments != "" {
+		const prefix string = ",\"comments\":"
+		out.RawString(prefix)
+		out.String(string(in.Comments))
+	}
+	if len(in.HostKeys) != 0 {
+		const prefix string = ",\"host_keys\":"
+		out.RawString(prefix)
+		{
+			out.RawByte('[')
+			for v2, v3 := range in.HostKeys {
+				if v2 > 0 {
+					out.RawByte(',')
+				}
+				if v3 == nil {
+					out.RawString("null")
+				} else {
+					(*v3).MarshalEasyJSON(out)
+				}
+			}
+			out.RawByte(']')
+		}
+	}
+	if in.Algorithms != nil {
+		const prefix string = ",\"algorithms\":"
+		out.RawString(prefix)
+		(*in.Algorithms).MarshalEasyJSON(out)
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v ScanResult) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonD3b49167EncodeGithubComVByteCpuSxPkgScanSsh(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v ScanResult) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD3b49167EncodeGithubComVByteCpuSxPkgScanSsh(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *ScanResult) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonD3b49167DecodeGithubComVByteCpuSxPkgScanSsh(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *ScanResult) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD3b49167DecodeGithubComVByteCpuSxPkgScanSsh(l, v)
+}
+func easyjsonD3b49167DecodeGithubComVByteCpuSxPkgScanSsh1(in *jlexer.Lexer, out *HostKey) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "type":
+			out.Type = string(in.String())
+		case "fingerprint":
+			out.Fingerprint = string(in.String())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonD3b49167EncodeGithubComVByteCpuSxPkgScanSsh1(out *jwriter.Writer, in HostKey) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"type\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.Type))
+	}
+	{
+		const prefix string = ",\"fingerprint\":"
+		out.RawString(prefix)
+		out.String(string(in.Fingerprint))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v HostKey) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonD3b49167EncodeGithubComVByteCpuSxPkgScanSsh1(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v HostKey) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD3b49167EncodeGithubComVByteCpuSxPkgScanSsh1(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *HostKey) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonD3b49167DecodeGithubComVByteCpuSxPkgScanSsh1(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *HostKey) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD3b49167DecodeGithubComVByteCpuSxPkgScanSsh1(l, v)
+}
+func easyjsonD3b49167DecodeGithubComVByteCpuSxPkgScanSsh2(in *jlexer.Lexer, out *Algorithms) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "kex":
+			if in.IsNull() {
+				in.Skip()
+				out.Kex = nil
+			} else {
+				in.Delim('[')
+				if out.Kex == nil {
+					if !in.IsDelim(']') {
+						out.Kex = make([]string, 0, 4)
+					} else {
+						out.Kex = []string{}
+					}
+				} else {
+					out.Kex = (out.Kex)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v4 string
+					v4 = string(in.String())
+					out.Kex = append(out.Kex, v4)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		case "host_key":
+			if in.IsNull() {
+				in.Skip()
+				out.HostKey = nil
+			} else {
+				in.Delim('[')
+				if out.HostKey == nil {
+					if !in.IsDelim(']') {
+						out.HostKey = make([]string, 0, 4)
+					} else {
+						out.HostKey = []string{}
+					}
+				} else {
+					out.HostKey = (out.HostKey)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v5 string
+					v5 = string(in.String())
+					out.HostKey = append(out.HostKey, v5)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		case "ciphers":
+			if in.IsNull() {
+				in.Skip()
+				out.Ciphers = nil
+			} else {
+				in.Delim('[')
+				if out.Ciphers == nil {
+					if !in.IsDelim(']') {
+						out.Ciphers = make([]string, 0, 4)
+					} else {
+						out.Ciphers = []string{}
+					}
+				} else {
+					out.Ciphers = (out.Ciphers)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v6 string
+					v6 = string(in.String())
+					out.Ciphers = append(out.Ciphers, v6)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		case "macs":
+			if in.IsNull() {
+				in.Skip()
+				out.MACs = nil
+			} else {
+				in.Delim('[')
+				if out.MACs == nil {
+					if !in.IsDelim(']') {
+						out.MACs = make([]string, 0, 4)
+					} else {
+						out.MACs = []string{}
+					}
+				} else {
+					out.MACs = (out.MACs)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v7 string
+					v7 = string(in.String())
+					out.MACs = append(out.MACs, v7)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		case "compression":
+			if in.IsNull() {
+				in.Skip()
+				out.Compression = nil
+			} else {
+				in.Delim('[')
+				if out.Compression == nil {
+					if !in.IsDelim(']') {
+						out.Compression = make([]string, 0, 4)
+					} else {
+						out.Compression = []string{}
+					}
+				} else {
+					out.Compression = (out.Compression)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v8 string
+					v8 = string(in.String())
+					out.Compression = append(out.Compression, v8)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonD3b49167EncodeGithubComVByteCpuSxPkgScanSsh2(out *jwriter.Writer, in Algorithms) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"kex\":"
+		out.RawString(prefix[1:])
+		if in.Kex == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+			out.RawString("null")
+		} else {
+			out.RawByte('[')
+			for v9, v10 := range in.Kex {
+				if v9 > 0 {
+					out.RawByte(',')
+				}
+				out.String(string(v10))
+			}
+			out.RawByte(']')
+		}
+	}
+	{
+		const prefix string = ",\"host_key\":"
+		out.RawString(prefix)
+		if in.HostKey == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+			out.RawString("null")
+		} else {
+			out.RawByte('[')
+			for v11, v12 := range in.HostKey {
+				if v11 > 0 {
+					out.RawByte(',')
+				}
+				out.String(string(v12))
+			}
+			out.RawByte(']')
+		}
+	}
+	{
+		const prefix string = ",\"ciphers\":"
+		out.RawString(prefix)
+		if in.Ciphers == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+			out.RawString("null")
+		} else {
+			out.RawByte('[')
+			for v13, v14 := range in.Ciphers {
+				if v13 > 0 {
+					out.RawByte(',')
+				}
+				out.String(string(v14))
+			}
+			out.RawByte(']')
+		}
+	}
+	{
+		const prefix string = ",\"macs\":"
+		out.RawString(prefix)
+		if in.MACs == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+			out.RawString("null")
+		} else {
+			out.RawByte('[')
+			for v15, v16 := range in.MACs {
+				if v15 > 0 {
+					out.RawByte(',')
+				}
+				out.String(string(v16))
+			}
+			out.RawByte(']')
+		}
+	}
+	{
+		const prefix string = ",\"compression\":"
+		out.RawString(prefix)
+		if in.Compression == nil && (out.Flags&jwriter.NilSliceAsEmpty) == 0 {
+			out.RawString("null")
+		} else {
+			out.RawByte('[')
+			for v17, v18 := range in.Compression {
+				if v17 > 0 {
+					out.RawByte(',')
+				}
+				out.String(string(v18))
+			}
+			out.RawByte(']')
+		}
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v Algorithms) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonD3b49167EncodeGithubComVByteCpuSxPkgScanSsh2(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v Algorithms) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD3b49167EncodeGithubComVByteCpuSxPkgScanSsh2(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *Algorithms) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonD3b49167DecodeGithubComVByteCpuSxPkgScanSsh2(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *Algorithms) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD3b49167DecodeGithubComVByteCpuSxPkgScanSsh2(l, v)
+}
@@ -0,0 +1,304 @@
+//go:generate easyjson -output_filename result_easyjson.go ssh.go
+
+package ssh
+
+import (
+	"bufio"
+	"context"
+	"crypto/elliptic"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+const (
+	ScanType = "ssh"
+
+	defaultDialTimeout = 2 * time.Second
+	defaultDataTimeout = 2 * time.Second
+)
+
+//easyjson:json
+type ScanResult struct {
+	ScanType string `json:"scan"`
+	IP       string `json:"ip"`
+	Port     uint16 `json:"port"`
+	// Banner is the identification string of the server, e.g. SSH-2.0-OpenSSH_8.4p1 Debian-5
+	Banner     string      `json:"banner"`
+	Proto      string      `json:"proto"`
+	Software   string      `json:"software"`
+	Comments   string      `json:"comments,omitempty"`
+	HostKeys   []*HostKey  `json:"host_keys,omitempty"`
+	Algorithms *Algorithms `json:"algorithms,omitempty"`
+}
+
+func (r *ScanResult) String() string {
+	return fmt.Sprintf("%-20s %-5d %s", r.IP, r.Port, r.Banner)
+}
+
+func (r *ScanResult) ID() string {
+	return fmt.Sprintf("%s:%d", r.IP, r.Port)
+}
+
+//easyjson:json
+type HostKey struct {
+	Type string `json:"type"`
+	// Fingerprint is the SHA256 fingerprint in OpenSSH format, e.g. SHA256:nThbg6kXUpJWGl7E1IGOCspRomTxdCARLviKw6E5SY8
+	Fingerprint string `json:"fingerprint"`
+}
+
+// Algorithms are supported by the server in the order of preference,
+// ciphers, MACs and compression algorithms are for the client to server direction
+//
+//easyjson:json
+type Algorithms struct {
+	Kex         []string `json:"kex"`
+	HostKey     []string `json:"host_key"`
+	Ciphers     []string `json:"ciphers"`
+	MACs        []string `json:"macs"`
+	Compression []string `json:"compression"`
+}
+
+type Scanner struct {
+	dialer      *net.Dialer
+	dataTimeout time.Duration
+	hostKeys    bool
+	algorithms  bool
+}
+
+// Assert that ssh.Scanner conforms to the scan.Scanner interface
+var _ scan.Scanner = (*Scanner)(nil)
+
+type ScannerOption func(*Scanner)
+
+func WithDialTimeout(timeout time.Duration) ScannerOption {
+	return func(s *Scanner) {
+		s.dialer.Timeout = timeout
+	}
+}
+
+func WithDataTimeout(timeout time.Duration) ScannerOption {
+	return func(s *Scanner) {
+		s.dataTimeout = timeout
+	}
+}
+
+// WithHostKeys enables collecting fingerprints of host keys,
+// one connection is opened for every host key type supported by the server
+func WithHostKeys() ScannerOption {
+	return func(s *Scanner) {
+		s.hostKeys = true
+	}
+}
+
+// WithAlgorithms enables collecting algorithms from the KEXINIT message of the server
+func WithAlgorithms() ScannerOption {
+	return func(s *Scanner) {
+		s.algorithms = true
+	}
+}
+
+func NewScanner(opts ...ScannerOption) *Scanner {
+	s := &Scanner{
+		dialer: &net.Dialer{
+			Timeout: defaultDialTimeout,
+		},
+		dataTimeout: defaultDataTimeout,
+	}
+	for _, o := range opts {
+		o(s)
+	}
+	return s
+}
+
+func (s *Scanner) Scan(ctx context.Context, r *scan.Request) (scan.Result, error) {
+	result, err := s.scanTarget(ctx, r)
+	return result, scan.WrapError(err)
+}
+
+func (s *Scanner) scanTarget(ctx context.Context, r *scan.Request) (scan.Result, error) {
+	addr := net.JoinHostPort(r.DstIP.String(), strconv.Itoa(int(r.DstPort)))
+	c, err := s.dial(ctx, addr)
+	if err != nil {
+		return nil, err
+	}
+	defer c.Close()
+
+	banner, err := c.identification()
+	if err != nil {
+		return nil, err
+	}
+	proto, software, comments, err := parseIdentification(banner)
+	if err != nil {
+		return nil, err
+	}
+	result := &ScanResult{
+		ScanType: ScanType,
+		IP:       r.DstIP.String(),
+		Port:     r.DstPort,
+		Banner:   banner,
+		Proto:    proto,
+		Software: software,
+		Comments: comments,
+	}
+	if !s.hostKeys && !s.algorithms {
+		return result, nil
+	}
+
+	server, err := c.kexInit()
+	if err != nil {
+		return nil, err
+	}
+	if s.algorithms {
+		result.Algorithms = &Algorithms{
+			Kex:         server.KexAlgos,
+			HostKey:     server.HostKeyAlgos,
+			Ciphers:     server.CiphersClientServer,
+			MACs:        server.MACsClientServer,
+			Compression: server.CompressionClientServer,
+		}
+	}
+	if s.hostKeys {
+		if result.HostKeys, err = s.collectHostKeys(ctx, addr, c, server); err != nil {
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+// collectHostKeys gets host keys of all types supported by the server, the first key is taken from
+// the already opened connection. Keys that can't be collected, e.g. due to the server limit
+// of unauthenticated connections, are skipped.
+func (s *Scanner) collectHostKeys(ctx context.Context, addr string, c *conn, server *kexInit) ([]*HostKey, error) {
+	var result []*HostKey
+	for i, algo := range hostKeyAlgos(server.HostKeyAlgos) {
+		key, err := s.hostKey(ctx, addr, c, i == 0, algo)
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if err != nil || key == nil {
+			continue
+		}
+		result = append(result, key)
+	}
+	return result, nil
+}
+
+func (s *Scanner) hostKey(ctx context.Context, addr string, c *conn, reuse bool, algo string) (*HostKey, error) {
+	server := c.server
+	if !reuse {
+		var err error
+		if c, err = s.dial(ctx, addr); err != nil {
+			return nil, err
+		}
+		defer c.Close()
+		if _, err = c.identification(); err != nil {
+			return nil, err
+		}
+		if server, err = c.kexInit(); err != nil {
+			return nil, err
+		}
+	}
+	kex, curve, ok := chooseKex(server)
+	if !ok {
+		return nil, nil
+	}
+	blob, err := c.exchangeKeys(clientKexInit(server, kex, algo), curve)
+	if err != nil {
+		return nil, err
+	}
+	keyType, err := hostKeyType(blob)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(blob)
+	return &HostKey{
+		Type:        keyType,
+		Fingerprint: "SHA256:" + base64.RawStdEncoding.EncodeToString(sum[:]),
+	}, nil
+}
+
+// hostKeyAlgos returns host key algorithms with distinct key types,
+// RSA keys are the same for all signature algorithms and certificates are skipped
+func hostKeyAlgos(algos []string) (result []string) {
+	seen := make(map[string]bool)
+	for _, algo := range algos {
+		if strings.HasSuffix(algo, "-cert-v01@openssh.com") {
+			continue
+		}
+		keyType := algo
+		if algo == "rsa-sha2-256" || algo == "rsa-sha2-512" {
+			keyType = "ssh-rsa"
+		}
+		if seen[keyType] {
+			continue
+		}
+		seen[keyType] = true
+		result = append(result, algo)
+	}
+	return
+}
+
+type conn struct {
+	net.Conn
+	rd     *bufio.Reader
+	server *kexInit
+}
+
+func (s *Scanner) dial(ctx context.Context, addr string) (*conn, error) {
+	c, err := s.dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	if err = c.SetDeadline(time.Now().Add(s.dataTimeout)); err != nil {
+		c.Close()
+		return nil, err
+	}
+	return &conn{Conn: c, rd: bufio.NewReader(c)}, nil
+}
+
+// identification exchanges identification strings and returns the server one
+func (c *conn) identification() (string, error) {
+	if _, err := c.Write([]byte(clientIdentification + "\r\n")); err != nil {
+		return "", err
+	}
+	return readIdentification(c.rd)
+}
+
+func (c *conn) kexInit() (server *kexInit, err error) {
+	var payload []byte
+	if payload, err = readMessage(c.rd); err != nil {
+		return
+	}
+	if server, err = parseKexInit(payload); err != nil {
+		return
+	}
+	c.server = server
+	return
+}
+
+// exchangeKeys starts the key exchange and returns the server public host key blob,
+// the key exchange is not completed since the host key is all we need
+func (c *conn) exchangeKeys(client *kexInit, curve elliptic.Curve) ([]byte, error) {
+	if err := writePacket(c, client.marshal()); err != nil {
+		return nil, err
+	}
+	msg, err := kexECDHInit(curve)
+	if err != nil {
+		return nil, err
+	}
+	if err = writePacket(c, msg); err != nil {
+		return nil, err
+	}
+	payload, err := readMessage(c.rd)
+	if err != nil {
+		return nil, err
+	}
+	return parseKexECDHReply(payload)
+}
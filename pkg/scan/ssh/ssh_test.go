@@ -0,0 +1,211 @@
+package ssh
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+var testServerKexInit = &kexInit{
+	KexAlgos:                []string{"diffie-hellman-group14-sha256", "curve25519-sha256", "ecdh-sha2-nistp256"},
+	HostKeyAlgos:            []string{"ssh-ed25519-cert-v01@openssh.com", "rsa-sha2-512", "rsa-sha2-256", "ssh-ed25519"},
+	CiphersClientServer:     []string{"chacha20-poly1305@openssh.com", "aes128-ctr"},
+	CiphersServerClient:     []string{"chacha20-poly1305@openssh.com", "aes128-ctr"},
+	MACsClientServer:        []string{"hmac-sha2-256"},
+	MACsServerClient:        []string{"hmac-sha2-256"},
+	CompressionClientServer: []string{"none", "zlib@openssh.com"},
+	CompressionServerClient: []string{"none", "zlib@openssh.com"},
+}
+
+var testHostKeys = map[string][]byte{
+	"rsa-sha2-512": appendString(appendString(nil, []byte("ssh-rsa")), []byte("rsa key")),
+	"ssh-ed25519":  appendString(appendString(nil, []byte("ssh-ed25519")), []byte("ed25519 key")),
+}
+
+func fingerprint(blob []byte) string {
+	sum := sha256.Sum256(blob)
+	return "SHA256:" + base64.RawStdEncoding.EncodeToString(sum[:])
+}
+
+// serveSSH answers connections as an SSH server up to the first key exchange reply
+func serveSSH(t *testing.T, banner string) (addr string) {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { l.Close() })
+
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go handleSSH(conn, banner)
+		}
+	}()
+	return l.Addr().String()
+}
+
+func handleSSH(conn net.Conn, banner string) {
+	defer conn.Close()
+	rd := bufio.NewReader(conn)
+	if _, err := conn.Write([]byte("preamble line\r\n" + banner + "\r\n")); err != nil {
+		return
+	}
+	if _, err := readIdentification(rd); err != nil {
+		return
+	}
+	if err := writePacket(conn, testServerKexInit.marshal()); err != nil {
+		return
+	}
+	payload, err := readMessage(rd)
+	if err != nil {
+		return
+	}
+	client, err := parseKexInit(payload)
+	if err != nil {
+		return
+	}
+	if _, err = readMessage(rd); err != nil {
+		return
+	}
+	reply := appendString([]byte{msgKexECDHReply}, testHostKeys[client.HostKeyAlgos[0]])
+	reply = appendString(reply, randomBytes(32))
+	reply = appendString(reply, []byte("signature"))
+	_ = writePacket(conn, reply)
+}
+
+func newTestRequest(t *testing.T, addr string) *scan.Request {
+	t.Helper()
+	host, port, err := net.SplitHostPort(addr)
+	require.NoError(t, err)
+	tcpAddr, err := net.ResolveTCPAddr("tcp", net.JoinHostPort(host, port))
+	require.NoError(t, err)
+	return &scan.Request{DstIP: tcpAddr.IP, DstPort: uint16(tcpAddr.Port)}
+}
+
+func TestScan(t *testing.T) {
+	t.Parallel()
+	addr := serveSSH(t, "SSH-2.0-OpenSSH_8.4p1 Debian-5")
+	req := newTestRequest(t, addr)
+	expected := ScanResult{
+		ScanType: ScanType,
+		IP:       req.DstIP.String(),
+		Port:     req.DstPort,
+		Banner:   "SSH-2.0-OpenSSH_8.4p1 Debian-5",
+		Proto:    "2.0",
+		Software: "OpenSSH_8.4p1",
+		Comments: "Debian-5",
+	}
+	tests := []struct {
+		name     string
+		opts     []ScannerOption
+		expected func() *ScanResult
+	}{
+		{
+			name: "Banner",
+			expected: func() *ScanResult {
+				result := expected
+				return &result
+			},
+		},
+		{
+			name: "Algorithms",
+			opts: []ScannerOption{WithAlgorithms()},
+			expected: func() *ScanResult {
+				result := expected
+				result.Algorithms = &Algorithms{
+					Kex:         testServerKexInit.KexAlgos,
+					HostKey:     testServerKexInit.HostKeyAlgos,
+					Ciphers:     testServerKexInit.CiphersClientServer,
+					MACs:        testServerKexInit.MACsClientServer,
+					Compression: testServerKexInit.CompressionClientServer,
+				}
+				return &result
+			},
+		},
+		{
+			name: "HostKeys",
+			opts: []ScannerOption{WithHostKeys()},
+			expected: func() *ScanResult {
+				result := expected
+				result.HostKeys = []*HostKey{
+					{Type: "ssh-rsa", Fingerprint: fingerprint(testHostKeys["rsa-sha2-512"])},
+					{Type: "ssh-ed25519", Fingerprint: fingerprint(testHostKeys["ssh-ed25519"])},
+				}
+				return &result
+			},
+		},
+	}
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			s := NewScanner(tt.opts...)
+			result, err := s.Scan(context.Background(), req)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected(), result)
+		})
+	}
+}
+
+func TestScanNotSSHServer(t *testing.T) {
+	t.Parallel()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		_, _ = conn.Write([]byte(strings.Repeat("HTTP/1.1 400 Bad Request\r\n", maxPreambleLines)))
+	}()
+
+	s := NewScanner()
+	result, err := s.Scan(context.Background(), newTestRequest(t, l.Addr().String()))
+	require.ErrorIs(t, err, errIdentification)
+	require.ErrorIs(t, err, scan.ErrParse)
+	require.Nil(t, result)
+}
+
+func TestScanTimeout(t *testing.T) {
+	t.Parallel()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+	done := make(chan interface{})
+	defer close(done)
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		<-done
+	}()
+
+	s := NewScanner(WithDataTimeout(100 * time.Millisecond))
+	result, err := s.Scan(context.Background(), newTestRequest(t, l.Addr().String()))
+	require.Error(t, err)
+	require.ErrorIs(t, err, scan.ErrTimeout)
+	require.Nil(t, result)
+}
+
+func TestHostKeyAlgos(t *testing.T) {
+	t.Parallel()
+	result := hostKeyAlgos([]string{
+		"ssh-ed25519-cert-v01@openssh.com", "rsa-sha2-512", "rsa-sha2-256", "ssh-rsa",
+		"ecdsa-sha2-nistp256", "ssh-ed25519",
+	})
+	require.Equal(t, []string{"rsa-sha2-512", "ecdsa-sha2-nistp256", "ssh-ed25519"}, result)
+}
@@ -0,0 +1,143 @@
+package stun
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+// message types and attributes of RFC 5389 Session Traversal Utilities for NAT
+const (
+	bindingRequest  = 0x0001
+	bindingSuccess  = 0x0101
+	bindingError    = 0x0111
+	magicCookie     = 0x2112a442
+	headerSize      = 20
+	transactionSize = 12
+
+	attrMappedAddress    = 0x0001
+	attrErrorCode        = 0x0009
+	attrXORMappedAddress = 0x0020
+	// attrXORMappedAddressOld is sent by servers implemented before RFC 5389 was published
+	attrXORMappedAddressOld = 0x8020
+	attrSoftware            = 0x8022
+
+	familyIPv4 = 0x01
+	familyIPv6 = 0x02
+)
+
+var errMessage = scan.NewError(scan.ErrParse, errors.New("invalid STUN message"))
+
+// newBindingRequest returns the Binding Request without attributes
+func newBindingRequest(transactionID [transactionSize]byte) []byte {
+	result := make([]byte, headerSize)
+	binary.BigEndian.PutUint16(result, bindingRequest)
+	binary.BigEndian.PutUint32(result[4:], magicCookie)
+	copy(result[8:], transactionID[:])
+	return result
+}
+
+type bindingResponse struct {
+	// mappedAddress is the ip:port of the request as the server sees it
+	mappedAddress string
+	software      string
+	// errorCode and errorReason are set for the Binding Error Response
+	errorCode   int
+	errorReason string
+}
+
+// parseBindingResponse parses the Binding Response to the request with the transaction ID,
+// XOR-MAPPED-ADDRESS takes precedence over MAPPED-ADDRESS of RFC 3489 servers
+func parseBindingResponse(data []byte, transactionID [transactionSize]byte) (*bindingResponse, error) {
+	if len(data) < headerSize || binary.BigEndian.Uint32(data[4:]) != magicCookie ||
+		string(data[8:headerSize]) != string(transactionID[:]) {
+		return nil, errMessage
+	}
+	msgType := binary.BigEndian.Uint16(data)
+	if msgType != bindingSuccess && msgType != bindingError {
+		return nil, errMessage
+	}
+	length := int(binary.BigEndian.Uint16(data[2:]))
+	if length%4 != 0 || headerSize+length > len(data) {
+		return nil, errMessage
+	}
+
+	resp := &bindingResponse{}
+	var mapped, xorMapped string
+	attrs := data[headerSize : headerSize+length]
+	for len(attrs) > 0 {
+		if len(attrs) < 4 {
+			return nil, errMessage
+		}
+		attrType := binary.BigEndian.Uint16(attrs)
+		attrLength := int(binary.BigEndian.Uint16(attrs[2:]))
+		// attribute values are padded to a multiple of 4 bytes
+		paddedLength := (attrLength + 3) &^ 3
+		if 4+paddedLength > len(attrs) {
+			return nil, errMessage
+		}
+		value := attrs[4 : 4+attrLength]
+		attrs = attrs[4+paddedLength:]
+
+		var err error
+		switch attrType {
+		case attrMappedAddress:
+			mapped, err = parseAddress(value, nil)
+		case attrXORMappedAddress, attrXORMappedAddressOld:
+			xorMapped, err = parseAddress(value, data[4:headerSize])
+		case attrSoftware:
+			resp.software = string(value)
+		case attrErrorCode:
+			if len(value) < 4 {
+				return nil, errMessage
+			}
+			resp.errorCode = int(value[2]&0x7)*100 + int(value[3])
+			resp.errorReason = string(value[4:])
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	resp.mappedAddress = mapped
+	if len(xorMapped) > 0 {
+		resp.mappedAddress = xorMapped
+	}
+	if msgType == bindingError && resp.errorCode == 0 {
+		return nil, errMessage
+	}
+	return resp, nil
+}
+
+// parseAddress parses the value of the address attribute, the port and the address are obfuscated
+// with the magic cookie and the transaction ID if the key is set
+func parseAddress(value, key []byte) (string, error) {
+	if len(value) < 4 {
+		return "", errMessage
+	}
+	var ipSize int
+	switch value[1] {
+	case familyIPv4:
+		ipSize = net.IPv4len
+	case familyIPv6:
+		ipSize = net.IPv6len
+	default:
+		return "", fmt.Errorf("%w: unknown address family %d", errMessage, value[1])
+	}
+	if len(value) != 4+ipSize {
+		return "", errMessage
+	}
+	port := binary.BigEndian.Uint16(value[2:])
+	ip := make(net.IP, ipSize)
+	copy(ip, value[4:])
+	if key != nil {
+		port ^= binary.BigEndian.Uint16(key)
+		for i := range ip {
+			ip[i] ^= key[i]
+		}
+	}
+	return net.JoinHostPort(ip.String(), strconv.Itoa(int(port))), nil
+}
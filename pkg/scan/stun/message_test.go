@@ -0,0 +1,155 @@
+package stun
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+var testTransactionID = [transactionSize]byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12}
+
+// bindingMessage returns the STUN message of the test transaction with attributes
+func bindingMessage(msgType uint16, attrs ...[]byte) []byte {
+	result := newBindingRequest(testTransactionID)
+	binary.BigEndian.PutUint16(result, msgType)
+	for _, attr := range attrs {
+		result = append(result, attr...)
+	}
+	binary.BigEndian.PutUint16(result[2:], uint16(len(result)-headerSize))
+	return result
+}
+
+// attribute returns the attribute with the value padded to a multiple of 4 bytes
+func attribute(attrType uint16, value []byte) []byte {
+	result := binary.BigEndian.AppendUint16(nil, attrType)
+	result = binary.BigEndian.AppendUint16(result, uint16(len(value)))
+	result = append(result, value...)
+	for len(result)%4 != 0 {
+		result = append(result, 0)
+	}
+	return result
+}
+
+func TestNewBindingRequest(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, []byte{
+		0x00, 0x01, 0x00, 0x00, 0x21, 0x12, 0xa4, 0x42,
+		0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x09, 0x0a, 0x0b, 0x0c,
+	}, newBindingRequest(testTransactionID))
+}
+
+func TestParseBindingResponse(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		data     []byte
+		expected *bindingResponse
+	}{
+		{
+			name: "XORMappedAddressIPv4",
+			data: bindingMessage(bindingSuccess,
+				// 192.0.2.1:32853 of RFC 5769 test vectors
+				attribute(attrXORMappedAddress, []byte{0x00, 0x01, 0xa1, 0x47, 0xe1, 0x12, 0xa6, 0x43}),
+				attribute(attrMappedAddress, []byte{0x00, 0x01, 0x0d, 0x96, 0x0a, 0x00, 0x00, 0x01}),
+				attribute(attrSoftware, []byte("test vector")),
+			),
+			expected: &bindingResponse{mappedAddress: "192.0.2.1:32853", software: "test vector"},
+		},
+		{
+			name: "XORMappedAddressIPv6",
+			data: bindingMessage(bindingSuccess,
+				// the address of RFC 5769 test vectors obfuscated with the test transaction ID
+				attribute(attrXORMappedAddress, []byte{
+					0x00, 0x02, 0xa1, 0x47,
+					0x01, 0x13, 0xa9, 0xfa, 0x13, 0x36, 0x55, 0x7c, 0x05, 0x17, 0x25, 0x3b, 0x4d, 0x5f, 0x6d, 0x7b,
+				}),
+			),
+			expected: &bindingResponse{mappedAddress: "[2001:db8:1234:5678:11:2233:4455:6677]:32853"},
+		},
+		{
+			name: "XORMappedAddressOld",
+			data: bindingMessage(bindingSuccess,
+				attribute(attrXORMappedAddressOld, []byte{0x00, 0x01, 0xa1, 0x47, 0xe1, 0x12, 0xa6, 0x43}),
+			),
+			expected: &bindingResponse{mappedAddress: "192.0.2.1:32853"},
+		},
+		{
+			name: "MappedAddress",
+			data: bindingMessage(bindingSuccess,
+				attribute(attrMappedAddress, []byte{0x00, 0x01, 0x0d, 0x96, 0x0a, 0x00, 0x00, 0x01}),
+			),
+			expected: &bindingResponse{mappedAddress: "10.0.0.1:3478"},
+		},
+		{
+			name: "Error",
+			data: bindingMessage(bindingError,
+				attribute(attrErrorCode, append([]byte{0x00, 0x00, 0x04, 0x01}, "Unauthorized"...)),
+				attribute(attrSoftware, []byte("coturn")),
+			),
+			expected: &bindingResponse{software: "coturn", errorCode: 401, errorReason: "Unauthorized"},
+		},
+	}
+
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			resp, err := parseBindingResponse(tt.data, testTransactionID)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, resp)
+		})
+	}
+}
+
+func TestParseBindingResponseInvalid(t *testing.T) {
+	t.Parallel()
+
+	otherTransaction := bindingMessage(bindingSuccess)
+	otherTransaction[headerSize-1]++
+	truncated := bindingMessage(bindingSuccess, attribute(attrSoftware, []byte("coturn")))
+	truncated = truncated[:len(truncated)-4]
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{
+			name: "Short",
+			data: []byte{0x01, 0x01, 0x00, 0x00},
+		},
+		{
+			name: "Request",
+			data: bindingMessage(bindingRequest),
+		},
+		{
+			name: "OtherTransaction",
+			data: otherTransaction,
+		},
+		{
+			name: "Truncated",
+			data: truncated,
+		},
+		{
+			name: "InvalidAddress",
+			data: bindingMessage(bindingSuccess, attribute(attrXORMappedAddress, []byte{0x00, 0x03, 0xa1, 0x47})),
+		},
+		{
+			name: "ErrorWithoutCode",
+			data: bindingMessage(bindingError),
+		},
+	}
+
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := parseBindingResponse(tt.data, testTransactionID)
+			require.ErrorIs(t, err, errMessage)
+		})
+	}
+}
@@ -0,0 +1,163 @@
+package stun
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+const (
+	ScanType = "stun"
+
+	defaultDataTimeout = 1 * time.Second
+	defaultRetries     = 1
+	maxPacketSize      = 1500
+)
+
+type ScanResult struct {
+	ScanType string `json:"scan"`
+	IP       string `json:"ip"`
+	Port     uint16 `json:"port"`
+	// MappedAddress is the ip:port of the scanner as the server sees it, it reveals the NAT mapping of the scanner
+	MappedAddress string `json:"mapped_address,omitempty"`
+	Software      string `json:"software,omitempty"`
+	// Error is the code and the reason of the Binding Error Response
+	Error string `json:"error,omitempty"`
+}
+
+func (r *ScanResult) String() string {
+	result := fmt.Sprintf("%-20s %-5d", r.IP, r.Port)
+	if len(r.MappedAddress) > 0 {
+		result += " mapped " + r.MappedAddress
+	}
+	if len(r.Software) > 0 {
+		result += fmt.Sprintf(" software %q", r.Software)
+	}
+	if len(r.Error) > 0 {
+		result += fmt.Sprintf(" error %q", r.Error)
+	}
+	return result
+}
+
+func (r *ScanResult) ID() string {
+	return fmt.Sprintf("%s:%d", r.IP, r.Port)
+}
+
+func (r *ScanResult) MarshalJSON() ([]byte, error) {
+	// Type definition for the recursive call
+	type JScanResult ScanResult
+	// This works because JScanResult doesn't have a MarshalJSON function associated with it
+	return json.Marshal(JScanResult(*r))
+}
+
+// Scanner sends the STUN Binding Request to each target over UDP and reports servers that answered
+// with the Binding Response. Servers that require authentication answer with the Binding Error Response,
+// they are reported with the error.
+type Scanner struct {
+	dataTimeout time.Duration
+	retries     int
+}
+
+// Assert that stun.Scanner conforms to the scan.Scanner interface
+var _ scan.Scanner = (*Scanner)(nil)
+
+type ScannerOption func(*Scanner)
+
+// WithDataTimeout sets the time to wait for a response to each request
+func WithDataTimeout(timeout time.Duration) ScannerOption {
+	return func(s *Scanner) {
+		s.dataTimeout = timeout
+	}
+}
+
+// WithRetries sets the number of additional requests if there is no response
+func WithRetries(retries int) ScannerOption {
+	return func(s *Scanner) {
+		s.retries = retries
+	}
+}
+
+func NewScanner(opts ...ScannerOption) *Scanner {
+	s := &Scanner{
+		dataTimeout: defaultDataTimeout,
+		retries:     defaultRetries,
+	}
+	for _, o := range opts {
+		o(s)
+	}
+	return s
+}
+
+func (s *Scanner) Scan(ctx context.Context, r *scan.Request) (scan.Result, error) {
+	result, err := s.scanTarget(ctx, r)
+	return result, scan.WrapError(err)
+}
+
+func (s *Scanner) scanTarget(ctx context.Context, r *scan.Request) (result scan.Result, err error) {
+	addr := net.JoinHostPort(r.DstIP.String(), strconv.Itoa(int(r.DstPort)))
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "udp", addr)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	// retransmissions have the same transaction ID, so late responses to previous requests are matched
+	var transactionID [transactionSize]byte
+	if _, err = rand.Read(transactionID[:]); err != nil {
+		return
+	}
+	request := newBindingRequest(transactionID)
+	buf := make([]byte, maxPacketSize)
+	for i := 0; i <= s.retries; i++ {
+		if _, err = conn.Write(request); err != nil {
+			return
+		}
+		if err = conn.SetReadDeadline(time.Now().Add(s.dataTimeout)); err != nil {
+			return
+		}
+		var resp *bindingResponse
+		if resp, err = readResponse(conn, buf, transactionID); err == nil {
+			res := &ScanResult{
+				ScanType:      ScanType,
+				IP:            r.DstIP.String(),
+				Port:          r.DstPort,
+				MappedAddress: resp.mappedAddress,
+				Software:      resp.software,
+			}
+			if resp.errorCode != 0 {
+				res.Error = fmt.Sprintf("%d %s", resp.errorCode, resp.errorReason)
+			}
+			return res, nil
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		var netErr net.Error
+		// ICMP port unreachable and other errors are not retried
+		if !errors.As(err, &netErr) || !netErr.Timeout() {
+			return nil, err
+		}
+	}
+	return nil, nil
+}
+
+// readResponse reads datagrams until the response to the transaction arrives, other datagrams are skipped
+func readResponse(conn net.Conn, buf []byte, transactionID [transactionSize]byte) (*bindingResponse, error) {
+	for {
+		n, err := conn.Read(buf)
+		if err != nil {
+			return nil, err
+		}
+		if resp, err := parseBindingResponse(buf[:n], transactionID); err == nil {
+			return resp, nil
+		}
+	}
+}
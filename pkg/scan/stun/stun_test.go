@@ -0,0 +1,95 @@
+package stun
+
+import (
+	"context"
+	"encoding/binary"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+// startFakeServer starts UDP STUN server that answers Binding Requests with the XOR-MAPPED-ADDRESS of the client,
+// the silent server drops them like filtered ports
+func startFakeServer(t *testing.T, silent bool) *scan.Request {
+	t.Helper()
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	go func() {
+		buf := make([]byte, maxPacketSize)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			if silent || n < headerSize || binary.BigEndian.Uint16(buf) != bindingRequest {
+				continue
+			}
+			// the response to another transaction is skipped by the scanner
+			other := make([]byte, headerSize)
+			binary.BigEndian.PutUint16(other, bindingSuccess)
+			binary.BigEndian.PutUint32(other[4:], magicCookie)
+			_, _ = conn.WriteTo(other, addr)
+
+			udpAddr := addr.(*net.UDPAddr)
+			value := []byte{0, familyIPv4}
+			value = binary.BigEndian.AppendUint16(value, uint16(udpAddr.Port)^uint16(magicCookie>>16))
+			value = binary.BigEndian.AppendUint32(value, binary.BigEndian.Uint32(udpAddr.IP.To4())^magicCookie)
+			software := []byte("fake")
+			resp := append([]byte{}, buf[:headerSize]...)
+			binary.BigEndian.PutUint16(resp, bindingSuccess)
+			binary.BigEndian.PutUint16(resp[2:], uint16(4+len(value)+4+len(software)))
+			resp = binary.BigEndian.AppendUint16(resp, attrXORMappedAddress)
+			resp = binary.BigEndian.AppendUint16(resp, uint16(len(value)))
+			resp = append(resp, value...)
+			resp = binary.BigEndian.AppendUint16(resp, attrSoftware)
+			resp = binary.BigEndian.AppendUint16(resp, uint16(len(software)))
+			resp = append(resp, software...)
+			_, _ = conn.WriteTo(resp, addr)
+		}
+	}()
+	addr := conn.LocalAddr().(*net.UDPAddr)
+	return &scan.Request{DstIP: addr.IP, DstPort: uint16(addr.Port)}
+}
+
+func TestScan(t *testing.T) {
+	t.Parallel()
+
+	req := startFakeServer(t, false)
+	result, err := NewScanner(WithDataTimeout(time.Second)).Scan(context.Background(), req)
+	require.NoError(t, err)
+	require.NotNil(t, result)
+	stunResult := result.(*ScanResult)
+	require.Equal(t, ScanType, stunResult.ScanType)
+	require.Equal(t, req.DstIP.String(), stunResult.IP)
+	require.Equal(t, req.DstPort, stunResult.Port)
+	require.Equal(t, "fake", stunResult.Software)
+	host, _, err := net.SplitHostPort(stunResult.MappedAddress)
+	require.NoError(t, err)
+	require.Equal(t, "127.0.0.1", host)
+}
+
+func TestScanNoResponse(t *testing.T) {
+	t.Parallel()
+
+	req := startFakeServer(t, true)
+	result, err := NewScanner(WithDataTimeout(50*time.Millisecond)).Scan(context.Background(), req)
+	require.NoError(t, err)
+	require.Nil(t, result)
+}
+
+func TestScanResultString(t *testing.T) {
+	t.Parallel()
+
+	result := &ScanResult{ScanType: ScanType, IP: "192.168.0.1", Port: 3478,
+		MappedAddress: "203.0.113.5:40000", Software: "Coturn-4.5.2"}
+	require.Equal(t, `192.168.0.1          3478  mapped 203.0.113.5:40000 software "Coturn-4.5.2"`, result.String())
+	require.Equal(t, "192.168.0.1:3478", result.ID())
+
+	result = &ScanResult{ScanType: ScanType, IP: "192.168.0.2", Port: 3478, Error: "401 Unauthorized"}
+	require.Equal(t, `192.168.0.2          3478  error "401 Unauthorized"`, result.String())
+}
@@ -0,0 +1,184 @@
+package scan
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// defaultPolicyMaxQueued limits the number of held back requests of each rate limited policy
+const defaultPolicyMaxQueued = 100000
+
+// ErrPortNotAllowed is the error of skipped requests to ports that are not allowed by the target policy
+var ErrPortNotAllowed = errors.New("port is not allowed by target policy")
+
+// TargetPolicy restricts scanning of targets with matching request metadata, e.g. targets tagged as ics
+// are scanned with a low rate and a restricted set of ports in the same run as other targets
+type TargetPolicy struct {
+	Name string
+	// Meta are metadata conditions of matching targets, see MatchMeta
+	Meta map[string]string
+	// Interval is the minimum delay between requests of matching targets, zero if the rate is not limited
+	Interval time.Duration
+	// Retries is the number of additional scan attempts of failed requests
+	Retries int
+	// Ports are allowed ports of matching targets, all ports are allowed if empty
+	Ports []*PortRange
+}
+
+// AllowPort checks whether the port may be scanned on matching targets
+func (p *TargetPolicy) AllowPort(port uint16) bool {
+	if len(p.Ports) == 0 {
+		return true
+	}
+	for _, r := range p.Ports {
+		if r.StartPort <= port && port <= r.EndPort {
+			return true
+		}
+	}
+	return false
+}
+
+// matchPolicy returns the first policy matching the request, requests with errors don't match any policy
+func matchPolicy(policies []*TargetPolicy, r *Request) *TargetPolicy {
+	if r.Err != nil {
+		return nil
+	}
+	for _, p := range policies {
+		if MatchMeta(r.Meta, p.Meta) {
+			return p
+		}
+	}
+	return nil
+}
+
+type policyQueue struct {
+	policy   *TargetPolicy
+	requests []*Request
+	// next is the earliest time of the next request of the policy
+	next time.Time
+}
+
+// policyScheduler delays requests of targets matching rate limited policies and skips requests
+// to ports that are not allowed by policies, requests of other targets are not delayed
+type policyScheduler struct {
+	policies []*TargetPolicy
+	// maxPending limits the number of requests of other targets read ahead from the request generator
+	maxPending int
+	// maxQueued limits the number of held back requests of each rate limited policy.
+	// Requests are read while the queue has room, so that requests of other targets are not
+	// slowed down to the rate of the policy, reading is paused only when the queue is full.
+	maxQueued int
+	now       func() time.Time
+}
+
+func newPolicyScheduler(policies []*TargetPolicy, maxPending int) *policyScheduler {
+	return &policyScheduler{
+		policies:   policies,
+		maxPending: maxPending,
+		maxQueued:  defaultPolicyMaxQueued,
+		now:        time.Now,
+	}
+}
+
+// schedule reorders requests of the input channel to meet rate limits of policies
+func (s *policyScheduler) schedule(ctx context.Context, in <-chan *Request) <-chan *Request {
+	out := make(chan *Request)
+	go func() {
+		defer close(out)
+		queues := make(map[*TargetPolicy]*policyQueue, len(s.policies))
+		for _, p := range s.policies {
+			queues[p] = &policyQueue{policy: p}
+		}
+		// unlimited are requests of targets without rate limited policies
+		var unlimited []*Request
+		// parked is the request of the full policy queue, no more requests are read until the queue has room
+		var parked *Request
+		var parkedQueue *policyQueue
+		var pending int
+
+		for in != nil || pending > 0 {
+			if parked != nil && len(parkedQueue.requests) < s.maxQueued {
+				parkedQueue.requests = append(parkedQueue.requests, parked)
+				parked, parkedQueue = nil, nil
+			}
+			now := s.now()
+			var outc chan<- *Request
+			var next *Request
+			var nextQueue *policyQueue
+			var wait time.Duration
+			// rate limited requests go first when they are due, so other requests don't delay them
+			for _, p := range s.policies {
+				q := queues[p]
+				if len(q.requests) == 0 {
+					continue
+				}
+				if d := q.next.Sub(now); d > 0 {
+					if wait == 0 || d < wait {
+						wait = d
+					}
+					continue
+				}
+				if nextQueue == nil {
+					nextQueue = q
+				}
+			}
+			switch {
+			case nextQueue != nil:
+				next = nextQueue.requests[0]
+			case len(unlimited) > 0:
+				next = unlimited[0]
+			}
+			if next != nil {
+				outc = out
+			}
+			// wake up when the next rate limited request is due
+			var timerc <-chan time.Time
+			if next == nil && wait > 0 {
+				timerc = time.After(wait)
+			}
+			inc := in
+			if parked != nil || len(unlimited) >= s.maxPending {
+				inc = nil
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case r, ok := <-inc:
+				if !ok {
+					in = nil
+					continue
+				}
+				p := matchPolicy(s.policies, r)
+				switch {
+				case p == nil:
+					unlimited = append(unlimited, r)
+				case !p.AllowPort(r.DstPort):
+					// the skipped request is reported as the target error by the engine
+					skipped := *r
+					skipped.Err = fmt.Errorf("%w %q", ErrPortNotAllowed, p.Name)
+					unlimited = append(unlimited, &skipped)
+				case p.Interval == 0:
+					unlimited = append(unlimited, r)
+				case len(queues[p].requests) >= s.maxQueued:
+					parked, parkedQueue = r, queues[p]
+				default:
+					queues[p].requests = append(queues[p].requests, r)
+				}
+				pending++
+			case outc <- next:
+				if nextQueue != nil {
+					nextQueue.requests = nextQueue.requests[1:]
+					nextQueue.next = s.now().Add(nextQueue.policy.Interval)
+				} else {
+					unlimited = unlimited[1:]
+				}
+				pending--
+			case <-timerc:
+			}
+		}
+	}()
+	return out
+}
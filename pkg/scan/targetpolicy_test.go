@@ -0,0 +1,211 @@
+package scan
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTargetPolicyAllowPort(t *testing.T) {
+	t.Parallel()
+	p := &TargetPolicy{Ports: []*PortRange{{StartPort: 502, EndPort: 502}, {StartPort: 20000, EndPort: 20010}}}
+	require.True(t, p.AllowPort(502))
+	require.True(t, p.AllowPort(20005))
+	require.False(t, p.AllowPort(22))
+	require.True(t, (&TargetPolicy{}).AllowPort(22))
+}
+
+func TestMatchPolicy(t *testing.T) {
+	t.Parallel()
+	ics := &TargetPolicy{Name: "ics", Meta: map[string]string{"tags": "ics"}}
+	prod := &TargetPolicy{Name: "prod", Meta: map[string]string{"tags.env": "prod"}}
+	policies := []*TargetPolicy{ics, prod}
+
+	require.Equal(t, ics, matchPolicy(policies, &Request{Meta: map[string]interface{}{"tags": []string{"plc", "ics"}}}))
+	require.Equal(t, prod, matchPolicy(policies,
+		&Request{Meta: map[string]interface{}{"tags": map[string]string{"env": "prod"}}}))
+	require.Nil(t, matchPolicy(policies, &Request{Meta: map[string]interface{}{"tags": []string{"web"}}}))
+	require.Nil(t, matchPolicy(policies, &Request{}))
+	require.Nil(t, matchPolicy(policies, &Request{Err: errors.New("request error"),
+		Meta: map[string]interface{}{"tags": []string{"ics"}}}))
+}
+
+func TestPolicySchedulerPorts(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ics := &TargetPolicy{Name: "ics", Meta: map[string]string{"tags": "ics"},
+		Ports: []*PortRange{{StartPort: 502, EndPort: 502}}}
+	meta := map[string]interface{}{"tags": []string{"ics"}}
+	req1 := &Request{DstIP: net.IPv4(192, 168, 0, 1).To4(), DstPort: 22, Meta: meta}
+	req2 := &Request{DstIP: net.IPv4(192, 168, 0, 1).To4(), DstPort: 502, Meta: meta}
+	req3 := &Request{DstIP: net.IPv4(192, 168, 0, 2).To4(), DstPort: 22}
+
+	requests := newPolicyScheduler([]*TargetPolicy{ics}, 100).schedule(ctx, newRequestChan(req1, req2, req3))
+
+	// the request to the port that is not allowed is passed on with the error to be reported as skipped
+	skipped := readScheduledRequest(t, requests)
+	require.Equal(t, req1.DstIP, skipped.DstIP)
+	require.Equal(t, req1.DstPort, skipped.DstPort)
+	require.ErrorIs(t, skipped.Err, ErrPortNotAllowed)
+	require.Equal(t, `port is not allowed by target policy "ics"`, skipped.Err.Error())
+	require.Nil(t, req1.Err)
+	require.Equal(t, req2, readScheduledRequest(t, requests))
+	require.Equal(t, req3, readScheduledRequest(t, requests))
+	_, ok := <-requests
+	require.False(t, ok, "requests channel is not closed")
+}
+
+func TestPolicySchedulerRate(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	interval := 100 * time.Millisecond
+	ics := &TargetPolicy{Meta: map[string]string{"tags": "ics"}, Interval: interval}
+	meta := map[string]interface{}{"tags": []string{"ics"}}
+	var in []*Request
+	for port := uint16(1); port <= 3; port++ {
+		in = append(in, &Request{DstIP: net.IPv4(192, 168, 0, 1).To4(), DstPort: port, Meta: meta})
+	}
+	for port := uint16(1); port <= 3; port++ {
+		in = append(in, &Request{DstIP: net.IPv4(192, 168, 0, 2).To4(), DstPort: port})
+	}
+
+	start := time.Now()
+	requests := newPolicyScheduler([]*TargetPolicy{ics}, 100).schedule(ctx, newRequestChan(in...))
+	var limited []time.Duration
+	var unlimited int
+	for r := range requests {
+		if r.Meta == nil {
+			unlimited++
+			continue
+		}
+		limited = append(limited, time.Since(start))
+	}
+	require.Equal(t, 3, unlimited)
+	require.Len(t, limited, 3)
+	// other targets are not delayed by the rate limited ones
+	require.GreaterOrEqual(t, limited[2], 2*interval)
+	for i := 1; i < len(limited); i++ {
+		require.GreaterOrEqual(t, limited[i]-limited[i-1], interval-10*time.Millisecond)
+	}
+}
+
+func TestScanEngineWithTargetPolicyRetries(t *testing.T) {
+	t.Parallel()
+
+	done := make(chan interface{})
+	go func() {
+		defer close(done)
+
+		ctrl := gomock.NewController(t)
+		reqgen := NewMockRequestGenerator(ctrl)
+		scanner := NewMockScanner(ctrl)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		req1 := &Request{DstIP: net.IPv4(192, 168, 0, 1), DstPort: 502, Meta: map[string]interface{}{"tags": "ics"}}
+		req2 := &Request{DstIP: net.IPv4(192, 168, 0, 2), DstPort: 502}
+		reqgen.EXPECT().GenerateRequests(gomock.Not(gomock.Nil()), &Range{}).
+			Return(newRequestChan(req1, req2), nil)
+		gomock.InOrder(
+			scanner.EXPECT().Scan(gomock.Not(gomock.Nil()), req1).Return(nil, errors.New("scan error")),
+			scanner.EXPECT().Scan(gomock.Not(gomock.Nil()), req1).Return(&mockScanResult{"192.168.0.1:502"}, nil),
+		)
+		// requests of targets without policies are not retried
+		scanner.EXPECT().Scan(gomock.Not(gomock.Nil()), req2).Return(nil, errors.New("scan error"))
+
+		resultCh := NewResultChan(ctx, 10)
+		engine := NewScanEngine(reqgen, scanner, resultCh, WithTargetPolicies(
+			&TargetPolicy{Meta: map[string]string{"tags": "ics"}, Interval: time.Millisecond, Retries: 2}))
+
+		done, errc := engine.Start(ctx, &Range{})
+		<-done
+		var targetErr *TargetError
+		require.ErrorAs(t, <-errc, &targetErr)
+		require.Equal(t, req2.DstIP, targetErr.IP)
+		result := <-resultCh.Chan()
+		require.Equal(t, "192.168.0.1:502", result.ID())
+	}()
+	waitDone(t, done)
+}
+
+func TestPolicySchedulerQueueLimit(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ics := &TargetPolicy{Meta: map[string]string{"tags": "ics"}, Interval: time.Hour}
+	meta := map[string]interface{}{"tags": []string{"ics"}}
+	var in []*Request
+	for port := uint16(1); port <= 3; port++ {
+		in = append(in, &Request{DstIP: net.IPv4(192, 168, 0, 1).To4(), DstPort: port, Meta: meta})
+	}
+	for port := uint16(1); port <= 10; port++ {
+		in = append(in, &Request{DstIP: net.IPv4(192, 168, 0, 2).To4(), DstPort: port})
+	}
+
+	// held back requests of the policy don't count towards the read ahead of other targets
+	requests := newPolicyScheduler([]*TargetPolicy{ics}, 2).schedule(ctx, newRequestChan(in...))
+	require.Equal(t, in[0], readScheduledRequest(t, requests))
+	for _, r := range in[3:] {
+		require.Equal(t, r, readScheduledRequest(t, requests))
+	}
+}
+
+func TestPolicySchedulerSlowPolicy(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ics := &TargetPolicy{Meta: map[string]string{"tags": "ics"}, Interval: time.Second}
+	meta := map[string]interface{}{"tags": []string{"ics"}}
+	var in []*Request
+	for port := uint16(1); port <= 100; port++ {
+		in = append(in, &Request{DstIP: net.IPv4(192, 168, 0, 1).To4(), DstPort: port, Meta: meta},
+			&Request{DstIP: net.IPv4(192, 168, 0, 2).To4(), DstPort: port})
+	}
+
+	// the read ahead of other targets is small, but the queue of the policy at 1/s doesn't stop reading
+	requests := newPolicyScheduler([]*TargetPolicy{ics}, 2).schedule(ctx, newRequestChan(in...))
+	start := time.Now()
+	var unlimited int
+	for unlimited < 100 {
+		if r := readScheduledRequest(t, requests); r.Meta == nil {
+			unlimited++
+		}
+	}
+	require.Less(t, time.Since(start), time.Second)
+}
+
+func TestPolicySchedulerFullQueue(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ics := &TargetPolicy{Meta: map[string]string{"tags": "ics"}, Interval: time.Hour}
+	meta := map[string]interface{}{"tags": []string{"ics"}}
+	var in []*Request
+	for port := uint16(1); port <= 4; port++ {
+		in = append(in, &Request{DstIP: net.IPv4(192, 168, 0, 1).To4(), DstPort: port, Meta: meta})
+	}
+	in = append(in, &Request{DstIP: net.IPv4(192, 168, 0, 2).To4(), DstPort: 1})
+
+	s := newPolicyScheduler([]*TargetPolicy{ics}, 2)
+	s.maxQueued = 2
+	requests := s.schedule(ctx, newRequestChan(in...))
+	require.Equal(t, in[0], readScheduledRequest(t, requests))
+	// reading is paused when the queue of the policy is full
+	select {
+	case r := <-requests:
+		require.Fail(t, "unexpected request", r)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
@@ -521,3 +521,21 @@ func BenchmarkTCPScanEngine(b *testing.B) {
 	})
 	<-done
 }
+
+func BenchmarkPacketFiller(b *testing.B) {
+	b.ReportAllocs()
+	filler := NewPacketFiller(WithSYN())
+	packet := gopacket.NewSerializeBuffer()
+	r := &scan.Request{
+		SrcIP:   net.IPv4(192, 168, 0, 3).To4(),
+		DstIP:   net.IPv4(192, 168, 0, 2).To4(),
+		SrcMAC:  net.HardwareAddr{0x1, 0x2, 0x3, 0x4, 0x5, 0x6},
+		DstMAC:  net.HardwareAddr{0x10, 0x11, 0x12, 0x13, 0x14, 0x15},
+		DstPort: 22,
+	}
+	for i := 0; i < b.N; i++ {
+		if err := filler.Fill(packet, r); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package tftp
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+// opcodes of TFTP packets, see RFC 1350
+const (
+	opRRQ   = 1
+	opDATA  = 3
+	opERROR = 5
+
+	// maxErrorCode is the last error code of RFC 1350 and RFC 2347, other codes are not meaningful
+	maxErrorCode = 8
+	errorAborted = 0
+
+	transferMode = "octet"
+)
+
+var errPacket = scan.NewError(scan.ErrParse, errors.New("invalid TFTP packet"))
+
+// errorMessages are descriptions of error codes for ERROR packets without the message
+var errorMessages = []string{
+	"Not defined", "File not found", "Access violation", "Disk full or allocation exceeded",
+	"Illegal TFTP operation", "Unknown transfer ID", "File already exists", "No such user",
+	"Option negotiation failed",
+}
+
+// readRequest returns the RRQ packet of the file in the octet mode
+func readRequest(file string) []byte {
+	result := make([]byte, 2, 2+len(file)+len(transferMode)+2)
+	binary.BigEndian.PutUint16(result, opRRQ)
+	result = append(result, file...)
+	result = append(result, 0)
+	result = append(result, transferMode...)
+	return append(result, 0)
+}
+
+// errorPacket returns the ERROR packet, it terminates the transfer of the file
+func errorPacket(code uint16, message string) []byte {
+	result := make([]byte, 4, 4+len(message)+1)
+	binary.BigEndian.PutUint16(result, opERROR)
+	binary.BigEndian.PutUint16(result[2:], code)
+	result = append(result, message...)
+	return append(result, 0)
+}
+
+type packet struct {
+	opcode uint16
+	// block and data are fields of DATA packets
+	block uint16
+	data  []byte
+	// errorCode and errorMessage are fields of ERROR packets
+	errorCode    uint16
+	errorMessage string
+}
+
+// parsePacket parses DATA and ERROR packets, other packets and unknown error codes are invalid
+func parsePacket(data []byte) (*packet, error) {
+	if len(data) < 4 {
+		return nil, errPacket
+	}
+	p := &packet{opcode: binary.BigEndian.Uint16(data)}
+	switch p.opcode {
+	case opDATA:
+		p.block = binary.BigEndian.Uint16(data[2:])
+		p.data = data[4:]
+	case opERROR:
+		p.errorCode = binary.BigEndian.Uint16(data[2:])
+		if p.errorCode > maxErrorCode {
+			return nil, errPacket
+		}
+		message := data[4:]
+		if i := bytes.IndexByte(message, 0); i >= 0 {
+			message = message[:i]
+		}
+		p.errorMessage = string(message)
+		if len(p.errorMessage) == 0 {
+			p.errorMessage = errorMessages[p.errorCode]
+		}
+	default:
+		return nil, errPacket
+	}
+	return p, nil
+}
@@ -0,0 +1,85 @@
+package tftp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadRequest(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, []byte("\x00\x01pxelinux.0\x00octet\x00"), readRequest("pxelinux.0"))
+}
+
+func TestErrorPacket(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, []byte("\x00\x05\x00\x00Transfer aborted\x00"), errorPacket(errorAborted, "Transfer aborted"))
+}
+
+func TestParsePacket(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		data     []byte
+		expected *packet
+	}{
+		{
+			name:     "Data",
+			data:     []byte("\x00\x03\x00\x01hostname R1\n"),
+			expected: &packet{opcode: opDATA, block: 1, data: []byte("hostname R1\n")},
+		},
+		{
+			name:     "Error",
+			data:     []byte("\x00\x05\x00\x02Permission denied\x00"),
+			expected: &packet{opcode: opERROR, errorCode: 2, errorMessage: "Permission denied"},
+		},
+		{
+			name:     "ErrorWithoutMessage",
+			data:     []byte("\x00\x05\x00\x01\x00"),
+			expected: &packet{opcode: opERROR, errorCode: 1, errorMessage: "File not found"},
+		},
+		{
+			name:     "ErrorWithoutTerminator",
+			data:     []byte("\x00\x05\x00\x01missing"),
+			expected: &packet{opcode: opERROR, errorCode: 1, errorMessage: "missing"},
+		},
+	}
+
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			p, err := parsePacket(tt.data)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, p)
+		})
+	}
+}
+
+func TestParsePacketInvalid(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{name: "Short", data: []byte{0x00, 0x03, 0x00}},
+		{name: "ReadRequest", data: readRequest("file")},
+		{name: "ErrorCode", data: []byte("\x00\x05\x00\x09unknown\x00")},
+		{name: "DNS", data: []byte{0x12, 0x34, 0x81, 0x80, 0x00, 0x01}},
+	}
+
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := parsePacket(tt.data)
+			require.ErrorIs(t, err, errPacket)
+		})
+	}
+}
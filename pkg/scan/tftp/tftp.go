@@ -0,0 +1,213 @@
+package tftp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+const (
+	ScanType = "tftp"
+
+	defaultDataTimeout = 1 * time.Second
+	defaultRetries     = 1
+	// maxPacketSize is the size of DATA packets with 512 bytes blocks, other packets are smaller
+	maxPacketSize = 516
+)
+
+// DefaultFiles are often served by TFTP servers of network boot and network devices
+var DefaultFiles = []string{"pxelinux.0", "startup-config", "running-config"}
+
+type ScanResult struct {
+	ScanType string  `json:"scan"`
+	IP       string  `json:"ip"`
+	Port     uint16  `json:"port"`
+	Files    []*File `json:"files"`
+}
+
+// File is the answer of the server to the read request of the file
+type File struct {
+	Name string `json:"name"`
+	// Readable is set if the server sent the first DATA block of the file
+	Readable bool `json:"readable"`
+	// ErrorCode and Error are fields of the ERROR packet, e.g. 1 File not found or 2 Access violation
+	ErrorCode uint16 `json:"error_code,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+func (r *ScanResult) String() string {
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "%-20s %-5d", r.IP, r.Port)
+	for _, f := range r.Files {
+		if f.Readable {
+			fmt.Fprintf(&buf, " %s:readable", f.Name)
+		} else {
+			fmt.Fprintf(&buf, " %s:%q", f.Name, f.Error)
+		}
+	}
+	return buf.String()
+}
+
+func (r *ScanResult) ID() string {
+	return fmt.Sprintf("%s:%d", r.IP, r.Port)
+}
+
+func (r *ScanResult) MarshalJSON() ([]byte, error) {
+	// Type definition for the recursive call
+	type JScanResult ScanResult
+	// This works because JScanResult doesn't have a MarshalJSON function associated with it
+	return json.Marshal(JScanResult(*r))
+}
+
+// Scanner sends read requests for each file over UDP. TFTP servers answer with the first DATA block of the file
+// or an ERROR packet, so servers are reported even if none of the files exist. Filtered ports and hosts without
+// the server don't answer the request of the first file, they are not reported and the other files are skipped.
+// Transfers are aborted with an ERROR packet after the first block.
+type Scanner struct {
+	files       []string
+	dataTimeout time.Duration
+	retries     int
+}
+
+// Assert that tftp.Scanner conforms to the scan.Scanner interface
+var _ scan.Scanner = (*Scanner)(nil)
+
+type ScannerOption func(*Scanner)
+
+// WithFiles sets names of files to read, DefaultFiles are used by default
+func WithFiles(files []string) ScannerOption {
+	return func(s *Scanner) {
+		s.files = files
+	}
+}
+
+// WithDataTimeout sets the time to wait for a response to each request
+func WithDataTimeout(timeout time.Duration) ScannerOption {
+	return func(s *Scanner) {
+		s.dataTimeout = timeout
+	}
+}
+
+// WithRetries sets the number of additional requests of the same file if there is no response
+func WithRetries(retries int) ScannerOption {
+	return func(s *Scanner) {
+		s.retries = retries
+	}
+}
+
+func NewScanner(opts ...ScannerOption) *Scanner {
+	s := &Scanner{
+		files:       DefaultFiles,
+		dataTimeout: defaultDataTimeout,
+		retries:     defaultRetries,
+	}
+	for _, o := range opts {
+		o(s)
+	}
+	return s
+}
+
+func (s *Scanner) Scan(ctx context.Context, r *scan.Request) (scan.Result, error) {
+	result, err := s.scanTarget(ctx, r)
+	return result, scan.WrapError(err)
+}
+
+func (s *Scanner) scanTarget(ctx context.Context, r *scan.Request) (result scan.Result, err error) {
+	dst := &net.UDPAddr{IP: r.DstIP, Port: int(r.DstPort)}
+
+	var res *ScanResult
+	for _, name := range s.files {
+		var file *File
+		if file, err = s.read(ctx, dst, name); err != nil {
+			return nil, err
+		}
+		if file == nil {
+			if res == nil {
+				return nil, nil
+			}
+			continue
+		}
+		if res == nil {
+			res = &ScanResult{
+				ScanType: ScanType,
+				IP:       r.DstIP.String(),
+				Port:     r.DstPort,
+			}
+		}
+		res.Files = append(res.Files, file)
+	}
+	if res == nil {
+		return nil, nil
+	}
+	return res, nil
+}
+
+// read sends the read request of the file and waits for the response, nil file means no answer.
+// Each file is requested from its own port, so late answers to previous requests are not mixed up
+func (s *Scanner) read(ctx context.Context, dst *net.UDPAddr, name string) (*File, error) {
+	// the server answers from another port (transfer ID), so the socket is not connected to the target
+	var lc net.ListenConfig
+	conn, err := lc.ListenPacket(ctx, "udp", "")
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	buf := make([]byte, maxPacketSize)
+	for i := 0; i <= s.retries; i++ {
+		if _, err = conn.WriteTo(readRequest(name), dst); err != nil {
+			return nil, err
+		}
+		if err = conn.SetReadDeadline(time.Now().Add(s.dataTimeout)); err != nil {
+			return nil, err
+		}
+		var p *packet
+		var src net.Addr
+		if p, src, err = readResponse(conn, buf, dst.IP); err == nil {
+			return s.file(conn, src, name, p)
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		var netErr net.Error
+		if !errors.As(err, &netErr) || !netErr.Timeout() {
+			return nil, err
+		}
+	}
+	return nil, nil
+}
+
+// file returns the answer to the read request, the transfer of the readable file is aborted
+func (*Scanner) file(conn net.PacketConn, src net.Addr, name string, p *packet) (*File, error) {
+	if p.opcode == opERROR {
+		return &File{Name: name, ErrorCode: p.errorCode, Error: p.errorMessage}, nil
+	}
+	if _, err := conn.WriteTo(errorPacket(errorAborted, "Transfer aborted"), src); err != nil {
+		return nil, err
+	}
+	return &File{Name: name, Readable: true}, nil
+}
+
+// readResponse reads datagrams until the DATA packet of the first block or the ERROR packet arrives
+// from the target IP, other datagrams are skipped
+func readResponse(conn net.PacketConn, buf []byte, ip net.IP) (*packet, net.Addr, error) {
+	for {
+		n, src, err := conn.ReadFrom(buf)
+		if err != nil {
+			return nil, nil, err
+		}
+		if addr, ok := src.(*net.UDPAddr); !ok || !addr.IP.Equal(ip) {
+			continue
+		}
+		p, err := parsePacket(buf[:n])
+		if err != nil || (p.opcode == opDATA && p.block != 1) {
+			continue
+		}
+		return p, src, nil
+	}
+}
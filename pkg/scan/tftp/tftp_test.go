@@ -0,0 +1,129 @@
+package tftp
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+type fakeServer struct {
+	conn net.PacketConn
+	// files are contents of readable files, other files are not found
+	files map[string]string
+	// denied files are answered with the access violation error
+	denied map[string]bool
+	silent bool
+	// aborts receives ERROR packets of clients sent to transfer ports
+	aborts chan []byte
+}
+
+// startFakeServer starts TFTP server that answers read requests from new ports like real servers
+func startFakeServer(t *testing.T, s *fakeServer) *scan.Request {
+	t.Helper()
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	s.conn = conn
+	s.aborts = make(chan []byte, 10)
+	go s.serve()
+	addr := conn.LocalAddr().(*net.UDPAddr)
+	return &scan.Request{DstIP: addr.IP, DstPort: uint16(addr.Port)}
+}
+
+func (s *fakeServer) serve() {
+	buf := make([]byte, maxPacketSize)
+	for {
+		n, addr, err := s.conn.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+		if s.silent || n < 2 || binary.BigEndian.Uint16(buf) != opRRQ {
+			continue
+		}
+		name := string(buf[2 : 2+bytes.IndexByte(buf[2:n], 0)])
+		transfer, err := net.ListenPacket("udp", "127.0.0.1:0")
+		if err != nil {
+			return
+		}
+		content, ok := s.files[name]
+		switch {
+		case ok:
+			data := append([]byte{0, opDATA, 0, 1}, content...)
+			_, _ = transfer.WriteTo(data, addr)
+			go s.readAbort(transfer)
+		case s.denied[name]:
+			_, _ = transfer.WriteTo(errorPacket(2, "Permission denied"), addr)
+			transfer.Close()
+		default:
+			_, _ = transfer.WriteTo(errorPacket(1, ""), addr)
+			transfer.Close()
+		}
+	}
+}
+
+func (s *fakeServer) readAbort(transfer net.PacketConn) {
+	defer transfer.Close()
+	_ = transfer.SetReadDeadline(time.Now().Add(time.Second))
+	buf := make([]byte, maxPacketSize)
+	if n, _, err := transfer.ReadFrom(buf); err == nil {
+		s.aborts <- buf[:n]
+	}
+}
+
+func TestScan(t *testing.T) {
+	t.Parallel()
+
+	server := &fakeServer{
+		files:  map[string]string{"startup-config": "hostname R1\n"},
+		denied: map[string]bool{"running-config": true},
+	}
+	req := startFakeServer(t, server)
+	s := NewScanner(WithDataTimeout(time.Second),
+		WithFiles([]string{"pxelinux.0", "startup-config", "running-config"}))
+	result, err := s.Scan(context.Background(), req)
+	require.NoError(t, err)
+	require.Equal(t, &ScanResult{
+		ScanType: ScanType,
+		IP:       req.DstIP.String(),
+		Port:     req.DstPort,
+		Files: []*File{
+			{Name: "pxelinux.0", ErrorCode: 1, Error: "File not found"},
+			{Name: "startup-config", Readable: true},
+			{Name: "running-config", ErrorCode: 2, Error: "Permission denied"},
+		},
+	}, result)
+
+	select {
+	case abort := <-server.aborts:
+		require.Equal(t, errorPacket(errorAborted, "Transfer aborted"), abort)
+	case <-time.After(time.Second):
+		require.Fail(t, "transfer is not aborted")
+	}
+}
+
+func TestScanNoResponse(t *testing.T) {
+	t.Parallel()
+
+	req := startFakeServer(t, &fakeServer{silent: true})
+	s := NewScanner(WithDataTimeout(50*time.Millisecond), WithRetries(0))
+	result, err := s.Scan(context.Background(), req)
+	require.NoError(t, err)
+	require.Nil(t, result)
+}
+
+func TestScanResultString(t *testing.T) {
+	t.Parallel()
+
+	result := &ScanResult{ScanType: ScanType, IP: "192.168.0.1", Port: 69, Files: []*File{
+		{Name: "pxelinux.0", Readable: true},
+		{Name: "startup-config", ErrorCode: 1, Error: "File not found"},
+	}}
+	require.Equal(t, `192.168.0.1          69    pxelinux.0:readable startup-config:"File not found"`, result.String())
+	require.Equal(t, "192.168.0.1:69", result.ID())
+}
@@ -0,0 +1,196 @@
+// Code generated by easyjson for marshaling/unmarshaling. DO NOT EDIT.
+
+package tls
+
+import (
+	json "encoding/json"
+	easyjson "github.com/mailru/easyjson"
+	jlexer "github.com/mailru/easyjson/jlexer"
+	jwriter "github.com/mailru/easyjson/jwriter"
+)
+
+// suppress unused package warning
+var (
+	_ *json.RawMessage
+	_ *jlexer.Lexer
+	_ *jwriter.Writer
+	_ easyjson.Marshaler
+)
+
+func easyjsonD3b49167DecodeGithubComVByteCpuSxPkgScanTls(in *jlexer.Lexer, out *ScanResult) {
+	isTopLevel := in.IsStart()
+	if in.IsNull() {
+		if isTopLevel {
+			in.Consumed()
+		}
+		in.Skip()
+		return
+	}
+	in.Delim('{')
+	for !in.IsDelim('}') {
+		key := in.UnsafeFieldName(false)
+		in.WantColon()
+		if in.IsNull() {
+			in.Skip()
+			in.WantComma()
+			continue
+		}
+		switch key {
+		case "scan":
+			out.ScanType = string(in.String())
+		case "ip":
+			out.IP = string(in.String())
+		case "port":
+			out.Port = uint16(in.Uint16())
+		case "subject":
+			out.Subject = string(in.String())
+		case "sans":
+			if in.IsNull() {
+				in.Skip()
+				out.SANs = nil
+			} else {
+				in.Delim('[')
+				if out.SANs == nil {
+					if !in.IsDelim(']') {
+						out.SANs = make([]string, 0, 4)
+					} else {
+						out.SANs = []string{}
+					}
+				} else {
+					out.SANs = (out.SANs)[:0]
+				}
+				for !in.IsDelim(']') {
+					var v1 string
+					v1 = string(in.String())
+					out.SANs = append(out.SANs, v1)
+					in.WantComma()
+				}
+				in.Delim(']')
+			}
+		case "issuer":
+			out.Issuer = string(in.String())
+		case "not_before":
+			if data := in.Raw(); in.Ok() {
+				in.AddError((out.NotBefore).UnmarshalJSON(data))
+			}
+		case "not_after":
+			if data := in.Raw(); in.Ok() {
+				in.AddError((out.NotAfter).UnmarshalJSON(data))
+			}
+		case "days_left":
+			out.DaysLeft = int(in.Int())
+		case "expiring":
+			out.Expiring = bool(in.Bool())
+		case "version":
+			out.Version = string(in.String())
+		case "cipher":
+			out.Cipher = string(in.String())
+		default:
+			in.SkipRecursive()
+		}
+		in.WantComma()
+	}
+	in.Delim('}')
+	if isTopLevel {
+		in.Consumed()
+	}
+}
+func easyjsonD3b49167EncodeGithubComVByteCpuSxPkgScanTls(out *jwriter.Writer, in ScanResult) {
+	out.RawByte('{')
+	first := true
+	_ = first
+	{
+		const prefix string = ",\"scan\":"
+		out.RawString(prefix[1:])
+		out.String(string(in.ScanType))
+	}
+	{
+		const prefix string = ",\"ip\":"
+		out.RawString(prefix)
+		out.String(string(in.IP))
+	}
+	{
+		const prefix string = ",\"port\":"
+		out.RawString(prefix)
+		out.Uint16(uint16(in.Port))
+	}
+	{
+		const prefix string = ",\"subject\":"
+		out.RawString(prefix)
+		out.String(string(in.Subject))
+	}
+	if len(in.SANs) != 0 {
+		const prefix string = ",\"sans\":"
+		out.RawString(prefix)
+		{
+			out.RawByte('[')
+			for v2, v3 := range in.SANs {
+				if v2 > 0 {
+					out.RawByte(',')
+				}
+				out.String(string(v3))
+			}
+			out.RawByte(']')
+		}
+	}
+	{
+		const prefix string = ",\"issuer\":"
+		out.RawString(prefix)
+		out.String(string(in.Issuer))
+	}
+	{
+		const prefix string = ",\"not_before\":"
+		out.RawString(prefix)
+		out.Raw((in.NotBefore).MarshalJSON())
+	}
+	{
+		const prefix string = ",\"not_after\":"
+		out.RawString(prefix)
+		out.Raw((in.NotAfter).MarshalJSON())
+	}
+	{
+		const prefix string = ",\"days_left\":"
+		out.RawString(prefix)
+		out.Int(int(in.DaysLeft))
+	}
+	if in.Expiring {
+		const prefix string = ",\"expiring\":"
+		out.RawString(prefix)
+		out.Bool(bool(in.Expiring))
+	}
+	{
+		const prefix string = ",\"version\":"
+		out.RawString(prefix)
+		out.String(string(in.Version))
+	}
+	{
+		const prefix string = ",\"cipher\":"
+		out.RawString(prefix)
+		out.String(string(in.Cipher))
+	}
+	out.RawByte('}')
+}
+
+// MarshalJSON supports json.Marshaler interface
+func (v ScanResult) MarshalJSON() ([]byte, error) {
+	w := jwriter.Writer{}
+	easyjsonD3b49167EncodeGithubComVByteCpuSxPkgScanTls(&w, v)
+	return w.Buffer.BuildBytes(), w.Error
+}
+
+// MarshalEasyJSON supports easyjson.Marshaler interface
+func (v ScanResult) MarshalEasyJSON(w *jwriter.Writer) {
+	easyjsonD3b49167EncodeGithubComVByteCpuSxPkgScanTls(w, v)
+}
+
+// UnmarshalJSON supports json.Unmarshaler interface
+func (v *ScanResult) UnmarshalJSON(data []byte) error {
+	r := jlexer.Lexer{Data: data}
+	easyjsonD3b49167DecodeGithubComVByteCpuSxPkgScanTls(&r, v)
+	return r.Error()
+}
+
+// UnmarshalEasyJSON supports easyjson.Unmarshaler interface
+func (v *ScanResult) UnmarshalEasyJSON(l *jlexer.Lexer) {
+	easyjsonD3b49167DecodeGithubComVByteCpuSxPkgScanTls(l, v)
+}
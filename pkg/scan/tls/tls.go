@@ -0,0 +1,174 @@
+//go:generate easyjson -output_filename result_easyjson.go tls.go
+
+package tls
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"time"
+
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+const (
+	ScanType = "tls"
+
+	defaultDialTimeout     = 2 * time.Second
+	defaultDataTimeout     = 2 * time.Second
+	defaultExpiryThreshold = 30 * 24 * time.Hour
+)
+
+var errNoCertificate = errors.New("no peer certificate")
+
+var versionNames = map[uint16]string{
+	tls.VersionTLS10: "TLS 1.0",
+	tls.VersionTLS11: "TLS 1.1",
+	tls.VersionTLS12: "TLS 1.2",
+	tls.VersionTLS13: "TLS 1.3",
+}
+
+//easyjson:json
+type ScanResult struct {
+	ScanType  string    `json:"scan"`
+	IP        string    `json:"ip"`
+	Port      uint16    `json:"port"`
+	Subject   string    `json:"subject"`
+	SANs      []string  `json:"sans,omitempty"`
+	Issuer    string    `json:"issuer"`
+	NotBefore time.Time `json:"not_before"`
+	NotAfter  time.Time `json:"not_after"`
+	DaysLeft  int       `json:"days_left"`
+	Expiring  bool      `json:"expiring,omitempty"`
+	// Version is the negotiated TLS version
+	Version string `json:"version"`
+	// Cipher is the negotiated cipher suite
+	Cipher string `json:"cipher"`
+}
+
+func (r *ScanResult) String() string {
+	return fmt.Sprintf("%-20s %-5d %-5d %s", r.IP, r.Port, r.DaysLeft, r.Subject)
+}
+
+func (r *ScanResult) ID() string {
+	return fmt.Sprintf("%s:%d", r.IP, r.Port)
+}
+
+// Expiring reports whether the result is a TLS certificate
+// that expires within the scanner expiry threshold or has already expired.
+func Expiring(result scan.Result) bool {
+	r, ok := scan.UnwrapResult(result).(*ScanResult)
+	return ok && r.Expiring
+}
+
+type Scanner struct {
+	dialer          *net.Dialer
+	dataTimeout     time.Duration
+	expiryThreshold time.Duration
+	now             func() time.Time
+}
+
+// Assert that tls.Scanner conforms to the scan.Scanner interface
+var _ scan.Scanner = (*Scanner)(nil)
+
+type ScannerOption func(*Scanner)
+
+func WithDialTimeout(timeout time.Duration) ScannerOption {
+	return func(s *Scanner) {
+		s.dialer.Timeout = timeout
+	}
+}
+
+func WithDataTimeout(timeout time.Duration) ScannerOption {
+	return func(s *Scanner) {
+		s.dataTimeout = timeout
+	}
+}
+
+// WithExpiryThreshold sets the remaining validity period below which
+// a certificate is flagged as expiring.
+func WithExpiryThreshold(threshold time.Duration) ScannerOption {
+	return func(s *Scanner) {
+		s.expiryThreshold = threshold
+	}
+}
+
+func NewScanner(opts ...ScannerOption) *Scanner {
+	s := &Scanner{
+		dialer: &net.Dialer{
+			Timeout: defaultDialTimeout,
+		},
+		dataTimeout:     defaultDataTimeout,
+		expiryThreshold: defaultExpiryThreshold,
+		now:             time.Now,
+	}
+	for _, o := range opts {
+		o(s)
+	}
+	return s
+}
+
+func (s *Scanner) Scan(ctx context.Context, r *scan.Request) (scan.Result, error) {
+	result, err := s.scanTarget(ctx, r)
+	return result, scan.WrapError(err)
+}
+
+func (s *Scanner) scanTarget(ctx context.Context, r *scan.Request) (result scan.Result, err error) {
+	var conn net.Conn
+	if conn, err = s.dialer.DialContext(ctx, "tcp", fmt.Sprintf("%s:%d", r.DstIP, r.DstPort)); err != nil {
+		return
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithTimeout(ctx, s.dataTimeout)
+	defer cancel()
+	tlsConn := tls.Client(conn, &tls.Config{
+		// we are interested in certificates themselves, not in their validity for this connection
+		InsecureSkipVerify: true,
+	})
+	if err = tlsConn.HandshakeContext(ctx); err != nil {
+		return
+	}
+
+	state := tlsConn.ConnectionState()
+	certs := state.PeerCertificates
+	if len(certs) == 0 {
+		return nil, errNoCertificate
+	}
+	cert := certs[0]
+	left := cert.NotAfter.Sub(s.now())
+	result = &ScanResult{
+		ScanType:  ScanType,
+		IP:        r.DstIP.String(),
+		Port:      r.DstPort,
+		Subject:   cert.Subject.String(),
+		SANs:      subjectAltNames(cert),
+		Issuer:    cert.Issuer.String(),
+		NotBefore: cert.NotBefore,
+		NotAfter:  cert.NotAfter,
+		DaysLeft:  int(math.Floor(left.Hours() / 24)),
+		Expiring:  left < s.expiryThreshold,
+		Version:   versionName(state.Version),
+		Cipher:    tls.CipherSuiteName(state.CipherSuite),
+	}
+	return
+}
+
+func subjectAltNames(cert *x509.Certificate) (names []string) {
+	names = append(names, cert.DNSNames...)
+	for _, ip := range cert.IPAddresses {
+		names = append(names, ip.String())
+	}
+	return
+}
+
+func versionName(version uint16) string {
+	if name, ok := versionNames[version]; ok {
+		return name
+	}
+	return fmt.Sprintf("0x%04X", version)
+}
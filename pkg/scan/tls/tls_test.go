@@ -0,0 +1,127 @@
+package tls
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+func newTLSServerRequest(t *testing.T) (*httptest.Server, *scan.Request) {
+	t.Helper()
+	srv := httptest.NewTLSServer(http.NotFoundHandler())
+	addr := srv.Listener.Addr().(*net.TCPAddr)
+	return srv, &scan.Request{DstIP: addr.IP, DstPort: uint16(addr.Port)}
+}
+
+func TestScanCertificate(t *testing.T) {
+	t.Parallel()
+	srv, req := newTLSServerRequest(t)
+	defer srv.Close()
+
+	s := NewScanner()
+	result, err := s.Scan(context.Background(), req)
+	require.NoError(t, err)
+
+	cert := srv.Certificate()
+	sans := append([]string{}, cert.DNSNames...)
+	for _, ip := range cert.IPAddresses {
+		sans = append(sans, ip.String())
+	}
+	require.Contains(t, sans, "127.0.0.1")
+	require.Equal(t, &ScanResult{
+		ScanType:  ScanType,
+		IP:        req.DstIP.String(),
+		Port:      req.DstPort,
+		Subject:   cert.Subject.String(),
+		SANs:      sans,
+		Issuer:    cert.Issuer.String(),
+		NotBefore: cert.NotBefore,
+		NotAfter:  cert.NotAfter,
+		DaysLeft:  result.(*ScanResult).DaysLeft,
+		Version:   "TLS 1.3",
+		Cipher:    result.(*ScanResult).Cipher,
+	}, result)
+	require.NotEmpty(t, result.(*ScanResult).Cipher)
+	require.Greater(t, result.(*ScanResult).DaysLeft, 0)
+	require.False(t, Expiring(result))
+}
+
+func TestScanNegotiatedParameters(t *testing.T) {
+	t.Parallel()
+	srv := httptest.NewUnstartedServer(http.NotFoundHandler())
+	srv.TLS = &tls.Config{
+		MaxVersion:   tls.VersionTLS12,
+		CipherSuites: []uint16{tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256},
+	}
+	srv.StartTLS()
+	defer srv.Close()
+	addr := srv.Listener.Addr().(*net.TCPAddr)
+
+	s := NewScanner()
+	result, err := s.Scan(context.Background(), &scan.Request{DstIP: addr.IP, DstPort: uint16(addr.Port)})
+	require.NoError(t, err)
+	require.Equal(t, "TLS 1.2", result.(*ScanResult).Version)
+	require.Equal(t, "TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256", result.(*ScanResult).Cipher)
+}
+
+func TestVersionName(t *testing.T) {
+	t.Parallel()
+	require.Equal(t, "TLS 1.0", versionName(tls.VersionTLS10))
+	require.Equal(t, "TLS 1.3", versionName(tls.VersionTLS13))
+	require.Equal(t, "0x0300", versionName(0x0300))
+}
+
+func TestScanExpiringCertificate(t *testing.T) {
+	t.Parallel()
+	srv, req := newTLSServerRequest(t)
+	defer srv.Close()
+
+	notAfter := srv.Certificate().NotAfter
+	tests := []struct {
+		name     string
+		now      time.Time
+		daysLeft int
+	}{
+		{
+			name:     "ExpiresSoon",
+			now:      notAfter.Add(-10*24*time.Hour - time.Hour),
+			daysLeft: 10,
+		},
+		{
+			name:     "AlreadyExpired",
+			now:      notAfter.Add(3*24*time.Hour - time.Hour),
+			daysLeft: -3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewScanner(WithExpiryThreshold(30 * 24 * time.Hour))
+			s.now = func() time.Time { return tt.now }
+
+			result, err := s.Scan(context.Background(), req)
+			require.NoError(t, err)
+			require.Equal(t, tt.daysLeft, result.(*ScanResult).DaysLeft)
+			require.True(t, Expiring(result))
+			require.True(t, Expiring(&scan.MetaResult{Result: result}))
+		})
+	}
+}
+
+func TestScanNoTLS(t *testing.T) {
+	t.Parallel()
+	srv := httptest.NewServer(http.NotFoundHandler())
+	defer srv.Close()
+	addr := srv.Listener.Addr().(*net.TCPAddr)
+
+	s := NewScanner(WithDataTimeout(time.Second))
+	result, err := s.Scan(context.Background(), &scan.Request{DstIP: addr.IP, DstPort: uint16(addr.Port)})
+	require.Error(t, err)
+	require.Nil(t, result)
+}
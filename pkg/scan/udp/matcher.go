@@ -0,0 +1,141 @@
+package udp
+
+import (
+	"encoding/binary"
+	"errors"
+	"sort"
+)
+
+// ErrMatcher is returned for unknown matcher names
+var ErrMatcher = errors.New("unknown UDP response matcher")
+
+// MatchFunc validates the payload of the UDP response
+type MatchFunc func(payload []byte) bool
+
+// Matcher reports the port as open only if the response is valid for the protocol,
+// so that arbitrary datagrams, e.g. from middleboxes, are not mistaken for open ports
+type Matcher struct {
+	Name string
+	// Probe is the payload that elicits the response of the protocol,
+	// the payload of the scan is sent if it is empty
+	Probe []byte
+	Match MatchFunc
+}
+
+const dnsProbeID = 0x5358
+
+var matchers = map[string]*Matcher{
+	"dns": {
+		Name: "dns",
+		// NS query of the root zone with the recursion desired flag
+		Probe: []byte{
+			dnsProbeID >> 8, dnsProbeID & 0xff, 0x01, 0x00,
+			0x00, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+			0x00, 0x00, 0x02, 0x00, 0x01},
+		Match: matchDNS,
+	},
+	"ntp": {
+		Name:  "ntp",
+		Probe: ntpProbe(),
+		Match: matchNTP,
+	},
+	"snmp": {
+		Name: "snmp",
+		// SNMPv2c GetRequest of sysDescr.0 with the public community
+		Probe: []byte{
+			0x30, 0x26, 0x02, 0x01, 0x01, 0x04, 0x06, 'p', 'u', 'b', 'l', 'i', 'c',
+			0xa0, 0x19, 0x02, 0x01, 0x01, 0x02, 0x01, 0x00, 0x02, 0x01, 0x00,
+			0x30, 0x0e, 0x30, 0x0c, 0x06, 0x08, 0x2b, 0x06, 0x01, 0x02, 0x01, 0x01, 0x01, 0x00, 0x05, 0x00},
+		Match: matchSNMP,
+	},
+	"generic-nonempty": {
+		Name:  "generic-nonempty",
+		Match: matchNonEmpty,
+	},
+}
+
+// GetMatcher returns the builtin matcher by the name: dns, ntp, snmp or generic-nonempty
+func GetMatcher(name string) (*Matcher, error) {
+	m, ok := matchers[name]
+	if !ok {
+		return nil, ErrMatcher
+	}
+	return m, nil
+}
+
+// MatcherNames returns sorted names of builtin matchers
+func MatcherNames() []string {
+	names := make([]string, 0, len(matchers))
+	for name := range matchers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// matchDNS checks that the payload is the response to the probe query
+func matchDNS(payload []byte) bool {
+	return len(payload) >= 12 && binary.BigEndian.Uint16(payload) == dnsProbeID && payload[2]&0x80 != 0
+}
+
+// ntpProbe returns the NTPv4 client request
+func ntpProbe() []byte {
+	probe := make([]byte, 48)
+	probe[0] = 4<<3 | 3
+	return probe
+}
+
+// matchNTP checks that the payload is the server mode packet
+func matchNTP(payload []byte) bool {
+	if len(payload) < 48 {
+		return false
+	}
+	version := (payload[0] >> 3) & 0x07
+	return payload[0]&0x07 == 4 && version >= 1 && version <= 4
+}
+
+// matchSNMP checks that the payload is the SNMP message with the GetResponse PDU
+func matchSNMP(payload []byte) bool {
+	msg, _, ok := berNext(payload, 0x30)
+	if !ok {
+		return false
+	}
+	// version
+	if _, msg, ok = berNext(msg, 0x02); !ok {
+		return false
+	}
+	// community
+	if _, msg, ok = berNext(msg, 0x04); !ok {
+		return false
+	}
+	_, _, ok = berNext(msg, 0xa2)
+	return ok
+}
+
+// berNext returns the value of the first BER element with the tag and the data after it
+func berNext(data []byte, tag byte) (value, rest []byte, ok bool) {
+	if len(data) < 2 || data[0] != tag {
+		return nil, nil, false
+	}
+	length := int(data[1])
+	data = data[2:]
+	if length&0x80 != 0 {
+		n := length & 0x7f
+		if n == 0 || n > 2 || len(data) < n {
+			return nil, nil, false
+		}
+		length = 0
+		for _, b := range data[:n] {
+			length = length<<8 | int(b)
+		}
+		data = data[n:]
+	}
+	if len(data) < length {
+		return nil, nil, false
+	}
+	return data[:length], data[length:], true
+}
+
+func matchNonEmpty(payload []byte) bool {
+	return len(payload) > 0
+}
@@ -0,0 +1,55 @@
+package udp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetMatcher(t *testing.T) {
+	t.Parallel()
+	for _, name := range MatcherNames() {
+		m, err := GetMatcher(name)
+		require.NoError(t, err)
+		require.Equal(t, name, m.Name)
+	}
+	require.Equal(t, []string{"dns", "generic-nonempty", "ntp", "snmp"}, MatcherNames())
+
+	_, err := GetMatcher("unknown")
+	require.ErrorIs(t, err, ErrMatcher)
+}
+
+func TestMatchers(t *testing.T) {
+	t.Parallel()
+	dnsResponse := []byte{0x53, 0x58, 0x81, 0x80, 0x00, 0x01, 0x00, 0x00, 0x00, 0x0d, 0x00, 0x00}
+	ntpResponse := make([]byte, 48)
+	ntpResponse[0] = 0x24
+	snmpResponse := []byte{0x30, 0x0f, 0x02, 0x01, 0x01, 0x04, 0x06, 'p', 'u', 'b', 'l', 'i', 'c', 0xa2, 0x02, 0x30, 0x00}
+	tests := []struct {
+		name     string
+		matcher  string
+		payload  []byte
+		expected bool
+	}{
+		{name: "DNSResponse", matcher: "dns", payload: dnsResponse, expected: true},
+		{name: "DNSQuery", matcher: "dns", payload: matchers["dns"].Probe},
+		{name: "DNSOtherID", matcher: "dns", payload: append([]byte{0x12, 0x34}, dnsResponse[2:]...)},
+		{name: "DNSShort", matcher: "dns", payload: dnsResponse[:10]},
+		{name: "NTPResponse", matcher: "ntp", payload: ntpResponse, expected: true},
+		{name: "NTPRequest", matcher: "ntp", payload: matchers["ntp"].Probe},
+		{name: "NTPShort", matcher: "ntp", payload: ntpResponse[:40]},
+		{name: "SNMPResponse", matcher: "snmp", payload: snmpResponse, expected: true},
+		{name: "SNMPRequest", matcher: "snmp", payload: matchers["snmp"].Probe},
+		{name: "SNMPTruncated", matcher: "snmp", payload: snmpResponse[:14]},
+		{name: "SNMPGarbage", matcher: "snmp", payload: []byte("hello")},
+		{name: "NonEmpty", matcher: "generic-nonempty", payload: []byte{0}, expected: true},
+		{name: "Empty", matcher: "generic-nonempty", payload: []byte{}},
+	}
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			require.Equal(t, tt.expected, matchers[tt.matcher].Match(tt.payload))
+		})
+	}
+}
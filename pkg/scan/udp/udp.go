@@ -1,7 +1,11 @@
 package udp
 
 import (
+	"encoding/json"
+	"fmt"
 	"math/rand"
+	"sort"
+	"strings"
 
 	"github.com/google/gopacket"
 	"github.com/google/gopacket/layers"
@@ -12,10 +16,34 @@ import (
 
 const ScanType = "udp"
 
+// ScanResult is the open port that answered with the response accepted by the matcher of the port
+type ScanResult struct {
+	ScanType string `json:"scan"`
+	IP       string `json:"ip"`
+	Port     uint16 `json:"port"`
+	Matcher  string `json:"matcher"`
+}
+
+func (r *ScanResult) String() string {
+	return fmt.Sprintf("%-20s %-5d %s", r.IP, r.Port, r.Matcher)
+}
+
+func (r *ScanResult) ID() string {
+	return fmt.Sprintf("%s:%d", r.IP, r.Port)
+}
+
+func (r *ScanResult) MarshalJSON() ([]byte, error) {
+	// Type definition for the recursive call
+	type JScanResult ScanResult
+	// This works because JScanResult doesn't have a MarshalJSON function associated with it
+	return json.Marshal(JScanResult(*r))
+}
+
 // ScanMethod exploits RFC1122 Section 4.1.3.1:
 // If a datagram arrives addressed to a UDP port for which
 // there is no pending LISTEN call, UDP SHOULD send an ICMP
 // Port Unreachable message.
+// Ports with matchers are also reported as open if they answer with a valid response of the protocol.
 type ScanMethod struct {
 	scan.PacketSource
 	packet.Processor
@@ -25,12 +53,112 @@ type ScanMethod struct {
 // Assert that udp.ScanMethod conforms to the scan.PacketMethod interface
 var _ scan.PacketMethod = (*ScanMethod)(nil)
 
-func NewScanMethod(psrc scan.PacketSource, results scan.ResultChan, vpnMode bool) *ScanMethod {
-	pp := icmp.NewPacketProcessor(ScanType, results, vpnMode)
+type ScanMethodOption func(p *PacketProcessor)
+
+// WithMatchers sets response matchers by ports, UDP responses from other ports are ignored
+func WithMatchers(matchers map[uint16]*Matcher) ScanMethodOption {
+	return func(p *PacketProcessor) {
+		p.matchers = matchers
+	}
+}
+
+// WithHostPacer reports ICMP replies to the pacer that delays probes to hosts rate limiting ICMP replies
+func WithHostPacer(pacer *scan.HostPacer) ScanMethodOption {
+	return func(p *PacketProcessor) {
+		p.pacer = pacer
+	}
+}
+
+func NewScanMethod(psrc scan.PacketSource, results scan.ResultChan, vpnMode bool, opts ...ScanMethodOption) *ScanMethod {
+	pp := &PacketProcessor{results: results}
+	for _, o := range opts {
+		o(pp)
+	}
+	var icmpOpts []icmp.PacketProcessorOption
+	if pp.pacer != nil {
+		icmpOpts = append(icmpOpts, icmp.WithReplyObserver(pp.pacer))
+	}
+	icmpProcessor := icmp.NewPacketProcessor(ScanType, results, vpnMode, icmpOpts...)
+	pp.icmp = icmpProcessor
+	if len(pp.matchers) == 0 {
+		return &ScanMethod{
+			PacketSource: psrc,
+			Processor:    icmpProcessor,
+			Resulter:     icmpProcessor,
+		}
+	}
+
+	layerType := layers.LayerTypeEthernet
+	if vpnMode {
+		layerType = layers.LayerTypeIPv4
+	}
+	pp.parser = gopacket.NewDecodingLayerParser(layerType, &pp.rcvEth, &pp.rcvIP, &pp.rcvUDP)
+	pp.parser.IgnoreUnsupported = true
 	return &ScanMethod{
 		PacketSource: psrc,
 		Processor:    pp,
-		Resulter:     pp,
+		Resulter:     icmpProcessor,
+	}
+}
+
+// PacketProcessor reports UDP responses accepted by matchers of their ports,
+// other packets are processed as ICMP replies
+type PacketProcessor struct {
+	icmp     *icmp.PacketProcessor
+	results  scan.ResultChan
+	matchers map[uint16]*Matcher
+	pacer    *scan.HostPacer
+	parser   *gopacket.DecodingLayerParser
+
+	rcvDecoded []gopacket.LayerType
+	rcvEth     layers.Ethernet
+	rcvIP      layers.IPv4
+	rcvUDP     layers.UDP
+}
+
+func (p *PacketProcessor) ProcessPacketData(data []byte, ci *gopacket.CaptureInfo) (err error) {
+	if err = p.parser.DecodeLayers(data, &p.rcvDecoded); err != nil {
+		return
+	}
+	if len(p.rcvDecoded) == 0 || p.rcvDecoded[len(p.rcvDecoded)-1] != layers.LayerTypeUDP {
+		return p.icmp.ProcessPacketData(data, ci)
+	}
+	port := uint16(p.rcvUDP.SrcPort)
+	m, ok := p.matchers[port]
+	if !ok || !m.Match(p.rcvUDP.Payload) {
+		return
+	}
+	p.results.Put(&ScanResult{
+		ScanType: ScanType,
+		IP:       p.rcvIP.SrcIP.String(),
+		Port:     port,
+		Matcher:  m.Name,
+	})
+	return
+}
+
+// BPFFilter captures ICMP replies and, if there are matchers, UDP responses from their ports
+func BPFFilter(matchers map[uint16]*Matcher) func(r *scan.Range) (filter string, maxPacketLength int) {
+	return func(r *scan.Range) (filter string, maxPacketLength int) {
+		filter, maxPacketLength = icmp.BPFFilter(&scan.Range{})
+		if len(matchers) > 0 {
+			ports := make([]int, 0, len(matchers))
+			for port := range matchers {
+				ports = append(ports, int(port))
+			}
+			sort.Ints(ports)
+			var sb strings.Builder
+			fmt.Fprintf(&sb, "(%s", filter)
+			for _, port := range ports {
+				fmt.Fprintf(&sb, " or udp src port %d", port)
+			}
+			sb.WriteString(")")
+			filter = sb.String()
+		}
+		if r.DstSubnet != nil {
+			filter += " and ip src net " + r.DstSubnet.String()
+		}
+		return
 	}
 }
 
@@ -40,7 +168,9 @@ type PacketFiller struct {
 	proto   layers.IPProtocol
 	flags   layers.IPv4Flag
 	payload []byte
-	vpnMode bool
+	// portPayloads override the payload for specific ports, e.g. probes of matchers
+	portPayloads map[uint16][]byte
+	vpnMode      bool
 }
 
 // Assert that udp.PacketFiller conforms to the scan.PacketFiller interface
@@ -80,6 +210,13 @@ func WithPayload(payload []byte) PacketFillerOption {
 	}
 }
 
+// WithPortPayloads sets payloads of specific ports instead of the common payload
+func WithPortPayloads(payloads map[uint16][]byte) PacketFillerOption {
+	return func(f *PacketFiller) {
+		f.portPayloads = payloads
+	}
+}
+
 func WithVPNmode(vpnMode bool) PacketFillerOption {
 	return func(f *PacketFiller) {
 		f.vpnMode = vpnMode
@@ -128,17 +265,22 @@ func (f *PacketFiller) Fill(packet gopacket.SerializeBuffer, r *scan.Request) (e
 		return err
 	}
 
+	payload := f.payload
+	if portPayload, ok := f.portPayloads[r.DstPort]; ok {
+		payload = portPayload
+	}
+
 	opt := gopacket.SerializeOptions{ComputeChecksums: true}
 	if ip.Length == 0 {
 		opt.FixLengths = true
 	}
 	if f.vpnMode {
-		return gopacket.SerializeLayers(packet, opt, ip, udp, gopacket.Payload(f.payload))
+		return gopacket.SerializeLayers(packet, opt, ip, udp, gopacket.Payload(payload))
 	}
 	eth := &layers.Ethernet{
 		SrcMAC:       r.SrcMAC,
 		DstMAC:       r.DstMAC,
 		EthernetType: layers.EthernetTypeIPv4,
 	}
-	return gopacket.SerializeLayers(packet, opt, eth, ip, udp, gopacket.Payload(f.payload))
+	return gopacket.SerializeLayers(packet, opt, eth, ip, udp, gopacket.Payload(payload))
 }
@@ -369,3 +369,123 @@ func TestProcessPacketDataIPv4(t *testing.T) {
 		t.Fatal("test timeout")
 	}
 }
+
+func TestPacketFillerPortPayloads(t *testing.T) {
+	t.Parallel()
+
+	filler := NewPacketFiller(WithVPNmode(true), WithPayload([]byte{0x1}),
+		WithPortPayloads(map[uint16][]byte{53: {0x2, 0x3}}))
+	for port, expected := range map[uint16][]byte{53: {0x2, 0x3}, 54: {0x1}} {
+		packet := gopacket.NewSerializeBuffer()
+		err := filler.Fill(packet, &scan.Request{
+			SrcIP:   net.IPv4(192, 168, 0, 3).To4(),
+			DstIP:   net.IPv4(192, 168, 0, 2).To4(),
+			DstPort: port,
+		})
+		require.NoError(t, err)
+
+		resultPacket := gopacket.NewPacket(packet.Bytes(), layers.LayerTypeIPv4, gopacket.Default)
+		udpLayer := resultPacket.Layer(layers.LayerTypeUDP)
+		require.NotNil(t, udpLayer, "udp layer is empty")
+		require.Equal(t, expected, udpLayer.(*layers.UDP).Payload)
+	}
+}
+
+func udpResponsePacket(t *testing.T, srcPort uint16, payload []byte) []byte {
+	t.Helper()
+	packet := gopacket.NewSerializeBuffer()
+	ip := &layers.IPv4{
+		Version:  4,
+		TTL:      64,
+		Protocol: layers.IPProtocolUDP,
+		SrcIP:    net.IPv4(192, 168, 0, 2).To4(),
+		DstIP:    net.IPv4(192, 168, 0, 3).To4(),
+	}
+	udp := &layers.UDP{SrcPort: layers.UDPPort(srcPort), DstPort: 40000}
+	require.NoError(t, udp.SetNetworkLayerForChecksum(ip))
+	opt := gopacket.SerializeOptions{FixLengths: true, ComputeChecksums: true}
+	require.NoError(t, gopacket.SerializeLayers(packet, opt, ip, udp, gopacket.Payload(payload)))
+	return packet.Bytes()
+}
+
+func TestProcessPacketDataMatchers(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	results := scan.NewResultChan(ctx, 1000)
+	dnsMatcher, err := GetMatcher("dns")
+	require.NoError(t, err)
+	sm := NewScanMethod(nil, results, true, WithMatchers(map[uint16]*Matcher{53: dnsMatcher}))
+
+	dnsResponse := []byte{0x53, 0x58, 0x81, 0x80, 0x00, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}
+	// invalid response of the matched port
+	require.NoError(t, sm.ProcessPacketData(udpResponsePacket(t, 53, []byte("garbage")), &gopacket.CaptureInfo{}))
+	// port without the matcher
+	require.NoError(t, sm.ProcessPacketData(udpResponsePacket(t, 54, dnsResponse), &gopacket.CaptureInfo{}))
+	require.NoError(t, sm.ProcessPacketData(udpResponsePacket(t, 53, dnsResponse), &gopacket.CaptureInfo{}))
+
+	// ICMP replies are still reported
+	packet := gopacket.NewSerializeBuffer()
+	ip := &layers.IPv4{
+		Version:  4,
+		TTL:      64,
+		Protocol: layers.IPProtocolICMPv4,
+		SrcIP:    net.IPv4(192, 168, 0, 4).To4(),
+		DstIP:    net.IPv4(192, 168, 0, 3).To4(),
+	}
+	icmpLayer := &layers.ICMPv4{
+		TypeCode: layers.CreateICMPv4TypeCode(layers.ICMPv4TypeDestinationUnreachable, layers.ICMPv4CodePort),
+	}
+	opt := gopacket.SerializeOptions{FixLengths: true, ComputeChecksums: true}
+	require.NoError(t, gopacket.SerializeLayers(packet, opt, ip, icmpLayer))
+	require.NoError(t, sm.ProcessPacketData(packet.Bytes(), &gopacket.CaptureInfo{}))
+
+	select {
+	case result := <-sm.Results():
+		require.Equal(t, &ScanResult{ScanType: ScanType, IP: "192.168.0.2", Port: 53, Matcher: "dns"}, result)
+	case <-time.After(3 * time.Second):
+		t.Fatal("test timeout")
+	}
+	select {
+	case result := <-sm.Results():
+		icmpResult, ok := result.(*icmp.ScanResult)
+		require.True(t, ok)
+		require.Equal(t, "192.168.0.4", icmpResult.IP)
+	case <-time.After(3 * time.Second):
+		t.Fatal("test timeout")
+	}
+}
+
+func TestBPFFilter(t *testing.T) {
+	t.Parallel()
+
+	subnet := &net.IPNet{IP: net.IPv4(192, 168, 0, 0), Mask: net.CIDRMask(24, 32)}
+	filter, maxPacketLength := BPFFilter(nil)(&scan.Range{DstSubnet: subnet})
+	require.Equal(t, "icmp and icmp[0]!=8 and ip src net 192.168.0.0/24", filter)
+	require.Equal(t, icmp.MaxPacketLength, maxPacketLength)
+
+	filter, _ = BPFFilter(map[uint16]*Matcher{53: matchers["dns"], 123: matchers["ntp"]})(&scan.Range{DstSubnet: subnet})
+	require.Equal(t, "(icmp and icmp[0]!=8 or udp src port 53 or udp src port 123) and ip src net 192.168.0.0/24", filter)
+
+	filter, _ = BPFFilter(map[uint16]*Matcher{53: matchers["dns"]})(&scan.Range{})
+	require.Equal(t, "(icmp and icmp[0]!=8 or udp src port 53)", filter)
+}
+
+func BenchmarkPacketFiller(b *testing.B) {
+	b.ReportAllocs()
+	filler := NewPacketFiller()
+	packet := gopacket.NewSerializeBuffer()
+	r := &scan.Request{
+		SrcIP:   net.IPv4(192, 168, 0, 3).To4(),
+		DstIP:   net.IPv4(192, 168, 0, 2).To4(),
+		SrcMAC:  net.HardwareAddr{0x1, 0x2, 0x3, 0x4, 0x5, 0x6},
+		DstMAC:  net.HardwareAddr{0x10, 0x11, 0x12, 0x13, 0x14, 0x15},
+		DstPort: 22,
+	}
+	for i := 0; i < b.N; i++ {
+		if err := filler.Fill(packet, r); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
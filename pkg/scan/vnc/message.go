@@ -0,0 +1,125 @@
+package vnc
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+// RFB protocol fields, see RFC 6143 section 7.1
+const (
+	versionSize      = 12
+	maxReasonLength  = 1024
+	securityInvalid  = 0
+	securityNone     = 1
+	securityVNCAuth  = 2
+	minorVersion33   = 3
+	minorVersion37   = 7
+	minorVersion38   = 8
+	versionFormatRFB = "RFB %03d.%03d\n"
+)
+
+var errProtocolVersion = scan.NewError(scan.ErrParse, errors.New("invalid RFB protocol version"))
+
+// securityTypeNames are names of security types registered by IANA, other types are reported by numbers
+var securityTypeNames = map[byte]string{
+	securityNone:    "none",
+	securityVNCAuth: "vnc",
+	5:               "ra2",
+	6:               "ra2ne",
+	16:              "tight",
+	17:              "ultra",
+	18:              "tls",
+	19:              "vencrypt",
+	20:              "sasl",
+	21:              "md5",
+	22:              "xvp",
+	30:              "ard",
+}
+
+func securityTypeName(securityType byte) string {
+	if name, ok := securityTypeNames[securityType]; ok {
+		return name
+	}
+	return fmt.Sprintf("unknown(%d)", securityType)
+}
+
+// readProtocolVersion reads the ProtocolVersion message of the server, e.g. RFB 003.008
+func readProtocolVersion(r io.Reader) (major, minor int, err error) {
+	buf := make([]byte, versionSize)
+	if _, err = io.ReadFull(r, buf); err != nil {
+		return
+	}
+	if _, err = fmt.Sscanf(string(buf), versionFormatRFB, &major, &minor); err != nil {
+		return 0, 0, fmt.Errorf("%w: %q", errProtocolVersion, buf)
+	}
+	return
+}
+
+// clientMinorVersion returns the minor version of the 3.x protocol the client replies with,
+// non-standard versions like 3.5 of old servers and 3.889 of Apple Remote Desktop are mapped to the nearest known one
+func clientMinorVersion(major, minor int) int {
+	switch {
+	case major > 3 || minor >= minorVersion38:
+		return minorVersion38
+	case minor == minorVersion37:
+		return minorVersion37
+	default:
+		return minorVersion33
+	}
+}
+
+func writeProtocolVersion(w io.Writer, minor int) error {
+	_, err := fmt.Fprintf(w, versionFormatRFB, 3, minor)
+	return err
+}
+
+// readSecurityTypes reads security types offered by the server, the server of the 3.3 protocol
+// decides on the only security type itself. The reason is set if the server refused the connection.
+func readSecurityTypes(r io.Reader, minor int) (types []byte, reason string, err error) {
+	if minor == minorVersion33 {
+		buf := make([]byte, 4)
+		if _, err = io.ReadFull(r, buf); err != nil {
+			return
+		}
+		securityType := binary.BigEndian.Uint32(buf)
+		if securityType == securityInvalid {
+			reason, err = readReason(r)
+			return
+		}
+		return []byte{byte(securityType)}, "", nil
+	}
+
+	count := make([]byte, 1)
+	if _, err = io.ReadFull(r, count); err != nil {
+		return
+	}
+	if count[0] == 0 {
+		reason, err = readReason(r)
+		return
+	}
+	types = make([]byte, count[0])
+	if _, err = io.ReadFull(r, types); err != nil {
+		return nil, "", err
+	}
+	return
+}
+
+func readReason(r io.Reader) (string, error) {
+	buf := make([]byte, 4)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return "", err
+	}
+	length := binary.BigEndian.Uint32(buf)
+	if length > maxReasonLength {
+		length = maxReasonLength
+	}
+	reason := make([]byte, length)
+	if _, err := io.ReadFull(r, reason); err != nil {
+		return "", err
+	}
+	return string(reason), nil
+}
@@ -0,0 +1,113 @@
+package vnc
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadProtocolVersion(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		input string
+		major int
+		minor int
+	}{
+		{input: "RFB 003.008\n", major: 3, minor: 8},
+		{input: "RFB 003.003\n", major: 3, minor: 3},
+		{input: "RFB 003.889\n", major: 3, minor: 889},
+		{input: "RFB 004.001\n", major: 4, minor: 1},
+	}
+	for _, tt := range tests {
+		major, minor, err := readProtocolVersion(bytes.NewReader([]byte(tt.input)))
+		require.NoError(t, err, tt.input)
+		require.Equal(t, tt.major, major, tt.input)
+		require.Equal(t, tt.minor, minor, tt.input)
+	}
+}
+
+func TestReadProtocolVersionError(t *testing.T) {
+	t.Parallel()
+	_, _, err := readProtocolVersion(bytes.NewReader([]byte("HTTP/1.1 400 Bad Request\r\n")))
+	require.ErrorIs(t, err, errProtocolVersion)
+
+	_, _, err = readProtocolVersion(bytes.NewReader([]byte("RFB 003")))
+	require.Error(t, err)
+}
+
+func TestClientMinorVersion(t *testing.T) {
+	t.Parallel()
+	require.Equal(t, minorVersion38, clientMinorVersion(3, 8))
+	require.Equal(t, minorVersion38, clientMinorVersion(3, 889))
+	require.Equal(t, minorVersion38, clientMinorVersion(4, 1))
+	require.Equal(t, minorVersion37, clientMinorVersion(3, 7))
+	require.Equal(t, minorVersion33, clientMinorVersion(3, 5))
+	require.Equal(t, minorVersion33, clientMinorVersion(3, 3))
+}
+
+func TestWriteProtocolVersion(t *testing.T) {
+	t.Parallel()
+	var buf bytes.Buffer
+	require.NoError(t, writeProtocolVersion(&buf, minorVersion37))
+	require.Equal(t, "RFB 003.007\n", buf.String())
+}
+
+func TestReadSecurityTypes(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		minor    int
+		input    []byte
+		expected []byte
+		reason   string
+	}{
+		{
+			name:     "Version38",
+			minor:    minorVersion38,
+			input:    []byte{2, securityNone, securityVNCAuth},
+			expected: []byte{securityNone, securityVNCAuth},
+		},
+		{
+			name:   "Version38Failure",
+			minor:  minorVersion38,
+			input:  append([]byte{0, 0, 0, 0, 5}, "error"...),
+			reason: "error",
+		},
+		{
+			name:     "Version33",
+			minor:    minorVersion33,
+			input:    []byte{0, 0, 0, securityVNCAuth},
+			expected: []byte{securityVNCAuth},
+		},
+		{
+			name:   "Version33Failure",
+			minor:  minorVersion33,
+			input:  append([]byte{0, 0, 0, 0, 0, 0, 0, 19}, "Too many auth fails"...),
+			reason: "Too many auth fails",
+		},
+	}
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			types, reason, err := readSecurityTypes(bytes.NewReader(tt.input), tt.minor)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, types)
+			require.Equal(t, tt.reason, reason)
+		})
+	}
+}
+
+func TestReadSecurityTypesTruncated(t *testing.T) {
+	t.Parallel()
+	_, _, err := readSecurityTypes(bytes.NewReader([]byte{3, securityNone}), minorVersion38)
+	require.Error(t, err)
+}
+
+func TestSecurityTypeName(t *testing.T) {
+	t.Parallel()
+	require.Equal(t, "none", securityTypeName(securityNone))
+	require.Equal(t, "vencrypt", securityTypeName(19))
+	require.Equal(t, "unknown(100)", securityTypeName(100))
+}
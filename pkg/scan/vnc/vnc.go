@@ -0,0 +1,143 @@
+package vnc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+const (
+	ScanType = "vnc"
+
+	defaultDialTimeout = 2 * time.Second
+	defaultDataTimeout = 2 * time.Second
+)
+
+type ScanResult struct {
+	ScanType string `json:"scan"`
+	IP       string `json:"ip"`
+	Port     uint16 `json:"port"`
+	// Version is the RFB protocol version of the server, e.g. 3.8
+	Version string `json:"version"`
+	// SecurityTypes are offered by the server, e.g. none, vnc, tight, vencrypt
+	SecurityTypes []string `json:"security_types,omitempty"`
+	// NoAuth is set if the server allows connections without authentication
+	NoAuth bool `json:"no_auth"`
+	// Reason is sent by the server instead of security types if it refused the connection,
+	// e.g. after too many authentication failures
+	Reason string `json:"reason,omitempty"`
+}
+
+func (r *ScanResult) String() string {
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "%-20s %-5d RFB %s", r.IP, r.Port, r.Version)
+	if len(r.SecurityTypes) > 0 {
+		fmt.Fprintf(&buf, " %s", strings.Join(r.SecurityTypes, ","))
+	}
+	if r.NoAuth {
+		buf.WriteString(" no-auth")
+	}
+	if len(r.Reason) > 0 {
+		fmt.Fprintf(&buf, " %q", r.Reason)
+	}
+	return buf.String()
+}
+
+func (r *ScanResult) ID() string {
+	return fmt.Sprintf("%s:%d", r.IP, r.Port)
+}
+
+func (r *ScanResult) MarshalJSON() ([]byte, error) {
+	// Type definition for the recursive call
+	type JScanResult ScanResult
+	// This works because JScanResult doesn't have a MarshalJSON function associated with it
+	return json.Marshal(JScanResult(*r))
+}
+
+// Scanner reads the protocol version of VNC servers and security types they offer,
+// the connection is closed before authentication
+type Scanner struct {
+	dialer      *net.Dialer
+	dataTimeout time.Duration
+}
+
+// Assert that vnc.Scanner conforms to the scan.Scanner interface
+var _ scan.Scanner = (*Scanner)(nil)
+
+type ScannerOption func(*Scanner)
+
+func WithDialTimeout(timeout time.Duration) ScannerOption {
+	return func(s *Scanner) {
+		s.dialer.Timeout = timeout
+	}
+}
+
+func WithDataTimeout(timeout time.Duration) ScannerOption {
+	return func(s *Scanner) {
+		s.dataTimeout = timeout
+	}
+}
+
+func NewScanner(opts ...ScannerOption) *Scanner {
+	s := &Scanner{
+		dialer: &net.Dialer{
+			Timeout: defaultDialTimeout,
+		},
+		dataTimeout: defaultDataTimeout,
+	}
+	for _, o := range opts {
+		o(s)
+	}
+	return s
+}
+
+func (s *Scanner) Scan(ctx context.Context, r *scan.Request) (scan.Result, error) {
+	result, err := s.scanTarget(ctx, r)
+	return result, scan.WrapError(err)
+}
+
+func (s *Scanner) scanTarget(ctx context.Context, r *scan.Request) (scan.Result, error) {
+	addr := net.JoinHostPort(r.DstIP.String(), strconv.Itoa(int(r.DstPort)))
+	conn, err := s.dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	if err = conn.SetDeadline(time.Now().Add(s.dataTimeout)); err != nil {
+		return nil, err
+	}
+
+	major, minor, err := readProtocolVersion(conn)
+	if err != nil {
+		return nil, err
+	}
+	clientMinor := clientMinorVersion(major, minor)
+	if err = writeProtocolVersion(conn, clientMinor); err != nil {
+		return nil, err
+	}
+	types, reason, err := readSecurityTypes(conn, clientMinor)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ScanResult{
+		ScanType: ScanType,
+		IP:       r.DstIP.String(),
+		Port:     r.DstPort,
+		Version:  fmt.Sprintf("%d.%d", major, minor),
+		Reason:   reason,
+	}
+	for _, securityType := range types {
+		result.SecurityTypes = append(result.SecurityTypes, securityTypeName(securityType))
+		if securityType == securityNone {
+			result.NoAuth = true
+		}
+	}
+	return result, nil
+}
@@ -0,0 +1,141 @@
+package vnc
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+// serveVNC sends the version to each connection and the security message
+// after the client version, which is sent to the clientVersion channel
+func serveVNC(t *testing.T, version string, security []byte) (*scan.Request, <-chan string) {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { l.Close() })
+
+	clientVersion := make(chan string, 1)
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		if _, err = conn.Write([]byte(version)); err != nil {
+			return
+		}
+		buf := make([]byte, versionSize)
+		if _, err = io.ReadFull(conn, buf); err != nil {
+			return
+		}
+		clientVersion <- string(buf)
+		_, _ = conn.Write(security)
+	}()
+	addr := l.Addr().(*net.TCPAddr)
+	return &scan.Request{DstIP: addr.IP, DstPort: uint16(addr.Port)}, clientVersion
+}
+
+func TestScan(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name          string
+		version       string
+		security      []byte
+		clientVersion string
+		expected      *ScanResult
+	}{
+		{
+			name:          "NoAuth",
+			version:       "RFB 003.008\n",
+			security:      []byte{2, securityNone, securityVNCAuth},
+			clientVersion: "RFB 003.008\n",
+			expected: &ScanResult{
+				Version:       "3.8",
+				SecurityTypes: []string{"none", "vnc"},
+				NoAuth:        true,
+			},
+		},
+		{
+			name:          "AppleRemoteDesktop",
+			version:       "RFB 003.889\n",
+			security:      []byte{2, 30, securityVNCAuth},
+			clientVersion: "RFB 003.008\n",
+			expected: &ScanResult{
+				Version:       "3.889",
+				SecurityTypes: []string{"ard", "vnc"},
+			},
+		},
+		{
+			name:          "Version33",
+			version:       "RFB 003.003\n",
+			security:      []byte{0, 0, 0, securityVNCAuth},
+			clientVersion: "RFB 003.003\n",
+			expected: &ScanResult{
+				Version:       "3.3",
+				SecurityTypes: []string{"vnc"},
+			},
+		},
+		{
+			name:          "Failure",
+			version:       "RFB 003.007\n",
+			security:      append([]byte{0, 0, 0, 0, 19}, "Too many auth fails"...),
+			clientVersion: "RFB 003.007\n",
+			expected: &ScanResult{
+				Version: "3.7",
+				Reason:  "Too many auth fails",
+			},
+		},
+	}
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			req, clientVersion := serveVNC(t, tt.version, tt.security)
+			result, err := NewScanner().Scan(context.Background(), req)
+			require.NoError(t, err)
+			require.Equal(t, tt.clientVersion, <-clientVersion)
+
+			tt.expected.ScanType = ScanType
+			tt.expected.IP = req.DstIP.String()
+			tt.expected.Port = req.DstPort
+			require.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestScanNotVNCServer(t *testing.T) {
+	t.Parallel()
+	req, _ := serveVNC(t, "SSH-2.0-OpenSSH_8.4p1\r\n", nil)
+	result, err := NewScanner().Scan(context.Background(), req)
+	require.ErrorIs(t, err, errProtocolVersion)
+	require.Nil(t, result)
+}
+
+func TestScanTimeout(t *testing.T) {
+	t.Parallel()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+	done := make(chan interface{})
+	defer close(done)
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		<-done
+	}()
+	addr := l.Addr().(*net.TCPAddr)
+
+	s := NewScanner(WithDataTimeout(50 * time.Millisecond))
+	result, err := s.Scan(context.Background(), &scan.Request{DstIP: addr.IP, DstPort: uint16(addr.Port)})
+	require.Error(t, err)
+	require.ErrorIs(t, err, scan.ErrTimeout)
+	require.Nil(t, result)
+}
@@ -0,0 +1,234 @@
+package winrm
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+const (
+	ScanType = "winrm"
+
+	defaultDataTimeout = 5 * time.Second
+
+	// maxResponseSize limits the size of read responses, the IdentifyResponse is much smaller
+	maxResponseSize = 1 << 16
+
+	wsmanPath = "/wsman"
+	// identifyRequest is the WS-Management Identify request of DSP0226
+	identifyRequest = `<s:Envelope xmlns:s="http://www.w3.org/2003/05/soap-envelope" ` +
+		`xmlns:wsmid="http://schemas.dmtf.org/wbem/wsman/identity/1/wsmanidentity.xsd">` +
+		`<s:Header/><s:Body><wsmid:Identify/></s:Body></s:Envelope>`
+	authBasic = "Basic"
+)
+
+// DefaultTLSPorts are ports of the WinRM HTTPS listener
+var DefaultTLSPorts = []uint16{5986}
+
+var errNotWinRM = errors.New("not a WinRM endpoint")
+
+type ScanResult struct {
+	ScanType string `json:"scan"`
+	IP       string `json:"ip"`
+	Port     uint16 `json:"port"`
+	// Proto is http or https
+	Proto string `json:"proto"`
+	// ProtocolVersion, ProductVendor and ProductVersion are reported by endpoints that answer the Identify request
+	// without authentication
+	ProtocolVersion string `json:"protocol_version,omitempty"`
+	ProductVendor   string `json:"product_vendor,omitempty"`
+	ProductVersion  string `json:"product_version,omitempty"`
+	// Auth holds authentication schemes offered by the endpoint, e.g. Negotiate, Kerberos or Basic
+	Auth []string `json:"auth,omitempty"`
+	// Unencrypted is set if Basic authentication is offered over plain HTTP, so credentials and messages
+	// are sent unencrypted (AllowUnencrypted is enabled)
+	Unencrypted bool   `json:"unencrypted"`
+	Server      string `json:"server,omitempty"`
+}
+
+func (r *ScanResult) String() string {
+	var buf strings.Builder
+	fmt.Fprintf(&buf, "%-20s %-5d %s", r.IP, r.Port, r.Proto)
+	if len(r.ProductVersion) > 0 {
+		fmt.Fprintf(&buf, " %q", strings.TrimSpace(r.ProductVendor+" "+r.ProductVersion))
+	}
+	if len(r.Auth) > 0 {
+		fmt.Fprintf(&buf, " auth:%s", strings.Join(r.Auth, ","))
+	}
+	if r.Unencrypted {
+		buf.WriteString(" unencrypted")
+	}
+	return buf.String()
+}
+
+func (r *ScanResult) ID() string {
+	return fmt.Sprintf("%s:%d", r.IP, r.Port)
+}
+
+func (r *ScanResult) MarshalJSON() ([]byte, error) {
+	// Type definition for the recursive call
+	type JScanResult ScanResult
+	// This works because JScanResult doesn't have a MarshalJSON function associated with it
+	return json.Marshal(JScanResult(*r))
+}
+
+// Scanner sends the WS-Management Identify request to the /wsman path of WinRM endpoints.
+// The unauthenticated Identify request reports the protocol version and product of the endpoint,
+// the Identify request without the WSMANIDENTIFY header is rejected with authentication schemes of the endpoint.
+type Scanner struct {
+	client      *http.Client
+	dataTimeout time.Duration
+	tlsPorts    map[uint16]bool
+}
+
+// Assert that winrm.Scanner conforms to the scan.Scanner interface
+var _ scan.Scanner = (*Scanner)(nil)
+
+type ScannerOption func(*Scanner)
+
+func WithDataTimeout(timeout time.Duration) ScannerOption {
+	return func(s *Scanner) {
+		s.dataTimeout = timeout
+	}
+}
+
+// WithTLSPorts sets ports that are requested over HTTPS, DefaultTLSPorts are used by default
+func WithTLSPorts(ports []uint16) ScannerOption {
+	return func(s *Scanner) {
+		s.tlsPorts = make(map[uint16]bool, len(ports))
+		for _, port := range ports {
+			s.tlsPorts[port] = true
+		}
+	}
+}
+
+func NewScanner(opts ...ScannerOption) *Scanner {
+	tr := &http.Transport{
+		MaxConnsPerHost:   1,
+		DisableKeepAlives: true,
+		TLSClientConfig: &tls.Config{
+			InsecureSkipVerify: true,
+		},
+	}
+	s := &Scanner{
+		client:      &http.Client{Transport: tr},
+		dataTimeout: defaultDataTimeout,
+	}
+	WithTLSPorts(DefaultTLSPorts)(s)
+	for _, o := range opts {
+		o(s)
+	}
+	return s
+}
+
+func (s *Scanner) Scan(ctx context.Context, r *scan.Request) (scan.Result, error) {
+	result, err := s.scanTarget(ctx, r)
+	return result, scan.WrapError(err)
+}
+
+func (s *Scanner) scanTarget(ctx context.Context, r *scan.Request) (result scan.Result, err error) {
+	res := &ScanResult{
+		ScanType: ScanType,
+		IP:       r.DstIP.String(),
+		Port:     r.DstPort,
+		Proto:    "http",
+	}
+	if s.tlsPorts[r.DstPort] {
+		res.Proto = "https"
+	}
+	url := fmt.Sprintf("%s://%s%s", res.Proto,
+		net.JoinHostPort(r.DstIP.String(), strconv.Itoa(int(r.DstPort))), wsmanPath)
+
+	resp, err := s.identify(ctx, url, true)
+	if err != nil {
+		return
+	}
+	res.Server = resp.server
+	if resp.identity != nil {
+		res.ProtocolVersion = resp.identity.ProtocolVersion
+		res.ProductVendor = resp.identity.ProductVendor
+		res.ProductVersion = resp.identity.ProductVersion
+		// authentication schemes are offered only to requests that require authentication
+		if resp, err = s.identify(ctx, url, false); err != nil {
+			return res, nil
+		}
+	}
+	res.Auth = resp.auth
+	for _, scheme := range res.Auth {
+		if strings.EqualFold(scheme, authBasic) && res.Proto == "http" {
+			res.Unencrypted = true
+		}
+	}
+	return res, nil
+}
+
+type identifyResponse struct {
+	server string
+	// identity is set if the request is answered without authentication
+	identity *identity
+	// auth holds authentication schemes of the rejected request
+	auth []string
+}
+
+type identity struct {
+	ProtocolVersion string `xml:"Body>IdentifyResponse>ProtocolVersion"`
+	ProductVendor   string `xml:"Body>IdentifyResponse>ProductVendor"`
+	ProductVersion  string `xml:"Body>IdentifyResponse>ProductVersion"`
+}
+
+// identify sends the Identify request, the unauthenticated request is answered by endpoints that allow it,
+// other responses than the IdentifyResponse and the 401 status are errors
+func (s *Scanner) identify(ctx context.Context, url string, unauthenticated bool) (result *identifyResponse, err error) {
+	ctx, cancel := context.WithTimeout(ctx, s.dataTimeout)
+	defer cancel()
+	var req *http.Request
+	if req, err = http.NewRequestWithContext(ctx, http.MethodPost, url, strings.NewReader(identifyRequest)); err != nil {
+		return
+	}
+	req.Header.Set("Content-Type", "application/soap+xml;charset=UTF-8")
+	if unauthenticated {
+		req.Header.Set("WSMANIDENTIFY", "unauthenticated")
+	}
+	var resp *http.Response
+	if resp, err = s.client.Do(req); err != nil {
+		return
+	}
+	defer resp.Body.Close()
+	result = &identifyResponse{server: resp.Header.Get("Server")}
+	switch resp.StatusCode {
+	case http.StatusUnauthorized:
+		for _, value := range resp.Header.Values("WWW-Authenticate") {
+			// the scheme is followed by its parameters or the token, e.g. Basic realm="WSMAN"
+			if scheme, _, _ := strings.Cut(strings.TrimSpace(value), " "); len(scheme) > 0 {
+				result.auth = append(result.auth, scheme)
+			}
+		}
+		if len(result.auth) == 0 {
+			return nil, fmt.Errorf("%w: no authentication schemes", errNotWinRM)
+		}
+		return
+	case http.StatusOK:
+		var id identity
+		if err = xml.NewDecoder(io.LimitReader(resp.Body, maxResponseSize)).Decode(&id); err != nil {
+			return nil, fmt.Errorf("%w: %v", errNotWinRM, err)
+		}
+		if len(id.ProtocolVersion) == 0 {
+			return nil, fmt.Errorf("%w: no protocol version", errNotWinRM)
+		}
+		result.identity = &id
+		return
+	default:
+		return nil, fmt.Errorf("%w: %s", errNotWinRM, resp.Status)
+	}
+}
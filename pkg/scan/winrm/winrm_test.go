@@ -0,0 +1,158 @@
+package winrm
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+const testIdentifyResponse = `<s:Envelope xml:lang="en-US" xmlns:s="http://www.w3.org/2003/05/soap-envelope">` +
+	`<s:Header/><s:Body><wsmid:IdentifyResponse xmlns:wsmid="http://schemas.dmtf.org/wbem/wsman/identity/1/wsmanidentity.xsd">` +
+	`<wsmid:ProtocolVersion>http://schemas.dmtf.org/wbem/wsman/1/wsman.xsd</wsmid:ProtocolVersion>` +
+	`<wsmid:ProductVendor>Microsoft Corporation</wsmid:ProductVendor>` +
+	`<wsmid:ProductVersion>OS: 0.0.0 SP: 0.0 Stack: 3.0</wsmid:ProductVersion>` +
+	`</wsmid:IdentifyResponse></s:Body></s:Envelope>`
+
+type fakeServer struct {
+	// identify is set if the unauthenticated Identify request is answered
+	identify bool
+	auth     []string
+}
+
+func (s *fakeServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	if r.URL.Path != wsmanPath || r.Method != http.MethodPost || !strings.Contains(string(body), "Identify") {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Server", "Microsoft-HTTPAPI/2.0")
+	if s.identify && r.Header.Get("WSMANIDENTIFY") == "unauthenticated" {
+		w.Header().Set("Content-Type", "application/soap+xml;charset=UTF-8")
+		fmt.Fprint(w, testIdentifyResponse)
+		return
+	}
+	for _, auth := range s.auth {
+		w.Header().Add("WWW-Authenticate", auth)
+	}
+	w.WriteHeader(http.StatusUnauthorized)
+}
+
+func startServer(t *testing.T, srv *httptest.Server) *scan.Request {
+	t.Helper()
+	t.Cleanup(srv.Close)
+	addr := srv.Listener.Addr().(*net.TCPAddr)
+	return &scan.Request{DstIP: addr.IP, DstPort: uint16(addr.Port)}
+}
+
+func TestScan(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		server   *fakeServer
+		tls      bool
+		expected *ScanResult
+	}{
+		{
+			name:   "Identify",
+			server: &fakeServer{identify: true, auth: []string{"Negotiate", "Kerberos"}},
+			expected: &ScanResult{Proto: "http",
+				ProtocolVersion: "http://schemas.dmtf.org/wbem/wsman/1/wsman.xsd",
+				ProductVendor:   "Microsoft Corporation", ProductVersion: "OS: 0.0.0 SP: 0.0 Stack: 3.0",
+				Auth: []string{"Negotiate", "Kerberos"}},
+		},
+		{
+			name:   "Unencrypted",
+			server: &fakeServer{auth: []string{"Negotiate", `Basic realm="WSMAN"`}},
+			expected: &ScanResult{Proto: "http", Auth: []string{"Negotiate", "Basic"},
+				Unencrypted: true},
+		},
+		{
+			name:     "BasicOverHTTPS",
+			server:   &fakeServer{auth: []string{`Basic realm="WSMAN"`}},
+			tls:      true,
+			expected: &ScanResult{Proto: "https", Auth: []string{"Basic"}},
+		},
+	}
+
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var req *scan.Request
+			opts := []ScannerOption{WithDataTimeout(time.Second)}
+			if tt.tls {
+				req = startServer(t, httptest.NewTLSServer(tt.server))
+				opts = append(opts, WithTLSPorts([]uint16{req.DstPort}))
+			} else {
+				req = startServer(t, httptest.NewServer(tt.server))
+			}
+			result, err := NewScanner(opts...).Scan(context.Background(), req)
+			require.NoError(t, err)
+			tt.expected.ScanType = ScanType
+			tt.expected.IP = req.DstIP.String()
+			tt.expected.Port = req.DstPort
+			tt.expected.Server = "Microsoft-HTTPAPI/2.0"
+			require.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestScanNotWinRM(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+	}{
+		{
+			name:    "NotFound",
+			handler: http.NotFound,
+		},
+		{
+			name: "NoAuthSchemes",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusUnauthorized)
+			},
+		},
+		{
+			name: "NotIdentifyResponse",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, "<html><body>It works!</body></html>")
+			},
+		},
+	}
+
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			req := startServer(t, httptest.NewServer(tt.handler))
+			result, err := NewScanner(WithDataTimeout(time.Second)).Scan(context.Background(), req)
+			require.ErrorIs(t, err, errNotWinRM)
+			require.Nil(t, result)
+		})
+	}
+}
+
+func TestScanResultString(t *testing.T) {
+	t.Parallel()
+
+	result := &ScanResult{ScanType: ScanType, IP: "192.168.0.1", Port: 5985, Proto: "http",
+		ProductVendor: "Microsoft Corporation", ProductVersion: "OS: 0.0.0 SP: 0.0 Stack: 3.0",
+		Auth: []string{"Negotiate", "Basic"}, Unencrypted: true}
+	require.Equal(t, `192.168.0.1          5985  http "Microsoft Corporation OS: 0.0.0 SP: 0.0 Stack: 3.0" `+
+		`auth:Negotiate,Basic unencrypted`, result.String())
+	require.Equal(t, "192.168.0.1:5985", result.ID())
+}
@@ -0,0 +1,179 @@
+package wireguard
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"hash"
+	"time"
+
+	"golang.org/x/crypto/blake2s"
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/curve25519"
+)
+
+// message types and sizes of the WireGuard protocol, see https://www.wireguard.com/protocol/
+const (
+	messageInitiationType = 1
+	messageResponseType   = 2
+	messageCookieReply    = 3
+
+	messageInitiationSize = 148
+	messageResponseSize   = 92
+	messageCookieSize     = 64
+
+	KeySize       = 32
+	macSize       = 16
+	timestampSize = 12
+	// mac1Offset is the offset of the mac1 field of the handshake initiation
+	mac1Offset = messageInitiationSize - 2*macSize
+)
+
+const (
+	noiseConstruction = "Noise_IKpsk2_25519_ChaChaPoly_BLAKE2s"
+	noiseIdentifier   = "WireGuard v1 zx2c4 Jason@zx2c4.com"
+	labelMAC1         = "mac1----"
+	// tai64Base is the TAI64 label of the Unix epoch
+	tai64Base = uint64(0x400000000000000a)
+)
+
+var errKey = errors.New("invalid WireGuard key")
+
+// Key is the Curve25519 private or public key
+type Key [KeySize]byte
+
+// PublicKey returns the public key of the private key
+func (k *Key) PublicKey() (result Key) {
+	// the base point is never a low-order point, so there is no error
+	public, _ := curve25519.X25519(k[:], curve25519.Basepoint)
+	copy(result[:], public)
+	return
+}
+
+// ParseKey parses the base64 key of WireGuard configuration files
+func ParseKey(s string) (result Key, err error) {
+	data, err := base64.StdEncoding.DecodeString(s)
+	if err != nil || len(data) != KeySize {
+		return result, errKey
+	}
+	copy(result[:], data)
+	return
+}
+
+// randomKey returns the random private key
+func randomKey() (result Key, err error) {
+	if _, err = rand.Read(result[:]); err != nil {
+		return
+	}
+	// clamp the scalar like the WireGuard implementation
+	result[0] &= 248
+	result[31] = (result[31] & 127) | 64
+	return
+}
+
+// handshakeInitiation returns the handshake initiation of the Noise IK handshake of the peer with the private key
+// to the server with the public key. mac2 is zero since there is no cookie from the server.
+func handshakeInitiation(senderIndex uint32, privateKey, serverKey *Key, now time.Time) ([]byte, error) {
+	ephemeral, err := randomKey()
+	if err != nil {
+		return nil, err
+	}
+	msg := make([]byte, messageInitiationSize)
+	msg[0] = messageInitiationType
+	binary.LittleEndian.PutUint32(msg[4:], senderIndex)
+	ephemeralPublic := ephemeral.PublicKey()
+	copy(msg[8:], ephemeralPublic[:])
+
+	chainKey := blake2s.Sum256([]byte(noiseConstruction))
+	hash := mixHash(chainKey, []byte(noiseIdentifier))
+	hash = mixHash(hash, serverKey[:])
+	chainKey = kdf1(chainKey[:], ephemeralPublic[:])
+	hash = mixHash(hash, ephemeralPublic[:])
+
+	// encrypted static public key of the peer
+	shared, err := curve25519.X25519(ephemeral[:], serverKey[:])
+	if err != nil {
+		return nil, errKey
+	}
+	var key [KeySize]byte
+	chainKey, key = kdf2(chainKey[:], shared)
+	publicKey := privateKey.PublicKey()
+	encryptedStatic := seal(key, publicKey[:], hash[:])
+	copy(msg[40:], encryptedStatic)
+	hash = mixHash(hash, encryptedStatic)
+
+	// encrypted timestamp
+	if shared, err = curve25519.X25519(privateKey[:], serverKey[:]); err != nil {
+		return nil, errKey
+	}
+	_, key = kdf2(chainKey[:], shared)
+	copy(msg[88:], seal(key, tai64n(now), hash[:]))
+
+	mac1Key := blake2s.Sum256(append([]byte(labelMAC1), serverKey[:]...))
+	mac, _ := blake2s.New128(mac1Key[:])
+	mac.Write(msg[:mac1Offset])
+	copy(msg[mac1Offset:], mac.Sum(nil))
+	return msg, nil
+}
+
+func mixHash(hash [blake2s.Size]byte, data []byte) [blake2s.Size]byte {
+	return blake2s.Sum256(append(hash[:], data...))
+}
+
+func newHash() hash.Hash {
+	h, _ := blake2s.New256(nil)
+	return h
+}
+
+func hmacSum(key []byte, data ...[]byte) []byte {
+	mac := hmac.New(newHash, key)
+	for _, d := range data {
+		mac.Write(d)
+	}
+	return mac.Sum(nil)
+}
+
+func kdf1(key, input []byte) (t1 [blake2s.Size]byte) {
+	t0 := hmacSum(key, input)
+	copy(t1[:], hmacSum(t0, []byte{0x1}))
+	return
+}
+
+func kdf2(key, input []byte) (t1, t2 [blake2s.Size]byte) {
+	t0 := hmacSum(key, input)
+	copy(t1[:], hmacSum(t0, []byte{0x1}))
+	copy(t2[:], hmacSum(t0, t1[:], []byte{0x2}))
+	return
+}
+
+// seal encrypts the plaintext with the zero nonce, keys of the handshake are used once
+func seal(key [KeySize]byte, plaintext, additionalData []byte) []byte {
+	aead, _ := chacha20poly1305.New(key[:])
+	var nonce [chacha20poly1305.NonceSize]byte
+	return aead.Seal(nil, nonce[:], plaintext, additionalData)
+}
+
+// tai64n returns the TAI64N timestamp of the time
+func tai64n(t time.Time) []byte {
+	result := make([]byte, timestampSize)
+	binary.BigEndian.PutUint64(result, tai64Base+uint64(t.Unix()))
+	binary.BigEndian.PutUint32(result[8:], uint32(t.Nanosecond()))
+	return result
+}
+
+// receiverIndex returns the receiver index of the handshake response or the cookie reply,
+// other messages are not answers to the handshake initiation
+func receiverIndex(data []byte) (uint32, bool) {
+	if len(data) < 8 || data[1] != 0 || data[2] != 0 || data[3] != 0 {
+		return 0, false
+	}
+	switch {
+	case data[0] == messageResponseType && len(data) == messageResponseSize:
+		return binary.LittleEndian.Uint32(data[8:]), true
+	case data[0] == messageCookieReply && len(data) == messageCookieSize:
+		return binary.LittleEndian.Uint32(data[4:]), true
+	}
+	return 0, false
+}
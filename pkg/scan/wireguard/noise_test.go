@@ -0,0 +1,134 @@
+package wireguard
+
+import (
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/blake2s"
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/curve25519"
+)
+
+var errInitiation = errors.New("invalid handshake initiation")
+
+// consumeInitiation verifies the handshake initiation like the responder does
+// and returns the static public key of the peer and the timestamp
+func consumeInitiation(msg []byte, serverKey *Key) (peerKey Key, timestamp []byte, err error) {
+	if len(msg) != messageInitiationSize || msg[0] != messageInitiationType {
+		return peerKey, nil, errInitiation
+	}
+	serverPublic := serverKey.PublicKey()
+	mac1Key := blake2s.Sum256(append([]byte(labelMAC1), serverPublic[:]...))
+	mac, _ := blake2s.New128(mac1Key[:])
+	mac.Write(msg[:mac1Offset])
+	if subtle.ConstantTimeCompare(mac.Sum(nil), msg[mac1Offset:mac1Offset+macSize]) != 1 {
+		return peerKey, nil, errInitiation
+	}
+
+	ephemeral := msg[8:40]
+	chainKey := blake2s.Sum256([]byte(noiseConstruction))
+	hash := blake2s.Sum256(append(chainKey[:], noiseIdentifier...))
+	hash = blake2s.Sum256(append(hash[:], serverPublic[:]...))
+	hash = blake2s.Sum256(append(hash[:], ephemeral...))
+	chainKey = kdf1(chainKey[:], ephemeral)
+
+	shared, err := curve25519.X25519(serverKey[:], ephemeral)
+	if err != nil {
+		return
+	}
+	var key [KeySize]byte
+	chainKey, key = kdf2(chainKey[:], shared)
+	aead, _ := chacha20poly1305.New(key[:])
+	nonce := make([]byte, chacha20poly1305.NonceSize)
+	static, err := aead.Open(nil, nonce, msg[40:88], hash[:])
+	if err != nil {
+		return
+	}
+	copy(peerKey[:], static)
+	hash = blake2s.Sum256(append(hash[:], msg[40:88]...))
+
+	if shared, err = curve25519.X25519(serverKey[:], peerKey[:]); err != nil {
+		return
+	}
+	_, key = kdf2(chainKey[:], shared)
+	aead, _ = chacha20poly1305.New(key[:])
+	timestamp, err = aead.Open(nil, nonce, msg[88:mac1Offset], hash[:])
+	return
+}
+
+func TestHandshakeInitiation(t *testing.T) {
+	t.Parallel()
+
+	serverKey, err := randomKey()
+	require.NoError(t, err)
+	peerKey, err := randomKey()
+	require.NoError(t, err)
+	serverPublic := serverKey.PublicKey()
+	now := time.Unix(1600000000, 123)
+
+	msg, err := handshakeInitiation(0x11223344, &peerKey, &serverPublic, now)
+	require.NoError(t, err)
+	require.Len(t, msg, messageInitiationSize)
+	require.Equal(t, []byte{messageInitiationType, 0, 0, 0, 0x44, 0x33, 0x22, 0x11}, msg[:8])
+	// there is no cookie
+	require.Equal(t, make([]byte, macSize), msg[messageInitiationSize-macSize:])
+
+	static, timestamp, err := consumeInitiation(msg, &serverKey)
+	require.NoError(t, err)
+	require.Equal(t, peerKey.PublicKey(), static)
+	require.Equal(t, tai64n(now), timestamp)
+
+	otherKey, err := randomKey()
+	require.NoError(t, err)
+	_, _, err = consumeInitiation(msg, &otherKey)
+	require.ErrorIs(t, err, errInitiation)
+}
+
+func TestTAI64N(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, []byte{0x40, 0, 0, 0, 0x5f, 0x5e, 0x10, 0x0a, 0, 0, 0, 0x7b}, tai64n(time.Unix(1600000000, 123)))
+}
+
+func TestParseKey(t *testing.T) {
+	t.Parallel()
+
+	key, err := randomKey()
+	require.NoError(t, err)
+	parsed, err := ParseKey(base64.StdEncoding.EncodeToString(key[:]))
+	require.NoError(t, err)
+	require.Equal(t, key, parsed)
+
+	_, err = ParseKey("invalid")
+	require.ErrorIs(t, err, errKey)
+	_, err = ParseKey(base64.StdEncoding.EncodeToString(key[:16]))
+	require.ErrorIs(t, err, errKey)
+}
+
+func TestReceiverIndex(t *testing.T) {
+	t.Parallel()
+
+	response := make([]byte, messageResponseSize)
+	response[0] = messageResponseType
+	binary.LittleEndian.PutUint32(response[8:], 7)
+	index, ok := receiverIndex(response)
+	require.True(t, ok)
+	require.Equal(t, uint32(7), index)
+
+	cookie := make([]byte, messageCookieSize)
+	cookie[0] = messageCookieReply
+	binary.LittleEndian.PutUint32(cookie[4:], 9)
+	index, ok = receiverIndex(cookie)
+	require.True(t, ok)
+	require.Equal(t, uint32(9), index)
+
+	_, ok = receiverIndex(response[:messageCookieSize])
+	require.False(t, ok)
+	_, ok = receiverIndex([]byte("SSH-2.0-OpenSSH_8.9\r\n"))
+	require.False(t, ok)
+}
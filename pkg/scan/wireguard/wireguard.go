@@ -0,0 +1,194 @@
+package wireguard
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+const (
+	ScanType = "wireguard"
+
+	defaultDataTimeout = 1 * time.Second
+	defaultRetries     = 1
+	maxPacketSize      = 1500
+)
+
+// states of targets
+const (
+	// StateHandshake means the server answered the handshake initiation, it is possible only with keys of the peer
+	StateHandshake = "handshake"
+	// StateOpenFiltered means there is no answer: WireGuard servers silently drop initiations of unknown peers,
+	// filtered ports and hosts that are down don't answer either
+	StateOpenFiltered = "open|filtered"
+	// StateClosed means the ICMP port unreachable message is received, there is no WireGuard server
+	StateClosed = "closed"
+	// StateUnexpected means another UDP service answered, it is not a WireGuard server
+	StateUnexpected = "unexpected"
+)
+
+type ScanResult struct {
+	ScanType string `json:"scan"`
+	IP       string `json:"ip"`
+	Port     uint16 `json:"port"`
+	State    string `json:"state"`
+	// Keys is set if the handshake initiation is sent with keys of the peer
+	Keys bool `json:"keys"`
+}
+
+func (r *ScanResult) String() string {
+	result := fmt.Sprintf("%-20s %-5d %s", r.IP, r.Port, r.State)
+	if r.Keys {
+		result += " keys"
+	}
+	return result
+}
+
+func (r *ScanResult) ID() string {
+	return fmt.Sprintf("%s:%d", r.IP, r.Port)
+}
+
+func (r *ScanResult) MarshalJSON() ([]byte, error) {
+	// Type definition for the recursive call
+	type JScanResult ScanResult
+	// This works because JScanResult doesn't have a MarshalJSON function associated with it
+	return json.Marshal(JScanResult(*r))
+}
+
+// Scanner sends the handshake initiation to each target over UDP and classifies targets by the answer.
+// Initiations are made with random keys by default, WireGuard servers drop them without any answer, so servers
+// are told apart only from closed ports and other services. With keys of the peer and the public key of the server
+// the server answers with the handshake response, it verifies that the server accepts the peer.
+type Scanner struct {
+	privateKey  *Key
+	serverKey   *Key
+	dataTimeout time.Duration
+	retries     int
+}
+
+// Assert that wireguard.Scanner conforms to the scan.Scanner interface
+var _ scan.Scanner = (*Scanner)(nil)
+
+type ScannerOption func(*Scanner)
+
+// WithKeys sets the private key of the peer and the public key of the server of handshake initiations
+func WithKeys(privateKey, serverKey Key) ScannerOption {
+	return func(s *Scanner) {
+		s.privateKey = &privateKey
+		s.serverKey = &serverKey
+	}
+}
+
+// WithDataTimeout sets the time to wait for a response to each request
+func WithDataTimeout(timeout time.Duration) ScannerOption {
+	return func(s *Scanner) {
+		s.dataTimeout = timeout
+	}
+}
+
+// WithRetries sets the number of additional requests if there is no response
+func WithRetries(retries int) ScannerOption {
+	return func(s *Scanner) {
+		s.retries = retries
+	}
+}
+
+func NewScanner(opts ...ScannerOption) *Scanner {
+	s := &Scanner{
+		dataTimeout: defaultDataTimeout,
+		retries:     defaultRetries,
+	}
+	for _, o := range opts {
+		o(s)
+	}
+	return s
+}
+
+func (s *Scanner) Scan(ctx context.Context, r *scan.Request) (scan.Result, error) {
+	result, err := s.scanTarget(ctx, r)
+	return result, scan.WrapError(err)
+}
+
+func (s *Scanner) scanTarget(ctx context.Context, r *scan.Request) (result scan.Result, err error) {
+	addr := net.JoinHostPort(r.DstIP.String(), strconv.Itoa(int(r.DstPort)))
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "udp", addr)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	state, err := s.probe(ctx, conn)
+	if err != nil {
+		return
+	}
+	return &ScanResult{
+		ScanType: ScanType,
+		IP:       r.DstIP.String(),
+		Port:     r.DstPort,
+		State:    state,
+		Keys:     s.privateKey != nil,
+	}, nil
+}
+
+// probe sends handshake initiations and returns the state of the target by the answer,
+// retries have the same sender index, so late answers to previous initiations are matched
+func (s *Scanner) probe(ctx context.Context, conn net.Conn) (string, error) {
+	buf := make([]byte, maxPacketSize)
+	senderIndex := rand.Uint32()
+	for i := 0; i <= s.retries; i++ {
+		msg, err := s.initiation(senderIndex)
+		if err != nil {
+			return "", err
+		}
+		n := 0
+		// the ICMP port unreachable message of the previous initiation is reported by the next write
+		if _, err = conn.Write(msg); err == nil {
+			if err = conn.SetReadDeadline(time.Now().Add(s.dataTimeout)); err != nil {
+				return "", err
+			}
+			n, err = conn.Read(buf)
+		}
+		switch {
+		case err == nil:
+			if index, ok := receiverIndex(buf[:n]); ok && index == senderIndex {
+				return StateHandshake, nil
+			}
+			return StateUnexpected, nil
+		case errors.Is(err, syscall.ECONNREFUSED):
+			return StateClosed, nil
+		case ctx.Err() != nil:
+			return "", ctx.Err()
+		}
+		var netErr net.Error
+		if !errors.As(err, &netErr) || !netErr.Timeout() {
+			return "", err
+		}
+	}
+	return StateOpenFiltered, nil
+}
+
+// initiation returns the handshake initiation with keys of the peer or with random keys
+func (s *Scanner) initiation(senderIndex uint32) ([]byte, error) {
+	if s.privateKey != nil {
+		return handshakeInitiation(senderIndex, s.privateKey, s.serverKey, time.Now())
+	}
+	privateKey, err := randomKey()
+	if err != nil {
+		return nil, err
+	}
+	serverKey, err := randomKey()
+	if err != nil {
+		return nil, err
+	}
+	serverPublic := serverKey.PublicKey()
+	return handshakeInitiation(senderIndex, &privateKey, &serverPublic, time.Now())
+}
@@ -0,0 +1,122 @@
+package wireguard
+
+import (
+	"context"
+	"encoding/binary"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+// startFakeServer starts UDP server that answers handshake initiations of the peer with the handshake response,
+// other initiations are dropped like WireGuard servers do. The reply is sent to any datagram if it is set.
+func startFakeServer(t *testing.T, serverKey *Key, peerKey Key, reply []byte) *scan.Request {
+	t.Helper()
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	go func() {
+		buf := make([]byte, maxPacketSize)
+		for {
+			n, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			if reply != nil {
+				_, _ = conn.WriteTo(reply, addr)
+				continue
+			}
+			static, _, err := consumeInitiation(buf[:n], serverKey)
+			if err != nil || static != peerKey.PublicKey() {
+				continue
+			}
+			response := make([]byte, messageResponseSize)
+			response[0] = messageResponseType
+			binary.LittleEndian.PutUint32(response[4:], 1)
+			copy(response[8:12], buf[4:8])
+			_, _ = conn.WriteTo(response, addr)
+		}
+	}()
+	addr := conn.LocalAddr().(*net.UDPAddr)
+	return &scan.Request{DstIP: addr.IP, DstPort: uint16(addr.Port)}
+}
+
+func TestScan(t *testing.T) {
+	t.Parallel()
+
+	serverKey, err := randomKey()
+	require.NoError(t, err)
+	peerKey, err := randomKey()
+	require.NoError(t, err)
+	otherKey, err := randomKey()
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		reply    []byte
+		opts     []ScannerOption
+		expected *ScanResult
+	}{
+		{
+			name:     "Handshake",
+			opts:     []ScannerOption{WithKeys(peerKey, serverKey.PublicKey())},
+			expected: &ScanResult{State: StateHandshake, Keys: true},
+		},
+		{
+			name:     "UnknownPeer",
+			opts:     []ScannerOption{WithKeys(otherKey, serverKey.PublicKey())},
+			expected: &ScanResult{State: StateOpenFiltered, Keys: true},
+		},
+		{
+			name:     "RandomKeys",
+			expected: &ScanResult{State: StateOpenFiltered},
+		},
+		{
+			name:     "Unexpected",
+			reply:    []byte("unknown command\n"),
+			expected: &ScanResult{State: StateUnexpected},
+		},
+	}
+
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			req := startFakeServer(t, &serverKey, peerKey, tt.reply)
+			opts := append([]ScannerOption{WithDataTimeout(100 * time.Millisecond)}, tt.opts...)
+			result, err := NewScanner(opts...).Scan(context.Background(), req)
+			require.NoError(t, err)
+			tt.expected.ScanType = ScanType
+			tt.expected.IP = req.DstIP.String()
+			tt.expected.Port = req.DstPort
+			require.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestScanClosed(t *testing.T) {
+	t.Parallel()
+
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := conn.LocalAddr().(*net.UDPAddr)
+	conn.Close()
+
+	result, err := NewScanner(WithDataTimeout(100*time.Millisecond)).Scan(context.Background(),
+		&scan.Request{DstIP: addr.IP, DstPort: uint16(addr.Port)})
+	require.NoError(t, err)
+	require.Equal(t, StateClosed, result.(*ScanResult).State)
+}
+
+func TestScanResultString(t *testing.T) {
+	t.Parallel()
+
+	result := &ScanResult{ScanType: ScanType, IP: "192.168.0.1", Port: 51820, State: StateHandshake, Keys: true}
+	require.Equal(t, "192.168.0.1          51820 handshake keys", result.String())
+	require.Equal(t, "192.168.0.1:51820", result.ID())
+}
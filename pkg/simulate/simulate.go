@@ -0,0 +1,354 @@
+// Package simulate runs a population of fake services on loopback addresses,
+// so that scan configurations can be benchmarked end-to-end under controlled conditions
+package simulate
+
+import (
+	"errors"
+	"hash/fnv"
+	"io"
+	"math"
+	"math/rand"
+	"net"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// connTimeout limits the time connections are held open if clients don't close them
+const connTimeout = 10 * time.Second
+
+var ErrNoServices = errors.New("at least one service port required")
+
+// Stats are counters of the simulated population
+type Stats struct {
+	// Hosts is the number of hosts that are up
+	Hosts     int
+	Listeners int
+	// Connections is the number of accepted TCP connections, including failed ones
+	Connections int64
+	// Failed is the number of TCP connections reset and UDP datagrams dropped on purpose
+	Failed    int64
+	Datagrams int64
+}
+
+// Population listens on ports of the hosts of a loopback subnet. TCP ports accept connections,
+// SOCKS ports answer SOCKS4 and SOCKS5 greetings and UDP ports echo datagrams back
+type Population struct {
+	subnet     *net.IPNet
+	tcpPorts   []uint16
+	socksPorts []uint16
+	udpPorts   []uint16
+	upRatio    float64
+	failRatio  float64
+	delay      time.Duration
+
+	hosts   []net.IP
+	closers []io.Closer
+	wg      sync.WaitGroup
+	// open TCP connections are closed along with listeners
+	connMu    sync.Mutex
+	openConns map[net.Conn]struct{}
+	closed    bool
+
+	rndMu     sync.Mutex
+	rnd       *rand.Rand
+	conns     int64
+	failed    int64
+	datagrams int64
+}
+
+type Option func(p *Population)
+
+func WithTCPPorts(ports ...uint16) Option {
+	return func(p *Population) {
+		p.tcpPorts = ports
+	}
+}
+
+func WithSOCKSPorts(ports ...uint16) Option {
+	return func(p *Population) {
+		p.socksPorts = ports
+	}
+}
+
+func WithUDPPorts(ports ...uint16) Option {
+	return func(p *Population) {
+		p.udpPorts = ports
+	}
+}
+
+// WithUpRatio sets the fraction of hosts with services, the same hosts are chosen in every run
+func WithUpRatio(ratio float64) Option {
+	return func(p *Population) {
+		p.upRatio = ratio
+	}
+}
+
+// WithFailRatio sets the fraction of TCP connections that are reset and UDP datagrams that are dropped,
+// failures are random, so retries of failed requests may succeed
+func WithFailRatio(ratio float64) Option {
+	return func(p *Population) {
+		p.failRatio = ratio
+	}
+}
+
+// WithDelay sets the delay of SOCKS replies and UDP echoes
+func WithDelay(delay time.Duration) Option {
+	return func(p *Population) {
+		p.delay = delay
+	}
+}
+
+func New(subnet *net.IPNet, opts ...Option) *Population {
+	p := &Population{
+		subnet:    subnet,
+		upRatio:   1,
+		rnd:       rand.New(rand.NewSource(time.Now().UnixNano())),
+		openConns: make(map[net.Conn]struct{}),
+	}
+	for _, o := range opts {
+		o(p)
+	}
+	return p
+}
+
+// Start listens on all ports of the hosts that are up, nothing is left listening on errors
+func (p *Population) Start() (err error) {
+	if len(p.tcpPorts)+len(p.socksPorts)+len(p.udpPorts) == 0 {
+		return ErrNoServices
+	}
+	defer func() {
+		if err != nil {
+			p.Close()
+		}
+	}()
+	p.hosts = upHosts(p.subnet, p.upRatio)
+	for _, host := range p.hosts {
+		for _, port := range p.tcpPorts {
+			if err = p.listenTCP(host, port, p.serveTCP); err != nil {
+				return
+			}
+		}
+		for _, port := range p.socksPorts {
+			if err = p.listenTCP(host, port, p.serveSOCKS); err != nil {
+				return
+			}
+		}
+		for _, port := range p.udpPorts {
+			if err = p.listenUDP(host, port); err != nil {
+				return
+			}
+		}
+	}
+	return nil
+}
+
+// Close stops all listeners and closes open connections
+func (p *Population) Close() {
+	for _, c := range p.closers {
+		c.Close()
+	}
+	p.connMu.Lock()
+	p.closed = true
+	for conn := range p.openConns {
+		conn.Close()
+	}
+	p.connMu.Unlock()
+	p.wg.Wait()
+}
+
+// track adds the connection to open ones, it returns false if the population is closed
+func (p *Population) track(conn net.Conn) bool {
+	p.connMu.Lock()
+	defer p.connMu.Unlock()
+	if p.closed {
+		return false
+	}
+	p.openConns[conn] = struct{}{}
+	return true
+}
+
+func (p *Population) untrack(conn net.Conn) {
+	p.connMu.Lock()
+	defer p.connMu.Unlock()
+	delete(p.openConns, conn)
+}
+
+func (p *Population) Stats() Stats {
+	return Stats{
+		Hosts:       len(p.hosts),
+		Listeners:   len(p.closers),
+		Connections: atomic.LoadInt64(&p.conns),
+		Failed:      atomic.LoadInt64(&p.failed),
+		Datagrams:   atomic.LoadInt64(&p.datagrams),
+	}
+}
+
+// upHosts returns hosts of the subnet with the hash of the IP below the ratio
+func upHosts(subnet *net.IPNet, ratio float64) []net.IP {
+	var result []net.IP
+	ones, bits := subnet.Mask.Size()
+	count := uint64(1) << uint(bits-ones)
+	ip := subnet.IP.Mask(subnet.Mask)
+	for i := uint64(0); i < count; i, ip = i+1, nextIP(ip) {
+		if ratio >= 1 || float64(hashIP(ip)) < ratio*math.MaxUint32 {
+			result = append(result, ip)
+		}
+	}
+	return result
+}
+
+// hashIP returns the fnv hash of the IP mixed with the murmur3 finalizer,
+// fnv alone doesn't spread addresses that differ only in the last byte
+func hashIP(ip net.IP) uint32 {
+	h := fnv.New32a()
+	_, _ = h.Write(ip)
+	x := h.Sum32()
+	x ^= x >> 16
+	x *= 0x85ebca6b
+	x ^= x >> 13
+	x *= 0xc2b2ae35
+	x ^= x >> 16
+	return x
+}
+
+// nextIP returns the next IP address
+func nextIP(ip net.IP) net.IP {
+	next := make(net.IP, len(ip))
+	copy(next, ip)
+	for i := len(next) - 1; i >= 0; i-- {
+		next[i]++
+		if next[i] != 0 {
+			break
+		}
+	}
+	return next
+}
+
+func (p *Population) fail() bool {
+	if p.failRatio <= 0 {
+		return false
+	}
+	p.rndMu.Lock()
+	defer p.rndMu.Unlock()
+	return p.rnd.Float64() < p.failRatio
+}
+
+func (p *Population) listenTCP(host net.IP, port uint16, serve func(conn net.Conn)) error {
+	ln, err := net.Listen("tcp", net.JoinHostPort(host.String(), strconv.Itoa(int(port))))
+	if err != nil {
+		return err
+	}
+	p.closers = append(p.closers, ln)
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				var nerr net.Error
+				if errors.As(err, &nerr) && nerr.Timeout() {
+					continue
+				}
+				return
+			}
+			atomic.AddInt64(&p.conns, 1)
+			if p.fail() {
+				atomic.AddInt64(&p.failed, 1)
+				// close with RST instead of FIN
+				if tcpConn, ok := conn.(*net.TCPConn); ok {
+					_ = tcpConn.SetLinger(0)
+				}
+				conn.Close()
+				continue
+			}
+			if !p.track(conn) {
+				conn.Close()
+				return
+			}
+			p.wg.Add(1)
+			go func() {
+				defer p.wg.Done()
+				defer p.untrack(conn)
+				defer conn.Close()
+				_ = conn.SetDeadline(time.Now().Add(connTimeout))
+				serve(conn)
+			}()
+		}
+	}()
+	return nil
+}
+
+// serveTCP holds the connection open until the client closes it
+func (p *Population) serveTCP(conn net.Conn) {
+	_, _ = io.Copy(io.Discard, conn)
+}
+
+// serveSOCKS accepts SOCKS5 clients without authentication and grants SOCKS4 connect requests
+func (p *Population) serveSOCKS(conn net.Conn) {
+	buf := make([]byte, 512)
+	n, err := conn.Read(buf)
+	if err != nil || n < 2 {
+		return
+	}
+	time.Sleep(p.delay)
+	var reply []byte
+	switch buf[0] {
+	case 5:
+		// VER NMETHODS METHODS
+		reply = []byte{5, 0xFF}
+		for _, m := range buf[2:n] {
+			if m == 0 {
+				reply[1] = 0
+			}
+		}
+	case 4:
+		// VN CD DSTPORT DSTIP
+		reply = []byte{0, 0x5A, 0, 0, 0, 0, 0, 0}
+	default:
+		return
+	}
+	if _, err = conn.Write(reply); err != nil {
+		return
+	}
+	_, _ = io.Copy(io.Discard, conn)
+}
+
+func (p *Population) listenUDP(host net.IP, port uint16) error {
+	pc, err := net.ListenPacket("udp", net.JoinHostPort(host.String(), strconv.Itoa(int(port))))
+	if err != nil {
+		return err
+	}
+	p.closers = append(p.closers, pc)
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		buf := make([]byte, 65535)
+		for {
+			n, addr, err := pc.ReadFrom(buf)
+			if err != nil {
+				var nerr net.Error
+				if errors.As(err, &nerr) && nerr.Timeout() {
+					continue
+				}
+				return
+			}
+			atomic.AddInt64(&p.datagrams, 1)
+			if p.fail() {
+				atomic.AddInt64(&p.failed, 1)
+				continue
+			}
+			data := make([]byte, n)
+			copy(data, buf[:n])
+			if p.delay == 0 {
+				_, _ = pc.WriteTo(data, addr)
+				continue
+			}
+			time.AfterFunc(p.delay, func() {
+				_, _ = pc.WriteTo(data, addr)
+			})
+		}
+	}()
+	return nil
+}
@@ -0,0 +1,139 @@
+package simulate
+
+import (
+	"io"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// freePort returns a port that is not used on the loopback interface at the moment
+func freePort(t *testing.T) uint16 {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+	return uint16(ln.Addr().(*net.TCPAddr).Port)
+}
+
+func startPopulation(t *testing.T, subnet string, opts ...Option) *Population {
+	t.Helper()
+	_, ipnet, err := net.ParseCIDR(subnet)
+	require.NoError(t, err)
+	p := New(ipnet, opts...)
+	require.NoError(t, p.Start())
+	t.Cleanup(p.Close)
+	return p
+}
+
+func addr(ip string, port uint16) string {
+	return net.JoinHostPort(ip, strconv.Itoa(int(port)))
+}
+
+func TestPopulationTCP(t *testing.T) {
+	t.Parallel()
+	port := freePort(t)
+	p := startPopulation(t, "127.0.3.0/30", WithTCPPorts(port))
+
+	for _, ip := range []string{"127.0.3.0", "127.0.3.1", "127.0.3.2", "127.0.3.3"} {
+		conn, err := net.DialTimeout("tcp", addr(ip, port), time.Second)
+		require.NoError(t, err)
+		conn.Close()
+	}
+	stats := p.Stats()
+	require.Equal(t, 4, stats.Hosts)
+	require.Equal(t, 4, stats.Listeners)
+}
+
+func TestPopulationSOCKS(t *testing.T) {
+	t.Parallel()
+	port := freePort(t)
+	startPopulation(t, "127.0.4.1/32", WithSOCKSPorts(port))
+
+	conn, err := net.DialTimeout("tcp", addr("127.0.4.1", port), time.Second)
+	require.NoError(t, err)
+	defer conn.Close()
+	_, err = conn.Write([]byte{5, 2, 0, 2})
+	require.NoError(t, err)
+	reply := make([]byte, 2)
+	_, err = io.ReadFull(conn, reply)
+	require.NoError(t, err)
+	require.Equal(t, []byte{5, 0}, reply)
+
+	conn4, err := net.DialTimeout("tcp", addr("127.0.4.1", port), time.Second)
+	require.NoError(t, err)
+	defer conn4.Close()
+	_, err = conn4.Write([]byte{4, 1, 0, 80, 10, 0, 0, 1, 0})
+	require.NoError(t, err)
+	reply = make([]byte, 8)
+	_, err = io.ReadFull(conn4, reply)
+	require.NoError(t, err)
+	require.Equal(t, byte(0x5A), reply[1])
+}
+
+func TestPopulationUDPEcho(t *testing.T) {
+	t.Parallel()
+	port := freePort(t)
+	p := startPopulation(t, "127.0.5.1/32", WithUDPPorts(port), WithDelay(10*time.Millisecond))
+
+	conn, err := net.Dial("udp", addr("127.0.5.1", port))
+	require.NoError(t, err)
+	defer conn.Close()
+	_, err = conn.Write([]byte("ping"))
+	require.NoError(t, err)
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(time.Second)))
+	buf := make([]byte, 16)
+	n, err := conn.Read(buf)
+	require.NoError(t, err)
+	require.Equal(t, "ping", string(buf[:n]))
+	require.Equal(t, int64(1), p.Stats().Datagrams)
+}
+
+func TestPopulationFailRatio(t *testing.T) {
+	t.Parallel()
+	port := freePort(t)
+	p := startPopulation(t, "127.0.6.1/32", WithSOCKSPorts(port), WithFailRatio(1))
+
+	// the connection may be reset before the dial returns
+	conn, err := net.DialTimeout("tcp", addr("127.0.6.1", port), time.Second)
+	if err == nil {
+		defer conn.Close()
+		_, _ = conn.Write([]byte{5, 1, 0})
+		require.NoError(t, conn.SetReadDeadline(time.Now().Add(time.Second)))
+		_, err = conn.Read(make([]byte, 2))
+	}
+	require.Error(t, err)
+
+	stats := p.Stats()
+	require.Equal(t, int64(1), stats.Connections)
+	require.Equal(t, int64(1), stats.Failed)
+}
+
+func TestPopulationNoServices(t *testing.T) {
+	t.Parallel()
+	_, ipnet, err := net.ParseCIDR("127.0.7.0/30")
+	require.NoError(t, err)
+	require.ErrorIs(t, New(ipnet).Start(), ErrNoServices)
+}
+
+func TestUpHosts(t *testing.T) {
+	t.Parallel()
+	_, ipnet, err := net.ParseCIDR("127.1.0.0/20")
+	require.NoError(t, err)
+
+	require.Len(t, upHosts(ipnet, 1), 4096)
+	require.Empty(t, upHosts(ipnet, 0))
+	hosts := upHosts(ipnet, 0.25)
+	require.InDelta(t, 1024, len(hosts), 150)
+	// the same hosts are up in every run
+	require.Equal(t, hosts, upHosts(ipnet, 0.25))
+	require.Equal(t, net.IP{127, 1, 0, 0}, upHosts(ipnet, 1)[0])
+
+	// hosts that differ only in the last byte are spread as well
+	_, ipnet, err = net.ParseCIDR("127.1.0.0/24")
+	require.NoError(t, err)
+	require.InDelta(t, 128, len(upHosts(ipnet, 0.5)), 40)
+}
@@ -0,0 +1,130 @@
+// Package tagpolicy reads scan policies of targets selected by their tags or other request metadata
+package tagpolicy
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/v-byte-cpu/sx/pkg/policy"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	ErrNoPolicies = errors.New("invalid tag policies: at least one policy required")
+
+	errPolicyMeta = errors.New("invalid tag policy: meta required")
+)
+
+// Config is a list of target policies, each target is scanned with the first policy matching its metadata
+type Config struct {
+	Policies []*Policy `yaml:"policies"`
+}
+
+// Policy limits the rate, retries and ports of matching targets
+type Policy struct {
+	Name string `yaml:"name"`
+	// Meta are values of metadata fields of the target, nested fields are separated by dots, e.g. tags.env
+	Meta map[string]string `yaml:"meta"`
+	// Rate is the maximum number of requests to all matching targets in the time window, e.g. 10/1s or 1/5s
+	Rate string `yaml:"rate"`
+	// Retries is the number of additional attempts of failed requests
+	Retries int `yaml:"retries"`
+	// Ports are allowed ports or port ranges, requests to other ports of matching targets are dropped
+	Ports []policy.Ports `yaml:"ports"`
+
+	interval time.Duration
+}
+
+// LoadFile reads tag policies from the YAML file
+func LoadFile(path string) (*Config, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Parse(f)
+}
+
+// Parse reads tag policies in YAML format, e.g.
+//
+//	policies:
+//	  - name: ics
+//	    meta: {tags: ics}
+//	    rate: 1/5s
+//	    retries: 2
+//	    ports: [102, 502, 20000]
+func Parse(r io.Reader) (*Config, error) {
+	var c Config
+	dec := yaml.NewDecoder(r)
+	dec.KnownFields(true)
+	if err := dec.Decode(&c); err != nil {
+		return nil, fmt.Errorf("invalid tag policies: %w", err)
+	}
+	if len(c.Policies) == 0 {
+		return nil, ErrNoPolicies
+	}
+	for i, p := range c.Policies {
+		if len(p.Name) == 0 {
+			p.Name = fmt.Sprintf("policy%d", i+1)
+		}
+		if err := p.parse(); err != nil {
+			return nil, err
+		}
+	}
+	return &c, nil
+}
+
+func (p *Policy) parse() (err error) {
+	if len(p.Meta) == 0 {
+		return fmt.Errorf("%w: %s", errPolicyMeta, p.Name)
+	}
+	if p.Retries < 0 {
+		return fmt.Errorf("invalid tag policy %s: negative retries", p.Name)
+	}
+	if len(p.Rate) > 0 {
+		if p.interval, err = parseRate(p.Rate); err != nil {
+			return fmt.Errorf("invalid tag policy %s: %w", p.Name, err)
+		}
+	}
+	return nil
+}
+
+// parseRate returns the interval between requests of the rate in the form count/window,
+// the window is one second if omitted, e.g. 10/s, 10/2s or 10
+func parseRate(rate string) (time.Duration, error) {
+	parts := strings.SplitN(rate, "/", 2)
+	count, err := strconv.ParseUint(strings.TrimSpace(parts[0]), 10, 31)
+	if err != nil || count == 0 {
+		return 0, fmt.Errorf("invalid rate %q", rate)
+	}
+	window := time.Second
+	if len(parts) > 1 {
+		win := strings.TrimSpace(parts[1])
+		if len(win) > 0 && (win[0] < '0' || win[0] > '9') {
+			win = "1" + win
+		}
+		if window, err = time.ParseDuration(win); err != nil || window <= 0 {
+			return 0, fmt.Errorf("invalid rate %q", rate)
+		}
+	}
+	return window / time.Duration(count), nil
+}
+
+// TargetPolicies returns policies of the scan engine in the order of the config
+func (c *Config) TargetPolicies() []*scan.TargetPolicy {
+	result := make([]*scan.TargetPolicy, 0, len(c.Policies))
+	for _, p := range c.Policies {
+		tp := &scan.TargetPolicy{Name: p.Name, Meta: p.Meta, Interval: p.interval, Retries: p.Retries}
+		for i := range p.Ports {
+			tp.Ports = append(tp.Ports, &p.Ports[i].PortRange)
+		}
+		result = append(result, tp)
+	}
+	return result
+}
@@ -0,0 +1,91 @@
+package tagpolicy
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+	c, err := Parse(strings.NewReader(`
+policies:
+  - name: ics
+    meta: {tags: ics}
+    rate: 1/5s
+    retries: 2
+    ports: [102, 502, 20000-20010]
+  - meta: {tags.env: prod}
+    rate: 100
+`))
+	require.NoError(t, err)
+	require.Equal(t, []*scan.TargetPolicy{
+		{
+			Name:     "ics",
+			Meta:     map[string]string{"tags": "ics"},
+			Interval: 5 * time.Second,
+			Retries:  2,
+			Ports: []*scan.PortRange{
+				{StartPort: 102, EndPort: 102},
+				{StartPort: 502, EndPort: 502},
+				{StartPort: 20000, EndPort: 20010},
+			},
+		},
+		{
+			Name:     "policy2",
+			Meta:     map[string]string{"tags.env": "prod"},
+			Interval: 10 * time.Millisecond,
+		},
+	}, c.TargetPolicies())
+}
+
+func TestParseRate(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		rate     string
+		expected time.Duration
+	}{
+		{rate: "10", expected: 100 * time.Millisecond},
+		{rate: "10/s", expected: 100 * time.Millisecond},
+		{rate: "2/1m", expected: 30 * time.Second},
+		{rate: "1/5s", expected: 5 * time.Second},
+	}
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.rate, func(t *testing.T) {
+			t.Parallel()
+			interval, err := parseRate(tt.rate)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, interval)
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		policies string
+	}{
+		{name: "NoPolicies", policies: "policies: []"},
+		{name: "NoMeta", policies: "policies: [{rate: 10/s}]"},
+		{name: "NegativeRetries", policies: "policies: [{meta: {tags: ics}, retries: -1}]"},
+		{name: "ZeroRate", policies: "policies: [{meta: {tags: ics}, rate: 0/s}]"},
+		{name: "InvalidRate", policies: "policies: [{meta: {tags: ics}, rate: fast}]"},
+		{name: "InvalidRateWindow", policies: "policies: [{meta: {tags: ics}, rate: 10/day}]"},
+		{name: "InvalidPort", policies: "policies: [{meta: {tags: ics}, ports: [modbus]}]"},
+		{name: "UnknownField", policies: "policies: [{meta: {tags: ics}, hosts: [10.0.0.1]}]"},
+		{name: "InvalidYAML", policies: "policies: [{"},
+	}
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := Parse(strings.NewReader(tt.policies))
+			require.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,3 @@
+// Package uring is an experimental io_uring backend for packet receiving and output file writes.
+// It is compiled only with the uring build tag and requires Linux 5.6 or later.
+package uring
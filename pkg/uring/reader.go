@@ -0,0 +1,171 @@
+//go:build linux && uring
+// +build linux,uring
+
+package uring
+
+import (
+	"math"
+	"sync"
+	"syscall"
+	"time"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+const (
+	defaultBufferCount = 64
+	defaultBufferSize  = 1 << 16
+	defaultReadTimeout = 100 * time.Millisecond
+
+	timeoutUserData = math.MaxUint64
+)
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "uring: read timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+// PacketReader receives datagrams from the socket keeping a receive operation in flight for each buffer,
+// so packets are copied by the kernel while the previous ones are processed
+type PacketReader struct {
+	mu          sync.Mutex
+	fd          int
+	ring        *ring
+	bufs        [][]byte
+	bufferCount int
+	bufferSize  int
+	readTimeout time.Duration
+	timeout     unix.Timespec
+	// timeoutPending is set while the timeout operation is in flight
+	timeoutPending bool
+	// last is the index of the buffer returned by the last read, it is resubmitted by the next read
+	last int
+}
+
+type ReaderOption func(*PacketReader)
+
+// WithBufferCount sets the number of receive operations in flight
+func WithBufferCount(count int) ReaderOption {
+	return func(r *PacketReader) {
+		r.bufferCount = count
+	}
+}
+
+// WithBufferSize sets the maximum size of received packets
+func WithBufferSize(size int) ReaderOption {
+	return func(r *PacketReader) {
+		r.bufferSize = size
+	}
+}
+
+// WithReadTimeout sets the time to wait for a packet, Read returns a timeout error after it
+func WithReadTimeout(timeout time.Duration) ReaderOption {
+	return func(r *PacketReader) {
+		r.readTimeout = timeout
+	}
+}
+
+// NewPacketReader creates a reader of the datagram or raw socket, the socket is not closed by the reader
+func NewPacketReader(fd int, opts ...ReaderOption) (*PacketReader, error) {
+	r := &PacketReader{
+		fd:          fd,
+		bufferCount: defaultBufferCount,
+		bufferSize:  defaultBufferSize,
+		readTimeout: defaultReadTimeout,
+		last:        -1,
+	}
+	for _, o := range opts {
+		o(r)
+	}
+	r.bufs = make([][]byte, r.bufferCount)
+	for i := range r.bufs {
+		r.bufs[i] = make([]byte, r.bufferSize)
+	}
+	r.timeout = unix.NsecToTimespec(r.readTimeout.Nanoseconds())
+
+	var err error
+	// one more entry for the timeout operation
+	if r.ring, err = newRing(uint32(len(r.bufs) + 1)); err != nil {
+		return nil, err
+	}
+	for i := range r.bufs {
+		r.prepareRecv(i)
+	}
+	if err = r.ring.submit(0); err != nil {
+		r.ring.close()
+		return nil, err
+	}
+	return r, nil
+}
+
+func (r *PacketReader) prepareRecv(idx int) {
+	e := r.ring.prepare()
+	e.opcode = opRecv
+	e.fd = int32(r.fd)
+	e.addr = uint64(uintptr(unsafe.Pointer(&r.bufs[idx][0])))
+	e.len = uint32(len(r.bufs[idx]))
+	e.userData = uint64(idx)
+}
+
+func (r *PacketReader) prepareTimeout() {
+	e := r.ring.prepare()
+	e.opcode = opTimeout
+	e.addr = uint64(uintptr(unsafe.Pointer(&r.timeout)))
+	e.len = 1
+	e.userData = timeoutUserData
+	r.timeoutPending = true
+}
+
+// Read returns the next received packet, the data is valid until the next call of Read
+func (r *PacketReader) Read() ([]byte, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.ring == nil {
+		return nil, syscall.EBADF
+	}
+	if r.last >= 0 {
+		r.prepareRecv(r.last)
+		r.last = -1
+	}
+	for {
+		c, ok := r.ring.peek()
+		if !ok {
+			if !r.timeoutPending {
+				r.prepareTimeout()
+			}
+			if err := r.ring.submit(1); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		userData, res := c.userData, c.res
+		r.ring.advance()
+
+		if userData == timeoutUserData {
+			r.timeoutPending = false
+			if _, ok := r.ring.peek(); ok {
+				continue
+			}
+			return nil, timeoutError{}
+		}
+		idx := int(userData)
+		if res < 0 {
+			r.prepareRecv(idx)
+			return nil, syscall.Errno(-res)
+		}
+		r.last = idx
+		return r.bufs[idx][:res], nil
+	}
+}
+
+// Close cancels receive operations and releases the io_uring instance
+func (r *PacketReader) Close() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.ring != nil {
+		r.ring.close()
+		r.ring = nil
+	}
+}
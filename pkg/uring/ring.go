@@ -0,0 +1,227 @@
+//go:build linux && uring
+// +build linux,uring
+
+package uring
+
+import (
+	"sync/atomic"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+const (
+	opRead    = 22
+	opWrite   = 23
+	opRecv    = 27
+	opTimeout = 11
+
+	enterGetEvents = 1
+
+	offSQRing = 0
+	offCQRing = 0x8000000
+	offSQEs   = 0x10000000
+
+	sqeSize = 64
+	cqeSize = 16
+)
+
+type sqRingOffsets struct {
+	head        uint32
+	tail        uint32
+	ringMask    uint32
+	ringEntries uint32
+	flags       uint32
+	dropped     uint32
+	array       uint32
+	resv1       uint32
+	resv2       uint64
+}
+
+type cqRingOffsets struct {
+	head        uint32
+	tail        uint32
+	ringMask    uint32
+	ringEntries uint32
+	overflow    uint32
+	cqes        uint32
+	flags       uint32
+	resv1       uint32
+	resv2       uint64
+}
+
+type params struct {
+	sqEntries    uint32
+	cqEntries    uint32
+	flags        uint32
+	sqThreadCPU  uint32
+	sqThreadIdle uint32
+	features     uint32
+	wqFd         uint32
+	resv         [3]uint32
+	sqOff        sqRingOffsets
+	cqOff        cqRingOffsets
+}
+
+// sqe is a submission queue entry
+type sqe struct {
+	opcode      uint8
+	flags       uint8
+	ioprio      uint16
+	fd          int32
+	off         uint64
+	addr        uint64
+	len         uint32
+	opFlags     uint32
+	userData    uint64
+	bufIndex    uint16
+	personality uint16
+	spliceFdIn  int32
+	addr3       uint64
+	pad         uint64
+}
+
+// cqe is a completion queue entry
+type cqe struct {
+	userData uint64
+	res      int32
+	flags    uint32
+}
+
+// ring is a minimal io_uring instance, it is not safe for concurrent use
+type ring struct {
+	fd int
+
+	sqMem   []byte
+	cqMem   []byte
+	sqesMem []byte
+
+	sqHead, sqTail, sqMask *uint32
+	sqArray                []uint32
+	sqes                   []sqe
+	cqHead, cqTail, cqMask *uint32
+	cqes                   []cqe
+
+	// number of prepared entries not yet submitted to the kernel
+	pending uint32
+}
+
+func newRing(entries uint32) (r *ring, err error) {
+	var p params
+	fd, _, errno := unix.Syscall(unix.SYS_IO_URING_SETUP, uintptr(entries), uintptr(unsafe.Pointer(&p)), 0)
+	if errno != 0 {
+		return nil, errno
+	}
+	r = &ring{fd: int(fd)}
+	defer func() {
+		if err != nil {
+			r.close()
+		}
+	}()
+
+	if r.sqMem, err = unix.Mmap(r.fd, offSQRing, int(p.sqOff.array+p.sqEntries*4),
+		unix.PROT_READ|unix.PROT_WRITE, unix.MAP_SHARED|unix.MAP_POPULATE); err != nil {
+		return
+	}
+	if r.cqMem, err = unix.Mmap(r.fd, offCQRing, int(p.cqOff.cqes+p.cqEntries*cqeSize),
+		unix.PROT_READ|unix.PROT_WRITE, unix.MAP_SHARED|unix.MAP_POPULATE); err != nil {
+		return
+	}
+	if r.sqesMem, err = unix.Mmap(r.fd, offSQEs, int(p.sqEntries*sqeSize),
+		unix.PROT_READ|unix.PROT_WRITE, unix.MAP_SHARED|unix.MAP_POPULATE); err != nil {
+		return
+	}
+
+	r.sqHead = (*uint32)(unsafe.Pointer(&r.sqMem[p.sqOff.head]))
+	r.sqTail = (*uint32)(unsafe.Pointer(&r.sqMem[p.sqOff.tail]))
+	r.sqMask = (*uint32)(unsafe.Pointer(&r.sqMem[p.sqOff.ringMask]))
+	r.sqArray = unsafe.Slice((*uint32)(unsafe.Pointer(&r.sqMem[p.sqOff.array])), p.sqEntries)
+	r.sqes = unsafe.Slice((*sqe)(unsafe.Pointer(&r.sqesMem[0])), p.sqEntries)
+
+	r.cqHead = (*uint32)(unsafe.Pointer(&r.cqMem[p.cqOff.head]))
+	r.cqTail = (*uint32)(unsafe.Pointer(&r.cqMem[p.cqOff.tail]))
+	r.cqMask = (*uint32)(unsafe.Pointer(&r.cqMem[p.cqOff.ringMask]))
+	r.cqes = unsafe.Slice((*cqe)(unsafe.Pointer(&r.cqMem[p.cqOff.cqes])), p.cqEntries)
+	return r, nil
+}
+
+// prepare returns the next free submission queue entry, nil is returned if the queue is full
+func (r *ring) prepare() *sqe {
+	head := atomic.LoadUint32(r.sqHead)
+	tail := *r.sqTail + r.pending
+	if tail-head >= uint32(len(r.sqes)) {
+		return nil
+	}
+	idx := tail & *r.sqMask
+	e := &r.sqes[idx]
+	*e = sqe{}
+	r.sqArray[idx] = idx
+	r.pending++
+	return e
+}
+
+// submit publishes prepared entries and waits for at least minComplete completions
+func (r *ring) submit(minComplete uint32) error {
+	toSubmit := r.pending
+	if toSubmit > 0 {
+		atomic.StoreUint32(r.sqTail, *r.sqTail+toSubmit)
+		r.pending = 0
+	}
+	if toSubmit == 0 && minComplete == 0 {
+		return nil
+	}
+	var flags uintptr
+	if minComplete > 0 {
+		flags = enterGetEvents
+	}
+	for {
+		_, _, errno := unix.Syscall6(unix.SYS_IO_URING_ENTER, uintptr(r.fd),
+			uintptr(toSubmit), uintptr(minComplete), flags, 0, 0)
+		if errno == syscall.EINTR {
+			// entries are already consumed by the kernel if it was interrupted while waiting
+			toSubmit = 0
+			continue
+		}
+		if errno != 0 {
+			return errno
+		}
+		return nil
+	}
+}
+
+// peek returns the next completion queue entry without consuming it
+func (r *ring) peek() (*cqe, bool) {
+	head := *r.cqHead
+	if head == atomic.LoadUint32(r.cqTail) {
+		return nil, false
+	}
+	return &r.cqes[head&*r.cqMask], true
+}
+
+// advance consumes the completion queue entry returned by peek
+func (r *ring) advance() {
+	atomic.StoreUint32(r.cqHead, *r.cqHead+1)
+}
+
+// wait returns the next completion queue entry, blocking until it is available
+func (r *ring) wait() (*cqe, error) {
+	for {
+		if e, ok := r.peek(); ok {
+			return e, nil
+		}
+		if err := r.submit(1); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (r *ring) close() {
+	for _, mem := range [][]byte{r.sqesMem, r.cqMem, r.sqMem} {
+		if mem != nil {
+			_ = unix.Munmap(mem)
+		}
+	}
+	r.sqesMem, r.cqMem, r.sqMem = nil, nil, nil
+	unix.Close(r.fd)
+}
@@ -0,0 +1,206 @@
+//go:build linux && uring
+// +build linux,uring
+
+package uring
+
+import (
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/unix"
+)
+
+// skipUnsupported skips the test if io_uring is not available, e.g. on old kernels or in sandboxes
+func skipUnsupported(tb testing.TB, err error) {
+	tb.Helper()
+	if errors.Is(err, syscall.ENOSYS) || errors.Is(err, syscall.EPERM) {
+		tb.Skip("io_uring is not supported:", err)
+	}
+}
+
+func newTestWriter(tb testing.TB) (*Writer, *os.File) {
+	tb.Helper()
+	f, err := os.Create(filepath.Join(tb.TempDir(), "out.txt"))
+	require.NoError(tb, err)
+	tb.Cleanup(func() { f.Close() })
+	w, err := NewWriter(f)
+	skipUnsupported(tb, err)
+	require.NoError(tb, err)
+	tb.Cleanup(func() { w.Close() })
+	return w, f
+}
+
+func TestWriter(t *testing.T) {
+	t.Parallel()
+	w, f := newTestWriter(t)
+
+	lines := []string{"192.168.0.1 22\n", "192.168.0.2 80\n", strings.Repeat("a", 100000) + "\n"}
+	for _, line := range lines {
+		n, err := w.Write([]byte(line))
+		require.NoError(t, err)
+		require.Equal(t, len(line), n)
+	}
+	data, err := os.ReadFile(f.Name())
+	require.NoError(t, err)
+	require.Equal(t, strings.Join(lines, ""), string(data))
+}
+
+func TestWriterClosed(t *testing.T) {
+	t.Parallel()
+	w, _ := newTestWriter(t)
+	require.NoError(t, w.Close())
+	_, err := w.Write([]byte("data"))
+	require.ErrorIs(t, err, os.ErrClosed)
+}
+
+func newSocketPair(tb testing.TB) (readFd, writeFd int) {
+	tb.Helper()
+	fds, err := unix.Socketpair(unix.AF_UNIX, unix.SOCK_DGRAM, 0)
+	require.NoError(tb, err)
+	tb.Cleanup(func() {
+		unix.Close(fds[0])
+		unix.Close(fds[1])
+	})
+	return fds[0], fds[1]
+}
+
+func newTestPacketReader(tb testing.TB, fd int, opts ...ReaderOption) *PacketReader {
+	tb.Helper()
+	r, err := NewPacketReader(fd, opts...)
+	skipUnsupported(tb, err)
+	require.NoError(tb, err)
+	tb.Cleanup(r.Close)
+	return r
+}
+
+func TestPacketReader(t *testing.T) {
+	t.Parallel()
+	readFd, writeFd := newSocketPair(t)
+	r := newTestPacketReader(t, readFd, WithBufferCount(4), WithBufferSize(1500))
+
+	packets := [][]byte{[]byte("packet1"), []byte("packet2"), []byte("packet3"),
+		[]byte("packet4"), []byte("packet5"), []byte("packet6")}
+	for _, pkt := range packets {
+		_, err := unix.Write(writeFd, pkt)
+		require.NoError(t, err)
+	}
+	var result []string
+	for range packets {
+		data, err := r.Read()
+		require.NoError(t, err)
+		result = append(result, string(data))
+	}
+	require.ElementsMatch(t, []string{"packet1", "packet2", "packet3", "packet4", "packet5", "packet6"}, result)
+}
+
+func TestPacketReaderTimeout(t *testing.T) {
+	t.Parallel()
+	readFd, writeFd := newSocketPair(t)
+	r := newTestPacketReader(t, readFd, WithBufferCount(2), WithReadTimeout(20*time.Millisecond))
+
+	_, err := r.Read()
+	var netErr net.Error
+	require.ErrorAs(t, err, &netErr)
+	require.True(t, netErr.Timeout())
+
+	_, err = unix.Write(writeFd, []byte("packet"))
+	require.NoError(t, err)
+	var data []byte
+	for {
+		if data, err = r.Read(); !errors.As(err, &netErr) {
+			break
+		}
+	}
+	require.NoError(t, err)
+	require.Equal(t, "packet", string(data))
+}
+
+func TestPacketReaderClosed(t *testing.T) {
+	t.Parallel()
+	readFd, _ := newSocketPair(t)
+	r := newTestPacketReader(t, readFd)
+	r.Close()
+	_, err := r.Read()
+	require.ErrorIs(t, err, syscall.EBADF)
+}
+
+var benchLine = []byte(`{"scan":"tcpsyn","ip":"192.168.0.1","port":443}` + "\n")
+
+func BenchmarkWriter(b *testing.B) {
+	w, _ := newTestWriter(b)
+	b.SetBytes(int64(len(benchLine)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := w.Write(benchLine); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkFileWrite(b *testing.B) {
+	f, err := os.Create(filepath.Join(b.TempDir(), "out.txt"))
+	require.NoError(b, err)
+	defer f.Close()
+	b.SetBytes(int64(len(benchLine)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := f.Write(benchLine); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// benchmarkPackets sends packets to the socket pair in the background until the benchmark ends
+func benchmarkPackets(b *testing.B, writeFd int) {
+	pkt := make([]byte, 64)
+	done := make(chan struct{})
+	b.Cleanup(func() { close(done) })
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			_, _ = unix.Write(writeFd, pkt)
+		}
+	}()
+}
+
+func BenchmarkPacketReader(b *testing.B) {
+	readFd, writeFd := newSocketPair(b)
+	r := newTestPacketReader(b, readFd)
+	benchmarkPackets(b, writeFd)
+	b.ResetTimer()
+	var netErr net.Error
+	for i := 0; i < b.N; i++ {
+		_, err := r.Read()
+		// retry on timeout like the packet receiver does
+		if errors.As(err, &netErr) && netErr.Timeout() {
+			i--
+			continue
+		}
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkSocketRead(b *testing.B) {
+	readFd, writeFd := newSocketPair(b)
+	benchmarkPackets(b, writeFd)
+	buf := make([]byte, defaultBufferSize)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := unix.Read(readFd, buf); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
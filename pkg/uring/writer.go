@@ -0,0 +1,83 @@
+//go:build linux && uring
+// +build linux,uring
+
+package uring
+
+import (
+	"io"
+	"os"
+	"runtime"
+	"sync"
+	"syscall"
+	"unsafe"
+)
+
+// currentPos is the offset of write operations to use and update the current file position
+const currentPos = ^uint64(0)
+
+// Writer writes data to the file with io_uring write operations, it is safe for concurrent use
+type Writer struct {
+	mu   sync.Mutex
+	file *os.File
+	ring *ring
+	// buf holds the data while the kernel writes it, so it can't be moved or collected
+	buf []byte
+}
+
+// Assert that uring.Writer conforms to the io.Writer interface
+var _ io.Writer = (*Writer)(nil)
+
+func NewWriter(file *os.File) (*Writer, error) {
+	r, err := newRing(4)
+	if err != nil {
+		return nil, err
+	}
+	return &Writer{file: file, ring: r}, nil
+}
+
+func (w *Writer) Write(p []byte) (n int, err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.ring == nil {
+		return 0, os.ErrClosed
+	}
+	w.buf = append(w.buf[:0], p...)
+	for n < len(w.buf) {
+		e := w.ring.prepare()
+		e.opcode = opWrite
+		e.fd = int32(w.file.Fd())
+		e.off = currentPos
+		e.addr = uint64(uintptr(unsafe.Pointer(&w.buf[n])))
+		e.len = uint32(len(w.buf) - n)
+
+		var c *cqe
+		if c, err = w.ring.wait(); err != nil {
+			return
+		}
+		res := c.res
+		w.ring.advance()
+		if res < 0 {
+			if syscall.Errno(-res) == syscall.EINTR || syscall.Errno(-res) == syscall.EAGAIN {
+				continue
+			}
+			return n, &os.PathError{Op: "write", Path: w.file.Name(), Err: syscall.Errno(-res)}
+		}
+		if res == 0 {
+			return n, io.ErrShortWrite
+		}
+		n += int(res)
+	}
+	runtime.KeepAlive(w.file)
+	return
+}
+
+// Close releases the io_uring instance, the file is not closed
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.ring != nil {
+		w.ring.close()
+		w.ring = nil
+	}
+	return nil
+}
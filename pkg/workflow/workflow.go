@@ -0,0 +1,177 @@
+// Package workflow reads multi-stage scan workflows, each stage runs one sx command with results of earlier stages as its input
+package workflow
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/v-byte-cpu/sx/pkg/policy"
+	"gopkg.in/yaml.v3"
+)
+
+// ReportCommand is the built-in command of stages that merge results of their input stages into the report
+const ReportCommand = "report"
+
+var (
+	ErrNoStages = errors.New("invalid workflow: at least one stage required")
+
+	errStageName    = errors.New("invalid workflow stage name")
+	errStageCommand = errors.New("invalid workflow stage: command required")
+	errStageInput   = errors.New("invalid workflow stage input")
+)
+
+// stageNameRegexp matches stage names, they are used as names of result files
+var stageNameRegexp = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]*$`)
+
+// Config is a list of stages that run in order
+type Config struct {
+	// Rate is the rate limit shared by all stages in the form count/window, e.g. 1000/s.
+	// Stages run one at a time, so the workflow never sends faster than the rate
+	Rate   string   `yaml:"rate"`
+	Stages []*Stage `yaml:"stages"`
+}
+
+// Stage runs the sx command, its results are written in JSON format and can be the input of later stages
+type Stage struct {
+	Name string `yaml:"name"`
+	// Command is the sx command path, e.g. tcp syn, or report
+	Command string `yaml:"command"`
+	// Args are arguments and flags of the command
+	Args []string `yaml:"args"`
+	// Input are earlier stages whose results are targets of the command, they are passed with the --file flag
+	Input []string `yaml:"input"`
+	// ARPCache is the earlier ARP scan stage whose results are passed with the --arp-cache flag
+	ARPCache string `yaml:"arp_cache"`
+	// Ports select results of input stages by their ports, all results are selected if empty
+	Ports []policy.Ports `yaml:"ports"`
+	// Output is the file results are copied to, results of the report stage are written to stdout if empty
+	Output string `yaml:"output"`
+}
+
+// LoadFile reads the workflow from the YAML file
+func LoadFile(path string) (*Config, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Parse(f)
+}
+
+// Parse reads the workflow in YAML format, e.g.
+//
+//	rate: 1000/s
+//	stages:
+//	  - name: hosts
+//	    command: arp
+//	    args: [192.168.0.0/24]
+//	  - name: ports
+//	    command: tcp syn
+//	    args: [-p, 1-1000]
+//	    input: [hosts]
+//	    arp_cache: hosts
+//	  - name: tls
+//	    command: tls
+//	    input: [ports]
+//	    ports: [443, 8443]
+//	  - name: report
+//	    command: report
+//	    input: [ports, tls]
+//	    output: report.jsonl
+func Parse(r io.Reader) (*Config, error) {
+	var c Config
+	dec := yaml.NewDecoder(r)
+	dec.KnownFields(true)
+	if err := dec.Decode(&c); err != nil {
+		return nil, fmt.Errorf("invalid workflow: %w", err)
+	}
+	if len(c.Stages) == 0 {
+		return nil, ErrNoStages
+	}
+	names := make(map[string]bool, len(c.Stages))
+	for i, s := range c.Stages {
+		if len(s.Name) == 0 {
+			s.Name = fmt.Sprintf("stage%d", i+1)
+		}
+		if !stageNameRegexp.MatchString(s.Name) || names[s.Name] {
+			return nil, fmt.Errorf("%w: %q", errStageName, s.Name)
+		}
+		if err := s.validate(names); err != nil {
+			return nil, err
+		}
+		names[s.Name] = true
+	}
+	return &c, nil
+}
+
+// validate checks the stage, earlier are names of stages that run before it
+func (s *Stage) validate(earlier map[string]bool) error {
+	if len(s.CommandPath()) == 0 {
+		return fmt.Errorf("%w: %s", errStageCommand, s.Name)
+	}
+	for _, name := range s.Input {
+		if !earlier[name] {
+			return fmt.Errorf("%w: stage %s reads unknown or later stage %q", errStageInput, s.Name, name)
+		}
+	}
+	if len(s.ARPCache) > 0 && !earlier[s.ARPCache] {
+		return fmt.Errorf("%w: stage %s reads ARP cache of unknown or later stage %q", errStageInput, s.Name, s.ARPCache)
+	}
+	if s.IsReport() && len(s.Input) == 0 {
+		return fmt.Errorf("%w: report stage %s requires input stages", errStageInput, s.Name)
+	}
+	return nil
+}
+
+// CommandPath returns names of the command and its subcommands
+func (s *Stage) CommandPath() []string {
+	return strings.Fields(s.Command)
+}
+
+// IsReport returns true for stages of the built-in report command
+func (s *Stage) IsReport() bool {
+	return strings.TrimSpace(s.Command) == ReportCommand
+}
+
+// CopyResults copies results in JSON lines format from r to w that are selected by ports of the stage
+// and returns the number of copied results. Results without the port are selected only if ports are empty.
+func (s *Stage) CopyResults(w io.Writer, r io.Reader) (n int, err error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(bytes.TrimSpace(line)) == 0 || !s.selects(line) {
+			continue
+		}
+		if _, err = fmt.Fprintf(w, "%s\n", line); err != nil {
+			return
+		}
+		n++
+	}
+	return n, scanner.Err()
+}
+
+func (s *Stage) selects(line []byte) bool {
+	if len(s.Ports) == 0 {
+		return true
+	}
+	var result struct {
+		Port uint16 `json:"port"`
+	}
+	if err := json.Unmarshal(line, &result); err != nil {
+		return false
+	}
+	for _, r := range s.Ports {
+		if r.StartPort <= result.Port && result.Port <= r.EndPort {
+			return true
+		}
+	}
+	return false
+}
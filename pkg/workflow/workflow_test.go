@@ -0,0 +1,154 @@
+package workflow
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"github.com/v-byte-cpu/sx/pkg/policy"
+	"github.com/v-byte-cpu/sx/pkg/scan"
+)
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+	c, err := Parse(strings.NewReader(`
+rate: 1000/s
+stages:
+  - name: hosts
+    command: arp
+    args: [192.168.0.0/24]
+  - name: ports
+    command: tcp syn
+    args: [-p, 1-1000]
+    input: [hosts]
+    arp_cache: hosts
+  - command: tls
+    input: [ports]
+    ports: [443, 8000-8443]
+  - name: report
+    command: report
+    input: [ports, stage3]
+    output: report.jsonl
+`))
+	require.NoError(t, err)
+	require.Equal(t, &Config{
+		Rate: "1000/s",
+		Stages: []*Stage{
+			{Name: "hosts", Command: "arp", Args: []string{"192.168.0.0/24"}},
+			{Name: "ports", Command: "tcp syn", Args: []string{"-p", "1-1000"}, Input: []string{"hosts"}, ARPCache: "hosts"},
+			{Name: "stage3", Command: "tls", Input: []string{"ports"}, Ports: []policy.Ports{
+				{PortRange: scan.PortRange{StartPort: 443, EndPort: 443}},
+				{PortRange: scan.PortRange{StartPort: 8000, EndPort: 8443}},
+			}},
+			{Name: "report", Command: "report", Input: []string{"ports", "stage3"}, Output: "report.jsonl"},
+		},
+	}, c)
+	require.Equal(t, []string{"tcp", "syn"}, c.Stages[1].CommandPath())
+	require.False(t, c.Stages[1].IsReport())
+	require.True(t, c.Stages[3].IsReport())
+}
+
+func TestParseError(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{
+			name:  "NoStages",
+			input: "rate: 10/s\n",
+		},
+		{
+			name:  "UnknownField",
+			input: "stages:\n  - command: arp\n    target: 10.0.0.1\n",
+		},
+		{
+			name:  "NoCommand",
+			input: "stages:\n  - name: hosts\n",
+		},
+		{
+			name:  "InvalidName",
+			input: "stages:\n  - name: ../hosts\n    command: arp\n",
+		},
+		{
+			name:  "DuplicateName",
+			input: "stages:\n  - name: hosts\n    command: arp\n  - name: hosts\n    command: icmp\n",
+		},
+		{
+			name:  "UnknownInput",
+			input: "stages:\n  - command: tls\n    input: [ports]\n",
+		},
+		{
+			name:  "LaterInput",
+			input: "stages:\n  - name: tls\n    command: tls\n    input: [ports]\n  - name: ports\n    command: tcp syn\n",
+		},
+		{
+			name:  "UnknownARPCache",
+			input: "stages:\n  - command: tcp syn\n    arp_cache: hosts\n",
+		},
+		{
+			name:  "ReportWithoutInput",
+			input: "stages:\n  - command: report\n",
+		},
+		{
+			name:  "InvalidPort",
+			input: "stages:\n  - command: tls\n    ports: [0]\n",
+		},
+	}
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := Parse(strings.NewReader(tt.input))
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestCopyResults(t *testing.T) {
+	t.Parallel()
+	input := strings.Join([]string{
+		`{"scan":"tcpsyn","ip":"192.168.0.1","port":22}`,
+		`{"scan":"tcpsyn","ip":"192.168.0.1","port":443}`,
+		``,
+		`{"scan":"tcpsyn","ip":"192.168.0.2","port":8080}`,
+		`{"ip":"192.168.0.3","mac":"00:11:22:33:44:55"}`,
+	}, "\n")
+	tests := []struct {
+		name     string
+		ports    []policy.Ports
+		expected string
+		count    int
+	}{
+		{
+			name: "AllResults",
+			expected: `{"scan":"tcpsyn","ip":"192.168.0.1","port":22}` + "\n" +
+				`{"scan":"tcpsyn","ip":"192.168.0.1","port":443}` + "\n" +
+				`{"scan":"tcpsyn","ip":"192.168.0.2","port":8080}` + "\n" +
+				`{"ip":"192.168.0.3","mac":"00:11:22:33:44:55"}` + "\n",
+			count: 4,
+		},
+		{
+			name: "SelectedPorts",
+			ports: []policy.Ports{
+				{PortRange: scan.PortRange{StartPort: 443, EndPort: 443}},
+				{PortRange: scan.PortRange{StartPort: 8000, EndPort: 8999}},
+			},
+			expected: `{"scan":"tcpsyn","ip":"192.168.0.1","port":443}` + "\n" +
+				`{"scan":"tcpsyn","ip":"192.168.0.2","port":8080}` + "\n",
+			count: 2,
+		},
+	}
+	for _, vtt := range tests {
+		tt := vtt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			stage := &Stage{Ports: tt.ports}
+			var out strings.Builder
+			n, err := stage.CopyResults(&out, strings.NewReader(input))
+			require.NoError(t, err)
+			require.Equal(t, tt.count, n)
+			require.Equal(t, tt.expected, out.String())
+		})
+	}
+}